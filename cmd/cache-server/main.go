@@ -0,0 +1,149 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	goflags "flag"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apiserver/pkg/authorization/authorizerfactory"
+	genericapifilters "k8s.io/apiserver/pkg/endpoints/filters"
+	apirequest "k8s.io/apiserver/pkg/endpoints/request"
+	genericapiserver "k8s.io/apiserver/pkg/server"
+	genericfilters "k8s.io/apiserver/pkg/server/filters"
+	"k8s.io/client-go/tools/clientcmd"
+	utilflag "k8s.io/component-base/cli/flag"
+	"k8s.io/component-base/logs"
+	"k8s.io/component-base/version"
+
+	cacheserveroptions "github.com/kcp-dev/kcp/cmd/cache-server/options"
+	cacheserver "github.com/kcp-dev/kcp/pkg/cache/server"
+)
+
+func main() {
+	ctx := genericapiserver.SetupSignalContext()
+
+	rand.Seed(time.Now().UTC().UnixNano())
+
+	pflag.CommandLine.SetNormalizeFunc(utilflag.WordSepNormalizeFunc)
+	pflag.CommandLine.AddGoFlagSet(goflags.CommandLine)
+
+	logs.InitLogs()
+	defer logs.FlushLogs()
+
+	command := NewCacheServerCommand(ctx)
+	if err := command.Execute(); err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+}
+
+func NewCacheServerCommand(ctx context.Context) *cobra.Command {
+	options := cacheserveroptions.NewOptions()
+	cmd := &cobra.Command{
+		Use:   "cache-server",
+		Short: "Replicates APIExports, ClusterWorkspaceTypes and ClusterWorkspaceShards of all shards",
+		Long: `cache-server replicates the objects controllers need to resolve across shards,
+i.e. APIExports, ClusterWorkspaceTypes and ClusterWorkspaceShards, from all shards
+registered in the root workspace, and serves them read-only. Requests must present
+a client certificate signed by --client-ca-file of a user in one of the
+--authorized-groups.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := options.Logs.ValidateAndApply(); err != nil {
+				return err
+			}
+			if err := options.Complete(); err != nil {
+				return err
+			}
+			if errs := options.Validate(); errs != nil {
+				return errors.NewAggregate(errs)
+			}
+
+			rootShardConfig, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+				&clientcmd.ClientConfigLoadingRules{ExplicitPath: options.Kubeconfig},
+				&clientcmd.ConfigOverrides{},
+			).ClientConfig()
+			if err != nil {
+				return err
+			}
+
+			server := cacheserver.NewServer(rootShardConfig, options.ResyncPeriod)
+
+			var servingInfo *genericapiserver.SecureServingInfo
+			if err := options.SecureServing.ApplyTo(&servingInfo); err != nil {
+				return err
+			}
+
+			var authenticationInfo genericapiserver.AuthenticationInfo
+			if err := options.ApplyAuthenticationTo(&authenticationInfo, servingInfo); err != nil {
+				return err
+			}
+
+			codecs := newCodecs()
+			requestInfoResolver := &apirequest.RequestInfoFactory{APIPrefixes: sets.NewString("api", "apis"), GrouplessAPIPrefixes: sets.NewString("api")}
+
+			var handler http.Handler = server
+			handler = genericapifilters.WithAuthorization(handler, authorizerfactory.NewPrivilegedGroups(options.AuthorizedGroups...), codecs)
+			handler = genericapifilters.WithAuthentication(handler, authenticationInfo.Authenticator, genericapifilters.Unauthorized(codecs), nil)
+			handler = genericapifilters.WithRequestInfo(handler, requestInfoResolver)
+			handler = genericfilters.WithPanicRecovery(handler, requestInfoResolver)
+
+			doneCh, err := servingInfo.Serve(handler, time.Second*60, ctx.Done())
+			if err != nil {
+				return err
+			}
+
+			if err := server.Run(ctx); err != nil {
+				return err
+			}
+
+			<-doneCh
+			return nil
+		},
+	}
+
+	options.AddFlags(cmd.Flags())
+
+	if v := version.Get().String(); len(v) == 0 {
+		cmd.Version = "<unknown>"
+	} else {
+		cmd.Version = v
+	}
+
+	return cmd
+}
+
+// newCodecs returns the codecs to encode the Status responses of rejected requests.
+func newCodecs() serializer.CodecFactory {
+	scheme := runtime.NewScheme()
+	metav1.AddToGroupVersion(scheme, schema.GroupVersion{Group: "", Version: "v1"})
+	return serializer.NewCodecFactory(scheme)
+}
@@ -0,0 +1,134 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package options
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	"k8s.io/apiserver/pkg/authentication/request/x509"
+	"k8s.io/apiserver/pkg/authentication/user"
+	genericapiserver "k8s.io/apiserver/pkg/server"
+	apiserveroptions "k8s.io/apiserver/pkg/server/options"
+	"k8s.io/component-base/config"
+	"k8s.io/component-base/logs"
+)
+
+type Options struct {
+	SecureServing apiserveroptions.SecureServingOptions
+	ClientCert    apiserveroptions.ClientCertAuthenticationOptions
+	Logs          *logs.Options
+
+	RootDirectory string
+	// Kubeconfig is the kubeconfig of the root shard, also used to talk to all other shards.
+	Kubeconfig   string
+	ResyncPeriod time.Duration
+	// AuthorizedGroups are the groups of the users allowed to read from the cache server.
+	AuthorizedGroups []string
+}
+
+func NewOptions() *Options {
+	o := &Options{
+		SecureServing: *apiserveroptions.NewSecureServingOptions(),
+		Logs:          logs.NewOptions(),
+
+		RootDirectory: ".kcp",
+		ResyncPeriod:  10 * time.Hour,
+
+		AuthorizedGroups: []string{user.SystemPrivilegedGroup},
+	}
+
+	// Default to -v=2
+	o.Logs.Config.Verbosity = config.VerbosityLevel(2)
+
+	o.SecureServing.BindPort = 6444
+	o.SecureServing.ServerCert.CertDirectory = ""
+	o.SecureServing.ServerCert.PairName = "cache-server"
+
+	return o
+}
+
+func (o *Options) AddFlags(fs *pflag.FlagSet) {
+	o.SecureServing.AddFlags(fs)
+	o.ClientCert.AddFlags(fs)
+
+	o.Logs.AddFlags(fs)
+
+	fs.StringVar(&o.RootDirectory, "root-directory", o.RootDirectory, "Root directory.")
+	fs.StringVar(&o.Kubeconfig, "kubeconfig", o.Kubeconfig, "The kubeconfig of the root shard. Its credentials are used for all shards.")
+	fs.DurationVar(&o.ResyncPeriod, "resync-period", o.ResyncPeriod, "The resync period of the replicated objects.")
+	fs.StringSliceVar(&o.AuthorizedGroups, "authorized-groups", o.AuthorizedGroups, "The groups of the users allowed to read from the cache server. Shards authenticate with client certificates in the system:masters group.")
+}
+
+func (o *Options) Complete() error {
+	if !filepath.IsAbs(o.RootDirectory) {
+		pwd, err := os.Getwd()
+		if err != nil {
+			return err
+		}
+		o.RootDirectory = filepath.Join(pwd, o.RootDirectory)
+	}
+
+	if len(o.SecureServing.ServerCert.CertDirectory) == 0 {
+		o.SecureServing.ServerCert.CertDirectory = o.RootDirectory
+	}
+	if !filepath.IsAbs(o.SecureServing.ServerCert.CertDirectory) {
+		o.SecureServing.ServerCert.CertDirectory = filepath.Join(o.RootDirectory, o.SecureServing.ServerCert.CertDirectory)
+	}
+
+	return o.SecureServing.MaybeDefaultWithSelfSignedCerts("localhost", nil, nil)
+}
+
+func (o *Options) Validate() []error {
+	var errs []error
+
+	errs = append(errs, o.SecureServing.Validate()...)
+	if o.ClientCert.ClientCA == "" {
+		errs = append(errs, fmt.Errorf("--client-ca-file is required"))
+	}
+	if len(o.AuthorizedGroups) == 0 {
+		errs = append(errs, fmt.Errorf("--authorized-groups must not be empty"))
+	}
+	if o.Kubeconfig == "" {
+		errs = append(errs, fmt.Errorf("--kubeconfig is required"))
+	}
+	if o.ResyncPeriod < 0 {
+		errs = append(errs, fmt.Errorf("--resync-period must not be negative"))
+	}
+
+	return errs
+}
+
+// ApplyAuthenticationTo sets up the x509 authenticator of the client-ca-file option.
+func (o *Options) ApplyAuthenticationTo(authenticationInfo *genericapiserver.AuthenticationInfo, servingInfo *genericapiserver.SecureServingInfo) error {
+	clientCAProvider, err := o.ClientCert.GetClientCAContentProvider()
+	if err != nil {
+		return fmt.Errorf("unable to load client CA provider: %w", err)
+	}
+	if clientCAProvider == nil {
+		return fmt.Errorf("--client-ca-file is required")
+	}
+	if err = authenticationInfo.ApplyClientCert(clientCAProvider, servingInfo); err != nil {
+		return fmt.Errorf("unable to assign client CA provider: %w", err)
+	}
+	authenticationInfo.Authenticator = x509.NewDynamic(clientCAProvider.VerifyOptions, x509.CommonNameUserConversion)
+	return nil
+}
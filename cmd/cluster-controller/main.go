@@ -102,6 +102,7 @@ func main() {
 		crdClusterClient,
 		kcpClusterClient,
 		options.ApiResourceOptions.AutoPublishAPIs,
+		options.ApiResourceOptions.AutoPublishAPIGroups,
 		kcpSharedInformerFactory.Apiresource().V1alpha1().NegotiatedAPIResources(),
 		kcpSharedInformerFactory.Apiresource().V1alpha1().APIResourceImports(),
 		crdSharedInformerFactory.Apiextensions().V1().CustomResourceDefinitions(),
@@ -54,6 +54,17 @@ func main() {
 
 					Has an optional Envoy XDS control plane that programs Envoy based on
 					ingresses in kcp.
+
+					Publishes the LeavesSynced, EnvoyConfigured and DNSProgrammed conditions
+					of root ingresses as JSON in their ingress.kcp.dev/conditions annotation.
+
+					With Envoy, root ingresses whose rules are not all in --domain get a host
+					generated from --domain-template, or from their ingress.kcp.dev/host-template
+					annotation, in their load balancer status. The templates can use
+					{{.workspace}}, {{.workspacePath}}, {{.namespace}}, {{.name}}, {{.hash}} and
+					{{.domain}}, e.g. {{.workspace}}.{{.namespace}}.{{.domain}}. Hosts outside
+					--domain and hosts already assigned to another ingress are rejected, which is
+					reported in the HostAssigned condition.
 				`),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx := genericapiserver.SetupSignalContext()
@@ -86,13 +97,18 @@ func main() {
 			ingressInformer := kubeInformerFactory.Networking().V1().Ingresses()
 			serviceInformer := kubeInformerFactory.Core().V1().Services()
 
+			hostTemplate, err := ingress.ParseHostTemplate(options.DomainTemplate)
+			if err != nil {
+				return fmt.Errorf("invalid --domain-template: %w", err)
+			}
+
 			var ecp *envoycontrolplane.EnvoyControlPlane
 			aggregateLeavesStatus := true
 			if options.EnvoyXDSPort > 0 && options.EnvoyListenerPort > 0 {
 				aggregateLeavesStatus = false
 
 				ecp = envoycontrolplane.NewEnvoyControlPlane(options.EnvoyXDSPort, options.EnvoyListenerPort, ingressInformer.Lister(), nil)
-				isr := ingress.NewController(kubeClient, ingressInformer, ecp, options.Domain)
+				isr := ingress.NewController(kubeClient, ingressInformer, ecp, options.Domain, hostTemplate)
 				go isr.Start(ctx, numThreads)
 				if err := ecp.Start(ctx); err != nil {
 					return err
@@ -127,6 +143,7 @@ type Options struct {
 	EnvoyXDSPort      uint
 	EnvoyListenerPort uint
 	Domain            string
+	DomainTemplate    string
 	Logs              *logs.Options
 }
 
@@ -141,6 +158,7 @@ func NewDefaultOptions() *Options {
 		EnvoyXDSPort:      18000,
 		EnvoyListenerPort: 80,
 		Domain:            "kcp-apps.127.0.0.1.nip.io",
+		DomainTemplate:    ingress.DefaultHostTemplate,
 		Logs:              logs,
 	}
 }
@@ -151,6 +169,7 @@ func (o *Options) BindFlags(fs *pflag.FlagSet) {
 	fs.UintVar(&o.EnvoyXDSPort, "envoy-xds-port", o.EnvoyXDSPort, "Envoy control plane port. Set to 0 to disable")
 	fs.UintVar(&o.EnvoyListenerPort, "envoy-listener-port", o.EnvoyListenerPort, "Envoy listener port")
 	fs.StringVar(&o.Domain, "domain", o.Domain, "The domain to use to expose ingresses")
+	fs.StringVar(&o.DomainTemplate, "domain-template", o.DomainTemplate, "Go template of the hosts generated for ingresses, in --domain. See above for the available keys.")
 
 	o.Logs.AddFlags(fs)
 }
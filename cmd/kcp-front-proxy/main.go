@@ -27,6 +27,7 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
+	"go.opentelemetry.io/otel"
 
 	"k8s.io/apimachinery/pkg/util/errors"
 	genericapifilters "k8s.io/apiserver/pkg/endpoints/filters"
@@ -39,6 +40,7 @@ import (
 
 	frontproxyoptions "github.com/kcp-dev/kcp/cmd/kcp-front-proxy/options"
 	"github.com/kcp-dev/kcp/pkg/proxy"
+	"github.com/kcp-dev/kcp/pkg/tracing"
 )
 
 func main() {
@@ -99,6 +101,16 @@ routed based on paths.`,
 			failedHandler := newUnauthorizedHandler()
 			handler = withOptionalClientCert(handler, failedHandler, authenticationInfo.Authenticator)
 
+			if options.Proxy.TracingConfigFile != "" {
+				tp, err := tracing.NewProvider(ctx, options.Proxy.TracingConfigFile, "kcp-front-proxy")
+				if err != nil {
+					return err
+				}
+				otel.SetTracerProvider(tp)
+				handler = proxy.WithClusterTracing(handler)
+				handler = genericapifilters.WithTracing(handler, &tp)
+			}
+
 			requestInfoFactory := newRequestInfoFactory()
 			handler = genericapifilters.WithRequestInfo(handler, requestInfoFactory)
 			handler = genericfilters.WithPanicRecovery(handler, requestInfoFactory)
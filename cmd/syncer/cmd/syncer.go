@@ -18,17 +18,38 @@ package cmd
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"text/tabwriter"
 
 	"github.com/kcp-dev/logicalcluster"
 	"github.com/spf13/cobra"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/trace"
 
 	"k8s.io/apimachinery/pkg/util/sets"
 	genericapiserver "k8s.io/apiserver/pkg/server"
+	"k8s.io/apiserver/pkg/server/healthz"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	"k8s.io/component-base/metrics/legacyregistry"
+	_ "k8s.io/component-base/metrics/prometheus/clientgo" // for client-go and workqueue metrics
 	"k8s.io/klog/v2"
 
 	synceroptions "github.com/kcp-dev/kcp/cmd/syncer/options"
+	"github.com/kcp-dev/kcp/pkg/secretstore"
 	"github.com/kcp-dev/kcp/pkg/syncer"
+	"github.com/kcp-dev/kcp/pkg/syncer/credentials"
+	"github.com/kcp-dev/kcp/pkg/syncer/endpointslices"
+	"github.com/kcp-dev/kcp/pkg/syncer/pruning"
+	"github.com/kcp-dev/kcp/pkg/syncer/shared"
+	"github.com/kcp-dev/kcp/pkg/syncer/spec"
+	specmutators "github.com/kcp-dev/kcp/pkg/syncer/spec/mutators"
+	"github.com/kcp-dev/kcp/pkg/tracing"
 )
 
 const numThreads = 2
@@ -48,6 +69,9 @@ func NewSyncerCommand() *cobra.Command {
 			}
 
 			ctx := genericapiserver.SetupSignalContext()
+			if options.APIImportDryRun {
+				return RunAPIImportDryRun(options, ctx, cmd.OutOrStdout())
+			}
 			if err := Run(options, ctx); err != nil {
 				return err
 			}
@@ -66,39 +90,193 @@ func NewSyncerCommand() *cobra.Command {
 func Run(options *synceroptions.Options, ctx context.Context) error {
 	klog.Infof("Syncing the following resource types: %s", options.SyncedResourceTypes)
 
-	kcpConfigOverrides := &clientcmd.ConfigOverrides{
-		CurrentContext: options.FromContext,
+	secretStore, err := options.SecretStore.NewStore()
+	if err != nil {
+		return err
+	}
+	kcpConfig, toConfig, err := clientConfigs(ctx, options, secretStore)
+	if err != nil {
+		return err
+	}
+
+	var tracerProvider trace.TracerProvider
+	if options.TracingConfigFile != "" {
+		tracerProvider, err = tracing.NewProvider(ctx, options.TracingConfigFile, "kcp-syncer")
+		if err != nil {
+			return err
+		}
+		otel.SetTracerProvider(tracerProvider)
+	}
+
+	cfg := &syncer.SyncerConfig{
+		UpstreamConfig:      kcpConfig,
+		DownstreamConfig:    toConfig,
+		ResourcesToSync:     sets.NewString(options.SyncedResourceTypes...),
+		KCPClusterName:      logicalcluster.New(options.FromClusterName),
+		WorkloadClusterName: options.PclusterID,
+
+		OrphanPruningMode:     pruning.Mode(options.OrphanPruningMode),
+		OrphanPruningInterval: options.OrphanPruningInterval,
+
+		CapacityReportInterval: options.CapacityReportInterval,
+		DriftDetectionInterval: options.DriftDetectionInterval,
+
+		FieldPruningPolicy: spec.FieldPruningPolicy{
+			PruneStatus:       options.DownstreamPruneStatus,
+			PrunedAnnotations: sets.NewString(options.DownstreamPrunedAnnotations...),
+			MaxAnnotationSize: options.DownstreamMaxAnnotationSize,
+			MaxObjectSize:     options.DownstreamMaxObjectSize,
+		},
+		RecordDownstreamMutations: options.RecordDownstreamMutations,
+		ClusterScopedPolicy: shared.ClusterScopedPolicy{
+			GroupResources: sets.NewString(options.ClusterScopedResourceTypes...),
+			NamePrefix:     options.ClusterScopedNamePrefix,
+		},
+		ServiceDNSPolicy: specmutators.ServiceDNSPolicy{
+			Enabled:       options.ServiceDNS,
+			ClusterDomain: options.ClusterDomain,
+		},
+		MirrorEndpointSlices: options.MirrorEndpointSlices,
+		TracerProvider:       tracerProvider,
+	}
+	if options.FromKubeconfigSecret != "" {
+		namespace, name, _ := cache.SplitMetaNamespaceKey(options.FromKubeconfigSecret)
+		cfg.UpstreamTokenRotation = credentials.RotationPolicy{
+			SecretNamespace: namespace,
+			SecretName:      name,
+			SecretKey:       options.FromKubeconfigSecretKey,
+			Context:         options.FromContext,
+			TokenLifetime:   options.FromTokenLifetime,
+		}
+	}
+	if options.FromKubeconfigSecretStoreKey != "" {
+		cfg.UpstreamTokenRotation = credentials.RotationPolicy{
+			Store:         secretStore,
+			StoreKey:      options.FromKubeconfigSecretStoreKey,
+			SecretKey:     options.FromKubeconfigSecretKey,
+			Context:       options.FromContext,
+			TokenLifetime: options.FromTokenLifetime,
+		}
+	}
+
+	if options.MetricsBindAddress != "" {
+		// serve metrics and health checks while the syncer starts, such that readiness reflects the start.
+		go serveMetricsAndHealth(ctx, options.MetricsBindAddress, syncer.HeartbeatCheck(cfg.ID()))
+	}
+
+	if err := syncer.StartSyncer(ctx, cfg, numThreads, options.APIImportPollInterval); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// RunAPIImportDryRun prints which APIs of the physical cluster would be imported into and negotiated in
+// kcp, and which conflict with the APIs negotiated there, without making changes.
+func RunAPIImportDryRun(options *synceroptions.Options, ctx context.Context, out io.Writer) error {
+	secretStore, err := options.SecretStore.NewStore()
+	if err != nil {
+		return err
+	}
+	kcpConfig, toConfig, err := clientConfigs(ctx, options, secretStore)
+	if err != nil {
+		return err
+	}
+
+	resources := sets.NewString(options.SyncedResourceTypes...).Insert(options.ClusterScopedResourceTypes...)
+	if options.MirrorEndpointSlices {
+		resources.Insert(endpointslices.Resource.GroupResource().String())
 	}
-	kcpConfig, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
-		&clientcmd.ClientConfigLoadingRules{ExplicitPath: options.FromKubeconfig},
-		kcpConfigOverrides).ClientConfig()
+	plan, err := syncer.PlanAPIImport(ctx, kcpConfig, toConfig, resources.List(), logicalcluster.New(options.FromClusterName), options.PclusterID)
 	if err != nil {
 		return err
 	}
 
-	toConfig, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+	w := tabwriter.NewWriter(out, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(w, "RESOURCE\tVERSION\tIMPORT\tNEGOTIATION\tCONFLICT")
+	for _, entry := range plan {
+		resource := entry.GVR.Resource
+		if entry.GVR.Group != "" {
+			resource += "." + entry.GVR.Group
+		}
+		negotiation := "New"
+		switch {
+		case entry.Conflict != "":
+			negotiation = "Conflict"
+		case entry.Negotiated:
+			negotiation = "Compatible"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", resource, entry.GVR.Version, entry.Action, negotiation, entry.Conflict)
+	}
+	return w.Flush()
+}
+
+func clientConfigs(ctx context.Context, options *synceroptions.Options, secretStore secretstore.Store) (kcpConfig, toConfig *rest.Config, err error) {
+	kcpConfigOverrides := &clientcmd.ConfigOverrides{
+		CurrentContext: options.FromContext,
+	}
+	if options.FromKubeconfigSecretStoreKey != "" {
+		data, err := secretStore.Get(ctx, options.FromKubeconfigSecretStoreKey)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read the kubeconfig from secret store key %q: %w", options.FromKubeconfigSecretStoreKey, err)
+		}
+		raw, err := clientcmd.Load(data[options.FromKubeconfigSecretKey])
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid kubeconfig in secret store key %q: %w", options.FromKubeconfigSecretStoreKey, err)
+		}
+		kcpConfig, err = clientcmd.NewNonInteractiveClientConfig(*raw, options.FromContext, kcpConfigOverrides, nil).ClientConfig()
+		if err != nil {
+			return nil, nil, err
+		}
+		// pick up rotated tokens without restart
+		kcpConfig = credentials.WithReloadedStoreToken(kcpConfig, secretStore, options.FromKubeconfigSecretStoreKey, options.FromKubeconfigSecretKey, options.FromContext)
+	} else {
+		kcpConfig, err = clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+			&clientcmd.ClientConfigLoadingRules{ExplicitPath: options.FromKubeconfig},
+			kcpConfigOverrides).ClientConfig()
+		if err != nil {
+			return nil, nil, err
+		}
+		// pick up rotated tokens without restart
+		kcpConfig = credentials.WithReloadedToken(kcpConfig, options.FromKubeconfig, options.FromContext)
+	}
+
+	toConfig, err = clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
 		&clientcmd.ClientConfigLoadingRules{ExplicitPath: options.ToKubeconfig},
 		&clientcmd.ConfigOverrides{
 			CurrentContext: options.ToContext,
+			ClusterInfo: clientcmdapi.Cluster{
+				Server:               options.ToServer,
+				CertificateAuthority: options.ToCertificateAuthority,
+			},
 		}).ClientConfig()
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
+	// an exec credential plugin replaces the token, which otherwise is reloaded like the kcp token
+	toConfig = credentials.WithDownstreamAuth(toConfig, options.DownstreamAuth())
+	toConfig = credentials.WithReloadedToken(toConfig, options.ToKubeconfig, options.ToContext)
 
-	if err := syncer.StartSyncer(
-		ctx,
-		&syncer.SyncerConfig{
-			UpstreamConfig:      kcpConfig,
-			DownstreamConfig:    toConfig,
-			ResourcesToSync:     sets.NewString(options.SyncedResourceTypes...),
-			KCPClusterName:      logicalcluster.New(options.FromClusterName),
-			WorkloadClusterName: options.PclusterID,
-		},
-		numThreads,
-		options.APIImportPollInterval,
-	); err != nil {
-		return err
-	}
+	return kcpConfig, toConfig, nil
+}
 
-	return nil
+// serveMetricsAndHealth serves /metrics, /healthz, /livez and /readyz on the given address until the
+// context is done. The readiness checks are only part of /readyz.
+func serveMetricsAndHealth(ctx context.Context, address string, readinessChecks ...healthz.HealthChecker) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", legacyregistry.Handler())
+	healthz.InstallHandler(mux)
+	healthz.InstallLivezHandler(mux)
+	healthz.InstallReadyzHandler(mux, readinessChecks...)
+
+	server := &http.Server{Addr: address, Handler: mux}
+	go func() {
+		<-ctx.Done()
+		_ = server.Close()
+	}()
+
+	klog.Infof("Serving metrics and health checks on %s", address)
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		klog.Errorf("Failed to serve metrics and health checks: %v", err)
+	}
 }
@@ -19,14 +19,27 @@ package options
 import (
 	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/spf13/pflag"
 
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/tools/cache"
 	"k8s.io/component-base/config"
 	"k8s.io/component-base/logs"
 
 	workloadv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/workload/v1alpha1"
+	kcpfeatures "github.com/kcp-dev/kcp/pkg/features"
+	secretstoreoptions "github.com/kcp-dev/kcp/pkg/secretstore/options"
+	"github.com/kcp-dev/kcp/pkg/syncer/credentials"
+	"github.com/kcp-dev/kcp/pkg/syncer/endpointslices"
+	"github.com/kcp-dev/kcp/pkg/syncer/pruning"
+	"github.com/kcp-dev/kcp/pkg/syncer/shared"
+	"github.com/kcp-dev/kcp/pkg/syncer/spec"
+	specmutators "github.com/kcp-dev/kcp/pkg/syncer/spec/mutators"
 )
 
 type Options struct {
@@ -40,6 +53,44 @@ type Options struct {
 	SyncedResourceTypes []string
 
 	APIImportPollInterval time.Duration
+	APIImportDryRun       bool
+	OrphanPruningMode     string
+	OrphanPruningInterval time.Duration
+	TracingConfigFile     string
+	MetricsBindAddress    string
+
+	CapacityReportInterval time.Duration
+	DriftDetectionInterval time.Duration
+
+	DownstreamPruneStatus       bool
+	DownstreamPrunedAnnotations []string
+	DownstreamMaxAnnotationSize int
+	DownstreamMaxObjectSize     int
+	RecordDownstreamMutations   bool
+
+	ClusterScopedResourceTypes []string
+	ClusterScopedNamePrefix    string
+
+	ServiceDNS    bool
+	ClusterDomain string
+
+	MirrorEndpointSlices bool
+
+	FromKubeconfigSecret    string
+	FromKubeconfigSecretKey string
+	FromTokenLifetime       time.Duration
+
+	SecretStore                  *secretstoreoptions.Options
+	FromKubeconfigSecretStoreKey string
+
+	ToServer               string
+	ToCertificateAuthority string
+	ToAuthProvider         string
+	ToExecCommand          string
+	ToExecArgs             []string
+	ToExecEnv              []string
+	ToEKSClusterName       string
+	ToEKSRegion            string
 }
 
 func NewOptions() *Options {
@@ -51,6 +102,28 @@ func NewOptions() *Options {
 		SyncedResourceTypes:   []string{},
 		Logs:                  logs,
 		APIImportPollInterval: 1 * time.Minute,
+		OrphanPruningMode:     string(pruning.ModeDryRun),
+		OrphanPruningInterval: 10 * time.Minute,
+		MetricsBindAddress:    ":8080",
+
+		CapacityReportInterval: 1 * time.Minute,
+		DriftDetectionInterval: 5 * time.Minute,
+
+		DownstreamPrunedAnnotations: []string{},
+		DownstreamMaxObjectSize:     spec.DefaultMaxObjectSize,
+
+		ClusterScopedResourceTypes: []string{},
+
+		ClusterDomain: specmutators.DefaultClusterDomain,
+
+		FromKubeconfigSecretKey: credentials.DefaultSecretKey,
+		FromTokenLifetime:       credentials.DefaultTokenLifetime,
+
+		SecretStore: secretstoreoptions.NewOptions(),
+
+		ToAuthProvider: string(credentials.DownstreamAuthKubeconfig),
+		ToExecArgs:     []string{},
+		ToExecEnv:      []string{},
 	}
 }
 
@@ -58,13 +131,48 @@ func (options *Options) AddFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&options.FromKubeconfig, "from-kubeconfig", options.FromKubeconfig, "Kubeconfig file for -from cluster.")
 	fs.StringVar(&options.FromContext, "from-context", options.FromContext, "Context to use in the Kubeconfig file for -from cluster, instead of the current context.")
 	fs.StringVar(&options.FromClusterName, "from-cluster", options.FromClusterName, "Name of the -from logical cluster.")
+	fs.StringVar(&options.FromKubeconfigSecret, "from-kubeconfig-secret", options.FromKubeconfigSecret, "Namespace/name of the secret on the -to cluster holding the kubeconfig for the -from cluster. If set, the service account token in it is rotated before it expires.")
+	fs.StringVar(&options.FromKubeconfigSecretKey, "from-kubeconfig-secret-key", options.FromKubeconfigSecretKey, "Key of the kubeconfig in the --from-kubeconfig-secret secret.")
+	fs.StringVar(&options.FromKubeconfigSecretStoreKey, "from-kubeconfig-secret-store-key", options.FromKubeconfigSecretStoreKey, "Key in the --secret-store holding the kubeconfig for the -from cluster under --from-kubeconfig-secret-key, instead of --from-kubeconfig. The service account token in it is rotated before it expires.")
+	fs.DurationVar(&options.FromTokenLifetime, "from-token-lifetime", options.FromTokenLifetime, "Lifetime of the tokens requested when rotating the token in --from-kubeconfig-secret or --from-kubeconfig-secret-store-key. Tokens are rotated when less than a third of their lifetime is left.")
 	fs.StringVar(&options.ToKubeconfig, "to-kubeconfig", options.ToKubeconfig, "Kubeconfig file for -to cluster. If not set, the InCluster configuration will be used.")
 	fs.StringVar(&options.ToContext, "to-context", options.ToContext, "Context to use in the Kubeconfig file for -to cluster, instead of the current context.")
+	fs.StringVar(&options.ToServer, "to-server", options.ToServer, "Address of the API server of the -to cluster, instead of the server in the -to kubeconfig.")
+	fs.StringVar(&options.ToCertificateAuthority, "to-certificate-authority", options.ToCertificateAuthority, "CA bundle file of the API server of the -to cluster, instead of the CA in the -to kubeconfig.")
+	fs.StringVar(&options.ToAuthProvider, "to-auth-provider", options.ToAuthProvider,
+		fmt.Sprintf("How to authenticate against the -to cluster. One of %s. %q uses the credentials of the -to kubeconfig, the others replace them by an exec credential plugin.", strings.Join(credentials.DownstreamAuthProviders.List(), ", "), credentials.DownstreamAuthKubeconfig))
+	fs.StringVar(&options.ToExecCommand, "to-exec-command", options.ToExecCommand, "Command of the exec credential plugin of the \"exec\" --to-auth-provider.")
+	fs.StringArrayVar(&options.ToExecArgs, "to-exec-arg", options.ToExecArgs, "Argument of the exec credential plugin of the \"exec\" --to-auth-provider. Can be repeated.")
+	fs.StringArrayVar(&options.ToExecEnv, "to-exec-env", options.ToExecEnv, "NAME=VALUE environment variable of the exec credential plugin. Can be repeated.")
+	fs.StringVar(&options.ToEKSClusterName, "to-eks-cluster-name", options.ToEKSClusterName, "Name of the EKS cluster of the \"eks\" --to-auth-provider.")
+	fs.StringVar(&options.ToEKSRegion, "to-eks-region", options.ToEKSRegion, "AWS region of the EKS cluster of the \"eks\" --to-auth-provider. Defaults to the region of the AWS CLI configuration.")
 	fs.StringVar(&options.PclusterID, "workload-cluster-name", options.PclusterID,
 		fmt.Sprintf("ID of the -to cluster. Resources with this ID set in the '%s' label will be synced.", workloadv1alpha1.InternalClusterResourceStateLabelPrefix+"<ClusterID>"))
 	fs.StringArrayVarP(&options.SyncedResourceTypes, "resources", "r", options.SyncedResourceTypes, "Resources to be synchronized in kcp.")
 	fs.DurationVar(&options.APIImportPollInterval, "api-import-poll-interval", options.APIImportPollInterval, "Polling interval for API import.")
+	fs.BoolVar(&options.APIImportDryRun, "api-import-dry-run", options.APIImportDryRun, "Print which APIs of the -to cluster would be imported into and negotiated in the -from cluster, and which conflict with the APIs negotiated there, and exit without making changes or syncing.")
+	fs.StringVar(&options.OrphanPruningMode, "orphan-pruning-mode", options.OrphanPruningMode,
+		fmt.Sprintf("What to do with downstream objects whose upstream object is gone. One of %s. %q only reports them in logs and metrics.", strings.Join(pruning.Modes.List(), ", "), pruning.ModeDryRun))
+	fs.DurationVar(&options.OrphanPruningInterval, "orphan-pruning-interval", options.OrphanPruningInterval, "Interval between two passes looking for orphaned downstream objects.")
+	fs.DurationVar(&options.CapacityReportInterval, "capacity-report-interval", options.CapacityReportInterval, "Interval between two reports of the capacity, allocatable resources and node counts of the -to cluster in the WorkloadCluster status. 0 disables reporting.")
+	fs.DurationVar(&options.DriftDetectionInterval, "drift-detection-interval", options.DriftDetectionInterval, "Interval between two passes looking for downstream objects changed out-of-band since they were synced, which are then synced again. 0 disables drift detection.")
+	fs.BoolVar(&options.DownstreamPruneStatus, "downstream-prune-status", options.DownstreamPruneStatus, "Do not sync the status of objects downstream, even for resources without status subresource.")
+	fs.StringSliceVar(&options.DownstreamPrunedAnnotations, "downstream-pruned-annotations", options.DownstreamPrunedAnnotations, "Annotations which are not synced downstream, e.g. kubectl.kubernetes.io/last-applied-configuration.")
+	fs.IntVar(&options.DownstreamMaxAnnotationSize, "downstream-max-annotation-size", options.DownstreamMaxAnnotationSize, "Maximal size in bytes of annotation values synced downstream. Longer annotations are dropped. 0 means no limit.")
+	fs.IntVar(&options.DownstreamMaxObjectSize, "downstream-max-object-size", options.DownstreamMaxObjectSize, "Maximal size in bytes of objects synced downstream. Larger objects are not synced, and reported in the experimental.sync-condition.workloads.kcp.dev/<workload-cluster-name> annotation upstream. 0 means no limit.")
+	fs.BoolVar(&options.RecordDownstreamMutations, "record-downstream-mutations", options.RecordDownstreamMutations, "Summarize the fields added, removed or changed by the syncer in objects synced downstream, e.g. pruned annotations, injected labels or rewritten namespaces, in the experimental.mutations.workloads.kcp.dev/<workload-cluster-name> annotation upstream.")
+	fs.StringSliceVar(&options.ClusterScopedResourceTypes, "cluster-scoped-resources", options.ClusterScopedResourceTypes, "Cluster-scoped resources to be synchronized in kcp, as <resource>.<group>, e.g. priorityclasses.scheduling.k8s.io. Downstream objects not created by the syncer for the -from logical cluster are never updated or deleted.")
+	fs.StringVar(&options.ClusterScopedNamePrefix, "cluster-scoped-name-prefix", options.ClusterScopedNamePrefix, "Prefix of the names of cluster-scoped objects synced downstream. References to them are not rewritten.")
+	fs.BoolVar(&options.ServiceDNS, "service-dns", options.ServiceDNS, "Add host aliases for the services of all namespaces of the -from logical cluster to synced pods and deployments, such that they resolve by their upstream names, also when placed on other physical clusters.")
+	fs.StringVar(&options.ClusterDomain, "cluster-domain", options.ClusterDomain, "DNS domain of the services of the -to cluster, used in the host aliases of --service-dns.")
+	fs.BoolVar(&options.MirrorEndpointSlices, "mirror-endpointslices", options.MirrorEndpointSlices, "Mirror the EndpointSlices of the services synced to the -to cluster into their namespaces in the -from logical cluster, importing the endpointslices.discovery.k8s.io API into kcp.")
+	fs.StringVar(&options.TracingConfigFile, "tracing-config-file", options.TracingConfigFile, "File with apiserver tracing configuration. The syncer traces the objects it syncs and propagates the trace context to kcp and the physical cluster.")
+	fs.StringVar(&options.MetricsBindAddress, "metrics-bind-address", options.MetricsBindAddress, "Address to serve /metrics, /healthz, /livez and /readyz on. Empty disables serving them.")
+	fs.Var(kcpfeatures.NewFlagValue(), "feature-gates", ""+
+		"A set of key=value pairs that describe feature gates for alpha/experimental features. "+
+		"Options are:\n"+strings.Join(kcpfeatures.KnownFeatures(), "\n"))
 
+	options.SecretStore.AddFlags(fs)
 	options.Logs.AddFlags(fs)
 }
 
@@ -76,9 +184,89 @@ func (options *Options) Validate() error {
 	if options.FromClusterName == "" {
 		return errors.New("--from-cluster is required")
 	}
-	if options.FromKubeconfig == "" {
+	if errs := options.SecretStore.Validate(); len(errs) > 0 {
+		return utilerrors.NewAggregate(errs)
+	}
+	if options.FromKubeconfigSecretStoreKey != "" {
+		if options.SecretStore.Provider == secretstoreoptions.ProviderEtcd {
+			return errors.New("--from-kubeconfig-secret-store-key requires --secret-store")
+		}
+		if options.FromKubeconfig != "" || options.FromKubeconfigSecret != "" {
+			return errors.New("--from-kubeconfig-secret-store-key is mutually exclusive with --from-kubeconfig and --from-kubeconfig-secret")
+		}
+		if options.FromKubeconfigSecretKey == "" {
+			return errors.New("--from-kubeconfig-secret-key is required")
+		}
+		if options.FromTokenLifetime < credentials.MinTokenLifetime {
+			return fmt.Errorf("--from-token-lifetime must be at least %s", credentials.MinTokenLifetime)
+		}
+	} else if options.FromKubeconfig == "" {
 		return errors.New("--from-kubeconfig is required")
 	}
+	if options.FromKubeconfigSecret != "" {
+		if namespace, name, err := cache.SplitMetaNamespaceKey(options.FromKubeconfigSecret); err != nil || namespace == "" || name == "" {
+			return errors.New("--from-kubeconfig-secret must be of the form <namespace>/<name>")
+		}
+		if options.FromKubeconfigSecretKey == "" {
+			return errors.New("--from-kubeconfig-secret-key is required")
+		}
+		if options.FromTokenLifetime < credentials.MinTokenLifetime {
+			return fmt.Errorf("--from-token-lifetime must be at least %s", credentials.MinTokenLifetime)
+		}
+	}
+	if err := options.DownstreamAuth().Validate(); err != nil {
+		return fmt.Errorf("--to-auth-provider: %w", err)
+	}
+	if !pruning.Modes.Has(options.OrphanPruningMode) {
+		return fmt.Errorf("--orphan-pruning-mode must be one of %s", strings.Join(pruning.Modes.List(), ", "))
+	}
+	if options.OrphanPruningInterval <= 0 {
+		return errors.New("--orphan-pruning-interval must be positive")
+	}
+	if options.CapacityReportInterval < 0 {
+		return errors.New("--capacity-report-interval must not be negative")
+	}
+	if options.DriftDetectionInterval < 0 {
+		return errors.New("--drift-detection-interval must not be negative")
+	}
+	if options.DownstreamMaxAnnotationSize < 0 {
+		return errors.New("--downstream-max-annotation-size must not be negative")
+	}
+	if options.DownstreamMaxObjectSize < 0 {
+		return errors.New("--downstream-max-object-size must not be negative")
+	}
+	for _, r := range options.ClusterScopedResourceTypes {
+		if gr := schema.ParseGroupResource(r); gr.Group == "" && gr.Resource == "namespaces" {
+			return errors.New("--cluster-scoped-resources must not contain namespaces")
+		}
+	}
+	if err := (shared.ClusterScopedPolicy{NamePrefix: options.ClusterScopedNamePrefix}).Validate(); err != nil {
+		return fmt.Errorf("--cluster-scoped-name-prefix: %w", err)
+	}
+	if options.ServiceDNS {
+		if errs := validation.IsDNS1123Subdomain(options.ClusterDomain); len(errs) > 0 {
+			return fmt.Errorf("--cluster-domain must be a valid DNS domain: %s", strings.Join(errs, ", "))
+		}
+	}
+	if options.MirrorEndpointSlices {
+		for _, r := range options.SyncedResourceTypes {
+			if gr := schema.ParseGroupResource(r); gr == endpointslices.Resource.GroupResource() || r == endpointslices.Resource.Resource {
+				return errors.New("--resources must not contain endpointslices with --mirror-endpointslices")
+			}
+		}
+	}
 
 	return nil
 }
+
+// DownstreamAuth returns how to authenticate against the -to cluster.
+func (options *Options) DownstreamAuth() credentials.DownstreamAuth {
+	return credentials.DownstreamAuth{
+		Provider:    credentials.DownstreamAuthProvider(options.ToAuthProvider),
+		ExecCommand: options.ToExecCommand,
+		ExecArgs:    options.ToExecArgs,
+		ExecEnv:     options.ToExecEnv,
+		ClusterName: options.ToEKSClusterName,
+		Region:      options.ToEKSRegion,
+	}
+}
@@ -20,10 +20,14 @@ import (
 	"bufio"
 	"bytes"
 	"context"
+	"crypto/sha256"
 	"embed"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
+	"os"
+	"text/template"
 	"time"
 
 	extensionsapiserver "k8s.io/apiextensions-apiserver/pkg/apiserver"
@@ -48,19 +52,40 @@ type TransformFileFunc func(bs []byte) ([]byte, error)
 type Option struct {
 	// TransformFileFunc is a function that transforms a resource file before being applied to the cluster.
 	TransformFile TransformFileFunc
+
+	// Force updates existing resources even if their content hash did not change.
+	Force bool
+}
+
+// ForceOption makes the bootstrap process update existing resources even if the
+// content hash stored on them did not change, i.e. it overwrites manual changes.
+func ForceOption(force bool) Option {
+	return Option{Force: force}
 }
 
-// ReplaceOption allows to customize the bootstrap process.
-func ReplaceOption(pairs ...string) Option {
+// TemplateOption renders every resource file as Go template with the given variables, e.g.
+// {{ .ShardName }}, and the env function returning an environment variable, e.g.
+// {{ env "KCP_EXTERNAL_URL" }}. Missing variables and unset environment variables are errors.
+func TemplateOption(vars map[string]string) Option {
 	return Option{
 		TransformFile: func(bs []byte) ([]byte, error) {
-			if len(pairs)%2 != 0 {
-				return nil, fmt.Errorf("odd number of arguments: %v", pairs)
+			tmpl, err := template.New("").Option("missingkey=error").Funcs(template.FuncMap{
+				"env": func(name string) (string, error) {
+					value, ok := os.LookupEnv(name)
+					if !ok {
+						return "", fmt.Errorf("environment variable %q is not set", name)
+					}
+					return value, nil
+				},
+			}).Parse(string(bs))
+			if err != nil {
+				return nil, err
 			}
-			for i := 0; i < len(pairs); i += 2 {
-				bs = bytes.ReplaceAll(bs, []byte(pairs[i]), []byte(pairs[i+1]))
+			var buf bytes.Buffer
+			if err := tmpl.Execute(&buf, vars); err != nil {
+				return nil, err
 			}
-			return bs, nil
+			return buf.Bytes(), nil
 		},
 	}
 }
@@ -74,11 +99,21 @@ func Bootstrap(ctx context.Context, discoveryClient discovery.DiscoveryInterface
 
 	// bootstrap non-crd resources
 	var transformers []TransformFileFunc
+	force := false
 	for _, opt := range opts {
-		transformers = append(transformers, opt.TransformFile)
+		if opt.TransformFile != nil {
+			transformers = append(transformers, opt.TransformFile)
+		}
+		force = force || opt.Force
+	}
+
+	// errors of the transformers, e.g. missing template variables, are not worth retrying
+	if err := transformFS(fs, transformers...); err != nil {
+		return err
 	}
+
 	return wait.PollImmediateInfiniteWithContext(ctx, time.Second, func(ctx context.Context) (bool, error) {
-		if err := CreateResourcesFromFS(ctx, dynamicClient, mapper, fs, transformers...); err != nil {
+		if err := createResourcesFromFS(ctx, dynamicClient, mapper, fs, force, transformers...); err != nil {
 			klog.Infof("Failed to bootstrap resources, retrying: %v", err)
 			// invalidate cache if resources not found
 			// xref: https://github.com/kcp-dev/kcp/issues/655
@@ -91,6 +126,10 @@ func Bootstrap(ctx context.Context, discoveryClient discovery.DiscoveryInterface
 
 // CreateResourcesFromFS creates all resources from a filesystem.
 func CreateResourcesFromFS(ctx context.Context, client dynamic.Interface, mapper meta.RESTMapper, fs embed.FS, transformers ...TransformFileFunc) error {
+	return createResourcesFromFS(ctx, client, mapper, fs, false, transformers...)
+}
+
+func createResourcesFromFS(ctx context.Context, client dynamic.Interface, mapper meta.RESTMapper, fs embed.FS, force bool, transformers ...TransformFileFunc) error {
 	files, err := fs.ReadDir(".")
 	if err != nil {
 		return err
@@ -101,7 +140,7 @@ func CreateResourcesFromFS(ctx context.Context, client dynamic.Interface, mapper
 		if f.IsDir() {
 			continue
 		}
-		if err := CreateResourceFromFS(ctx, client, mapper, f.Name(), fs, transformers...); err != nil {
+		if err := createResourceFromFS(ctx, client, mapper, f.Name(), fs, force, transformers...); err != nil {
 			errs = append(errs, err)
 		}
 	}
@@ -110,45 +149,97 @@ func CreateResourcesFromFS(ctx context.Context, client dynamic.Interface, mapper
 
 // CreateResourceFromFS creates given resource file.
 func CreateResourceFromFS(ctx context.Context, client dynamic.Interface, mapper meta.RESTMapper, filename string, fs embed.FS, transformers ...TransformFileFunc) error {
+	return createResourceFromFS(ctx, client, mapper, filename, fs, false, transformers...)
+}
+
+func createResourceFromFS(ctx context.Context, client dynamic.Interface, mapper meta.RESTMapper, filename string, fs embed.FS, force bool, transformers ...TransformFileFunc) error {
+	docs, err := readDocumentsFromFS(filename, fs, transformers...)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for i, doc := range docs {
+		if doc == nil {
+			continue
+		}
+		if err := upsertResource(ctx, client, mapper, doc, force); err != nil {
+			errs = append(errs, fmt.Errorf("failed to create resource %s doc %d: %w", filename, i+1, err))
+		}
+	}
+	return apimachineryerrors.NewAggregate(errs)
+}
+
+// transformFS applies the transformers to all documents of all files in the filesystem.
+func transformFS(fs embed.FS, transformers ...TransformFileFunc) error {
+	files, err := fs.ReadDir(".")
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		if f.IsDir() {
+			continue
+		}
+		if _, err := readDocumentsFromFS(f.Name(), fs, transformers...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readDocumentsFromFS returns the transformed YAML documents of the given file. Empty documents are
+// returned as nil to keep the document numbers.
+func readDocumentsFromFS(filename string, fs embed.FS, transformers ...TransformFileFunc) ([][]byte, error) {
 	raw, err := fs.ReadFile(filename)
 	if err != nil {
-		return fmt.Errorf("could not read %s: %w", filename, err)
+		return nil, fmt.Errorf("could not read %s: %w", filename, err)
 	}
 
 	if len(raw) == 0 {
-		return nil // ignore empty files
+		return nil, nil // ignore empty files
 	}
 
 	d := kubeyaml.NewYAMLReader(bufio.NewReader(bytes.NewReader(raw)))
-	var errs []error
+	var docs [][]byte
 	for i := 1; ; i++ {
 		doc, err := d.Read()
 		if errors.Is(err, io.EOF) {
 			break
 		} else if err != nil {
-			return err
+			return nil, err
 		}
 		if len(bytes.TrimSpace(doc)) == 0 {
+			docs = append(docs, nil)
 			continue
 		}
 
 		for _, transformer := range transformers {
 			doc, err = transformer(doc)
 			if err != nil {
-				return err
+				return nil, fmt.Errorf("failed to transform %s doc %d: %w", filename, i, err)
 			}
 		}
-
-		if err := createResourceFromFS(ctx, client, mapper, doc); err != nil {
-			errs = append(errs, fmt.Errorf("failed to create resource %s doc %d: %w", filename, i, err))
-		}
+		docs = append(docs, doc)
 	}
-	return apimachineryerrors.NewAggregate(errs)
+	return docs, nil
 }
 
-const annotationCreateOnlyKey = "bootstrap.kcp.dev/create-only"
+const (
+	annotationCreateOnlyKey = "bootstrap.kcp.dev/create-only"
 
-func createResourceFromFS(ctx context.Context, client dynamic.Interface, mapper meta.RESTMapper, raw []byte) error {
+	// annotationHashKey holds the hash of the bootstrap content a resource was last created or updated from.
+	annotationHashKey = "bootstrap.kcp.dev/hash"
+)
+
+// contentHash returns the hash of a resource document as stored in the hash annotation.
+func contentHash(raw []byte) string {
+	sum := sha256.Sum256(raw)
+	return hex.EncodeToString(sum[:])
+}
+
+// upsertResource creates the given resource, or updates it if it exists, unless it has the create-only
+// annotation or, without force, the content hash of the existing resource is unchanged.
+func upsertResource(ctx context.Context, client dynamic.Interface, mapper meta.RESTMapper, raw []byte, force bool) error {
 	obj, gvk, err := extensionsapiserver.Codecs.UniversalDeserializer().Decode(raw, nil, &unstructured.Unstructured{})
 	if err != nil {
 		return fmt.Errorf("could not decode raw: %w", err)
@@ -158,6 +249,14 @@ func createResourceFromFS(ctx context.Context, client dynamic.Interface, mapper
 		return fmt.Errorf("decoded into incorrect type, got %T, wanted %T", obj, &unstructured.Unstructured{})
 	}
 
+	hash := contentHash(raw)
+	annotations := u.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[annotationHashKey] = hash
+	u.SetAnnotations(annotations)
+
 	m, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
 	if err != nil {
 		return fmt.Errorf("could not get REST mapping for %s: %w", gvk, err)
@@ -190,6 +289,11 @@ func createResourceFromFS(ctx context.Context, client dynamic.Interface, mapper
 				return nil
 			}
 
+			if !force && existing.GetAnnotations()[annotationHashKey] == hash {
+				klog.V(4).Infof("Skipping update of %s %s because it is up-to-date", gvk, logName(existing))
+				return nil
+			}
+
 			u.SetResourceVersion(existing.GetResourceVersion())
 			if _, err = client.Resource(m.Resource).Namespace(u.GetNamespace()).Update(ctx, u, metav1.UpdateOptions{}); err != nil {
 				return fmt.Errorf("could not update %s %s: %w", gvk.Kind, logName(existing), err)
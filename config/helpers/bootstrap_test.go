@@ -0,0 +1,174 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helpers
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+)
+
+const configMapDoc = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: test
+  namespace: default
+data:
+  key: value
+`
+
+func TestUpsertResource(t *testing.T) {
+	configMapGVR := schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+	configMapGVK := schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"}
+
+	existing := func(annotations map[string]string) *unstructured.Unstructured {
+		u := &unstructured.Unstructured{}
+		u.SetGroupVersionKind(configMapGVK)
+		u.SetNamespace("default")
+		u.SetName("test")
+		u.SetAnnotations(annotations)
+		return u
+	}
+
+	tests := []struct {
+		name       string
+		existing   []runtime.Object
+		force      bool
+		wantUpdate bool
+		wantCreate bool
+	}{
+		{
+			name:       "not existing",
+			wantCreate: true,
+		},
+		{
+			name:       "existing without hash",
+			existing:   []runtime.Object{existing(nil)},
+			wantUpdate: true,
+		},
+		{
+			name:       "existing with outdated hash",
+			existing:   []runtime.Object{existing(map[string]string{annotationHashKey: "outdated"})},
+			wantUpdate: true,
+		},
+		{
+			name:     "existing with current hash",
+			existing: []runtime.Object{existing(map[string]string{annotationHashKey: contentHash([]byte(configMapDoc))})},
+		},
+		{
+			name:       "existing with current hash, forced",
+			existing:   []runtime.Object{existing(map[string]string{annotationHashKey: contentHash([]byte(configMapDoc))})},
+			force:      true,
+			wantUpdate: true,
+		},
+		{
+			name:     "existing with create-only annotation, forced",
+			existing: []runtime.Object{existing(map[string]string{annotationCreateOnlyKey: ""})},
+			force:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), tt.existing...)
+			mapper := meta.NewDefaultRESTMapper(nil)
+			mapper.Add(configMapGVK, meta.RESTScopeNamespace)
+
+			err := upsertResource(context.Background(), client, mapper, []byte(configMapDoc), tt.force)
+			require.NoError(t, err)
+
+			var created, updated bool
+			for _, action := range client.Actions() {
+				switch action.GetVerb() {
+				case "create":
+					created = len(tt.existing) == 0
+				case "update":
+					updated = true
+				}
+			}
+			require.Equal(t, tt.wantCreate, created, "unexpected create")
+			require.Equal(t, tt.wantUpdate, updated, "unexpected update")
+
+			got, err := client.Resource(configMapGVR).Namespace("default").Get(context.Background(), "test", metav1.GetOptions{})
+			require.NoError(t, err)
+			if tt.wantCreate || tt.wantUpdate {
+				require.Equal(t, contentHash([]byte(configMapDoc)), got.GetAnnotations()[annotationHashKey])
+			}
+		})
+	}
+}
+
+func TestTemplateOption(t *testing.T) {
+	require.NoError(t, os.Setenv("TEST_BOOTSTRAP_URL", "https://kcp.example.com"))
+	defer os.Unsetenv("TEST_BOOTSTRAP_URL") // nolint:errcheck
+
+	tests := []struct {
+		name    string
+		doc     string
+		vars    map[string]string
+		want    string
+		wantErr string
+	}{
+		{
+			name: "no template",
+			doc:  configMapDoc,
+			want: configMapDoc,
+		},
+		{
+			name: "variable",
+			doc:  "name: {{ .ShardName }}",
+			vars: map[string]string{"ShardName": "root"},
+			want: "name: root",
+		},
+		{
+			name:    "missing variable",
+			doc:     "name: {{ .ShardName }}",
+			vars:    map[string]string{"Other": "root"},
+			wantErr: `map has no entry for key "ShardName"`,
+		},
+		{
+			name: "environment variable",
+			doc:  `url: {{ env "TEST_BOOTSTRAP_URL" }}`,
+			want: "url: https://kcp.example.com",
+		},
+		{
+			name:    "missing environment variable",
+			doc:     `url: {{ env "TEST_BOOTSTRAP_UNSET" }}`,
+			wantErr: `environment variable "TEST_BOOTSTRAP_UNSET" is not set`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := TemplateOption(tt.vars).TransformFile([]byte(tt.doc))
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, string(got))
+		})
+	}
+}
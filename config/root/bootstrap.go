@@ -35,14 +35,34 @@ var fs embed.FS
 // Bootstrap creates resources in this package by continuously retrying the list.
 // This is blocking, i.e. it only returns (with error) when the context is closed or with nil when
 // the bootstrapping is successfully completed.
-func Bootstrap(ctx context.Context, rootDiscoveryClient discovery.DiscoveryInterface, rootDynamicClient dynamic.Interface, shardName string, kubeconfig clientcmdapi.Config) error {
+//
+// The resources are templates with the variables ShardName, ShardKubeconfig, ShardBaseURL,
+// ShardExternalURL, ShardVirtualWorkspaceURL and DefaultOrganizationName. The defaults derived from the given shard name
+// and kubeconfig can be overridden, and further variables added, through templateVars.
+func Bootstrap(ctx context.Context, rootDiscoveryClient discovery.DiscoveryInterface, rootDynamicClient dynamic.Interface, shardName string, kubeconfig clientcmdapi.Config, templateVars map[string]string, opts ...confighelpers.Option) error {
 	kubeconfigRaw, err := clientcmd.Write(kubeconfig)
 	if err != nil {
 		return err
 	}
 
-	return confighelpers.Bootstrap(ctx, rootDiscoveryClient, rootDynamicClient, fs, confighelpers.ReplaceOption(
-		"SHARD_NAME", shardName,
-		"SHARD_KUBECONFIG", base64.StdEncoding.EncodeToString(kubeconfigRaw),
-	))
+	var shardURL string
+	if context, found := kubeconfig.Contexts[kubeconfig.CurrentContext]; found {
+		if cluster, found := kubeconfig.Clusters[context.Cluster]; found {
+			shardURL = cluster.Server
+		}
+	}
+
+	vars := map[string]string{
+		"ShardName":                shardName,
+		"ShardKubeconfig":          base64.StdEncoding.EncodeToString(kubeconfigRaw),
+		"ShardBaseURL":             shardURL,
+		"ShardExternalURL":         shardURL,
+		"ShardVirtualWorkspaceURL": shardURL,
+		"DefaultOrganizationName":  "default",
+	}
+	for k, v := range templateVars {
+		vars[k] = v
+	}
+
+	return confighelpers.Bootstrap(ctx, rootDiscoveryClient, rootDynamicClient, fs, append(opts, confighelpers.TemplateOption(vars))...)
 }
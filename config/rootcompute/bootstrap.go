@@ -0,0 +1,50 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rootcompute
+
+import (
+	"context"
+	"embed"
+
+	"github.com/kcp-dev/logicalcluster"
+
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+
+	confighelpers "github.com/kcp-dev/kcp/config/helpers"
+)
+
+// ClusterName is the logical cluster of the compute workspace, exporting the standard
+// Kubernetes workload APIs through the kubernetes APIExport.
+var ClusterName = logicalcluster.New("root:compute")
+
+//go:embed clusterworkspace-compute.yaml
+var rootFS embed.FS
+
+//go:embed kubernetes-*.yaml
+var computeFS embed.FS
+
+// Bootstrap creates the compute workspace in the root workspace, and the APIResourceSchemas of
+// deployments, services and ingresses and the kubernetes APIExport in the compute workspace, by
+// continuously retrying the list. This is blocking, i.e. it only returns (with error) when the
+// context is closed or with nil when the bootstrapping is successfully completed.
+func Bootstrap(ctx context.Context, rootDiscoveryClient discovery.DiscoveryInterface, rootDynamicClient dynamic.Interface, computeDiscoveryClient discovery.DiscoveryInterface, computeDynamicClient dynamic.Interface, opts ...confighelpers.Option) error {
+	if err := confighelpers.Bootstrap(ctx, rootDiscoveryClient, rootDynamicClient, rootFS, opts...); err != nil {
+		return err
+	}
+	return confighelpers.Bootstrap(ctx, computeDiscoveryClient, computeDynamicClient, computeFS, opts...)
+}
@@ -46,7 +46,7 @@ var fs embed.FS
 // Bootstrap creates CRDs and the resources in this package by continuously retrying the list.
 // This is blocking, i.e. it only returns (with error) when the context is closed or with nil when
 // the bootstrapping is successfully completed.
-func Bootstrap(ctx context.Context, crdClient apiextensionsclient.Interface, discoveryClient discovery.DiscoveryInterface, dynamicClient dynamic.Interface) error {
+func Bootstrap(ctx context.Context, crdClient apiextensionsclient.Interface, discoveryClient discovery.DiscoveryInterface, dynamicClient dynamic.Interface, opts ...confighelpers.Option) error {
 	// This is the full list of CRDs that kcp owns and manages in the system:system-crds logical cluster. Our custom CRD
 	// lister currently has a hard-coded list of which system CRDs are made available to which workspaces. See
 	// pkg/server/apiextensions.go newSystemCRDProvider for the list. These CRDs should never be installed in any other
@@ -57,17 +57,22 @@ func Bootstrap(ctx context.Context, crdClient apiextensionsclient.Interface, dis
 		{Group: tenancy.GroupName, Resource: "clusterworkspacetypes"},
 		{Group: tenancy.GroupName, Resource: "clusterworkspaceshards"},
 		{Group: tenancy.GroupName, Resource: "workspaces"},
+		{Group: tenancy.GroupName, Resource: "workspacesnapshots"},
 		{Group: apiresource.GroupName, Resource: "apiresourceimports"},
 		{Group: apiresource.GroupName, Resource: "negotiatedapiresources"},
 		{Group: workload.GroupName, Resource: "workloadclusters"},
 		{Group: apis.GroupName, Resource: "apiexports"},
 		{Group: apis.GroupName, Resource: "apibindings"},
 		{Group: apis.GroupName, Resource: "apiresourceschemas"},
+		{Group: apis.GroupName, Resource: "secretshares"},
 	}
 
 	if utilfeature.DefaultFeatureGate.Enabled(kcpfeatures.LocationAPI) {
 		crds = append(crds, metav1.GroupResource{Group: scheduling.GroupName, Resource: "locations"})
 	}
+	if utilfeature.DefaultFeatureGate.Enabled(kcpfeatures.WorkspaceSource) {
+		crds = append(crds, metav1.GroupResource{Group: tenancy.GroupName, Resource: "workspacesources"})
+	}
 
 	if err := wait.PollImmediateInfiniteWithContext(ctx, time.Second, func(ctx context.Context) (bool, error) {
 		if err := configcrds.Create(ctx, crdClient.ApiextensionsV1().CustomResourceDefinitions(), crds...); err != nil {
@@ -79,5 +84,5 @@ func Bootstrap(ctx context.Context, crdClient apiextensionsclient.Interface, dis
 		return fmt.Errorf("failed to bootstrap system CRDs: %w", err)
 	}
 
-	return confighelpers.Bootstrap(ctx, discoveryClient, dynamicClient, fs)
+	return confighelpers.Bootstrap(ctx, discoveryClient, dynamicClient, fs, opts...)
 }
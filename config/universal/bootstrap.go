@@ -32,6 +32,6 @@ var fs embed.FS
 // Bootstrap creates resources in this package by continuously retrying the list.
 // This is blocking, i.e. it only returns (with error) when the context is closed or with nil when
 // the bootstrapping is successfully completed.
-func Bootstrap(ctx context.Context, discoveryClient discovery.DiscoveryInterface, dynamicClient dynamic.Interface) error {
-	return confighelpers.Bootstrap(ctx, discoveryClient, dynamicClient, fs)
+func Bootstrap(ctx context.Context, discoveryClient discovery.DiscoveryInterface, dynamicClient dynamic.Interface, opts ...confighelpers.Option) error {
+	return confighelpers.Bootstrap(ctx, discoveryClient, dynamicClient, fs, opts...)
 }
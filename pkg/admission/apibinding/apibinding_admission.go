@@ -113,11 +113,10 @@ func (o *apiBindingAdmission) Validate(ctx context.Context, a admission.Attribut
 	if err != nil {
 		return admission.NewForbidden(a, fmt.Errorf("error determining workspace: %w", err))
 	}
-	org, hasParent := cluster.Name.Parent()
-	if !hasParent {
-		return admission.NewForbidden(a, fmt.Errorf("%q is not a valid workspace name: %w", cluster.Name, err))
+	apiExportClusterName, ok := apiBinding.Spec.Reference.Workspace.ClusterName(cluster.Name)
+	if !ok {
+		return admission.NewForbidden(a, fmt.Errorf("an APIBinding in %q cannot reference workspace %q by name", cluster.Name, apiBinding.Spec.Reference.Workspace.WorkspaceName))
 	}
-	apiExportClusterName := org.Join(apiBinding.Spec.Reference.Workspace.WorkspaceName)
 
 	// Access check
 	if err := o.checkAPIExportAccess(ctx, a.GetUserInfo(), apiExportClusterName, apiBinding.Spec.Reference.Workspace.ExportName); err != nil {
@@ -102,6 +102,20 @@ func TestValidate(t *testing.T) {
 			),
 			authzDecision: authorizer.DecisionAllow,
 		},
+		{
+			name: "Create: workspace path reference passes when authorized",
+			attr: createAttr(
+				newAPIBinding().withName("test").withWorkspacePathReference("root:compute", "kubernetes").APIBinding,
+			),
+			authzDecision: authorizer.DecisionAllow,
+		},
+		{
+			name: "Create: workspace reference with name and path fails",
+			attr: createAttr(
+				newAPIBinding().withName("test").withWorkspaceReference("workspaceName", "someExport").withWorkspacePathReference("root:compute", "someExport").APIBinding,
+			),
+			expectedErrors: []string{"spec.reference.path: Invalid value"},
+		},
 		{
 			name: "Create: complete workspace reference fails with no authorization decision",
 			attr: createAttr(
@@ -303,6 +317,15 @@ func (b *bindingBuilder) withWorkspaceReference(workspaceName, exportName string
 	return b
 }
 
+func (b *bindingBuilder) withWorkspacePathReference(path, exportName string) *bindingBuilder {
+	if b.Spec.Reference.Workspace == nil {
+		b.Spec.Reference.Workspace = &apisv1alpha1.WorkspaceExportReference{}
+	}
+	b.Spec.Reference.Workspace.Path = path
+	b.Spec.Reference.Workspace.ExportName = exportName
+	return b
+}
+
 func (b *bindingBuilder) withPhase(phase apisv1alpha1.APIBindingPhaseType) *bindingBuilder {
 	b.Status.Phase = phase
 	return b
@@ -57,9 +57,11 @@ func ValidateAPIBindingReference(reference apisv1alpha1.ExportReference, path *f
 
 	// For now, workspace is required via OpenAPI. But just in case...
 	if workspace := reference.Workspace; workspace != nil {
-		// These are required by OpenAPI, but just in case...
-		if workspace.WorkspaceName == "" {
-			allErrs = append(allErrs, field.Required(path.Child("name"), ""))
+		switch {
+		case workspace.WorkspaceName == "" && workspace.Path == "":
+			allErrs = append(allErrs, field.Required(path.Child("name"), "either name or path must be set"))
+		case workspace.WorkspaceName != "" && workspace.Path != "":
+			allErrs = append(allErrs, field.Invalid(path.Child("path"), workspace.Path, "must not be set together with name"))
 		}
 
 		if workspace.ExportName == "" {
@@ -0,0 +1,160 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apiexport
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/apiserver/pkg/admission"
+	genericapirequest "k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/client-go/tools/clusters"
+
+	kcpinitializers "github.com/kcp-dev/kcp/pkg/admission/initializers"
+	apisv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apis/v1alpha1"
+	kcpinformers "github.com/kcp-dev/kcp/pkg/client/informers/externalversions"
+	apislisters "github.com/kcp-dev/kcp/pkg/client/listers/apis/v1alpha1"
+)
+
+const (
+	PluginName = "apis.kcp.dev/APIExport"
+)
+
+func Register(plugins *admission.Plugins) {
+	plugins.Register(PluginName,
+		func(_ io.Reader) (admission.Interface, error) {
+			return &apiExportAdmission{
+				Handler: admission.NewHandler(admission.Update),
+			}, nil
+		})
+}
+
+// apiExportAdmission rejects updates of spec.latestResourceSchemas of an APIExport that
+// replace an APIResourceSchema by an incompatible one, unless the name of the new APIResourceSchema
+// is listed in the apis.kcp.dev/breaking-change-accepted annotation. As schemas are immutable, the
+// annotation only accepts the given breaking change, not later ones.
+type apiExportAdmission struct {
+	*admission.Handler
+
+	apiResourceSchemaLister apislisters.APIResourceSchemaLister
+}
+
+// Ensure that the required admission interfaces are implemented.
+var _ = admission.ValidationInterface(&apiExportAdmission{})
+var _ = admission.InitializationValidator(&apiExportAdmission{})
+var _ = kcpinitializers.WantsKcpInformers(&apiExportAdmission{})
+
+// Validate checks the compatibility of APIResourceSchemas replaced in spec.latestResourceSchemas.
+func (o *apiExportAdmission) Validate(ctx context.Context, a admission.Attributes, _ admission.ObjectInterfaces) (err error) {
+	if a.GetResource().GroupResource() != apisv1alpha1.Resource("apiexports") {
+		return nil
+	}
+	if a.GetSubresource() != "" {
+		return nil
+	}
+
+	u, ok := a.GetObject().(*unstructured.Unstructured)
+	if !ok {
+		return fmt.Errorf("unexpected type %T", a.GetObject())
+	}
+	export := &apisv1alpha1.APIExport{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, export); err != nil {
+		return fmt.Errorf("failed to convert unstructured to APIExport: %w", err)
+	}
+	oldU, ok := a.GetOldObject().(*unstructured.Unstructured)
+	if !ok {
+		return fmt.Errorf("unexpected type %T", a.GetOldObject())
+	}
+	old := &apisv1alpha1.APIExport{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(oldU.Object, old); err != nil {
+		return fmt.Errorf("failed to convert unstructured to APIExport: %w", err)
+	}
+
+	if equality.Semantic.DeepEqual(old.Spec.LatestResourceSchemas, export.Spec.LatestResourceSchemas) {
+		return nil
+	}
+	if !o.WaitForReady() {
+		return admission.NewForbidden(a, fmt.Errorf("not yet ready to handle request"))
+	}
+
+	clusterName, err := genericapirequest.ClusterNameFrom(ctx)
+	if err != nil {
+		return apierrors.NewInternalError(err)
+	}
+
+	oldNames := sets.NewString(old.Spec.LatestResourceSchemas...)
+	accepted := sets.NewString()
+	if value := export.Annotations[apisv1alpha1.AnnotationBreakingChangeAcceptedKey]; value != "" {
+		for _, name := range strings.Split(value, ",") {
+			accepted.Insert(strings.TrimSpace(name))
+		}
+	}
+	oldSchemas := map[schema.GroupResource]*apisv1alpha1.APIResourceSchema{}
+	for _, name := range old.Spec.LatestResourceSchemas {
+		sch, err := o.apiResourceSchemaLister.Get(clusters.ToClusterAwareKey(clusterName, name))
+		if apierrors.IsNotFound(err) {
+			continue // nothing can have been bound to it
+		} else if err != nil {
+			return admission.NewForbidden(a, err)
+		}
+		oldSchemas[schema.GroupResource{Group: sch.Spec.Group, Resource: sch.Spec.Names.Plural}] = sch
+	}
+
+	var errs field.ErrorList
+	for i, name := range export.Spec.LatestResourceSchemas {
+		if oldNames.Has(name) || accepted.Has(name) {
+			continue
+		}
+		sch, err := o.apiResourceSchemaLister.Get(clusters.ToClusterAwareKey(clusterName, name))
+		if apierrors.IsNotFound(err) {
+			continue // the APIExport controller reports missing schemas
+		} else if err != nil {
+			return admission.NewForbidden(a, err)
+		}
+		oldSchema, found := oldSchemas[schema.GroupResource{Group: sch.Spec.Group, Resource: sch.Spec.Names.Plural}]
+		if !found {
+			continue
+		}
+		errs = append(errs, ValidateAPIResourceSchemaCompatibility(oldSchema, sch, field.NewPath("spec", "latestResourceSchemas").Index(i))...)
+	}
+	if len(errs) > 0 {
+		return admission.NewForbidden(a, fmt.Errorf("%v; list the incompatible APIResourceSchemas in the %s annotation to accept the breaking change", errs.ToAggregate(), apisv1alpha1.AnnotationBreakingChangeAcceptedKey))
+	}
+
+	return nil
+}
+
+func (o *apiExportAdmission) ValidateInitialization() error {
+	if o.apiResourceSchemaLister == nil {
+		return fmt.Errorf(PluginName + " plugin needs an APIResourceSchema lister")
+	}
+	return nil
+}
+
+func (o *apiExportAdmission) SetKcpInformers(informers kcpinformers.SharedInformerFactory) {
+	o.SetReadyFunc(informers.Apis().V1alpha1().APIResourceSchemas().Informer().HasSynced)
+	o.apiResourceSchemaLister = informers.Apis().V1alpha1().APIResourceSchemas().Lister()
+}
@@ -0,0 +1,273 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apiexport
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/kcp-dev/logicalcluster"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apiserver/pkg/admission"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/apiserver/pkg/endpoints/request"
+
+	"github.com/kcp-dev/kcp/pkg/admission/helpers"
+	apisv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apis/v1alpha1"
+)
+
+func updateAttr(export, old *apisv1alpha1.APIExport) admission.Attributes {
+	return admission.NewAttributesRecord(
+		helpers.ToUnstructuredOrDie(export),
+		helpers.ToUnstructuredOrDie(old),
+		apisv1alpha1.Kind("APIExport").WithVersion("v1alpha1"),
+		"",
+		export.Name,
+		apisv1alpha1.Resource("apiexports").WithVersion("v1alpha1"),
+		"",
+		admission.Update,
+		&metav1.UpdateOptions{},
+		false,
+		&user.DefaultInfo{},
+	)
+}
+
+func newExport(annotations map[string]string, schemas ...string) *apisv1alpha1.APIExport {
+	return &apisv1alpha1.APIExport{
+		ObjectMeta: metav1.ObjectMeta{Name: "widgets", Annotations: annotations},
+		Spec:       apisv1alpha1.APIExportSpec{LatestResourceSchemas: schemas},
+	}
+}
+
+func newSchema(name, resource string, scope apiextensionsv1.ResourceScope, versions ...apisv1alpha1.APIResourceVersion) *apisv1alpha1.APIResourceSchema {
+	return &apisv1alpha1.APIResourceSchema{
+		ObjectMeta: metav1.ObjectMeta{Name: "root:org#$#" + name},
+		Spec: apisv1alpha1.APIResourceSchemaSpec{
+			Group:    "example.com",
+			Names:    apiextensionsv1.CustomResourceDefinitionNames{Plural: resource},
+			Scope:    scope,
+			Versions: versions,
+		},
+	}
+}
+
+func version(name string, storage bool, schema string) apisv1alpha1.APIResourceVersion {
+	return apisv1alpha1.APIResourceVersion{
+		Name:    name,
+		Served:  true,
+		Storage: storage,
+		Schema:  runtime.RawExtension{Raw: []byte(schema)},
+	}
+}
+
+const (
+	specSchema         = `{"type":"object","properties":{"spec":{"type":"object","properties":{"color":{"type":"string"},"sizes":{"type":"array","items":{"type":"object","properties":{"value":{"type":"integer"}}}}}}}}`
+	extendedSpecSchema = `{"type":"object","properties":{"spec":{"type":"object","properties":{"color":{"type":"string"},"shape":{"type":"string"},"sizes":{"type":"array","items":{"type":"object","properties":{"value":{"type":"integer"}}}}}}}}`
+	removedColorSchema = `{"type":"object","properties":{"spec":{"type":"object","properties":{"sizes":{"type":"array","items":{"type":"object","properties":{"value":{"type":"integer"}}}}}}}}`
+	preservedSchema    = `{"type":"object","properties":{"spec":{"type":"object","x-kubernetes-preserve-unknown-fields":true}}}`
+	retypedItemSchema  = `{"type":"object","properties":{"spec":{"type":"object","properties":{"color":{"type":"string"},"sizes":{"type":"array","items":{"type":"object","properties":{"value":{"type":"string"}}}}}}}}`
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name           string
+		schemas        []*apisv1alpha1.APIResourceSchema
+		attr           admission.Attributes
+		expectedErrors []string
+	}{
+		{
+			name: "unchanged schemas",
+			schemas: []*apisv1alpha1.APIResourceSchema{
+				newSchema("v1.widgets.example.com", "widgets", apiextensionsv1.NamespaceScoped, version("v1", true, specSchema)),
+			},
+			attr: updateAttr(
+				newExport(map[string]string{"foo": "bar"}, "v1.widgets.example.com"),
+				newExport(nil, "v1.widgets.example.com"),
+			),
+		},
+		{
+			name: "added field",
+			schemas: []*apisv1alpha1.APIResourceSchema{
+				newSchema("v1.widgets.example.com", "widgets", apiextensionsv1.NamespaceScoped, version("v1", true, specSchema)),
+				newSchema("v2.widgets.example.com", "widgets", apiextensionsv1.NamespaceScoped, version("v1", true, extendedSpecSchema)),
+			},
+			attr: updateAttr(
+				newExport(nil, "v2.widgets.example.com"),
+				newExport(nil, "v1.widgets.example.com"),
+			),
+		},
+		{
+			name: "removed field",
+			schemas: []*apisv1alpha1.APIResourceSchema{
+				newSchema("v1.widgets.example.com", "widgets", apiextensionsv1.NamespaceScoped, version("v1", true, specSchema)),
+				newSchema("v2.widgets.example.com", "widgets", apiextensionsv1.NamespaceScoped, version("v1", true, removedColorSchema)),
+			},
+			attr: updateAttr(
+				newExport(nil, "v2.widgets.example.com"),
+				newExport(nil, "v1.widgets.example.com"),
+			),
+			expectedErrors: []string{`version v1: field .spec.color is removed`, apisv1alpha1.AnnotationBreakingChangeAcceptedKey},
+		},
+		{
+			name: "removed field with breaking change accepted",
+			schemas: []*apisv1alpha1.APIResourceSchema{
+				newSchema("v1.widgets.example.com", "widgets", apiextensionsv1.NamespaceScoped, version("v1", true, specSchema)),
+				newSchema("v2.widgets.example.com", "widgets", apiextensionsv1.NamespaceScoped, version("v1", true, removedColorSchema)),
+			},
+			attr: updateAttr(
+				newExport(map[string]string{apisv1alpha1.AnnotationBreakingChangeAcceptedKey: "v2.widgets.example.com"}, "v2.widgets.example.com"),
+				newExport(nil, "v1.widgets.example.com"),
+			),
+		},
+		{
+			name: "removed field with another breaking change accepted",
+			schemas: []*apisv1alpha1.APIResourceSchema{
+				newSchema("v2.widgets.example.com", "widgets", apiextensionsv1.NamespaceScoped, version("v1", true, specSchema)),
+				newSchema("v3.widgets.example.com", "widgets", apiextensionsv1.NamespaceScoped, version("v1", true, removedColorSchema)),
+			},
+			attr: updateAttr(
+				newExport(map[string]string{apisv1alpha1.AnnotationBreakingChangeAcceptedKey: "v2.widgets.example.com"}, "v3.widgets.example.com"),
+				newExport(map[string]string{apisv1alpha1.AnnotationBreakingChangeAcceptedKey: "v2.widgets.example.com"}, "v2.widgets.example.com"),
+			),
+			expectedErrors: []string{`version v1: field .spec.color is removed`, apisv1alpha1.AnnotationBreakingChangeAcceptedKey},
+		},
+		{
+			name: "removed field preserved as unknown field",
+			schemas: []*apisv1alpha1.APIResourceSchema{
+				newSchema("v1.widgets.example.com", "widgets", apiextensionsv1.NamespaceScoped, version("v1", true, specSchema)),
+				newSchema("v2.widgets.example.com", "widgets", apiextensionsv1.NamespaceScoped, version("v1", true, preservedSchema)),
+			},
+			attr: updateAttr(
+				newExport(nil, "v2.widgets.example.com"),
+				newExport(nil, "v1.widgets.example.com"),
+			),
+		},
+		{
+			name: "changed type of array item field",
+			schemas: []*apisv1alpha1.APIResourceSchema{
+				newSchema("v1.widgets.example.com", "widgets", apiextensionsv1.NamespaceScoped, version("v1", true, specSchema)),
+				newSchema("v2.widgets.example.com", "widgets", apiextensionsv1.NamespaceScoped, version("v1", true, retypedItemSchema)),
+			},
+			attr: updateAttr(
+				newExport(nil, "v2.widgets.example.com"),
+				newExport(nil, "v1.widgets.example.com"),
+			),
+			expectedErrors: []string{`field .spec.sizes[*].value changes type from integer to string`},
+		},
+		{
+			name: "changed scope",
+			schemas: []*apisv1alpha1.APIResourceSchema{
+				newSchema("v1.widgets.example.com", "widgets", apiextensionsv1.NamespaceScoped, version("v1", true, specSchema)),
+				newSchema("v2.widgets.example.com", "widgets", apiextensionsv1.ClusterScoped, version("v1", true, specSchema)),
+			},
+			attr: updateAttr(
+				newExport(nil, "v2.widgets.example.com"),
+				newExport(nil, "v1.widgets.example.com"),
+			),
+			expectedErrors: []string{`changes scope from Namespaced to Cluster`},
+		},
+		{
+			name: "removed storage version",
+			schemas: []*apisv1alpha1.APIResourceSchema{
+				newSchema("v1.widgets.example.com", "widgets", apiextensionsv1.NamespaceScoped, version("v1", true, specSchema)),
+				newSchema("v2.widgets.example.com", "widgets", apiextensionsv1.NamespaceScoped, version("v2", true, specSchema)),
+			},
+			attr: updateAttr(
+				newExport(nil, "v2.widgets.example.com"),
+				newExport(nil, "v1.widgets.example.com"),
+			),
+			expectedErrors: []string{`removes storage version v1`},
+		},
+		{
+			name: "new storage version keeping the old one served",
+			schemas: []*apisv1alpha1.APIResourceSchema{
+				newSchema("v1.widgets.example.com", "widgets", apiextensionsv1.NamespaceScoped, version("v1", true, specSchema)),
+				newSchema("v2.widgets.example.com", "widgets", apiextensionsv1.NamespaceScoped, version("v1", false, specSchema), version("v2", true, removedColorSchema)),
+			},
+			attr: updateAttr(
+				newExport(nil, "v2.widgets.example.com"),
+				newExport(nil, "v1.widgets.example.com"),
+			),
+		},
+		{
+			name: "replaced schema of another resource",
+			schemas: []*apisv1alpha1.APIResourceSchema{
+				newSchema("v1.widgets.example.com", "widgets", apiextensionsv1.NamespaceScoped, version("v1", true, specSchema)),
+				newSchema("v1.gadgets.example.com", "gadgets", apiextensionsv1.ClusterScoped, version("v1", true, removedColorSchema)),
+			},
+			attr: updateAttr(
+				newExport(nil, "v1.gadgets.example.com"),
+				newExport(nil, "v1.widgets.example.com"),
+			),
+		},
+		{
+			name: "missing new schema",
+			schemas: []*apisv1alpha1.APIResourceSchema{
+				newSchema("v1.widgets.example.com", "widgets", apiextensionsv1.NamespaceScoped, version("v1", true, specSchema)),
+			},
+			attr: updateAttr(
+				newExport(nil, "v2.widgets.example.com"),
+				newExport(nil, "v1.widgets.example.com"),
+			),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := &apiExportAdmission{
+				Handler:                 admission.NewHandler(admission.Update),
+				apiResourceSchemaLister: fakeAPIResourceSchemaLister(tt.schemas),
+			}
+			ctx := request.WithCluster(context.Background(), request.Cluster{Name: logicalcluster.New("root:org")})
+			err := o.Validate(ctx, tt.attr, nil)
+			if len(tt.expectedErrors) == 0 {
+				if err != nil {
+					t.Fatalf("Validate() unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil {
+				t.Fatalf("Validate() expected errors %v, got none", tt.expectedErrors)
+			}
+			for _, expected := range tt.expectedErrors {
+				if !strings.Contains(err.Error(), expected) {
+					t.Errorf("Validate() error %q does not contain %q", err.Error(), expected)
+				}
+			}
+		})
+	}
+}
+
+type fakeAPIResourceSchemaLister []*apisv1alpha1.APIResourceSchema
+
+func (l fakeAPIResourceSchemaLister) List(selector labels.Selector) (ret []*apisv1alpha1.APIResourceSchema, err error) {
+	return l, nil
+}
+
+func (l fakeAPIResourceSchemaLister) Get(name string) (*apisv1alpha1.APIResourceSchema, error) {
+	for _, s := range l {
+		if s.Name == name {
+			return s, nil
+		}
+	}
+	return nil, apierrors.NewNotFound(apisv1alpha1.Resource("apiresourceschemas"), name)
+}
@@ -0,0 +1,127 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apiexport
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	apisv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apis/v1alpha1"
+)
+
+// ValidateAPIResourceSchemaCompatibility returns the changes from old to new that break objects
+// already stored for the resource: a scope change, the removal of the old storage version, and
+// fields that are removed or change their type in versions served by both schemas.
+func ValidateAPIResourceSchemaCompatibility(old, new *apisv1alpha1.APIResourceSchema, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if old.Spec.Scope != new.Spec.Scope {
+		allErrs = append(allErrs, field.Forbidden(fldPath, fmt.Sprintf("APIResourceSchema %q changes scope from %s to %s", new.Name, old.Spec.Scope, new.Spec.Scope)))
+	}
+
+	newVersions := map[string]apisv1alpha1.APIResourceVersion{}
+	for _, v := range new.Spec.Versions {
+		newVersions[v.Name] = v
+	}
+	for _, oldVersion := range old.Spec.Versions {
+		newVersion, found := newVersions[oldVersion.Name]
+		if !found {
+			if oldVersion.Storage {
+				allErrs = append(allErrs, field.Forbidden(fldPath, fmt.Sprintf("APIResourceSchema %q removes storage version %s", new.Name, oldVersion.Name)))
+			}
+			continue
+		}
+
+		oldProps, err := versionSchema(oldVersion)
+		if err != nil {
+			allErrs = append(allErrs, field.InternalError(fldPath, fmt.Errorf("APIResourceSchema %q version %s: %w", old.Name, oldVersion.Name, err)))
+			continue
+		}
+		newProps, err := versionSchema(newVersion)
+		if err != nil {
+			allErrs = append(allErrs, field.InternalError(fldPath, fmt.Errorf("APIResourceSchema %q version %s: %w", new.Name, newVersion.Name, err)))
+			continue
+		}
+		for _, change := range incompatibleSchemaChanges(oldProps, newProps, "") {
+			allErrs = append(allErrs, field.Forbidden(fldPath, fmt.Sprintf("APIResourceSchema %q version %s: %s", new.Name, newVersion.Name, change)))
+		}
+	}
+
+	return allErrs
+}
+
+func versionSchema(version apisv1alpha1.APIResourceVersion) (*apiextensionsv1.JSONSchemaProps, error) {
+	props := &apiextensionsv1.JSONSchemaProps{}
+	if len(version.Schema.Raw) == 0 || string(version.Schema.Raw) == "null" {
+		return props, nil
+	}
+	if err := json.Unmarshal(version.Schema.Raw, props); err != nil {
+		return nil, fmt.Errorf("invalid schema: %w", err)
+	}
+	return props, nil
+}
+
+// incompatibleSchemaChanges walks old and new in parallel and describes every field that is
+// removed or changes its type. Fields below x-kubernetes-preserve-unknown-fields in the new
+// schema are not pruned and hence not considered removed.
+func incompatibleSchemaChanges(old, new *apiextensionsv1.JSONSchemaProps, path string) []string {
+	if old == nil || new == nil {
+		return nil
+	}
+
+	var changes []string
+	if old.Type != "" && new.Type != "" && old.Type != new.Type {
+		return append(changes, fmt.Sprintf("field %s changes type from %s to %s", fieldPath(path), old.Type, new.Type))
+	}
+
+	names := make([]string, 0, len(old.Properties))
+	for name := range old.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		oldProp := old.Properties[name]
+		newProp, found := new.Properties[name]
+		if !found {
+			if new.XPreserveUnknownFields == nil || !*new.XPreserveUnknownFields {
+				changes = append(changes, fmt.Sprintf("field %s is removed", fieldPath(path+"."+name)))
+			}
+			continue
+		}
+		changes = append(changes, incompatibleSchemaChanges(&oldProp, &newProp, path+"."+name)...)
+	}
+
+	if old.Items != nil && new.Items != nil {
+		changes = append(changes, incompatibleSchemaChanges(old.Items.Schema, new.Items.Schema, path+"[*]")...)
+	}
+	if old.AdditionalProperties != nil && new.AdditionalProperties != nil {
+		changes = append(changes, incompatibleSchemaChanges(old.AdditionalProperties.Schema, new.AdditionalProperties.Schema, path+"[*]")...)
+	}
+
+	return changes
+}
+
+func fieldPath(path string) string {
+	if path == "" {
+		return "."
+	}
+	return path
+}
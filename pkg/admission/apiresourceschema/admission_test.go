@@ -27,6 +27,9 @@ import (
 	"k8s.io/apiserver/pkg/admission"
 	"k8s.io/apiserver/pkg/authentication/user"
 	"k8s.io/apiserver/pkg/endpoints/request"
+	genericfeatures "k8s.io/apiserver/pkg/features"
+	utilfeature "k8s.io/apiserver/pkg/util/feature"
+	featuregatetesting "k8s.io/component-base/featuregate/testing"
 	"sigs.k8s.io/yaml"
 
 	"github.com/kcp-dev/kcp/pkg/admission/helpers"
@@ -71,6 +74,7 @@ func TestValidate(t *testing.T) {
 	tests := []struct {
 		name           string
 		attr           admission.Attributes
+		celEnabled     bool
 		expectedErrors []string
 	}{
 		{
@@ -181,9 +185,227 @@ spec:
 				"spec.group: Invalid value: \"core\": must be empty string for the core group",
 			},
 		},
+		{
+			name:       "an APIResourceSchema can have CEL validation rules",
+			celEnabled: true,
+			attr: createAttr(unmarshalOrDie(`
+apiVersion: apis.kcp.sh/v1alpha1
+kind: APIResourceSchema
+metadata:
+  name: july.cowboys.wild.west
+spec:
+  group: wild.west
+  names:
+    plural: cowboys
+    singular: cowboy
+    kind: Cowboy
+    listKind: CowboyList
+  scope: Cluster
+  versions:
+  - name: v1
+    served: true
+    storage: true
+    schema:
+      type: object
+      properties:
+        spec:
+          type: object
+          x-kubernetes-validations:
+          - rule: "self.minReplicas <= self.maxReplicas"
+            message: "minReplicas must not exceed maxReplicas"
+          properties:
+            minReplicas:
+              type: integer
+              default: 1
+            maxReplicas:
+              type: integer
+              default: 10
+            `)),
+		},
+		{
+			name:       "an APIResourceSchema with invalid CEL validation rules fails admission",
+			celEnabled: true,
+			attr: createAttr(unmarshalOrDie(`
+apiVersion: apis.kcp.sh/v1alpha1
+kind: APIResourceSchema
+metadata:
+  name: july.cowboys.wild.west
+spec:
+  group: wild.west
+  names:
+    plural: cowboys
+    singular: cowboy
+    kind: Cowboy
+    listKind: CowboyList
+  scope: Cluster
+  versions:
+  - name: v1
+    served: true
+    storage: true
+    schema:
+      type: object
+      properties:
+        spec:
+          type: object
+          x-kubernetes-validations:
+          - rule: "self.minReplicas <= self.unknownField"
+            message: "minReplicas must not exceed maxReplicas"
+          properties:
+            minReplicas:
+              type: integer
+              default: 1
+            maxReplicas:
+              type: integer
+              default: 10
+            `)),
+			expectedErrors: []string{
+				"spec.versions[0].schema.openAPIV3Schema.properties[spec].x-kubernetes-validations[0].rule: Invalid value",
+			},
+		},
+		{
+			name: "CEL validation rules are forbidden without the feature gate",
+			attr: createAttr(unmarshalOrDie(`
+apiVersion: apis.kcp.sh/v1alpha1
+kind: APIResourceSchema
+metadata:
+  name: july.cowboys.wild.west
+spec:
+  group: wild.west
+  names:
+    plural: cowboys
+    singular: cowboy
+    kind: Cowboy
+    listKind: CowboyList
+  scope: Cluster
+  versions:
+  - name: v1
+    served: true
+    storage: true
+    schema:
+      type: object
+      properties:
+        spec:
+          type: object
+          x-kubernetes-validations:
+          - rule: "self.minReplicas <= self.maxReplicas"
+            message: "minReplicas must not exceed maxReplicas"
+          properties:
+            minReplicas:
+              type: integer
+              default: 1
+            maxReplicas:
+              type: integer
+              default: 10
+            `)),
+			expectedErrors: []string{
+				"spec.versions[0].schema: Forbidden: x-kubernetes-validations requires the CustomResourceValidationExpressions feature gate",
+			},
+		},
+		{
+			name: "an APIResourceSchema can declare conversions between versions",
+			attr: createAttr(unmarshalOrDie(`
+apiVersion: apis.kcp.sh/v1alpha1
+kind: APIResourceSchema
+metadata:
+  name: july.cowboys.wild.west
+spec:
+  group: wild.west
+  names:
+    plural: cowboys
+    singular: cowboy
+    kind: Cowboy
+    listKind: CowboyList
+  scope: Cluster
+  versions:
+  - name: v1
+    served: true
+    storage: false
+    schema:
+      type: object
+      properties:
+        spec:
+          type: object
+          properties:
+            horse:
+              type: string
+  - name: v2
+    served: true
+    storage: true
+    schema:
+      type: object
+      properties:
+        spec:
+          type: object
+          properties:
+            mount:
+              type: string
+  conversions:
+  - from: v1
+    to: v2
+    fields:
+    - from: .spec.horse
+      to: .spec.mount
+            `)),
+		},
+		{
+			name: "invalid conversions fail admission",
+			attr: createAttr(unmarshalOrDie(`
+apiVersion: apis.kcp.sh/v1alpha1
+kind: APIResourceSchema
+metadata:
+  name: july.cowboys.wild.west
+spec:
+  group: wild.west
+  names:
+    plural: cowboys
+    singular: cowboy
+    kind: Cowboy
+    listKind: CowboyList
+  scope: Cluster
+  versions:
+  - name: v1
+    served: true
+    storage: false
+    schema:
+      type: object
+  - name: v2
+    served: true
+    storage: true
+    schema:
+      type: object
+  conversions:
+  - from: v1
+    to: v2
+    fields:
+    - from: .metadata.name
+      to: spec.name
+  - from: v2
+    to: v1
+    fields:
+    - from: .spec.a
+      to: .spec.b
+    - from: .spec.a
+      to: .spec.c[0]
+  - from: v1
+    to: v3
+    fields:
+    - from: .spec.a
+      to: .spec.b
+            `)),
+			expectedErrors: []string{
+				"spec.conversions[0].fields[0].from: Invalid value: \".metadata.name\": metadata cannot be converted",
+				"spec.conversions[0].fields[0].to: Invalid value: \"spec.name\": must start with a dot",
+				"spec.conversions[1]: Duplicate value: \"v1/v2\"",
+				"spec.conversions[1].fields[1].from: Duplicate value: \".spec.a\"",
+				"spec.conversions[1].fields[1].to: Invalid value: \".spec.c[0]\": only field names are supported, got \"c[0]\"",
+				"spec.conversions[2].to: Not found: \"v3\"",
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
+			defer featuregatetesting.SetFeatureGateDuringTest(t, utilfeature.DefaultFeatureGate, genericfeatures.CustomResourceValidationExpressions, tt.celEnabled)()
+
 			o := &apiResourceSchemaValidation{
 				Handler: admission.NewHandler(admission.Create, admission.Update),
 			}
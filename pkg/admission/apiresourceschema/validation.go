@@ -29,8 +29,11 @@ import (
 	"k8s.io/apimachinery/pkg/util/sets"
 	utilvalidation "k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/apimachinery/pkg/util/validation/field"
+	genericfeatures "k8s.io/apiserver/pkg/features"
+	utilfeature "k8s.io/apiserver/pkg/util/feature"
 
 	apisv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apis/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/schemaconversion"
 )
 
 var (
@@ -130,8 +133,58 @@ func ValidateAPIResourceSchemaSpec(spec *apisv1alpha1.APIResourceSchemaSpec, fld
 		allErrs = append(allErrs, crdvalidation.ValidateCustomResourceDefinitionNames(&crdNames, fldPath.Child("names"))...)
 	}
 
+	allErrs = append(allErrs, ValidateAPIResourceConversions(spec.Conversions, versionsMap, fldPath.Child("conversions"))...)
+
 	// TODO(sttts): validate predecessors
-	// TODO(sttts): validate conversions
+
+	return allErrs
+}
+
+// ValidateAPIResourceConversions validates the conversions of an APIResourceSchema against its versions.
+func ValidateAPIResourceConversions(conversions []apisv1alpha1.APIResourceConversion, versions map[string]bool, fldPath *field.Path) field.ErrorList {
+	allErrs := field.ErrorList{}
+
+	seen := sets.NewString()
+	for i, c := range conversions {
+		if !versions[c.From] {
+			allErrs = append(allErrs, field.NotFound(fldPath.Index(i).Child("from"), c.From))
+		}
+		if !versions[c.To] {
+			allErrs = append(allErrs, field.NotFound(fldPath.Index(i).Child("to"), c.To))
+		}
+		if c.From == c.To {
+			allErrs = append(allErrs, field.Invalid(fldPath.Index(i).Child("to"), c.To, "must differ from from"))
+		}
+
+		// conversions are bidirectional, hence there must be at most one for each pair of versions.
+		pair := c.From + "/" + c.To
+		if c.To < c.From {
+			pair = c.To + "/" + c.From
+		}
+		if seen.Has(pair) {
+			allErrs = append(allErrs, field.Duplicate(fldPath.Index(i), pair))
+		}
+		seen.Insert(pair)
+
+		if len(c.Fields) == 0 {
+			allErrs = append(allErrs, field.Required(fldPath.Index(i).Child("fields"), ""))
+		}
+		froms, tos := sets.NewString(), sets.NewString()
+		for j, f := range c.Fields {
+			if _, err := schemaconversion.ParseFieldPath(f.From); err != nil {
+				allErrs = append(allErrs, field.Invalid(fldPath.Index(i).Child("fields").Index(j).Child("from"), f.From, err.Error()))
+			} else if froms.Has(f.From) {
+				allErrs = append(allErrs, field.Duplicate(fldPath.Index(i).Child("fields").Index(j).Child("from"), f.From))
+			}
+			froms.Insert(f.From)
+			if _, err := schemaconversion.ParseFieldPath(f.To); err != nil {
+				allErrs = append(allErrs, field.Invalid(fldPath.Index(i).Child("fields").Index(j).Child("to"), f.To, err.Error()))
+			} else if tos.Has(f.To) {
+				allErrs = append(allErrs, field.Duplicate(fldPath.Index(i).Child("fields").Index(j).Child("to"), f.To))
+			}
+			tos.Insert(f.To)
+		}
+	}
 
 	return allErrs
 }
@@ -171,6 +224,12 @@ func ValidateAPIResourceVersion(version *apisv1alpha1.APIResourceVersion, fldPat
 			allErrs = append(allErrs, field.Invalid(fldPath.Child("schema"), string(version.Schema.Raw), fmt.Sprintf("invalid schema: %v", err)))
 		} else {
 			allErrs = append(allErrs, crdvalidation.ValidateCustomResourceDefinitionValidation(&crdSchemaInternal, statusEnabled, defaultValidationOpts, fldPath.Child("schema"))...)
+
+			// bound CRDs drop x-kubernetes-validations without the feature gate. Fail early instead of silently
+			// serving an API without its validation rules.
+			if !utilfeature.DefaultFeatureGate.Enabled(genericfeatures.CustomResourceValidationExpressions) && schemaHasXValidations(crdSchemaInternal.OpenAPIV3Schema) {
+				allErrs = append(allErrs, field.Forbidden(fldPath.Child("schema"), fmt.Sprintf("x-kubernetes-validations requires the %s feature gate", genericfeatures.CustomResourceValidationExpressions)))
+			}
 		}
 	}
 
@@ -193,6 +252,12 @@ func ValidateAPIResourceVersion(version *apisv1alpha1.APIResourceVersion, fldPat
 	return allErrs
 }
 
+func schemaHasXValidations(s *apiextensionsinternal.JSONSchemaProps) bool {
+	return crdvalidation.SchemaHas(s, func(s *apiextensionsinternal.JSONSchemaProps) bool {
+		return s.XValidations != nil
+	})
+}
+
 // ValidateAPIResourceSchemaUpdate validates an APIResourceSchema on update.
 func ValidateAPIResourceSchemaUpdate(s, old *apisv1alpha1.APIResourceSchema) field.ErrorList {
 	allErrs := ValidateAPIResourceSchema(s)
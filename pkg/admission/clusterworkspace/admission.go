@@ -21,7 +21,9 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"strings"
 
+	"k8s.io/apimachinery/pkg/api/equality"
 	"k8s.io/apimachinery/pkg/api/validation"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -29,12 +31,15 @@ import (
 	"k8s.io/apiserver/pkg/admission"
 
 	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+	tenancyhelper "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1/helper"
 )
 
 // Validate ClusterWorkspace creation and updates for
 // - immutability of fields like type
 // - valid phase transitions fulfilling pre-conditions
-// - status.location.current and status.baseURL cannot be unset.
+// - status.location.current and status.baseURL cannot be unset
+// - initializers are only cleared after their dependencies
+// - initializer labels are only added for initializers of the workspace.
 
 const (
 	PluginName = "tenancy.kcp.dev/ClusterWorkspace"
@@ -81,12 +86,12 @@ func (o *clusterWorkspace) Validate(ctx context.Context, a admission.Attributes,
 		return fmt.Errorf("failed to convert unstructured to ClusterWorkspace: %w", err)
 	}
 
+	old := &tenancyv1alpha1.ClusterWorkspace{}
 	if a.GetOperation() == admission.Update {
 		u, ok = a.GetOldObject().(*unstructured.Unstructured)
 		if !ok {
 			return fmt.Errorf("unexpected type %T", a.GetOldObject())
 		}
-		old := &tenancyv1alpha1.ClusterWorkspace{}
 		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, old); err != nil {
 			return fmt.Errorf("failed to convert unstructured to ClusterWorkspace: %w", err)
 		}
@@ -109,6 +114,23 @@ func (o *clusterWorkspace) Validate(ctx context.Context, a admission.Attributes,
 		if phaseOrdinal[old.Status.Phase] > phaseOrdinal[cw.Status.Phase] {
 			return admission.NewForbidden(a, fmt.Errorf("cannot transition from %q to %q", old.Status.Phase, cw.Status.Phase))
 		}
+
+		if old.Status.Phase == tenancyv1alpha1.ClusterWorkspacePhaseInitializing {
+			if !equality.Semantic.DeepEqual(old.Status.InitializerDependencies, cw.Status.InitializerDependencies) {
+				return admission.NewForbidden(a, errors.New("status.initializerDependencies is immutable"))
+			}
+			if err := validateInitializerOrder(old, cw); err != nil {
+				return admission.NewForbidden(a, err)
+			}
+		}
+	}
+
+	if cw.Spec.TTL != nil && cw.Spec.TTL.Duration <= 0 {
+		return admission.NewForbidden(a, fmt.Errorf("spec.ttl must be positive, got %s", cw.Spec.TTL.Duration))
+	}
+
+	if err := validateInitializerLabels(old, cw); err != nil {
+		return admission.NewForbidden(a, err)
 	}
 
 	if phaseOrdinal[cw.Status.Phase] > phaseOrdinal[tenancyv1alpha1.ClusterWorkspacePhaseInitializing] && len(cw.Status.Initializers) > 0 {
@@ -126,3 +148,34 @@ func (o *clusterWorkspace) Validate(ctx context.Context, a admission.Attributes,
 
 	return nil
 }
+
+// validateInitializerLabels checks that initializer labels are only added for the initializers of the workspace.
+func validateInitializerLabels(old, cw *tenancyv1alpha1.ClusterWorkspace) error {
+	initializerLabels := tenancyhelper.InitializerLabels(cw.Status.Initializers)
+	for key, value := range cw.Labels {
+		if !strings.HasPrefix(key, tenancyv1alpha1.ClusterWorkspaceInitializerLabelPrefix) || old.Labels[key] == value {
+			continue
+		}
+		if initializerLabels[key] != value {
+			return fmt.Errorf("label %s=%s does not belong to an initializer of the workspace", key, value)
+		}
+	}
+	return nil
+}
+
+// validateInitializerOrder checks that the initializers cleared by an update had no pending dependencies.
+func validateInitializerOrder(old, cw *tenancyv1alpha1.ClusterWorkspace) error {
+	current := make(map[tenancyv1alpha1.ClusterWorkspaceInitializer]bool, len(cw.Status.Initializers))
+	for _, initializer := range cw.Status.Initializers {
+		current[initializer] = true
+	}
+	for _, initializer := range old.Status.Initializers {
+		if current[initializer] {
+			continue
+		}
+		if pending := tenancyhelper.PendingInitializerDependencies(cw, initializer); len(pending) > 0 {
+			return fmt.Errorf("initializer %q cannot be cleared before the initializers it depends on: %v", initializer, pending)
+		}
+	}
+	return nil
+}
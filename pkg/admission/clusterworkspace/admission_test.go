@@ -19,6 +19,7 @@ package clusterworkspace
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/kcp-dev/logicalcluster"
 
@@ -29,6 +30,7 @@ import (
 
 	"github.com/kcp-dev/kcp/pkg/admission/helpers"
 	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+	tenancyhelper "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1/helper"
 )
 
 func createAttr(ws *tenancyv1alpha1.ClusterWorkspace) admission.Attributes {
@@ -64,6 +66,10 @@ func updateAttr(ws, old *tenancyv1alpha1.ClusterWorkspace) admission.Attributes
 }
 
 func TestValidate(t *testing.T) {
+	rbacFirst := []tenancyv1alpha1.ClusterWorkspaceInitializerDependency{
+		{Initializer: "bindings", DependsOn: []tenancyv1alpha1.ClusterWorkspaceInitializer{"rbac"}},
+	}
+
 	tests := []struct {
 		name    string
 		a       admission.Attributes
@@ -310,6 +316,277 @@ func TestValidate(t *testing.T) {
 				}),
 			wantErr: true,
 		},
+		{
+			name: "rejects clearing an initializer before its dependencies",
+			a: updateAttr(&tenancyv1alpha1.ClusterWorkspace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test",
+				},
+				Spec: tenancyv1alpha1.ClusterWorkspaceSpec{
+					Type: "Foo",
+				},
+				Status: tenancyv1alpha1.ClusterWorkspaceStatus{
+					Phase:                   tenancyv1alpha1.ClusterWorkspacePhaseInitializing,
+					Initializers:            []tenancyv1alpha1.ClusterWorkspaceInitializer{"rbac"},
+					InitializerDependencies: rbacFirst,
+					Location:                tenancyv1alpha1.ClusterWorkspaceLocation{Current: "somewhere"},
+					BaseURL:                 "https://kcp.bigcorp.com/clusters/org:test",
+				},
+			},
+				&tenancyv1alpha1.ClusterWorkspace{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "test",
+					},
+					Spec: tenancyv1alpha1.ClusterWorkspaceSpec{
+						Type: "Foo",
+					},
+					Status: tenancyv1alpha1.ClusterWorkspaceStatus{
+						Phase:                   tenancyv1alpha1.ClusterWorkspacePhaseInitializing,
+						Initializers:            []tenancyv1alpha1.ClusterWorkspaceInitializer{"rbac", "bindings"},
+						InitializerDependencies: rbacFirst,
+						Location:                tenancyv1alpha1.ClusterWorkspaceLocation{Current: "somewhere"},
+						BaseURL:                 "https://kcp.bigcorp.com/clusters/org:test",
+					},
+				}),
+			wantErr: true,
+		},
+		{
+			name: "allows clearing an initializer after its dependencies",
+			a: updateAttr(&tenancyv1alpha1.ClusterWorkspace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test",
+				},
+				Spec: tenancyv1alpha1.ClusterWorkspaceSpec{
+					Type: "Foo",
+				},
+				Status: tenancyv1alpha1.ClusterWorkspaceStatus{
+					Phase:                   tenancyv1alpha1.ClusterWorkspacePhaseInitializing,
+					Initializers:            []tenancyv1alpha1.ClusterWorkspaceInitializer{"bindings"},
+					InitializerDependencies: rbacFirst,
+					Location:                tenancyv1alpha1.ClusterWorkspaceLocation{Current: "somewhere"},
+					BaseURL:                 "https://kcp.bigcorp.com/clusters/org:test",
+				},
+			},
+				&tenancyv1alpha1.ClusterWorkspace{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "test",
+					},
+					Spec: tenancyv1alpha1.ClusterWorkspaceSpec{
+						Type: "Foo",
+					},
+					Status: tenancyv1alpha1.ClusterWorkspaceStatus{
+						Phase:                   tenancyv1alpha1.ClusterWorkspacePhaseInitializing,
+						Initializers:            []tenancyv1alpha1.ClusterWorkspaceInitializer{"rbac", "bindings"},
+						InitializerDependencies: rbacFirst,
+						Location:                tenancyv1alpha1.ClusterWorkspaceLocation{Current: "somewhere"},
+						BaseURL:                 "https://kcp.bigcorp.com/clusters/org:test",
+					},
+				}),
+			wantErr: false,
+		},
+		{
+			name: "allows clearing an initializer together with its dependencies",
+			a: updateAttr(&tenancyv1alpha1.ClusterWorkspace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test",
+				},
+				Spec: tenancyv1alpha1.ClusterWorkspaceSpec{
+					Type: "Foo",
+				},
+				Status: tenancyv1alpha1.ClusterWorkspaceStatus{
+					Phase:                   tenancyv1alpha1.ClusterWorkspacePhaseInitializing,
+					Initializers:            []tenancyv1alpha1.ClusterWorkspaceInitializer{},
+					InitializerDependencies: rbacFirst,
+					Location:                tenancyv1alpha1.ClusterWorkspaceLocation{Current: "somewhere"},
+					BaseURL:                 "https://kcp.bigcorp.com/clusters/org:test",
+				},
+			},
+				&tenancyv1alpha1.ClusterWorkspace{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "test",
+					},
+					Spec: tenancyv1alpha1.ClusterWorkspaceSpec{
+						Type: "Foo",
+					},
+					Status: tenancyv1alpha1.ClusterWorkspaceStatus{
+						Phase:                   tenancyv1alpha1.ClusterWorkspacePhaseInitializing,
+						Initializers:            []tenancyv1alpha1.ClusterWorkspaceInitializer{"rbac", "bindings"},
+						InitializerDependencies: rbacFirst,
+						Location:                tenancyv1alpha1.ClusterWorkspaceLocation{Current: "somewhere"},
+						BaseURL:                 "https://kcp.bigcorp.com/clusters/org:test",
+					},
+				}),
+			wantErr: false,
+		},
+		{
+			name: "rejects changing initializer dependencies while initializing",
+			a: updateAttr(&tenancyv1alpha1.ClusterWorkspace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test",
+				},
+				Spec: tenancyv1alpha1.ClusterWorkspaceSpec{
+					Type: "Foo",
+				},
+				Status: tenancyv1alpha1.ClusterWorkspaceStatus{
+					Phase:                   tenancyv1alpha1.ClusterWorkspacePhaseInitializing,
+					Initializers:            []tenancyv1alpha1.ClusterWorkspaceInitializer{"rbac", "bindings"},
+					InitializerDependencies: nil,
+					Location:                tenancyv1alpha1.ClusterWorkspaceLocation{Current: "somewhere"},
+					BaseURL:                 "https://kcp.bigcorp.com/clusters/org:test",
+				},
+			},
+				&tenancyv1alpha1.ClusterWorkspace{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "test",
+					},
+					Spec: tenancyv1alpha1.ClusterWorkspaceSpec{
+						Type: "Foo",
+					},
+					Status: tenancyv1alpha1.ClusterWorkspaceStatus{
+						Phase:                   tenancyv1alpha1.ClusterWorkspacePhaseInitializing,
+						Initializers:            []tenancyv1alpha1.ClusterWorkspaceInitializer{"rbac", "bindings"},
+						InitializerDependencies: rbacFirst,
+						Location:                tenancyv1alpha1.ClusterWorkspaceLocation{Current: "somewhere"},
+						BaseURL:                 "https://kcp.bigcorp.com/clusters/org:test",
+					},
+				}),
+			wantErr: true,
+		},
+		{
+			name: "rejects creation with initializer labels",
+			a: createAttr(&tenancyv1alpha1.ClusterWorkspace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   "test",
+					Labels: tenancyhelper.InitializerLabels([]tenancyv1alpha1.ClusterWorkspaceInitializer{"rbac"}),
+				},
+				Spec: tenancyv1alpha1.ClusterWorkspaceSpec{
+					Type: "Foo",
+				},
+			}),
+			wantErr: true,
+		},
+		{
+			name: "allows adding the labels of initializers of the workspace",
+			a: updateAttr(&tenancyv1alpha1.ClusterWorkspace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   "test",
+					Labels: tenancyhelper.InitializerLabels([]tenancyv1alpha1.ClusterWorkspaceInitializer{"rbac"}),
+				},
+				Spec: tenancyv1alpha1.ClusterWorkspaceSpec{
+					Type: "Foo",
+				},
+				Status: tenancyv1alpha1.ClusterWorkspaceStatus{
+					Phase:        tenancyv1alpha1.ClusterWorkspacePhaseInitializing,
+					Initializers: []tenancyv1alpha1.ClusterWorkspaceInitializer{"rbac"},
+					Location:     tenancyv1alpha1.ClusterWorkspaceLocation{Current: "somewhere"},
+					BaseURL:      "https://kcp.bigcorp.com/clusters/org:test",
+				},
+			},
+				&tenancyv1alpha1.ClusterWorkspace{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "test",
+					},
+					Spec: tenancyv1alpha1.ClusterWorkspaceSpec{
+						Type: "Foo",
+					},
+					Status: tenancyv1alpha1.ClusterWorkspaceStatus{
+						Phase:        tenancyv1alpha1.ClusterWorkspacePhaseInitializing,
+						Initializers: []tenancyv1alpha1.ClusterWorkspaceInitializer{"rbac"},
+						Location:     tenancyv1alpha1.ClusterWorkspaceLocation{Current: "somewhere"},
+						BaseURL:      "https://kcp.bigcorp.com/clusters/org:test",
+					},
+				}),
+		},
+		{
+			name: "rejects adding the labels of other initializers",
+			a: updateAttr(&tenancyv1alpha1.ClusterWorkspace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   "test",
+					Labels: tenancyhelper.InitializerLabels([]tenancyv1alpha1.ClusterWorkspaceInitializer{"bindings"}),
+				},
+				Spec: tenancyv1alpha1.ClusterWorkspaceSpec{
+					Type: "Foo",
+				},
+				Status: tenancyv1alpha1.ClusterWorkspaceStatus{
+					Phase:        tenancyv1alpha1.ClusterWorkspacePhaseInitializing,
+					Initializers: []tenancyv1alpha1.ClusterWorkspaceInitializer{"rbac"},
+					Location:     tenancyv1alpha1.ClusterWorkspaceLocation{Current: "somewhere"},
+					BaseURL:      "https://kcp.bigcorp.com/clusters/org:test",
+				},
+			},
+				&tenancyv1alpha1.ClusterWorkspace{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "test",
+					},
+					Spec: tenancyv1alpha1.ClusterWorkspaceSpec{
+						Type: "Foo",
+					},
+					Status: tenancyv1alpha1.ClusterWorkspaceStatus{
+						Phase:        tenancyv1alpha1.ClusterWorkspacePhaseInitializing,
+						Initializers: []tenancyv1alpha1.ClusterWorkspaceInitializer{"rbac"},
+						Location:     tenancyv1alpha1.ClusterWorkspaceLocation{Current: "somewhere"},
+						BaseURL:      "https://kcp.bigcorp.com/clusters/org:test",
+					},
+				}),
+			wantErr: true,
+		},
+		{
+			name: "allows keeping the labels of cleared initializers",
+			a: updateAttr(&tenancyv1alpha1.ClusterWorkspace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:   "test",
+					Labels: tenancyhelper.InitializerLabels([]tenancyv1alpha1.ClusterWorkspaceInitializer{"rbac"}),
+				},
+				Spec: tenancyv1alpha1.ClusterWorkspaceSpec{
+					Type: "Foo",
+				},
+				Status: tenancyv1alpha1.ClusterWorkspaceStatus{
+					Phase:    tenancyv1alpha1.ClusterWorkspacePhaseInitializing,
+					Location: tenancyv1alpha1.ClusterWorkspaceLocation{Current: "somewhere"},
+					BaseURL:  "https://kcp.bigcorp.com/clusters/org:test",
+				},
+			},
+				&tenancyv1alpha1.ClusterWorkspace{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:   "test",
+						Labels: tenancyhelper.InitializerLabels([]tenancyv1alpha1.ClusterWorkspaceInitializer{"rbac"}),
+					},
+					Spec: tenancyv1alpha1.ClusterWorkspaceSpec{
+						Type: "Foo",
+					},
+					Status: tenancyv1alpha1.ClusterWorkspaceStatus{
+						Phase:        tenancyv1alpha1.ClusterWorkspacePhaseInitializing,
+						Initializers: []tenancyv1alpha1.ClusterWorkspaceInitializer{"rbac"},
+						Location:     tenancyv1alpha1.ClusterWorkspaceLocation{Current: "somewhere"},
+						BaseURL:      "https://kcp.bigcorp.com/clusters/org:test",
+					},
+				}),
+		},
+		{
+			name: "allows creation with a ttl",
+			a: createAttr(&tenancyv1alpha1.ClusterWorkspace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test",
+				},
+				Spec: tenancyv1alpha1.ClusterWorkspaceSpec{
+					Type: "Foo",
+					TTL:  &metav1.Duration{Duration: time.Hour},
+				},
+			}),
+		},
+		{
+			name: "rejects a non-positive ttl",
+			a: createAttr(&tenancyv1alpha1.ClusterWorkspace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test",
+				},
+				Spec: tenancyv1alpha1.ClusterWorkspaceSpec{
+					Type: "Foo",
+					TTL:  &metav1.Duration{},
+				},
+			}),
+			wantErr: true,
+		},
 		{
 			name: "ignores different resources",
 			a: admission.NewAttributesRecord(
@@ -21,6 +21,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"net/url"
 
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -61,8 +62,10 @@ var _ = admission.MutationInterface(&clusterWorkspaceShard{})
 var _ = initializers.WantsExternalAddressProvider(&clusterWorkspaceShard{})
 
 // Validate ensures that
-// - baseURL is set
-// - externalURL is set
+// - baseURL is set and a valid URL
+// - externalURL is set and a valid URL
+// - virtualWorkspaceURL is a valid URL if set
+// - externalURL is only changed when acknowledged by the ExperimentalMigrateExternalURLAnnotationKey annotation.
 func (o *clusterWorkspaceShard) Validate(ctx context.Context, a admission.Attributes, _ admission.ObjectInterfaces) (err error) {
 	if a.GetResource().GroupResource() != tenancyv1alpha1.Resource("clusterworkspaceshards") {
 		return nil
@@ -83,11 +86,59 @@ func (o *clusterWorkspaceShard) Validate(ctx context.Context, a admission.Attrib
 	if cws.Spec.ExternalURL == "" {
 		return admission.NewForbidden(a, errors.New("spec.externalURL must be set"))
 	}
+	if err := validateURL(cws.Spec.BaseURL); err != nil {
+		return admission.NewForbidden(a, fmt.Errorf("spec.baseURL is invalid: %w", err))
+	}
+	if err := validateURL(cws.Spec.ExternalURL); err != nil {
+		return admission.NewForbidden(a, fmt.Errorf("spec.externalURL is invalid: %w", err))
+	}
+	if cws.Spec.VirtualWorkspaceURL != "" {
+		if err := validateURL(cws.Spec.VirtualWorkspaceURL); err != nil {
+			return admission.NewForbidden(a, fmt.Errorf("spec.virtualWorkspaceURL is invalid: %w", err))
+		}
+	}
+
+	if a.GetOperation() == admission.Update {
+		u, ok := a.GetOldObject().(*unstructured.Unstructured)
+		if !ok {
+			return fmt.Errorf("unexpected type %T", a.GetOldObject())
+		}
+		old := &tenancyv1alpha1.ClusterWorkspaceShard{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, old); err != nil {
+			return fmt.Errorf("failed to convert unstructured to ClusterWorkspaceShard: %w", err)
+		}
+
+		// changing the external URL breaks the URLs of existing workspaces. Hence, ask for explicit
+		// acknowledgement naming the URL migrated away from.
+		if old.Spec.ExternalURL != "" && old.Spec.ExternalURL != cws.Spec.ExternalURL && cws.Annotations[tenancyv1alpha1.ExperimentalMigrateExternalURLAnnotationKey] != old.Spec.ExternalURL {
+			return admission.NewForbidden(a, fmt.Errorf("spec.externalURL cannot be changed from %q without the %s=%q annotation", old.Spec.ExternalURL, tenancyv1alpha1.ExperimentalMigrateExternalURLAnnotationKey, old.Spec.ExternalURL))
+		}
+	}
 
 	return nil
 }
 
-// Admit defaults the baseURL and externalURL to the shards external hostname.
+// validateURL checks that the given string is an absolute http or https URL without
+// query or fragment, such that workspace paths can be appended.
+func validateURL(s string) error {
+	u, err := url.Parse(s)
+	if err != nil {
+		return err
+	}
+	if u.Scheme != "https" && u.Scheme != "http" {
+		return fmt.Errorf("scheme must be https or http, got %q", u.Scheme)
+	}
+	if u.Host == "" {
+		return errors.New("host must be set")
+	}
+	if u.RawQuery != "" || u.Fragment != "" {
+		return errors.New("query and fragment are not allowed")
+	}
+	return nil
+}
+
+// Admit defaults the baseURL to the shards external hostname, and the externalURL and
+// virtualWorkspaceURL to the baseURL.
 func (o *clusterWorkspaceShard) Admit(ctx context.Context, a admission.Attributes, _ admission.ObjectInterfaces) (err error) {
 	if a.GetResource().GroupResource() != tenancyv1alpha1.Resource("clusterworkspaceshards") {
 		return nil
@@ -115,6 +166,9 @@ func (o *clusterWorkspaceShard) Admit(ctx context.Context, a admission.Attribute
 	if cws.Spec.ExternalURL == "" {
 		cws.Spec.ExternalURL = cws.Spec.BaseURL
 	}
+	if cws.Spec.VirtualWorkspaceURL == "" {
+		cws.Spec.VirtualWorkspaceURL = cws.Spec.BaseURL
+	}
 
 	raw, err := runtime.DefaultUnstructuredConverter.ToUnstructured(cws)
 	if err != nil {
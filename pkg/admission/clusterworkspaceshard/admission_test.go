@@ -102,8 +102,9 @@ func TestAdmit(t *testing.T) {
 					Name: "test",
 				},
 				Spec: tenancyv1alpha1.ClusterWorkspaceShardSpec{
-					BaseURL:     "https://boston2.kcp.dev",
-					ExternalURL: "https://kcp2.dev",
+					BaseURL:             "https://boston2.kcp.dev",
+					ExternalURL:         "https://kcp2.dev",
+					VirtualWorkspaceURL: "https://boston2.kcp.dev",
 				},
 			},
 		},
@@ -123,8 +124,32 @@ func TestAdmit(t *testing.T) {
 					Name: "test",
 				},
 				Spec: tenancyv1alpha1.ClusterWorkspaceShardSpec{
-					BaseURL:     "https://boston2.kcp.dev",
-					ExternalURL: "https://kcp2.dev",
+					BaseURL:             "https://boston2.kcp.dev",
+					ExternalURL:         "https://kcp2.dev",
+					VirtualWorkspaceURL: "https://boston2.kcp.dev",
+				},
+			},
+		},
+		{
+			name: "does nothing on create when virtualWorkspaceURL is set",
+			a: createAttr(&tenancyv1alpha1.ClusterWorkspaceShard{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test",
+				},
+				Spec: tenancyv1alpha1.ClusterWorkspaceShardSpec{
+					BaseURL:             "https://boston2.kcp.dev",
+					ExternalURL:         "https://kcp2.dev",
+					VirtualWorkspaceURL: "https://virtual.kcp2.dev",
+				},
+			}),
+			expectedObj: &tenancyv1alpha1.ClusterWorkspaceShard{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test",
+				},
+				Spec: tenancyv1alpha1.ClusterWorkspaceShardSpec{
+					BaseURL:             "https://boston2.kcp.dev",
+					ExternalURL:         "https://kcp2.dev",
+					VirtualWorkspaceURL: "https://virtual.kcp2.dev",
 				},
 			},
 		},
@@ -152,8 +177,9 @@ func TestAdmit(t *testing.T) {
 					Name: "test",
 				},
 				Spec: tenancyv1alpha1.ClusterWorkspaceShardSpec{
-					BaseURL:     "https://boston2.kcp.dev",
-					ExternalURL: "https://boston2.kcp.dev",
+					BaseURL:             "https://boston2.kcp.dev",
+					ExternalURL:         "https://boston2.kcp.dev",
+					VirtualWorkspaceURL: "https://boston2.kcp.dev",
 				},
 			},
 		},
@@ -172,8 +198,9 @@ func TestAdmit(t *testing.T) {
 					Name: "test",
 				},
 				Spec: tenancyv1alpha1.ClusterWorkspaceShardSpec{
-					BaseURL:     "https://boston2.kcp.dev",
-					ExternalURL: "https://boston2.kcp.dev",
+					BaseURL:             "https://boston2.kcp.dev",
+					ExternalURL:         "https://boston2.kcp.dev",
+					VirtualWorkspaceURL: "https://boston2.kcp.dev",
 				},
 			},
 		},
@@ -201,8 +228,9 @@ func TestAdmit(t *testing.T) {
 					Name: "test",
 				},
 				Spec: tenancyv1alpha1.ClusterWorkspaceShardSpec{
-					BaseURL:     "https://external.kcp.dev",
-					ExternalURL: "https://kcp.dev",
+					BaseURL:             "https://external.kcp.dev",
+					ExternalURL:         "https://kcp.dev",
+					VirtualWorkspaceURL: "https://external.kcp.dev",
 				},
 			},
 		},
@@ -221,8 +249,9 @@ func TestAdmit(t *testing.T) {
 					Name: "test",
 				},
 				Spec: tenancyv1alpha1.ClusterWorkspaceShardSpec{
-					BaseURL:     "https://external.kcp.dev",
-					ExternalURL: "https://kcp.dev",
+					BaseURL:             "https://external.kcp.dev",
+					ExternalURL:         "https://kcp.dev",
+					VirtualWorkspaceURL: "https://external.kcp.dev",
 				},
 			},
 		},
@@ -248,8 +277,9 @@ func TestAdmit(t *testing.T) {
 					Name: "test",
 				},
 				Spec: tenancyv1alpha1.ClusterWorkspaceShardSpec{
-					BaseURL:     "https://external.kcp.dev",
-					ExternalURL: "https://external.kcp.dev",
+					BaseURL:             "https://external.kcp.dev",
+					ExternalURL:         "https://external.kcp.dev",
+					VirtualWorkspaceURL: "https://external.kcp.dev",
 				},
 			},
 		},
@@ -266,8 +296,9 @@ func TestAdmit(t *testing.T) {
 					Name: "test",
 				},
 				Spec: tenancyv1alpha1.ClusterWorkspaceShardSpec{
-					BaseURL:     "https://external.kcp.dev",
-					ExternalURL: "https://external.kcp.dev",
+					BaseURL:             "https://external.kcp.dev",
+					ExternalURL:         "https://external.kcp.dev",
+					VirtualWorkspaceURL: "https://external.kcp.dev",
 				},
 			},
 		},
@@ -323,8 +354,9 @@ func TestAdmit(t *testing.T) {
 					Name: "test",
 				},
 				Spec: tenancyv1alpha1.ClusterWorkspaceShardSpec{
-					BaseURL:     "https://boston2.kcp.dev",
-					ExternalURL: "https://boston2.kcp.dev",
+					BaseURL:             "https://boston2.kcp.dev",
+					ExternalURL:         "https://boston2.kcp.dev",
+					VirtualWorkspaceURL: "https://boston2.kcp.dev",
 				},
 			},
 		},
@@ -452,6 +484,33 @@ func TestValidate(t *testing.T) {
 				},
 			}),
 		},
+		{
+			name: "accept virtualWorkspaceURL on create",
+			a: createAttr(&tenancyv1alpha1.ClusterWorkspaceShard{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test",
+				},
+				Spec: tenancyv1alpha1.ClusterWorkspaceShardSpec{
+					BaseURL:             "https://kcp",
+					ExternalURL:         "https://kcp",
+					VirtualWorkspaceURL: "https://virtual.kcp",
+				},
+			}),
+		},
+		{
+			name: "reject invalid virtualWorkspaceURL on create",
+			a: createAttr(&tenancyv1alpha1.ClusterWorkspaceShard{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test",
+				},
+				Spec: tenancyv1alpha1.ClusterWorkspaceShardSpec{
+					BaseURL:             "https://kcp",
+					ExternalURL:         "https://kcp",
+					VirtualWorkspaceURL: "virtual.kcp",
+				},
+			}),
+			wantErr: true,
+		},
 		{
 			name: "reject empty baseURL on update",
 			a: updateAttr(&tenancyv1alpha1.ClusterWorkspaceShard{
@@ -518,6 +577,124 @@ func TestValidate(t *testing.T) {
 			}),
 			wantErr: true,
 		},
+		{
+			name: "reject invalid baseURL",
+			a: createAttr(&tenancyv1alpha1.ClusterWorkspaceShard{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test",
+				},
+				Spec: tenancyv1alpha1.ClusterWorkspaceShardSpec{
+					BaseURL:     "kcp:6443",
+					ExternalURL: "https://kcp",
+				},
+			}),
+			wantErr: true,
+		},
+		{
+			name: "reject externalURL with query",
+			a: createAttr(&tenancyv1alpha1.ClusterWorkspaceShard{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test",
+				},
+				Spec: tenancyv1alpha1.ClusterWorkspaceShardSpec{
+					BaseURL:     "https://kcp",
+					ExternalURL: "https://kcp?foo=bar",
+				},
+			}),
+			wantErr: true,
+		},
+		{
+			name: "accept changed baseURL on update",
+			a: updateAttr(&tenancyv1alpha1.ClusterWorkspaceShard{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test",
+				},
+				Spec: tenancyv1alpha1.ClusterWorkspaceShardSpec{
+					BaseURL:     "https://kcp2",
+					ExternalURL: "https://kcp",
+				},
+			},
+				&tenancyv1alpha1.ClusterWorkspaceShard{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "test",
+					},
+					Spec: tenancyv1alpha1.ClusterWorkspaceShardSpec{
+						BaseURL:     "https://kcp",
+						ExternalURL: "https://kcp",
+					},
+				}),
+		},
+		{
+			name: "reject changed externalURL without annotation on update",
+			a: updateAttr(&tenancyv1alpha1.ClusterWorkspaceShard{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test",
+				},
+				Spec: tenancyv1alpha1.ClusterWorkspaceShardSpec{
+					BaseURL:     "https://kcp",
+					ExternalURL: "https://kcp.example.com",
+				},
+			},
+				&tenancyv1alpha1.ClusterWorkspaceShard{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "test",
+					},
+					Spec: tenancyv1alpha1.ClusterWorkspaceShardSpec{
+						BaseURL:     "https://kcp",
+						ExternalURL: "https://kcp",
+					},
+				}),
+			wantErr: true,
+		},
+		{
+			name: "reject changed externalURL with stale annotation on update",
+			a: updateAttr(&tenancyv1alpha1.ClusterWorkspaceShard{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test",
+					Annotations: map[string]string{
+						tenancyv1alpha1.ExperimentalMigrateExternalURLAnnotationKey: "https://kcp.old",
+					},
+				},
+				Spec: tenancyv1alpha1.ClusterWorkspaceShardSpec{
+					BaseURL:     "https://kcp",
+					ExternalURL: "https://kcp.example.com",
+				},
+			},
+				&tenancyv1alpha1.ClusterWorkspaceShard{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "test",
+					},
+					Spec: tenancyv1alpha1.ClusterWorkspaceShardSpec{
+						BaseURL:     "https://kcp",
+						ExternalURL: "https://kcp",
+					},
+				}),
+			wantErr: true,
+		},
+		{
+			name: "accept changed externalURL with annotation on update",
+			a: updateAttr(&tenancyv1alpha1.ClusterWorkspaceShard{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test",
+					Annotations: map[string]string{
+						tenancyv1alpha1.ExperimentalMigrateExternalURLAnnotationKey: "https://kcp",
+					},
+				},
+				Spec: tenancyv1alpha1.ClusterWorkspaceShardSpec{
+					BaseURL:     "https://kcp",
+					ExternalURL: "https://kcp.example.com",
+				},
+			},
+				&tenancyv1alpha1.ClusterWorkspaceShard{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "test",
+					},
+					Spec: tenancyv1alpha1.ClusterWorkspaceShardSpec{
+						BaseURL:     "https://kcp",
+						ExternalURL: "https://kcp",
+					},
+				}),
+		},
 		{
 			name: "ignores different resources",
 			a: admission.NewAttributesRecord(
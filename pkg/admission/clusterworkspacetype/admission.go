@@ -29,10 +29,12 @@ import (
 	genericapirequest "k8s.io/apiserver/pkg/endpoints/request"
 
 	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+	tenancyhelper "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1/helper"
 )
 
 // Validate ClusterWorkspaceTypes creation and updates for
-//  - "organization" type is only created in root workspace.
+//  - "organization" type is only created in root workspace,
+//  - initializer dependencies refer to initializers of the type and do not form a cycle.
 
 const (
 	PluginName = "tenancy.kcp.dev/ClusterWorkspaceType"
@@ -76,5 +78,9 @@ func (o *clusterWorkspaceType) Validate(ctx context.Context, a admission.Attribu
 		return errors.New("organization type can only be created in root workspace")
 	}
 
+	if err := tenancyhelper.ValidateInitializerDependencies(cwt.Spec.Initializers, cwt.Spec.InitializerDependencies); err != nil {
+		return admission.NewForbidden(a, err)
+	}
+
 	return nil
 }
@@ -101,6 +101,56 @@ func TestValidate(t *testing.T) {
 			clusterName: logicalcluster.New("foo:bar"),
 			wantErr:     true,
 		},
+		{
+			name: "allow initializer dependencies",
+			a: createAttr(&tenancyv1alpha1.ClusterWorkspaceType{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test",
+				},
+				Spec: tenancyv1alpha1.ClusterWorkspaceTypeSpec{
+					Initializers: []tenancyv1alpha1.ClusterWorkspaceInitializer{"rbac", "bindings"},
+					InitializerDependencies: []tenancyv1alpha1.ClusterWorkspaceInitializerDependency{
+						{Initializer: "bindings", DependsOn: []tenancyv1alpha1.ClusterWorkspaceInitializer{"rbac"}},
+					},
+				},
+			}),
+			clusterName: logicalcluster.New("foo:bar"),
+			wantErr:     false,
+		},
+		{
+			name: "deny dependency on unknown initializer",
+			a: createAttr(&tenancyv1alpha1.ClusterWorkspaceType{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test",
+				},
+				Spec: tenancyv1alpha1.ClusterWorkspaceTypeSpec{
+					Initializers: []tenancyv1alpha1.ClusterWorkspaceInitializer{"bindings"},
+					InitializerDependencies: []tenancyv1alpha1.ClusterWorkspaceInitializerDependency{
+						{Initializer: "bindings", DependsOn: []tenancyv1alpha1.ClusterWorkspaceInitializer{"rbac"}},
+					},
+				},
+			}),
+			clusterName: logicalcluster.New("foo:bar"),
+			wantErr:     true,
+		},
+		{
+			name: "deny initializer dependency cycle",
+			a: createAttr(&tenancyv1alpha1.ClusterWorkspaceType{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test",
+				},
+				Spec: tenancyv1alpha1.ClusterWorkspaceTypeSpec{
+					Initializers: []tenancyv1alpha1.ClusterWorkspaceInitializer{"a", "b", "c"},
+					InitializerDependencies: []tenancyv1alpha1.ClusterWorkspaceInitializerDependency{
+						{Initializer: "a", DependsOn: []tenancyv1alpha1.ClusterWorkspaceInitializer{"c"}},
+						{Initializer: "b", DependsOn: []tenancyv1alpha1.ClusterWorkspaceInitializer{"a"}},
+						{Initializer: "c", DependsOn: []tenancyv1alpha1.ClusterWorkspaceInitializer{"b"}},
+					},
+				},
+			}),
+			clusterName: logicalcluster.New("foo:bar"),
+			wantErr:     true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
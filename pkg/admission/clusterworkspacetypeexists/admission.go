@@ -37,7 +37,9 @@ import (
 
 	kcpinitializers "github.com/kcp-dev/kcp/pkg/admission/initializers"
 	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+	tenancyhelper "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1/helper"
 	"github.com/kcp-dev/kcp/pkg/authorization/delegated"
+	cacheclient "github.com/kcp-dev/kcp/pkg/cache/client"
 	kcpinformers "github.com/kcp-dev/kcp/pkg/client/informers/externalversions"
 	tenancyv1alpha1lister "github.com/kcp-dev/kcp/pkg/client/listers/tenancy/v1alpha1"
 )
@@ -58,12 +60,18 @@ func Register(plugins *admission.Plugins) {
 
 // clusterWorkspaceTypeExists  does the following
 // - it checks existence of ClusterWorkspaceType in the same workspace,
-// - it applies the ClusterWorkspaceType initializers to the ClusterWorkspace when it
-//   transitions to the Initializing state.
+// - it applies the ClusterWorkspaceType initializers and their dependencies to the
+//   ClusterWorkspace when it transitions to the Initializing state,
+// - it checks the allowed child and parent workspace types of the ClusterWorkspaceTypes
+//   of the new workspace and of its parent workspace on creation.
 type clusterWorkspaceTypeExists struct {
 	*admission.Handler
 	typeLister        tenancyv1alpha1lister.ClusterWorkspaceTypeLister
+	workspaceLister   tenancyv1alpha1lister.ClusterWorkspaceLister
 	kubeClusterClient *kubernetes.Cluster
+	// getCachedType resolves ClusterWorkspaceTypes not known to the local informers, e.g.
+	// because they live on another shard.
+	getCachedType func(ctx context.Context, clusterName logicalcluster.Name, name string) (*tenancyv1alpha1.ClusterWorkspaceType, error)
 
 	createAuthorizer delegated.DelegatedAuthorizerFactory
 }
@@ -74,6 +82,7 @@ var _ = admission.ValidationInterface(&clusterWorkspaceTypeExists{})
 var _ = admission.InitializationValidator(&clusterWorkspaceTypeExists{})
 var _ = kcpinitializers.WantsKcpInformers(&clusterWorkspaceTypeExists{})
 var _ = kcpinitializers.WantsKubeClusterClient(&clusterWorkspaceTypeExists{})
+var _ = kcpinitializers.WantsCacheClient(&clusterWorkspaceTypeExists{})
 
 // Admit adds type initializer on transition to initializing phase.
 func (o *clusterWorkspaceTypeExists) Admit(ctx context.Context, a admission.Attributes, _ admission.ObjectInterfaces) (err error) {
@@ -102,7 +111,7 @@ func (o *clusterWorkspaceTypeExists) Admit(ctx context.Context, a admission.Attr
 		return apierrors.NewInternalError(err)
 	}
 
-	cwt, err := o.typeLister.Get(clusters.ToClusterAwareKey(clusterName, strings.ToLower(cw.Spec.Type)))
+	cwt, err := o.getType(ctx, clusterName, strings.ToLower(cw.Spec.Type))
 	if err != nil && apierrors.IsNotFound(err) {
 		if cw.Spec.Type == "Universal" {
 			return nil // Universal is always valid
@@ -114,6 +123,7 @@ func (o *clusterWorkspaceTypeExists) Admit(ctx context.Context, a admission.Attr
 
 	if a.GetOperation() == admission.Create {
 		addAdditionalWorkspaceLabels(cwt, cw)
+		addDefaultExpiration(cwt, cw)
 
 		return updateUnstructured(u, cw)
 	}
@@ -142,16 +152,24 @@ func (o *clusterWorkspaceTypeExists) Admit(ctx context.Context, a admission.Attr
 		return nil
 	}
 
-	// add initializers from type to workspace
+	// add initializers from type to workspace, dependencies first
+	initializers, err := tenancyhelper.SortInitializers(cwt.Spec.Initializers, cwt.Spec.InitializerDependencies)
+	if err != nil {
+		return admission.NewForbidden(a, fmt.Errorf("invalid initializers of type %q: %w", cw.Spec.Type, err))
+	}
 	existing := sets.NewString()
 	for _, i := range cw.Status.Initializers {
 		existing.Insert(string(i))
 	}
-	for _, i := range cwt.Spec.Initializers {
+	for _, i := range initializers {
 		if !existing.Has(string(i)) {
 			cw.Status.Initializers = append(cw.Status.Initializers, i)
 		}
 	}
+	cw.Status.InitializerDependencies = nil
+	for _, d := range cwt.Spec.InitializerDependencies {
+		cw.Status.InitializerDependencies = append(cw.Status.InitializerDependencies, *d.DeepCopy())
+	}
 
 	return updateUnstructured(u, cw)
 }
@@ -219,15 +237,25 @@ func (o *clusterWorkspaceTypeExists) Validate(ctx context.Context, a admission.A
 			return apierrors.NewInternalError(err)
 		}
 
-		cwt, err = o.typeLister.Get(clusters.ToClusterAwareKey(clusterName, strings.ToLower(cw.Spec.Type)))
+		cwt, err = o.getType(ctx, clusterName, strings.ToLower(cw.Spec.Type))
 		if err != nil && apierrors.IsNotFound(err) {
-			if cw.Spec.Type == "Universal" {
-				return nil // Universal is always valid
+			if cw.Spec.Type != "Universal" {
+				return admission.NewForbidden(a, fmt.Errorf("spec.type %q does not exist", cw.Spec.Type))
 			}
-			return admission.NewForbidden(a, fmt.Errorf("spec.type %q does not exist", cw.Spec.Type))
+			cwt = nil // Universal is always valid
 		} else if err != nil {
 			return admission.NewForbidden(a, err)
 		}
+
+		if a.GetOperation() == admission.Create {
+			if err := o.validateTypeConstraints(ctx, clusterName, cw, cwt); err != nil {
+				return admission.NewForbidden(a, err)
+			}
+		}
+
+		if cwt == nil {
+			return nil
+		}
 	}
 
 	// add initializers from type to workspace
@@ -271,22 +299,98 @@ func (o *clusterWorkspaceTypeExists) Validate(ctx context.Context, a admission.A
 	return nil
 }
 
+// validateTypeConstraints checks that the type of the given workspace, created in the given logical
+// cluster, is allowed as a child of the parent workspace's type, and that the parent workspace's type is
+// allowed as a parent of the given type. The given type is nil if it does not exist (Universal).
+func (o *clusterWorkspaceTypeExists) validateTypeConstraints(ctx context.Context, clusterName logicalcluster.Name, cw *tenancyv1alpha1.ClusterWorkspace, cwt *tenancyv1alpha1.ClusterWorkspaceType) error {
+	parentType, parentCwt, err := o.resolveParentType(ctx, clusterName)
+	if err != nil {
+		return err
+	}
+	childType := tenancyv1alpha1.ClusterWorkspaceTypeName(cw.Spec.Type)
+
+	if parentCwt != nil && len(parentCwt.Spec.AllowedChildWorkspaceTypes) > 0 && !hasTypeName(parentCwt.Spec.AllowedChildWorkspaceTypes, childType) {
+		return fmt.Errorf("workspace %q of type %q only allows child workspaces of types %v, not %q; use one of the allowed types or a different parent workspace",
+			clusterName, parentType, parentCwt.Spec.AllowedChildWorkspaceTypes, childType)
+	}
+	if cwt != nil && len(cwt.Spec.AllowedParentWorkspaceTypes) > 0 && !hasTypeName(cwt.Spec.AllowedParentWorkspaceTypes, parentType) {
+		return fmt.Errorf("workspaces of type %q can only be created in workspaces of types %v, but workspace %q is of type %q",
+			childType, cwt.Spec.AllowedParentWorkspaceTypes, clusterName, parentType)
+	}
+	return nil
+}
+
+// resolveParentType returns the type of the workspace of the given logical cluster, and its
+// ClusterWorkspaceType, which lives in the grandparent workspace. The latter is nil for the root
+// workspace and for Universal workspaces without a ClusterWorkspaceType.
+func (o *clusterWorkspaceTypeExists) resolveParentType(ctx context.Context, clusterName logicalcluster.Name) (tenancyv1alpha1.ClusterWorkspaceTypeName, *tenancyv1alpha1.ClusterWorkspaceType, error) {
+	if clusterName == tenancyv1alpha1.RootCluster {
+		return tenancyv1alpha1.RootWorkspaceTypeName, nil, nil
+	}
+
+	grandparent, name := clusterName.Split()
+	parent, err := o.workspaceLister.Get(clusters.ToClusterAwareKey(grandparent, name))
+	if err != nil {
+		return "", nil, fmt.Errorf("unable to determine the type of workspace %q: %w", clusterName, err)
+	}
+	parentType := tenancyv1alpha1.ClusterWorkspaceTypeName(parent.Spec.Type)
+
+	parentCwt, err := o.getType(ctx, grandparent, strings.ToLower(parent.Spec.Type))
+	if apierrors.IsNotFound(err) {
+		return parentType, nil, nil
+	} else if err != nil {
+		return "", nil, fmt.Errorf("unable to get cluster workspace type %q of workspace %q: %w", parentType, clusterName, err)
+	}
+	return parentType, parentCwt, nil
+}
+
+// getType returns the ClusterWorkspaceType with the given name in the given workspace. Types not
+// known to the local informers are resolved through the cache client, if there is one.
+func (o *clusterWorkspaceTypeExists) getType(ctx context.Context, clusterName logicalcluster.Name, name string) (*tenancyv1alpha1.ClusterWorkspaceType, error) {
+	cwt, err := o.typeLister.Get(clusters.ToClusterAwareKey(clusterName, name))
+	if apierrors.IsNotFound(err) && o.getCachedType != nil {
+		return o.getCachedType(ctx, clusterName, name)
+	}
+	return cwt, err
+}
+
+func hasTypeName(names []tenancyv1alpha1.ClusterWorkspaceTypeName, name tenancyv1alpha1.ClusterWorkspaceTypeName) bool {
+	for _, n := range names {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
 func (o *clusterWorkspaceTypeExists) ValidateInitialization() error {
 	if o.typeLister == nil {
 		return fmt.Errorf(PluginName + " plugin needs an ClusterWorkspaceType lister")
 	}
+	if o.workspaceLister == nil {
+		return fmt.Errorf(PluginName + " plugin needs an ClusterWorkspace lister")
+	}
 	return nil
 }
 
 func (o *clusterWorkspaceTypeExists) SetKcpInformers(informers kcpinformers.SharedInformerFactory) {
-	o.SetReadyFunc(informers.Tenancy().V1alpha1().ClusterWorkspaceTypes().Informer().HasSynced)
+	typesSynced := informers.Tenancy().V1alpha1().ClusterWorkspaceTypes().Informer().HasSynced
+	workspacesSynced := informers.Tenancy().V1alpha1().ClusterWorkspaces().Informer().HasSynced
+	o.SetReadyFunc(func() bool {
+		return typesSynced() && workspacesSynced()
+	})
 	o.typeLister = informers.Tenancy().V1alpha1().ClusterWorkspaceTypes().Lister()
+	o.workspaceLister = informers.Tenancy().V1alpha1().ClusterWorkspaces().Lister()
 }
 
 func (o *clusterWorkspaceTypeExists) SetKubeClusterClient(kubeClusterClient *kubernetes.Cluster) {
 	o.kubeClusterClient = kubeClusterClient
 }
 
+func (o *clusterWorkspaceTypeExists) SetCacheClient(cacheClient *cacheclient.Client) {
+	o.getCachedType = cacheClient.GetClusterWorkspaceType
+}
+
 // updateUnstructured updates the given unstructured object to match the given cluster workspace.
 func updateUnstructured(u *unstructured.Unstructured, cw *tenancyv1alpha1.ClusterWorkspace) error {
 	raw, err := runtime.DefaultUnstructuredConverter.ToUnstructured(cw)
@@ -316,3 +420,18 @@ func addAdditionalWorkspaceLabels(
 		}
 	}
 }
+
+// addDefaultExpiration sets the default ttl and expiration action of the workspace
+// type on the workspace if they are not set.
+func addDefaultExpiration(
+	cwt *tenancyv1alpha1.ClusterWorkspaceType,
+	cw *tenancyv1alpha1.ClusterWorkspace,
+) {
+	if cw.Spec.TTL == nil && cwt.Spec.DefaultTTL != nil {
+		ttl := *cwt.Spec.DefaultTTL
+		cw.Spec.TTL = &ttl
+	}
+	if cw.Spec.ExpirationAction == "" {
+		cw.Spec.ExpirationAction = cwt.Spec.DefaultExpirationAction
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/kcp-dev/logicalcluster"
 	"github.com/stretchr/testify/require"
@@ -35,6 +36,7 @@ import (
 	"k8s.io/apiserver/pkg/authorization/authorizer"
 	"k8s.io/apiserver/pkg/endpoints/request"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clusters"
 	"k8s.io/utils/diff"
 
 	"github.com/kcp-dev/kcp/pkg/admission/helpers"
@@ -134,6 +136,63 @@ func TestAdmit(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "adds initializers ordered by their dependencies during transition to initializing",
+			types: []*tenancyv1alpha1.ClusterWorkspaceType{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "root:org#$#foo",
+					},
+					Spec: tenancyv1alpha1.ClusterWorkspaceTypeSpec{
+						Initializers: []tenancyv1alpha1.ClusterWorkspaceInitializer{"bindings", "rbac"},
+						InitializerDependencies: []tenancyv1alpha1.ClusterWorkspaceInitializerDependency{
+							{Initializer: "bindings", DependsOn: []tenancyv1alpha1.ClusterWorkspaceInitializer{"rbac"}},
+						},
+					},
+				},
+			},
+			a: updateAttr(&tenancyv1alpha1.ClusterWorkspace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test",
+				},
+				Spec: tenancyv1alpha1.ClusterWorkspaceSpec{
+					Type: "Foo",
+				},
+				Status: tenancyv1alpha1.ClusterWorkspaceStatus{
+					Phase:    tenancyv1alpha1.ClusterWorkspacePhaseInitializing,
+					Location: tenancyv1alpha1.ClusterWorkspaceLocation{Current: "somewhere"},
+					BaseURL:  "https://kcp.bigcorp.com/clusters/org:test",
+				},
+			},
+				&tenancyv1alpha1.ClusterWorkspace{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "test",
+					},
+					Spec: tenancyv1alpha1.ClusterWorkspaceSpec{
+						Type: "Foo",
+					},
+					Status: tenancyv1alpha1.ClusterWorkspaceStatus{
+						Phase: tenancyv1alpha1.ClusterWorkspacePhaseScheduling,
+					},
+				}),
+			expectedObj: &tenancyv1alpha1.ClusterWorkspace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test",
+				},
+				Spec: tenancyv1alpha1.ClusterWorkspaceSpec{
+					Type: "Foo",
+				},
+				Status: tenancyv1alpha1.ClusterWorkspaceStatus{
+					Phase:        tenancyv1alpha1.ClusterWorkspacePhaseInitializing,
+					Initializers: []tenancyv1alpha1.ClusterWorkspaceInitializer{"rbac", "bindings"},
+					InitializerDependencies: []tenancyv1alpha1.ClusterWorkspaceInitializerDependency{
+						{Initializer: "bindings", DependsOn: []tenancyv1alpha1.ClusterWorkspaceInitializer{"rbac"}},
+					},
+					Location: tenancyv1alpha1.ClusterWorkspaceLocation{Current: "somewhere"},
+					BaseURL:  "https://kcp.bigcorp.com/clusters/org:test",
+				},
+			},
+		},
 		{
 			name: "does not add initializers during transition not to initializing",
 			types: []*tenancyv1alpha1.ClusterWorkspaceType{
@@ -305,6 +364,72 @@ func TestAdmit(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "adds default ttl and expiration action if missing",
+			types: []*tenancyv1alpha1.ClusterWorkspaceType{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "root:org#$#foo",
+					},
+					Spec: tenancyv1alpha1.ClusterWorkspaceTypeSpec{
+						DefaultTTL:              &metav1.Duration{Duration: 24 * time.Hour},
+						DefaultExpirationAction: tenancyv1alpha1.ClusterWorkspaceExpirationActionArchive,
+					},
+				},
+			},
+			a: createAttr(&tenancyv1alpha1.ClusterWorkspace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test",
+				},
+				Spec: tenancyv1alpha1.ClusterWorkspaceSpec{
+					Type: "Foo",
+				},
+			}),
+			expectedObj: &tenancyv1alpha1.ClusterWorkspace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test",
+				},
+				Spec: tenancyv1alpha1.ClusterWorkspaceSpec{
+					Type:             "Foo",
+					TTL:              &metav1.Duration{Duration: 24 * time.Hour},
+					ExpirationAction: tenancyv1alpha1.ClusterWorkspaceExpirationActionArchive,
+				},
+			},
+		},
+		{
+			name: "keeps ttl and expiration action of the workspace",
+			types: []*tenancyv1alpha1.ClusterWorkspaceType{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "root:org#$#foo",
+					},
+					Spec: tenancyv1alpha1.ClusterWorkspaceTypeSpec{
+						DefaultTTL:              &metav1.Duration{Duration: 24 * time.Hour},
+						DefaultExpirationAction: tenancyv1alpha1.ClusterWorkspaceExpirationActionArchive,
+					},
+				},
+			},
+			a: createAttr(&tenancyv1alpha1.ClusterWorkspace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test",
+				},
+				Spec: tenancyv1alpha1.ClusterWorkspaceSpec{
+					Type:             "Foo",
+					TTL:              &metav1.Duration{Duration: time.Hour},
+					ExpirationAction: tenancyv1alpha1.ClusterWorkspaceExpirationActionDelete,
+				},
+			}),
+			expectedObj: &tenancyv1alpha1.ClusterWorkspace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test",
+				},
+				Spec: tenancyv1alpha1.ClusterWorkspaceSpec{
+					Type:             "Foo",
+					TTL:              &metav1.Duration{Duration: time.Hour},
+					ExpirationAction: tenancyv1alpha1.ClusterWorkspaceExpirationActionDelete,
+				},
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -329,9 +454,11 @@ func TestAdmit(t *testing.T) {
 
 func TestValidate(t *testing.T) {
 	tests := []struct {
-		name  string
-		types []*tenancyv1alpha1.ClusterWorkspaceType
-		attr  admission.Attributes
+		name        string
+		types       []*tenancyv1alpha1.ClusterWorkspaceType
+		cachedTypes []*tenancyv1alpha1.ClusterWorkspaceType
+		workspaces  []*tenancyv1alpha1.ClusterWorkspace
+		attr        admission.Attributes
 
 		authzDecision authorizer.Decision
 		authzError    error
@@ -470,6 +597,81 @@ func TestValidate(t *testing.T) {
 			}),
 			authzDecision: authorizer.DecisionAllow,
 		},
+		{
+			name: "passes create if parent type allows the child type",
+			types: []*tenancyv1alpha1.ClusterWorkspaceType{
+				newType("root#$#organization").allowingChildren("Foo").ClusterWorkspaceType,
+				newType("root:org#$#foo").ClusterWorkspaceType,
+			},
+			attr:          createAttr(newWorkspace("test", "Foo")),
+			authzDecision: authorizer.DecisionAllow,
+		},
+		{
+			name: "fails create if parent type does not allow the child type",
+			types: []*tenancyv1alpha1.ClusterWorkspaceType{
+				newType("root#$#organization").allowingChildren("Bar").ClusterWorkspaceType,
+				newType("root:org#$#foo").ClusterWorkspaceType,
+			},
+			attr:          createAttr(newWorkspace("test", "Foo")),
+			authzDecision: authorizer.DecisionAllow,
+			wantErr:       true,
+		},
+		{
+			name: "fails create of implicit Universal type if parent type does not allow it",
+			types: []*tenancyv1alpha1.ClusterWorkspaceType{
+				newType("root#$#organization").allowingChildren("Team").ClusterWorkspaceType,
+			},
+			attr:          createAttr(newWorkspace("test", "Universal")),
+			authzDecision: authorizer.DecisionAllow,
+			wantErr:       true,
+		},
+		{
+			name: "passes create if the type is only known to the cache",
+			cachedTypes: []*tenancyv1alpha1.ClusterWorkspaceType{
+				newType("root:org#$#foo").ClusterWorkspaceType,
+			},
+			attr:          createAttr(newWorkspace("test", "Foo")),
+			authzDecision: authorizer.DecisionAllow,
+		},
+		{
+			name: "fails create if parent type from the cache does not allow the child type",
+			types: []*tenancyv1alpha1.ClusterWorkspaceType{
+				newType("root:org#$#foo").ClusterWorkspaceType,
+			},
+			cachedTypes: []*tenancyv1alpha1.ClusterWorkspaceType{
+				newType("root#$#organization").allowingChildren("Bar").ClusterWorkspaceType,
+			},
+			attr:          createAttr(newWorkspace("test", "Foo")),
+			authzDecision: authorizer.DecisionAllow,
+			wantErr:       true,
+		},
+		{
+			name: "passes create if child type allows the parent type",
+			types: []*tenancyv1alpha1.ClusterWorkspaceType{
+				newType("root:org#$#foo").allowingParents("Organization").ClusterWorkspaceType,
+			},
+			attr:          createAttr(newWorkspace("test", "Foo")),
+			authzDecision: authorizer.DecisionAllow,
+		},
+		{
+			name: "fails create if child type does not allow the parent type",
+			types: []*tenancyv1alpha1.ClusterWorkspaceType{
+				newType("root:org#$#foo").allowingParents("Team", "Root").ClusterWorkspaceType,
+			},
+			attr:          createAttr(newWorkspace("test", "Foo")),
+			authzDecision: authorizer.DecisionAllow,
+			wantErr:       true,
+		},
+		{
+			name: "fails create if the parent workspace cannot be found",
+			types: []*tenancyv1alpha1.ClusterWorkspaceType{
+				newType("root:org#$#foo").ClusterWorkspaceType,
+			},
+			workspaces:    []*tenancyv1alpha1.ClusterWorkspace{},
+			attr:          createAttr(newWorkspace("test", "Foo")),
+			authzDecision: authorizer.DecisionAllow,
+			wantErr:       true,
+		},
 		{
 			name: "validates initializers on phase transition",
 			types: []*tenancyv1alpha1.ClusterWorkspaceType{
@@ -570,15 +772,28 @@ func TestValidate(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
+			workspaces := tt.workspaces
+			if workspaces == nil {
+				workspaces = []*tenancyv1alpha1.ClusterWorkspace{
+					{
+						ObjectMeta: metav1.ObjectMeta{Name: "root#$#org"},
+						Spec:       tenancyv1alpha1.ClusterWorkspaceSpec{Type: "Organization"},
+					},
+				}
+			}
 			o := &clusterWorkspaceTypeExists{
-				Handler:    admission.NewHandler(admission.Create, admission.Update),
-				typeLister: fakeClusterWorkspaceTypeLister(tt.types),
+				Handler:         admission.NewHandler(admission.Create, admission.Update),
+				typeLister:      fakeClusterWorkspaceTypeLister(tt.types),
+				workspaceLister: fakeClusterWorkspaceLister(workspaces),
 				createAuthorizer: func(clusterName logicalcluster.Name, client kubernetes.ClusterInterface) (authorizer.Authorizer, error) {
 					return &fakeAuthorizer{
 						tt.authzDecision,
 						tt.authzError,
 					}, nil
 				},
+				getCachedType: func(ctx context.Context, clusterName logicalcluster.Name, name string) (*tenancyv1alpha1.ClusterWorkspaceType, error) {
+					return fakeClusterWorkspaceTypeLister(tt.cachedTypes).Get(clusters.ToClusterAwareKey(clusterName, name))
+				},
 			}
 			ctx := request.WithCluster(context.Background(), request.Cluster{Name: logicalcluster.New("root:org")})
 			if err := o.Validate(ctx, tt.attr, nil); (err != nil) != tt.wantErr {
@@ -611,6 +826,48 @@ func (l fakeClusterWorkspaceTypeLister) GetWithContext(ctx context.Context, name
 	return nil, apierrors.NewNotFound(tenancyv1alpha1.Resource("clusterworkspacetype"), name)
 }
 
+type fakeClusterWorkspaceLister []*tenancyv1alpha1.ClusterWorkspace
+
+func (l fakeClusterWorkspaceLister) List(selector labels.Selector) (ret []*tenancyv1alpha1.ClusterWorkspace, err error) {
+	return l, nil
+}
+
+func (l fakeClusterWorkspaceLister) Get(name string) (*tenancyv1alpha1.ClusterWorkspace, error) {
+	for _, w := range l {
+		if w.Name == name {
+			return w, nil
+		}
+	}
+	return nil, apierrors.NewNotFound(tenancyv1alpha1.Resource("clusterworkspace"), name)
+}
+
+type typeBuilder struct {
+	*tenancyv1alpha1.ClusterWorkspaceType
+}
+
+func newType(name string) typeBuilder {
+	return typeBuilder{&tenancyv1alpha1.ClusterWorkspaceType{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+	}}
+}
+
+func (b typeBuilder) allowingChildren(types ...tenancyv1alpha1.ClusterWorkspaceTypeName) typeBuilder {
+	b.Spec.AllowedChildWorkspaceTypes = types
+	return b
+}
+
+func (b typeBuilder) allowingParents(types ...tenancyv1alpha1.ClusterWorkspaceTypeName) typeBuilder {
+	b.Spec.AllowedParentWorkspaceTypes = types
+	return b
+}
+
+func newWorkspace(name, typeName string) *tenancyv1alpha1.ClusterWorkspace {
+	return &tenancyv1alpha1.ClusterWorkspace{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec:       tenancyv1alpha1.ClusterWorkspaceSpec{Type: typeName},
+	}
+}
+
 type fakeAuthorizer struct {
 	authorized authorizer.Decision
 	err        error
@@ -20,6 +20,7 @@ import (
 	"k8s.io/apiserver/pkg/admission"
 	"k8s.io/client-go/kubernetes"
 
+	cacheclient "github.com/kcp-dev/kcp/pkg/cache/client"
 	kcpclientset "github.com/kcp-dev/kcp/pkg/client/clientset/versioned"
 	kcpinformers "github.com/kcp-dev/kcp/pkg/client/informers/externalversions"
 )
@@ -103,3 +104,23 @@ func (i *externalAddressInitializer) Initialize(plugin admission.Interface) {
 		wants.SetExternalAddressProvider(i.externalAddressProvider)
 	}
 }
+
+// NewCacheClientInitializer returns an admission plugin initializer that injects
+// a cache client into admission plugins.
+func NewCacheClientInitializer(
+	cacheClient *cacheclient.Client,
+) *cacheClientInitializer {
+	return &cacheClientInitializer{
+		cacheClient: cacheClient,
+	}
+}
+
+type cacheClientInitializer struct {
+	cacheClient *cacheclient.Client
+}
+
+func (i *cacheClientInitializer) Initialize(plugin admission.Interface) {
+	if wants, ok := plugin.(WantsCacheClient); ok {
+		wants.SetCacheClient(i.cacheClient)
+	}
+}
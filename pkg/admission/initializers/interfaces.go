@@ -19,6 +19,7 @@ package initializers
 import (
 	"k8s.io/client-go/kubernetes"
 
+	cacheclient "github.com/kcp-dev/kcp/pkg/cache/client"
 	kcpclientset "github.com/kcp-dev/kcp/pkg/client/clientset/versioned"
 	kcpinformers "github.com/kcp-dev/kcp/pkg/client/informers/externalversions"
 )
@@ -46,3 +47,9 @@ type WantsKcpClusterClient interface {
 type WantsExternalAddressProvider interface {
 	SetExternalAddressProvider(externalAddressProvider func() string)
 }
+
+// WantsCacheClient interface should be implemented by admission plugins
+// that want to have a cache client injected to resolve objects of other shards.
+type WantsCacheClient interface {
+	SetCacheClient(cacheClient *cacheclient.Client)
+}
@@ -40,6 +40,7 @@ import (
 	"k8s.io/kubernetes/plugin/pkg/admission/storage/storageobjectinuseprotection"
 
 	"github.com/kcp-dev/kcp/pkg/admission/apibinding"
+	"github.com/kcp-dev/kcp/pkg/admission/apiexport"
 	"github.com/kcp-dev/kcp/pkg/admission/apiresourceschema"
 	"github.com/kcp-dev/kcp/pkg/admission/clusterworkspace"
 	"github.com/kcp-dev/kcp/pkg/admission/clusterworkspaceshard"
@@ -47,20 +48,33 @@ import (
 	"github.com/kcp-dev/kcp/pkg/admission/clusterworkspacetypeexists"
 	kcpmutatingwebhook "github.com/kcp-dev/kcp/pkg/admission/mutatingwebhook"
 	workspacenamespacelifecycle "github.com/kcp-dev/kcp/pkg/admission/namespacelifecycle"
+	"github.com/kcp-dev/kcp/pkg/admission/readonlyworkspace"
 	"github.com/kcp-dev/kcp/pkg/admission/reservedcrdannotations"
 	"github.com/kcp-dev/kcp/pkg/admission/reservedcrdgroups"
+	workspaceresourcequota "github.com/kcp-dev/kcp/pkg/admission/resourcequota"
+	"github.com/kcp-dev/kcp/pkg/admission/secretshare"
 	kcpvalidatingwebhook "github.com/kcp-dev/kcp/pkg/admission/validatingwebhook"
+	"github.com/kcp-dev/kcp/pkg/admission/workspacesnapshot"
+	"github.com/kcp-dev/kcp/pkg/admission/workspacesource"
+	"github.com/kcp-dev/kcp/pkg/admission/workspacestoragelimit"
 )
 
 // AllOrderedPlugins is the list of all the plugins in order.
 var AllOrderedPlugins = beforeWebhooks(kubeapiserveroptions.AllOrderedPlugins,
 	workspacenamespacelifecycle.PluginName,
+	readonlyworkspace.PluginName,
+	workspacestoragelimit.PluginName,
 	apiresourceschema.PluginName,
+	apiexport.PluginName,
 	clusterworkspace.PluginName,
 	clusterworkspaceshard.PluginName,
 	clusterworkspacetype.PluginName,
 	clusterworkspacetypeexists.PluginName,
 	apibinding.PluginName,
+	secretshare.PluginName,
+	workspacesnapshot.PluginName,
+	workspacesource.PluginName,
+	workspaceresourcequota.PluginName,
 	kcpvalidatingwebhook.PluginName,
 	kcpmutatingwebhook.PluginName,
 	reservedcrdannotations.PluginName,
@@ -87,8 +101,15 @@ func RegisterAllKcpAdmissionPlugins(plugins *admission.Plugins) {
 	clusterworkspacetype.Register(plugins)
 	clusterworkspacetypeexists.Register(plugins)
 	apiresourceschema.Register(plugins)
+	apiexport.Register(plugins)
 	apibinding.Register(plugins)
+	secretshare.Register(plugins)
+	workspacesnapshot.Register(plugins)
+	workspacesource.Register(plugins)
 	workspacenamespacelifecycle.Register(plugins)
+	readonlyworkspace.Register(plugins)
+	workspacestoragelimit.Register(plugins)
+	workspaceresourcequota.Register(plugins)
 	kcpvalidatingwebhook.Register(plugins)
 	kcpmutatingwebhook.Register(plugins)
 	reservedcrdannotations.Register(plugins)
@@ -103,12 +124,19 @@ var defaultOnPluginsInKcp = sets.NewString(
 	certsubjectrestriction.PluginName,      // CertificateSubjectRestriction
 
 	// KCP
+	readonlyworkspace.PluginName,
+	workspacestoragelimit.PluginName,
 	clusterworkspace.PluginName,
 	clusterworkspaceshard.PluginName,
 	clusterworkspacetype.PluginName,
 	clusterworkspacetypeexists.PluginName,
 	apiresourceschema.PluginName,
+	apiexport.PluginName,
 	apibinding.PluginName,
+	secretshare.PluginName,
+	workspacesnapshot.PluginName,
+	workspacesource.PluginName,
+	workspaceresourcequota.PluginName,
 	kcpvalidatingwebhook.PluginName,
 	kcpmutatingwebhook.PluginName,
 	reservedcrdannotations.PluginName,
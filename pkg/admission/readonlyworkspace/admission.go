@@ -0,0 +1,191 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package readonlyworkspace
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/kcp-dev/logicalcluster"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apiserver/pkg/admission"
+	"k8s.io/apiserver/pkg/authorization/authorizer"
+	genericapirequest "k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clusters"
+	"k8s.io/klog/v2"
+
+	kcpinitializers "github.com/kcp-dev/kcp/pkg/admission/initializers"
+	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/authorization/bootstrap"
+	"github.com/kcp-dev/kcp/pkg/authorization/delegated"
+	kcpinformers "github.com/kcp-dev/kcp/pkg/client/informers/externalversions"
+)
+
+// Validate writes against read-only workspaces:
+// - all writes in a workspace with spec.readOnly are rejected, unless the workspace is being deleted
+// - setting or removing spec.readOnly of a ClusterWorkspace requires admin permission on its content.
+
+const (
+	PluginName = "tenancy.kcp.dev/ReadOnlyWorkspace"
+)
+
+func Register(plugins *admission.Plugins) {
+	plugins.Register(PluginName,
+		func(_ io.Reader) (admission.Interface, error) {
+			return &readOnlyWorkspace{
+				Handler:          admission.NewHandler(admission.Create, admission.Update, admission.Delete),
+				createAuthorizer: delegated.NewDelegatedAuthorizer,
+			}, nil
+		})
+}
+
+type readOnlyWorkspace struct {
+	*admission.Handler
+	kubeClusterClient *kubernetes.Cluster
+	getWorkspace      func(clusterName logicalcluster.Name, name string) (*tenancyv1alpha1.ClusterWorkspace, error)
+
+	createAuthorizer delegated.DelegatedAuthorizerFactory
+}
+
+// Ensure that the required admission interfaces are implemented.
+var _ = admission.ValidationInterface(&readOnlyWorkspace{})
+var _ = admission.InitializationValidator(&readOnlyWorkspace{})
+var _ = kcpinitializers.WantsKcpInformers(&readOnlyWorkspace{})
+var _ = kcpinitializers.WantsKubeClusterClient(&readOnlyWorkspace{})
+
+func (o *readOnlyWorkspace) Validate(ctx context.Context, a admission.Attributes, _ admission.ObjectInterfaces) error {
+	clusterName, err := genericapirequest.ClusterNameFrom(ctx)
+	if err != nil {
+		return apierrors.NewInternalError(err)
+	}
+
+	if err := o.validateWrite(a, clusterName); err != nil {
+		return err
+	}
+
+	if a.GetResource().GroupResource() == tenancyv1alpha1.Resource("clusterworkspaces") && a.GetSubresource() == "" && a.GetOperation() != admission.Delete {
+		return o.validateReadOnlyChange(ctx, a)
+	}
+	return nil
+}
+
+// validateWrite rejects writes in a logical cluster whose ClusterWorkspace is read-only.
+func (o *readOnlyWorkspace) validateWrite(a admission.Attributes, clusterName logicalcluster.Name) error {
+	parent, hasParent := clusterName.Parent()
+	if !hasParent {
+		return nil
+	}
+
+	workspace, err := o.getWorkspace(parent, clusterName.Base())
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return apierrors.NewInternalError(err)
+	}
+
+	// let the workspace deletion clean up the content
+	if !workspace.Spec.ReadOnly || !workspace.DeletionTimestamp.IsZero() {
+		return nil
+	}
+	return admission.NewForbidden(a, fmt.Errorf("workspace %s is read-only", clusterName))
+}
+
+// validateReadOnlyChange requires admin permission on the content of a ClusterWorkspace to set or remove spec.readOnly.
+func (o *readOnlyWorkspace) validateReadOnlyChange(ctx context.Context, a admission.Attributes) error {
+	u, ok := a.GetObject().(*unstructured.Unstructured)
+	if !ok {
+		return fmt.Errorf("unexpected type %T", a.GetObject())
+	}
+	readOnly, _, err := unstructured.NestedBool(u.Object, "spec", "readOnly")
+	if err != nil {
+		return admission.NewForbidden(a, err)
+	}
+
+	var oldReadOnly bool
+	if a.GetOperation() == admission.Update {
+		old, ok := a.GetOldObject().(*unstructured.Unstructured)
+		if !ok {
+			return fmt.Errorf("unexpected type %T", a.GetOldObject())
+		}
+		if oldReadOnly, _, err = unstructured.NestedBool(old.Object, "spec", "readOnly"); err != nil {
+			return admission.NewForbidden(a, err)
+		}
+	}
+	if readOnly == oldReadOnly {
+		return nil
+	}
+
+	cluster, err := genericapirequest.ValidClusterFrom(ctx)
+	if err != nil {
+		return apierrors.NewInternalError(err)
+	}
+	authz, err := o.createAuthorizer(cluster.Name, o.kubeClusterClient)
+	if err != nil {
+		// Logging a more specific error for the operator
+		klog.Errorf("error creating authorizer from delegating authorizer config: %v", err)
+		// Returning a less specific error to the end user
+		return admission.NewForbidden(a, errors.New("unable to authorize request"))
+	}
+
+	attr := authorizer.AttributesRecord{
+		User:            a.GetUserInfo(),
+		Verb:            bootstrap.WorkspaceAdminVerb,
+		APIGroup:        tenancyv1alpha1.SchemeGroupVersion.Group,
+		APIVersion:      tenancyv1alpha1.SchemeGroupVersion.Version,
+		Resource:        "clusterworkspaces",
+		Subresource:     "content",
+		Name:            u.GetName(),
+		ResourceRequest: true,
+	}
+	if decision, _, err := authz.Authorize(ctx, attr); err != nil {
+		return admission.NewForbidden(a, fmt.Errorf("unable to determine access to clusterworkspaces/content: %w", err))
+	} else if decision != authorizer.DecisionAllow {
+		return admission.NewForbidden(a, fmt.Errorf("changing spec.readOnly requires verb=%q permission on clusterworkspaces/content", bootstrap.WorkspaceAdminVerb))
+	}
+	return nil
+}
+
+// ValidateInitialization ensures the required injected fields are set.
+func (o *readOnlyWorkspace) ValidateInitialization() error {
+	if o.kubeClusterClient == nil {
+		return fmt.Errorf(PluginName + " plugin needs a Kubernetes ClusterInterface")
+	}
+	if o.getWorkspace == nil {
+		return fmt.Errorf(PluginName + " plugin needs a ClusterWorkspace lister")
+	}
+	return nil
+}
+
+// SetKubeClusterClient is an admission plugin initializer function that injects a Kubernetes cluster client into
+// this admission plugin.
+func (o *readOnlyWorkspace) SetKubeClusterClient(clusterClient *kubernetes.Cluster) {
+	o.kubeClusterClient = clusterClient
+}
+
+func (o *readOnlyWorkspace) SetKcpInformers(informers kcpinformers.SharedInformerFactory) {
+	o.SetReadyFunc(informers.Tenancy().V1alpha1().ClusterWorkspaces().Informer().HasSynced)
+	workspaceLister := informers.Tenancy().V1alpha1().ClusterWorkspaces().Lister()
+	o.getWorkspace = func(clusterName logicalcluster.Name, name string) (*tenancyv1alpha1.ClusterWorkspace, error) {
+		return workspaceLister.Get(clusters.ToClusterAwareKey(clusterName, name))
+	}
+}
@@ -0,0 +1,195 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package readonlyworkspace
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kcp-dev/logicalcluster"
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apiserver/pkg/admission"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/apiserver/pkg/authorization/authorizer"
+	"k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/kcp-dev/kcp/pkg/admission/helpers"
+	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+)
+
+func attr(op admission.Operation, obj, old runtime.Object, kind, resource string) admission.Attributes {
+	gv := corev1.SchemeGroupVersion
+	if kind == "ClusterWorkspace" {
+		gv = tenancyv1alpha1.SchemeGroupVersion
+		obj = helpers.ToUnstructuredOrDie(obj)
+		if old != nil {
+			old = helpers.ToUnstructuredOrDie(old)
+		}
+	}
+	return admission.NewAttributesRecord(
+		obj,
+		old,
+		gv.WithKind(kind),
+		"",
+		"test",
+		gv.WithResource(resource),
+		"",
+		op,
+		nil,
+		false,
+		&user.DefaultInfo{},
+	)
+}
+
+func newWorkspace(name string, readOnly bool) *tenancyv1alpha1.ClusterWorkspace {
+	return &tenancyv1alpha1.ClusterWorkspace{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec:       tenancyv1alpha1.ClusterWorkspaceSpec{ReadOnly: readOnly},
+	}
+}
+
+func TestValidate(t *testing.T) {
+	configMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "test"}}
+	deleting := newWorkspace("deleting", true)
+	now := metav1.Now()
+	deleting.DeletionTimestamp = &now
+
+	workspaces := map[string]*tenancyv1alpha1.ClusterWorkspace{
+		"frozen":   newWorkspace("frozen", true),
+		"active":   newWorkspace("active", false),
+		"deleting": deleting,
+	}
+
+	tests := []struct {
+		name           string
+		cluster        string
+		attr           admission.Attributes
+		authzDecision  authorizer.Decision
+		expectedErrors []string
+		expectedAuthz  bool
+	}{
+		{
+			name:           "create in read-only workspace",
+			cluster:        "root:org:frozen",
+			attr:           attr(admission.Create, configMap, nil, "ConfigMap", "configmaps"),
+			expectedErrors: []string{"workspace root:org:frozen is read-only"},
+		},
+		{
+			name:           "delete in read-only workspace",
+			cluster:        "root:org:frozen",
+			attr:           attr(admission.Delete, nil, nil, "ConfigMap", "configmaps"),
+			expectedErrors: []string{"is read-only"},
+		},
+		{
+			name:    "delete in read-only workspace being deleted",
+			cluster: "root:org:deleting",
+			attr:    attr(admission.Delete, nil, nil, "ConfigMap", "configmaps"),
+		},
+		{
+			name:    "create in writable workspace",
+			cluster: "root:org:active",
+			attr:    attr(admission.Create, configMap, nil, "ConfigMap", "configmaps"),
+		},
+		{
+			name:    "create in unknown workspace",
+			cluster: "root:org:unknown",
+			attr:    attr(admission.Create, configMap, nil, "ConfigMap", "configmaps"),
+		},
+		{
+			name:          "setting readOnly as admin",
+			cluster:       "root:org",
+			attr:          attr(admission.Update, newWorkspace("active", true), newWorkspace("active", false), "ClusterWorkspace", "clusterworkspaces"),
+			authzDecision: authorizer.DecisionAllow,
+			expectedAuthz: true,
+		},
+		{
+			name:           "removing readOnly without admin permission",
+			cluster:        "root:org",
+			attr:           attr(admission.Update, newWorkspace("frozen", false), newWorkspace("frozen", true), "ClusterWorkspace", "clusterworkspaces"),
+			authzDecision:  authorizer.DecisionNoOpinion,
+			expectedErrors: []string{`changing spec.readOnly requires verb="admin" permission on clusterworkspaces/content`},
+			expectedAuthz:  true,
+		},
+		{
+			name:          "creating read-only workspace as admin",
+			cluster:       "root:org",
+			attr:          attr(admission.Create, newWorkspace("new", true), nil, "ClusterWorkspace", "clusterworkspaces"),
+			authzDecision: authorizer.DecisionAllow,
+			expectedAuthz: true,
+		},
+		{
+			name:    "update keeping readOnly",
+			cluster: "root:org",
+			attr:    attr(admission.Update, newWorkspace("frozen", true), newWorkspace("frozen", true), "ClusterWorkspace", "clusterworkspaces"),
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			authz := &fakeAuthorizer{authorized: tc.authzDecision}
+			o := &readOnlyWorkspace{
+				Handler: admission.NewHandler(admission.Create, admission.Update, admission.Delete),
+				getWorkspace: func(clusterName logicalcluster.Name, name string) (*tenancyv1alpha1.ClusterWorkspace, error) {
+					if clusterName.String() != "root:org" || workspaces[name] == nil {
+						return nil, apierrors.NewNotFound(tenancyv1alpha1.Resource("clusterworkspaces"), name)
+					}
+					return workspaces[name], nil
+				},
+				createAuthorizer: func(clusterName logicalcluster.Name, client kubernetes.ClusterInterface) (authorizer.Authorizer, error) {
+					require.Equal(t, "root:org", clusterName.String())
+					return authz, nil
+				},
+			}
+
+			ctx := request.WithCluster(context.Background(), request.Cluster{Name: logicalcluster.New(tc.cluster)})
+
+			err := o.Validate(ctx, tc.attr, nil)
+
+			wantErr := len(tc.expectedErrors) > 0
+			require.Equal(t, wantErr, err != nil, "unexpected error: %v", err)
+			for _, expected := range tc.expectedErrors {
+				require.Contains(t, err.Error(), expected)
+			}
+
+			if tc.expectedAuthz {
+				require.NotNil(t, authz.attr)
+				require.Equal(t, "admin", authz.attr.GetVerb())
+				require.Equal(t, "clusterworkspaces", authz.attr.GetResource())
+				require.Equal(t, "content", authz.attr.GetSubresource())
+			} else {
+				require.Nil(t, authz.attr)
+			}
+		})
+	}
+}
+
+type fakeAuthorizer struct {
+	authorized authorizer.Decision
+	attr       authorizer.Attributes
+}
+
+func (a *fakeAuthorizer) Authorize(ctx context.Context, attr authorizer.Attributes) (authorized authorizer.Decision, reason string, err error) {
+	a.attr = attr
+	return a.authorized, "reason", nil
+}
@@ -23,8 +23,13 @@ import (
 	"strings"
 
 	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apiserver/pkg/admission"
 	"k8s.io/apiserver/pkg/endpoints/request"
+	"sigs.k8s.io/yaml"
+
+	apisv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apis/v1alpha1"
 )
 
 const (
@@ -32,36 +37,85 @@ const (
 	SystemCRDLogicalClusterName = "system:system-crds"
 )
 
+// DefaultReservedGroups are the reserved API groups if the plugin is not configured.
+var DefaultReservedGroups = []string{"*.kcp.dev"}
+
+// Configuration is the configuration of the plugin in the admission control config file.
+type Configuration struct {
+	// ReservedGroups are the reserved API groups. An entry starting with "*." reserves the rest
+	// of the entry and all groups ending in it, e.g. "*.kcp.dev" reserves kcp.dev and tenancy.kcp.dev.
+	ReservedGroups []string `json:"reservedGroups"`
+}
+
 func Register(plugins *admission.Plugins) {
 	plugins.Register(PluginName,
-		func(_ io.Reader) (admission.Interface, error) {
+		func(config io.Reader) (admission.Interface, error) {
+			groups, err := loadReservedGroups(config)
+			if err != nil {
+				return nil, err
+			}
 			return &reservedCRDGroups{
 				Handler: admission.NewHandler(admission.Create, admission.Update),
+				groups:  groups,
 			}, nil
 		})
 }
 
+func loadReservedGroups(config io.Reader) ([]string, error) {
+	if config == nil {
+		return DefaultReservedGroups, nil
+	}
+	bs, err := io.ReadAll(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s configuration: %w", PluginName, err)
+	}
+	var c Configuration
+	if err := yaml.Unmarshal(bs, &c); err != nil {
+		return nil, fmt.Errorf("failed to parse %s configuration: %w", PluginName, err)
+	}
+	if c.ReservedGroups == nil {
+		return DefaultReservedGroups, nil
+	}
+	return c.ReservedGroups, nil
+}
+
 type reservedCRDGroups struct {
 	*admission.Handler
+	groups []string
 }
 
 // Ensure that the required admission interfaces are implemented.
 var _ = admission.ValidationInterface(&reservedCRDGroups{})
 
-// Ensure that CRDs in *.kcp.dev group are ony created inside system:system-crds workspace
+// Ensure that CRDs and APIResourceSchemas of reserved groups are only created inside the system:system-crds
+// workspace. As APIBindings only bind the APIResourceSchemas of APIExports, they cannot bind reserved groups
+// either.
 func (o *reservedCRDGroups) Validate(ctx context.Context, a admission.Attributes, _ admission.ObjectInterfaces) (err error) {
-	if a.GetResource().GroupResource() != apiextensions.Resource("customresourcedefinitions") {
+	var group string
+	switch a.GetResource().GroupResource() {
+	case apiextensions.Resource("customresourcedefinitions"):
+		if a.GetKind().GroupKind() != apiextensions.Kind("CustomResourceDefinition") {
+			return nil
+		}
+		crd, ok := a.GetObject().(*apiextensions.CustomResourceDefinition)
+		if !ok {
+			return fmt.Errorf("unexpected type %T", a.GetObject())
+		}
+		group = crd.Spec.Group
+	case apisv1alpha1.Resource("apiresourceschemas"):
+		u, ok := a.GetObject().(*unstructured.Unstructured)
+		if !ok {
+			return fmt.Errorf("unexpected type %T", a.GetObject())
+		}
+		schema := &apisv1alpha1.APIResourceSchema{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, schema); err != nil {
+			return fmt.Errorf("failed to convert unstructured to APIResourceSchema: %w", err)
+		}
+		group = schema.Spec.Group
+	default:
 		return nil
 	}
 
-	if a.GetKind().GroupKind() != apiextensions.Kind("CustomResourceDefinition") {
-		return nil
-	}
-	crd, ok := a.GetObject().(*apiextensions.CustomResourceDefinition)
-	if !ok {
-		return fmt.Errorf("unexpected type %T", a.GetObject())
-	}
-
 	clusterName, err := request.ClusterNameFrom(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to retrieve cluster from context: %w", err)
@@ -70,8 +124,21 @@ func (o *reservedCRDGroups) Validate(ctx context.Context, a admission.Attributes
 		return nil
 	}
 
-	if strings.HasSuffix(crd.Spec.Group, "kcp.dev") {
-		return admission.NewForbidden(a, fmt.Errorf("%s is a reserved group", crd.Spec.Group))
+	if o.isReserved(group) {
+		return admission.NewForbidden(a, fmt.Errorf("%s is a reserved group", group))
 	}
 	return nil
 }
+
+func (o *reservedCRDGroups) isReserved(group string) bool {
+	for _, reserved := range o.groups {
+		if suffix := strings.TrimPrefix(reserved, "*."); suffix != reserved {
+			if group == suffix || strings.HasSuffix(group, "."+suffix) {
+				return true
+			}
+		} else if group == reserved {
+			return true
+		}
+	}
+	return false
+}
@@ -66,6 +66,22 @@ func createAttrAPIBinding(apiBinding *apisv1alpha1.APIBinding) admission.Attribu
 	)
 }
 
+func createAttrAPIResourceSchema(schema *apisv1alpha1.APIResourceSchema) admission.Attributes {
+	return admission.NewAttributesRecord(
+		helpers.ToUnstructuredOrDie(schema),
+		nil,
+		apisv1alpha1.Kind("APIResourceSchema").WithVersion("v1alpha1"),
+		"",
+		schema.Name,
+		apisv1alpha1.Resource("apiresourceschemas").WithVersion("v1alpha1"),
+		"",
+		admission.Create,
+		&metav1.CreateOptions{},
+		false,
+		&user.DefaultInfo{},
+	)
+}
+
 func updateAttr(obj, old *apiextensions.CustomResourceDefinition) admission.Attributes {
 	return admission.NewAttributesRecord(
 		obj,
@@ -87,6 +103,7 @@ func TestValidate(t *testing.T) {
 		name        string
 		attr        admission.Attributes
 		clusterName string
+		groups      []string
 
 		wantErr bool
 	}{
@@ -127,6 +144,96 @@ func TestValidate(t *testing.T) {
 			}),
 			clusterName: "root:org:ws",
 		},
+		{
+			name: "fails create of the reserved group itself",
+			attr: createAttr(&apiextensions.CustomResourceDefinition{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test",
+				},
+				Spec: apiextensions.CustomResourceDefinitionSpec{
+					Group: "kcp.dev",
+				},
+			}),
+			wantErr:     true,
+			clusterName: "root:org:ws",
+		},
+		{
+			name: "passes create of group only sharing the suffix of a reserved group",
+			attr: createAttr(&apiextensions.CustomResourceDefinition{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test",
+				},
+				Spec: apiextensions.CustomResourceDefinitionSpec{
+					Group: "notkcp.dev",
+				},
+			}),
+			clusterName: "root:org:ws",
+		},
+		{
+			name: "fails create reserved group in other system logical cluster",
+			attr: createAttr(&apiextensions.CustomResourceDefinition{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test",
+				},
+				Spec: apiextensions.CustomResourceDefinitionSpec{
+					Group: "foo.kcp.dev",
+				},
+			}),
+			wantErr:     true,
+			clusterName: "system:admin",
+		},
+		{
+			name: "fails create configured reserved group",
+			attr: createAttr(&apiextensions.CustomResourceDefinition{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test",
+				},
+				Spec: apiextensions.CustomResourceDefinitionSpec{
+					Group: "example.com",
+				},
+			}),
+			groups:      []string{"*.kcp.dev", "example.com"},
+			wantErr:     true,
+			clusterName: "root:org:ws",
+		},
+		{
+			name: "passes create of subgroup of configured reserved group without wildcard",
+			attr: createAttr(&apiextensions.CustomResourceDefinition{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test",
+				},
+				Spec: apiextensions.CustomResourceDefinitionSpec{
+					Group: "foo.example.com",
+				},
+			}),
+			groups:      []string{"*.kcp.dev", "example.com"},
+			clusterName: "root:org:ws",
+		},
+		{
+			name: "fails create APIResourceSchema of reserved group outside of system crd logical cluster",
+			attr: createAttrAPIResourceSchema(&apisv1alpha1.APIResourceSchema{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "today.workloadclusters.workload.kcp.dev",
+				},
+				Spec: apisv1alpha1.APIResourceSchemaSpec{
+					Group: "workload.kcp.dev",
+				},
+			}),
+			wantErr:     true,
+			clusterName: "root:org:ws",
+		},
+		{
+			name: "passes create APIResourceSchema of non-reserved group outside of system crd logical cluster",
+			attr: createAttrAPIResourceSchema(&apisv1alpha1.APIResourceSchema{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "today.widgets.example.com",
+				},
+				Spec: apisv1alpha1.APIResourceSchemaSpec{
+					Group: "example.com",
+				},
+			}),
+			clusterName: "root:org:ws",
+		},
 		{
 			name: "passes not a CRD",
 			attr: createAttrAPIBinding(&apisv1alpha1.APIBinding{
@@ -200,8 +307,13 @@ func TestValidate(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
+			groups := tt.groups
+			if groups == nil {
+				groups = DefaultReservedGroups
+			}
 			o := &reservedCRDGroups{
 				Handler: admission.NewHandler(admission.Create, admission.Update),
+				groups:  groups,
 			}
 			var ctx context.Context
 
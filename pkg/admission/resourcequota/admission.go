@@ -0,0 +1,196 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resourcequota
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/kcp-dev/logicalcluster"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apiserver/pkg/admission"
+	"k8s.io/apiserver/pkg/admission/initializer"
+	genericapirequest "k8s.io/apiserver/pkg/endpoints/request"
+	quotav1generic "k8s.io/apiserver/pkg/quota/v1/generic"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	corelisters "k8s.io/client-go/listers/core/v1"
+
+	kcpinitializers "github.com/kcp-dev/kcp/pkg/admission/initializers"
+)
+
+// Enforce the object count limits of ResourceQuotas, i.e. count/<resource>.<group>,
+// in the namespaces of all workspaces, for all resources including CRDs and bound APIs.
+//
+// The usage in the quota status is charged on creation, and is recalculated by the
+// ResourceQuota controller.
+
+const (
+	// PluginName is the kcp replacement of the ResourceQuota plugin of Kubernetes, which
+	// is not workspace aware.
+	PluginName = "WorkspaceResourceQuota"
+
+	// maxChargeAttempts is the number of attempts to charge a quota on conflicts.
+	maxChargeAttempts = 5
+)
+
+func Register(plugins *admission.Plugins) {
+	plugins.Register(PluginName,
+		func(_ io.Reader) (admission.Interface, error) {
+			return &resourceQuota{
+				Handler: admission.NewHandler(admission.Create),
+			}, nil
+		})
+}
+
+type resourceQuota struct {
+	*admission.Handler
+
+	quotaLister       corelisters.ResourceQuotaLister
+	kubeClusterClient kubernetes.ClusterInterface
+}
+
+// Ensure that the required admission interfaces are implemented.
+var _ = admission.ValidationInterface(&resourceQuota{})
+var _ = admission.InitializationValidator(&resourceQuota{})
+var _ = initializer.WantsExternalKubeInformerFactory(&resourceQuota{})
+var _ = kcpinitializers.WantsKubeClusterClient(&resourceQuota{})
+
+// Validate rejects the creation of namespaced objects exceeding the object count limit of
+// a ResourceQuota in the namespace, and otherwise charges the objects to the quotas.
+func (o *resourceQuota) Validate(ctx context.Context, a admission.Attributes, _ admission.ObjectInterfaces) error {
+	if a.GetNamespace() == "" || a.GetSubresource() != "" {
+		return nil
+	}
+
+	clusterName, err := genericapirequest.ClusterNameFrom(ctx)
+	if err != nil {
+		return apierrors.NewInternalError(err)
+	}
+
+	resourceName := quotav1generic.ObjectCountQuotaResourceNameFor(a.GetResource().GroupResource())
+	quotas, err := o.quotaLister.ResourceQuotas(a.GetNamespace()).List(labels.Everything())
+	if err != nil {
+		return apierrors.NewInternalError(err)
+	}
+	var limiting []*corev1.ResourceQuota
+	for _, quota := range quotas {
+		if logicalcluster.From(quota) != clusterName {
+			continue
+		}
+		if _, found := quota.Spec.Hard[resourceName]; !found {
+			continue
+		}
+		if err := checkQuota(quota, resourceName); err != nil {
+			return admission.NewForbidden(a, err)
+		}
+		limiting = append(limiting, quota)
+	}
+
+	if a.IsDryRun() {
+		return nil
+	}
+	for _, quota := range limiting {
+		if err := o.charge(ctx, clusterName, quota, resourceName); err != nil {
+			if apierrors.IsForbidden(err) {
+				return admission.NewForbidden(a, err)
+			}
+			return err
+		}
+	}
+
+	return nil
+}
+
+// checkQuota returns a forbidden error if one more object would exceed the given quota.
+func checkQuota(quota *corev1.ResourceQuota, resourceName corev1.ResourceName) error {
+	used, found := quota.Status.Used[resourceName]
+	if !found {
+		return apierrors.NewForbidden(corev1.Resource("resourcequotas"), quota.Name, fmt.Errorf("status unknown for quota: %s, resources: %s", quota.Name, resourceName))
+	}
+	hard := quota.Spec.Hard[resourceName]
+	requested := used.DeepCopy()
+	requested.Add(*resource.NewQuantity(1, resource.DecimalSI))
+	if requested.Cmp(hard) > 0 {
+		return apierrors.NewForbidden(corev1.Resource("resourcequotas"), quota.Name, fmt.Errorf("exceeded quota: %s, requested: %s=1, used: %s=%s, limited: %s=%s",
+			quota.Name, resourceName, resourceName, used.String(), resourceName, hard.String()))
+	}
+	return nil
+}
+
+// charge increments the usage of the given quota by one object, retrying with the latest
+// quota on conflicts.
+func (o *resourceQuota) charge(ctx context.Context, clusterName logicalcluster.Name, quota *corev1.ResourceQuota, resourceName corev1.ResourceName) error {
+	client := o.kubeClusterClient.Cluster(clusterName).CoreV1().ResourceQuotas(quota.Namespace)
+	for attempt := 0; attempt < maxChargeAttempts; attempt++ {
+		if attempt > 0 {
+			latest, err := client.Get(ctx, quota.Name, metav1.GetOptions{})
+			if err != nil {
+				return apierrors.NewInternalError(err)
+			}
+			if _, found := latest.Spec.Hard[resourceName]; !found {
+				return nil // limit removed in the meantime
+			}
+			if err := checkQuota(latest, resourceName); err != nil {
+				return err
+			}
+			quota = latest
+		}
+
+		charged := quota.DeepCopy()
+		used := charged.Status.Used[resourceName]
+		used.Add(*resource.NewQuantity(1, resource.DecimalSI))
+		charged.Status.Used[resourceName] = used
+		_, err := client.UpdateStatus(ctx, charged, metav1.UpdateOptions{})
+		if err == nil {
+			return nil
+		}
+		if !apierrors.IsConflict(err) {
+			return apierrors.NewInternalError(err)
+		}
+	}
+	return apierrors.NewInternalError(fmt.Errorf("failed to charge quota %s after %d attempts", quota.Name, maxChargeAttempts))
+}
+
+// ValidateInitialization ensures the required injected fields are set.
+func (o *resourceQuota) ValidateInitialization() error {
+	if o.quotaLister == nil {
+		return fmt.Errorf(PluginName + " plugin needs a ResourceQuota lister")
+	}
+	if o.kubeClusterClient == nil {
+		return fmt.Errorf(PluginName + " plugin needs a Kubernetes ClusterInterface")
+	}
+	return nil
+}
+
+// SetExternalKubeInformerFactory implements the WantsExternalKubeInformerFactory interface.
+func (o *resourceQuota) SetExternalKubeInformerFactory(f informers.SharedInformerFactory) {
+	o.SetReadyFunc(f.Core().V1().ResourceQuotas().Informer().HasSynced)
+	o.quotaLister = f.Core().V1().ResourceQuotas().Lister()
+}
+
+// SetKubeClusterClient is an admission plugin initializer function that injects a Kubernetes cluster client into
+// this admission plugin.
+func (o *resourceQuota) SetKubeClusterClient(clusterClient *kubernetes.Cluster) {
+	o.kubeClusterClient = clusterClient
+}
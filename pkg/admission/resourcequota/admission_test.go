@@ -0,0 +1,204 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resourcequota
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kcp-dev/logicalcluster"
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apiserver/pkg/admission"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/apiserver/pkg/endpoints/request"
+	quotav1generic "k8s.io/apiserver/pkg/quota/v1/generic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/fake"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+type fakeClusterClient struct {
+	*fake.Clientset
+}
+
+func (c fakeClusterClient) Cluster(logicalcluster.Name) kubernetes.Interface {
+	return c.Clientset
+}
+
+func createAttr(namespace string, gvr schema.GroupVersionResource, subresource string, dryRun bool) admission.Attributes {
+	return admission.NewAttributesRecord(
+		nil,
+		nil,
+		schema.GroupVersionKind{},
+		namespace,
+		"test",
+		gvr,
+		subresource,
+		admission.Create,
+		&metav1.CreateOptions{},
+		dryRun,
+		&user.DefaultInfo{},
+	)
+}
+
+func newQuota(cluster, name string, hard, used corev1.ResourceList) *corev1.ResourceQuota {
+	return &corev1.ResourceQuota{
+		ObjectMeta: metav1.ObjectMeta{
+			ClusterName: cluster,
+			Name:        name,
+			Namespace:   "default",
+		},
+		Spec:   corev1.ResourceQuotaSpec{Hard: hard},
+		Status: corev1.ResourceQuotaStatus{Hard: hard, Used: used},
+	}
+}
+
+func TestValidate(t *testing.T) {
+	widgets := schema.GroupVersionResource{Group: "example.com", Version: "v1", Resource: "widgets"}
+	configMaps := corev1.SchemeGroupVersion.WithResource("configmaps")
+	countWidgets := corev1.ResourceName("count/widgets.example.com")
+	countConfigMaps := corev1.ResourceName("count/configmaps")
+
+	tests := []struct {
+		name           string
+		attr           admission.Attributes
+		quotas         []*corev1.ResourceQuota
+		expectedErrors []string
+		expectedUsed   map[string]string
+	}{
+		{
+			name: "passes without quota",
+			attr: createAttr("default", widgets, "", false),
+		},
+		{
+			name: "passes and charges below the limit",
+			attr: createAttr("default", widgets, "", false),
+			quotas: []*corev1.ResourceQuota{
+				newQuota("root:org:ws", "widgets", corev1.ResourceList{countWidgets: resource.MustParse("2")}, corev1.ResourceList{countWidgets: resource.MustParse("1")}),
+			},
+			expectedUsed: map[string]string{"widgets": "2"},
+		},
+		{
+			name: "passes without charging on dry run",
+			attr: createAttr("default", widgets, "", true),
+			quotas: []*corev1.ResourceQuota{
+				newQuota("root:org:ws", "widgets", corev1.ResourceList{countWidgets: resource.MustParse("2")}, corev1.ResourceList{countWidgets: resource.MustParse("1")}),
+			},
+			expectedUsed: map[string]string{"widgets": "1"},
+		},
+		{
+			name: "fails at the limit",
+			attr: createAttr("default", widgets, "", false),
+			quotas: []*corev1.ResourceQuota{
+				newQuota("root:org:ws", "widgets", corev1.ResourceList{countWidgets: resource.MustParse("1")}, corev1.ResourceList{countWidgets: resource.MustParse("1")}),
+			},
+			expectedErrors: []string{"exceeded quota: widgets, requested: count/widgets.example.com=1, used: count/widgets.example.com=1, limited: count/widgets.example.com=1"},
+			expectedUsed:   map[string]string{"widgets": "1"},
+		},
+		{
+			name: "fails without usage",
+			attr: createAttr("default", widgets, "", false),
+			quotas: []*corev1.ResourceQuota{
+				newQuota("root:org:ws", "widgets", corev1.ResourceList{countWidgets: resource.MustParse("1")}, nil),
+			},
+			expectedErrors: []string{"status unknown for quota: widgets, resources: count/widgets.example.com"},
+		},
+		{
+			name: "does not charge other quotas if one is exceeded",
+			attr: createAttr("default", widgets, "", false),
+			quotas: []*corev1.ResourceQuota{
+				newQuota("root:org:ws", "a", corev1.ResourceList{countWidgets: resource.MustParse("5")}, corev1.ResourceList{countWidgets: resource.MustParse("1")}),
+				newQuota("root:org:ws", "b", corev1.ResourceList{countWidgets: resource.MustParse("1")}, corev1.ResourceList{countWidgets: resource.MustParse("1")}),
+			},
+			expectedErrors: []string{"exceeded quota: b"},
+			expectedUsed:   map[string]string{"a": "1", "b": "1"},
+		},
+		{
+			name: "ignores quotas of other resources",
+			attr: createAttr("default", configMaps, "", false),
+			quotas: []*corev1.ResourceQuota{
+				newQuota("root:org:ws", "widgets", corev1.ResourceList{countWidgets: resource.MustParse("1")}, corev1.ResourceList{countWidgets: resource.MustParse("1")}),
+			},
+		},
+		{
+			name: "charges core resources",
+			attr: createAttr("default", configMaps, "", false),
+			quotas: []*corev1.ResourceQuota{
+				newQuota("root:org:ws", "configmaps", corev1.ResourceList{countConfigMaps: resource.MustParse("10")}, corev1.ResourceList{countConfigMaps: resource.MustParse("3")}),
+			},
+			expectedUsed: map[string]string{"configmaps": "4"},
+		},
+		{
+			name: "ignores quotas of other workspaces",
+			attr: createAttr("default", widgets, "", false),
+			quotas: []*corev1.ResourceQuota{
+				newQuota("root:org:other", "widgets", corev1.ResourceList{countWidgets: resource.MustParse("1")}, corev1.ResourceList{countWidgets: resource.MustParse("1")}),
+			},
+		},
+		{
+			name: "ignores subresources",
+			attr: createAttr("default", widgets, "status", false),
+			quotas: []*corev1.ResourceQuota{
+				newQuota("root:org:ws", "widgets", corev1.ResourceList{countWidgets: resource.MustParse("1")}, corev1.ResourceList{countWidgets: resource.MustParse("1")}),
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+			var objects []runtime.Object
+			for _, quota := range tc.quotas {
+				require.NoError(t, indexer.Add(quota))
+				objects = append(objects, quota.DeepCopy())
+			}
+			client := fake.NewSimpleClientset(objects...)
+
+			o := &resourceQuota{
+				Handler:           admission.NewHandler(admission.Create),
+				quotaLister:       corelisters.NewResourceQuotaLister(indexer),
+				kubeClusterClient: fakeClusterClient{client},
+			}
+			ctx := request.WithCluster(context.Background(), request.Cluster{Name: logicalcluster.New("root:org:ws")})
+
+			err := o.Validate(ctx, tc.attr, nil)
+			if len(tc.expectedErrors) == 0 {
+				require.NoError(t, err)
+			} else {
+				require.Error(t, err)
+				for _, expected := range tc.expectedErrors {
+					require.Contains(t, err.Error(), expected)
+				}
+			}
+
+			resourceName := quotav1generic.ObjectCountQuotaResourceNameFor(tc.attr.GetResource().GroupResource())
+			for name, expected := range tc.expectedUsed {
+				quota, err := client.CoreV1().ResourceQuotas("default").Get(ctx, name, metav1.GetOptions{})
+				require.NoError(t, err)
+				used := quota.Status.Used[resourceName]
+				require.Equal(t, expected, used.String(), "unexpected usage of quota %s", name)
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secretshare
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apiserver/pkg/admission"
+	"k8s.io/apiserver/pkg/authorization/authorizer"
+	genericapirequest "k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+
+	apisv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apis/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/authorization/delegated"
+)
+
+const (
+	PluginName = "apis.kcp.dev/SecretShare"
+)
+
+func Register(plugins *admission.Plugins) {
+	plugins.Register(PluginName,
+		func(_ io.Reader) (admission.Interface, error) {
+			return &secretShareAdmission{
+				Handler:          admission.NewHandler(admission.Create, admission.Update),
+				createAuthorizer: delegated.NewDelegatedAuthorizer,
+			}, nil
+		})
+}
+
+type secretShareAdmission struct {
+	*admission.Handler
+	kubeClusterClient *kubernetes.Cluster
+
+	createAuthorizer delegated.DelegatedAuthorizerFactory
+}
+
+// Ensure that the required admission interfaces are implemented.
+var _ = admission.ValidationInterface(&secretShareAdmission{})
+var _ = admission.InitializationValidator(&secretShareAdmission{})
+
+// Validate validates the creation and updating of SecretShare resources. It performs a SubjectAccessReview
+// making sure the user is allowed to get the shared Secret, i.e. nobody can share a Secret they cannot read.
+func (o *secretShareAdmission) Validate(ctx context.Context, a admission.Attributes, _ admission.ObjectInterfaces) error {
+	if a.GetResource().GroupResource() != apisv1alpha1.Resource("secretshares") {
+		return nil
+	}
+
+	u, ok := a.GetObject().(*unstructured.Unstructured)
+	if !ok {
+		return fmt.Errorf("unexpected type %T", a.GetObject())
+	}
+	share := &apisv1alpha1.SecretShare{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, share); err != nil {
+		return fmt.Errorf("failed to convert unstructured to SecretShare: %w", err)
+	}
+
+	if share.Spec.Export.Workspace == nil {
+		return admission.NewForbidden(a, errors.New("spec.export.workspace is required"))
+	}
+
+	cluster, err := genericapirequest.ValidClusterFrom(ctx)
+	if err != nil {
+		return admission.NewForbidden(a, fmt.Errorf("error determining workspace: %w", err))
+	}
+	authz, err := o.createAuthorizer(cluster.Name, o.kubeClusterClient)
+	if err != nil {
+		// Logging a more specific error for the operator
+		klog.Errorf("error creating authorizer from delegating authorizer config: %v", err)
+		// Returning a less specific error to the end user
+		return admission.NewForbidden(a, errors.New("unable to authorize request"))
+	}
+
+	getAttr := authorizer.AttributesRecord{
+		User:            a.GetUserInfo(),
+		Verb:            "get",
+		APIVersion:      "v1",
+		Resource:        "secrets",
+		Namespace:       share.Spec.SecretRef.Namespace,
+		Name:            share.Spec.SecretRef.Name,
+		ResourceRequest: true,
+	}
+	if decision, _, err := authz.Authorize(ctx, getAttr); err != nil {
+		return admission.NewForbidden(a, fmt.Errorf("unable to determine access to secrets: %w", err))
+	} else if decision != authorizer.DecisionAllow {
+		return admission.NewForbidden(a, fmt.Errorf("missing verb='get' permission on secret %s/%s", share.Spec.SecretRef.Namespace, share.Spec.SecretRef.Name))
+	}
+
+	return nil
+}
+
+// ValidateInitialization ensures the required injected fields are set.
+func (o *secretShareAdmission) ValidateInitialization() error {
+	if o.kubeClusterClient == nil {
+		return fmt.Errorf(PluginName + " plugin needs a Kubernetes ClusterInterface")
+	}
+
+	return nil
+}
+
+// SetKubeClusterClient is an admission plugin initializer function that injects a Kubernetes cluster client into
+// this admission plugin.
+func (o *secretShareAdmission) SetKubeClusterClient(clusterClient *kubernetes.Cluster) {
+	o.kubeClusterClient = clusterClient
+}
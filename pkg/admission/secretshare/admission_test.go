@@ -0,0 +1,131 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secretshare
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kcp-dev/logicalcluster"
+	"github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apiserver/pkg/admission"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/apiserver/pkg/authorization/authorizer"
+	"k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/kcp-dev/kcp/pkg/admission/helpers"
+	apisv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apis/v1alpha1"
+)
+
+func createAttr(obj runtime.Object) admission.Attributes {
+	return admission.NewAttributesRecord(
+		helpers.ToUnstructuredOrDie(obj),
+		nil,
+		apisv1alpha1.Kind("SecretShare").WithVersion("v1alpha1"),
+		"",
+		"test",
+		apisv1alpha1.Resource("secretshares").WithVersion("v1alpha1"),
+		"",
+		admission.Create,
+		&metav1.CreateOptions{},
+		false,
+		&user.DefaultInfo{},
+	)
+}
+
+func newSecretShare(export *apisv1alpha1.WorkspaceExportReference) *apisv1alpha1.SecretShare {
+	return &apisv1alpha1.SecretShare{
+		ObjectMeta: metav1.ObjectMeta{Name: "test"},
+		Spec: apisv1alpha1.SecretShareSpec{
+			SecretRef: apisv1alpha1.SharedSecretReference{Namespace: "default", Name: "credentials"},
+			Export:    apisv1alpha1.ExportReference{Workspace: export},
+		},
+	}
+}
+
+func TestValidate(t *testing.T) {
+	export := &apisv1alpha1.WorkspaceExportReference{WorkspaceName: "provider", ExportName: "widgets"}
+
+	tests := []struct {
+		name           string
+		attr           admission.Attributes
+		authzDecision  authorizer.Decision
+		expectedErrors []string
+	}{
+		{
+			name:          "share of a readable secret",
+			attr:          createAttr(newSecretShare(export)),
+			authzDecision: authorizer.DecisionAllow,
+		},
+		{
+			name:           "share of a secret not readable",
+			attr:           createAttr(newSecretShare(export)),
+			authzDecision:  authorizer.DecisionNoOpinion,
+			expectedErrors: []string{`missing verb='get' permission on secret default/credentials`},
+		},
+		{
+			name:           "share without export workspace",
+			attr:           createAttr(newSecretShare(nil)),
+			authzDecision:  authorizer.DecisionAllow,
+			expectedErrors: []string{"spec.export.workspace is required"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			authz := &fakeAuthorizer{authorized: tc.authzDecision}
+			o := &secretShareAdmission{
+				Handler: admission.NewHandler(admission.Create, admission.Update),
+				createAuthorizer: func(clusterName logicalcluster.Name, client kubernetes.ClusterInterface) (authorizer.Authorizer, error) {
+					require.Equal(t, "root:org:consumer", clusterName.String())
+					return authz, nil
+				},
+			}
+
+			ctx := request.WithCluster(context.Background(), request.Cluster{Name: logicalcluster.New("root:org:consumer")})
+
+			err := o.Validate(ctx, tc.attr, nil)
+
+			wantErr := len(tc.expectedErrors) > 0
+			require.Equal(t, wantErr, err != nil, "unexpected error: %v", err)
+			for _, expected := range tc.expectedErrors {
+				require.Contains(t, err.Error(), expected)
+			}
+
+			if authz.attr != nil {
+				require.Equal(t, "get", authz.attr.GetVerb())
+				require.Equal(t, "secrets", authz.attr.GetResource())
+				require.Equal(t, "default", authz.attr.GetNamespace())
+				require.Equal(t, "credentials", authz.attr.GetName())
+			}
+		})
+	}
+}
+
+type fakeAuthorizer struct {
+	authorized authorizer.Decision
+	attr       authorizer.Attributes
+}
+
+func (a *fakeAuthorizer) Authorize(ctx context.Context, attr authorizer.Attributes) (authorized authorizer.Decision, reason string, err error) {
+	a.attr = attr
+	return a.authorized, "reason", nil
+}
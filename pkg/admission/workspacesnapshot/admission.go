@@ -0,0 +1,267 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workspacesnapshot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apiserver/pkg/admission"
+	"k8s.io/apiserver/pkg/authorization/authorizer"
+	genericapirequest "k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+
+	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/authorization/bootstrap"
+	"github.com/kcp-dev/kcp/pkg/authorization/delegated"
+)
+
+// Validate WorkspaceSnapshot and ClusterWorkspace creation and updates for
+// - immutability of spec.workspace of WorkspaceSnapshots and of the snapshot annotation of ClusterWorkspaces
+// - admin permission on the content of the workspace captured by a WorkspaceSnapshot
+// - use permission on the WorkspaceSnapshot a ClusterWorkspace is created from.
+//
+// Add the snapshot initializer to ClusterWorkspaces created from a WorkspaceSnapshot when they
+// transition to initializing, depending on the initializers of their type.
+
+const (
+	PluginName = "tenancy.kcp.dev/WorkspaceSnapshot"
+)
+
+func Register(plugins *admission.Plugins) {
+	plugins.Register(PluginName,
+		func(_ io.Reader) (admission.Interface, error) {
+			return &workspaceSnapshot{
+				Handler:          admission.NewHandler(admission.Create, admission.Update),
+				createAuthorizer: delegated.NewDelegatedAuthorizer,
+			}, nil
+		})
+}
+
+type workspaceSnapshot struct {
+	*admission.Handler
+	kubeClusterClient *kubernetes.Cluster
+
+	createAuthorizer delegated.DelegatedAuthorizerFactory
+}
+
+// Ensure that the required admission interfaces are implemented.
+var _ = admission.MutationInterface(&workspaceSnapshot{})
+var _ = admission.ValidationInterface(&workspaceSnapshot{})
+var _ = admission.InitializationValidator(&workspaceSnapshot{})
+
+// Admit adds the snapshot initializer to ClusterWorkspaces with the snapshot annotation on the transition
+// to initializing. The ClusterWorkspaceType admission plugin has added the initializers of the type before.
+func (o *workspaceSnapshot) Admit(ctx context.Context, a admission.Attributes, _ admission.ObjectInterfaces) error {
+	if a.GetResource().GroupResource() != tenancyv1alpha1.Resource("clusterworkspaces") || a.GetOperation() != admission.Update {
+		return nil
+	}
+
+	u, ok := a.GetObject().(*unstructured.Unstructured)
+	if !ok {
+		return fmt.Errorf("unexpected type %T", a.GetObject())
+	}
+	if u.GetAnnotations()[tenancyv1alpha1.ExperimentalWorkspaceSnapshotAnnotationKey] == "" {
+		return nil
+	}
+	cw := &tenancyv1alpha1.ClusterWorkspace{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, cw); err != nil {
+		return fmt.Errorf("failed to convert unstructured to ClusterWorkspace: %w", err)
+	}
+	old := &tenancyv1alpha1.ClusterWorkspace{}
+	oldU, ok := a.GetOldObject().(*unstructured.Unstructured)
+	if !ok {
+		return fmt.Errorf("unexpected type %T", a.GetOldObject())
+	}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(oldU.Object, old); err != nil {
+		return fmt.Errorf("failed to convert unstructured to ClusterWorkspace: %w", err)
+	}
+
+	transitioningToInitializing := old.Status.Phase != tenancyv1alpha1.ClusterWorkspacePhaseInitializing &&
+		cw.Status.Phase == tenancyv1alpha1.ClusterWorkspacePhaseInitializing
+	if !transitioningToInitializing {
+		return nil
+	}
+
+	// restore after the initializers of the type, such that their objects are kept
+	var dependsOn []tenancyv1alpha1.ClusterWorkspaceInitializer
+	for _, i := range cw.Status.Initializers {
+		if i == tenancyv1alpha1.WorkspaceSnapshotInitializer {
+			return nil
+		}
+		dependsOn = append(dependsOn, i)
+	}
+	cw.Status.Initializers = append(cw.Status.Initializers, tenancyv1alpha1.WorkspaceSnapshotInitializer)
+	if len(dependsOn) > 0 {
+		cw.Status.InitializerDependencies = append(cw.Status.InitializerDependencies, tenancyv1alpha1.ClusterWorkspaceInitializerDependency{
+			Initializer: tenancyv1alpha1.WorkspaceSnapshotInitializer,
+			DependsOn:   dependsOn,
+		})
+	}
+
+	raw, err := runtime.DefaultUnstructuredConverter.ToUnstructured(cw)
+	if err != nil {
+		return err
+	}
+	u.Object = raw
+	return nil
+}
+
+func (o *workspaceSnapshot) Validate(ctx context.Context, a admission.Attributes, _ admission.ObjectInterfaces) error {
+	switch a.GetResource().GroupResource() {
+	case tenancyv1alpha1.Resource("workspacesnapshots"):
+		return o.validateSnapshot(ctx, a)
+	case tenancyv1alpha1.Resource("clusterworkspaces"):
+		return o.validateWorkspace(ctx, a)
+	}
+	return nil
+}
+
+func (o *workspaceSnapshot) validateSnapshot(ctx context.Context, a admission.Attributes) error {
+	if a.GetSubresource() != "" {
+		return nil
+	}
+
+	snapshot := &tenancyv1alpha1.WorkspaceSnapshot{}
+	if err := fromUnstructured(a.GetObject(), snapshot); err != nil {
+		return err
+	}
+
+	if a.GetOperation() == admission.Update {
+		old := &tenancyv1alpha1.WorkspaceSnapshot{}
+		if err := fromUnstructured(a.GetOldObject(), old); err != nil {
+			return err
+		}
+		if old.Spec.Workspace != snapshot.Spec.Workspace {
+			return admission.NewForbidden(a, errors.New("spec.workspace is immutable"))
+		}
+		return nil
+	}
+
+	attr := authorizer.AttributesRecord{
+		User:            a.GetUserInfo(),
+		Verb:            bootstrap.WorkspaceAdminVerb,
+		APIGroup:        tenancyv1alpha1.SchemeGroupVersion.Group,
+		APIVersion:      tenancyv1alpha1.SchemeGroupVersion.Version,
+		Resource:        "clusterworkspaces",
+		Subresource:     "content",
+		Name:            snapshot.Spec.Workspace,
+		ResourceRequest: true,
+	}
+	if err := o.authorize(ctx, attr); err != nil {
+		return admission.NewForbidden(a, fmt.Errorf("unable to snapshot workspace %q: %w", snapshot.Spec.Workspace, err))
+	}
+	return nil
+}
+
+func (o *workspaceSnapshot) validateWorkspace(ctx context.Context, a admission.Attributes) error {
+	if a.GetSubresource() != "" {
+		return nil
+	}
+
+	u, ok := a.GetObject().(*unstructured.Unstructured)
+	if !ok {
+		return fmt.Errorf("unexpected type %T", a.GetObject())
+	}
+	snapshotName := u.GetAnnotations()[tenancyv1alpha1.ExperimentalWorkspaceSnapshotAnnotationKey]
+
+	if a.GetOperation() == admission.Update {
+		old, ok := a.GetOldObject().(*unstructured.Unstructured)
+		if !ok {
+			return fmt.Errorf("unexpected type %T", a.GetOldObject())
+		}
+		if old.GetAnnotations()[tenancyv1alpha1.ExperimentalWorkspaceSnapshotAnnotationKey] != snapshotName {
+			return admission.NewForbidden(a, fmt.Errorf("annotation %s is immutable", tenancyv1alpha1.ExperimentalWorkspaceSnapshotAnnotationKey))
+		}
+		return nil
+	}
+
+	if snapshotName == "" {
+		return nil
+	}
+	attr := authorizer.AttributesRecord{
+		User:            a.GetUserInfo(),
+		Verb:            "use",
+		APIGroup:        tenancyv1alpha1.SchemeGroupVersion.Group,
+		APIVersion:      tenancyv1alpha1.SchemeGroupVersion.Version,
+		Resource:        "workspacesnapshots",
+		Name:            snapshotName,
+		ResourceRequest: true,
+	}
+	if err := o.authorize(ctx, attr); err != nil {
+		return admission.NewForbidden(a, fmt.Errorf("unable to create workspace from snapshot %q: %w", snapshotName, err))
+	}
+	return nil
+}
+
+// authorize checks the given attributes in the workspace of the request.
+func (o *workspaceSnapshot) authorize(ctx context.Context, attr authorizer.AttributesRecord) error {
+	cluster, err := genericapirequest.ValidClusterFrom(ctx)
+	if err != nil {
+		return fmt.Errorf("error determining workspace: %w", err)
+	}
+
+	authz, err := o.createAuthorizer(cluster.Name, o.kubeClusterClient)
+	if err != nil {
+		// Logging a more specific error for the operator
+		klog.Errorf("error creating authorizer from delegating authorizer config: %v", err)
+		// Returning a less specific error to the end user
+		return errors.New("unable to authorize request")
+	}
+
+	if decision, _, err := authz.Authorize(ctx, attr); err != nil {
+		return fmt.Errorf("unable to determine access to %s: %w", attr.Resource, err)
+	} else if decision != authorizer.DecisionAllow {
+		resource := attr.Resource
+		if attr.Subresource != "" {
+			resource += "/" + attr.Subresource
+		}
+		return fmt.Errorf("missing verb=%q permission on %s", attr.Verb, resource)
+	}
+	return nil
+}
+
+func fromUnstructured(obj runtime.Object, into *tenancyv1alpha1.WorkspaceSnapshot) error {
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return fmt.Errorf("unexpected type %T", obj)
+	}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, into); err != nil {
+		return fmt.Errorf("failed to convert unstructured to WorkspaceSnapshot: %w", err)
+	}
+	return nil
+}
+
+// ValidateInitialization ensures the required injected fields are set.
+func (o *workspaceSnapshot) ValidateInitialization() error {
+	if o.kubeClusterClient == nil {
+		return fmt.Errorf(PluginName + " plugin needs a Kubernetes ClusterInterface")
+	}
+
+	return nil
+}
+
+// SetKubeClusterClient is an admission plugin initializer function that injects a Kubernetes cluster client into
+// this admission plugin.
+func (o *workspaceSnapshot) SetKubeClusterClient(clusterClient *kubernetes.Cluster) {
+	o.kubeClusterClient = clusterClient
+}
@@ -0,0 +1,242 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workspacesnapshot
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kcp-dev/logicalcluster"
+	"github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apiserver/pkg/admission"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/apiserver/pkg/authorization/authorizer"
+	"k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/kcp-dev/kcp/pkg/admission/helpers"
+	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+)
+
+func createAttr(obj runtime.Object, kind, resource string) admission.Attributes {
+	return admission.NewAttributesRecord(
+		helpers.ToUnstructuredOrDie(obj),
+		nil,
+		tenancyv1alpha1.Kind(kind).WithVersion("v1alpha1"),
+		"",
+		"test",
+		tenancyv1alpha1.Resource(resource).WithVersion("v1alpha1"),
+		"",
+		admission.Create,
+		&metav1.CreateOptions{},
+		false,
+		&user.DefaultInfo{},
+	)
+}
+
+func updateAttr(obj, old runtime.Object, kind, resource string) admission.Attributes {
+	return admission.NewAttributesRecord(
+		helpers.ToUnstructuredOrDie(obj),
+		helpers.ToUnstructuredOrDie(old),
+		tenancyv1alpha1.Kind(kind).WithVersion("v1alpha1"),
+		"",
+		"test",
+		tenancyv1alpha1.Resource(resource).WithVersion("v1alpha1"),
+		"",
+		admission.Update,
+		&metav1.UpdateOptions{},
+		false,
+		&user.DefaultInfo{},
+	)
+}
+
+func newSnapshot(workspace string) *tenancyv1alpha1.WorkspaceSnapshot {
+	return &tenancyv1alpha1.WorkspaceSnapshot{
+		ObjectMeta: metav1.ObjectMeta{Name: "test"},
+		Spec:       tenancyv1alpha1.WorkspaceSnapshotSpec{Workspace: workspace},
+	}
+}
+
+func newWorkspace(snapshot string) *tenancyv1alpha1.ClusterWorkspace {
+	ws := &tenancyv1alpha1.ClusterWorkspace{ObjectMeta: metav1.ObjectMeta{Name: "test"}}
+	if snapshot != "" {
+		ws.Annotations = map[string]string{tenancyv1alpha1.ExperimentalWorkspaceSnapshotAnnotationKey: snapshot}
+	}
+	return ws
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name           string
+		attr           admission.Attributes
+		authzDecision  authorizer.Decision
+		expectedAttr   *authorizer.AttributesRecord
+		expectedErrors []string
+	}{
+		{
+			name:          "snapshot creation by workspace admin",
+			attr:          createAttr(newSnapshot("staging"), "WorkspaceSnapshot", "workspacesnapshots"),
+			authzDecision: authorizer.DecisionAllow,
+			expectedAttr:  &authorizer.AttributesRecord{Verb: "admin", Resource: "clusterworkspaces", Subresource: "content", Name: "staging"},
+		},
+		{
+			name:           "snapshot creation by non-admin",
+			attr:           createAttr(newSnapshot("staging"), "WorkspaceSnapshot", "workspacesnapshots"),
+			authzDecision:  authorizer.DecisionNoOpinion,
+			expectedErrors: []string{`missing verb="admin" permission on clusterworkspaces/content`},
+		},
+		{
+			name:           "snapshot workspace change",
+			attr:           updateAttr(newSnapshot("prod"), newSnapshot("staging"), "WorkspaceSnapshot", "workspacesnapshots"),
+			authzDecision:  authorizer.DecisionAllow,
+			expectedErrors: []string{"spec.workspace is immutable"},
+		},
+		{
+			name:          "workspace creation without snapshot",
+			attr:          createAttr(newWorkspace(""), "ClusterWorkspace", "clusterworkspaces"),
+			authzDecision: authorizer.DecisionNoOpinion,
+		},
+		{
+			name:          "workspace creation from snapshot with use permission",
+			attr:          createAttr(newWorkspace("staging"), "ClusterWorkspace", "clusterworkspaces"),
+			authzDecision: authorizer.DecisionAllow,
+			expectedAttr:  &authorizer.AttributesRecord{Verb: "use", Resource: "workspacesnapshots", Name: "staging"},
+		},
+		{
+			name:           "workspace creation from snapshot without use permission",
+			attr:           createAttr(newWorkspace("staging"), "ClusterWorkspace", "clusterworkspaces"),
+			authzDecision:  authorizer.DecisionNoOpinion,
+			expectedErrors: []string{`missing verb="use" permission on workspacesnapshots`},
+		},
+		{
+			name:           "workspace snapshot annotation change",
+			attr:           updateAttr(newWorkspace("other"), newWorkspace("staging"), "ClusterWorkspace", "clusterworkspaces"),
+			authzDecision:  authorizer.DecisionAllow,
+			expectedErrors: []string{"is immutable"},
+		},
+		{
+			name:          "workspace update keeping the snapshot annotation",
+			attr:          updateAttr(newWorkspace("staging"), newWorkspace("staging"), "ClusterWorkspace", "clusterworkspaces"),
+			authzDecision: authorizer.DecisionNoOpinion,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			authz := &fakeAuthorizer{authorized: tc.authzDecision}
+			o := &workspaceSnapshot{
+				Handler: admission.NewHandler(admission.Create, admission.Update),
+				createAuthorizer: func(clusterName logicalcluster.Name, client kubernetes.ClusterInterface) (authorizer.Authorizer, error) {
+					require.Equal(t, "root:org", clusterName.String())
+					return authz, nil
+				},
+			}
+
+			ctx := request.WithCluster(context.Background(), request.Cluster{Name: logicalcluster.New("root:org")})
+
+			err := o.Validate(ctx, tc.attr, nil)
+
+			wantErr := len(tc.expectedErrors) > 0
+			require.Equal(t, wantErr, err != nil, "unexpected error: %v", err)
+			for _, expected := range tc.expectedErrors {
+				require.Contains(t, err.Error(), expected)
+			}
+
+			if tc.expectedAttr != nil {
+				require.NotNil(t, authz.attr)
+				require.Equal(t, tc.expectedAttr.Verb, authz.attr.GetVerb())
+				require.Equal(t, tc.expectedAttr.Resource, authz.attr.GetResource())
+				require.Equal(t, tc.expectedAttr.Subresource, authz.attr.GetSubresource())
+				require.Equal(t, tc.expectedAttr.Name, authz.attr.GetName())
+			}
+		})
+	}
+}
+
+func TestAdmit(t *testing.T) {
+	initializing := func(ws *tenancyv1alpha1.ClusterWorkspace, initializers ...tenancyv1alpha1.ClusterWorkspaceInitializer) *tenancyv1alpha1.ClusterWorkspace {
+		ws.Status.Phase = tenancyv1alpha1.ClusterWorkspacePhaseInitializing
+		ws.Status.Initializers = initializers
+		return ws
+	}
+	scheduling := func(ws *tenancyv1alpha1.ClusterWorkspace) *tenancyv1alpha1.ClusterWorkspace {
+		ws.Status.Phase = tenancyv1alpha1.ClusterWorkspacePhaseScheduling
+		return ws
+	}
+	snapshotInitializer := tenancyv1alpha1.WorkspaceSnapshotInitializer
+
+	tests := []struct {
+		name                    string
+		attr                    admission.Attributes
+		expectedInitializers    []tenancyv1alpha1.ClusterWorkspaceInitializer
+		expectedDependencies    []tenancyv1alpha1.ClusterWorkspaceInitializerDependency
+		expectedUnchangedObject bool
+	}{
+		{
+			name:                    "workspace without snapshot",
+			attr:                    updateAttr(initializing(newWorkspace(""), "a"), scheduling(newWorkspace("")), "ClusterWorkspace", "clusterworkspaces"),
+			expectedUnchangedObject: true,
+		},
+		{
+			name:                 "workspace from snapshot transitioning to initializing",
+			attr:                 updateAttr(initializing(newWorkspace("staging"), "a", "b"), scheduling(newWorkspace("staging")), "ClusterWorkspace", "clusterworkspaces"),
+			expectedInitializers: []tenancyv1alpha1.ClusterWorkspaceInitializer{"a", "b", snapshotInitializer},
+			expectedDependencies: []tenancyv1alpha1.ClusterWorkspaceInitializerDependency{{Initializer: snapshotInitializer, DependsOn: []tenancyv1alpha1.ClusterWorkspaceInitializer{"a", "b"}}},
+		},
+		{
+			name:                 "workspace from snapshot of a type without initializers",
+			attr:                 updateAttr(initializing(newWorkspace("staging")), scheduling(newWorkspace("staging")), "ClusterWorkspace", "clusterworkspaces"),
+			expectedInitializers: []tenancyv1alpha1.ClusterWorkspaceInitializer{snapshotInitializer},
+		},
+		{
+			name:                    "workspace from snapshot already initializing",
+			attr:                    updateAttr(initializing(newWorkspace("staging"), "a"), initializing(newWorkspace("staging"), "a", snapshotInitializer), "ClusterWorkspace", "clusterworkspaces"),
+			expectedUnchangedObject: true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			o := &workspaceSnapshot{Handler: admission.NewHandler(admission.Create, admission.Update)}
+			before := tc.attr.GetObject().DeepCopyObject()
+
+			require.NoError(t, o.Admit(context.Background(), tc.attr, nil))
+
+			if tc.expectedUnchangedObject {
+				require.Equal(t, before, tc.attr.GetObject())
+				return
+			}
+			ws := &tenancyv1alpha1.ClusterWorkspace{}
+			require.NoError(t, runtime.DefaultUnstructuredConverter.FromUnstructured(tc.attr.GetObject().(*unstructured.Unstructured).Object, ws))
+			require.Equal(t, tc.expectedInitializers, ws.Status.Initializers)
+			require.Equal(t, tc.expectedDependencies, ws.Status.InitializerDependencies)
+		})
+	}
+}
+
+type fakeAuthorizer struct {
+	authorized authorizer.Decision
+	attr       authorizer.Attributes
+}
+
+func (a *fakeAuthorizer) Authorize(ctx context.Context, attr authorizer.Attributes) (authorized authorizer.Decision, reason string, err error) {
+	a.attr = attr
+	return a.authorized, "reason", nil
+}
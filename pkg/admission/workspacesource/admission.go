@@ -0,0 +1,127 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workspacesource
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apiserver/pkg/admission"
+	"k8s.io/apiserver/pkg/authorization/authorizer"
+	genericapirequest "k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+
+	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/authorization/delegated"
+)
+
+const (
+	PluginName = "tenancy.kcp.dev/WorkspaceSource"
+)
+
+func Register(plugins *admission.Plugins) {
+	plugins.Register(PluginName,
+		func(_ io.Reader) (admission.Interface, error) {
+			return &workspaceSourceAdmission{
+				Handler:          admission.NewHandler(admission.Create, admission.Update),
+				createAuthorizer: delegated.NewDelegatedAuthorizer,
+			}, nil
+		})
+}
+
+type workspaceSourceAdmission struct {
+	*admission.Handler
+	kubeClusterClient *kubernetes.Cluster
+
+	createAuthorizer delegated.DelegatedAuthorizerFactory
+}
+
+// Ensure that the required admission interfaces are implemented.
+var _ = admission.ValidationInterface(&workspaceSourceAdmission{})
+var _ = admission.InitializationValidator(&workspaceSourceAdmission{})
+
+// Validate validates the creation and updating of WorkspaceSource resources. It performs a SubjectAccessReview
+// making sure the user is allowed to impersonate the service account of the WorkspaceSource, i.e. nobody can
+// apply objects with permissions they do not have.
+func (o *workspaceSourceAdmission) Validate(ctx context.Context, a admission.Attributes, _ admission.ObjectInterfaces) error {
+	if a.GetResource().GroupResource() != tenancyv1alpha1.Resource("workspacesources") || a.GetSubresource() != "" {
+		return nil
+	}
+
+	u, ok := a.GetObject().(*unstructured.Unstructured)
+	if !ok {
+		return fmt.Errorf("unexpected type %T", a.GetObject())
+	}
+	source := &tenancyv1alpha1.WorkspaceSource{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, source); err != nil {
+		return fmt.Errorf("failed to convert unstructured to WorkspaceSource: %w", err)
+	}
+
+	ref := source.Spec.ServiceAccountRef
+	if ref.Namespace == "" || ref.Name == "" {
+		return admission.NewForbidden(a, errors.New("spec.serviceAccountRef is required"))
+	}
+
+	cluster, err := genericapirequest.ValidClusterFrom(ctx)
+	if err != nil {
+		return admission.NewForbidden(a, fmt.Errorf("error determining workspace: %w", err))
+	}
+	authz, err := o.createAuthorizer(cluster.Name, o.kubeClusterClient)
+	if err != nil {
+		// Logging a more specific error for the operator
+		klog.Errorf("error creating authorizer from delegating authorizer config: %v", err)
+		// Returning a less specific error to the end user
+		return admission.NewForbidden(a, errors.New("unable to authorize request"))
+	}
+
+	impersonateAttr := authorizer.AttributesRecord{
+		User:            a.GetUserInfo(),
+		Verb:            "impersonate",
+		APIVersion:      "v1",
+		Resource:        "serviceaccounts",
+		Namespace:       ref.Namespace,
+		Name:            ref.Name,
+		ResourceRequest: true,
+	}
+	if decision, _, err := authz.Authorize(ctx, impersonateAttr); err != nil {
+		return admission.NewForbidden(a, fmt.Errorf("unable to determine access to serviceaccounts: %w", err))
+	} else if decision != authorizer.DecisionAllow {
+		return admission.NewForbidden(a, fmt.Errorf("missing verb='impersonate' permission on serviceaccount %s/%s", ref.Namespace, ref.Name))
+	}
+
+	return nil
+}
+
+// ValidateInitialization ensures the required injected fields are set.
+func (o *workspaceSourceAdmission) ValidateInitialization() error {
+	if o.kubeClusterClient == nil {
+		return fmt.Errorf(PluginName + " plugin needs a Kubernetes ClusterInterface")
+	}
+
+	return nil
+}
+
+// SetKubeClusterClient is an admission plugin initializer function that injects a Kubernetes cluster client into
+// this admission plugin.
+func (o *workspaceSourceAdmission) SetKubeClusterClient(clusterClient *kubernetes.Cluster) {
+	o.kubeClusterClient = clusterClient
+}
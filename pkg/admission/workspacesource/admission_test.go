@@ -0,0 +1,140 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workspacesource
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kcp-dev/logicalcluster"
+	"github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apiserver/pkg/admission"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/apiserver/pkg/authorization/authorizer"
+	"k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/kcp-dev/kcp/pkg/admission/helpers"
+	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+)
+
+func createAttr(obj runtime.Object, subresource string) admission.Attributes {
+	return admission.NewAttributesRecord(
+		helpers.ToUnstructuredOrDie(obj),
+		nil,
+		tenancyv1alpha1.Kind("WorkspaceSource").WithVersion("v1alpha1"),
+		"",
+		"test",
+		tenancyv1alpha1.Resource("workspacesources").WithVersion("v1alpha1"),
+		subresource,
+		admission.Create,
+		&metav1.CreateOptions{},
+		false,
+		&user.DefaultInfo{},
+	)
+}
+
+func newWorkspaceSource(ref tenancyv1alpha1.WorkspaceSourceServiceAccountReference) *tenancyv1alpha1.WorkspaceSource {
+	return &tenancyv1alpha1.WorkspaceSource{
+		ObjectMeta: metav1.ObjectMeta{Name: "test"},
+		Spec: tenancyv1alpha1.WorkspaceSourceSpec{
+			URL:               "https://github.com/example/platform.git",
+			ServiceAccountRef: ref,
+		},
+	}
+}
+
+func TestValidate(t *testing.T) {
+	applier := tenancyv1alpha1.WorkspaceSourceServiceAccountReference{Namespace: "gitops", Name: "applier"}
+
+	tests := []struct {
+		name           string
+		attr           admission.Attributes
+		authzDecision  authorizer.Decision
+		wantAuthorized bool
+		expectedErrors []string
+	}{
+		{
+			name:           "service account that can be impersonated",
+			attr:           createAttr(newWorkspaceSource(applier), ""),
+			authzDecision:  authorizer.DecisionAllow,
+			wantAuthorized: true,
+		},
+		{
+			name:           "service account that cannot be impersonated",
+			attr:           createAttr(newWorkspaceSource(applier), ""),
+			authzDecision:  authorizer.DecisionNoOpinion,
+			wantAuthorized: true,
+			expectedErrors: []string{`missing verb='impersonate' permission on serviceaccount gitops/applier`},
+		},
+		{
+			name:           "without service account",
+			attr:           createAttr(newWorkspaceSource(tenancyv1alpha1.WorkspaceSourceServiceAccountReference{}), ""),
+			authzDecision:  authorizer.DecisionAllow,
+			expectedErrors: []string{"spec.serviceAccountRef is required"},
+		},
+		{
+			name:          "status updates are not checked",
+			attr:          createAttr(newWorkspaceSource(applier), "status"),
+			authzDecision: authorizer.DecisionNoOpinion,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			authz := &fakeAuthorizer{authorized: tc.authzDecision}
+			o := &workspaceSourceAdmission{
+				Handler: admission.NewHandler(admission.Create, admission.Update),
+				createAuthorizer: func(clusterName logicalcluster.Name, client kubernetes.ClusterInterface) (authorizer.Authorizer, error) {
+					require.Equal(t, "root:org:ws", clusterName.String())
+					return authz, nil
+				},
+			}
+
+			ctx := request.WithCluster(context.Background(), request.Cluster{Name: logicalcluster.New("root:org:ws")})
+
+			err := o.Validate(ctx, tc.attr, nil)
+
+			wantErr := len(tc.expectedErrors) > 0
+			require.Equal(t, wantErr, err != nil, "unexpected error: %v", err)
+			for _, expected := range tc.expectedErrors {
+				require.Contains(t, err.Error(), expected)
+			}
+
+			require.Equal(t, tc.wantAuthorized, authz.attr != nil)
+			if authz.attr != nil {
+				require.Equal(t, "impersonate", authz.attr.GetVerb())
+				require.Equal(t, "serviceaccounts", authz.attr.GetResource())
+				require.Equal(t, "gitops", authz.attr.GetNamespace())
+				require.Equal(t, "applier", authz.attr.GetName())
+			}
+		})
+	}
+}
+
+type fakeAuthorizer struct {
+	authorized authorizer.Decision
+	attr       authorizer.Attributes
+}
+
+func (a *fakeAuthorizer) Authorize(ctx context.Context, attr authorizer.Attributes) (authorized authorizer.Decision, reason string, err error) {
+	a.attr = attr
+	return a.authorized, "reason", nil
+}
@@ -0,0 +1,112 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workspacestoragelimit
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/kcp-dev/logicalcluster"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apiserver/pkg/admission"
+	genericapirequest "k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/client-go/tools/clusters"
+
+	kcpinitializers "github.com/kcp-dev/kcp/pkg/admission/initializers"
+	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+	kcpinformers "github.com/kcp-dev/kcp/pkg/client/informers/externalversions"
+	"github.com/kcp-dev/kcp/third_party/conditions/util/conditions"
+)
+
+// Validate creates in workspaces over their storage limits:
+// - creates in a workspace whose StorageWithinLimits condition is False are rejected
+// - updates, deletes and creates of subresources or of reviews, which are not persisted, are still allowed.
+
+const (
+	PluginName = "tenancy.kcp.dev/WorkspaceStorageLimit"
+)
+
+// exemptGroups hold the review APIs. Their creates are not persisted.
+var exemptGroups = map[string]bool{
+	"authentication.k8s.io": true,
+	"authorization.k8s.io":  true,
+}
+
+func Register(plugins *admission.Plugins) {
+	plugins.Register(PluginName,
+		func(_ io.Reader) (admission.Interface, error) {
+			return &workspaceStorageLimit{
+				Handler: admission.NewHandler(admission.Create),
+			}, nil
+		})
+}
+
+type workspaceStorageLimit struct {
+	*admission.Handler
+	getWorkspace func(clusterName logicalcluster.Name, name string) (*tenancyv1alpha1.ClusterWorkspace, error)
+}
+
+// Ensure that the required admission interfaces are implemented.
+var _ = admission.ValidationInterface(&workspaceStorageLimit{})
+var _ = admission.InitializationValidator(&workspaceStorageLimit{})
+var _ = kcpinitializers.WantsKcpInformers(&workspaceStorageLimit{})
+
+func (o *workspaceStorageLimit) Validate(ctx context.Context, a admission.Attributes, _ admission.ObjectInterfaces) error {
+	if a.GetOperation() != admission.Create || a.GetSubresource() != "" || exemptGroups[a.GetResource().Group] {
+		return nil
+	}
+
+	clusterName, err := genericapirequest.ClusterNameFrom(ctx)
+	if err != nil {
+		return apierrors.NewInternalError(err)
+	}
+	parent, hasParent := clusterName.Parent()
+	if !hasParent {
+		return nil
+	}
+
+	workspace, err := o.getWorkspace(parent, clusterName.Base())
+	if apierrors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return apierrors.NewInternalError(err)
+	}
+
+	if !conditions.IsFalse(workspace, tenancyv1alpha1.WorkspaceStorageWithinLimits) {
+		return nil
+	}
+	return admission.NewForbidden(a, fmt.Errorf("workspace %s is over its storage limits: %s", clusterName, conditions.GetMessage(workspace, tenancyv1alpha1.WorkspaceStorageWithinLimits)))
+}
+
+// ValidateInitialization ensures the required injected fields are set.
+func (o *workspaceStorageLimit) ValidateInitialization() error {
+	if o.getWorkspace == nil {
+		return fmt.Errorf(PluginName + " plugin needs a ClusterWorkspace lister")
+	}
+	return nil
+}
+
+func (o *workspaceStorageLimit) SetKcpInformers(informers kcpinformers.SharedInformerFactory) {
+	o.SetReadyFunc(informers.Tenancy().V1alpha1().ClusterWorkspaces().Informer().HasSynced)
+	workspaceLister := informers.Tenancy().V1alpha1().ClusterWorkspaces().Lister()
+	o.getWorkspace = func(clusterName logicalcluster.Name, name string) (*tenancyv1alpha1.ClusterWorkspace, error) {
+		return workspaceLister.Get(clusters.ToClusterAwareKey(clusterName, name))
+	}
+}
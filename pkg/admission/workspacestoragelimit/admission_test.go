@@ -0,0 +1,150 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workspacestoragelimit
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kcp-dev/logicalcluster"
+	"github.com/stretchr/testify/require"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apiserver/pkg/admission"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/apiserver/pkg/endpoints/request"
+
+	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+	conditionsv1alpha1 "github.com/kcp-dev/kcp/third_party/conditions/apis/conditions/v1alpha1"
+	"github.com/kcp-dev/kcp/third_party/conditions/util/conditions"
+)
+
+func attr(op admission.Operation, obj runtime.Object, gvr schema.GroupVersionResource, subresource string) admission.Attributes {
+	return admission.NewAttributesRecord(
+		obj,
+		nil,
+		gvr.GroupVersion().WithKind("Kind"),
+		"",
+		"test",
+		gvr,
+		subresource,
+		op,
+		nil,
+		false,
+		&user.DefaultInfo{},
+	)
+}
+
+func TestValidate(t *testing.T) {
+	configMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "test"}}
+	configMaps := corev1.SchemeGroupVersion.WithResource("configmaps")
+
+	full := &tenancyv1alpha1.ClusterWorkspace{ObjectMeta: metav1.ObjectMeta{Name: "full"}}
+	conditions.MarkFalse(full, tenancyv1alpha1.WorkspaceStorageWithinLimits, tenancyv1alpha1.WorkspaceStorageWithinLimitsReasonObjectCountExceeded, conditionsv1alpha1.ConditionSeverityError, "too many objects")
+	within := &tenancyv1alpha1.ClusterWorkspace{ObjectMeta: metav1.ObjectMeta{Name: "within"}}
+	conditions.MarkTrue(within, tenancyv1alpha1.WorkspaceStorageWithinLimits)
+
+	workspaces := map[string]*tenancyv1alpha1.ClusterWorkspace{
+		"full":      full,
+		"within":    within,
+		"unlimited": {ObjectMeta: metav1.ObjectMeta{Name: "unlimited"}},
+	}
+
+	tests := []struct {
+		name           string
+		cluster        string
+		attr           admission.Attributes
+		expectedErrors []string
+	}{
+		{
+			name:           "create in workspace over limits",
+			cluster:        "root:org:full",
+			attr:           attr(admission.Create, configMap, configMaps, ""),
+			expectedErrors: []string{"workspace root:org:full is over its storage limits: too many objects"},
+		},
+		{
+			name:    "update in workspace over limits",
+			cluster: "root:org:full",
+			attr:    attr(admission.Update, configMap, configMaps, ""),
+		},
+		{
+			name:    "delete in workspace over limits",
+			cluster: "root:org:full",
+			attr:    attr(admission.Delete, nil, configMaps, ""),
+		},
+		{
+			name:    "create of subresource in workspace over limits",
+			cluster: "root:org:full",
+			attr:    attr(admission.Create, nil, corev1.SchemeGroupVersion.WithResource("serviceaccounts"), "token"),
+		},
+		{
+			name:    "create of review in workspace over limits",
+			cluster: "root:org:full",
+			attr:    attr(admission.Create, &authorizationv1.SelfSubjectAccessReview{}, authorizationv1.SchemeGroupVersion.WithResource("selfsubjectaccessreviews"), ""),
+		},
+		{
+			name:    "create in workspace within limits",
+			cluster: "root:org:within",
+			attr:    attr(admission.Create, configMap, configMaps, ""),
+		},
+		{
+			name:    "create in workspace without limits",
+			cluster: "root:org:unlimited",
+			attr:    attr(admission.Create, configMap, configMaps, ""),
+		},
+		{
+			name:    "create in unknown workspace",
+			cluster: "root:org:unknown",
+			attr:    attr(admission.Create, configMap, configMaps, ""),
+		},
+		{
+			name:    "create in root",
+			cluster: "root",
+			attr:    attr(admission.Create, configMap, configMaps, ""),
+		},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			o := &workspaceStorageLimit{
+				Handler: admission.NewHandler(admission.Create),
+				getWorkspace: func(clusterName logicalcluster.Name, name string) (*tenancyv1alpha1.ClusterWorkspace, error) {
+					if clusterName.String() != "root:org" || workspaces[name] == nil {
+						return nil, apierrors.NewNotFound(tenancyv1alpha1.Resource("clusterworkspaces"), name)
+					}
+					return workspaces[name], nil
+				},
+			}
+
+			ctx := request.WithCluster(context.Background(), request.Cluster{Name: logicalcluster.New(tc.cluster)})
+
+			err := o.Validate(ctx, tc.attr, nil)
+
+			wantErr := len(tc.expectedErrors) > 0
+			require.Equal(t, wantErr, err != nil, "unexpected error: %v", err)
+			for _, expected := range tc.expectedErrors {
+				require.Contains(t, err.Error(), expected)
+			}
+		})
+	}
+}
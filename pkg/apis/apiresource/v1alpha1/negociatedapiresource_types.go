@@ -31,6 +31,7 @@ import (
 // +kubebuilder:subresource:status
 // +kubebuilder:resource:scope=Cluster,categories=kcp
 // +kubebuilder:printcolumn:name="Publish",type="boolean",JSONPath=`.spec.publish`,priority=1
+// +kubebuilder:printcolumn:name="Approved",type="string",JSONPath=`.status.conditions[?(@.type=="Approved")].status`,priority=2
 // +kubebuilder:printcolumn:name="API Version",type="string",JSONPath=`.metadata.annotations.apiresource\.kcp\.dev/apiVersion`,priority=3
 // +kubebuilder:printcolumn:name="API Resource",type="string",JSONPath=`.spec.plural`,priority=4
 // +kubebuilder:printcolumn:name="Published",type="string",JSONPath=`.status.conditions[?(@.type=="Published")].status`,priority=5
@@ -50,7 +51,11 @@ type NegotiatedAPIResource struct {
 // NegotiatedAPIResourceSpec holds the desired state of the NegotiatedAPIResource (from the client).
 type NegotiatedAPIResourceSpec struct {
 	CommonAPIResourceSpec `json:",inline"`
-	Publish               bool `json:"publish,omitempty"`
+
+	// publish approves the negotiated API resource to be published into the logical cluster
+	// as a CRD. It is set either by the approval policy of the controller when the resource is
+	// negotiated, or manually, e.g. with `kubectl kcp workload approve-api`.
+	Publish bool `json:"publish,omitempty"`
 }
 
 // NegotiatedAPIResourceConditionType is a valid value for NegotiatedAPIResourceCondition.Type
@@ -78,11 +83,27 @@ const (
 	// enforced CRD schema, and flag the API Resource import (and possibly the corresponding cluster location)
 	// accordingly.
 	Enforced NegotiatedAPIResourceConditionType = "Enforced"
+
+	// Approved means that this negotiated API Resource is approved to be published,
+	// i.e. spec.publish is true. If false, the API Resource is waiting for a manual approval.
+	Approved NegotiatedAPIResourceConditionType = "Approved"
+)
+
+// These are valid reasons of the Approved condition.
+const (
+	// ApprovedByPolicyReason means that the API group is published without manual approval.
+	ApprovedByPolicyReason = "ApprovedByPolicy"
+	// ApprovedManuallyReason means that spec.publish was set manually.
+	ApprovedManuallyReason = "ApprovedManually"
+	// ApprovedByEnforcedCRDReason means that a CRD for the same GVR has been manually applied.
+	ApprovedByEnforcedCRDReason = "EnforcedCRD"
+	// ApprovalPendingReason means that the API resource waits for a manual approval.
+	ApprovalPendingReason = "ApprovalPending"
 )
 
 // NegotiatedAPIResourceCondition contains details for the current condition of this negotiated api resource.
 type NegotiatedAPIResourceCondition struct {
-	// Type is the type of the condition. Types include Submitted, Published, Refused and Enforced.
+	// Type is the type of the condition. Types include Submitted, Published, Refused, Enforced and Approved.
 	Type NegotiatedAPIResourceConditionType `json:"type"`
 	// Status is the status of the condition.
 	// Can be True, False, Unknown.
@@ -0,0 +1,43 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"github.com/kcp-dev/logicalcluster"
+)
+
+// ClusterName returns the logical cluster of the referenced workspace, resolving a name relative to the
+// parent of the given logical cluster of the referencing object. It returns false if a name is referenced
+// from a logical cluster without parent.
+func (r *WorkspaceExportReference) ClusterName(referencing logicalcluster.Name) (logicalcluster.Name, bool) {
+	if r.Path != "" {
+		return logicalcluster.New(r.Path), true
+	}
+	parent, hasParent := referencing.Parent()
+	if !hasParent {
+		return logicalcluster.Name{}, false
+	}
+	return parent.Join(r.WorkspaceName), true
+}
+
+// String returns the path or the name of the referenced workspace.
+func (r *WorkspaceExportReference) String() string {
+	if r.Path != "" {
+		return r.Path
+	}
+	return r.WorkspaceName
+}
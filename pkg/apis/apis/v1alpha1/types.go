@@ -66,6 +66,14 @@ type APIBindingSpec struct {
 	// +required
 	// +kubebuilder:validation:Required
 	Reference ExportReference `json:"reference"`
+
+	// priority decides which APIBinding serves an API when multiple APIBindings in the
+	// workspace provide conflicting API names. The APIBinding with the highest priority
+	// wins. On equal priority, the oldest APIBinding wins, and on equal age the one with
+	// the lexicographically smallest name.
+	//
+	// +optional
+	Priority int32 `json:"priority,omitempty"`
 }
 
 // ExportReference describes a reference to an APIExport. Exactly one of the
@@ -82,13 +90,18 @@ type ExportReference struct {
 // WorkspaceExportReference describes an API and backing implementation that are provided by an actor in the
 // specified Workspace.
 type WorkspaceExportReference struct {
-	// name is a workspace name in the same organization.
+	// name is a workspace name in the same organization. Exactly one of name and path must be set.
 	//
-	// +required
-	// +kubebuilder:validation:Required
-	// +kube:validation:MinLength=1
+	// +optional
 	// +kubebuilder:validation:Pattern:="^[a-z0-9]([-a-z0-9]*[a-z0-9])?$"
-	WorkspaceName string `json:"name"`
+	WorkspaceName string `json:"name,omitempty"`
+
+	// path is the absolute logical cluster path of a workspace outside of the organization,
+	// e.g. root:compute. Exactly one of name and path must be set.
+	//
+	// +optional
+	// +kubebuilder:validation:Pattern:="^root(:[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$"
+	Path string `json:"path,omitempty"`
 
 	// Name of the APIExport that describes the API.
 	//
@@ -129,6 +142,18 @@ type APIBindingStatus struct {
 	// +listMapKey=resource
 	BoundResources []BoundAPIResource `json:"boundResources,omitempty"`
 
+	// deprecatedVersionUsage records the requests in this workspace to versions of the
+	// bound resources that are marked as deprecated in their APIResourceSchema. Clients
+	// receive a warning for these requests. The usage is flushed periodically and can lag
+	// behind.
+	//
+	// +optional
+	// +listType=map
+	// +listMapKey=group
+	// +listMapKey=resource
+	// +listMapKey=version
+	DeprecatedVersionUsage []DeprecatedVersionUsage `json:"deprecatedVersionUsage,omitempty"`
+
 	// initializers tracks the binding process of the APIBinding. The APIBinding cannot
 	// be moved to Bound until the initializers have finished their work. Initializers are
 	// added before transition to Initializing phase and verified through admission to be
@@ -177,9 +202,17 @@ const (
 	// the binding's desired export.
 	BindingUpToDate conditionsv1alpha1.ConditionType = "BindingUpToDate"
 
-	// NamingConflictsReason is a reason for the BindingUpToDate condition that at least one API coming in from the APIBinding
-	// has a naming conflict with other APIs.
+	// ConflictFree is a condition for APIBinding that indicates that the APIs coming in from the APIBinding do not
+	// conflict with other APIs in the workspace.
+	ConflictFree conditionsv1alpha1.ConditionType = "ConflictFree"
+
+	// NamingConflictsReason is a reason for the BindingUpToDate and ConflictFree conditions that at least one API
+	// coming in from the APIBinding has a naming conflict with the APIs of another APIBinding taking precedence.
 	NamingConflictsReason = "NamingConflicts"
+	// LocalCRDConflictReason is a reason for the ConflictFree condition that at least one API coming in from the
+	// APIBinding has the same group and resource as a CustomResourceDefinition in the workspace. The APIBinding
+	// takes precedence, i.e. the CustomResourceDefinition is not served.
+	LocalCRDConflictReason = "LocalCRDConflict"
 )
 
 // These are annotations for bound CRDs
@@ -198,6 +231,12 @@ const (
 	AnnotationAPIIdentityKey = "apis.kcp.dev/identity"
 )
 
+// AnnotationBreakingChangeAcceptedKey is the annotation key on an APIExport that allows spec.latestResourceSchemas
+// to be updated to APIResourceSchemas that are incompatible with the ones they replace, e.g. by removing fields or
+// changing their type. Its value is the comma separated list of the names of the incompatible APIResourceSchemas
+// accepted. Other updates are rejected to protect the data stored in consumer workspaces.
+const AnnotationBreakingChangeAcceptedKey = "apis.kcp.dev/breaking-change-accepted"
+
 // BoundAPIResource describes a bound GroupVersionResource through an APIResourceSchema of an APIExport..
 type BoundAPIResource struct {
 	// group is the group of the bound API. Empty string for the core API group.
@@ -229,7 +268,41 @@ type BoundAPIResource struct {
 	StorageVersions []string `json:"storageVersions,omitempty"`
 }
 
+// DeprecatedVersionUsage is the usage of a deprecated version of a bound resource.
+type DeprecatedVersionUsage struct {
+	// group is the group of the resource. Empty string for the core API group.
+	//
+	// +required
+	Group string `json:"group"`
+
+	// resource is the resource name.
+	//
+	// +required
+	Resource string `json:"resource"`
+
+	// version is the deprecated version.
+	//
+	// +required
+	Version string `json:"version"`
+
+	// requestCount is the number of requests to the deprecated version.
+	//
+	// +required
+	RequestCount int64 `json:"requestCount"`
+
+	// lastRequestTime is the time of the latest request to the deprecated version.
+	//
+	// +required
+	LastRequestTime metav1.Time `json:"lastRequestTime"`
+
+	// lastUserAgent is the user agent of the latest request to the deprecated version.
+	//
+	// +optional
+	LastUserAgent string `json:"lastUserAgent,omitempty"`
+}
+
 // BoundAPIResourceSchema is a reference to an APIResourceSchema.
+
 type BoundAPIResourceSchema struct {
 	// name is the bound APIResourceSchema name.
 	//
@@ -347,6 +420,59 @@ type APIExportSpec struct {
 	//
 	// +optional
 	Identity *Identity `json:"identity"`
+
+	// catalog holds display metadata of the APIExport for users looking for APIs to
+	// bind to, e.g. in UIs listing the APIExports of the apiexportcatalog virtual workspace.
+	//
+	// +optional
+	Catalog *APIExportCatalog `json:"catalog,omitempty"`
+
+	// aggregateToRoles opts the resources of this APIExport into the admin, edit and view
+	// ClusterRoles of the workspaces bound to it, like the aggregate-to-* labels of ClusterRoles
+	// in Kubernetes. admin and edit are granted all read and write verbs, view is granted get,
+	// list and watch. The resources are not added to any of these roles unless listed here.
+	//
+	// +optional
+	// +listType=set
+	AggregateToRoles []AggregatedRoleName `json:"aggregateToRoles,omitempty"`
+}
+
+// AggregatedRoleName is the name of a ClusterRole the resources of an APIExport can be aggregated to.
+//
+// +kubebuilder:validation:Enum=admin;edit;view
+type AggregatedRoleName string
+
+const (
+	AggregatedRoleAdmin AggregatedRoleName = "admin"
+	AggregatedRoleEdit  AggregatedRoleName = "edit"
+	AggregatedRoleView  AggregatedRoleName = "view"
+)
+
+// APIExportCatalog holds display metadata of an APIExport.
+type APIExportCatalog struct {
+	// displayName is the human readable name of the API.
+	//
+	// +optional
+	// +kubebuilder:validation:MaxLength=63
+	DisplayName string `json:"displayName,omitempty"`
+
+	// description is a short description of the API.
+	//
+	// +optional
+	// +kubebuilder:validation:MaxLength=1024
+	Description string `json:"description,omitempty"`
+
+	// iconURL is the http or https URL, or the data URL of an image representing the API.
+	//
+	// +optional
+	// +kubebuilder:validation:Pattern:="^(https?://|data:image/)"
+	IconURL string `json:"iconURL,omitempty"`
+
+	// documentationURL is the http or https URL of the documentation of the API.
+	//
+	// +optional
+	// +kubebuilder:validation:Pattern:="^https?://"
+	DocumentationURL string `json:"documentationURL,omitempty"`
 }
 
 // Identity defines the identity of an APIExport, i.e. determines the etcd prefix
@@ -370,6 +496,57 @@ type APIExportStatus struct {
 	//
 	// +optional
 	Conditions conditionsv1alpha1.Conditions `json:"conditions,omitempty"`
+
+	// usage summarizes how the APIExport is used in the workspaces bound to it,
+	// e.g. to plan deprecations. It is computed periodically and can be stale.
+	//
+	// +optional
+	Usage *APIExportUsage `json:"usage,omitempty"`
+}
+
+// APIExportUsage summarizes the usage of an APIExport.
+type APIExportUsage struct {
+	// boundWorkspaces is the number of workspaces with an APIBinding bound to this APIExport.
+	//
+	// +required
+	BoundWorkspaces int32 `json:"boundWorkspaces"`
+
+	// resources records the usage per resource of the latest APIResourceSchemas.
+	//
+	// +optional
+	// +listType=map
+	// +listMapKey=group
+	// +listMapKey=resource
+	Resources []APIExportResourceUsage `json:"resources,omitempty"`
+
+	// lastUpdateTime is the time the usage was computed.
+	//
+	// +required
+	LastUpdateTime metav1.Time `json:"lastUpdateTime"`
+}
+
+// APIExportResourceUsage is the usage of an exported resource in all bound workspaces.
+type APIExportResourceUsage struct {
+	// group is the group of the resource. Empty string for the core API group.
+	//
+	// +required
+	Group string `json:"group"`
+
+	// resource is the resource name.
+	//
+	// +required
+	Resource string `json:"resource"`
+
+	// objectCount is the number of objects of the resource in all bound workspaces.
+	//
+	// +required
+	ObjectCount int64 `json:"objectCount"`
+
+	// lastActivityTime is the latest creation or update time of the objects of the
+	// resource, as recorded in their managed fields.
+	//
+	// +optional
+	LastActivityTime *metav1.Time `json:"lastActivityTime,omitempty"`
 }
 
 // APIExportList is a list of APIExport resources
@@ -424,14 +601,64 @@ type APIResourceSchemaSpec struct {
 
 	// versions is the API version of the defined custom resource.
 	//
-	// Note: the OpenAPI v3 schemas must be equal for all versions until CEL
-	//       version migration is supported.
+	// Note: the OpenAPI v3 schemas of different versions must only differ in
+	//       fields moved by the conversions below.
 	//
 	// +required
 	// +listType=map
 	// +listMapKey=name
 	// +kubebuilder:validation:MinItems=1
 	Versions []APIResourceVersion `json:"versions"`
+
+	// conversions declare how objects are converted between versions. They are executed
+	// by kcp for bound APIs, i.e. no conversion webhook has to be deployed. Objects of
+	// versions without a conversion between them are converted by changing the apiVersion only.
+	//
+	// +optional
+	// +listType=atomic
+	Conversions []APIResourceConversion `json:"conversions,omitempty"`
+}
+
+// APIResourceConversion describes how objects are converted between two versions of a resource.
+// Conversions are bidirectional, i.e. converting from the `to` to the `from` version moves the
+// fields in the reverse direction.
+type APIResourceConversion struct {
+	// from is the name of a version of the resource.
+	//
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	From string `json:"from"`
+
+	// to is the name of another version of the resource.
+	//
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	To string `json:"to"`
+
+	// fields lists the fields whose values are moved when converting between the versions.
+	// Fields not listed are kept as they are.
+	//
+	// +required
+	// +listType=atomic
+	// +kubebuilder:validation:MinItems=1
+	Fields []APIResourceConversionField `json:"fields"`
+}
+
+// APIResourceConversionField describes a field moved by an APIResourceConversion.
+type APIResourceConversionField struct {
+	// from is a JSONPath to the field in the `from` version, e.g. `.spec.replicas`.
+	// Only field selection is supported, and metadata cannot be converted.
+	//
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	From string `json:"from"`
+
+	// to is a JSONPath to the field in the `to` version, e.g. `.spec.size`.
+	// Only field selection is supported, and metadata cannot be converted.
+	//
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	To string `json:"to"`
 }
 
 // APIResourceVersion describes one API version of a resource.
@@ -496,3 +723,68 @@ type APIResourceSchemaList struct {
 
 	Items []APIResourceSchema `json:"items"`
 }
+
+// SecretShare shares a Secret of the workspace with the provider of an APIExport, i.e.
+// users allowed to get the content of the APIExport can get the Secret, e.g. credentials
+// the consumer created for the provider's controllers. Nothing else is shared.
+//
+// +crd
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:resource:scope=Cluster,categories=kcp
+// +kubebuilder:printcolumn:name="Namespace",type="string",JSONPath=".spec.secretRef.namespace",description="The namespace of the shared Secret"
+// +kubebuilder:printcolumn:name="Secret",type="string",JSONPath=".spec.secretRef.name",description="The name of the shared Secret"
+// +kubebuilder:printcolumn:name="Export",type="string",JSONPath=".spec.export.workspace.exportName",description="The APIExport the Secret is shared with"
+type SecretShare struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Spec holds the desired state.
+	Spec SecretShareSpec `json:"spec"`
+}
+
+// SecretShareSpec records the Secret to share and the APIExport to share it with.
+type SecretShareSpec struct {
+	// secretRef references the shared Secret in the workspace of the SecretShare.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	SecretRef SharedSecretReference `json:"secretRef"`
+
+	// export references the APIExport whose provider may get the Secret. Users with the
+	// verb `get` on `apiexports/content` of the APIExport in its workspace are granted
+	// to get the Secret.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	Export ExportReference `json:"export"`
+}
+
+// SharedSecretReference references a Secret.
+type SharedSecretReference struct {
+	// namespace is the namespace of the Secret.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Namespace string `json:"namespace"`
+
+	// name is the name of the Secret.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+}
+
+// SecretShareList is a list of SecretShare resources
+//
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type SecretShareList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []SecretShare `json:"items"`
+}
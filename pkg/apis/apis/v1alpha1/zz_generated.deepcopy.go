@@ -122,6 +122,13 @@ func (in *APIBindingStatus) DeepCopyInto(out *APIBindingStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.DeprecatedVersionUsage != nil {
+		in, out := &in.DeprecatedVersionUsage, &out.DeprecatedVersionUsage
+		*out = make([]DeprecatedVersionUsage, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.Initializers != nil {
 		in, out := &in.Initializers, &out.Initializers
 		*out = make([]string, len(*in))
@@ -175,6 +182,22 @@ func (in *APIExport) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *APIExportCatalog) DeepCopyInto(out *APIExportCatalog) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new APIExportCatalog.
+func (in *APIExportCatalog) DeepCopy() *APIExportCatalog {
+	if in == nil {
+		return nil
+	}
+	out := new(APIExportCatalog)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *APIExportList) DeepCopyInto(out *APIExportList) {
 	*out = *in
@@ -208,6 +231,26 @@ func (in *APIExportList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *APIExportResourceUsage) DeepCopyInto(out *APIExportResourceUsage) {
+	*out = *in
+	if in.LastActivityTime != nil {
+		in, out := &in.LastActivityTime, &out.LastActivityTime
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new APIExportResourceUsage.
+func (in *APIExportResourceUsage) DeepCopy() *APIExportResourceUsage {
+	if in == nil {
+		return nil
+	}
+	out := new(APIExportResourceUsage)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *APIExportSpec) DeepCopyInto(out *APIExportSpec) {
 	*out = *in
@@ -221,6 +264,16 @@ func (in *APIExportSpec) DeepCopyInto(out *APIExportSpec) {
 		*out = new(Identity)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Catalog != nil {
+		in, out := &in.Catalog, &out.Catalog
+		*out = new(APIExportCatalog)
+		**out = **in
+	}
+	if in.AggregateToRoles != nil {
+		in, out := &in.AggregateToRoles, &out.AggregateToRoles
+		*out = make([]AggregatedRoleName, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -244,6 +297,11 @@ func (in *APIExportStatus) DeepCopyInto(out *APIExportStatus) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Usage != nil {
+		in, out := &in.Usage, &out.Usage
+		*out = new(APIExportUsage)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -257,6 +315,67 @@ func (in *APIExportStatus) DeepCopy() *APIExportStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *APIExportUsage) DeepCopyInto(out *APIExportUsage) {
+	*out = *in
+	if in.Resources != nil {
+		in, out := &in.Resources, &out.Resources
+		*out = make([]APIExportResourceUsage, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	in.LastUpdateTime.DeepCopyInto(&out.LastUpdateTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new APIExportUsage.
+func (in *APIExportUsage) DeepCopy() *APIExportUsage {
+	if in == nil {
+		return nil
+	}
+	out := new(APIExportUsage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *APIResourceConversion) DeepCopyInto(out *APIResourceConversion) {
+	*out = *in
+	if in.Fields != nil {
+		in, out := &in.Fields, &out.Fields
+		*out = make([]APIResourceConversionField, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new APIResourceConversion.
+func (in *APIResourceConversion) DeepCopy() *APIResourceConversion {
+	if in == nil {
+		return nil
+	}
+	out := new(APIResourceConversion)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *APIResourceConversionField) DeepCopyInto(out *APIResourceConversionField) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new APIResourceConversionField.
+func (in *APIResourceConversionField) DeepCopy() *APIResourceConversionField {
+	if in == nil {
+		return nil
+	}
+	out := new(APIResourceConversionField)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *APIResourceSchema) DeepCopyInto(out *APIResourceSchema) {
 	*out = *in
@@ -328,6 +447,13 @@ func (in *APIResourceSchemaSpec) DeepCopyInto(out *APIResourceSchemaSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Conversions != nil {
+		in, out := &in.Conversions, &out.Conversions
+		*out = make([]APIResourceConversion, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -407,8 +533,26 @@ func (in *BoundAPIResourceSchema) DeepCopy() *BoundAPIResourceSchema {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeprecatedVersionUsage) DeepCopyInto(out *DeprecatedVersionUsage) {
+	*out = *in
+	in.LastRequestTime.DeepCopyInto(&out.LastRequestTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeprecatedVersionUsage.
+func (in *DeprecatedVersionUsage) DeepCopy() *DeprecatedVersionUsage {
+	if in == nil {
+		return nil
+	}
+	out := new(DeprecatedVersionUsage)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ExportReference) DeepCopyInto(out *ExportReference) {
+
 	*out = *in
 	if in.Workspace != nil {
 		in, out := &in.Workspace, &out.Workspace
@@ -449,6 +593,100 @@ func (in *Identity) DeepCopy() *Identity {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecretShare) DeepCopyInto(out *SecretShare) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretShare.
+func (in *SecretShare) DeepCopy() *SecretShare {
+	if in == nil {
+		return nil
+	}
+	out := new(SecretShare)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SecretShare) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecretShareList) DeepCopyInto(out *SecretShareList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]SecretShare, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretShareList.
+func (in *SecretShareList) DeepCopy() *SecretShareList {
+	if in == nil {
+		return nil
+	}
+	out := new(SecretShareList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *SecretShareList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecretShareSpec) DeepCopyInto(out *SecretShareSpec) {
+	*out = *in
+	out.SecretRef = in.SecretRef
+	in.Export.DeepCopyInto(&out.Export)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretShareSpec.
+func (in *SecretShareSpec) DeepCopy() *SecretShareSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(SecretShareSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SharedSecretReference) DeepCopyInto(out *SharedSecretReference) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SharedSecretReference.
+func (in *SharedSecretReference) DeepCopy() *SharedSecretReference {
+	if in == nil {
+		return nil
+	}
+	out := new(SharedSecretReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *WorkspaceExportReference) DeepCopyInto(out *WorkspaceExportReference) {
 	*out = *in
@@ -0,0 +1,22 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package v1alpha1 contains the shared API types of long-running operations (migrations, exports, diagnostics)
+// in kcp. They are not served by themselves, but embedded into the spec and status of the resources of such
+// subsystems, such that all of them share the same lifecycle, garbage collection and status conventions.
+//
+// +k8s:deepcopy-gen=package
+package v1alpha1
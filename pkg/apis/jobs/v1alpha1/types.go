@@ -0,0 +1,100 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	conditionsv1alpha1 "github.com/kcp-dev/kcp/third_party/conditions/apis/conditions/v1alpha1"
+)
+
+// JobPolicy defines how long a finished job and its siblings are kept around. It is meant to be embedded
+// into the spec of a job-like resource.
+type JobPolicy struct {
+	// ttlSecondsAfterFinished limits the lifetime of a job that has finished execution (either Succeeded
+	// or Failed). After the TTL expires, the job is deleted. If unset, the job is only deleted by the
+	// history limits.
+	//
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	TTLSecondsAfterFinished *int32 `json:"ttlSecondsAfterFinished,omitempty"`
+
+	// successfulJobsHistoryLimit is the number of successfully finished sibling jobs to keep. Older jobs
+	// are deleted. If unset, successful jobs are not limited.
+	//
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	SuccessfulJobsHistoryLimit *int32 `json:"successfulJobsHistoryLimit,omitempty"`
+
+	// failedJobsHistoryLimit is the number of failed sibling jobs to keep. Older jobs are deleted. If
+	// unset, failed jobs are not limited.
+	//
+	// +optional
+	// +kubebuilder:validation:Minimum=0
+	FailedJobsHistoryLimit *int32 `json:"failedJobsHistoryLimit,omitempty"`
+}
+
+// JobPhase is the lifecycle phase of a job.
+//
+// +kubebuilder:validation:Enum=Pending;Running;Succeeded;Failed
+type JobPhase string
+
+const (
+	// JobPhasePending means the job has been accepted, but has not started yet.
+	JobPhasePending JobPhase = "Pending"
+	// JobPhaseRunning means the job is executing.
+	JobPhaseRunning JobPhase = "Running"
+	// JobPhaseSucceeded means the job has finished successfully. This is a terminal phase.
+	JobPhaseSucceeded JobPhase = "Succeeded"
+	// JobPhaseFailed means the job has finished with an error. This is a terminal phase.
+	JobPhaseFailed JobPhase = "Failed"
+)
+
+// JobStatus is the observed state of a job. It is meant to be embedded into the status of a job-like
+// resource.
+type JobStatus struct {
+	// phase is the lifecycle phase of the job.
+	//
+	// +optional
+	Phase JobPhase `json:"phase,omitempty"`
+
+	// startTime is the time the job started running.
+	//
+	// +optional
+	StartTime *metav1.Time `json:"startTime,omitempty"`
+
+	// completionTime is the time the job reached a terminal phase.
+	//
+	// +optional
+	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
+
+	// Current processing state of the job.
+	//
+	// +optional
+	Conditions conditionsv1alpha1.Conditions `json:"conditions,omitempty"`
+}
+
+// These are valid conditions of a job.
+const (
+	// JobComplete means the job has finished, either successfully or not. The reason is
+	// JobReasonFailed if the job has failed.
+	JobComplete conditionsv1alpha1.ConditionType = "Complete"
+	// JobReasonRunning reason in Complete condition means that the job is still executing.
+	JobReasonRunning = "Running"
+	// JobReasonFailed reason in Complete condition means that the job has finished with an error.
+	JobReasonFailed = "Failed"
+)
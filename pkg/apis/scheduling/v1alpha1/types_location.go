@@ -27,6 +27,25 @@ const (
 
 	// PlacementAnnotationKey is the label key for the label holding a PlacementAnnotation struct.
 	PlacementAnnotationKey = "scheduling.kcp.dev/placement"
+
+	// TolerationsAnnotationKey is the annotation key on namespaces holding a JSON list of
+	// core/v1 Tolerations. Namespaces are only scheduled to workload clusters whose taints
+	// are tolerated.
+	TolerationsAnnotationKey = "scheduling.kcp.dev/tolerations"
+
+	// NamespaceAffinityAnnotationKey is the annotation key on namespaces holding a label selector
+	// of other namespaces of the same workspace. The namespace is preferably scheduled to the
+	// workload clusters the selected namespaces are scheduled to.
+	NamespaceAffinityAnnotationKey = "scheduling.kcp.dev/namespace-affinity"
+
+	// NamespaceAntiAffinityAnnotationKey is the annotation key on namespaces holding a label selector
+	// of other namespaces of the same workspace. The namespace is preferably not scheduled to the
+	// workload clusters the selected namespaces are scheduled to.
+	NamespaceAntiAffinityAnnotationKey = "scheduling.kcp.dev/namespace-anti-affinity"
+
+	// TopologyLocationLabelKey is the label key marking Location objects that are maintained
+	// for a topology region of workload clusters. Its value is the region.
+	TopologyLocationLabelKey = "scheduling.kcp.dev/topology-region"
 )
 
 // PlacementAnnotation is the type marshalled into the PlacementAnnotationKey annotation.
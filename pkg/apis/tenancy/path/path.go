@@ -0,0 +1,155 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package path parses and validates workspace paths like root:org:team, i.e. the logical cluster
+// names of workspaces, consisting of the workspace names from the root workspace down to the
+// workspace, separated by colons.
+package path
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/kcp-dev/logicalcluster"
+)
+
+// Separator separates the workspace names of a path.
+const Separator = ":"
+
+// clustersPrefix is the URL path prefix of requests to a logical cluster.
+const clustersPrefix = "/clusters/"
+
+var (
+	// Root is the path of the root workspace.
+	Root = logicalcluster.New("root")
+
+	// System is the prefix of the system logical clusters, which are not workspaces.
+	System = logicalcluster.New("system")
+)
+
+// segmentRegExp matches a workspace name in a path. The length of names is not limited here,
+// as that is decided by the server.
+var segmentRegExp = regexp.MustCompile(`^[a-z][a-z0-9-]*[a-z0-9]$`)
+
+// Parse returns the path of the given string, or an error if it is not a valid path.
+func Parse(s string) (logicalcluster.Name, error) {
+	p := logicalcluster.New(s)
+	if err := Validate(p); err != nil {
+		return logicalcluster.Name{}, err
+	}
+	return p, nil
+}
+
+// Validate returns an error if the given path is not below the root workspace or a system logical cluster,
+// or if it contains an invalid workspace name.
+func Validate(p logicalcluster.Name) error {
+	if p.Empty() {
+		return fmt.Errorf("path must not be empty")
+	}
+	segments := Segments(p)
+	for i, segment := range segments {
+		if !segmentRegExp.MatchString(segment) {
+			return fmt.Errorf("invalid path %q: segment %d %q must consist of lower case alphanumeric characters or '-', start with a letter and end with an alphanumeric character", p, i+1, segment)
+		}
+	}
+	if segments[0] != Root.String() && segments[0] != System.String() {
+		return fmt.Errorf("invalid path %q: must start with %q or %q", p, Root, System)
+	}
+	return nil
+}
+
+// IsValid returns true if the given path is valid, see Validate.
+func IsValid(p logicalcluster.Name) bool {
+	return Validate(p) == nil
+}
+
+// IsAbsolute returns true if the given string is a path rather than a single workspace name relative
+// to some workspace, i.e. if it contains a separator or is the root workspace.
+func IsAbsolute(s string) bool {
+	return strings.Contains(s, Separator) || s == Root.String()
+}
+
+// Segments returns the workspace names of the given path, or nil for the empty path.
+func Segments(p logicalcluster.Name) []string {
+	if p.Empty() {
+		return nil
+	}
+	return strings.Split(p.String(), Separator)
+}
+
+// Join returns the path of the given descendants of the workspace with the given path.
+func Join(p logicalcluster.Name, names ...string) logicalcluster.Name {
+	for _, name := range names {
+		p = p.Join(name)
+	}
+	return p
+}
+
+// Depth returns the number of workspace names of the given path, e.g. 1 for the root workspace
+// and 2 for organizations.
+func Depth(p logicalcluster.Name) int {
+	return len(Segments(p))
+}
+
+// IsRoot returns true if the given path is the root workspace.
+func IsRoot(p logicalcluster.Name) bool {
+	return p == Root
+}
+
+// IsSystem returns true if the given path is a system logical cluster.
+func IsSystem(p logicalcluster.Name) bool {
+	return p == System || IsAncestorOf(System, p)
+}
+
+// IsOrganization returns true if the given path is an organization, i.e. a child of the root workspace.
+func IsOrganization(p logicalcluster.Name) bool {
+	parent, hasParent := p.Parent()
+	return hasParent && parent == Root
+}
+
+// Organization returns the organization the given path belongs to, which is the path itself for an
+// organization. It returns false for the root workspace and paths not below it.
+func Organization(p logicalcluster.Name) (logicalcluster.Name, bool) {
+	segments := Segments(p)
+	if len(segments) < 2 || segments[0] != Root.String() {
+		return logicalcluster.Name{}, false
+	}
+	return Root.Join(segments[1]), true
+}
+
+// IsAncestorOf returns true if the workspace with the given path is a strict ancestor of the other, e.g.
+// root:org is an ancestor of root:org:team, but not of root:org or root:organization.
+func IsAncestorOf(ancestor, p logicalcluster.Name) bool {
+	return !ancestor.Empty() && strings.HasPrefix(p.String(), ancestor.String()+Separator)
+}
+
+// FromURLPath returns the path of a /clusters/<path>/... URL path, and the remainder of the URL path
+// after it, which is empty or starts with a slash. It returns false for other URL paths.
+func FromURLPath(urlPath string) (logicalcluster.Name, string, bool) {
+	rest := strings.TrimPrefix(urlPath, clustersPrefix)
+	if rest == urlPath {
+		return logicalcluster.Name{}, "", false
+	}
+	i := strings.Index(rest, "/")
+	if i == -1 {
+		i = len(rest)
+	}
+	if i == 0 {
+		return logicalcluster.Name{}, "", false
+	}
+	return logicalcluster.New(rest[:i]), rest[i:], true
+}
@@ -0,0 +1,205 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package path
+
+import (
+	"testing"
+
+	"github.com/kcp-dev/logicalcluster"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		path  string
+		valid bool
+	}{
+		{"", false},
+
+		{"root", true},
+		{"root:foo", true},
+		{"root:foo:bar", true},
+
+		{"system", true},
+		{"system:foo", true},
+		{"system:foo:bar", true},
+
+		// the length of segments is decided by the server
+		{"root:b1234567890123456789012345678912", true},
+		{"root:test-8827a131-f796-4473-8904-a0fa527696eb:b1234567890123456789012345678912", true},
+		{"root:test-too-long-org-0020-4473-0030-a0fa-0040-5276-0050-sdg2-0060:b1234567890123456789012345678912", true},
+
+		{"foo", false},
+		{"foo:bar", false},
+		{"rootfoo", false},
+		{"root:", false},
+		{":root", false},
+		{"root::foo", false},
+		{"root:föö:bär", false},
+		{"root:bar_bar", false},
+		{"root:a", false},
+		{"root:0a", false},
+		{"root:0bar", false},
+		{"root/bar", false},
+		{"root:bar-", false},
+		{"root:-bar", false},
+		{"root:Bar", false},
+		{"*", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			err := Validate(logicalcluster.New(tt.path))
+			require.Equal(t, tt.valid, err == nil, "Validate(%q) = %v", tt.path, err)
+			require.Equal(t, tt.valid, IsValid(logicalcluster.New(tt.path)))
+
+			p, err := Parse(tt.path)
+			if tt.valid {
+				require.NoError(t, err)
+				require.Equal(t, logicalcluster.New(tt.path), p)
+			} else {
+				require.Error(t, err)
+				require.True(t, p.Empty())
+			}
+		})
+	}
+}
+
+func TestIsAbsolute(t *testing.T) {
+	tests := map[string]bool{
+		"":             false,
+		"foo":          false,
+		"..":           false,
+		"-":            false,
+		"root":         true,
+		"root:foo":     true,
+		"system:admin": true,
+		"foo:bar":      true,
+	}
+	for s, want := range tests {
+		t.Run(s, func(t *testing.T) {
+			require.Equal(t, want, IsAbsolute(s))
+		})
+	}
+}
+
+func TestSegmentsAndDepth(t *testing.T) {
+	tests := []struct {
+		path     string
+		segments []string
+	}{
+		{"", nil},
+		{"root", []string{"root"}},
+		{"root:org", []string{"root", "org"}},
+		{"root:org:team:ws", []string{"root", "org", "team", "ws"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			require.Equal(t, tt.segments, Segments(logicalcluster.New(tt.path)))
+			require.Equal(t, len(tt.segments), Depth(logicalcluster.New(tt.path)))
+		})
+	}
+}
+
+func TestJoin(t *testing.T) {
+	require.Equal(t, Root, Join(Root))
+	require.Equal(t, logicalcluster.New("root:org"), Join(Root, "org"))
+	require.Equal(t, logicalcluster.New("root:org:team:ws"), Join(Root, "org", "team", "ws"))
+	require.Equal(t, logicalcluster.New("root:org:team"), Join(logicalcluster.New("root:org"), "team"))
+}
+
+func TestHierarchy(t *testing.T) {
+	tests := []struct {
+		path           string
+		isRoot         bool
+		isSystem       bool
+		isOrganization bool
+		organization   string
+	}{
+		{path: ""},
+		{path: "root", isRoot: true},
+		{path: "root:org", isOrganization: true, organization: "root:org"},
+		{path: "root:org:team", organization: "root:org"},
+		{path: "root:org:team:ws", organization: "root:org"},
+		{path: "system", isSystem: true},
+		{path: "system:admin", isSystem: true},
+		{path: "systems:admin"},
+		{path: "foo:bar"},
+		{path: "foo:bar:baz"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			p := logicalcluster.New(tt.path)
+			require.Equal(t, tt.isRoot, IsRoot(p), "IsRoot")
+			require.Equal(t, tt.isSystem, IsSystem(p), "IsSystem")
+			require.Equal(t, tt.isOrganization, IsOrganization(p), "IsOrganization")
+
+			org, ok := Organization(p)
+			require.Equal(t, tt.organization != "", ok, "Organization")
+			require.Equal(t, logicalcluster.New(tt.organization), org, "Organization")
+		})
+	}
+}
+
+func TestIsAncestorOf(t *testing.T) {
+	tests := []struct {
+		ancestor, path string
+		want           bool
+	}{
+		{"root", "root:org", true},
+		{"root", "root:org:team", true},
+		{"root:org", "root:org:team", true},
+		{"root:org", "root:org", false},
+		{"root:org", "root:organization", false},
+		{"root:org:team", "root:org", false},
+		{"root", "system:admin", false},
+		{"", "root", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ancestor+"/"+tt.path, func(t *testing.T) {
+			require.Equal(t, tt.want, IsAncestorOf(logicalcluster.New(tt.ancestor), logicalcluster.New(tt.path)))
+		})
+	}
+}
+
+func TestFromURLPath(t *testing.T) {
+	tests := []struct {
+		urlPath string
+		path    string
+		rest    string
+		ok      bool
+	}{
+		{urlPath: ""},
+		{urlPath: "/"},
+		{urlPath: "/api/v1/namespaces"},
+		{urlPath: "/services/workspaces/root:org/all/personal"},
+		{urlPath: "/clusters"},
+		{urlPath: "/clusters/"},
+		{urlPath: "/clusters//api"},
+		{urlPath: "/clusters/root", path: "root", ok: true},
+		{urlPath: "/clusters/root:org/", path: "root:org", rest: "/", ok: true},
+		{urlPath: "/clusters/root:org/api/v1/namespaces", path: "root:org", rest: "/api/v1/namespaces", ok: true},
+		{urlPath: "/clusters/*/apis/apps/v1/deployments", path: "*", rest: "/apis/apps/v1/deployments", ok: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.urlPath, func(t *testing.T) {
+			p, rest, ok := FromURLPath(tt.urlPath)
+			require.Equal(t, tt.ok, ok)
+			require.Equal(t, logicalcluster.New(tt.path), p)
+			require.Equal(t, tt.rest, rest)
+		})
+	}
+}
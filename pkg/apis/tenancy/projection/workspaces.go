@@ -21,9 +21,22 @@ import (
 	"github.com/kcp-dev/kcp/pkg/apis/tenancy/v1beta1"
 )
 
+// ProjectClusterWorkspaceToWorkspace projects a ClusterWorkspace to the user-facing Workspace. Metadata
+// that is internal to the ClusterWorkspace, i.e. its managed fields and finalizers, is not projected.
 func ProjectClusterWorkspaceToWorkspace(from *v1alpha1.ClusterWorkspace, to *v1beta1.Workspace) {
 	to.ObjectMeta = from.ObjectMeta
+	to.ManagedFields = nil
+	to.Finalizers = nil
 	to.Spec.Type = from.Spec.Type
 	to.Status.URL = from.Status.BaseURL
 	to.Status.Phase = from.Status.Phase
 }
+
+// ProjectWorkspaceToClusterWorkspace projects a user-facing Workspace to a ClusterWorkspace to be
+// created. Only the name, labels, annotations and type are taken over.
+func ProjectWorkspaceToClusterWorkspace(from *v1beta1.Workspace, to *v1alpha1.ClusterWorkspace) {
+	to.Name = from.Name
+	to.Labels = from.Labels
+	to.Annotations = from.Annotations
+	to.Spec.Type = from.Spec.Type
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package projection
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/apis/tenancy/v1beta1"
+)
+
+func TestProjectClusterWorkspaceToWorkspace(t *testing.T) {
+	var ws v1beta1.Workspace
+	ProjectClusterWorkspaceToWorkspace(&v1alpha1.ClusterWorkspace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:          "foo",
+			Labels:        map[string]string{"a": "b"},
+			Finalizers:    []string{"tenancy.kcp.dev/workspace-finalizer"},
+			ManagedFields: []metav1.ManagedFieldsEntry{{Manager: "kcp"}},
+		},
+		Spec: v1alpha1.ClusterWorkspaceSpec{Type: "Team"},
+		Status: v1alpha1.ClusterWorkspaceStatus{
+			Phase:    v1alpha1.ClusterWorkspacePhaseReady,
+			BaseURL:  "https://kcp/clusters/root:org:foo",
+			Location: v1alpha1.ClusterWorkspaceLocation{Current: "shard-1"},
+		},
+	}, &ws)
+
+	require.Equal(t, v1beta1.Workspace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   "foo",
+			Labels: map[string]string{"a": "b"},
+		},
+		Spec: v1beta1.WorkspaceSpec{Type: "Team"},
+		Status: v1beta1.WorkspaceStatus{
+			URL:   "https://kcp/clusters/root:org:foo",
+			Phase: v1alpha1.ClusterWorkspacePhaseReady,
+		},
+	}, ws)
+}
+
+func TestProjectWorkspaceToClusterWorkspace(t *testing.T) {
+	var cws v1alpha1.ClusterWorkspace
+	ProjectWorkspaceToClusterWorkspace(&v1beta1.Workspace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "foo",
+			Labels:      map[string]string{"a": "b"},
+			Annotations: map[string]string{"c": "d"},
+			Finalizers:  []string{"example.com/finalizer"},
+		},
+		Spec: v1beta1.WorkspaceSpec{Type: "Team"},
+	}, &cws)
+
+	require.Equal(t, v1alpha1.ClusterWorkspace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "foo",
+			Labels:      map[string]string{"a": "b"},
+			Annotations: map[string]string{"c": "d"},
+		},
+		Spec: v1alpha1.ClusterWorkspaceSpec{Type: "Team"},
+	}, cws)
+}
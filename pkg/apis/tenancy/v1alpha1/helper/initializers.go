@@ -0,0 +1,203 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helper
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+)
+
+// SortInitializers orders the given initializers such that every initializer comes after its
+// dependencies, and keeps the given order otherwise. Dependencies on initializers that are not
+// given are ignored. It fails if the dependencies form a cycle.
+func SortInitializers(initializers []tenancyv1alpha1.ClusterWorkspaceInitializer, dependencies []tenancyv1alpha1.ClusterWorkspaceInitializerDependency) ([]tenancyv1alpha1.ClusterWorkspaceInitializer, error) {
+	given := sets.NewString()
+	for _, initializer := range initializers {
+		given.Insert(string(initializer))
+	}
+	dependsOn := map[tenancyv1alpha1.ClusterWorkspaceInitializer][]tenancyv1alpha1.ClusterWorkspaceInitializer{}
+	for _, d := range dependencies {
+		for _, dep := range d.DependsOn {
+			if given.Has(string(dep)) {
+				dependsOn[d.Initializer] = append(dependsOn[d.Initializer], dep)
+			}
+		}
+	}
+
+	sorted := make([]tenancyv1alpha1.ClusterWorkspaceInitializer, 0, len(initializers))
+	done := sets.NewString()
+	remaining := initializers
+	for len(remaining) > 0 {
+		var next []tenancyv1alpha1.ClusterWorkspaceInitializer
+		for _, initializer := range remaining {
+			if done.Has(string(initializer)) {
+				continue // duplicate
+			}
+			if hasAll(done, dependsOn[initializer]) {
+				sorted = append(sorted, initializer)
+				done.Insert(string(initializer))
+			} else {
+				next = append(next, initializer)
+			}
+		}
+		if len(next) == len(remaining) {
+			return nil, fmt.Errorf("initializer dependencies form a cycle: %s", formatCycle(findCycle(next[0], dependsOn, done)))
+		}
+		remaining = next
+	}
+
+	return sorted, nil
+}
+
+// ValidateInitializerDependencies checks that the dependencies only refer to the given initializers,
+// and that they do not form a cycle.
+func ValidateInitializerDependencies(initializers []tenancyv1alpha1.ClusterWorkspaceInitializer, dependencies []tenancyv1alpha1.ClusterWorkspaceInitializerDependency) error {
+	given := sets.NewString()
+	for _, initializer := range initializers {
+		given.Insert(string(initializer))
+	}
+	for _, d := range dependencies {
+		if !given.Has(string(d.Initializer)) {
+			return fmt.Errorf("initializer %q has dependencies, but is not an initializer of the type", d.Initializer)
+		}
+		for _, dep := range d.DependsOn {
+			if !given.Has(string(dep)) {
+				return fmt.Errorf("initializer %q depends on %q, which is not an initializer of the type", d.Initializer, dep)
+			}
+		}
+	}
+
+	_, err := SortInitializers(initializers, dependencies)
+	return err
+}
+
+// PendingInitializerDependencies returns the dependencies of the given initializer that have not
+// been cleared from the workspace yet. The initializer must not start before they are.
+func PendingInitializerDependencies(workspace *tenancyv1alpha1.ClusterWorkspace, initializer tenancyv1alpha1.ClusterWorkspaceInitializer) []tenancyv1alpha1.ClusterWorkspaceInitializer {
+	current := sets.NewString()
+	for _, i := range workspace.Status.Initializers {
+		current.Insert(string(i))
+	}
+
+	var pending []tenancyv1alpha1.ClusterWorkspaceInitializer
+	for _, d := range workspace.Status.InitializerDependencies {
+		if d.Initializer != initializer {
+			continue
+		}
+		for _, dep := range d.DependsOn {
+			if current.Has(string(dep)) {
+				pending = append(pending, dep)
+			}
+		}
+	}
+	return pending
+}
+
+// SetInitializerFailure records a failed attempt of the given initializer in the status of the workspace,
+// incrementing the failures of an existing entry. nextRetry is optional. Initializer controllers call it
+// for the ClusterWorkspaces they fail to initialize, and update their status.
+func SetInitializerFailure(workspace *tenancyv1alpha1.ClusterWorkspace, initializer tenancyv1alpha1.ClusterWorkspaceInitializer, err error, now metav1.Time, nextRetry *metav1.Time) {
+	failure := tenancyv1alpha1.ClusterWorkspaceInitializerFailure{
+		Initializer:     initializer,
+		Failures:        1,
+		LastError:       err.Error(),
+		LastFailureTime: now,
+		NextRetryTime:   nextRetry,
+	}
+	for i := range workspace.Status.InitializerFailures {
+		if workspace.Status.InitializerFailures[i].Initializer == initializer {
+			failure.Failures = workspace.Status.InitializerFailures[i].Failures + 1
+			workspace.Status.InitializerFailures[i] = failure
+			return
+		}
+	}
+	workspace.Status.InitializerFailures = append(workspace.Status.InitializerFailures, failure)
+}
+
+// ClearInitializerFailure removes the failures of the given initializer from the status of the workspace.
+// Initializer controllers call it when they succeed.
+func ClearInitializerFailure(workspace *tenancyv1alpha1.ClusterWorkspace, initializer tenancyv1alpha1.ClusterWorkspaceInitializer) {
+	var failures []tenancyv1alpha1.ClusterWorkspaceInitializerFailure
+	for _, failure := range workspace.Status.InitializerFailures {
+		if failure.Initializer != initializer {
+			failures = append(failures, failure)
+		}
+	}
+	workspace.Status.InitializerFailures = failures
+}
+
+func hasAll(s sets.String, initializers []tenancyv1alpha1.ClusterWorkspaceInitializer) bool {
+	for _, initializer := range initializers {
+		if !s.Has(string(initializer)) {
+			return false
+		}
+	}
+	return true
+}
+
+// findCycle follows unsatisfied dependencies starting at the given initializer until one repeats.
+// Every initializer that cannot be sorted has such a dependency.
+func findCycle(start tenancyv1alpha1.ClusterWorkspaceInitializer, dependsOn map[tenancyv1alpha1.ClusterWorkspaceInitializer][]tenancyv1alpha1.ClusterWorkspaceInitializer, done sets.String) []tenancyv1alpha1.ClusterWorkspaceInitializer {
+	var path []tenancyv1alpha1.ClusterWorkspaceInitializer
+	seen := map[tenancyv1alpha1.ClusterWorkspaceInitializer]int{}
+	current := start
+	for {
+		if i, found := seen[current]; found {
+			return append(path[i:], current)
+		}
+		seen[current] = len(path)
+		path = append(path, current)
+		for _, dep := range dependsOn[current] {
+			if !done.Has(string(dep)) {
+				current = dep
+				break
+			}
+		}
+	}
+}
+
+func formatCycle(cycle []tenancyv1alpha1.ClusterWorkspaceInitializer) string {
+	names := make([]string, 0, len(cycle))
+	for _, initializer := range cycle {
+		names = append(names, string(initializer))
+	}
+	return strings.Join(names, " -> ")
+}
+
+// InitializerToLabel returns the label key and value which are set on ClusterWorkspaces for the given initializer.
+// The key is prefixed with ClusterWorkspaceInitializerLabelPrefix, and both key and value are derived from a
+// hash of the initializer, as initializers are not valid label names.
+func InitializerToLabel(initializer tenancyv1alpha1.ClusterWorkspaceInitializer) (string, string) {
+	hash := fmt.Sprintf("%x", sha256.Sum224([]byte(initializer)))
+	return tenancyv1alpha1.ClusterWorkspaceInitializerLabelPrefix + hash, hash
+}
+
+// InitializerLabels returns the labels of all the given initializers.
+func InitializerLabels(initializers []tenancyv1alpha1.ClusterWorkspaceInitializer) map[string]string {
+	labels := make(map[string]string, len(initializers))
+	for _, initializer := range initializers {
+		key, value := InitializerToLabel(initializer)
+		labels[key] = value
+	}
+	return labels
+}
@@ -0,0 +1,148 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package helper
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+)
+
+func TestSortInitializers(t *testing.T) {
+	tests := []struct {
+		name         string
+		initializers []tenancyv1alpha1.ClusterWorkspaceInitializer
+		dependencies []tenancyv1alpha1.ClusterWorkspaceInitializerDependency
+		want         []tenancyv1alpha1.ClusterWorkspaceInitializer
+		wantErr      string
+	}{
+		{
+			name:         "no dependencies keep the order",
+			initializers: []tenancyv1alpha1.ClusterWorkspaceInitializer{"c", "a", "b"},
+			want:         []tenancyv1alpha1.ClusterWorkspaceInitializer{"c", "a", "b"},
+		},
+		{
+			name:         "dependencies come first",
+			initializers: []tenancyv1alpha1.ClusterWorkspaceInitializer{"bindings", "other", "rbac"},
+			dependencies: []tenancyv1alpha1.ClusterWorkspaceInitializerDependency{
+				{Initializer: "bindings", DependsOn: []tenancyv1alpha1.ClusterWorkspaceInitializer{"rbac"}},
+			},
+			want: []tenancyv1alpha1.ClusterWorkspaceInitializer{"other", "rbac", "bindings"},
+		},
+		{
+			name:         "transitive dependencies",
+			initializers: []tenancyv1alpha1.ClusterWorkspaceInitializer{"a", "b", "c"},
+			dependencies: []tenancyv1alpha1.ClusterWorkspaceInitializerDependency{
+				{Initializer: "a", DependsOn: []tenancyv1alpha1.ClusterWorkspaceInitializer{"b"}},
+				{Initializer: "b", DependsOn: []tenancyv1alpha1.ClusterWorkspaceInitializer{"c"}},
+			},
+			want: []tenancyv1alpha1.ClusterWorkspaceInitializer{"c", "b", "a"},
+		},
+		{
+			name:         "unknown dependencies are ignored",
+			initializers: []tenancyv1alpha1.ClusterWorkspaceInitializer{"a", "b"},
+			dependencies: []tenancyv1alpha1.ClusterWorkspaceInitializerDependency{
+				{Initializer: "a", DependsOn: []tenancyv1alpha1.ClusterWorkspaceInitializer{"x"}},
+			},
+			want: []tenancyv1alpha1.ClusterWorkspaceInitializer{"a", "b"},
+		},
+		{
+			name:         "self dependency",
+			initializers: []tenancyv1alpha1.ClusterWorkspaceInitializer{"a"},
+			dependencies: []tenancyv1alpha1.ClusterWorkspaceInitializerDependency{
+				{Initializer: "a", DependsOn: []tenancyv1alpha1.ClusterWorkspaceInitializer{"a"}},
+			},
+			wantErr: "initializer dependencies form a cycle: a -> a",
+		},
+		{
+			name:         "cycle",
+			initializers: []tenancyv1alpha1.ClusterWorkspaceInitializer{"x", "a", "b", "c"},
+			dependencies: []tenancyv1alpha1.ClusterWorkspaceInitializerDependency{
+				{Initializer: "x", DependsOn: []tenancyv1alpha1.ClusterWorkspaceInitializer{"a"}},
+				{Initializer: "a", DependsOn: []tenancyv1alpha1.ClusterWorkspaceInitializer{"b"}},
+				{Initializer: "b", DependsOn: []tenancyv1alpha1.ClusterWorkspaceInitializer{"c"}},
+				{Initializer: "c", DependsOn: []tenancyv1alpha1.ClusterWorkspaceInitializer{"a"}},
+			},
+			wantErr: "initializer dependencies form a cycle: a -> b -> c -> a",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := SortInitializers(tt.initializers, tt.dependencies)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestPendingInitializerDependencies(t *testing.T) {
+	workspace := &tenancyv1alpha1.ClusterWorkspace{
+		Status: tenancyv1alpha1.ClusterWorkspaceStatus{
+			Initializers: []tenancyv1alpha1.ClusterWorkspaceInitializer{"b", "c"},
+			InitializerDependencies: []tenancyv1alpha1.ClusterWorkspaceInitializerDependency{
+				{Initializer: "c", DependsOn: []tenancyv1alpha1.ClusterWorkspaceInitializer{"a", "b"}},
+			},
+		},
+	}
+
+	require.Equal(t, []tenancyv1alpha1.ClusterWorkspaceInitializer{"b"}, PendingInitializerDependencies(workspace, "c"))
+	require.Empty(t, PendingInitializerDependencies(workspace, "b"))
+}
+
+func TestInitializerFailures(t *testing.T) {
+	now := metav1.NewTime(time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC))
+	retry := metav1.NewTime(now.Add(time.Minute))
+	workspace := &tenancyv1alpha1.ClusterWorkspace{}
+
+	SetInitializerFailure(workspace, "a", errors.New("first"), now, nil)
+	SetInitializerFailure(workspace, "b", errors.New("other"), now, nil)
+	SetInitializerFailure(workspace, "a", errors.New("second"), now, &retry)
+	require.Equal(t, []tenancyv1alpha1.ClusterWorkspaceInitializerFailure{
+		{Initializer: "a", Failures: 2, LastError: "second", LastFailureTime: now, NextRetryTime: &retry},
+		{Initializer: "b", Failures: 1, LastError: "other", LastFailureTime: now},
+	}, workspace.Status.InitializerFailures)
+
+	ClearInitializerFailure(workspace, "a")
+	require.Equal(t, []tenancyv1alpha1.ClusterWorkspaceInitializerFailure{
+		{Initializer: "b", Failures: 1, LastError: "other", LastFailureTime: now},
+	}, workspace.Status.InitializerFailures)
+
+	ClearInitializerFailure(workspace, "b")
+	require.Nil(t, workspace.Status.InitializerFailures)
+}
+
+func TestInitializerToLabel(t *testing.T) {
+	key, value := InitializerToLabel("initializers.tenancy.kcp.dev/organization")
+	require.True(t, strings.HasPrefix(key, tenancyv1alpha1.ClusterWorkspaceInitializerLabelPrefix))
+	require.Empty(t, validation.IsQualifiedName(key))
+	require.Empty(t, validation.IsValidLabelValue(value))
+
+	otherKey, _ := InitializerToLabel("initializers.tenancy.kcp.dev/universal")
+	require.NotEqual(t, key, otherKey)
+}
@@ -51,6 +51,10 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 		&ClusterWorkspaceTypeList{},
 		&ClusterWorkspaceShard{},
 		&ClusterWorkspaceShardList{},
+		&WorkspaceSnapshot{},
+		&WorkspaceSnapshotList{},
+		&WorkspaceSource{},
+		&WorkspaceSourceList{},
 	)
 	metav1.AddToGroupVersion(scheme, SchemeGroupVersion)
 	return nil
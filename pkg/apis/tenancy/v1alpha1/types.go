@@ -22,6 +22,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
+	jobsv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/jobs/v1alpha1"
 	conditionsv1alpha1 "github.com/kcp-dev/kcp/third_party/conditions/apis/conditions/v1alpha1"
 	"github.com/kcp-dev/kcp/third_party/conditions/util/conditions"
 )
@@ -69,9 +70,29 @@ var _ conditions.Setter = &ClusterWorkspace{}
 
 // ClusterWorkspaceSpec holds the desired state of the ClusterWorkspace.
 type ClusterWorkspaceSpec struct {
+	// readOnly freezes the workspace, e.g. for compliance holds or to archive finished
+	// projects: all writes to objects in the workspace are rejected, while reads keep
+	// working. Setting or removing it requires the admin verb on clusterworkspaces/content.
+	//
 	// +optional
 	ReadOnly bool `json:"readOnly,omitempty"`
 
+	// ttl is the lifetime of the workspace counted from its creation, e.g. for ephemeral
+	// CI or demo workspaces. Once it has passed, the workspace is deleted or archived
+	// according to expirationAction. If not set on creation, it defaults to the defaultTTL
+	// of the ClusterWorkspaceType.
+	//
+	// +optional
+	TTL *metav1.Duration `json:"ttl,omitempty"`
+
+	// expirationAction is what happens to the workspace when its ttl has passed: "Delete"
+	// deletes it, "Archive" sets readOnly. If not set on creation, it defaults to the
+	// defaultExpirationAction of the ClusterWorkspaceType, and to "Delete" otherwise.
+	//
+	// +optional
+	// +kubebuilder:validation:Enum=Delete;Archive
+	ExpirationAction ClusterWorkspaceExpirationAction `json:"expirationAction,omitempty"`
+
 	// type defines properties of the workspace both on creation (e.g. initial
 	// resources and initially installed APIs) and during runtime (e.g. permissions).
 	//
@@ -88,6 +109,16 @@ type ClusterWorkspaceSpec struct {
 	Type string `json:"type,omitempty"`
 }
 
+// ClusterWorkspaceExpirationAction is what happens to a ClusterWorkspace when its ttl has passed.
+type ClusterWorkspaceExpirationAction string
+
+const (
+	// ClusterWorkspaceExpirationActionDelete deletes an expired workspace.
+	ClusterWorkspaceExpirationActionDelete ClusterWorkspaceExpirationAction = "Delete"
+	// ClusterWorkspaceExpirationActionArchive makes an expired workspace read-only.
+	ClusterWorkspaceExpirationActionArchive ClusterWorkspaceExpirationAction = "Archive"
+)
+
 // ClusterWorkspaceType specifies behaviour of workspaces of this type.
 //
 // +crd
@@ -104,6 +135,18 @@ type ClusterWorkspaceType struct {
 	Spec ClusterWorkspaceTypeSpec `json:"spec,omitempty"`
 }
 
+const (
+	// ExperimentalPropagateWorkspaceLabelsAnnotationKey is the annotation on a ClusterWorkspaceType
+	// opting in to keep its additionalWorkspaceLabels in sync on the existing ClusterWorkspaces of
+	// the type, i.e. to update changed labels and to remove labels removed from the type.
+	ExperimentalPropagateWorkspaceLabelsAnnotationKey = "experimental.tenancy.kcp.dev/propagate-workspace-labels"
+
+	// ExperimentalPropagatedWorkspaceLabelsAnnotationKey is the annotation on a ClusterWorkspace
+	// holding the comma separated keys of the labels propagated from its ClusterWorkspaceType, such
+	// that labels removed from the type are removed from the workspace too.
+	ExperimentalPropagatedWorkspaceLabelsAnnotationKey = "experimental.tenancy.kcp.dev/propagated-workspace-labels"
+)
+
 type ClusterWorkspaceTypeSpec struct {
 	// initializers are set of a ClusterWorkspace on creation and must be
 	// cleared by a controller before the workspace can be used. The workspace
@@ -112,13 +155,72 @@ type ClusterWorkspaceTypeSpec struct {
 	// +optional
 	Initializers []ClusterWorkspaceInitializer `json:"initializers,omitempty"`
 
+	// initializerDependencies declares initializers that must not start before other
+	// initializers of this type have completed, e.g. default APIBindings should only be
+	// created once RBAC has been bootstrapped. Initializers without dependencies run
+	// in parallel. The dependencies must not form a cycle.
+	//
+	// +optional
+	InitializerDependencies []ClusterWorkspaceInitializerDependency `json:"initializerDependencies,omitempty"`
+
 	// additionalWorkspaceLabels are a set of labels that will be added to a
-	// ClusterWorkspace on creation.
+	// ClusterWorkspace on creation. With the
+	// experimental.tenancy.kcp.dev/propagate-workspace-labels annotation on the
+	// ClusterWorkspaceType, they are also kept in sync on existing workspaces.
 	//
 	// +optional
 	AdditionalWorkspaceLabels map[string]string `json:"additionalWorkspaceLabels,omitempty"`
+
+	// allowedChildWorkspaceTypes is a list of workspace types that can be
+	// created in a workspace of this type, e.g. "Team". If empty, workspaces
+	// of any type can be created.
+	//
+	// +optional
+	AllowedChildWorkspaceTypes []ClusterWorkspaceTypeName `json:"allowedChildWorkspaceTypes,omitempty"`
+
+	// allowedParentWorkspaceTypes is a list of workspace types that workspaces
+	// of this type can be created in, e.g. "Organization". The root workspace
+	// is of type "Root". If empty, workspaces of this type can be created in
+	// any workspace.
+	//
+	// +optional
+	AllowedParentWorkspaceTypes []ClusterWorkspaceTypeName `json:"allowedParentWorkspaceTypes,omitempty"`
+
+	// excludedSystemCRDGroups is a list of API groups whose system CRDs are not
+	// served in workspaces of this type, e.g. "workload.kcp.dev" for a type whose
+	// workspaces never sync to workload clusters. System CRDs are kcp's own APIs,
+	// shared by all workspaces. This only opts workspaces out of them, it does not
+	// bind them through APIExports. Only "apiresource.kcp.dev", "workload.kcp.dev"
+	// and "scheduling.kcp.dev" can be excluded, other groups are ignored. If empty,
+	// all system CRDs of the type are served.
+	//
+	// +optional
+	// +listType=set
+	ExcludedSystemCRDGroups []string `json:"excludedSystemCRDGroups,omitempty"`
+
+	// defaultTTL is set as spec.ttl of new workspaces of this type which do not set one.
+	//
+	// +optional
+	DefaultTTL *metav1.Duration `json:"defaultTTL,omitempty"`
+
+	// defaultExpirationAction is set as spec.expirationAction of new workspaces of this
+	// type which do not set one.
+	//
+	// +optional
+	// +kubebuilder:validation:Enum=Delete;Archive
+	DefaultExpirationAction ClusterWorkspaceExpirationAction `json:"defaultExpirationAction,omitempty"`
 }
 
+// ClusterWorkspaceTypeName is the name of a ClusterWorkspaceType as used in
+// spec.type of a ClusterWorkspace, e.g. "Organization".
+//
+// +kubebuilder:validation:Pattern=`^[A-Z][a-zA-Z0-9]+$`
+type ClusterWorkspaceTypeName string
+
+// RootWorkspaceTypeName is the type of the root workspace, which has no
+// ClusterWorkspace object.
+const RootWorkspaceTypeName ClusterWorkspaceTypeName = "Root"
+
 // ClusterWorkspaceTypeList is a list of cluster workspace types
 //
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -133,6 +235,58 @@ type ClusterWorkspaceTypeList struct {
 // initialization controller for the given type of workspaces.
 type ClusterWorkspaceInitializer string
 
+// ClusterWorkspaceInitializerLabelPrefix is the prefix of the labels set on a ClusterWorkspace
+// for each of its initializers, in order to select the workspaces an initializer is responsible for.
+// The label key is derived from a hash of the initializer name.
+const ClusterWorkspaceInitializerLabelPrefix = "initializer.internal.kcp.dev/"
+
+// ClusterWorkspaceInitializerDependency declares that an initializer must not start
+// before the given other initializers have completed.
+type ClusterWorkspaceInitializerDependency struct {
+	// initializer is the initializer that waits for its dependencies.
+	//
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	Initializer ClusterWorkspaceInitializer `json:"initializer"`
+
+	// dependsOn are the initializers that must be completed before initializer starts.
+	//
+	// +required
+	// +kubebuilder:validation:MinItems=1
+	DependsOn []ClusterWorkspaceInitializer `json:"dependsOn"`
+}
+
+// ClusterWorkspaceInitializerFailure records the failed attempts of an initializer controller to
+// initialize a workspace.
+type ClusterWorkspaceInitializerFailure struct {
+	// initializer is the failing initializer.
+	//
+	// +required
+	// +kubebuilder:validation:MinLength=1
+	Initializer ClusterWorkspaceInitializer `json:"initializer"`
+
+	// failures is the number of consecutive failed attempts.
+	//
+	// +required
+	// +kubebuilder:validation:Minimum=1
+	Failures int32 `json:"failures"`
+
+	// lastError is the error of the last failed attempt.
+	//
+	// +optional
+	LastError string `json:"lastError,omitempty"`
+
+	// lastFailureTime is the time of the last failed attempt.
+	//
+	// +required
+	LastFailureTime metav1.Time `json:"lastFailureTime"`
+
+	// nextRetryTime is the earliest time the initializer controller retries, if known.
+	//
+	// +optional
+	NextRetryTime *metav1.Time `json:"nextRetryTime,omitempty"`
+}
+
 // ClusterWorkspacePhaseType is the type of the current phase of the workspace
 type ClusterWorkspacePhaseType string
 
@@ -173,6 +327,70 @@ type ClusterWorkspaceStatus struct {
 	//
 	// +optional
 	Initializers []ClusterWorkspaceInitializer `json:"initializers,omitempty"`
+
+	// initializerDependencies are copied from the ClusterWorkspaceType on transition to the
+	// "Initializing" phase. An initializer must not be cleared before its dependencies.
+	//
+	// +optional
+	InitializerDependencies []ClusterWorkspaceInitializerDependency `json:"initializerDependencies,omitempty"`
+
+	// initializerFailures record the failed attempts of initializer controllers to initialize the
+	// workspace, one entry per failing initializer. An initializer controller updates its entry on
+	// every failed attempt, and removes it when it succeeds. Entries of initializers that are no
+	// longer pending are removed by the system.
+	//
+	// +optional
+	// +listType=map
+	// +listMapKey=initializer
+	InitializerFailures []ClusterWorkspaceInitializerFailure `json:"initializerFailures,omitempty"`
+
+	// timeline records when the workspace went through the phases of its lifecycle.
+	//
+	// +optional
+	Timeline *ClusterWorkspaceTimeline `json:"timeline,omitempty"`
+
+	// usage is the number of objects in the workspace, updated periodically by the storage
+	// watchdog.
+	//
+	// +optional
+	Usage *ClusterWorkspaceUsage `json:"usage,omitempty"`
+
+	// expirationTimestamp is the time the ttl of the workspace passes, i.e. when it is
+	// deleted or archived.
+	//
+	// +optional
+	ExpirationTimestamp *metav1.Time `json:"expirationTimestamp,omitempty"`
+}
+
+// ClusterWorkspaceUsage is the number of objects in a workspace.
+type ClusterWorkspaceUsage struct {
+	// objectCount is the number of objects of the workspace in storage.
+	//
+	// +required
+	ObjectCount int64 `json:"objectCount"`
+
+	// lastUpdateTime is the time the usage was computed.
+	//
+	// +required
+	LastUpdateTime metav1.Time `json:"lastUpdateTime"`
+}
+
+// ClusterWorkspaceTimeline records when a workspace went through the phases of its lifecycle.
+type ClusterWorkspaceTimeline struct {
+	// scheduled is the time the workspace was accepted by a shard and moved to the "Initializing" phase.
+	//
+	// +optional
+	Scheduled *metav1.Time `json:"scheduled,omitempty"`
+
+	// initialized is the time all initializers of the workspace had been cleared.
+	//
+	// +optional
+	Initialized *metav1.Time `json:"initialized,omitempty"`
+
+	// ready is the time the workspace moved to the "Ready" phase.
+	//
+	// +optional
+	Ready *metav1.Time `json:"ready,omitempty"`
 }
 
 // These are valid conditions of workspace.
@@ -192,11 +410,53 @@ const (
 	// referenced ClusterWorkspaceShard object got deleted.
 	WorkspaceShardValidReasonShardNotFound = "ShardNotFound"
 
+	// WorkspaceInitialized represents the status of the initializers of the workspace. It is false while
+	// initializers are pending, and true once all of them have completed.
+	WorkspaceInitialized conditionsv1alpha1.ConditionType = "WorkspaceInitialized"
+	// WorkspaceInitializedReasonInitializersPending reason in WorkspaceInitialized condition means that
+	// some initializers have not completed yet.
+	WorkspaceInitializedReasonInitializersPending = "InitializersPending"
+	// WorkspaceInitializedReasonInitializerTimeout reason in WorkspaceInitialized condition means that
+	// no initializer has completed for a while, i.e. the pending initializers are likely stuck.
+	WorkspaceInitializedReasonInitializerTimeout = "InitializerTimeout"
+
+	// WorkspaceInitializersHealthy reports whether the initializer controllers initialize the workspace without
+	// errors. It is false while status.initializerFailures lists pending initializers, and true otherwise.
+	WorkspaceInitializersHealthy conditionsv1alpha1.ConditionType = "InitializersHealthy"
+	// WorkspaceInitializersHealthyReasonInitializerFailed reason in InitializersHealthy condition means that
+	// the initializers named in the message failed and are being retried.
+	WorkspaceInitializersHealthyReasonInitializerFailed = "InitializerFailed"
+
 	// WorkspaceDeletionContentSuccess represents the status that all resources in the workspace is deleting
 	WorkspaceDeletionContentSuccess conditionsv1alpha1.ConditionType = "WorkspaceDeletionContentSuccess"
 
 	// WorkspaceContentDeleted represents the status that all resources in the workspace is deleted.
 	WorkspaceContentDeleted conditionsv1alpha1.ConditionType = "WorkspaceContentDeleted"
+
+	// WorkspaceDeletionProgress reports the progress of the content deletion of a terminating workspace. It is
+	// false with the number of resources remaining by API group in the message while content is left, and true
+	// once all content is gone.
+	WorkspaceDeletionProgress conditionsv1alpha1.ConditionType = "DeletionProgress"
+	// WorkspaceDeletionProgressReasonResourcesRemaining reason in DeletionProgress condition means that some
+	// resources in the workspace are still waiting to be deleted.
+	WorkspaceDeletionProgressReasonResourcesRemaining = "ResourcesRemaining"
+
+	// WorkspaceStorageWithinLimits reports whether the usage of the workspace is within the object count
+	// limit of the storage watchdog. While it is false, creates in the workspace are rejected.
+	WorkspaceStorageWithinLimits conditionsv1alpha1.ConditionType = "StorageWithinLimits"
+	// WorkspaceStorageWithinLimitsReasonObjectCountExceeded reason in StorageWithinLimits condition means that
+	// the workspace has more objects than allowed.
+	WorkspaceStorageWithinLimitsReasonObjectCountExceeded = "ObjectCountExceeded"
+
+	// WorkspaceLifetimeRemaining reports whether a workspace with a ttl is far from its expiration. It turns
+	// false ahead of the expiration as a warning, and stays false on archived workspaces.
+	WorkspaceLifetimeRemaining conditionsv1alpha1.ConditionType = "LifetimeRemaining"
+	// WorkspaceLifetimeRemainingReasonExpiringSoon reason in LifetimeRemaining condition means that the
+	// workspace is about to be deleted or archived.
+	WorkspaceLifetimeRemainingReasonExpiringSoon = "ExpiringSoon"
+	// WorkspaceLifetimeRemainingReasonExpired reason in LifetimeRemaining condition means that the ttl of the
+	// workspace has passed.
+	WorkspaceLifetimeRemainingReasonExpired = "Expired"
 )
 
 // ClusterWorkspaceLocation specifies workspace placement information, including current, desired (target), and
@@ -258,6 +518,13 @@ func (in *ClusterWorkspaceShard) GetConditions() conditionsv1alpha1.Conditions {
 var _ conditions.Getter = &ClusterWorkspaceShard{}
 var _ conditions.Setter = &ClusterWorkspaceShard{}
 
+// ExperimentalMigrateExternalURLAnnotationKey is the annotation on a ClusterWorkspaceShard
+// acknowledging a change of spec.externalURL, e.g. during a domain migration. Its value must
+// be the previous external URL. Without it, changes of the external URL are rejected as they
+// would break the URLs of existing workspaces. With it, the status.baseURL of the workspaces
+// scheduled to the shard is rewritten to the new external URL.
+const ExperimentalMigrateExternalURLAnnotationKey = "experimental.tenancy.kcp.dev/migrate-external-url"
+
 // ClusterWorkspaceShardSpec holds the desired state of the ClusterWorkspaceShard.
 type ClusterWorkspaceShardSpec struct {
 	// baseURL is the address of the KCP shard for direct connections, e.g. by some
@@ -274,7 +541,8 @@ type ClusterWorkspaceShardSpec struct {
 	// ExternalURL is the externally visible address presented to users in Workspace URLs.
 	// Changing this will break all existing workspaces on that shard, i.e. existing
 	// kubeconfigs of clients will be invalid. Hence, when changing this value, the old
-	// URL used by clients must keep working.
+	// URL used by clients must keep working. Changes must be acknowledged with the
+	// experimental.tenancy.kcp.dev/migrate-external-url annotation.
 	//
 	// The external address will not be unique if a front-proxy does a fan-out to
 	// shards, but all workspace client will talk to the front-proxy. In that case,
@@ -290,6 +558,17 @@ type ClusterWorkspaceShardSpec struct {
 	// +kubebuilder:Required
 	// +required
 	ExternalURL string `json:"externalURL"`
+
+	// virtualWorkspaceURL is the externally visible address of the virtual workspaces of the shard,
+	// without the /services path, e.g. of a stand-alone virtual workspace apiserver when the shard
+	// does not run them in-process. It is used in the virtual workspace URLs presented to users and
+	// syncers.
+	//
+	// This will be defaulted to the value of the baseURL.
+	//
+	// +kubebuilder:validation:Format=uri
+	// +optional
+	VirtualWorkspaceURL string `json:"virtualWorkspaceURL,omitempty"`
 }
 
 // ClusterWorkspaceShardStatus communicates the observed state of the ClusterWorkspaceShard.
@@ -312,3 +591,329 @@ type ClusterWorkspaceShardList struct {
 
 	Items []ClusterWorkspaceShard `json:"items"`
 }
+
+// WorkspaceSnapshot captures the objects of a ClusterWorkspace at one resourceVersion. New
+// ClusterWorkspaces of any type can be created from the snapshot by setting the
+// experimental.tenancy.kcp.dev/snapshot annotation.
+//
+// +crd
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster,categories=kcp
+// +kubebuilder:printcolumn:name="Workspace",type=string,JSONPath=`.spec.workspace`,description="The workspace of the snapshot"
+// +kubebuilder:printcolumn:name="Phase",type=string,JSONPath=`.status.phase`,description="The current phase (e.g. Pending, Running, Succeeded, Failed)"
+// +kubebuilder:printcolumn:name="Objects",type=integer,JSONPath=`.status.objectCount`,description="The number of captured objects"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+type WorkspaceSnapshot struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// +optional
+	Spec WorkspaceSnapshotSpec `json:"spec,omitempty"`
+
+	// +optional
+	Status WorkspaceSnapshotStatus `json:"status,omitempty"`
+}
+
+func (in *WorkspaceSnapshot) SetConditions(c conditionsv1alpha1.Conditions) {
+	in.Status.Conditions = c
+}
+
+func (in *WorkspaceSnapshot) GetConditions() conditionsv1alpha1.Conditions {
+	return in.Status.Conditions
+}
+
+func (in *WorkspaceSnapshot) GetJobPolicy() jobsv1alpha1.JobPolicy {
+	return in.Spec.JobPolicy
+}
+
+func (in *WorkspaceSnapshot) GetJobStatus() *jobsv1alpha1.JobStatus {
+	return &in.Status.JobStatus
+}
+
+var _ conditions.Getter = &WorkspaceSnapshot{}
+var _ conditions.Setter = &WorkspaceSnapshot{}
+
+// WorkspaceSnapshotSpec holds the desired state of the WorkspaceSnapshot.
+type WorkspaceSnapshotSpec struct {
+	// workspace is the name of the ClusterWorkspace to snapshot, in the workspace of the
+	// WorkspaceSnapshot. It is immutable.
+	//
+	// +kubebuilder:validation:MinLength=1
+	// +required
+	// +kubebuilder:validation:Required
+	Workspace string `json:"workspace"`
+
+	// The snapshots of the same workspace are siblings, i.e. the history limits apply to the
+	// snapshots of spec.workspace. Snapshots still being restored into a workspace are kept.
+	jobsv1alpha1.JobPolicy `json:",inline"`
+}
+
+// WorkspaceSnapshotStatus communicates the observed state of the WorkspaceSnapshot. The phase is
+// Succeeded once the objects have been captured and workspaces can be created from the snapshot,
+// and Failed if the objects cannot be captured, e.g. because they exceed the maximal size of a
+// snapshot.
+type WorkspaceSnapshotStatus struct {
+	jobsv1alpha1.JobStatus `json:",inline"`
+
+	// resourceVersion is the resourceVersion of the workspace at which the objects were captured.
+	//
+	// +optional
+	ResourceVersion string `json:"resourceVersion,omitempty"`
+
+	// objectCount is the number of captured objects.
+	//
+	// +optional
+	ObjectCount int `json:"objectCount,omitempty"`
+}
+
+const (
+	// WorkspaceSnapshotCaptured represents the status of capturing the objects of the workspace.
+	WorkspaceSnapshotCaptured conditionsv1alpha1.ConditionType = "SnapshotCaptured"
+	// WorkspaceSnapshotReasonWorkspaceNotFound reason in the SnapshotCaptured condition means that
+	// the workspace does not exist or is not ready.
+	WorkspaceSnapshotReasonWorkspaceNotFound = "WorkspaceNotFound"
+	// WorkspaceSnapshotReasonCaptureFailed reason in the SnapshotCaptured condition means that the
+	// objects of the workspace could not be listed.
+	WorkspaceSnapshotReasonCaptureFailed = "CaptureFailed"
+	// WorkspaceSnapshotReasonTooLarge reason in the SnapshotCaptured condition means that the
+	// objects of the workspace exceed the maximal size of a snapshot.
+	WorkspaceSnapshotReasonTooLarge = "TooLarge"
+
+	// ExperimentalWorkspaceSnapshotAnnotationKey is the annotation on a ClusterWorkspace naming a
+	// WorkspaceSnapshot in the same workspace to create the objects of the new workspace from.
+	// It is immutable after creation.
+	ExperimentalWorkspaceSnapshotAnnotationKey = "experimental.tenancy.kcp.dev/snapshot"
+
+	// WorkspaceSnapshotInitializer is the initializer of ClusterWorkspaces created from a WorkspaceSnapshot.
+	// It depends on the initializers of the type of the workspace, and is cleared once the objects of the
+	// snapshot have been created.
+	WorkspaceSnapshotInitializer ClusterWorkspaceInitializer = "tenancy.kcp.dev/snapshot"
+
+	// WorkspaceSnapshotRestored represents the status of creating the objects of a
+	// WorkspaceSnapshot in a ClusterWorkspace created from the snapshot.
+	WorkspaceSnapshotRestored conditionsv1alpha1.ConditionType = "SnapshotRestored"
+	// WorkspaceSnapshotReasonSnapshotNotReady reason in the SnapshotRestored condition means that
+	// the WorkspaceSnapshot does not exist or is not ready.
+	WorkspaceSnapshotReasonSnapshotNotReady = "SnapshotNotReady"
+	// WorkspaceSnapshotReasonRestoreFailed reason in the SnapshotRestored condition means that
+	// some objects could not be created yet.
+	WorkspaceSnapshotReasonRestoreFailed = "RestoreFailed"
+)
+
+// WorkspaceSnapshotList is a list of WorkspaceSnapshot resources
+//
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type WorkspaceSnapshotList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []WorkspaceSnapshot `json:"items"`
+}
+
+// WorkspaceSource reconciles the objects of its workspace from the manifests in a path of a Git
+// repository. The manifests are applied with server-side apply in regular intervals, and
+// objects removed from the repository are deleted if pruning is enabled.
+//
+// +crd
+// +genclient
+// +genclient:nonNamespaced
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster,categories=kcp
+// +kubebuilder:printcolumn:name="URL",type=string,JSONPath=`.spec.url`,description="The Git repository"
+// +kubebuilder:printcolumn:name="Revision",type=string,JSONPath=`.status.lastAppliedRevision`,description="The last applied commit"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+type WorkspaceSource struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	// +optional
+	Spec WorkspaceSourceSpec `json:"spec,omitempty"`
+
+	// +optional
+	Status WorkspaceSourceStatus `json:"status,omitempty"`
+}
+
+func (in *WorkspaceSource) SetConditions(c conditionsv1alpha1.Conditions) {
+	in.Status.Conditions = c
+}
+
+func (in *WorkspaceSource) GetConditions() conditionsv1alpha1.Conditions {
+	return in.Status.Conditions
+}
+
+var _ conditions.Getter = &WorkspaceSource{}
+var _ conditions.Setter = &WorkspaceSource{}
+
+// WorkspaceSourceSpec holds the desired state of the WorkspaceSource.
+type WorkspaceSourceSpec struct {
+	// url is the http or https URL of the Git repository.
+	//
+	// +kubebuilder:validation:Pattern:="^https?://"
+	// +required
+	// +kubebuilder:validation:Required
+	URL string `json:"url"`
+
+	// ref is the branch or tag to check out. The default branch of the repository is used
+	// if it is empty.
+	//
+	// +optional
+	Ref string `json:"ref,omitempty"`
+
+	// path is the directory in the repository holding the YAML or JSON manifests. All
+	// manifests in the directory and its subdirectories are applied. The root of the
+	// repository is used if it is empty.
+	//
+	// +optional
+	Path string `json:"path,omitempty"`
+
+	// serviceAccountRef references the service account in the workspace whose permissions
+	// are used to read the secret, and to apply and prune the objects. Users creating or
+	// updating the WorkspaceSource must be allowed to impersonate it.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	ServiceAccountRef WorkspaceSourceServiceAccountReference `json:"serviceAccountRef"`
+
+	// secretRef references a secret in the workspace with username and password keys
+	// to authenticate against the Git repository.
+	//
+	// +optional
+	SecretRef *WorkspaceSourceSecretReference `json:"secretRef,omitempty"`
+
+	// interval is the time between two reconciliations of the repository.
+	//
+	// +kubebuilder:default="5m"
+	// +optional
+	Interval metav1.Duration `json:"interval,omitempty"`
+
+	// prune enables the deletion of objects that were applied before, but are removed
+	// from the repository.
+	//
+	// +optional
+	Prune bool `json:"prune,omitempty"`
+
+	// suspend stops the reconciliation of the repository.
+	//
+	// +optional
+	Suspend bool `json:"suspend,omitempty"`
+}
+
+// WorkspaceSourceSecretReference references a secret in the workspace of the WorkspaceSource.
+type WorkspaceSourceSecretReference struct {
+	// namespace of the secret.
+	//
+	// +kubebuilder:validation:MinLength=1
+	// +required
+	// +kubebuilder:validation:Required
+	Namespace string `json:"namespace"`
+
+	// name of the secret.
+	//
+	// +kubebuilder:validation:MinLength=1
+	// +required
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+}
+
+// WorkspaceSourceServiceAccountReference references a service account in the workspace of the WorkspaceSource.
+type WorkspaceSourceServiceAccountReference struct {
+	// namespace of the service account.
+	//
+	// +kubebuilder:validation:MinLength=1
+	// +required
+	// +kubebuilder:validation:Required
+	Namespace string `json:"namespace"`
+
+	// name of the service account.
+	//
+	// +kubebuilder:validation:MinLength=1
+	// +required
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+}
+
+// WorkspaceSourceStatus communicates the observed state of the WorkspaceSource.
+type WorkspaceSourceStatus struct {
+	// lastAppliedRevision is the commit of the last successfully applied manifests.
+	//
+	// +optional
+	LastAppliedRevision string `json:"lastAppliedRevision,omitempty"`
+
+	// lastSyncTime is the time the manifests were last applied successfully.
+	//
+	// +optional
+	LastSyncTime *metav1.Time `json:"lastSyncTime,omitempty"`
+
+	// objects are the objects applied from the manifests of the last applied revision.
+	//
+	// +optional
+	Objects []WorkspaceSourceObject `json:"objects,omitempty"`
+
+	// Current processing state of the WorkspaceSource.
+	// +optional
+	Conditions conditionsv1alpha1.Conditions `json:"conditions,omitempty"`
+}
+
+// WorkspaceSourceObject references an object applied by a WorkspaceSource.
+type WorkspaceSourceObject struct {
+	// group of the object. Empty for the core group.
+	//
+	// +optional
+	Group string `json:"group,omitempty"`
+
+	// version of the object.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	Version string `json:"version"`
+
+	// resource of the object.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	Resource string `json:"resource"`
+
+	// namespace of the object. Empty for cluster-scoped objects.
+	//
+	// +optional
+	Namespace string `json:"namespace,omitempty"`
+
+	// name of the object.
+	//
+	// +required
+	// +kubebuilder:validation:Required
+	Name string `json:"name"`
+}
+
+const (
+	// WorkspaceSourceSynced represents the status of applying the manifests of the repository.
+	WorkspaceSourceSynced conditionsv1alpha1.ConditionType = "Synced"
+	// WorkspaceSourceReasonFetchFailed reason in the Synced condition means that the repository
+	// could not be cloned.
+	WorkspaceSourceReasonFetchFailed = "FetchFailed"
+	// WorkspaceSourceReasonInvalidManifests reason in the Synced condition means that the
+	// manifests in the repository could not be decoded.
+	WorkspaceSourceReasonInvalidManifests = "InvalidManifests"
+	// WorkspaceSourceReasonApplyFailed reason in the Synced condition means that some objects
+	// could not be applied or pruned.
+	WorkspaceSourceReasonApplyFailed = "ApplyFailed"
+	// WorkspaceSourceReasonSuspended reason in the Synced condition means that the
+	// reconciliation is suspended.
+	WorkspaceSourceReasonSuspended = "Suspended"
+)
+
+// WorkspaceSourceList is a list of WorkspaceSource resources
+//
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type WorkspaceSourceList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []WorkspaceSource `json:"items"`
+}
@@ -23,6 +23,7 @@ package v1alpha1
 
 import (
 	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 
 	conditionsv1alpha1 "github.com/kcp-dev/kcp/third_party/conditions/apis/conditions/v1alpha1"
@@ -33,7 +34,7 @@ func (in *ClusterWorkspace) DeepCopyInto(out *ClusterWorkspace) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
+	in.Spec.DeepCopyInto(&out.Spec)
 	in.Status.DeepCopyInto(&out.Status)
 	return
 }
@@ -56,6 +57,48 @@ func (in *ClusterWorkspace) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterWorkspaceInitializerDependency) DeepCopyInto(out *ClusterWorkspaceInitializerDependency) {
+	*out = *in
+	if in.DependsOn != nil {
+		in, out := &in.DependsOn, &out.DependsOn
+		*out = make([]ClusterWorkspaceInitializer, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterWorkspaceInitializerDependency.
+func (in *ClusterWorkspaceInitializerDependency) DeepCopy() *ClusterWorkspaceInitializerDependency {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterWorkspaceInitializerDependency)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterWorkspaceInitializerFailure) DeepCopyInto(out *ClusterWorkspaceInitializerFailure) {
+	*out = *in
+	in.LastFailureTime.DeepCopyInto(&out.LastFailureTime)
+	if in.NextRetryTime != nil {
+		in, out := &in.NextRetryTime, &out.NextRetryTime
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterWorkspaceInitializerFailure.
+func (in *ClusterWorkspaceInitializerFailure) DeepCopy() *ClusterWorkspaceInitializerFailure {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterWorkspaceInitializerFailure)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ClusterWorkspaceList) DeepCopyInto(out *ClusterWorkspaceList) {
 	*out = *in
@@ -215,6 +258,11 @@ func (in *ClusterWorkspaceShardStatus) DeepCopy() *ClusterWorkspaceShardStatus {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ClusterWorkspaceSpec) DeepCopyInto(out *ClusterWorkspaceSpec) {
 	*out = *in
+	if in.TTL != nil {
+		in, out := &in.TTL, &out.TTL
+		*out = new(metav1.Duration)
+		**out = **in
+	}
 	return
 }
 
@@ -244,6 +292,34 @@ func (in *ClusterWorkspaceStatus) DeepCopyInto(out *ClusterWorkspaceStatus) {
 		*out = make([]ClusterWorkspaceInitializer, len(*in))
 		copy(*out, *in)
 	}
+	if in.InitializerDependencies != nil {
+		in, out := &in.InitializerDependencies, &out.InitializerDependencies
+		*out = make([]ClusterWorkspaceInitializerDependency, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.InitializerFailures != nil {
+		in, out := &in.InitializerFailures, &out.InitializerFailures
+		*out = make([]ClusterWorkspaceInitializerFailure, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Timeline != nil {
+		in, out := &in.Timeline, &out.Timeline
+		*out = new(ClusterWorkspaceTimeline)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Usage != nil {
+		in, out := &in.Usage, &out.Usage
+		*out = new(ClusterWorkspaceUsage)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ExpirationTimestamp != nil {
+		in, out := &in.ExpirationTimestamp, &out.ExpirationTimestamp
+		*out = (*in).DeepCopy()
+	}
 	return
 }
 
@@ -257,6 +333,34 @@ func (in *ClusterWorkspaceStatus) DeepCopy() *ClusterWorkspaceStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterWorkspaceTimeline) DeepCopyInto(out *ClusterWorkspaceTimeline) {
+	*out = *in
+	if in.Scheduled != nil {
+		in, out := &in.Scheduled, &out.Scheduled
+		*out = (*in).DeepCopy()
+	}
+	if in.Initialized != nil {
+		in, out := &in.Initialized, &out.Initialized
+		*out = (*in).DeepCopy()
+	}
+	if in.Ready != nil {
+		in, out := &in.Ready, &out.Ready
+		*out = (*in).DeepCopy()
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterWorkspaceTimeline.
+func (in *ClusterWorkspaceTimeline) DeepCopy() *ClusterWorkspaceTimeline {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterWorkspaceTimeline)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ClusterWorkspaceType) DeepCopyInto(out *ClusterWorkspaceType) {
 	*out = *in
@@ -325,6 +429,13 @@ func (in *ClusterWorkspaceTypeSpec) DeepCopyInto(out *ClusterWorkspaceTypeSpec)
 		*out = make([]ClusterWorkspaceInitializer, len(*in))
 		copy(*out, *in)
 	}
+	if in.InitializerDependencies != nil {
+		in, out := &in.InitializerDependencies, &out.InitializerDependencies
+		*out = make([]ClusterWorkspaceInitializerDependency, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.AdditionalWorkspaceLabels != nil {
 		in, out := &in.AdditionalWorkspaceLabels, &out.AdditionalWorkspaceLabels
 		*out = make(map[string]string, len(*in))
@@ -332,6 +443,26 @@ func (in *ClusterWorkspaceTypeSpec) DeepCopyInto(out *ClusterWorkspaceTypeSpec)
 			(*out)[key] = val
 		}
 	}
+	if in.AllowedChildWorkspaceTypes != nil {
+		in, out := &in.AllowedChildWorkspaceTypes, &out.AllowedChildWorkspaceTypes
+		*out = make([]ClusterWorkspaceTypeName, len(*in))
+		copy(*out, *in)
+	}
+	if in.AllowedParentWorkspaceTypes != nil {
+		in, out := &in.AllowedParentWorkspaceTypes, &out.AllowedParentWorkspaceTypes
+		*out = make([]ClusterWorkspaceTypeName, len(*in))
+		copy(*out, *in)
+	}
+	if in.ExcludedSystemCRDGroups != nil {
+		in, out := &in.ExcludedSystemCRDGroups, &out.ExcludedSystemCRDGroups
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.DefaultTTL != nil {
+		in, out := &in.DefaultTTL, &out.DefaultTTL
+		*out = new(metav1.Duration)
+		**out = **in
+	}
 	return
 }
 
@@ -344,3 +475,279 @@ func (in *ClusterWorkspaceTypeSpec) DeepCopy() *ClusterWorkspaceTypeSpec {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterWorkspaceUsage) DeepCopyInto(out *ClusterWorkspaceUsage) {
+	*out = *in
+	in.LastUpdateTime.DeepCopyInto(&out.LastUpdateTime)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterWorkspaceUsage.
+func (in *ClusterWorkspaceUsage) DeepCopy() *ClusterWorkspaceUsage {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterWorkspaceUsage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkspaceSnapshot) DeepCopyInto(out *WorkspaceSnapshot) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkspaceSnapshot.
+func (in *WorkspaceSnapshot) DeepCopy() *WorkspaceSnapshot {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkspaceSnapshot)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *WorkspaceSnapshot) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkspaceSnapshotList) DeepCopyInto(out *WorkspaceSnapshotList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]WorkspaceSnapshot, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkspaceSnapshotList.
+func (in *WorkspaceSnapshotList) DeepCopy() *WorkspaceSnapshotList {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkspaceSnapshotList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *WorkspaceSnapshotList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkspaceSnapshotSpec) DeepCopyInto(out *WorkspaceSnapshotSpec) {
+	*out = *in
+	in.JobPolicy.DeepCopyInto(&out.JobPolicy)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkspaceSnapshotSpec.
+func (in *WorkspaceSnapshotSpec) DeepCopy() *WorkspaceSnapshotSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkspaceSnapshotSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkspaceSnapshotStatus) DeepCopyInto(out *WorkspaceSnapshotStatus) {
+	*out = *in
+	in.JobStatus.DeepCopyInto(&out.JobStatus)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkspaceSnapshotStatus.
+func (in *WorkspaceSnapshotStatus) DeepCopy() *WorkspaceSnapshotStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkspaceSnapshotStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkspaceSource) DeepCopyInto(out *WorkspaceSource) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkspaceSource.
+func (in *WorkspaceSource) DeepCopy() *WorkspaceSource {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkspaceSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *WorkspaceSource) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkspaceSourceList) DeepCopyInto(out *WorkspaceSourceList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]WorkspaceSource, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkspaceSourceList.
+func (in *WorkspaceSourceList) DeepCopy() *WorkspaceSourceList {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkspaceSourceList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *WorkspaceSourceList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkspaceSourceObject) DeepCopyInto(out *WorkspaceSourceObject) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkspaceSourceObject.
+func (in *WorkspaceSourceObject) DeepCopy() *WorkspaceSourceObject {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkspaceSourceObject)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkspaceSourceSecretReference) DeepCopyInto(out *WorkspaceSourceSecretReference) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkspaceSourceSecretReference.
+func (in *WorkspaceSourceSecretReference) DeepCopy() *WorkspaceSourceSecretReference {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkspaceSourceSecretReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkspaceSourceServiceAccountReference) DeepCopyInto(out *WorkspaceSourceServiceAccountReference) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkspaceSourceServiceAccountReference.
+func (in *WorkspaceSourceServiceAccountReference) DeepCopy() *WorkspaceSourceServiceAccountReference {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkspaceSourceServiceAccountReference)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkspaceSourceSpec) DeepCopyInto(out *WorkspaceSourceSpec) {
+	*out = *in
+	out.ServiceAccountRef = in.ServiceAccountRef
+	if in.SecretRef != nil {
+		in, out := &in.SecretRef, &out.SecretRef
+		*out = new(WorkspaceSourceSecretReference)
+		**out = **in
+	}
+	out.Interval = in.Interval
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkspaceSourceSpec.
+func (in *WorkspaceSourceSpec) DeepCopy() *WorkspaceSourceSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkspaceSourceSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkspaceSourceStatus) DeepCopyInto(out *WorkspaceSourceStatus) {
+	*out = *in
+	if in.LastSyncTime != nil {
+		in, out := &in.LastSyncTime, &out.LastSyncTime
+		*out = (*in).DeepCopy()
+	}
+	if in.Objects != nil {
+		in, out := &in.Objects, &out.Objects
+		*out = make([]WorkspaceSourceObject, len(*in))
+		copy(*out, *in)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make(conditionsv1alpha1.Conditions, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkspaceSourceStatus.
+func (in *WorkspaceSourceStatus) DeepCopy() *WorkspaceSourceStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkspaceSourceStatus)
+	in.DeepCopyInto(out)
+	return out
+}
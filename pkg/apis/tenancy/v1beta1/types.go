@@ -91,3 +91,59 @@ type WorkspaceList struct {
 
 	Items []Workspace `json:"items"`
 }
+
+// WorkspaceAccessReview checks in one request which of a number of workspaces
+// the requesting user can access and administrate. It is only served by the
+// workspaces virtual workspace and is never persisted.
+//
+// +genclient
+// +genclient:nonNamespaced
+// +genclient:onlyVerbs=create
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+type WorkspaceAccessReview struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec WorkspaceAccessReviewSpec `json:"spec"`
+
+	// +optional
+	Status WorkspaceAccessReviewStatus `json:"status,omitempty"`
+}
+
+// WorkspaceAccessReviewSpec holds the workspaces to review.
+type WorkspaceAccessReviewSpec struct {
+	// workspaces are the names of the workspaces to review, as they are
+	// returned when listing workspaces in the same scope.
+	//
+	// +required
+	Workspaces []string `json:"workspaces"`
+}
+
+// WorkspaceAccessReviewStatus holds the result of the review.
+type WorkspaceAccessReviewStatus struct {
+	// workspaces holds one entry per reviewed workspace, in the order of spec.workspaces.
+	//
+	// +optional
+	Workspaces []WorkspaceAccess `json:"workspaces,omitempty"`
+}
+
+// WorkspaceAccess describes the permissions of the requesting user on one workspace.
+// A workspace that does not exist or that is not visible to the user is reported
+// without any permission.
+type WorkspaceAccess struct {
+	// name is the name of the workspace, as given in spec.workspaces.
+	//
+	// +required
+	Name string `json:"name"`
+
+	// access is true if the user can access the content of the workspace.
+	//
+	// +required
+	Access bool `json:"access"`
+
+	// admin is true if the user can administrate the workspace.
+	//
+	// +required
+	Admin bool `json:"admin"`
+}
@@ -87,6 +87,8 @@ const (
 	// on upstream resources storing the status of the downstream resource per workload cluster.
 	// Note that this is experimental and will disappear in the future without prior notice. It
 	// is used temporarily in the case that a resource is scheduled to multiple workload clusters.
+	// The syncers summarize the statuses of all workload clusters into the status of the upstream
+	// resource.
 	//
 	// The format is JSON.
 	InternalClusterStatusAnnotationPrefix = "experimental.status.workloads.kcp.dev/"
@@ -105,7 +107,72 @@ const (
 	// The format for the value of this annotation is: JSON Patch (https://tools.ietf.org/html/rfc6902).
 	ClusterSpecDiffAnnotationPrefix = "experimental.spec-diff.workloads.kcp.dev/"
 
+	// ExperimentalClusterSyncConditionAnnotationPrefix is the prefix of the annotation
+	//
+	//   experimental.sync-condition.workloads.kcp.dev/<workload-cluster-name>
+	//
+	// on upstream resources the syncer of <workload-cluster-name> could not sync downstream, e.g. because
+	// they exceed the maximal object size. The annotation is removed once the resource is synced.
+	// Note that this is experimental and will disappear in the future without prior notice.
+	//
+	// The format is a JSON encoded condition of type "Synced".
+	ExperimentalClusterSyncConditionAnnotationPrefix = "experimental.sync-condition.workloads.kcp.dev/"
+
+	// ExperimentalClusterMutationsAnnotationPrefix is the prefix of the annotation
+	//
+	//   experimental.mutations.workloads.kcp.dev/<workload-cluster-name>
+	//
+	// on upstream resources summarizing how the syncer of <workload-cluster-name> mutated the resource
+	// downstream, e.g. by pruning fields, injecting labels or rewriting the namespace. It is only set by
+	// syncers started with --record-downstream-mutations. Note that this is experimental and will
+	// disappear in the future without prior notice.
+	//
+	// The format is a JSON object with "added", "removed" and "changed" lists of JSON pointers
+	// (https://tools.ietf.org/html/rfc6901) into the resource, and the number of further paths
+	// left out in "truncated".
+	ExperimentalClusterMutationsAnnotationPrefix = "experimental.mutations.workloads.kcp.dev/"
+
 	// InternalDownstreamClusterLabel is a label with the upstream cluster name applied on the downstream cluster
 	// instead of state.internal.workloads.kcp.dev/<workload-cluster-name> which is used upstream.
 	InternalDownstreamClusterLabel = "internal.workloads.kcp.dev/cluster"
+
+	// InternalEndpointSliceMirrorLabel is a label on upstream EndpointSlices with the name of the workload
+	// cluster whose syncer mirrored them from the EndpointSlices of a synced service on the physical cluster.
+	// Together with kubernetes.io/service-name, the mirrors of all workload clusters make up the endpoints
+	// of the service across all placements.
+	InternalEndpointSliceMirrorLabel = "endpointslice.internal.workloads.kcp.dev/cluster"
+
+	// ExperimentalNamespaceNamingStrategyAnnotation is an annotation on a workload cluster selecting
+	// how the syncer names the namespaces on the physical cluster:
+	//
+	// - "hashed" (default): <prefix><sha224 of workspace and namespace>, with "kcp" as default prefix.
+	// - "readable": <prefix><workspace>-<namespace>, with the colons of the workspace replaced by dashes.
+	//
+	// Changing the strategy of a workload cluster with synced resources will orphan the existing
+	// namespaces on the physical cluster. Note that this is experimental and will disappear in the
+	// future without prior notice.
+	ExperimentalNamespaceNamingStrategyAnnotation = "experimental.workloads.kcp.dev/namespace-naming-strategy"
+
+	// ExperimentalNamespacePrefixAnnotation is an annotation on a workload cluster setting the custom
+	// prefix of the namespaces on the physical cluster for the strategy selected by
+	// experimental.workloads.kcp.dev/namespace-naming-strategy.
+	ExperimentalNamespacePrefixAnnotation = "experimental.workloads.kcp.dev/namespace-prefix"
+
+	// ExperimentalSyncPausedAnnotation is an annotation on namespaces of the physical cluster pausing the
+	// syncer for the objects in the namespace, e.g. while an operator troubleshoots the physical cluster:
+	//
+	// - "Spec": the syncer stops applying and deleting the objects downstream. Their status is still
+	//   synced upstream.
+	// - "All": the syncer stops syncing the objects in both directions.
+	//
+	// When the annotation is removed, the objects are synced again. Note that this is experimental and
+	// will disappear in the future without prior notice.
+	ExperimentalSyncPausedAnnotation = "experimental.workloads.kcp.dev/sync-paused"
+
+	// ExperimentalSyncPausedResourcesAnnotation is an annotation on a workload cluster pausing its syncer
+	// for whole resources. The value is a comma-separated list of <resource>.<group>=<mode> entries, e.g.
+	// "deployments.apps=Spec,services=All", with the modes of experimental.workloads.kcp.dev/sync-paused.
+	// The syncer picks up changes with its next heartbeat. Note that this is experimental and will
+	// disappear in the future without prior notice.
+	ExperimentalSyncPausedResourcesAnnotation = "experimental.workloads.kcp.dev/sync-paused-resources"
 )
@@ -65,6 +65,14 @@ type WorkloadClusterSpec struct {
 	// will be unassigned from the cluster.
 	// By default, workloads scheduled to the cluster are not evicted.
 	EvictAfter *metav1.Time `json:"evictAfter,omitempty"`
+
+	// Taints keep workloads off the cluster unless they tolerate them through the
+	// scheduling.kcp.dev/tolerations annotation on their namespace. The NoSchedule
+	// and NoExecute effects exclude the cluster from scheduling, NoExecute also
+	// unassigns namespaces already scheduled to the cluster. PreferNoSchedule
+	// clusters are only chosen if there is no other candidate.
+	// +optional
+	Taints []corev1.Taint `json:"taints,omitempty"`
 }
 
 // WorkloadClusterStatus communicates the observed state of the WorkloadCluster (from the controller).
@@ -78,6 +86,10 @@ type WorkloadClusterStatus struct {
 	// +optional
 	Capacity *corev1.ResourceList `json:"capacity,omitempty"`
 
+	// nodes counts the nodes of the physical cluster by readiness, as reported by the syncer.
+	// +optional
+	Nodes *WorkloadClusterNodeCounts `json:"nodes,omitempty"`
+
 	// Current processing state of the WorkloadCluster.
 	// +optional
 	Conditions conditionsv1alpha1.Conditions `json:"conditions,omitempty"`
@@ -88,6 +100,42 @@ type WorkloadClusterStatus struct {
 	// A timestamp indicating when the syncer last reported status.
 	// +optional
 	LastSyncerHeartbeatTime *metav1.Time `json:"lastSyncerHeartbeatTime,omitempty"`
+
+	// topologyLabels are the well-known topology labels (topology.kubernetes.io/region and
+	// topology.kubernetes.io/zone) shared by all nodes of the physical cluster, as reported
+	// by the syncer. A label whose value differs between nodes is not reported.
+	// +optional
+	TopologyLabels map[string]string `json:"topologyLabels,omitempty"`
+
+	// virtualWorkspaces are the URLs of the syncer virtual workspace serving the APIs of this
+	// WorkloadCluster to its syncer, one per shard.
+	// +optional
+	VirtualWorkspaces []VirtualWorkspace `json:"virtualWorkspaces,omitempty"`
+}
+
+// VirtualWorkspace is a virtual workspace endpoint.
+type VirtualWorkspace struct {
+	// url is the URL of the virtual workspace.
+	//
+	// +kubebuilder:validation:Format=uri
+	// +kubebuilder:validation:MinLength=1
+	// +required
+	URL string `json:"url"`
+}
+
+// WorkloadClusterNodeCounts counts the nodes of a physical cluster.
+type WorkloadClusterNodeCounts struct {
+	// total is the number of nodes.
+	// +kubebuilder:validation:Minimum=0
+	Total int32 `json:"total"`
+
+	// ready is the number of nodes with a Ready condition that is true.
+	// +kubebuilder:validation:Minimum=0
+	Ready int32 `json:"ready"`
+
+	// schedulable is the number of ready nodes that are not cordoned.
+	// +kubebuilder:validation:Minimum=0
+	Schedulable int32 `json:"schedulable"`
 }
 
 // WorkloadClusterList is a list of WorkloadCluster resources
@@ -29,6 +29,22 @@ import (
 	conditionsv1alpha1 "github.com/kcp-dev/kcp/third_party/conditions/apis/conditions/v1alpha1"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VirtualWorkspace) DeepCopyInto(out *VirtualWorkspace) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VirtualWorkspace.
+func (in *VirtualWorkspace) DeepCopy() *VirtualWorkspace {
+	if in == nil {
+		return nil
+	}
+	out := new(VirtualWorkspace)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *WorkloadCluster) DeepCopyInto(out *WorkloadCluster) {
 	*out = *in
@@ -90,6 +106,22 @@ func (in *WorkloadClusterList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *WorkloadClusterNodeCounts) DeepCopyInto(out *WorkloadClusterNodeCounts) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new WorkloadClusterNodeCounts.
+func (in *WorkloadClusterNodeCounts) DeepCopy() *WorkloadClusterNodeCounts {
+	if in == nil {
+		return nil
+	}
+	out := new(WorkloadClusterNodeCounts)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *WorkloadClusterSpec) DeepCopyInto(out *WorkloadClusterSpec) {
 	*out = *in
@@ -97,6 +129,13 @@ func (in *WorkloadClusterSpec) DeepCopyInto(out *WorkloadClusterSpec) {
 		in, out := &in.EvictAfter, &out.EvictAfter
 		*out = (*in).DeepCopy()
 	}
+	if in.Taints != nil {
+		in, out := &in.Taints, &out.Taints
+		*out = make([]v1.Taint, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -135,6 +174,11 @@ func (in *WorkloadClusterStatus) DeepCopyInto(out *WorkloadClusterStatus) {
 			}
 		}
 	}
+	if in.Nodes != nil {
+		in, out := &in.Nodes, &out.Nodes
+		*out = new(WorkloadClusterNodeCounts)
+		**out = **in
+	}
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
 		*out = make(conditionsv1alpha1.Conditions, len(*in))
@@ -151,6 +195,18 @@ func (in *WorkloadClusterStatus) DeepCopyInto(out *WorkloadClusterStatus) {
 		in, out := &in.LastSyncerHeartbeatTime, &out.LastSyncerHeartbeatTime
 		*out = (*in).DeepCopy()
 	}
+	if in.TopologyLabels != nil {
+		in, out := &in.TopologyLabels, &out.TopologyLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.VirtualWorkspaces != nil {
+		in, out := &in.VirtualWorkspaces, &out.VirtualWorkspaces
+		*out = make([]VirtualWorkspace, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -27,6 +27,18 @@ const (
 	SystemKcpClusterWorkspaceAdminGroup  = "system:kcp:clusterworkspace:admin"
 )
 
+// Verbs on the clusterworkspaces/content resource in the parent workspace that grant
+// a user access to a workspace. They are evaluated by the workspace content and the
+// top-level organization authorizers.
+const (
+	// WorkspaceAccessVerb grants access to the workspace.
+	WorkspaceAccessVerb = "access"
+	// WorkspaceMemberVerb grants access to the workspace and allows to create workspaces in it.
+	WorkspaceMemberVerb = "member"
+	// WorkspaceAdminVerb grants access to the workspace with cluster-admin permissions in it.
+	WorkspaceAdminVerb = "admin"
+)
+
 // ClusterRoleBindings return default rolebindings to the default roles
 func clusterRoleBindings() []rbacv1.ClusterRoleBinding {
 	return []rbacv1.ClusterRoleBinding{
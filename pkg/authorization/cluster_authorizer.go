@@ -0,0 +1,80 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package authorization
+
+import (
+	"context"
+
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/apiserver/pkg/authorization/authorizer"
+	genericapirequest "k8s.io/apiserver/pkg/endpoints/request"
+)
+
+// ClusterNameExtraKey is the user extra key holding the logical cluster of the request, as seen by
+// authorizers outside of kcp like the SubjectAccessReview webhook.
+const ClusterNameExtraKey = "authorization.kcp.dev/cluster-name"
+
+// NewClusterAwareAuthorizer returns an authorizer that adds the logical cluster of the request to the
+// user extra under ClusterNameExtraKey before asking the delegate. Authorizers that are not aware of
+// logical clusters, like the SubjectAccessReview webhook, see the cluster in spec.extra of the review,
+// which is also part of the key of the webhook response cache. Requests without a valid cluster are
+// not authorized by the delegate.
+func NewClusterAwareAuthorizer(delegate authorizer.Authorizer) authorizer.Authorizer {
+	return &clusterAwareAuthorizer{delegate: delegate}
+}
+
+type clusterAwareAuthorizer struct {
+	delegate authorizer.Authorizer
+}
+
+func (a *clusterAwareAuthorizer) Authorize(ctx context.Context, attr authorizer.Attributes) (authorizer.Decision, string, error) {
+	cluster, err := genericapirequest.ValidClusterFrom(ctx)
+	if err != nil {
+		return authorizer.DecisionNoOpinion, "", err
+	}
+	if cluster.Wildcard {
+		return authorizer.DecisionNoOpinion, "wildcard requests are not authorized by cluster unaware authorizers", nil
+	}
+
+	return a.delegate.Authorize(ctx, attributesWithClusterName(attr, cluster.Name.String()))
+}
+
+func attributesWithClusterName(attr authorizer.Attributes, clusterName string) authorizer.Attributes {
+	extra := map[string][]string{}
+	for k, v := range attr.GetUser().GetExtra() {
+		extra[k] = v
+	}
+	extra[ClusterNameExtraKey] = []string{clusterName}
+
+	return authorizer.AttributesRecord{
+		User: &user.DefaultInfo{
+			Name:   attr.GetUser().GetName(),
+			UID:    attr.GetUser().GetUID(),
+			Groups: attr.GetUser().GetGroups(),
+			Extra:  extra,
+		},
+		Verb:            attr.GetVerb(),
+		Namespace:       attr.GetNamespace(),
+		APIGroup:        attr.GetAPIGroup(),
+		APIVersion:      attr.GetAPIVersion(),
+		Resource:        attr.GetResource(),
+		Subresource:     attr.GetSubresource(),
+		Name:            attr.GetName(),
+		ResourceRequest: attr.IsResourceRequest(),
+		Path:            attr.GetPath(),
+	}
+}
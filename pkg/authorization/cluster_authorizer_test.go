@@ -0,0 +1,57 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package authorization
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kcp-dev/logicalcluster"
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/apiserver/pkg/authorization/authorizer"
+	genericapirequest "k8s.io/apiserver/pkg/endpoints/request"
+)
+
+func TestClusterAwareAuthorizer(t *testing.T) {
+	var got authorizer.Attributes
+	a := NewClusterAwareAuthorizer(authorizer.AuthorizerFunc(func(ctx context.Context, attr authorizer.Attributes) (authorizer.Decision, string, error) {
+		got = attr
+		return authorizer.DecisionAllow, "", nil
+	}))
+	attr := authorizer.AttributesRecord{
+		User:            &user.DefaultInfo{Name: "anna", Extra: map[string][]string{"scopes": {"read"}}},
+		Verb:            "get",
+		Resource:        "configmaps",
+		ResourceRequest: true,
+	}
+
+	ctx := genericapirequest.WithCluster(context.Background(), genericapirequest.Cluster{Name: logicalcluster.New("root:org:ws")})
+	dec, _, err := a.Authorize(ctx, attr)
+	require.NoError(t, err)
+	require.Equal(t, authorizer.DecisionAllow, dec)
+	require.Equal(t, map[string][]string{"scopes": {"read"}, ClusterNameExtraKey: {"root:org:ws"}}, got.GetUser().GetExtra())
+	require.Equal(t, map[string][]string{"scopes": {"read"}}, attr.User.GetExtra(), "the user of the request must not be modified")
+
+	got = nil
+	ctx = genericapirequest.WithCluster(context.Background(), genericapirequest.Cluster{Name: logicalcluster.Wildcard, Wildcard: true})
+	dec, _, err = a.Authorize(ctx, attr)
+	require.NoError(t, err)
+	require.Equal(t, authorizer.DecisionNoOpinion, dec)
+	require.Nil(t, got, "wildcard requests must not reach the delegate")
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package authorization
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apiserver/pkg/authorization/authorizer"
+	genericapirequest "k8s.io/apiserver/pkg/endpoints/request"
+)
+
+// NewSubtreeImpersonationAuthorizer returns an authorizer that evaluates impersonation requests not only
+// in the requested workspace, but also in all of its ancestors. Hence, a user granted the impersonate verb
+// in a workspace can impersonate in the whole subtree of that workspace, e.g. an org admin can impersonate
+// in the team workspaces of the org, but never outside of it. Impersonating one of the privilegedGroups
+// is denied as they are not scoped to a workspace.
+func NewSubtreeImpersonationAuthorizer(privilegedGroups []string, delegate authorizer.Authorizer) authorizer.Authorizer {
+	return &subtreeImpersonationAuthorizer{
+		privilegedGroups: sets.NewString(privilegedGroups...),
+		delegate:         delegate,
+	}
+}
+
+type subtreeImpersonationAuthorizer struct {
+	privilegedGroups sets.String
+	delegate         authorizer.Authorizer
+}
+
+func (a *subtreeImpersonationAuthorizer) Authorize(ctx context.Context, attr authorizer.Attributes) (authorized authorizer.Decision, reason string, err error) {
+	if attr.GetVerb() != "impersonate" || !attr.IsResourceRequest() {
+		return a.delegate.Authorize(ctx, attr)
+	}
+
+	if attr.GetResource() == "groups" && a.privilegedGroups.Has(attr.GetName()) {
+		return authorizer.DecisionDeny, fmt.Sprintf("impersonating group %q is not allowed", attr.GetName()), nil
+	}
+
+	dec, reason, err := a.delegate.Authorize(ctx, attr)
+	if err != nil || dec != authorizer.DecisionNoOpinion {
+		return dec, reason, err
+	}
+
+	cluster := genericapirequest.ClusterFrom(ctx)
+	if cluster == nil || cluster.Wildcard {
+		return dec, reason, nil
+	}
+
+	// walk up the workspace hierarchy and authorize the impersonation as if the request was for the ancestor
+	for clusterName, hasParent := cluster.Name.Parent(); hasParent; clusterName, hasParent = clusterName.Parent() {
+		ancestorCtx := genericapirequest.WithCluster(ctx, genericapirequest.Cluster{Name: clusterName})
+		if ancestorDec, _, err := a.delegate.Authorize(ancestorCtx, attr); err != nil {
+			return authorizer.DecisionNoOpinion, reason, err
+		} else if ancestorDec == authorizer.DecisionAllow {
+			return authorizer.DecisionAllow, fmt.Sprintf("impersonation granted in ancestor workspace %q", clusterName), nil
+		}
+	}
+
+	return dec, reason, nil
+}
@@ -0,0 +1,135 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package authorization
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kcp-dev/logicalcluster"
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/apiserver/pkg/authorization/authorizer"
+	genericapirequest "k8s.io/apiserver/pkg/endpoints/request"
+)
+
+// clusterAuthorizer allows everything in the given clusters, denies in the denied clusters
+// and has no opinion otherwise.
+type clusterAuthorizer struct {
+	allowed, denied []logicalcluster.Name
+}
+
+func (a *clusterAuthorizer) Authorize(ctx context.Context, attr authorizer.Attributes) (authorizer.Decision, string, error) {
+	cluster := genericapirequest.ClusterFrom(ctx)
+	for _, c := range a.allowed {
+		if cluster.Name == c {
+			return authorizer.DecisionAllow, "", nil
+		}
+	}
+	for _, c := range a.denied {
+		if cluster.Name == c {
+			return authorizer.DecisionDeny, "", nil
+		}
+	}
+	return authorizer.DecisionNoOpinion, "", nil
+}
+
+func TestSubtreeImpersonationAuthorizer(t *testing.T) {
+	org := logicalcluster.New("root:org")
+	team := org.Join("team")
+
+	impersonateUser := authorizer.AttributesRecord{Verb: "impersonate", Resource: "users", Name: "anna", ResourceRequest: true}
+
+	tests := []struct {
+		name         string
+		cluster      genericapirequest.Cluster
+		attr         authorizer.AttributesRecord
+		allowed      []logicalcluster.Name
+		denied       []logicalcluster.Name
+		wantDecision authorizer.Decision
+	}{
+		{
+			name:         "impersonation granted in the workspace",
+			cluster:      genericapirequest.Cluster{Name: team},
+			attr:         impersonateUser,
+			allowed:      []logicalcluster.Name{team},
+			wantDecision: authorizer.DecisionAllow,
+		},
+		{
+			name:         "impersonation granted in the parent",
+			cluster:      genericapirequest.Cluster{Name: team},
+			attr:         impersonateUser,
+			allowed:      []logicalcluster.Name{org},
+			wantDecision: authorizer.DecisionAllow,
+		},
+		{
+			name:         "impersonation granted in a child",
+			cluster:      genericapirequest.Cluster{Name: org},
+			attr:         impersonateUser,
+			allowed:      []logicalcluster.Name{team},
+			wantDecision: authorizer.DecisionNoOpinion,
+		},
+		{
+			name:         "impersonation granted in a sibling",
+			cluster:      genericapirequest.Cluster{Name: org.Join("other")},
+			attr:         impersonateUser,
+			allowed:      []logicalcluster.Name{team},
+			wantDecision: authorizer.DecisionNoOpinion,
+		},
+		{
+			name:         "impersonation denied in the workspace",
+			cluster:      genericapirequest.Cluster{Name: team},
+			attr:         impersonateUser,
+			allowed:      []logicalcluster.Name{org},
+			denied:       []logicalcluster.Name{team},
+			wantDecision: authorizer.DecisionDeny,
+		},
+		{
+			name:         "impersonation across workspaces",
+			cluster:      genericapirequest.Cluster{Name: logicalcluster.Wildcard, Wildcard: true},
+			attr:         impersonateUser,
+			allowed:      []logicalcluster.Name{org},
+			wantDecision: authorizer.DecisionNoOpinion,
+		},
+		{
+			name:         "impersonating system:masters",
+			cluster:      genericapirequest.Cluster{Name: team},
+			attr:         authorizer.AttributesRecord{Verb: "impersonate", Resource: "groups", Name: user.SystemPrivilegedGroup, ResourceRequest: true},
+			allowed:      []logicalcluster.Name{team},
+			wantDecision: authorizer.DecisionDeny,
+		},
+		{
+			name:         "other verbs are not granted in the parent",
+			cluster:      genericapirequest.Cluster{Name: team},
+			attr:         authorizer.AttributesRecord{Verb: "get", Resource: "configmaps", ResourceRequest: true},
+			allowed:      []logicalcluster.Name{org},
+			wantDecision: authorizer.DecisionNoOpinion,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authz := NewSubtreeImpersonationAuthorizer([]string{user.SystemPrivilegedGroup}, &clusterAuthorizer{allowed: tt.allowed, denied: tt.denied})
+
+			tt.attr.User = &user.DefaultInfo{Name: "org-admin"}
+			ctx := genericapirequest.WithCluster(context.Background(), tt.cluster)
+			dec, _, err := authz.Authorize(ctx, tt.attr)
+			require.NoError(t, err)
+			require.Equal(t, tt.wantDecision, dec)
+		})
+	}
+}
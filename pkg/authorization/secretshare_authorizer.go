@@ -0,0 +1,118 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package authorization
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kcp-dev/logicalcluster"
+
+	"k8s.io/apiserver/pkg/authorization/authorizer"
+	genericapirequest "k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/clusters"
+
+	apisv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apis/v1alpha1"
+	apisinformers "github.com/kcp-dev/kcp/pkg/client/informers/externalversions/apis/v1alpha1"
+)
+
+const bySharedSecretIndex = "secretshare-bySharedSecret"
+
+// NewSecretShareAuthorizer returns an authorizer that grants to get a Secret shared through a
+// SecretShare in its workspace to the users allowed by the delegate to get the content of the
+// APIExport of the SecretShare, i.e. the verb get on apiexports/content in the workspace of the
+// APIExport. Other requests, and those already decided by the delegate, are left to the delegate.
+func NewSecretShareAuthorizer(secretShareInformer apisinformers.SecretShareInformer, delegate authorizer.Authorizer) (authorizer.Authorizer, error) {
+	if err := secretShareInformer.Informer().AddIndexers(cache.Indexers{
+		bySharedSecretIndex: indexBySharedSecret,
+	}); err != nil {
+		return nil, fmt.Errorf("failed to add indexer for SecretShare: %w", err)
+	}
+
+	return &secretShareAuthorizer{
+		secretShareIndexer: secretShareInformer.Informer().GetIndexer(),
+		delegate:           delegate,
+	}, nil
+}
+
+type secretShareAuthorizer struct {
+	secretShareIndexer cache.Indexer
+	delegate           authorizer.Authorizer
+}
+
+func indexBySharedSecret(obj interface{}) ([]string, error) {
+	share, ok := obj.(*apisv1alpha1.SecretShare)
+	if !ok {
+		return []string{}, nil
+	}
+	return []string{sharedSecretKey(logicalcluster.From(share), share.Spec.SecretRef.Namespace, share.Spec.SecretRef.Name)}, nil
+}
+
+func sharedSecretKey(clusterName logicalcluster.Name, namespace, name string) string {
+	return namespace + "/" + clusters.ToClusterAwareKey(clusterName, name)
+}
+
+func (a *secretShareAuthorizer) Authorize(ctx context.Context, attr authorizer.Attributes) (authorized authorizer.Decision, reason string, err error) {
+	dec, reason, err := a.delegate.Authorize(ctx, attr)
+	if err != nil || dec != authorizer.DecisionNoOpinion {
+		return dec, reason, err
+	}
+
+	// only single secrets can be shared
+	if !attr.IsResourceRequest() || attr.GetVerb() != "get" || attr.GetAPIGroup() != "" || attr.GetResource() != "secrets" ||
+		attr.GetSubresource() != "" || attr.GetNamespace() == "" || attr.GetName() == "" {
+		return dec, reason, nil
+	}
+	cluster := genericapirequest.ClusterFrom(ctx)
+	if cluster == nil || cluster.Wildcard || cluster.Name.Empty() {
+		return dec, reason, nil
+	}
+	shares, err := a.secretShareIndexer.ByIndex(bySharedSecretIndex, sharedSecretKey(cluster.Name, attr.GetNamespace(), attr.GetName()))
+	if err != nil {
+		return authorizer.DecisionNoOpinion, reason, err
+	}
+	for _, obj := range shares {
+		share := obj.(*apisv1alpha1.SecretShare)
+		if share.Spec.Export.Workspace == nil {
+			continue
+		}
+		exportClusterName, ok := share.Spec.Export.Workspace.ClusterName(cluster.Name)
+		if !ok {
+			continue
+		}
+
+		contentAttr := authorizer.AttributesRecord{
+			User:            attr.GetUser(),
+			Verb:            "get",
+			APIGroup:        apisv1alpha1.SchemeGroupVersion.Group,
+			APIVersion:      apisv1alpha1.SchemeGroupVersion.Version,
+			Resource:        "apiexports",
+			Subresource:     "content",
+			Name:            share.Spec.Export.Workspace.ExportName,
+			ResourceRequest: true,
+		}
+		exportCtx := genericapirequest.WithCluster(ctx, genericapirequest.Cluster{Name: exportClusterName})
+		if exportDec, _, err := a.delegate.Authorize(exportCtx, contentAttr); err != nil {
+			return authorizer.DecisionNoOpinion, reason, err
+		} else if exportDec == authorizer.DecisionAllow {
+			return authorizer.DecisionAllow, fmt.Sprintf("secret shared with APIExport %s|%s by SecretShare %q", exportClusterName, contentAttr.Name, share.Name), nil
+		}
+	}
+
+	return dec, reason, nil
+}
@@ -0,0 +1,127 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package authorization
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kcp-dev/logicalcluster"
+	"github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apiserver/pkg/authorization/authorizer"
+	genericapirequest "k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/client-go/tools/cache"
+
+	apisv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apis/v1alpha1"
+)
+
+func TestSecretShareAuthorizer(t *testing.T) {
+	org := logicalcluster.New("root:org")
+	consumer := org.Join("consumer")
+	provider := org.Join("provider")
+
+	share := &apisv1alpha1.SecretShare{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "share",
+			ClusterName: consumer.String(),
+		},
+		Spec: apisv1alpha1.SecretShareSpec{
+			SecretRef: apisv1alpha1.SharedSecretReference{Namespace: "default", Name: "credentials"},
+			Export: apisv1alpha1.ExportReference{
+				Workspace: &apisv1alpha1.WorkspaceExportReference{WorkspaceName: "provider", ExportName: "widgets"},
+			},
+		},
+	}
+	getSecret := authorizer.AttributesRecord{Verb: "get", APIVersion: "v1", Resource: "secrets", Namespace: "default", Name: "credentials", ResourceRequest: true}
+
+	tests := []struct {
+		name         string
+		cluster      logicalcluster.Name
+		attr         authorizer.AttributesRecord
+		allowed      []logicalcluster.Name
+		denied       []logicalcluster.Name
+		wantDecision authorizer.Decision
+	}{
+		{
+			name:         "shared secret with access to the export",
+			cluster:      consumer,
+			attr:         getSecret,
+			allowed:      []logicalcluster.Name{provider},
+			wantDecision: authorizer.DecisionAllow,
+		},
+		{
+			name:         "shared secret without access to the export",
+			cluster:      consumer,
+			attr:         getSecret,
+			wantDecision: authorizer.DecisionNoOpinion,
+		},
+		{
+			name:         "denied by the delegate",
+			cluster:      consumer,
+			attr:         getSecret,
+			allowed:      []logicalcluster.Name{provider},
+			denied:       []logicalcluster.Name{consumer},
+			wantDecision: authorizer.DecisionDeny,
+		},
+		{
+			name:         "secret not shared",
+			cluster:      consumer,
+			attr:         authorizer.AttributesRecord{Verb: "get", APIVersion: "v1", Resource: "secrets", Namespace: "default", Name: "other", ResourceRequest: true},
+			allowed:      []logicalcluster.Name{provider},
+			wantDecision: authorizer.DecisionNoOpinion,
+		},
+		{
+			name:         "secret of the same name in another workspace",
+			cluster:      org.Join("other"),
+			attr:         getSecret,
+			allowed:      []logicalcluster.Name{provider},
+			wantDecision: authorizer.DecisionNoOpinion,
+		},
+		{
+			name:         "list is not shared",
+			cluster:      consumer,
+			attr:         authorizer.AttributesRecord{Verb: "list", APIVersion: "v1", Resource: "secrets", Namespace: "default", ResourceRequest: true},
+			allowed:      []logicalcluster.Name{provider},
+			wantDecision: authorizer.DecisionNoOpinion,
+		},
+		{
+			name:         "update is not shared",
+			cluster:      consumer,
+			attr:         authorizer.AttributesRecord{Verb: "update", APIVersion: "v1", Resource: "secrets", Namespace: "default", Name: "credentials", ResourceRequest: true},
+			allowed:      []logicalcluster.Name{provider},
+			wantDecision: authorizer.DecisionNoOpinion,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{bySharedSecretIndex: indexBySharedSecret})
+			require.NoError(t, indexer.Add(share))
+
+			a := &secretShareAuthorizer{
+				secretShareIndexer: indexer,
+				delegate:           &clusterAuthorizer{allowed: tt.allowed, denied: tt.denied},
+			}
+			ctx := genericapirequest.WithCluster(context.Background(), genericapirequest.Cluster{Name: tt.cluster})
+			dec, _, err := a.Authorize(ctx, tt.attr)
+			require.NoError(t, err)
+			require.Equal(t, tt.wantDecision, dec)
+		})
+	}
+}
@@ -33,7 +33,9 @@ import (
 	"k8s.io/client-go/tools/clusters"
 	"k8s.io/kubernetes/plugin/pkg/auth/authorizer/rbac"
 
+	tenancypath "github.com/kcp-dev/kcp/pkg/apis/tenancy/path"
 	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/authorization/bootstrap"
 	tenancyv1 "github.com/kcp-dev/kcp/pkg/client/listers/tenancy/v1alpha1"
 	rbacwrapper "github.com/kcp-dev/kcp/pkg/virtual/framework/wrappers/rbac"
 )
@@ -109,7 +111,7 @@ func (a *topLevelOrgAccessAuthorizer) Authorize(ctx context.Context, attr author
 			errList    []error
 			reasonList []string
 		)
-		for _, verb := range []string{"access", "member"} {
+		for _, verb := range []string{bootstrap.WorkspaceAccessVerb, bootstrap.WorkspaceMemberVerb} {
 			workspaceAttr := authorizer.AttributesRecord{
 				User:        attr.GetUser(),
 				Verb:        verb,
@@ -141,15 +143,10 @@ func (a *topLevelOrgAccessAuthorizer) Authorize(ctx context.Context, attr author
 }
 
 func topLevelOrg(clusterName logicalcluster.Name) (string, bool) {
-	for {
-		parent, hasParent := clusterName.Parent()
-		if !hasParent {
-			// apparently not under `root`
-			return "", false
-		}
-		if parent == tenancyv1alpha1.RootCluster {
-			return clusterName.Base(), true
-		}
-		clusterName = parent
+	org, ok := tenancypath.Organization(clusterName)
+	if !ok {
+		// apparently not under `root`
+		return "", false
 	}
+	return org.Base(), true
 }
@@ -41,6 +41,10 @@ import (
 	rbacwrapper "github.com/kcp-dev/kcp/pkg/virtual/framework/wrappers/rbac"
 )
 
+// NewWorkspaceContentAuthorizer returns an authorizer that checks for the admin, member and access verbs
+// in clusterworkspaces/content of the parent workspace for the requested workspace. Each admitted verb adds
+// the associated system:kcp:clusterworkspace groups to the user before the delegate authorizer is called.
+// If none is admitted, NoOpinion is returned.
 func NewWorkspaceContentAuthorizer(versionedInformers clientgoinformers.SharedInformerFactory, clusterWorkspaceLister tenancyv1.ClusterWorkspaceLister, delegate authorizer.Authorizer) authorizer.Authorizer {
 	return &workspaceContentAuthorizer{
 		versionedInformers: versionedInformers,
@@ -146,8 +150,9 @@ func (a *workspaceContentAuthorizer) Authorize(ctx context.Context, attr authori
 		}
 	} else {
 		verbToGroupMembership := map[string][]string{
-			"admin":  {bootstrap.SystemKcpClusterWorkspaceAccessGroup, bootstrap.SystemKcpClusterWorkspaceAdminGroup},
-			"access": {bootstrap.SystemKcpClusterWorkspaceAccessGroup},
+			bootstrap.WorkspaceAdminVerb:  {bootstrap.SystemKcpClusterWorkspaceAccessGroup, bootstrap.SystemKcpClusterWorkspaceAdminGroup},
+			bootstrap.WorkspaceMemberVerb: {bootstrap.SystemKcpClusterWorkspaceAccessGroup},
+			bootstrap.WorkspaceAccessVerb: {bootstrap.SystemKcpClusterWorkspaceAccessGroup},
 		}
 
 		var (
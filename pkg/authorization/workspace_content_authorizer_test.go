@@ -0,0 +1,208 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package authorization
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kcp-dev/logicalcluster"
+	"github.com/stretchr/testify/require"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	authserviceaccount "k8s.io/apiserver/pkg/authentication/serviceaccount"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/apiserver/pkg/authorization/authorizer"
+	genericapirequest "k8s.io/apiserver/pkg/endpoints/request"
+	clientgoinformers "k8s.io/client-go/informers"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+
+	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/authorization/bootstrap"
+	kcpfake "github.com/kcp-dev/kcp/pkg/client/clientset/versioned/fake"
+	kcpinformers "github.com/kcp-dev/kcp/pkg/client/informers/externalversions"
+)
+
+func newWorkspaceContentRole(clusterName logicalcluster.Name, workspace string, verbs ...string) *rbacv1.ClusterRole {
+	return &rbacv1.ClusterRole{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        workspace + "-" + verbs[0],
+			ClusterName: clusterName.String(),
+		},
+		Rules: []rbacv1.PolicyRule{
+			{
+				APIGroups:     []string{tenancyv1alpha1.SchemeGroupVersion.Group},
+				Resources:     []string{"clusterworkspaces/content"},
+				ResourceNames: []string{workspace},
+				Verbs:         verbs,
+			},
+		},
+	}
+}
+
+func newUserBinding(clusterName logicalcluster.Name, userName, roleName string) *rbacv1.ClusterRoleBinding {
+	return &rbacv1.ClusterRoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        userName + "-" + roleName,
+			ClusterName: clusterName.String(),
+		},
+		Subjects: []rbacv1.Subject{
+			{Kind: rbacv1.UserKind, APIGroup: rbacv1.GroupName, Name: userName},
+		},
+		RoleRef: rbacv1.RoleRef{APIGroup: rbacv1.GroupName, Kind: "ClusterRole", Name: roleName},
+	}
+}
+
+// recordingAuthorizer allows every request and records the groups of the last user.
+type recordingAuthorizer struct {
+	groups []string
+}
+
+func (a *recordingAuthorizer) Authorize(ctx context.Context, attr authorizer.Attributes) (authorizer.Decision, string, error) {
+	a.groups = attr.GetUser().GetGroups()
+	return authorizer.DecisionAllow, "", nil
+}
+
+func TestWorkspaceContentAuthorizer(t *testing.T) {
+	org := logicalcluster.New("root:org")
+
+	tests := []struct {
+		name          string
+		requestedWs   logicalcluster.Name
+		user          user.Info
+		wantDecision  authorizer.Decision
+		wantAddGroups []string
+	}{
+		{
+			name:          "authenticated user in root gets access",
+			requestedWs:   tenancyv1alpha1.RootCluster,
+			user:          &user.DefaultInfo{Name: "anna", Groups: []string{"system:authenticated"}},
+			wantDecision:  authorizer.DecisionAllow,
+			wantAddGroups: []string{bootstrap.SystemKcpClusterWorkspaceAccessGroup},
+		},
+		{
+			name:         "non-existing workspace is denied",
+			requestedWs:  org.Join("unknown"),
+			user:         &user.DefaultInfo{Name: "admin-user", Groups: []string{"system:authenticated"}},
+			wantDecision: authorizer.DecisionDeny,
+		},
+		{
+			name:         "user without verbs gets no opinion",
+			requestedWs:  org.Join("ws"),
+			user:         &user.DefaultInfo{Name: "nobody", Groups: []string{"system:authenticated"}},
+			wantDecision: authorizer.DecisionNoOpinion,
+		},
+		{
+			name:          "access verb adds access group",
+			requestedWs:   org.Join("ws"),
+			user:          &user.DefaultInfo{Name: "access-user", Groups: []string{"system:authenticated"}},
+			wantDecision:  authorizer.DecisionAllow,
+			wantAddGroups: []string{bootstrap.SystemKcpClusterWorkspaceAccessGroup},
+		},
+		{
+			name:          "member verb adds access group",
+			requestedWs:   org.Join("ws"),
+			user:          &user.DefaultInfo{Name: "member-user", Groups: []string{"system:authenticated"}},
+			wantDecision:  authorizer.DecisionAllow,
+			wantAddGroups: []string{bootstrap.SystemKcpClusterWorkspaceAccessGroup},
+		},
+		{
+			name:          "admin verb adds access and admin groups",
+			requestedWs:   org.Join("ws"),
+			user:          &user.DefaultInfo{Name: "admin-user", Groups: []string{"system:authenticated"}},
+			wantDecision:  authorizer.DecisionAllow,
+			wantAddGroups: []string{bootstrap.SystemKcpClusterWorkspaceAccessGroup, bootstrap.SystemKcpClusterWorkspaceAdminGroup},
+		},
+		{
+			name:         "verbs on another workspace do not apply",
+			requestedWs:  org.Join("other"),
+			user:         &user.DefaultInfo{Name: "admin-user", Groups: []string{"system:authenticated"}},
+			wantDecision: authorizer.DecisionNoOpinion,
+		},
+		{
+			name:        "service account of the workspace gets access",
+			requestedWs: org.Join("ws"),
+			user: &user.DefaultInfo{
+				Name:   "system:serviceaccount:default:default",
+				Groups: []string{"system:authenticated"},
+				Extra:  map[string][]string{authserviceaccount.ClusterNameKey: {org.Join("ws").String()}},
+			},
+			wantDecision:  authorizer.DecisionAllow,
+			wantAddGroups: []string{bootstrap.SystemKcpClusterWorkspaceAccessGroup},
+		},
+		{
+			name:        "service account of another workspace gets no opinion",
+			requestedWs: org.Join("ws"),
+			user: &user.DefaultInfo{
+				Name:   "system:serviceaccount:default:default",
+				Groups: []string{"system:authenticated"},
+				Extra:  map[string][]string{authserviceaccount.ClusterNameKey: {org.Join("other").String()}},
+			},
+			wantDecision: authorizer.DecisionNoOpinion,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kubeInformers := clientgoinformers.NewSharedInformerFactory(kubefake.NewSimpleClientset(), 0)
+			kcpInformers := kcpinformers.NewSharedInformerFactory(kcpfake.NewSimpleClientset(), 0)
+
+			for _, ws := range []string{"ws", "other"} {
+				require.NoError(t, kcpInformers.Tenancy().V1alpha1().ClusterWorkspaces().Informer().GetIndexer().Add(&tenancyv1alpha1.ClusterWorkspace{
+					ObjectMeta: metav1.ObjectMeta{Name: ws, ClusterName: org.String()},
+				}))
+			}
+			for _, obj := range []interface{}{
+				newWorkspaceContentRole(org, "ws", bootstrap.WorkspaceAccessVerb),
+				newWorkspaceContentRole(org, "ws", bootstrap.WorkspaceMemberVerb),
+				newWorkspaceContentRole(org, "ws", bootstrap.WorkspaceAdminVerb),
+			} {
+				require.NoError(t, kubeInformers.Rbac().V1().ClusterRoles().Informer().GetIndexer().Add(obj))
+			}
+			for _, obj := range []interface{}{
+				newUserBinding(org, "access-user", "ws-"+bootstrap.WorkspaceAccessVerb),
+				newUserBinding(org, "member-user", "ws-"+bootstrap.WorkspaceMemberVerb),
+				newUserBinding(org, "admin-user", "ws-"+bootstrap.WorkspaceAdminVerb),
+			} {
+				require.NoError(t, kubeInformers.Rbac().V1().ClusterRoleBindings().Informer().GetIndexer().Add(obj))
+			}
+
+			delegate := &recordingAuthorizer{}
+			authz := NewWorkspaceContentAuthorizer(kubeInformers, kcpInformers.Tenancy().V1alpha1().ClusterWorkspaces().Lister(), delegate)
+
+			ctx := genericapirequest.WithCluster(context.Background(), genericapirequest.Cluster{Name: tt.requestedWs})
+			attr := authorizer.AttributesRecord{
+				User:            tt.user,
+				Verb:            "get",
+				Resource:        "configmaps",
+				Namespace:       "default",
+				ResourceRequest: true,
+			}
+
+			dec, _, err := authz.Authorize(ctx, attr)
+			require.NoError(t, err)
+			require.Equal(t, tt.wantDecision, dec)
+			if tt.wantDecision != authorizer.DecisionAllow {
+				return
+			}
+
+			added := sets.NewString(delegate.groups...).Difference(sets.NewString(tt.user.GetGroups()...))
+			require.Equal(t, sets.NewString(tt.wantAddGroups...), added)
+		})
+	}
+}
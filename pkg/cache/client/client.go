@@ -0,0 +1,108 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/kcp-dev/logicalcluster"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/klog/v2"
+
+	apisv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apis/v1alpha1"
+	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/cache/server"
+	kcpclient "github.com/kcp-dev/kcp/pkg/client/clientset/versioned"
+)
+
+// Client gets replicated objects from the cache server, and falls back to a live request
+// through the given kcp client when the cache server fails or does not know the object,
+// e.g. because the object has just been created and is not replicated yet.
+//
+// The live client only reaches the local shard. Hence, on a cache miss, objects of other
+// shards are reported as NotFound until the cache server has replicated them.
+type Client struct {
+	cache rest.Interface
+	live  kcpclient.ClusterInterface
+}
+
+// New returns a client for the cache server at the given config, falling back to the given live client.
+// Without cache server config all requests are live.
+func New(cacheConfig *rest.Config, live kcpclient.ClusterInterface) (*Client, error) {
+	if cacheConfig == nil {
+		return &Client{live: live}, nil
+	}
+
+	config := rest.CopyConfig(cacheConfig)
+	config.NegotiatedSerializer = scheme.Codecs.WithoutConversion()
+	cache, err := rest.UnversionedRESTClientFor(config)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{cache: cache, live: live}, nil
+}
+
+// GetAPIExport returns the APIExport with the given name in the given workspace.
+func (c *Client) GetAPIExport(ctx context.Context, clusterName logicalcluster.Name, name string) (*apisv1alpha1.APIExport, error) {
+	export := &apisv1alpha1.APIExport{}
+	if c.getCached(ctx, clusterName, apisv1alpha1.SchemeGroupVersion.WithResource("apiexports"), name, export) {
+		return export, nil
+	}
+	return c.live.Cluster(clusterName).ApisV1alpha1().APIExports().Get(ctx, name, metav1.GetOptions{})
+}
+
+// GetClusterWorkspaceType returns the ClusterWorkspaceType with the given name in the given workspace.
+func (c *Client) GetClusterWorkspaceType(ctx context.Context, clusterName logicalcluster.Name, name string) (*tenancyv1alpha1.ClusterWorkspaceType, error) {
+	cwt := &tenancyv1alpha1.ClusterWorkspaceType{}
+	if c.getCached(ctx, clusterName, tenancyv1alpha1.SchemeGroupVersion.WithResource("clusterworkspacetypes"), name, cwt) {
+		return cwt, nil
+	}
+	return c.live.Cluster(clusterName).TenancyV1alpha1().ClusterWorkspaceTypes().Get(ctx, name, metav1.GetOptions{})
+}
+
+// GetClusterWorkspaceShard returns the ClusterWorkspaceShard with the given name in the root workspace.
+func (c *Client) GetClusterWorkspaceShard(ctx context.Context, name string) (*tenancyv1alpha1.ClusterWorkspaceShard, error) {
+	shard := &tenancyv1alpha1.ClusterWorkspaceShard{}
+	if c.getCached(ctx, tenancyv1alpha1.RootCluster, tenancyv1alpha1.SchemeGroupVersion.WithResource("clusterworkspaceshards"), name, shard) {
+		return shard, nil
+	}
+	return c.live.Cluster(tenancyv1alpha1.RootCluster).TenancyV1alpha1().ClusterWorkspaceShards().Get(ctx, name, metav1.GetOptions{})
+}
+
+// getCached gets the named object from the cache server into the given object, and returns
+// whether it succeeded.
+func (c *Client) getCached(ctx context.Context, clusterName logicalcluster.Name, gvr schema.GroupVersionResource, name string, into interface{}) bool {
+	if c.cache == nil {
+		return false
+	}
+
+	data, err := c.cache.Get().AbsPath(server.ResourcePath(server.AnyShard, clusterName, gvr, name)).Do(ctx).Raw()
+	if err != nil {
+		klog.V(4).Infof("Failed to get %s %s|%s from the cache server, falling back to a live request: %v", gvr.Resource, clusterName, name, err)
+		return false
+	}
+	if err := json.Unmarshal(data, into); err != nil {
+		klog.V(4).Infof("Failed to decode %s %s|%s from the cache server, falling back to a live request: %v", gvr.Resource, clusterName, name, err)
+		return false
+	}
+	return true
+}
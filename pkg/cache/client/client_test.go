@@ -0,0 +1,120 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kcp-dev/logicalcluster"
+	"github.com/stretchr/testify/require"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+
+	apisv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apis/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/cache/server"
+	kcpclient "github.com/kcp-dev/kcp/pkg/client/clientset/versioned"
+	kcpfakeclient "github.com/kcp-dev/kcp/pkg/client/clientset/versioned/fake"
+)
+
+type fakeClusterClient struct {
+	*kcpfakeclient.Clientset
+}
+
+func (c fakeClusterClient) Cluster(logicalcluster.Name) kcpclient.Interface {
+	return c.Clientset
+}
+
+func TestGetAPIExport(t *testing.T) {
+	clusterName := logicalcluster.New("root:org")
+	apiExports := apisv1alpha1.SchemeGroupVersion.WithResource("apiexports")
+
+	var cacheRequests []string
+	cacheServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		cacheRequests = append(cacheRequests, req.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		switch req.URL.Path {
+		case server.ResourcePath(server.AnyShard, clusterName, apiExports, "cached"):
+			require.NoError(t, json.NewEncoder(w).Encode(&apisv1alpha1.APIExport{
+				TypeMeta:   metav1.TypeMeta{APIVersion: apisv1alpha1.SchemeGroupVersion.String(), Kind: "APIExport"},
+				ObjectMeta: metav1.ObjectMeta{Name: "cached", ClusterName: clusterName.String()},
+			}))
+		case server.ResourcePath(server.AnyShard, clusterName, apiExports, "unavailable"):
+			status := apierrors.NewServiceUnavailable("not replicated yet").Status()
+			status.Kind, status.APIVersion = "Status", "v1"
+			w.WriteHeader(http.StatusServiceUnavailable)
+			require.NoError(t, json.NewEncoder(w).Encode(status))
+		default:
+			status := apierrors.NewNotFound(apiExports.GroupResource(), "").Status()
+			status.Kind, status.APIVersion = "Status", "v1"
+			w.WriteHeader(http.StatusNotFound)
+			require.NoError(t, json.NewEncoder(w).Encode(status))
+		}
+	}))
+	t.Cleanup(cacheServer.Close)
+
+	live := fakeClusterClient{kcpfakeclient.NewSimpleClientset(
+		&apisv1alpha1.APIExport{ObjectMeta: metav1.ObjectMeta{Name: "unavailable"}},
+		&apisv1alpha1.APIExport{ObjectMeta: metav1.ObjectMeta{Name: "new"}},
+	)}
+
+	c, err := New(&rest.Config{Host: cacheServer.URL}, live)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+
+	t.Log("An object in the cache is not requested live")
+	export, err := c.GetAPIExport(ctx, clusterName, "cached")
+	require.NoError(t, err)
+	require.Equal(t, "cached", export.Name)
+	require.Empty(t, live.Actions())
+
+	t.Log("An object the cache server cannot serve is requested live")
+	export, err = c.GetAPIExport(ctx, clusterName, "unavailable")
+	require.NoError(t, err)
+	require.Equal(t, "unavailable", export.Name)
+
+	t.Log("An object not replicated yet is requested live")
+	export, err = c.GetAPIExport(ctx, clusterName, "new")
+	require.NoError(t, err)
+	require.Equal(t, "new", export.Name)
+
+	t.Log("An object existing nowhere is not found")
+	_, err = c.GetAPIExport(ctx, clusterName, "missing")
+	require.True(t, apierrors.IsNotFound(err), "expected not found, got: %v", err)
+
+	require.Len(t, live.Actions(), 3)
+	require.Equal(t, []string{
+		server.ResourcePath(server.AnyShard, clusterName, apiExports, "cached"),
+		server.ResourcePath(server.AnyShard, clusterName, apiExports, "unavailable"),
+		server.ResourcePath(server.AnyShard, clusterName, apiExports, "new"),
+		server.ResourcePath(server.AnyShard, clusterName, apiExports, "missing"),
+	}, cacheRequests)
+
+	t.Log("Without cache server all requests are live")
+	c, err = New(nil, live)
+	require.NoError(t, err)
+	export, err = c.GetAPIExport(ctx, clusterName, "new")
+	require.NoError(t, err)
+	require.Equal(t, "new", export.Name)
+	require.Len(t, cacheRequests, 4)
+}
@@ -0,0 +1,27 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package server implements the cache server. It replicates the objects of all
+// shards that controllers need to resolve across shards, i.e. APIExports,
+// ClusterWorkspaceTypes and ClusterWorkspaceShards, and serves them read-only.
+//
+// The shards are discovered through the ClusterWorkspaceShards in the root
+// workspace of the root shard. Replicated objects are served at
+//
+//	/shards/<shard>/clusters/<cluster>/apis/<group>/<version>/<resource>[/<name>]
+//
+// where both shard and cluster can be "*" to list across shards and workspaces.
+package server
@@ -0,0 +1,367 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kcp-dev/logicalcluster"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apiserver/pkg/endpoints/handlers/responsewriters"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/clusters"
+	"k8s.io/klog/v2"
+
+	apisv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apis/v1alpha1"
+	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+	kcpclient "github.com/kcp-dev/kcp/pkg/client/clientset/versioned"
+	kcpinformer "github.com/kcp-dev/kcp/pkg/client/informers/externalversions"
+)
+
+// AnyShard selects the objects of all shards in a path.
+const AnyShard = "*"
+
+// replicatedResource describes a resource replicated from the shards.
+type replicatedResource struct {
+	kind     string
+	informer func(kcpinformer.SharedInformerFactory) cache.SharedIndexInformer
+}
+
+var replicatedResources = map[schema.GroupVersionResource]replicatedResource{
+	apisv1alpha1.SchemeGroupVersion.WithResource("apiexports"): {
+		kind: "APIExport",
+		informer: func(f kcpinformer.SharedInformerFactory) cache.SharedIndexInformer {
+			return f.Apis().V1alpha1().APIExports().Informer()
+		},
+	},
+	tenancyv1alpha1.SchemeGroupVersion.WithResource("clusterworkspacetypes"): {
+		kind: "ClusterWorkspaceType",
+		informer: func(f kcpinformer.SharedInformerFactory) cache.SharedIndexInformer {
+			return f.Tenancy().V1alpha1().ClusterWorkspaceTypes().Informer()
+		},
+	},
+	tenancyv1alpha1.SchemeGroupVersion.WithResource("clusterworkspaceshards"): {
+		kind: "ClusterWorkspaceShard",
+		informer: func(f kcpinformer.SharedInformerFactory) cache.SharedIndexInformer {
+			return f.Tenancy().V1alpha1().ClusterWorkspaceShards().Informer()
+		},
+	},
+}
+
+// ReplicatedResources returns the resources replicated by the cache server.
+func ReplicatedResources() []schema.GroupVersionResource {
+	gvrs := make([]schema.GroupVersionResource, 0, len(replicatedResources))
+	for gvr := range replicatedResources {
+		gvrs = append(gvrs, gvr)
+	}
+	sort.Slice(gvrs, func(i, j int) bool {
+		return gvrs[i].String() < gvrs[j].String()
+	})
+	return gvrs
+}
+
+// ResourcePath returns the path of a replicated resource in the given shard and workspace, or the
+// path of the named object if name is not empty. Both shard and workspace can be wildcards.
+func ResourcePath(shard string, clusterName logicalcluster.Name, gvr schema.GroupVersionResource, name string) string {
+	path := fmt.Sprintf("/shards/%s/clusters/%s/apis/%s/%s/%s", shard, clusterName, gvr.Group, gvr.Version, gvr.Resource)
+	if name != "" {
+		path += "/" + name
+	}
+	return path
+}
+
+// Server replicates the objects of the replicated resources from all shards, and serves them.
+type Server struct {
+	rootShardConfig *rest.Config
+	resyncPeriod    time.Duration
+
+	newShardInformers func(shard *tenancyv1alpha1.ClusterWorkspaceShard) (kcpinformer.SharedInformerFactory, error)
+
+	lock   sync.RWMutex
+	shards map[string]*shardReplica
+}
+
+// shardReplica holds the replicated objects of a shard.
+type shardReplica struct {
+	baseURL   string
+	informers map[schema.GroupVersionResource]cache.SharedIndexInformer
+	hasSynced func() bool
+	stop      func()
+}
+
+// NewServer returns a cache server discovering the shards through the given root shard config.
+// The credentials of the config are used to talk to all shards.
+func NewServer(rootShardConfig *rest.Config, resyncPeriod time.Duration) *Server {
+	s := &Server{
+		rootShardConfig: rootShardConfig,
+		resyncPeriod:    resyncPeriod,
+		shards:          map[string]*shardReplica{},
+	}
+	s.newShardInformers = func(shard *tenancyv1alpha1.ClusterWorkspaceShard) (kcpinformer.SharedInformerFactory, error) {
+		config := rest.CopyConfig(s.rootShardConfig)
+		config.Host = shard.Spec.BaseURL
+		kcpClusterClient, err := kcpclient.NewClusterForConfig(config)
+		if err != nil {
+			return nil, err
+		}
+		return kcpinformer.NewSharedInformerFactoryWithOptions(kcpClusterClient.Cluster(logicalcluster.Wildcard), s.resyncPeriod), nil
+	}
+	return s
+}
+
+// Run watches the ClusterWorkspaceShards of the root shard and replicates the shards until the context is done.
+func (s *Server) Run(ctx context.Context) error {
+	kcpClusterClient, err := kcpclient.NewClusterForConfig(s.rootShardConfig)
+	if err != nil {
+		return err
+	}
+	rootInformers := kcpinformer.NewSharedInformerFactoryWithOptions(kcpClusterClient.Cluster(tenancyv1alpha1.RootCluster), s.resyncPeriod)
+	rootInformers.Tenancy().V1alpha1().ClusterWorkspaceShards().Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			s.upsertShard(ctx, obj.(*tenancyv1alpha1.ClusterWorkspaceShard))
+		},
+		UpdateFunc: func(_, obj interface{}) {
+			s.upsertShard(ctx, obj.(*tenancyv1alpha1.ClusterWorkspaceShard))
+		},
+		DeleteFunc: func(obj interface{}) {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			shard, ok := obj.(*tenancyv1alpha1.ClusterWorkspaceShard)
+			if !ok {
+				utilruntime.HandleError(fmt.Errorf("obj is supposed to be a ClusterWorkspaceShard, but is %T", obj))
+				return
+			}
+			s.removeShard(shard.Name)
+		},
+	})
+	rootInformers.Start(ctx.Done())
+
+	klog.Info("Starting cache server replication")
+	defer klog.Info("Shutting down cache server replication")
+
+	<-ctx.Done()
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for name, replica := range s.shards {
+		replica.stop()
+		delete(s.shards, name)
+	}
+	return nil
+}
+
+func (s *Server) upsertShard(ctx context.Context, shard *tenancyv1alpha1.ClusterWorkspaceShard) {
+	if shard.Spec.BaseURL == "" {
+		klog.V(2).Infof("Not replicating shard %s without base URL", shard.Name)
+		s.removeShard(shard.Name)
+		return
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if existing, found := s.shards[shard.Name]; found {
+		if existing.baseURL == shard.Spec.BaseURL {
+			return
+		}
+		klog.V(2).Infof("Base URL of shard %s changed from %s to %s", shard.Name, existing.baseURL, shard.Spec.BaseURL)
+		existing.stop()
+		delete(s.shards, shard.Name)
+	}
+
+	factory, err := s.newShardInformers(shard)
+	if err != nil {
+		utilruntime.HandleError(fmt.Errorf("failed to replicate shard %s: %w", shard.Name, err))
+		return
+	}
+	replica := &shardReplica{
+		baseURL:   shard.Spec.BaseURL,
+		informers: make(map[schema.GroupVersionResource]cache.SharedIndexInformer, len(replicatedResources)),
+	}
+	for gvr, resource := range replicatedResources {
+		replica.informers[gvr] = resource.informer(factory)
+	}
+	replica.hasSynced = func() bool {
+		for _, informer := range replica.informers {
+			if !informer.HasSynced() {
+				return false
+			}
+		}
+		return true
+	}
+	shardCtx, cancel := context.WithCancel(ctx)
+	replica.stop = cancel
+	factory.Start(shardCtx.Done())
+
+	klog.V(2).Infof("Replicating shard %s at %s", shard.Name, shard.Spec.BaseURL)
+	s.shards[shard.Name] = replica
+}
+
+func (s *Server) removeShard(name string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if replica, found := s.shards[name]; found {
+		klog.V(2).Infof("Stopping replication of shard %s", name)
+		replica.stop()
+		delete(s.shards, name)
+	}
+}
+
+// ServeHTTP serves the replicated objects read-only. Requests fail with 503 while one of
+// the selected shards is not replicated yet, so that clients can fall back to the shards.
+func (s *Server) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet {
+		writeError(w, apierrors.NewMethodNotSupported(schema.GroupResource{}, strings.ToLower(req.Method)))
+		return
+	}
+
+	shard, clusterName, gvr, name, err := parsePath(req.URL.Path)
+	if err != nil {
+		writeError(w, apierrors.NewBadRequest(err.Error()))
+		return
+	}
+	resource, found := replicatedResources[gvr]
+	if !found {
+		writeError(w, apierrors.NewBadRequest(fmt.Sprintf("%s is not replicated", gvr.GroupResource())))
+		return
+	}
+	if name != "" && clusterName == logicalcluster.Wildcard {
+		writeError(w, apierrors.NewBadRequest("a workspace is required to get an object"))
+		return
+	}
+
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	var shardNames []string
+	if shard == AnyShard {
+		for name := range s.shards {
+			shardNames = append(shardNames, name)
+		}
+		sort.Strings(shardNames)
+	} else if _, found := s.shards[shard]; found {
+		shardNames = []string{shard}
+	} else {
+		writeError(w, apierrors.NewNotFound(tenancyv1alpha1.SchemeGroupVersion.WithResource("clusterworkspaceshards").GroupResource(), shard))
+		return
+	}
+	for _, shardName := range shardNames {
+		if !s.shards[shardName].hasSynced() {
+			writeError(w, apierrors.NewServiceUnavailable(fmt.Sprintf("shard %s is not replicated yet", shardName)))
+			return
+		}
+	}
+
+	gvk := gvr.GroupVersion().WithKind(resource.kind)
+	if name != "" {
+		key := clusters.ToClusterAwareKey(clusterName, name)
+		for _, shardName := range shardNames {
+			obj, exists, err := s.shards[shardName].informers[gvr].GetIndexer().GetByKey(key)
+			if err != nil {
+				writeError(w, err)
+				return
+			}
+			if exists {
+				writeJSON(w, http.StatusOK, withKind(obj.(runtime.Object), gvk))
+				return
+			}
+		}
+		writeError(w, apierrors.NewNotFound(gvr.GroupResource(), name))
+		return
+	}
+
+	list := &objectList{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: gvk.GroupVersion().String(),
+			Kind:       resource.kind + "List",
+		},
+		Items: []runtime.Object{},
+	}
+	for _, shardName := range shardNames {
+		objs := s.shards[shardName].informers[gvr].GetIndexer().List()
+		sort.Slice(objs, func(i, j int) bool {
+			return clusterAwareKey(objs[i]) < clusterAwareKey(objs[j])
+		})
+		for _, obj := range objs {
+			if clusterName != logicalcluster.Wildcard && logicalcluster.From(obj.(metav1.Object)) != clusterName {
+				continue
+			}
+			list.Items = append(list.Items, withKind(obj.(runtime.Object), gvk))
+		}
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+// objectList is the list of replicated objects of a resource, decodable into the typed list.
+type objectList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+
+	Items []runtime.Object `json:"items"`
+}
+
+// parsePath splits paths like /shards/<shard>/clusters/<cluster>/apis/<group>/<version>/<resource>[/<name>].
+func parsePath(path string) (shard string, clusterName logicalcluster.Name, gvr schema.GroupVersionResource, name string, err error) {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	if (len(segments) != 8 && len(segments) != 9) || segments[0] != "shards" || segments[2] != "clusters" || segments[4] != "apis" {
+		return "", logicalcluster.Name{}, schema.GroupVersionResource{}, "", fmt.Errorf("invalid path %q, expected /shards/<shard>/clusters/<cluster>/apis/<group>/<version>/<resource>[/<name>]", path)
+	}
+	if len(segments) == 9 {
+		name = segments[8]
+	}
+	return segments[1], logicalcluster.New(segments[3]), schema.GroupVersionResource{Group: segments[5], Version: segments[6], Resource: segments[7]}, name, nil
+}
+
+func clusterAwareKey(obj interface{}) string {
+	key, _ := cache.MetaNamespaceKeyFunc(obj)
+	return key
+}
+
+// withKind returns a copy of the object with its kind set, as objects in informers have no kind.
+func withKind(obj runtime.Object, gvk schema.GroupVersionKind) runtime.Object {
+	obj = obj.DeepCopyObject()
+	obj.GetObjectKind().SetGroupVersionKind(gvk)
+	return obj
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	status := responsewriters.ErrorToAPIStatus(err)
+	writeJSON(w, int(status.Code), status)
+}
+
+func writeJSON(w http.ResponseWriter, code int, obj interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(obj); err != nil {
+		klog.Errorf("Failed to write response: %v", err)
+	}
+}
@@ -0,0 +1,192 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kcp-dev/logicalcluster"
+	"github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/cache"
+
+	apisv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apis/v1alpha1"
+	kcpfakeclient "github.com/kcp-dev/kcp/pkg/client/clientset/versioned/fake"
+	kcpinformer "github.com/kcp-dev/kcp/pkg/client/informers/externalversions"
+)
+
+func newReplica(t *testing.T, synced bool, exports ...*apisv1alpha1.APIExport) *shardReplica {
+	factory := kcpinformer.NewSharedInformerFactory(kcpfakeclient.NewSimpleClientset(), 0)
+	replica := &shardReplica{
+		informers: map[schema.GroupVersionResource]cache.SharedIndexInformer{},
+		hasSynced: func() bool { return synced },
+		stop:      func() {},
+	}
+	for gvr, resource := range replicatedResources {
+		replica.informers[gvr] = resource.informer(factory)
+	}
+	for _, export := range exports {
+		require.NoError(t, replica.informers[apisv1alpha1.SchemeGroupVersion.WithResource("apiexports")].GetIndexer().Add(export))
+	}
+	return replica
+}
+
+func export(clusterName, name string) *apisv1alpha1.APIExport {
+	return &apisv1alpha1.APIExport{
+		ObjectMeta: metav1.ObjectMeta{Name: name, ClusterName: clusterName},
+	}
+}
+
+func TestServeHTTP(t *testing.T) {
+	apiExports := apisv1alpha1.SchemeGroupVersion.WithResource("apiexports")
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		unsynced   bool
+		wantCode   int
+		wantKind   string
+		wantNames  []string
+		wantReason metav1.StatusReason
+	}{
+		{
+			name:      "list across shards and workspaces",
+			path:      ResourcePath(AnyShard, logicalcluster.Wildcard, apiExports, ""),
+			wantCode:  http.StatusOK,
+			wantKind:  "APIExportList",
+			wantNames: []string{"root:org|a", "root:org:ws|b", "root:org|c"},
+		},
+		{
+			name:      "list in a workspace across shards",
+			path:      ResourcePath(AnyShard, logicalcluster.New("root:org"), apiExports, ""),
+			wantCode:  http.StatusOK,
+			wantKind:  "APIExportList",
+			wantNames: []string{"root:org|a", "root:org|c"},
+		},
+		{
+			name:      "list in a shard",
+			path:      ResourcePath("shard-1", logicalcluster.Wildcard, apiExports, ""),
+			wantCode:  http.StatusOK,
+			wantKind:  "APIExportList",
+			wantNames: []string{"root:org|c"},
+		},
+		{
+			name:      "get from another shard",
+			path:      ResourcePath(AnyShard, logicalcluster.New("root:org"), apiExports, "c"),
+			wantCode:  http.StatusOK,
+			wantKind:  "APIExport",
+			wantNames: []string{"root:org|c"},
+		},
+		{
+			name:       "get unknown object",
+			path:       ResourcePath(AnyShard, logicalcluster.New("root:org"), apiExports, "b"),
+			wantCode:   http.StatusNotFound,
+			wantReason: metav1.StatusReasonNotFound,
+		},
+		{
+			name:       "get without workspace",
+			path:       ResourcePath(AnyShard, logicalcluster.Wildcard, apiExports, "a"),
+			wantCode:   http.StatusBadRequest,
+			wantReason: metav1.StatusReasonBadRequest,
+		},
+		{
+			name:       "unknown shard",
+			path:       ResourcePath("shard-2", logicalcluster.Wildcard, apiExports, ""),
+			wantCode:   http.StatusNotFound,
+			wantReason: metav1.StatusReasonNotFound,
+		},
+		{
+			name:       "not replicated resource",
+			path:       ResourcePath(AnyShard, logicalcluster.Wildcard, apisv1alpha1.SchemeGroupVersion.WithResource("apibindings"), ""),
+			wantCode:   http.StatusBadRequest,
+			wantReason: metav1.StatusReasonBadRequest,
+		},
+		{
+			name:       "invalid path",
+			path:       "/apis/apis.kcp.dev/v1alpha1/apiexports",
+			wantCode:   http.StatusBadRequest,
+			wantReason: metav1.StatusReasonBadRequest,
+		},
+		{
+			name:       "shard not replicated yet",
+			path:       ResourcePath(AnyShard, logicalcluster.New("root:org"), apiExports, "a"),
+			unsynced:   true,
+			wantCode:   http.StatusServiceUnavailable,
+			wantReason: metav1.StatusReasonServiceUnavailable,
+		},
+		{
+			name:       "writes are not supported",
+			method:     http.MethodPost,
+			path:       ResourcePath(AnyShard, logicalcluster.New("root:org"), apiExports, ""),
+			wantCode:   http.StatusMethodNotAllowed,
+			wantReason: metav1.StatusReasonMethodNotAllowed,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Server{
+				shards: map[string]*shardReplica{
+					"root":    newReplica(t, true, export("root:org", "a"), export("root:org:ws", "b")),
+					"shard-1": newReplica(t, !tt.unsynced, export("root:org", "c")),
+				},
+			}
+
+			method := tt.method
+			if method == "" {
+				method = http.MethodGet
+			}
+			rec := httptest.NewRecorder()
+			s.ServeHTTP(rec, httptest.NewRequest(method, tt.path, nil))
+			body, err := ioutil.ReadAll(rec.Body)
+			require.NoError(t, err)
+			require.Equal(t, tt.wantCode, rec.Code, string(body))
+
+			if tt.wantReason != "" {
+				status := &metav1.Status{}
+				require.NoError(t, json.Unmarshal(body, status))
+				require.Equal(t, "Status", status.Kind)
+				require.Equal(t, tt.wantReason, status.Reason)
+				return
+			}
+
+			var names []string
+			switch tt.wantKind {
+			case "APIExportList":
+				list := &apisv1alpha1.APIExportList{}
+				require.NoError(t, json.Unmarshal(body, list))
+				require.Equal(t, tt.wantKind, list.Kind)
+				for _, export := range list.Items {
+					require.Equal(t, "APIExport", export.Kind)
+					names = append(names, export.ClusterName+"|"+export.Name)
+				}
+			case "APIExport":
+				export := &apisv1alpha1.APIExport{}
+				require.NoError(t, json.Unmarshal(body, export))
+				require.Equal(t, tt.wantKind, export.Kind)
+				names = append(names, export.ClusterName+"|"+export.Name)
+			}
+			require.Equal(t, tt.wantNames, names)
+		})
+	}
+}
@@ -41,6 +41,14 @@ func (c *FakeTenancyV1alpha1) ClusterWorkspaceTypes() v1alpha1.ClusterWorkspaceT
 	return &FakeClusterWorkspaceTypes{c}
 }
 
+func (c *FakeTenancyV1alpha1) WorkspaceSnapshots() v1alpha1.WorkspaceSnapshotInterface {
+	return &FakeWorkspaceSnapshots{c}
+}
+
+func (c *FakeTenancyV1alpha1) WorkspaceSources() v1alpha1.WorkspaceSourceInterface {
+	return &FakeWorkspaceSources{c}
+}
+
 // RESTClient returns a RESTClient that is used to communicate
 // with API server by this client implementation.
 func (c *FakeTenancyV1alpha1) RESTClient() rest.Interface {
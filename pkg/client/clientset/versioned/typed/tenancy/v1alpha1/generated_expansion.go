@@ -23,3 +23,7 @@ type ClusterWorkspaceExpansion interface{}
 type ClusterWorkspaceShardExpansion interface{}
 
 type ClusterWorkspaceTypeExpansion interface{}
+
+type WorkspaceSnapshotExpansion interface{}
+
+type WorkspaceSourceExpansion interface{}
@@ -34,6 +34,8 @@ type TenancyV1alpha1Interface interface {
 	ClusterWorkspacesGetter
 	ClusterWorkspaceShardsGetter
 	ClusterWorkspaceTypesGetter
+	WorkspaceSnapshotsGetter
+	WorkspaceSourcesGetter
 }
 
 // TenancyV1alpha1Client is used to interact with features provided by the tenancy.kcp.dev group.
@@ -54,6 +56,14 @@ func (c *TenancyV1alpha1Client) ClusterWorkspaceTypes() ClusterWorkspaceTypeInte
 	return newClusterWorkspaceTypes(c)
 }
 
+func (c *TenancyV1alpha1Client) WorkspaceSnapshots() WorkspaceSnapshotInterface {
+	return newWorkspaceSnapshots(c)
+}
+
+func (c *TenancyV1alpha1Client) WorkspaceSources() WorkspaceSourceInterface {
+	return newWorkspaceSources(c)
+}
+
 // NewForConfig creates a new TenancyV1alpha1Client for the given config.
 // NewForConfig is equivalent to NewForConfigAndClient(c, httpClient),
 // where httpClient was generated with rest.HTTPClientFor(c).
@@ -33,6 +33,10 @@ func (c *FakeTenancyV1beta1) Workspaces() v1beta1.WorkspaceInterface {
 	return &FakeWorkspaces{c}
 }
 
+func (c *FakeTenancyV1beta1) WorkspaceAccessReviews() v1beta1.WorkspaceAccessReviewInterface {
+	return &FakeWorkspaceAccessReviews{c}
+}
+
 // RESTClient returns a RESTClient that is used to communicate
 // with API server by this client implementation.
 func (c *FakeTenancyV1beta1) RESTClient() rest.Interface {
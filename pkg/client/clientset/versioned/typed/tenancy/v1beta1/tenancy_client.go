@@ -32,6 +32,7 @@ import (
 type TenancyV1beta1Interface interface {
 	RESTClient() rest.Interface
 	WorkspacesGetter
+	WorkspaceAccessReviewsGetter
 }
 
 // TenancyV1beta1Client is used to interact with features provided by the tenancy.kcp.dev group.
@@ -44,6 +45,10 @@ func (c *TenancyV1beta1Client) Workspaces() WorkspaceInterface {
 	return newWorkspaces(c)
 }
 
+func (c *TenancyV1beta1Client) WorkspaceAccessReviews() WorkspaceAccessReviewInterface {
+	return newWorkspaceAccessReviews(c)
+}
+
 // NewForConfig creates a new TenancyV1beta1Client for the given config.
 // NewForConfig is equivalent to NewForConfigAndClient(c, httpClient),
 // where httpClient was generated with rest.HTTPClientFor(c).
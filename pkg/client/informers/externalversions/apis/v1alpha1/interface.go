@@ -30,6 +30,8 @@ type Interface interface {
 	APIExports() APIExportInformer
 	// APIResourceSchemas returns a APIResourceSchemaInformer.
 	APIResourceSchemas() APIResourceSchemaInformer
+	// SecretShares returns a SecretShareInformer.
+	SecretShares() SecretShareInformer
 }
 
 type version struct {
@@ -57,3 +59,8 @@ func (v *version) APIExports() APIExportInformer {
 func (v *version) APIResourceSchemas() APIResourceSchemaInformer {
 	return &aPIResourceSchemaInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
 }
+
+// SecretShares returns a SecretShareInformer.
+func (v *version) SecretShares() SecretShareInformer {
+	return &secretShareInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
+}
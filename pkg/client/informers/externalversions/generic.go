@@ -71,6 +71,8 @@ func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Apis().V1alpha1().APIExports().Informer()}, nil
 	case apisv1alpha1.SchemeGroupVersion.WithResource("apiresourceschemas"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Apis().V1alpha1().APIResourceSchemas().Informer()}, nil
+	case apisv1alpha1.SchemeGroupVersion.WithResource("secretshares"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Apis().V1alpha1().SecretShares().Informer()}, nil
 
 		// Group=scheduling.kcp.dev, Version=v1alpha1
 	case schedulingv1alpha1.SchemeGroupVersion.WithResource("locations"):
@@ -83,6 +85,10 @@ func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Tenancy().V1alpha1().ClusterWorkspaceShards().Informer()}, nil
 	case tenancyv1alpha1.SchemeGroupVersion.WithResource("clusterworkspacetypes"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Tenancy().V1alpha1().ClusterWorkspaceTypes().Informer()}, nil
+	case tenancyv1alpha1.SchemeGroupVersion.WithResource("workspacesnapshots"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Tenancy().V1alpha1().WorkspaceSnapshots().Informer()}, nil
+	case tenancyv1alpha1.SchemeGroupVersion.WithResource("workspacesources"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Tenancy().V1alpha1().WorkspaceSources().Informer()}, nil
 
 		// Group=tenancy.kcp.dev, Version=v1beta1
 	case v1beta1.SchemeGroupVersion.WithResource("workspaces"):
@@ -30,6 +30,10 @@ type Interface interface {
 	ClusterWorkspaceShards() ClusterWorkspaceShardInformer
 	// ClusterWorkspaceTypes returns a ClusterWorkspaceTypeInformer.
 	ClusterWorkspaceTypes() ClusterWorkspaceTypeInformer
+	// WorkspaceSnapshots returns a WorkspaceSnapshotInformer.
+	WorkspaceSnapshots() WorkspaceSnapshotInformer
+	// WorkspaceSources returns a WorkspaceSourceInformer.
+	WorkspaceSources() WorkspaceSourceInformer
 }
 
 type version struct {
@@ -57,3 +61,13 @@ func (v *version) ClusterWorkspaceShards() ClusterWorkspaceShardInformer {
 func (v *version) ClusterWorkspaceTypes() ClusterWorkspaceTypeInformer {
 	return &clusterWorkspaceTypeInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
 }
+
+// WorkspaceSnapshots returns a WorkspaceSnapshotInformer.
+func (v *version) WorkspaceSnapshots() WorkspaceSnapshotInformer {
+	return &workspaceSnapshotInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
+}
+
+// WorkspaceSources returns a WorkspaceSourceInformer.
+func (v *version) WorkspaceSources() WorkspaceSourceInformer {
+	return &workspaceSourceInformer{factory: v.factory, tweakListOptions: v.tweakListOptions}
+}
@@ -29,3 +29,11 @@ type ClusterWorkspaceShardListerExpansion interface{}
 // ClusterWorkspaceTypeListerExpansion allows custom methods to be added to
 // ClusterWorkspaceTypeLister.
 type ClusterWorkspaceTypeListerExpansion interface{}
+
+// WorkspaceSnapshotListerExpansion allows custom methods to be added to
+// WorkspaceSnapshotLister.
+type WorkspaceSnapshotListerExpansion interface{}
+
+// WorkspaceSourceListerExpansion allows custom methods to be added to
+// WorkspaceSourceLister.
+type WorkspaceSourceListerExpansion interface{}
@@ -0,0 +1,64 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+
+	"github.com/kcp-dev/kcp/pkg/cliplugins/diagnose/plugin"
+)
+
+var (
+	diagnoseExample = `
+	# Check shards, front proxy routing, virtual workspaces, and the workspaces, APIBindings
+	# and workload clusters of the current workspace, and print remediation hints.
+	%[1]s diagnose
+`
+)
+
+// New provides a cobra command to diagnose a kcp installation.
+func New(streams genericclioptions.IOStreams) (*cobra.Command, error) {
+	opts := plugin.NewOptions(streams)
+
+	cmd := &cobra.Command{
+		Aliases:      []string{"doctor"},
+		Use:          "diagnose",
+		Short:        "Runs health checks against kcp and the current workspace and prints remediation hints",
+		Example:      fmt.Sprintf(diagnoseExample, "kubectl kcp"),
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, args []string) error {
+			if len(args) != 0 {
+				return c.Help()
+			}
+			if err := opts.Validate(); err != nil {
+				return err
+			}
+			kubeconfig, err := plugin.NewConfig(opts)
+			if err != nil {
+				return err
+			}
+			return kubeconfig.Diagnose(c.Context())
+		},
+	}
+	opts.BindFlags(cmd)
+
+	return cmd, nil
+}
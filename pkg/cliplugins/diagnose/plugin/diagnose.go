@@ -0,0 +1,454 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kcp-dev/logicalcluster"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+
+	apisv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apis/v1alpha1"
+	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+	kcpclientset "github.com/kcp-dev/kcp/pkg/client/clientset/versioned"
+	"github.com/kcp-dev/kcp/pkg/cliplugins/helpers"
+	conditionsv1alpha1 "github.com/kcp-dev/kcp/third_party/conditions/apis/conditions/v1alpha1"
+	"github.com/kcp-dev/kcp/third_party/conditions/util/conditions"
+)
+
+const (
+	// stuckWorkspaceThreshold is the time after which a workspace that is not ready is reported as stuck.
+	stuckWorkspaceThreshold = 10 * time.Minute
+	// orphanedWorkloadClusterThreshold is the time without syncer heartbeat after which a workload cluster
+	// is reported as orphaned.
+	orphanedWorkloadClusterThreshold = time.Hour
+
+	probeTimeout = 10 * time.Second
+)
+
+// Severity is the severity of a Finding.
+type Severity string
+
+const (
+	SeverityOK      Severity = "OK"
+	SeverityWarning Severity = "WARNING"
+	SeverityError   Severity = "ERROR"
+)
+
+// Finding is the result of a check, with a hint how to remediate it if the check failed.
+type Finding struct {
+	Check    string
+	Severity Severity
+	Message  string
+	Hint     string
+}
+
+type Config struct {
+	startingConfig *clientcmdapi.Config
+	overrides      *clientcmd.ConfigOverrides
+
+	genericclioptions.IOStreams
+}
+
+// NewConfig load a kubeconfig with default config access
+func NewConfig(opts *Options) (*Config, error) {
+	configAccess := clientcmd.NewDefaultClientConfigLoadingRules()
+	startingConfig, err := configAccess.GetStartingConfig()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Config{
+		startingConfig: startingConfig,
+		overrides:      opts.KubectlOverrides,
+
+		IOStreams: opts.IOStreams,
+	}, nil
+}
+
+// Diagnose runs the checks against the current workspace and prints the findings with remediation hints.
+// It fails if any check found an error.
+func (c *Config) Diagnose(ctx context.Context) error {
+	config, err := clientcmd.NewDefaultClientConfig(*c.startingConfig, c.overrides).ClientConfig()
+	if err != nil {
+		return err
+	}
+
+	serverURL, clusterName, err := helpers.ParseClusterURL(config.Host)
+	if err != nil {
+		return fmt.Errorf("current URL %q does not point to cluster workspace", config.Host)
+	}
+
+	kcpClientFor := func(clusterName logicalcluster.Name) (kcpclientset.Interface, error) {
+		clusterConfig := rest.CopyConfig(config)
+		clusterURL := *serverURL
+		clusterURL.Path = path.Join(serverURL.Path, clusterName.Path())
+		clusterConfig.Host = clusterURL.String()
+		return kcpclientset.NewForConfig(clusterConfig)
+	}
+	kcpClient, err := kcpClientFor(clusterName)
+	if err != nil {
+		return fmt.Errorf("failed to create kcp client: %w", err)
+	}
+	rootKcpClient, err := kcpClientFor(tenancyv1alpha1.RootCluster)
+	if err != nil {
+		return fmt.Errorf("failed to create kcp client: %w", err)
+	}
+	virtualWorkspaceURL, err := helpers.VirtualWorkspaceBaseURL(ctx, kcpClientFor, clusterName, serverURL)
+	if err != nil {
+		return err
+	}
+
+	transport, err := rest.TransportFor(config)
+	if err != nil {
+		return err
+	}
+	httpClient := &http.Client{Transport: transport, Timeout: probeTimeout}
+
+	d := &diagnoser{
+		clusterName:         clusterName,
+		virtualWorkspaceURL: virtualWorkspaceURL,
+		kcpClient:           kcpClient,
+		rootKcpClient:       rootKcpClient,
+		probe: func(ctx context.Context, u string) error {
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+			if err != nil {
+				return err
+			}
+			resp, err := httpClient.Do(req)
+			if err != nil {
+				return err
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != http.StatusOK {
+				body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+				return fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(body)))
+			}
+			return nil
+		},
+		now: time.Now,
+	}
+
+	findings := d.diagnose(ctx)
+	return printFindings(c.Out, findings)
+}
+
+// printFindings prints the findings, and returns an error if any of them is an error.
+func printFindings(out io.Writer, findings []Finding) error {
+	var errs, warnings int
+	for _, f := range findings {
+		fmt.Fprintf(out, "%-8s %-18s %s\n", f.Severity, f.Check, f.Message)
+		if f.Hint != "" && f.Severity != SeverityOK {
+			fmt.Fprintf(out, "%-8s %-18s hint: %s\n", "", "", f.Hint)
+		}
+		switch f.Severity {
+		case SeverityError:
+			errs++
+		case SeverityWarning:
+			warnings++
+		}
+	}
+	fmt.Fprintf(out, "\n%d checks, %d errors, %d warnings\n", len(findings), errs, warnings)
+
+	if errs > 0 {
+		return fmt.Errorf("%d checks failed", errs)
+	}
+	return nil
+}
+
+type diagnoser struct {
+	clusterName         logicalcluster.Name
+	virtualWorkspaceURL *url.URL
+	kcpClient           kcpclientset.Interface
+	rootKcpClient       kcpclientset.Interface
+	probe               func(ctx context.Context, url string) error
+	now                 func() time.Time
+}
+
+func (d *diagnoser) diagnose(ctx context.Context) []Finding {
+	var findings []Finding
+	for _, check := range []func(ctx context.Context) []Finding{
+		d.checkShards,
+		d.checkFrontProxy,
+		d.checkVirtualWorkspaces,
+		d.checkWorkspaces,
+		d.checkAPIBindings,
+		d.checkWorkloadClusters,
+	} {
+		findings = append(findings, check(ctx)...)
+	}
+	return findings
+}
+
+// listFailed turns an error of a list request into a finding.
+func listFailed(check, what string, err error) []Finding {
+	if apierrors.IsForbidden(err) {
+		return []Finding{{Check: check, Severity: SeverityWarning, Message: fmt.Sprintf("not allowed to list %s: %v", what, err), Hint: "run the diagnosis with credentials allowed to list " + what}}
+	}
+	if apierrors.IsNotFound(err) {
+		return []Finding{{Check: check, Severity: SeverityOK, Message: fmt.Sprintf("%s are not served here", what)}}
+	}
+	return []Finding{{Check: check, Severity: SeverityError, Message: fmt.Sprintf("failed to list %s: %v", what, err), Hint: "check that the server is reachable and healthy"}}
+}
+
+// checkShards probes the readiness of all shards.
+func (d *diagnoser) checkShards(ctx context.Context) []Finding {
+	const check = "shards"
+
+	shards, err := d.rootKcpClient.TenancyV1alpha1().ClusterWorkspaceShards().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return listFailed(check, "ClusterWorkspaceShards in the root workspace", err)
+	}
+
+	var findings []Finding
+	for _, shard := range shards.Items {
+		if err := d.probe(ctx, strings.TrimSuffix(shard.Spec.BaseURL, "/")+"/readyz"); err != nil {
+			findings = append(findings, Finding{
+				Check:    check,
+				Severity: SeverityError,
+				Message:  fmt.Sprintf("shard %s at %s is not ready: %v", shard.Name, shard.Spec.BaseURL, err),
+				Hint:     "check the logs of the shard and its /readyz?verbose output; workspaces scheduled to it are unavailable",
+			})
+			continue
+		}
+		findings = append(findings, Finding{Check: check, Severity: SeverityOK, Message: fmt.Sprintf("shard %s at %s is ready", shard.Name, shard.Spec.BaseURL)})
+	}
+	if len(findings) == 0 {
+		findings = append(findings, Finding{Check: check, Severity: SeverityWarning, Message: "no ClusterWorkspaceShards found", Hint: "no workspace can be scheduled without a shard; check that the root workspace has been bootstrapped"})
+	}
+	return findings
+}
+
+// checkFrontProxy requests the ready child workspaces through their URL, i.e. usually through the front proxy.
+func (d *diagnoser) checkFrontProxy(ctx context.Context) []Finding {
+	const check = "front-proxy"
+
+	workspaces, err := d.kcpClient.TenancyV1alpha1().ClusterWorkspaces().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return listFailed(check, "ClusterWorkspaces", err)
+	}
+
+	var findings []Finding
+	for _, ws := range workspaces.Items {
+		if ws.Status.Phase != tenancyv1alpha1.ClusterWorkspacePhaseReady || ws.Status.BaseURL == "" {
+			continue
+		}
+		if err := d.probe(ctx, strings.TrimSuffix(ws.Status.BaseURL, "/")+"/version"); err != nil {
+			findings = append(findings, Finding{
+				Check:    check,
+				Severity: SeverityError,
+				Message:  fmt.Sprintf("workspace %s is not reachable at %s: %v", ws.Name, ws.Status.BaseURL, err),
+				Hint:     "check that the front proxy maps /clusters/ to the shard of the workspace, and that the externalURL of the ClusterWorkspaceShard points to the front proxy",
+			})
+			continue
+		}
+		findings = append(findings, Finding{Check: check, Severity: SeverityOK, Message: fmt.Sprintf("workspace %s is reachable at %s", ws.Name, ws.Status.BaseURL)})
+	}
+	return findings
+}
+
+// checkVirtualWorkspaces requests the workspaces virtual workspace of the current workspace.
+func (d *diagnoser) checkVirtualWorkspaces(ctx context.Context) []Finding {
+	const check = "virtual-workspaces"
+
+	u := *d.virtualWorkspaceURL
+	u.Path = path.Join(u.Path, "/services/workspaces", d.clusterName.String(), "personal", "apis/tenancy.kcp.dev/v1beta1/workspaces")
+	if err := d.probe(ctx, u.String()); err != nil {
+		return []Finding{{
+			Check:    check,
+			Severity: SeverityError,
+			Message:  fmt.Sprintf("the workspaces virtual workspace is not reachable at %s: %v", u.String(), err),
+			Hint:     "check that the virtual workspaces server is running, and that the front proxy maps /services/ to it or the virtualWorkspaceURL of the ClusterWorkspaceShard points to it",
+		}}
+	}
+	return []Finding{{Check: check, Severity: SeverityOK, Message: fmt.Sprintf("the workspaces virtual workspace is reachable at %s", u.String())}}
+}
+
+// checkWorkspaces reports child workspaces that do not become ready.
+func (d *diagnoser) checkWorkspaces(ctx context.Context) []Finding {
+	const check = "workspaces"
+
+	workspaces, err := d.kcpClient.TenancyV1alpha1().ClusterWorkspaces().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return listFailed(check, "ClusterWorkspaces", err)
+	}
+
+	var findings []Finding
+	var ready int
+	for _, ws := range workspaces.Items {
+		if ws.Status.Phase == tenancyv1alpha1.ClusterWorkspacePhaseReady {
+			ready++
+			continue
+		}
+		age := d.now().Sub(ws.CreationTimestamp.Time)
+		if age < stuckWorkspaceThreshold {
+			continue
+		}
+
+		finding := Finding{
+			Check:    check,
+			Severity: SeverityError,
+			Message:  fmt.Sprintf("workspace %s is stuck in phase %q since %s%s", ws.Name, ws.Status.Phase, age.Round(time.Second), falseConditions(ws.Status.Conditions)),
+		}
+		switch ws.Status.Phase {
+		case tenancyv1alpha1.ClusterWorkspacePhaseInitializing:
+			var initializers []string
+			for _, initializer := range ws.Status.Initializers {
+				initializers = append(initializers, string(initializer))
+			}
+			finding.Hint = fmt.Sprintf("check the controllers of the pending initializers %s", strings.Join(initializers, ", "))
+		default:
+			finding.Hint = "no shard accepted the workspace; check the shards and the scheduling conditions of the workspace"
+		}
+		findings = append(findings, finding)
+	}
+	findings = append(findings, Finding{Check: check, Severity: SeverityOK, Message: fmt.Sprintf("%d of %d child workspaces are ready", ready, len(workspaces.Items))})
+	return findings
+}
+
+// checkAPIBindings reports APIBindings that are not bound or have failing conditions.
+func (d *diagnoser) checkAPIBindings(ctx context.Context) []Finding {
+	const check = "apibindings"
+
+	bindings, err := d.kcpClient.ApisV1alpha1().APIBindings().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return listFailed(check, "APIBindings", err)
+	}
+
+	var findings []Finding
+	for i := range bindings.Items {
+		binding := &bindings.Items[i]
+		if binding.Status.Phase == apisv1alpha1.APIBindingPhaseBound && conditions.IsTrue(binding, conditionsv1alpha1.ReadyCondition) {
+			findings = append(findings, Finding{Check: check, Severity: SeverityOK, Message: fmt.Sprintf("APIBinding %s is bound", binding.Name)})
+			continue
+		}
+
+		severity := SeverityError
+		if binding.Status.Phase == apisv1alpha1.APIBindingPhaseBound {
+			// bound, but e.g. with conflicts or rebinding
+			severity = SeverityWarning
+		}
+		findings = append(findings, Finding{
+			Check:    check,
+			Severity: severity,
+			Message:  fmt.Sprintf("APIBinding %s is not ready in phase %q%s", binding.Name, binding.Status.Phase, falseConditions(binding.Status.Conditions)),
+			Hint:     apiBindingHint(binding),
+		})
+	}
+	return findings
+}
+
+func apiBindingHint(binding *apisv1alpha1.APIBinding) string {
+	for _, c := range binding.Status.Conditions {
+		if c.Status == corev1.ConditionTrue {
+			continue
+		}
+		switch c.Reason {
+		case apisv1alpha1.APIExportNotFoundReason, apisv1alpha1.APIExportInvalidReferenceReason:
+			return "check spec.reference: the APIExport must exist and the user must be allowed to bind it"
+		case apisv1alpha1.NamingConflictsReason:
+			return "another APIBinding or a CRD serves the same resources; delete one of them"
+		case apisv1alpha1.LocalCRDConflictReason:
+			return "a CRD in the workspace is shadowed by the APIBinding; delete the CRD"
+		case apisv1alpha1.WaitingForEstablishedReason:
+			return "the bound CRDs are not established yet; check the logs of the apibinding controller"
+		}
+	}
+	return "check the conditions of the APIBinding and the logs of the apibinding controller"
+}
+
+// checkWorkloadClusters reports workload clusters without a syncer.
+func (d *diagnoser) checkWorkloadClusters(ctx context.Context) []Finding {
+	const check = "sync-targets"
+
+	clusters, err := d.kcpClient.WorkloadV1alpha1().WorkloadClusters().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return listFailed(check, "WorkloadClusters", err)
+	}
+
+	var findings []Finding
+	for i := range clusters.Items {
+		cluster := &clusters.Items[i]
+		var lastHeartbeat time.Time
+		if cluster.Status.LastSyncerHeartbeatTime != nil {
+			lastHeartbeat = cluster.Status.LastSyncerHeartbeatTime.Time
+		} else {
+			lastHeartbeat = cluster.CreationTimestamp.Time
+		}
+		switch {
+		case d.now().Sub(lastHeartbeat) > orphanedWorkloadClusterThreshold:
+			msg := fmt.Sprintf("WorkloadCluster %s is orphaned: no syncer heartbeat since %s", cluster.Name, lastHeartbeat.UTC().Format(time.RFC3339))
+			if cluster.Status.LastSyncerHeartbeatTime == nil {
+				msg = fmt.Sprintf("WorkloadCluster %s is orphaned: no syncer has ever sent a heartbeat", cluster.Name)
+			}
+			findings = append(findings, Finding{
+				Check:    check,
+				Severity: SeverityError,
+				Message:  msg,
+				Hint:     fmt.Sprintf("deploy a syncer with 'kubectl kcp workload sync %s', or delete the WorkloadCluster if the physical cluster is gone", cluster.Name),
+			})
+		case !conditions.IsTrue(cluster, conditionsv1alpha1.ReadyCondition):
+			findings = append(findings, Finding{
+				Check:    check,
+				Severity: SeverityWarning,
+				Message:  fmt.Sprintf("WorkloadCluster %s is not ready%s", cluster.Name, falseConditions(cluster.Status.Conditions)),
+				Hint:     "check the logs of the syncer in the physical cluster",
+			})
+		default:
+			findings = append(findings, Finding{Check: check, Severity: SeverityOK, Message: fmt.Sprintf("WorkloadCluster %s is ready", cluster.Name)})
+		}
+	}
+	return findings
+}
+
+// falseConditions formats the reasons of the conditions that are not true.
+func falseConditions(cs conditionsv1alpha1.Conditions) string {
+	var reasons []string
+	for _, c := range cs {
+		if c.Status == corev1.ConditionTrue || c.Type == conditionsv1alpha1.ReadyCondition {
+			continue
+		}
+		reason := string(c.Type)
+		if c.Reason != "" {
+			reason += "=" + c.Reason
+		}
+		if c.Message != "" {
+			reason += " (" + c.Message + ")"
+		}
+		reasons = append(reasons, reason)
+	}
+	if len(reasons) == 0 {
+		return ""
+	}
+	sort.Strings(reasons)
+	return ": " + strings.Join(reasons, ", ")
+}
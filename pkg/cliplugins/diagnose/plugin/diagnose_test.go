@@ -0,0 +1,141 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kcp-dev/logicalcluster"
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apisv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apis/v1alpha1"
+	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/workload/v1alpha1"
+	kcpfakeclient "github.com/kcp-dev/kcp/pkg/client/clientset/versioned/fake"
+	conditionsv1alpha1 "github.com/kcp-dev/kcp/third_party/conditions/apis/conditions/v1alpha1"
+)
+
+func TestDiagnose(t *testing.T) {
+	now := time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC)
+	created := metav1.NewTime(now.Add(-2 * time.Hour))
+	recently := metav1.NewTime(now.Add(-time.Minute))
+
+	rootKcpClient := kcpfakeclient.NewSimpleClientset(
+		&tenancyv1alpha1.ClusterWorkspaceShard{ObjectMeta: metav1.ObjectMeta{Name: "root"}, Spec: tenancyv1alpha1.ClusterWorkspaceShardSpec{BaseURL: "https://shard-1"}},
+		&tenancyv1alpha1.ClusterWorkspaceShard{ObjectMeta: metav1.ObjectMeta{Name: "broken"}, Spec: tenancyv1alpha1.ClusterWorkspaceShardSpec{BaseURL: "https://shard-2/"}},
+	)
+	kcpClient := kcpfakeclient.NewSimpleClientset(
+		&tenancyv1alpha1.ClusterWorkspace{
+			ObjectMeta: metav1.ObjectMeta{Name: "ready", CreationTimestamp: created},
+			Status:     tenancyv1alpha1.ClusterWorkspaceStatus{Phase: tenancyv1alpha1.ClusterWorkspacePhaseReady, BaseURL: "https://proxy/clusters/root:org:ready"},
+		},
+		&tenancyv1alpha1.ClusterWorkspace{
+			ObjectMeta: metav1.ObjectMeta{Name: "initializing", CreationTimestamp: created},
+			Status: tenancyv1alpha1.ClusterWorkspaceStatus{
+				Phase:        tenancyv1alpha1.ClusterWorkspacePhaseInitializing,
+				Initializers: []tenancyv1alpha1.ClusterWorkspaceInitializer{"root:org:Universal"},
+			},
+		},
+		&tenancyv1alpha1.ClusterWorkspace{
+			ObjectMeta: metav1.ObjectMeta{Name: "new", CreationTimestamp: recently},
+			Status:     tenancyv1alpha1.ClusterWorkspaceStatus{Phase: tenancyv1alpha1.ClusterWorkspacePhaseScheduling},
+		},
+		&apisv1alpha1.APIBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: "kubernetes"},
+			Status: apisv1alpha1.APIBindingStatus{
+				Phase:      apisv1alpha1.APIBindingPhaseBound,
+				Conditions: conditionsv1alpha1.Conditions{{Type: conditionsv1alpha1.ReadyCondition, Status: corev1.ConditionTrue}},
+			},
+		},
+		&apisv1alpha1.APIBinding{
+			ObjectMeta: metav1.ObjectMeta{Name: "missing"},
+			Status: apisv1alpha1.APIBindingStatus{
+				Phase: apisv1alpha1.APIBindingPhaseBinding,
+				Conditions: conditionsv1alpha1.Conditions{
+					{Type: conditionsv1alpha1.ReadyCondition, Status: corev1.ConditionFalse, Reason: apisv1alpha1.APIExportNotFoundReason},
+					{Type: apisv1alpha1.APIExportValid, Status: corev1.ConditionFalse, Reason: apisv1alpha1.APIExportNotFoundReason},
+				},
+			},
+		},
+		&workloadv1alpha1.WorkloadCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "healthy", CreationTimestamp: created},
+			Status: workloadv1alpha1.WorkloadClusterStatus{
+				LastSyncerHeartbeatTime: &recently,
+				Conditions:              conditionsv1alpha1.Conditions{{Type: conditionsv1alpha1.ReadyCondition, Status: corev1.ConditionTrue}},
+			},
+		},
+		&workloadv1alpha1.WorkloadCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "orphan", CreationTimestamp: created},
+		},
+	)
+
+	var probed []string
+	d := &diagnoser{
+		clusterName:         logicalcluster.New("root:org"),
+		virtualWorkspaceURL: &url.URL{Scheme: "https", Host: "virtual"},
+		kcpClient:           kcpClient,
+		rootKcpClient:       rootKcpClient,
+		probe: func(ctx context.Context, u string) error {
+			probed = append(probed, u)
+			if strings.HasPrefix(u, "https://shard-2") {
+				return errors.New("connection refused")
+			}
+			return nil
+		},
+		now: func() time.Time { return now },
+	}
+
+	out := &bytes.Buffer{}
+	err := printFindings(out, d.diagnose(context.Background()))
+	require.EqualError(t, err, "4 checks failed")
+	require.ElementsMatch(t, []string{
+		"https://shard-1/readyz",
+		"https://shard-2/readyz",
+		"https://proxy/clusters/root:org:ready/version",
+		"https://virtual/services/workspaces/root:org/personal/apis/tenancy.kcp.dev/v1beta1/workspaces",
+	}, probed)
+
+	output := out.String()
+	for _, expected := range []string{
+		"OK       shards             shard root at https://shard-1 is ready",
+		"ERROR    shards             shard broken at https://shard-2/ is not ready: connection refused",
+		"OK       front-proxy        workspace ready is reachable at https://proxy/clusters/root:org:ready",
+		"OK       virtual-workspaces the workspaces virtual workspace is reachable",
+		`ERROR    workspaces         workspace initializing is stuck in phase "Initializing" since 2h0m0s`,
+		"hint: check the controllers of the pending initializers root:org:Universal",
+		"OK       workspaces         1 of 3 child workspaces are ready",
+		"OK       apibindings        APIBinding kubernetes is bound",
+		`ERROR    apibindings        APIBinding missing is not ready in phase "Binding": APIExportValid=APIExportNotFound`,
+		"hint: check spec.reference",
+		"OK       sync-targets       WorkloadCluster healthy is ready",
+		"ERROR    sync-targets       WorkloadCluster orphan is orphaned: no syncer has ever sent a heartbeat",
+		"hint: deploy a syncer with 'kubectl kcp workload sync orphan'",
+		"10 checks, 4 errors, 0 warnings",
+	} {
+		require.Contains(t, output, expected)
+	}
+	require.NotContains(t, output, "workspace new")
+}
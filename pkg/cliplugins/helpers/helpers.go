@@ -17,16 +17,21 @@ limitations under the License.
 package helpers
 
 import (
+	"context"
 	"fmt"
 	"net/url"
 	"path"
-	"regexp"
 	"strings"
 
 	"github.com/kcp-dev/logicalcluster"
 
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
 	virtualcommandoptions "github.com/kcp-dev/kcp/cmd/virtual-workspaces/options"
-	"github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+	tenancypath "github.com/kcp-dev/kcp/pkg/apis/tenancy/path"
+	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+	kcpclientset "github.com/kcp-dev/kcp/pkg/client/clientset/versioned"
 )
 
 func ParseClusterURL(host string) (*url.URL, logicalcluster.Name, error) {
@@ -46,19 +51,54 @@ func ParseClusterURL(host string) (*url.URL, logicalcluster.Name, error) {
 			break
 		}
 	}
-	if clusterName.Empty() || !IsValid(clusterName) {
+	if !tenancypath.IsValid(clusterName) {
 		return nil, logicalcluster.Name{}, fmt.Errorf("current cluster URL %s is not pointing to a cluster workspace", u)
 	}
 
 	return &ret, clusterName, nil
 }
 
-var lclusterRegExp = regexp.MustCompile(`^[a-z][a-z0-9-]*[a-z0-9](:[a-z][a-z0-9-]*[a-z0-9])*$`)
+// VirtualWorkspaceBaseURL returns the base URL of the virtual workspaces of the given workspace, i.e. the
+// virtualWorkspaceURL of the ClusterWorkspaceShard the workspace is scheduled to. It falls back to the given
+// server URL if the shard is not known, e.g. for the root workspace, if the shard has no virtual workspace URL,
+// or if the user is not allowed to read the ClusterWorkspace or the ClusterWorkspaceShard.
+func VirtualWorkspaceBaseURL(ctx context.Context, kcpClientFor func(logicalcluster.Name) (kcpclientset.Interface, error), clusterName logicalcluster.Name, server *url.URL) (*url.URL, error) {
+	parent, hasParent := clusterName.Parent()
+	if !hasParent {
+		return server, nil
+	}
+
+	parentClient, err := kcpClientFor(parent)
+	if err != nil {
+		return nil, err
+	}
+	ws, err := parentClient.TenancyV1alpha1().ClusterWorkspaces().Get(ctx, clusterName.Base(), metav1.GetOptions{})
+	if apierrors.IsNotFound(err) || apierrors.IsForbidden(err) {
+		return server, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to get ClusterWorkspace %s: %w", clusterName, err)
+	}
+	if ws.Status.Location.Current == "" {
+		return server, nil
+	}
 
-func IsValid(cluster logicalcluster.Name) bool {
-	if !lclusterRegExp.MatchString(cluster.String()) {
-		return false
+	rootClient, err := kcpClientFor(tenancyv1alpha1.RootCluster)
+	if err != nil {
+		return nil, err
+	}
+	shard, err := rootClient.TenancyV1alpha1().ClusterWorkspaceShards().Get(ctx, ws.Status.Location.Current, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) || apierrors.IsForbidden(err) {
+		return server, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to get ClusterWorkspaceShard %s: %w", ws.Status.Location.Current, err)
+	}
+	if shard.Spec.VirtualWorkspaceURL == "" {
+		return server, nil
 	}
 
-	return cluster.HasPrefix(v1alpha1.RootCluster) || cluster.HasPrefix(logicalcluster.New("system"))
+	u, err := url.Parse(shard.Spec.VirtualWorkspaceURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid virtual workspace URL of ClusterWorkspaceShard %s: %w", shard.Name, err)
+	}
+	return u, nil
 }
@@ -17,54 +17,20 @@ limitations under the License.
 package helpers
 
 import (
+	"context"
+	"net/url"
 	"testing"
 
 	"github.com/kcp-dev/logicalcluster"
 	"github.com/stretchr/testify/require"
-)
-
-func TestReCluster(t *testing.T) {
-	tests := []struct {
-		workspace string
-		valid     bool
-	}{
-		{"", false},
 
-		{"root", true},
-		{"root:foo", true},
-		{"root:foo:bar", true},
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 
-		{"system", true},
-		{"system:foo", true},
-		{"system:foo:bar", true},
-
-		// the plugin does not decide about segment length, the server does
-		{"root:b1234567890123456789012345678912", true},
-		{"root:test-8827a131-f796-4473-8904-a0fa527696eb:b1234567890123456789012345678912", true},
-		{"root:test-too-long-org-0020-4473-0030-a0fa-0040-5276-0050-sdg2-0060:b1234567890123456789012345678912", true},
-
-		{"foo", false},
-		{"foo:bar", false},
-		{"root:", false},
-		{":root", false},
-		{"root::foo", false},
-		{"root:föö:bär", false},
-		{"root:bar_bar", false},
-		{"root:a", false},
-		{"root:0a", false},
-		{"root:0bar", false},
-		{"root/bar", false},
-		{"root:bar-", false},
-		{"root:-bar", false},
-	}
-	for _, tt := range tests {
-		t.Run(tt.workspace, func(t *testing.T) {
-			if got := IsValid(logicalcluster.New(tt.workspace)); got != tt.valid {
-				t.Errorf("isValid(%q) = %v, want %v", tt.workspace, got, tt.valid)
-			}
-		})
-	}
-}
+	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+	kcpclientset "github.com/kcp-dev/kcp/pkg/client/clientset/versioned"
+	kcpfakeclient "github.com/kcp-dev/kcp/pkg/client/clientset/versioned/fake"
+)
 
 func TestParseClusterURL(t *testing.T) {
 	tests := []struct {
@@ -111,3 +77,45 @@ func TestParseClusterURL(t *testing.T) {
 		})
 	}
 }
+
+func TestVirtualWorkspaceBaseURL(t *testing.T) {
+	server := &url.URL{Scheme: "https", Host: "proxy"}
+	scheduled := func(name, shard string) *tenancyv1alpha1.ClusterWorkspace {
+		return &tenancyv1alpha1.ClusterWorkspace{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Status:     tenancyv1alpha1.ClusterWorkspaceStatus{Location: tenancyv1alpha1.ClusterWorkspaceLocation{Current: shard}},
+		}
+	}
+	root := []runtime.Object{
+		&tenancyv1alpha1.ClusterWorkspaceShard{ObjectMeta: metav1.ObjectMeta{Name: "external"}, Spec: tenancyv1alpha1.ClusterWorkspaceShardSpec{VirtualWorkspaceURL: "https://virtual"}},
+		&tenancyv1alpha1.ClusterWorkspaceShard{ObjectMeta: metav1.ObjectMeta{Name: "in-process"}},
+		scheduled("org", "external"),
+		scheduled("other", "in-process"),
+		scheduled("unscheduled", ""),
+		scheduled("lost", "unknown"),
+	}
+
+	tests := []struct {
+		cluster string
+		want    string
+	}{
+		{cluster: "root", want: "https://proxy"},
+		{cluster: "root:org", want: "https://virtual"},
+		{cluster: "root:other", want: "https://proxy"},
+		{cluster: "root:unscheduled", want: "https://proxy"},
+		{cluster: "root:lost", want: "https://proxy"},
+		{cluster: "root:missing", want: "https://proxy"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.cluster, func(t *testing.T) {
+			rootClient := kcpfakeclient.NewSimpleClientset(root...)
+			kcpClientFor := func(clusterName logicalcluster.Name) (kcpclientset.Interface, error) {
+				require.Equal(t, tenancyv1alpha1.RootCluster, clusterName)
+				return rootClient, nil
+			}
+			got, err := VirtualWorkspaceBaseURL(context.Background(), kcpClientFor, logicalcluster.New(tt.cluster), server)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got.String())
+		})
+	}
+}
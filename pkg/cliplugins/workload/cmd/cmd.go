@@ -22,6 +22,7 @@ import (
 
 	"github.com/spf13/cobra"
 
+	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 
@@ -31,7 +32,27 @@ import (
 var (
 	syncExample = `
 	# Ensure a syncer is running on the specified workload cluster.
-	%[1]s workload sync <workload-cluster-name> --syncer-image <kcp-syncer-image>
+	%[1]s workload sync <workload-cluster-name> --syncer-image <kcp-syncer-image> -o syncer.yaml
+
+	# Ensure a syncer is running on the specified workload cluster, and deploy it to the physical cluster of the
+	# given kubeconfig context.
+	%[1]s workload sync <workload-cluster-name> --syncer-image <kcp-syncer-image> --apply-context kind-kind
+
+	# Render a Helm chart for the syncer of the specified workload cluster to the syncer-chart directory.
+	%[1]s workload sync <workload-cluster-name> --syncer-image <kcp-syncer-image> --output-format helm -o syncer-chart
+`
+
+	approveAPIExample = `
+	# Publish the negotiated API resource of deployments.
+	%[1]s workload approve-api deployments.v1.apps
+
+	# Publish all negotiated API resources pending approval.
+	%[1]s workload approve-api --all
+`
+
+	explainPlacementExample = `
+	# Explain why the namespace default is or is not placed on the workload clusters.
+	%[1]s workload explain-placement default
 `
 )
 
@@ -56,14 +77,19 @@ func New(streams genericclioptions.IOStreams) (*cobra.Command, error) {
 	// talking to kcp.
 	//
 	// TODO(marun) Consider allowing a user-specified and exclusive set of types.
-	requiredResourcesToSync := sets.NewString("deployments.apps", "secrets", "configmaps", "serviceaccounts")
+	requiredResourcesToSync := sets.NewString("deployments.apps", "secrets", "configmaps", "serviceaccounts", "limitranges")
 
 	var userResourcesToSync []string
 	var syncerImage string
 	var replicas int = 1
+	var featureGates string
+	var requests, limits map[string]string
 	kcpNamespaceName := "default"
+	outputFormat := string(plugin.ManifestOutputFormat)
+	outputFile := "-"
+	var applyContext string
 	enableSyncerCmd := &cobra.Command{
-		Use:          "sync <workload-cluster-name> --syncer-image <kcp-syncer-image> [--resources=<resource1>,<resource2>..]",
+		Use:          "sync <workload-cluster-name> --syncer-image <kcp-syncer-image> [--resources=<resource1>,<resource2>..] [--output-format manifest|helm|kustomize] [-o <file-or-dir>] [--apply-context <context>]",
 		Short:        "Deploy a syncer for the given workload cluster",
 		Example:      fmt.Sprintf(syncExample, "kubectl kcp"),
 		SilenceUsage: true,
@@ -96,6 +122,25 @@ func New(streams genericclioptions.IOStreams) (*cobra.Command, error) {
 				return errors.New("only 0 and 1 are allowed as --replicas values")
 			}
 
+			for flag, quantities := range map[string]map[string]string{"requests": requests, "limits": limits} {
+				for name, quantity := range quantities {
+					if _, err := resource.ParseQuantity(quantity); err != nil {
+						return fmt.Errorf("invalid --%s quantity %q for %s: %w", flag, quantity, name, err)
+					}
+				}
+			}
+
+			format := plugin.OutputFormat(outputFormat)
+			switch format {
+			case plugin.ManifestOutputFormat:
+			case plugin.HelmOutputFormat, plugin.KustomizeOutputFormat:
+				if outputFile == "-" {
+					return fmt.Errorf("--output-format %s requires --output-file to name a directory", format)
+				}
+			default:
+				return fmt.Errorf("--output-format must be one of %v", plugin.OutputFormats)
+			}
+
 			workloadClusterName := args[0]
 			if len(workloadClusterName)+len(plugin.SyncerAuthResourcePrefix) > plugin.MaxSyncerAuthResourceName {
 				return fmt.Errorf("the maximum length of the workload-cluster-name is %d", plugin.MaxSyncerAuthResourceName)
@@ -103,15 +148,87 @@ func New(streams genericclioptions.IOStreams) (*cobra.Command, error) {
 
 			resourcesToSync := sets.NewString(userResourcesToSync...).Union(requiredResourcesToSync).List()
 
-			return kubeconfig.Sync(c.Context(), workloadClusterName, kcpNamespaceName, syncerImage, resourcesToSync, replicas)
+			// when applying, only print the resources if explicitly asked for
+			if applyContext != "" && !c.Flags().Changed("output-file") {
+				outputFile = ""
+			}
+
+			deployment := plugin.SyncerDeployment{
+				Image:           syncerImage,
+				Replicas:        replicas,
+				ResourcesToSync: resourcesToSync,
+				FeatureGates:    featureGates,
+				Requests:        requests,
+				Limits:          limits,
+			}
+			return kubeconfig.Sync(c.Context(), workloadClusterName, kcpNamespaceName, deployment, format, outputFile, applyContext)
 		},
 	}
 	enableSyncerCmd.Flags().StringSliceVar(&userResourcesToSync, "resources", userResourcesToSync, "Resources to synchronize with kcp.")
 	enableSyncerCmd.Flags().StringVar(&syncerImage, "syncer-image", syncerImage, "The syncer image to use in the syncer's deployment YAML.")
 	enableSyncerCmd.Flags().IntVar(&replicas, "replicas", replicas, "Number of replicas of the syncer deployment.")
+	enableSyncerCmd.Flags().StringVar(&featureGates, "feature-gates", featureGates, "A set of key=value pairs that describe feature gates of the syncer.")
+	enableSyncerCmd.Flags().StringToStringVar(&requests, "requests", requests, "The compute resources requested by the syncer container, e.g. cpu=100m,memory=128Mi.")
+	enableSyncerCmd.Flags().StringToStringVar(&limits, "limits", limits, "The compute resource limits of the syncer container, e.g. memory=512Mi.")
 	enableSyncerCmd.Flags().StringVar(&kcpNamespaceName, "kcp-namespace", kcpNamespaceName, "The name of the kcp namespace to create a service account in.")
+	enableSyncerCmd.Flags().StringVar(&outputFormat, "output-format", outputFormat, "The format of the syncer resources: manifest, helm for a Helm chart or kustomize for a kustomize base.")
+	enableSyncerCmd.Flags().StringVarP(&outputFile, "output-file", "o", outputFile, "The manifest file to write the syncer resources to, or - for stdout. The directory to write the chart or base to for the helm and kustomize formats.")
+	enableSyncerCmd.Flags().StringVar(&applyContext, "apply-context", applyContext, "The kubeconfig context of the physical cluster to apply the syncer resources to. If set, the resources are only written out if --output-file is set explicitly.")
 
 	cmd.AddCommand(enableSyncerCmd)
 
+	var approveAll bool
+	approveAPICmd := &cobra.Command{
+		Use:          "approve-api [<negotiated-api-resource-name>...] [--all]",
+		Short:        "Approve negotiated API resources for publishing in the current workspace",
+		Example:      fmt.Sprintf(approveAPIExample, "kubectl kcp"),
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, args []string) error {
+			if err := opts.Validate(); err != nil {
+				return err
+			}
+			kubeconfig, err := plugin.NewConfig(opts)
+			if err != nil {
+				return err
+			}
+
+			if len(args) == 0 && !approveAll {
+				return c.Help()
+			}
+			if len(args) > 0 && approveAll {
+				return errors.New("--all cannot be combined with negotiated API resource names")
+			}
+
+			return kubeconfig.ApproveAPI(c.Context(), args, approveAll)
+		},
+	}
+	approveAPICmd.Flags().BoolVar(&approveAll, "all", approveAll, "Approve all negotiated API resources pending approval.")
+
+	cmd.AddCommand(approveAPICmd)
+
+	explainPlacementCmd := &cobra.Command{
+		Use:          "explain-placement <namespace>",
+		Short:        "Explain which locations and workload clusters are chosen for a namespace of the current workspace",
+		Example:      fmt.Sprintf(explainPlacementExample, "kubectl kcp"),
+		SilenceUsage: true,
+		RunE: func(c *cobra.Command, args []string) error {
+			if err := opts.Validate(); err != nil {
+				return err
+			}
+			kubeconfig, err := plugin.NewConfig(opts)
+			if err != nil {
+				return err
+			}
+
+			if len(args) != 1 {
+				return c.Help()
+			}
+
+			return kubeconfig.ExplainPlacement(c.Context(), args[0])
+		},
+	}
+
+	cmd.AddCommand(explainPlacementCmd)
+
 	return cmd, nil
 }
@@ -0,0 +1,53 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeManifests(t *testing.T) {
+	manifests, err := renderSyncerResources(templateInput{
+		ServerURL:       "server-url",
+		Token:           "token",
+		CAData:          "ca-data",
+		KCPNamespace:    "kcp-namespace",
+		LogicalCluster:  "root:default:foo",
+		WorkloadCluster: "workload-cluster-name",
+		SyncerDeployment: SyncerDeployment{
+			Image:           "image",
+			Replicas:        1,
+			ResourcesToSync: []string{"resource1", "resource2"},
+		},
+	})
+	require.NoError(t, err)
+
+	objs, err := decodeManifests(manifests)
+	require.NoError(t, err)
+
+	var kinds []string
+	for _, obj := range objs {
+		kinds = append(kinds, obj.GetKind())
+	}
+	require.Equal(t, []string{"Namespace", "ServiceAccount", "ClusterRole", "ClusterRoleBinding", "Role", "RoleBinding", "Secret", "Deployment"}, kinds)
+	require.Equal(t, "kcpsync25e6e3ce5be10b16411448aec95b6b6d695a1daa5120732019531d8d", objs[1].GetNamespace())
+
+	_, err = decodeManifests([]byte("---\nkind: [\n"))
+	require.Error(t, err)
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/clientcmd"
+
+	kcpclientset "github.com/kcp-dev/kcp/pkg/client/clientset/versioned"
+)
+
+// ApproveAPI sets spec.publish on the given negotiated API resources of the current
+// workspace, or on all pending ones if all is true.
+func (c *Config) ApproveAPI(ctx context.Context, names []string, all bool) error {
+	config, err := clientcmd.NewDefaultClientConfig(*c.startingConfig, c.overrides).ClientConfig()
+	if err != nil {
+		return err
+	}
+
+	kcpClient, err := kcpclientset.NewForConfig(config)
+	if err != nil {
+		return fmt.Errorf("failed to create kcp client: %w", err)
+	}
+
+	return approveAPIs(ctx, kcpClient, names, all, c.Out)
+}
+
+func approveAPIs(ctx context.Context, kcpClient kcpclientset.Interface, names []string, all bool, out io.Writer) error {
+	if all {
+		negotiatedAPIResources, err := kcpClient.ApiresourceV1alpha1().NegotiatedAPIResources().List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return fmt.Errorf("failed to list negotiated API resources: %w", err)
+		}
+		names = nil
+		for _, negotiatedAPIResource := range negotiatedAPIResources.Items {
+			if !negotiatedAPIResource.Spec.Publish {
+				names = append(names, negotiatedAPIResource.Name)
+			}
+		}
+		if len(names) == 0 {
+			fmt.Fprintln(out, "No negotiated API resource is pending approval")
+			return nil
+		}
+	}
+	if len(names) == 0 {
+		return errors.New("at least one negotiated API resource name or --all must be specified")
+	}
+
+	patch := []byte(`{"spec":{"publish":true}}`)
+	for _, name := range names {
+		if _, err := kcpClient.ApiresourceV1alpha1().NegotiatedAPIResources().Patch(ctx, name, types.MergePatchType, patch, metav1.PatchOptions{}); err != nil {
+			return fmt.Errorf("failed to approve negotiated API resource %q: %w", name, err)
+		}
+		fmt.Fprintf(out, "Negotiated API resource %q approved for publishing\n", name)
+	}
+	return nil
+}
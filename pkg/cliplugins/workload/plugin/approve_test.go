@@ -0,0 +1,91 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiresourcev1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apiresource/v1alpha1"
+	kcpfakeclient "github.com/kcp-dev/kcp/pkg/client/clientset/versioned/fake"
+)
+
+func TestApproveAPIs(t *testing.T) {
+	negotiatedAPIResource := func(name string, publish bool) *apiresourcev1alpha1.NegotiatedAPIResource {
+		return &apiresourcev1alpha1.NegotiatedAPIResource{
+			ObjectMeta: metav1.ObjectMeta{Name: name},
+			Spec: apiresourcev1alpha1.NegotiatedAPIResourceSpec{
+				Publish: publish,
+			},
+		}
+	}
+
+	tests := map[string]struct {
+		names         []string
+		all           bool
+		wantPublished []string
+		wantErr       bool
+	}{
+		"approve by name": {
+			names:         []string{"deployments.v1.apps"},
+			wantPublished: []string{"deployments.v1.apps", "services.v1.core"},
+		},
+		"approve all pending": {
+			all:           true,
+			wantPublished: []string{"deployments.v1.apps", "ingresses.v1.networking.k8s.io", "services.v1.core"},
+		},
+		"unknown name": {
+			names:   []string{"foos.v1.example.com"},
+			wantErr: true,
+		},
+		"no name": {
+			wantErr: true,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+			kcpClient := kcpfakeclient.NewSimpleClientset(
+				negotiatedAPIResource("deployments.v1.apps", false),
+				negotiatedAPIResource("ingresses.v1.networking.k8s.io", false),
+				negotiatedAPIResource("services.v1.core", true),
+			)
+
+			err := approveAPIs(ctx, kcpClient, tc.names, tc.all, &bytes.Buffer{})
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			negotiatedAPIResources, err := kcpClient.ApiresourceV1alpha1().NegotiatedAPIResources().List(ctx, metav1.ListOptions{})
+			require.NoError(t, err)
+			var published []string
+			for _, r := range negotiatedAPIResources.Items {
+				if r.Spec.Publish {
+					published = append(published, r.Name)
+				}
+			}
+			require.ElementsMatch(t, tc.wantPublished, published)
+		})
+	}
+}
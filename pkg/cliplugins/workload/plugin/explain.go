@@ -0,0 +1,139 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path"
+
+	"github.com/kcp-dev/logicalcluster"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+
+	apisv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apis/v1alpha1"
+	schedulingv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/scheduling/v1alpha1"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/workload/v1alpha1"
+	kcpclientset "github.com/kcp-dev/kcp/pkg/client/clientset/versioned"
+	"github.com/kcp-dev/kcp/pkg/cliplugins/helpers"
+	placementreconciler "github.com/kcp-dev/kcp/pkg/reconciler/scheduling/placement"
+)
+
+// ExplainPlacement prints why the Locations and WorkloadClusters are or are not chosen
+// by the placement controller for the given namespace of the current workspace.
+func (c *Config) ExplainPlacement(ctx context.Context, namespace string) error {
+	config, err := clientcmd.NewDefaultClientConfig(*c.startingConfig, c.overrides).ClientConfig()
+	if err != nil {
+		return err
+	}
+
+	u, currentClusterName, err := helpers.ParseClusterURL(config.Host)
+	if err != nil {
+		return fmt.Errorf("current URL %q does not point to cluster workspace", config.Host)
+	}
+
+	kubeClient, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return fmt.Errorf("failed to create kubernetes client: %w", err)
+	}
+	kcpClient, err := kcpclientset.NewForConfig(config)
+	if err != nil {
+		return fmt.Errorf("failed to create kcp client: %w", err)
+	}
+
+	// Locations and WorkloadClusters live in the negotiation workspace of the APIBinding.
+	kcpClientFor := func(clusterName logicalcluster.Name) (kcpclientset.Interface, error) {
+		clusterConfig := rest.CopyConfig(config)
+		clusterURL := *u
+		clusterURL.Path = path.Join(u.Path, clusterName.Path())
+		clusterConfig.Host = clusterURL.String()
+		return kcpclientset.NewForConfig(clusterConfig)
+	}
+
+	return explainPlacement(ctx, currentClusterName, kubeClient, kcpClient, kcpClientFor, namespace, c.Out)
+}
+
+func explainPlacement(ctx context.Context, clusterName logicalcluster.Name, kubeClient kubernetes.Interface, kcpClient kcpclientset.Interface, kcpClientFor func(logicalcluster.Name) (kcpclientset.Interface, error), namespace string, out io.Writer) error {
+	ns, err := kubeClient.CoreV1().Namespaces().Get(ctx, namespace, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to get namespace %q: %w", namespace, err)
+	}
+
+	bindingList, err := kcpClient.ApisV1alpha1().APIBindings().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to list APIBindings: %w", err)
+	}
+	bindings := make([]*apisv1alpha1.APIBinding, 0, len(bindingList.Items))
+	for i := range bindingList.Items {
+		bindings = append(bindings, &bindingList.Items[i])
+	}
+
+	explanation, err := placementreconciler.Explain(clusterName, ns, bindings,
+		func(clusterName logicalcluster.Name) ([]*schedulingv1alpha1.Location, error) {
+			client, err := kcpClientFor(clusterName)
+			if err != nil {
+				return nil, err
+			}
+			list, err := client.SchedulingV1alpha1().Locations().List(ctx, metav1.ListOptions{})
+			if err != nil {
+				return nil, err
+			}
+			ret := make([]*schedulingv1alpha1.Location, 0, len(list.Items))
+			for i := range list.Items {
+				ret = append(ret, &list.Items[i])
+			}
+			return ret, nil
+		},
+		func(clusterName logicalcluster.Name) ([]*workloadv1alpha1.WorkloadCluster, error) {
+			client, err := kcpClientFor(clusterName)
+			if err != nil {
+				return nil, err
+			}
+			list, err := client.WorkloadV1alpha1().WorkloadClusters().List(ctx, metav1.ListOptions{})
+			if err != nil {
+				return nil, err
+			}
+			ret := make([]*workloadv1alpha1.WorkloadCluster, 0, len(list.Items))
+			for i := range list.Items {
+				ret = append(ret, &list.Items[i])
+			}
+			return ret, nil
+		},
+		func(clusterName logicalcluster.Name) ([]*corev1.Namespace, error) {
+			list, err := kubeClient.CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+			if err != nil {
+				return nil, err
+			}
+			ret := make([]*corev1.Namespace, 0, len(list.Items))
+			for i := range list.Items {
+				ret = append(ret, &list.Items[i])
+			}
+			return ret, nil
+		},
+	)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprint(out, explanation.String())
+	return nil
+}
@@ -0,0 +1,84 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/kcp-dev/logicalcluster"
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kubefakeclient "k8s.io/client-go/kubernetes/fake"
+
+	apisv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apis/v1alpha1"
+	schedulingv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/scheduling/v1alpha1"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/workload/v1alpha1"
+	kcpclientset "github.com/kcp-dev/kcp/pkg/client/clientset/versioned"
+	kcpfakeclient "github.com/kcp-dev/kcp/pkg/client/clientset/versioned/fake"
+	conditionsv1alpha1 "github.com/kcp-dev/kcp/third_party/conditions/apis/conditions/v1alpha1"
+)
+
+func TestExplainPlacement(t *testing.T) {
+	ctx := context.Background()
+	kubeClient := kubefakeclient.NewSimpleClientset(&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}})
+	kcpClient := kcpfakeclient.NewSimpleClientset(&apisv1alpha1.APIBinding{
+		ObjectMeta: metav1.ObjectMeta{Name: "kubernetes"},
+		Spec: apisv1alpha1.APIBindingSpec{
+			Reference: apisv1alpha1.ExportReference{Workspace: &apisv1alpha1.WorkspaceExportReference{WorkspaceName: "compute"}},
+		},
+		Status: apisv1alpha1.APIBindingStatus{
+			Conditions: conditionsv1alpha1.Conditions{
+				{Type: apisv1alpha1.InitialBindingCompleted, Status: corev1.ConditionTrue},
+				{Type: apisv1alpha1.APIExportValid, Status: corev1.ConditionTrue},
+			},
+		},
+	})
+	negotiationClient := kcpfakeclient.NewSimpleClientset(
+		&schedulingv1alpha1.Location{
+			ObjectMeta: metav1.ObjectMeta{Name: "us-east1"},
+			Spec:       schedulingv1alpha1.LocationSpec{InstanceSelector: &metav1.LabelSelector{}},
+		},
+		&workloadv1alpha1.WorkloadCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "cluster1"},
+			Status: workloadv1alpha1.WorkloadClusterStatus{
+				Conditions: conditionsv1alpha1.Conditions{{Type: conditionsv1alpha1.ReadyCondition, Status: corev1.ConditionTrue}},
+			},
+		},
+	)
+	kcpClientFor := func(clusterName logicalcluster.Name) (kcpclientset.Interface, error) {
+		require.Equal(t, "root:org:compute", clusterName.String())
+		return negotiationClient, nil
+	}
+
+	out := &bytes.Buffer{}
+	err := explainPlacement(ctx, logicalcluster.New("root:org:ws"), kubeClient, kcpClient, kcpClientFor, "default", out)
+	require.NoError(t, err)
+	require.Equal(t, `Namespace "default":
+  APIBindings:
+    + kubernetes: 1 Locations in workspace root:org:compute
+  Locations in root:org:compute:
+    + us-east1 (100%): 1 of 1 matching WorkloadClusters are candidates
+      + cluster1 (100%): ready and tolerated
+`, out.String())
+
+	err = explainPlacement(ctx, logicalcluster.New("root:org:ws"), kubeClient, kcpClient, kcpClientFor, "missing", out)
+	require.Error(t, err)
+}
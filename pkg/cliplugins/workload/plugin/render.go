@@ -0,0 +1,133 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+//go:embed syncer-chart
+var embeddedChart embed.FS
+
+// OutputFormat is the format in which the syncer resources are written out.
+type OutputFormat string
+
+const (
+	// ManifestOutputFormat is a single multi-document YAML manifest.
+	ManifestOutputFormat OutputFormat = "manifest"
+	// HelmOutputFormat is a Helm chart directory whose values default to the syncer configuration.
+	HelmOutputFormat OutputFormat = "helm"
+	// KustomizeOutputFormat is a kustomize base directory with one file per resource.
+	KustomizeOutputFormat OutputFormat = "kustomize"
+)
+
+// OutputFormats are all supported output formats.
+var OutputFormats = []OutputFormat{ManifestOutputFormat, HelmOutputFormat, KustomizeOutputFormat}
+
+const (
+	chartRoot          = "syncer-chart"
+	chartTemplatesRoot = chartRoot + "/templates"
+)
+
+// renderSyncerHelmChart renders a Helm chart deploying the syncer. Chart.yaml and values.yaml
+// are rendered from the input, the chart templates are copied verbatim such that all
+// configuration can be overridden through values. It returns the file contents by relative path.
+func renderSyncerHelmChart(input templateInput) (map[string][]byte, error) {
+	args := newTemplateArgs(input)
+	files := map[string][]byte{}
+	err := fs.WalkDir(embeddedChart, chartRoot, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		content, err := embeddedChart.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		rel := strings.TrimPrefix(p, chartRoot+"/")
+		if !strings.HasPrefix(p, chartTemplatesRoot+"/") {
+			if content, err = renderTemplate(path.Base(p), content, args); err != nil {
+				return fmt.Errorf("failed to render %s: %w", rel, err)
+			}
+		}
+		files[rel] = content
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return files, nil
+}
+
+// renderSyncerKustomization splits the given syncer manifests into a kustomize base with one
+// file per resource, named after its kind, and a kustomization.yaml listing them in order.
+// It returns the file contents by relative path.
+func renderSyncerKustomization(manifests []byte) (map[string][]byte, error) {
+	files := map[string][]byte{}
+	kustomization := bytes.NewBufferString("apiVersion: kustomize.config.k8s.io/v1beta1\nkind: Kustomization\nresources:\n")
+	for _, doc := range strings.Split(string(manifests), "\n---\n") {
+		doc = strings.TrimPrefix(doc, "---\n")
+		objs, err := decodeManifests([]byte(doc))
+		if err != nil {
+			return nil, err
+		}
+		if len(objs) == 0 {
+			continue
+		}
+		if len(objs) > 1 {
+			return nil, fmt.Errorf("unexpected multi-document manifest %q", doc)
+		}
+
+		name := strings.ToLower(objs[0].GetKind()) + ".yaml"
+		if _, found := files[name]; found {
+			return nil, fmt.Errorf("duplicate %s in syncer manifests", objs[0].GetKind())
+		}
+		files[name] = []byte(strings.TrimSuffix(doc, "\n") + "\n")
+		fmt.Fprintf(kustomization, "- %s\n", name)
+	}
+	files["kustomization.yaml"] = kustomization.Bytes()
+	return files, nil
+}
+
+// writeFiles writes the given files by relative path below dir. As the files contain the token
+// of the syncer, they are only readable by the owner.
+func writeFiles(dir string, files map[string][]byte) error {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0700); err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(p, files[name], 0600); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"flag"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+var updateGoldenFiles = flag.Bool("update", false, "update the golden files in testdata")
+
+func goldenInput() templateInput {
+	return templateInput{
+		ServerURL:       "server-url",
+		Token:           "token",
+		CAData:          "ca-data",
+		KCPNamespace:    "kcp-namespace",
+		LogicalCluster:  "root:default:foo",
+		WorkloadCluster: "workload-cluster-name",
+		SyncerDeployment: SyncerDeployment{
+			Image:           "image",
+			Replicas:        1,
+			ResourcesToSync: []string{"deployments.apps", "resource1", "resource2"},
+			FeatureGates:    "KCPLocationAPI=true",
+			Requests:        map[string]string{"memory": "128Mi", "cpu": "100m"},
+			Limits:          map[string]string{"memory": "512Mi"},
+		},
+	}
+}
+
+func TestRenderSyncerHelmChart(t *testing.T) {
+	files, err := renderSyncerHelmChart(goldenInput())
+	require.NoError(t, err)
+	requireGoldenFiles(t, filepath.Join("testdata", "helm"), files)
+}
+
+func TestRenderSyncerKustomization(t *testing.T) {
+	manifests, err := renderSyncerResources(goldenInput())
+	require.NoError(t, err)
+	files, err := renderSyncerKustomization(manifests)
+	require.NoError(t, err)
+	requireGoldenFiles(t, filepath.Join("testdata", "kustomize"), files)
+}
+
+func TestWriteFiles(t *testing.T) {
+	dir := t.TempDir()
+	err := writeFiles(dir, map[string][]byte{
+		"Chart.yaml":          []byte("chart"),
+		"templates/foo.yaml":  []byte("foo"),
+		"templates/bar/a.txt": []byte("a"),
+	})
+	require.NoError(t, err)
+
+	content, err := ioutil.ReadFile(filepath.Join(dir, "templates", "bar", "a.txt"))
+	require.NoError(t, err)
+	require.Equal(t, "a", string(content))
+
+	info, err := os.Stat(filepath.Join(dir, "Chart.yaml"))
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0600), info.Mode().Perm())
+}
+
+// requireGoldenFiles compares the given files by relative path with the files below dir,
+// or rewrites dir with them if -update is passed.
+func requireGoldenFiles(t *testing.T, dir string, files map[string][]byte) {
+	t.Helper()
+
+	if *updateGoldenFiles {
+		require.NoError(t, os.RemoveAll(dir))
+		require.NoError(t, writeFiles(dir, files))
+		return
+	}
+
+	golden := map[string]string{}
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		content, err := ioutil.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		golden[filepath.ToSlash(rel)] = string(content)
+		return nil
+	})
+	require.NoError(t, err)
+
+	actual := map[string]string{}
+	for name, content := range files {
+		actual[name] = string(content)
+	}
+	require.Equal(t, golden, actual, "run with -update to regenerate the golden files")
+}
@@ -24,6 +24,7 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"sort"
 	"strings"
 	"text/template"
@@ -73,9 +74,31 @@ const (
 	SyncerIDPrefix = "kcpsync"
 )
 
+// SyncerDeployment describes how the syncer is deployed to the pcluster.
+type SyncerDeployment struct {
+	// Image is the name of the container image that the syncer deployment will use
+	Image string
+	// Replicas is the number of syncer pods to run (should be 0 or 1).
+	Replicas int
+	// ResourcesToSync is the set of qualified resource names (eg. ["services",
+	// "deployments.apps.k8s.io") that the syncer will synchronize between the kcp
+	// workspace and the pcluster.
+	ResourcesToSync []string
+	// FeatureGates is the comma separated list of key=value feature gates passed to the
+	// syncer, or empty to use the defaults.
+	FeatureGates string
+	// Requests are the compute resources (e.g. cpu=100m) requested by the syncer container.
+	Requests map[string]string
+	// Limits are the compute resource (e.g. memory=512Mi) limits of the syncer container.
+	Limits map[string]string
+}
+
 // Sync prepares a kcp workspace for use with a syncer and outputs the
-// configuration required to deploy a syncer to the pcluster to stdout.
-func (c *Config) Sync(ctx context.Context, workloadClusterName, kcpNamespaceName, image string, resourcesToSync []string, replicas int) error {
+// configuration required to deploy a syncer to the pcluster. The configuration is
+// written in the given outputFormat to output: a file ("-" for stdout, empty to skip)
+// for ManifestOutputFormat, a directory otherwise. If applyContext is set, the
+// configuration is also applied to the pcluster of that kubeconfig context.
+func (c *Config) Sync(ctx context.Context, workloadClusterName, kcpNamespaceName string, deployment SyncerDeployment, outputFormat OutputFormat, output, applyContext string) error {
 	config, err := clientcmd.NewDefaultClientConfig(*c.startingConfig, c.overrides).ClientConfig()
 	if err != nil {
 		return err
@@ -99,15 +122,13 @@ func (c *Config) Sync(ctx context.Context, workloadClusterName, kcpNamespaceName
 	serverURL := configURL.Scheme + "://" + configURL.Host
 
 	input := templateInput{
-		ServerURL:       serverURL,
-		CAData:          base64.StdEncoding.EncodeToString(config.CAData),
-		Token:           token,
-		KCPNamespace:    kcpNamespaceName,
-		LogicalCluster:  currentClusterName.String(),
-		WorkloadCluster: workloadClusterName,
-		Image:           image,
-		Replicas:        replicas,
-		ResourcesToSync: resourcesToSync,
+		ServerURL:        serverURL,
+		CAData:           base64.StdEncoding.EncodeToString(config.CAData),
+		Token:            token,
+		KCPNamespace:     kcpNamespaceName,
+		LogicalCluster:   currentClusterName.String(),
+		WorkloadCluster:  workloadClusterName,
+		SyncerDeployment: deployment,
 	}
 
 	resources, err := renderSyncerResources(input)
@@ -115,8 +136,41 @@ func (c *Config) Sync(ctx context.Context, workloadClusterName, kcpNamespaceName
 		return err
 	}
 
-	_, err = c.Out.Write(resources)
-	return err
+	switch {
+	case output == "":
+	case outputFormat == HelmOutputFormat || outputFormat == KustomizeOutputFormat:
+		var files map[string][]byte
+		if outputFormat == HelmOutputFormat {
+			files, err = renderSyncerHelmChart(input)
+		} else {
+			files, err = renderSyncerKustomization(resources)
+		}
+		if err != nil {
+			return err
+		}
+		if err := writeFiles(output, files); err != nil {
+			return fmt.Errorf("failed to write syncer %s output to %s: %w", outputFormat, output, err)
+		}
+	case output == "-":
+		if _, err := c.Out.Write(resources); err != nil {
+			return err
+		}
+	default:
+		// the resources contain the token of the syncer, hence only readable by the owner
+		if err := ioutil.WriteFile(output, resources, 0600); err != nil {
+			return fmt.Errorf("failed to write syncer resources to %s: %w", output, err)
+		}
+	}
+
+	if applyContext == "" {
+		return nil
+	}
+
+	pclusterConfig, err := clientcmd.NewDefaultClientConfig(*c.startingConfig, &clientcmd.ConfigOverrides{CurrentContext: applyContext}).ClientConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load kubeconfig context %q: %w", applyContext, err)
+	}
+	return applySyncerResources(ctx, pclusterConfig, resources, c.ErrOut)
 }
 
 // GetSyncerID returns the resource identifier of a syncer for the given logical
@@ -355,14 +409,8 @@ type templateInput struct {
 	// WorkloadCluster is the name of the workload cluster the syncer will use to
 	// communicate its status and read configuration from
 	WorkloadCluster string
-	// ResourcesToSync is the set of qualified resource names (eg. ["services",
-	// "deployments.apps.k8s.io") that the syncer will synchronize between the kcp
-	// workspace and the pcluster.
-	ResourcesToSync []string
-	// Image is the name of the container image that the syncer deployment will use
-	Image string
-	// Replicas is the number of syncer pods to run (should be 0 or 1).
-	Replicas int
+
+	SyncerDeployment
 }
 
 // templateArgs represents the full set of arguments required to render the resources
@@ -407,9 +455,18 @@ type templateArgs struct {
 // cluster role and role binding would be owned by the namespace to ensure cleanup on deletion
 // of the namespace.
 func renderSyncerResources(input templateInput) ([]byte, error) {
+	syncerTemplate, err := embeddedResources.ReadFile("syncer.yaml")
+	if err != nil {
+		return nil, err
+	}
+	return renderTemplate("syncerTemplate", syncerTemplate, newTemplateArgs(input))
+}
+
+// newTemplateArgs derives the full set of template arguments from the given input.
+func newTemplateArgs(input templateInput) templateArgs {
 	syncerID := GetSyncerID(input.LogicalCluster, input.WorkloadCluster)
 
-	tmplArgs := templateArgs{
+	return templateArgs{
 		templateInput:           input,
 		LabelSafeLogicalCluster: strings.ReplaceAll(input.LogicalCluster, ":", "_"),
 		Namespace:               syncerID,
@@ -422,18 +479,16 @@ func renderSyncerResources(input templateInput) ([]byte, error) {
 		Deployment:              SyncerResourceName,
 		DeploymentApp:           syncerID,
 	}
+}
 
-	syncerTemplate, err := embeddedResources.ReadFile("syncer.yaml")
-	if err != nil {
-		return nil, err
-	}
-	tmpl, err := template.New("syncerTemplate").Parse(string(syncerTemplate))
+// renderTemplate executes the given text template with args.
+func renderTemplate(name string, text []byte, args templateArgs) ([]byte, error) {
+	tmpl, err := template.New(name).Parse(string(text))
 	if err != nil {
 		return nil, err
 	}
 	buffer := bytes.NewBuffer([]byte{})
-	err = tmpl.Execute(buffer, tmplArgs)
-	if err != nil {
+	if err := tmpl.Execute(buffer, args); err != nil {
 		return nil, err
 	}
 	return buffer.Bytes(), nil
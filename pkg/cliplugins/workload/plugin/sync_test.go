@@ -51,6 +51,12 @@ rules:
   - "create"
   - "list"
   - "watch"
+- apiGroups:
+  - ""
+  resources:
+  - nodes
+  verbs:
+  - "list"
 - apiGroups:
   - "apiextensions.k8s.io"
   resources:
@@ -80,6 +86,36 @@ subjects:
   name: kcp-syncer
   namespace:  kcpsync25e6e3ce5be10b16411448aec95b6b6d695a1daa5120732019531d8d
 ---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: Role
+metadata:
+  name: kcp-syncer
+  namespace:  kcpsync25e6e3ce5be10b16411448aec95b6b6d695a1daa5120732019531d8d
+rules:
+- apiGroups:
+  - ""
+  resources:
+  - secrets
+  resourceNames:
+  - kcp-syncer-config
+  verbs:
+  - "get"
+  - "update"
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: RoleBinding
+metadata:
+  name: kcp-syncer
+  namespace:  kcpsync25e6e3ce5be10b16411448aec95b6b6d695a1daa5120732019531d8d
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: Role
+  name: kcp-syncer
+subjects:
+- kind: ServiceAccount
+  name: kcp-syncer
+  namespace:  kcpsync25e6e3ce5be10b16411448aec95b6b6d695a1daa5120732019531d8d
+---
 apiVersion: v1
 kind: Secret
 metadata:
@@ -129,6 +165,8 @@ spec:
         - /ko-app/syncer
         args:
         - --from-kubeconfig=/kcp/kubeconfig
+        - --from-kubeconfig-secret=kcpsync25e6e3ce5be10b16411448aec95b6b6d695a1daa5120732019531d8d/kcp-syncer-config
+        - --from-kubeconfig-secret-key=kubeconfig
         - --workload-cluster-name=workload-cluster-name
         - --from-cluster=root:default:foo
         - --resources=resource1
@@ -136,6 +174,17 @@ spec:
         image: image
         imagePullPolicy: IfNotPresent
         terminationMessagePolicy: FallbackToLogsOnError
+        ports:
+        - name: metrics
+          containerPort: 8080
+        livenessProbe:
+          httpGet:
+            path: /livez
+            port: metrics
+        readinessProbe:
+          httpGet:
+            path: /readyz
+            port: metrics
         volumeMounts:
         - name: kcp-config
           mountPath: /kcp/
@@ -154,9 +203,11 @@ spec:
 		KCPNamespace:    "kcp-namespace",
 		LogicalCluster:  "root:default:foo",
 		WorkloadCluster: "workload-cluster-name",
-		Image:           "image",
-		Replicas:        1,
-		ResourcesToSync: []string{"resource1", "resource2"},
+		SyncerDeployment: SyncerDeployment{
+			Image:           "image",
+			Replicas:        1,
+			ResourcesToSync: []string{"resource1", "resource2"},
+		},
 	})
 	require.NoError(t, err)
 	require.Equal(t, expectedYAML, string(actualYAML))
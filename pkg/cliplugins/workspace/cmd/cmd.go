@@ -17,7 +17,9 @@ limitations under the License.
 package cmd
 
 import (
+	"errors"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
@@ -55,6 +57,12 @@ var (
 
 	# create a context with the current workspace, named context-name
 	%[1]s workspace create-context context-name
+
+	# print a kubeconfig for the syncer virtual workspace of workload cluster my-cluster in the current workspace
+	%[1]s workspace virtual-kubeconfig syncer my-cluster --service-account=default/syncer-my-cluster
+
+	# dump the deployments and configmaps of the current workspace and its descendants to YAML files
+	%[1]s workspace dump --resources=deployments,configmaps --output-dir=dump --recursive
 `
 )
 
@@ -81,14 +89,31 @@ func New(streams genericclioptions.IOStreams) (*cobra.Command, error) {
 		}
 		return kubeconfig.UseWorkspace(cmd.Context(), arg)
 	}
+	completeWorkspaces := func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+
+		kubeconfig, err := plugin.NewKubeConfig(opts)
+		if err != nil {
+			return nil, cobra.ShellCompDirectiveError
+		}
+		completions, err := kubeconfig.CompleteWorkspaces(cmd.Context(), toComplete)
+		if err != nil && len(completions) == 0 {
+			return nil, cobra.ShellCompDirectiveError
+		}
+		// no space such that absolute paths can be continued with a colon
+		return completions, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace
+	}
 	cmd := &cobra.Command{
-		Aliases:          []string{"ws", "workspaces"},
-		Use:              "workspace [list|create|create-context|<workspace>|..|-|<root:absolute:workspace>]",
-		Short:            "Manages KCP workspaces",
-		Example:          fmt.Sprintf(workspaceExample, "kubectl kcp"),
-		SilenceUsage:     true,
-		TraverseChildren: true,
-		RunE:             useRunE,
+		Aliases:           []string{"ws", "workspaces"},
+		Use:               "workspace [list|create|create-context|<workspace>|..|-|<root:absolute:workspace>]",
+		Short:             "Manages KCP workspaces",
+		Example:           fmt.Sprintf(workspaceExample, "kubectl kcp"),
+		SilenceUsage:      true,
+		TraverseChildren:  true,
+		RunE:              useRunE,
+		ValidArgsFunction: completeWorkspaces,
 	}
 	opts.BindFlags(cmd)
 
@@ -102,6 +127,7 @@ func New(streams genericclioptions.IOStreams) (*cobra.Command, error) {
 			}
 			return useRunE(c, args)
 		},
+		ValidArgsFunction: completeWorkspaces,
 	}
 
 	var shortWorkspaceOutput bool
@@ -176,10 +202,12 @@ func New(streams genericclioptions.IOStreams) (*cobra.Command, error) {
 	}
 
 	var overwriteContext bool
+	var contextWorkspace string
 	createContextCmd := &cobra.Command{
-		Use:          "create-context [<context-name>] [--overwrite]",
-		Short:        "Create a kubeconfig context for the current workspace",
-		Example:      "kcp workspace create-context",
+		Use:          "create-context [<context-name>] [--workspace=<workspace>] [--overwrite]",
+		Short:        "Create a kubeconfig context for the current or the given workspace",
+		Long:         "Create a kubeconfig context for the current workspace, or for the given workspace path. The path is either \"..\", an absolute path like root:org:ws, or the name of a child workspace. The context name defaults to the workspace path.",
+		Example:      "kcp workspace create-context --workspace root:org:ws\n\nkubectl config use-context root:org:ws",
 		SilenceUsage: true,
 		RunE: func(c *cobra.Command, args []string) error {
 			if err := opts.Validate(); err != nil {
@@ -199,10 +227,60 @@ func New(streams genericclioptions.IOStreams) (*cobra.Command, error) {
 				arg = args[0]
 			}
 
-			return kubeconfig.CreateContext(cmd.Context(), arg, overwriteContext)
+			return kubeconfig.CreateContext(cmd.Context(), arg, contextWorkspace, overwriteContext)
 		},
 	}
 	createContextCmd.Flags().BoolVar(&overwriteContext, "overwrite", overwriteContext, "Overwrite the context if it already exists")
+	createContextCmd.Flags().StringVar(&contextWorkspace, "workspace", contextWorkspace, "The workspace path to create the context for (default: the current workspace)")
+
+	var serviceAccount string
+	virtualKubeconfigCmd := &cobra.Command{
+		Use:          "virtual-kubeconfig <" + strings.Join(plugin.VirtualWorkspaces.List(), "|") + "> <name> [--service-account=<namespace>/<name>]",
+		Short:        "Print a kubeconfig for a virtual workspace of the current workspace",
+		Long:         "Print a self-contained kubeconfig with embedded CA data for a virtual workspace of the current workspace. The name is the workload cluster for syncer, the APIExport for apiexport, the initializer for initializingworkspaces, and the scope for workspaces.",
+		Example:      "kcp workspace virtual-kubeconfig apiexport my-export > apiexport.kubeconfig",
+		SilenceUsage: true,
+		Args:         cobra.ExactArgs(2),
+		RunE: func(c *cobra.Command, args []string) error {
+			if err := opts.Validate(); err != nil {
+				return err
+			}
+			kubeconfig, err := plugin.NewKubeConfig(opts)
+			if err != nil {
+				return err
+			}
+			return kubeconfig.VirtualWorkspaceKubeConfig(c.Context(), args[0], args[1], serviceAccount)
+		},
+	}
+	virtualKubeconfigCmd.Flags().StringVar(&serviceAccount, "service-account", serviceAccount, "Use the token of this service account of the current workspace, in the form <namespace>/<name>, instead of the current credentials")
+
+	var dumpResources []string
+	dumpOutputDir := "."
+	var dumpRecursive bool
+	dumpCmd := &cobra.Command{
+		Use:          "dump --resources=<resource>[,<resource>...] [--output-dir=<dir>] [--recursive]",
+		Short:        "Dump the given resources of the current workspace as YAML files",
+		Long:         "Dump the objects of the given resources of the current workspace, and with --recursive of all its descendant workspaces, as YAML files. Every workspace gets a directory named after its logical cluster, containing a file per object at <namespace>/<resource>/<name>.yaml, or _cluster/<resource>/<name>.yaml for cluster-scoped objects. Server-populated fields like status, uid and resourceVersion are stripped such that the directory of a workspace can be applied again with \"kubectl apply -R -f\".",
+		Example:      "kcp workspace dump --resources=deployments,configmaps --output-dir=dump\n\nkubectl apply -R -f dump/root:org:ws",
+		SilenceUsage: true,
+		Args:         cobra.NoArgs,
+		RunE: func(c *cobra.Command, args []string) error {
+			if err := opts.Validate(); err != nil {
+				return err
+			}
+			if len(dumpResources) == 0 {
+				return errors.New("--resources is required")
+			}
+			kubeconfig, err := plugin.NewKubeConfig(opts)
+			if err != nil {
+				return err
+			}
+			return kubeconfig.DumpWorkspace(c.Context(), dumpResources, dumpOutputDir, dumpRecursive)
+		},
+	}
+	dumpCmd.Flags().StringSliceVar(&dumpResources, "resources", dumpResources, "The resources to dump, e.g. deployments or widgets.example.io")
+	dumpCmd.Flags().StringVar(&dumpOutputDir, "output-dir", dumpOutputDir, "The directory to write the YAML files to")
+	dumpCmd.Flags().BoolVar(&dumpRecursive, "recursive", dumpRecursive, "Also dump all descendant workspaces")
 
 	deleteCmd := &cobra.Command{
 		Use:          "delete",
@@ -220,6 +298,8 @@ func New(streams genericclioptions.IOStreams) (*cobra.Command, error) {
 	cmd.AddCommand(listCmd)
 	cmd.AddCommand(createCmd)
 	cmd.AddCommand(createContextCmd)
+	cmd.AddCommand(virtualKubeconfigCmd)
+	cmd.AddCommand(dumpCmd)
 	cmd.AddCommand(deleteCmd)
 	return cmd, nil
 }
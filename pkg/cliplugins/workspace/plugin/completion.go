@@ -0,0 +1,132 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kcp-dev/logicalcluster"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/clientcmd"
+
+	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+	pluginhelpers "github.com/kcp-dev/kcp/pkg/cliplugins/helpers"
+)
+
+const (
+	// completionTimeout bounds the time a completion waits for the server, such that the shell does not hang.
+	completionTimeout = 2 * time.Second
+	// completionCacheTTL is how long the child workspaces of a workspace are cached for completion.
+	completionCacheTTL = 30 * time.Second
+)
+
+// completionCacheEntry is the on-disk format of the cached child workspaces of a workspace.
+type completionCacheEntry struct {
+	Timestamp  time.Time `json:"timestamp"`
+	Workspaces []string  `json:"workspaces"`
+}
+
+// CompleteWorkspaces returns the workspace paths starting with toComplete, for shell completion.
+// Relative paths are completed with the child workspaces of the current workspace, and with "..", "-"
+// and the root workspace. Absolute paths, i.e. those containing a colon, are completed with the child
+// workspaces of the workspace before the last colon. The child workspaces are read from the workspaces
+// virtual workspace and cached for a short time.
+func (kc *KubeConfig) CompleteWorkspaces(ctx context.Context, toComplete string) ([]string, error) {
+	config, err := clientcmd.NewDefaultClientConfig(*kc.startingConfig, kc.overrides).ClientConfig()
+	if err != nil {
+		return nil, err
+	}
+	u, currentClusterName, err := pluginhelpers.ParseClusterURL(config.Host)
+	if err != nil {
+		return nil, fmt.Errorf("current URL %q does not point to cluster workspace", config.Host)
+	}
+
+	var candidates []string
+	parentClusterName, prefix := currentClusterName, ""
+	if parent, base := logicalcluster.New(toComplete).Split(); !parent.Empty() {
+		parentClusterName, prefix = parent, strings.TrimSuffix(toComplete, base)
+	} else {
+		candidates = append(candidates, "..", "-", tenancyv1alpha1.RootCluster.String())
+	}
+
+	children, err := kc.childWorkspaces(ctx, u.String(), parentClusterName)
+	for _, child := range children {
+		candidates = append(candidates, prefix+child)
+	}
+
+	var completions []string
+	for _, candidate := range candidates {
+		if strings.HasPrefix(candidate, toComplete) {
+			completions = append(completions, candidate)
+		}
+	}
+	return completions, err
+}
+
+// childWorkspaces returns the names of the workspaces in the given workspace visible to the current user,
+// using the completion cache if possible.
+func (kc *KubeConfig) childWorkspaces(ctx context.Context, server string, clusterName logicalcluster.Name) ([]string, error) {
+	var cacheFile string
+	if kc.completionCacheDir != "" {
+		var authInfo string
+		if currentContext, found := kc.startingConfig.Contexts[kc.currentContext]; found {
+			authInfo = currentContext.AuthInfo
+		}
+		key := sha256.Sum256([]byte(strings.Join([]string{server, authInfo, clusterName.String()}, "|")))
+		cacheFile = filepath.Join(kc.completionCacheDir, fmt.Sprintf("%x.json", key))
+
+		if bs, err := ioutil.ReadFile(cacheFile); err == nil {
+			var entry completionCacheEntry
+			if err := json.Unmarshal(bs, &entry); err == nil && time.Since(entry.Timestamp) < completionCacheTTL {
+				return entry.Workspaces, nil
+			}
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, completionTimeout)
+	defer cancel()
+	list, err := kc.personalClient.Cluster(clusterName).TenancyV1beta1().Workspaces().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(list.Items))
+	for _, ws := range list.Items {
+		names = append(names, ws.Name)
+	}
+	sort.Strings(names)
+
+	// the cache is best-effort, completion works without it
+	if cacheFile != "" {
+		if bs, err := json.Marshal(completionCacheEntry{Timestamp: time.Now(), Workspaces: names}); err == nil {
+			if err := os.MkdirAll(kc.completionCacheDir, 0700); err == nil {
+				_ = ioutil.WriteFile(cacheFile, bs, 0600)
+			}
+		}
+	}
+
+	return names, nil
+}
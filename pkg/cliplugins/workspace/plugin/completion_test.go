@@ -0,0 +1,129 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kcp-dev/logicalcluster"
+	"github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+
+	tenancyv1beta1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1beta1"
+	tenancyfake "github.com/kcp-dev/kcp/pkg/client/clientset/versioned/fake"
+)
+
+func TestCompleteWorkspaces(t *testing.T) {
+	config := clientcmdapi.Config{CurrentContext: "test",
+		Contexts:  map[string]*clientcmdapi.Context{"test": {Cluster: "test", AuthInfo: "test"}},
+		Clusters:  map[string]*clientcmdapi.Cluster{"test": {Server: "https://test/clusters/root:foo"}},
+		AuthInfos: map[string]*clientcmdapi.AuthInfo{"test": {Token: "test"}},
+	}
+	workspaces := func(names ...string) *tenancyfake.Clientset {
+		var objs []runtime.Object
+		for _, name := range names {
+			objs = append(objs, &tenancyv1beta1.Workspace{ObjectMeta: metav1.ObjectMeta{Name: name}})
+		}
+		return tenancyfake.NewSimpleClientset(objs...)
+	}
+
+	tests := []struct {
+		name       string
+		toComplete string
+		want       []string
+	}{
+		{name: "empty", toComplete: "", want: []string{"..", "-", "root", "bar", "baz"}},
+		{name: "root workspace", toComplete: "ro", want: []string{"root"}},
+		{name: "relative", toComplete: "ba", want: []string{"bar", "baz"}},
+		{name: "relative exact", toComplete: "bar", want: []string{"bar"}},
+		{name: "no match", toComplete: "x", want: nil},
+		{name: "absolute", toComplete: "root:", want: []string{"root:foo", "root:org"}},
+		{name: "absolute prefix", toComplete: "root:o", want: []string{"root:org"}},
+		{name: "nested absolute", toComplete: "root:foo:b", want: []string{"root:foo:bar", "root:foo:baz"}},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			kc := &KubeConfig{
+				startingConfig: config.DeepCopy(),
+				currentContext: config.CurrentContext,
+				personalClient: fakeTenancyClient{
+					t: t,
+					clients: map[logicalcluster.Name]*tenancyfake.Clientset{
+						logicalcluster.New("root"):     workspaces("org", "foo"),
+						logicalcluster.New("root:foo"): workspaces("baz", "bar"),
+					},
+				},
+				IOStreams: genericclioptions.NewTestIOStreamsDiscard(),
+			}
+
+			got, err := kc.CompleteWorkspaces(context.Background(), tt.toComplete)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestCompleteWorkspacesCache(t *testing.T) {
+	config := clientcmdapi.Config{CurrentContext: "test",
+		Contexts:  map[string]*clientcmdapi.Context{"test": {Cluster: "test", AuthInfo: "test"}},
+		Clusters:  map[string]*clientcmdapi.Cluster{"test": {Server: "https://test/clusters/root:foo"}},
+		AuthInfos: map[string]*clientcmdapi.AuthInfo{"test": {Token: "test"}},
+	}
+	clients := map[logicalcluster.Name]*tenancyfake.Clientset{
+		logicalcluster.New("root:foo"): tenancyfake.NewSimpleClientset(&tenancyv1beta1.Workspace{ObjectMeta: metav1.ObjectMeta{Name: "bar"}}),
+	}
+	cacheDir := t.TempDir()
+	kc := &KubeConfig{
+		startingConfig:     config.DeepCopy(),
+		currentContext:     config.CurrentContext,
+		personalClient:     fakeTenancyClient{t: t, clients: clients},
+		completionCacheDir: cacheDir,
+		IOStreams:          genericclioptions.NewTestIOStreamsDiscard(),
+	}
+
+	got, err := kc.CompleteWorkspaces(context.Background(), "b")
+	require.NoError(t, err)
+	require.Equal(t, []string{"bar"}, got)
+
+	// a fresh cache entry is used instead of the server
+	clients[logicalcluster.New("root:foo")] = tenancyfake.NewSimpleClientset(&tenancyv1beta1.Workspace{ObjectMeta: metav1.ObjectMeta{Name: "baz"}})
+	got, err = kc.CompleteWorkspaces(context.Background(), "b")
+	require.NoError(t, err)
+	require.Equal(t, []string{"bar"}, got)
+
+	// a stale cache entry is refreshed
+	files, err := filepath.Glob(filepath.Join(cacheDir, "*.json"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	bs, err := json.Marshal(completionCacheEntry{Timestamp: time.Now().Add(-completionCacheTTL), Workspaces: []string{"bar"}})
+	require.NoError(t, err)
+	require.NoError(t, ioutil.WriteFile(files[0], bs, 0600))
+
+	got, err = kc.CompleteWorkspaces(context.Background(), "b")
+	require.NoError(t, err)
+	require.Equal(t, []string{"baz"}, got)
+}
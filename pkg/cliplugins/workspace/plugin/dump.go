@@ -0,0 +1,170 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/kcp-dev/logicalcluster"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/restmapper"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/pager"
+	"sigs.k8s.io/yaml"
+
+	pluginhelpers "github.com/kcp-dev/kcp/pkg/cliplugins/helpers"
+)
+
+// clusterScopedDir is the directory of cluster-scoped objects next to the namespace directories of a workspace.
+const clusterScopedDir = "_cluster"
+
+// DumpWorkspace writes the objects of the given resources, e.g. "deployments" or "widgets.example.io", of the
+// current workspace as YAML files to outputDir, and with recursive also those of all descendant workspaces.
+// Every workspace gets its own directory named after its logical cluster, containing one file per object
+// at <namespace>/<resource>/<name>.yaml, or _cluster/<resource>/<name>.yaml for cluster-scoped objects,
+// such that it can be re-applied with "kubectl apply -R -f <output-dir>/<workspace>".
+func (kc *KubeConfig) DumpWorkspace(ctx context.Context, resources []string, outputDir string, recursive bool) error {
+	config, err := clientcmd.NewDefaultClientConfig(*kc.startingConfig, kc.overrides).ClientConfig()
+	if err != nil {
+		return err
+	}
+	u, currentClusterName, err := pluginhelpers.ParseClusterURL(config.Host)
+	if err != nil {
+		return fmt.Errorf("current URL %q does not point to cluster workspace", config.Host)
+	}
+
+	clusterNames := []logicalcluster.Name{currentClusterName}
+	for len(clusterNames) > 0 {
+		clusterName := clusterNames[0]
+		clusterNames = clusterNames[1:]
+
+		clusterConfig := rest.CopyConfig(config)
+		clusterURL := *u
+		clusterURL.Path = path.Join(u.Path, clusterName.Path())
+		clusterConfig.Host = clusterURL.String()
+
+		if err := dumpCluster(ctx, clusterConfig, resources, filepath.Join(outputDir, clusterName.String()), kc.Out); err != nil {
+			return fmt.Errorf("failed to dump workspace %q: %w", clusterName, err)
+		}
+
+		if !recursive {
+			continue
+		}
+		workspaces, err := kc.clusterClient.Cluster(clusterName).TenancyV1alpha1().ClusterWorkspaces().List(ctx, metav1.ListOptions{})
+		if err != nil {
+			return fmt.Errorf("failed to list child workspaces of %q: %w", clusterName, err)
+		}
+		for _, ws := range workspaces.Items {
+			clusterNames = append(clusterNames, clusterName.Join(ws.Name))
+		}
+	}
+
+	return nil
+}
+
+// dumpCluster writes the objects of the given resources of the cluster of the given config below dir.
+func dumpCluster(ctx context.Context, config *rest.Config, resources []string, dir string, out io.Writer) error {
+	discoveryClient, err := discovery.NewDiscoveryClientForConfig(config)
+	if err != nil {
+		return fmt.Errorf("failed to create discovery client: %w", err)
+	}
+	mapper := restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(discoveryClient))
+	dynamicClient, err := dynamic.NewForConfig(config)
+	if err != nil {
+		return fmt.Errorf("failed to create dynamic client: %w", err)
+	}
+
+	for _, resource := range resources {
+		gvr, err := mapper.ResourceFor(schema.ParseGroupResource(resource).WithVersion(""))
+		if err != nil {
+			return fmt.Errorf("failed to map resource %q: %w", resource, err)
+		}
+
+		// stream the objects page by page instead of holding all of them in memory
+		p := pager.New(func(ctx context.Context, opts metav1.ListOptions) (runtime.Object, error) {
+			return dynamicClient.Resource(gvr).List(ctx, opts)
+		})
+		count := 0
+		if err := p.EachListItem(ctx, metav1.ListOptions{}, func(obj runtime.Object) error {
+			u, ok := obj.(*unstructured.Unstructured)
+			if !ok {
+				return fmt.Errorf("unexpected type %T", obj)
+			}
+			count++
+			return writeObject(dir, gvr.GroupResource(), u)
+		}); err != nil {
+			return fmt.Errorf("failed to list %s: %w", gvr.GroupResource(), err)
+		}
+
+		if _, err := fmt.Fprintf(out, "Dumped %d %s to %s\n", count, gvr.GroupResource(), dir); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// writeObject writes the given object, stripped of server-populated fields, as YAML below dir.
+func writeObject(dir string, gr schema.GroupResource, obj *unstructured.Unstructured) error {
+	namespaceDir := obj.GetNamespace()
+	if namespaceDir == "" {
+		namespaceDir = clusterScopedDir
+	}
+	resourceDir := filepath.Join(dir, namespaceDir, gr.String())
+	if err := os.MkdirAll(resourceDir, 0755); err != nil {
+		return err
+	}
+
+	bs, err := yaml.Marshal(stripServerFields(obj).Object)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s %s: %w", gr, obj.GetName(), err)
+	}
+	return os.WriteFile(filepath.Join(resourceDir, obj.GetName()+".yaml"), bs, 0644)
+}
+
+// stripServerFields returns a copy of the given object without the fields populated by the server, which
+// would otherwise conflict or be meaningless when applying the object to another workspace.
+func stripServerFields(obj *unstructured.Unstructured) *unstructured.Unstructured {
+	obj = obj.DeepCopy()
+	unstructured.RemoveNestedField(obj.Object, "status")
+
+	obj.SetUID("")
+	obj.SetResourceVersion("")
+	obj.SetGeneration(0)
+	obj.SetDeletionTimestamp(nil)
+	obj.SetDeletionGracePeriodSeconds(nil)
+	obj.SetSelfLink("")
+	obj.SetManagedFields(nil)
+	obj.SetOwnerReferences(nil) // the owners' UIDs do not survive
+	obj.SetClusterName("")
+	unstructured.RemoveNestedField(obj.Object, "metadata", "creationTimestamp")
+
+	return obj
+}
@@ -0,0 +1,106 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/yaml"
+)
+
+func TestWriteObject(t *testing.T) {
+	tests := []struct {
+		name     string
+		resource schema.GroupResource
+		obj      map[string]interface{}
+
+		wantPath string
+		wantObj  map[string]interface{}
+	}{
+		{
+			name:     "namespaced",
+			resource: schema.GroupResource{Group: "apps", Resource: "deployments"},
+			obj: map[string]interface{}{
+				"apiVersion": "apps/v1",
+				"kind":       "Deployment",
+				"metadata": map[string]interface{}{
+					"name":              "web",
+					"namespace":         "default",
+					"uid":               "1234",
+					"resourceVersion":   "42",
+					"generation":        int64(3),
+					"creationTimestamp": "2022-01-01T00:00:00Z",
+					"clusterName":       "root:org:ws",
+					"labels":            map[string]interface{}{"app": "web"},
+					"managedFields":     []interface{}{map[string]interface{}{"manager": "kubectl"}},
+					"ownerReferences":   []interface{}{map[string]interface{}{"apiVersion": "v1", "kind": "Foo", "name": "foo", "uid": "5678"}},
+				},
+				"spec":   map[string]interface{}{"replicas": int64(1)},
+				"status": map[string]interface{}{"readyReplicas": int64(1)},
+			},
+			wantPath: "default/deployments.apps/web.yaml",
+			wantObj: map[string]interface{}{
+				"apiVersion": "apps/v1",
+				"kind":       "Deployment",
+				"metadata": map[string]interface{}{
+					"name":      "web",
+					"namespace": "default",
+					"labels":    map[string]interface{}{"app": "web"},
+				},
+				"spec": map[string]interface{}{"replicas": float64(1)},
+			},
+		},
+		{
+			name:     "cluster-scoped",
+			resource: schema.GroupResource{Group: "example.io", Resource: "widgets"},
+			obj: map[string]interface{}{
+				"apiVersion": "example.io/v1",
+				"kind":       "Widget",
+				"metadata":   map[string]interface{}{"name": "foo", "resourceVersion": "42"},
+			},
+			wantPath: "_cluster/widgets.example.io/foo.yaml",
+			wantObj: map[string]interface{}{
+				"apiVersion": "example.io/v1",
+				"kind":       "Widget",
+				"metadata":   map[string]interface{}{"name": "foo"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			obj := &unstructured.Unstructured{Object: tt.obj}
+			original := obj.DeepCopy()
+
+			err := writeObject(dir, tt.resource, obj)
+			require.NoError(t, err)
+			require.Equal(t, original, obj, "object must not be mutated")
+
+			bs, err := os.ReadFile(filepath.Join(dir, tt.wantPath))
+			require.NoError(t, err)
+			var got map[string]interface{}
+			require.NoError(t, yaml.Unmarshal(bs, &got))
+			require.Equal(t, tt.wantObj, got)
+		})
+	}
+}
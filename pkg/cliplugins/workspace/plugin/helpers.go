@@ -19,7 +19,7 @@ package plugin
 import (
 	"context"
 	"fmt"
-	"path"
+	"net/url"
 
 	"github.com/kcp-dev/logicalcluster"
 
@@ -28,6 +28,7 @@ import (
 
 	tenancyv1beta1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1beta1"
 	tenancyclient "github.com/kcp-dev/kcp/pkg/client/clientset/versioned"
+	pluginhelpers "github.com/kcp-dev/kcp/pkg/cliplugins/helpers"
 )
 
 // getWorkspaceFromInternalName retrieves the workspace with this internal name in the
@@ -47,11 +48,30 @@ func getWorkspaceFromInternalName(ctx context.Context, workspaceInternalName str
 }
 
 type personalClusterClient struct {
-	config *rest.Config
+	config        *rest.Config
+	clusterClient tenancyclient.ClusterInterface
 }
 
+// Cluster returns a client of the personal scope of the workspaces virtual workspace of the given workspace.
+// It is served at the virtual workspace URL of the shard of the workspace, or at the kcp server if that is
+// not known or cannot be determined.
 func (c *personalClusterClient) Cluster(cluster logicalcluster.Name) tenancyclient.Interface {
 	virtualConfig := rest.CopyConfig(c.config)
-	virtualConfig.Host += path.Join("/services/workspaces", cluster.String(), "personal")
+	if server, err := url.Parse(c.config.Host); err == nil {
+		base, err := pluginhelpers.VirtualWorkspaceBaseURL(context.TODO(), kcpClientFor(c.clusterClient), cluster, server)
+		if err != nil {
+			base = server
+		}
+		if host, err := VirtualWorkspaceURL(base, VirtualWorkspaceWorkspaces, cluster, "personal"); err == nil {
+			virtualConfig.Host = host
+		}
+	}
 	return tenancyclient.NewForConfigOrDie(virtualConfig)
 }
+
+// kcpClientFor returns a func returning the clients of the given cluster client for pluginhelpers.
+func kcpClientFor(clusterClient tenancyclient.ClusterInterface) func(logicalcluster.Name) (tenancyclient.Interface, error) {
+	return func(clusterName logicalcluster.Name) (tenancyclient.Interface, error) {
+		return clusterClient.Cluster(clusterName), nil
+	}
+}
@@ -23,6 +23,7 @@ import (
 	"net/http"
 	"net/url"
 	"path"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -34,10 +35,13 @@ import (
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"k8s.io/cli-runtime/pkg/printers"
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	"k8s.io/client-go/util/homedir"
 
+	tenancypath "github.com/kcp-dev/kcp/pkg/apis/tenancy/path"
 	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
 	tenancyv1beta1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1beta1"
 	tenancyclient "github.com/kcp-dev/kcp/pkg/client/clientset/versioned"
@@ -57,9 +61,13 @@ type KubeConfig struct {
 	overrides      *clientcmd.ConfigOverrides
 	currentContext string // including override
 
-	clusterClient  tenancyclient.ClusterInterface
-	personalClient tenancyclient.ClusterInterface
-	modifyConfig   func(newConfig *clientcmdapi.Config) error
+	clusterClient     tenancyclient.ClusterInterface
+	personalClient    tenancyclient.ClusterInterface
+	kubeClusterClient kubernetes.ClusterInterface
+	modifyConfig      func(newConfig *clientcmdapi.Config) error
+
+	// completionCacheDir is where the child workspaces are cached for shell completion. Empty disables caching.
+	completionCacheDir string
 
 	genericclioptions.IOStreams
 }
@@ -95,17 +103,23 @@ func NewKubeConfig(opts *Options) (*KubeConfig, error) {
 	if err != nil {
 		return nil, err
 	}
+	kubeClusterClient, err := kubernetes.NewClusterForConfig(clusterConfig)
+	if err != nil {
+		return nil, err
+	}
 
 	return &KubeConfig{
 		startingConfig: startingConfig,
 		overrides:      opts.KubectlOverrides,
 		currentContext: currentContext,
 
-		clusterClient:  clusterClient,
-		personalClient: &personalClusterClient{clusterConfig},
+		clusterClient:     clusterClient,
+		personalClient:    &personalClusterClient{config: clusterConfig, clusterClient: clusterClient},
+		kubeClusterClient: kubeClusterClient,
 		modifyConfig: func(newConfig *clientcmdapi.Config) error {
 			return clientcmd.ModifyConfig(configAccess, *newConfig, true)
 		},
+		completionCacheDir: filepath.Join(homedir.HomeDir(), ".kube", "cache", "kcp", "workspaces"),
 
 		IOStreams: opts.IOStreams,
 	}, nil
@@ -161,25 +175,6 @@ func (kc *KubeConfig) UseWorkspace(ctx context.Context, name string) error {
 
 		return kc.currentWorkspace(ctx, newKubeConfig.Clusters[newKubeConfig.Contexts[kcpCurrentWorkspaceContextKey].Cluster].Server, "", false)
 
-	case "..":
-		config, err := clientcmd.NewDefaultClientConfig(*kc.startingConfig, kc.overrides).ClientConfig()
-		if err != nil {
-			return err
-		}
-		u, currentClusterName, err := pluginhelpers.ParseClusterURL(config.Host)
-		if err != nil {
-			return fmt.Errorf("current URL %q does not point to cluster workspace", config.Host)
-		}
-		parentClusterName, hasParent := currentClusterName.Parent()
-		if !hasParent {
-			if currentClusterName == tenancyv1alpha1.RootCluster {
-				return fmt.Errorf("current workspace is %q", currentClusterName)
-			}
-			return fmt.Errorf("current workspace %q has no parent", currentClusterName)
-		}
-		u.Path = path.Join(u.Path, parentClusterName.Path())
-		newServerHost = u.String()
-
 	case "":
 		return kc.CurrentWorkspace(ctx, false)
 
@@ -188,27 +183,9 @@ func (kc *KubeConfig) UseWorkspace(ctx context.Context, name string) error {
 		if err != nil {
 			return err
 		}
-		u, currentClusterName, err := pluginhelpers.ParseClusterURL(config.Host)
+		newServerHost, workspaceType, err = kc.resolveWorkspaceURL(ctx, config.Host, name)
 		if err != nil {
-			return fmt.Errorf("current URL %q does not point to cluster workspace", config.Host)
-		}
-
-		if strings.Contains(name, ":") || name == tenancyv1alpha1.RootCluster.String() {
-			// absolute logical cluster
-			u.Path = path.Join(u.Path, logicalcluster.New(name).Path())
-			newServerHost = u.String()
-		} else {
-			// relative logical cluster, get URL from workspace object in current context
-			ws, err := kc.personalClient.Cluster(currentClusterName).TenancyV1beta1().Workspaces().Get(ctx, name, metav1.GetOptions{})
-			if err != nil {
-				return err
-			}
-			if ws.Status.Phase != tenancyv1alpha1.ClusterWorkspacePhaseReady {
-				return fmt.Errorf("workspace %q is not ready", name)
-			}
-
-			newServerHost = ws.Status.URL
-			workspaceType = ws.Spec.Type
+			return err
 		}
 	}
 
@@ -242,6 +219,47 @@ func (kc *KubeConfig) UseWorkspace(ctx context.Context, name string) error {
 	return kc.currentWorkspace(ctx, newServerHost, workspaceType, false)
 }
 
+// resolveWorkspaceURL returns the server URL of the workspace with the given
+// name, relative to the workspace host points to. The name is either "..",
+// an absolute logical cluster name like "root:org:ws", or the name of a child
+// workspace of the current one. The workspace type is only returned for child
+// workspaces.
+func (kc *KubeConfig) resolveWorkspaceURL(ctx context.Context, host, name string) (string, string, error) {
+	u, currentClusterName, err := pluginhelpers.ParseClusterURL(host)
+	if err != nil {
+		return "", "", fmt.Errorf("current URL %q does not point to cluster workspace", host)
+	}
+
+	if name == ".." {
+		parentClusterName, hasParent := currentClusterName.Parent()
+		if !hasParent {
+			if currentClusterName == tenancyv1alpha1.RootCluster {
+				return "", "", fmt.Errorf("current workspace is %q", currentClusterName)
+			}
+			return "", "", fmt.Errorf("current workspace %q has no parent", currentClusterName)
+		}
+		u.Path = path.Join(u.Path, parentClusterName.Path())
+		return u.String(), "", nil
+	}
+
+	if tenancypath.IsAbsolute(name) {
+		// absolute logical cluster
+		u.Path = path.Join(u.Path, logicalcluster.New(name).Path())
+		return u.String(), "", nil
+	}
+
+	// relative logical cluster, get URL from workspace object in current context
+	ws, err := kc.personalClient.Cluster(currentClusterName).TenancyV1beta1().Workspaces().Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return "", "", err
+	}
+	if ws.Status.Phase != tenancyv1alpha1.ClusterWorkspacePhaseReady {
+		return "", "", fmt.Errorf("workspace %q is not ready", name)
+	}
+
+	return ws.Status.URL, ws.Spec.Type, nil
+}
+
 // CurrentWorkspace outputs the current workspace.
 func (kc *KubeConfig) CurrentWorkspace(ctx context.Context, shortWorkspaceOutput bool) error {
 	config, err := clientcmd.NewDefaultClientConfig(*kc.startingConfig, kc.overrides).ClientConfig()
@@ -417,7 +435,10 @@ func (kc *KubeConfig) ListWorkspaces(ctx context.Context, opts *Options) error {
 	return printer.PrintObj(table, opts.Out)
 }
 
-func (kc *KubeConfig) CreateContext(ctx context.Context, name string, overwrite bool) error {
+// CreateContext creates a kubeconfig context with the given name for the
+// current workspace, or for the given workspace if not empty, using the
+// cluster and user of the current context. It then switches to that context.
+func (kc *KubeConfig) CreateContext(ctx context.Context, name, workspace string, overwrite bool) error {
 	config, err := clientcmd.NewDefaultClientConfig(*kc.startingConfig, nil).RawConfig()
 	if err != nil {
 		return err
@@ -435,6 +456,17 @@ func (kc *KubeConfig) CreateContext(ctx context.Context, name string, overwrite
 		return fmt.Errorf("current URL %q does not point to cluster workspace", currentCluster.Server)
 	}
 
+	newCluster := *currentCluster
+	if workspace != "" {
+		newCluster.Server, _, err = kc.resolveWorkspaceURL(ctx, currentCluster.Server, workspace)
+		if err != nil {
+			return err
+		}
+		if _, currentClusterName, err = pluginhelpers.ParseClusterURL(newCluster.Server); err != nil {
+			return fmt.Errorf("workspace URL %q does not point to cluster workspace", newCluster.Server)
+		}
+	}
+
 	if name == "" {
 		name = currentClusterName.String()
 	}
@@ -445,7 +477,6 @@ func (kc *KubeConfig) CreateContext(ctx context.Context, name string, overwrite
 	}
 
 	newKubeConfig := kc.startingConfig.DeepCopy()
-	newCluster := *currentCluster
 	newKubeConfig.Clusters[name] = &newCluster
 	newContext := *currentContext
 	newContext.Cluster = name
@@ -709,6 +709,7 @@ func TestCreateContext(t *testing.T) {
 		overrides *clientcmd.ConfigOverrides
 
 		param     string
+		workspace string
 		overwrite bool
 
 		expected   *clientcmdapi.Config
@@ -866,6 +867,59 @@ func TestCreateContext(t *testing.T) {
 			},
 			wantStdout: []string{"Created context \"root:foo:bar\" and switched to it."},
 		},
+		{
+			name: "absolute workspace, no arg",
+			config: clientcmdapi.Config{CurrentContext: "workspace.kcp.dev/current",
+				Contexts:  map[string]*clientcmdapi.Context{"workspace.kcp.dev/current": {Cluster: "workspace.kcp.dev/current", AuthInfo: "test"}},
+				Clusters:  map[string]*clientcmdapi.Cluster{"workspace.kcp.dev/current": {Server: "https://test/clusters/root:foo:bar"}},
+				AuthInfos: map[string]*clientcmdapi.AuthInfo{"test": {Token: "test"}},
+			},
+			workspace: "root:baz",
+			expected: &clientcmdapi.Config{CurrentContext: "root:baz",
+				Contexts: map[string]*clientcmdapi.Context{
+					"workspace.kcp.dev/current": {Cluster: "workspace.kcp.dev/current", AuthInfo: "test"},
+					"root:baz":                  {Cluster: "root:baz", AuthInfo: "test"},
+				},
+				Clusters: map[string]*clientcmdapi.Cluster{
+					"workspace.kcp.dev/current": {Server: "https://test/clusters/root:foo:bar"},
+					"root:baz":                  {Server: "https://test/clusters/root:baz"},
+				},
+				AuthInfos: map[string]*clientcmdapi.AuthInfo{"test": {Token: "test"}},
+			},
+			wantStdout: []string{"Created context \"root:baz\" and switched to it."},
+		},
+		{
+			name: "parent workspace, with arg",
+			config: clientcmdapi.Config{CurrentContext: "workspace.kcp.dev/current",
+				Contexts:  map[string]*clientcmdapi.Context{"workspace.kcp.dev/current": {Cluster: "workspace.kcp.dev/current", AuthInfo: "test"}},
+				Clusters:  map[string]*clientcmdapi.Cluster{"workspace.kcp.dev/current": {Server: "https://test/clusters/root:foo:bar"}},
+				AuthInfos: map[string]*clientcmdapi.AuthInfo{"test": {Token: "test"}},
+			},
+			param:     "foo",
+			workspace: "..",
+			expected: &clientcmdapi.Config{CurrentContext: "foo",
+				Contexts: map[string]*clientcmdapi.Context{
+					"workspace.kcp.dev/current": {Cluster: "workspace.kcp.dev/current", AuthInfo: "test"},
+					"foo":                       {Cluster: "foo", AuthInfo: "test"},
+				},
+				Clusters: map[string]*clientcmdapi.Cluster{
+					"workspace.kcp.dev/current": {Server: "https://test/clusters/root:foo:bar"},
+					"foo":                       {Server: "https://test/clusters/root:foo"},
+				},
+				AuthInfos: map[string]*clientcmdapi.AuthInfo{"test": {Token: "test"}},
+			},
+			wantStdout: []string{"Created context \"foo\" and switched to it."},
+		},
+		{
+			name: "parent workspace of root",
+			config: clientcmdapi.Config{CurrentContext: "workspace.kcp.dev/current",
+				Contexts:  map[string]*clientcmdapi.Context{"workspace.kcp.dev/current": {Cluster: "workspace.kcp.dev/current", AuthInfo: "test"}},
+				Clusters:  map[string]*clientcmdapi.Cluster{"workspace.kcp.dev/current": {Server: "https://test/clusters/root"}},
+				AuthInfos: map[string]*clientcmdapi.AuthInfo{"test": {Token: "test"}},
+			},
+			workspace: "..",
+			wantErr:   true,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -889,7 +943,7 @@ func TestCreateContext(t *testing.T) {
 				},
 				IOStreams: streams,
 			}
-			err := kc.CreateContext(context.Background(), tt.param, tt.overwrite)
+			err := kc.CreateContext(context.Background(), tt.param, tt.workspace, tt.overwrite)
 			if tt.wantErr {
 				require.Error(t, err)
 			} else {
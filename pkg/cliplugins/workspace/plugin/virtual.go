@@ -0,0 +1,251 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/kcp-dev/logicalcluster"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+
+	virtualcommandoptions "github.com/kcp-dev/kcp/cmd/virtual-workspaces/options"
+	pluginhelpers "github.com/kcp-dev/kcp/pkg/cliplugins/helpers"
+)
+
+const (
+	// VirtualWorkspaceSyncer is the virtual workspace serving the APIs of a workload cluster to its syncer.
+	VirtualWorkspaceSyncer = "syncer"
+	// VirtualWorkspaceAPIExport is the virtual workspace serving the resources bound through an APIExport to its provider.
+	VirtualWorkspaceAPIExport = "apiexport"
+	// VirtualWorkspaceInitializingWorkspaces is the virtual workspace serving workspaces in the initializing phase
+	// to the controller owning an initializer.
+	VirtualWorkspaceInitializingWorkspaces = "initializingworkspaces"
+	// VirtualWorkspaceWorkspaces is the virtual workspace serving the workspaces of a user.
+	VirtualWorkspaceWorkspaces = "workspaces"
+)
+
+// VirtualWorkspaces is the list of virtual workspaces a kubeconfig can be generated for.
+var VirtualWorkspaces = sets.NewString(
+	VirtualWorkspaceSyncer,
+	VirtualWorkspaceAPIExport,
+	VirtualWorkspaceInitializingWorkspaces,
+	VirtualWorkspaceWorkspaces,
+)
+
+// VirtualWorkspaceURL returns the URL of the given virtual workspace, relative to the base URL of a kcp server
+// (i.e. without /clusters/<name> path) or the virtual workspace URL of a shard, for the given workspace and
+// virtual workspace specific name:
+//
+//   - syncer: the name of the workload cluster.
+//   - apiexport: the name of the APIExport.
+//   - initializingworkspaces: the initializer. The workspace is ignored because initializers are global.
+//   - workspaces: the scope, i.e. "personal" or "all".
+func VirtualWorkspaceURL(base *url.URL, virtualWorkspace string, clusterName logicalcluster.Name, name string) (string, error) {
+	if name == "" {
+		return "", fmt.Errorf("a name is required for the %s virtual workspace", virtualWorkspace)
+	}
+
+	u := *base
+	switch virtualWorkspace {
+	case VirtualWorkspaceSyncer, VirtualWorkspaceAPIExport:
+		u.Path = path.Join(u.Path, virtualcommandoptions.DefaultRootPathPrefix, virtualWorkspace, clusterName.String(), name)
+	case VirtualWorkspaceInitializingWorkspaces:
+		u.Path = path.Join(u.Path, virtualcommandoptions.DefaultRootPathPrefix, virtualWorkspace, name)
+	case VirtualWorkspaceWorkspaces:
+		if name != "personal" && name != "all" {
+			return "", fmt.Errorf("the scope of the workspaces virtual workspace should be either 'personal' or 'all'")
+		}
+		u.Path = path.Join(u.Path, virtualcommandoptions.DefaultRootPathPrefix, virtualWorkspace, clusterName.String(), name)
+	default:
+		return "", fmt.Errorf("unknown virtual workspace %q, must be one of %s", virtualWorkspace, strings.Join(VirtualWorkspaces.List(), ", "))
+	}
+
+	return u.String(), nil
+}
+
+// VirtualWorkspaceKubeConfig outputs a self-contained kubeconfig pointing to the given virtual workspace
+// of the current workspace, at the virtual workspace URL of its shard or, for the syncer, at the URL
+// advertised by the WorkloadCluster. The CA data is embedded. The credentials are either those of the current
+// context with all referenced files inlined, or, if serviceAccount is set in the form <namespace>/<name>,
+// the token of that service account in the current workspace.
+func (kc *KubeConfig) VirtualWorkspaceKubeConfig(ctx context.Context, virtualWorkspace, name, serviceAccount string) error {
+	config, err := clientcmd.NewDefaultClientConfig(*kc.startingConfig, kc.overrides).ClientConfig()
+	if err != nil {
+		return err
+	}
+	u, currentClusterName, err := pluginhelpers.ParseClusterURL(config.Host)
+	if err != nil {
+		return fmt.Errorf("current URL %q does not point to cluster workspace", config.Host)
+	}
+
+	base, err := pluginhelpers.VirtualWorkspaceBaseURL(ctx, kcpClientFor(kc.clusterClient), currentClusterName, u)
+	if err != nil {
+		return err
+	}
+	server, err := VirtualWorkspaceURL(base, virtualWorkspace, currentClusterName, name)
+	if err != nil {
+		return err
+	}
+	if virtualWorkspace == VirtualWorkspaceSyncer {
+		if server, err = kc.syncerVirtualWorkspaceURL(ctx, currentClusterName, name, server); err != nil {
+			return err
+		}
+	}
+
+	cluster := &clientcmdapi.Cluster{
+		Server:                server,
+		InsecureSkipTLSVerify: config.Insecure,
+		TLSServerName:         config.ServerName,
+	}
+	if !config.Insecure {
+		if cluster.CertificateAuthorityData, err = dataOrFile(config.CAData, config.CAFile); err != nil {
+			return fmt.Errorf("failed to read certificate authority: %w", err)
+		}
+	}
+
+	var authInfo *clientcmdapi.AuthInfo
+	if serviceAccount != "" {
+		if authInfo, err = kc.serviceAccountAuthInfo(ctx, currentClusterName, serviceAccount); err != nil {
+			return err
+		}
+	} else if authInfo, err = authInfoFromConfig(config); err != nil {
+		return err
+	}
+
+	contextName := virtualWorkspace + ":" + currentClusterName.String() + ":" + name
+	if virtualWorkspace == VirtualWorkspaceInitializingWorkspaces {
+		contextName = virtualWorkspace + ":" + name
+	}
+	newKubeConfig := &clientcmdapi.Config{
+		Clusters:       map[string]*clientcmdapi.Cluster{contextName: cluster},
+		AuthInfos:      map[string]*clientcmdapi.AuthInfo{contextName: authInfo},
+		Contexts:       map[string]*clientcmdapi.Context{contextName: {Cluster: contextName, AuthInfo: contextName}},
+		CurrentContext: contextName,
+	}
+
+	bs, err := clientcmd.Write(*newKubeConfig)
+	if err != nil {
+		return err
+	}
+	_, err = kc.Out.Write(bs)
+	return err
+}
+
+// syncerVirtualWorkspaceURL returns the syncer virtual workspace URL advertised in the status of the given
+// WorkloadCluster. This is the given URL if it is one of them, or if the WorkloadCluster does not advertise
+// any URL (yet) or cannot be read. Otherwise it is the first advertised URL.
+func (kc *KubeConfig) syncerVirtualWorkspaceURL(ctx context.Context, clusterName logicalcluster.Name, name, server string) (string, error) {
+	cluster, err := kc.clusterClient.Cluster(clusterName).WorkloadV1alpha1().WorkloadClusters().Get(ctx, name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) || apierrors.IsForbidden(err) {
+		return server, nil
+	} else if err != nil {
+		return "", fmt.Errorf("failed to get WorkloadCluster %s|%s: %w", clusterName, name, err)
+	}
+	if len(cluster.Status.VirtualWorkspaces) == 0 {
+		return server, nil
+	}
+	for _, vw := range cluster.Status.VirtualWorkspaces {
+		if vw.URL == server {
+			return server, nil
+		}
+	}
+	return cluster.Status.VirtualWorkspaces[0].URL, nil
+}
+
+// serviceAccountAuthInfo returns an AuthInfo with the token of the given service account in the given workspace.
+func (kc *KubeConfig) serviceAccountAuthInfo(ctx context.Context, clusterName logicalcluster.Name, serviceAccount string) (*clientcmdapi.AuthInfo, error) {
+	parts := strings.SplitN(serviceAccount, "/", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return nil, fmt.Errorf("service account %q must be of the form <namespace>/<name>", serviceAccount)
+	}
+	namespace, name := parts[0], parts[1]
+
+	kubeClient := kc.kubeClusterClient.Cluster(clusterName)
+	sa, err := kubeClient.CoreV1().ServiceAccounts(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ServiceAccount %s|%s/%s: %w", clusterName, namespace, name, err)
+	}
+	if len(sa.Secrets) == 0 {
+		return nil, fmt.Errorf("ServiceAccount %s|%s/%s has no token secret yet", clusterName, namespace, name)
+	}
+
+	secret, err := kubeClient.CoreV1().Secrets(namespace).Get(ctx, sa.Secrets[0].Name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get token Secret %s|%s/%s: %w", clusterName, namespace, sa.Secrets[0].Name, err)
+	}
+	token := secret.Data["token"]
+	if len(token) == 0 {
+		return nil, fmt.Errorf("token secret %s|%s/%s is missing a value for `token`", clusterName, namespace, secret.Name)
+	}
+
+	return &clientcmdapi.AuthInfo{Token: string(token)}, nil
+}
+
+// authInfoFromConfig turns the credentials of a rest config into a self-contained AuthInfo.
+func authInfoFromConfig(config *rest.Config) (*clientcmdapi.AuthInfo, error) {
+	authInfo := &clientcmdapi.AuthInfo{
+		Token:                 config.BearerToken,
+		TokenFile:             config.BearerTokenFile,
+		Username:              config.Username,
+		Password:              config.Password,
+		Impersonate:           config.Impersonate.UserName,
+		ImpersonateUID:        config.Impersonate.UID,
+		ImpersonateGroups:     config.Impersonate.Groups,
+		ImpersonateUserExtra:  config.Impersonate.Extra,
+		AuthProvider:          config.AuthProvider,
+		Exec:                  config.ExecProvider,
+		ClientCertificateData: config.CertData,
+		ClientKeyData:         config.KeyData,
+	}
+
+	if authInfo.Token == "" && authInfo.TokenFile != "" {
+		token, err := ioutil.ReadFile(authInfo.TokenFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read token file: %w", err)
+		}
+		authInfo.Token = strings.TrimSpace(string(token))
+		authInfo.TokenFile = ""
+	}
+
+	var err error
+	if authInfo.ClientCertificateData, err = dataOrFile(config.CertData, config.CertFile); err != nil {
+		return nil, fmt.Errorf("failed to read client certificate: %w", err)
+	}
+	if authInfo.ClientKeyData, err = dataOrFile(config.KeyData, config.KeyFile); err != nil {
+		return nil, fmt.Errorf("failed to read client key: %w", err)
+	}
+
+	return authInfo, nil
+}
+
+func dataOrFile(data []byte, file string) ([]byte, error) {
+	if len(data) > 0 || file == "" {
+		return data, nil
+	}
+	return ioutil.ReadFile(file)
+}
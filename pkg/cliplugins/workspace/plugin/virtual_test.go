@@ -0,0 +1,232 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kcp-dev/logicalcluster"
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/kubernetes"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+
+	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/workload/v1alpha1"
+	tenancyfake "github.com/kcp-dev/kcp/pkg/client/clientset/versioned/fake"
+)
+
+func TestVirtualWorkspaceKubeConfig(t *testing.T) {
+	startingConfig := clientcmdapi.Config{CurrentContext: "test",
+		Contexts:  map[string]*clientcmdapi.Context{"test": {Cluster: "test", AuthInfo: "test"}},
+		Clusters:  map[string]*clientcmdapi.Cluster{"test": {Server: "https://test/clusters/root:foo", CertificateAuthorityData: []byte("ca")}},
+		AuthInfos: map[string]*clientcmdapi.AuthInfo{"test": {Token: "user-token"}},
+	}
+	externalShard := []runtime.Object{
+		&tenancyv1alpha1.ClusterWorkspaceShard{
+			ObjectMeta: metav1.ObjectMeta{Name: "external"},
+			Spec:       tenancyv1alpha1.ClusterWorkspaceShardSpec{BaseURL: "https://shard", VirtualWorkspaceURL: "https://virtual"},
+		},
+		&tenancyv1alpha1.ClusterWorkspace{
+			ObjectMeta: metav1.ObjectMeta{Name: "foo"},
+			Status:     tenancyv1alpha1.ClusterWorkspaceStatus{Location: tenancyv1alpha1.ClusterWorkspaceLocation{Current: "external"}},
+		},
+	}
+	advertisingWorkloadCluster := []runtime.Object{
+		&workloadv1alpha1.WorkloadCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "us-east1"},
+			Status: workloadv1alpha1.WorkloadClusterStatus{VirtualWorkspaces: []workloadv1alpha1.VirtualWorkspace{
+				{URL: "https://virtual/services/syncer/root:foo/us-east1"},
+			}},
+		},
+	}
+
+	tests := []struct {
+		name             string
+		virtualWorkspace string
+		param            string
+		serviceAccount   string
+		objects          []runtime.Object
+		rootKcpObjects   []runtime.Object
+		kcpObjects       []runtime.Object
+
+		wantContext string
+		wantServer  string
+		wantToken   string
+		wantErr     bool
+	}{
+		{
+			name:             "syncer with current credentials",
+			virtualWorkspace: "syncer",
+			param:            "us-east1",
+			wantContext:      "syncer:root:foo:us-east1",
+			wantServer:       "https://test/services/syncer/root:foo/us-east1",
+			wantToken:        "user-token",
+		},
+		{
+			name:             "apiexport with current credentials",
+			virtualWorkspace: "apiexport",
+			param:            "today-cowboys",
+			wantContext:      "apiexport:root:foo:today-cowboys",
+			wantServer:       "https://test/services/apiexport/root:foo/today-cowboys",
+			wantToken:        "user-token",
+		},
+		{
+			name:             "initializing workspaces are not scoped to the current workspace",
+			virtualWorkspace: "initializingworkspaces",
+			param:            "root:org:Universal",
+			wantContext:      "initializingworkspaces:root:org:Universal",
+			wantServer:       "https://test/services/initializingworkspaces/root:org:Universal",
+			wantToken:        "user-token",
+		},
+		{
+			name:             "apiexport on the virtual workspace URL of the shard",
+			virtualWorkspace: "apiexport",
+			param:            "today-cowboys",
+			rootKcpObjects:   externalShard,
+			wantContext:      "apiexport:root:foo:today-cowboys",
+			wantServer:       "https://virtual/services/apiexport/root:foo/today-cowboys",
+			wantToken:        "user-token",
+		},
+		{
+			name:             "syncer on the virtual workspace URL of the shard",
+			virtualWorkspace: "syncer",
+			param:            "us-east1",
+			rootKcpObjects:   externalShard,
+			kcpObjects:       advertisingWorkloadCluster,
+			wantContext:      "syncer:root:foo:us-east1",
+			wantServer:       "https://virtual/services/syncer/root:foo/us-east1",
+			wantToken:        "user-token",
+		},
+		{
+			name:             "syncer on the URL advertised by the workload cluster",
+			virtualWorkspace: "syncer",
+			param:            "us-east1",
+			kcpObjects:       advertisingWorkloadCluster,
+			wantContext:      "syncer:root:foo:us-east1",
+			wantServer:       "https://virtual/services/syncer/root:foo/us-east1",
+			wantToken:        "user-token",
+		},
+		{
+			name:             "workspaces with invalid scope",
+			virtualWorkspace: "workspaces",
+			param:            "mine",
+			wantErr:          true,
+		},
+		{
+			name:             "unknown virtual workspace",
+			virtualWorkspace: "foo",
+			param:            "bar",
+			wantErr:          true,
+		},
+		{
+			name:             "syncer with service account",
+			virtualWorkspace: "syncer",
+			param:            "us-east1",
+			serviceAccount:   "default/syncer-us-east1",
+			objects: []runtime.Object{
+				&corev1.ServiceAccount{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "syncer-us-east1"},
+					Secrets:    []corev1.ObjectReference{{Name: "syncer-us-east1-token-abcde"}},
+				},
+				&corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "syncer-us-east1-token-abcde"},
+					Data:       map[string][]byte{"token": []byte("sa-token")},
+				},
+			},
+			wantContext: "syncer:root:foo:us-east1",
+			wantServer:  "https://test/services/syncer/root:foo/us-east1",
+			wantToken:   "sa-token",
+		},
+		{
+			name:             "service account without token",
+			virtualWorkspace: "syncer",
+			param:            "us-east1",
+			serviceAccount:   "default/syncer-us-east1",
+			objects: []runtime.Object{
+				&corev1.ServiceAccount{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "syncer-us-east1"},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name:             "invalid service account",
+			virtualWorkspace: "syncer",
+			param:            "us-east1",
+			serviceAccount:   "syncer-us-east1",
+			wantErr:          true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			streams, _, stdout, _ := genericclioptions.NewTestIOStreams()
+			kc := &KubeConfig{
+				startingConfig: startingConfig.DeepCopy(),
+				currentContext: startingConfig.CurrentContext,
+				clusterClient: fakeTenancyClient{
+					t: t,
+					clients: map[logicalcluster.Name]*tenancyfake.Clientset{
+						logicalcluster.New("root"):     tenancyfake.NewSimpleClientset(tt.rootKcpObjects...),
+						logicalcluster.New("root:foo"): tenancyfake.NewSimpleClientset(tt.kcpObjects...),
+					},
+				},
+				kubeClusterClient: fakeKubeClusterClient{
+					t: t,
+					clients: map[logicalcluster.Name]*kubefake.Clientset{
+						logicalcluster.New("root:foo"): kubefake.NewSimpleClientset(tt.objects...),
+					},
+				},
+				IOStreams: streams,
+			}
+
+			err := kc.VirtualWorkspaceKubeConfig(context.Background(), tt.virtualWorkspace, tt.param, tt.serviceAccount)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			got, err := clientcmd.Load(stdout.Bytes())
+			require.NoError(t, err)
+
+			require.Equal(t, tt.wantContext, got.CurrentContext)
+			require.Equal(t, tt.wantServer, got.Clusters[tt.wantContext].Server)
+			require.Equal(t, []byte("ca"), got.Clusters[tt.wantContext].CertificateAuthorityData)
+			require.Equal(t, tt.wantToken, got.AuthInfos[tt.wantContext].Token)
+		})
+	}
+}
+
+type fakeKubeClusterClient struct {
+	t       *testing.T
+	clients map[logicalcluster.Name]*kubefake.Clientset
+}
+
+func (f fakeKubeClusterClient) Cluster(cluster logicalcluster.Name) kubernetes.Interface {
+	client, ok := f.clients[cluster]
+	require.True(f.t, ok, "no client for cluster %s", cluster)
+	return client
+}
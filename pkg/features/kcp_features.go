@@ -24,6 +24,7 @@ import (
 	"github.com/spf13/pflag"
 
 	"k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
 	genericfeatures "k8s.io/apiserver/pkg/features"
 	utilfeature "k8s.io/apiserver/pkg/util/feature"
 	"k8s.io/component-base/featuregate"
@@ -41,6 +42,19 @@ const (
 	//
 	// Enable the scheduling.kcp.dev/v1alpha1 API group, and related controllers.
 	LocationAPI featuregate.Feature = "KCPLocationAPI"
+
+	// owner: @dinhxuanvu
+	// alpha: v0.5
+	//
+	// Enable the WorkspaceSource API reconciling the objects of workspaces from Git repositories.
+	WorkspaceSource featuregate.Feature = "KCPWorkspaceSource"
+
+	// owner: @dinhxuanvu
+	// alpha: v0.5
+	//
+	// Bootstrap the root:compute workspace exporting the standard Kubernetes workload APIs through the
+	// kubernetes APIExport, to be bound by workload workspaces.
+	RootComputeWorkspace featuregate.Feature = "KCPRootComputeWorkspace"
 )
 
 func init() {
@@ -55,6 +69,13 @@ func KnownFeatures() []string {
 	return features
 }
 
+// ReloadableFeatures are the features evaluated per request, which hence can be changed at runtime
+// without restarting the server. All other features are only read on startup.
+var ReloadableFeatures = sets.NewString(
+	string(genericfeatures.APIResponseCompression),
+	string(genericfeatures.DryRun),
+)
+
 // NewFlagValue returns a wrapper to be used for a pflag flag value.
 func NewFlagValue() pflag.Value {
 	return &kcpFeatureGate{
@@ -83,17 +104,21 @@ func (f *kcpFeatureGate) Type() string {
 // in the generic control plane code. To add a new feature, define a key for it above and add it
 // here. The features will be available throughout Kubernetes binaries.
 var defaultGenericControlPlaneFeatureGates = map[featuregate.Feature]featuregate.FeatureSpec{
-	LocationAPI: {Default: false, PreRelease: featuregate.Alpha},
+	LocationAPI:          {Default: false, PreRelease: featuregate.Alpha},
+	WorkspaceSource:      {Default: false, PreRelease: featuregate.Alpha},
+	RootComputeWorkspace: {Default: false, PreRelease: featuregate.Alpha},
 
 	// inherited features from generic apiserver, relisted here to get a conflict if it is changed
 	// unintentionally on either side:
-	genericfeatures.StreamingProxyRedirects: {Default: false, PreRelease: featuregate.Deprecated}, // remove in 1.24
-	genericfeatures.ValidateProxyRedirects:  {Default: true, PreRelease: featuregate.Deprecated},
-	genericfeatures.AdvancedAuditing:        {Default: true, PreRelease: featuregate.GA},
-	genericfeatures.APIResponseCompression:  {Default: true, PreRelease: featuregate.Beta},
-	genericfeatures.APIListChunking:         {Default: true, PreRelease: featuregate.Beta},
-	genericfeatures.DryRun:                  {Default: true, PreRelease: featuregate.GA},
-	genericfeatures.ServerSideApply:         {Default: true, PreRelease: featuregate.GA},
-	genericfeatures.APIPriorityAndFairness:  {Default: true, PreRelease: featuregate.Beta},
-	genericfeatures.WarningHeaders:          {Default: true, PreRelease: featuregate.GA, LockToDefault: true}, // remove in 1.24
+	genericfeatures.StreamingProxyRedirects:             {Default: false, PreRelease: featuregate.Deprecated}, // remove in 1.24
+	genericfeatures.ValidateProxyRedirects:              {Default: true, PreRelease: featuregate.Deprecated},
+	genericfeatures.AdvancedAuditing:                    {Default: true, PreRelease: featuregate.GA},
+	genericfeatures.APIResponseCompression:              {Default: true, PreRelease: featuregate.Beta},
+	genericfeatures.APIListChunking:                     {Default: true, PreRelease: featuregate.Beta},
+	genericfeatures.DryRun:                              {Default: true, PreRelease: featuregate.GA},
+	genericfeatures.ServerSideApply:                     {Default: true, PreRelease: featuregate.GA},
+	genericfeatures.APIPriorityAndFairness:              {Default: true, PreRelease: featuregate.Beta},
+	genericfeatures.WarningHeaders:                      {Default: true, PreRelease: featuregate.GA, LockToDefault: true}, // remove in 1.24
+	genericfeatures.CustomResourceValidationExpressions: {Default: false, PreRelease: featuregate.Alpha},
+	genericfeatures.APIServerTracing:                    {Default: false, PreRelease: featuregate.Alpha},
 }
@@ -0,0 +1,178 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package informer
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/klog/v2"
+)
+
+// BookmarkStore persists the resourceVersion and the objects of informers in a directory. After a restart,
+// an informer starts from the persisted objects and resumes its watch at the persisted resourceVersion,
+// instead of relisting the objects of every logical cluster. If the resourceVersion is too old to resume
+// the watch from, the informer relists as usual.
+type BookmarkStore struct {
+	dir string
+
+	lock sync.Mutex
+}
+
+// NewBookmarkStore returns a BookmarkStore persisting bookmarks in the given directory.
+func NewBookmarkStore(dir string) *BookmarkStore {
+	return &BookmarkStore{dir: dir}
+}
+
+func (b *BookmarkStore) path(gvr schema.GroupVersionResource) string {
+	group := gvr.Group
+	if group == "" {
+		group = "core"
+	}
+	return filepath.Join(b.dir, fmt.Sprintf("%s_%s_%s.json.gz", group, gvr.Version, gvr.Resource))
+}
+
+// Load returns the persisted objects of the resource, with the resourceVersion they were persisted at.
+// It returns nil if nothing is persisted.
+func (b *BookmarkStore) Load(gvr schema.GroupVersionResource) (*unstructured.UnstructuredList, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	f, err := os.Open(b.path(gvr))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, err
+	}
+	bs, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	list := &unstructured.UnstructuredList{}
+	if err := list.UnmarshalJSON(bs); err != nil {
+		return nil, err
+	}
+	if list.GetResourceVersion() == "" {
+		return nil, nil
+	}
+	return list, nil
+}
+
+// Save persists the objects of the resource at the given resourceVersion. The resourceVersion must not be
+// newer than the objects, i.e. it must be read before the objects are listed from the informer store.
+func (b *BookmarkStore) Save(gvr schema.GroupVersionResource, resourceVersion string, objs []interface{}) error {
+	list := &unstructured.UnstructuredList{Object: map[string]interface{}{}}
+	list.SetAPIVersion("v1")
+	list.SetKind("List")
+	list.SetResourceVersion(resourceVersion)
+	for _, obj := range objs {
+		u, ok := obj.(*unstructured.Unstructured)
+		if !ok {
+			return fmt.Errorf("unexpected type %T", obj)
+		}
+		list.Items = append(list.Items, *u)
+	}
+	bs, err := list.MarshalJSON()
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(bs); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if err := os.MkdirAll(b.dir, 0700); err != nil {
+		return err
+	}
+	// write and rename such that a crash never leaves a partial bookmark behind
+	tmp := b.path(gvr) + ".tmp"
+	if err := ioutil.WriteFile(tmp, buf.Bytes(), 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, b.path(gvr))
+}
+
+// Delete removes the persisted objects of the resource.
+func (b *BookmarkStore) Delete(gvr schema.GroupVersionResource) error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if err := os.Remove(b.path(gvr)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// SaveInformer persists the objects of a synced informer.
+func (b *BookmarkStore) SaveInformer(gvr schema.GroupVersionResource, inf cache.SharedIndexInformer) error {
+	if !inf.HasSynced() {
+		return nil
+	}
+	// read the resourceVersion first: objects newer than it are replayed by the resumed watch
+	resourceVersion := inf.LastSyncResourceVersion()
+	if resourceVersion == "" {
+		return nil
+	}
+	return b.Save(gvr, resourceVersion, inf.GetStore().List())
+}
+
+// resumingListFunc returns a ListFunc that returns the persisted objects of the resource on its first call,
+// and calls the given ListFunc afterwards, e.g. when the persisted resourceVersion is too old to be watched.
+func resumingListFunc(bookmarks *BookmarkStore, gvr schema.GroupVersionResource, listFunc cache.ListFunc) cache.ListFunc {
+	var resumed bool
+	return func(options metav1.ListOptions) (runtime.Object, error) {
+		if bookmarks == nil || resumed {
+			return listFunc(options)
+		}
+		resumed = true
+
+		list, err := bookmarks.Load(gvr)
+		if err != nil {
+			klog.Errorf("Failed to load bookmark for %q, relisting: %v", gvr, err)
+			return listFunc(options)
+		}
+		if list == nil {
+			return listFunc(options)
+		}
+		klog.Infof("Resuming informer for %q at resourceVersion %s with %d objects", gvr, list.GetResourceVersion(), len(list.Items))
+		return list, nil
+	}
+}
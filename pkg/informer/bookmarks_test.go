@@ -0,0 +1,88 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package informer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestBookmarkStore(t *testing.T) {
+	gvr := schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+	bookmarks := NewBookmarkStore(t.TempDir())
+
+	list, err := bookmarks.Load(gvr)
+	require.NoError(t, err)
+	require.Nil(t, list, "nothing persisted yet")
+
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion("meta.k8s.io/v1")
+	obj.SetKind("PartialObjectMetadata")
+	obj.SetClusterName("root:org:ws")
+	obj.SetNamespace("default")
+	obj.SetName("cm")
+	obj.SetGeneration(3)
+	require.NoError(t, bookmarks.Save(gvr, "42", []interface{}{obj}))
+
+	list, err = bookmarks.Load(gvr)
+	require.NoError(t, err)
+	require.Equal(t, "42", list.GetResourceVersion())
+	require.Len(t, list.Items, 1)
+	require.Equal(t, obj, &list.Items[0])
+	require.Equal(t, int64(3), list.Items[0].GetGeneration())
+
+	require.NoError(t, bookmarks.Delete(gvr))
+	list, err = bookmarks.Load(gvr)
+	require.NoError(t, err)
+	require.Nil(t, list)
+}
+
+func TestResumingListFunc(t *testing.T) {
+	gvr := schema.GroupVersionResource{Group: "example.com", Version: "v1", Resource: "widgets"}
+	bookmarks := NewBookmarkStore(t.TempDir())
+
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion("meta.k8s.io/v1")
+	obj.SetKind("PartialObjectMetadata")
+	obj.SetName("persisted")
+	require.NoError(t, bookmarks.Save(gvr, "42", []interface{}{obj}))
+
+	var listed int
+	listFunc := resumingListFunc(bookmarks, gvr, func(options metav1.ListOptions) (runtime.Object, error) {
+		listed++
+		list := &unstructured.UnstructuredList{Object: map[string]interface{}{}}
+		list.SetResourceVersion("100")
+		return list, nil
+	})
+
+	got, err := listFunc(metav1.ListOptions{ResourceVersion: "0"})
+	require.NoError(t, err)
+	require.Equal(t, 0, listed, "first list must be served from the bookmark")
+	require.Equal(t, "42", got.(*unstructured.UnstructuredList).GetResourceVersion())
+
+	// e.g. after the watch failed with a too old resourceVersion
+	got, err = listFunc(metav1.ListOptions{ResourceVersion: "42"})
+	require.NoError(t, err)
+	require.Equal(t, 1, listed)
+	require.Equal(t, "100", got.(*unstructured.UnstructuredList).GetResourceVersion())
+}
@@ -25,12 +25,16 @@ import (
 	"github.com/kcp-dev/logicalcluster"
 
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/dynamic"
-	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/dynamic/dynamiclister"
 	"k8s.io/client-go/informers"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/klog/v2"
@@ -40,6 +44,9 @@ import (
 
 const (
 	resyncPeriod = 10 * time.Hour
+
+	// bookmarkInterval is the interval in which the informers are persisted to the BookmarkStore.
+	bookmarkInterval = time.Minute
 )
 
 type clusterDiscovery interface {
@@ -55,6 +62,7 @@ type DynamicDiscoverySharedInformerFactory struct {
 	handler         GVREventHandler
 	filterFunc      func(interface{}) bool
 	pollInterval    time.Duration
+	bookmarks       *BookmarkStore
 
 	mu            sync.RWMutex // guards gvrs
 	gvrs          map[schema.GroupVersionResource]struct{}
@@ -97,14 +105,7 @@ func (d *DynamicDiscoverySharedInformerFactory) informerForResourceLockHeld(gvr
 	}
 
 	// Definitely need to create it
-	inf = dynamicinformer.NewFilteredDynamicInformer(
-		d.dynamicClient,
-		gvr,
-		corev1.NamespaceAll,
-		resyncPeriod,
-		cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc},
-		nil,
-	)
+	inf = newTrimmingDynamicInformer(d.dynamicClient, gvr, d.bookmarks)
 
 	// Store in cache
 	d.informers[gvr] = inf
@@ -112,6 +113,70 @@ func (d *DynamicDiscoverySharedInformerFactory) informerForResourceLockHeld(gvr
 	return inf
 }
 
+// newTrimmingDynamicInformer returns an informer for gvr that strips metadata.managedFields and the
+// last-applied-configuration annotation from every object before storing it. Controllers using this factory
+// only look at names, labels and ownership, but watch every namespaced resource across all logical clusters,
+// so these fields would otherwise make up most of the memory held by the caches. With bookmarks, the informer
+// starts from the persisted objects.
+func newTrimmingDynamicInformer(client dynamic.Interface, gvr schema.GroupVersionResource, bookmarks *BookmarkStore) informers.GenericInformer {
+	resourceClient := client.Resource(gvr).Namespace(corev1.NamespaceAll)
+	inf := cache.NewSharedIndexInformer(
+		&cache.ListWatch{
+			ListFunc: resumingListFunc(bookmarks, gvr, func(options metav1.ListOptions) (runtime.Object, error) {
+				list, err := resourceClient.List(context.TODO(), options)
+				if err != nil {
+					return nil, err
+				}
+				for i := range list.Items {
+					trimObject(&list.Items[i])
+				}
+				return list, nil
+			}),
+			WatchFunc: func(options metav1.ListOptions) (watch.Interface, error) {
+				w, err := resourceClient.Watch(context.TODO(), options)
+				if err != nil {
+					return nil, err
+				}
+				return watch.Filter(w, func(in watch.Event) (watch.Event, bool) {
+					if u, ok := in.Object.(*unstructured.Unstructured); ok {
+						trimObject(u)
+					}
+					return in, true
+				}), nil
+			},
+		},
+		&unstructured.Unstructured{},
+		resyncPeriod,
+		cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc},
+	)
+
+	return &genericInformer{informer: inf, gvr: gvr}
+}
+
+// trimObject removes metadata fields that are not used by consumers of the informers.
+func trimObject(u *unstructured.Unstructured) {
+	u.SetManagedFields(nil)
+	if annotations := u.GetAnnotations(); annotations != nil {
+		if _, found := annotations[corev1.LastAppliedConfigAnnotation]; found {
+			delete(annotations, corev1.LastAppliedConfigAnnotation)
+			u.SetAnnotations(annotations)
+		}
+	}
+}
+
+type genericInformer struct {
+	informer cache.SharedIndexInformer
+	gvr      schema.GroupVersionResource
+}
+
+func (i *genericInformer) Informer() cache.SharedIndexInformer {
+	return i.informer
+}
+
+func (i *genericInformer) Lister() cache.GenericLister {
+	return dynamiclister.NewRuntimeObjectShim(dynamiclister.New(i.informer.GetIndexer(), i.gvr))
+}
+
 // Listers returns a map of per-resource-type listers for all types that are
 // known by this informer factory, and that are synced.
 //
@@ -144,7 +209,8 @@ func (d *DynamicDiscoverySharedInformerFactory) Listers() (listers map[schema.Gr
 
 // NewDynamicDiscoverySharedInformerFactory returns a factory for shared
 // informers that discovers new types and informs on updates to resources of
-// those types.
+// those types. If bookmarks is not nil, the informers are persisted periodically
+// and on shutdown, and resume from there on the next start.
 func NewDynamicDiscoverySharedInformerFactory(
 	workspaceLister tenancylisters.ClusterWorkspaceLister,
 	disco clusterDiscovery,
@@ -152,6 +218,7 @@ func NewDynamicDiscoverySharedInformerFactory(
 	filterFunc func(obj interface{}) bool,
 	handler GVREventHandler,
 	pollInterval time.Duration,
+	bookmarks *BookmarkStore,
 ) DynamicDiscoverySharedInformerFactory {
 	return DynamicDiscoverySharedInformerFactory{
 		workspaceLister: workspaceLister,
@@ -161,6 +228,7 @@ func NewDynamicDiscoverySharedInformerFactory(
 		filterFunc:      filterFunc,
 		gvrs:            make(map[schema.GroupVersionResource]struct{}),
 		pollInterval:    pollInterval,
+		bookmarks:       bookmarks,
 		informers:       make(map[schema.GroupVersionResource]informers.GenericInformer),
 		informerStops:   make(map[schema.GroupVersionResource]chan struct{}),
 	}
@@ -207,11 +275,18 @@ func (d *DynamicDiscoverySharedInformerFactory) Start(ctx context.Context) {
 
 	// Poll for new types in the background.
 	ticker := time.NewTicker(d.pollInterval)
+	bookmarkTicker := time.NewTicker(bookmarkInterval)
+	if d.bookmarks == nil {
+		bookmarkTicker.Stop()
+	}
 	go func() {
 		defer func() {
 			d.mu.Lock()
 			defer d.mu.Unlock()
 
+			// persist the informers a last time, such that a restart resumes close to here.
+			d.saveBookmarksLockHeld()
+
 			// tear down all informers when done.
 			for _, stopCh := range d.informerStops {
 				close(stopCh)
@@ -225,11 +300,16 @@ func (d *DynamicDiscoverySharedInformerFactory) Start(ctx context.Context) {
 			select {
 			case <-ctx.Done():
 				ticker.Stop()
+				bookmarkTicker.Stop()
 				return
 			case <-ticker.C:
 				if err := d.discoverTypes(ctx); err != nil {
 					klog.Errorf("Error discovering types: %v", err)
 				}
+			case <-bookmarkTicker.C:
+				d.mu.RLock()
+				d.saveBookmarksLockHeld()
+				d.mu.RUnlock()
 			}
 		}
 	}()
@@ -341,6 +421,12 @@ func (d *DynamicDiscoverySharedInformerFactory) discoverTypes(ctx context.Contex
 		klog.V(4).Infof("Removing dynamic informer from maps for %q", gvr)
 		delete(d.informers, gvr)
 		delete(d.informerStops, gvr)
+
+		if d.bookmarks != nil {
+			if err := d.bookmarks.Delete(gvr); err != nil {
+				klog.Errorf("Failed to delete bookmark for %q: %v", gvr, err)
+			}
+		}
 	}
 
 	d.gvrs = latest
@@ -348,7 +434,20 @@ func (d *DynamicDiscoverySharedInformerFactory) discoverTypes(ctx context.Contex
 	return nil
 }
 
-func (d *DynamicDiscoverySharedInformerFactory) calculateInformersLockHeld(latest map[schema.GroupVersionResource]struct{}) (toAdd, toRemove []schema.GroupVersionResource) {
+// saveBookmarksLockHeld persists all synced informers. The caller must hold at least the read lock.
+func (d *DynamicDiscoverySharedInformerFactory) saveBookmarksLockHeld() {
+	if d.bookmarks == nil {
+		return
+	}
+	for gvr, inf := range d.informers {
+		if err := d.bookmarks.SaveInformer(gvr, inf.Informer()); err != nil {
+			klog.Errorf("Failed to save bookmark for %q: %v", gvr, err)
+		}
+	}
+}
+
+func (d *DynamicDiscoverySharedInformerFactory) calculateInformersLockHeld(
+	latest map[schema.GroupVersionResource]struct{}) (toAdd, toRemove []schema.GroupVersionResource) {
 	for gvr := range latest {
 		if _, found := d.gvrs[gvr]; !found {
 			toAdd = append(toAdd, gvr)
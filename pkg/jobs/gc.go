@@ -0,0 +1,99 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package jobs
+
+import (
+	"sort"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	jobsv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/jobs/v1alpha1"
+)
+
+// GarbageCollect returns those of the given sibling jobs that must be deleted, either because their TTL after
+// finishing has expired, or because there are more newer finished jobs of the same phase than the history
+// limit of the job allows. Which jobs are siblings is up to the subsystem, e.g. the jobs of the same owner,
+// or of the same subject. If some finished job is kept but its TTL will expire later, requeueAfter is the
+// duration until the first expiry, zero otherwise.
+func GarbageCollect(siblings []Job, now time.Time) (toDelete []Job, requeueAfter time.Duration) {
+	var succeeded, failed []Job
+	for _, job := range siblings {
+		if !IsFinished(job) {
+			continue
+		}
+
+		if expiry, hasTTL := expiresAt(job); hasTTL {
+			if !expiry.After(now) {
+				toDelete = append(toDelete, job)
+				continue
+			}
+			if d := expiry.Sub(now); requeueAfter == 0 || d < requeueAfter {
+				requeueAfter = d
+			}
+		}
+
+		if job.GetJobStatus().Phase == jobsv1alpha1.JobPhaseSucceeded {
+			succeeded = append(succeeded, job)
+		} else {
+			failed = append(failed, job)
+		}
+	}
+
+	toDelete = append(toDelete, exceedingHistoryLimit(succeeded, func(policy jobsv1alpha1.JobPolicy) *int32 {
+		return policy.SuccessfulJobsHistoryLimit
+	})...)
+	toDelete = append(toDelete, exceedingHistoryLimit(failed, func(policy jobsv1alpha1.JobPolicy) *int32 {
+		return policy.FailedJobsHistoryLimit
+	})...)
+
+	return toDelete, requeueAfter
+}
+
+// exceedingHistoryLimit returns the jobs which have at least as many newer jobs in the given list
+// as their history limit. Jobs without a limit are kept.
+func exceedingHistoryLimit(jobs []Job, limitFn func(jobsv1alpha1.JobPolicy) *int32) []Job {
+	sort.SliceStable(jobs, func(i, j int) bool {
+		ti, tj := jobs[i].GetJobStatus().CompletionTime, jobs[j].GetJobStatus().CompletionTime
+		if ti.Equal(tj) {
+			return jobs[i].GetName() < jobs[j].GetName()
+		}
+		// newest first, and jobs without completion time last
+		return tj == nil || (ti != nil && ti.After(tj.Time))
+	})
+
+	var exceeding []Job
+	for i, job := range jobs {
+		if limit := limitFn(job.GetJobPolicy()); limit != nil && int32(i) >= *limit {
+			exceeding = append(exceeding, job)
+		}
+	}
+	return exceeding
+}
+
+// expiresAt returns the time the TTL of a finished job expires, and false if the job has no TTL.
+func expiresAt(job Job) (time.Time, bool) {
+	ttl := job.GetJobPolicy().TTLSecondsAfterFinished
+	if ttl == nil {
+		return time.Time{}, false
+	}
+	finished := job.GetJobStatus().CompletionTime
+	if finished == nil {
+		finished = &metav1.Time{Time: job.GetCreationTimestamp().Time}
+	}
+	return finished.Add(time.Duration(*ttl) * time.Second), true
+}
@@ -0,0 +1,121 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package jobs
+
+import (
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/pointer"
+
+	jobsv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/jobs/v1alpha1"
+)
+
+func TestGarbageCollect(t *testing.T) {
+	now := time.Date(2022, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	job := func(name string, phase jobsv1alpha1.JobPhase, finishedAgo time.Duration, policy jobsv1alpha1.JobPolicy) Job {
+		j := &testJob{
+			ObjectMeta: metav1.ObjectMeta{Name: name, CreationTimestamp: metav1.NewTime(now.Add(-time.Hour))},
+			Spec:       policy,
+			Status:     jobsv1alpha1.JobStatus{Phase: phase},
+		}
+		if phase == jobsv1alpha1.JobPhaseSucceeded || phase == jobsv1alpha1.JobPhaseFailed {
+			finished := metav1.NewTime(now.Add(-finishedAgo))
+			j.Status.CompletionTime = &finished
+		}
+		return j
+	}
+	ttl := func(seconds int32) jobsv1alpha1.JobPolicy {
+		return jobsv1alpha1.JobPolicy{TTLSecondsAfterFinished: pointer.Int32(seconds)}
+	}
+	limits := func(succeeded, failed int32) jobsv1alpha1.JobPolicy {
+		return jobsv1alpha1.JobPolicy{SuccessfulJobsHistoryLimit: pointer.Int32(succeeded), FailedJobsHistoryLimit: pointer.Int32(failed)}
+	}
+
+	tests := []struct {
+		name             string
+		siblings         []Job
+		wantDeleted      []string
+		wantRequeueAfter time.Duration
+	}{
+		{
+			name: "unfinished jobs are kept",
+			siblings: []Job{
+				job("pending", jobsv1alpha1.JobPhasePending, 0, ttl(0)),
+				job("running", jobsv1alpha1.JobPhaseRunning, 0, limits(0, 0)),
+			},
+		},
+		{
+			name: "expired TTL",
+			siblings: []Job{
+				job("expired", jobsv1alpha1.JobPhaseSucceeded, 2*time.Minute, ttl(60)),
+				job("not-expired", jobsv1alpha1.JobPhaseFailed, 30*time.Second, ttl(60)),
+				job("no-ttl", jobsv1alpha1.JobPhaseSucceeded, 24*time.Hour, jobsv1alpha1.JobPolicy{}),
+			},
+			wantDeleted:      []string{"expired"},
+			wantRequeueAfter: 30 * time.Second,
+		},
+		{
+			name: "no history limits",
+			siblings: []Job{
+				job("s1", jobsv1alpha1.JobPhaseSucceeded, 1*time.Minute, jobsv1alpha1.JobPolicy{}),
+				job("s2", jobsv1alpha1.JobPhaseSucceeded, 2*time.Minute, jobsv1alpha1.JobPolicy{}),
+				job("f1", jobsv1alpha1.JobPhaseFailed, 1*time.Minute, jobsv1alpha1.JobPolicy{}),
+				job("f2", jobsv1alpha1.JobPhaseFailed, 2*time.Minute, jobsv1alpha1.JobPolicy{}),
+			},
+		},
+		{
+			name: "explicit history limits",
+			siblings: []Job{
+				job("s1", jobsv1alpha1.JobPhaseSucceeded, 1*time.Minute, limits(1, 0)),
+				job("s2", jobsv1alpha1.JobPhaseSucceeded, 2*time.Minute, limits(1, 0)),
+				job("f1", jobsv1alpha1.JobPhaseFailed, 1*time.Minute, limits(1, 0)),
+			},
+			wantDeleted: []string{"f1", "s2"},
+		},
+		{
+			name: "jobs deleted by TTL do not count against the history limit",
+			siblings: []Job{
+				job("s1", jobsv1alpha1.JobPhaseSucceeded, 1*time.Minute, limits(1, 1)),
+				job("s2", jobsv1alpha1.JobPhaseSucceeded, 2*time.Minute, jobsv1alpha1.JobPolicy{TTLSecondsAfterFinished: pointer.Int32(60), SuccessfulJobsHistoryLimit: pointer.Int32(1)}),
+				job("s3", jobsv1alpha1.JobPhaseSucceeded, 3*time.Minute, limits(1, 1)),
+			},
+			wantDeleted: []string{"s2", "s3"},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			toDelete, requeueAfter := GarbageCollect(tt.siblings, now)
+			deleted := []string{}
+			for _, job := range toDelete {
+				deleted = append(deleted, job.GetName())
+			}
+			sort.Strings(deleted)
+			if tt.wantDeleted == nil {
+				tt.wantDeleted = []string{}
+			}
+			require.Equal(t, tt.wantDeleted, deleted)
+			require.Equal(t, tt.wantRequeueAfter, requeueAfter)
+		})
+	}
+}
@@ -0,0 +1,78 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package jobs implements the lifecycle of job-like resources, i.e. of resources embedding
+// jobsv1alpha1.JobPolicy into their spec and jobsv1alpha1.JobStatus into their status. Controllers of
+// long-running operations use these helpers to transition their jobs through the phases, and to garbage
+// collect finished jobs according to their TTL and history limits.
+package jobs
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	jobsv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/jobs/v1alpha1"
+	conditionsv1alpha1 "github.com/kcp-dev/kcp/third_party/conditions/apis/conditions/v1alpha1"
+	"github.com/kcp-dev/kcp/third_party/conditions/util/conditions"
+)
+
+// Job is a job-like object. Its conditions are those of the embedded JobStatus.
+type Job interface {
+	conditions.Setter
+
+	// GetJobPolicy returns the JobPolicy embedded into the spec.
+	GetJobPolicy() jobsv1alpha1.JobPolicy
+	// GetJobStatus returns a pointer to the JobStatus embedded into the status.
+	GetJobStatus() *jobsv1alpha1.JobStatus
+}
+
+// IsFinished returns true if the job has reached a terminal phase.
+func IsFinished(job Job) bool {
+	phase := job.GetJobStatus().Phase
+	return phase == jobsv1alpha1.JobPhaseSucceeded || phase == jobsv1alpha1.JobPhaseFailed
+}
+
+// MarkRunning moves the job into the Running phase, setting the start time if not set yet.
+func MarkRunning(job Job, now metav1.Time) {
+	status := job.GetJobStatus()
+	status.Phase = jobsv1alpha1.JobPhaseRunning
+	if status.StartTime == nil {
+		status.StartTime = &now
+	}
+	conditions.MarkFalse(job, jobsv1alpha1.JobComplete, jobsv1alpha1.JobReasonRunning, conditionsv1alpha1.ConditionSeverityInfo, "")
+}
+
+// MarkSucceeded moves the job into the terminal Succeeded phase.
+func MarkSucceeded(job Job, now metav1.Time) {
+	markFinished(job, jobsv1alpha1.JobPhaseSucceeded, now)
+	conditions.MarkTrue(job, jobsv1alpha1.JobComplete)
+}
+
+// MarkFailed moves the job into the terminal Failed phase, with the given message in the Complete condition.
+func MarkFailed(job Job, now metav1.Time, messageFormat string, messageArgs ...interface{}) {
+	markFinished(job, jobsv1alpha1.JobPhaseFailed, now)
+	conditions.MarkFalse(job, jobsv1alpha1.JobComplete, jobsv1alpha1.JobReasonFailed, conditionsv1alpha1.ConditionSeverityError, messageFormat, messageArgs...)
+}
+
+func markFinished(job Job, phase jobsv1alpha1.JobPhase, now metav1.Time) {
+	status := job.GetJobStatus()
+	status.Phase = phase
+	if status.StartTime == nil {
+		status.StartTime = &now
+	}
+	if status.CompletionTime == nil {
+		status.CompletionTime = &now
+	}
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package jobs
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	jobsv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/jobs/v1alpha1"
+	conditionsv1alpha1 "github.com/kcp-dev/kcp/third_party/conditions/apis/conditions/v1alpha1"
+	"github.com/kcp-dev/kcp/third_party/conditions/util/conditions"
+)
+
+// testJob is a minimal job-like resource.
+type testJob struct {
+	metav1.TypeMeta
+	metav1.ObjectMeta
+
+	Spec   jobsv1alpha1.JobPolicy
+	Status jobsv1alpha1.JobStatus
+}
+
+var _ Job = &testJob{}
+
+func (j *testJob) DeepCopyObject() runtime.Object {
+	out := &testJob{TypeMeta: j.TypeMeta}
+	j.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	j.Spec.DeepCopyInto(&out.Spec)
+	j.Status.DeepCopyInto(&out.Status)
+	return out
+}
+
+func (j *testJob) GetConditions() conditionsv1alpha1.Conditions  { return j.Status.Conditions }
+func (j *testJob) SetConditions(c conditionsv1alpha1.Conditions) { j.Status.Conditions = c }
+func (j *testJob) GetJobPolicy() jobsv1alpha1.JobPolicy          { return j.Spec }
+func (j *testJob) GetJobStatus() *jobsv1alpha1.JobStatus         { return &j.Status }
+
+func TestLifecycle(t *testing.T) {
+	started := metav1.NewTime(time.Date(2022, 5, 1, 10, 0, 0, 0, time.UTC))
+	finished := metav1.NewTime(started.Add(time.Minute))
+
+	job := &testJob{ObjectMeta: metav1.ObjectMeta{Name: "export"}}
+	require.False(t, IsFinished(job))
+
+	MarkRunning(job, started)
+	require.Equal(t, jobsv1alpha1.JobPhaseRunning, job.Status.Phase)
+	require.Equal(t, &started, job.Status.StartTime)
+	require.False(t, IsFinished(job))
+	require.Equal(t, jobsv1alpha1.JobReasonRunning, conditions.GetReason(job, jobsv1alpha1.JobComplete))
+
+	MarkFailed(job, finished, "failed to export %d objects", 3)
+	require.Equal(t, jobsv1alpha1.JobPhaseFailed, job.Status.Phase)
+	require.Equal(t, &started, job.Status.StartTime, "start time must not change")
+	require.Equal(t, &finished, job.Status.CompletionTime)
+	require.True(t, IsFinished(job))
+	cond := conditions.Get(job, jobsv1alpha1.JobComplete)
+	require.NotNil(t, cond)
+	require.Equal(t, corev1.ConditionFalse, cond.Status)
+	require.Equal(t, jobsv1alpha1.JobReasonFailed, cond.Reason)
+	require.Equal(t, "failed to export 3 objects", cond.Message)
+
+	job = &testJob{ObjectMeta: metav1.ObjectMeta{Name: "migration"}}
+	MarkSucceeded(job, finished)
+	require.Equal(t, jobsv1alpha1.JobPhaseSucceeded, job.Status.Phase)
+	require.Equal(t, &finished, job.Status.StartTime, "start time defaults to the completion time")
+	require.Equal(t, &finished, job.Status.CompletionTime)
+	require.True(t, conditions.IsTrue(job, jobsv1alpha1.JobComplete))
+}
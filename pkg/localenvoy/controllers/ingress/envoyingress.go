@@ -20,12 +20,12 @@ import (
 	"context"
 	"fmt"
 	"hash/fnv"
-	"strings"
 
 	"github.com/kcp-dev/logicalcluster"
 
 	corev1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/klog/v2"
 
 	envoycontrolplane "github.com/kcp-dev/kcp/pkg/localenvoy/controlplane"
@@ -39,16 +39,7 @@ func (c *Controller) reconcile(ctx context.Context, ingress *networkingv1.Ingres
 	//nolint:staticcheck
 	if shared.DeprecatedGetAssignedWorkloadCluster(ingress.Labels) == "" {
 		// Root
-		if len(ingress.Status.LoadBalancer.Ingress) > 0 && ingress.Status.LoadBalancer.Ingress[0].Hostname != "" {
-			// Already set - never changes - do nothing
-			return nil
-		}
-
-		ingress.Status.LoadBalancer.Ingress = []corev1.LoadBalancerIngress{{
-			Hostname: generateStatusHost(c.domain, ingress),
-		}}
-
-		return nil
+		return c.reconcileHost(ingress)
 	}
 
 	// Leaf:
@@ -75,23 +66,38 @@ func domainHashString(s string) string {
 	return fmt.Sprint(h.Sum32())
 }
 
-// generateStatusHost returns a string that represent the desired status hostname for the ingress.
-// If the host is part of the same domain, it will be preserved as the status hostname, if not
-// a new one will be generated based on a hash of the ingress name, namespace and clusterName.
-func generateStatusHost(domain string, ingress *networkingv1.Ingress) string {
-	// TODO(jmprusi): using "contains" is a bad idea as it could be abused by crafting a malicious hostname, but for a PoC it should be good enough?
-	allRulesAreDomain := true
-	for _, rule := range ingress.Spec.Rules {
-		if !strings.Contains(rule.Host, domain) {
-			allRulesAreDomain = false
-			break
-		}
+// reconcileHost sets the load balancer status of the root Ingress to its desired host, unless
+// that host is already assigned to another root Ingress. The outcome is reported in the
+// HostAssigned condition.
+func (c *Controller) reconcileHost(ingress *networkingv1.Ingress) error {
+	host, err := c.desiredHost(ingress)
+	if err != nil {
+		setHostAssignedCondition(ingress, metav1.ConditionFalse, InvalidHostTemplateReason, err.Error())
+		return nil
 	}
 
-	// TODO(jmprusi): Hardcoded to the first one...
-	if allRulesAreDomain {
-		return ingress.Spec.Rules[0].Host
+	if len(ingress.Status.LoadBalancer.Ingress) == 1 && ingress.Status.LoadBalancer.Ingress[0].Hostname == host {
+		setHostAssignedCondition(ingress, metav1.ConditionTrue, HostAssignedReason, fmt.Sprintf("host %s assigned", host))
+		return nil
 	}
 
-	return domainHashString(ingress.Name+ingress.Namespace+logicalcluster.From(ingress).String()) + "." + domain
+	// serialize the assignment of hosts between the workers to not assign the same host twice.
+	c.hostsLock.Lock()
+	defer c.hostsLock.Unlock()
+
+	owner, err := c.hostOwner(host, ingress)
+	if err != nil {
+		return err
+	}
+	if owner != nil {
+		setHostAssignedCondition(ingress, metav1.ConditionFalse, HostConflictReason,
+			fmt.Sprintf("host %s is already assigned to Ingress %s|%s/%s", host, logicalcluster.From(owner), owner.Namespace, owner.Name))
+		return nil
+	}
+
+	ingress.Status.LoadBalancer.Ingress = []corev1.LoadBalancerIngress{{
+		Hostname: host,
+	}}
+	setHostAssignedCondition(ingress, metav1.ConditionTrue, HostAssignedReason, fmt.Sprintf("host %s assigned", host))
+	return nil
 }
@@ -19,6 +19,8 @@ package ingress
 import (
 	"context"
 	"fmt"
+	"sync"
+	"text/template"
 	"time"
 
 	"github.com/kcp-dev/logicalcluster"
@@ -37,6 +39,7 @@ import (
 	"k8s.io/klog/v2"
 
 	envoycontrolplane "github.com/kcp-dev/kcp/pkg/localenvoy/controlplane"
+	"github.com/kcp-dev/kcp/pkg/logging"
 	"github.com/kcp-dev/kcp/pkg/reconciler/workload/ingresssplitter"
 )
 
@@ -48,13 +51,14 @@ const controllerName = "kcp-envoy-ingress-status-aggregator"
 func NewController(
 	kubeClient kubernetes.ClusterInterface,
 	ingressInformer networkinginformers.IngressInformer,
-	ecp *envoycontrolplane.EnvoyControlPlane, domain string) *Controller {
+	ecp *envoycontrolplane.EnvoyControlPlane, domain string, hostTemplate *template.Template) *Controller {
 
 	c := &Controller{
-		queue:  workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), controllerName),
-		client: kubeClient,
-		ecp:    ecp,
-		domain: domain,
+		queue:        workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), controllerName),
+		client:       kubeClient,
+		ecp:          ecp,
+		domain:       domain,
+		hostTemplate: hostTemplate,
 
 		ingressIndexer: ingressInformer.Informer().GetIndexer(),
 		ingressLister:  ingressInformer.Lister(),
@@ -82,7 +86,9 @@ type Controller struct {
 	ingressIndexer cache.Indexer
 	ingressLister  networkinglisters.IngressLister
 
-	domain string
+	domain       string
+	hostTemplate *template.Template
+	hostsLock    sync.Mutex
 
 	ecp *envoycontrolplane.EnvoyControlPlane
 }
@@ -145,6 +151,9 @@ func (c *Controller) processNextWorkItem(ctx context.Context) bool {
 	}
 	key := k.(string)
 
+	logger := logging.WithQueueKey(logging.NewLogger(controllerName), key)
+	ctx = logging.NewContext(ctx, logger)
+
 	// No matter what, tell the queue we're done with this key, to unblock
 	// other workers.
 	defer c.queue.Done(key)
@@ -188,11 +197,21 @@ func (c *Controller) process(ctx context.Context, key string) (requeue bool, err
 	}
 	if !equality.Semantic.DeepEqual(previous, current) {
 		if current.Labels[envoycontrolplane.ToEnvoyLabel] == "" {
-			// If it's a root, we need to patch only status
+			// If it's a root, we need to update the conditions annotation and the status
 			// TODO(jmprusi): Move to patch instead of Update.
-			_, err := c.client.Cluster(logicalcluster.From(current)).NetworkingV1().Ingresses(current.Namespace).UpdateStatus(ctx, current, metav1.UpdateOptions{})
-			if err != nil {
-				return false, err
+			if !equality.Semantic.DeepEqual(previous.Annotations, current.Annotations) {
+				updated, err := c.client.Cluster(logicalcluster.From(current)).NetworkingV1().Ingresses(current.Namespace).Update(ctx, current, metav1.UpdateOptions{})
+				if err != nil {
+					return false, err
+				}
+				updated.Status = current.Status
+				current = updated
+			}
+			if !equality.Semantic.DeepEqual(previous.Status, current.Status) {
+				_, err := c.client.Cluster(logicalcluster.From(current)).NetworkingV1().Ingresses(current.Namespace).UpdateStatus(ctx, current, metav1.UpdateOptions{})
+				if err != nil {
+					return false, err
+				}
 			}
 		} else {
 			// If it's a leaf, we need to patch only non-status (to set labels)
@@ -0,0 +1,176 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingress
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/kcp-dev/logicalcluster"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"github.com/kcp-dev/kcp/pkg/reconciler/workload/ingresssplitter"
+	"github.com/kcp-dev/kcp/pkg/syncer/shared"
+)
+
+const (
+	// HostTemplateAnnotation is the annotation on a root Ingress overriding the host template
+	// of the ingress controller for that Ingress. The generated host must be in the domain of
+	// the ingress controller.
+	HostTemplateAnnotation = "ingress.kcp.dev/host-template"
+
+	// DefaultHostTemplate is the host template generating a hash of the Ingress name, namespace
+	// and logical cluster in the domain of the ingress controller.
+	DefaultHostTemplate = "{{.hash}}.{{.domain}}"
+
+	// HostAssignedCondition tells whether a host has been generated for the root Ingress and
+	// written into its load balancer status.
+	HostAssignedCondition = "HostAssigned"
+
+	// HostAssignedReason is the reason of a true HostAssigned condition.
+	HostAssignedReason = "Assigned"
+	// InvalidHostTemplateReason is the reason of a false HostAssigned condition because the host
+	// template cannot be rendered, or renders to an invalid host or to a host outside the domain.
+	InvalidHostTemplateReason = "InvalidHostTemplate"
+	// HostConflictReason is the reason of a false HostAssigned condition because the host is
+	// already assigned to another Ingress.
+	HostConflictReason = "HostConflict"
+)
+
+// ParseHostTemplate parses a host template. The template is executed with the following keys:
+//
+//   - workspace: the name of the workspace of the Ingress, e.g. "ws" for root:org:ws.
+//   - workspacePath: the logical cluster of the Ingress with ":" replaced by "-", e.g. "root-org-ws".
+//   - namespace and name: the namespace and name of the Ingress.
+//   - hash: a hash of the name, namespace and logical cluster of the Ingress.
+//   - domain: the domain of the ingress controller.
+func ParseHostTemplate(text string) (*template.Template, error) {
+	return template.New("host").Option("missingkey=error").Parse(text)
+}
+
+// renderHost executes the host template for the given Ingress, and checks that the result is
+// a valid host in the given domain.
+func renderHost(tmpl *template.Template, domain string, ingress *networkingv1.Ingress) (string, error) {
+	clusterName := logicalcluster.From(ingress)
+	data := map[string]string{
+		"workspace":     clusterName.Base(),
+		"workspacePath": strings.ReplaceAll(clusterName.String(), ":", "-"),
+		"namespace":     ingress.Namespace,
+		"name":          ingress.Name,
+		"hash":          domainHashString(ingress.Name + ingress.Namespace + clusterName.String()),
+		"domain":        domain,
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", err
+	}
+	host := strings.ToLower(strings.TrimSpace(buf.String()))
+	if errs := validation.IsDNS1123Subdomain(host); len(errs) > 0 {
+		return "", fmt.Errorf("invalid host %q: %s", host, strings.Join(errs, ", "))
+	}
+	if !strings.HasSuffix(host, "."+domain) {
+		return "", fmt.Errorf("host %q is not in domain %q", host, domain)
+	}
+	return host, nil
+}
+
+// desiredHost returns the host of the root Ingress. If all the hosts of its rules are in the
+// domain, the first one is used. Otherwise the host is generated from the host template of the
+// Ingress, or the default template of the controller.
+func (c *Controller) desiredHost(ingress *networkingv1.Ingress) (string, error) {
+	allRulesAreDomain := len(ingress.Spec.Rules) > 0
+	for _, rule := range ingress.Spec.Rules {
+		if !strings.HasSuffix(rule.Host, "."+c.domain) {
+			allRulesAreDomain = false
+			break
+		}
+	}
+
+	// TODO(jmprusi): Hardcoded to the first one...
+	if allRulesAreDomain {
+		return ingress.Spec.Rules[0].Host, nil
+	}
+
+	tmpl := c.hostTemplate
+	if text, found := ingress.Annotations[HostTemplateAnnotation]; found {
+		var err error
+		if tmpl, err = ParseHostTemplate(text); err != nil {
+			return "", fmt.Errorf("invalid %s annotation: %w", HostTemplateAnnotation, err)
+		}
+	}
+	return renderHost(tmpl, c.domain, ingress)
+}
+
+// hostOwner returns the root Ingress other than the given one which has the given host in
+// its load balancer status, or nil.
+func (c *Controller) hostOwner(host string, ingress *networkingv1.Ingress) (*networkingv1.Ingress, error) {
+	ingresses, err := c.ingressLister.List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	for _, other := range ingresses {
+		//nolint:staticcheck
+		if shared.DeprecatedGetAssignedWorkloadCluster(other.Labels) != "" || rootIngressKeyFor(other) != "" {
+			continue
+		}
+		if logicalcluster.From(other) == logicalcluster.From(ingress) && other.Namespace == ingress.Namespace && other.Name == ingress.Name {
+			continue
+		}
+		for _, lb := range other.Status.LoadBalancer.Ingress {
+			if lb.Hostname == host {
+				return other, nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+// setHostAssignedCondition sets the HostAssigned condition in the conditions annotation of the
+// root Ingress, next to the conditions maintained by the ingress splitter.
+func setHostAssignedCondition(ingress *networkingv1.Ingress, status metav1.ConditionStatus, reason, message string) {
+	var conditions []metav1.Condition
+	if value, found := ingress.Annotations[ingresssplitter.ConditionsAnnotation]; found {
+		if err := json.Unmarshal([]byte(value), &conditions); err != nil {
+			conditions = nil
+		}
+	}
+	meta.SetStatusCondition(&conditions, metav1.Condition{
+		Type:               HostAssignedCondition,
+		Status:             status,
+		Reason:             reason,
+		Message:            message,
+		ObservedGeneration: ingress.Generation,
+	})
+
+	bs, err := json.Marshal(conditions)
+	if err != nil {
+		return
+	}
+	if ingress.Annotations == nil {
+		ingress.Annotations = map[string]string{}
+	}
+	ingress.Annotations[ingresssplitter.ConditionsAnnotation] = string(bs)
+}
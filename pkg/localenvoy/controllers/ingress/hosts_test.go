@@ -0,0 +1,214 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingress
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	networkinglisters "k8s.io/client-go/listers/networking/v1"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/kcp-dev/kcp/pkg/reconciler/workload/ingresssplitter"
+)
+
+func rootIngress(clusterName, namespace, name string, annotations map[string]string, hosts ...string) *networkingv1.Ingress {
+	ingress := &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			Namespace:   namespace,
+			ClusterName: clusterName,
+			Annotations: annotations,
+		},
+		Spec: networkingv1.IngressSpec{
+			Rules: []networkingv1.IngressRule{{Host: "app.example.com"}},
+		},
+	}
+	for _, host := range hosts {
+		ingress.Status.LoadBalancer.Ingress = append(ingress.Status.LoadBalancer.Ingress, corev1.LoadBalancerIngress{Hostname: host})
+	}
+	return ingress
+}
+
+func TestRenderHost(t *testing.T) {
+	tests := map[string]struct {
+		template string
+		want     string
+		wantErr  bool
+	}{
+		"default template": {
+			template: DefaultHostTemplate,
+			want:     domainHashString("webdefaultroot:org:ws") + ".apps.example.com",
+		},
+		"workspace and namespace": {
+			template: "{{.workspace}}.{{.namespace}}.{{.domain}}",
+			want:     "ws.default.apps.example.com",
+		},
+		"workspace path": {
+			template: "{{.name}}-{{.workspacePath}}.apps.example.com",
+			want:     "web-root-org-ws.apps.example.com",
+		},
+		"outside of the domain": {
+			template: "{{.name}}.evil.com",
+			wantErr:  true,
+		},
+		"the domain itself": {
+			template: "{{.domain}}",
+			wantErr:  true,
+		},
+		"invalid host": {
+			template: "{{.name}}_{{.namespace}}.{{.domain}}",
+			wantErr:  true,
+		},
+		"unknown key": {
+			template: "{{.cluster}}.{{.domain}}",
+			wantErr:  true,
+		},
+	}
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			tmpl, err := ParseHostTemplate(tc.template)
+			require.NoError(t, err)
+			got, err := renderHost(tmpl, "apps.example.com", rootIngress("root:org:ws", "default", "web", nil))
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestReconcileHost(t *testing.T) {
+	tests := map[string]struct {
+		ingress    *networkingv1.Ingress
+		others     []*networkingv1.Ingress
+		wantHosts  []string
+		wantStatus metav1.ConditionStatus
+		wantReason string
+	}{
+		"host is assigned": {
+			ingress:    rootIngress("root:org:ws", "default", "web", nil),
+			wantHosts:  []string{"ws.default.apps.example.com"},
+			wantStatus: metav1.ConditionTrue,
+			wantReason: HostAssignedReason,
+		},
+		"annotation overrides the template": {
+			ingress:    rootIngress("root:org:ws", "default", "web", map[string]string{HostTemplateAnnotation: "{{.name}}.{{.workspace}}.{{.domain}}"}),
+			wantHosts:  []string{"web.ws.apps.example.com"},
+			wantStatus: metav1.ConditionTrue,
+			wantReason: HostAssignedReason,
+		},
+		"annotation outside of the domain is rejected": {
+			ingress:    rootIngress("root:org:ws", "default", "web", map[string]string{HostTemplateAnnotation: "www.example.com"}),
+			wantStatus: metav1.ConditionFalse,
+			wantReason: InvalidHostTemplateReason,
+		},
+		"host of another ingress is not assigned": {
+			ingress:    rootIngress("root:org:ws", "default", "web", nil),
+			others:     []*networkingv1.Ingress{rootIngress("root:other:ws", "default", "api", nil, "ws.default.apps.example.com")},
+			wantStatus: metav1.ConditionFalse,
+			wantReason: HostConflictReason,
+		},
+		"already assigned host is kept": {
+			ingress:    rootIngress("root:org:ws", "default", "web", nil, "ws.default.apps.example.com"),
+			wantHosts:  []string{"ws.default.apps.example.com"},
+			wantStatus: metav1.ConditionTrue,
+			wantReason: HostAssignedReason,
+		},
+	}
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+			for _, other := range append(tc.others, tc.ingress) {
+				require.NoError(t, indexer.Add(other))
+			}
+			tmpl, err := ParseHostTemplate("{{.workspace}}.{{.namespace}}.{{.domain}}")
+			require.NoError(t, err)
+			c := &Controller{
+				ingressLister: networkinglisters.NewIngressLister(indexer),
+				domain:        "apps.example.com",
+				hostTemplate:  tmpl,
+			}
+
+			ingress := tc.ingress.DeepCopy()
+			require.NoError(t, c.reconcileHost(ingress))
+
+			var hosts []string
+			for _, lb := range ingress.Status.LoadBalancer.Ingress {
+				hosts = append(hosts, lb.Hostname)
+			}
+			require.Equal(t, tc.wantHosts, hosts)
+
+			var conditions []metav1.Condition
+			require.NoError(t, json.Unmarshal([]byte(ingress.Annotations[ingresssplitter.ConditionsAnnotation]), &conditions))
+			require.Len(t, conditions, 1)
+			require.Equal(t, HostAssignedCondition, conditions[0].Type)
+			require.Equal(t, tc.wantStatus, conditions[0].Status)
+			require.Equal(t, tc.wantReason, conditions[0].Reason, conditions[0].Message)
+		})
+	}
+}
+
+func TestDesiredHost(t *testing.T) {
+	tests := map[string]struct {
+		ruleHosts []string
+		want      string
+	}{
+		"rule host in the domain is used": {
+			ruleHosts: []string{"web.apps.example.com"},
+			want:      "web.apps.example.com",
+		},
+		"rule host only containing the domain is replaced": {
+			ruleHosts: []string{"apps.example.com.evil.com"},
+			want:      "ws.default.apps.example.com",
+		},
+		"rule host ending in the domain without a dot is replaced": {
+			ruleHosts: []string{"evilapps.example.com"},
+			want:      "ws.default.apps.example.com",
+		},
+		"rule hosts outside of the domain": {
+			ruleHosts: []string{"web.apps.example.com", "app.example.com"},
+			want:      "ws.default.apps.example.com",
+		},
+	}
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			tmpl, err := ParseHostTemplate("{{.workspace}}.{{.namespace}}.{{.domain}}")
+			require.NoError(t, err)
+			c := &Controller{domain: "apps.example.com", hostTemplate: tmpl}
+
+			ingress := rootIngress("root:org:ws", "default", "web", nil)
+			ingress.Spec.Rules = nil
+			for _, host := range tc.ruleHosts {
+				ingress.Spec.Rules = append(ingress.Spec.Rules, networkingv1.IngressRule{Host: host})
+			}
+			got, err := c.desiredHost(ingress)
+			require.NoError(t, err)
+			require.Equal(t, tc.want, got)
+		})
+	}
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package logging defines the structured logging conventions of kcp. Controllers, the syncer and
+// virtual workspaces log through a klog backed logr.Logger passed in the context, which carries
+// consistent keys identifying the tenant and the reconciled object, such that logs can be filtered
+// per workspace, logical cluster and sync target in log aggregation systems.
+package logging
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	"github.com/kcp-dev/logicalcluster"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/clusters"
+	"k8s.io/klog/v2/klogr"
+)
+
+const (
+	// ReconcilerNameKey is the name of the controller or component logging.
+	ReconcilerNameKey = "reconciler"
+	// ReconcilerKey is the queue key being reconciled.
+	ReconcilerKey = "reconcilerKey"
+	// LogicalClusterKey is the logical cluster of the reconciled object or request.
+	LogicalClusterKey = "logicalCluster"
+	// WorkspaceKey is the workspace a ClusterWorkspace object or a request refers to.
+	WorkspaceKey = "workspace"
+	// SyncTargetKey is the workload cluster a syncer syncs to, or an object is synced to.
+	SyncTargetKey = "syncTarget"
+	// VirtualWorkspaceKey is the virtual workspace serving a request.
+	VirtualWorkspaceKey = "virtualWorkspace"
+	// NamespaceKey and NameKey are the namespace and name of the reconciled object.
+	NamespaceKey = "namespace"
+	NameKey      = "name"
+)
+
+// NewLogger returns a klog backed logger for the given controller or component.
+func NewLogger(reconciler string) logr.Logger {
+	return klogr.New().WithValues(ReconcilerNameKey, reconciler)
+}
+
+// WithQueueKey returns a logger with the given queue key, and its logical cluster if it is
+// cluster-aware, i.e. of the form [<namespace>/]<logical-cluster>#$#<name>.
+func WithQueueKey(logger logr.Logger, key string) logr.Logger {
+	logger = logger.WithValues(ReconcilerKey, key)
+	_, clusterAwareName, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		return logger
+	}
+	if clusterName, _ := clusters.SplitClusterAwareKey(clusterAwareName); !clusterName.Empty() {
+		logger = logger.WithValues(LogicalClusterKey, clusterName.String())
+	}
+	return logger
+}
+
+// WithObject returns a logger with the logical cluster, namespace and name of the given object.
+func WithObject(logger logr.Logger, obj metav1.Object) logr.Logger {
+	keysAndValues := []interface{}{LogicalClusterKey, logicalcluster.From(obj).String()}
+	if namespace := obj.GetNamespace(); namespace != "" {
+		keysAndValues = append(keysAndValues, NamespaceKey, namespace)
+	}
+	return logger.WithValues(append(keysAndValues, NameKey, obj.GetName())...)
+}
+
+// WithWorkspace returns a logger with the given workspace.
+func WithWorkspace(logger logr.Logger, workspace logicalcluster.Name) logr.Logger {
+	return logger.WithValues(WorkspaceKey, workspace.String())
+}
+
+// WithClusterWorkspace returns a logger with the workspace the given ClusterWorkspace object stands for,
+// i.e. the logical cluster of the object joined with its name.
+func WithClusterWorkspace(logger logr.Logger, workspace metav1.Object) logr.Logger {
+	return WithWorkspace(logger, logicalcluster.From(workspace).Join(workspace.GetName()))
+}
+
+// WithSyncTarget returns a logger with the given sync target, i.e. workload cluster.
+func WithSyncTarget(logger logr.Logger, syncTarget string) logr.Logger {
+	return logger.WithValues(SyncTargetKey, syncTarget)
+}
+
+// NewContext returns a context carrying the given logger.
+func NewContext(ctx context.Context, logger logr.Logger) context.Context {
+	return logr.NewContext(ctx, logger)
+}
+
+// FromContext returns the logger of the given context, or a plain klog backed logger if there is
+// none.
+func FromContext(ctx context.Context) logr.Logger {
+	if logger, err := logr.FromContext(ctx); err == nil {
+		return logger
+	}
+	return klogr.New()
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logging
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/go-logr/logr/funcr"
+	"github.com/kcp-dev/logicalcluster"
+	"github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func recordingLogger(lines *[]string) logr.Logger {
+	return funcr.New(func(prefix, args string) {
+		*lines = append(*lines, args)
+	}, funcr.Options{})
+}
+
+func TestWithQueueKey(t *testing.T) {
+	tests := []struct {
+		name string
+		key  string
+		want string
+	}{
+		{
+			name: "cluster-scoped cluster-aware key",
+			key:  "root:org#$#foo",
+			want: `"level"=0 "msg"="test" "reconcilerKey"="root:org#$#foo" "logicalCluster"="root:org"`,
+		},
+		{
+			name: "namespaced cluster-aware key",
+			key:  "default/root:org#$#foo",
+			want: `"level"=0 "msg"="test" "reconcilerKey"="default/root:org#$#foo" "logicalCluster"="root:org"`,
+		},
+		{
+			name: "plain key",
+			key:  "default/foo",
+			want: `"level"=0 "msg"="test" "reconcilerKey"="default/foo"`,
+		},
+		{
+			name: "invalid key",
+			key:  "a/b/c",
+			want: `"level"=0 "msg"="test" "reconcilerKey"="a/b/c"`,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			var lines []string
+			WithQueueKey(recordingLogger(&lines), tt.key).Info("test")
+			require.Equal(t, []string{tt.want}, lines)
+		})
+	}
+}
+
+func TestWithObject(t *testing.T) {
+	var lines []string
+	logger := recordingLogger(&lines)
+
+	WithObject(logger, &metav1.ObjectMeta{ClusterName: "root:org", Namespace: "default", Name: "foo"}).Info("test")
+	WithClusterWorkspace(logger, &metav1.ObjectMeta{ClusterName: "root:org", Name: "team"}).Info("test")
+	WithSyncTarget(WithWorkspace(logger, logicalcluster.New("root:org")), "us-east1").Info("test")
+
+	require.Equal(t, []string{
+		`"level"=0 "msg"="test" "logicalCluster"="root:org" "namespace"="default" "name"="foo"`,
+		`"level"=0 "msg"="test" "workspace"="root:org:team"`,
+		`"level"=0 "msg"="test" "workspace"="root:org" "syncTarget"="us-east1"`,
+	}, lines)
+}
+
+func TestFromContext(t *testing.T) {
+	var lines []string
+	ctx := NewContext(context.Background(), recordingLogger(&lines).WithValues(SyncTargetKey, "us-east1"))
+	FromContext(ctx).Info("test")
+	require.Equal(t, []string{`"level"=0 "msg"="test" "syncTarget"="us-east1"`}, lines)
+
+	// without a logger in the context, klog is used.
+	require.NotNil(t, FromContext(context.Background()).GetSink())
+}
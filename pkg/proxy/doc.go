@@ -19,6 +19,18 @@ limitations under the License.
 // headers. The proxy terminates client TLS and communicates with API servers
 // via mTLS. Traffic is routed based on paths.
 //
+// Requests without a client certificate, e.g. with a bearer token obtained through an
+// exec credential plugin, are passed through to the backend which authenticates them.
+// Authentication headers sent by clients are always dropped. Impersonation headers are
+// only passed through for requests to a single workspace, i.e. /clusters/<workspace>/...,
+// so that the backend authorizes the impersonation in that workspace only.
+//
+// A backend can have alternate backends serving the same content, e.g. replicas of a shard
+// serving replicated system workspaces. Requests stick to the first available backend in
+// order. Requests without body failing to connect are retried against the next backend, a
+// backend failing repeatedly is skipped for a cooldown, and discovery requests are hedged,
+// i.e. also sent to the next backend if there is no timely response.
+//
 // An example configuration:
 //
 //  - path: /services/
@@ -28,6 +40,8 @@ limitations under the License.
 //    proxy_client_key: certs/proxy-client-key.pem
 //  - path: /
 //    backend: https://localhost:6443
+//    alternate_backends:
+//    - https://localhost:6445
 //    backend_server_ca: certs/kcp-ca-cert.pem
 //    proxy_client_cert: certs/proxy-client-cert.pem
 //    proxy_client_key: certs/proxy-client-key.pem
@@ -0,0 +1,269 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	tenancypath "github.com/kcp-dev/kcp/pkg/apis/tenancy/path"
+)
+
+// FailoverPolicy defines how the proxy fails over between the endpoints of a backend, i.e. the
+// primary backend of a path mapping and its alternate backends, e.g. replicas of a shard serving
+// replicated system workspaces.
+type FailoverPolicy struct {
+	// FailureThreshold is the number of consecutive connection failures after which an endpoint is
+	// skipped for Cooldown. Zero disables circuit breaking.
+	FailureThreshold int
+	// Cooldown is the time an endpoint is skipped. After the cooldown, requests are tried against it
+	// again, and one more connection failure skips it again.
+	Cooldown time.Duration
+	// HedgeDelay is the time after which a read-only discovery request without response is also sent
+	// to the next endpoint. The first response wins. Zero disables hedging.
+	HedgeDelay time.Duration
+}
+
+// endpoint is a backend endpoint with its circuit breaker state.
+type endpoint struct {
+	url *url.URL
+
+	lock      sync.Mutex
+	failures  int
+	openUntil time.Time
+}
+
+// available returns true if the circuit of the endpoint is closed, or its cooldown is over.
+func (e *endpoint) available(now time.Time) bool {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	return !now.Before(e.openUntil)
+}
+
+// observe records the result of a request to the endpoint. Only connection failures count as
+// failures, any response closes the circuit.
+func (e *endpoint) observe(err error, policy FailoverPolicy, now time.Time) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	if err == nil {
+		e.failures = 0
+		e.openUntil = time.Time{}
+		return
+	}
+	if !isConnectionFailure(err) || policy.FailureThreshold <= 0 {
+		return
+	}
+	e.failures++
+	if e.failures >= policy.FailureThreshold {
+		if now.After(e.openUntil) {
+			klog.Warningf("Skipping backend %s for %s after %d connection failures: %v", e.url.Host, policy.Cooldown, e.failures, err)
+		}
+		e.openUntil = now.Add(policy.Cooldown)
+	}
+}
+
+// failoverTransport sends requests to the first available endpoint, in order of preference, i.e.
+// requests stick to the primary endpoint as long as it is available. Requests without body which fail
+// to connect are retried against the next endpoint. Read-only discovery requests are hedged.
+type failoverTransport struct {
+	endpoints []*endpoint
+	policy    FailoverPolicy
+	base      http.RoundTripper
+
+	// for testing
+	now func() time.Time
+}
+
+func newFailoverTransport(endpoints []*url.URL, policy FailoverPolicy, base http.RoundTripper) *failoverTransport {
+	t := &failoverTransport{
+		policy: policy,
+		base:   base,
+		now:    time.Now,
+	}
+	for _, u := range endpoints {
+		t.endpoints = append(t.endpoints, &endpoint{url: u})
+	}
+	return t
+}
+
+func (t *failoverTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var candidates []*endpoint
+	now := t.now()
+	for _, e := range t.endpoints {
+		if e.available(now) {
+			candidates = append(candidates, e)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("all endpoints of backend %s are unavailable", t.endpoints[0].url.Host)
+	}
+
+	if t.policy.HedgeDelay > 0 && len(candidates) > 1 && isHedgeable(req) {
+		return t.hedge(req, candidates)
+	}
+
+	var err error
+	for _, e := range candidates {
+		var resp *http.Response
+		if resp, err = t.roundTrip(req, e); err == nil {
+			return resp, nil
+		}
+		if !isConnectionFailure(err) || !isReplayable(req) {
+			return nil, err
+		}
+		klog.V(4).Infof("Retrying %s %s after connection failure to %s: %v", req.Method, req.URL.Path, e.url.Host, err)
+	}
+	return nil, err
+}
+
+// roundTrip sends the request to the given endpoint.
+func (t *failoverTransport) roundTrip(req *http.Request, e *endpoint) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.URL.Scheme = e.url.Scheme
+	req.URL.Host = e.url.Host
+	resp, err := t.base.RoundTrip(req)
+	e.observe(err, t.policy, t.now())
+	return resp, err
+}
+
+// hedge sends the request to the first candidate, and to the next candidate if there is no response
+// after the hedge delay, or right away on failure. The first response is returned, the other
+// requests are cancelled.
+func (t *failoverTransport) hedge(req *http.Request, candidates []*endpoint) (*http.Response, error) {
+	type result struct {
+		resp    *http.Response
+		err     error
+		attempt int
+	}
+	results := make(chan result, len(candidates))
+	var cancels []context.CancelFunc
+	attempt := func(e *endpoint) {
+		ctx, cancel := context.WithCancel(req.Context())
+		i := len(cancels)
+		cancels = append(cancels, cancel)
+		go func() {
+			resp, err := t.roundTrip(req.WithContext(ctx), e)
+			results <- result{resp: resp, err: err, attempt: i}
+		}()
+	}
+
+	attempt(candidates[0])
+	next, pending := 1, 1
+	timer := time.NewTimer(t.policy.HedgeDelay)
+	defer timer.Stop()
+
+	var err error
+	for pending > 0 {
+		select {
+		case <-timer.C:
+			if next < len(candidates) {
+				klog.V(4).Infof("Hedging %s %s to %s", req.Method, req.URL.Path, candidates[next].url.Host)
+				attempt(candidates[next])
+				next++
+				pending++
+			}
+		case r := <-results:
+			pending--
+			if r.err == nil {
+				// cancel the other requests, and close their responses should they arrive anyway.
+				for i, cancel := range cancels {
+					if i != r.attempt {
+						cancel()
+					}
+				}
+				go func(pending int) {
+					for i := 0; i < pending; i++ {
+						if other := <-results; other.resp != nil {
+							other.resp.Body.Close()
+						}
+					}
+				}(pending)
+				r.resp.Body = &cancelOnClose{ReadCloser: r.resp.Body, cancel: cancels[r.attempt]}
+				return r.resp, nil
+			}
+			cancels[r.attempt]()
+			err = r.err
+			if pending == 0 && next < len(candidates) {
+				attempt(candidates[next])
+				next++
+				pending++
+			}
+		}
+	}
+	return nil, err
+}
+
+// cancelOnClose cancels the context of a request when its response body is closed.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (c *cancelOnClose) Close() error {
+	defer c.cancel()
+	return c.ReadCloser.Close()
+}
+
+// isConnectionFailure returns true if the error happened when connecting, i.e. before the request
+// was sent.
+func isConnectionFailure(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// isReplayable returns true if the request has no body, i.e. it can be sent again.
+func isReplayable(req *http.Request) bool {
+	return req.Body == nil || req.Body == http.NoBody
+}
+
+// isHedgeable returns true for read-only discovery requests, which can safely be sent to several
+// endpoints at the same time.
+func isHedgeable(req *http.Request) bool {
+	return req.Method == http.MethodGet && isReplayable(req) && isDiscoveryPath(req.URL.Path)
+}
+
+// isDiscoveryPath returns true for the discovery, openapi and version paths, with or without
+// /clusters/<workspace> prefix.
+func isDiscoveryPath(path string) bool {
+	if _, rest, ok := tenancypath.FromURLPath(path); ok {
+		if rest == "" {
+			return false
+		}
+		path = rest
+	}
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	switch segments[0] {
+	case "api":
+		return len(segments) <= 2
+	case "apis":
+		return len(segments) <= 3
+	case "openapi", "version":
+		return true
+	}
+	return false
+}
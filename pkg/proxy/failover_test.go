@@ -0,0 +1,180 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"errors"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakeBackends answers requests by host: down hosts fail to connect, slow hosts only answer when
+// the request is cancelled, other hosts answer with their name.
+type fakeBackends struct {
+	down map[string]bool
+	slow map[string]bool
+
+	lock     sync.Mutex
+	requests []string
+}
+
+func (b *fakeBackends) RoundTrip(req *http.Request) (*http.Response, error) {
+	b.lock.Lock()
+	b.requests = append(b.requests, req.URL.Host)
+	b.lock.Unlock()
+
+	if b.down[req.URL.Host] {
+		return nil, &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}
+	}
+	if b.slow[req.URL.Host] {
+		<-req.Context().Done()
+		return nil, req.Context().Err()
+	}
+	return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(strings.NewReader(req.URL.Host))}, nil
+}
+
+func (b *fakeBackends) requested() []string {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return append([]string(nil), b.requests...)
+}
+
+func newTestFailoverTransport(t *testing.T, backends *fakeBackends, policy FailoverPolicy) *failoverTransport {
+	var endpoints []*url.URL
+	for _, host := range []string{"primary", "replica-1", "replica-2"} {
+		u, err := url.Parse("https://" + host)
+		require.NoError(t, err)
+		endpoints = append(endpoints, u)
+	}
+	return newFailoverTransport(endpoints, policy, backends)
+}
+
+func body(t *testing.T, resp *http.Response) string {
+	defer resp.Body.Close()
+	data, err := ioutil.ReadAll(resp.Body)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestFailoverTransport(t *testing.T) {
+	t.Run("requests stick to the primary", func(t *testing.T) {
+		backends := &fakeBackends{}
+		transport := newTestFailoverTransport(t, backends, FailoverPolicy{FailureThreshold: 3, Cooldown: time.Minute})
+		for i := 0; i < 3; i++ {
+			resp, err := transport.RoundTrip(httptest.NewRequest(http.MethodGet, "https://primary/api/v1/namespaces", nil))
+			require.NoError(t, err)
+			require.Equal(t, "primary", body(t, resp))
+		}
+		require.Equal(t, []string{"primary", "primary", "primary"}, backends.requested())
+	})
+
+	t.Run("connection failures are retried against the next backend", func(t *testing.T) {
+		backends := &fakeBackends{down: map[string]bool{"primary": true, "replica-1": true}}
+		transport := newTestFailoverTransport(t, backends, FailoverPolicy{FailureThreshold: 3, Cooldown: time.Minute})
+		resp, err := transport.RoundTrip(httptest.NewRequest(http.MethodDelete, "https://primary/api/v1/namespaces/default", nil))
+		require.NoError(t, err)
+		require.Equal(t, "replica-2", body(t, resp))
+		require.Equal(t, []string{"primary", "replica-1", "replica-2"}, backends.requested())
+	})
+
+	t.Run("requests with body are not retried", func(t *testing.T) {
+		backends := &fakeBackends{down: map[string]bool{"primary": true}}
+		transport := newTestFailoverTransport(t, backends, FailoverPolicy{FailureThreshold: 3, Cooldown: time.Minute})
+		_, err := transport.RoundTrip(httptest.NewRequest(http.MethodPost, "https://primary/api/v1/namespaces", strings.NewReader("{}")))
+		require.Error(t, err)
+		require.Equal(t, []string{"primary"}, backends.requested())
+	})
+
+	t.Run("failing backends are skipped until the cooldown is over", func(t *testing.T) {
+		backends := &fakeBackends{down: map[string]bool{"primary": true}}
+		transport := newTestFailoverTransport(t, backends, FailoverPolicy{FailureThreshold: 2, Cooldown: time.Minute})
+		now := time.Now()
+		transport.now = func() time.Time { return now }
+
+		for i := 0; i < 3; i++ {
+			resp, err := transport.RoundTrip(httptest.NewRequest(http.MethodGet, "https://primary/api/v1/namespaces", nil))
+			require.NoError(t, err)
+			require.Equal(t, "replica-1", body(t, resp))
+		}
+		require.Equal(t, []string{"primary", "replica-1", "primary", "replica-1", "replica-1"}, backends.requested())
+
+		// after the cooldown, the recovered primary is used again.
+		backends.down = nil
+		now = now.Add(time.Minute)
+		resp, err := transport.RoundTrip(httptest.NewRequest(http.MethodGet, "https://primary/api/v1/namespaces", nil))
+		require.NoError(t, err)
+		require.Equal(t, "primary", body(t, resp))
+	})
+
+	t.Run("all backends failing", func(t *testing.T) {
+		backends := &fakeBackends{down: map[string]bool{"primary": true, "replica-1": true, "replica-2": true}}
+		transport := newTestFailoverTransport(t, backends, FailoverPolicy{FailureThreshold: 1, Cooldown: time.Minute})
+		_, err := transport.RoundTrip(httptest.NewRequest(http.MethodGet, "https://primary/api/v1/namespaces", nil))
+		require.Error(t, err)
+		_, err = transport.RoundTrip(httptest.NewRequest(http.MethodGet, "https://primary/api/v1/namespaces", nil))
+		require.EqualError(t, err, "all endpoints of backend primary are unavailable")
+		require.Len(t, backends.requested(), 3)
+	})
+
+	t.Run("slow discovery requests are hedged", func(t *testing.T) {
+		backends := &fakeBackends{slow: map[string]bool{"primary": true}}
+		transport := newTestFailoverTransport(t, backends, FailoverPolicy{HedgeDelay: 10 * time.Millisecond})
+		resp, err := transport.RoundTrip(httptest.NewRequest(http.MethodGet, "https://primary/clusters/root/apis", nil))
+		require.NoError(t, err)
+		require.Equal(t, "replica-1", body(t, resp))
+		require.Equal(t, []string{"primary", "replica-1"}, backends.requested())
+	})
+
+	t.Run("fast discovery requests are not hedged", func(t *testing.T) {
+		backends := &fakeBackends{}
+		transport := newTestFailoverTransport(t, backends, FailoverPolicy{HedgeDelay: time.Minute})
+		resp, err := transport.RoundTrip(httptest.NewRequest(http.MethodGet, "https://primary/api", nil))
+		require.NoError(t, err)
+		require.Equal(t, "primary", body(t, resp))
+		require.Equal(t, []string{"primary"}, backends.requested())
+	})
+}
+
+func TestIsDiscoveryPath(t *testing.T) {
+	tests := map[string]bool{
+		"/api":                            true,
+		"/api/v1":                         true,
+		"/apis":                           true,
+		"/apis/apps":                      true,
+		"/apis/apps/v1":                   true,
+		"/openapi/v2":                     true,
+		"/version":                        true,
+		"/clusters/root:org/apis/apps/v1": true,
+		"/clusters/root:org":              false,
+		"/api/v1/namespaces":              false,
+		"/apis/apps/v1/deployments":       false,
+		"/clusters/root:org/api/v1/namespaces/test": false,
+		"/services/workspaces/root/all/apis":        false,
+	}
+	for path, want := range tests {
+		require.Equal(t, want, isDiscoveryPath(path), path)
+	}
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/kcp-dev/logicalcluster"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/apiserver/pkg/endpoints/handlers/responsewriters"
+
+	tenancypath "github.com/kcp-dev/kcp/pkg/apis/tenancy/path"
+)
+
+var (
+	errorScheme = runtime.NewScheme()
+	errorCodecs = serializer.NewCodecFactory(errorScheme)
+)
+
+func init() {
+	errorScheme.AddUnversionedTypes(metav1.Unversioned,
+		&metav1.Status{},
+	)
+}
+
+// isImpersonating returns true if the request carries any of the impersonation headers.
+func isImpersonating(header http.Header) bool {
+	for key := range header {
+		if strings.HasPrefix(key, authenticationv1.ImpersonateUserExtraHeaderPrefix) {
+			return true
+		}
+	}
+	return header.Get(authenticationv1.ImpersonateUserHeader) != "" ||
+		header.Get(authenticationv1.ImpersonateGroupHeader) != "" ||
+		header.Get(authenticationv1.ImpersonateUIDHeader) != ""
+}
+
+// impersonatedWorkspace returns the logical cluster an impersonating request targets. Impersonation
+// is only forwarded for requests to a single workspace, i.e. /clusters/<workspace>/..., because the
+// backend authorizes the impersonation against the RBAC rules of exactly that workspace.
+func impersonatedWorkspace(path string) (logicalcluster.Name, error) {
+	clusterName, _, ok := tenancypath.FromURLPath(path)
+	if !ok {
+		return logicalcluster.Name{}, fmt.Errorf("impersonation is only allowed for requests to a workspace")
+	}
+	if clusterName == logicalcluster.Wildcard {
+		return logicalcluster.Name{}, fmt.Errorf("impersonation is not allowed across workspaces")
+	}
+	return clusterName, nil
+}
+
+// WithWorkspaceScopedImpersonation rejects impersonating requests that do not target a single
+// workspace. Requests that do are passed through with their impersonation headers, such that the
+// backend checks the impersonate verb within the requested workspace only.
+func WithWorkspaceScopedImpersonation(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if !isImpersonating(req.Header) {
+			handler.ServeHTTP(w, req)
+			return
+		}
+		if _, err := impersonatedWorkspace(req.URL.Path); err != nil {
+			responsewriters.ErrorNegotiated(
+				apierrors.NewForbidden(schema.GroupResource{Group: authenticationv1.GroupName, Resource: "users"}, req.Header.Get(authenticationv1.ImpersonateUserHeader), err),
+				errorCodecs, schema.GroupVersion{},
+				w, req)
+			return
+		}
+		handler.ServeHTTP(w, req)
+	})
+}
@@ -17,6 +17,8 @@ limitations under the License.
 package proxy
 
 import (
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
 	"io/ioutil"
 	"net/http"
@@ -25,22 +27,42 @@ import (
 	"sigs.k8s.io/yaml"
 
 	proxyoptions "github.com/kcp-dev/kcp/pkg/proxy/options"
+	"github.com/kcp-dev/kcp/pkg/secretstore"
+	"github.com/kcp-dev/kcp/pkg/shardidentity"
 )
 
 // PathMapping describes how to route traffic from a path to a backend server.
 // Each Path is registered with the DefaultServeMux with a handler that
 // delegates to the specified backend.
+//
+// BackendServerCA can be omitted if the proxy is configured with the kcp shard CA, which
+// then verifies the backend. ProxyClientCert and ProxyClientKey can be omitted if the proxy
+// is configured with the client certificate a shard issues for it.
 type PathMapping struct {
-	Path            string `json:"path"`
-	Backend         string `json:"backend"`
-	BackendServerCA string `json:"backend_server_ca"`
-	ProxyClientCert string `json:"proxy_client_cert"`
-	ProxyClientKey  string `json:"proxy_client_key"`
-	UserHeader      string `json:"user_header,omitempty"`
-	GroupHeader     string `json:"group_header,omitempty"`
+	Path              string `json:"path"`
+	Backend           string `json:"backend"`
+	BackendServerCA   string `json:"backend_server_ca,omitempty"`
+	ProxyClientCert   string `json:"proxy_client_cert,omitempty"`
+	ProxyClientKey    string `json:"proxy_client_key,omitempty"`
+	UserHeader        string `json:"user_header,omitempty"`
+	GroupHeader       string `json:"group_header,omitempty"`
+	ExtraHeaderPrefix string `json:"extra_header_prefix,omitempty"`
+
+	// AlternateBackends serve the same content as Backend, e.g. replicas of a shard. Requests go to
+	// Backend while it is available, and fail over to the alternates in order.
+	AlternateBackends []string `json:"alternate_backends,omitempty"`
 }
 
+// NewHandler returns a handler proxying requests to the backends of the mapping file.
 func NewHandler(o *proxyoptions.Options) (http.Handler, error) {
+	secretStore, err := o.SecretStore.NewStore()
+	if err != nil {
+		return nil, err
+	}
+	return newHandler(o, secretStore)
+}
+
+func newHandler(o *proxyoptions.Options, secretStore secretstore.Store) (http.Handler, error) {
 	mappingData, err := ioutil.ReadFile(o.MappingFile)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read mapping file %q: %w", o.MappingFile, err)
@@ -51,10 +73,43 @@ func NewHandler(o *proxyoptions.Options) (http.Handler, error) {
 		return nil, fmt.Errorf("failed to unmarshal mapping file %q: %w", o.MappingFile, err)
 	}
 
+	var shardCAs *x509.CertPool
+	if o.ShardCACertFile != "" {
+		caCert, err := ioutil.ReadFile(o.ShardCACertFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read kcp shard CA: %w", err)
+		}
+		shardCAs = x509.NewCertPool()
+		if !shardCAs.AppendCertsFromPEM(caCert) {
+			return nil, fmt.Errorf("no certificates found in kcp shard CA file %q", o.ShardCACertFile)
+		}
+	}
+	var shardClientCert func() (*tls.Certificate, error)
+	switch {
+	case o.ShardClientCertFile != "":
+		shardClientCert = shardidentity.NewCertFileLoader(o.ShardClientCertFile, o.ShardClientKeyFile).Certificate
+	case o.ShardClientCertSecretStoreKey != "" && secretStore != nil:
+		shardClientCert = shardidentity.NewCertStoreLoader(secretStore, o.ShardClientCertSecretStoreKey).Certificate
+	}
+	if shardClientCert != nil {
+		if _, err := shardClientCert(); err != nil {
+			return nil, fmt.Errorf("failed to load shard client certificate: %w", err)
+		}
+	}
+
+	failoverPolicy := FailoverPolicy{
+		FailureThreshold: o.BackendFailureThreshold,
+		Cooldown:         o.BackendFailureCooldown,
+		HedgeDelay:       o.DiscoveryHedgeDelay,
+	}
 	mux := http.NewServeMux()
 	for _, m := range mapping {
 		klog.V(2).Infof("Adding mapping %v", m)
-		proxy, err := NewReverseProxy(m.Backend, m.ProxyClientCert, m.ProxyClientKey, m.BackendServerCA)
+		tlsConfig, err := backendTLSConfig(m, shardCAs, shardClientCert)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create path mapping for path %q: %w", m.Path, err)
+		}
+		proxy, err := NewReverseProxy(m.Backend, m.AlternateBackends, tlsConfig, failoverPolicy)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create path mapping for path %q: %w", m.Path, err)
 		}
@@ -66,8 +121,50 @@ func NewHandler(o *proxyoptions.Options) (http.Handler, error) {
 		if m.GroupHeader != "" {
 			groupHeader = m.GroupHeader
 		}
-		mux.Handle(m.Path, http.HandlerFunc(ProxyHandler(proxy, userHeader, groupHeader)))
+		extraHeaderPrefix := "X-Remote-Extra-"
+		if m.ExtraHeaderPrefix != "" {
+			extraHeaderPrefix = m.ExtraHeaderPrefix
+		}
+		mux.Handle(m.Path, WithWorkspaceScopedImpersonation(http.HandlerFunc(ProxyHandler(proxy, userHeader, groupHeader, extraHeaderPrefix))))
 	}
 
 	return mux, nil
 }
+
+// backendTLSConfig returns the TLS config to connect to the backends of the mapping, using the
+// CA and client certificate files of the mapping, or else the kcp shard CA and the client
+// certificate a shard issued for the proxy.
+func backendTLSConfig(m PathMapping, shardCAs *x509.CertPool, shardClientCert func() (*tls.Certificate, error)) (*tls.Config, error) {
+	tlsConfig := &tls.Config{}
+
+	switch {
+	case m.BackendServerCA != "":
+		caCert, err := ioutil.ReadFile(m.BackendServerCA)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.RootCAs = x509.NewCertPool()
+		tlsConfig.RootCAs.AppendCertsFromPEM(caCert)
+	case shardCAs != nil:
+		tlsConfig.RootCAs = shardCAs
+	default:
+		return nil, fmt.Errorf("backend_server_ca is required without --shard-ca-cert-file")
+	}
+
+	switch {
+	case m.ProxyClientCert != "" || m.ProxyClientKey != "":
+		cert, err := tls.LoadX509KeyPair(m.ProxyClientCert, m.ProxyClientKey)
+		if err != nil {
+			return nil, err
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	case shardClientCert != nil:
+		tlsConfig.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return shardClientCert()
+		}
+	default:
+		return nil, fmt.Errorf("proxy_client_cert and proxy_client_key are required without --shard-client-cert-file or --shard-client-cert-secret-store-key")
+	}
+
+	return tlsConfig, nil
+}
@@ -0,0 +1,127 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"context"
+	"crypto/tls"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"sigs.k8s.io/yaml"
+
+	proxyoptions "github.com/kcp-dev/kcp/pkg/proxy/options"
+	"github.com/kcp-dev/kcp/pkg/secretstore"
+	"github.com/kcp-dev/kcp/pkg/shardidentity"
+)
+
+func TestNewHandlerWithShardIdentity(t *testing.T) {
+	dir := t.TempDir()
+	caCertFile := filepath.Join(dir, "ca.crt")
+	ca, err := shardidentity.LoadOrCreateCA(caCertFile, filepath.Join(dir, "ca.key"))
+	require.NoError(t, err)
+	frontProxyCA, err := shardidentity.LoadOrCreateCA(filepath.Join(dir, "front-proxy-ca.crt"), filepath.Join(dir, "front-proxy-ca.key"))
+	require.NoError(t, err)
+
+	servingCert, servingKey, err := ca.IssueServingCert("kcp-shard-root", []string{"127.0.0.1"}, time.Hour)
+	require.NoError(t, err)
+	cert, err := tls.X509KeyPair(servingCert, servingKey)
+	require.NoError(t, err)
+
+	t.Log("The shard issues the client certificate of the proxy from the front-proxy CA")
+	clientCertFile, clientKeyFile := filepath.Join(dir, "front-proxy-client.crt"), filepath.Join(dir, "front-proxy-client.key")
+	require.NoError(t, shardidentity.NewClientCertRotator(frontProxyCA, shardidentity.FrontProxyUser, nil, time.Hour, clientCertFile, clientKeyFile).Ensure(context.Background()))
+
+	backend := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Write([]byte(req.TLS.PeerCertificates[0].Subject.CommonName)) // nolint:errcheck
+	}))
+	backend.TLS = &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    frontProxyCA.CertPool(),
+	}
+	backend.StartTLS()
+	t.Cleanup(backend.Close)
+
+	mapping, err := yaml.Marshal([]PathMapping{{Path: "/", Backend: backend.URL}})
+	require.NoError(t, err)
+	mappingFile := filepath.Join(dir, "mapping.yaml")
+	require.NoError(t, ioutil.WriteFile(mappingFile, mapping, 0600))
+
+	t.Log("Without the shard CA and client certificate, the mapping lacks TLS configuration")
+	o := proxyoptions.NewOptions()
+	o.MappingFile = mappingFile
+	_, err = NewHandler(o)
+	require.Error(t, err)
+
+	t.Log("With the shard CA only, the mapping lacks a client certificate")
+	o.ShardCACertFile = caCertFile
+	_, err = NewHandler(o)
+	require.Error(t, err)
+
+	t.Log("With both, the proxy verifies the backend and authenticates with the issued client certificate")
+	o.ShardClientCertFile, o.ShardClientKeyFile = clientCertFile, clientKeyFile
+	handler, err := NewHandler(o)
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, shardidentity.FrontProxyUser, w.Body.String())
+
+	t.Log("With a secret store, the proxy authenticates with the client certificate the shard keeps in the store")
+	store := memoryStore{}
+	require.NoError(t, shardidentity.NewClientCertRotator(frontProxyCA, shardidentity.FrontProxyUser, nil, time.Hour, "", "").WithSecretStore(store, "shards/root/front-proxy-client").Ensure(context.Background()))
+	o.ShardClientCertFile, o.ShardClientKeyFile = "", ""
+	o.ShardClientCertSecretStoreKey = "shards/root/front-proxy-client"
+	handler, err = newHandler(o, store)
+	require.NoError(t, err)
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, shardidentity.FrontProxyUser, w.Body.String())
+}
+
+// memoryStore is an in-memory secretstore.Store.
+type memoryStore map[string]map[string][]byte
+
+func (s memoryStore) Get(_ context.Context, key string) (map[string][]byte, error) {
+	data, ok := s[key]
+	if !ok {
+		return nil, secretstore.ErrNotFound
+	}
+	return data, nil
+}
+
+func (s memoryStore) Put(_ context.Context, key string, data map[string][]byte) error {
+	s[key] = data
+	return nil
+}
+
+func (s memoryStore) Create(_ context.Context, key string, data map[string][]byte) error {
+	if _, ok := s[key]; ok {
+		return secretstore.ErrAlreadyExists
+	}
+	s[key] = data
+	return nil
+}
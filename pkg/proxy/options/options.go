@@ -18,21 +18,50 @@ package options
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/spf13/pflag"
+
+	secretstoreoptions "github.com/kcp-dev/kcp/pkg/secretstore/options"
 )
 
 type Options struct {
-	MappingFile string
+	MappingFile       string
+	TracingConfigFile string
+
+	BackendFailureThreshold int
+	BackendFailureCooldown  time.Duration
+	DiscoveryHedgeDelay     time.Duration
+
+	ShardCACertFile               string
+	ShardClientCertFile           string
+	ShardClientKeyFile            string
+	ShardClientCertSecretStoreKey string
+
+	SecretStore *secretstoreoptions.Options
 }
 
 func NewOptions() *Options {
-	o := &Options{}
+	o := &Options{
+		BackendFailureThreshold: 3,
+		BackendFailureCooldown:  30 * time.Second,
+		DiscoveryHedgeDelay:     250 * time.Millisecond,
+		SecretStore:             secretstoreoptions.NewOptions(),
+	}
 	return o
 }
 
 func (o *Options) AddFlags(fs *pflag.FlagSet) {
 	fs.StringVar(&o.MappingFile, "mapping-file", o.MappingFile, "Config file mapping paths to backends")
+	fs.StringVar(&o.TracingConfigFile, "tracing-config-file", o.TracingConfigFile, "File with apiserver tracing configuration. Requests to workspaces are traced with the workspace as attribute, and the trace context is propagated to the backends.")
+	fs.IntVar(&o.BackendFailureThreshold, "backend-failure-threshold", o.BackendFailureThreshold, "Number of consecutive connection failures after which a backend with alternate backends is skipped for --backend-failure-cooldown. 0 disables skipping.")
+	fs.DurationVar(&o.BackendFailureCooldown, "backend-failure-cooldown", o.BackendFailureCooldown, "Time a failing backend is skipped, before requests are tried against it again.")
+	fs.DurationVar(&o.DiscoveryHedgeDelay, "discovery-hedge-delay", o.DiscoveryHedgeDelay, "Time after which discovery requests without response are also sent to the next alternate backend. 0 disables hedging.")
+	fs.StringVar(&o.ShardCACertFile, "shard-ca-cert-file", o.ShardCACertFile, "File with the kcp shard CA certificate. If set, backends of mappings without backend_server_ca are verified with it.")
+	fs.StringVar(&o.ShardClientCertFile, "shard-client-cert-file", o.ShardClientCertFile, "File with the client certificate a kcp shard with --shard-identity issues for the proxy. If set, mappings without proxy_client_cert authenticate with it. It is reloaded when the shard rotates it.")
+	fs.StringVar(&o.ShardClientKeyFile, "shard-client-key-file", o.ShardClientKeyFile, "File with the key of --shard-client-cert-file.")
+	fs.StringVar(&o.ShardClientCertSecretStoreKey, "shard-client-cert-secret-store-key", o.ShardClientCertSecretStoreKey, "Key in the --secret-store holding the client certificate a kcp shard with --shard-identity issues for the proxy, i.e. shards/<shard name>/front-proxy-client. Alternative to --shard-client-cert-file when the shard uses the same secret store.")
+	o.SecretStore.AddFlags(fs)
 }
 
 func (o *Options) Complete() error {
@@ -45,6 +74,27 @@ func (o *Options) Validate() []error {
 	if o.MappingFile == "" {
 		errs = append(errs, fmt.Errorf("--mapping-file is required"))
 	}
+	if o.BackendFailureThreshold < 0 {
+		errs = append(errs, fmt.Errorf("--backend-failure-threshold must not be negative"))
+	}
+	if o.BackendFailureCooldown < 0 {
+		errs = append(errs, fmt.Errorf("--backend-failure-cooldown must not be negative"))
+	}
+	if o.DiscoveryHedgeDelay < 0 {
+		errs = append(errs, fmt.Errorf("--discovery-hedge-delay must not be negative"))
+	}
+	if (o.ShardClientCertFile == "") != (o.ShardClientKeyFile == "") {
+		errs = append(errs, fmt.Errorf("--shard-client-cert-file and --shard-client-key-file must be set together"))
+	}
+	errs = append(errs, o.SecretStore.Validate()...)
+	if o.ShardClientCertSecretStoreKey != "" {
+		if o.SecretStore.Provider == secretstoreoptions.ProviderEtcd {
+			errs = append(errs, fmt.Errorf("--shard-client-cert-secret-store-key requires --secret-store"))
+		}
+		if o.ShardClientCertFile != "" {
+			errs = append(errs, fmt.Errorf("--shard-client-cert-secret-store-key is mutually exclusive with --shard-client-cert-file"))
+		}
+	}
 
 	return errs
 }
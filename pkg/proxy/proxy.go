@@ -18,14 +18,17 @@ package proxy
 
 import (
 	"crypto/tls"
-	"crypto/x509"
-	"io/ioutil"
+	"fmt"
 	"net/http"
 	"net/http/httputil"
 	"net/url"
+	"strings"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 
 	userinfo "k8s.io/apiserver/pkg/authentication/user"
 	"k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/component-base/traces"
 	"k8s.io/klog/v2"
 )
 
@@ -36,45 +39,49 @@ type KCPProxy struct {
 }
 
 // NewReverseProxy returns a new reverse proxy where backend is the backend URL to
-// connect to, clientCert is the proxy's client cert to use to connect to it,
-// clientKeyFile is the proxy's client private key file, and caFile is the CA
-// the proxy uses to verify the backend server's cert.
-func NewReverseProxy(backend, clientCert, clientKeyFile, caFile string) (*KCPProxy, error) {
+// connect to, alternateBackends are further endpoints serving the same content with
+// the same path, and tlsConfig holds the proxy's client certificate and the CAs
+// to verify the backend servers' certs. The failover policy defines how requests
+// fail over between the backend and its alternates.
+func NewReverseProxy(backend string, alternateBackends []string, tlsConfig *tls.Config, policy FailoverPolicy) (*KCPProxy, error) {
 	target, err := url.Parse(backend)
 	if err != nil {
 		return nil, err
 	}
-
-	caCert, err := ioutil.ReadFile(caFile)
-	if err != nil {
-		return nil, err
-	}
-
-	caCertPool := x509.NewCertPool()
-	caCertPool.AppendCertsFromPEM(caCert)
-
-	cert, err := tls.LoadX509KeyPair(clientCert, clientKeyFile)
-	if err != nil {
-		return nil, err
+	endpoints := []*url.URL{target}
+	for _, alternate := range alternateBackends {
+		u, err := url.Parse(alternate)
+		if err != nil {
+			return nil, err
+		}
+		if u.Path != target.Path {
+			return nil, fmt.Errorf("alternate backend %q must have the path %q of backend %q", alternate, target.Path, backend)
+		}
+		endpoints = append(endpoints, u)
 	}
 
 	transport := http.DefaultTransport.(*http.Transport).Clone()
-	transport.TLSClientConfig = &tls.Config{
-		Certificates: []tls.Certificate{cert},
-		RootCAs:      caCertPool,
-	}
+	transport.TLSClientConfig = tlsConfig
 	proxy := httputil.NewSingleHostReverseProxy(target)
-	proxy.Transport = transport
+	// propagate the trace context of the request to the backend, using the global tracer provider.
+	proxy.Transport = otelhttp.NewTransport(transport, otelhttp.WithPropagators(traces.Propagators()))
+	if len(endpoints) > 1 {
+		proxy.Transport = newFailoverTransport(endpoints, policy, proxy.Transport)
+	}
 
 	return &KCPProxy{proxy: proxy, backend: backend}, nil
 }
 
 // ProxyHandler extracts the CN as a user name and Organizations as groups from
-// the client cert and adds them as HTTP headers to backend request.
-func ProxyHandler(p *KCPProxy, UserHeader, GroupHeader string) func(wr http.ResponseWriter, req *http.Request) {
+// the client cert and adds them as HTTP headers to backend request. Any
+// authentication headers sent by the client are dropped, so that they cannot be
+// spoofed by clients authenticating with a bearer token, e.g. from an exec
+// credential plugin, which is passed through to the backend.
+func ProxyHandler(p *KCPProxy, userHeader, groupHeader, extraHeaderPrefix string) func(wr http.ResponseWriter, req *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
+		removeClientCertAuthHeaders(r.Header, userHeader, groupHeader, extraHeaderPrefix)
 		if u, ok := request.UserFrom(r.Context()); ok {
-			appendClientCertAuthHeaders(r.Header, u, UserHeader, GroupHeader)
+			appendClientCertAuthHeaders(r.Header, u, userHeader, groupHeader, extraHeaderPrefix)
 		}
 		if klog.V(6).Enabled() {
 			klog.Infof("%s %s (%s -> %s) ", r.Method, r.RequestURI, r.RemoteAddr, p.backend)
@@ -83,10 +90,26 @@ func ProxyHandler(p *KCPProxy, UserHeader, GroupHeader string) func(wr http.Resp
 	}
 }
 
-func appendClientCertAuthHeaders(header http.Header, user userinfo.Info, UserHeader, GroupHeader string) {
-	header.Set(UserHeader, user.GetName())
+func removeClientCertAuthHeaders(header http.Header, userHeader, groupHeader, extraHeaderPrefix string) {
+	header.Del(userHeader)
+	header.Del(groupHeader)
+	for key := range header {
+		if strings.HasPrefix(strings.ToLower(key), strings.ToLower(extraHeaderPrefix)) {
+			header.Del(key)
+		}
+	}
+}
+
+func appendClientCertAuthHeaders(header http.Header, user userinfo.Info, userHeader, groupHeader, extraHeaderPrefix string) {
+	header.Set(userHeader, user.GetName())
 
 	for _, group := range user.GetGroups() {
-		header.Add(GroupHeader, group)
+		header.Add(groupHeader, group)
+	}
+
+	for key, values := range user.GetExtra() {
+		for _, value := range values {
+			header.Add(extraHeaderPrefix+url.PathEscape(key), value)
+		}
 	}
 }
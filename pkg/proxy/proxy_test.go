@@ -0,0 +1,82 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	"k8s.io/apiserver/pkg/authentication/user"
+)
+
+func TestWithWorkspaceScopedImpersonation(t *testing.T) {
+	tests := []struct {
+		name       string
+		path       string
+		header     http.Header
+		wantStatus int
+	}{
+		{"no impersonation", "/api/v1/namespaces", nil, http.StatusOK},
+		{"no impersonation across workspaces", "/clusters/*/api/v1/namespaces", nil, http.StatusOK},
+		{"impersonation in workspace", "/clusters/root:org:ws/api/v1/namespaces", http.Header{authenticationv1.ImpersonateUserHeader: {"anna"}}, http.StatusOK},
+		{"group impersonation in workspace", "/clusters/root:org/api", http.Header{authenticationv1.ImpersonateGroupHeader: {"team"}}, http.StatusOK},
+		{"impersonation without workspace", "/api/v1/namespaces", http.Header{authenticationv1.ImpersonateUserHeader: {"anna"}}, http.StatusForbidden},
+		{"impersonation across workspaces", "/clusters/*/api/v1/namespaces", http.Header{authenticationv1.ImpersonateUserHeader: {"anna"}}, http.StatusForbidden},
+		{"extra impersonation across workspaces", "/clusters/*/api/v1/namespaces", http.Header{authenticationv1.ImpersonateUserExtraHeaderPrefix + "Scopes": {"foo"}}, http.StatusForbidden},
+		{"impersonation of virtual workspace", "/services/workspaces/root:org/personal/apis", http.Header{authenticationv1.ImpersonateUserHeader: {"anna"}}, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := WithWorkspaceScopedImpersonation(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			for key, values := range tt.header {
+				req.Header[key] = values
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+			require.Equal(t, tt.wantStatus, w.Code)
+		})
+	}
+}
+
+func TestClientCertAuthHeaders(t *testing.T) {
+	header := http.Header{}
+	header.Set("X-Remote-User", "spoofed")
+	header.Add("X-Remote-Group", "system:masters")
+	header.Set("X-Remote-Extra-Foo", "bar")
+	header.Set("Authorization", "Bearer token")
+
+	removeClientCertAuthHeaders(header, "X-Remote-User", "X-Remote-Group", "X-Remote-Extra-")
+	require.Equal(t, http.Header{"Authorization": {"Bearer token"}}, header)
+
+	appendClientCertAuthHeaders(header, &user.DefaultInfo{
+		Name:   "anna",
+		Groups: []string{"team-a", "team-b"},
+		Extra:  map[string][]string{"example.com/scope": {"ws"}},
+	}, "X-Remote-User", "X-Remote-Group", "X-Remote-Extra-")
+	require.Equal(t, "anna", header.Get("X-Remote-User"))
+	require.Equal(t, []string{"team-a", "team-b"}, header.Values("X-Remote-Group"))
+	require.Equal(t, []string{"ws"}, header.Values("X-Remote-Extra-example.com%2Fscope"))
+	require.Equal(t, "Bearer token", header.Get("Authorization"))
+}
@@ -0,0 +1,35 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package proxy
+
+import (
+	"net/http"
+
+	tenancypath "github.com/kcp-dev/kcp/pkg/apis/tenancy/path"
+	"github.com/kcp-dev/kcp/pkg/tracing"
+)
+
+// WithClusterTracing annotates the span of the request with the workspace of /clusters/<workspace>/...
+// paths. Other paths, e.g. of virtual workspaces, are traced without workspace.
+func WithClusterTracing(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if clusterName, _, ok := tenancypath.FromURLPath(req.URL.Path); ok {
+			tracing.AnnotateSpan(req.Context(), clusterName)
+		}
+		handler.ServeHTTP(w, req)
+	})
+}
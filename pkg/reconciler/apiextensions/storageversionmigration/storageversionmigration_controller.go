@@ -0,0 +1,186 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storageversionmigration
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/kcp-dev/logicalcluster"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apiextensionsclient "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	apiextensionsinformers "k8s.io/apiextensions-apiserver/pkg/client/informers/externalversions/apiextensions/v1"
+	apiextensionslisters "k8s.io/apiextensions-apiserver/pkg/client/listers/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+
+	"github.com/kcp-dev/kcp/pkg/apis/apis"
+	"github.com/kcp-dev/kcp/pkg/apis/tenancy"
+	"github.com/kcp-dev/kcp/pkg/apis/workload"
+	"github.com/kcp-dev/kcp/pkg/logging"
+)
+
+const (
+	controllerName = "kcp-storage-version-migration"
+
+	listPageSize = 500
+)
+
+// migratedGroups are the API groups of the system CRDs whose objects are migrated.
+var migratedGroups = sets.NewString(tenancy.GroupName, workload.GroupName, apis.GroupName)
+
+// NewController returns a new controller that migrates the persisted objects of the kcp system CRDs
+// in the given logical cluster to the current storage version when it changes, e.g. when a new kcp
+// release graduates an API to a new version. Objects are rewritten in all logical clusters, and the
+// CRD's status.storedVersions is pruned afterwards.
+func NewController(
+	crdClusterClient apiextensionsclient.ClusterInterface,
+	dynamicClusterClient dynamic.ClusterInterface,
+	crdInformer apiextensionsinformers.CustomResourceDefinitionInformer,
+	systemCRDClusterName logicalcluster.Name,
+) (*controller, error) {
+	queue := workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), controllerName)
+
+	c := &controller{
+		queue:     queue,
+		crdLister: crdInformer.Lister(),
+		listObjects: func(ctx context.Context, gvr schema.GroupVersionResource, opts metav1.ListOptions) (*unstructured.UnstructuredList, error) {
+			return dynamicClusterClient.Cluster(logicalcluster.Wildcard).Resource(gvr).List(ctx, opts)
+		},
+		updateObject: func(ctx context.Context, clusterName logicalcluster.Name, gvr schema.GroupVersionResource, obj *unstructured.Unstructured) error {
+			_, err := dynamicClusterClient.Cluster(clusterName).Resource(gvr).Namespace(obj.GetNamespace()).Update(ctx, obj, metav1.UpdateOptions{})
+			return err
+		},
+		updateCRDStatus: func(ctx context.Context, crd *apiextensionsv1.CustomResourceDefinition) error {
+			_, err := crdClusterClient.Cluster(systemCRDClusterName).ApiextensionsV1().CustomResourceDefinitions().UpdateStatus(ctx, crd, metav1.UpdateOptions{})
+			return err
+		},
+		migratedClusters: map[string]sets.String{},
+	}
+
+	crdInformer.Informer().AddEventHandler(cache.FilteringResourceEventHandler{
+		FilterFunc: func(obj interface{}) bool {
+			crd, ok := obj.(*apiextensionsv1.CustomResourceDefinition)
+			if !ok {
+				return false
+			}
+			return logicalcluster.From(crd) == systemCRDClusterName && migratedGroups.Has(crd.Spec.Group)
+		},
+		Handler: cache.ResourceEventHandlerFuncs{
+			AddFunc:    func(obj interface{}) { c.enqueue(obj) },
+			UpdateFunc: func(_, obj interface{}) { c.enqueue(obj) },
+		},
+	})
+
+	return c, nil
+}
+
+// controller rewrites the objects of system CRDs whose status.storedVersions contains versions other
+// than the storage version.
+type controller struct {
+	queue workqueue.RateLimitingInterface
+
+	crdLister apiextensionslisters.CustomResourceDefinitionLister
+
+	listObjects     func(ctx context.Context, gvr schema.GroupVersionResource, opts metav1.ListOptions) (*unstructured.UnstructuredList, error)
+	updateObject    func(ctx context.Context, clusterName logicalcluster.Name, gvr schema.GroupVersionResource, obj *unstructured.Unstructured) error
+	updateCRDStatus func(ctx context.Context, crd *apiextensionsv1.CustomResourceDefinition) error
+
+	// migratedClustersLock guards migratedClusters.
+	migratedClustersLock sync.Mutex
+	// migratedClusters holds the logical clusters already migrated, keyed by CRD name and target
+	// storage version, such that a failed migration continues where it stopped.
+	migratedClusters map[string]sets.String
+}
+
+func (c *controller) enqueue(obj interface{}) {
+	key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+	if err != nil {
+		runtime.HandleError(err)
+		return
+	}
+	logging.WithQueueKey(logging.NewLogger(controllerName), key).V(2).Info("Queueing CRD")
+	c.queue.Add(key)
+}
+
+// Start starts the controller, which stops when ctx.Done() is closed.
+func (c *controller) Start(ctx context.Context, numThreads int) {
+	defer runtime.HandleCrash()
+	defer c.queue.ShutDown()
+
+	klog.Infof("Starting %s controller", controllerName)
+	defer klog.Infof("Shutting down %s controller", controllerName)
+
+	for i := 0; i < numThreads; i++ {
+		go wait.UntilWithContext(ctx, c.startWorker, time.Second)
+	}
+
+	<-ctx.Done()
+}
+
+func (c *controller) startWorker(ctx context.Context) {
+	for c.processNextWorkItem(ctx) {
+	}
+}
+
+func (c *controller) processNextWorkItem(ctx context.Context) bool {
+	// Wait until there is a new item in the working queue
+	k, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+	key := k.(string)
+
+	logger := logging.WithQueueKey(logging.NewLogger(controllerName), key)
+	ctx = logging.NewContext(ctx, logger)
+
+	// No matter what, tell the queue we're done with this key, to unblock
+	// other workers.
+	defer c.queue.Done(key)
+
+	if err := c.process(ctx, key); err != nil {
+		runtime.HandleError(fmt.Errorf("%q controller failed to sync %q, err: %w", controllerName, key, err))
+		c.queue.AddRateLimited(key)
+		return true
+	}
+	c.queue.Forget(key)
+	return true
+}
+
+func (c *controller) process(ctx context.Context, key string) error {
+	crd, err := c.crdLister.Get(key)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil // object deleted before we handled it
+		}
+		return err
+	}
+
+	return c.reconcile(ctx, crd.DeepCopy())
+}
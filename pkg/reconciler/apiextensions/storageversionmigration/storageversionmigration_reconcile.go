@@ -0,0 +1,130 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storageversionmigration
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/kcp-dev/logicalcluster"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/kcp-dev/kcp/pkg/logging"
+)
+
+func (c *controller) reconcile(ctx context.Context, crd *apiextensionsv1.CustomResourceDefinition) error {
+	storageVersion := ""
+	for _, v := range crd.Spec.Versions {
+		if v.Storage {
+			storageVersion = v.Name
+			break
+		}
+	}
+	if storageVersion == "" {
+		return nil // invalid CRD, nothing we can do
+	}
+
+	if len(crd.Status.StoredVersions) == 0 ||
+		(len(crd.Status.StoredVersions) == 1 && crd.Status.StoredVersions[0] == storageVersion) {
+		return nil // nothing to migrate
+	}
+
+	gvr := schema.GroupVersionResource{Group: crd.Spec.Group, Version: storageVersion, Resource: crd.Spec.Names.Plural}
+	logger := logging.FromContext(ctx).WithValues("storageVersion", storageVersion)
+	logger.Info("Migrating from stored versions to storage version", "storedVersions", crd.Status.StoredVersions)
+
+	objsByCluster, err := c.listObjectsByCluster(ctx, gvr)
+	if err != nil {
+		return err
+	}
+
+	migrationKey := crd.Name + "/" + storageVersion
+	clusterNames := make([]logicalcluster.Name, 0, len(objsByCluster))
+	for clusterName := range objsByCluster {
+		clusterNames = append(clusterNames, clusterName)
+	}
+	sort.Slice(clusterNames, func(i, j int) bool { return clusterNames[i].String() < clusterNames[j].String() })
+	for _, clusterName := range clusterNames {
+		if c.isMigrated(migrationKey, clusterName) {
+			continue
+		}
+		objs := objsByCluster[clusterName]
+		for i := range objs {
+			// an update without changes is enough to store the object in the current storage version
+			if err := c.updateObject(ctx, clusterName, gvr, &objs[i]); err != nil && !errors.IsNotFound(err) && !errors.IsConflict(err) {
+				return fmt.Errorf("failed to migrate %s %s|%s/%s: %w", gvr.GroupResource(), clusterName, objs[i].GetNamespace(), objs[i].GetName(), err)
+			}
+		}
+		c.setMigrated(migrationKey, clusterName)
+		logger.V(2).Info("Migrated objects to storage version", "objectsLogicalCluster", clusterName.String())
+	}
+
+	crd.Status.StoredVersions = []string{storageVersion}
+	if err := c.updateCRDStatus(ctx, crd); err != nil {
+		return err
+	}
+
+	c.migratedClustersLock.Lock()
+	delete(c.migratedClusters, migrationKey)
+	c.migratedClustersLock.Unlock()
+
+	logger.Info("Finished migrating to storage version")
+
+	return nil
+}
+
+// listObjectsByCluster lists all objects of the given resource across logical clusters, grouped by logical cluster.
+func (c *controller) listObjectsByCluster(ctx context.Context, gvr schema.GroupVersionResource) (map[logicalcluster.Name][]unstructured.Unstructured, error) {
+	objsByCluster := map[logicalcluster.Name][]unstructured.Unstructured{}
+	opts := metav1.ListOptions{Limit: listPageSize}
+	for {
+		list, err := c.listObjects(ctx, gvr, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list %s: %w", gvr.GroupResource(), err)
+		}
+		for _, obj := range list.Items {
+			clusterName := logicalcluster.From(&obj)
+			objsByCluster[clusterName] = append(objsByCluster[clusterName], obj)
+		}
+		if list.GetContinue() == "" {
+			return objsByCluster, nil
+		}
+		opts.Continue = list.GetContinue()
+	}
+}
+
+func (c *controller) isMigrated(migrationKey string, clusterName logicalcluster.Name) bool {
+	c.migratedClustersLock.Lock()
+	defer c.migratedClustersLock.Unlock()
+	return c.migratedClusters[migrationKey].Has(clusterName.String())
+}
+
+func (c *controller) setMigrated(migrationKey string, clusterName logicalcluster.Name) {
+	c.migratedClustersLock.Lock()
+	defer c.migratedClustersLock.Unlock()
+	if _, ok := c.migratedClusters[migrationKey]; !ok {
+		c.migratedClusters[migrationKey] = sets.NewString()
+	}
+	c.migratedClusters[migrationKey].Insert(clusterName.String())
+}
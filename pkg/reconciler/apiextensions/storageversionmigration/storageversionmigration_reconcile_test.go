@@ -0,0 +1,170 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storageversionmigration
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/kcp-dev/logicalcluster"
+	"github.com/stretchr/testify/require"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+func newCRD(storageVersion string, storedVersions ...string) *apiextensionsv1.CustomResourceDefinition {
+	return &apiextensionsv1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: "clusterworkspaces.tenancy.kcp.dev"},
+		Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+			Group: "tenancy.kcp.dev",
+			Names: apiextensionsv1.CustomResourceDefinitionNames{Plural: "clusterworkspaces"},
+			Versions: []apiextensionsv1.CustomResourceDefinitionVersion{
+				{Name: "v1alpha1", Storage: storageVersion == "v1alpha1"},
+				{Name: "v1beta1", Storage: storageVersion == "v1beta1"},
+			},
+		},
+		Status: apiextensionsv1.CustomResourceDefinitionStatus{StoredVersions: storedVersions},
+	}
+}
+
+func newObject(clusterName, name string) unstructured.Unstructured {
+	u := unstructured.Unstructured{}
+	u.SetClusterName(clusterName)
+	u.SetName(name)
+	return u
+}
+
+func TestReconcile(t *testing.T) {
+	// two pages of objects in three logical clusters
+	pages := []unstructured.UnstructuredList{
+		{Items: []unstructured.Unstructured{newObject("root", "a"), newObject("root:org", "b")}},
+		{Items: []unstructured.Unstructured{newObject("root:org", "c"), newObject("root:other", "d")}},
+	}
+	pages[0].SetContinue("next")
+
+	tests := map[string]struct {
+		crd         *apiextensionsv1.CustomResourceDefinition
+		updateErrs  map[string]error
+		migrated    []logicalcluster.Name
+		wantUpdated []string
+		wantStored  []string
+		wantError   bool
+	}{
+		"stored in storage version only": {
+			crd: newCRD("v1alpha1", "v1alpha1"),
+		},
+		"no stored versions": {
+			crd: newCRD("v1alpha1"),
+		},
+		"stored in old version": {
+			crd:         newCRD("v1beta1", "v1alpha1", "v1beta1"),
+			wantUpdated: []string{"root|a", "root:org|b", "root:org|c", "root:other|d"},
+			wantStored:  []string{"v1beta1"},
+		},
+		"deleted and conflicting objects are ignored": {
+			crd: newCRD("v1beta1", "v1alpha1", "v1beta1"),
+			updateErrs: map[string]error{
+				"root:org|b": apierrors.NewNotFound(schema.GroupResource{}, "b"),
+				"root:org|c": apierrors.NewConflict(schema.GroupResource{}, "c", errors.New("conflict")),
+			},
+			wantUpdated: []string{"root|a", "root:org|b", "root:org|c", "root:other|d"},
+			wantStored:  []string{"v1beta1"},
+		},
+		"failure keeps stored versions": {
+			crd:         newCRD("v1beta1", "v1alpha1", "v1beta1"),
+			updateErrs:  map[string]error{"root:org|c": errors.New("boom")},
+			wantUpdated: []string{"root|a", "root:org|b", "root:org|c"},
+			wantError:   true,
+		},
+		"already migrated clusters are skipped": {
+			crd:         newCRD("v1beta1", "v1alpha1", "v1beta1"),
+			migrated:    []logicalcluster.Name{logicalcluster.New("root"), logicalcluster.New("root:org")},
+			wantUpdated: []string{"root:other|d"},
+			wantStored:  []string{"v1beta1"},
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var updated []string
+			var stored []string
+			c := &controller{
+				listObjects: func(ctx context.Context, gvr schema.GroupVersionResource, opts metav1.ListOptions) (*unstructured.UnstructuredList, error) {
+					require.Equal(t, schema.GroupVersionResource{Group: "tenancy.kcp.dev", Version: "v1beta1", Resource: "clusterworkspaces"}, gvr)
+					if opts.Continue == "" {
+						return pages[0].DeepCopy(), nil
+					}
+					return pages[1].DeepCopy(), nil
+				},
+				updateObject: func(ctx context.Context, clusterName logicalcluster.Name, gvr schema.GroupVersionResource, obj *unstructured.Unstructured) error {
+					key := clusterName.String() + "|" + obj.GetName()
+					updated = append(updated, key)
+					return tc.updateErrs[key]
+				},
+				updateCRDStatus: func(ctx context.Context, crd *apiextensionsv1.CustomResourceDefinition) error {
+					stored = crd.Status.StoredVersions
+					return nil
+				},
+				migratedClusters: map[string]sets.String{},
+			}
+			for _, clusterName := range tc.migrated {
+				c.setMigrated("clusterworkspaces.tenancy.kcp.dev/v1beta1", clusterName)
+			}
+
+			err := c.reconcile(context.Background(), tc.crd)
+			if tc.wantError {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, tc.wantUpdated, updated)
+			require.Equal(t, tc.wantStored, stored)
+		})
+	}
+}
+
+func TestReconcileResumesAfterFailure(t *testing.T) {
+	failing := true
+	var updated []string
+	c := &controller{
+		listObjects: func(ctx context.Context, gvr schema.GroupVersionResource, opts metav1.ListOptions) (*unstructured.UnstructuredList, error) {
+			return &unstructured.UnstructuredList{Items: []unstructured.Unstructured{newObject("root", "a"), newObject("root:org", "b")}}, nil
+		},
+		updateObject: func(ctx context.Context, clusterName logicalcluster.Name, gvr schema.GroupVersionResource, obj *unstructured.Unstructured) error {
+			updated = append(updated, clusterName.String()+"|"+obj.GetName())
+			if failing && obj.GetName() == "b" {
+				return errors.New("boom")
+			}
+			return nil
+		},
+		updateCRDStatus: func(ctx context.Context, crd *apiextensionsv1.CustomResourceDefinition) error {
+			return nil
+		},
+		migratedClusters: map[string]sets.String{},
+	}
+
+	require.Error(t, c.reconcile(context.Background(), newCRD("v1beta1", "v1alpha1", "v1beta1")))
+	failing = false
+	require.NoError(t, c.reconcile(context.Background(), newCRD("v1beta1", "v1alpha1", "v1beta1")))
+	require.Equal(t, []string{"root|a", "root:org|b", "root:org|b"}, updated)
+	require.Empty(t, c.migratedClusters, "tracking should be cleared after the migration finished")
+}
@@ -42,9 +42,11 @@ import (
 	"k8s.io/klog/v2"
 
 	apisv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apis/v1alpha1"
+	cacheclient "github.com/kcp-dev/kcp/pkg/cache/client"
 	kcpclient "github.com/kcp-dev/kcp/pkg/client/clientset/versioned"
 	apisinformers "github.com/kcp-dev/kcp/pkg/client/informers/externalversions/apis/v1alpha1"
 	apislisters "github.com/kcp-dev/kcp/pkg/client/listers/apis/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/logging"
 )
 
 const (
@@ -59,6 +61,7 @@ var (
 func NewController(
 	crdClusterClient apiextensionclientset.ClusterInterface,
 	kcpClusterClient kcpclient.ClusterInterface,
+	cacheClient *cacheclient.Client,
 	apiBindingInformer apisinformers.APIBindingInformer,
 	apiExportInformer apisinformers.APIExportInformer,
 	apiResourceSchemaInformer apisinformers.APIResourceSchemaInformer,
@@ -94,7 +97,12 @@ func NewController(
 		apiBindingsIndexer: apiBindingInformer.Informer().GetIndexer(),
 
 		getAPIExport: func(clusterName logicalcluster.Name, name string) (*apisv1alpha1.APIExport, error) {
-			return apiExportInformer.Lister().Get(clusters.ToClusterAwareKey(clusterName, name))
+			apiExport, err := apiExportInformer.Lister().Get(clusters.ToClusterAwareKey(clusterName, name))
+			if !errors.IsNotFound(err) {
+				return apiExport, err
+			}
+			// the APIExport might live on another shard
+			return cacheClient.GetAPIExport(context.TODO(), clusterName, name)
 		},
 		apiExportsIndexer: apiExportInformer.Informer().GetIndexer(),
 
@@ -109,14 +117,51 @@ func NewController(
 		getCRD: func(clusterName logicalcluster.Name, name string) (*apiextensionsv1.CustomResourceDefinition, error) {
 			return crdInformer.Lister().Get(clusters.ToClusterAwareKey(clusterName, name))
 		},
+		listCRDs: func(clusterName logicalcluster.Name) ([]*apiextensionsv1.CustomResourceDefinition, error) {
+			objs, err := crdInformer.Informer().GetIndexer().ByIndex(indexCRDsByLogicalCluster, clusterName.String())
+			if err != nil {
+				return nil, err
+			}
+
+			ret := make([]*apiextensionsv1.CustomResourceDefinition, 0, len(objs))
+			for _, obj := range objs {
+				ret = append(ret, obj.(*apiextensionsv1.CustomResourceDefinition))
+			}
+
+			return ret, nil
+		},
 		crdIndexer:        crdInformer.Informer().GetIndexer(),
 		deletedCRDTracker: newLockedStringSet(),
 	}
 
 	apiBindingInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
-		AddFunc:    func(obj interface{}) { c.enqueueAPIBinding(obj) },
-		UpdateFunc: func(_, obj interface{}) { c.enqueueAPIBinding(obj) },
-		DeleteFunc: func(obj interface{}) { c.enqueueAPIBinding(obj) },
+		AddFunc: func(obj interface{}) { c.enqueueAPIBinding(obj) },
+		UpdateFunc: func(oldObj, obj interface{}) {
+			c.enqueueAPIBinding(obj)
+
+			// the outcome of conflicts with other APIBindings might have changed
+			old, ok := oldObj.(*apisv1alpha1.APIBinding)
+			if !ok {
+				return
+			}
+			apiBinding, ok := obj.(*apisv1alpha1.APIBinding)
+			if !ok {
+				return
+			}
+			if old.Spec.Priority != apiBinding.Spec.Priority || !equality.Semantic.DeepEqual(old.Status.BoundResources, apiBinding.Status.BoundResources) {
+				c.enqueueAPIBindingsInCluster(logicalcluster.From(apiBinding), apiBinding.Name)
+			}
+		},
+		DeleteFunc: func(obj interface{}) {
+			c.enqueueAPIBinding(obj)
+
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			if apiBinding, ok := obj.(*apisv1alpha1.APIBinding); ok {
+				c.enqueueAPIBindingsInCluster(logicalcluster.From(apiBinding), apiBinding.Name)
+			}
+		},
 	})
 
 	if err := apiBindingInformer.Informer().AddIndexers(cache.Indexers{
@@ -126,6 +171,12 @@ func NewController(
 		return nil, err
 	}
 
+	if err := crdInformer.Informer().AddIndexers(cache.Indexers{
+		indexCRDsByLogicalCluster: indexCRDsByLogicalClusterFunc,
+	}); err != nil {
+		return nil, err
+	}
+
 	crdInformer.Informer().AddEventHandler(cache.FilteringResourceEventHandler{
 		FilterFunc: func(obj interface{}) bool {
 			crd, ok := obj.(*apiextensionsv1.CustomResourceDefinition)
@@ -155,6 +206,30 @@ func NewController(
 		},
 	})
 
+	// CRDs in the workspace of an APIBinding might be shadowed by it
+	crdInformer.Informer().AddEventHandler(cache.FilteringResourceEventHandler{
+		FilterFunc: func(obj interface{}) bool {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			crd, ok := obj.(*apiextensionsv1.CustomResourceDefinition)
+			if !ok {
+				return false
+			}
+
+			return logicalcluster.From(crd) != ShadowWorkspaceName
+		},
+		Handler: cache.ResourceEventHandlerFuncs{
+			AddFunc: func(obj interface{}) { c.enqueueLocalCRD(obj) },
+			DeleteFunc: func(obj interface{}) {
+				if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+					obj = tombstone.Obj
+				}
+				c.enqueueLocalCRD(obj)
+			},
+		},
+	})
+
 	apiResourceSchemaInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
 		AddFunc:    func(obj interface{}) { c.enqueueAPIResourceSchema(obj) },
 		UpdateFunc: func(_, obj interface{}) { c.enqueueAPIResourceSchema(obj) },
@@ -198,6 +273,7 @@ type controller struct {
 
 	createCRD  func(ctx context.Context, clusterName logicalcluster.Name, crd *apiextensionsv1.CustomResourceDefinition) (*apiextensionsv1.CustomResourceDefinition, error)
 	getCRD     func(clusterName logicalcluster.Name, name string) (*apiextensionsv1.CustomResourceDefinition, error)
+	listCRDs   func(clusterName logicalcluster.Name) ([]*apiextensionsv1.CustomResourceDefinition, error)
 	crdIndexer cache.Indexer
 
 	deletedCRDTracker *lockedStringSet
@@ -211,10 +287,37 @@ func (c *controller) enqueueAPIBinding(obj interface{}) {
 		return
 	}
 
-	klog.V(2).Infof("Queueing APIBinding %q", key)
+	logging.WithQueueKey(logging.NewLogger(controllerName), key).V(2).Info("Queueing APIBinding")
 	c.queue.Add(key)
 }
 
+// enqueueAPIBindingsInCluster enqueues all APIBindings in the given logical cluster, except the given one.
+func (c *controller) enqueueAPIBindingsInCluster(clusterName logicalcluster.Name, except string) {
+	apiBindings, err := c.listAPIBindings(clusterName)
+	if err != nil {
+		runtime.HandleError(err)
+		return
+	}
+
+	for _, apiBinding := range apiBindings {
+		if apiBinding.Name == except {
+			continue
+		}
+		c.enqueueAPIBinding(apiBinding)
+	}
+}
+
+// enqueueLocalCRD maps a CRD outside of the shadow workspace to the APIBindings in its logical cluster for enqueuing.
+func (c *controller) enqueueLocalCRD(obj interface{}) {
+	crd, ok := obj.(*apiextensionsv1.CustomResourceDefinition)
+	if !ok {
+		runtime.HandleError(fmt.Errorf("obj is supposed to be a CustomResourceDefinition, but is %T", obj))
+		return
+	}
+
+	c.enqueueAPIBindingsInCluster(logicalcluster.From(crd), "")
+}
+
 // enqueueAPIExport enqueues maps an APIExport to APIBindings for enqueuing.
 func (c *controller) enqueueAPIExport(obj interface{}) {
 	key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
@@ -223,7 +326,7 @@ func (c *controller) enqueueAPIExport(obj interface{}) {
 		return
 	}
 
-	klog.V(2).Infof("Mapping APIExport %q", key)
+	logging.WithQueueKey(logging.NewLogger(controllerName), key).V(2).Info("Mapping APIExport")
 	bindingsForExport, err := c.apiBindingsIndexer.ByIndex(indexAPIBindingsByWorkspaceExport, key)
 	if err != nil {
 		runtime.HandleError(err)
@@ -304,6 +407,9 @@ func (c *controller) processNextWorkItem(ctx context.Context) bool {
 	}
 	key := k.(string)
 
+	logger := logging.WithQueueKey(logging.NewLogger(controllerName), key)
+	ctx = logging.NewContext(ctx, logger)
+
 	// No matter what, tell the queue we're done with this key, to unblock
 	// other workers.
 	defer c.queue.Done(key)
@@ -320,7 +426,7 @@ func (c *controller) processNextWorkItem(ctx context.Context) bool {
 func (c *controller) process(ctx context.Context, key string) error {
 	namespace, clusterAwareName, err := cache.SplitMetaNamespaceKey(key)
 	if err != nil {
-		klog.Errorf("invalid key: %q: %v", key, err)
+		logging.FromContext(ctx).Error(err, "Invalid key")
 		return nil
 	}
 	clusterName, name := clusters.SplitClusterAwareKey(clusterAwareName)
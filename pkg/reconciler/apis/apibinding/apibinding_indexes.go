@@ -21,6 +21,7 @@ import (
 
 	"github.com/kcp-dev/logicalcluster"
 
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	"k8s.io/client-go/tools/clusters"
 
 	apisv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apis/v1alpha1"
@@ -37,11 +38,10 @@ func indexAPIBindingsByWorkspaceExportFunc(obj interface{}) ([]string, error) {
 	}
 
 	if apiBinding.Spec.Reference.Workspace != nil {
-		parent, hasParent := logicalcluster.From(apiBinding).Parent()
-		if !hasParent {
+		apiExportClusterName, ok := apiBinding.Spec.Reference.Workspace.ClusterName(logicalcluster.From(apiBinding))
+		if !ok {
 			return []string{}, fmt.Errorf("an APIBinding in %s cannot reference a workspace", logicalcluster.From(apiBinding))
 		}
-		apiExportClusterName := parent.Join(apiBinding.Spec.Reference.Workspace.WorkspaceName)
 		key := clusters.ToClusterAwareKey(apiExportClusterName, apiBinding.Spec.Reference.Workspace.ExportName)
 		return []string{key}, nil
 	}
@@ -66,6 +66,18 @@ func indexAPIExportsByAPIResourceSchemasFunc(obj interface{}) ([]string, error)
 	return ret, nil
 }
 
+const indexCRDsByLogicalCluster = "crdsByLogicalCluster"
+
+// indexCRDsByLogicalClusterFunc is an index function that maps a CRD to its logical cluster.
+func indexCRDsByLogicalClusterFunc(obj interface{}) ([]string, error) {
+	crd, ok := obj.(*apiextensionsv1.CustomResourceDefinition)
+	if !ok {
+		return []string{}, fmt.Errorf("obj is supposed to be a CustomResourceDefinition, but is %T", obj)
+	}
+
+	return []string{logicalcluster.From(crd).String()}, nil
+}
+
 const IndexAPIBindingsByIdentityGroupResource = "apiBindingsByIdentityGroupResource"
 
 func indexAPIBindingsByIdentityGroupResourceFunc(obj interface{}) ([]string, error) {
@@ -21,6 +21,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"sort"
+	"strings"
 
 	"github.com/kcp-dev/logicalcluster"
 
@@ -29,9 +30,10 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/sets"
-	"k8s.io/klog/v2"
 
 	apisv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apis/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/logging"
+	"github.com/kcp-dev/kcp/pkg/schemaconversion"
 	conditionsv1alpha1 "github.com/kcp-dev/kcp/third_party/conditions/apis/conditions/v1alpha1"
 	"github.com/kcp-dev/kcp/third_party/conditions/util/conditions"
 )
@@ -45,6 +47,7 @@ func (c *controller) reconcile(ctx context.Context, apiBinding *apisv1alpha1.API
 		conditions.WithConditions(
 			apisv1alpha1.InitialBindingCompleted,
 		),
+		conditions.WithObservedGeneration(),
 	)
 
 	switch apiBinding.Status.Phase {
@@ -55,7 +58,7 @@ func (c *controller) reconcile(ctx context.Context, apiBinding *apisv1alpha1.API
 	case apisv1alpha1.APIBindingPhaseBound:
 		return c.reconcileBound(ctx, apiBinding)
 	default:
-		klog.Errorf("Invalid phase %q for APIBinding %s|%s", apiBinding.Status.Phase, apiBinding.ClusterName, apiBinding.Name)
+		logging.FromContext(ctx).Error(nil, "Invalid APIBinding phase", "phase", apiBinding.Status.Phase)
 		return nil
 	}
 }
@@ -63,7 +66,7 @@ func (c *controller) reconcile(ctx context.Context, apiBinding *apisv1alpha1.API
 func (c *controller) reconcileNew(ctx context.Context, apiBinding *apisv1alpha1.APIBinding) error {
 	apiBinding.Status.Phase = apisv1alpha1.APIBindingPhaseBinding
 
-	conditions.MarkFalse(
+	conditions.MarkFalseObserved(
 		apiBinding,
 		apisv1alpha1.InitialBindingCompleted,
 		apisv1alpha1.WaitingForEstablishedReason,
@@ -75,10 +78,12 @@ func (c *controller) reconcileNew(ctx context.Context, apiBinding *apisv1alpha1.
 }
 
 func (c *controller) reconcileBinding(ctx context.Context, apiBinding *apisv1alpha1.APIBinding) error {
+	logger := logging.FromContext(ctx)
+
 	workspaceRef := apiBinding.Spec.Reference.Workspace
 	if workspaceRef == nil {
 		// this should not happen because of OpenAPI
-		conditions.MarkFalse(
+		conditions.MarkFalseObserved(
 			apiBinding,
 			apisv1alpha1.APIExportValid,
 			apisv1alpha1.APIExportInvalidReferenceReason,
@@ -91,7 +96,7 @@ func (c *controller) reconcileBinding(ctx context.Context, apiBinding *apisv1alp
 	apiExportClusterName, err := getAPIExportClusterName(apiBinding)
 	if err != nil {
 		// this should not happen because of OpenAPI
-		conditions.MarkFalse(
+		conditions.MarkFalseObserved(
 			apiBinding,
 			apisv1alpha1.APIExportValid,
 			apisv1alpha1.APIExportInvalidReferenceReason,
@@ -103,7 +108,7 @@ func (c *controller) reconcileBinding(ctx context.Context, apiBinding *apisv1alp
 
 	apiExport, err := c.getAPIExport(apiExportClusterName, workspaceRef.ExportName)
 	if apierrors.IsNotFound(err) {
-		conditions.MarkFalse(
+		conditions.MarkFalseObserved(
 			apiBinding,
 			apisv1alpha1.APIExportValid,
 			apisv1alpha1.APIExportNotFoundReason,
@@ -115,7 +120,7 @@ func (c *controller) reconcileBinding(ctx context.Context, apiBinding *apisv1alp
 		return nil
 	}
 	if err != nil {
-		conditions.MarkFalse(
+		conditions.MarkFalseObserved(
 			apiBinding,
 			apisv1alpha1.APIExportValid,
 			apisv1alpha1.InternalErrorReason,
@@ -129,7 +134,7 @@ func (c *controller) reconcileBinding(ctx context.Context, apiBinding *apisv1alp
 	}
 
 	if apiExport.Status.IdentityHash == "" {
-		conditions.MarkFalse(
+		conditions.MarkFalseObserved(
 			apiBinding,
 			apisv1alpha1.APIExportValid,
 			"MissingIdentityHash",
@@ -142,20 +147,15 @@ func (c *controller) reconcileBinding(ctx context.Context, apiBinding *apisv1alp
 	}
 
 	var boundResources []apisv1alpha1.BoundAPIResource
+	var localConflicts []string
 	needToWaitForRequeue := false
 
 	for _, schemaName := range apiExport.Spec.LatestResourceSchemas {
 		schema, err := c.getAPIResourceSchema(apiExportClusterName, schemaName)
 		if err != nil {
-			klog.Errorf(
-				"Error binding APIBinding %s|%s, APIExport %s|%s, APIResourceSchema %s|%s: %v",
-				apiBinding.ClusterName, apiBinding.Name,
-				apiExport.ClusterName, apiExport.Name,
-				apiExport.ClusterName, schemaName,
-				err,
-			)
+			logger.Error(err, "Error binding APIBinding", "apiExportCluster", apiExport.ClusterName, "apiExport", apiExport.Name, "apiResourceSchema", schemaName)
 
-			conditions.MarkFalse(
+			conditions.MarkFalseObserved(
 				apiBinding,
 				apisv1alpha1.APIExportValid,
 				apisv1alpha1.InternalErrorReason,
@@ -172,15 +172,9 @@ func (c *controller) reconcileBinding(ctx context.Context, apiBinding *apisv1alp
 
 		crd, err := generateCRD(schema)
 		if err != nil {
-			klog.Errorf(
-				"Error generating CRD for APIBinding %s|%s, APIExport %s|%s, APIResourceSchema %s|%s: %v",
-				apiBinding.ClusterName, apiBinding.Name,
-				apiExport.ClusterName, apiExport.Name,
-				apiExport.ClusterName, schemaName,
-				err,
-			)
+			logger.Error(err, "Error generating CRD", "apiExportCluster", apiExport.ClusterName, "apiExport", apiExport.Name, "apiResourceSchema", schemaName)
 
-			conditions.MarkFalse(
+			conditions.MarkFalseObserved(
 				apiBinding,
 				apisv1alpha1.APIExportValid,
 				apisv1alpha1.InternalErrorReason,
@@ -197,10 +191,11 @@ func (c *controller) reconcileBinding(ctx context.Context, apiBinding *apisv1alp
 			getAPIExport:         c.getAPIExport,
 			getAPIResourceSchema: c.getAPIResourceSchema,
 			getCRD:               c.getCRD,
+			listCRDs:             c.listCRDs,
 		}
 
 		if err := nameConflictChecker.checkForConflicts(crd, apiBinding); err != nil {
-			conditions.MarkFalse(
+			conditions.MarkFalseObserved(
 				apiBinding,
 				apisv1alpha1.BindingUpToDate,
 				apisv1alpha1.NamingConflictsReason,
@@ -208,10 +203,18 @@ func (c *controller) reconcileBinding(ctx context.Context, apiBinding *apisv1alp
 				"Unable to bind APIs: %v",
 				err,
 			)
+			conditions.MarkFalseObserved(
+				apiBinding,
+				apisv1alpha1.ConflictFree,
+				apisv1alpha1.NamingConflictsReason,
+				conditionsv1alpha1.ConditionSeverityError,
+				"Unable to bind APIs: %v",
+				err,
+			)
 
 			// Only change InitialBindingCompleted if it's false
 			if conditions.IsFalse(apiBinding, apisv1alpha1.InitialBindingCompleted) {
-				conditions.MarkFalse(
+				conditions.MarkFalseObserved(
 					apiBinding,
 					apisv1alpha1.InitialBindingCompleted,
 					apisv1alpha1.NamingConflictsReason,
@@ -223,9 +226,15 @@ func (c *controller) reconcileBinding(ctx context.Context, apiBinding *apisv1alp
 			return nil
 		}
 
+		conflicts, err := nameConflictChecker.checkForLocalConflicts(crd, apiBinding)
+		if err != nil {
+			return err
+		}
+		localConflicts = append(localConflicts, conflicts...)
+
 		existingCRD, err := c.getCRD(ShadowWorkspaceName, crd.Name)
 		if err != nil && !apierrors.IsNotFound(err) {
-			conditions.MarkFalse(
+			conditions.MarkFalseObserved(
 				apiBinding,
 				apisv1alpha1.APIExportValid,
 				apisv1alpha1.InternalErrorReason,
@@ -246,7 +255,7 @@ func (c *controller) reconcileBinding(ctx context.Context, apiBinding *apisv1alp
 		// The crd was deleted and needs to be recreated. `existingCRD` might be non-nil if
 		// the lister is behind, so explicitly set to nil to ensure recreation.
 		if c.deletedCRDTracker.Has(crd.Name) {
-			klog.V(4).Infof("Bound CRD %s|%s was deleted - need to recreate", ShadowWorkspaceName, crd.Name)
+			logger.V(4).Info("Bound CRD was deleted - need to recreate", "crd", crd.Name)
 			existingCRD = nil
 		}
 
@@ -254,7 +263,7 @@ func (c *controller) reconcileBinding(ctx context.Context, apiBinding *apisv1alp
 			// Create flow
 
 			if _, err := c.createCRD(ctx, ShadowWorkspaceName, crd); err != nil {
-				conditions.MarkFalse(
+				conditions.MarkFalseObserved(
 					apiBinding,
 					apisv1alpha1.BindingUpToDate,
 					apisv1alpha1.InternalErrorReason,
@@ -263,7 +272,7 @@ func (c *controller) reconcileBinding(ctx context.Context, apiBinding *apisv1alp
 				)
 				// Only change InitialBindingCompleted if it's false
 				if conditions.IsFalse(apiBinding, apisv1alpha1.InitialBindingCompleted) {
-					conditions.MarkFalse(
+					conditions.MarkFalseObserved(
 						apiBinding,
 						apisv1alpha1.InitialBindingCompleted,
 						apisv1alpha1.InternalErrorReason,
@@ -273,13 +282,7 @@ func (c *controller) reconcileBinding(ctx context.Context, apiBinding *apisv1alp
 				}
 
 				if apierrors.IsInvalid(err) {
-					klog.Errorf(
-						"Error creating CRD for APIBinding %s|%s, APIExport %s|%s, APIResourceSchema %s|%s: %v",
-						apiBinding.ClusterName, apiBinding.Name,
-						apiExport.ClusterName, apiExport.Name,
-						apiExport.ClusterName, schemaName,
-						err,
-					)
+					logger.Error(err, "Error creating CRD", "apiExportCluster", apiExport.ClusterName, "apiExport", apiExport.Name, "apiResourceSchema", schemaName)
 
 					return nil
 				}
@@ -325,13 +328,14 @@ func (c *controller) reconcileBinding(ctx context.Context, apiBinding *apisv1alp
 		})
 	}
 
-	conditions.MarkTrue(apiBinding, apisv1alpha1.APIExportValid)
+	conditions.MarkTrueObserved(apiBinding, apisv1alpha1.APIExportValid)
+	markLocalConflicts(apiBinding, localConflicts)
 
 	apiBinding.Status.BoundAPIExport = &apiBinding.Spec.Reference
 	apiBinding.Status.BoundResources = boundResources
 
 	if needToWaitForRequeue {
-		conditions.MarkFalse(
+		conditions.MarkFalseObserved(
 			apiBinding,
 			apisv1alpha1.BindingUpToDate,
 			apisv1alpha1.WaitingForEstablishedReason,
@@ -340,7 +344,7 @@ func (c *controller) reconcileBinding(ctx context.Context, apiBinding *apisv1alp
 		)
 		// Only change InitialBindingCompleted if it's false
 		if conditions.IsFalse(apiBinding, apisv1alpha1.InitialBindingCompleted) {
-			conditions.MarkFalse(
+			conditions.MarkFalseObserved(
 				apiBinding,
 				apisv1alpha1.InitialBindingCompleted,
 				apisv1alpha1.WaitingForEstablishedReason,
@@ -349,8 +353,8 @@ func (c *controller) reconcileBinding(ctx context.Context, apiBinding *apisv1alp
 			)
 		}
 	} else {
-		conditions.MarkTrue(apiBinding, apisv1alpha1.InitialBindingCompleted)
-		conditions.MarkTrue(apiBinding, apisv1alpha1.BindingUpToDate)
+		conditions.MarkTrueObserved(apiBinding, apisv1alpha1.InitialBindingCompleted)
+		conditions.MarkTrueObserved(apiBinding, apisv1alpha1.BindingUpToDate)
 		apiBinding.Status.Initializers = []string{}
 		apiBinding.Status.Phase = apisv1alpha1.APIBindingPhaseBound
 	}
@@ -359,10 +363,12 @@ func (c *controller) reconcileBinding(ctx context.Context, apiBinding *apisv1alp
 }
 
 func (c *controller) reconcileBound(ctx context.Context, apiBinding *apisv1alpha1.APIBinding) error {
+	logger := logging.FromContext(ctx)
+
 	apiExportClusterName, err := getAPIExportClusterName(apiBinding)
 	if err != nil {
 		// Should never happen
-		conditions.MarkFalse(
+		conditions.MarkFalseObserved(
 			apiBinding,
 			apisv1alpha1.APIExportValid,
 			apisv1alpha1.APIExportNotFoundReason,
@@ -374,7 +380,7 @@ func (c *controller) reconcileBound(ctx context.Context, apiBinding *apisv1alpha
 	}
 
 	if referencedAPIExportChanged(apiBinding) {
-		klog.V(4).Infof("APIBinding %s|%s needs rebinding because it now points to a different APIExport", apiBinding.ClusterName, apiBinding.Name)
+		logger.V(4).Info("APIBinding needs rebinding because it now points to a different APIExport")
 
 		apiBinding.Status.Phase = apisv1alpha1.APIBindingPhaseBinding
 
@@ -383,7 +389,7 @@ func (c *controller) reconcileBound(ctx context.Context, apiBinding *apisv1alpha
 
 	apiExport, err := c.getAPIExport(apiExportClusterName, apiBinding.Spec.Reference.Workspace.ExportName)
 	if apierrors.IsNotFound(err) {
-		conditions.MarkFalse(
+		conditions.MarkFalseObserved(
 			apiBinding,
 			apisv1alpha1.APIExportValid,
 			apisv1alpha1.APIExportNotFoundReason,
@@ -398,7 +404,7 @@ func (c *controller) reconcileBound(ctx context.Context, apiBinding *apisv1alpha
 		return nil
 	}
 	if err != nil {
-		conditions.MarkFalse(
+		conditions.MarkFalseObserved(
 			apiBinding,
 			apisv1alpha1.APIExportValid,
 			apisv1alpha1.InternalErrorReason,
@@ -416,7 +422,7 @@ func (c *controller) reconcileBound(ctx context.Context, apiBinding *apisv1alpha
 	for _, schemaName := range apiExport.Spec.LatestResourceSchemas {
 		apiResourceSchema, err := c.getAPIResourceSchema(apiExportClusterName, schemaName)
 		if err != nil {
-			conditions.MarkFalse(
+			conditions.MarkFalseObserved(
 				apiBinding,
 				apisv1alpha1.APIExportValid,
 				apisv1alpha1.InternalErrorReason,
@@ -436,14 +442,93 @@ func (c *controller) reconcileBound(ctx context.Context, apiBinding *apisv1alpha
 	}
 
 	if apiExportLatestResourceSchemasChanged(apiBinding, exportedSchemas) {
-		klog.V(4).Infof("APIBinding %s|%s needs rebinding because the APIExport's latestResourceSchemas has changed", apiBinding.ClusterName, apiBinding.Name)
+		logger.V(4).Info("APIBinding needs rebinding because the APIExport's latestResourceSchemas has changed")
 
 		apiBinding.Status.Phase = apisv1alpha1.APIBindingPhaseBinding
+
+		return nil
 	}
 
+	return c.reconcileBoundConflicts(apiBinding)
+}
+
+// reconcileBoundConflicts checks the bound APIs for conflicts. Since the binding completed, an APIBinding taking
+// precedence might have been bound, or CRDs might have been created in the workspace.
+func (c *controller) reconcileBoundConflicts(apiBinding *apisv1alpha1.APIBinding) error {
+	var localConflicts []string
+
+	for _, boundResource := range apiBinding.Status.BoundResources {
+		crd, err := c.getCRD(ShadowWorkspaceName, boundResource.Schema.UID)
+		if apierrors.IsNotFound(err) {
+			// the CRD is recreated on rebinding
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		nameConflictChecker := &nameConflictChecker{
+			listAPIBindings:      c.listAPIBindings,
+			getAPIExport:         c.getAPIExport,
+			getAPIResourceSchema: c.getAPIResourceSchema,
+			getCRD:               c.getCRD,
+			listCRDs:             c.listCRDs,
+		}
+
+		if err := nameConflictChecker.checkForConflicts(crd, apiBinding); err != nil {
+			conditions.MarkFalseObserved(
+				apiBinding,
+				apisv1alpha1.BindingUpToDate,
+				apisv1alpha1.NamingConflictsReason,
+				conditionsv1alpha1.ConditionSeverityError,
+				"Unable to serve APIs: %v",
+				err,
+			)
+			conditions.MarkFalseObserved(
+				apiBinding,
+				apisv1alpha1.ConflictFree,
+				apisv1alpha1.NamingConflictsReason,
+				conditionsv1alpha1.ConditionSeverityError,
+				"Unable to serve APIs: %v",
+				err,
+			)
+			return nil
+		}
+
+		conflicts, err := nameConflictChecker.checkForLocalConflicts(crd, apiBinding)
+		if err != nil {
+			return err
+		}
+		localConflicts = append(localConflicts, conflicts...)
+	}
+
+	if conditions.GetReason(apiBinding, apisv1alpha1.BindingUpToDate) == apisv1alpha1.NamingConflictsReason {
+		conditions.MarkTrueObserved(apiBinding, apisv1alpha1.BindingUpToDate)
+	}
+	markLocalConflicts(apiBinding, localConflicts)
+
 	return nil
 }
 
+// markLocalConflicts sets the ConflictFree condition, reporting the given CRDs of the workspace being shadowed by
+// the APIBinding.
+func markLocalConflicts(apiBinding *apisv1alpha1.APIBinding, localConflicts []string) {
+	if len(localConflicts) == 0 {
+		conditions.MarkTrueObserved(apiBinding, apisv1alpha1.ConflictFree)
+		return
+	}
+
+	sort.Strings(localConflicts)
+	conditions.MarkFalseObserved(
+		apiBinding,
+		apisv1alpha1.ConflictFree,
+		apisv1alpha1.LocalCRDConflictReason,
+		conditionsv1alpha1.ConditionSeverityWarning,
+		"APIs take precedence over CustomResourceDefinition(s) %s in this workspace",
+		strings.Join(localConflicts, ", "),
+	)
+}
+
 func generateCRD(schema *apisv1alpha1.APIResourceSchema) (*apiextensionsv1.CustomResourceDefinition, error) {
 	crd := &apiextensionsv1.CustomResourceDefinition{
 		ObjectMeta: metav1.ObjectMeta{
@@ -482,19 +567,29 @@ func generateCRD(schema *apisv1alpha1.APIResourceSchema) (*apiextensionsv1.Custo
 		crd.Spec.Versions = append(crd.Spec.Versions, crdVersion)
 	}
 
+	if len(schema.Spec.Conversions) > 0 {
+		crd.Spec.Conversion = &apiextensionsv1.CustomResourceConversion{
+			Strategy: apiextensionsv1.WebhookConverter,
+			Webhook: &apiextensionsv1.WebhookConversion{
+				ClientConfig:             schemaconversion.WebhookClientConfig(schema),
+				ConversionReviewVersions: []string{"v1"},
+			},
+		}
+	}
+
 	return crd, nil
 }
 
 func getAPIExportClusterName(apiBinding *apisv1alpha1.APIBinding) (logicalcluster.Name, error) {
-	parent, hasParent := logicalcluster.From(apiBinding).Parent()
-	if !hasParent {
-		return logicalcluster.Name{}, fmt.Errorf("a APIBinding in %s cannot reference a workspace", logicalcluster.From(apiBinding))
-	}
 	if apiBinding.Spec.Reference.Workspace == nil {
 		// cannot happen due to APIBinding validation
 		return logicalcluster.Name{}, fmt.Errorf("APIBinding does not specify an APIExport")
 	}
-	return parent.Join(apiBinding.Spec.Reference.Workspace.WorkspaceName), nil
+	clusterName, ok := apiBinding.Spec.Reference.Workspace.ClusterName(logicalcluster.From(apiBinding))
+	if !ok {
+		return logicalcluster.Name{}, fmt.Errorf("a APIBinding in %s cannot reference a workspace", logicalcluster.From(apiBinding))
+	}
+	return clusterName, nil
 }
 
 func referencedAPIExportChanged(apiBinding *apisv1alpha1.APIBinding) bool {
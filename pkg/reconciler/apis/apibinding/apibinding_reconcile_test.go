@@ -202,6 +202,9 @@ func TestReconcileBinding(t *testing.T) {
 		wantPhaseBound                          bool
 		wantBoundResources                      []apisv1alpha1.BoundAPIResource
 		wantNamingConflict                      bool
+		localCRDs                               []*apiextensionsv1.CustomResourceDefinition
+		wantLocalConflict                       bool
+		wantConflictFree                        bool
 		crdEstablished                          bool
 		crdStorageVerions                       []string
 	}{
@@ -314,6 +317,60 @@ func TestReconcileBinding(t *testing.T) {
 			getCRDError:        apierrors.NewNotFound(schema.GroupResource{}, ""),
 			wantNamingConflict: true,
 		},
+		"create CRD - other bindings - conflicts - higher priority wins": {
+			apiBinding: binding.DeepCopy().WithPriority(1).Build(),
+			existingAPIBindings: []*apisv1alpha1.APIBinding{
+				conflicting.Build(),
+			},
+			getCRDError:               apierrors.NewNotFound(schema.GroupResource{}, ""),
+			wantCreateCRD:             true,
+			wantWaitingForEstablished: true,
+			wantAPIExportValid:        true,
+			wantBoundAPIExport:        true,
+			wantConflictFree:          true,
+			wantBoundResources: []apisv1alpha1.BoundAPIResource{
+				{
+					Group:    "kcp.dev",
+					Resource: "widgets",
+					Schema: apisv1alpha1.BoundAPIResourceSchema{
+						Name:         "today.widgets.kcp.dev",
+						UID:          "todaywidgetsuid",
+						IdentityHash: "hash1",
+					},
+					StorageVersions: []string{},
+				},
+			},
+		},
+		"create CRD - local CRD conflicts": {
+			apiBinding: binding.Build(),
+			localCRDs: []*apiextensionsv1.CustomResourceDefinition{
+				{
+					ObjectMeta: metav1.ObjectMeta{ClusterName: "org:ws", Name: "widgets.kcp.dev"},
+					Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+						Group: "kcp.dev",
+						Names: apiextensionsv1.CustomResourceDefinitionNames{Plural: "widgets"},
+					},
+				},
+			},
+			getCRDError:               apierrors.NewNotFound(schema.GroupResource{}, ""),
+			wantCreateCRD:             true,
+			wantWaitingForEstablished: true,
+			wantAPIExportValid:        true,
+			wantBoundAPIExport:        true,
+			wantLocalConflict:         true,
+			wantBoundResources: []apisv1alpha1.BoundAPIResource{
+				{
+					Group:    "kcp.dev",
+					Resource: "widgets",
+					Schema: apisv1alpha1.BoundAPIResourceSchema{
+						Name:         "today.widgets.kcp.dev",
+						UID:          "todaywidgetsuid",
+						IdentityHash: "hash1",
+					},
+					StorageVersions: []string{},
+				},
+			},
+		},
 		"bind existing CRD - other bindings - conflicts": {
 			apiBinding: binding.Build(),
 			crdExists:  true,
@@ -501,6 +558,10 @@ func TestReconcileBinding(t *testing.T) {
 
 					return crd, nil
 				},
+				listCRDs: func(clusterName logicalcluster.Name) ([]*apiextensionsv1.CustomResourceDefinition, error) {
+					require.Equal(t, "org:ws", clusterName.String())
+					return tc.localCRDs, nil
+				},
 				createCRD: func(ctx context.Context, clusterName logicalcluster.Name, crd *apiextensionsv1.CustomResourceDefinition) (*apiextensionsv1.CustomResourceDefinition, error) {
 					createCRDCalled = true
 					return crd, tc.createCRDError
@@ -607,6 +668,26 @@ func TestReconcileBinding(t *testing.T) {
 					Reason:   apisv1alpha1.NamingConflictsReason,
 					Message:  "naming conflict with APIBinding conflicting",
 				})
+				requireConditionMatches(t, tc.apiBinding, &conditionsv1alpha1.Condition{
+					Type:     apisv1alpha1.ConflictFree,
+					Status:   corev1.ConditionFalse,
+					Severity: conditionsv1alpha1.ConditionSeverityError,
+					Reason:   apisv1alpha1.NamingConflictsReason,
+				})
+			}
+
+			if tc.wantLocalConflict {
+				requireConditionMatches(t, tc.apiBinding, &conditionsv1alpha1.Condition{
+					Type:     apisv1alpha1.ConflictFree,
+					Status:   corev1.ConditionFalse,
+					Severity: conditionsv1alpha1.ConditionSeverityWarning,
+					Reason:   apisv1alpha1.LocalCRDConflictReason,
+					Message:  "widgets.kcp.dev",
+				})
+			}
+
+			if tc.wantConflictFree {
+				requireConditionMatches(t, tc.apiBinding, conditions.TrueCondition(apisv1alpha1.ConflictFree))
 			}
 
 			if tc.wantInitialBindingCompleteInternalError {
@@ -622,16 +703,75 @@ func TestReconcileBinding(t *testing.T) {
 }
 
 func TestReconcileBound(t *testing.T) {
+	boundAPIExport := &apisv1alpha1.APIExport{
+		Spec: apisv1alpha1.APIExportSpec{
+			LatestResourceSchemas: []string{"someresources", "otherresources"},
+		},
+	}
+	boundAPIResourceSchemas := map[string]*apisv1alpha1.APIResourceSchema{
+		"someresources": {
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "someresources",
+				UID:  "uid1",
+			},
+		},
+		"otherresources": {
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "otherresources",
+				UID:  "uid2",
+			},
+		},
+	}
+	boundCRDs := map[string]*apiextensionsv1.CustomResourceDefinition{
+		"uid1": crdWithGroupResource("mygroup", "someresources"),
+		"uid2": crdWithGroupResource("anothergroup", "otherresources"),
+	}
+
 	tests := map[string]struct {
 		apiBinding            *apisv1alpha1.APIBinding
 		apiExport             *apisv1alpha1.APIExport
 		getAPIExportError     error
 		apiResourceSchemas    map[string]*apisv1alpha1.APIResourceSchema
+		existingAPIBindings   []*apisv1alpha1.APIBinding
+		localCRDs             []*apiextensionsv1.CustomResourceDefinition
 		wantBinding           bool
 		wantBound             bool
 		wantError             bool
 		wantAPIExportNotFound bool
+		wantNamingConflict    bool
+		wantLocalConflict     bool
+		wantConflictFree      bool
 	}{
+		"bound reports naming conflict with APIBinding taking precedence": {
+			apiBinding:         bound.Build(),
+			apiExport:          boundAPIExport,
+			apiResourceSchemas: boundAPIResourceSchemas,
+			existingAPIBindings: []*apisv1alpha1.APIBinding{
+				bound.DeepCopy().WithName("winner").WithPriority(1).Build(),
+			},
+			wantBound:          true,
+			wantNamingConflict: true,
+		},
+		"bound wins naming conflict with APIBinding of lower priority": {
+			apiBinding:         bound.Build(),
+			apiExport:          boundAPIExport,
+			apiResourceSchemas: boundAPIResourceSchemas,
+			existingAPIBindings: []*apisv1alpha1.APIBinding{
+				bound.DeepCopy().WithName("loser").WithPriority(-1).Build(),
+			},
+			wantBound:        true,
+			wantConflictFree: true,
+		},
+		"bound reports local CRD conflict": {
+			apiBinding:         bound.Build(),
+			apiExport:          boundAPIExport,
+			apiResourceSchemas: boundAPIResourceSchemas,
+			localCRDs: []*apiextensionsv1.CustomResourceDefinition{
+				crdWithGroupResource("mygroup", "someresources"),
+			},
+			wantBound:         true,
+			wantLocalConflict: true,
+		},
 		"bound becomes binding when referenced export changes": {
 			apiBinding: bound.DeepCopy().
 				WithWorkspaceReference("new-workspace", "new-export").
@@ -703,6 +843,16 @@ func TestReconcileBound(t *testing.T) {
 					require.Equal(t, "org:some-workspace", clusterName.String())
 					return tc.apiResourceSchemas[name], nil
 				},
+				listAPIBindings: func(clusterName logicalcluster.Name) ([]*apisv1alpha1.APIBinding, error) {
+					return tc.existingAPIBindings, nil
+				},
+				getCRD: func(clusterName logicalcluster.Name, name string) (*apiextensionsv1.CustomResourceDefinition, error) {
+					require.Equal(t, ShadowWorkspaceName, clusterName)
+					return boundCRDs[name], nil
+				},
+				listCRDs: func(clusterName logicalcluster.Name) ([]*apiextensionsv1.CustomResourceDefinition, error) {
+					return tc.localCRDs, nil
+				},
 			}
 
 			err := c.reconcile(context.Background(), tc.apiBinding)
@@ -727,10 +877,48 @@ func TestReconcileBound(t *testing.T) {
 					Reason:   apisv1alpha1.APIExportNotFoundReason,
 				})
 			}
+
+			if tc.wantNamingConflict {
+				requireConditionMatches(t, tc.apiBinding, &conditionsv1alpha1.Condition{
+					Type:     apisv1alpha1.ConflictFree,
+					Status:   corev1.ConditionFalse,
+					Severity: conditionsv1alpha1.ConditionSeverityError,
+					Reason:   apisv1alpha1.NamingConflictsReason,
+					Message:  "naming conflict with APIBinding winner",
+				})
+			}
+
+			if tc.wantLocalConflict {
+				requireConditionMatches(t, tc.apiBinding, &conditionsv1alpha1.Condition{
+					Type:     apisv1alpha1.ConflictFree,
+					Status:   corev1.ConditionFalse,
+					Severity: conditionsv1alpha1.ConditionSeverityWarning,
+					Reason:   apisv1alpha1.LocalCRDConflictReason,
+					Message:  "someresources.mygroup",
+				})
+			}
+
+			if tc.wantConflictFree {
+				requireConditionMatches(t, tc.apiBinding, conditions.TrueCondition(apisv1alpha1.ConflictFree))
+			}
 		})
 	}
 }
 
+func crdWithGroupResource(group, resource string) *apiextensionsv1.CustomResourceDefinition {
+	names := apiextensionsv1.CustomResourceDefinitionNames{Plural: resource}
+	return &apiextensionsv1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: resource + "." + group},
+		Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+			Group: group,
+			Names: names,
+		},
+		Status: apiextensionsv1.CustomResourceDefinitionStatus{
+			AcceptedNames: names,
+		},
+	}
+}
+
 func TestCRDFromAPIResourceSchema(t *testing.T) {
 	tests := map[string]struct {
 		schema  *apisv1alpha1.APIResourceSchema
@@ -914,6 +1102,90 @@ func TestCRDFromAPIResourceSchema(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		"schema with conversions": {
+			schema: &apisv1alpha1.APIResourceSchema{
+				ObjectMeta: metav1.ObjectMeta{
+					ClusterName: "my-cluster",
+					Name:        "my-name",
+					UID:         types.UID("my-uuid"),
+				},
+				Spec: apisv1alpha1.APIResourceSchemaSpec{
+					Group: "my-group",
+					Names: apiextensionsv1.CustomResourceDefinitionNames{
+						Plural:   "widgets",
+						Singular: "widget",
+						Kind:     "Widget",
+						ListKind: "WidgetList",
+					},
+					Scope: apiextensionsv1.ClusterScoped,
+					Versions: []apisv1alpha1.APIResourceVersion{
+						{
+							Name:    "v1",
+							Served:  true,
+							Storage: true,
+							Schema: runtime.RawExtension{
+								Raw: []byte(`{"type":"object"}`),
+							},
+						},
+					},
+					Conversions: []apisv1alpha1.APIResourceConversion{
+						{
+							From:   "v1",
+							To:     "v2",
+							Fields: []apisv1alpha1.APIResourceConversionField{{From: ".spec.a", To: ".spec.b"}},
+						},
+					},
+				},
+			},
+			want: &apiextensionsv1.CustomResourceDefinition{
+				ObjectMeta: metav1.ObjectMeta{
+					ClusterName: ShadowWorkspaceName.String(),
+					Name:        "my-uuid",
+					Annotations: map[string]string{
+						apisv1alpha1.AnnotationBoundCRDKey:      "",
+						apisv1alpha1.AnnotationSchemaClusterKey: "my-cluster",
+						apisv1alpha1.AnnotationSchemaNameKey:    "my-name",
+					},
+				},
+				Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+					Group: "my-group",
+					Names: apiextensionsv1.CustomResourceDefinitionNames{
+						Plural:   "widgets",
+						Singular: "widget",
+						Kind:     "Widget",
+						ListKind: "WidgetList",
+					},
+					Scope: apiextensionsv1.ClusterScoped,
+					Versions: []apiextensionsv1.CustomResourceDefinitionVersion{
+						{
+							Name:    "v1",
+							Served:  true,
+							Storage: true,
+							Schema: &apiextensionsv1.CustomResourceValidation{
+								OpenAPIV3Schema: &apiextensionsv1.JSONSchemaProps{
+									Type: "object",
+								},
+							},
+							Subresources: &apiextensionsv1.CustomResourceSubresources{},
+						},
+					},
+					Conversion: &apiextensionsv1.CustomResourceConversion{
+						Strategy: apiextensionsv1.WebhookConverter,
+						Webhook: &apiextensionsv1.WebhookConversion{
+							ClientConfig: &apiextensionsv1.WebhookClientConfig{
+								Service: &apiextensionsv1.ServiceReference{
+									Namespace: "kcp-system",
+									Name:      "apiresourceschema-conversion",
+									Path:      pointer.StringPtr("/my-cluster/my-name"),
+									Port:      pointer.Int32Ptr(443),
+								},
+							},
+							ConversionReviewVersions: []string{"v1"},
+						},
+					},
+				},
+			},
+		},
 		"error when schema is invalid": {
 			schema: &apisv1alpha1.APIResourceSchema{
 				Spec: apisv1alpha1.APIResourceSchemaSpec{
@@ -1004,6 +1276,11 @@ func (b *bindingBuilder) WithBoundAPIExport(workspaceName, exportName string) *b
 	return b
 }
 
+func (b *bindingBuilder) WithPriority(priority int32) *bindingBuilder {
+	b.Spec.Priority = priority
+	return b
+}
+
 func (b *bindingBuilder) WithBoundResources(boundResources ...apisv1alpha1.BoundAPIResource) *bindingBuilder {
 	b.Status.BoundResources = boundResources
 	return b
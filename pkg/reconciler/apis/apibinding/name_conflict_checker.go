@@ -32,6 +32,7 @@ type nameConflictChecker struct {
 	getAPIExport         func(clusterName logicalcluster.Name, name string) (*apisv1alpha1.APIExport, error)
 	getAPIResourceSchema func(clusterName logicalcluster.Name, name string) (*apisv1alpha1.APIResourceSchema, error)
 	getCRD               func(clusterName logicalcluster.Name, name string) (*apiextensionsv1.CustomResourceDefinition, error)
+	listCRDs             func(clusterName logicalcluster.Name) ([]*apiextensionsv1.CustomResourceDefinition, error)
 
 	boundCRDs    []*apiextensionsv1.CustomResourceDefinition
 	crdToBinding map[string]*apisv1alpha1.APIBinding
@@ -98,6 +99,10 @@ func (ncc *nameConflictChecker) checkForConflicts(crd *apiextensionsv1.CustomRes
 	for _, boundCRD := range ncc.boundCRDs {
 		if namesConflict(boundCRD, crd) {
 			conflict := ncc.crdToBinding[boundCRD.Name]
+			if APIBindingPrecedes(apiBinding, conflict) {
+				// apiBinding wins, the other APIBinding reports the conflict
+				continue
+			}
 			return fmt.Errorf("naming conflict with APIBinding %s", conflict.Name)
 		}
 	}
@@ -105,6 +110,36 @@ func (ncc *nameConflictChecker) checkForConflicts(crd *apiextensionsv1.CustomRes
 	return nil
 }
 
+// checkForLocalConflicts returns the names of the CRDs in the workspace of the APIBinding that have the same group
+// and resource as crd. These are shadowed by the APIBinding.
+func (ncc *nameConflictChecker) checkForLocalConflicts(crd *apiextensionsv1.CustomResourceDefinition, apiBinding *apisv1alpha1.APIBinding) ([]string, error) {
+	localCRDs, err := ncc.listCRDs(logicalcluster.From(apiBinding))
+	if err != nil {
+		return nil, fmt.Errorf("error checking for local conflicts for APIBinding %s|%s: error listing CRDs: %w", logicalcluster.From(apiBinding), apiBinding.Name, err)
+	}
+
+	var conflicts []string
+	for _, localCRD := range localCRDs {
+		if localCRD.Spec.Group == crd.Spec.Group && localCRD.Spec.Names.Plural == crd.Spec.Names.Plural {
+			conflicts = append(conflicts, localCRD.Name)
+		}
+	}
+
+	return conflicts, nil
+}
+
+// APIBindingPrecedes returns true if the APIs of APIBinding a take precedence over those of APIBinding b in case of
+// conflicts. Higher priority wins, then the older APIBinding, and then the lexicographically smaller name.
+func APIBindingPrecedes(a, b *apisv1alpha1.APIBinding) bool {
+	if a.Spec.Priority != b.Spec.Priority {
+		return a.Spec.Priority > b.Spec.Priority
+	}
+	if !a.CreationTimestamp.Equal(&b.CreationTimestamp) {
+		return a.CreationTimestamp.Before(&b.CreationTimestamp)
+	}
+	return a.Name < b.Name
+}
+
 func namesConflict(existing, incoming *apiextensionsv1.CustomResourceDefinition) bool {
 	existingNames := sets.NewString()
 	existingNames.Insert(existing.Status.AcceptedNames.Plural)
@@ -19,6 +19,7 @@ package apibinding
 import (
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/kcp-dev/logicalcluster"
@@ -171,3 +172,41 @@ func TestNamesConflict(t *testing.T) {
 		})
 	}
 }
+
+func TestAPIBindingPrecedes(t *testing.T) {
+	older := metav1.NewTime(time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC))
+	newer := metav1.NewTime(older.Add(time.Hour))
+
+	tests := map[string]struct {
+		a, b *apisv1alpha1.APIBinding
+		want bool
+	}{
+		"higher priority wins": {
+			a:    new(bindingBuilder).WithName("b").WithPriority(1).Build(),
+			b:    new(bindingBuilder).WithName("a").Build(),
+			want: true,
+		},
+		"lower priority loses": {
+			a:    new(bindingBuilder).WithName("a").WithPriority(-1).Build(),
+			b:    new(bindingBuilder).WithName("b").Build(),
+			want: false,
+		},
+		"older wins on equal priority": {
+			a:    &apisv1alpha1.APIBinding{ObjectMeta: metav1.ObjectMeta{Name: "b", CreationTimestamp: older}},
+			b:    &apisv1alpha1.APIBinding{ObjectMeta: metav1.ObjectMeta{Name: "a", CreationTimestamp: newer}},
+			want: true,
+		},
+		"smaller name wins on equal priority and age": {
+			a:    &apisv1alpha1.APIBinding{ObjectMeta: metav1.ObjectMeta{Name: "a", CreationTimestamp: older}},
+			b:    &apisv1alpha1.APIBinding{ObjectMeta: metav1.ObjectMeta{Name: "b", CreationTimestamp: older}},
+			want: true,
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, tc.want, APIBindingPrecedes(tc.a, tc.b))
+			require.Equal(t, !tc.want, APIBindingPrecedes(tc.b, tc.a))
+		})
+	}
+}
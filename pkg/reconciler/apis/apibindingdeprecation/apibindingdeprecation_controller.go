@@ -0,0 +1,163 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apibindingdeprecation
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/kcp-dev/logicalcluster"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+
+	apisv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apis/v1alpha1"
+	kcpclient "github.com/kcp-dev/kcp/pkg/client/clientset/versioned"
+	apisinformers "github.com/kcp-dev/kcp/pkg/client/informers/externalversions/apis/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/logging"
+)
+
+const (
+	controllerName = "kcp-apibinding-deprecation"
+
+	// flushInterval is the minimal time between two status updates of an APIBinding.
+	flushInterval = time.Minute
+)
+
+// NewController returns a new controller that flushes the usage of deprecated versions recorded by the
+// given tracker into the status of the APIBindings, at most once per minute per APIBinding.
+func NewController(
+	kcpClusterClient kcpclient.ClusterInterface,
+	apiBindingInformer apisinformers.APIBindingInformer,
+	tracker *Tracker,
+) (*controller, error) {
+	queue := workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), controllerName)
+
+	c := &controller{
+		queue:   queue,
+		tracker: tracker,
+		getAPIBinding: func(key string) (*apisv1alpha1.APIBinding, error) {
+			return apiBindingInformer.Lister().Get(key)
+		},
+		patchAPIBindingStatus: func(ctx context.Context, clusterName logicalcluster.Name, name string, patch []byte) error {
+			_, err := kcpClusterClient.Cluster(clusterName).ApisV1alpha1().APIBindings().Patch(ctx, name, types.MergePatchType, patch, metav1.PatchOptions{}, "status")
+			return err
+		},
+	}
+
+	apiBindingInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		DeleteFunc: func(obj interface{}) {
+			key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+			if err != nil {
+				runtime.HandleError(err)
+				return
+			}
+			c.tracker.drain(key)
+		},
+	})
+
+	return c, nil
+}
+
+// controller flushes the usage of deprecated versions into the APIBinding status.
+type controller struct {
+	queue   workqueue.RateLimitingInterface
+	tracker *Tracker
+
+	getAPIBinding         func(key string) (*apisv1alpha1.APIBinding, error)
+	patchAPIBindingStatus func(ctx context.Context, clusterName logicalcluster.Name, name string, patch []byte) error
+}
+
+func (c *controller) enqueue(key string) {
+	logging.WithQueueKey(logging.NewLogger(controllerName), key).V(6).Info("Queueing APIBinding")
+	c.queue.AddAfter(key, flushInterval)
+}
+
+// Start starts the controller, which stops when ctx.Done() is closed.
+func (c *controller) Start(ctx context.Context, numThreads int) {
+	defer runtime.HandleCrash()
+	defer c.queue.ShutDown()
+
+	klog.Infof("Starting %s controller", controllerName)
+	defer klog.Infof("Shutting down %s controller", controllerName)
+
+	c.tracker.setEnqueue(c.enqueue)
+
+	for i := 0; i < numThreads; i++ {
+		go wait.UntilWithContext(ctx, c.startWorker, time.Second)
+	}
+
+	<-ctx.Done()
+}
+
+func (c *controller) startWorker(ctx context.Context) {
+	for c.processNextWorkItem(ctx) {
+	}
+}
+
+func (c *controller) processNextWorkItem(ctx context.Context) bool {
+	// Wait until there is a new item in the working queue
+	k, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+	key := k.(string)
+
+	logger := logging.WithQueueKey(logging.NewLogger(controllerName), key)
+	ctx = logging.NewContext(ctx, logger)
+
+	// No matter what, tell the queue we're done with this key, to unblock
+	// other workers.
+	defer c.queue.Done(key)
+
+	if err := c.process(ctx, key); err != nil {
+		runtime.HandleError(fmt.Errorf("%q controller failed to sync %q, err: %w", controllerName, key, err))
+		c.queue.AddRateLimited(key)
+		return true
+	}
+	c.queue.Forget(key)
+	return true
+}
+
+func (c *controller) process(ctx context.Context, key string) error {
+	pending := c.tracker.drain(key)
+	if len(pending) == 0 {
+		return nil
+	}
+
+	apiBinding, err := c.getAPIBinding(key)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil // object deleted before we handled it
+		}
+		c.tracker.restore(key, pending)
+		return err
+	}
+
+	if err := c.reconcile(ctx, apiBinding, pending); err != nil {
+		c.tracker.restore(key, pending)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apibindingdeprecation
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+
+	"github.com/kcp-dev/logicalcluster"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	apisv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apis/v1alpha1"
+)
+
+func (c *controller) reconcile(ctx context.Context, apiBinding *apisv1alpha1.APIBinding, pending map[schema.GroupVersionResource]*usage) error {
+	merged := mergeUsage(apiBinding.Status.DeprecatedVersionUsage, pending)
+
+	// the resourceVersion precondition makes sure usage flushed concurrently, e.g. by another replica, is not overwritten.
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"resourceVersion": apiBinding.ResourceVersion,
+		},
+		"status": map[string]interface{}{
+			"deprecatedVersionUsage": merged,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	return c.patchAPIBindingStatus(ctx, logicalcluster.From(apiBinding), apiBinding.Name, patch)
+}
+
+// mergeUsage adds the pending usage to the existing usage, sorted by group, resource and version.
+func mergeUsage(existing []apisv1alpha1.DeprecatedVersionUsage, pending map[schema.GroupVersionResource]*usage) []apisv1alpha1.DeprecatedVersionUsage {
+	merged := make([]apisv1alpha1.DeprecatedVersionUsage, 0, len(existing)+len(pending))
+	seen := map[schema.GroupVersionResource]bool{}
+	for _, e := range existing {
+		gvr := schema.GroupVersionResource{Group: e.Group, Version: e.Version, Resource: e.Resource}
+		seen[gvr] = true
+		if u, found := pending[gvr]; found {
+			e.RequestCount += u.count
+			if u.last.After(e.LastRequestTime.Time) {
+				e.LastRequestTime = metav1.NewTime(u.last)
+				e.LastUserAgent = u.userAgent
+			}
+		}
+		merged = append(merged, e)
+	}
+	for gvr, u := range pending {
+		if seen[gvr] {
+			continue
+		}
+		merged = append(merged, apisv1alpha1.DeprecatedVersionUsage{
+			Group:           gvr.Group,
+			Resource:        gvr.Resource,
+			Version:         gvr.Version,
+			RequestCount:    u.count,
+			LastRequestTime: metav1.NewTime(u.last),
+			LastUserAgent:   u.userAgent,
+		})
+	}
+
+	sort.Slice(merged, func(i, j int) bool {
+		if merged[i].Group != merged[j].Group {
+			return merged[i].Group < merged[j].Group
+		}
+		if merged[i].Resource != merged[j].Resource {
+			return merged[i].Resource < merged[j].Resource
+		}
+		return merged[i].Version < merged[j].Version
+	})
+	return merged
+}
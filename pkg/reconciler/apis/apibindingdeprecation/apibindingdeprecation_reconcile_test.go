@@ -0,0 +1,146 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apibindingdeprecation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/kcp-dev/logicalcluster"
+	"github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/clusters"
+
+	apisv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apis/v1alpha1"
+)
+
+func TestProcess(t *testing.T) {
+	earlier := metav1.NewTime(time.Date(2022, 6, 1, 11, 0, 0, 0, time.UTC))
+	now := time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC)
+	consumer := logicalcluster.New("root:org:consumer")
+	key := clusters.ToClusterAwareKey(consumer, "widgets")
+
+	widgetsV1alpha1 := schema.GroupVersionResource{Group: "example.io", Version: "v1alpha1", Resource: "widgets"}
+	gadgetsV1alpha1 := schema.GroupVersionResource{Group: "example.io", Version: "v1alpha1", Resource: "gadgets"}
+
+	apiBinding := &apisv1alpha1.APIBinding{
+		ObjectMeta: metav1.ObjectMeta{ClusterName: "root:org:consumer", Name: "widgets", ResourceVersion: "42"},
+		Status: apisv1alpha1.APIBindingStatus{
+			DeprecatedVersionUsage: []apisv1alpha1.DeprecatedVersionUsage{
+				{Group: "example.io", Resource: "widgets", Version: "v1alpha1", RequestCount: 5, LastRequestTime: earlier, LastUserAgent: "old"},
+			},
+		},
+	}
+
+	tests := map[string]struct {
+		record     func(tracker *Tracker)
+		patchErr   error
+		wantPatch  map[string]interface{}
+		wantErr    bool
+		wantQueued int
+	}{
+		"nothing recorded": {
+			record: func(tracker *Tracker) {},
+		},
+		"existing and new versions": {
+			record: func(tracker *Tracker) {
+				tracker.Record(consumer, "widgets", widgetsV1alpha1, "kubectl")
+				tracker.Record(consumer, "widgets", widgetsV1alpha1, "kubectl")
+				tracker.Record(consumer, "widgets", gadgetsV1alpha1, "controller")
+			},
+			wantPatch: map[string]interface{}{
+				"metadata": map[string]interface{}{"resourceVersion": "42"},
+				"status": map[string]interface{}{
+					"deprecatedVersionUsage": []interface{}{
+						map[string]interface{}{"group": "example.io", "resource": "gadgets", "version": "v1alpha1", "requestCount": float64(1), "lastRequestTime": "2022-06-01T12:00:00Z", "lastUserAgent": "controller"},
+						map[string]interface{}{"group": "example.io", "resource": "widgets", "version": "v1alpha1", "requestCount": float64(7), "lastRequestTime": "2022-06-01T12:00:00Z", "lastUserAgent": "kubectl"},
+					},
+				},
+			},
+		},
+		"other APIBinding": {
+			record: func(tracker *Tracker) {
+				tracker.Record(logicalcluster.New("root:org:other"), "widgets", widgetsV1alpha1, "kubectl")
+			},
+			wantQueued: 1,
+		},
+		"patch fails": {
+			record: func(tracker *Tracker) {
+				tracker.Record(consumer, "widgets", widgetsV1alpha1, "kubectl")
+			},
+			patchErr:   errors.New("conflict"),
+			wantErr:    true,
+			wantQueued: 1,
+		},
+	}
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			tracker := NewTracker()
+			tracker.now = func() time.Time { return now }
+			tt.record(tracker)
+
+			var gotPatch map[string]interface{}
+			c := &controller{
+				tracker: tracker,
+				getAPIBinding: func(k string) (*apisv1alpha1.APIBinding, error) {
+					require.Equal(t, key, k)
+					return apiBinding, nil
+				},
+				patchAPIBindingStatus: func(ctx context.Context, clusterName logicalcluster.Name, name string, patch []byte) error {
+					require.Equal(t, consumer, clusterName)
+					require.Equal(t, "widgets", name)
+					require.NoError(t, json.Unmarshal(patch, &gotPatch))
+					return tt.patchErr
+				},
+			}
+
+			err := c.process(context.Background(), key)
+			if tt.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				require.Equal(t, tt.wantPatch, gotPatch)
+			}
+			require.Len(t, tracker.pending, tt.wantQueued, "unexpected number of APIBindings with pending usage")
+		})
+	}
+}
+
+func TestTrackerEnqueue(t *testing.T) {
+	consumer := logicalcluster.New("root:org:consumer")
+	key := clusters.ToClusterAwareKey(consumer, "widgets")
+	tracker := NewTracker()
+	gvr := schema.GroupVersionResource{Group: "example.io", Version: "v1alpha1", Resource: "widgets"}
+	tracker.Record(consumer, "widgets", gvr, "kubectl")
+
+	var queued []string
+	tracker.setEnqueue(func(k string) { queued = append(queued, k) })
+	require.Equal(t, []string{key}, queued, "usage recorded before the controller started must be enqueued")
+
+	tracker.Record(consumer, "widgets", gvr, "kubectl")
+	require.Equal(t, []string{key, key}, queued)
+
+	drained := tracker.drain(key)
+	require.Equal(t, int64(2), drained[gvr].count)
+	require.Empty(t, tracker.drain(key))
+}
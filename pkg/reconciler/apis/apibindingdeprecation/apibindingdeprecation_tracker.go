@@ -0,0 +1,123 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apibindingdeprecation
+
+import (
+	"sync"
+	"time"
+
+	"github.com/kcp-dev/logicalcluster"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/clusters"
+)
+
+// usage is the not yet flushed usage of a deprecated version.
+type usage struct {
+	count     int64
+	last      time.Time
+	userAgent string
+}
+
+// Tracker accumulates requests to deprecated versions of bound resources in memory, per APIBinding,
+// until the controller flushes them into the APIBinding status.
+type Tracker struct {
+	lock    sync.Mutex
+	pending map[string]map[schema.GroupVersionResource]*usage
+	enqueue func(key string)
+	now     func() time.Time
+}
+
+// NewTracker returns a new Tracker. Recorded usage is kept in memory until a controller is started
+// for the tracker.
+func NewTracker() *Tracker {
+	return &Tracker{
+		pending: map[string]map[schema.GroupVersionResource]*usage{},
+		now:     time.Now,
+	}
+}
+
+// Record records a request to the deprecated version of a resource bound through the given APIBinding.
+func (t *Tracker) Record(clusterName logicalcluster.Name, apiBindingName string, gvr schema.GroupVersionResource, userAgent string) {
+	key := clusters.ToClusterAwareKey(clusterName, apiBindingName)
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	versions, found := t.pending[key]
+	if !found {
+		versions = map[schema.GroupVersionResource]*usage{}
+		t.pending[key] = versions
+	}
+	u, found := versions[gvr]
+	if !found {
+		u = &usage{}
+		versions[gvr] = u
+	}
+	u.count++
+	u.last = t.now()
+	u.userAgent = userAgent
+
+	if t.enqueue != nil {
+		t.enqueue(key)
+	}
+}
+
+// setEnqueue sets the func called for every recorded request, and calls it for the usage recorded so far.
+func (t *Tracker) setEnqueue(enqueue func(key string)) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.enqueue = enqueue
+	for key := range t.pending {
+		enqueue(key)
+	}
+}
+
+// drain returns and forgets the usage recorded for the APIBinding with the given key.
+func (t *Tracker) drain(key string) map[schema.GroupVersionResource]*usage {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	versions := t.pending[key]
+	delete(t.pending, key)
+	return versions
+}
+
+// restore adds drained usage back, e.g. when it could not be flushed.
+func (t *Tracker) restore(key string, drained map[schema.GroupVersionResource]*usage) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	versions, found := t.pending[key]
+	if !found {
+		t.pending[key] = drained
+		return
+	}
+	for gvr, d := range drained {
+		u, found := versions[gvr]
+		if !found {
+			versions[gvr] = d
+			continue
+		}
+		u.count += d.count
+		if d.last.After(u.last) {
+			u.last = d.last
+			u.userAgent = d.userAgent
+		}
+	}
+}
@@ -0,0 +1,286 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apibindingroles
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/kcp-dev/logicalcluster"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	rbacinformers "k8s.io/client-go/informers/rbac/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/clusters"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+	"k8s.io/kubernetes/pkg/genericcontrolplane"
+
+	apisv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apis/v1alpha1"
+	apisinformers "github.com/kcp-dev/kcp/pkg/client/informers/externalversions/apis/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/logging"
+)
+
+const (
+	controllerName = "kcp-apibinding-roles"
+	byWorkspace    = controllerName + "-byWorkspace" // will go away with scoping
+	byBoundExport  = controllerName + "-byBoundExport"
+)
+
+// NewController returns a new controller that maintains admin, edit and view ClusterRoles in every
+// workspace with APIBindings, with rules for the bound resources of the APIExports opting into these
+// roles with spec.aggregateToRoles. The authorizer merges them with the bootstrap ClusterRoles of
+// the same name.
+func NewController(
+	kubeClusterClient kubernetes.ClusterInterface,
+	apiBindingInformer apisinformers.APIBindingInformer,
+	apiExportInformer apisinformers.APIExportInformer,
+	clusterRoleInformer rbacinformers.ClusterRoleInformer,
+) (*controller, error) {
+	queue := workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), controllerName)
+
+	c := &controller{
+		queue: queue,
+		listAPIBindings: func(clusterName logicalcluster.Name) ([]*apisv1alpha1.APIBinding, error) {
+			objs, err := apiBindingInformer.Informer().GetIndexer().ByIndex(byWorkspace, clusterName.String())
+			if err != nil {
+				return nil, err
+			}
+			bindings := make([]*apisv1alpha1.APIBinding, 0, len(objs))
+			for _, obj := range objs {
+				bindings = append(bindings, obj.(*apisv1alpha1.APIBinding))
+			}
+			return bindings, nil
+		},
+		getAPIExport: func(clusterName logicalcluster.Name, name string) (*apisv1alpha1.APIExport, error) {
+			return apiExportInformer.Lister().Get(clusters.ToClusterAwareKey(clusterName, name))
+		},
+		listBoundAPIBindings: func(clusterName logicalcluster.Name, name string) ([]*apisv1alpha1.APIBinding, error) {
+			objs, err := apiBindingInformer.Informer().GetIndexer().ByIndex(byBoundExport, clusters.ToClusterAwareKey(clusterName, name))
+			if err != nil {
+				return nil, err
+			}
+			bindings := make([]*apisv1alpha1.APIBinding, 0, len(objs))
+			for _, obj := range objs {
+				bindings = append(bindings, obj.(*apisv1alpha1.APIBinding))
+			}
+			return bindings, nil
+		},
+		getClusterRole: func(clusterName logicalcluster.Name, name string) (*rbacv1.ClusterRole, error) {
+			return clusterRoleInformer.Lister().Get(clusters.ToClusterAwareKey(clusterName, name))
+		},
+		createClusterRole: func(ctx context.Context, clusterName logicalcluster.Name, role *rbacv1.ClusterRole) error {
+			_, err := kubeClusterClient.Cluster(clusterName).RbacV1().ClusterRoles().Create(ctx, role, metav1.CreateOptions{})
+			return err
+		},
+		updateClusterRole: func(ctx context.Context, clusterName logicalcluster.Name, role *rbacv1.ClusterRole) error {
+			_, err := kubeClusterClient.Cluster(clusterName).RbacV1().ClusterRoles().Update(ctx, role, metav1.UpdateOptions{})
+			return err
+		},
+		deleteClusterRole: func(ctx context.Context, clusterName logicalcluster.Name, name string) error {
+			return kubeClusterClient.Cluster(clusterName).RbacV1().ClusterRoles().Delete(ctx, name, metav1.DeleteOptions{})
+		},
+	}
+
+	if err := apiBindingInformer.Informer().AddIndexers(cache.Indexers{
+		byWorkspace:   indexByWorkspace,
+		byBoundExport: indexByBoundExport,
+	}); err != nil {
+		return nil, err
+	}
+
+	apiBindingInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj interface{}) { c.enqueueAPIBinding(obj) },
+		UpdateFunc: func(_, obj interface{}) { c.enqueueAPIBinding(obj) },
+		DeleteFunc: func(obj interface{}) { c.enqueueAPIBinding(obj) },
+	})
+
+	apiExportInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) { c.enqueueAPIExport(obj) },
+		UpdateFunc: func(oldObj, obj interface{}) {
+			oldExport, ok := oldObj.(*apisv1alpha1.APIExport)
+			if !ok {
+				return
+			}
+			newExport, ok := obj.(*apisv1alpha1.APIExport)
+			if !ok {
+				return
+			}
+			if equality.Semantic.DeepEqual(oldExport.Spec.AggregateToRoles, newExport.Spec.AggregateToRoles) {
+				return
+			}
+			c.enqueueAPIExport(obj)
+		},
+		DeleteFunc: func(obj interface{}) { c.enqueueAPIExport(obj) },
+	})
+
+	clusterRoleInformer.Informer().AddEventHandler(cache.FilteringResourceEventHandler{
+		FilterFunc: func(obj interface{}) bool {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			// changes of the bootstrap roles need no reconciling, the authorizer reads them live
+			role, ok := obj.(*rbacv1.ClusterRole)
+			return ok && managedRoleVerbs[apisv1alpha1.AggregatedRoleName(role.Name)] != nil && logicalcluster.From(role) != genericcontrolplane.LocalAdminCluster
+		},
+		Handler: cache.ResourceEventHandlerFuncs{
+			AddFunc:    func(obj interface{}) { c.enqueueClusterRole(obj) },
+			UpdateFunc: func(_, obj interface{}) { c.enqueueClusterRole(obj) },
+			DeleteFunc: func(obj interface{}) { c.enqueueClusterRole(obj) },
+		},
+	})
+
+	return c, nil
+}
+
+// controller maintains the admin, edit and view ClusterRoles of workspaces with APIBindings.
+type controller struct {
+	queue workqueue.RateLimitingInterface
+
+	listAPIBindings      func(clusterName logicalcluster.Name) ([]*apisv1alpha1.APIBinding, error)
+	getAPIExport         func(clusterName logicalcluster.Name, name string) (*apisv1alpha1.APIExport, error)
+	listBoundAPIBindings func(clusterName logicalcluster.Name, name string) ([]*apisv1alpha1.APIBinding, error)
+	getClusterRole       func(clusterName logicalcluster.Name, name string) (*rbacv1.ClusterRole, error)
+	createClusterRole    func(ctx context.Context, clusterName logicalcluster.Name, role *rbacv1.ClusterRole) error
+	updateClusterRole    func(ctx context.Context, clusterName logicalcluster.Name, role *rbacv1.ClusterRole) error
+	deleteClusterRole    func(ctx context.Context, clusterName logicalcluster.Name, name string) error
+}
+
+func (c *controller) enqueueAPIBinding(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	apiBinding, ok := obj.(*apisv1alpha1.APIBinding)
+	if !ok {
+		runtime.HandleError(fmt.Errorf("obj is supposed to be an APIBinding, but is %T", obj))
+		return
+	}
+	c.enqueueWorkspace(logicalcluster.From(apiBinding))
+}
+
+// enqueueAPIExport enqueues the workspaces bound to the given APIExport.
+func (c *controller) enqueueAPIExport(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	apiExport, ok := obj.(*apisv1alpha1.APIExport)
+	if !ok {
+		runtime.HandleError(fmt.Errorf("obj is supposed to be an APIExport, but is %T", obj))
+		return
+	}
+	apiBindings, err := c.listBoundAPIBindings(logicalcluster.From(apiExport), apiExport.Name)
+	if err != nil {
+		runtime.HandleError(err)
+		return
+	}
+	for _, apiBinding := range apiBindings {
+		c.enqueueWorkspace(logicalcluster.From(apiBinding))
+	}
+}
+
+func (c *controller) enqueueClusterRole(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	role, ok := obj.(*rbacv1.ClusterRole)
+	if !ok {
+		runtime.HandleError(fmt.Errorf("obj is supposed to be a ClusterRole, but is %T", obj))
+		return
+	}
+	c.enqueueWorkspace(logicalcluster.From(role))
+}
+
+func (c *controller) enqueueWorkspace(clusterName logicalcluster.Name) {
+	key := clusterName.String()
+	logging.WithQueueKey(logging.NewLogger(controllerName), key).V(4).Info("Queueing workspace")
+	c.queue.Add(key)
+}
+
+// Start starts the controller, which stops when ctx.Done() is closed.
+func (c *controller) Start(ctx context.Context, numThreads int) {
+	defer runtime.HandleCrash()
+	defer c.queue.ShutDown()
+
+	klog.Infof("Starting %s controller", controllerName)
+	defer klog.Infof("Shutting down %s controller", controllerName)
+
+	for i := 0; i < numThreads; i++ {
+		go wait.UntilWithContext(ctx, c.startWorker, time.Second)
+	}
+
+	<-ctx.Done()
+}
+
+func (c *controller) startWorker(ctx context.Context) {
+	for c.processNextWorkItem(ctx) {
+	}
+}
+
+func (c *controller) processNextWorkItem(ctx context.Context) bool {
+	// Wait until there is a new item in the working queue
+	k, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+	key := k.(string)
+
+	logger := logging.WithQueueKey(logging.NewLogger(controllerName), key)
+	ctx = logging.NewContext(ctx, logger)
+
+	// No matter what, tell the queue we're done with this key, to unblock
+	// other workers.
+	defer c.queue.Done(key)
+
+	if err := c.reconcile(ctx, logicalcluster.New(key)); err != nil {
+		runtime.HandleError(fmt.Errorf("%q controller failed to sync %q, err: %w", controllerName, key, err))
+		c.queue.AddRateLimited(key)
+		return true
+	}
+	c.queue.Forget(key)
+	return true
+}
+
+// indexByWorkspace indexes objects by their logical cluster.
+func indexByWorkspace(obj interface{}) ([]string, error) {
+	metaObj, ok := obj.(metav1.Object)
+	if !ok {
+		return []string{}, fmt.Errorf("obj is supposed to be a metav1.Object, but is %T", obj)
+	}
+
+	return []string{logicalcluster.From(metaObj).String()}, nil
+}
+
+// indexByBoundExport indexes APIBindings by the key of the APIExport they are bound to, i.e. of
+// status.boundExport.
+func indexByBoundExport(obj interface{}) ([]string, error) {
+	apiBinding, ok := obj.(*apisv1alpha1.APIBinding)
+	if !ok {
+		return []string{}, fmt.Errorf("obj is supposed to be an APIBinding, but is %T", obj)
+	}
+
+	clusterName, ok := boundExportClusterName(apiBinding)
+	if !ok {
+		return []string{}, nil
+	}
+	return []string{clusters.ToClusterAwareKey(clusterName, apiBinding.Status.BoundAPIExport.Workspace.ExportName)}, nil
+}
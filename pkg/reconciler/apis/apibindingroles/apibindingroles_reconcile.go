@@ -0,0 +1,192 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apibindingroles
+
+import (
+	"context"
+	"sort"
+
+	"github.com/kcp-dev/logicalcluster"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/kubernetes/pkg/genericcontrolplane"
+
+	apisv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apis/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/logging"
+)
+
+// BoundAPIsRoleLabel marks the ClusterRoles maintained by this controller. ClusterRoles
+// of the same name without this label are left alone.
+const BoundAPIsRoleLabel = "apis.kcp.dev/bound-apis-role"
+
+var (
+	readVerbs  = []string{"get", "list", "watch"}
+	writeVerbs = []string{"create", "delete", "deletecollection", "get", "list", "patch", "update", "watch"}
+
+	// managedRoleVerbs are the verbs granted on bound resources, by role name.
+	managedRoleVerbs = map[apisv1alpha1.AggregatedRoleName][]string{
+		apisv1alpha1.AggregatedRoleAdmin: writeVerbs,
+		apisv1alpha1.AggregatedRoleEdit:  writeVerbs,
+		apisv1alpha1.AggregatedRoleView:  readVerbs,
+	}
+)
+
+func (c *controller) reconcile(ctx context.Context, clusterName logicalcluster.Name) error {
+	if clusterName == genericcontrolplane.LocalAdminCluster {
+		return nil
+	}
+
+	apiBindings, err := c.listAPIBindings(clusterName)
+	if err != nil {
+		return err
+	}
+	boundResources, err := c.boundResourcesByRole(apiBindings)
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, 0, len(managedRoleVerbs))
+	for name := range managedRoleVerbs {
+		names = append(names, string(name))
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if err := c.reconcileClusterRole(ctx, clusterName, apisv1alpha1.AggregatedRoleName(name), boundResources[apisv1alpha1.AggregatedRoleName(name)]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *controller) reconcileClusterRole(ctx context.Context, clusterName logicalcluster.Name, name apisv1alpha1.AggregatedRoleName, boundResources map[string]sets.String) error {
+	logger := logging.FromContext(ctx).WithValues("clusterRole", name)
+
+	existing, err := c.getClusterRole(clusterName, string(name))
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	if errors.IsNotFound(err) {
+		existing = nil
+	}
+	if existing != nil && existing.Labels[BoundAPIsRoleLabel] != "true" {
+		logger.V(4).Info("ClusterRole is not managed by this controller, skipping")
+		return nil
+	}
+
+	if len(boundResources) == 0 {
+		if existing == nil {
+			return nil
+		}
+		logger.V(2).Info("No bound resources left, deleting ClusterRole")
+		if err := c.deleteClusterRole(ctx, clusterName, string(name)); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+		return nil
+	}
+
+	rules := desiredRules(managedRoleVerbs[name], boundResources)
+
+	if existing == nil {
+		logger.V(2).Info("Creating ClusterRole")
+		return c.createClusterRole(ctx, clusterName, &rbacv1.ClusterRole{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: string(name),
+				Labels: map[string]string{
+					BoundAPIsRoleLabel: "true",
+				},
+			},
+			Rules: rules,
+		})
+	}
+
+	if equality.Semantic.DeepEqual(existing.Rules, rules) {
+		return nil
+	}
+	logger.V(2).Info("Updating ClusterRole")
+	updated := existing.DeepCopy()
+	updated.Rules = rules
+	return c.updateClusterRole(ctx, clusterName, updated)
+}
+
+// boundResourcesByRole returns the resources bound by the given APIBindings, by role and API group,
+// for the roles the bound APIExports opt into. Resources of missing APIExports are skipped.
+func (c *controller) boundResourcesByRole(apiBindings []*apisv1alpha1.APIBinding) (map[apisv1alpha1.AggregatedRoleName]map[string]sets.String, error) {
+	ret := map[apisv1alpha1.AggregatedRoleName]map[string]sets.String{}
+	for _, apiBinding := range apiBindings {
+		if len(apiBinding.Status.BoundResources) == 0 {
+			continue
+		}
+		exportClusterName, ok := boundExportClusterName(apiBinding)
+		if !ok {
+			continue
+		}
+		apiExport, err := c.getAPIExport(exportClusterName, apiBinding.Status.BoundAPIExport.Workspace.ExportName)
+		if errors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+
+		for _, role := range apiExport.Spec.AggregateToRoles {
+			if managedRoleVerbs[role] == nil {
+				continue
+			}
+			if ret[role] == nil {
+				ret[role] = map[string]sets.String{}
+			}
+			for _, r := range apiBinding.Status.BoundResources {
+				if ret[role][r.Group] == nil {
+					ret[role][r.Group] = sets.NewString()
+				}
+				ret[role][r.Group].Insert(r.Resource)
+			}
+		}
+	}
+	return ret, nil
+}
+
+// boundExportClusterName returns the logical cluster of the APIExport the given APIBinding is bound to.
+func boundExportClusterName(apiBinding *apisv1alpha1.APIBinding) (logicalcluster.Name, bool) {
+	if apiBinding.Status.BoundAPIExport == nil || apiBinding.Status.BoundAPIExport.Workspace == nil {
+		return logicalcluster.Name{}, false
+	}
+	return apiBinding.Status.BoundAPIExport.Workspace.ClusterName(logicalcluster.From(apiBinding))
+}
+
+// desiredRules returns one rule per group of bound resources, granting the given verbs. The rules
+// of the bootstrap role of the same name are not copied, the authorizer merges them in.
+func desiredRules(verbs []string, boundResources map[string]sets.String) []rbacv1.PolicyRule {
+	var rules []rbacv1.PolicyRule
+	groups := make([]string, 0, len(boundResources))
+	for group := range boundResources {
+		groups = append(groups, group)
+	}
+	sort.Strings(groups)
+	for _, group := range groups {
+		rules = append(rules, rbacv1.PolicyRule{
+			APIGroups: []string{group},
+			Resources: boundResources[group].List(),
+			Verbs:     append([]string(nil), verbs...),
+		})
+	}
+	return rules
+}
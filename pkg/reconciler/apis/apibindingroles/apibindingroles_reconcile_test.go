@@ -0,0 +1,192 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apibindingroles
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kcp-dev/logicalcluster"
+	"github.com/stretchr/testify/require"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apisv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apis/v1alpha1"
+)
+
+func TestReconcile(t *testing.T) {
+	consumer := logicalcluster.New("root:org:consumer")
+	provider := logicalcluster.New("root:org:provider")
+
+	binding := func(name, export string, resources ...apisv1alpha1.BoundAPIResource) *apisv1alpha1.APIBinding {
+		return &apisv1alpha1.APIBinding{
+			ObjectMeta: metav1.ObjectMeta{ClusterName: consumer.String(), Name: name},
+			Status: apisv1alpha1.APIBindingStatus{
+				BoundAPIExport: &apisv1alpha1.ExportReference{Workspace: &apisv1alpha1.WorkspaceExportReference{WorkspaceName: "provider", ExportName: export}},
+				BoundResources: resources,
+			},
+		}
+	}
+	bound := func(group, resource string) apisv1alpha1.BoundAPIResource {
+		return apisv1alpha1.BoundAPIResource{Group: group, Resource: resource}
+	}
+	managed := func(name string, rules ...rbacv1.PolicyRule) *rbacv1.ClusterRole {
+		return &rbacv1.ClusterRole{
+			ObjectMeta: metav1.ObjectMeta{ClusterName: consumer.String(), Name: name, Labels: map[string]string{BoundAPIsRoleLabel: "true"}},
+			Rules:      rules,
+		}
+	}
+
+	admin, edit, view := apisv1alpha1.AggregatedRoleAdmin, apisv1alpha1.AggregatedRoleEdit, apisv1alpha1.AggregatedRoleView
+	apiExports := map[string]*apisv1alpha1.APIExport{
+		"widgets":  {ObjectMeta: metav1.ObjectMeta{ClusterName: provider.String(), Name: "widgets"}, Spec: apisv1alpha1.APIExportSpec{AggregateToRoles: []apisv1alpha1.AggregatedRoleName{admin, edit, view}}},
+		"sheriffs": {ObjectMeta: metav1.ObjectMeta{ClusterName: provider.String(), Name: "sheriffs"}, Spec: apisv1alpha1.APIExportSpec{AggregateToRoles: []apisv1alpha1.AggregatedRoleName{admin, view}}},
+		"private":  {ObjectMeta: metav1.ObjectMeta{ClusterName: provider.String(), Name: "private"}},
+	}
+
+	widgetsWrite := rbacv1.PolicyRule{APIGroups: []string{"example.io"}, Resources: []string{"gadgets", "widgets"}, Verbs: writeVerbs}
+	widgetsRead := rbacv1.PolicyRule{APIGroups: []string{"example.io"}, Resources: []string{"gadgets", "widgets"}, Verbs: readVerbs}
+	coreWrite := rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"sheriffs"}, Verbs: writeVerbs}
+	coreRead := rbacv1.PolicyRule{APIGroups: []string{""}, Resources: []string{"sheriffs"}, Verbs: readVerbs}
+
+	tests := map[string]struct {
+		apiBindings []*apisv1alpha1.APIBinding
+		existing    []*rbacv1.ClusterRole
+
+		wantCreated map[string][]rbacv1.PolicyRule
+		wantUpdated map[string][]rbacv1.PolicyRule
+		wantDeleted []string
+	}{
+		"no bindings, nothing to do": {},
+		"bound resources create the roles the exports opt into": {
+			apiBindings: []*apisv1alpha1.APIBinding{
+				binding("widgets", "widgets", bound("example.io", "widgets"), bound("example.io", "gadgets")),
+				binding("sheriffs", "sheriffs", bound("", "sheriffs")),
+				binding("binding", "widgets"),
+			},
+			wantCreated: map[string][]rbacv1.PolicyRule{
+				"admin": {coreWrite, widgetsWrite},
+				"edit":  {widgetsWrite},
+				"view":  {coreRead, widgetsRead},
+			},
+		},
+		"exports without opt-in and missing exports are skipped": {
+			apiBindings: []*apisv1alpha1.APIBinding{
+				binding("private", "private", bound("example.io", "widgets")),
+				binding("missing", "missing", bound("", "sheriffs")),
+			},
+		},
+		"changed bound resources update the managed roles": {
+			apiBindings: []*apisv1alpha1.APIBinding{
+				binding("widgets", "widgets", bound("example.io", "widgets"), bound("example.io", "gadgets")),
+			},
+			existing: []*rbacv1.ClusterRole{
+				managed("admin", widgetsWrite),
+				managed("edit", coreWrite),
+				managed("view", coreRead),
+			},
+			wantUpdated: map[string][]rbacv1.PolicyRule{
+				"edit": {widgetsWrite},
+				"view": {widgetsRead},
+			},
+		},
+		"unmanaged roles are left alone": {
+			apiBindings: []*apisv1alpha1.APIBinding{
+				binding("widgets", "widgets", bound("example.io", "widgets"), bound("example.io", "gadgets")),
+			},
+			existing: []*rbacv1.ClusterRole{
+				{ObjectMeta: metav1.ObjectMeta{ClusterName: consumer.String(), Name: "admin"}},
+			},
+			wantCreated: map[string][]rbacv1.PolicyRule{
+				"edit": {widgetsWrite},
+				"view": {widgetsRead},
+			},
+		},
+		"no bound resources left delete the managed roles": {
+			apiBindings: []*apisv1alpha1.APIBinding{
+				binding("binding", "widgets"),
+				binding("private", "private", bound("example.io", "widgets")),
+			},
+			existing: []*rbacv1.ClusterRole{
+				managed("admin", widgetsWrite),
+				{ObjectMeta: metav1.ObjectMeta{ClusterName: consumer.String(), Name: "view"}},
+			},
+			wantDeleted: []string{"admin"},
+		},
+	}
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			created := map[string][]rbacv1.PolicyRule{}
+			updated := map[string][]rbacv1.PolicyRule{}
+			var deleted []string
+
+			c := &controller{
+				listAPIBindings: func(clusterName logicalcluster.Name) ([]*apisv1alpha1.APIBinding, error) {
+					require.Equal(t, consumer, clusterName)
+					return tc.apiBindings, nil
+				},
+				getAPIExport: func(clusterName logicalcluster.Name, name string) (*apisv1alpha1.APIExport, error) {
+					require.Equal(t, provider, clusterName)
+					if apiExport, found := apiExports[name]; found {
+						return apiExport, nil
+					}
+					return nil, errors.NewNotFound(apisv1alpha1.Resource("apiexports"), name)
+				},
+				getClusterRole: func(clusterName logicalcluster.Name, name string) (*rbacv1.ClusterRole, error) {
+					require.Equal(t, consumer, clusterName)
+					for _, role := range tc.existing {
+						if role.Name == name {
+							return role, nil
+						}
+					}
+					return nil, errors.NewNotFound(rbacv1.Resource("clusterroles"), name)
+				},
+				createClusterRole: func(ctx context.Context, clusterName logicalcluster.Name, role *rbacv1.ClusterRole) error {
+					require.Equal(t, consumer, clusterName)
+					require.Equal(t, "true", role.Labels[BoundAPIsRoleLabel])
+					created[role.Name] = role.Rules
+					return nil
+				},
+				updateClusterRole: func(ctx context.Context, clusterName logicalcluster.Name, role *rbacv1.ClusterRole) error {
+					require.Equal(t, consumer, clusterName)
+					updated[role.Name] = role.Rules
+					return nil
+				},
+				deleteClusterRole: func(ctx context.Context, clusterName logicalcluster.Name, name string) error {
+					require.Equal(t, consumer, clusterName)
+					deleted = append(deleted, name)
+					return nil
+				},
+			}
+
+			require.NoError(t, c.reconcile(context.Background(), consumer))
+
+			if tc.wantCreated == nil {
+				tc.wantCreated = map[string][]rbacv1.PolicyRule{}
+			}
+			if tc.wantUpdated == nil {
+				tc.wantUpdated = map[string][]rbacv1.PolicyRule{}
+			}
+			require.Equal(t, tc.wantCreated, created, "unexpected created roles")
+			require.Equal(t, tc.wantUpdated, updated, "unexpected updated roles")
+			require.Equal(t, tc.wantDeleted, deleted, "unexpected deleted roles")
+		})
+	}
+}
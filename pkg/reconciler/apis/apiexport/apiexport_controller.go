@@ -45,6 +45,8 @@ import (
 	kcpclient "github.com/kcp-dev/kcp/pkg/client/clientset/versioned"
 	apisinformers "github.com/kcp-dev/kcp/pkg/client/informers/externalversions/apis/v1alpha1"
 	apislisters "github.com/kcp-dev/kcp/pkg/client/listers/apis/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/logging"
+	"github.com/kcp-dev/kcp/pkg/secretstore"
 )
 
 const (
@@ -56,7 +58,8 @@ const (
 	IndexAPIExportByIdentity = "byIdentity"
 )
 
-// NewController returns a new controller for APIExports.
+// NewController returns a new controller for APIExports. If secretStore is not nil, identities of
+// APIExports without spec.identity.secretRef are generated in the secret store instead of in Secrets.
 func NewController(
 	kcpClusterClient kcpclient.ClusterInterface,
 	apiExportInformer apisinformers.APIExportInformer,
@@ -64,6 +67,7 @@ func NewController(
 	kubeClusterClient kubernetes.ClusterInterface,
 	namespaceInformer coreinformers.NamespaceInformer,
 	secretInformer coreinformers.SecretInformer,
+	secretStore secretstore.Store,
 ) (*controller, error) {
 	queue := workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), controllerName)
 
@@ -86,6 +90,7 @@ func NewController(
 			_, err := kubeClusterClient.Cluster(clusterName).CoreV1().Secrets(secret.Namespace).Create(ctx, secret, metav1.CreateOptions{})
 			return err
 		},
+		secretStore: secretStore,
 	}
 
 	c.getSecret = c.readThroughGetSecret
@@ -165,6 +170,9 @@ type controller struct {
 
 	getSecret    func(ctx context.Context, clusterName logicalcluster.Name, ns, name string) (*corev1.Secret, error)
 	createSecret func(ctx context.Context, clusterName logicalcluster.Name, secret *corev1.Secret) error
+
+	// secretStore keeps the generated identities outside of etcd. It is nil if they are kept in Secrets.
+	secretStore secretstore.Store
 }
 
 // enqueueAPIBinding enqueues an APIExport .
@@ -175,7 +183,7 @@ func (c *controller) enqueueAPIExport(obj interface{}) {
 		return
 	}
 
-	klog.V(2).Infof("Queueing APIExport %q", key)
+	logging.WithQueueKey(logging.NewLogger(controllerName), key).V(2).Info("Queueing APIExport")
 	c.queue.Add(key)
 }
 
@@ -226,6 +234,9 @@ func (c *controller) processNextWorkItem(ctx context.Context) bool {
 	}
 	key := k.(string)
 
+	logger := logging.WithQueueKey(logging.NewLogger(controllerName), key)
+	ctx = logging.NewContext(ctx, logger)
+
 	// No matter what, tell the queue we're done with this key, to unblock
 	// other workers.
 	defer c.queue.Done(key)
@@ -31,6 +31,7 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	apisv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apis/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/secretstore"
 	conditionsv1alpha1 "github.com/kcp-dev/kcp/third_party/conditions/apis/conditions/v1alpha1"
 	"github.com/kcp-dev/kcp/third_party/conditions/util/conditions"
 )
@@ -228,6 +229,183 @@ func TestReconcile(t *testing.T) {
 	}
 }
 
+func TestReconcileWithSecretStore(t *testing.T) {
+	expectedKey := []byte("abc")
+	expectedHash := fmt.Sprintf("%x", sha256.Sum256(expectedKey))
+	storeKey := "apiexports/root:org:ws/my-export"
+
+	tests := map[string]struct {
+		stored                   []byte
+		storeErr                 error
+		secretRefSet             bool
+		apiExportHasExpectedHash bool
+
+		wantError            bool
+		wantGenerationFailed bool
+		wantVerifyFailure    bool
+		wantIdentityValid    bool
+		wantStored           bool
+		wantHash             string
+	}{
+		"identity generated in the store when ref is nil": {
+			wantIdentityValid: true,
+			wantStored:        true,
+		},
+		"identity from the store is used": {
+			stored: expectedKey,
+
+			wantIdentityValid: true,
+			wantStored:        true,
+			wantHash:          expectedHash,
+		},
+		"identity verification fails when the stored identity differs from the APIExport's hash": {
+			stored:                   []byte("def"),
+			apiExportHasExpectedHash: true,
+
+			wantVerifyFailure: true,
+			wantStored:        true,
+			wantHash:          expectedHash,
+		},
+		"error reading the store - identity not valid": {
+			storeErr: errors.New("store unavailable"),
+
+			wantError:            true,
+			wantGenerationFailed: true,
+		},
+		"referenced secret is used instead of the store": {
+			secretRefSet: true,
+
+			wantIdentityValid: true,
+			wantHash:          expectedHash,
+		},
+	}
+
+	for name, tc := range tests {
+		tc := tc // to avoid t.Parallel() races
+
+		t.Run(name, func(t *testing.T) {
+			store := &fakeStore{data: map[string]map[string][]byte{}, err: tc.storeErr}
+			if tc.stored != nil {
+				store.data[storeKey] = map[string][]byte{apisv1alpha1.SecretKeyAPIExportIdentity: tc.stored}
+			}
+
+			createSecretCalled := false
+			c := &controller{
+				getSecret: func(ctx context.Context, clusterName logicalcluster.Name, ns, name string) (*corev1.Secret, error) {
+					return &corev1.Secret{Data: map[string][]byte{apisv1alpha1.SecretKeyAPIExportIdentity: expectedKey}}, nil
+				},
+				createSecret: func(ctx context.Context, clusterName logicalcluster.Name, secret *corev1.Secret) error {
+					createSecretCalled = true
+					return nil
+				},
+				secretStore: store,
+			}
+
+			apiExport := &apisv1alpha1.APIExport{
+				ObjectMeta: metav1.ObjectMeta{
+					ClusterName: "root:org:ws",
+					Name:        "my-export",
+				},
+			}
+			if tc.secretRefSet {
+				apiExport.Spec.Identity = &apisv1alpha1.Identity{
+					SecretRef: &corev1.SecretReference{Namespace: "somens", Name: "somename"},
+				}
+			}
+			if tc.apiExportHasExpectedHash {
+				apiExport.Status.IdentityHash = expectedHash
+			}
+
+			err := c.reconcile(context.Background(), apiExport)
+			if tc.wantError {
+				require.Error(t, err, "expected an error")
+			} else {
+				require.NoError(t, err, "expected no error")
+			}
+
+			require.False(t, createSecretCalled, "expected no secret to be created")
+			if !tc.secretRefSet {
+				require.Nil(t, apiExport.Spec.Identity, "expected no secret reference for identities in the store")
+			}
+
+			if tc.wantStored {
+				stored := store.data[storeKey][apisv1alpha1.SecretKeyAPIExportIdentity]
+				require.NotEmpty(t, stored, "expected the identity in the store")
+				if tc.wantHash == "" {
+					require.Equal(t, fmt.Sprintf("%x", sha256.Sum256(stored)), apiExport.Status.IdentityHash)
+				}
+			} else {
+				require.NotContains(t, store.data, storeKey)
+			}
+			if tc.wantHash != "" {
+				require.Equal(t, tc.wantHash, apiExport.Status.IdentityHash)
+			}
+
+			if tc.wantGenerationFailed {
+				requireConditionMatches(t, apiExport,
+					conditions.FalseCondition(
+						apisv1alpha1.APIExportIdentityValid,
+						apisv1alpha1.IdentityGenerationFailedReason,
+						conditionsv1alpha1.ConditionSeverityError,
+						"",
+					),
+				)
+			}
+
+			if tc.wantVerifyFailure {
+				requireConditionMatches(t, apiExport,
+					conditions.FalseCondition(
+						apisv1alpha1.APIExportIdentityValid,
+						apisv1alpha1.IdentityVerificationFailedReason,
+						conditionsv1alpha1.ConditionSeverityError,
+						"",
+					),
+				)
+			}
+
+			if tc.wantIdentityValid {
+				requireConditionMatches(t, apiExport, conditions.TrueCondition(apisv1alpha1.APIExportIdentityValid))
+			}
+		})
+	}
+}
+
+// fakeStore is an in-memory secretstore.Store failing all requests with err, if set.
+type fakeStore struct {
+	data map[string]map[string][]byte
+	err  error
+}
+
+func (s *fakeStore) Get(_ context.Context, key string) (map[string][]byte, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	data, ok := s.data[key]
+	if !ok {
+		return nil, secretstore.ErrNotFound
+	}
+	return data, nil
+}
+
+func (s *fakeStore) Put(_ context.Context, key string, data map[string][]byte) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.data[key] = data
+	return nil
+}
+
+func (s *fakeStore) Create(_ context.Context, key string, data map[string][]byte) error {
+	if s.err != nil {
+		return s.err
+	}
+	if _, ok := s.data[key]; ok {
+		return secretstore.ErrAlreadyExists
+	}
+	s.data[key] = data
+	return nil
+}
+
 // requireConditionMatches looks for a condition matching c in g. Only fields that are set in c are compared (Type is
 // required, though). If c.Message is set, the test performed is contains rather than an exact match.
 func requireConditionMatches(t *testing.T, g conditions.Getter, c *conditionsv1alpha1.Condition) {
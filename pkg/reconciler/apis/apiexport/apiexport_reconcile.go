@@ -29,9 +29,10 @@ import (
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/util/keyutil"
-	"k8s.io/klog/v2"
 
 	apisv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apis/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/logging"
+	"github.com/kcp-dev/kcp/pkg/secretstore"
 	conditionsv1alpha1 "github.com/kcp-dev/kcp/third_party/conditions/apis/conditions/v1alpha1"
 	"github.com/kcp-dev/kcp/third_party/conditions/util/conditions"
 )
@@ -44,6 +45,34 @@ func (c *controller) reconcile(ctx context.Context, apiExport *apisv1alpha1.APIE
 
 	clusterName := logicalcluster.From(apiExport)
 
+	if identity.SecretRef == nil && c.secretStore != nil {
+		key, err := c.getOrCreateStoredIdentity(ctx, clusterName, apiExport.Name)
+		if err != nil {
+			conditions.MarkFalse(
+				apiExport,
+				apisv1alpha1.APIExportIdentityValid,
+				apisv1alpha1.IdentityGenerationFailedReason,
+				conditionsv1alpha1.ConditionSeverityError,
+				"Error creating identity in secret store: %v",
+				err,
+			)
+
+			return err
+		}
+
+		if err := updateOrVerifyIdentityHash(apiExport, key); err != nil {
+			conditions.MarkFalse(
+				apiExport,
+				apisv1alpha1.APIExportIdentityValid,
+				apisv1alpha1.IdentityVerificationFailedReason,
+				conditionsv1alpha1.ConditionSeverityError,
+				err.Error(),
+			)
+		}
+
+		return nil
+	}
+
 	if identity.SecretRef == nil {
 		c.ensureSecretNamespaceExists(ctx, clusterName)
 
@@ -104,13 +133,13 @@ func (c *controller) ensureSecretNamespaceExists(ctx context.Context, clusterNam
 			},
 		}
 		if err := c.createNamespace(ctx, clusterName, ns); err != nil && !errors.IsAlreadyExists(err) {
-			klog.Errorf("Error creating namespace %q in cluster %q for APIExport secret identities: %v", c.secretNamespace, clusterName, err)
+			logging.FromContext(ctx).Error(err, "Error creating namespace for APIExport secret identities", "namespace", c.secretNamespace)
 			// Keep going - maybe things will work. If the secret creation fails, we'll make sure to set a condition.
 		}
 	}
 }
 
-func (c *controller) generateIdentitySecret(apiExportName string) (*corev1.Secret, error) {
+func generateIdentityKey() ([]byte, error) {
 	privateKey, err := rsa.GenerateKey(cryptorand.Reader, 4096)
 	if err != nil {
 		return nil, fmt.Errorf("error generating private key: %w", err)
@@ -121,6 +150,15 @@ func (c *controller) generateIdentitySecret(apiExportName string) (*corev1.Secre
 		return nil, fmt.Errorf("error encoding private key: %w", err)
 	}
 
+	return encoded, nil
+}
+
+func (c *controller) generateIdentitySecret(apiExportName string) (*corev1.Secret, error) {
+	encoded, err := generateIdentityKey()
+	if err != nil {
+		return nil, err
+	}
+
 	secret := &corev1.Secret{
 		ObjectMeta: metav1.ObjectMeta{
 			Namespace: c.secretNamespace,
@@ -147,6 +185,42 @@ func (c *controller) createIdentitySecret(ctx context.Context, clusterName logic
 	return nil
 }
 
+// identitySecretStoreKey returns the key of the identity of the given APIExport in the secret store.
+func identitySecretStoreKey(clusterName logicalcluster.Name, apiExportName string) string {
+	return secretstore.Key("apiexports", clusterName.String(), apiExportName)
+}
+
+// getOrCreateStoredIdentity returns the identity of the APIExport from the secret store, generating it
+// if it does not exist yet. Of concurrent generations only the first is stored.
+func (c *controller) getOrCreateStoredIdentity(ctx context.Context, clusterName logicalcluster.Name, apiExportName string) ([]byte, error) {
+	storeKey := identitySecretStoreKey(clusterName, apiExportName)
+
+	data, err := c.secretStore.Get(ctx, storeKey)
+	if secretstore.IsNotFound(err) {
+		var encoded []byte
+		if encoded, err = generateIdentityKey(); err != nil {
+			return nil, err
+		}
+		err = c.secretStore.Create(ctx, storeKey, map[string][]byte{apisv1alpha1.SecretKeyAPIExportIdentity: encoded})
+		if err == nil {
+			return encoded, nil
+		}
+		if secretstore.IsAlreadyExists(err) {
+			data, err = c.secretStore.Get(ctx, storeKey)
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error getting identity from secret store key %q: %w", storeKey, err)
+	}
+
+	key := data[apisv1alpha1.SecretKeyAPIExportIdentity]
+	if len(key) == 0 {
+		return nil, fmt.Errorf("secret store key %q is missing %s", storeKey, apisv1alpha1.SecretKeyAPIExportIdentity)
+	}
+
+	return key, nil
+}
+
 func (c *controller) updateOrVerifyIdentitySecretHash(ctx context.Context, clusterName logicalcluster.Name, apiExport *apisv1alpha1.APIExport) error {
 	secret, err := c.getSecret(ctx, clusterName, apiExport.Spec.Identity.SecretRef.Namespace, apiExport.Spec.Identity.SecretRef.Name)
 	if err != nil {
@@ -158,6 +232,12 @@ func (c *controller) updateOrVerifyIdentitySecretHash(ctx context.Context, clust
 		return fmt.Errorf("secret is missing data.%s", apisv1alpha1.SecretKeyAPIExportIdentity)
 	}
 
+	return updateOrVerifyIdentityHash(apiExport, key)
+}
+
+// updateOrVerifyIdentityHash sets status.identityHash to the hash of the given identity key if
+// unset, or else verifies that it matches.
+func updateOrVerifyIdentityHash(apiExport *apisv1alpha1.APIExport, key []byte) error {
 	hashBytes := sha256.Sum256(key)
 	hash := fmt.Sprintf("%x", hashBytes)
 
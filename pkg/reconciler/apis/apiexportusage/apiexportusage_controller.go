@@ -0,0 +1,247 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apiexportusage
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/kcp-dev/logicalcluster"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/clusters"
+	"k8s.io/client-go/util/flowcontrol"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+
+	apisv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apis/v1alpha1"
+	kcpclient "github.com/kcp-dev/kcp/pkg/client/clientset/versioned"
+	apisinformers "github.com/kcp-dev/kcp/pkg/client/informers/externalversions/apis/v1alpha1"
+	apislisters "github.com/kcp-dev/kcp/pkg/client/listers/apis/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/logging"
+)
+
+const (
+	controllerName = "kcp-apiexport-usage"
+
+	indexAPIBindingsByBoundExport = "apiBindingsByBoundExport"
+
+	listPageSize = 500
+
+	// DefaultInterval is the default minimal time between two usage scans of an APIExport.
+	DefaultInterval = 10 * time.Minute
+	// scanQPS and scanBurst limit the list requests of all usage scans.
+	scanQPS   = 5
+	scanBurst = 10
+)
+
+// NewController returns a new controller that periodically aggregates the usage of APIExports
+// into their status, and into metrics if exportMetrics is true: the number of bound workspaces, and
+// the number of objects and the last activity per exported resource. Objects are counted by
+// metadata-only, paged wildcard lists of the identity of the APIExport, and these lists are rate
+// limited across all APIExports.
+func NewController(
+	kcpClusterClient kcpclient.ClusterInterface,
+	metadataClusterClient dynamic.ClusterInterface,
+	apiExportInformer apisinformers.APIExportInformer,
+	apiBindingInformer apisinformers.APIBindingInformer,
+	apiResourceSchemaInformer apisinformers.APIResourceSchemaInformer,
+	interval time.Duration,
+	exportMetrics bool,
+) (*controller, error) {
+	queue := workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), controllerName)
+
+	c := &controller{
+		queue:           queue,
+		apiExportLister: apiExportInformer.Lister(),
+		listBoundAPIBindings: func(clusterName logicalcluster.Name, name string) ([]*apisv1alpha1.APIBinding, error) {
+			objs, err := apiBindingInformer.Informer().GetIndexer().ByIndex(indexAPIBindingsByBoundExport, clusters.ToClusterAwareKey(clusterName, name))
+			if err != nil {
+				return nil, err
+			}
+			ret := make([]*apisv1alpha1.APIBinding, 0, len(objs))
+			for _, obj := range objs {
+				ret = append(ret, obj.(*apisv1alpha1.APIBinding))
+			}
+			return ret, nil
+		},
+		getAPIResourceSchema: func(clusterName logicalcluster.Name, name string) (*apisv1alpha1.APIResourceSchema, error) {
+			return apiResourceSchemaInformer.Lister().Get(clusters.ToClusterAwareKey(clusterName, name))
+		},
+		listObjects: func(ctx context.Context, gvr schema.GroupVersionResource, opts metav1.ListOptions) (*unstructured.UnstructuredList, error) {
+			return metadataClusterClient.Cluster(logicalcluster.Wildcard).Resource(gvr).List(ctx, opts)
+		},
+		patchAPIExportStatus: func(ctx context.Context, clusterName logicalcluster.Name, name string, patch []byte) error {
+			_, err := kcpClusterClient.Cluster(clusterName).ApisV1alpha1().APIExports().Patch(ctx, name, types.MergePatchType, patch, metav1.PatchOptions{}, "status")
+			return err
+		},
+		scanRateLimiter: flowcontrol.NewTokenBucketRateLimiter(scanQPS, scanBurst),
+		interval:        interval,
+		exportMetrics:   exportMetrics,
+		now:             time.Now,
+	}
+
+	if exportMetrics {
+		RegisterMetrics()
+	}
+
+	if err := apiBindingInformer.Informer().AddIndexers(cache.Indexers{
+		indexAPIBindingsByBoundExport: indexAPIBindingsByBoundExportFunc,
+	}); err != nil {
+		return nil, err
+	}
+
+	apiExportInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj interface{}) { c.enqueueAPIExport(obj) },
+		UpdateFunc: func(_, obj interface{}) { c.enqueueAPIExport(obj) },
+		DeleteFunc: func(obj interface{}) {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			if apiExport, ok := obj.(*apisv1alpha1.APIExport); ok && c.exportMetrics {
+				forgetMetrics(apiExport)
+			}
+		},
+	})
+
+	return c, nil
+}
+
+// controller aggregates the usage of APIExports. Every APIExport is scanned at most once per interval.
+type controller struct {
+	queue workqueue.RateLimitingInterface
+
+	apiExportLister      apislisters.APIExportLister
+	listBoundAPIBindings func(clusterName logicalcluster.Name, name string) ([]*apisv1alpha1.APIBinding, error)
+	getAPIResourceSchema func(clusterName logicalcluster.Name, name string) (*apisv1alpha1.APIResourceSchema, error)
+	listObjects          func(ctx context.Context, gvr schema.GroupVersionResource, opts metav1.ListOptions) (*unstructured.UnstructuredList, error)
+	patchAPIExportStatus func(ctx context.Context, clusterName logicalcluster.Name, name string, patch []byte) error
+
+	scanRateLimiter flowcontrol.RateLimiter
+	interval        time.Duration
+	exportMetrics   bool
+	now             func() time.Time
+}
+
+// indexAPIBindingsByBoundExportFunc is an index function that maps an APIBinding to the key of the
+// APIExport it is bound to, i.e. of status.boundExport.
+func indexAPIBindingsByBoundExportFunc(obj interface{}) ([]string, error) {
+	apiBinding, ok := obj.(*apisv1alpha1.APIBinding)
+	if !ok {
+		return []string{}, fmt.Errorf("obj is supposed to be an APIBinding, but is %T", obj)
+	}
+
+	if apiBinding.Status.BoundAPIExport == nil || apiBinding.Status.BoundAPIExport.Workspace == nil {
+		return []string{}, nil
+	}
+
+	ref := apiBinding.Status.BoundAPIExport.Workspace
+	exportClusterName, ok := ref.ClusterName(logicalcluster.From(apiBinding))
+	if !ok {
+		return []string{}, nil
+	}
+	return []string{clusters.ToClusterAwareKey(exportClusterName, ref.ExportName)}, nil
+}
+
+func (c *controller) enqueueAPIExport(obj interface{}) {
+	key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+	if err != nil {
+		runtime.HandleError(err)
+		return
+	}
+
+	logging.WithQueueKey(logging.NewLogger(controllerName), key).V(4).Info("Queueing APIExport")
+	c.queue.Add(key)
+}
+
+// Start starts the controller, which stops when ctx.Done() is closed.
+func (c *controller) Start(ctx context.Context, numThreads int) {
+	defer runtime.HandleCrash()
+	defer c.queue.ShutDown()
+
+	klog.Infof("Starting %s controller", controllerName)
+	defer klog.Infof("Shutting down %s controller", controllerName)
+
+	for i := 0; i < numThreads; i++ {
+		go wait.UntilWithContext(ctx, c.startWorker, time.Second)
+	}
+
+	<-ctx.Done()
+}
+
+func (c *controller) startWorker(ctx context.Context) {
+	for c.processNextWorkItem(ctx) {
+	}
+}
+
+func (c *controller) processNextWorkItem(ctx context.Context) bool {
+	// Wait until there is a new item in the working queue
+	k, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+	key := k.(string)
+
+	logger := logging.WithQueueKey(logging.NewLogger(controllerName), key)
+	ctx = logging.NewContext(ctx, logger)
+
+	// No matter what, tell the queue we're done with this key, to unblock
+	// other workers.
+	defer c.queue.Done(key)
+
+	if err := c.process(ctx, key); err != nil {
+		runtime.HandleError(fmt.Errorf("%q controller failed to sync %q, err: %w", controllerName, key, err))
+		c.queue.AddRateLimited(key)
+		return true
+	}
+	c.queue.Forget(key)
+	return true
+}
+
+func (c *controller) process(ctx context.Context, key string) error {
+	apiExport, err := c.apiExportLister.Get(key)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil // object deleted before we handled it
+		}
+		return err
+	}
+
+	// scan at most once per interval, also when the APIExport changes in between
+	if usage := apiExport.Status.Usage; usage != nil {
+		if next := usage.LastUpdateTime.Add(c.interval); c.now().Before(next) {
+			c.queue.AddAfter(key, next.Sub(c.now()))
+			return nil
+		}
+	}
+
+	if err := c.reconcile(ctx, apiExport); err != nil {
+		return err
+	}
+
+	c.queue.AddAfter(key, c.interval)
+	return nil
+}
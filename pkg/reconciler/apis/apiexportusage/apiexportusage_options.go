@@ -0,0 +1,48 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apiexportusage
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+func DefaultOptions() *Options {
+	return &Options{
+		Interval: DefaultInterval,
+	}
+}
+
+func BindOptions(o *Options, fs *pflag.FlagSet) *Options {
+	fs.DurationVar(&o.Interval, "apiexport-usage-interval", o.Interval, "Minimal time between two scans of the objects bound through an APIExport for its usage status")
+	fs.BoolVar(&o.Metrics, "apiexport-usage-metrics", o.Metrics, "Export the usage of every APIExport also as metrics, labelled by workspace and name of the APIExport. Only enable with a bounded number of APIExports")
+	return o
+}
+
+type Options struct {
+	Interval time.Duration
+	Metrics  bool
+}
+
+func (o *Options) Validate() error {
+	if o.Interval <= 0 {
+		return fmt.Errorf("--apiexport-usage-interval must be >0 (%s)", o.Interval)
+	}
+	return nil
+}
@@ -0,0 +1,184 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apiexportusage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/kcp-dev/logicalcluster"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	apisv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apis/v1alpha1"
+)
+
+func (c *controller) reconcile(ctx context.Context, apiExport *apisv1alpha1.APIExport) error {
+	if apiExport.Status.IdentityHash == "" {
+		// without identity, nothing can be bound. We are requeued when the identity is set.
+		return nil
+	}
+
+	clusterName := logicalcluster.From(apiExport)
+
+	apiBindings, err := c.listBoundAPIBindings(clusterName, apiExport.Name)
+	if err != nil {
+		return err
+	}
+	workspaces := sets.NewString()
+	for _, apiBinding := range apiBindings {
+		workspaces.Insert(logicalcluster.From(apiBinding).String())
+	}
+
+	usage := &apisv1alpha1.APIExportUsage{
+		BoundWorkspaces: int32(workspaces.Len()),
+		LastUpdateTime:  metav1.NewTime(c.now()),
+	}
+	for _, schemaName := range apiExport.Spec.LatestResourceSchemas {
+		apiResourceSchema, err := c.getAPIResourceSchema(clusterName, schemaName)
+		if errors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		resourceUsage := apisv1alpha1.APIExportResourceUsage{
+			Group:    apiResourceSchema.Spec.Group,
+			Resource: apiResourceSchema.Spec.Names.Plural,
+		}
+		if workspaces.Len() > 0 {
+			if err := c.scanResource(ctx, apiExport.Status.IdentityHash, apiResourceSchema, &resourceUsage); err != nil {
+				return fmt.Errorf("failed to scan %s.%s of APIExport %s|%s: %w", resourceUsage.Resource, resourceUsage.Group, clusterName, apiExport.Name, err)
+			}
+		}
+		usage.Resources = append(usage.Resources, resourceUsage)
+	}
+
+	if c.exportMetrics {
+		updateMetrics(apiExport, usage)
+	}
+
+	patch, err := json.Marshal(map[string]interface{}{
+		"status": map[string]interface{}{
+			"usage": usage,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	return c.patchAPIExportStatus(ctx, clusterName, apiExport.Name, patch)
+}
+
+// scanResource counts the objects of the resource of the given schema in all workspaces bound with the
+// given identity, and finds their last activity.
+func (c *controller) scanResource(ctx context.Context, identityHash string, apiResourceSchema *apisv1alpha1.APIResourceSchema, usage *apisv1alpha1.APIExportResourceUsage) error {
+	var version string
+	for _, v := range apiResourceSchema.Spec.Versions {
+		if v.Storage {
+			version = v.Name
+			break
+		}
+	}
+	if version == "" {
+		return fmt.Errorf("APIResourceSchema %s has no storage version", apiResourceSchema.Name)
+	}
+
+	gvr := schema.GroupVersionResource{
+		Group:    apiResourceSchema.Spec.Group,
+		Version:  version,
+		Resource: apiResourceSchema.Spec.Names.Plural + ":" + identityHash,
+	}
+	opts := metav1.ListOptions{Limit: listPageSize}
+	for {
+		if err := c.scanRateLimiter.Wait(ctx); err != nil {
+			return err
+		}
+
+		list, err := c.listObjects(ctx, gvr, opts)
+		if err != nil {
+			return err
+		}
+
+		for i := range list.Items {
+			usage.ObjectCount++
+			if t := lastActivity(&list.Items[i]); t != nil && (usage.LastActivityTime == nil || usage.LastActivityTime.Before(t)) {
+				usage.LastActivityTime = t
+			}
+		}
+
+		if list.GetContinue() == "" {
+			return nil
+		}
+		opts.Continue = list.GetContinue()
+	}
+}
+
+// lastActivity returns the latest of the creation time and the managed fields times of the object.
+func lastActivity(obj metav1.Object) *metav1.Time {
+	latest := obj.GetCreationTimestamp()
+	for _, managedFields := range obj.GetManagedFields() {
+		if managedFields.Time != nil && latest.Before(managedFields.Time) {
+			latest = *managedFields.Time
+		}
+	}
+	if latest.IsZero() {
+		return nil
+	}
+	return &latest
+}
+
+// updateMetrics sets the usage metrics of the APIExport, and removes those of resources not exported anymore.
+func updateMetrics(apiExport *apisv1alpha1.APIExport, usage *apisv1alpha1.APIExportUsage) {
+	clusterName := logicalcluster.From(apiExport).String()
+
+	boundWorkspaces.WithLabelValues(clusterName, apiExport.Name).Set(float64(usage.BoundWorkspaces))
+
+	exported := sets.NewString()
+	for _, r := range usage.Resources {
+		boundObjects.WithLabelValues(clusterName, apiExport.Name, r.Group, r.Resource).Set(float64(r.ObjectCount))
+		exported.Insert(r.Resource + "." + r.Group)
+	}
+
+	if apiExport.Status.Usage != nil {
+		for _, r := range apiExport.Status.Usage.Resources {
+			if !exported.Has(r.Resource + "." + r.Group) {
+				boundObjects.Delete(objectsLabels(clusterName, apiExport.Name, r))
+			}
+		}
+	}
+}
+
+// forgetMetrics removes the usage metrics of a deleted APIExport.
+func forgetMetrics(apiExport *apisv1alpha1.APIExport) {
+	clusterName := logicalcluster.From(apiExport).String()
+
+	boundWorkspaces.Delete(map[string]string{"workspace": clusterName, "export": apiExport.Name})
+	if apiExport.Status.Usage != nil {
+		for _, r := range apiExport.Status.Usage.Resources {
+			boundObjects.Delete(objectsLabels(clusterName, apiExport.Name, r))
+		}
+	}
+}
+
+func objectsLabels(clusterName, exportName string, r apisv1alpha1.APIExportResourceUsage) map[string]string {
+	return map[string]string{"workspace": clusterName, "export": exportName, "group": r.Group, "resource": r.Resource}
+}
@@ -0,0 +1,173 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apiexportusage
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/kcp-dev/logicalcluster"
+	"github.com/stretchr/testify/require"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/util/flowcontrol"
+
+	apisv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apis/v1alpha1"
+)
+
+func TestReconcile(t *testing.T) {
+	now := time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC)
+	created := metav1.NewTime(now.Add(-2 * time.Hour))
+	updated := metav1.NewTime(now.Add(-time.Hour))
+
+	object := func(name string, creationTimestamp metav1.Time, managedFieldsTimes ...metav1.Time) unstructured.Unstructured {
+		var obj unstructured.Unstructured
+		obj.SetName(name)
+		obj.SetCreationTimestamp(creationTimestamp)
+		var managedFields []metav1.ManagedFieldsEntry
+		for i := range managedFieldsTimes {
+			managedFields = append(managedFields, metav1.ManagedFieldsEntry{Manager: "test", Time: &managedFieldsTimes[i]})
+		}
+		obj.SetManagedFields(managedFields)
+		return obj
+	}
+
+	apiExport := &apisv1alpha1.APIExport{
+		ObjectMeta: metav1.ObjectMeta{ClusterName: "root:org:provider", Name: "widgets"},
+		Spec: apisv1alpha1.APIExportSpec{
+			LatestResourceSchemas: []string{"today.widgets.example.io", "today.gadgets.example.io"},
+		},
+		Status: apisv1alpha1.APIExportStatus{IdentityHash: "hash"},
+	}
+	schemas := map[string]*apisv1alpha1.APIResourceSchema{
+		"today.widgets.example.io": {
+			ObjectMeta: metav1.ObjectMeta{Name: "today.widgets.example.io"},
+			Spec: apisv1alpha1.APIResourceSchemaSpec{
+				Group:    "example.io",
+				Names:    apiextensionsv1.CustomResourceDefinitionNames{Plural: "widgets"},
+				Versions: []apisv1alpha1.APIResourceVersion{{Name: "v1alpha1"}, {Name: "v1", Storage: true}},
+			},
+		},
+		"today.gadgets.example.io": {
+			ObjectMeta: metav1.ObjectMeta{Name: "today.gadgets.example.io"},
+			Spec: apisv1alpha1.APIResourceSchemaSpec{
+				Group:    "example.io",
+				Names:    apiextensionsv1.CustomResourceDefinitionNames{Plural: "gadgets"},
+				Versions: []apisv1alpha1.APIResourceVersion{{Name: "v1", Storage: true}},
+			},
+		},
+	}
+
+	tests := map[string]struct {
+		apiBindings []*apisv1alpha1.APIBinding
+		pages       map[string][]unstructured.UnstructuredList
+		want        *apisv1alpha1.APIExportUsage
+	}{
+		"no bindings": {
+			want: &apisv1alpha1.APIExportUsage{
+				LastUpdateTime: metav1.NewTime(now),
+				Resources: []apisv1alpha1.APIExportResourceUsage{
+					{Group: "example.io", Resource: "widgets"},
+					{Group: "example.io", Resource: "gadgets"},
+				},
+			},
+		},
+		"bindings in two workspaces": {
+			apiBindings: []*apisv1alpha1.APIBinding{
+				{ObjectMeta: metav1.ObjectMeta{ClusterName: "root:org:a", Name: "widgets"}},
+				{ObjectMeta: metav1.ObjectMeta{ClusterName: "root:org:a", Name: "widgets-2"}},
+				{ObjectMeta: metav1.ObjectMeta{ClusterName: "root:org:b", Name: "widgets"}},
+			},
+			pages: map[string][]unstructured.UnstructuredList{
+				"widgets:hash": {
+					{Items: []unstructured.Unstructured{object("a", created), object("b", created, updated)}},
+					{Items: []unstructured.Unstructured{object("c", created)}},
+				},
+				"gadgets:hash": {
+					{},
+				},
+			},
+			want: &apisv1alpha1.APIExportUsage{
+				BoundWorkspaces: 2,
+				LastUpdateTime:  metav1.NewTime(now),
+				Resources: []apisv1alpha1.APIExportResourceUsage{
+					{Group: "example.io", Resource: "widgets", ObjectCount: 3, LastActivityTime: &updated},
+					{Group: "example.io", Resource: "gadgets"},
+				},
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var patched *apisv1alpha1.APIExport
+			c := &controller{
+				listBoundAPIBindings: func(clusterName logicalcluster.Name, name string) ([]*apisv1alpha1.APIBinding, error) {
+					require.Equal(t, "root:org:provider", clusterName.String())
+					require.Equal(t, "widgets", name)
+					return tc.apiBindings, nil
+				},
+				getAPIResourceSchema: func(clusterName logicalcluster.Name, name string) (*apisv1alpha1.APIResourceSchema, error) {
+					return schemas[name], nil
+				},
+				listObjects: func(ctx context.Context, gvr schema.GroupVersionResource, opts metav1.ListOptions) (*unstructured.UnstructuredList, error) {
+					require.Equal(t, "v1", gvr.Version)
+					require.Equal(t, int64(listPageSize), opts.Limit)
+					pages := tc.pages[gvr.Resource]
+					page := 0
+					if opts.Continue != "" {
+						page = 1
+					}
+					list := pages[page].DeepCopy()
+					if page+1 < len(pages) {
+						list.SetContinue("next")
+					}
+					return list, nil
+				},
+				patchAPIExportStatus: func(ctx context.Context, clusterName logicalcluster.Name, name string, patch []byte) error {
+					patched = &apisv1alpha1.APIExport{}
+					return json.Unmarshal(patch, patched)
+				},
+				scanRateLimiter: flowcontrol.NewFakeAlwaysRateLimiter(),
+				now:             func() time.Time { return now },
+			}
+
+			err := c.reconcile(context.Background(), apiExport.DeepCopy())
+			require.NoError(t, err)
+			require.NotNil(t, patched)
+			require.Equal(t, tc.want.BoundWorkspaces, patched.Status.Usage.BoundWorkspaces)
+			require.True(t, tc.want.LastUpdateTime.Equal(&patched.Status.Usage.LastUpdateTime))
+			require.Len(t, patched.Status.Usage.Resources, len(tc.want.Resources))
+			for i, want := range tc.want.Resources {
+				got := patched.Status.Usage.Resources[i]
+				require.Equal(t, want.Group, got.Group)
+				require.Equal(t, want.Resource, got.Resource)
+				require.Equal(t, want.ObjectCount, got.ObjectCount)
+				if want.LastActivityTime == nil {
+					require.Nil(t, got.LastActivityTime)
+				} else {
+					require.True(t, want.LastActivityTime.Equal(got.LastActivityTime))
+				}
+			}
+		})
+	}
+}
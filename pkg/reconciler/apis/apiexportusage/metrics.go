@@ -0,0 +1,55 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apiexportusage
+
+import (
+	"sync"
+
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+)
+
+var (
+	boundWorkspaces = metrics.NewGaugeVec(
+		&metrics.GaugeOpts{
+			Namespace:      "kcp",
+			Name:           "apiexport_bound_workspaces",
+			Help:           "Number of workspaces bound to an APIExport, by workspace and name of the APIExport.",
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"workspace", "export"},
+	)
+	boundObjects = metrics.NewGaugeVec(
+		&metrics.GaugeOpts{
+			Namespace:      "kcp",
+			Name:           "apiexport_bound_objects",
+			Help:           "Number of objects of an exported resource in all bound workspaces, by workspace and name of the APIExport, group and resource.",
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"workspace", "export", "group", "resource"},
+	)
+)
+
+var registerMetrics sync.Once
+
+// RegisterMetrics registers the APIExport usage controller metrics.
+func RegisterMetrics() {
+	registerMetrics.Do(func() {
+		legacyregistry.MustRegister(boundWorkspaces)
+		legacyregistry.MustRegister(boundObjects)
+	})
+}
@@ -0,0 +1,84 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apiresource
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kcp-dev/logicalcluster"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apiresourcev1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apiresource/v1alpha1"
+)
+
+// isApprovedByPolicy returns whether negotiated API resources of the given group are
+// published without waiting for a manual approval.
+func (c *Controller) isApprovedByPolicy(group string) bool {
+	if c.AutoPublishNegotiatedAPIResource {
+		return true
+	}
+	if group == "" {
+		group = "core"
+	}
+	return c.AutoPublishAPIGroups.Has(group)
+}
+
+// approvalCondition returns the Approved condition matching spec.publish of the given negotiated API resource.
+func approvalCondition(negotiatedAPIResource *apiresourcev1alpha1.NegotiatedAPIResource, approvedByPolicy bool) apiresourcev1alpha1.NegotiatedAPIResourceCondition {
+	switch {
+	case !negotiatedAPIResource.Spec.Publish:
+		return apiresourcev1alpha1.NegotiatedAPIResourceCondition{
+			Type:    apiresourcev1alpha1.Approved,
+			Status:  metav1.ConditionFalse,
+			Reason:  apiresourcev1alpha1.ApprovalPendingReason,
+			Message: fmt.Sprintf("Run 'kubectl kcp workload approve-api %s' to publish the API", negotiatedAPIResource.Name),
+		}
+	case negotiatedAPIResource.IsConditionTrue(apiresourcev1alpha1.Enforced):
+		return apiresourcev1alpha1.NegotiatedAPIResourceCondition{
+			Type:   apiresourcev1alpha1.Approved,
+			Status: metav1.ConditionTrue,
+			Reason: apiresourcev1alpha1.ApprovedByEnforcedCRDReason,
+		}
+	case approvedByPolicy:
+		return apiresourcev1alpha1.NegotiatedAPIResourceCondition{
+			Type:   apiresourcev1alpha1.Approved,
+			Status: metav1.ConditionTrue,
+			Reason: apiresourcev1alpha1.ApprovedByPolicyReason,
+		}
+	default:
+		return apiresourcev1alpha1.NegotiatedAPIResourceCondition{
+			Type:   apiresourcev1alpha1.Approved,
+			Status: metav1.ConditionTrue,
+			Reason: apiresourcev1alpha1.ApprovedManuallyReason,
+		}
+	}
+}
+
+// updateApprovalCondition updates the Approved condition of the given negotiated API resource if
+// it changed, and returns the updated object.
+func (c *Controller) updateApprovalCondition(ctx context.Context, negotiatedAPIResource *apiresourcev1alpha1.NegotiatedAPIResource) (*apiresourcev1alpha1.NegotiatedAPIResource, error) {
+	condition := approvalCondition(negotiatedAPIResource, c.isApprovedByPolicy(negotiatedAPIResource.Spec.GroupVersion.Group))
+	if apiresourcev1alpha1.IsNegotiatedAPIResourceConditionEquivalent(negotiatedAPIResource.FindCondition(apiresourcev1alpha1.Approved), &condition) {
+		return negotiatedAPIResource, nil
+	}
+
+	negotiatedAPIResource = negotiatedAPIResource.DeepCopy()
+	negotiatedAPIResource.SetCondition(condition)
+	return c.kcpClusterClient.Cluster(logicalcluster.From(negotiatedAPIResource)).ApiresourceV1alpha1().NegotiatedAPIResources().UpdateStatus(ctx, negotiatedAPIResource, metav1.UpdateOptions{})
+}
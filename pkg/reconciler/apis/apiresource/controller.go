@@ -30,6 +30,7 @@ import (
 	"k8s.io/apimachinery/pkg/api/equality"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/util/workqueue"
@@ -39,6 +40,7 @@ import (
 	kcpclient "github.com/kcp-dev/kcp/pkg/client/clientset/versioned"
 	apiresourceinformer "github.com/kcp-dev/kcp/pkg/client/informers/externalversions/apiresource/v1alpha1"
 	apiresourcelister "github.com/kcp-dev/kcp/pkg/client/listers/apiresource/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/logging"
 )
 
 const clusterNameAndGVRIndexName = "clusterNameAndGVR"
@@ -52,6 +54,7 @@ func NewController(
 	crdClusterClient *apiextensionsclientset.Cluster,
 	kcpClusterClient *kcpclient.Cluster,
 	autoPublishNegotiatedAPIResource bool,
+	autoPublishAPIGroups []string,
 	negotiatedAPIResourceInformer apiresourceinformer.NegotiatedAPIResourceInformer,
 	apiResourceImportInformer apiresourceinformer.APIResourceImportInformer,
 	crdInformer crdinfomer.CustomResourceDefinitionInformer,
@@ -63,6 +66,7 @@ func NewController(
 		crdClusterClient:                 crdClusterClient,
 		kcpClusterClient:                 kcpClusterClient,
 		AutoPublishNegotiatedAPIResource: autoPublishNegotiatedAPIResource,
+		AutoPublishAPIGroups:             sets.NewString(autoPublishAPIGroups...),
 		negotiatedApiResourceIndexer:     negotiatedAPIResourceInformer.Informer().GetIndexer(),
 		negotiatedApiResourceLister:      negotiatedAPIResourceInformer.Lister(),
 		apiResourceImportIndexer:         apiResourceImportInformer.Informer().GetIndexer(),
@@ -140,6 +144,9 @@ type Controller struct {
 	crdLister  crdlister.CustomResourceDefinitionLister
 
 	AutoPublishNegotiatedAPIResource bool
+	// AutoPublishAPIGroups are the API groups published without manual approval if
+	// AutoPublishNegotiatedAPIResource is false. The core group is "core".
+	AutoPublishAPIGroups sets.String
 }
 
 type queueElementType string
@@ -316,6 +323,9 @@ func (c *Controller) processNextWorkItem(ctx context.Context) bool {
 	}
 	key := k.(queueElement)
 
+	logger := logging.NewLogger(controllerName).WithValues(logging.ReconcilerKey, key.theKey, logging.LogicalClusterKey, key.clusterName.String(), "type", key.theType, "action", key.theAction)
+	ctx = logging.NewContext(ctx, logger)
+
 	// No matter what, tell the queue we're done with this key, to unblock
 	// other workers.
 	defer c.queue.Done(key)
@@ -31,9 +31,9 @@ import (
 	"k8s.io/apimachinery/pkg/version"
 	"k8s.io/apiserver/pkg/endpoints/request"
 	"k8s.io/client-go/tools/cache"
-	"k8s.io/klog/v2"
 
 	apiresourcev1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apiresource/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/logging"
 	"github.com/kcp-dev/kcp/pkg/schemacompat"
 )
 
@@ -133,6 +133,12 @@ func (c *Controller) process(ctx context.Context, key queueElement) error {
 		}
 		switch key.theAction {
 		case createdAction, specChangedAction:
+			// => Reflect the approval (spec.Publish) in the Approved condition.
+			negotiatedApiResource, err = c.updateApprovalCondition(ctx, negotiatedApiResource)
+			if err != nil {
+				return err
+			}
+
 			// if status.Enforced
 			// => Check the schema of all APIResourceImports for this GVR against the schema of the NegotiatedAPIResource, and update the
 			//    status of each one with the right Compatible condition.
@@ -210,7 +216,7 @@ func (c *Controller) enforceCRDToNegotiatedAPIResource(ctx context.Context, clus
 				Resource: gvr.Resource,
 			}))
 		if err != nil {
-			klog.Errorf("Error in %s: %v", runtime.GetCaller(), err)
+			logging.FromContext(ctx).Error(err, "Error", "caller", runtime.GetCaller())
 			return err
 		}
 		for _, obj := range objects {
@@ -220,7 +226,7 @@ func (c *Controller) enforceCRDToNegotiatedAPIResource(ctx context.Context, clus
 				Status: metav1.ConditionTrue,
 			})
 			if _, err := c.kcpClusterClient.Cluster(logicalcluster.From(negotiatedAPIResource)).ApiresourceV1alpha1().NegotiatedAPIResources().UpdateStatus(ctx, negotiatedAPIResource, metav1.UpdateOptions{}); err != nil {
-				klog.Errorf("Error updating NegotiatedAPIResource status: %v", err)
+				logging.FromContext(ctx).Error(err, "Error updating NegotiatedAPIResource status")
 				return err
 			}
 			// TODO: manage the case when the manually applied CRD has no schema or an invalid schema...
@@ -228,7 +234,7 @@ func (c *Controller) enforceCRDToNegotiatedAPIResource(ctx context.Context, clus
 				return err
 			}
 			if _, err := c.kcpClusterClient.Cluster(logicalcluster.From(negotiatedAPIResource)).ApiresourceV1alpha1().NegotiatedAPIResources().Update(ctx, negotiatedAPIResource, metav1.UpdateOptions{}); err != nil {
-				klog.Errorf("Error updating NegotiatedAPIResource: %v", err)
+				logging.FromContext(ctx).Error(err, "Error updating NegotiatedAPIResource")
 				return err
 			}
 		}
@@ -277,7 +283,7 @@ func (c *Controller) updatePublishingStatusOnNegotiatedAPIResources(ctx context.
 				Resource: gvr.Resource,
 			}))
 		if err != nil {
-			klog.Errorf("Error in %s: %v", runtime.GetCaller(), err)
+			logging.FromContext(ctx).Error(err, "Error", "caller", runtime.GetCaller())
 			return err
 		}
 		for _, obj := range objects {
@@ -285,7 +291,7 @@ func (c *Controller) updatePublishingStatusOnNegotiatedAPIResources(ctx context.
 			c.setPublishingStatusOnNegotiatedAPIResource(ctx, clusterName, gvr, negotiatedAPIResource, crd)
 			_, err := c.kcpClusterClient.Cluster(logicalcluster.From(negotiatedAPIResource)).ApiresourceV1alpha1().NegotiatedAPIResources().UpdateStatus(ctx, negotiatedAPIResource, metav1.UpdateOptions{})
 			if err != nil {
-				klog.Errorf("Error in %s: %v", runtime.GetCaller(), err)
+				logging.FromContext(ctx).Error(err, "Error", "caller", runtime.GetCaller())
 				return err
 			}
 		}
@@ -302,7 +308,7 @@ func (c *Controller) deleteNegotiatedAPIResource(ctx context.Context, clusterNam
 		gvrsToDelete = []metav1.GroupVersionResource{gvr}
 	} else {
 		if crd == nil {
-			klog.Errorf("CRD is nil after deletion => no way to find the NegotiatedAPIResources to delete from the CRD versions")
+			logging.FromContext(ctx).Error(nil, "CRD is nil after deletion => no way to find the NegotiatedAPIResources to delete from the CRD versions")
 			return nil
 		}
 		for _, version := range crd.Spec.Versions {
@@ -316,17 +322,17 @@ func (c *Controller) deleteNegotiatedAPIResource(ctx context.Context, clusterNam
 	for _, gvrToDelete := range gvrsToDelete {
 		objs, err := c.negotiatedApiResourceIndexer.ByIndex(clusterNameAndGVRIndexName, GetClusterNameAndGVRIndexKey(clusterName, gvrToDelete))
 		if err != nil {
-			klog.Warningf("NegotiatedAPIResource for GVR %s could not be searched in index, and could not be deleted: %v", gvr.String(), err)
+			logging.FromContext(ctx).Error(err, "NegotiatedAPIResource could not be searched in index, and could not be deleted", "gvr", gvr.String())
 		}
 		if len(objs) == 0 {
-			klog.Warningf("NegotiatedAPIResource for GVR %s was not found and could not be deleted", gvr.String())
+			logging.FromContext(ctx).Info("NegotiatedAPIResource was not found and could not be deleted", "gvr", gvr.String())
 			continue
 		}
 
 		toDelete := objs[0].(*apiresourcev1alpha1.NegotiatedAPIResource)
 		err = c.kcpClusterClient.Cluster(logicalcluster.From(toDelete)).ApiresourceV1alpha1().NegotiatedAPIResources().Delete(ctx, toDelete.Name, metav1.DeleteOptions{})
 		if err != nil {
-			klog.Errorf("Error in %s: %v", runtime.GetCaller(), err)
+			logging.FromContext(ctx).Error(err, "Error", "caller", runtime.GetCaller())
 			return err
 		}
 	}
@@ -347,7 +353,7 @@ func (c *Controller) ensureAPIResourceCompatibility(ctx context.Context, cluster
 	var negotiatedAPIResource *apiresourcev1alpha1.NegotiatedAPIResource
 	objs, err := c.negotiatedApiResourceIndexer.ByIndex(clusterNameAndGVRIndexName, GetClusterNameAndGVRIndexKey(clusterName, gvr))
 	if err != nil {
-		klog.Errorf("Error in %s: %v", runtime.GetCaller(), err)
+		logging.FromContext(ctx).Error(err, "Error", "caller", runtime.GetCaller())
 		return err
 	}
 	if len(objs) > 0 {
@@ -360,7 +366,7 @@ func (c *Controller) ensureAPIResourceCompatibility(ctx context.Context, cluster
 	} else {
 		objs, err := c.apiResourceImportIndexer.ByIndex(clusterNameAndGVRIndexName, GetClusterNameAndGVRIndexKey(clusterName, gvr))
 		if err != nil {
-			klog.Errorf("Error in %s: %v", runtime.GetCaller(), err)
+			logging.FromContext(ctx).Error(err, "Error", "caller", runtime.GetCaller())
 			return err
 		}
 		for _, obj := range objs {
@@ -402,12 +408,12 @@ func (c *Controller) ensureAPIResourceCompatibility(ctx context.Context, cluster
 		},
 	})
 	if err != nil {
-		klog.Errorf("Error in %s: %v", runtime.GetCaller(), err)
+		logging.FromContext(ctx).Error(err, "Error", "caller", runtime.GetCaller())
 		return err
 	}
 	crd, err := c.crdLister.Get(crdkey)
 	if err != nil && !k8serrors.IsNotFound(err) {
-		klog.Errorf("Error in %s: %v", runtime.GetCaller(), err)
+		logging.FromContext(ctx).Error(err, "Error", "caller", runtime.GetCaller())
 		return err
 	}
 	if crd != nil && c.isManuallyCreatedCRD(ctx, crd) {
@@ -470,7 +476,7 @@ func (c *Controller) ensureAPIResourceCompatibility(ctx context.Context, cluster
 				},
 				Spec: apiresourcev1alpha1.NegotiatedAPIResourceSpec{
 					CommonAPIResourceSpec: apiResourceImport.Spec.CommonAPIResourceSpec,
-					Publish:               c.AutoPublishNegotiatedAPIResource,
+					Publish:               c.isApprovedByPolicy(gvr.Group),
 				},
 			}
 			if negotiatedAPIResource != nil {
@@ -492,12 +498,12 @@ func (c *Controller) ensureAPIResourceCompatibility(ctx context.Context, cluster
 
 			importSchema, err := apiResourceImport.Spec.GetSchema()
 			if err != nil {
-				klog.Errorf("Error in %s: %v", runtime.GetCaller(), err)
+				logging.FromContext(ctx).Error(err, "Error", "caller", runtime.GetCaller())
 				return err
 			}
 			negotiatedSchema, err := newNegotiatedAPIResource.Spec.GetSchema()
 			if err != nil {
-				klog.Errorf("Error in %s: %v", runtime.GetCaller(), err)
+				logging.FromContext(ctx).Error(err, "Error", "caller", runtime.GetCaller())
 				return err
 			}
 
@@ -537,17 +543,17 @@ func (c *Controller) ensureAPIResourceCompatibility(ctx context.Context, cluster
 		apiResourceImportUpdateStatusFuncs = append(apiResourceImportUpdateStatusFuncs, func() error {
 			key, err := cache.MetaNamespaceKeyFunc(apiResourceImport)
 			if err != nil {
-				klog.Errorf("Error in %s: %v", runtime.GetCaller(), err)
+				logging.FromContext(ctx).Error(err, "Error", "caller", runtime.GetCaller())
 				return err
 			}
 			lastOne, err := c.apiResourceImportLister.Get(key)
 			if err != nil {
-				klog.Errorf("Error in %s: %v", runtime.GetCaller(), err)
+				logging.FromContext(ctx).Error(err, "Error", "caller", runtime.GetCaller())
 				return err
 			}
 			apiResourceImport.SetResourceVersion(lastOne.GetResourceVersion())
 			if _, err := c.kcpClusterClient.Cluster(logicalcluster.From(apiResourceImport)).ApiresourceV1alpha1().APIResourceImports().UpdateStatus(ctx, apiResourceImport, metav1.UpdateOptions{}); err != nil {
-				klog.Errorf("Error in %s: %v", runtime.GetCaller(), err)
+				logging.FromContext(ctx).Error(err, "Error", "caller", runtime.GetCaller())
 				return err
 			}
 			return nil
@@ -559,20 +565,20 @@ func (c *Controller) ensureAPIResourceCompatibility(ctx context.Context, cluster
 			existing, err = c.kcpClusterClient.Cluster(logicalcluster.From(newNegotiatedAPIResource)).ApiresourceV1alpha1().NegotiatedAPIResources().Get(ctx, newNegotiatedAPIResource.Name, metav1.GetOptions{})
 		}
 		if err != nil {
-			klog.Errorf("Error in %s: %v", runtime.GetCaller(), err)
+			logging.FromContext(ctx).Error(err, "Error", "caller", runtime.GetCaller())
 			return err
 		}
 		if len(newNegotiatedAPIResource.Status.Conditions) > 0 {
 			existing.Status = newNegotiatedAPIResource.Status
 			_, err = c.kcpClusterClient.Cluster(logicalcluster.From(existing)).ApiresourceV1alpha1().NegotiatedAPIResources().UpdateStatus(ctx, existing, metav1.UpdateOptions{})
 			if err != nil {
-				klog.Errorf("Error in %s: %v", runtime.GetCaller(), err)
+				logging.FromContext(ctx).Error(err, "Error", "caller", runtime.GetCaller())
 				return err
 			}
 		}
 	} else if updatedNegotiatedSchema {
 		if _, err := c.kcpClusterClient.Cluster(logicalcluster.From(newNegotiatedAPIResource)).ApiresourceV1alpha1().NegotiatedAPIResources().Update(ctx, newNegotiatedAPIResource, metav1.UpdateOptions{}); err != nil {
-			klog.Errorf("Error in %s: %v", runtime.GetCaller(), err)
+			logging.FromContext(ctx).Error(err, "Error", "caller", runtime.GetCaller())
 			return err
 		}
 	}
@@ -589,7 +595,7 @@ func (c *Controller) ensureAPIResourceCompatibility(ctx context.Context, cluster
 func (c *Controller) negotiatedAPIResourceIsOrphan(ctx context.Context, clusterName logicalcluster.Name, gvr metav1.GroupVersionResource) (bool, error) {
 	objs, err := c.apiResourceImportIndexer.ByIndex(clusterNameAndGVRIndexName, GetClusterNameAndGVRIndexKey(clusterName, gvr))
 	if err != nil {
-		klog.Errorf("Error in %s: %v", runtime.GetCaller(), err)
+		logging.FromContext(ctx).Error(err, "Error", "caller", runtime.GetCaller())
 		return false, err
 	}
 
@@ -599,7 +605,7 @@ func (c *Controller) negotiatedAPIResourceIsOrphan(ctx context.Context, clusterN
 
 	objs, err = c.negotiatedApiResourceIndexer.ByIndex(clusterNameAndGVRIndexName, GetClusterNameAndGVRIndexKey(clusterName, gvr))
 	if err != nil {
-		klog.Errorf("Error in %s: %v", runtime.GetCaller(), err)
+		logging.FromContext(ctx).Error(err, "Error", "caller", runtime.GetCaller())
 		return false, err
 	}
 	if len(objs) != 1 {
@@ -620,7 +626,7 @@ func (c *Controller) publishNegotiatedResource(ctx context.Context, clusterName
 
 	negotiatedSchema, err := negotiatedApiResource.Spec.CommonAPIResourceSpec.GetSchema()
 	if err != nil {
-		klog.Errorf("Error in %s: %v", runtime.GetCaller(), err)
+		logging.FromContext(ctx).Error(err, "Error", "caller", runtime.GetCaller())
 		return err
 	}
 
@@ -657,12 +663,12 @@ func (c *Controller) publishNegotiatedResource(ctx context.Context, clusterName
 		},
 	})
 	if err != nil {
-		klog.Errorf("Error in %s: %v", runtime.GetCaller(), err)
+		logging.FromContext(ctx).Error(err, "Error", "caller", runtime.GetCaller())
 		return err
 	}
 	crd, err := c.crdLister.Get(crdKey)
 	if err != nil && !k8serrors.IsNotFound(err) {
-		klog.Errorf("Error in %s: %v", runtime.GetCaller(), err)
+		logging.FromContext(ctx).Error(err, "Error", "caller", runtime.GetCaller())
 		return err
 	}
 
@@ -707,7 +713,7 @@ func (c *Controller) publishNegotiatedResource(ctx context.Context, clusterName
 		}
 
 		if _, err := c.crdClusterClient.Cluster(clusterName).ApiextensionsV1().CustomResourceDefinitions().Create(ctx, cr, metav1.CreateOptions{}); err != nil {
-			klog.Errorf("Error in %s: %v", runtime.GetCaller(), err)
+			logging.FromContext(ctx).Error(err, "Error", "caller", runtime.GetCaller())
 			return err
 		}
 	} else if !c.isManuallyCreatedCRD(ctx, crd) {
@@ -757,7 +763,7 @@ func (c *Controller) publishNegotiatedResource(ctx context.Context, clusterName
 		}
 
 		if _, err := c.crdClusterClient.Cluster(clusterName).ApiextensionsV1().CustomResourceDefinitions().Update(ctx, crd, metav1.UpdateOptions{}); err != nil {
-			klog.Errorf("Error in %s: %v", runtime.GetCaller(), err)
+			logging.FromContext(ctx).Error(err, "Error", "caller", runtime.GetCaller())
 			return err
 		}
 	}
@@ -770,7 +776,7 @@ func (c *Controller) publishNegotiatedResource(ctx context.Context, clusterName
 		Status: metav1.ConditionTrue,
 	})
 	if _, err := c.kcpClusterClient.Cluster(logicalcluster.From(negotiatedApiResource)).ApiresourceV1alpha1().NegotiatedAPIResources().UpdateStatus(ctx, negotiatedApiResource, metav1.UpdateOptions{}); err != nil {
-		klog.Errorf("Error in %s: %v", runtime.GetCaller(), err)
+		logging.FromContext(ctx).Error(err, "Error", "caller", runtime.GetCaller())
 		return err
 	}
 
@@ -783,7 +789,7 @@ func (c *Controller) updateStatusOnRelatedAPIResourceImports(ctx context.Context
 	if publishedCondition != nil {
 		objs, err := c.apiResourceImportIndexer.ByIndex(clusterNameAndGVRIndexName, GetClusterNameAndGVRIndexKey(clusterName, gvr))
 		if err != nil {
-			klog.Errorf("Error in %s: %v", runtime.GetCaller(), err)
+			logging.FromContext(ctx).Error(err, "Error", "caller", runtime.GetCaller())
 			return err
 		}
 		for _, obj := range objs {
@@ -793,7 +799,7 @@ func (c *Controller) updateStatusOnRelatedAPIResourceImports(ctx context.Context
 				Status: publishedCondition.Status,
 			})
 			if _, err := c.kcpClusterClient.Cluster(logicalcluster.From(apiResourceImport)).ApiresourceV1alpha1().APIResourceImports().UpdateStatus(ctx, apiResourceImport, metav1.UpdateOptions{}); err != nil {
-				klog.Errorf("Error in %s: %v", runtime.GetCaller(), err)
+				logging.FromContext(ctx).Error(err, "Error", "caller", runtime.GetCaller())
 				return err
 			}
 		}
@@ -807,7 +813,7 @@ func (c *Controller) cleanupNegotiatedAPIResource(ctx context.Context, clusterNa
 
 	objs, err := c.apiResourceImportIndexer.ByIndex(clusterNameAndGVRIndexName, GetClusterNameAndGVRIndexKey(clusterName, gvr))
 	if err != nil {
-		klog.Errorf("Error in %s: %v", runtime.GetCaller(), err)
+		logging.FromContext(ctx).Error(err, "Error", "caller", runtime.GetCaller())
 		return err
 	}
 	for _, obj := range objs {
@@ -815,7 +821,7 @@ func (c *Controller) cleanupNegotiatedAPIResource(ctx context.Context, clusterNa
 		apiResourceImport.RemoveCondition(apiresourcev1alpha1.Available)
 		apiResourceImport.RemoveCondition(apiresourcev1alpha1.Compatible)
 		if _, err := c.kcpClusterClient.Cluster(logicalcluster.From(apiResourceImport)).ApiresourceV1alpha1().APIResourceImports().UpdateStatus(ctx, apiResourceImport, metav1.UpdateOptions{}); err != nil {
-			klog.Errorf("Error in %s: %v", runtime.GetCaller(), err)
+			logging.FromContext(ctx).Error(err, "Error", "caller", runtime.GetCaller())
 			return err
 		}
 	}
@@ -838,7 +844,7 @@ func (c *Controller) cleanupNegotiatedAPIResource(ctx context.Context, clusterNa
 		},
 	})
 	if err != nil {
-		klog.Errorf("Error in %s: %v", runtime.GetCaller(), err)
+		logging.FromContext(ctx).Error(err, "Error", "caller", runtime.GetCaller())
 		return err
 	}
 	crd, err := c.crdLister.Get(crdKey)
@@ -846,7 +852,7 @@ func (c *Controller) cleanupNegotiatedAPIResource(ctx context.Context, clusterNa
 		return nil
 	}
 	if err != nil {
-		klog.Errorf("Error in %s: %v", runtime.GetCaller(), err)
+		logging.FromContext(ctx).Error(err, "Error", "caller", runtime.GetCaller())
 		return err
 	}
 
@@ -875,7 +881,7 @@ func (c *Controller) cleanupNegotiatedAPIResource(ctx context.Context, clusterNa
 	}
 	if len(cleanedVersions) == 0 {
 		if err := c.crdClusterClient.Cluster(clusterName).ApiextensionsV1().CustomResourceDefinitions().Delete(ctx, crd.Name, metav1.DeleteOptions{}); err != nil {
-			klog.Errorf("Error in %s: %v", runtime.GetCaller(), err)
+			logging.FromContext(ctx).Error(err, "Error", "caller", runtime.GetCaller())
 			return err
 		}
 	} else {
@@ -883,7 +889,7 @@ func (c *Controller) cleanupNegotiatedAPIResource(ctx context.Context, clusterNa
 		crd.Spec.Versions = cleanedVersions
 		crd.OwnerReferences = cleanedOwnerReferences
 		if _, err := c.crdClusterClient.Cluster(clusterName).ApiextensionsV1().CustomResourceDefinitions().Update(ctx, crd, metav1.UpdateOptions{}); err != nil {
-			klog.Errorf("Error in %s: %v", runtime.GetCaller(), err)
+			logging.FromContext(ctx).Error(err, "Error", "caller", runtime.GetCaller())
 			return err
 		}
 	}
@@ -34,14 +34,16 @@ func DefaultOptions() *Options {
 // BindOptions binds the apiresource controller options to the flag set.
 func BindOptions(o *Options, fs *pflag.FlagSet) *Options {
 	fs.BoolVar(&o.AutoPublishAPIs, "auto-publish-apis", o.AutoPublishAPIs, "If true, the APIs imported from physical clusters will be published automatically as CRDs")
+	fs.StringSliceVar(&o.AutoPublishAPIGroups, "auto-publish-api-groups", o.AutoPublishAPIGroups, "API groups of APIs imported from physical clusters which are published automatically as CRDs if --auto-publish-apis is false. Use \"core\" for the core group. APIs of other groups wait for manual approval.")
 	fs.IntVar(&o.NumThreads, "apiresource-controller-threads", o.NumThreads, "Number of threads to use for the cluster controller.")
 	return o
 }
 
 // Options are the options for the cluster controller
 type Options struct {
-	AutoPublishAPIs bool
-	NumThreads      int
+	AutoPublishAPIs      bool
+	AutoPublishAPIGroups []string
+	NumThreads           int
 }
 
 func (o *Options) Validate() error {
@@ -17,8 +17,10 @@ limitations under the License.
 package location
 
 import (
+	"encoding/json"
 	"fmt"
 
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 
@@ -55,3 +57,115 @@ func FilterReady(workloadClusters []*workloadv1alpha1.WorkloadCluster) []*worklo
 	}
 	return ready
 }
+
+// TolerationsFromAnnotations returns the tolerations of the scheduling.kcp.dev/tolerations annotation.
+func TolerationsFromAnnotations(annotations map[string]string) ([]corev1.Toleration, error) {
+	value, found := annotations[schedulingv1alpha1.TolerationsAnnotationKey]
+	if !found || value == "" {
+		return nil, nil
+	}
+	var tolerations []corev1.Toleration
+	if err := json.Unmarshal([]byte(value), &tolerations); err != nil {
+		return nil, fmt.Errorf("failed to decode %s annotation: %w", schedulingv1alpha1.TolerationsAnnotationKey, err)
+	}
+	return tolerations, nil
+}
+
+// UntoleratedTaint returns the first taint of the workload cluster with one of the given effects
+// that is not tolerated, or nil if there is none.
+func UntoleratedTaint(workloadCluster *workloadv1alpha1.WorkloadCluster, tolerations []corev1.Toleration, effects ...corev1.TaintEffect) *corev1.Taint {
+	for i := range workloadCluster.Spec.Taints {
+		taint := &workloadCluster.Spec.Taints[i]
+		if !hasEffect(taint, effects) {
+			continue
+		}
+		tolerated := false
+		for j := range tolerations {
+			if tolerations[j].ToleratesTaint(taint) {
+				tolerated = true
+				break
+			}
+		}
+		if !tolerated {
+			return taint
+		}
+	}
+	return nil
+}
+
+// FilterTolerated returns the workload clusters whose NoSchedule and NoExecute taints are tolerated.
+// Workload clusters with untolerated PreferNoSchedule taints are only returned if there are no others.
+func FilterTolerated(workloadClusters []*workloadv1alpha1.WorkloadCluster, tolerations []corev1.Toleration) []*workloadv1alpha1.WorkloadCluster {
+	var tolerated, preferNot []*workloadv1alpha1.WorkloadCluster
+	for _, wc := range workloadClusters {
+		if UntoleratedTaint(wc, tolerations, corev1.TaintEffectNoSchedule, corev1.TaintEffectNoExecute) != nil {
+			continue
+		}
+		if UntoleratedTaint(wc, tolerations, corev1.TaintEffectPreferNoSchedule) != nil {
+			preferNot = append(preferNot, wc)
+			continue
+		}
+		tolerated = append(tolerated, wc)
+	}
+	if len(tolerated) == 0 {
+		return preferNot
+	}
+	return tolerated
+}
+
+// NamespaceAffinityFromAnnotations returns the label selectors of the namespace affinity and anti-affinity
+// annotations, or nil for those that are not set.
+func NamespaceAffinityFromAnnotations(annotations map[string]string) (affinity, antiAffinity labels.Selector, err error) {
+	if value := annotations[schedulingv1alpha1.NamespaceAffinityAnnotationKey]; value != "" {
+		if affinity, err = labels.Parse(value); err != nil {
+			return nil, nil, fmt.Errorf("failed to parse %s annotation: %w", schedulingv1alpha1.NamespaceAffinityAnnotationKey, err)
+		}
+	}
+	if value := annotations[schedulingv1alpha1.NamespaceAntiAffinityAnnotationKey]; value != "" {
+		if antiAffinity, err = labels.Parse(value); err != nil {
+			return nil, nil, fmt.Errorf("failed to parse %s annotation: %w", schedulingv1alpha1.NamespaceAntiAffinityAnnotationKey, err)
+		}
+	}
+	return affinity, antiAffinity, nil
+}
+
+// NamespaceAffinityScore returns how much a namespace with the given affinity and anti-affinity prefers
+// the workload cluster of another namespace with the given labels: +1 if the other namespace is selected
+// by the affinity, -1 if it is selected by the anti-affinity, 0 if by both or none.
+func NamespaceAffinityScore(affinity, antiAffinity labels.Selector, other map[string]string) int {
+	score := 0
+	if affinity != nil && affinity.Matches(labels.Set(other)) {
+		score++
+	}
+	if antiAffinity != nil && antiAffinity.Matches(labels.Set(other)) {
+		score--
+	}
+	return score
+}
+
+// FilterHighestScore returns the workload clusters with the highest score.
+func FilterHighestScore(workloadClusters []*workloadv1alpha1.WorkloadCluster, score func(wc *workloadv1alpha1.WorkloadCluster) int) []*workloadv1alpha1.WorkloadCluster {
+	var best []*workloadv1alpha1.WorkloadCluster
+	bestScore := 0
+	for _, wc := range workloadClusters {
+		s := score(wc)
+		if len(best) > 0 && s < bestScore {
+			continue
+		}
+		if len(best) > 0 && s > bestScore {
+			best = nil
+		}
+		best = append(best, wc)
+		bestScore = s
+	}
+	return best
+}
+
+func hasEffect(taint *corev1.Taint, effects []corev1.TaintEffect) bool {
+	for _, effect := range effects {
+		if taint.Effect == effect {
+			return true
+		}
+	}
+	return false
+}
@@ -49,6 +49,7 @@ import (
 	apislisters "github.com/kcp-dev/kcp/pkg/client/listers/apis/v1alpha1"
 	schedulinglisters "github.com/kcp-dev/kcp/pkg/client/listers/scheduling/v1alpha1"
 	workloadlisters "github.com/kcp-dev/kcp/pkg/client/listers/workload/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/logging"
 	"github.com/kcp-dev/kcp/third_party/conditions/util/conditions"
 )
 
@@ -206,8 +207,8 @@ func (c *controller) enqueueAPIBinding(obj interface{}) {
 			continue
 		}
 
-		klog.Infof("Mapping APIBinding %s|%s to unscheduled namespace %s", clusterName.String(), bindingName, ns.Name)
 		key := clusters.ToClusterAwareKey(logicalcluster.From(ns), ns.Name)
+		logging.WithQueueKey(logging.NewLogger(controllerName), key).Info("Mapping APIBinding to unscheduled namespace", "apiBinding", bindingName)
 		c.queue.Add(key)
 	}
 }
@@ -219,9 +220,7 @@ func (c *controller) enqueueNamespace(obj interface{}) {
 		runtime.HandleError(err)
 		return
 	}
-	clusterName, name := clusters.SplitClusterAwareKey(key)
-
-	klog.Infof("Queueing Namespace %s|%s", clusterName.String(), name)
+	logging.WithQueueKey(logging.NewLogger(controllerName), key).Info("Queueing Namespace")
 	c.queue.Add(key)
 }
 
@@ -253,6 +252,9 @@ func (c *controller) processNextWorkItem(ctx context.Context) bool {
 	}
 	key := k.(string)
 
+	logger := logging.WithQueueKey(logging.NewLogger(controllerName), key)
+	ctx = logging.NewContext(ctx, logger)
+
 	// No matter what, tell the queue we're done with this key, to unblock
 	// other workers.
 	defer c.queue.Done(key)
@@ -269,7 +271,7 @@ func (c *controller) processNextWorkItem(ctx context.Context) bool {
 func (c *controller) process(ctx context.Context, key string) error {
 	namespace, clusterAwareName, err := cache.SplitMetaNamespaceKey(key)
 	if err != nil {
-		klog.Errorf("invalid key: %q: %v", key, err)
+		logging.FromContext(ctx).Error(err, "Invalid key")
 		return nil
 	}
 	clusterName, name := clusters.SplitClusterAwareKey(clusterAwareName)
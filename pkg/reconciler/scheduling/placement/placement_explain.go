@@ -0,0 +1,359 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package placement
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/kcp-dev/logicalcluster"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	apisv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apis/v1alpha1"
+	schedulingv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/scheduling/v1alpha1"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/workload/v1alpha1"
+	locationreconciler "github.com/kcp-dev/kcp/pkg/reconciler/scheduling/location"
+	conditionsapi "github.com/kcp-dev/kcp/third_party/conditions/apis/conditions/v1alpha1"
+	"github.com/kcp-dev/kcp/third_party/conditions/util/conditions"
+)
+
+// Explanation describes why the placement controller would choose or not choose the
+// Locations and WorkloadClusters of a workspace for a namespace.
+type Explanation struct {
+	// Namespace is the name of the explained namespace.
+	Namespace string
+	// Bindings explains which APIBinding of the workspace is used to find Locations.
+	Bindings []BindingExplanation
+	// NegotiationWorkspace is the workspace of the Locations, or empty if no binding is selected.
+	NegotiationWorkspace logicalcluster.Name
+	// Locations explains the Locations of the negotiation workspace.
+	Locations []LocationExplanation
+	// Scored is true if the WorkloadClusters are scored by the namespace affinity and anti-affinity.
+	Scored bool
+}
+
+// BindingExplanation explains why an APIBinding is or is not used to find Locations.
+type BindingExplanation struct {
+	Name     string
+	Selected bool
+	Reason   string
+}
+
+// LocationExplanation explains why a Location is or is not a candidate.
+type LocationExplanation struct {
+	Name      string
+	Candidate bool
+	Reason    string
+	// Probability is the chance of the Location to be chosen.
+	Probability float64
+	// Score is the highest score of the ready and tolerated WorkloadClusters of the Location.
+	Score int
+	// WorkloadClusters explains the WorkloadClusters of the negotiation workspace. They are
+	// only set if the Location's instance selector is valid.
+	WorkloadClusters []WorkloadClusterExplanation
+}
+
+// WorkloadClusterExplanation explains why a WorkloadCluster is or is not a candidate of a Location.
+type WorkloadClusterExplanation struct {
+	Name      string
+	Candidate bool
+	Reason    string
+	// Probability is the chance of the WorkloadCluster to be chosen through this Location.
+	Probability float64
+	// Score is the number of namespaces selected by the namespace affinity minus those selected by the
+	// namespace anti-affinity that are placed on the WorkloadCluster.
+	Score int
+	// Placed is true if the namespace is currently placed on the WorkloadCluster through this Location.
+	Placed bool
+}
+
+// Explain explains the placement of the given namespace in the given workspace, following the
+// steps of the placement controller: the workload APIBindings are filtered and the first by name is
+// selected; then the ready, schedulable and tolerated WorkloadClusters of the Locations of its
+// negotiation workspace are scored by the namespace affinity and anti-affinity of the namespace.
+// The candidates are the Locations with the highest scored WorkloadCluster, and within them the
+// WorkloadClusters with that score. The controller chooses among them at random.
+func Explain(
+	clusterName logicalcluster.Name,
+	ns *corev1.Namespace,
+	bindings []*apisv1alpha1.APIBinding,
+	listLocations func(clusterName logicalcluster.Name) ([]*schedulingv1alpha1.Location, error),
+	listWorkloadClusters func(clusterName logicalcluster.Name) ([]*workloadv1alpha1.WorkloadCluster, error),
+	listNamespaces func(clusterName logicalcluster.Name) ([]*corev1.Namespace, error),
+) (*Explanation, error) {
+	ret := &Explanation{Namespace: ns.Name}
+
+	if _, found := clusterName.Parent(); !found {
+		return ret, nil
+	}
+
+	sort.Slice(bindings, func(i, j int) bool {
+		return bindings[i].Name < bindings[j].Name
+	})
+	var locations []*schedulingv1alpha1.Location
+	var selected string
+	for _, binding := range bindings {
+		be := BindingExplanation{Name: binding.Name}
+		if reason := workloadBindingCandidate(binding); reason != "" {
+			be.Reason = reason
+		} else if selected != "" {
+			be.Reason = fmt.Sprintf("APIBinding %q is selected first by name", selected)
+		} else {
+			negotiationClusterName, _ := binding.Spec.Reference.Workspace.ClusterName(clusterName)
+			ls, err := listLocations(negotiationClusterName)
+			if err != nil {
+				return nil, fmt.Errorf("failed to list Locations in %s: %w", negotiationClusterName, err)
+			}
+			if len(ls) == 0 {
+				be.Reason = fmt.Sprintf("no Locations in workspace %s", negotiationClusterName)
+			} else {
+				be.Selected = true
+				be.Reason = fmt.Sprintf("%d Locations in workspace %s", len(ls), negotiationClusterName)
+				ret.NegotiationWorkspace = negotiationClusterName
+				selected = binding.Name
+				locations = ls
+			}
+		}
+		ret.Bindings = append(ret.Bindings, be)
+	}
+	// selected binding first
+	sort.SliceStable(ret.Bindings, func(i, j int) bool {
+		return ret.Bindings[i].Selected && !ret.Bindings[j].Selected
+	})
+	if ret.NegotiationWorkspace.Empty() {
+		return ret, nil
+	}
+
+	workloadClusters, err := listWorkloadClusters(ret.NegotiationWorkspace)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list WorkloadClusters in %s: %w", ret.NegotiationWorkspace, err)
+	}
+	sort.Slice(workloadClusters, func(i, j int) bool {
+		return workloadClusters[i].Name < workloadClusters[j].Name
+	})
+
+	tolerations, tolerationsErr := locationreconciler.TolerationsFromAnnotations(ns.Annotations)
+	placed := placedKeys(ns)
+
+	scores := map[types.UID]int{}
+	affinity, antiAffinity, affinityErr := locationreconciler.NamespaceAffinityFromAnnotations(ns.Annotations)
+	if affinityErr == nil && (affinity != nil || antiAffinity != nil) {
+		namespaces, err := listNamespaces(clusterName)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list Namespaces in %s: %w", clusterName, err)
+		}
+		scores = scoreWorkloadClusters(ns, namespaces, affinity, antiAffinity)
+		ret.Scored = true
+	}
+	score := func(wc *workloadv1alpha1.WorkloadCluster) int { return scores[wc.UID] }
+
+	sort.Slice(locations, func(i, j int) bool {
+		return locations[i].Name < locations[j].Name
+	})
+	candidateLocations := 0
+	for _, l := range locations {
+		le := LocationExplanation{Name: l.Name}
+		locationClusters, err := locationreconciler.LocationWorkloadClusters(workloadClusters, l)
+		if err != nil {
+			le.Reason = err.Error()
+			ret.Locations = append(ret.Locations, le)
+			continue
+		}
+		matching := sets.NewString()
+		for _, wc := range locationClusters {
+			matching.Insert(wc.Name)
+		}
+		ready := locationreconciler.FilterTolerated(locationreconciler.FilterReady(locationClusters), tolerations)
+		tolerated := sets.NewString()
+		for _, wc := range ready {
+			tolerated.Insert(wc.Name)
+		}
+		candidates := sets.NewString()
+		for _, wc := range locationreconciler.FilterHighestScore(ready, score) {
+			candidates.Insert(wc.Name)
+			le.Score = score(wc)
+		}
+
+		for _, wc := range workloadClusters {
+			we := WorkloadClusterExplanation{
+				Name:   wc.Name,
+				Placed: placed.Has(fmt.Sprintf("%s+%s", l.Name, wc.UID)),
+				Score:  score(wc),
+			}
+			switch {
+			case !matching.Has(wc.Name):
+				we.Reason = "does not match the instance selector"
+			case !conditions.IsTrue(wc, conditionsapi.ReadyCondition):
+				we.Reason = "not ready"
+			case wc.Spec.Unschedulable:
+				we.Reason = "unschedulable"
+			default:
+				if taint := locationreconciler.UntoleratedTaint(wc, tolerations, corev1.TaintEffectNoSchedule, corev1.TaintEffectNoExecute); taint != nil {
+					we.Reason = fmt.Sprintf("taint %s not tolerated", taint.ToString())
+				} else if taint := locationreconciler.UntoleratedTaint(wc, tolerations, corev1.TaintEffectPreferNoSchedule); taint != nil && !tolerated.Has(wc.Name) {
+					we.Reason = fmt.Sprintf("taint %s not tolerated, other WorkloadClusters are preferred", taint.ToString())
+				} else if taint != nil {
+					we.Candidate = true
+					we.Reason = fmt.Sprintf("taint %s not tolerated, but no other WorkloadCluster is preferred", taint.ToString())
+				} else {
+					we.Candidate = true
+					we.Reason = "ready and tolerated"
+				}
+				if we.Candidate && !candidates.Has(wc.Name) {
+					we.Candidate = false
+					we.Reason += fmt.Sprintf(", but scores %d, other WorkloadClusters score %d", we.Score, le.Score)
+				}
+			}
+			if we.Candidate {
+				we.Probability = 1 / float64(candidates.Len())
+			}
+			le.WorkloadClusters = append(le.WorkloadClusters, we)
+		}
+
+		if candidates.Len() > 0 {
+			le.Candidate = true
+			le.Reason = fmt.Sprintf("%d of %d matching WorkloadClusters are candidates", candidates.Len(), matching.Len())
+			candidateLocations++
+		} else if matching.Len() == 0 {
+			le.Reason = "no WorkloadCluster matches the instance selector"
+		} else {
+			le.Reason = fmt.Sprintf("none of %d matching WorkloadClusters is ready and tolerated", matching.Len())
+		}
+		if tolerationsErr != nil {
+			le.Reason += fmt.Sprintf(", tolerations ignored: %v", tolerationsErr)
+		}
+		if affinityErr != nil {
+			le.Reason += fmt.Sprintf(", namespace affinity ignored: %v", affinityErr)
+		}
+		ret.Locations = append(ret.Locations, le)
+	}
+
+	// only the Locations with the highest scored WorkloadCluster are chosen
+	bestScore, scored := 0, false
+	for _, le := range ret.Locations {
+		if le.Candidate && (!scored || le.Score > bestScore) {
+			bestScore, scored = le.Score, true
+		}
+	}
+	for i := range ret.Locations {
+		le := &ret.Locations[i]
+		if le.Candidate && le.Score < bestScore {
+			le.Candidate = false
+			le.Reason += fmt.Sprintf(", but its WorkloadClusters score at most %d, other Locations score %d", le.Score, bestScore)
+			candidateLocations--
+			for j := range le.WorkloadClusters {
+				le.WorkloadClusters[j].Probability = 0
+			}
+		}
+	}
+
+	for i := range ret.Locations {
+		le := &ret.Locations[i]
+		if !le.Candidate {
+			continue
+		}
+		le.Probability = 1 / float64(candidateLocations)
+		for j := range le.WorkloadClusters {
+			le.WorkloadClusters[j].Probability *= le.Probability
+		}
+	}
+
+	return ret, nil
+}
+
+// workloadBindingCandidate returns why the binding cannot be used to find Locations, or an empty
+// string if it can.
+func workloadBindingCandidate(binding *apisv1alpha1.APIBinding) string {
+	if !conditions.IsTrue(binding, apisv1alpha1.InitialBindingCompleted) {
+		return fmt.Sprintf("condition %s is not true", apisv1alpha1.InitialBindingCompleted)
+	}
+	if !conditions.IsTrue(binding, apisv1alpha1.APIExportValid) {
+		return fmt.Sprintf("condition %s is not true", apisv1alpha1.APIExportValid)
+	}
+	if binding.Spec.Reference.Workspace == nil {
+		return "no workspace reference"
+	}
+	return ""
+}
+
+func placedKeys(ns *corev1.Namespace) sets.String {
+	ret := sets.NewString()
+	value, found := ns.Annotations[schedulingv1alpha1.PlacementAnnotationKey]
+	if !found {
+		return ret
+	}
+	var placement schedulingv1alpha1.PlacementAnnotation
+	if err := json.Unmarshal([]byte(value), &placement); err != nil {
+		return ret
+	}
+	for key, state := range placement {
+		if state != schedulingv1alpha1.PlacementStateUnbound && state != schedulingv1alpha1.PlacementStateRemoving {
+			ret.Insert(key)
+		}
+	}
+	return ret
+}
+
+// String renders the explanation in a human readable form.
+func (e *Explanation) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Namespace %q:\n", e.Namespace)
+	if len(e.Bindings) == 0 {
+		fmt.Fprintln(&b, "  no APIBindings, the namespace is not placed")
+		return b.String()
+	}
+	fmt.Fprintln(&b, "  APIBindings:")
+	for _, be := range e.Bindings {
+		fmt.Fprintf(&b, "    %s %s: %s\n", mark(be.Selected), be.Name, be.Reason)
+	}
+	if e.NegotiationWorkspace.Empty() {
+		fmt.Fprintln(&b, "  no APIBinding with Locations, the namespace is not placed")
+		return b.String()
+	}
+	fmt.Fprintf(&b, "  Locations in %s:\n", e.NegotiationWorkspace)
+	for _, le := range e.Locations {
+		fmt.Fprintf(&b, "    %s %s (%.0f%%%s): %s\n", mark(le.Candidate), le.Name, le.Probability*100, e.score(le.Score), le.Reason)
+		for _, we := range le.WorkloadClusters {
+			current := ""
+			if we.Placed {
+				current = ", current placement"
+			}
+			fmt.Fprintf(&b, "      %s %s (%.0f%%%s): %s%s\n", mark(we.Candidate), we.Name, we.Probability*100, e.score(we.Score), we.Reason, current)
+		}
+	}
+	return b.String()
+}
+
+// score renders the given score if the WorkloadClusters are scored.
+func (e *Explanation) score(score int) string {
+	if !e.Scored {
+		return ""
+	}
+	return fmt.Sprintf(", score %d", score)
+}
+
+func mark(candidate bool) string {
+	if candidate {
+		return "+"
+	}
+	return "-"
+}
@@ -0,0 +1,176 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package placement
+
+import (
+	"testing"
+
+	"github.com/kcp-dev/logicalcluster"
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	apisv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apis/v1alpha1"
+	schedulingv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/scheduling/v1alpha1"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/workload/v1alpha1"
+	conditionsv1alpha1 "github.com/kcp-dev/kcp/third_party/conditions/apis/conditions/v1alpha1"
+)
+
+func TestExplain(t *testing.T) {
+	ready := conditionsv1alpha1.Condition{Type: conditionsv1alpha1.ReadyCondition, Status: corev1.ConditionTrue}
+	negotiationClusterName := logicalcluster.New("root:org:negotiation-workspace")
+
+	ns := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "test",
+			Annotations: map[string]string{
+				schedulingv1alpha1.PlacementAnnotationKey: `{"us-east1+uid-1":"Bound"}`,
+			},
+		},
+	}
+	bindings := []*apisv1alpha1.APIBinding{
+		bound(validExport(binding("kubernetes", "negotiation-workspace"))),
+		bound(binding("invalid", "negotiation-workspace")),
+		bound(validExport(binding("other", "negotiation-workspace"))),
+	}
+	locations := []*schedulingv1alpha1.Location{
+		withInstances(location("us-west1"), map[string]string{"region": "us-west1"}),
+		withInstances(location("us-east1"), map[string]string{"region": "us-east1"}),
+		withInstances(location("eu-central1"), map[string]string{"region": "eu-central1"}),
+	}
+	workloadClusters := []*workloadv1alpha1.WorkloadCluster{
+		withConditions(withLabels(cluster("cluster1", "uid-1"), map[string]string{"region": "us-east1"}), ready),
+		withConditions(withLabels(cluster("cluster2", "uid-2"), map[string]string{"region": "us-east1"}), ready),
+		tainted(withConditions(withLabels(cluster("cluster3", "uid-3"), map[string]string{"region": "us-east1"}), ready), corev1.TaintEffectNoSchedule),
+		withLabels(cluster("cluster4", "uid-4"), map[string]string{"region": "us-west1"}),
+		tainted(withConditions(withLabels(cluster("cluster5", "uid-5"), map[string]string{"region": "eu-central1"}), ready), corev1.TaintEffectPreferNoSchedule),
+	}
+
+	e, err := Explain(logicalcluster.New("root:org:ws"), ns, bindings,
+		func(clusterName logicalcluster.Name) ([]*schedulingv1alpha1.Location, error) {
+			require.Equal(t, negotiationClusterName, clusterName)
+			return locations, nil
+		},
+		func(clusterName logicalcluster.Name) ([]*workloadv1alpha1.WorkloadCluster, error) {
+			require.Equal(t, negotiationClusterName, clusterName)
+			return workloadClusters, nil
+		},
+		func(clusterName logicalcluster.Name) ([]*corev1.Namespace, error) {
+			t.Fatal("namespaces must only be listed with namespace affinity")
+			return nil, nil
+		},
+	)
+	require.NoError(t, err)
+
+	require.False(t, e.Scored)
+	require.Equal(t, negotiationClusterName, e.NegotiationWorkspace)
+	require.Equal(t, []BindingExplanation{
+		{Name: "kubernetes", Selected: true, Reason: "3 Locations in workspace root:org:negotiation-workspace"},
+		{Name: "invalid", Reason: "condition APIExportValid is not true"},
+		{Name: "other", Reason: `APIBinding "kubernetes" is selected first by name`},
+	}, e.Bindings)
+
+	require.Len(t, e.Locations, 3)
+	eu, east, west := e.Locations[0], e.Locations[1], e.Locations[2]
+
+	require.True(t, eu.Candidate)
+	require.Equal(t, 0.5, eu.Probability)
+	require.Equal(t, WorkloadClusterExplanation{Name: "cluster5", Candidate: true, Probability: 0.5, Reason: "taint gpu:PreferNoSchedule not tolerated, but no other WorkloadCluster is preferred"}, eu.WorkloadClusters[4])
+
+	require.True(t, east.Candidate)
+	require.Equal(t, 0.5, east.Probability)
+	require.Equal(t, "2 of 3 matching WorkloadClusters are candidates", east.Reason)
+	require.Equal(t, WorkloadClusterExplanation{Name: "cluster1", Candidate: true, Probability: 0.25, Reason: "ready and tolerated", Placed: true}, east.WorkloadClusters[0])
+	require.Equal(t, WorkloadClusterExplanation{Name: "cluster2", Candidate: true, Probability: 0.25, Reason: "ready and tolerated"}, east.WorkloadClusters[1])
+	require.Equal(t, WorkloadClusterExplanation{Name: "cluster3", Reason: "taint gpu:NoSchedule not tolerated"}, east.WorkloadClusters[2])
+	require.Equal(t, WorkloadClusterExplanation{Name: "cluster4", Reason: "does not match the instance selector"}, east.WorkloadClusters[3])
+
+	require.False(t, west.Candidate)
+	require.Equal(t, "none of 1 matching WorkloadClusters is ready and tolerated", west.Reason)
+	require.Equal(t, WorkloadClusterExplanation{Name: "cluster4", Reason: "not ready"}, west.WorkloadClusters[3])
+
+	require.Contains(t, e.String(), "+ cluster1 (25%): ready and tolerated, current placement")
+}
+
+func TestExplainScores(t *testing.T) {
+	ready := conditionsv1alpha1.Condition{Type: conditionsv1alpha1.ReadyCondition, Status: corev1.ConditionTrue}
+
+	ns := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "frontend",
+			Annotations: map[string]string{
+				schedulingv1alpha1.NamespaceAffinityAnnotationKey:     "app=backend",
+				schedulingv1alpha1.NamespaceAntiAffinityAnnotationKey: "stage=staging",
+			},
+		},
+	}
+	namespaces := []*corev1.Namespace{
+		ns,
+		{ObjectMeta: metav1.ObjectMeta{Name: "backend", Labels: map[string]string{"app": "backend"}, Annotations: map[string]string{
+			schedulingv1alpha1.PlacementAnnotationKey: `{"us-east1+uid-2":"Bound"}`,
+		}}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "staging", Labels: map[string]string{"stage": "staging"}, Annotations: map[string]string{
+			schedulingv1alpha1.PlacementAnnotationKey: `{"us-west1+uid-3":"Bound"}`,
+		}}},
+	}
+	bindings := []*apisv1alpha1.APIBinding{
+		bound(validExport(binding("kubernetes", "negotiation-workspace"))),
+	}
+	locations := []*schedulingv1alpha1.Location{
+		withInstances(location("us-west1"), map[string]string{"region": "us-west1"}),
+		withInstances(location("us-east1"), map[string]string{"region": "us-east1"}),
+	}
+	workloadClusters := []*workloadv1alpha1.WorkloadCluster{
+		withConditions(withLabels(cluster("cluster1", "uid-1"), map[string]string{"region": "us-east1"}), ready),
+		withConditions(withLabels(cluster("cluster2", "uid-2"), map[string]string{"region": "us-east1"}), ready),
+		withConditions(withLabels(cluster("cluster3", "uid-3"), map[string]string{"region": "us-west1"}), ready),
+	}
+
+	e, err := Explain(logicalcluster.New("root:org:ws"), ns, bindings,
+		func(clusterName logicalcluster.Name) ([]*schedulingv1alpha1.Location, error) {
+			return locations, nil
+		},
+		func(clusterName logicalcluster.Name) ([]*workloadv1alpha1.WorkloadCluster, error) {
+			return workloadClusters, nil
+		},
+		func(clusterName logicalcluster.Name) ([]*corev1.Namespace, error) {
+			require.Equal(t, logicalcluster.New("root:org:ws"), clusterName)
+			return namespaces, nil
+		},
+	)
+	require.NoError(t, err)
+
+	require.True(t, e.Scored)
+	require.Len(t, e.Locations, 2)
+	east, west := e.Locations[0], e.Locations[1]
+
+	require.True(t, east.Candidate)
+	require.Equal(t, 1.0, east.Probability)
+	require.Equal(t, 1, east.Score)
+	require.Equal(t, "1 of 2 matching WorkloadClusters are candidates", east.Reason)
+	require.Equal(t, WorkloadClusterExplanation{Name: "cluster1", Reason: "ready and tolerated, but scores 0, other WorkloadClusters score 1"}, east.WorkloadClusters[0])
+	require.Equal(t, WorkloadClusterExplanation{Name: "cluster2", Candidate: true, Probability: 1, Score: 1, Reason: "ready and tolerated"}, east.WorkloadClusters[1])
+
+	require.False(t, west.Candidate)
+	require.Equal(t, 0.0, west.Probability)
+	require.Equal(t, -1, west.Score)
+	require.Equal(t, "1 of 1 matching WorkloadClusters are candidates, but its WorkloadClusters score at most -1, other Locations score 1", west.Reason)
+	require.Equal(t, WorkloadClusterExplanation{Name: "cluster3", Candidate: true, Score: -1, Reason: "ready and tolerated"}, west.WorkloadClusters[2])
+
+	require.Contains(t, e.String(), "+ cluster2 (100%, score 1): ready and tolerated")
+}
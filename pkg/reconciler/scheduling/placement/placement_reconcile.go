@@ -22,22 +22,23 @@ import (
 	"fmt"
 	"math/rand"
 	"sort"
+	"strings"
 	"time"
 
 	"github.com/kcp-dev/logicalcluster"
 
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
 	utilserrors "k8s.io/apimachinery/pkg/util/errors"
-	"k8s.io/klog/v2"
 
 	apisv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apis/v1alpha1"
 	schedulingv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/scheduling/v1alpha1"
 	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
 	workloadv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/workload/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/logging"
 	locationreconciler "github.com/kcp-dev/kcp/pkg/reconciler/scheduling/location"
-	"github.com/kcp-dev/kcp/third_party/conditions/util/conditions"
 )
 
 type reconcileStatus int
@@ -57,15 +58,16 @@ type placementReconciler struct {
 	listAPIBindings      func(clusterName logicalcluster.Name) ([]*apisv1alpha1.APIBinding, error)
 	listLocations        func(clusterName logicalcluster.Name) ([]*schedulingv1alpha1.Location, error)
 	listWorkloadClusters func(clusterName logicalcluster.Name) ([]*workloadv1alpha1.WorkloadCluster, error)
+	listNamespaces       func(clusterName logicalcluster.Name) ([]*corev1.Namespace, error)
 	patchNamespace       func(ctx context.Context, clusterName logicalcluster.Name, name string, pt types.PatchType, data []byte, opts metav1.PatchOptions, subresources ...string) (*corev1.Namespace, error)
 
 	enqueueAfter func(logicalcluster.Name, *corev1.Namespace, time.Duration)
 }
 
 func (r *placementReconciler) reconcile(ctx context.Context, ns *corev1.Namespace) (reconcileStatus, error) {
+	logger := logging.FromContext(ctx)
 	clusterName := logicalcluster.From(ns)
-	orgClusterName, found := clusterName.Parent()
-	if !found || !clusterName.HasPrefix(tenancyv1alpha1.RootCluster) {
+	if _, found := clusterName.Parent(); !found || !clusterName.HasPrefix(tenancyv1alpha1.RootCluster) {
 		// ignore the root and every non-workspace.
 		return reconcileStatusContinue, nil
 	}
@@ -76,7 +78,7 @@ func (r *placementReconciler) reconcile(ctx context.Context, ns *corev1.Namespac
 		return reconcileStatusStop, err
 	}
 	deletePlacementAnnotation := func() (reconcileStatus, error) {
-		klog.V(4).Infof("Removing placement from namespace %s|%s, no api bindings", ns.Name)
+		logger.V(4).Info("Removing placement from namespace, no api bindings")
 		delete(ns.Annotations, schedulingv1alpha1.PlacementAnnotationKey)
 		if _, err := r.patchNamespace(ctx, clusterName, ns.Name, types.MergePatchType, []byte(fmt.Sprintf(`{"metadata":{"annotations":{%q:null}}}}`, schedulingv1alpha1.PlacementAnnotationKey)), metav1.PatchOptions{}); err != nil {
 			return reconcileStatusStop, err
@@ -95,13 +97,10 @@ func (r *placementReconciler) reconcile(ctx context.Context, ns *corev1.Namespac
 	var workloadBindings []*apisv1alpha1.APIBinding
 	locationsByWorkspace := map[logicalcluster.Name][]*schedulingv1alpha1.Location{}
 	for _, binding := range bindings {
-		if !conditions.IsTrue(binding, apisv1alpha1.InitialBindingCompleted) || !conditions.IsTrue(binding, apisv1alpha1.APIExportValid) {
+		if workloadBindingCandidate(binding) != "" {
 			continue
 		}
-		if binding.Spec.Reference.Workspace == nil {
-			continue
-		}
-		negotationClusterName := orgClusterName.Join(binding.Spec.Reference.Workspace.WorkspaceName)
+		negotationClusterName, _ := binding.Spec.Reference.Workspace.ClusterName(clusterName)
 		if locations, err := r.listLocations(negotationClusterName); err != nil {
 			errs = append(errs, err)
 		} else if len(locations) > 0 {
@@ -125,15 +124,36 @@ func (r *placementReconciler) reconcile(ctx context.Context, ns *corev1.Namespac
 		return workloadBindings[i].Name < workloadBindings[j].Name
 	})
 	binding := workloadBindings[0]
-	negotiationClusterName := orgClusterName.Join(binding.Spec.Reference.Workspace.WorkspaceName)
+	negotiationClusterName, _ := binding.Spec.Reference.Workspace.ClusterName(clusterName)
 	locations := locationsByWorkspace[negotiationClusterName]
 
 	workloadClusters, err := r.listWorkloadClusters(negotiationClusterName)
 	if err != nil {
-		klog.Errorf("failed to list WorkloadClusters in %s for APIBinding %s|%s: %v", negotiationClusterName, clusterName, binding.Name, err)
+		logger.Error(err, "Failed to list WorkloadClusters for APIBinding", "negotiationWorkspace", negotiationClusterName.String(), "apiBinding", binding.Name)
 		return reconcileStatusStop, err
 	}
 
+	tolerations, err := locationreconciler.TolerationsFromAnnotations(ns.Annotations)
+	if err != nil {
+		// only schedule to untainted clusters
+		logger.Error(err, "Invalid tolerations of Namespace")
+	}
+
+	scores := map[types.UID]int{}
+	affinity, antiAffinity, err := locationreconciler.NamespaceAffinityFromAnnotations(ns.Annotations)
+	if err != nil {
+		// schedule without preferences
+		logger.Error(err, "Invalid namespace affinity of Namespace")
+	} else if affinity != nil || antiAffinity != nil {
+		namespaces, err := r.listNamespaces(clusterName)
+		if err != nil {
+			return reconcileStatusStop, err
+		}
+		scores = scoreWorkloadClusters(ns, namespaces, affinity, antiAffinity)
+	}
+	score := func(wc *workloadv1alpha1.WorkloadCluster) int { return scores[wc.UID] }
+
+	// choose a Location with a highest scored candidate WorkloadCluster, randomly among equally scored ones.
 	perm := rand.Perm(len(locationsByWorkspace[negotiationClusterName]))
 	var lastErr error
 	var chosenClusters []*workloadv1alpha1.WorkloadCluster
@@ -145,21 +165,25 @@ func (r *placementReconciler) reconcile(ctx context.Context, ns *corev1.Namespac
 			lastErr = fmt.Errorf("failed to get location %s|%s WorkloadClusters: %w", negotiationClusterName, l.Name, err)
 			continue // try another one
 		}
-		ready := locationreconciler.FilterReady(locationClusters)
-		if len(ready) == 0 {
+		ready := locationreconciler.FilterTolerated(locationreconciler.FilterReady(locationClusters), tolerations)
+		best := locationreconciler.FilterHighestScore(ready, score)
+		if len(best) == 0 {
+			continue
+		}
+		if chosenLocationName != "" && score(best[0]) <= score(chosenClusters[0]) {
 			continue
 		}
 		chosenLocationName = l.Name
-		chosenClusters = ready
+		chosenClusters = best
 	}
 	if chosenLocationName == "" {
 		// TODO(sttts): come up with some both quicker rescheduling initially, but also some backoff when scheduling fails again
-		klog.V(2).Infof("Requeuing after 30s, failed to schedule Namespace %s|%s against locations in %s. No ready clusters: %v", clusterName, ns.Name, negotiationClusterName, lastErr)
+		logger.V(2).Info("Requeuing after 30s, failed to schedule Namespace against locations. No ready clusters tolerated by the namespace", "negotiationWorkspace", negotiationClusterName.String(), "lastError", lastErr)
 		r.enqueueAfter(clusterName, ns, time.Second*30)
 		return reconcileStatusContinue, nil
 	}
 
-	// TODO(sttts): be more clever than just random: follow allocable, co-location workspace and workloads, load-balance, etcd.
+	// TODO(sttts): be more clever than just random: follow allocable, co-location workspace, load-balance, etcd.
 	chosenCluster := chosenClusters[rand.Intn(len(chosenClusters))]
 
 	placementUID := fmt.Sprintf("%s+%s", chosenLocationName, chosenCluster.UID)
@@ -170,7 +194,7 @@ func (r *placementReconciler) reconcile(ctx context.Context, ns *corev1.Namespac
 	// patch Namespace
 	bs, err := json.Marshal(newPlacement)
 	if err != nil {
-		klog.Errorf("failed to marshal placement %v: %v", placementValue, err)
+		logger.Error(err, "Failed to marshal placement", "placement", placementValue)
 		return reconcileStatusStop, err
 	}
 	annotations := map[string]string{
@@ -178,7 +202,7 @@ func (r *placementReconciler) reconcile(ctx context.Context, ns *corev1.Namespac
 	}
 	bs, err = json.Marshal(annotations)
 	if err != nil {
-		klog.Errorf("failed to marshal placement %v: %v", placementValue, err)
+		logger.Error(err, "Failed to marshal placement", "placement", placementValue)
 		return reconcileStatusStop, err
 	}
 
@@ -190,12 +214,36 @@ func (r *placementReconciler) reconcile(ctx context.Context, ns *corev1.Namespac
 	return reconcileStatusContinue, nil
 }
 
+// scoreWorkloadClusters scores the WorkloadClusters, by UID, the given namespaces of the workspace of ns
+// are placed on: +1 for every namespace selected by the affinity, -1 for every namespace selected by the
+// anti-affinity. The namespace ns itself is never counted.
+func scoreWorkloadClusters(ns *corev1.Namespace, namespaces []*corev1.Namespace, affinity, antiAffinity labels.Selector) map[types.UID]int {
+	scores := map[types.UID]int{}
+	for _, other := range namespaces {
+		if other.Name == ns.Name {
+			continue
+		}
+		score := locationreconciler.NamespaceAffinityScore(affinity, antiAffinity, other.Labels)
+		if score == 0 {
+			continue
+		}
+		for key := range placedKeys(other) {
+			// placement keys are <location>+<WorkloadCluster UID>
+			if i := strings.LastIndex(key, "+"); i >= 0 {
+				scores[types.UID(key[i+1:])] += score
+			}
+		}
+	}
+	return scores
+}
+
 func (c *controller) reconcile(ctx context.Context, ns *corev1.Namespace) error {
 	reconcilers := []reconciler{
 		&placementReconciler{
 			listAPIBindings:      c.listAPIBindings,
 			listLocations:        c.listLocations,
 			listWorkloadClusters: c.listWorkloadClusters,
+			listNamespaces:       c.listNamespaces,
 			patchNamespace:       c.patchNamespace,
 			enqueueAfter:         c.enqueueAfter,
 		},
@@ -252,6 +300,18 @@ func (c *controller) listWorkloadClusters(clusterName logicalcluster.Name) ([]*w
 	return ret, nil
 }
 
+func (c *controller) listNamespaces(clusterName logicalcluster.Name) ([]*corev1.Namespace, error) {
+	items, err := c.namespaceIndexer.ByIndex(byWorkspace, clusterName.String())
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]*corev1.Namespace, 0, len(items))
+	for _, item := range items {
+		ret = append(ret, item.(*corev1.Namespace))
+	}
+	return ret, nil
+}
+
 func (c *controller) patchNamespace(ctx context.Context, clusterName logicalcluster.Name, name string, pt types.PatchType, data []byte, opts metav1.PatchOptions, subresources ...string) (*corev1.Namespace, error) {
 	return c.kubeClusterClient.Cluster(clusterName).CoreV1().Namespaces().Patch(ctx, name, pt, data, opts, subresources...)
 }
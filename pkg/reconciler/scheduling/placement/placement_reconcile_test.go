@@ -41,6 +41,7 @@ func TestPlacementReconciler(t *testing.T) {
 		apibindings      map[logicalcluster.Name][]*apisv1alpha1.APIBinding
 		locations        map[logicalcluster.Name][]*schedulingv1alpha1.Location
 		workloadClusters map[logicalcluster.Name][]*workloadv1alpha1.WorkloadCluster
+		namespaces       []*corev1.Namespace
 		namespace        *corev1.Namespace
 
 		listLocationsError        error
@@ -170,6 +171,111 @@ func TestPlacementReconciler(t *testing.T) {
 			wantPatch:           `{"metadata":{"annotations":{"scheduling.kcp.dev/placement":"{\"us-east1+uid-3\":\"Pending\"}"}}}`,
 			wantReconcileStatus: reconcileStatusContinue,
 		},
+		"tainted workload cluster is skipped without toleration": {
+			namespace: &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "test",
+					ClusterName: "root:org:ws",
+				},
+			},
+			apibindings: map[logicalcluster.Name][]*apisv1alpha1.APIBinding{logicalcluster.New("root:org:ws"): {
+				bound(validExport(binding("kubernetes", "negotiation-workspace"))),
+			}},
+			locations: map[logicalcluster.Name][]*schedulingv1alpha1.Location{logicalcluster.New("root:org:negotiation-workspace"): {
+				withInstances(location("us-east1"), map[string]string{"region": "us-east1"}),
+			}},
+			workloadClusters: map[logicalcluster.Name][]*workloadv1alpha1.WorkloadCluster{
+				logicalcluster.New("root:org:negotiation-workspace"): {
+					withLabels(tainted(withConditions(cluster("us-east1-gpu", "uid-1"), conditionsv1alpha1.Condition{Type: "Ready", Status: "True"}), corev1.TaintEffectNoSchedule), map[string]string{"region": "us-east1"}),
+				},
+			},
+			wantRequeue:         time.Second * 30,
+			wantReconcileStatus: reconcileStatusContinue,
+		},
+		"tainted workload cluster is chosen with toleration": {
+			namespace: &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "test",
+					ClusterName: "root:org:ws",
+					Annotations: map[string]string{
+						"scheduling.kcp.dev/tolerations": `[{"key":"gpu","operator":"Exists","effect":"NoSchedule"}]`,
+					},
+				},
+			},
+			apibindings: map[logicalcluster.Name][]*apisv1alpha1.APIBinding{logicalcluster.New("root:org:ws"): {
+				bound(validExport(binding("kubernetes", "negotiation-workspace"))),
+			}},
+			locations: map[logicalcluster.Name][]*schedulingv1alpha1.Location{logicalcluster.New("root:org:negotiation-workspace"): {
+				withInstances(location("us-east1"), map[string]string{"region": "us-east1"}),
+			}},
+			workloadClusters: map[logicalcluster.Name][]*workloadv1alpha1.WorkloadCluster{
+				logicalcluster.New("root:org:negotiation-workspace"): {
+					withLabels(tainted(withConditions(cluster("us-east1-gpu", "uid-1"), conditionsv1alpha1.Condition{Type: "Ready", Status: "True"}), corev1.TaintEffectNoSchedule), map[string]string{"region": "us-east1"}),
+				},
+			},
+			wantPatch:           `{"metadata":{"annotations":{"scheduling.kcp.dev/placement":"{\"us-east1+uid-1\":\"Pending\"}"}}}`,
+			wantReconcileStatus: reconcileStatusContinue,
+		},
+		"namespace affinity chooses the workload cluster of the selected namespace, across locations": {
+			namespace: &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "frontend",
+					ClusterName: "root:org:ws",
+					Annotations: map[string]string{
+						"scheduling.kcp.dev/namespace-affinity": "app=backend",
+					},
+				},
+			},
+			namespaces: []*corev1.Namespace{
+				placed(namespace("backend", map[string]string{"app": "backend"}), "us-west1+uid-12"),
+				placed(namespace("other", map[string]string{"app": "other"}), "us-east1+uid-1"),
+			},
+			apibindings: map[logicalcluster.Name][]*apisv1alpha1.APIBinding{logicalcluster.New("root:org:ws"): {
+				bound(validExport(binding("kubernetes", "negotiation-workspace"))),
+			}},
+			locations: map[logicalcluster.Name][]*schedulingv1alpha1.Location{logicalcluster.New("root:org:negotiation-workspace"): {
+				withInstances(location("us-east1"), map[string]string{"region": "us-east1"}),
+				withInstances(location("us-west1"), map[string]string{"region": "us-west1"}),
+			}},
+			workloadClusters: map[logicalcluster.Name][]*workloadv1alpha1.WorkloadCluster{
+				logicalcluster.New("root:org:negotiation-workspace"): {
+					withLabels(withConditions(cluster("us-east1-1", "uid-1"), conditionsv1alpha1.Condition{Type: "Ready", Status: "True"}), map[string]string{"region": "us-east1"}),
+					withLabels(withConditions(cluster("us-west1-1", "uid-11"), conditionsv1alpha1.Condition{Type: "Ready", Status: "True"}), map[string]string{"region": "us-west1"}),
+					withLabels(withConditions(cluster("us-west1-2", "uid-12"), conditionsv1alpha1.Condition{Type: "Ready", Status: "True"}), map[string]string{"region": "us-west1"}),
+				},
+			},
+			wantPatch:           `{"metadata":{"annotations":{"scheduling.kcp.dev/placement":"{\"us-west1+uid-12\":\"Pending\"}"}}}`,
+			wantReconcileStatus: reconcileStatusContinue,
+		},
+		"namespace anti-affinity avoids the workload cluster of the selected namespace": {
+			namespace: &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "staging-2",
+					ClusterName: "root:org:ws",
+					Labels:      map[string]string{"env": "staging"},
+					Annotations: map[string]string{
+						"scheduling.kcp.dev/namespace-anti-affinity": "env=staging",
+					},
+				},
+			},
+			namespaces: []*corev1.Namespace{
+				placed(namespace("staging-1", map[string]string{"env": "staging"}), "us-east1+uid-1"),
+			},
+			apibindings: map[logicalcluster.Name][]*apisv1alpha1.APIBinding{logicalcluster.New("root:org:ws"): {
+				bound(validExport(binding("kubernetes", "negotiation-workspace"))),
+			}},
+			locations: map[logicalcluster.Name][]*schedulingv1alpha1.Location{logicalcluster.New("root:org:negotiation-workspace"): {
+				withInstances(location("us-east1"), map[string]string{"region": "us-east1"}),
+			}},
+			workloadClusters: map[logicalcluster.Name][]*workloadv1alpha1.WorkloadCluster{
+				logicalcluster.New("root:org:negotiation-workspace"): {
+					withLabels(withConditions(cluster("us-east1-1", "uid-1"), conditionsv1alpha1.Condition{Type: "Ready", Status: "True"}), map[string]string{"region": "us-east1"}),
+					withLabels(withConditions(cluster("us-east1-2", "uid-2"), conditionsv1alpha1.Condition{Type: "Ready", Status: "True"}), map[string]string{"region": "us-east1"}),
+				},
+			},
+			wantPatch:           `{"metadata":{"annotations":{"scheduling.kcp.dev/placement":"{\"us-east1+uid-2\":\"Pending\"}"}}}`,
+			wantReconcileStatus: reconcileStatusContinue,
+		},
 		"patch fails": {
 			namespace: &corev1.Namespace{
 				ObjectMeta: metav1.ObjectMeta{
@@ -217,6 +323,9 @@ func TestPlacementReconciler(t *testing.T) {
 					}
 					return tc.workloadClusters[clusterName], nil
 				},
+				listNamespaces: func(clusterName logicalcluster.Name) ([]*corev1.Namespace, error) {
+					return tc.namespaces, nil
+				},
 				patchNamespace: func(ctx context.Context, clusterName logicalcluster.Name, name string, pt types.PatchType, data []byte, opts metav1.PatchOptions, subresources ...string) (*corev1.Namespace, error) {
 					if tc.patchNamespaceError != nil {
 						return nil, tc.patchNamespaceError
@@ -243,6 +352,23 @@ func TestPlacementReconciler(t *testing.T) {
 	}
 }
 
+func namespace(name string, labels map[string]string) *corev1.Namespace {
+	return &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			ClusterName: "root:org:ws",
+			Labels:      labels,
+		},
+	}
+}
+
+func placed(ns *corev1.Namespace, key string) *corev1.Namespace {
+	ns.Annotations = map[string]string{
+		"scheduling.kcp.dev/placement": fmt.Sprintf(`{%q:"Bound"}`, key),
+	}
+	return ns
+}
+
 func location(name string) *schedulingv1alpha1.Location {
 	return &schedulingv1alpha1.Location{
 		ObjectMeta: metav1.ObjectMeta{
@@ -313,6 +439,11 @@ func withConditions(cluster *workloadv1alpha1.WorkloadCluster, conditions ...con
 	return cluster
 }
 
+func tainted(cluster *workloadv1alpha1.WorkloadCluster, effect corev1.TaintEffect) *workloadv1alpha1.WorkloadCluster {
+	cluster.Spec.Taints = append(cluster.Spec.Taints, corev1.Taint{Key: "gpu", Effect: effect})
+	return cluster
+}
+
 func unschedulable(cluster *workloadv1alpha1.WorkloadCluster) *workloadv1alpha1.WorkloadCluster {
 	cluster.Spec.Unschedulable = true
 	return cluster
@@ -0,0 +1,215 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topology
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/kcp-dev/logicalcluster"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/clusters"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+
+	schedulingv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/scheduling/v1alpha1"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/workload/v1alpha1"
+	kcpclient "github.com/kcp-dev/kcp/pkg/client/clientset/versioned"
+	schedulinginformers "github.com/kcp-dev/kcp/pkg/client/informers/externalversions/scheduling/v1alpha1"
+	workloadinformers "github.com/kcp-dev/kcp/pkg/client/informers/externalversions/workload/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/logging"
+)
+
+const (
+	controllerName = "kcp-scheduling-topology"
+	byWorkspace    = controllerName + "-byWorkspace" // will go away with scoping
+)
+
+// NewController returns a new controller that labels workload clusters with the topology
+// reported by their syncers, and maintains one Location per region of workload clusters.
+func NewController(
+	kcpClusterClient kcpclient.ClusterInterface,
+	locationInformer schedulinginformers.LocationInformer,
+	workloadClusterInformer workloadinformers.WorkloadClusterInformer,
+) (*controller, error) {
+	queue := workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), controllerName)
+
+	c := &controller{
+		queue:                  queue,
+		kcpClusterClient:       kcpClusterClient,
+		locationIndexer:        locationInformer.Informer().GetIndexer(),
+		workloadClusterIndexer: workloadClusterInformer.Informer().GetIndexer(),
+	}
+
+	if err := workloadClusterInformer.Informer().AddIndexers(cache.Indexers{
+		byWorkspace: indexByWorkspace,
+	}); err != nil {
+		return nil, err
+	}
+
+	if err := locationInformer.Informer().AddIndexers(cache.Indexers{
+		byWorkspace: indexByWorkspace,
+	}); err != nil {
+		return nil, err
+	}
+
+	locationInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj interface{}) { c.enqueue(obj) },
+		UpdateFunc: func(_, obj interface{}) { c.enqueue(obj) },
+		DeleteFunc: func(obj interface{}) { c.enqueue(obj) },
+	})
+
+	workloadClusterInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) { c.enqueue(obj) },
+		UpdateFunc: func(old, obj interface{}) {
+			oldCluster, ok := old.(*workloadv1alpha1.WorkloadCluster)
+			if !ok {
+				return
+			}
+			objCluster, ok := obj.(*workloadv1alpha1.WorkloadCluster)
+			if !ok {
+				return
+			}
+
+			// only enqueue if labels or reported topology change.
+			if !equality.Semantic.DeepEqual(oldCluster.Labels, objCluster.Labels) ||
+				!equality.Semantic.DeepEqual(oldCluster.Status.TopologyLabels, objCluster.Status.TopologyLabels) {
+				c.enqueue(obj)
+			}
+		},
+		DeleteFunc: func(obj interface{}) { c.enqueue(obj) },
+	})
+
+	return c, nil
+}
+
+// controller
+type controller struct {
+	queue workqueue.RateLimitingInterface
+
+	kcpClusterClient kcpclient.ClusterInterface
+
+	locationIndexer        cache.Indexer
+	workloadClusterIndexer cache.Indexer
+}
+
+// enqueue maps a WorkloadCluster or Location to its workspace for enqueuing.
+func (c *controller) enqueue(obj interface{}) {
+	key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+	if err != nil {
+		runtime.HandleError(err)
+		return
+	}
+	lcluster, _ := clusters.SplitClusterAwareKey(key)
+
+	logging.WithWorkspace(logging.NewLogger(controllerName), lcluster).V(4).Info("Queueing workspace")
+	c.queue.Add(lcluster.String())
+}
+
+// Start starts the controller, which stops when ctx.Done() is closed.
+func (c *controller) Start(ctx context.Context, numThreads int) {
+	defer runtime.HandleCrash()
+	defer c.queue.ShutDown()
+
+	klog.Infof("Starting %s controller", controllerName)
+	defer klog.Infof("Shutting down %s controller", controllerName)
+
+	for i := 0; i < numThreads; i++ {
+		go wait.UntilWithContext(ctx, c.startWorker, time.Second)
+	}
+
+	<-ctx.Done()
+}
+
+func (c *controller) startWorker(ctx context.Context) {
+	for c.processNextWorkItem(ctx) {
+	}
+}
+
+func (c *controller) processNextWorkItem(ctx context.Context) bool {
+	// Wait until there is a new item in the working queue
+	k, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+	key := k.(string)
+
+	logger := logging.WithWorkspace(logging.WithQueueKey(logging.NewLogger(controllerName), key), logicalcluster.New(key))
+	ctx = logging.NewContext(ctx, logger)
+
+	// No matter what, tell the queue we're done with this key, to unblock
+	// other workers.
+	defer c.queue.Done(key)
+
+	if err := c.reconcile(ctx, logicalcluster.New(key)); err != nil {
+		runtime.HandleError(fmt.Errorf("%q controller failed to sync %q, err: %w", controllerName, key, err))
+		c.queue.AddRateLimited(key)
+		return true
+	}
+	c.queue.Forget(key)
+	return true
+}
+
+func (c *controller) listWorkloadClusters(clusterName logicalcluster.Name) ([]*workloadv1alpha1.WorkloadCluster, error) {
+	items, err := c.workloadClusterIndexer.ByIndex(byWorkspace, clusterName.String())
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]*workloadv1alpha1.WorkloadCluster, 0, len(items))
+	for _, item := range items {
+		ret = append(ret, item.(*workloadv1alpha1.WorkloadCluster))
+	}
+	return ret, nil
+}
+
+func (c *controller) listLocations(clusterName logicalcluster.Name) ([]*schedulingv1alpha1.Location, error) {
+	items, err := c.locationIndexer.ByIndex(byWorkspace, clusterName.String())
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]*schedulingv1alpha1.Location, 0, len(items))
+	for _, item := range items {
+		ret = append(ret, item.(*schedulingv1alpha1.Location))
+	}
+	return ret, nil
+}
+
+func (c *controller) patchWorkloadCluster(ctx context.Context, clusterName logicalcluster.Name, name string, patch []byte) error {
+	_, err := c.kcpClusterClient.Cluster(clusterName).WorkloadV1alpha1().WorkloadClusters().Patch(ctx, name, types.MergePatchType, patch, metav1.PatchOptions{})
+	return err
+}
+
+func (c *controller) createLocation(ctx context.Context, clusterName logicalcluster.Name, location *schedulingv1alpha1.Location) error {
+	_, err := c.kcpClusterClient.Cluster(clusterName).SchedulingV1alpha1().Locations().Create(ctx, location, metav1.CreateOptions{})
+	return err
+}
+
+func (c *controller) updateLocation(ctx context.Context, clusterName logicalcluster.Name, location *schedulingv1alpha1.Location) error {
+	_, err := c.kcpClusterClient.Cluster(clusterName).SchedulingV1alpha1().Locations().Update(ctx, location, metav1.UpdateOptions{})
+	return err
+}
+
+func (c *controller) deleteLocation(ctx context.Context, clusterName logicalcluster.Name, name string) error {
+	return c.kcpClusterClient.Cluster(clusterName).SchedulingV1alpha1().Locations().Delete(ctx, name, metav1.DeleteOptions{})
+}
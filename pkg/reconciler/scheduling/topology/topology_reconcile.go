@@ -0,0 +1,199 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topology
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/kcp-dev/logicalcluster"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilserrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	schedulingv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/scheduling/v1alpha1"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/workload/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/logging"
+)
+
+// topologyLabelKeys are the labels copied from status.topologyLabels to the labels of a workload cluster.
+var topologyLabelKeys = []string{corev1.LabelTopologyRegion, corev1.LabelTopologyZone}
+
+// topologyReconciler labels workload clusters with their reported topology and maintains
+// one Location per region.
+type topologyReconciler struct {
+	listWorkloadClusters func(clusterName logicalcluster.Name) ([]*workloadv1alpha1.WorkloadCluster, error)
+	listLocations        func(clusterName logicalcluster.Name) ([]*schedulingv1alpha1.Location, error)
+	patchWorkloadCluster func(ctx context.Context, clusterName logicalcluster.Name, name string, patch []byte) error
+	createLocation       func(ctx context.Context, clusterName logicalcluster.Name, location *schedulingv1alpha1.Location) error
+	updateLocation       func(ctx context.Context, clusterName logicalcluster.Name, location *schedulingv1alpha1.Location) error
+	deleteLocation       func(ctx context.Context, clusterName logicalcluster.Name, name string) error
+}
+
+func (r *topologyReconciler) reconcile(ctx context.Context, clusterName logicalcluster.Name) error {
+	logger := logging.FromContext(ctx)
+
+	workloadClusters, err := r.listWorkloadClusters(clusterName)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+
+	// copy the reported topology into the labels such that Locations can select on them.
+	zonesByRegion := map[string]sets.String{}
+	for _, wc := range workloadClusters {
+		missing := map[string]string{}
+		for _, key := range topologyLabelKeys {
+			if value, found := wc.Status.TopologyLabels[key]; found && value != "" && wc.Labels[key] != value {
+				missing[key] = value
+			}
+		}
+		if len(missing) > 0 {
+			patch, err := json.Marshal(map[string]interface{}{"metadata": map[string]interface{}{"labels": missing}})
+			if err != nil {
+				return err
+			}
+			logger.V(2).Info("Labelling WorkloadCluster with topology", logging.SyncTargetKey, wc.Name, "labels", missing)
+			if err := r.patchWorkloadCluster(ctx, clusterName, wc.Name, patch); err != nil {
+				errs = append(errs, err)
+			}
+		}
+
+		region, zone := wc.Labels[corev1.LabelTopologyRegion], wc.Labels[corev1.LabelTopologyZone]
+		if value, found := missing[corev1.LabelTopologyRegion]; found {
+			region = value
+		}
+		if value, found := missing[corev1.LabelTopologyZone]; found {
+			zone = value
+		}
+		if region == "" {
+			continue
+		}
+		if zonesByRegion[region] == nil {
+			zonesByRegion[region] = sets.NewString()
+		}
+		if zone != "" {
+			zonesByRegion[region].Insert(zone)
+		}
+	}
+
+	locations, err := r.listLocations(clusterName)
+	if err != nil {
+		return err
+	}
+	existing := make(map[string]*schedulingv1alpha1.Location, len(locations))
+	for _, location := range locations {
+		existing[location.Name] = location
+
+		// delete region Locations without workload clusters.
+		region, managed := location.Labels[schedulingv1alpha1.TopologyLocationLabelKey]
+		if !managed {
+			continue
+		}
+		if _, found := zonesByRegion[region]; found && location.Name == region {
+			continue
+		}
+		logger.V(2).Info("Deleting Location of region without workload clusters", "location", location.Name, "region", region)
+		if err := r.deleteLocation(ctx, clusterName, location.Name); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	regions := make([]string, 0, len(zonesByRegion))
+	for region := range zonesByRegion {
+		regions = append(regions, region)
+	}
+	sort.Strings(regions)
+	for _, region := range regions {
+		if msgs := validation.IsDNS1123Subdomain(region); len(msgs) > 0 {
+			logger.V(2).Info("Skipping Location for region, not a valid name", "region", region, "reasons", msgs)
+			continue
+		}
+		desired := regionLocation(region, zonesByRegion[region])
+
+		location, found := existing[region]
+		switch {
+		case !found:
+			logger.V(2).Info("Creating Location for region", "region", region)
+			if err := r.createLocation(ctx, clusterName, desired); err != nil {
+				errs = append(errs, err)
+			}
+		case location.Labels[schedulingv1alpha1.TopologyLocationLabelKey] != region:
+			// created by somebody else, leave it alone.
+			continue
+		case !equality.Semantic.DeepEqual(location.Spec, desired.Spec):
+			location = location.DeepCopy()
+			location.Spec = desired.Spec
+			if err := r.updateLocation(ctx, clusterName, location); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	return utilserrors.NewAggregate(errs)
+}
+
+// regionLocation returns the Location selecting the workload clusters of the given region.
+func regionLocation(region string, zones sets.String) *schedulingv1alpha1.Location {
+	location := &schedulingv1alpha1.Location{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: region,
+			Labels: map[string]string{
+				corev1.LabelTopologyRegion:                  region,
+				schedulingv1alpha1.TopologyLocationLabelKey: region,
+			},
+		},
+		Spec: schedulingv1alpha1.LocationSpec{
+			Resource: schedulingv1alpha1.GroupVersionResource{
+				Group:    workloadv1alpha1.SchemeGroupVersion.Group,
+				Version:  workloadv1alpha1.SchemeGroupVersion.Version,
+				Resource: "workloadclusters",
+			},
+			Description:      fmt.Sprintf("Workload clusters in region %s", region),
+			InstanceSelector: &metav1.LabelSelector{MatchLabels: map[string]string{corev1.LabelTopologyRegion: region}},
+		},
+	}
+	if zones.Len() > 0 {
+		values := make([]schedulingv1alpha1.LabelValue, 0, zones.Len())
+		for _, zone := range zones.List() {
+			values = append(values, schedulingv1alpha1.LabelValue(zone))
+		}
+		location.Spec.AvailableSelectorLabels = []schedulingv1alpha1.AvailableSelectorLabel{
+			{Key: corev1.LabelTopologyZone, Values: values, Description: "Zone of the workload cluster"},
+		}
+	}
+	return location
+}
+
+func (c *controller) reconcile(ctx context.Context, clusterName logicalcluster.Name) error {
+	r := &topologyReconciler{
+		listWorkloadClusters: c.listWorkloadClusters,
+		listLocations:        c.listLocations,
+		patchWorkloadCluster: c.patchWorkloadCluster,
+		createLocation:       c.createLocation,
+		updateLocation:       c.updateLocation,
+		deleteLocation:       c.deleteLocation,
+	}
+	return r.reconcile(ctx, clusterName)
+}
@@ -0,0 +1,164 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package topology
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/kcp-dev/logicalcluster"
+	"github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	schedulingv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/scheduling/v1alpha1"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/workload/v1alpha1"
+)
+
+func cluster(name string, labels, topology map[string]string) *workloadv1alpha1.WorkloadCluster {
+	return &workloadv1alpha1.WorkloadCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels, ClusterName: "root:org:ws"},
+		Status:     workloadv1alpha1.WorkloadClusterStatus{TopologyLabels: topology},
+	}
+}
+
+func TestTopologyReconciler(t *testing.T) {
+	usEast1 := regionLocation("us-east1", sets.NewString("us-east1-b"))
+	unmanaged := regionLocation("us-east1", nil)
+	unmanaged.Labels = nil
+	outdated := regionLocation("us-east1", nil)
+	stale := regionLocation("eu-west1", nil)
+
+	tests := map[string]struct {
+		workloadClusters []*workloadv1alpha1.WorkloadCluster
+		locations        []*schedulingv1alpha1.Location
+		patchError       error
+
+		wantPatches map[string]string
+		wantCreates []string
+		wantUpdates []string
+		wantDeletes []string
+		wantError   bool
+	}{
+		"no workload clusters": {},
+		"reported topology is labelled and location is created": {
+			workloadClusters: []*workloadv1alpha1.WorkloadCluster{
+				cluster("a", nil, map[string]string{"topology.kubernetes.io/region": "us-east1", "topology.kubernetes.io/zone": "us-east1-b"}),
+			},
+			wantPatches: map[string]string{
+				"a": `{"metadata":{"labels":{"topology.kubernetes.io/region":"us-east1","topology.kubernetes.io/zone":"us-east1-b"}}}`,
+			},
+			wantCreates: []string{"us-east1"},
+		},
+		"manual labels without reported topology": {
+			workloadClusters: []*workloadv1alpha1.WorkloadCluster{
+				cluster("a", map[string]string{"topology.kubernetes.io/region": "us-east1", "topology.kubernetes.io/zone": "us-east1-b"}, nil),
+			},
+			wantCreates: []string{"us-east1"},
+		},
+		"up-to-date location": {
+			workloadClusters: []*workloadv1alpha1.WorkloadCluster{
+				cluster("a", map[string]string{"topology.kubernetes.io/region": "us-east1", "topology.kubernetes.io/zone": "us-east1-b"}, map[string]string{"topology.kubernetes.io/region": "us-east1", "topology.kubernetes.io/zone": "us-east1-b"}),
+			},
+			locations: []*schedulingv1alpha1.Location{usEast1},
+		},
+		"outdated location is updated": {
+			workloadClusters: []*workloadv1alpha1.WorkloadCluster{
+				cluster("a", map[string]string{"topology.kubernetes.io/region": "us-east1", "topology.kubernetes.io/zone": "us-east1-b"}, nil),
+			},
+			locations:   []*schedulingv1alpha1.Location{outdated},
+			wantUpdates: []string{"us-east1"},
+		},
+		"location of somebody else is left alone": {
+			workloadClusters: []*workloadv1alpha1.WorkloadCluster{
+				cluster("a", map[string]string{"topology.kubernetes.io/region": "us-east1"}, nil),
+			},
+			locations: []*schedulingv1alpha1.Location{unmanaged},
+		},
+		"location of a region without workload clusters is deleted": {
+			workloadClusters: []*workloadv1alpha1.WorkloadCluster{
+				cluster("a", map[string]string{"topology.kubernetes.io/region": "us-east1", "topology.kubernetes.io/zone": "us-east1-b"}, nil),
+			},
+			locations:   []*schedulingv1alpha1.Location{usEast1, stale},
+			wantDeletes: []string{"eu-west1"},
+		},
+		"invalid region name": {
+			workloadClusters: []*workloadv1alpha1.WorkloadCluster{
+				cluster("a", map[string]string{"topology.kubernetes.io/region": "US_East"}, nil),
+			},
+		},
+		"patch error": {
+			workloadClusters: []*workloadv1alpha1.WorkloadCluster{
+				cluster("a", nil, map[string]string{"topology.kubernetes.io/region": "us-east1"}),
+			},
+			patchError: errors.New("failed"),
+			wantPatches: map[string]string{
+				"a": `{"metadata":{"labels":{"topology.kubernetes.io/region":"us-east1"}}}`,
+			},
+			wantCreates: []string{"us-east1"},
+			wantError:   true,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			patches := map[string]string{}
+			var creates, updates, deletes []string
+			r := &topologyReconciler{
+				listWorkloadClusters: func(clusterName logicalcluster.Name) ([]*workloadv1alpha1.WorkloadCluster, error) {
+					return tc.workloadClusters, nil
+				},
+				listLocations: func(clusterName logicalcluster.Name) ([]*schedulingv1alpha1.Location, error) {
+					return tc.locations, nil
+				},
+				patchWorkloadCluster: func(ctx context.Context, clusterName logicalcluster.Name, name string, patch []byte) error {
+					patches[name] = string(patch)
+					return tc.patchError
+				},
+				createLocation: func(ctx context.Context, clusterName logicalcluster.Name, location *schedulingv1alpha1.Location) error {
+					require.Equal(t, location.Name, location.Labels["scheduling.kcp.dev/topology-region"])
+					creates = append(creates, location.Name)
+					return nil
+				},
+				updateLocation: func(ctx context.Context, clusterName logicalcluster.Name, location *schedulingv1alpha1.Location) error {
+					updates = append(updates, location.Name)
+					return nil
+				},
+				deleteLocation: func(ctx context.Context, clusterName logicalcluster.Name, name string) error {
+					deletes = append(deletes, name)
+					return nil
+				},
+			}
+
+			err := r.reconcile(context.Background(), logicalcluster.New("root:org:ws"))
+			if tc.wantError {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+
+			if tc.wantPatches == nil {
+				tc.wantPatches = map[string]string{}
+			}
+			require.Equal(t, tc.wantPatches, patches)
+			require.Equal(t, tc.wantCreates, creates)
+			require.Equal(t, tc.wantUpdates, updates)
+			require.Equal(t, tc.wantDeletes, deletes)
+		})
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"sync"
 	"time"
 
 	jsonpatch "github.com/evanphx/json-patch"
@@ -29,7 +30,9 @@ import (
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/dynamic"
@@ -38,10 +41,12 @@ import (
 	"k8s.io/client-go/util/workqueue"
 	"k8s.io/klog/v2"
 
+	confighelpers "github.com/kcp-dev/kcp/config/helpers"
 	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
 	kcpclient "github.com/kcp-dev/kcp/pkg/client/clientset/versioned"
 	tenancyinformer "github.com/kcp-dev/kcp/pkg/client/informers/externalversions/tenancy/v1alpha1"
 	tenancylister "github.com/kcp-dev/kcp/pkg/client/listers/tenancy/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/logging"
 )
 
 const (
@@ -54,10 +59,11 @@ func NewController(
 	kcpClusterClient kcpclient.ClusterInterface,
 	workspaceInformer tenancyinformer.ClusterWorkspaceInformer,
 	workspaceType string,
-	bootstrap func(context.Context, discovery.DiscoveryInterface, dynamic.Interface) error,
+	bootstrap func(context.Context, discovery.DiscoveryInterface, dynamic.Interface, ...confighelpers.Option) error,
+	forceReconcile bool,
 ) (*controller, error) {
 	controllerName := fmt.Sprintf("%s-%s", controllerNameBase, workspaceType)
-	queue := workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), controllerName)
+	queue := workqueue.NewNamedRateLimitingQueue(workqueue.NewItemExponentialFailureRateLimiter(initializerRetryBaseDelay, initializerRetryMaxDelay), controllerName)
 
 	c := &controller{
 		controllerName:  controllerName,
@@ -69,20 +75,59 @@ func NewController(
 		syncChecks: []cache.InformerSynced{
 			workspaceInformer.Informer().HasSynced,
 		},
-		workspaceType: workspaceType,
-		bootstrap:     bootstrap,
+		workspaceType:  workspaceType,
+		bootstrap:      bootstrap,
+		forceReconcile: forceReconcile,
+		reconciled:     sets.NewString(),
 	}
 
 	workspaceInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
-		AddFunc:    func(obj interface{}) { c.enqueue(obj) },
-		UpdateFunc: func(_, obj interface{}) { c.enqueue(obj) },
+		AddFunc: func(obj interface{}) { c.enqueue(obj) },
+		UpdateFunc: func(oldObj, obj interface{}) {
+			// skip updates of the status only, e.g. of our own entry in initializerFailures, which must
+			// not bypass the backoff of the queue.
+			oldWorkspace, ok := oldObj.(*tenancyv1alpha1.ClusterWorkspace)
+			if !ok {
+				return
+			}
+			newWorkspace, ok := obj.(*tenancyv1alpha1.ClusterWorkspace)
+			if !ok {
+				return
+			}
+			if oldWorkspace.Spec.Type == newWorkspace.Spec.Type &&
+				oldWorkspace.Status.Phase == newWorkspace.Status.Phase &&
+				equality.Semantic.DeepEqual(oldWorkspace.Status.Initializers, newWorkspace.Status.Initializers) {
+				return
+			}
+			c.enqueue(obj)
+		},
 	})
 
 	return c, nil
 }
 
+const (
+	initializerRetryBaseDelay = 100 * time.Millisecond
+	initializerRetryMaxDelay  = 5 * time.Minute
+)
+
+// retryDelay returns the backoff of the queue after the given number of requeues, i.e. when the
+// next attempt of the initializer is due.
+func retryDelay(requeues int) time.Duration {
+	delay := initializerRetryBaseDelay
+	for i := 0; i < requeues && delay < initializerRetryMaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > initializerRetryMaxDelay {
+		return initializerRetryMaxDelay
+	}
+	return delay
+}
+
 // controller watches ClusterWorkspaces of a given type in initializing
 // state and bootstrap resources from the configs/<lower-case-type> package.
+// Ready workspaces of the type are reconciled once per process, such that
+// resources changed in a newer kcp version are updated.
 type controller struct {
 	controllerName string
 
@@ -96,8 +141,14 @@ type controller struct {
 
 	syncChecks []cache.InformerSynced
 
-	workspaceType string
-	bootstrap     func(context.Context, discovery.DiscoveryInterface, dynamic.Interface) error
+	workspaceType  string
+	bootstrap      func(context.Context, discovery.DiscoveryInterface, dynamic.Interface, ...confighelpers.Option) error
+	forceReconcile bool
+
+	// reconciledLock guards reconciled.
+	reconciledLock sync.Mutex
+	// reconciled holds the keys of the ready workspaces reconciled by this process.
+	reconciled sets.String
 }
 
 func (c *controller) enqueue(obj interface{}) {
@@ -106,7 +157,7 @@ func (c *controller) enqueue(obj interface{}) {
 		runtime.HandleError(err)
 		return
 	}
-	klog.Infof("queueing cluster workspace %q", key)
+	logging.WithQueueKey(logging.NewLogger(c.controllerName), key).Info("Queueing cluster workspace")
 	c.queue.Add(key)
 }
 
@@ -142,7 +193,10 @@ func (c *controller) processNextWorkItem(ctx context.Context) bool {
 	}
 	key := k.(string)
 
-	klog.Infof("processing key %q", key)
+	logger := logging.WithQueueKey(logging.NewLogger(c.controllerName), key)
+	ctx = logging.NewContext(ctx, logger)
+
+	logger.Info("Processing")
 
 	// No matter what, tell the queue we're done with this key, to unblock
 	// other workers.
@@ -160,7 +214,7 @@ func (c *controller) processNextWorkItem(ctx context.Context) bool {
 func (c *controller) process(ctx context.Context, key string) error {
 	namespace, clusterAwareName, err := cache.SplitMetaNamespaceKey(key)
 	if err != nil {
-		klog.Errorf("invalid key: %q: %v", key, err)
+		logging.FromContext(ctx).Error(err, "Invalid key")
 		return nil
 	}
 	clusterName, name := clusters.SplitClusterAwareKey(clusterAwareName)
@@ -175,9 +229,8 @@ func (c *controller) process(ctx context.Context, key string) error {
 	old := obj
 	obj = obj.DeepCopy()
 
-	if err := c.reconcile(ctx, obj); err != nil {
-		return err
-	}
+	// the status is updated also on error, in order to report the failure
+	reconcileErr := c.reconcile(ctx, obj)
 
 	// If the object being reconciled changed as a result, update it.
 	if !equality.Semantic.DeepEqual(old.Status, obj.Status) {
@@ -203,9 +256,10 @@ func (c *controller) process(ctx context.Context, key string) error {
 		if err != nil {
 			return fmt.Errorf("failed to create patch for workspace %s|%s/%s: %w", clusterName, namespace, name, err)
 		}
-		_, uerr := c.kcpClient.Cluster(clusterName).TenancyV1alpha1().ClusterWorkspaces().Patch(ctx, obj.Name, types.MergePatchType, patchBytes, metav1.PatchOptions{}, "status")
-		return uerr
+		if _, err := c.kcpClient.Cluster(clusterName).TenancyV1alpha1().ClusterWorkspaces().Patch(ctx, obj.Name, types.MergePatchType, patchBytes, metav1.PatchOptions{}, "status"); err != nil {
+			return utilerrors.NewAggregate([]error{reconcileErr, err})
+		}
 	}
 
-	return nil
+	return reconcileErr
 }
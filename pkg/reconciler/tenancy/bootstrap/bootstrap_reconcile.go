@@ -23,9 +23,14 @@ import (
 
 	"github.com/kcp-dev/logicalcluster"
 
-	"k8s.io/klog/v2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/clusters"
 
+	confighelpers "github.com/kcp-dev/kcp/config/helpers"
 	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+	tenancyhelper "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1/helper"
+	"github.com/kcp-dev/kcp/pkg/logging"
 )
 
 const (
@@ -33,13 +38,21 @@ const (
 )
 
 func (c *controller) reconcile(ctx context.Context, workspace *tenancyv1alpha1.ClusterWorkspace) error {
-	if workspace.Status.Phase != tenancyv1alpha1.ClusterWorkspacePhaseInitializing {
-		return nil
-	}
 	if workspace.Spec.Type != c.workspaceType {
 		return nil
 	}
 
+	switch workspace.Status.Phase {
+	case tenancyv1alpha1.ClusterWorkspacePhaseInitializing:
+		return c.reconcileInitializing(ctx, workspace)
+	case tenancyv1alpha1.ClusterWorkspacePhaseReady:
+		return c.reconcileReady(ctx, workspace)
+	}
+
+	return nil
+}
+
+func (c *controller) reconcileInitializing(ctx context.Context, workspace *tenancyv1alpha1.ClusterWorkspace) error {
 	// have we done our work before?
 	found := false
 	initializerName := tenancyv1alpha1.ClusterWorkspaceInitializer(typeInitializerKeyDomain + "/" + strings.ToLower(c.workspaceType))
@@ -53,14 +66,24 @@ func (c *controller) reconcile(ctx context.Context, workspace *tenancyv1alpha1.C
 		return nil
 	}
 
-	// bootstrap resources
-	wsClusterName := logicalcluster.From(workspace).Join(workspace.Name)
-	klog.Infof("Bootstrapping resources for org workspace %s, logical cluster %s", workspace.Name, wsClusterName)
-	bootstrapCtx, cancel := context.WithDeadline(ctx, time.Now().Add(time.Second*30)) // to not block the controller
-	defer cancel()
-	if err := c.bootstrap(bootstrapCtx, c.crdClient.Cluster(wsClusterName).Discovery(), c.dynamicClient.Cluster(wsClusterName)); err != nil {
+	// wait for the initializers we depend on. We are triggered again when they are cleared.
+	if pending := tenancyhelper.PendingInitializerDependencies(workspace, initializerName); len(pending) > 0 {
+		logging.WithClusterWorkspace(logging.FromContext(ctx), workspace).V(4).Info("Waiting for initializers before bootstrapping", "initializers", pending)
+		return nil
+	}
+
+	// bootstrap resources, and record failures in the status. The queue retries with backoff.
+	if err := c.bootstrapWorkspace(ctx, workspace); err != nil {
+		now := metav1.Now()
+		var nextRetry *metav1.Time
+		if key, keyErr := cache.MetaNamespaceKeyFunc(workspace); keyErr == nil {
+			t := metav1.NewTime(now.Add(retryDelay(c.queue.NumRequeues(key))))
+			nextRetry = &t
+		}
+		tenancyhelper.SetInitializerFailure(workspace, initializerName, err, now, nextRetry)
 		return err // requeue
 	}
+	tenancyhelper.ClearInitializerFailure(workspace, initializerName)
 
 	// we are done. remove our initializer
 	newInitializers := make([]tenancyv1alpha1.ClusterWorkspaceInitializer, 0, len(workspace.Status.Initializers))
@@ -73,3 +96,34 @@ func (c *controller) reconcile(ctx context.Context, workspace *tenancyv1alpha1.C
 
 	return nil
 }
+
+// reconcileReady bootstraps the resources of a ready workspace again, once per process. Resources
+// whose content did not change since they were bootstrapped are not touched unless forced.
+func (c *controller) reconcileReady(ctx context.Context, workspace *tenancyv1alpha1.ClusterWorkspace) error {
+	key := clusters.ToClusterAwareKey(logicalcluster.From(workspace), workspace.Name)
+
+	c.reconciledLock.Lock()
+	done := c.reconciled.Has(key)
+	c.reconciledLock.Unlock()
+	if done {
+		return nil
+	}
+
+	if err := c.bootstrapWorkspace(ctx, workspace); err != nil {
+		return err // requeue
+	}
+
+	c.reconciledLock.Lock()
+	c.reconciled.Insert(key)
+	c.reconciledLock.Unlock()
+
+	return nil
+}
+
+func (c *controller) bootstrapWorkspace(ctx context.Context, workspace *tenancyv1alpha1.ClusterWorkspace) error {
+	wsClusterName := logicalcluster.From(workspace).Join(workspace.Name)
+	logging.WithWorkspace(logging.FromContext(ctx), wsClusterName).Info("Bootstrapping resources", "workspaceType", c.workspaceType)
+	bootstrapCtx, cancel := context.WithDeadline(ctx, time.Now().Add(time.Second*30)) // to not block the controller
+	defer cancel()
+	return c.bootstrap(bootstrapCtx, c.crdClient.Cluster(wsClusterName).Discovery(), c.dynamicClient.Cluster(wsClusterName), confighelpers.ForceOption(c.forceReconcile))
+}
@@ -20,8 +20,6 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"net/url"
-	"path"
 	"strings"
 	"time"
 
@@ -35,6 +33,7 @@ import (
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/apimachinery/pkg/util/rand"
 	"k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/clusters"
@@ -42,9 +41,12 @@ import (
 	"k8s.io/klog/v2"
 
 	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+	tenancyhelper "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1/helper"
 	kcpclient "github.com/kcp-dev/kcp/pkg/client/clientset/versioned"
 	tenancyinformer "github.com/kcp-dev/kcp/pkg/client/informers/externalversions/tenancy/v1alpha1"
 	tenancylister "github.com/kcp-dev/kcp/pkg/client/listers/tenancy/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/logging"
+	"github.com/kcp-dev/kcp/pkg/reconciler/tenancy/workspaceurl"
 	conditionsv1alpha1 "github.com/kcp-dev/kcp/third_party/conditions/apis/conditions/v1alpha1"
 	"github.com/kcp-dev/kcp/third_party/conditions/util/conditions"
 )
@@ -53,6 +55,10 @@ const (
 	currentShardIndex  = "shard"
 	unschedulableIndex = "unschedulable"
 	controllerName     = "workspace"
+
+	// defaultInitializerTimeout is the default time after which initializers that have not made progress are
+	// reported as stuck.
+	defaultInitializerTimeout = 10 * time.Minute
 )
 
 func NewController(
@@ -62,6 +68,8 @@ func NewController(
 ) (*Controller, error) {
 	queue := workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), controllerName)
 
+	RegisterMetrics()
+
 	c := &Controller{
 		queue:                     queue,
 		kcpClient:                 kcpClient,
@@ -69,6 +77,7 @@ func NewController(
 		workspaceLister:           workspaceInformer.Lister(),
 		rootWorkspaceShardIndexer: rootWorkspaceShardInformer.Informer().GetIndexer(),
 		rootWorkspaceShardLister:  rootWorkspaceShardInformer.Lister(),
+		initializerTimeout:        defaultInitializerTimeout,
 	}
 
 	workspaceInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
@@ -114,6 +123,8 @@ type Controller struct {
 
 	rootWorkspaceShardIndexer cache.Indexer
 	rootWorkspaceShardLister  tenancylister.ClusterWorkspaceShardLister
+
+	initializerTimeout time.Duration
 }
 
 func (c *Controller) enqueue(obj interface{}) {
@@ -122,10 +133,19 @@ func (c *Controller) enqueue(obj interface{}) {
 		runtime.HandleError(err)
 		return
 	}
-	klog.Infof("Queueing workspace %q", key)
+	logging.WithQueueKey(logging.NewLogger(controllerName), key).Info("Queueing workspace")
 	c.queue.Add(key)
 }
 
+func (c *Controller) enqueueAfter(obj interface{}, duration time.Duration) {
+	key, err := cache.MetaNamespaceKeyFunc(obj)
+	if err != nil {
+		runtime.HandleError(err)
+		return
+	}
+	c.queue.AddAfter(key, duration)
+}
+
 func (c *Controller) enqueueUpsertedShard(obj interface{}, verb string) {
 	shard, ok := obj.(*tenancyv1alpha1.ClusterWorkspaceShard)
 	if !ok {
@@ -144,7 +164,7 @@ func (c *Controller) enqueueUpsertedShard(obj interface{}, verb string) {
 			runtime.HandleError(err)
 			return
 		}
-		klog.Infof("Queuing unschedulable workspace %q", key)
+		logging.WithQueueKey(logging.NewLogger(controllerName), key).Info("Queueing unschedulable workspace")
 		c.queue.Add(key)
 	}
 }
@@ -175,7 +195,7 @@ func (c *Controller) enqueueDeletedShard(obj interface{}) {
 			runtime.HandleError(err)
 			return
 		}
-		klog.Infof("queuing orphaned workspace %q", key)
+		logging.WithQueueKey(logging.NewLogger(controllerName), key).Info("Queueing orphaned workspace")
 		c.queue.Add(key)
 	}
 }
@@ -207,7 +227,10 @@ func (c *Controller) processNextWorkItem(ctx context.Context) bool {
 	}
 	key := k.(string)
 
-	klog.Infof("processing key %q", key)
+	logger := logging.WithQueueKey(logging.NewLogger(controllerName), key)
+	ctx = logging.NewContext(ctx, logger)
+
+	logger.Info("Processing")
 
 	// No matter what, tell the queue we're done with this key, to unblock
 	// other workers.
@@ -225,7 +248,7 @@ func (c *Controller) processNextWorkItem(ctx context.Context) bool {
 func (c *Controller) process(ctx context.Context, key string) error {
 	namespace, clusterAwareName, err := cache.SplitMetaNamespaceKey(key)
 	if err != nil {
-		klog.Errorf("invalid key: %q: %v", key, err)
+		logging.FromContext(ctx).Error(err, "Invalid key")
 		return nil
 	}
 	clusterName, name := clusters.SplitClusterAwareKey(clusterAwareName)
@@ -269,26 +292,64 @@ func (c *Controller) process(ctx context.Context, key string) error {
 			return fmt.Errorf("failed to create patch for workspace %s|%s/%s: %w", clusterName, namespace, name, err)
 		}
 		_, uerr := c.kcpClient.Cluster(clusterName).TenancyV1alpha1().ClusterWorkspaces().Patch(ctx, obj.Name, types.MergePatchType, patchBytes, metav1.PatchOptions{}, "status")
-		return uerr
+		if uerr != nil {
+			return uerr
+		}
+		if previous.Status.Phase != tenancyv1alpha1.ClusterWorkspacePhaseReady && obj.Status.Timeline != nil && obj.Status.Timeline.Ready != nil {
+			workspaceReadyDuration.WithLabelValues(obj.Spec.Type).Observe(obj.Status.Timeline.Ready.Sub(obj.CreationTimestamp.Time).Seconds())
+		}
 	}
 
-	return nil
+	return c.reconcileInitializerLabels(ctx, clusterName, previous, obj.Status.Initializers)
+}
+
+// reconcileInitializerLabels sets the labels of the given initializers on the workspace, and removes
+// the labels of initializers that have been cleared.
+func (c *Controller) reconcileInitializerLabels(ctx context.Context, clusterName logicalcluster.Name, workspace *tenancyv1alpha1.ClusterWorkspace, initializers []tenancyv1alpha1.ClusterWorkspaceInitializer) error {
+	desired := tenancyhelper.InitializerLabels(initializers)
+	changed := map[string]interface{}{}
+	for key := range workspace.Labels {
+		if _, found := desired[key]; !found && strings.HasPrefix(key, tenancyv1alpha1.ClusterWorkspaceInitializerLabelPrefix) {
+			changed[key] = nil
+		}
+	}
+	for key, value := range desired {
+		if workspace.Labels[key] != value {
+			changed[key] = value
+		}
+	}
+	if len(changed) == 0 {
+		return nil
+	}
+
+	patchBytes, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"labels": changed,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create label patch for workspace %s|%s: %w", clusterName, workspace.Name, err)
+	}
+	_, err = c.kcpClient.Cluster(clusterName).TenancyV1alpha1().ClusterWorkspaces().Patch(ctx, workspace.Name, types.MergePatchType, patchBytes, metav1.PatchOptions{})
+	return err
 }
 
 func (c *Controller) reconcile(ctx context.Context, workspace *tenancyv1alpha1.ClusterWorkspace) error {
+	logger := logging.WithClusterWorkspace(logging.FromContext(ctx), workspace)
+
 	switch workspace.Status.Phase {
 	case tenancyv1alpha1.ClusterWorkspacePhaseScheduling:
 		// possibly de-schedule while still in scheduling phase
 		if current := workspace.Status.Location.Current; current != "" {
 			// make sure current shard still exists
 			if shard, err := c.rootWorkspaceShardLister.Get(clusters.ToClusterAwareKey(tenancyv1alpha1.RootCluster, current)); errors.IsNotFound(err) {
-				klog.Infof("De-scheduling workspace %s|%s from nonexistent shard %q", tenancyv1alpha1.RootCluster, workspace.Name, current)
+				logger.Info("De-scheduling workspace from nonexistent shard", "shard", current)
 				workspace.Status.Location.Current = ""
 				workspace.Status.BaseURL = ""
 			} else if err != nil {
 				return err
 			} else if valid, _, _ := isValidShard(shard); !valid {
-				klog.Infof("De-scheduling workspace %s|%s from invalid shard %q", tenancyv1alpha1.RootCluster, workspace.Name, current)
+				logger.Info("De-scheduling workspace from invalid shard", "shard", current)
 				workspace.Status.Location.Current = ""
 				workspace.Status.BaseURL = ""
 			}
@@ -321,27 +382,26 @@ func (c *Controller) reconcile(ctx context.Context, workspace *tenancyv1alpha1.C
 			if len(validShards) > 0 {
 				targetShard := validShards[rand.Intn(len(validShards))]
 
-				u, err := url.Parse(targetShard.Spec.ExternalURL)
+				baseURL, err := workspaceurl.WorkspaceBaseURL(targetShard, workspace)
 				if err != nil {
 					// shouldn't happen since we just checked in isValidShard
-					conditions.MarkFalse(workspace, tenancyv1alpha1.WorkspaceScheduled, tenancyv1alpha1.WorkspaceReasonReasonUnknown, conditionsv1alpha1.ConditionSeverityError, "Invalid connection information on target ClusterWorkspaceShard: %v.", err)
+					conditions.MarkFalseObserved(workspace, tenancyv1alpha1.WorkspaceScheduled, tenancyv1alpha1.WorkspaceReasonReasonUnknown, conditionsv1alpha1.ConditionSeverityError, "Invalid connection information on target ClusterWorkspaceShard: %v.", err)
 					return err // requeue
 				}
-				logicalCluster := logicalcluster.From(workspace)
-				u.Path = path.Join(u.Path, logicalCluster.Join(workspace.Name).Path())
 
-				workspace.Status.BaseURL = u.String()
+				workspace.Status.BaseURL = baseURL
+
 				workspace.Status.Location.Current = targetShard.Name
 
-				conditions.MarkTrue(workspace, tenancyv1alpha1.WorkspaceScheduled)
-				klog.Infof("Scheduled workspace %s|%s to %s|%s", workspace.ClusterName, workspace.Name, targetShard.ClusterName, targetShard.Name)
+				conditions.MarkTrueObserved(workspace, tenancyv1alpha1.WorkspaceScheduled)
+				logger.Info("Scheduled workspace", "shard", targetShard.Name)
 			} else {
-				conditions.MarkFalse(workspace, tenancyv1alpha1.WorkspaceScheduled, tenancyv1alpha1.WorkspaceReasonUnschedulable, conditionsv1alpha1.ConditionSeverityError, "No available shards to schedule the workspace.")
+				conditions.MarkFalseObserved(workspace, tenancyv1alpha1.WorkspaceScheduled, tenancyv1alpha1.WorkspaceReasonUnschedulable, conditionsv1alpha1.ConditionSeverityError, "No available shards to schedule the workspace.")
 				failures := make([]string, 0, len(invalidShards))
 				for name, x := range invalidShards {
 					failures = append(failures, fmt.Sprintf("  %s: reason %q, message %q", name, x.reason, x.message))
 				}
-				klog.Infof("No valid shards found for workspace %s|%s, skipped:\n%s", workspace.ClusterName, workspace.Name, strings.Join(failures, "\n"))
+				logger.Info("No valid shards found for workspace", "skipped", failures)
 			}
 		}
 
@@ -359,12 +419,12 @@ func (c *Controller) reconcile(ctx context.Context, workspace *tenancyv1alpha1.C
 
 		_, err := c.rootWorkspaceShardLister.Get(clusters.ToClusterAwareKey(tenancyv1alpha1.RootCluster, target))
 		if errors.IsNotFound(err) {
-			klog.Infof("Cannot move to nonexistent shard %q", tenancyv1alpha1.RootCluster, workspace.Name, target)
+			logger.Info("Cannot move to nonexistent shard", "shard", target)
 		} else if err != nil {
 			return err
 		}
 
-		klog.Infof("Moving workspace %q to %q", workspace.Name, workspace.Status.Location.Target)
+		logger.Info("Moving workspace", "shard", workspace.Status.Location.Target)
 		workspace.Status.Location.Current = workspace.Status.Location.Target
 		workspace.Status.Location.Target = ""
 	}
@@ -373,13 +433,13 @@ func (c *Controller) reconcile(ctx context.Context, workspace *tenancyv1alpha1.C
 	// a movement controller in the future (or a human intervention) to move workspaces off a shard.
 	if workspace.Status.Location.Current != "" {
 		if shard, err := c.rootWorkspaceShardLister.Get(clusters.ToClusterAwareKey(tenancyv1alpha1.RootCluster, workspace.Status.Location.Current)); errors.IsNotFound(err) {
-			conditions.MarkFalse(workspace, tenancyv1alpha1.WorkspaceShardValid, tenancyv1alpha1.WorkspaceShardValidReasonShardNotFound, conditionsv1alpha1.ConditionSeverityError, fmt.Sprintf("ClusterWorkspaceShard %q got deleted.", workspace.Status.Location.Current))
+			conditions.MarkFalseObserved(workspace, tenancyv1alpha1.WorkspaceShardValid, tenancyv1alpha1.WorkspaceShardValidReasonShardNotFound, conditionsv1alpha1.ConditionSeverityError, fmt.Sprintf("ClusterWorkspaceShard %q got deleted.", workspace.Status.Location.Current))
 		} else if err != nil {
 			return err
 		} else if valid, reason, message := isValidShard(shard); !valid {
-			conditions.MarkFalse(workspace, tenancyv1alpha1.WorkspaceShardValid, reason, conditionsv1alpha1.ConditionSeverityError, message)
+			conditions.MarkFalseObserved(workspace, tenancyv1alpha1.WorkspaceShardValid, reason, conditionsv1alpha1.ConditionSeverityError, message)
 		} else {
-			conditions.MarkTrue(workspace, tenancyv1alpha1.WorkspaceShardValid)
+			conditions.MarkTrueObserved(workspace, tenancyv1alpha1.WorkspaceShardValid)
 		}
 	}
 
@@ -400,16 +460,85 @@ func (c *Controller) reconcile(ctx context.Context, workspace *tenancyv1alpha1.C
 			}
 
 			workspace.Status.Phase = tenancyv1alpha1.ClusterWorkspacePhaseInitializing
+			if workspace.Status.Timeline == nil {
+				workspace.Status.Timeline = &tenancyv1alpha1.ClusterWorkspaceTimeline{}
+			}
+			now := metav1.Now()
+			workspace.Status.Timeline.Scheduled = &now
 		}
 	case tenancyv1alpha1.ClusterWorkspacePhaseInitializing:
+		reconcileInitializerFailures(workspace)
 		if len(workspace.Status.Initializers) == 0 {
+			conditions.MarkTrueObserved(workspace, tenancyv1alpha1.WorkspaceInitialized)
 			workspace.Status.Phase = tenancyv1alpha1.ClusterWorkspacePhaseReady
+			if workspace.Status.Timeline == nil {
+				workspace.Status.Timeline = &tenancyv1alpha1.ClusterWorkspaceTimeline{}
+			}
+			now := metav1.Now()
+			workspace.Status.Timeline.Initialized = &now
+			workspace.Status.Timeline.Ready = &now
+			break
 		}
+		c.reconcileInitializerProgress(workspace)
 	}
 
 	return nil
 }
 
+// reconcileInitializerProgress reports the pending initializers in the WorkspaceInitialized condition, and
+// flags them as stuck if none of them has been cleared for initializerTimeout.
+func (c *Controller) reconcileInitializerProgress(workspace *tenancyv1alpha1.ClusterWorkspace) {
+	pending := make([]string, 0, len(workspace.Status.Initializers))
+	for _, initializer := range workspace.Status.Initializers {
+		pending = append(pending, string(initializer))
+	}
+	message := fmt.Sprintf("Waiting for initializers: %s", strings.Join(pending, ", "))
+
+	// a changed message means an initializer has been cleared, which resets the timeout.
+	condition := conditions.Get(workspace, tenancyv1alpha1.WorkspaceInitialized)
+	if condition == nil || condition.Message != message {
+		conditions.MarkFalseObserved(workspace, tenancyv1alpha1.WorkspaceInitialized, tenancyv1alpha1.WorkspaceInitializedReasonInitializersPending, conditionsv1alpha1.ConditionSeverityInfo, "%s", message)
+		c.enqueueAfter(workspace, c.initializerTimeout)
+		return
+	}
+	if condition.Reason != tenancyv1alpha1.WorkspaceInitializedReasonInitializersPending {
+		return
+	}
+
+	if waiting := time.Since(condition.LastTransitionTime.Time); waiting < c.initializerTimeout {
+		c.enqueueAfter(workspace, c.initializerTimeout-waiting)
+		return
+	}
+	logging.WithClusterWorkspace(logging.NewLogger(controllerName), workspace).Info("Initializers did not make progress", "initializers", pending, "timeout", c.initializerTimeout)
+	conditions.MarkFalseObserved(workspace, tenancyv1alpha1.WorkspaceInitialized, tenancyv1alpha1.WorkspaceInitializedReasonInitializerTimeout, conditionsv1alpha1.ConditionSeverityWarning, "%s", message)
+}
+
+// reconcileInitializerFailures drops the failures of initializers that are no longer pending, and reports the
+// remaining ones in the InitializersHealthy condition.
+func reconcileInitializerFailures(workspace *tenancyv1alpha1.ClusterWorkspace) {
+	pending := sets.NewString()
+	for _, initializer := range workspace.Status.Initializers {
+		pending.Insert(string(initializer))
+	}
+
+	var failures []tenancyv1alpha1.ClusterWorkspaceInitializerFailure
+	var messages []string
+	for _, failure := range workspace.Status.InitializerFailures {
+		if !pending.Has(string(failure.Initializer)) {
+			continue
+		}
+		failures = append(failures, failure)
+		messages = append(messages, fmt.Sprintf("initializer %s failed %d times: %s", failure.Initializer, failure.Failures, failure.LastError))
+	}
+	workspace.Status.InitializerFailures = failures
+
+	if len(messages) == 0 {
+		conditions.MarkTrueObserved(workspace, tenancyv1alpha1.WorkspaceInitializersHealthy)
+		return
+	}
+	conditions.MarkFalseObserved(workspace, tenancyv1alpha1.WorkspaceInitializersHealthy, tenancyv1alpha1.WorkspaceInitializersHealthyReasonInitializerFailed, conditionsv1alpha1.ConditionSeverityWarning, "%s", strings.Join(messages, "; "))
+}
+
 func isValidShard(shard *tenancyv1alpha1.ClusterWorkspaceShard) (valid bool, reason, message string) {
 	return true, "", ""
 }
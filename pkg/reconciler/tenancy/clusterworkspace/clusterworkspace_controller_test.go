@@ -0,0 +1,157 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterworkspace
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/workqueue"
+
+	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+	conditionsv1alpha1 "github.com/kcp-dev/kcp/third_party/conditions/apis/conditions/v1alpha1"
+	"github.com/kcp-dev/kcp/third_party/conditions/util/conditions"
+)
+
+func TestReconcileInitializerProgress(t *testing.T) {
+	const message = "Waiting for initializers: a, b"
+
+	tests := []struct {
+		name       string
+		condition  *conditionsv1alpha1.Condition
+		wantReason string
+	}{
+		{
+			name:       "initializers pending",
+			wantReason: tenancyv1alpha1.WorkspaceInitializedReasonInitializersPending,
+		},
+		{
+			name: "initializers pending within timeout",
+			condition: &conditionsv1alpha1.Condition{
+				Type:               tenancyv1alpha1.WorkspaceInitialized,
+				Status:             "False",
+				Reason:             tenancyv1alpha1.WorkspaceInitializedReasonInitializersPending,
+				Message:            message,
+				LastTransitionTime: metav1.NewTime(time.Now().Add(-time.Minute)),
+			},
+			wantReason: tenancyv1alpha1.WorkspaceInitializedReasonInitializersPending,
+		},
+		{
+			name: "initializers stuck",
+			condition: &conditionsv1alpha1.Condition{
+				Type:               tenancyv1alpha1.WorkspaceInitialized,
+				Status:             "False",
+				Reason:             tenancyv1alpha1.WorkspaceInitializedReasonInitializersPending,
+				Message:            message,
+				LastTransitionTime: metav1.NewTime(time.Now().Add(-time.Hour)),
+			},
+			wantReason: tenancyv1alpha1.WorkspaceInitializedReasonInitializerTimeout,
+		},
+		{
+			name: "initializer cleared after timeout",
+			condition: &conditionsv1alpha1.Condition{
+				Type:               tenancyv1alpha1.WorkspaceInitialized,
+				Status:             "False",
+				Reason:             tenancyv1alpha1.WorkspaceInitializedReasonInitializerTimeout,
+				Message:            "Waiting for initializers: x, a, b",
+				LastTransitionTime: metav1.NewTime(time.Now().Add(-time.Hour)),
+			},
+			wantReason: tenancyv1alpha1.WorkspaceInitializedReasonInitializersPending,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := &Controller{
+				queue:              workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter()),
+				initializerTimeout: 10 * time.Minute,
+			}
+			defer c.queue.ShutDown()
+
+			workspace := &tenancyv1alpha1.ClusterWorkspace{
+				ObjectMeta: metav1.ObjectMeta{Name: "test"},
+				Status: tenancyv1alpha1.ClusterWorkspaceStatus{
+					Phase:        tenancyv1alpha1.ClusterWorkspacePhaseInitializing,
+					Initializers: []tenancyv1alpha1.ClusterWorkspaceInitializer{"a", "b"},
+				},
+			}
+			if tt.condition != nil {
+				workspace.Status.Conditions = conditionsv1alpha1.Conditions{*tt.condition}
+			}
+
+			c.reconcileInitializerProgress(workspace)
+
+			require.True(t, conditions.IsFalse(workspace, tenancyv1alpha1.WorkspaceInitialized))
+			require.Equal(t, tt.wantReason, conditions.GetReason(workspace, tenancyv1alpha1.WorkspaceInitialized))
+			require.Equal(t, message, conditions.GetMessage(workspace, tenancyv1alpha1.WorkspaceInitialized))
+		})
+	}
+}
+
+func TestReconcileInitializerFailures(t *testing.T) {
+	failure := func(initializer tenancyv1alpha1.ClusterWorkspaceInitializer) tenancyv1alpha1.ClusterWorkspaceInitializerFailure {
+		return tenancyv1alpha1.ClusterWorkspaceInitializerFailure{Initializer: initializer, Failures: 3, LastError: "boom"}
+	}
+	workspace := &tenancyv1alpha1.ClusterWorkspace{
+		Status: tenancyv1alpha1.ClusterWorkspaceStatus{
+			Phase:               tenancyv1alpha1.ClusterWorkspacePhaseInitializing,
+			Initializers:        []tenancyv1alpha1.ClusterWorkspaceInitializer{"a", "b"},
+			InitializerFailures: []tenancyv1alpha1.ClusterWorkspaceInitializerFailure{failure("a"), failure("cleared")},
+		},
+	}
+
+	reconcileInitializerFailures(workspace)
+	require.Equal(t, []tenancyv1alpha1.ClusterWorkspaceInitializerFailure{failure("a")}, workspace.Status.InitializerFailures, "failures of cleared initializers are dropped")
+	require.True(t, conditions.IsFalse(workspace, tenancyv1alpha1.WorkspaceInitializersHealthy))
+	require.Equal(t, tenancyv1alpha1.WorkspaceInitializersHealthyReasonInitializerFailed, conditions.GetReason(workspace, tenancyv1alpha1.WorkspaceInitializersHealthy))
+	require.Equal(t, "initializer a failed 3 times: boom", conditions.GetMessage(workspace, tenancyv1alpha1.WorkspaceInitializersHealthy))
+
+	workspace.Status.Initializers = []tenancyv1alpha1.ClusterWorkspaceInitializer{"b"}
+	reconcileInitializerFailures(workspace)
+	require.Empty(t, workspace.Status.InitializerFailures)
+	require.True(t, conditions.IsTrue(workspace, tenancyv1alpha1.WorkspaceInitializersHealthy))
+}
+
+func TestReconcileTimeline(t *testing.T) {
+	c := &Controller{
+		queue:              workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter()),
+		initializerTimeout: 10 * time.Minute,
+	}
+	defer c.queue.ShutDown()
+
+	workspace := &tenancyv1alpha1.ClusterWorkspace{
+		ObjectMeta: metav1.ObjectMeta{Name: "test"},
+		Status: tenancyv1alpha1.ClusterWorkspaceStatus{
+			Phase:        tenancyv1alpha1.ClusterWorkspacePhaseInitializing,
+			Initializers: []tenancyv1alpha1.ClusterWorkspaceInitializer{"a"},
+		},
+	}
+	require.NoError(t, c.reconcile(context.Background(), workspace))
+	require.Equal(t, tenancyv1alpha1.ClusterWorkspacePhaseInitializing, workspace.Status.Phase)
+	require.Nil(t, workspace.Status.Timeline)
+
+	workspace.Status.Initializers = nil
+	require.NoError(t, c.reconcile(context.Background(), workspace))
+	require.Equal(t, tenancyv1alpha1.ClusterWorkspacePhaseReady, workspace.Status.Phase)
+	require.True(t, conditions.IsTrue(workspace, tenancyv1alpha1.WorkspaceInitialized))
+	require.NotNil(t, workspace.Status.Timeline)
+	require.NotNil(t, workspace.Status.Timeline.Initialized)
+	require.NotNil(t, workspace.Status.Timeline.Ready)
+}
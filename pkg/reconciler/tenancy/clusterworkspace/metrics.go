@@ -0,0 +1,46 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterworkspace
+
+import (
+	"sync"
+
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+)
+
+var (
+	workspaceReadyDuration = metrics.NewHistogramVec(
+		&metrics.HistogramOpts{
+			Namespace:      "kcp",
+			Name:           "workspace_ready_duration_seconds",
+			Help:           "Time from the creation of a ClusterWorkspace until it is ready, by workspace type.",
+			Buckets:        []float64{0.5, 1, 2.5, 5, 10, 30, 60, 120, 300, 600, 1800},
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"type"},
+	)
+)
+
+var registerMetrics sync.Once
+
+// RegisterMetrics registers the ClusterWorkspace controller metrics.
+func RegisterMetrics() {
+	registerMetrics.Do(func() {
+		legacyregistry.MustRegister(workspaceReadyDuration)
+	})
+}
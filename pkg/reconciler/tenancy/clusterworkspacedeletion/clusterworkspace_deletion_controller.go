@@ -41,16 +41,21 @@ import (
 	kcpclient "github.com/kcp-dev/kcp/pkg/client/clientset/versioned"
 	tenancyinformer "github.com/kcp-dev/kcp/pkg/client/informers/externalversions/tenancy/v1alpha1"
 	tenancylister "github.com/kcp-dev/kcp/pkg/client/listers/tenancy/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/logging"
 	"github.com/kcp-dev/kcp/pkg/reconciler/tenancy/clusterworkspacedeletion/deletion"
 )
 
+const (
+	controllerName = "workspace-deletion"
+)
+
 func NewController(
 	kcpClient kcpclient.ClusterInterface,
 	metadataClient metadata.Interface,
 	workspaceInformer tenancyinformer.ClusterWorkspaceInformer,
 	discoverResourcesFn func(clusterName logicalcluster.Name) ([]*metav1.APIResourceList, error),
 ) *Controller {
-	queue := workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "workspace-deletion")
+	queue := workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), controllerName)
 
 	c := &Controller{
 		queue:           queue,
@@ -84,7 +89,7 @@ func (c *Controller) enqueue(obj interface{}) {
 		runtime.HandleError(err)
 		return
 	}
-	klog.Infof("Queueing workspace %q", key)
+	logging.WithQueueKey(logging.NewLogger(controllerName), key).Info("Queueing workspace")
 	c.queue.Add(key)
 }
 
@@ -119,7 +124,10 @@ func (c *Controller) processNextWorkItem(ctx context.Context) bool {
 	}
 	key := k.(string)
 
-	klog.Infof("processing key %q", key)
+	logger := logging.WithQueueKey(logging.NewLogger(controllerName), key)
+	ctx = logging.NewContext(ctx, logger)
+
+	logger.Info("Processing")
 
 	// No matter what, tell the queue we're done with this key, to unblock
 	// other workers.
@@ -136,7 +144,7 @@ func (c *Controller) processNextWorkItem(ctx context.Context) bool {
 	var estimate *deletion.ResourcesRemainingError
 	if errors.As(err, &estimate) {
 		t := estimate.Estimate/2 + 1
-		klog.V(2).Infof("Content remaining in workspace %s, waiting %d seconds", key, t)
+		logger.V(2).Info("Content remaining in workspace", "waitSeconds", t)
 		c.queue.AddAfter(key, time.Duration(t)*time.Second)
 	} else {
 		// rather than wait for a full resync, re-add the workspace to the queue to be processed
@@ -148,15 +156,16 @@ func (c *Controller) processNextWorkItem(ctx context.Context) bool {
 }
 
 func (c *Controller) process(ctx context.Context, key string) error {
+	logger := logging.FromContext(ctx)
 	startTime := time.Now()
 
 	defer func() {
-		klog.V(4).Infof("Finished syncing workspace %q (%v)", key, time.Since(startTime))
+		logger.V(4).Info("Finished syncing workspace", "duration", time.Since(startTime))
 	}()
 
 	workspace, err := c.workspaceLister.Get(key)
 	if apierrors.IsNotFound(err) {
-		klog.Infof("Workspace has been deleted %v", key)
+		logger.Info("Workspace has been deleted")
 		return nil
 	}
 	if err != nil {
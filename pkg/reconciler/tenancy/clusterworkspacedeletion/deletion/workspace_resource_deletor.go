@@ -37,6 +37,7 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+	"sync"
 
 	"github.com/kcp-dev/logicalcluster"
 
@@ -48,6 +49,7 @@ import (
 	genericapirequest "k8s.io/apiserver/pkg/endpoints/request"
 	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/metadata"
+	"k8s.io/client-go/util/workqueue"
 	"k8s.io/klog/v2"
 
 	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
@@ -57,6 +59,9 @@ import (
 
 const (
 	WorkspaceFinalizer = "tenancy.kcp.dev/workspace-finalizer"
+
+	// deletionWorkers bounds the number of resources of a workspace that are cleaned up in parallel.
+	deletionWorkers = 10
 )
 
 // WorkspaceResourcesDeleterInterface is the interface to delete a workspace with all resources in it.
@@ -75,6 +80,7 @@ func NewWorkspacedResourcesDeleter(
 	d := &workspacedResourcesDeleter{
 		metadataClient:      metadataClient,
 		discoverResourcesFn: discoverResourcesFn,
+		workers:             deletionWorkers,
 	}
 	return d
 }
@@ -87,6 +93,9 @@ type workspacedResourcesDeleter struct {
 	metadataClient metadata.Interface
 
 	discoverResourcesFn func(clusterName logicalcluster.Name) ([]*metav1.APIResourceList, error)
+
+	// workers is the number of resources deleted in parallel.
+	workers int
 }
 
 // Delete deletes all resources in the given workspace.
@@ -396,13 +405,35 @@ func (d *workspacedResourcesDeleter) deleteAllContent(ctx context.Context, ws *t
 		finalizersToNumRemaining: map[string]int{},
 	}
 	deleteContentErrs := []error{}
-	for gvr, verbs := range groupVersionResources {
-		gvrDeletionMetadata, err := d.deleteAllContentForGroupVersionResource(ctx, wsClusterName, gvr, verbs, workspaceDeletedAt)
+
+	gvrs := make([]schema.GroupVersionResource, 0, len(groupVersionResources))
+	for gvr := range groupVersionResources {
+		gvrs = append(gvrs, gvr)
+	}
+	sort.Slice(gvrs, func(i, j int) bool { return gvrs[i].String() < gvrs[j].String() })
+
+	// delete the content of the resources in parallel, bounded by the number of workers. The results
+	// are stored by index and aggregated below.
+	var lock sync.Mutex
+	results := make([]gvrDeletionMetadata, len(gvrs))
+	workqueue.ParallelizeUntil(ctx, d.workers, len(gvrs), func(i int) {
+		gvr := gvrs[i]
+		gvrDeletionMetadata, err := d.deleteAllContentForGroupVersionResource(ctx, wsClusterName, gvr, groupVersionResources[gvr], workspaceDeletedAt)
+		results[i] = gvrDeletionMetadata
 		if err != nil {
 			// If there is an error, hold on to it but proceed with all the remaining
 			// groupVersionResources.
+			lock.Lock()
 			deleteContentErrs = append(deleteContentErrs, err)
+			lock.Unlock()
 		}
+	})
+	if err := ctx.Err(); err != nil {
+		deleteContentErrs = append(deleteContentErrs, err)
+	}
+
+	for i, gvr := range gvrs {
+		gvrDeletionMetadata := results[i]
 		if gvrDeletionMetadata.finalizerEstimateSeconds > estimate {
 			estimate = gvrDeletionMetadata.finalizerEstimateSeconds
 		}
@@ -476,10 +507,48 @@ func (d *workspacedResourcesDeleter) deleteAllContent(ctx context.Context, ws *t
 		conditions.MarkTrue(ws, tenancyv1alpha1.WorkspaceContentDeleted)
 	}
 
+	if total, remainingByGroup := remainingResourcesByGroup(numRemainingTotals.gvrToNumRemaining); total > 0 {
+		conditions.MarkFalse(
+			ws,
+			tenancyv1alpha1.WorkspaceDeletionProgress,
+			tenancyv1alpha1.WorkspaceDeletionProgressReasonResourcesRemaining,
+			conditionsv1alpha1.ConditionSeverityInfo,
+			"%d resource instances remaining: %s", total, strings.Join(remainingByGroup, ", "),
+		)
+	} else {
+		conditions.MarkTrue(ws, tenancyv1alpha1.WorkspaceDeletionProgress)
+	}
+
 	klog.V(4).Infof("workspace deletion controller - deleteAllContent - workspace: %s, estimate: %v, errors: %v", wsClusterName, estimate, utilerrors.NewAggregate(errs))
 	return estimate, utilerrors.NewAggregate(errs)
 }
 
+// remainingResourcesByGroup sums up the remaining resource instances by API group. It returns the total and
+// a sorted, human-readable list of the counts per group.
+func remainingResourcesByGroup(gvrToNumRemaining map[schema.GroupVersionResource]int) (int, []string) {
+	total := 0
+	groupToNumRemaining := map[string]int{}
+	for gvr, numRemaining := range gvrToNumRemaining {
+		total += numRemaining
+		groupToNumRemaining[gvr.Group] += numRemaining
+	}
+
+	remaining := make([]string, 0, len(groupToNumRemaining))
+	for group, numRemaining := range groupToNumRemaining {
+		if numRemaining == 0 {
+			continue
+		}
+		if group == "" {
+			group = "core"
+		}
+		remaining = append(remaining, fmt.Sprintf("%s has %d", group, numRemaining))
+	}
+	// sort for stable updates
+	sort.Strings(remaining)
+
+	return total, remaining
+}
+
 // estimateGracefulTermination will estimate the graceful termination required for the specific entity in the workspace
 func (d *workspacedResourcesDeleter) estimateGracefulTermination(gvr schema.GroupVersionResource, ws logicalcluster.Name, workspaceDeletedAt metav1.Time) (int64, error) {
 	groupResource := gvr.GroupResource()
@@ -19,6 +19,7 @@ package deletion
 import (
 	"context"
 	"fmt"
+	"reflect"
 	"testing"
 
 	"github.com/kcp-dev/logicalcluster"
@@ -26,6 +27,7 @@ import (
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	metadatafake "k8s.io/client-go/metadata/fake"
 	clienttesting "k8s.io/client-go/testing"
@@ -81,6 +83,10 @@ func TestWorkspaceTerminating(t *testing.T) {
 					Type:   tenancyv1alpha1.WorkspaceContentDeleted,
 					Status: v1.ConditionTrue,
 				},
+				{
+					Type:   tenancyv1alpha1.WorkspaceDeletionProgress,
+					Status: v1.ConditionTrue,
+				},
 			},
 		},
 		{
@@ -107,6 +113,10 @@ func TestWorkspaceTerminating(t *testing.T) {
 					Type:   tenancyv1alpha1.WorkspaceContentDeleted,
 					Status: v1.ConditionFalse,
 				},
+				{
+					Type:   tenancyv1alpha1.WorkspaceDeletionProgress,
+					Status: v1.ConditionFalse,
+				},
 			},
 		},
 		{
@@ -132,6 +142,10 @@ func TestWorkspaceTerminating(t *testing.T) {
 					Type:   tenancyv1alpha1.WorkspaceContentDeleted,
 					Status: v1.ConditionFalse,
 				},
+				{
+					Type:   tenancyv1alpha1.WorkspaceDeletionProgress,
+					Status: v1.ConditionFalse,
+				},
 			},
 		},
 	}
@@ -172,6 +186,21 @@ func TestWorkspaceTerminating(t *testing.T) {
 	}
 }
 
+func TestRemainingResourcesByGroup(t *testing.T) {
+	total, remaining := remainingResourcesByGroup(map[schema.GroupVersionResource]int{
+		{Version: "v1", Resource: "secrets"}:                    3,
+		{Version: "v1", Resource: "configmaps"}:                 2,
+		{Group: "apps", Version: "v1", Resource: "deployments"}: 4,
+		{Group: "apps", Version: "v1", Resource: "replicasets"}: 0,
+	})
+	if total != 9 {
+		t.Errorf("expected 9 remaining resources, got %d", total)
+	}
+	if expected := []string{"apps has 4", "core has 5"}; !reflect.DeepEqual(remaining, expected) {
+		t.Errorf("expected %v, got %v", expected, remaining)
+	}
+}
+
 type metaAction struct {
 	resource string
 	verb     string
@@ -38,6 +38,7 @@ import (
 	kcpclient "github.com/kcp-dev/kcp/pkg/client/clientset/versioned"
 	tenancyinformer "github.com/kcp-dev/kcp/pkg/client/informers/externalversions/tenancy/v1alpha1"
 	tenancylister "github.com/kcp-dev/kcp/pkg/client/listers/tenancy/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/logging"
 )
 
 const (
@@ -82,7 +83,7 @@ func (c *Controller) enqueue(obj interface{}) {
 		runtime.HandleError(err)
 		return
 	}
-	klog.Infof("queueing workspace shard %q", key)
+	logging.WithQueueKey(logging.NewLogger(controllerName), key).Info("Queueing workspace shard")
 	c.queue.Add(key)
 }
 
@@ -113,7 +114,10 @@ func (c *Controller) processNextWorkItem(ctx context.Context) bool {
 	}
 	key := k.(string)
 
-	klog.Infof("processing key %q", key)
+	logger := logging.WithQueueKey(logging.NewLogger(controllerName), key)
+	ctx = logging.NewContext(ctx, logger)
+
+	logger.Info("Processing")
 
 	// No matter what, tell the queue we're done with this key, to unblock
 	// other workers.
@@ -131,11 +135,11 @@ func (c *Controller) processNextWorkItem(ctx context.Context) bool {
 func (c *Controller) process(ctx context.Context, key string) error {
 	namespace, name, err := cache.SplitMetaNamespaceKey(key)
 	if err != nil {
-		klog.Errorf("invalid key: %q: %v", key, err)
+		logging.FromContext(ctx).Error(err, "Invalid key")
 		return nil
 	}
 	if namespace != "" {
-		klog.Errorf("namespace %q found in key for cluster-wide ClusterWorkspaceShard object", namespace)
+		logging.FromContext(ctx).Error(nil, "Namespace found in key for cluster-wide ClusterWorkspaceShard object", "namespace", namespace)
 		return nil
 	}
 
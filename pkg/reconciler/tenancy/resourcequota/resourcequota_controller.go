@@ -0,0 +1,256 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resourcequota
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/kcp-dev/logicalcluster"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+	coreinformers "k8s.io/client-go/informers/core/v1"
+	"k8s.io/client-go/kubernetes"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/clusters"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+
+	"github.com/kcp-dev/kcp/pkg/logging"
+)
+
+const (
+	controllerName = "kcp-resourcequota"
+
+	// objectCountPrefix is the prefix of the quota resource names counting objects,
+	// i.e. count/<resource>.<group>, or count/<resource> for the core group.
+	objectCountPrefix = "count/"
+)
+
+// NewController returns a controller calculating the object count usage of ResourceQuotas
+// in all workspaces. The usage is recalculated every resyncPeriod, catching up with deleted
+// objects and with objects charged by admission that failed to be created.
+func NewController(
+	kubeClusterClient kubernetes.ClusterInterface,
+	metadataClusterClient dynamic.ClusterInterface,
+	resourceQuotaInformer coreinformers.ResourceQuotaInformer,
+	resyncPeriod time.Duration,
+) *Controller {
+	c := &Controller{
+		queue: workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), controllerName),
+
+		kubeClusterClient:   kubeClusterClient,
+		resourceQuotaLister: resourceQuotaInformer.Lister(),
+		resyncPeriod:        resyncPeriod,
+
+		getPreferredVersions: func(clusterName logicalcluster.Name) (map[string]string, error) {
+			groups, err := kubeClusterClient.Cluster(clusterName).Discovery().ServerGroups()
+			if err != nil {
+				return nil, err
+			}
+			versions := make(map[string]string, len(groups.Groups))
+			for _, group := range groups.Groups {
+				versions[group.Name] = group.PreferredVersion.Version
+			}
+			return versions, nil
+		},
+		countObjects: func(ctx context.Context, clusterName logicalcluster.Name, namespace string, gvr schema.GroupVersionResource) (int64, error) {
+			list, err := metadataClusterClient.Cluster(clusterName).Resource(gvr).Namespace(namespace).List(ctx, metav1.ListOptions{})
+			if err != nil {
+				return 0, err
+			}
+			return int64(len(list.Items)), nil
+		},
+	}
+
+	resourceQuotaInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) { c.enqueue(obj) },
+		UpdateFunc: func(oldObj, obj interface{}) {
+			old := oldObj.(*corev1.ResourceQuota)
+			quota := obj.(*corev1.ResourceQuota)
+			// status updates are ours or admission's, no need to recalculate
+			if equality.Semantic.DeepEqual(old.Spec, quota.Spec) {
+				return
+			}
+			c.enqueue(obj)
+		},
+	})
+
+	return c
+}
+
+// Controller maintains the status of ResourceQuotas, i.e. the enforced hard object count
+// limits and the number of objects they count.
+type Controller struct {
+	queue workqueue.RateLimitingInterface
+
+	kubeClusterClient   kubernetes.ClusterInterface
+	resourceQuotaLister corelisters.ResourceQuotaLister
+	resyncPeriod        time.Duration
+
+	getPreferredVersions func(clusterName logicalcluster.Name) (map[string]string, error)
+	countObjects         func(ctx context.Context, clusterName logicalcluster.Name, namespace string, gvr schema.GroupVersionResource) (int64, error)
+}
+
+func (c *Controller) enqueue(obj interface{}) {
+	key, err := cache.MetaNamespaceKeyFunc(obj)
+	if err != nil {
+		runtime.HandleError(err)
+		return
+	}
+	logging.WithQueueKey(logging.NewLogger(controllerName), key).V(2).Info("Queueing ResourceQuota")
+	c.queue.Add(key)
+}
+
+func (c *Controller) Start(ctx context.Context, numThreads int) {
+	defer runtime.HandleCrash()
+	defer c.queue.ShutDown()
+
+	klog.Infof("Starting %s controller", controllerName)
+	defer klog.Infof("Shutting down %s controller", controllerName)
+
+	for i := 0; i < numThreads; i++ {
+		go wait.UntilWithContext(ctx, c.startWorker, time.Second)
+	}
+
+	<-ctx.Done()
+}
+
+func (c *Controller) startWorker(ctx context.Context) {
+	for c.processNextWorkItem(ctx) {
+	}
+}
+
+func (c *Controller) processNextWorkItem(ctx context.Context) bool {
+	// Wait until there is a new item in the working queue
+	k, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+	key := k.(string)
+
+	logger := logging.WithQueueKey(logging.NewLogger(controllerName), key)
+	ctx = logging.NewContext(ctx, logger)
+
+	// No matter what, tell the queue we're done with this key, to unblock
+	// other workers.
+	defer c.queue.Done(key)
+
+	if err := c.process(ctx, key); err != nil {
+		runtime.HandleError(fmt.Errorf("%s: failed to sync %q, err: %w", controllerName, key, err))
+		c.queue.AddRateLimited(key)
+		return true
+	}
+	c.queue.Forget(key)
+	return true
+}
+
+func (c *Controller) process(ctx context.Context, key string) error {
+	namespace, clusterAwareName, err := cache.SplitMetaNamespaceKey(key)
+	if err != nil {
+		runtime.HandleError(err)
+		return nil
+	}
+
+	quota, err := c.resourceQuotaLister.ResourceQuotas(namespace).Get(clusterAwareName)
+	if errors.IsNotFound(err) {
+		return nil // object deleted before we handled it
+	} else if err != nil {
+		return err
+	}
+	clusterName, _ := clusters.SplitClusterAwareKey(clusterAwareName)
+
+	status, err := c.calculateStatus(ctx, clusterName, quota)
+	if err != nil {
+		return err
+	}
+	if !equality.Semantic.DeepEqual(quota.Status, status) {
+		quota = quota.DeepCopy()
+		quota.Status = status
+		logging.FromContext(ctx).V(3).Info("Updating usage of ResourceQuota", "used", status.Used)
+		if _, err := c.kubeClusterClient.Cluster(clusterName).CoreV1().ResourceQuotas(namespace).UpdateStatus(ctx, quota, metav1.UpdateOptions{}); err != nil {
+			return err
+		}
+	}
+
+	c.queue.AddAfter(key, c.resyncPeriod)
+	return nil
+}
+
+// calculateStatus returns the status of the given quota with the object count limits as hard
+// limits, and the number of objects in the namespace of the quota as usage. Unknown resources
+// have no objects.
+func (c *Controller) calculateStatus(ctx context.Context, clusterName logicalcluster.Name, quota *corev1.ResourceQuota) (corev1.ResourceQuotaStatus, error) {
+	status := corev1.ResourceQuotaStatus{
+		Hard: corev1.ResourceList{},
+		Used: corev1.ResourceList{},
+	}
+
+	var versions map[string]string
+	for name, hard := range quota.Spec.Hard {
+		gr, ok := objectCountResource(name)
+		if !ok {
+			continue
+		}
+		status.Hard[name] = hard
+
+		if versions == nil {
+			var err error
+			if versions, err = c.getPreferredVersions(clusterName); err != nil {
+				return corev1.ResourceQuotaStatus{}, err
+			}
+		}
+		version, found := versions[gr.Group]
+		if !found {
+			status.Used[name] = *resource.NewQuantity(0, resource.DecimalSI)
+			continue
+		}
+		count, err := c.countObjects(ctx, clusterName, quota.Namespace, gr.WithVersion(version))
+		if errors.IsNotFound(err) {
+			count = 0
+		} else if err != nil {
+			return corev1.ResourceQuotaStatus{}, err
+		}
+		status.Used[name] = *resource.NewQuantity(count, resource.DecimalSI)
+	}
+
+	return status, nil
+}
+
+// objectCountResource returns the resource counted by the given quota resource name, and whether
+// it is an object count at all.
+func objectCountResource(name corev1.ResourceName) (schema.GroupResource, bool) {
+	if !strings.HasPrefix(string(name), objectCountPrefix) {
+		return schema.GroupResource{}, false
+	}
+	gr := schema.ParseGroupResource(strings.TrimPrefix(string(name), objectCountPrefix))
+	if gr.Resource == "" {
+		return schema.GroupResource{}, false
+	}
+	return gr, true
+}
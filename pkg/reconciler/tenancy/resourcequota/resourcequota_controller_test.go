@@ -0,0 +1,104 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resourcequota
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kcp-dev/logicalcluster"
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func TestCalculateStatus(t *testing.T) {
+	versions := map[string]string{"": "v1", "example.com": "v1alpha1", "missing.com": "v1"}
+	counts := map[schema.GroupVersionResource]int64{
+		{Version: "v1", Resource: "configmaps"}:                          3,
+		{Group: "example.com", Version: "v1alpha1", Resource: "widgets"}: 5,
+	}
+
+	c := &Controller{
+		getPreferredVersions: func(clusterName logicalcluster.Name) (map[string]string, error) {
+			require.Equal(t, "root:org:ws", clusterName.String())
+			return versions, nil
+		},
+		countObjects: func(ctx context.Context, clusterName logicalcluster.Name, namespace string, gvr schema.GroupVersionResource) (int64, error) {
+			require.Equal(t, "default", namespace)
+			count, found := counts[gvr]
+			if !found {
+				return 0, apierrors.NewNotFound(gvr.GroupResource(), "")
+			}
+			return count, nil
+		},
+	}
+
+	quota := &corev1.ResourceQuota{
+		ObjectMeta: metav1.ObjectMeta{Name: "quota", Namespace: "default"},
+		Spec: corev1.ResourceQuotaSpec{
+			Hard: corev1.ResourceList{
+				"count/configmaps":          resource.MustParse("10"),
+				"count/widgets.example.com": resource.MustParse("4"),
+				"count/gadgets.missing.com": resource.MustParse("1"),
+				"count/things.unknown.com":  resource.MustParse("1"),
+				corev1.ResourcePods:         resource.MustParse("2"),
+			},
+		},
+	}
+
+	status, err := c.calculateStatus(context.Background(), logicalcluster.New("root:org:ws"), quota)
+	require.NoError(t, err)
+
+	require.NotContains(t, status.Hard, corev1.ResourcePods, "only object counts are enforced")
+	require.Len(t, status.Hard, 4)
+
+	expected := map[corev1.ResourceName]string{
+		"count/configmaps":          "3",
+		"count/widgets.example.com": "5",
+		"count/gadgets.missing.com": "0",
+		"count/things.unknown.com":  "0",
+	}
+	require.Len(t, status.Used, len(expected))
+	for name, value := range expected {
+		used := status.Used[name]
+		require.Equal(t, value, used.String(), "unexpected usage of %s", name)
+	}
+}
+
+func TestObjectCountResource(t *testing.T) {
+	tests := []struct {
+		name     corev1.ResourceName
+		expected schema.GroupResource
+		ok       bool
+	}{
+		{name: "count/configmaps", expected: schema.GroupResource{Resource: "configmaps"}, ok: true},
+		{name: "count/widgets.example.com", expected: schema.GroupResource{Group: "example.com", Resource: "widgets"}, ok: true},
+		{name: "count/"},
+		{name: "pods"},
+		{name: "requests.cpu"},
+	}
+	for _, tc := range tests {
+		gr, ok := objectCountResource(tc.name)
+		require.Equal(t, tc.ok, ok, "unexpected result for %s", tc.name)
+		require.Equal(t, tc.expected, gr, "unexpected resource for %s", tc.name)
+	}
+}
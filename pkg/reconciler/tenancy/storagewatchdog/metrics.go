@@ -0,0 +1,45 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storagewatchdog
+
+import (
+	"sync"
+
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+)
+
+var (
+	workspaceObjects = metrics.NewHistogram(
+		&metrics.HistogramOpts{
+			Namespace:      "kcp",
+			Name:           "workspace_objects",
+			Help:           "Number of objects in the workspaces, observed on every scan of a workspace. The number of a single workspace is in the status of its ClusterWorkspace.",
+			Buckets:        metrics.ExponentialBuckets(10, 10, 6),
+			StabilityLevel: metrics.ALPHA,
+		},
+	)
+)
+
+var registerMetrics sync.Once
+
+// RegisterMetrics registers the storage watchdog metrics.
+func RegisterMetrics() {
+	registerMetrics.Do(func() {
+		legacyregistry.MustRegister(workspaceObjects)
+	})
+}
@@ -0,0 +1,199 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storagewatchdog
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/kcp-dev/logicalcluster"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/flowcontrol"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+
+	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+	kcpclient "github.com/kcp-dev/kcp/pkg/client/clientset/versioned"
+	tenancyinformers "github.com/kcp-dev/kcp/pkg/client/informers/externalversions/tenancy/v1alpha1"
+	tenancylisters "github.com/kcp-dev/kcp/pkg/client/listers/tenancy/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/logging"
+)
+
+const (
+	controllerName = "kcp-workspace-storage-watchdog"
+
+	// listPageSize is the page size of the lists of the objects of a workspace, if the server
+	// does not return the number of objects.
+	listPageSize = 500
+
+	// scanQPS and scanBurst limit the list requests of all scans together.
+	scanQPS   = 10
+	scanBurst = 20
+)
+
+// NewController returns a new controller that periodically counts the objects of every ready workspace
+// in storage, writes the number into the status of the ClusterWorkspace, and marks workspaces over the
+// configured limit with a false StorageWithinLimits condition. Creates in such workspaces are rejected
+// by admission.
+func NewController(
+	kcpClusterClient kcpclient.ClusterInterface,
+	dynamicClusterClient dynamic.ClusterInterface,
+	discoverResources func(clusterName logicalcluster.Name) ([]*metav1.APIResourceList, error),
+	clusterWorkspaceInformer tenancyinformers.ClusterWorkspaceInformer,
+	options Options,
+) *controller {
+	queue := workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), controllerName)
+
+	c := &controller{
+		queue:                  queue,
+		clusterWorkspaceLister: clusterWorkspaceInformer.Lister(),
+		discoverResources:      discoverResources,
+		listObjects: func(ctx context.Context, clusterName logicalcluster.Name, gvr schema.GroupVersionResource, opts metav1.ListOptions) (*unstructured.UnstructuredList, error) {
+			return dynamicClusterClient.Cluster(clusterName).Resource(gvr).List(ctx, opts)
+		},
+		patchClusterWorkspaceStatus: func(ctx context.Context, clusterName logicalcluster.Name, name string, patch []byte) error {
+			_, err := kcpClusterClient.Cluster(clusterName).TenancyV1alpha1().ClusterWorkspaces().Patch(ctx, name, types.MergePatchType, patch, metav1.PatchOptions{}, "status")
+			return err
+		},
+		scanRateLimiter: flowcontrol.NewTokenBucketRateLimiter(scanQPS, scanBurst),
+		options:         options,
+		now:             time.Now,
+	}
+
+	RegisterMetrics()
+
+	clusterWorkspaceInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj interface{}) { c.enqueue(obj) },
+		UpdateFunc: func(_, obj interface{}) { c.enqueue(obj) },
+	})
+
+	return c
+}
+
+// controller computes the usage of workspaces. Every workspace is scanned at most once per interval.
+type controller struct {
+	queue workqueue.RateLimitingInterface
+
+	clusterWorkspaceLister      tenancylisters.ClusterWorkspaceLister
+	discoverResources           func(clusterName logicalcluster.Name) ([]*metav1.APIResourceList, error)
+	listObjects                 func(ctx context.Context, clusterName logicalcluster.Name, gvr schema.GroupVersionResource, opts metav1.ListOptions) (*unstructured.UnstructuredList, error)
+	patchClusterWorkspaceStatus func(ctx context.Context, clusterName logicalcluster.Name, name string, patch []byte) error
+
+	scanRateLimiter flowcontrol.RateLimiter
+	options         Options
+	now             func() time.Time
+}
+
+func (c *controller) enqueue(obj interface{}) {
+	key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+	if err != nil {
+		runtime.HandleError(err)
+		return
+	}
+
+	logging.WithQueueKey(logging.NewLogger(controllerName), key).V(4).Info("Queueing ClusterWorkspace")
+	c.queue.Add(key)
+}
+
+// Start starts the controller, which stops when ctx.Done() is closed.
+func (c *controller) Start(ctx context.Context, numThreads int) {
+	defer runtime.HandleCrash()
+	defer c.queue.ShutDown()
+
+	klog.Infof("Starting %s controller", controllerName)
+	defer klog.Infof("Shutting down %s controller", controllerName)
+
+	for i := 0; i < numThreads; i++ {
+		go wait.UntilWithContext(ctx, c.startWorker, time.Second)
+	}
+
+	<-ctx.Done()
+}
+
+func (c *controller) startWorker(ctx context.Context) {
+	for c.processNextWorkItem(ctx) {
+	}
+}
+
+func (c *controller) processNextWorkItem(ctx context.Context) bool {
+	// Wait until there is a new item in the working queue
+	k, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+	key := k.(string)
+
+	logger := logging.WithQueueKey(logging.NewLogger(controllerName), key)
+	ctx = logging.NewContext(ctx, logger)
+
+	// No matter what, tell the queue we're done with this key, to unblock
+	// other workers.
+	defer c.queue.Done(key)
+
+	if err := c.process(ctx, key); err != nil {
+		runtime.HandleError(fmt.Errorf("%q controller failed to sync %q, err: %w", controllerName, key, err))
+		c.queue.AddRateLimited(key)
+		return true
+	}
+	c.queue.Forget(key)
+	return true
+}
+
+func (c *controller) process(ctx context.Context, key string) error {
+	workspace, err := c.clusterWorkspaceLister.Get(key)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil // object deleted before we handled it
+		}
+		return err
+	}
+
+	// only ready workspaces have content worth watching. We are requeued on the phase change.
+	if workspace.Status.Phase != tenancyv1alpha1.ClusterWorkspacePhaseReady || !workspace.DeletionTimestamp.IsZero() {
+		return nil
+	}
+
+	// scan at most once per interval, also when the ClusterWorkspace changes in between
+	if usage := workspace.Status.Usage; usage != nil {
+		if next := usage.LastUpdateTime.Add(c.options.Interval); c.now().Before(next) {
+			c.queue.AddAfter(key, next.Sub(c.now()))
+			return nil
+		}
+	}
+
+	if err := c.reconcile(ctx, workspace); err != nil {
+		return err
+	}
+
+	c.queue.AddAfter(key, c.options.Interval)
+	return nil
+}
+
+// workspaceClusterName returns the logical cluster of the content of the given ClusterWorkspace.
+func workspaceClusterName(workspace *tenancyv1alpha1.ClusterWorkspace) logicalcluster.Name {
+	return logicalcluster.From(workspace).Join(workspace.Name)
+}
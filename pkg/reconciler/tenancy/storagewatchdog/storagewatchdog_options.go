@@ -0,0 +1,54 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storagewatchdog
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+// DefaultInterval is the default minimal time between two scans of a workspace.
+const DefaultInterval = 10 * time.Minute
+
+func DefaultOptions() *Options {
+	return &Options{
+		Interval: DefaultInterval,
+	}
+}
+
+func BindOptions(o *Options, fs *pflag.FlagSet) *Options {
+	fs.DurationVar(&o.Interval, "workspace-storage-watchdog-interval", o.Interval, "Minimal time between two scans of the objects of a workspace for its usage status")
+	fs.Int64Var(&o.MaxObjects, "workspace-max-objects", o.MaxObjects, "Number of objects in a workspace above which creates in the workspace are rejected. 0 means no limit")
+	return o
+}
+
+type Options struct {
+	Interval   time.Duration
+	MaxObjects int64
+}
+
+func (o *Options) Validate() error {
+	if o.Interval <= 0 {
+		return fmt.Errorf("--workspace-storage-watchdog-interval must be >0 (%s)", o.Interval)
+	}
+	if o.MaxObjects < 0 {
+		return fmt.Errorf("--workspace-max-objects must be >=0 (%d)", o.MaxObjects)
+	}
+	return nil
+}
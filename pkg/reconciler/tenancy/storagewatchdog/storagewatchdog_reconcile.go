@@ -0,0 +1,135 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storagewatchdog
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/kcp-dev/logicalcluster"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+	conditionsv1alpha1 "github.com/kcp-dev/kcp/third_party/conditions/apis/conditions/v1alpha1"
+	"github.com/kcp-dev/kcp/third_party/conditions/util/conditions"
+)
+
+func (c *controller) reconcile(ctx context.Context, workspace *tenancyv1alpha1.ClusterWorkspace) error {
+	clusterName := workspaceClusterName(workspace)
+
+	usage, err := c.scan(ctx, clusterName)
+	if err != nil {
+		return fmt.Errorf("failed to scan workspace %s: %w", clusterName, err)
+	}
+	usage.LastUpdateTime = metav1.NewTime(c.now())
+
+	workspaceObjects.Observe(float64(usage.ObjectCount))
+
+	updated := workspace.DeepCopy()
+	c.updateCondition(updated, usage)
+
+	// the resourceVersion precondition makes sure conditions set concurrently by other controllers are not overwritten.
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"resourceVersion": workspace.ResourceVersion,
+		},
+		"status": map[string]interface{}{
+			"usage":      usage,
+			"conditions": updated.Status.Conditions,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	return c.patchClusterWorkspaceStatus(ctx, logicalcluster.From(workspace), workspace.Name, patch)
+}
+
+// updateCondition sets the StorageWithinLimits condition according to the given usage, or removes it
+// when no limit is configured.
+func (c *controller) updateCondition(workspace *tenancyv1alpha1.ClusterWorkspace, usage *tenancyv1alpha1.ClusterWorkspaceUsage) {
+	switch {
+	case c.options.MaxObjects > 0 && usage.ObjectCount > c.options.MaxObjects:
+		conditions.MarkFalse(workspace, tenancyv1alpha1.WorkspaceStorageWithinLimits, tenancyv1alpha1.WorkspaceStorageWithinLimitsReasonObjectCountExceeded, conditionsv1alpha1.ConditionSeverityError,
+			"The workspace has %d objects, more than the limit of %d. Creates are rejected until objects are deleted.", usage.ObjectCount, c.options.MaxObjects)
+	case c.options.MaxObjects > 0:
+		conditions.MarkTrue(workspace, tenancyv1alpha1.WorkspaceStorageWithinLimits)
+	default:
+		conditions.Delete(workspace, tenancyv1alpha1.WorkspaceStorageWithinLimits)
+	}
+}
+
+// scan counts the objects of all listable resources of the given logical cluster.
+func (c *controller) scan(ctx context.Context, clusterName logicalcluster.Name) (*tenancyv1alpha1.ClusterWorkspaceUsage, error) {
+	resourceLists, err := c.discoverResources(clusterName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover the resources: %w", err)
+	}
+
+	usage := &tenancyv1alpha1.ClusterWorkspaceUsage{}
+	for _, resourceList := range resourceLists {
+		gv, err := schema.ParseGroupVersion(resourceList.GroupVersion)
+		if err != nil {
+			return nil, err
+		}
+		for _, resource := range resourceList.APIResources {
+			if strings.Contains(resource.Name, "/") || !sets.NewString(resource.Verbs...).Has("list") {
+				continue
+			}
+			if err := c.scanResource(ctx, clusterName, gv.WithResource(resource.Name), usage); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return usage, nil
+}
+
+// scanResource adds the number of objects of the given resource in the given logical cluster to the usage.
+// The number is taken from the count of the storage range of the resource in the logical cluster, which
+// the server returns as remainingItemCount of a list with limit 1, such that the objects themselves are not
+// transferred. Only if the server does not return a count, the remaining objects are paged through.
+func (c *controller) scanResource(ctx context.Context, clusterName logicalcluster.Name, gvr schema.GroupVersionResource, usage *tenancyv1alpha1.ClusterWorkspaceUsage) error {
+	opts := metav1.ListOptions{Limit: 1}
+	for {
+		if err := c.scanRateLimiter.Wait(ctx); err != nil {
+			return err
+		}
+
+		list, err := c.listObjects(ctx, clusterName, gvr, opts)
+		if errors.IsNotFound(err) || errors.IsMethodNotSupported(err) {
+			return nil // the resource went away, or cannot be listed after all
+		}
+		if err != nil {
+			return fmt.Errorf("failed to list %s: %w", gvr, err)
+		}
+
+		usage.ObjectCount += int64(len(list.Items))
+		if list.GetContinue() == "" {
+			return nil
+		}
+		if remaining := list.GetRemainingItemCount(); remaining != nil {
+			usage.ObjectCount += *remaining
+			return nil
+		}
+		opts = metav1.ListOptions{Limit: listPageSize, Continue: list.GetContinue()}
+	}
+}
@@ -0,0 +1,170 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package storagewatchdog
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/kcp-dev/logicalcluster"
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/util/flowcontrol"
+
+	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+	conditionsv1alpha1 "github.com/kcp-dev/kcp/third_party/conditions/apis/conditions/v1alpha1"
+	"github.com/kcp-dev/kcp/third_party/conditions/util/conditions"
+)
+
+func TestReconcile(t *testing.T) {
+	now := time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	resources := []*metav1.APIResourceList{
+		{
+			GroupVersion: "v1",
+			APIResources: []metav1.APIResource{
+				{Name: "configmaps", Verbs: []string{"create", "get", "list"}},
+				{Name: "configmaps/status", Verbs: []string{"get", "list"}},
+				{Name: "bindings", Verbs: []string{"create"}},
+			},
+		},
+		{
+			GroupVersion: "example.io/v1",
+			APIResources: []metav1.APIResource{
+				{Name: "widgets", Verbs: []string{"get", "list"}},
+				{Name: "gadgets", Verbs: []string{"get", "list"}},
+			},
+		},
+	}
+	object := func(name string) unstructured.Unstructured {
+		return unstructured.Unstructured{Object: map[string]interface{}{"metadata": map[string]interface{}{"name": name}}}
+	}
+
+	tests := map[string]struct {
+		options       Options
+		withCondition bool
+
+		wantObjects   int64
+		wantCondition *conditionsv1alpha1.Condition
+	}{
+		"no limits": {
+			wantObjects: 44,
+		},
+		"no limits, stale condition is removed": {
+			withCondition: true,
+			wantObjects:   44,
+		},
+		"within limits": {
+			options:       Options{MaxObjects: 44},
+			wantObjects:   44,
+			wantCondition: &conditionsv1alpha1.Condition{Type: tenancyv1alpha1.WorkspaceStorageWithinLimits, Status: "True"},
+		},
+		"object count exceeded": {
+			options:     Options{MaxObjects: 43},
+			wantObjects: 44,
+			wantCondition: &conditionsv1alpha1.Condition{
+				Type:     tenancyv1alpha1.WorkspaceStorageWithinLimits,
+				Status:   "False",
+				Severity: conditionsv1alpha1.ConditionSeverityError,
+				Reason:   tenancyv1alpha1.WorkspaceStorageWithinLimitsReasonObjectCountExceeded,
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			workspace := &tenancyv1alpha1.ClusterWorkspace{
+				ObjectMeta: metav1.ObjectMeta{ClusterName: "root:org", Name: "ws", ResourceVersion: "42"},
+				Status:     tenancyv1alpha1.ClusterWorkspaceStatus{Phase: tenancyv1alpha1.ClusterWorkspacePhaseReady},
+			}
+			if tc.withCondition {
+				conditions.MarkTrue(workspace, tenancyv1alpha1.WorkspaceStorageWithinLimits)
+			}
+
+			var listed []string
+			var patch []byte
+			c := &controller{
+				discoverResources: func(clusterName logicalcluster.Name) ([]*metav1.APIResourceList, error) {
+					require.Equal(t, "root:org:ws", clusterName.String())
+					return resources, nil
+				},
+				listObjects: func(ctx context.Context, clusterName logicalcluster.Name, gvr schema.GroupVersionResource, opts metav1.ListOptions) (*unstructured.UnstructuredList, error) {
+					listed = append(listed, fmt.Sprintf("%s?limit=%d&continue=%s", gvr.Resource, opts.Limit, opts.Continue))
+					switch {
+					case gvr.Resource == "configmaps":
+						// the server returns the count of the storage range
+						list := &unstructured.UnstructuredList{Items: []unstructured.Unstructured{object("a")}}
+						list.SetContinue("next")
+						remaining := int64(41)
+						list.SetRemainingItemCount(&remaining)
+						return list, nil
+					case gvr.Resource == "widgets" && opts.Continue == "":
+						// the server does not return a count
+						list := &unstructured.UnstructuredList{Items: []unstructured.Unstructured{object("b")}}
+						list.SetContinue("next")
+						return list, nil
+					case gvr.Resource == "widgets":
+						return &unstructured.UnstructuredList{Items: []unstructured.Unstructured{object("c")}}, nil
+					default:
+						return nil, errors.NewNotFound(gvr.GroupResource(), "")
+					}
+				},
+				patchClusterWorkspaceStatus: func(ctx context.Context, clusterName logicalcluster.Name, name string, bs []byte) error {
+					require.Equal(t, "root:org", clusterName.String())
+					require.Equal(t, "ws", name)
+					patch = bs
+					return nil
+				},
+				scanRateLimiter: flowcontrol.NewFakeAlwaysRateLimiter(),
+				options:         tc.options,
+				now:             func() time.Time { return now },
+			}
+
+			err := c.reconcile(context.Background(), workspace)
+			require.NoError(t, err)
+			require.Equal(t, []string{"configmaps?limit=1&continue=", "widgets?limit=1&continue=", "widgets?limit=500&continue=next", "gadgets?limit=1&continue="}, listed)
+
+			var got struct {
+				Metadata metav1.ObjectMeta                      `json:"metadata"`
+				Status   tenancyv1alpha1.ClusterWorkspaceStatus `json:"status"`
+			}
+			require.NoError(t, json.Unmarshal(patch, &got))
+			require.Equal(t, "42", got.Metadata.ResourceVersion)
+			require.NotNil(t, got.Status.Usage)
+			require.Equal(t, tc.wantObjects, got.Status.Usage.ObjectCount)
+			require.True(t, got.Status.Usage.LastUpdateTime.Time.Equal(now))
+
+			cond := conditions.Get(&tenancyv1alpha1.ClusterWorkspace{Status: got.Status}, tenancyv1alpha1.WorkspaceStorageWithinLimits)
+			if tc.wantCondition == nil {
+				require.Nil(t, cond)
+				return
+			}
+			require.NotNil(t, cond)
+			require.Equal(t, tc.wantCondition.Status, cond.Status)
+			require.Equal(t, tc.wantCondition.Reason, cond.Reason)
+			require.Equal(t, tc.wantCondition.Severity, cond.Severity)
+		})
+	}
+}
@@ -0,0 +1,248 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workspacelabels
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/kcp-dev/logicalcluster"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/clusters"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+
+	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+	kcpclient "github.com/kcp-dev/kcp/pkg/client/clientset/versioned"
+	tenancyinformer "github.com/kcp-dev/kcp/pkg/client/informers/externalversions/tenancy/v1alpha1"
+	tenancylister "github.com/kcp-dev/kcp/pkg/client/listers/tenancy/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/logging"
+)
+
+const (
+	controllerName = "kcp-workspace-labels"
+
+	// byTypeIndex indexes ClusterWorkspaces by the key of their ClusterWorkspaceType.
+	byTypeIndex = "workspacelabels-byType"
+)
+
+// NewController returns a controller propagating the additionalWorkspaceLabels of the
+// ClusterWorkspaceTypes annotated with ExperimentalPropagateWorkspaceLabelsAnnotationKey to
+// their existing ClusterWorkspaces.
+func NewController(
+	kcpClusterClient kcpclient.ClusterInterface,
+	workspaceTypeInformer tenancyinformer.ClusterWorkspaceTypeInformer,
+	workspaceInformer tenancyinformer.ClusterWorkspaceInformer,
+) (*Controller, error) {
+	c := &Controller{
+		queue: workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), controllerName),
+
+		kcpClusterClient:    kcpClusterClient,
+		workspaceTypeLister: workspaceTypeInformer.Lister(),
+		workspaceIndexer:    workspaceInformer.Informer().GetIndexer(),
+	}
+
+	if err := c.workspaceIndexer.AddIndexers(cache.Indexers{
+		byTypeIndex: func(obj interface{}) ([]string, error) {
+			if workspace, ok := obj.(*tenancyv1alpha1.ClusterWorkspace); ok {
+				return []string{workspaceTypeKey(workspace)}, nil
+			}
+			return []string{}, nil
+		},
+	}); err != nil {
+		return nil, fmt.Errorf("failed to add indexer for ClusterWorkspace: %w", err)
+	}
+
+	workspaceTypeInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj interface{}) { c.enqueue(obj) },
+		UpdateFunc: func(_, obj interface{}) { c.enqueue(obj) },
+	})
+	// catch up with label changes on the workspaces
+	workspaceInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj interface{}) { c.enqueueWorkspaceType(obj) },
+		UpdateFunc: func(_, obj interface{}) { c.enqueueWorkspaceType(obj) },
+	})
+
+	return c, nil
+}
+
+// Controller keeps the additionalWorkspaceLabels of opted-in ClusterWorkspaceTypes in sync
+// on their existing ClusterWorkspaces.
+type Controller struct {
+	queue workqueue.RateLimitingInterface
+
+	kcpClusterClient    kcpclient.ClusterInterface
+	workspaceTypeLister tenancylister.ClusterWorkspaceTypeLister
+	workspaceIndexer    cache.Indexer
+}
+
+// workspaceTypeKey returns the queue key of the ClusterWorkspaceType of the given workspace.
+func workspaceTypeKey(workspace *tenancyv1alpha1.ClusterWorkspace) string {
+	return clusters.ToClusterAwareKey(logicalcluster.From(workspace), strings.ToLower(workspace.Spec.Type))
+}
+
+func (c *Controller) enqueue(obj interface{}) {
+	key, err := cache.MetaNamespaceKeyFunc(obj)
+	if err != nil {
+		runtime.HandleError(err)
+		return
+	}
+	logging.WithQueueKey(logging.NewLogger(controllerName), key).V(2).Info("Queueing ClusterWorkspaceType")
+	c.queue.Add(key)
+}
+
+func (c *Controller) enqueueWorkspaceType(obj interface{}) {
+	workspace, ok := obj.(*tenancyv1alpha1.ClusterWorkspace)
+	if !ok {
+		runtime.HandleError(fmt.Errorf("got %T when handling ClusterWorkspace", obj))
+		return
+	}
+	c.queue.Add(workspaceTypeKey(workspace))
+}
+
+func (c *Controller) Start(ctx context.Context, numThreads int) {
+	defer runtime.HandleCrash()
+	defer c.queue.ShutDown()
+
+	klog.Infof("Starting %s controller", controllerName)
+	defer klog.Infof("Shutting down %s controller", controllerName)
+
+	for i := 0; i < numThreads; i++ {
+		go wait.UntilWithContext(ctx, c.startWorker, time.Second)
+	}
+
+	<-ctx.Done()
+}
+
+func (c *Controller) startWorker(ctx context.Context) {
+	for c.processNextWorkItem(ctx) {
+	}
+}
+
+func (c *Controller) processNextWorkItem(ctx context.Context) bool {
+	// Wait until there is a new item in the working queue
+	k, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+	key := k.(string)
+
+	logger := logging.WithQueueKey(logging.NewLogger(controllerName), key)
+	ctx = logging.NewContext(ctx, logger)
+
+	// No matter what, tell the queue we're done with this key, to unblock
+	// other workers.
+	defer c.queue.Done(key)
+
+	if err := c.process(ctx, key); err != nil {
+		runtime.HandleError(fmt.Errorf("%q controller failed to sync %q, err: %w", controllerName, key, err))
+		c.queue.AddRateLimited(key)
+		return true
+	}
+	c.queue.Forget(key)
+	return true
+}
+
+func (c *Controller) process(ctx context.Context, key string) error {
+	workspaceType, err := c.workspaceTypeLister.Get(key)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil // e.g. Universal workspaces without ClusterWorkspaceType
+		}
+		return err
+	}
+	if _, found := workspaceType.Annotations[tenancyv1alpha1.ExperimentalPropagateWorkspaceLabelsAnnotationKey]; !found {
+		return nil
+	}
+
+	workspaces, err := c.workspaceIndexer.ByIndex(byTypeIndex, key)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, obj := range workspaces {
+		workspace := obj.(*tenancyv1alpha1.ClusterWorkspace)
+		patch := labelPatch(workspaceType.Spec.AdditionalWorkspaceLabels, workspace)
+		if patch == nil {
+			continue
+		}
+		patchBytes, err := json.Marshal(patch)
+		if err != nil {
+			return err
+		}
+		logging.WithClusterWorkspace(logging.FromContext(ctx), workspace).V(2).Info("Propagating labels of ClusterWorkspaceType to ClusterWorkspace")
+		if _, err := c.kcpClusterClient.Cluster(logicalcluster.From(workspace)).TenancyV1alpha1().ClusterWorkspaces().Patch(ctx, workspace.Name, types.MergePatchType, patchBytes, metav1.PatchOptions{}); err != nil && !errors.IsNotFound(err) {
+			errs = append(errs, err)
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// labelPatch returns a merge patch setting the given labels on the workspace, and removing
+// the labels propagated before but not part of the given labels anymore. It returns nil if
+// the workspace is up-to-date.
+func labelPatch(desired map[string]string, workspace *tenancyv1alpha1.ClusterWorkspace) map[string]interface{} {
+	previous := sets.NewString()
+	if value := workspace.Annotations[tenancyv1alpha1.ExperimentalPropagatedWorkspaceLabelsAnnotationKey]; value != "" {
+		previous.Insert(strings.Split(value, ",")...)
+	}
+
+	labels := map[string]interface{}{}
+	for key := range previous {
+		if _, found := desired[key]; !found {
+			if _, found := workspace.Labels[key]; found {
+				labels[key] = nil
+			}
+		}
+	}
+	keys := make([]string, 0, len(desired))
+	for key, value := range desired {
+		keys = append(keys, key)
+		if current, found := workspace.Labels[key]; !found || current != value {
+			labels[key] = value
+		}
+	}
+	sort.Strings(keys)
+
+	metadata := map[string]interface{}{}
+	if len(labels) > 0 {
+		metadata["labels"] = labels
+	}
+	if !previous.Equal(sets.NewString(keys...)) {
+		if len(keys) == 0 {
+			metadata["annotations"] = map[string]interface{}{tenancyv1alpha1.ExperimentalPropagatedWorkspaceLabelsAnnotationKey: nil}
+		} else {
+			metadata["annotations"] = map[string]interface{}{tenancyv1alpha1.ExperimentalPropagatedWorkspaceLabelsAnnotationKey: strings.Join(keys, ",")}
+		}
+	}
+	if len(metadata) == 0 {
+		return nil
+	}
+	return map[string]interface{}{"metadata": metadata}
+}
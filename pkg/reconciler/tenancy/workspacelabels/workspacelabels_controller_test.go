@@ -0,0 +1,110 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workspacelabels
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+)
+
+func TestLabelPatch(t *testing.T) {
+	const propagated = tenancyv1alpha1.ExperimentalPropagatedWorkspaceLabelsAnnotationKey
+
+	tests := []struct {
+		name        string
+		desired     map[string]string
+		labels      map[string]string
+		annotations map[string]string
+		want        map[string]interface{}
+	}{
+		{
+			name: "no labels",
+		},
+		{
+			name:    "new labels",
+			desired: map[string]string{"team": "a", "env": "prod"},
+			labels:  map[string]string{"other": "x"},
+			want: map[string]interface{}{"metadata": map[string]interface{}{
+				"labels":      map[string]interface{}{"team": "a", "env": "prod"},
+				"annotations": map[string]interface{}{propagated: "env,team"},
+			}},
+		},
+		{
+			name:        "up-to-date",
+			desired:     map[string]string{"team": "a", "env": "prod"},
+			labels:      map[string]string{"team": "a", "env": "prod", "other": "x"},
+			annotations: map[string]string{propagated: "env,team"},
+		},
+		{
+			name:        "changed label",
+			desired:     map[string]string{"team": "b"},
+			labels:      map[string]string{"team": "a"},
+			annotations: map[string]string{propagated: "team"},
+			want: map[string]interface{}{"metadata": map[string]interface{}{
+				"labels": map[string]interface{}{"team": "b"},
+			}},
+		},
+		{
+			name:        "labels applied on creation",
+			desired:     map[string]string{"team": "a"},
+			labels:      map[string]string{"team": "a"},
+			annotations: map[string]string{},
+			want: map[string]interface{}{"metadata": map[string]interface{}{
+				"annotations": map[string]interface{}{propagated: "team"},
+			}},
+		},
+		{
+			name:        "removed label",
+			desired:     map[string]string{"team": "a"},
+			labels:      map[string]string{"team": "a", "env": "prod", "other": "x"},
+			annotations: map[string]string{propagated: "env,team"},
+			want: map[string]interface{}{"metadata": map[string]interface{}{
+				"labels":      map[string]interface{}{"env": nil},
+				"annotations": map[string]interface{}{propagated: "team"},
+			}},
+		},
+		{
+			name:        "all labels removed",
+			labels:      map[string]string{"env": "prod", "other": "x"},
+			annotations: map[string]string{propagated: "env"},
+			want: map[string]interface{}{"metadata": map[string]interface{}{
+				"labels":      map[string]interface{}{"env": nil},
+				"annotations": map[string]interface{}{propagated: nil},
+			}},
+		},
+		{
+			name:        "removed label already gone",
+			annotations: map[string]string{propagated: "env"},
+			want: map[string]interface{}{"metadata": map[string]interface{}{
+				"annotations": map[string]interface{}{propagated: nil},
+			}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			workspace := &tenancyv1alpha1.ClusterWorkspace{
+				ObjectMeta: metav1.ObjectMeta{Name: "test", Labels: tt.labels, Annotations: tt.annotations},
+			}
+			require.Equal(t, tt.want, labelPatch(tt.desired, workspace))
+		})
+	}
+}
@@ -0,0 +1,154 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workspacesnapshot
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/kcp-dev/logicalcluster"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/kcp-dev/kcp/pkg/apis/apiresource"
+	"github.com/kcp-dev/kcp/pkg/apis/tenancy"
+	"github.com/kcp-dev/kcp/pkg/apis/workload"
+)
+
+var (
+	// excludedGroups are the API groups whose objects are not captured: child workspaces, their
+	// types and snapshots, and the objects tied to the physical clusters of the workspace.
+	excludedGroups = sets.NewString(tenancy.GroupName, workload.GroupName, apiresource.GroupName, "events.k8s.io")
+
+	namespacesGVR = corev1.SchemeGroupVersion.WithResource("namespaces")
+)
+
+// capturable returns whether objects of the given resource are captured, i.e. whether they
+// can be listed and created, and are not excluded.
+func capturable(gv schema.GroupVersion, resource metav1.APIResource) bool {
+	if excludedGroups.Has(gv.Group) || (gv.Group == "" && resource.Name == "events") {
+		return false
+	}
+	verbs := sets.NewString(resource.Verbs...)
+	return verbs.HasAll("list", "create")
+}
+
+// capturedObject returns whether the given object is captured. Objects created by kcp or by a
+// controller in every workspace or for their owner are not.
+func capturedObject(gvr schema.GroupVersionResource, obj *unstructured.Unstructured) bool {
+	if metav1.GetControllerOf(obj) != nil {
+		return false
+	}
+	if gvr.Group == "" {
+		switch {
+		case gvr.Resource == "secrets":
+			secretType, _, _ := unstructured.NestedString(obj.Object, "type")
+			return secretType != string(corev1.SecretTypeServiceAccountToken)
+		case gvr.Resource == "configmaps":
+			return obj.GetName() != "kube-root-ca.crt"
+		}
+	}
+	return true
+}
+
+// sanitize removes the metadata set by the server and the status from the given object, such
+// that it can be created in another workspace.
+func sanitize(obj *unstructured.Unstructured) {
+	obj.SetUID("")
+	obj.SetResourceVersion("")
+	obj.SetGeneration(0)
+	obj.SetCreationTimestamp(metav1.Time{})
+	obj.SetDeletionTimestamp(nil)
+	obj.SetDeletionGracePeriodSeconds(nil)
+	obj.SetManagedFields(nil)
+	obj.SetSelfLink("")
+	obj.SetClusterName("")
+	obj.SetOwnerReferences(nil)
+	unstructured.RemoveNestedField(obj.Object, "status")
+}
+
+// capture lists the objects of all capturable resources of the given logical cluster at one
+// resourceVersion, and returns them with that resourceVersion.
+func (c *Controller) capture(ctx context.Context, clusterName logicalcluster.Name) ([]entry, string, error) {
+	resourceLists, err := c.listResources(clusterName)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to discover the resources of workspace %s: %w", clusterName, err)
+	}
+
+	// A quorum read of the namespaces fixes the resourceVersion all other resources are listed at.
+	namespaces, err := c.listObjects(ctx, clusterName, namespacesGVR, "")
+	if err != nil {
+		return nil, "", err
+	}
+	rv := namespaces.GetResourceVersion()
+
+	var entries []entry
+	for _, resourceList := range resourceLists {
+		gv, err := schema.ParseGroupVersion(resourceList.GroupVersion)
+		if err != nil {
+			return nil, "", err
+		}
+		for _, resource := range resourceList.APIResources {
+			if !capturable(gv, resource) {
+				continue
+			}
+			gvr := gv.WithResource(resource.Name)
+
+			list := namespaces
+			if gvr != namespacesGVR {
+				if list, err = c.listObjects(ctx, clusterName, gvr, rv); err != nil {
+					return nil, "", fmt.Errorf("failed to list %s in workspace %s: %w", gvr, clusterName, err)
+				}
+			}
+			for i := range list.Items {
+				obj := list.Items[i].DeepCopy()
+				if !capturedObject(gvr, obj) {
+					continue
+				}
+				sanitize(obj)
+				entries = append(entries, entry{Group: gvr.Group, Version: gvr.Version, Resource: gvr.Resource, Object: obj})
+			}
+		}
+	}
+
+	sortForRestore(entries)
+	return entries, rv, nil
+}
+
+// sortForRestore orders the entries such that the APIs and namespaces are created before the
+// objects depending on them.
+func sortForRestore(entries []entry) {
+	rank := func(e entry) int {
+		switch {
+		case e.Group == "apiextensions.k8s.io" && e.Resource == "customresourcedefinitions":
+			return 0
+		case e.Group == "apis.kcp.dev" && e.Resource == "apibindings":
+			return 1
+		case e.Group == "" && e.Resource == "namespaces":
+			return 2
+		}
+		return 3
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return rank(entries[i]) < rank(entries[j])
+	})
+}
@@ -0,0 +1,131 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workspacesnapshot
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kcp-dev/logicalcluster"
+	"github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+func newObject(apiVersion, kind, namespace, name string) unstructured.Unstructured {
+	obj := unstructured.Unstructured{}
+	obj.SetAPIVersion(apiVersion)
+	obj.SetKind(kind)
+	obj.SetNamespace(namespace)
+	obj.SetName(name)
+	obj.SetUID("uid")
+	obj.SetResourceVersion("42")
+	obj.SetClusterName("root:org:staging")
+	obj.SetManagedFields([]metav1.ManagedFieldsEntry{{Manager: "kubectl"}})
+	obj.Object["status"] = map[string]interface{}{"phase": "Active"}
+	return obj
+}
+
+func TestCapture(t *testing.T) {
+	ownedPod := newObject("v1", "Pod", "app", "owned")
+	ownedPod.SetOwnerReferences([]metav1.OwnerReference{{APIVersion: "apps/v1", Kind: "ReplicaSet", Name: "rs", UID: "rs-uid", Controller: boolPtr(true)}})
+	tokenSecret := newObject("v1", "Secret", "app", "token")
+	tokenSecret.Object["type"] = "kubernetes.io/service-account-token"
+
+	objects := map[schema.GroupVersionResource][]unstructured.Unstructured{
+		{Version: "v1", Resource: "namespaces"}:                                               {newObject("v1", "Namespace", "", "app")},
+		{Version: "v1", Resource: "configmaps"}:                                               {newObject("v1", "ConfigMap", "app", "config"), newObject("v1", "ConfigMap", "app", "kube-root-ca.crt")},
+		{Version: "v1", Resource: "secrets"}:                                                  {newObject("v1", "Secret", "app", "credentials"), tokenSecret},
+		{Version: "v1", Resource: "pods"}:                                                     {newObject("v1", "Pod", "app", "standalone"), ownedPod},
+		{Group: "apiextensions.k8s.io", Version: "v1", Resource: "customresourcedefinitions"}: {newObject("apiextensions.k8s.io/v1", "CustomResourceDefinition", "", "widgets.example.com")},
+		{Group: "tenancy.kcp.dev", Version: "v1alpha1", Resource: "clusterworkspaces"}:        {newObject("tenancy.kcp.dev/v1alpha1", "ClusterWorkspace", "", "child")},
+		{Version: "v1", Resource: "events"}:                                                   {newObject("v1", "Event", "app", "event")},
+	}
+
+	c := &Controller{
+		listResources: func(clusterName logicalcluster.Name) ([]*metav1.APIResourceList, error) {
+			require.Equal(t, "root:org:staging", clusterName.String())
+			return []*metav1.APIResourceList{
+				{
+					GroupVersion: "v1",
+					APIResources: []metav1.APIResource{
+						{Name: "configmaps", Verbs: []string{"create", "list"}},
+						{Name: "events", Verbs: []string{"create", "list"}},
+						{Name: "namespaces", Verbs: []string{"create", "list"}},
+						{Name: "pods", Verbs: []string{"create", "list"}},
+						{Name: "secrets", Verbs: []string{"create", "list"}},
+						{Name: "componentstatuses", Verbs: []string{"list"}},
+					},
+				},
+				{
+					GroupVersion: "apiextensions.k8s.io/v1",
+					APIResources: []metav1.APIResource{{Name: "customresourcedefinitions", Verbs: []string{"create", "list"}}},
+				},
+				{
+					GroupVersion: "tenancy.kcp.dev/v1alpha1",
+					APIResources: []metav1.APIResource{{Name: "clusterworkspaces", Verbs: []string{"create", "list"}}},
+				},
+			}, nil
+		},
+		listObjects: func(ctx context.Context, clusterName logicalcluster.Name, gvr schema.GroupVersionResource, resourceVersion string) (*unstructured.UnstructuredList, error) {
+			if gvr.Resource == "namespaces" {
+				require.Empty(t, resourceVersion, "namespaces are expected to be listed with a quorum read")
+			} else {
+				require.Equal(t, "100", resourceVersion)
+			}
+			require.NotEqual(t, "componentstatuses", gvr.Resource)
+			list := &unstructured.UnstructuredList{Items: objects[gvr]}
+			list.SetResourceVersion("100")
+			return list, nil
+		},
+	}
+
+	entries, rv, err := c.capture(context.Background(), logicalcluster.New("root:org:staging"))
+	require.NoError(t, err)
+	require.Equal(t, "100", rv)
+
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Resource+"/"+e.Object.GetName())
+
+		require.Empty(t, e.Object.GetUID())
+		require.Empty(t, e.Object.GetResourceVersion())
+		require.Empty(t, e.Object.GetClusterName())
+		require.Empty(t, e.Object.GetManagedFields())
+		_, found := e.Object.Object["status"]
+		require.False(t, found, "status of %s is expected to be removed", e.Object.GetName())
+	}
+	require.Equal(t, []string{
+		"customresourcedefinitions/widgets.example.com",
+		"namespaces/app",
+		"configmaps/config",
+		"pods/standalone",
+		"secrets/credentials",
+	}, names)
+
+	data, err := encode(entries)
+	require.NoError(t, err)
+	decoded, err := decode(data)
+	require.NoError(t, err)
+	require.Equal(t, entries, decoded)
+}
+
+func boolPtr(b bool) *bool {
+	return &b
+}
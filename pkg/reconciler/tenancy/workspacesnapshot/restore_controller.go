@@ -0,0 +1,259 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workspacesnapshot
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/kcp-dev/logicalcluster"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/clusters"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+
+	jobsv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/jobs/v1alpha1"
+	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+	tenancyhelper "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1/helper"
+	kcpclient "github.com/kcp-dev/kcp/pkg/client/clientset/versioned"
+	tenancyinformer "github.com/kcp-dev/kcp/pkg/client/informers/externalversions/tenancy/v1alpha1"
+	tenancylister "github.com/kcp-dev/kcp/pkg/client/listers/tenancy/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/logging"
+	conditionsv1alpha1 "github.com/kcp-dev/kcp/third_party/conditions/apis/conditions/v1alpha1"
+	"github.com/kcp-dev/kcp/third_party/conditions/util/conditions"
+)
+
+const (
+	restoreControllerName = "kcp-workspace-snapshot-restore"
+
+	// snapshotNotReadyRequeueDelay is the time after which the restore of a snapshot that is not
+	// ready yet is retried.
+	snapshotNotReadyRequeueDelay = 10 * time.Second
+)
+
+// NewRestoreController returns a controller creating the objects of a WorkspaceSnapshot in the
+// ClusterWorkspaces annotated with it, while they are initializing.
+func NewRestoreController(
+	kcpClusterClient kcpclient.ClusterInterface,
+	kubeClusterClient kubernetes.ClusterInterface,
+	dynamicClusterClient dynamic.ClusterInterface,
+	workspaceInformer tenancyinformer.ClusterWorkspaceInformer,
+	snapshotInformer tenancyinformer.WorkspaceSnapshotInformer,
+) *RestoreController {
+	queue := workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), restoreControllerName)
+
+	c := &RestoreController{
+		queue:                queue,
+		kcpClusterClient:     kcpClusterClient,
+		kubeClusterClient:    kubeClusterClient,
+		dynamicClusterClient: dynamicClusterClient,
+		workspaceLister:      workspaceInformer.Lister(),
+		snapshotLister:       snapshotInformer.Lister(),
+	}
+
+	workspaceInformer.Informer().AddEventHandler(cache.FilteringResourceEventHandler{
+		FilterFunc: func(obj interface{}) bool {
+			workspace, ok := obj.(*tenancyv1alpha1.ClusterWorkspace)
+			return ok && workspace.Annotations[tenancyv1alpha1.ExperimentalWorkspaceSnapshotAnnotationKey] != ""
+		},
+		Handler: cache.ResourceEventHandlerFuncs{
+			AddFunc:    func(obj interface{}) { c.enqueue(obj) },
+			UpdateFunc: func(_, obj interface{}) { c.enqueue(obj) },
+		},
+	})
+
+	return c
+}
+
+// RestoreController creates the objects captured by a WorkspaceSnapshot in the ClusterWorkspaces
+// annotated with ExperimentalWorkspaceSnapshotAnnotationKey, once the initializers their
+// WorkspaceSnapshotInitializer depends on are cleared. It clears the WorkspaceSnapshotInitializer
+// when done, such that the workspace does not turn ready before its content exists.
+type RestoreController struct {
+	queue workqueue.RateLimitingInterface
+
+	kcpClusterClient     kcpclient.ClusterInterface
+	kubeClusterClient    kubernetes.ClusterInterface
+	dynamicClusterClient dynamic.ClusterInterface
+
+	workspaceLister tenancylister.ClusterWorkspaceLister
+	snapshotLister  tenancylister.WorkspaceSnapshotLister
+}
+
+func (c *RestoreController) enqueue(obj interface{}) {
+	key, err := cache.MetaNamespaceKeyFunc(obj)
+	if err != nil {
+		runtime.HandleError(err)
+		return
+	}
+	logging.WithQueueKey(logging.NewLogger(restoreControllerName), key).V(2).Info("Queueing ClusterWorkspace for snapshot restore")
+	c.queue.Add(key)
+}
+
+func (c *RestoreController) Start(ctx context.Context, numThreads int) {
+	defer runtime.HandleCrash()
+	defer c.queue.ShutDown()
+
+	klog.Info("Starting WorkspaceSnapshot restore controller")
+	defer klog.Info("Shutting down WorkspaceSnapshot restore controller")
+
+	for i := 0; i < numThreads; i++ {
+		go wait.UntilWithContext(ctx, c.startWorker, time.Second)
+	}
+
+	<-ctx.Done()
+}
+
+func (c *RestoreController) startWorker(ctx context.Context) {
+	for c.processNextWorkItem(ctx) {
+	}
+}
+
+func (c *RestoreController) processNextWorkItem(ctx context.Context) bool {
+	// Wait until there is a new item in the working queue
+	k, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+	key := k.(string)
+
+	logger := logging.WithQueueKey(logging.NewLogger(restoreControllerName), key)
+	ctx = logging.NewContext(ctx, logger)
+
+	// No matter what, tell the queue we're done with this key, to unblock
+	// other workers.
+	defer c.queue.Done(key)
+
+	if err := c.process(ctx, key); err != nil {
+		runtime.HandleError(fmt.Errorf("%q controller failed to sync %q, err: %w", restoreControllerName, key, err))
+		c.queue.AddRateLimited(key)
+		return true
+	}
+	c.queue.Forget(key)
+	return true
+}
+
+func (c *RestoreController) process(ctx context.Context, key string) error {
+	obj, err := c.workspaceLister.Get(key)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil // object deleted before we handled it
+		}
+		return err
+	}
+	if obj.DeletionTimestamp != nil || obj.Status.Phase != tenancyv1alpha1.ClusterWorkspacePhaseInitializing {
+		return nil
+	}
+	if !hasInitializer(obj, tenancyv1alpha1.WorkspaceSnapshotInitializer) {
+		return nil // restored before
+	}
+	if pending := tenancyhelper.PendingInitializerDependencies(obj, tenancyv1alpha1.WorkspaceSnapshotInitializer); len(pending) > 0 {
+		logging.FromContext(ctx).V(4).Info("Waiting for initializers before restoring the snapshot", "initializers", pending)
+		return nil // we are triggered again when they are cleared
+	}
+
+	workspace := obj.DeepCopy()
+	requeue, reconcileErr := c.reconcile(ctx, workspace)
+	if requeue {
+		c.queue.AddAfter(key, snapshotNotReadyRequeueDelay)
+	}
+	if reconcileErr != nil {
+		tenancyhelper.SetInitializerFailure(workspace, tenancyv1alpha1.WorkspaceSnapshotInitializer, reconcileErr, metav1.Now(), nil)
+	}
+	if !equality.Semantic.DeepEqual(obj.Status, workspace.Status) {
+		if _, err := c.kcpClusterClient.Cluster(logicalcluster.From(workspace)).TenancyV1alpha1().ClusterWorkspaces().UpdateStatus(ctx, workspace, metav1.UpdateOptions{}); err != nil {
+			return err
+		}
+	}
+	return reconcileErr
+}
+
+// reconcile creates the objects of the snapshot of the given workspace in its logical cluster.
+// It returns true if the snapshot is not ready yet.
+func (c *RestoreController) reconcile(ctx context.Context, workspace *tenancyv1alpha1.ClusterWorkspace) (bool, error) {
+	parent := logicalcluster.From(workspace)
+	snapshotName := workspace.Annotations[tenancyv1alpha1.ExperimentalWorkspaceSnapshotAnnotationKey]
+
+	snapshot, err := c.snapshotLister.Get(clusters.ToClusterAwareKey(parent, snapshotName))
+	if err != nil && !errors.IsNotFound(err) {
+		return false, err
+	}
+	if errors.IsNotFound(err) || snapshot.Status.Phase != jobsv1alpha1.JobPhaseSucceeded {
+		conditions.MarkFalse(workspace, tenancyv1alpha1.WorkspaceSnapshotRestored, tenancyv1alpha1.WorkspaceSnapshotReasonSnapshotNotReady, conditionsv1alpha1.ConditionSeverityInfo,
+			"WorkspaceSnapshot %q does not exist or is not ready", snapshotName)
+		return true, nil
+	}
+
+	entries, err := loadSnapshot(ctx, c.kubeClusterClient, storageSecretName(parent, snapshotName))
+	if err == nil {
+		err = c.restore(ctx, parent.Join(workspace.Name), entries)
+	}
+	if err != nil {
+		conditions.MarkFalse(workspace, tenancyv1alpha1.WorkspaceSnapshotRestored, tenancyv1alpha1.WorkspaceSnapshotReasonRestoreFailed, conditionsv1alpha1.ConditionSeverityWarning,
+			"Failed to restore WorkspaceSnapshot %q: %v", snapshotName, err)
+		return false, err
+	}
+
+	conditions.MarkTrue(workspace, tenancyv1alpha1.WorkspaceSnapshotRestored)
+	tenancyhelper.ClearInitializerFailure(workspace, tenancyv1alpha1.WorkspaceSnapshotInitializer)
+	initializers := make([]tenancyv1alpha1.ClusterWorkspaceInitializer, 0, len(workspace.Status.Initializers))
+	for _, i := range workspace.Status.Initializers {
+		if i != tenancyv1alpha1.WorkspaceSnapshotInitializer {
+			initializers = append(initializers, i)
+		}
+	}
+	workspace.Status.Initializers = initializers
+	return false, nil
+}
+
+// restore creates the given objects in the given logical cluster, in order. Existing objects are
+// left alone, such that a failed restore can be retried.
+func (c *RestoreController) restore(ctx context.Context, clusterName logicalcluster.Name, entries []entry) error {
+	for i := range entries {
+		e := &entries[i]
+		client := c.dynamicClusterClient.Cluster(clusterName).Resource(e.GroupVersionResource())
+		var err error
+		if ns := e.Object.GetNamespace(); ns != "" {
+			_, err = client.Namespace(ns).Create(ctx, e.Object, metav1.CreateOptions{})
+		} else {
+			_, err = client.Create(ctx, e.Object, metav1.CreateOptions{})
+		}
+		if err != nil && !errors.IsAlreadyExists(err) {
+			return fmt.Errorf("failed to create %s %s/%s: %w", e.GroupVersionResource(), e.Object.GetNamespace(), e.Object.GetName(), err)
+		}
+	}
+	return nil
+}
+
+// hasInitializer returns whether the given initializer is pending on the given workspace.
+func hasInitializer(workspace *tenancyv1alpha1.ClusterWorkspace, initializer tenancyv1alpha1.ClusterWorkspaceInitializer) bool {
+	for _, i := range workspace.Status.Initializers {
+		if i == initializer {
+			return true
+		}
+	}
+	return false
+}
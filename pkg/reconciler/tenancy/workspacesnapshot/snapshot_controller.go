@@ -0,0 +1,379 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workspacesnapshot
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/kcp-dev/logicalcluster"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/clusters"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+
+	jobsv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/jobs/v1alpha1"
+	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+	kcpclient "github.com/kcp-dev/kcp/pkg/client/clientset/versioned"
+	tenancyinformer "github.com/kcp-dev/kcp/pkg/client/informers/externalversions/tenancy/v1alpha1"
+	tenancylister "github.com/kcp-dev/kcp/pkg/client/listers/tenancy/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/jobs"
+	"github.com/kcp-dev/kcp/pkg/logging"
+	conditionsv1alpha1 "github.com/kcp-dev/kcp/third_party/conditions/apis/conditions/v1alpha1"
+	"github.com/kcp-dev/kcp/third_party/conditions/util/conditions"
+)
+
+const (
+	controllerName = "kcp-workspace-snapshot"
+
+	// snapshotFinalizer makes sure the captured objects are deleted with the WorkspaceSnapshot.
+	snapshotFinalizer = "tenancy.kcp.dev/workspace-snapshot"
+
+	// workspaceNotReadyRequeueDelay is the time after which a snapshot of a workspace that is not
+	// ready yet is retried.
+	workspaceNotReadyRequeueDelay = 10 * time.Second
+
+	// byWorkspace indexes WorkspaceSnapshots by the key of their workspace, i.e. by their siblings.
+	byWorkspace = "workspacesnapshot-byWorkspace"
+)
+
+// NewController returns a controller capturing the objects of the workspaces referenced by
+// WorkspaceSnapshots.
+func NewController(
+	kcpClusterClient kcpclient.ClusterInterface,
+	kubeClusterClient kubernetes.ClusterInterface,
+	dynamicClusterClient dynamic.ClusterInterface,
+	listResources func(clusterName logicalcluster.Name) ([]*metav1.APIResourceList, error),
+	snapshotInformer tenancyinformer.WorkspaceSnapshotInformer,
+	workspaceInformer tenancyinformer.ClusterWorkspaceInformer,
+) (*Controller, error) {
+	queue := workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), controllerName)
+
+	c := &Controller{
+		queue:             queue,
+		kcpClusterClient:  kcpClusterClient,
+		kubeClusterClient: kubeClusterClient,
+		listResources:     listResources,
+		listObjects: func(ctx context.Context, clusterName logicalcluster.Name, gvr schema.GroupVersionResource, resourceVersion string) (*unstructured.UnstructuredList, error) {
+			opts := metav1.ListOptions{}
+			if resourceVersion != "" {
+				opts.ResourceVersion = resourceVersion
+				opts.ResourceVersionMatch = metav1.ResourceVersionMatchExact
+			}
+			return dynamicClusterClient.Cluster(clusterName).Resource(gvr).List(ctx, opts)
+		},
+		deleteSnapshot: func(ctx context.Context, clusterName logicalcluster.Name, name string) error {
+			return kcpClusterClient.Cluster(clusterName).TenancyV1alpha1().WorkspaceSnapshots().Delete(ctx, name, metav1.DeleteOptions{})
+		},
+		snapshotLister:  snapshotInformer.Lister(),
+		snapshotIndexer: snapshotInformer.Informer().GetIndexer(),
+		workspaceLister: workspaceInformer.Lister(),
+		now:             time.Now,
+	}
+
+	if err := snapshotInformer.Informer().AddIndexers(cache.Indexers{
+		byWorkspace: indexByWorkspace,
+	}); err != nil {
+		return nil, err
+	}
+
+	snapshotInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj interface{}) { c.enqueue(obj) },
+		UpdateFunc: func(_, obj interface{}) { c.enqueue(obj) },
+	})
+	workspaceInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		UpdateFunc: func(_, obj interface{}) { c.enqueueSnapshotsOf(obj) },
+	})
+
+	return c, nil
+}
+
+// Controller captures the objects of the workspace referenced by a WorkspaceSnapshot once the
+// workspace is ready, and stores them in StorageCluster. Finished snapshots are deleted according
+// to their TTL and the history limits of the snapshots of the same workspace.
+type Controller struct {
+	queue workqueue.RateLimitingInterface
+
+	kcpClusterClient  kcpclient.ClusterInterface
+	kubeClusterClient kubernetes.ClusterInterface
+
+	listResources func(clusterName logicalcluster.Name) ([]*metav1.APIResourceList, error)
+	listObjects   func(ctx context.Context, clusterName logicalcluster.Name, gvr schema.GroupVersionResource, resourceVersion string) (*unstructured.UnstructuredList, error)
+
+	deleteSnapshot func(ctx context.Context, clusterName logicalcluster.Name, name string) error
+
+	snapshotLister  tenancylister.WorkspaceSnapshotLister
+	snapshotIndexer cache.Indexer
+	workspaceLister tenancylister.ClusterWorkspaceLister
+
+	now func() time.Time
+}
+
+// indexByWorkspace indexes WorkspaceSnapshots by the key of the workspace they capture.
+func indexByWorkspace(obj interface{}) ([]string, error) {
+	snapshot, ok := obj.(*tenancyv1alpha1.WorkspaceSnapshot)
+	if !ok {
+		return []string{}, fmt.Errorf("obj is supposed to be a WorkspaceSnapshot, but is %T", obj)
+	}
+	return []string{clusters.ToClusterAwareKey(logicalcluster.From(snapshot), snapshot.Spec.Workspace)}, nil
+}
+
+func (c *Controller) enqueue(obj interface{}) {
+	key, err := cache.MetaNamespaceKeyFunc(obj)
+	if err != nil {
+		runtime.HandleError(err)
+		return
+	}
+	logging.WithQueueKey(logging.NewLogger(controllerName), key).V(2).Info("Queueing WorkspaceSnapshot")
+	c.queue.Add(key)
+}
+
+// enqueueSnapshotsOf queues the pending snapshots of the given ClusterWorkspace, such that they
+// are captured as soon as the workspace is ready, and the snapshot it is created from, such that
+// the snapshot is garbage collected once it has been restored.
+func (c *Controller) enqueueSnapshotsOf(obj interface{}) {
+	workspace, ok := obj.(*tenancyv1alpha1.ClusterWorkspace)
+	if !ok {
+		return
+	}
+	if name := workspace.Annotations[tenancyv1alpha1.ExperimentalWorkspaceSnapshotAnnotationKey]; name != "" && !hasInitializer(workspace, tenancyv1alpha1.WorkspaceSnapshotInitializer) {
+		c.queue.Add(clusters.ToClusterAwareKey(logicalcluster.From(workspace), name))
+	}
+	if workspace.Status.Phase != tenancyv1alpha1.ClusterWorkspacePhaseReady {
+		return
+	}
+	snapshots, err := c.snapshotLister.List(labels.Everything())
+	if err != nil {
+		runtime.HandleError(err)
+		return
+	}
+	clusterName := logicalcluster.From(workspace)
+	for _, snapshot := range snapshots {
+		if logicalcluster.From(snapshot) == clusterName && snapshot.Spec.Workspace == workspace.Name && snapshot.Status.Phase == jobsv1alpha1.JobPhasePending {
+			c.enqueue(snapshot)
+		}
+	}
+}
+
+func (c *Controller) Start(ctx context.Context, numThreads int) {
+	defer runtime.HandleCrash()
+	defer c.queue.ShutDown()
+
+	klog.Info("Starting WorkspaceSnapshot controller")
+	defer klog.Info("Shutting down WorkspaceSnapshot controller")
+
+	for i := 0; i < numThreads; i++ {
+		go wait.UntilWithContext(ctx, c.startWorker, time.Second)
+	}
+
+	<-ctx.Done()
+}
+
+func (c *Controller) startWorker(ctx context.Context) {
+	for c.processNextWorkItem(ctx) {
+	}
+}
+
+func (c *Controller) processNextWorkItem(ctx context.Context) bool {
+	// Wait until there is a new item in the working queue
+	k, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+	key := k.(string)
+
+	logger := logging.WithQueueKey(logging.NewLogger(controllerName), key)
+	ctx = logging.NewContext(ctx, logger)
+
+	// No matter what, tell the queue we're done with this key, to unblock
+	// other workers.
+	defer c.queue.Done(key)
+
+	if err := c.process(ctx, key); err != nil {
+		runtime.HandleError(fmt.Errorf("%q controller failed to sync %q, err: %w", controllerName, key, err))
+		c.queue.AddRateLimited(key)
+		return true
+	}
+	c.queue.Forget(key)
+	return true
+}
+
+func (c *Controller) process(ctx context.Context, key string) error {
+	obj, err := c.snapshotLister.Get(key)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil // object deleted before we handled it
+		}
+		return err
+	}
+	clusterName := logicalcluster.From(obj)
+	secretName := storageSecretName(clusterName, obj.Name)
+
+	if obj.DeletionTimestamp != nil {
+		if !sets.NewString(obj.Finalizers...).Has(snapshotFinalizer) {
+			return nil
+		}
+		if err := deleteSnapshot(ctx, c.kubeClusterClient, secretName); err != nil {
+			return err
+		}
+		obj = obj.DeepCopy()
+		obj.Finalizers = sets.NewString(obj.Finalizers...).Delete(snapshotFinalizer).List()
+		_, err := c.kcpClusterClient.Cluster(clusterName).TenancyV1alpha1().WorkspaceSnapshots().Update(ctx, obj, metav1.UpdateOptions{})
+		return err
+	}
+
+	if jobs.IsFinished(obj) {
+		return c.collectGarbage(ctx, key, obj)
+	}
+
+	if !sets.NewString(obj.Finalizers...).Has(snapshotFinalizer) {
+		obj = obj.DeepCopy()
+		obj.Finalizers = append(obj.Finalizers, snapshotFinalizer)
+		// the update triggers another reconciliation
+		_, err := c.kcpClusterClient.Cluster(clusterName).TenancyV1alpha1().WorkspaceSnapshots().Update(ctx, obj, metav1.UpdateOptions{})
+		return err
+	}
+
+	snapshot := obj.DeepCopy()
+	requeue, reconcileErr := c.reconcile(ctx, snapshot, secretName)
+	if requeue {
+		c.queue.AddAfter(key, workspaceNotReadyRequeueDelay)
+	}
+	if snapshot.Status.Phase == "" {
+		snapshot.Status.Phase = jobsv1alpha1.JobPhasePending
+	}
+	if !equality.Semantic.DeepEqual(obj.Status, snapshot.Status) {
+		if _, err := c.kcpClusterClient.Cluster(clusterName).TenancyV1alpha1().WorkspaceSnapshots().UpdateStatus(ctx, snapshot, metav1.UpdateOptions{}); err != nil {
+			return err
+		}
+	}
+	return reconcileErr
+}
+
+// reconcile captures the objects of the workspace of the given snapshot and stores them in the
+// given Secret. It returns true if the workspace is not ready yet.
+func (c *Controller) reconcile(ctx context.Context, snapshot *tenancyv1alpha1.WorkspaceSnapshot, secretName string) (bool, error) {
+	clusterName := logicalcluster.From(snapshot)
+
+	workspace, err := c.workspaceLister.Get(clusters.ToClusterAwareKey(clusterName, snapshot.Spec.Workspace))
+	if err != nil && !errors.IsNotFound(err) {
+		return false, err
+	}
+	if errors.IsNotFound(err) || workspace.Status.Phase != tenancyv1alpha1.ClusterWorkspacePhaseReady {
+		conditions.MarkFalse(snapshot, tenancyv1alpha1.WorkspaceSnapshotCaptured, tenancyv1alpha1.WorkspaceSnapshotReasonWorkspaceNotFound, conditionsv1alpha1.ConditionSeverityInfo,
+			"Workspace %q does not exist or is not ready", snapshot.Spec.Workspace)
+		return true, nil
+	}
+
+	jobs.MarkRunning(snapshot, metav1.NewTime(c.now()))
+	entries, rv, err := c.capture(ctx, clusterName.Join(snapshot.Spec.Workspace))
+	if err != nil {
+		conditions.MarkFalse(snapshot, tenancyv1alpha1.WorkspaceSnapshotCaptured, tenancyv1alpha1.WorkspaceSnapshotReasonCaptureFailed, conditionsv1alpha1.ConditionSeverityWarning,
+			"Failed to capture workspace %q: %v", snapshot.Spec.Workspace, err)
+		return false, err
+	}
+	data, err := encode(entries)
+	if err != nil {
+		return false, err
+	}
+	if len(data) > maxSnapshotSize {
+		message := fmt.Sprintf("The %d objects of workspace %q exceed the maximal snapshot size of %d bytes compressed", len(entries), snapshot.Spec.Workspace, maxSnapshotSize)
+		jobs.MarkFailed(snapshot, metav1.NewTime(c.now()), "%s", message)
+		conditions.MarkFalse(snapshot, tenancyv1alpha1.WorkspaceSnapshotCaptured, tenancyv1alpha1.WorkspaceSnapshotReasonTooLarge, conditionsv1alpha1.ConditionSeverityError, "%s", message)
+		return false, nil
+	}
+	if err := storeSnapshot(ctx, c.kubeClusterClient, secretName, data); err != nil {
+		conditions.MarkFalse(snapshot, tenancyv1alpha1.WorkspaceSnapshotCaptured, tenancyv1alpha1.WorkspaceSnapshotReasonCaptureFailed, conditionsv1alpha1.ConditionSeverityWarning,
+			"Failed to store the snapshot: %v", err)
+		return false, err
+	}
+
+	jobs.MarkSucceeded(snapshot, metav1.NewTime(c.now()))
+	snapshot.Status.ResourceVersion = rv
+	snapshot.Status.ObjectCount = len(entries)
+	conditions.MarkTrue(snapshot, tenancyv1alpha1.WorkspaceSnapshotCaptured)
+	return false, nil
+}
+
+// collectGarbage deletes the finished siblings of the given finished snapshot whose TTL has expired or
+// which exceed the history limits, and requeues the snapshot when the next TTL expires. Snapshots
+// still being restored into a workspace are kept.
+func (c *Controller) collectGarbage(ctx context.Context, key string, snapshot *tenancyv1alpha1.WorkspaceSnapshot) error {
+	clusterName := logicalcluster.From(snapshot)
+	objs, err := c.snapshotIndexer.ByIndex(byWorkspace, clusters.ToClusterAwareKey(clusterName, snapshot.Spec.Workspace))
+	if err != nil {
+		return err
+	}
+	restoring, err := c.restoringSnapshots(clusterName)
+	if err != nil {
+		return err
+	}
+	var siblings []jobs.Job
+	for _, obj := range objs {
+		sibling := obj.(*tenancyv1alpha1.WorkspaceSnapshot)
+		if sibling.DeletionTimestamp == nil && !restoring.Has(sibling.Name) {
+			siblings = append(siblings, sibling)
+		}
+	}
+
+	toDelete, requeueAfter := jobs.GarbageCollect(siblings, c.now())
+	var errs []error
+	for _, job := range toDelete {
+		logging.WithObject(logging.FromContext(ctx), job).V(2).Info("Deleting finished WorkspaceSnapshot")
+		if err := c.deleteSnapshot(ctx, clusterName, job.GetName()); err != nil && !errors.IsNotFound(err) {
+			errs = append(errs, err)
+		}
+	}
+	if requeueAfter > 0 {
+		c.queue.AddAfter(key, requeueAfter)
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// restoringSnapshots returns the names of the snapshots in the given logical cluster that workspaces
+// are still being created from.
+func (c *Controller) restoringSnapshots(clusterName logicalcluster.Name) (sets.String, error) {
+	workspaces, err := c.workspaceLister.List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	restoring := sets.NewString()
+	for _, workspace := range workspaces {
+		name := workspace.Annotations[tenancyv1alpha1.ExperimentalWorkspaceSnapshotAnnotationKey]
+		if name == "" || logicalcluster.From(workspace) != clusterName {
+			continue
+		}
+		if workspace.Status.Phase == "" || workspace.Status.Phase == tenancyv1alpha1.ClusterWorkspacePhaseScheduling || hasInitializer(workspace, tenancyv1alpha1.WorkspaceSnapshotInitializer) {
+			restoring.Insert(name)
+		}
+	}
+	return restoring, nil
+}
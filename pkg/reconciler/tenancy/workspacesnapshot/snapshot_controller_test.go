@@ -0,0 +1,99 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workspacesnapshot
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kcp-dev/logicalcluster"
+	"github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/clusters"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/utils/pointer"
+
+	jobsv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/jobs/v1alpha1"
+	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+	tenancylister "github.com/kcp-dev/kcp/pkg/client/listers/tenancy/v1alpha1"
+)
+
+func TestCollectGarbage(t *testing.T) {
+	now := time.Date(2022, 5, 1, 10, 0, 0, 0, time.UTC)
+	snapshot := func(cluster, name, workspace string, phase jobsv1alpha1.JobPhase, finishedAgo time.Duration) *tenancyv1alpha1.WorkspaceSnapshot {
+		s := &tenancyv1alpha1.WorkspaceSnapshot{
+			ObjectMeta: metav1.ObjectMeta{Name: name, ClusterName: cluster},
+			Spec: tenancyv1alpha1.WorkspaceSnapshotSpec{
+				Workspace: workspace,
+				JobPolicy: jobsv1alpha1.JobPolicy{SuccessfulJobsHistoryLimit: pointer.Int32(1), TTLSecondsAfterFinished: pointer.Int32(3600)},
+			},
+			Status: tenancyv1alpha1.WorkspaceSnapshotStatus{JobStatus: jobsv1alpha1.JobStatus{Phase: phase}},
+		}
+		if phase == jobsv1alpha1.JobPhaseSucceeded || phase == jobsv1alpha1.JobPhaseFailed {
+			finished := metav1.NewTime(now.Add(-finishedAgo))
+			s.Status.CompletionTime = &finished
+		}
+		return s
+	}
+
+	snapshotIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{byWorkspace: indexByWorkspace})
+	for _, s := range []*tenancyv1alpha1.WorkspaceSnapshot{
+		snapshot("root:org", "newest", "staging", jobsv1alpha1.JobPhaseSucceeded, time.Minute),
+		snapshot("root:org", "older", "staging", jobsv1alpha1.JobPhaseSucceeded, 10*time.Minute),
+		snapshot("root:org", "restoring", "staging", jobsv1alpha1.JobPhaseSucceeded, 20*time.Minute),
+		snapshot("root:org", "expired", "staging", jobsv1alpha1.JobPhaseFailed, 2*time.Hour),
+		snapshot("root:org", "pending", "staging", jobsv1alpha1.JobPhasePending, 0),
+		snapshot("root:org", "other-workspace", "prod", jobsv1alpha1.JobPhaseSucceeded, 10*time.Minute),
+		snapshot("root:other", "other-cluster", "staging", jobsv1alpha1.JobPhaseSucceeded, 10*time.Minute),
+	} {
+		require.NoError(t, snapshotIndexer.Add(s))
+	}
+
+	workspaceIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	require.NoError(t, workspaceIndexer.Add(&tenancyv1alpha1.ClusterWorkspace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "copy",
+			ClusterName: "root:org",
+			Annotations: map[string]string{tenancyv1alpha1.ExperimentalWorkspaceSnapshotAnnotationKey: "restoring"},
+		},
+		Status: tenancyv1alpha1.ClusterWorkspaceStatus{
+			Phase:        tenancyv1alpha1.ClusterWorkspacePhaseInitializing,
+			Initializers: []tenancyv1alpha1.ClusterWorkspaceInitializer{tenancyv1alpha1.WorkspaceSnapshotInitializer},
+		},
+	}))
+
+	deleted := sets.NewString()
+	c := &Controller{
+		queue: workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter()),
+		deleteSnapshot: func(ctx context.Context, clusterName logicalcluster.Name, name string) error {
+			deleted.Insert(clusters.ToClusterAwareKey(clusterName, name))
+			return nil
+		},
+		snapshotIndexer: snapshotIndexer,
+		workspaceLister: tenancylister.NewClusterWorkspaceLister(workspaceIndexer),
+		now:             func() time.Time { return now },
+	}
+	defer c.queue.ShutDown()
+
+	newest := snapshot("root:org", "newest", "staging", jobsv1alpha1.JobPhaseSucceeded, time.Minute)
+	require.NoError(t, c.collectGarbage(context.Background(), "root:org|newest", newest))
+	require.Equal(t, []string{"root:org|expired", "root:org|older"}, deleted.List(), "snapshots being restored are kept")
+}
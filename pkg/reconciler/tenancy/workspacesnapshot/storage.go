@@ -0,0 +1,140 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workspacesnapshot
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/kcp-dev/logicalcluster"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+)
+
+var (
+	// StorageCluster is the system logical cluster holding the captured objects of all snapshots,
+	// as they may contain secrets not to be exposed to readers of the WorkspaceSnapshots.
+	StorageCluster = logicalcluster.New("system:workspace-snapshots")
+
+	// storageNamespace is the namespace in StorageCluster holding the captured objects.
+	storageNamespace = "default"
+)
+
+const (
+	// maxSnapshotSize is the maximal compressed size of the captured objects, leaving some room
+	// below the size limit of a Secret.
+	maxSnapshotSize = 1000 * 1024
+
+	// snapshotDataKey is the key of the compressed objects in the storage Secret.
+	snapshotDataKey = "objects.json.gz"
+)
+
+// entry is a captured object with the resource it has been captured from.
+type entry struct {
+	Group    string                     `json:"group,omitempty"`
+	Version  string                     `json:"version"`
+	Resource string                     `json:"resource"`
+	Object   *unstructured.Unstructured `json:"object"`
+}
+
+func (e *entry) GroupVersionResource() schema.GroupVersionResource {
+	return schema.GroupVersionResource{Group: e.Group, Version: e.Version, Resource: e.Resource}
+}
+
+// storageSecretName returns the name of the Secret in StorageCluster holding the captured objects
+// of the snapshot with the given name in the given logical cluster.
+func storageSecretName(clusterName logicalcluster.Name, snapshotName string) string {
+	hash := sha256.Sum224([]byte(clusterName.String() + "|" + snapshotName))
+	return fmt.Sprintf("snapshot-%x", hash)
+}
+
+// encode serializes and compresses the given entries.
+func encode(entries []entry) ([]byte, error) {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if err := json.NewEncoder(w).Encode(entries); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// decode is the inverse of encode.
+func decode(data []byte) ([]entry, error) {
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	bs, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var entries []entry
+	if err := json.Unmarshal(bs, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// storeSnapshot stores the compressed objects in a Secret in StorageCluster.
+func storeSnapshot(ctx context.Context, kubeClusterClient kubernetes.ClusterInterface, name string, data []byte) error {
+	client := kubeClusterClient.Cluster(StorageCluster)
+	if _, err := client.CoreV1().Namespaces().Create(ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: storageNamespace}}, metav1.CreateOptions{}); err != nil && !errors.IsAlreadyExists(err) {
+		return err
+	}
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: storageNamespace},
+		Data:       map[string][]byte{snapshotDataKey: data},
+	}
+	_, err := client.CoreV1().Secrets(storageNamespace).Create(ctx, secret, metav1.CreateOptions{})
+	if errors.IsAlreadyExists(err) {
+		// a previous capture failed to update the status
+		_, err = client.CoreV1().Secrets(storageNamespace).Update(ctx, secret, metav1.UpdateOptions{})
+	}
+	return err
+}
+
+// loadSnapshot returns the objects stored by storeSnapshot.
+func loadSnapshot(ctx context.Context, kubeClusterClient kubernetes.ClusterInterface, name string) ([]entry, error) {
+	secret, err := kubeClusterClient.Cluster(StorageCluster).CoreV1().Secrets(storageNamespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+	return decode(secret.Data[snapshotDataKey])
+}
+
+// deleteSnapshot deletes the objects stored by storeSnapshot, if they exist.
+func deleteSnapshot(ctx context.Context, kubeClusterClient kubernetes.ClusterInterface, name string) error {
+	err := kubeClusterClient.Cluster(StorageCluster).CoreV1().Secrets(storageNamespace).Delete(ctx, name, metav1.DeleteOptions{})
+	if errors.IsNotFound(err) {
+		return nil
+	}
+	return err
+}
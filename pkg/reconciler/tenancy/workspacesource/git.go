@@ -0,0 +1,94 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workspacesource
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+)
+
+// fetchTimeout is the maximal duration of cloning a repository.
+const fetchTimeout = 2 * time.Minute
+
+// gitAuth holds the basic auth credentials for a repository.
+type gitAuth struct {
+	username string
+	password string
+}
+
+// fetchGit shallow-clones the ref of the repository of the given spec into dir with the git binary,
+// and returns the checked out commit. Redirects are not followed and submodules are not cloned, i.e.
+// only the host of the URL is contacted.
+func fetchGit(ctx context.Context, spec *tenancyv1alpha1.WorkspaceSourceSpec, auth *gitAuth, dir string) (string, error) {
+	u, err := url.Parse(spec.URL)
+	if err != nil {
+		return "", err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", fmt.Errorf("unsupported URL scheme %q, only http and https are allowed", u.Scheme)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, fetchTimeout)
+	defer cancel()
+
+	env := append(os.Environ(),
+		"GIT_TERMINAL_PROMPT=0",
+		"GIT_CONFIG_NOSYSTEM=1",
+		// also applies to redirects and submodules
+		"GIT_ALLOW_PROTOCOL=http:https",
+	)
+	// redirects could lead to hosts that are not allowed
+	config := [][2]string{{"http.followRedirects", "false"}}
+	if auth != nil {
+		// passed via environment to keep the credentials out of the process list
+		credentials := base64.StdEncoding.EncodeToString([]byte(auth.username + ":" + auth.password))
+		config = append(config, [2]string{"http.extraHeader", "Authorization: Basic " + credentials})
+	}
+	env = append(env, fmt.Sprintf("GIT_CONFIG_COUNT=%d", len(config)))
+	for i, kv := range config {
+		env = append(env, fmt.Sprintf("GIT_CONFIG_KEY_%d=%s", i, kv[0]), fmt.Sprintf("GIT_CONFIG_VALUE_%d=%s", i, kv[1]))
+	}
+
+	args := []string{"clone", "--depth=1", "--single-branch"}
+	if spec.Ref != "" {
+		args = append(args, "--branch", spec.Ref)
+	}
+	args = append(args, "--", spec.URL, dir)
+	if _, err := runGit(ctx, env, args...); err != nil {
+		return "", err
+	}
+
+	return runGit(ctx, env, "-C", dir, "rev-parse", "HEAD")
+}
+
+func runGit(ctx context.Context, env []string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Env = env
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("git %s failed: %w: %s", args[0], err, strings.TrimSpace(string(out)))
+	}
+	return strings.TrimSpace(string(out)), nil
+}
@@ -0,0 +1,137 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workspacesource
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/yaml"
+)
+
+// maxManifestSize is the maximal size of all manifests of a WorkspaceSource.
+const maxManifestSize = 10 * 1024 * 1024
+
+// readManifests decodes the YAML and JSON manifests in the given path of the checkout in dir,
+// including its subdirectories. Hidden files and directories, and symlinks are skipped, such that
+// a repository cannot refer to files outside of the checkout.
+func readManifests(dir, path string) ([]*unstructured.Unstructured, error) {
+	root := filepath.Join(dir, filepath.FromSlash(path))
+	resolvedDir, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return nil, err
+	}
+	resolvedRoot, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("path %q does not exist", path)
+		}
+		return nil, err
+	}
+	if rel, err := filepath.Rel(resolvedDir, resolvedRoot); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return nil, fmt.Errorf("path %q is outside of the repository", path)
+	}
+
+	var objs []*unstructured.Unstructured
+	var size int64
+	err = filepath.Walk(resolvedRoot, func(file string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if file != resolvedRoot && strings.HasPrefix(info.Name(), ".") {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		switch filepath.Ext(file) {
+		case ".yaml", ".yml", ".json":
+		default:
+			return nil
+		}
+
+		size += info.Size()
+		if size > maxManifestSize {
+			return fmt.Errorf("manifests exceed the maximal size of %d bytes", maxManifestSize)
+		}
+
+		rel, _ := filepath.Rel(resolvedDir, file)
+		fileObjs, err := decodeManifests(file)
+		if err != nil {
+			return fmt.Errorf("%s: %w", filepath.ToSlash(rel), err)
+		}
+		objs = append(objs, fileObjs...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return objs, nil
+}
+
+// decodeManifests decodes the objects of a file with one or many YAML documents, or JSON. Lists
+// are expanded into their items.
+func decodeManifests(file string) ([]*unstructured.Unstructured, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var objs []*unstructured.Unstructured
+	decoder := yaml.NewYAMLOrJSONDecoder(f, 4096)
+	for {
+		var content map[string]interface{}
+		if err := decoder.Decode(&content); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, err
+		}
+		if len(content) == 0 {
+			continue // empty document
+		}
+
+		obj := &unstructured.Unstructured{Object: content}
+		if obj.IsList() {
+			list, err := obj.ToList()
+			if err != nil {
+				return nil, err
+			}
+			for i := range list.Items {
+				objs = append(objs, &list.Items[i])
+			}
+			continue
+		}
+		objs = append(objs, obj)
+	}
+
+	for _, obj := range objs {
+		if obj.GetAPIVersion() == "" || obj.GetKind() == "" || obj.GetName() == "" {
+			return nil, fmt.Errorf("object without apiVersion, kind or name")
+		}
+	}
+	return objs, nil
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workspacesource
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+)
+
+func TestReadManifests(t *testing.T) {
+	dir := t.TempDir()
+	outside := t.TempDir()
+	files := map[string]string{
+		"app/list.yaml": `apiVersion: v1
+kind: List
+items:
+- apiVersion: v1
+  kind: ConfigMap
+  metadata:
+    name: a
+- apiVersion: v1
+  kind: ConfigMap
+  metadata:
+    name: b
+---
+`,
+		"app/.hidden.yaml":     "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: hidden\n",
+		"app/.github/ci.yaml":  "on: push\n",
+		"app/notes.txt":        "not a manifest",
+		"app/sub/secret.yml":   "apiVersion: v1\nkind: Secret\nmetadata:\n  name: c\n",
+		"other/ignored.yaml":   "apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: other\n",
+		"broken/missing.yaml":  "apiVersion: v1\nkind: ConfigMap\n",
+		"broken/invalid.json":  "{",
+		"app/sub/z-empty.yaml": "---\n---\n",
+	}
+	for file, content := range files {
+		require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(dir, file)), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, file), []byte(content), 0644))
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(outside, "leak.yaml"), []byte("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: leak\n"), 0644))
+	require.NoError(t, os.Symlink(filepath.Join(outside, "leak.yaml"), filepath.Join(dir, "app", "leak.yaml")))
+	require.NoError(t, os.Symlink(outside, filepath.Join(dir, "app", "linked")))
+	require.NoError(t, os.Symlink(outside, filepath.Join(dir, "escape")))
+
+	objs, err := readManifests(dir, "app")
+	require.NoError(t, err)
+	var names []string
+	for _, obj := range objs {
+		names = append(names, obj.GetKind()+"/"+obj.GetName())
+	}
+	require.Equal(t, []string{"ConfigMap/a", "ConfigMap/b", "Secret/c"}, names)
+
+	_, err = readManifests(dir, "broken")
+	require.Error(t, err)
+
+	_, err = readManifests(dir, "missing")
+	require.EqualError(t, err, `path "missing" does not exist`)
+
+	_, err = readManifests(dir, "../"+filepath.Base(outside))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "outside of the repository")
+
+	_, err = readManifests(dir, "escape")
+	require.EqualError(t, err, `path "escape" is outside of the repository`)
+}
+
+func TestFetchGitRejectsLocalURLs(t *testing.T) {
+	for _, u := range []string{"file:///etc", "/etc", "ssh://git@example.com/repo.git", "ext::sh -c touch% /tmp/pwned"} {
+		_, err := fetchGit(context.Background(), &tenancyv1alpha1.WorkspaceSourceSpec{URL: u}, nil, t.TempDir())
+		require.Error(t, err, u)
+	}
+}
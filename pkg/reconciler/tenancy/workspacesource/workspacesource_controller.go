@@ -0,0 +1,260 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workspacesource
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/kcp-dev/logicalcluster"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apiserver/pkg/authentication/serviceaccount"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+
+	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+	kcpclient "github.com/kcp-dev/kcp/pkg/client/clientset/versioned"
+	tenancyinformer "github.com/kcp-dev/kcp/pkg/client/informers/externalversions/tenancy/v1alpha1"
+	tenancylister "github.com/kcp-dev/kcp/pkg/client/listers/tenancy/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/logging"
+	conditionsv1alpha1 "github.com/kcp-dev/kcp/third_party/conditions/apis/conditions/v1alpha1"
+	"github.com/kcp-dev/kcp/third_party/conditions/util/conditions"
+)
+
+const (
+	controllerName = "kcp-workspace-source"
+
+	// fieldManager is the server-side apply field manager of the applied objects.
+	fieldManager = "kcp-workspace-source"
+
+	// defaultInterval is used for WorkspaceSources without interval.
+	defaultInterval = 5 * time.Minute
+)
+
+// NewController returns a controller applying the manifests of the Git repositories referenced
+// by WorkspaceSources to their workspaces. The secret is read, and the objects are applied and
+// pruned, impersonating the service account of the WorkspaceSource with the given config.
+func NewController(
+	kcpClusterClient kcpclient.ClusterInterface,
+	config *rest.Config,
+	listResources func(clusterName logicalcluster.Name) ([]*metav1.APIResourceList, error),
+	sourceInformer tenancyinformer.WorkspaceSourceInformer,
+	options Options,
+) *Controller {
+	queue := workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), controllerName)
+
+	force := true
+	c := &Controller{
+		queue:            queue,
+		kcpClusterClient: kcpClusterClient,
+		listResources:    listResources,
+		allowedHosts:     options.AllowedHosts,
+		getSecret: func(ctx context.Context, clusterName logicalcluster.Name, user rest.ImpersonationConfig, namespace, name string) (*corev1.Secret, error) {
+			kubeClusterClient, err := kubernetes.NewClusterForConfig(impersonatingConfig(config, user))
+			if err != nil {
+				return nil, err
+			}
+			return kubeClusterClient.Cluster(clusterName).CoreV1().Secrets(namespace).Get(ctx, name, metav1.GetOptions{})
+		},
+		fetch: fetchGit,
+		applyObject: func(ctx context.Context, clusterName logicalcluster.Name, user rest.ImpersonationConfig, gvr schema.GroupVersionResource, obj *unstructured.Unstructured) error {
+			dynamicClusterClient, err := dynamic.NewClusterForConfig(impersonatingConfig(config, user))
+			if err != nil {
+				return err
+			}
+			data, err := obj.MarshalJSON()
+			if err != nil {
+				return err
+			}
+			_, err = dynamicClusterClient.Cluster(clusterName).Resource(gvr).Namespace(obj.GetNamespace()).Patch(ctx, obj.GetName(), types.ApplyPatchType, data, metav1.PatchOptions{FieldManager: fieldManager, Force: &force})
+			return err
+		},
+		deleteObject: func(ctx context.Context, clusterName logicalcluster.Name, user rest.ImpersonationConfig, gvr schema.GroupVersionResource, namespace, name string) error {
+			dynamicClusterClient, err := dynamic.NewClusterForConfig(impersonatingConfig(config, user))
+			if err != nil {
+				return err
+			}
+			return dynamicClusterClient.Cluster(clusterName).Resource(gvr).Namespace(namespace).Delete(ctx, name, metav1.DeleteOptions{})
+		},
+		sourceLister: sourceInformer.Lister(),
+		now:          time.Now,
+	}
+
+	sourceInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) { c.enqueue(obj) },
+		UpdateFunc: func(oldObj, obj interface{}) {
+			// status updates are ignored, the repository is polled in the interval of the source
+			oldSource, ok := oldObj.(*tenancyv1alpha1.WorkspaceSource)
+			if !ok {
+				return
+			}
+			source, ok := obj.(*tenancyv1alpha1.WorkspaceSource)
+			if !ok {
+				return
+			}
+			if oldSource.Generation != source.Generation {
+				c.enqueue(obj)
+			}
+		},
+	})
+
+	return c
+}
+
+// Controller applies the manifests of the Git repository referenced by a WorkspaceSource to its
+// workspace in the interval of the source, and prunes objects removed from the repository.
+type Controller struct {
+	queue workqueue.RateLimitingInterface
+
+	kcpClusterClient kcpclient.ClusterInterface
+
+	// allowedHosts are the hosts of the repositories that may be fetched, see hostAllowed.
+	allowedHosts []string
+
+	listResources func(clusterName logicalcluster.Name) ([]*metav1.APIResourceList, error)
+	getSecret     func(ctx context.Context, clusterName logicalcluster.Name, user rest.ImpersonationConfig, namespace, name string) (*corev1.Secret, error)
+	fetch         func(ctx context.Context, spec *tenancyv1alpha1.WorkspaceSourceSpec, auth *gitAuth, dir string) (string, error)
+	applyObject   func(ctx context.Context, clusterName logicalcluster.Name, user rest.ImpersonationConfig, gvr schema.GroupVersionResource, obj *unstructured.Unstructured) error
+	deleteObject  func(ctx context.Context, clusterName logicalcluster.Name, user rest.ImpersonationConfig, gvr schema.GroupVersionResource, namespace, name string) error
+
+	sourceLister tenancylister.WorkspaceSourceLister
+
+	now func() time.Time
+}
+
+func (c *Controller) enqueue(obj interface{}) {
+	key, err := cache.MetaNamespaceKeyFunc(obj)
+	if err != nil {
+		runtime.HandleError(err)
+		return
+	}
+	logging.WithQueueKey(logging.NewLogger(controllerName), key).V(2).Info("Queueing WorkspaceSource")
+	c.queue.Add(key)
+}
+
+func (c *Controller) Start(ctx context.Context, numThreads int) {
+	defer runtime.HandleCrash()
+	defer c.queue.ShutDown()
+
+	klog.Info("Starting WorkspaceSource controller")
+	defer klog.Info("Shutting down WorkspaceSource controller")
+
+	for i := 0; i < numThreads; i++ {
+		go wait.UntilWithContext(ctx, c.startWorker, time.Second)
+	}
+
+	<-ctx.Done()
+}
+
+func (c *Controller) startWorker(ctx context.Context) {
+	for c.processNextWorkItem(ctx) {
+	}
+}
+
+func (c *Controller) processNextWorkItem(ctx context.Context) bool {
+	// Wait until there is a new item in the working queue
+	k, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+	key := k.(string)
+
+	logger := logging.WithQueueKey(logging.NewLogger(controllerName), key)
+	ctx = logging.NewContext(ctx, logger)
+
+	// No matter what, tell the queue we're done with this key, to unblock
+	// other workers.
+	defer c.queue.Done(key)
+
+	if err := c.process(ctx, key); err != nil {
+		runtime.HandleError(fmt.Errorf("%q controller failed to sync %q, err: %w", controllerName, key, err))
+		c.queue.AddRateLimited(key)
+		return true
+	}
+	c.queue.Forget(key)
+	return true
+}
+
+func (c *Controller) process(ctx context.Context, key string) error {
+	obj, err := c.sourceLister.Get(key)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil // object deleted before we handled it
+		}
+		return err
+	}
+	if obj.DeletionTimestamp != nil {
+		return nil
+	}
+
+	source := obj.DeepCopy()
+	var reconcileErr error
+	if source.Spec.Suspend {
+		conditions.MarkFalse(source, tenancyv1alpha1.WorkspaceSourceSynced, tenancyv1alpha1.WorkspaceSourceReasonSuspended, conditionsv1alpha1.ConditionSeverityInfo,
+			"The reconciliation of the repository is suspended")
+	} else {
+		reconcileErr = c.reconcile(ctx, source)
+		if reconcileErr == nil {
+			c.queue.AddAfter(key, interval(source))
+		}
+	}
+
+	if !equality.Semantic.DeepEqual(obj.Status, source.Status) {
+		if _, err := c.kcpClusterClient.Cluster(logicalcluster.From(source)).TenancyV1alpha1().WorkspaceSources().UpdateStatus(ctx, source, metav1.UpdateOptions{}); err != nil {
+			return err
+		}
+	}
+	return reconcileErr
+}
+
+// serviceAccountUser returns the user of the service account of the given source, bound to the
+// logical cluster of the source.
+func serviceAccountUser(clusterName logicalcluster.Name, ref tenancyv1alpha1.WorkspaceSourceServiceAccountReference) rest.ImpersonationConfig {
+	return rest.ImpersonationConfig{
+		UserName: serviceaccount.MakeUsername(ref.Namespace, ref.Name),
+		Groups:   append(serviceaccount.MakeGroupNames(ref.Namespace), user.AllAuthenticated),
+		Extra:    map[string][]string{serviceaccount.ClusterNameKey: {clusterName.String()}},
+	}
+}
+
+func impersonatingConfig(config *rest.Config, user rest.ImpersonationConfig) *rest.Config {
+	config = rest.CopyConfig(config)
+	config.Impersonate = user
+	return config
+}
+
+func interval(source *tenancyv1alpha1.WorkspaceSource) time.Duration {
+	if source.Spec.Interval.Duration <= 0 {
+		return defaultInterval
+	}
+	return source.Spec.Interval.Duration
+}
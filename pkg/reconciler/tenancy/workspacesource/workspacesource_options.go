@@ -0,0 +1,62 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workspacesource
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/pflag"
+)
+
+func DefaultOptions() *Options {
+	return &Options{}
+}
+
+func BindOptions(o *Options, fs *pflag.FlagSet) *Options {
+	fs.StringSliceVar(&o.AllowedHosts, "workspace-source-allowed-hosts", o.AllowedHosts, "Hosts of the Git repositories WorkspaceSources may fetch from, e.g. github.com or *.example.com for all subdomains. Repositories on other hosts are not fetched. Empty means none.")
+	return o
+}
+
+type Options struct {
+	AllowedHosts []string
+}
+
+func (o *Options) Validate() error {
+	for _, host := range o.AllowedHosts {
+		if host == "" || strings.ContainsAny(strings.TrimPrefix(host, "*."), "*/:") {
+			return fmt.Errorf("--workspace-source-allowed-hosts must contain host names or *.<domain> (%q)", host)
+		}
+	}
+	return nil
+}
+
+// hostAllowed returns true if the given host matches one of the given allowed hosts.
+func hostAllowed(allowedHosts []string, host string) bool {
+	host = strings.ToLower(host)
+	for _, allowed := range allowedHosts {
+		allowed = strings.ToLower(allowed)
+		if strings.HasPrefix(allowed, "*.") {
+			if strings.HasSuffix(host, allowed[1:]) {
+				return true
+			}
+		} else if host == allowed {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,248 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workspacesource
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/kcp-dev/logicalcluster"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+
+	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+	conditionsv1alpha1 "github.com/kcp-dev/kcp/third_party/conditions/apis/conditions/v1alpha1"
+	"github.com/kcp-dev/kcp/third_party/conditions/util/conditions"
+)
+
+// resourceInfo is the resource of a kind, and whether it is namespaced.
+type resourceInfo struct {
+	gvr        schema.GroupVersionResource
+	namespaced bool
+}
+
+// reconcile applies the manifests of the repository of the given source to its workspace, and
+// prunes the objects of the last applied revision that are not part of the manifests anymore.
+func (c *Controller) reconcile(ctx context.Context, source *tenancyv1alpha1.WorkspaceSource) error {
+	clusterName := logicalcluster.From(source)
+	user := serviceAccountUser(clusterName, source.Spec.ServiceAccountRef)
+
+	u, err := url.Parse(source.Spec.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		// retrying does not help before the next spec change
+		conditions.MarkFalse(source, tenancyv1alpha1.WorkspaceSourceSynced, tenancyv1alpha1.WorkspaceSourceReasonFetchFailed, conditionsv1alpha1.ConditionSeverityError,
+			"Invalid URL %q, only http and https are allowed", source.Spec.URL)
+		return nil
+	}
+	if !hostAllowed(c.allowedHosts, u.Hostname()) {
+		conditions.MarkFalse(source, tenancyv1alpha1.WorkspaceSourceSynced, tenancyv1alpha1.WorkspaceSourceReasonFetchFailed, conditionsv1alpha1.ConditionSeverityError,
+			"Fetching from host %q is not allowed", u.Hostname())
+		return nil
+	}
+
+	var auth *gitAuth
+	if ref := source.Spec.SecretRef; ref != nil {
+		secret, err := c.getSecret(ctx, clusterName, user, ref.Namespace, ref.Name)
+		if err != nil {
+			conditions.MarkFalse(source, tenancyv1alpha1.WorkspaceSourceSynced, tenancyv1alpha1.WorkspaceSourceReasonFetchFailed, conditionsv1alpha1.ConditionSeverityError,
+				"Failed to get secret %s/%s: %v", ref.Namespace, ref.Name, err)
+			return err
+		}
+		auth = &gitAuth{username: string(secret.Data["username"]), password: string(secret.Data["password"])}
+	}
+
+	dir, err := os.MkdirTemp("", "kcp-workspace-source-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	revision, err := c.fetch(ctx, &source.Spec, auth, dir)
+	if err != nil {
+		conditions.MarkFalse(source, tenancyv1alpha1.WorkspaceSourceSynced, tenancyv1alpha1.WorkspaceSourceReasonFetchFailed, conditionsv1alpha1.ConditionSeverityError,
+			"Failed to fetch %s: %v", source.Spec.URL, err)
+		return err
+	}
+
+	objs, err := readManifests(dir, source.Spec.Path)
+	if err != nil {
+		// retrying does not help before the next revision
+		conditions.MarkFalse(source, tenancyv1alpha1.WorkspaceSourceSynced, tenancyv1alpha1.WorkspaceSourceReasonInvalidManifests, conditionsv1alpha1.ConditionSeverityError,
+			"Invalid manifests in revision %s: %v", revision, err)
+		return nil
+	}
+
+	resourceLists, err := c.listResources(clusterName)
+	if err != nil && len(resourceLists) == 0 {
+		return err
+	}
+	resources := map[schema.GroupVersionKind]resourceInfo{}
+	for _, list := range resourceLists {
+		gv, err := schema.ParseGroupVersion(list.GroupVersion)
+		if err != nil {
+			continue
+		}
+		for _, r := range list.APIResources {
+			if strings.Contains(r.Name, "/") {
+				continue // subresource
+			}
+			resources[gv.WithKind(r.Kind)] = resourceInfo{gvr: gv.WithResource(r.Name), namespaced: r.Namespaced}
+		}
+	}
+
+	sortForApply(objs)
+	var applied []tenancyv1alpha1.WorkspaceSourceObject
+	var errs []error
+	for _, obj := range objs {
+		info, ok := resources[obj.GroupVersionKind()]
+		if !ok {
+			// e.g. the CRD of the kind is applied in the same revision, and not served yet
+			errs = append(errs, fmt.Errorf("%s %q: no resource found for kind %s", obj.GetKind(), obj.GetName(), obj.GroupVersionKind()))
+			continue
+		}
+		if !info.namespaced {
+			obj.SetNamespace("")
+		} else if obj.GetNamespace() == "" {
+			obj.SetNamespace(metav1.NamespaceDefault)
+		}
+		if err := c.applyObject(ctx, clusterName, user, info.gvr, obj); err != nil {
+			errs = append(errs, fmt.Errorf("%s %s: %w", info.gvr.GroupResource(), objectName(obj.GetNamespace(), obj.GetName()), err))
+			continue
+		}
+		applied = append(applied, tenancyv1alpha1.WorkspaceSourceObject{
+			Group:     info.gvr.Group,
+			Version:   info.gvr.Version,
+			Resource:  info.gvr.Resource,
+			Namespace: obj.GetNamespace(),
+			Name:      obj.GetName(),
+		})
+	}
+
+	if len(errs) > 0 {
+		// keep the objects of the last applied revision for pruning on the next successful apply
+		source.Status.Objects = mergeObjects(source.Status.Objects, applied)
+		err := utilerrors.NewAggregate(errs)
+		conditions.MarkFalse(source, tenancyv1alpha1.WorkspaceSourceSynced, tenancyv1alpha1.WorkspaceSourceReasonApplyFailed, conditionsv1alpha1.ConditionSeverityWarning,
+			"Failed to apply revision %s: %v", revision, err)
+		return err
+	}
+
+	if source.Spec.Prune {
+		keep := map[tenancyv1alpha1.WorkspaceSourceObject]bool{}
+		for _, ref := range applied {
+			keep[objectKey(ref)] = true
+		}
+		var remaining []tenancyv1alpha1.WorkspaceSourceObject
+		for _, ref := range source.Status.Objects {
+			if keep[objectKey(ref)] {
+				continue
+			}
+			gvr := schema.GroupVersionResource{Group: ref.Group, Version: ref.Version, Resource: ref.Resource}
+			if err := c.deleteObject(ctx, clusterName, user, gvr, ref.Namespace, ref.Name); err != nil && !errors.IsNotFound(err) {
+				errs = append(errs, fmt.Errorf("%s %s: %w", gvr.GroupResource(), objectName(ref.Namespace, ref.Name), err))
+				remaining = append(remaining, ref)
+			}
+		}
+		if len(errs) > 0 {
+			source.Status.Objects = mergeObjects(remaining, applied)
+			err := utilerrors.NewAggregate(errs)
+			conditions.MarkFalse(source, tenancyv1alpha1.WorkspaceSourceSynced, tenancyv1alpha1.WorkspaceSourceReasonApplyFailed, conditionsv1alpha1.ConditionSeverityWarning,
+				"Failed to prune objects removed in revision %s: %v", revision, err)
+			return err
+		}
+	}
+
+	now := metav1.NewTime(c.now())
+	source.Status.Objects = mergeObjects(nil, applied)
+	source.Status.LastAppliedRevision = revision
+	source.Status.LastSyncTime = &now
+	conditions.MarkTrue(source, tenancyv1alpha1.WorkspaceSourceSynced)
+	return nil
+}
+
+// sortForApply moves namespaces and CRDs to the front, such that the objects in them can be
+// applied in the same pass.
+func sortForApply(objs []*unstructured.Unstructured) {
+	priority := func(obj *unstructured.Unstructured) int {
+		switch obj.GroupVersionKind().GroupKind() {
+		case schema.GroupKind{Kind: "Namespace"}:
+			return 0
+		case schema.GroupKind{Group: "apiextensions.k8s.io", Kind: "CustomResourceDefinition"}, schema.GroupKind{Group: "apis.kcp.dev", Kind: "APIBinding"}:
+			return 1
+		default:
+			return 2
+		}
+	}
+	sort.SliceStable(objs, func(i, j int) bool {
+		return priority(objs[i]) < priority(objs[j])
+	})
+}
+
+// objectKey returns the given reference without version, such that an object is not pruned when
+// its manifest changes the version.
+func objectKey(ref tenancyv1alpha1.WorkspaceSourceObject) tenancyv1alpha1.WorkspaceSourceObject {
+	ref.Version = ""
+	return ref
+}
+
+// mergeObjects returns the union of the given references, sorted. References in b win over
+// those in a.
+func mergeObjects(a, b []tenancyv1alpha1.WorkspaceSourceObject) []tenancyv1alpha1.WorkspaceSourceObject {
+	refs := map[tenancyv1alpha1.WorkspaceSourceObject]tenancyv1alpha1.WorkspaceSourceObject{}
+	for _, ref := range a {
+		refs[objectKey(ref)] = ref
+	}
+	for _, ref := range b {
+		refs[objectKey(ref)] = ref
+	}
+	if len(refs) == 0 {
+		return nil
+	}
+	ret := make([]tenancyv1alpha1.WorkspaceSourceObject, 0, len(refs))
+	for _, ref := range refs {
+		ret = append(ret, ref)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		a, b := ret[i], ret[j]
+		if a.Group != b.Group {
+			return a.Group < b.Group
+		}
+		if a.Resource != b.Resource {
+			return a.Resource < b.Resource
+		}
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		return a.Name < b.Name
+	})
+	return ret
+}
+
+func objectName(namespace, name string) string {
+	if namespace == "" {
+		return name
+	}
+	return namespace + "/" + name
+}
@@ -0,0 +1,293 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workspacesource
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kcp-dev/logicalcluster"
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apiserver/pkg/authentication/serviceaccount"
+	"k8s.io/client-go/rest"
+
+	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+	"github.com/kcp-dev/kcp/third_party/conditions/util/conditions"
+)
+
+func TestReconcile(t *testing.T) {
+	now := time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC)
+	resources := []*metav1.APIResourceList{
+		{GroupVersion: "v1", APIResources: []metav1.APIResource{
+			{Name: "namespaces", Kind: "Namespace"},
+			{Name: "namespaces/status", Kind: "Namespace"},
+			{Name: "configmaps", Kind: "ConfigMap", Namespaced: true},
+		}},
+		{GroupVersion: "rbac.authorization.k8s.io/v1", APIResources: []metav1.APIResource{
+			{Name: "clusterroles", Kind: "ClusterRole"},
+		}},
+	}
+	manifests := map[string]string{
+		"README.md": "not a manifest",
+		"deploy/app.yaml": `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+  namespace: app
+---
+apiVersion: v1
+kind: Namespace
+metadata:
+  name: app
+`,
+		"deploy/rbac/role.json": `{"apiVersion": "rbac.authorization.k8s.io/v1", "kind": "ClusterRole", "metadata": {"name": "reader", "namespace": "ignored"}}`,
+		"deploy/default.yaml": `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: defaulted
+`,
+	}
+
+	tests := map[string]struct {
+		spec      tenancyv1alpha1.WorkspaceSourceSpec
+		objects   []tenancyv1alpha1.WorkspaceSourceObject
+		manifests map[string]string
+		fetchErr  error
+		applyErr  error
+		deleteErr error
+
+		wantErr      bool
+		wantReason   string
+		wantApplied  []string
+		wantDeleted  []string
+		wantObjects  []tenancyv1alpha1.WorkspaceSourceObject
+		wantRevision string
+	}{
+		"applies manifests in path": {
+			spec:      tenancyv1alpha1.WorkspaceSourceSpec{URL: "https://example.com/repo.git", Path: "deploy"},
+			manifests: manifests,
+			wantApplied: []string{
+				"/v1, Resource=namespaces /app",
+				"/v1, Resource=configmaps app/config",
+				"/v1, Resource=configmaps default/defaulted",
+				"rbac.authorization.k8s.io/v1, Resource=clusterroles /reader",
+			},
+			wantObjects: []tenancyv1alpha1.WorkspaceSourceObject{
+				{Version: "v1", Resource: "configmaps", Namespace: "app", Name: "config"},
+				{Version: "v1", Resource: "configmaps", Namespace: "default", Name: "defaulted"},
+				{Version: "v1", Resource: "namespaces", Name: "app"},
+				{Group: "rbac.authorization.k8s.io", Version: "v1", Resource: "clusterroles", Name: "reader"},
+			},
+			wantRevision: "abc123",
+		},
+		"prunes removed objects": {
+			spec: tenancyv1alpha1.WorkspaceSourceSpec{URL: "https://example.com/repo.git", Prune: true},
+			objects: []tenancyv1alpha1.WorkspaceSourceObject{
+				{Version: "v1", Resource: "namespaces", Name: "app"},
+				{Version: "v1", Resource: "configmaps", Namespace: "app", Name: "removed"},
+			},
+			manifests: map[string]string{"ns.yaml": "apiVersion: v1\nkind: Namespace\nmetadata:\n  name: app\n"},
+			wantApplied: []string{
+				"/v1, Resource=namespaces /app",
+			},
+			wantDeleted: []string{"/v1, Resource=configmaps app/removed"},
+			wantObjects: []tenancyv1alpha1.WorkspaceSourceObject{
+				{Version: "v1", Resource: "namespaces", Name: "app"},
+			},
+			wantRevision: "abc123",
+		},
+		"keeps removed objects without prune": {
+			spec: tenancyv1alpha1.WorkspaceSourceSpec{URL: "https://example.com/repo.git"},
+			objects: []tenancyv1alpha1.WorkspaceSourceObject{
+				{Version: "v1", Resource: "configmaps", Namespace: "app", Name: "removed"},
+			},
+			manifests: map[string]string{"ns.yaml": "apiVersion: v1\nkind: Namespace\nmetadata:\n  name: app\n"},
+			wantApplied: []string{
+				"/v1, Resource=namespaces /app",
+			},
+			wantObjects: []tenancyv1alpha1.WorkspaceSourceObject{
+				{Version: "v1", Resource: "namespaces", Name: "app"},
+			},
+			wantRevision: "abc123",
+		},
+		"host not allowed is not retried": {
+			spec:       tenancyv1alpha1.WorkspaceSourceSpec{URL: "https://internal.example.org/repo.git"},
+			wantReason: tenancyv1alpha1.WorkspaceSourceReasonFetchFailed,
+		},
+		"fetch fails": {
+			spec:       tenancyv1alpha1.WorkspaceSourceSpec{URL: "https://example.com/repo.git"},
+			fetchErr:   errors.New("repository not found"),
+			wantErr:    true,
+			wantReason: tenancyv1alpha1.WorkspaceSourceReasonFetchFailed,
+		},
+		"invalid manifests are not retried": {
+			spec:       tenancyv1alpha1.WorkspaceSourceSpec{URL: "https://example.com/repo.git"},
+			manifests:  map[string]string{"broken.yaml": "apiVersion: v1\nkind: ConfigMap\n"},
+			wantReason: tenancyv1alpha1.WorkspaceSourceReasonInvalidManifests,
+		},
+		"unknown kind fails but applies the rest and does not prune": {
+			spec: tenancyv1alpha1.WorkspaceSourceSpec{URL: "https://example.com/repo.git", Prune: true},
+			objects: []tenancyv1alpha1.WorkspaceSourceObject{
+				{Version: "v1", Resource: "configmaps", Namespace: "app", Name: "removed"},
+			},
+			manifests: map[string]string{"all.yaml": "apiVersion: example.com/v1\nkind: Widget\nmetadata:\n  name: w\n---\napiVersion: v1\nkind: Namespace\nmetadata:\n  name: app\n"},
+			wantErr:   true,
+			wantApplied: []string{
+				"/v1, Resource=namespaces /app",
+			},
+			wantObjects: []tenancyv1alpha1.WorkspaceSourceObject{
+				{Version: "v1", Resource: "configmaps", Namespace: "app", Name: "removed"},
+				{Version: "v1", Resource: "namespaces", Name: "app"},
+			},
+			wantReason: tenancyv1alpha1.WorkspaceSourceReasonApplyFailed,
+		},
+		"prune fails": {
+			spec: tenancyv1alpha1.WorkspaceSourceSpec{URL: "https://example.com/repo.git", Prune: true},
+			objects: []tenancyv1alpha1.WorkspaceSourceObject{
+				{Version: "v1", Resource: "configmaps", Namespace: "app", Name: "removed"},
+			},
+			manifests: map[string]string{"ns.yaml": "apiVersion: v1\nkind: Namespace\nmetadata:\n  name: app\n"},
+			deleteErr: errors.New("forbidden"),
+			wantErr:   true,
+			wantApplied: []string{
+				"/v1, Resource=namespaces /app",
+			},
+			wantDeleted: []string{"/v1, Resource=configmaps app/removed"},
+			wantObjects: []tenancyv1alpha1.WorkspaceSourceObject{
+				{Version: "v1", Resource: "configmaps", Namespace: "app", Name: "removed"},
+				{Version: "v1", Resource: "namespaces", Name: "app"},
+			},
+			wantReason: tenancyv1alpha1.WorkspaceSourceReasonApplyFailed,
+		},
+	}
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			var applied, deleted []string
+			c := &Controller{
+				allowedHosts: []string{"example.com"},
+				listResources: func(clusterName logicalcluster.Name) ([]*metav1.APIResourceList, error) {
+					require.Equal(t, "root:org:ws", clusterName.String())
+					return resources, nil
+				},
+				fetch: func(ctx context.Context, spec *tenancyv1alpha1.WorkspaceSourceSpec, auth *gitAuth, dir string) (string, error) {
+					require.Nil(t, auth)
+					if tt.fetchErr != nil {
+						return "", tt.fetchErr
+					}
+					for file, content := range tt.manifests {
+						require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(dir, file)), 0755))
+						require.NoError(t, os.WriteFile(filepath.Join(dir, file), []byte(content), 0644))
+					}
+					return "abc123", nil
+				},
+				applyObject: func(ctx context.Context, clusterName logicalcluster.Name, user rest.ImpersonationConfig, gvr schema.GroupVersionResource, obj *unstructured.Unstructured) error {
+					require.Equal(t, "system:serviceaccount:gitops:applier", user.UserName)
+					require.Equal(t, []string{"root:org:ws"}, user.Extra[serviceaccount.ClusterNameKey])
+					applied = append(applied, gvr.String()+" "+obj.GetNamespace()+"/"+obj.GetName())
+					return tt.applyErr
+				},
+				deleteObject: func(ctx context.Context, clusterName logicalcluster.Name, user rest.ImpersonationConfig, gvr schema.GroupVersionResource, namespace, name string) error {
+					require.Equal(t, "system:serviceaccount:gitops:applier", user.UserName)
+					deleted = append(deleted, gvr.String()+" "+namespace+"/"+name)
+					return tt.deleteErr
+				},
+				now: func() time.Time { return now },
+			}
+
+			tt.spec.ServiceAccountRef = tenancyv1alpha1.WorkspaceSourceServiceAccountReference{Namespace: "gitops", Name: "applier"}
+			source := &tenancyv1alpha1.WorkspaceSource{
+				ObjectMeta: metav1.ObjectMeta{Name: "source", ClusterName: "root:org:ws"},
+				Spec:       tt.spec,
+				Status:     tenancyv1alpha1.WorkspaceSourceStatus{Objects: tt.objects},
+			}
+			err := c.reconcile(context.Background(), source)
+			if tt.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Equal(t, tt.wantApplied, applied)
+			require.Equal(t, tt.wantDeleted, deleted)
+
+			if tt.wantReason != "" {
+				require.True(t, conditions.IsFalse(source, tenancyv1alpha1.WorkspaceSourceSynced))
+				require.Equal(t, tt.wantReason, conditions.GetReason(source, tenancyv1alpha1.WorkspaceSourceSynced))
+			} else {
+				require.True(t, conditions.IsTrue(source, tenancyv1alpha1.WorkspaceSourceSynced))
+				require.Equal(t, now, source.Status.LastSyncTime.Time)
+			}
+			if tt.wantReason != tenancyv1alpha1.WorkspaceSourceReasonFetchFailed && tt.wantReason != tenancyv1alpha1.WorkspaceSourceReasonInvalidManifests {
+				require.Equal(t, tt.wantObjects, source.Status.Objects)
+			}
+			require.Equal(t, tt.wantRevision, source.Status.LastAppliedRevision)
+		})
+	}
+}
+
+func TestReconcileSecret(t *testing.T) {
+	c := &Controller{
+		allowedHosts: []string{"*.example.com"},
+		getSecret: func(ctx context.Context, clusterName logicalcluster.Name, user rest.ImpersonationConfig, namespace, name string) (*corev1.Secret, error) {
+			require.Equal(t, "system:serviceaccount:gitops:applier", user.UserName, "the secret must be read as the service account")
+			if namespace != "git" || name != "credentials" {
+				return nil, apierrors.NewNotFound(corev1.Resource("secrets"), name)
+			}
+			return &corev1.Secret{Data: map[string][]byte{"username": []byte("user"), "password": []byte("token")}}, nil
+		},
+		fetch: func(ctx context.Context, spec *tenancyv1alpha1.WorkspaceSourceSpec, auth *gitAuth, dir string) (string, error) {
+			require.Equal(t, &gitAuth{username: "user", password: "token"}, auth)
+			return "", errors.New("stop")
+		},
+	}
+
+	source := &tenancyv1alpha1.WorkspaceSource{
+		ObjectMeta: metav1.ObjectMeta{Name: "source", ClusterName: "root:org:ws"},
+		Spec: tenancyv1alpha1.WorkspaceSourceSpec{
+			URL:               "https://git.example.com/repo.git",
+			ServiceAccountRef: tenancyv1alpha1.WorkspaceSourceServiceAccountReference{Namespace: "gitops", Name: "applier"},
+			SecretRef:         &tenancyv1alpha1.WorkspaceSourceSecretReference{Namespace: "git", Name: "credentials"},
+		},
+	}
+	require.EqualError(t, c.reconcile(context.Background(), source), "stop")
+
+	source.Spec.SecretRef.Name = "missing"
+	require.Error(t, c.reconcile(context.Background(), source))
+	require.Equal(t, tenancyv1alpha1.WorkspaceSourceReasonFetchFailed, conditions.GetReason(source, tenancyv1alpha1.WorkspaceSourceSynced))
+}
+
+func TestHostAllowed(t *testing.T) {
+	allowed := []string{"github.com", "*.example.com"}
+	require.True(t, hostAllowed(allowed, "github.com"))
+	require.True(t, hostAllowed(allowed, "GitHub.com"))
+	require.True(t, hostAllowed(allowed, "git.example.com"))
+	require.False(t, hostAllowed(allowed, "example.com"))
+	require.False(t, hostAllowed(allowed, "gist.github.com"))
+	require.False(t, hostAllowed(allowed, "evil-example.com"))
+	require.False(t, hostAllowed(allowed, "169.254.169.254"))
+	require.False(t, hostAllowed(nil, "github.com"))
+}
@@ -0,0 +1,166 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workspacettl
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/kcp-dev/logicalcluster"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+
+	kcpclient "github.com/kcp-dev/kcp/pkg/client/clientset/versioned"
+	tenancyinformers "github.com/kcp-dev/kcp/pkg/client/informers/externalversions/tenancy/v1alpha1"
+	tenancylisters "github.com/kcp-dev/kcp/pkg/client/listers/tenancy/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/logging"
+)
+
+const (
+	controllerName = "kcp-workspace-ttl"
+)
+
+// NewController returns a new controller that expires ClusterWorkspaces with a spec.ttl: it sets
+// status.expirationTimestamp, warns with the LifetimeRemaining condition ahead of the expiration, and
+// deletes or archives the workspace once the ttl has passed.
+func NewController(
+	kcpClusterClient kcpclient.ClusterInterface,
+	clusterWorkspaceInformer tenancyinformers.ClusterWorkspaceInformer,
+	options Options,
+) *controller {
+	queue := workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), controllerName)
+
+	c := &controller{
+		queue:                  queue,
+		clusterWorkspaceLister: clusterWorkspaceInformer.Lister(),
+		deleteClusterWorkspace: func(ctx context.Context, clusterName logicalcluster.Name, name string, opts metav1.DeleteOptions) error {
+			return kcpClusterClient.Cluster(clusterName).TenancyV1alpha1().ClusterWorkspaces().Delete(ctx, name, opts)
+		},
+		patchClusterWorkspace: func(ctx context.Context, clusterName logicalcluster.Name, name string, patch []byte, subresources ...string) error {
+			_, err := kcpClusterClient.Cluster(clusterName).TenancyV1alpha1().ClusterWorkspaces().Patch(ctx, name, types.MergePatchType, patch, metav1.PatchOptions{}, subresources...)
+			return err
+		},
+		options: options,
+		now:     time.Now,
+	}
+
+	clusterWorkspaceInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj interface{}) { c.enqueue(obj) },
+		UpdateFunc: func(_, obj interface{}) { c.enqueue(obj) },
+	})
+
+	return c
+}
+
+// controller expires ClusterWorkspaces with a ttl. Every workspace is requeued for the time its
+// LifetimeRemaining condition or its expiration is due.
+type controller struct {
+	queue workqueue.RateLimitingInterface
+
+	clusterWorkspaceLister tenancylisters.ClusterWorkspaceLister
+	deleteClusterWorkspace func(ctx context.Context, clusterName logicalcluster.Name, name string, opts metav1.DeleteOptions) error
+	patchClusterWorkspace  func(ctx context.Context, clusterName logicalcluster.Name, name string, patch []byte, subresources ...string) error
+
+	options Options
+	now     func() time.Time
+}
+
+func (c *controller) enqueue(obj interface{}) {
+	key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+	if err != nil {
+		runtime.HandleError(err)
+		return
+	}
+
+	logging.WithQueueKey(logging.NewLogger(controllerName), key).V(4).Info("Queueing ClusterWorkspace")
+	c.queue.Add(key)
+}
+
+// Start starts the controller, which stops when ctx.Done() is closed.
+func (c *controller) Start(ctx context.Context, numThreads int) {
+	defer runtime.HandleCrash()
+	defer c.queue.ShutDown()
+
+	klog.Infof("Starting %s controller", controllerName)
+	defer klog.Infof("Shutting down %s controller", controllerName)
+
+	for i := 0; i < numThreads; i++ {
+		go wait.UntilWithContext(ctx, c.startWorker, time.Second)
+	}
+
+	<-ctx.Done()
+}
+
+func (c *controller) startWorker(ctx context.Context) {
+	for c.processNextWorkItem(ctx) {
+	}
+}
+
+func (c *controller) processNextWorkItem(ctx context.Context) bool {
+	// Wait until there is a new item in the working queue
+	k, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+	key := k.(string)
+
+	logger := logging.WithQueueKey(logging.NewLogger(controllerName), key)
+	ctx = logging.NewContext(ctx, logger)
+
+	// No matter what, tell the queue we're done with this key, to unblock
+	// other workers.
+	defer c.queue.Done(key)
+
+	if err := c.process(ctx, key); err != nil {
+		runtime.HandleError(fmt.Errorf("%q controller failed to sync %q, err: %w", controllerName, key, err))
+		c.queue.AddRateLimited(key)
+		return true
+	}
+	c.queue.Forget(key)
+	return true
+}
+
+func (c *controller) process(ctx context.Context, key string) error {
+	workspace, err := c.clusterWorkspaceLister.Get(key)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil // object deleted before we handled it
+		}
+		return err
+	}
+
+	if !workspace.DeletionTimestamp.IsZero() {
+		return nil
+	}
+
+	requeueAfter, err := c.reconcile(ctx, workspace)
+	if err != nil {
+		return err
+	}
+	if requeueAfter > 0 {
+		c.queue.AddAfter(key, requeueAfter)
+	}
+	return nil
+}
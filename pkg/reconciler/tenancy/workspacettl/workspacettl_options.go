@@ -0,0 +1,50 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workspacettl
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/pflag"
+)
+
+// DefaultExpirationWarning is the default time ahead of the expiration of a workspace from which on
+// its LifetimeRemaining condition turns false.
+const DefaultExpirationWarning = 24 * time.Hour
+
+func DefaultOptions() *Options {
+	return &Options{
+		ExpirationWarning: DefaultExpirationWarning,
+	}
+}
+
+func BindOptions(o *Options, fs *pflag.FlagSet) *Options {
+	fs.DurationVar(&o.ExpirationWarning, "workspace-expiration-warning", o.ExpirationWarning, "Time ahead of the expiration of a workspace with a ttl from which on its LifetimeRemaining condition warns about the expiration")
+	return o
+}
+
+type Options struct {
+	ExpirationWarning time.Duration
+}
+
+func (o *Options) Validate() error {
+	if o.ExpirationWarning < 0 {
+		return fmt.Errorf("--workspace-expiration-warning must be >=0 (%s)", o.ExpirationWarning)
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workspacettl
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/kcp-dev/logicalcluster"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/logging"
+	conditionsv1alpha1 "github.com/kcp-dev/kcp/third_party/conditions/apis/conditions/v1alpha1"
+	"github.com/kcp-dev/kcp/third_party/conditions/util/conditions"
+)
+
+// reconcile updates the expiration status of the workspace, and deletes or archives it when expired.
+// It returns when the workspace has to be reconciled again.
+func (c *controller) reconcile(ctx context.Context, workspace *tenancyv1alpha1.ClusterWorkspace) (time.Duration, error) {
+	logger := logging.WithClusterWorkspace(logging.FromContext(ctx), workspace)
+
+	updated := workspace.DeepCopy()
+	var requeueAfter time.Duration
+
+	if workspace.Spec.TTL == nil {
+		updated.Status.ExpirationTimestamp = nil
+		conditions.Delete(updated, tenancyv1alpha1.WorkspaceLifetimeRemaining)
+	} else {
+		expiration := workspace.CreationTimestamp.Add(workspace.Spec.TTL.Duration)
+		updated.Status.ExpirationTimestamp = &metav1.Time{Time: expiration}
+
+		action := workspace.Spec.ExpirationAction
+		if action == "" {
+			action = tenancyv1alpha1.ClusterWorkspaceExpirationActionDelete
+		}
+
+		now := c.now()
+		switch {
+		case !now.Before(expiration) && action == tenancyv1alpha1.ClusterWorkspaceExpirationActionDelete:
+			logger.Info("Deleting expired ClusterWorkspace", "expiration", expiration)
+			// the precondition makes sure the ttl has not been extended in the meantime
+			err := c.deleteClusterWorkspace(ctx, logicalcluster.From(workspace), workspace.Name, metav1.DeleteOptions{
+				Preconditions: &metav1.Preconditions{ResourceVersion: &workspace.ResourceVersion},
+			})
+			if errors.IsNotFound(err) {
+				return 0, nil
+			}
+			return 0, err
+		case !now.Before(expiration) && !workspace.Spec.ReadOnly:
+			logger.Info("Archiving expired ClusterWorkspace", "expiration", expiration)
+			patch, err := json.Marshal(map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"resourceVersion": workspace.ResourceVersion,
+				},
+				"spec": map[string]interface{}{
+					"readOnly": true,
+				},
+			})
+			if err != nil {
+				return 0, err
+			}
+			// the status is updated when the update of the spec comes back through the informer
+			return 0, c.patchClusterWorkspace(ctx, logicalcluster.From(workspace), workspace.Name, patch)
+		case !now.Before(expiration):
+			conditions.MarkFalse(updated, tenancyv1alpha1.WorkspaceLifetimeRemaining, tenancyv1alpha1.WorkspaceLifetimeRemainingReasonExpired, conditionsv1alpha1.ConditionSeverityInfo,
+				"The workspace expired at %s and has been archived.", expiration.UTC().Format(time.RFC3339))
+		case !now.Before(expiration.Add(-c.options.ExpirationWarning)):
+			conditions.MarkFalse(updated, tenancyv1alpha1.WorkspaceLifetimeRemaining, tenancyv1alpha1.WorkspaceLifetimeRemainingReasonExpiringSoon, conditionsv1alpha1.ConditionSeverityWarning,
+				"The workspace expires at %s and will then be %s.", expiration.UTC().Format(time.RFC3339), actionVerb(action))
+			requeueAfter = expiration.Sub(now)
+		default:
+			conditions.MarkTrue(updated, tenancyv1alpha1.WorkspaceLifetimeRemaining)
+			requeueAfter = expiration.Add(-c.options.ExpirationWarning).Sub(now)
+		}
+	}
+
+	if equality.Semantic.DeepEqual(workspace.Status, updated.Status) {
+		return requeueAfter, nil
+	}
+
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"resourceVersion": workspace.ResourceVersion,
+		},
+		"status": map[string]interface{}{
+			"expirationTimestamp": updated.Status.ExpirationTimestamp,
+			"conditions":          updated.Status.Conditions,
+		},
+	})
+	if err != nil {
+		return 0, err
+	}
+	if err := c.patchClusterWorkspace(ctx, logicalcluster.From(workspace), workspace.Name, patch, "status"); err != nil {
+		return 0, err
+	}
+	return requeueAfter, nil
+}
+
+func actionVerb(action tenancyv1alpha1.ClusterWorkspaceExpirationAction) string {
+	if action == tenancyv1alpha1.ClusterWorkspaceExpirationActionArchive {
+		return "archived"
+	}
+	return "deleted"
+}
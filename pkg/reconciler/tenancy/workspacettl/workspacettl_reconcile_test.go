@@ -0,0 +1,173 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workspacettl
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/kcp-dev/logicalcluster"
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+	"github.com/kcp-dev/kcp/third_party/conditions/util/conditions"
+)
+
+func TestReconcile(t *testing.T) {
+	created := time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC)
+	expiration := created.Add(48 * time.Hour)
+
+	tests := map[string]struct {
+		ttl           *metav1.Duration
+		action        tenancyv1alpha1.ClusterWorkspaceExpirationAction
+		readOnly      bool
+		withCondition bool
+		now           time.Time
+
+		wantDeleted      bool
+		wantArchived     bool
+		wantStatusPatch  bool
+		wantExpiration   *time.Time
+		wantStatus       corev1.ConditionStatus
+		wantReason       string
+		wantRequeueAfter time.Duration
+	}{
+		"no ttl": {
+			now: created,
+		},
+		"ttl removed": {
+			withCondition:   true,
+			now:             created,
+			wantStatusPatch: true,
+		},
+		"far from expiration": {
+			ttl:              &metav1.Duration{Duration: 48 * time.Hour},
+			now:              created.Add(time.Hour),
+			wantStatusPatch:  true,
+			wantExpiration:   &expiration,
+			wantStatus:       "True",
+			wantRequeueAfter: 23 * time.Hour,
+		},
+		"expiring soon": {
+			ttl:              &metav1.Duration{Duration: 48 * time.Hour},
+			now:              created.Add(40 * time.Hour),
+			wantStatusPatch:  true,
+			wantExpiration:   &expiration,
+			wantStatus:       "False",
+			wantReason:       tenancyv1alpha1.WorkspaceLifetimeRemainingReasonExpiringSoon,
+			wantRequeueAfter: 8 * time.Hour,
+		},
+		"expired and deleted": {
+			ttl:         &metav1.Duration{Duration: 48 * time.Hour},
+			now:         expiration,
+			wantDeleted: true,
+		},
+		"expired and archived": {
+			ttl:          &metav1.Duration{Duration: 48 * time.Hour},
+			action:       tenancyv1alpha1.ClusterWorkspaceExpirationActionArchive,
+			now:          expiration.Add(time.Minute),
+			wantArchived: true,
+		},
+		"archived": {
+			ttl:             &metav1.Duration{Duration: 48 * time.Hour},
+			action:          tenancyv1alpha1.ClusterWorkspaceExpirationActionArchive,
+			readOnly:        true,
+			now:             expiration.Add(time.Minute),
+			wantStatusPatch: true,
+			wantExpiration:  &expiration,
+			wantStatus:      "False",
+			wantReason:      tenancyv1alpha1.WorkspaceLifetimeRemainingReasonExpired,
+		},
+	}
+
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			workspace := &tenancyv1alpha1.ClusterWorkspace{
+				ObjectMeta: metav1.ObjectMeta{ClusterName: "root:org", Name: "ws", ResourceVersion: "42", CreationTimestamp: metav1.NewTime(created)},
+				Spec:       tenancyv1alpha1.ClusterWorkspaceSpec{TTL: tc.ttl, ExpirationAction: tc.action, ReadOnly: tc.readOnly},
+			}
+			if tc.withCondition {
+				conditions.MarkTrue(workspace, tenancyv1alpha1.WorkspaceLifetimeRemaining)
+				workspace.Status.ExpirationTimestamp = &metav1.Time{Time: expiration}
+			}
+
+			var deleted, archived bool
+			var statusPatch []byte
+			c := &controller{
+				deleteClusterWorkspace: func(ctx context.Context, clusterName logicalcluster.Name, name string, opts metav1.DeleteOptions) error {
+					require.Equal(t, "root:org", clusterName.String())
+					require.Equal(t, "ws", name)
+					require.Equal(t, "42", *opts.Preconditions.ResourceVersion)
+					deleted = true
+					return nil
+				},
+				patchClusterWorkspace: func(ctx context.Context, clusterName logicalcluster.Name, name string, patch []byte, subresources ...string) error {
+					require.Equal(t, "root:org", clusterName.String())
+					require.Equal(t, "ws", name)
+					if len(subresources) == 0 {
+						require.JSONEq(t, `{"metadata":{"resourceVersion":"42"},"spec":{"readOnly":true}}`, string(patch))
+						archived = true
+						return nil
+					}
+					require.Equal(t, []string{"status"}, subresources)
+					statusPatch = patch
+					return nil
+				},
+				options: Options{ExpirationWarning: 24 * time.Hour},
+				now:     func() time.Time { return tc.now },
+			}
+
+			requeueAfter, err := c.reconcile(context.Background(), workspace)
+			require.NoError(t, err)
+			require.Equal(t, tc.wantRequeueAfter, requeueAfter)
+			require.Equal(t, tc.wantDeleted, deleted)
+			require.Equal(t, tc.wantArchived, archived)
+			require.Equal(t, tc.wantStatusPatch, statusPatch != nil)
+			if statusPatch == nil {
+				return
+			}
+
+			var got struct {
+				Metadata metav1.ObjectMeta                      `json:"metadata"`
+				Status   tenancyv1alpha1.ClusterWorkspaceStatus `json:"status"`
+			}
+			require.NoError(t, json.Unmarshal(statusPatch, &got))
+			require.Equal(t, "42", got.Metadata.ResourceVersion)
+			if tc.wantExpiration == nil {
+				require.Nil(t, got.Status.ExpirationTimestamp)
+			} else {
+				require.NotNil(t, got.Status.ExpirationTimestamp)
+				require.True(t, got.Status.ExpirationTimestamp.Time.Equal(*tc.wantExpiration))
+			}
+
+			cond := conditions.Get(&tenancyv1alpha1.ClusterWorkspace{Status: got.Status}, tenancyv1alpha1.WorkspaceLifetimeRemaining)
+			if tc.wantStatus == "" {
+				require.Nil(t, cond)
+				return
+			}
+			require.NotNil(t, cond)
+			require.Equal(t, tc.wantStatus, cond.Status)
+			require.Equal(t, tc.wantReason, cond.Reason)
+		})
+	}
+}
@@ -0,0 +1,221 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workspaceurl
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path"
+	"time"
+
+	"github.com/kcp-dev/logicalcluster"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/clusters"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+
+	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+	kcpclient "github.com/kcp-dev/kcp/pkg/client/clientset/versioned"
+	tenancyinformer "github.com/kcp-dev/kcp/pkg/client/informers/externalversions/tenancy/v1alpha1"
+	tenancylister "github.com/kcp-dev/kcp/pkg/client/listers/tenancy/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/logging"
+)
+
+const (
+	controllerName = "kcp-workspace-url"
+
+	// byShardIndex indexes ClusterWorkspaces by the key of the ClusterWorkspaceShard they are scheduled to.
+	byShardIndex = "workspaceurl-byShard"
+)
+
+// NewController returns a controller rewriting the status.baseURL of ClusterWorkspaces
+// when the external URL of the ClusterWorkspaceShard they are scheduled to changes.
+func NewController(
+	kcpClusterClient kcpclient.ClusterInterface,
+	rootWorkspaceShardInformer tenancyinformer.ClusterWorkspaceShardInformer,
+	workspaceInformer tenancyinformer.ClusterWorkspaceInformer,
+) (*Controller, error) {
+	c := &Controller{
+		queue: workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), controllerName),
+
+		kcpClusterClient:         kcpClusterClient,
+		rootWorkspaceShardLister: rootWorkspaceShardInformer.Lister(),
+		workspaceIndexer:         workspaceInformer.Informer().GetIndexer(),
+	}
+
+	if err := c.workspaceIndexer.AddIndexers(cache.Indexers{
+		byShardIndex: func(obj interface{}) ([]string, error) {
+			if workspace, ok := obj.(*tenancyv1alpha1.ClusterWorkspace); ok && workspace.Status.Location.Current != "" {
+				return []string{clusters.ToClusterAwareKey(tenancyv1alpha1.RootCluster, workspace.Status.Location.Current)}, nil
+			}
+			return []string{}, nil
+		},
+	}); err != nil {
+		return nil, fmt.Errorf("failed to add indexer for ClusterWorkspace: %w", err)
+	}
+
+	rootWorkspaceShardInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) { c.enqueue(obj) },
+		UpdateFunc: func(oldObj, obj interface{}) {
+			oldShard, ok := oldObj.(*tenancyv1alpha1.ClusterWorkspaceShard)
+			if !ok {
+				return
+			}
+			shard, ok := obj.(*tenancyv1alpha1.ClusterWorkspaceShard)
+			if !ok {
+				return
+			}
+			if oldShard.Spec.ExternalURL != shard.Spec.ExternalURL {
+				c.enqueue(obj)
+			}
+		},
+	})
+
+	return c, nil
+}
+
+// Controller keeps the status.baseURL of scheduled ClusterWorkspaces in sync with the
+// external URL of their ClusterWorkspaceShard, e.g. after a domain migration.
+type Controller struct {
+	queue workqueue.RateLimitingInterface
+
+	kcpClusterClient         kcpclient.ClusterInterface
+	rootWorkspaceShardLister tenancylister.ClusterWorkspaceShardLister
+	workspaceIndexer         cache.Indexer
+}
+
+func (c *Controller) enqueue(obj interface{}) {
+	key, err := cache.MetaNamespaceKeyFunc(obj)
+	if err != nil {
+		runtime.HandleError(err)
+		return
+	}
+	logging.WithQueueKey(logging.NewLogger(controllerName), key).V(2).Info("Queueing ClusterWorkspaceShard")
+	c.queue.Add(key)
+}
+
+func (c *Controller) Start(ctx context.Context, numThreads int) {
+	defer runtime.HandleCrash()
+	defer c.queue.ShutDown()
+
+	klog.Infof("Starting %s controller", controllerName)
+	defer klog.Infof("Shutting down %s controller", controllerName)
+
+	for i := 0; i < numThreads; i++ {
+		go wait.UntilWithContext(ctx, c.startWorker, time.Second)
+	}
+
+	<-ctx.Done()
+}
+
+func (c *Controller) startWorker(ctx context.Context) {
+	for c.processNextWorkItem(ctx) {
+	}
+}
+
+func (c *Controller) processNextWorkItem(ctx context.Context) bool {
+	// Wait until there is a new item in the working queue
+	k, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+	key := k.(string)
+
+	logger := logging.WithQueueKey(logging.NewLogger(controllerName), key)
+	ctx = logging.NewContext(ctx, logger)
+
+	// No matter what, tell the queue we're done with this key, to unblock
+	// other workers.
+	defer c.queue.Done(key)
+
+	if err := c.process(ctx, key); err != nil {
+		runtime.HandleError(fmt.Errorf("%q controller failed to sync %q, err: %w", controllerName, key, err))
+		c.queue.AddRateLimited(key)
+		return true
+	}
+	c.queue.Forget(key)
+	return true
+}
+
+func (c *Controller) process(ctx context.Context, key string) error {
+	shard, err := c.rootWorkspaceShardLister.Get(key)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil // the workspace scheduler takes care of workspaces of deleted shards
+		}
+		return err
+	}
+	if _, err := url.Parse(shard.Spec.ExternalURL); err != nil {
+		logging.FromContext(ctx).Error(err, "Invalid external URL of ClusterWorkspaceShard")
+		return nil // nothing we can do until the shard is fixed
+	}
+
+	workspaces, err := c.workspaceIndexer.ByIndex(byShardIndex, key)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, obj := range workspaces {
+		workspace := obj.(*tenancyv1alpha1.ClusterWorkspace)
+		if workspace.Status.BaseURL == "" {
+			continue // not fully scheduled yet, the workspace scheduler will set the URL
+		}
+		baseURL, err := WorkspaceBaseURL(shard, workspace)
+		if err != nil {
+			return err
+		}
+		if baseURL == workspace.Status.BaseURL {
+			continue
+		}
+
+		patchBytes, err := json.Marshal(map[string]interface{}{
+			"metadata": map[string]interface{}{
+				"resourceVersion": workspace.ResourceVersion,
+			},
+			"status": map[string]interface{}{
+				"baseURL": baseURL,
+			},
+		})
+		if err != nil {
+			return err
+		}
+		logging.WithClusterWorkspace(logging.FromContext(ctx), workspace).Info("Rewriting base URL of ClusterWorkspace", "from", workspace.Status.BaseURL, "to", baseURL)
+		if _, err := c.kcpClusterClient.Cluster(logicalcluster.From(workspace)).TenancyV1alpha1().ClusterWorkspaces().Patch(ctx, workspace.Name, types.MergePatchType, patchBytes, metav1.PatchOptions{}, "status"); err != nil && !errors.IsNotFound(err) {
+			errs = append(errs, err)
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// WorkspaceBaseURL returns the base URL of the given workspace when scheduled to the given shard.
+func WorkspaceBaseURL(shard *tenancyv1alpha1.ClusterWorkspaceShard, workspace *tenancyv1alpha1.ClusterWorkspace) (string, error) {
+	u, err := url.Parse(shard.Spec.ExternalURL)
+	if err != nil {
+		return "", err
+	}
+	u.Path = path.Join(u.Path, logicalcluster.From(workspace).Join(workspace.Name).Path())
+	return u.String(), nil
+}
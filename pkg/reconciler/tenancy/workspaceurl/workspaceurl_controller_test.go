@@ -0,0 +1,70 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package workspaceurl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+)
+
+func TestWorkspaceBaseURL(t *testing.T) {
+	tests := []struct {
+		name        string
+		externalURL string
+		want        string
+		wantErr     bool
+	}{
+		{
+			name:        "host only",
+			externalURL: "https://kcp.example.com",
+			want:        "https://kcp.example.com/clusters/root:org:team",
+		},
+		{
+			name:        "with port and path prefix",
+			externalURL: "https://kcp.example.com:6443/proxy/",
+			want:        "https://kcp.example.com:6443/proxy/clusters/root:org:team",
+		},
+		{
+			name:        "invalid",
+			externalURL: "https://kcp.example.com:port",
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			shard := &tenancyv1alpha1.ClusterWorkspaceShard{
+				Spec: tenancyv1alpha1.ClusterWorkspaceShardSpec{ExternalURL: tt.externalURL},
+			}
+			workspace := &tenancyv1alpha1.ClusterWorkspace{
+				ObjectMeta: metav1.ObjectMeta{ClusterName: "root:org", Name: "team"},
+			}
+			got, err := WorkspaceBaseURL(shard, workspace)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
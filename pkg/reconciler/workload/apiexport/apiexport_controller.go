@@ -38,6 +38,7 @@ import (
 	apisinformers "github.com/kcp-dev/kcp/pkg/client/informers/externalversions/apis/v1alpha1"
 	apiresourcelisters "github.com/kcp-dev/kcp/pkg/client/listers/apiresource/v1alpha1"
 	apislisters "github.com/kcp-dev/kcp/pkg/client/listers/apis/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/logging"
 )
 
 const (
@@ -126,7 +127,7 @@ func (c *controller) enqueueNegotiatedAPIResource(obj interface{}) {
 	for _, obj := range exports {
 		export := obj.(*apisv1alpha1.APIExport)
 		key := clusters.ToClusterAwareKey(clusterName, export.Name)
-		klog.Infof("Mapping NegotiatedAPIResource %s|%s to APIExport %q", clusterName, resource.Name, key)
+		logging.WithQueueKey(logging.NewLogger(controllerName), key).Info("Mapping NegotiatedAPIResource to APIExport", "negotiatedAPIResource", resource.Name)
 		c.queue.Add(key)
 	}
 }
@@ -138,7 +139,7 @@ func (c *controller) enqueueAPIExport(obj interface{}) {
 		return
 	}
 
-	klog.Infof("Enqueueing APIExport %q", key)
+	logging.WithQueueKey(logging.NewLogger(controllerName), key).Info("Queueing APIExport")
 
 	c.queue.Add(key)
 }
@@ -171,6 +172,9 @@ func (c *controller) processNextWorkItem(ctx context.Context) bool {
 	}
 	key := k.(string)
 
+	logger := logging.WithQueueKey(logging.NewLogger(controllerName), key)
+	ctx = logging.NewContext(ctx, logger)
+
 	// No matter what, tell the queue we're done with this key, to unblock
 	// other workers.
 	defer c.queue.Done(key)
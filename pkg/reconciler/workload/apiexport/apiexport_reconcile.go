@@ -31,10 +31,10 @@ import (
 	"k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/tools/clusters"
-	"k8s.io/klog/v2"
 
 	apiresourcev1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apiresource/v1alpha1"
 	apisv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apis/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/logging"
 )
 
 type reconcileStatus int
@@ -60,6 +60,7 @@ type schemaReconciler struct {
 }
 
 func (r *schemaReconciler) reconcile(ctx context.Context, export *apisv1alpha1.APIExport) (reconcileStatus, error) {
+	logger := logging.FromContext(ctx)
 	clusterName := logicalcluster.From(export)
 
 	resources, err := r.listNegotiatedAPIResources(clusterName)
@@ -123,7 +124,7 @@ func (r *schemaReconciler) reconcile(ctx context.Context, export *apisv1alpha1.A
 	// create missing or outdated schemas
 	outdatedOrMissing := expectedResourceGroups.Difference(upToDate)
 	for _, resourceGroup := range outdatedOrMissing.List() {
-		klog.V(2).Infof("Missing or outdated schema %q in APIExport %s|%s, adding.", resourceGroup, clusterName, export.Name)
+		logger.V(2).Info("Missing or outdated schema in APIExport, adding", "resource", resourceGroup)
 		resource := resourcesByResourceGroup[resourceGroup]
 
 		group := resource.Spec.GroupVersion.Group
@@ -154,7 +155,7 @@ func (r *schemaReconciler) reconcile(ctx context.Context, export *apisv1alpha1.A
 		schema, ok := schemasByResourceGroup[resourceGroup]
 		if !ok {
 			// should not happen. We should have all schemas by now
-			klog.Errorf("Unexpectedly missing schema for resource %q in APIExport %s|%s", resourceGroup, clusterName, export.Name)
+			logger.Error(nil, "Unexpectedly missing schema for resource in APIExport", "resource", resourceGroup)
 			return reconcileStatusStop, nil
 		}
 
@@ -174,7 +175,7 @@ func (r *schemaReconciler) reconcile(ctx context.Context, export *apisv1alpha1.A
 	}
 	for _, schema := range allSchemas {
 		if !referencedSchemaNames[schema.Name] && metav1.IsControlledBy(schema, export) {
-			klog.V(2).Infof("Deleting schema %q of APIExport %s|%s", schema.Name, clusterName, export.Name)
+			logger.V(2).Info("Deleting schema of APIExport", "apiResourceSchema", schema.Name)
 			if err := r.deleteAPIResourceSchema(ctx, clusterName, schema.Name); err != nil && !apierrors.IsNotFound(err) {
 				return reconcileStatusStop, err
 			}
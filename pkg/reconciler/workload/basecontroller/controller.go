@@ -36,6 +36,7 @@ import (
 	kcpclient "github.com/kcp-dev/kcp/pkg/client/clientset/versioned"
 	apiresourceinformer "github.com/kcp-dev/kcp/pkg/client/informers/externalversions/apiresource/v1alpha1"
 	workloadinformer "github.com/kcp-dev/kcp/pkg/client/informers/externalversions/workload/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/logging"
 	"github.com/kcp-dev/kcp/pkg/reconciler/apis/apiresource"
 )
 
@@ -203,6 +204,9 @@ func (c *ClusterReconciler) processNextWorkItem(ctx context.Context) bool {
 	}
 	key := k.(string)
 
+	logger := logging.WithQueueKey(logging.NewLogger(c.name), key)
+	ctx = logging.NewContext(ctx, logger)
+
 	// No matter what, tell the queue we're done with this key, to unblock
 	// other workers.
 	defer c.queue.Done(key)
@@ -223,12 +227,13 @@ func (c *ClusterReconciler) process(ctx context.Context, key string) error {
 	}
 
 	if !exists {
-		klog.Errorf("%s: Object with key %q was deleted", c.name, key)
+		logging.FromContext(ctx).Info("Object was deleted")
 		return nil
 	}
 	current := obj.(*workloadv1alpha1.WorkloadCluster).DeepCopy()
 	previous := current.DeepCopy()
 
+	ctx = logging.NewContext(ctx, logging.WithSyncTarget(logging.FromContext(ctx), current.Name))
 	if err := c.reconciler.Reconcile(ctx, current); err != nil {
 		return err
 	}
@@ -43,6 +43,7 @@ func (c *clusterManager) Reconcile(ctx context.Context, cluster *workloadv1alpha
 			workloadv1alpha1.APIImporterReady,
 			workloadv1alpha1.HeartbeatHealthy,
 		),
+		conditions.WithObservedGeneration(),
 	)
 
 	latestHeartbeat := time.Time{}
@@ -51,21 +52,21 @@ func (c *clusterManager) Reconcile(ctx context.Context, cluster *workloadv1alpha
 	}
 	if latestHeartbeat.IsZero() {
 		klog.V(5).Infof("Marking HeartbeatHealthy false for WorkloadCluster %s|%s due to no heartbeat", cluster.ClusterName, cluster.Name)
-		conditions.MarkFalse(cluster,
+		conditions.MarkFalseObserved(cluster,
 			workloadv1alpha1.HeartbeatHealthy,
 			workloadv1alpha1.ErrorHeartbeatMissedReason,
 			conditionsapi.ConditionSeverityWarning,
 			"No heartbeat yet seen")
 	} else if time.Since(latestHeartbeat) > c.heartbeatThreshold {
 		klog.V(5).Infof("Marking HeartbeatHealthy false for WorkloadCluster %s|%s due to a stale heartbeat", cluster.ClusterName, cluster.Name)
-		conditions.MarkFalse(cluster,
+		conditions.MarkFalseObserved(cluster,
 			workloadv1alpha1.HeartbeatHealthy,
 			workloadv1alpha1.ErrorHeartbeatMissedReason,
 			conditionsapi.ConditionSeverityWarning,
 			"No heartbeat since %s", latestHeartbeat)
 	} else {
 		klog.V(5).Infof("Marking Heartbeat healthy true for WorkloadCluster %s|%s", cluster.ClusterName, cluster.Name)
-		conditions.MarkTrue(cluster, workloadv1alpha1.HeartbeatHealthy)
+		conditions.MarkTrueObserved(cluster, workloadv1alpha1.HeartbeatHealthy)
 
 		// Enqueue another check after which the heartbeat should have been updated again.
 		dur := time.Until(latestHeartbeat.Add(c.heartbeatThreshold))
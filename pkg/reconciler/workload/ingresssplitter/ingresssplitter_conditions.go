@@ -0,0 +1,184 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingresssplitter
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/klog/v2"
+
+	envoycontrolplane "github.com/kcp-dev/kcp/pkg/localenvoy/controlplane"
+	"github.com/kcp-dev/kcp/pkg/syncer/shared"
+)
+
+// ConditionsAnnotation is the annotation on a root Ingress holding the JSON encoded list of
+// Gateway-style conditions (metav1.Condition) telling where the exposure of the Ingress stands.
+const ConditionsAnnotation = "ingress.kcp.dev/conditions"
+
+const (
+	// LeavesSyncedCondition tells whether all the leaves of the root Ingress exist with the
+	// desired spec and got a load balancer status from their workload cluster.
+	LeavesSyncedCondition = "LeavesSynced"
+	// EnvoyConfiguredCondition tells whether all leaves are part of the Envoy configuration.
+	// It is only set when the Envoy control plane is enabled.
+	EnvoyConfiguredCondition = "EnvoyConfigured"
+	// DNSProgrammedCondition tells whether the root Ingress has a load balancer address in
+	// its status that the hostnames of its rules can be resolved to.
+	DNSProgrammedCondition = "DNSProgrammed"
+
+	// AllLeavesSyncedReason is the reason of a true LeavesSynced condition.
+	AllLeavesSyncedReason = "AllLeavesSynced"
+	// LeavesPendingReason is the reason of a false condition because some leaves have not
+	// been picked up yet.
+	LeavesPendingReason = "LeavesPending"
+	// NoLeavesReason is the reason of a false condition because no backend service is
+	// assigned to a workload cluster.
+	NoLeavesReason = "NoLeaves"
+	// LeafSyncFailedReason is the reason of a false LeavesSynced condition because
+	// generating, creating, updating or deleting the leaves failed.
+	LeafSyncFailedReason = "LeafSyncFailed"
+	// EnvoyConfiguredReason is the reason of a true EnvoyConfigured condition.
+	EnvoyConfiguredReason = "Configured"
+	// AddressAssignedReason is the reason of a true DNSProgrammed condition.
+	AddressAssignedReason = "AddressAssigned"
+	// NoAddressReason is the reason of a false DNSProgrammed condition.
+	NoAddressReason = "NoAddress"
+)
+
+// setConditions computes the conditions of the root Ingress from its current and desired
+// leaves, and the error syncing the leaves if any, and stores them in its ConditionsAnnotation.
+func setConditions(root *networkingv1.Ingress, currentLeaves, desiredLeaves []*networkingv1.Ingress, syncErr error, envoyEnabled bool) {
+	conditions := getConditions(root)
+
+	var synced, configured int
+	for _, desired := range desiredLeaves {
+		current := findLeaf(currentLeaves, desired)
+		if current == nil || !equality.Semantic.DeepEqual(current.Spec, desired.Spec) || len(current.Status.LoadBalancer.Ingress) == 0 {
+			continue
+		}
+		synced++
+		if current.Labels[envoycontrolplane.ToEnvoyLabel] == "true" {
+			configured++
+		}
+	}
+
+	leavesSynced := countCondition(LeavesSyncedCondition, AllLeavesSyncedReason, "synced", synced, len(desiredLeaves))
+	if syncErr != nil {
+		leavesSynced.Status = metav1.ConditionFalse
+		leavesSynced.Reason = LeafSyncFailedReason
+		leavesSynced.Message = syncErr.Error()
+	}
+	setCondition(&conditions, root, leavesSynced)
+
+	if envoyEnabled {
+		setCondition(&conditions, root, countCondition(EnvoyConfiguredCondition, EnvoyConfiguredReason, "configured in Envoy", configured, len(desiredLeaves)))
+	} else {
+		meta.RemoveStatusCondition(&conditions, EnvoyConfiguredCondition)
+	}
+
+	var addresses []string
+	for _, lb := range root.Status.LoadBalancer.Ingress {
+		if lb.Hostname != "" {
+			addresses = append(addresses, lb.Hostname)
+		}
+		if lb.IP != "" {
+			addresses = append(addresses, lb.IP)
+		}
+	}
+	if len(addresses) > 0 {
+		setCondition(&conditions, root, metav1.Condition{
+			Type:    DNSProgrammedCondition,
+			Status:  metav1.ConditionTrue,
+			Reason:  AddressAssignedReason,
+			Message: fmt.Sprintf("load balancer address %s assigned", strings.Join(addresses, ", ")),
+		})
+	} else {
+		setCondition(&conditions, root, metav1.Condition{
+			Type:    DNSProgrammedCondition,
+			Status:  metav1.ConditionFalse,
+			Reason:  NoAddressReason,
+			Message: "no load balancer address assigned yet",
+		})
+	}
+
+	bs, err := json.Marshal(conditions)
+	if err != nil {
+		klog.Errorf("Failed to encode conditions of Ingress %s|%s/%s: %v", root.ClusterName, root.Namespace, root.Name, err)
+		return
+	}
+	if root.Annotations[ConditionsAnnotation] == string(bs) {
+		return
+	}
+	if root.Annotations == nil {
+		root.Annotations = map[string]string{}
+	}
+	root.Annotations[ConditionsAnnotation] = string(bs)
+}
+
+// getConditions decodes the ConditionsAnnotation of the given Ingress. Invalid values are dropped.
+func getConditions(ingress *networkingv1.Ingress) []metav1.Condition {
+	value, found := ingress.Annotations[ConditionsAnnotation]
+	if !found {
+		return nil
+	}
+	var conditions []metav1.Condition
+	if err := json.Unmarshal([]byte(value), &conditions); err != nil {
+		klog.Warningf("Ignoring invalid %s annotation on Ingress %s|%s/%s: %v", ConditionsAnnotation, ingress.ClusterName, ingress.Namespace, ingress.Name, err)
+		return nil
+	}
+	return conditions
+}
+
+// countCondition returns a condition that is true if all the leaves are done, with the counts in its message.
+func countCondition(conditionType, trueReason, verb string, done, total int) metav1.Condition {
+	c := metav1.Condition{
+		Type:    conditionType,
+		Status:  metav1.ConditionFalse,
+		Reason:  LeavesPendingReason,
+		Message: fmt.Sprintf("%d of %d leaves %s", done, total, verb),
+	}
+	switch {
+	case total == 0:
+		c.Reason = NoLeavesReason
+		c.Message = "no backend service is assigned to a workload cluster"
+	case done == total:
+		c.Status = metav1.ConditionTrue
+		c.Reason = trueReason
+	}
+	return c
+}
+
+func setCondition(conditions *[]metav1.Condition, root *networkingv1.Ingress, c metav1.Condition) {
+	c.ObservedGeneration = root.Generation
+	meta.SetStatusCondition(conditions, c)
+}
+
+func findLeaf(leaves []*networkingv1.Ingress, leaf *networkingv1.Ingress) *networkingv1.Ingress {
+	for _, l := range leaves {
+		//nolint:staticcheck
+		if l.Name == leaf.Name && shared.DeprecatedGetAssignedWorkloadCluster(l.Labels) == shared.DeprecatedGetAssignedWorkloadCluster(leaf.Labels) {
+			return l
+		}
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ingresssplitter
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	workloadv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/workload/v1alpha1"
+	envoycontrolplane "github.com/kcp-dev/kcp/pkg/localenvoy/controlplane"
+)
+
+func TestSetConditions(t *testing.T) {
+	leaf := func(cluster string, lbHost string, envoy bool) *networkingv1.Ingress {
+		l := &networkingv1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "root-" + cluster,
+				Labels: map[string]string{
+					workloadv1alpha1.InternalClusterResourceStateLabelPrefix + cluster: string(workloadv1alpha1.ResourceStateSync),
+				},
+			},
+		}
+		if lbHost != "" {
+			l.Status.LoadBalancer.Ingress = []corev1.LoadBalancerIngress{{Hostname: lbHost}}
+		}
+		if envoy {
+			l.Labels[envoycontrolplane.ToEnvoyLabel] = "true"
+		}
+		return l
+	}
+	root := func(lbHost string) *networkingv1.Ingress {
+		r := &networkingv1.Ingress{ObjectMeta: metav1.ObjectMeta{Name: "root", Generation: 3}}
+		if lbHost != "" {
+			r.Status.LoadBalancer.Ingress = []corev1.LoadBalancerIngress{{Hostname: lbHost}}
+		}
+		return r
+	}
+
+	tests := map[string]struct {
+		root          *networkingv1.Ingress
+		currentLeaves []*networkingv1.Ingress
+		desiredLeaves []*networkingv1.Ingress
+		syncErr       error
+		envoyEnabled  bool
+
+		wantConditions []metav1.Condition
+	}{
+		"no leaves": {
+			root: root(""),
+			wantConditions: []metav1.Condition{
+				{Type: LeavesSyncedCondition, Status: metav1.ConditionFalse, Reason: NoLeavesReason, Message: "no backend service is assigned to a workload cluster"},
+				{Type: DNSProgrammedCondition, Status: metav1.ConditionFalse, Reason: NoAddressReason, Message: "no load balancer address assigned yet"},
+			},
+		},
+		"leaves pending": {
+			root:          root(""),
+			currentLeaves: []*networkingv1.Ingress{leaf("east", "east.lb", false), leaf("west", "", false)},
+			desiredLeaves: []*networkingv1.Ingress{leaf("east", "", false), leaf("west", "", false), leaf("north", "", false)},
+			envoyEnabled:  true,
+			wantConditions: []metav1.Condition{
+				{Type: LeavesSyncedCondition, Status: metav1.ConditionFalse, Reason: LeavesPendingReason, Message: "1 of 3 leaves synced"},
+				{Type: EnvoyConfiguredCondition, Status: metav1.ConditionFalse, Reason: LeavesPendingReason, Message: "0 of 3 leaves configured in Envoy"},
+				{Type: DNSProgrammedCondition, Status: metav1.ConditionFalse, Reason: NoAddressReason, Message: "no load balancer address assigned yet"},
+			},
+		},
+		"all synced and configured": {
+			root:          root("root.kcp.dev"),
+			currentLeaves: []*networkingv1.Ingress{leaf("east", "east.lb", true), leaf("west", "west.lb", true)},
+			desiredLeaves: []*networkingv1.Ingress{leaf("east", "", false), leaf("west", "", false)},
+			envoyEnabled:  true,
+			wantConditions: []metav1.Condition{
+				{Type: LeavesSyncedCondition, Status: metav1.ConditionTrue, Reason: AllLeavesSyncedReason, Message: "2 of 2 leaves synced"},
+				{Type: EnvoyConfiguredCondition, Status: metav1.ConditionTrue, Reason: EnvoyConfiguredReason, Message: "2 of 2 leaves configured in Envoy"},
+				{Type: DNSProgrammedCondition, Status: metav1.ConditionTrue, Reason: AddressAssignedReason, Message: "load balancer address root.kcp.dev assigned"},
+			},
+		},
+		"sync error": {
+			root:          root(""),
+			currentLeaves: []*networkingv1.Ingress{leaf("east", "east.lb", false)},
+			desiredLeaves: []*networkingv1.Ingress{leaf("east", "", false)},
+			syncErr:       errors.New("failed to create leaf: boom"),
+			wantConditions: []metav1.Condition{
+				{Type: LeavesSyncedCondition, Status: metav1.ConditionFalse, Reason: LeafSyncFailedReason, Message: "failed to create leaf: boom"},
+				{Type: DNSProgrammedCondition, Status: metav1.ConditionFalse, Reason: NoAddressReason, Message: "no load balancer address assigned yet"},
+			},
+		},
+	}
+
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			setConditions(tc.root, tc.currentLeaves, tc.desiredLeaves, tc.syncErr, tc.envoyEnabled)
+
+			got := getConditions(tc.root)
+			require.Len(t, got, len(tc.wantConditions))
+			for _, want := range tc.wantConditions {
+				c := meta.FindStatusCondition(got, want.Type)
+				require.NotNil(t, c, "missing condition %s", want.Type)
+				require.Equal(t, want.Status, c.Status, "condition %s", want.Type)
+				require.Equal(t, want.Reason, c.Reason, "condition %s", want.Type)
+				require.Equal(t, want.Message, c.Message, "condition %s", want.Type)
+				require.Equal(t, int64(3), c.ObservedGeneration, "condition %s", want.Type)
+			}
+		})
+	}
+}
+
+func TestSetConditionsIsStable(t *testing.T) {
+	root := &networkingv1.Ingress{ObjectMeta: metav1.ObjectMeta{Name: "root"}}
+	setConditions(root, nil, nil, nil, true)
+	annotation := root.Annotations[ConditionsAnnotation]
+	require.NotEmpty(t, annotation)
+
+	setConditions(root, nil, nil, nil, true)
+	require.Equal(t, annotation, root.Annotations[ConditionsAnnotation], "unchanged conditions must not change the annotation")
+
+	setConditions(root, nil, nil, nil, false)
+	require.Nil(t, meta.FindStatusCondition(getConditions(root), EnvoyConfiguredCondition), "EnvoyConfigured must be removed without Envoy")
+}
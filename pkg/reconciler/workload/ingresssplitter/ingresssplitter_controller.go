@@ -27,6 +27,7 @@ import (
 	networkingv1 "k8s.io/api/networking/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/apimachinery/pkg/util/wait"
 	coreinformers "k8s.io/client-go/informers/core/v1"
@@ -38,6 +39,8 @@ import (
 	"k8s.io/client-go/tools/clusters"
 	"k8s.io/client-go/util/workqueue"
 	"k8s.io/klog/v2"
+
+	"github.com/kcp-dev/kcp/pkg/logging"
 )
 
 const controllerName = "kcp-ingress-splitter"
@@ -169,6 +172,9 @@ func (c *Controller) processNextWorkItem(ctx context.Context) bool {
 	}
 	key := k.(string)
 
+	logger := logging.WithQueueKey(logging.NewLogger(controllerName), key)
+	ctx = logging.NewContext(ctx, logger)
+
 	// No matter what, tell the queue we're done with this key, to unblock
 	// other workers.
 	defer c.queue.Done(key)
@@ -202,20 +208,21 @@ func (c *Controller) process(ctx context.Context, key string) error {
 
 	klog.Infof("Processing ingress %q", key)
 
+	var errs []error
 	if err := c.reconcile(ctx, current); err != nil {
-		return err
+		errs = append(errs, err)
 	}
 
-	// If the object being reconciled changed as a result, update it.
+	// If the object being reconciled changed as a result, update it, also on error to persist the conditions.
 	if !equality.Semantic.DeepEqual(previous, current) {
 		//TODO(jmprusi): Move to patch instead of Update.
 		_, err := c.client.Cluster(logicalcluster.From(current)).NetworkingV1().Ingresses(current.Namespace).Update(ctx, current, metav1.UpdateOptions{})
 		if err != nil {
-			return err
+			errs = append(errs, err)
 		}
 	}
 
-	return nil
+	return utilerrors.NewAggregate(errs)
 }
 
 // ingressesFromService enqueues all the related ingresses for a given service.
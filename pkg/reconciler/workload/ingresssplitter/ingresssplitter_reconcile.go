@@ -52,10 +52,17 @@ func (c *Controller) reconcile(ctx context.Context, ingress *networkingv1.Ingres
 		if err := c.reconcileLeaves(ctx, ingress); err != nil {
 			return err
 		}
-	} else if c.aggregateLeavesStatus {
-		// we have a leave ingress here and have to reconcile the root status
-		if err := c.reconcileRootStatusFromLeaves(ctx, ingress); err != nil {
-			return err
+	} else {
+		// the conditions of the root depend on the status of its leaves
+		if rootIngressKey := rootIngressKeyFor(ingress); rootIngressKey != "" {
+			c.queue.Add(rootIngressKey)
+		}
+
+		if c.aggregateLeavesStatus {
+			// we have a leave ingress here and have to reconcile the root status
+			if err := c.reconcileRootStatusFromLeaves(ctx, ingress); err != nil {
+				return err
+			}
 		}
 	}
 
@@ -76,9 +83,18 @@ func (c *Controller) reconcileLeaves(ctx context.Context, ingress *networkingv1.
 	// Generate the desired leaves
 	desiredLeaves, err := c.desiredLeaves(ctx, ingress)
 	if err != nil {
+		setConditions(ingress, currentLeaves, nil, err, !c.aggregateLeavesStatus)
 		return err
 	}
 
+	err = c.syncLeaves(ctx, ingress, currentLeaves, desiredLeaves)
+	// with Envoy enabled, the leaves' status is not aggregated into the root
+	setConditions(ingress, currentLeaves, desiredLeaves, err, !c.aggregateLeavesStatus)
+	return err
+}
+
+// syncLeaves creates, updates and deletes the current leaves of the root ingress to match the desired leaves.
+func (c *Controller) syncLeaves(ctx context.Context, ingress *networkingv1.Ingress, currentLeaves, desiredLeaves []*networkingv1.Ingress) error {
 	// Update the leafs and get missing ones to create and the ones be deleted.
 	toCreate, toDelete, err := c.updateLeafs(ctx, currentLeaves, desiredLeaves)
 	if err != nil {
@@ -90,7 +106,6 @@ func (c *Controller) reconcileLeaves(ctx context.Context, ingress *networkingv1.
 		klog.InfoS("Creating leaf", "ClusterName", leaf.ClusterName, "Namespace", leaf.Namespace, "Name", leaf.Name)
 
 		if _, err := c.client.Cluster(logicalcluster.From(ingress)).NetworkingV1().Ingresses(leaf.Namespace).Create(ctx, leaf, metav1.CreateOptions{}); err != nil && !apierrors.IsAlreadyExists(err) {
-			//TODO(jmprusi): Surface as user-facing condition.
 			return fmt.Errorf("failed to create leaf: %w", err)
 		}
 	}
@@ -100,7 +115,6 @@ func (c *Controller) reconcileLeaves(ctx context.Context, ingress *networkingv1.
 		klog.InfoS("Deleting leaf", "ClusterName", leaf.ClusterName, "Namespace", leaf.Namespace, "Name", leaf.Name)
 
 		if err := c.client.Cluster(logicalcluster.From(ingress)).NetworkingV1().Ingresses(leaf.Namespace).Delete(ctx, leaf.Name, metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
-			//TODO(jmprusi): Surface as user-facing condition.
 			return fmt.Errorf("failed to delete leaf: %w", err)
 		}
 	}
@@ -173,7 +187,6 @@ func (c *Controller) updateLeafs(ctx context.Context, currentLeaves []*networkin
 			updated := currentLeaf.DeepCopy()
 			updated.Spec = desiredLeaf.Spec
 			if _, err := c.client.Cluster(logicalcluster.From(currentLeaf)).NetworkingV1().Ingresses(currentLeaf.Namespace).Update(ctx, updated, metav1.UpdateOptions{}); err != nil {
-				//TODO(jmprusi): Update root Ingress condition to reflect the error.
 				return nil, nil, err
 			}
 			break
@@ -46,6 +46,7 @@ import (
 	tenancylisters "github.com/kcp-dev/kcp/pkg/client/listers/tenancy/v1alpha1"
 	workloadlisters "github.com/kcp-dev/kcp/pkg/client/listers/workload/v1alpha1"
 	"github.com/kcp-dev/kcp/pkg/informer"
+	"github.com/kcp-dev/kcp/pkg/logging"
 )
 
 const controllerName = "namespace-scheduler"
@@ -66,6 +67,8 @@ func NewController(
 	namespaceInformer coreinformers.NamespaceInformer,
 	namespaceLister corelisters.NamespaceLister,
 	pollInterval time.Duration,
+	bookmarks *informer.BookmarkStore,
+	options Options,
 ) *Controller {
 	resourceQueue := workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "kcp-namespace-resource")
 	gvrQueue := workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "kcp-namespace-gvr")
@@ -87,6 +90,7 @@ func NewController(
 		clusterLister:   clusterLister,
 		namespaceLister: namespaceLister,
 		kubeClient:      kubeClusterClient,
+		rebalancer:      newRebalancer(options),
 	}
 
 	clusterInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
@@ -117,7 +121,7 @@ func NewController(
 			AddFunc:    func(gvr schema.GroupVersionResource, obj interface{}) { c.enqueueResource(gvr, obj) },
 			UpdateFunc: func(gvr schema.GroupVersionResource, _, obj interface{}) { c.enqueueResource(gvr, obj) },
 			DeleteFunc: nil, // Nothing to do.
-		}, pollInterval)
+		}, pollInterval, bookmarks)
 
 	return c
 }
@@ -135,6 +139,7 @@ type Controller struct {
 	workspaceLister tenancylisters.ClusterWorkspaceLister
 	kubeClient      kubernetes.ClusterInterface
 	ddsif           informer.DynamicDiscoverySharedInformerFactory
+	rebalancer      *rebalancer
 }
 
 func filterResource(obj interface{}) bool {
@@ -278,6 +283,9 @@ func processNext(
 	}
 	key := k.(string)
 
+	logger := logging.WithQueueKey(logging.NewLogger(controllerName), key)
+	ctx = logging.NewContext(ctx, logger)
+
 	// No matter what, tell the queue we're done with this key, to unblock
 	// other workers.
 	defer queue.Done(key)
@@ -293,31 +301,32 @@ func processNext(
 
 // key is gvr::KEY
 func (c *Controller) processResource(ctx context.Context, key string) error {
+	logger := logging.FromContext(ctx)
 	parts := strings.SplitN(key, "::", 2)
 	if len(parts) != 2 {
-		klog.Errorf("Error parsing key %q; dropping", key)
+		logger.Error(nil, "Error parsing key; dropping")
 		return nil
 	}
 	gvrstr := parts[0]
 	gvr, _ := schema.ParseResourceArg(gvrstr)
 	if gvr == nil {
-		klog.Errorf("Error parsing GVR %q; dropping", gvrstr)
+		logger.Error(nil, "Error parsing GVR; dropping", "gvr", gvrstr)
 		return nil
 	}
 	key = parts[1]
 
 	obj, exists, err := c.ddsif.IndexerFor(*gvr).GetByKey(key)
 	if err != nil {
-		klog.Errorf("Error getting %q GVR %q from indexer: %v", key, gvrstr, err)
+		logger.Error(err, "Error getting object from indexer", "gvr", gvrstr)
 		return err
 	}
 	if !exists {
-		klog.V(3).Infof("object %q GVR %q does not exist", key, gvrstr)
+		logger.V(3).Info("Object does not exist", "gvr", gvrstr)
 		return nil
 	}
 	unstr, ok := obj.(*unstructured.Unstructured)
 	if !ok {
-		klog.Errorf("object was not Unstructured, dropping: %T", obj)
+		logger.Error(nil, "Object was not Unstructured, dropping", "type", fmt.Sprintf("%T", obj))
 		return nil
 	}
 	unstr = unstr.DeepCopy()
@@ -325,7 +334,7 @@ func (c *Controller) processResource(ctx context.Context, key string) error {
 	// Get logical cluster name.
 	_, clusterAwareName, err := cache.SplitMetaNamespaceKey(key)
 	if err != nil {
-		klog.Errorf("failed to split key %q, dropping: %v", key, err)
+		logger.Error(err, "Failed to split key, dropping")
 		return nil
 	}
 	lclusterName, _ := clusters.SplitClusterAwareKey(clusterAwareName)
@@ -335,7 +344,7 @@ func (c *Controller) processResource(ctx context.Context, key string) error {
 func (c *Controller) processGVR(ctx context.Context, gvrstr string) error {
 	gvr, _ := schema.ParseResourceArg(gvrstr)
 	if gvr == nil {
-		klog.Errorf("Error parsing GVR %q; dropping", gvrstr)
+		logging.FromContext(ctx).Error(nil, "Error parsing GVR; dropping")
 		return nil
 	}
 	return c.reconcileGVR(ctx, *gvr)
@@ -355,7 +364,7 @@ func (c *Controller) processNamespace(ctx context.Context, key string) error {
 	// Get logical cluster name.
 	_, clusterAwareName, err := cache.SplitMetaNamespaceKey(key)
 	if err != nil {
-		klog.Errorf("failed to split key %q, dropping: %v", key, err)
+		logging.FromContext(ctx).Error(err, "Failed to split key, dropping")
 		return nil
 	}
 	lclusterName, _ := clusters.SplitClusterAwareKey(clusterAwareName)
@@ -41,6 +41,7 @@ import (
 
 	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
 	workloadv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/workload/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/logging"
 	"github.com/kcp-dev/kcp/pkg/syncer/shared"
 	conditionsapi "github.com/kcp-dev/kcp/third_party/conditions/apis/conditions/v1alpha1"
 	"github.com/kcp-dev/kcp/third_party/conditions/util/conditions"
@@ -94,17 +95,18 @@ func init() {
 // reconcileResource is responsible for setting the cluster for a resource of
 // any type, to match the cluster where its namespace is assigned.
 func (c *Controller) reconcileResource(ctx context.Context, lclusterName logicalcluster.Name, unstr *unstructured.Unstructured, gvr *schema.GroupVersionResource) error {
+	logger := logging.WithObject(logging.FromContext(ctx), unstr).WithValues("gvr", gvr.String())
 	if gvr.Group == "networking.k8s.io" && gvr.Resource == "ingresses" {
-		klog.V(4).Infof("Skipping reconciliation of ingress %s/%s", unstr.GetNamespace(), unstr.GetName())
+		logger.V(4).Info("Skipping reconciliation of ingress")
 		return nil
 	}
 
-	klog.V(2).Infof("Reconciling GVR %q %s|%s/%s", gvr.String(), lclusterName, unstr.GetNamespace(), unstr.GetName())
+	logger.V(2).Info("Reconciling resource")
 
 	// If the resource is not namespaced (incl if the resource is itself a
 	// namespace), ignore it.
 	if unstr.GetNamespace() == "" {
-		klog.V(4).Infof("GVR %q %s|%s had no namespace; ignoring", gvr.String(), unstr.GetClusterName(), unstr.GetName())
+		logger.V(4).Info("Resource has no namespace; ignoring")
 		return nil
 	}
 
@@ -141,7 +143,7 @@ func (c *Controller) reconcileResource(ctx context.Context, lclusterName logical
 	// Update the resource's assignment.
 	patchType, patchBytes, err := clusterLabelPatchBytes(previousCluster, newCluster)
 	if err != nil {
-		klog.Errorf("error creating patch for %s %s|%s: %v", gvr.String(), unstr.GetClusterName(), unstr.GetName(), err)
+		logger.Error(err, "Error creating patch")
 		return err
 	}
 
@@ -149,8 +151,7 @@ func (c *Controller) reconcileResource(ctx context.Context, lclusterName logical
 		Patch(ctx, unstr.GetName(), patchType, patchBytes, metav1.PatchOptions{}); err != nil {
 		return err
 	} else {
-		klog.V(2).Infof("Patched cluster assignment for %q %s|%s/%s: %q -> %q. Labels=%v",
-			gvr, lclusterName, ns.Name, unstr.GetName(), previousCluster, newCluster, updated.GetLabels())
+		logger.V(2).Info("Patched cluster assignment", "previousSyncTarget", previousCluster, logging.SyncTargetKey, newCluster, "labels", updated.GetLabels())
 	}
 	return nil
 }
@@ -183,8 +184,9 @@ func (c *Controller) ensureScheduled(ctx context.Context, ns *corev1.Namespace)
 	oldPClusterName := ns.Labels[DeprecatedScheduledClusterNamespaceLabel]
 
 	scheduler := namespaceScheduler{
-		getCluster:   c.clusterLister.Get,
-		listClusters: c.clusterLister.List,
+		getCluster:     c.clusterLister.Get,
+		listClusters:   c.clusterLister.List,
+		listNamespaces: c.listNamespaces,
 	}
 	newPClusterName, err := scheduler.AssignCluster(ns)
 	if err != nil {
@@ -195,11 +197,11 @@ func (c *Controller) ensureScheduled(ctx context.Context, ns *corev1.Namespace)
 		return ns, false, nil
 	}
 
-	klog.V(2).Infof("Patching to update cluster assignment for namespace %s|%s: %s -> %s",
-		logicalcluster.From(ns), ns.Name, oldPClusterName, newPClusterName)
+	logger := logging.FromContext(ctx)
+	logger.V(2).Info("Patching to update cluster assignment for namespace", "previousSyncTarget", oldPClusterName, logging.SyncTargetKey, newPClusterName)
 	patchType, patchBytes, err := schedulingClusterLabelPatchBytes(oldPClusterName, newPClusterName)
 	if err != nil {
-		klog.Errorf("Failed to create patch for cluster assignment: %v", err)
+		logger.Error(err, "Failed to create patch for cluster assignment")
 		return ns, false, err
 	}
 
@@ -212,6 +214,22 @@ func (c *Controller) ensureScheduled(ctx context.Context, ns *corev1.Namespace)
 	return patchedNamespace, true, nil
 }
 
+// listNamespaces returns the namespaces of the given workspace.
+func (c *Controller) listNamespaces(clusterName logicalcluster.Name) ([]*corev1.Namespace, error) {
+	// TODO(ncdc): use cluster scoped generated lister when available
+	allNamespaces, err := c.namespaceLister.List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	var namespaces []*corev1.Namespace
+	for _, ns := range allNamespaces {
+		if logicalcluster.From(ns) == clusterName {
+			namespaces = append(namespaces, ns)
+		}
+	}
+	return namespaces, nil
+}
+
 // ensureScheduledStatus ensures the status of the given namespace reflects the
 // namespace's scheduled state.
 func (c *Controller) ensureScheduledStatus(ctx context.Context, ns *corev1.Namespace) (*corev1.Namespace, error) {
@@ -241,14 +259,15 @@ func (c *Controller) ensureScheduledStatus(ctx context.Context, ns *corev1.Names
 // After assigning (or if it's already assigned), this also updates all
 // resources in the namespace to be assigned to the namespace's cluster.
 func (c *Controller) reconcileNamespace(ctx context.Context, lclusterName logicalcluster.Name, ns *corev1.Namespace) error {
-	klog.Infof("Reconciling namespace %s|%s", lclusterName, ns.Name)
+	logger := logging.FromContext(ctx)
+	logger.Info("Reconciling namespace")
 
 	workspaceSchedulingEnabled, err := isWorkspaceSchedulable(c.workspaceLister.Get, logicalcluster.From(ns))
 	if err != nil {
 		return err
 	}
 	if !workspaceSchedulingEnabled {
-		klog.V(4).Infof("Scheduling is disabled for the workspace of namespace %s|%s", lclusterName, ns.Name)
+		logger.V(4).Info("Scheduling is disabled for the workspace of namespace")
 		return nil
 	}
 
@@ -386,9 +405,10 @@ func schedulingClusterLabelPatchBytes(oldClusterName, newClusterName string) (ty
 //
 // After the namespace is unassigned, it will be picked up by
 // reconcileNamespace above and assigned to another happy cluster if one can be
-// found.
+// found. If the cluster is happy and schedulable, namespaces scheduled to other
+// clusters may be moved to it according to the rebalance policy.
 func (c *Controller) observeCluster(ctx context.Context, cluster *workloadv1alpha1.WorkloadCluster) error {
-	klog.V(2).Infof("Observing WorkloadCluster %s|%s", logicalcluster.From(cluster), cluster.Name)
+	logging.WithSyncTarget(logging.FromContext(ctx), cluster.Name).V(2).Info("Observing WorkloadCluster")
 
 	strategy, pendingCordon := enqueueStrategyForCluster(cluster)
 
@@ -404,6 +424,7 @@ func (c *Controller) observeCluster(ctx context.Context, cluster *workloadv1alph
 		var errs []error
 		errs = append(errs, c.enqueueNamespaces(clusterName, labels.NewSelector().Add(unscheduledRequirement).Add(scheduleRequirement)))
 		errs = append(errs, c.enqueueNamespaces(clusterName, labels.NewSelector().Add(scheduleEmptyLabelRequirement).Add(scheduleRequirement)))
+		errs = append(errs, c.rebalanceToCluster(ctx, cluster))
 		return errors.NewAggregate(errs)
 
 	case enqueueScheduled:
@@ -0,0 +1,235 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package namespace
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kcp-dev/logicalcluster"
+	"github.com/spf13/pflag"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	workloadv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/workload/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/logging"
+	locationreconciler "github.com/kcp-dev/kcp/pkg/reconciler/scheduling/location"
+)
+
+// RebalancePolicy decides whether namespaces already scheduled are moved to a newly schedulable cluster.
+type RebalancePolicy string
+
+const (
+	// RebalanceNone only schedules new namespaces to a newly schedulable cluster.
+	RebalanceNone RebalancePolicy = "None"
+	// RebalanceSpread moves namespaces from the clusters with most namespaces of the workspace to a newly
+	// schedulable cluster, until the namespaces are spread evenly.
+	RebalanceSpread RebalancePolicy = "Spread"
+)
+
+// RebalancePolicies are the supported rebalance policies.
+var RebalancePolicies = sets.NewString(string(RebalanceNone), string(RebalanceSpread))
+
+func DefaultOptions() *Options {
+	return &Options{
+		RebalancePolicy:   string(RebalanceNone),
+		RebalanceBudget:   1,
+		RebalanceInterval: time.Minute,
+	}
+}
+
+func BindOptions(o *Options, fs *pflag.FlagSet) *Options {
+	fs.StringVar(&o.RebalancePolicy, "namespace-scheduler-rebalance-policy", o.RebalancePolicy,
+		fmt.Sprintf("Whether scheduled namespaces are moved to workload clusters becoming schedulable. One of %s. %q spreads the namespaces of a workspace evenly.", strings.Join(RebalancePolicies.List(), ", "), RebalanceSpread))
+	fs.IntVar(&o.RebalanceBudget, "namespace-scheduler-rebalance-budget", o.RebalanceBudget, "Maximal number of namespaces of a workspace moved to another workload cluster per --namespace-scheduler-rebalance-interval.")
+	fs.DurationVar(&o.RebalanceInterval, "namespace-scheduler-rebalance-interval", o.RebalanceInterval, "Minimal time between two rebalancings of the namespaces of a workspace.")
+	return o
+}
+
+type Options struct {
+	RebalancePolicy   string
+	RebalanceBudget   int
+	RebalanceInterval time.Duration
+}
+
+func (o *Options) Validate() error {
+	if !RebalancePolicies.Has(o.RebalancePolicy) {
+		return fmt.Errorf("--namespace-scheduler-rebalance-policy must be one of %s", strings.Join(RebalancePolicies.List(), ", "))
+	}
+	if o.RebalanceBudget <= 0 {
+		return fmt.Errorf("--namespace-scheduler-rebalance-budget must be >0 (%d)", o.RebalanceBudget)
+	}
+	if o.RebalanceInterval <= 0 {
+		return fmt.Errorf("--namespace-scheduler-rebalance-interval must be >0 (%s)", o.RebalanceInterval)
+	}
+	return nil
+}
+
+// rebalancer limits how often the namespaces of a workspace are rebalanced.
+type rebalancer struct {
+	Options
+
+	lock sync.Mutex
+	// lastRebalance is when namespaces of a workspace were moved last.
+	lastRebalance map[logicalcluster.Name]time.Time
+
+	now func() time.Time
+}
+
+func newRebalancer(options Options) *rebalancer {
+	return &rebalancer{
+		Options:       options,
+		lastRebalance: map[logicalcluster.Name]time.Time{},
+		now:           time.Now,
+	}
+}
+
+// wait returns how long to wait until namespaces of the workspace may be moved again.
+func (r *rebalancer) wait(clusterName logicalcluster.Name) time.Duration {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	last, found := r.lastRebalance[clusterName]
+	if !found {
+		return 0
+	}
+	if wait := last.Add(r.RebalanceInterval).Sub(r.now()); wait > 0 {
+		return wait
+	}
+	return 0
+}
+
+func (r *rebalancer) rebalanced(clusterName logicalcluster.Name) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.lastRebalance[clusterName] = r.now()
+}
+
+// rebalanceToCluster moves namespaces of the workspace of the given cluster, which is ready and schedulable, to
+// it according to the rebalance policy. At most RebalanceBudget namespaces are moved per RebalanceInterval; if
+// more moves are needed, the cluster is enqueued again.
+func (c *Controller) rebalanceToCluster(ctx context.Context, cluster *workloadv1alpha1.WorkloadCluster) error {
+	if RebalancePolicy(c.rebalancer.RebalancePolicy) != RebalanceSpread {
+		return nil
+	}
+
+	clusterName := logicalcluster.From(cluster)
+	logger := logging.WithSyncTarget(logging.FromContext(ctx), cluster.Name)
+
+	schedulable, err := isWorkspaceSchedulable(c.workspaceLister.Get, clusterName)
+	if err != nil || !schedulable {
+		return err
+	}
+
+	if wait := c.rebalancer.wait(clusterName); wait > 0 {
+		c.enqueueClusterAfter(cluster, wait)
+		return nil
+	}
+
+	workspaceNamespaces, err := c.listNamespaces(clusterName)
+	if err != nil {
+		return err
+	}
+	var namespaces []*corev1.Namespace
+	for _, ns := range workspaceNamespaces {
+		if !namespaceBlocklist.Has(ns.Name) {
+			namespaces = append(namespaces, ns)
+		}
+	}
+
+	moves, balanced := spreadMoves(cluster, namespaces, c.rebalancer.RebalanceBudget)
+	for _, ns := range moves {
+		oldClusterName := ns.Labels[DeprecatedScheduledClusterNamespaceLabel]
+		logger.V(2).Info("Moving namespace to rebalance", "namespace", ns.Name, "previousSyncTarget", oldClusterName)
+		patchType, patchBytes, err := schedulingClusterLabelPatchBytes(oldClusterName, cluster.Name)
+		if err != nil {
+			return err
+		}
+		if _, err := c.kubeClient.Cluster(clusterName).CoreV1().Namespaces().Patch(ctx, ns.Name, patchType, patchBytes, metav1.PatchOptions{}); err != nil {
+			return err
+		}
+	}
+	if len(moves) > 0 {
+		c.rebalancer.rebalanced(clusterName)
+	}
+	if !balanced {
+		c.enqueueClusterAfter(cluster, c.rebalancer.RebalanceInterval)
+	}
+	return nil
+}
+
+// spreadMoves returns the namespaces to move to the target cluster, at most budget, to spread the given
+// namespaces of its workspace evenly across the clusters. Namespaces are taken from the clusters with most
+// namespaces first. Only namespaces scheduled automatically and tolerating the taints of the target are moved.
+// It also returns whether the namespaces are spread evenly after the moves.
+func spreadMoves(target *workloadv1alpha1.WorkloadCluster, namespaces []*corev1.Namespace, budget int) ([]*corev1.Namespace, bool) {
+	counts := map[string]int{}
+	movable := map[string][]*corev1.Namespace{}
+	for _, ns := range namespaces {
+		assigned := ns.Labels[DeprecatedScheduledClusterNamespaceLabel]
+		if assigned == "" {
+			continue
+		}
+		counts[assigned]++
+
+		if assigned == target.Name || !scheduleRequirement.Matches(labels.Set(ns.Labels)) {
+			continue
+		}
+		tolerations, err := locationreconciler.TolerationsFromAnnotations(ns.Annotations)
+		if err != nil {
+			continue
+		}
+		if locationreconciler.UntoleratedTaint(target, tolerations, corev1.TaintEffectNoSchedule, corev1.TaintEffectPreferNoSchedule, corev1.TaintEffectNoExecute) != nil {
+			continue
+		}
+		movable[assigned] = append(movable[assigned], ns)
+	}
+	for _, nss := range movable {
+		sort.Slice(nss, func(i, j int) bool { return nss[i].Name < nss[j].Name })
+	}
+
+	var moves []*corev1.Namespace
+	for {
+		source := ""
+		for name, nss := range movable {
+			if len(nss) == 0 {
+				continue
+			}
+			if source == "" || counts[name] > counts[source] || (counts[name] == counts[source] && name < source) {
+				source = name
+			}
+		}
+		if source == "" || counts[source]-counts[target.Name] <= 1 {
+			return moves, true
+		}
+		if len(moves) == budget {
+			return moves, false
+		}
+
+		moves = append(moves, movable[source][0])
+		movable[source] = movable[source][1:]
+		counts[source]--
+		counts[target.Name]++
+	}
+}
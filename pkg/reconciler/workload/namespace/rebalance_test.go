@@ -0,0 +1,142 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package namespace
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/kcp-dev/logicalcluster"
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	schedulingv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/scheduling/v1alpha1"
+)
+
+func scheduledNamespaces(clusterName string, n int, labels map[string]string) []*corev1.Namespace {
+	var namespaces []*corev1.Namespace
+	for i := 0; i < n; i++ {
+		ns := &corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        fmt.Sprintf("%s-%d", clusterName, i),
+				ClusterName: testLclusterName.String(),
+				Labels:      map[string]string{DeprecatedScheduledClusterNamespaceLabel: clusterName},
+			},
+		}
+		for k, v := range labels {
+			ns.Labels[k] = v
+		}
+		namespaces = append(namespaces, ns)
+	}
+	return namespaces
+}
+
+func concat(namespaces ...[]*corev1.Namespace) []*corev1.Namespace {
+	var all []*corev1.Namespace
+	for _, nss := range namespaces {
+		all = append(all, nss...)
+	}
+	return all
+}
+
+func TestSpreadMoves(t *testing.T) {
+	tolerating := scheduledNamespaces("east", 2, nil)
+	for _, ns := range tolerating {
+		ns.Annotations = map[string]string{schedulingv1alpha1.TolerationsAnnotationKey: `[{"key":"gpu","operator":"Equal","value":"true"}]`}
+	}
+
+	tests := map[string]struct {
+		target       *clusterFixture
+		namespaces   []*corev1.Namespace
+		budget       int
+		wantMoves    []string
+		wantBalanced bool
+	}{
+		"nothing to move": {
+			target:       defaultClusterFixture(),
+			wantBalanced: true,
+		},
+		"already spread": {
+			target:       defaultClusterFixture(),
+			namespaces:   concat(scheduledNamespaces("east", 2, nil), scheduledNamespaces(testClusterName, 1, nil)),
+			budget:       10,
+			wantBalanced: true,
+		},
+		"spread from the most loaded clusters": {
+			target:       defaultClusterFixture(),
+			namespaces:   concat(scheduledNamespaces("east", 4, nil), scheduledNamespaces("west", 2, nil)),
+			budget:       10,
+			wantMoves:    []string{"east-0", "east-1"},
+			wantBalanced: true,
+		},
+		"budget limits the moves": {
+			target:     defaultClusterFixture(),
+			namespaces: concat(scheduledNamespaces("east", 6, nil), scheduledNamespaces("west", 6, nil)),
+			budget:     3,
+			wantMoves:  []string{"east-0", "west-0", "east-1"},
+		},
+		"namespaces with scheduling disabled are counted, but not moved": {
+			target:       defaultClusterFixture(),
+			namespaces:   concat(scheduledNamespaces("east", 4, map[string]string{SchedulingDisabledLabel: ""}), scheduledNamespaces("west", 2, nil)),
+			budget:       10,
+			wantMoves:    []string{"west-0"},
+			wantBalanced: true,
+		},
+		"unscheduled namespaces are ignored": {
+			target:       defaultClusterFixture(),
+			namespaces:   concat(scheduledNamespaces("", 4, nil), scheduledNamespaces("east", 1, nil)),
+			budget:       10,
+			wantBalanced: true,
+		},
+		"only namespaces tolerating the taints of the target are moved": {
+			target:       defaultClusterFixture().withTaint(corev1.TaintEffectNoSchedule),
+			namespaces:   concat(tolerating, scheduledNamespaces("west", 4, nil)),
+			budget:       10,
+			wantMoves:    []string{"east-0"},
+			wantBalanced: true,
+		},
+	}
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			moves, balanced := spreadMoves(tc.target.cluster, tc.namespaces, tc.budget)
+			var names []string
+			for _, ns := range moves {
+				names = append(names, ns.Name)
+			}
+			require.Equal(t, tc.wantMoves, names)
+			require.Equal(t, tc.wantBalanced, balanced)
+		})
+	}
+}
+
+func TestRebalancerWait(t *testing.T) {
+	now := time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC)
+	r := newRebalancer(Options{RebalanceInterval: time.Minute})
+	r.now = func() time.Time { return now }
+
+	require.Zero(t, r.wait(testLclusterName))
+	r.rebalanced(testLclusterName)
+	now = now.Add(20 * time.Second)
+	require.Equal(t, 40*time.Second, r.wait(testLclusterName))
+	require.Zero(t, r.wait(logicalcluster.New("test:other")))
+	now = now.Add(time.Minute)
+	require.Zero(t, r.wait(testLclusterName))
+}
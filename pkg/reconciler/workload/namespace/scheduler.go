@@ -17,6 +17,7 @@ limitations under the License.
 package namespace
 
 import (
+	"fmt"
 	"math/rand"
 	"time"
 
@@ -29,16 +30,19 @@ import (
 	"k8s.io/klog/v2"
 
 	workloadv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/workload/v1alpha1"
+	locationreconciler "github.com/kcp-dev/kcp/pkg/reconciler/scheduling/location"
 	conditionsapi "github.com/kcp-dev/kcp/third_party/conditions/apis/conditions/v1alpha1"
 	"github.com/kcp-dev/kcp/third_party/conditions/util/conditions"
 )
 
 type getClusterFunc func(name string) (*workloadv1alpha1.WorkloadCluster, error)
 type listClustersFunc func(selector labels.Selector) ([]*workloadv1alpha1.WorkloadCluster, error)
+type listNamespacesFunc func(clusterName logicalcluster.Name) ([]*corev1.Namespace, error)
 
 type namespaceScheduler struct {
-	getCluster   getClusterFunc
-	listClusters listClustersFunc
+	getCluster     getClusterFunc
+	listClusters   listClustersFunc
+	listNamespaces listNamespacesFunc
 }
 
 // AssignCluster returns the name of the cluster to assign to the provided
@@ -54,8 +58,14 @@ func (s *namespaceScheduler) AssignCluster(ns *corev1.Namespace) (string, error)
 		return assignedCluster, nil
 	}
 
+	tolerations, err := locationreconciler.TolerationsFromAnnotations(ns.Annotations)
+	if err != nil {
+		// only schedule to untainted clusters
+		klog.Errorf("Invalid tolerations of namespace %s|%s: %v", logicalcluster.From(ns), ns.Name, err)
+	}
+
 	if assignedCluster != "" {
-		isValid, invalidMsg, err := s.isValidCluster(logicalcluster.From(ns), assignedCluster)
+		isValid, invalidMsg, err := s.isValidCluster(logicalcluster.From(ns), assignedCluster, tolerations)
 		if err != nil {
 			return "", err
 		}
@@ -70,17 +80,47 @@ func (s *namespaceScheduler) AssignCluster(ns *corev1.Namespace) (string, error)
 	if err != nil {
 		return "", err
 	}
-	return pickCluster(allClusters, logicalcluster.From(ns)), nil
+
+	var scores map[string]int
+	affinity, antiAffinity, err := locationreconciler.NamespaceAffinityFromAnnotations(ns.Annotations)
+	if err != nil {
+		// schedule without preferences
+		klog.Errorf("Invalid namespace affinity of namespace %s|%s: %v", logicalcluster.From(ns), ns.Name, err)
+	} else if affinity != nil || antiAffinity != nil {
+		namespaces, err := s.listNamespaces(logicalcluster.From(ns))
+		if err != nil {
+			return "", err
+		}
+		scores = scoreClusters(ns, namespaces, affinity, antiAffinity)
+	}
+
+	return pickCluster(allClusters, logicalcluster.From(ns), tolerations, scores), nil
+}
+
+// scoreClusters scores the workload clusters the given namespaces of the workspace of ns are
+// scheduled to: +1 for every namespace selected by the affinity, -1 for every namespace selected
+// by the anti-affinity. The namespace ns itself is never counted.
+func scoreClusters(ns *corev1.Namespace, namespaces []*corev1.Namespace, affinity, antiAffinity labels.Selector) map[string]int {
+	scores := map[string]int{}
+	for _, other := range namespaces {
+		assigned := other.Labels[DeprecatedScheduledClusterNamespaceLabel]
+		if other.Name == ns.Name || assigned == "" {
+			continue
+		}
+		scores[assigned] += locationreconciler.NamespaceAffinityScore(affinity, antiAffinity, other.Labels)
+	}
+	return scores
 }
 
 // isValidCluster checks whether the given cluster name exists and is valid for
 // the purposes of any namespace already scheduled to it (i.e., if it reports
-// as Ready, and any evictAfter value, if specified, has not yet passed).
+// as Ready, any evictAfter value, if specified, has not yet passed, and all
+// NoExecute taints are tolerated).
 //
-// It doesn't take into account Unschedulable, and should only be used when
-// determining if a cluster that a namespace has already been assigned to
-// should keep having that namespace.
-func (s *namespaceScheduler) isValidCluster(lclusterName logicalcluster.Name, clusterName string) (
+// It doesn't take into account Unschedulable and NoSchedule taints, and should
+// only be used when determining if a cluster that a namespace has already been
+// assigned to should keep having that namespace.
+func (s *namespaceScheduler) isValidCluster(lclusterName logicalcluster.Name, clusterName string, tolerations []corev1.Toleration) (
 	isValid bool, invalidMsg string, err error) {
 
 	cluster, err := s.getCluster(clusters.ToClusterAwareKey(lclusterName, clusterName))
@@ -97,14 +137,19 @@ func (s *namespaceScheduler) isValidCluster(lclusterName logicalcluster.Name, cl
 	if evictAfter := cluster.Spec.EvictAfter; evictAfter != nil && evictAfter.Time.Before(time.Now()) {
 		return false, "is cordoned", nil
 	}
+	if taint := locationreconciler.UntoleratedTaint(cluster, tolerations, corev1.TaintEffectNoExecute); taint != nil {
+		return false, fmt.Sprintf("has untolerated taint %s", taint.ToString()), nil
+	}
 	return true, "", nil
 }
 
 // pickCluster attempts to choose a cluster in the given logical
-// cluster to assign to a namespace. If a suitable cluster is
+// cluster to assign to a namespace with the given tolerations. Among
+// the suitable clusters, those with the highest score are preferred,
+// with a missing score counting as 0. If a suitable cluster is
 // identified, its name will be returned. Otherwise, an empty string
 // will be returned.
-func pickCluster(allClusters []*workloadv1alpha1.WorkloadCluster, lclusterName logicalcluster.Name) string {
+func pickCluster(allClusters []*workloadv1alpha1.WorkloadCluster, lclusterName logicalcluster.Name, tolerations []corev1.Toleration, scores map[string]int) string {
 	var clusters []*workloadv1alpha1.WorkloadCluster
 	for i := range allClusters {
 		// Only include Clusters that are in the logical cluster
@@ -130,6 +175,10 @@ func pickCluster(allClusters []*workloadv1alpha1.WorkloadCluster, lclusterName l
 		klog.V(3).InfoS("pickCluster: found a ready candidate", "metadata.name", allClusters[i].Name, "ns.clusterName", lclusterName)
 		clusters = append(clusters, allClusters[i])
 	}
+	clusters = locationreconciler.FilterTolerated(clusters, tolerations)
+	clusters = locationreconciler.FilterHighestScore(clusters, func(wc *workloadv1alpha1.WorkloadCluster) int {
+		return scores[wc.Name]
+	})
 
 	newClusterName := ""
 	if len(clusters) > 0 {
@@ -90,7 +90,14 @@ func (f *clusterFixture) withPassedEvictionTime() *clusterFixture {
 	return f
 }
 
-func newTestScheduler(clusters []*workloadv1alpha1.WorkloadCluster) namespaceScheduler {
+func (f *clusterFixture) withTaint(effect corev1.TaintEffect) *clusterFixture {
+	f.cluster.Spec.Taints = append(f.cluster.Spec.Taints, corev1.Taint{Key: "gpu", Value: "true", Effect: effect})
+	return f
+}
+
+var gpuToleration = corev1.Toleration{Key: "gpu", Operator: corev1.TolerationOpEqual, Value: "true"}
+
+func newTestScheduler(clusters []*workloadv1alpha1.WorkloadCluster, namespaces ...*corev1.Namespace) namespaceScheduler {
 	return namespaceScheduler{
 		getCluster: func(name string) (*workloadv1alpha1.WorkloadCluster, error) {
 			for _, cluster := range clusters {
@@ -103,6 +110,9 @@ func newTestScheduler(clusters []*workloadv1alpha1.WorkloadCluster) namespaceSch
 		listClusters: func(selector labels.Selector) ([]*workloadv1alpha1.WorkloadCluster, error) {
 			return clusters, nil
 		},
+		listNamespaces: func(clusterName logicalcluster.Name) ([]*corev1.Namespace, error) {
+			return namespaces, nil
+		},
 	}
 }
 
@@ -111,6 +121,7 @@ func TestAssignCluster(t *testing.T) {
 
 	testCases := map[string]struct {
 		labels          map[string]string
+		annotations     map[string]string
 		expectedCluster string
 	}{
 		"scheduling disabled set to empty -> no change even for unknown cluster name": {
@@ -142,11 +153,27 @@ func TestAssignCluster(t *testing.T) {
 		"no assignment -> new assignment": {
 			expectedCluster: testClusterName,
 		},
+		"valid assignment to tainted cluster with tolerations -> no change": {
+			labels: map[string]string{
+				DeprecatedScheduledClusterNamespaceLabel: otherTestClusterName,
+			},
+			annotations: map[string]string{
+				"scheduling.kcp.dev/tolerations": `[{"key":"gpu","operator":"Exists"}]`,
+			},
+			expectedCluster: otherTestClusterName,
+		},
+		"valid assignment to tainted cluster without tolerations -> new assignment": {
+			labels: map[string]string{
+				DeprecatedScheduledClusterNamespaceLabel: otherTestClusterName,
+			},
+			expectedCluster: testClusterName,
+		},
 	}
 	for testName, testCase := range testCases {
 		t.Run(testName, func(t *testing.T) {
 			clusters := []*workloadv1alpha1.WorkloadCluster{
 				defaultClusterFixture().withReady().cluster,
+				otherClusterFixture().withReady().withTaint(corev1.TaintEffectNoExecute).cluster,
 			}
 			scheduler := newTestScheduler(clusters)
 			ns := &corev1.Namespace{
@@ -154,6 +181,78 @@ func TestAssignCluster(t *testing.T) {
 					Name:        "default",
 					ClusterName: testLclusterName.String(),
 					Labels:      testCase.labels,
+					Annotations: testCase.annotations,
+				},
+			}
+			clusterName, err := scheduler.AssignCluster(ns)
+			require.NoError(t, err)
+			require.Equal(t, testCase.expectedCluster, clusterName)
+		})
+	}
+}
+
+func TestAssignClusterNamespaceAffinity(t *testing.T) {
+	namespace := func(name, app, cluster string) *corev1.Namespace {
+		return &corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        name,
+				ClusterName: testLclusterName.String(),
+				Labels:      map[string]string{"app": app, DeprecatedScheduledClusterNamespaceLabel: cluster},
+			},
+		}
+	}
+
+	testCases := map[string]struct {
+		annotations     map[string]string
+		namespaces      []*corev1.Namespace
+		expectedCluster string
+	}{
+		"affinity -> cluster of the selected namespace": {
+			annotations:     map[string]string{"scheduling.kcp.dev/namespace-affinity": "app=backend"},
+			namespaces:      []*corev1.Namespace{namespace("backend", "backend", otherTestClusterName)},
+			expectedCluster: otherTestClusterName,
+		},
+		"anti-affinity -> other cluster than the selected namespace": {
+			annotations:     map[string]string{"scheduling.kcp.dev/namespace-anti-affinity": "app=staging"},
+			namespaces:      []*corev1.Namespace{namespace("staging-1", "staging", otherTestClusterName)},
+			expectedCluster: testClusterName,
+		},
+		"affinity outweighed by anti-affinity": {
+			annotations: map[string]string{
+				"scheduling.kcp.dev/namespace-affinity":      "app in (backend,cache)",
+				"scheduling.kcp.dev/namespace-anti-affinity": "app=staging",
+			},
+			namespaces: []*corev1.Namespace{
+				namespace("backend", "backend", otherTestClusterName),
+				namespace("staging-1", "staging", otherTestClusterName),
+				namespace("staging-2", "staging", otherTestClusterName),
+				namespace("cache", "cache", testClusterName),
+			},
+			expectedCluster: testClusterName,
+		},
+		"selected namespace not scheduled -> ignored": {
+			annotations:     map[string]string{"scheduling.kcp.dev/namespace-anti-affinity": "app=staging"},
+			namespaces:      []*corev1.Namespace{namespace("staging-1", "staging", otherTestClusterName), namespace("staging-2", "staging", "")},
+			expectedCluster: testClusterName,
+		},
+		"anti-affinity selecting the namespace itself -> ignored": {
+			annotations:     map[string]string{"scheduling.kcp.dev/namespace-anti-affinity": "app=staging"},
+			namespaces:      []*corev1.Namespace{namespace("default", "staging", testClusterName), namespace("staging-1", "staging", otherTestClusterName)},
+			expectedCluster: testClusterName,
+		},
+	}
+	for testName, testCase := range testCases {
+		t.Run(testName, func(t *testing.T) {
+			clusters := []*workloadv1alpha1.WorkloadCluster{
+				defaultClusterFixture().withReady().cluster,
+				otherClusterFixture().withReady().cluster,
+			}
+			scheduler := newTestScheduler(clusters, testCase.namespaces...)
+			ns := &corev1.Namespace{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:        "default",
+					ClusterName: testLclusterName.String(),
+					Annotations: testCase.annotations,
 				},
 			}
 			clusterName, err := scheduler.AssignCluster(ns)
@@ -165,8 +264,9 @@ func TestAssignCluster(t *testing.T) {
 
 func TestIsValidCluster(t *testing.T) {
 	testCases := map[string]struct {
-		cluster *clusterFixture
-		isValid bool
+		cluster     *clusterFixture
+		tolerations []corev1.Toleration
+		isValid     bool
 	}{
 		"missing -> false": {},
 		"unready -> false": {
@@ -183,6 +283,18 @@ func TestIsValidCluster(t *testing.T) {
 			cluster: defaultClusterFixture().withReady().withFutureEvictionTime(),
 			isValid: true,
 		},
+		"ready and untolerated NoExecute taint -> false": {
+			cluster: defaultClusterFixture().withReady().withTaint(corev1.TaintEffectNoExecute),
+		},
+		"ready and tolerated NoExecute taint -> true": {
+			cluster:     defaultClusterFixture().withReady().withTaint(corev1.TaintEffectNoExecute),
+			tolerations: []corev1.Toleration{gpuToleration},
+			isValid:     true,
+		},
+		"ready and untolerated NoSchedule taint -> true": {
+			cluster: defaultClusterFixture().withReady().withTaint(corev1.TaintEffectNoSchedule),
+			isValid: true,
+		},
 	}
 	for testName, testCase := range testCases {
 		t.Run(testName, func(t *testing.T) {
@@ -191,7 +303,7 @@ func TestIsValidCluster(t *testing.T) {
 				clusters = append(clusters, testCase.cluster.cluster)
 			}
 			scheduler := newTestScheduler(clusters)
-			isValid, _, err := scheduler.isValidCluster(testLclusterName, testClusterName)
+			isValid, _, err := scheduler.isValidCluster(testLclusterName, testClusterName, testCase.tolerations)
 			require.NoError(t, err)
 			require.Equal(t, testCase.isValid, isValid)
 		})
@@ -201,6 +313,8 @@ func TestIsValidCluster(t *testing.T) {
 func TestPickCluster(t *testing.T) {
 	testCases := map[string]struct {
 		clusters        []*clusterFixture
+		tolerations     []corev1.Toleration
+		scores          map[string]int
 		anyAssignment   bool
 		expectedCluster string
 	}{
@@ -236,6 +350,52 @@ func TestPickCluster(t *testing.T) {
 			},
 			expectedCluster: testClusterName,
 		},
+		"ignore a cluster with untolerated NoSchedule taint": {
+			clusters: []*clusterFixture{
+				defaultClusterFixture().withReady().withTaint(corev1.TaintEffectNoSchedule),
+			},
+		},
+		"ignore a cluster with untolerated NoExecute taint": {
+			clusters: []*clusterFixture{
+				defaultClusterFixture().withReady().withTaint(corev1.TaintEffectNoExecute),
+			},
+		},
+		"return a cluster with tolerated NoSchedule taint": {
+			clusters: []*clusterFixture{
+				defaultClusterFixture().withReady().withTaint(corev1.TaintEffectNoSchedule),
+			},
+			tolerations:     []corev1.Toleration{gpuToleration},
+			expectedCluster: testClusterName,
+		},
+		"prefer a cluster without untolerated PreferNoSchedule taint": {
+			clusters: []*clusterFixture{
+				defaultClusterFixture().withReady().withTaint(corev1.TaintEffectPreferNoSchedule),
+				otherClusterFixture().withReady(),
+			},
+			expectedCluster: otherTestClusterName,
+		},
+		"return a cluster with untolerated PreferNoSchedule taint if there is no other": {
+			clusters: []*clusterFixture{
+				defaultClusterFixture().withReady().withTaint(corev1.TaintEffectPreferNoSchedule),
+			},
+			expectedCluster: testClusterName,
+		},
+		"prefer the cluster with the highest score": {
+			clusters: []*clusterFixture{
+				defaultClusterFixture().withReady(),
+				otherClusterFixture().withReady(),
+			},
+			scores:          map[string]int{testClusterName: -1},
+			expectedCluster: otherTestClusterName,
+		},
+		"ignore the score of an unsuitable cluster": {
+			clusters: []*clusterFixture{
+				defaultClusterFixture().withReady().withUnscheduable(),
+				otherClusterFixture().withReady(),
+			},
+			scores:          map[string]int{testClusterName: 2},
+			expectedCluster: otherTestClusterName,
+		},
 		"2 clusters -> any cluster name": {
 			clusters: []*clusterFixture{
 				defaultClusterFixture().withReady(),
@@ -250,7 +410,7 @@ func TestPickCluster(t *testing.T) {
 			for _, fixture := range testCase.clusters {
 				clusters = append(clusters, fixture.cluster)
 			}
-			clusterName := pickCluster(clusters, testLclusterName)
+			clusterName := pickCluster(clusters, testLclusterName, testCase.tolerations, testCase.scores)
 			if testCase.anyAssignment {
 				found := false
 				for _, cluster := range clusters {
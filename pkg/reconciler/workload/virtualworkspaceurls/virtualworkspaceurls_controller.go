@@ -0,0 +1,196 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package virtualworkspaceurls
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/kcp-dev/logicalcluster"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"k8s.io/klog/v2"
+
+	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/workload/v1alpha1"
+	kcpclient "github.com/kcp-dev/kcp/pkg/client/clientset/versioned"
+	tenancyinformer "github.com/kcp-dev/kcp/pkg/client/informers/externalversions/tenancy/v1alpha1"
+	workloadinformer "github.com/kcp-dev/kcp/pkg/client/informers/externalversions/workload/v1alpha1"
+	tenancylister "github.com/kcp-dev/kcp/pkg/client/listers/tenancy/v1alpha1"
+	workloadlister "github.com/kcp-dev/kcp/pkg/client/listers/workload/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/logging"
+)
+
+const controllerName = "kcp-workloadcluster-virtualworkspace-urls"
+
+// NewController returns a controller maintaining status.virtualWorkspaces of WorkloadClusters,
+// i.e. the syncer virtual workspace URLs of every ClusterWorkspaceShard.
+func NewController(
+	kcpClusterClient kcpclient.ClusterInterface,
+	rootWorkspaceShardInformer tenancyinformer.ClusterWorkspaceShardInformer,
+	workloadClusterInformer workloadinformer.WorkloadClusterInformer,
+) (*Controller, error) {
+	c := &Controller{
+		queue: workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), controllerName),
+
+		kcpClusterClient:         kcpClusterClient,
+		rootWorkspaceShardLister: rootWorkspaceShardInformer.Lister(),
+		workloadClusterLister:    workloadClusterInformer.Lister(),
+	}
+
+	rootWorkspaceShardInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) { c.enqueueAllWorkloadClusters() },
+		UpdateFunc: func(oldObj, obj interface{}) {
+			oldShard, ok := oldObj.(*tenancyv1alpha1.ClusterWorkspaceShard)
+			if !ok {
+				return
+			}
+			shard, ok := obj.(*tenancyv1alpha1.ClusterWorkspaceShard)
+			if !ok {
+				return
+			}
+			if oldShard.Spec.VirtualWorkspaceURL != shard.Spec.VirtualWorkspaceURL || oldShard.Spec.BaseURL != shard.Spec.BaseURL {
+				c.enqueueAllWorkloadClusters()
+			}
+		},
+		DeleteFunc: func(obj interface{}) { c.enqueueAllWorkloadClusters() },
+	})
+
+	workloadClusterInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) { c.enqueue(obj) },
+		UpdateFunc: func(oldObj, obj interface{}) {
+			oldCluster, ok := oldObj.(*workloadv1alpha1.WorkloadCluster)
+			if !ok {
+				return
+			}
+			cluster, ok := obj.(*workloadv1alpha1.WorkloadCluster)
+			if !ok {
+				return
+			}
+			if !equality.Semantic.DeepEqual(oldCluster.Status.VirtualWorkspaces, cluster.Status.VirtualWorkspaces) {
+				c.enqueue(obj)
+			}
+		},
+	})
+
+	return c, nil
+}
+
+// Controller keeps status.virtualWorkspaces of WorkloadClusters in sync with the virtual
+// workspace URLs of the ClusterWorkspaceShards, e.g. when virtual workspaces run out-of-process.
+type Controller struct {
+	queue workqueue.RateLimitingInterface
+
+	kcpClusterClient         kcpclient.ClusterInterface
+	rootWorkspaceShardLister tenancylister.ClusterWorkspaceShardLister
+	workloadClusterLister    workloadlister.WorkloadClusterLister
+}
+
+func (c *Controller) enqueue(obj interface{}) {
+	key, err := cache.MetaNamespaceKeyFunc(obj)
+	if err != nil {
+		runtime.HandleError(err)
+		return
+	}
+	logging.WithQueueKey(logging.NewLogger(controllerName), key).V(4).Info("Queueing WorkloadCluster")
+	c.queue.Add(key)
+}
+
+func (c *Controller) enqueueAllWorkloadClusters() {
+	workloadClusters, err := c.workloadClusterLister.List(labels.Everything())
+	if err != nil {
+		runtime.HandleError(err)
+		return
+	}
+	for _, cluster := range workloadClusters {
+		c.enqueue(cluster)
+	}
+}
+
+func (c *Controller) Start(ctx context.Context, numThreads int) {
+	defer runtime.HandleCrash()
+	defer c.queue.ShutDown()
+
+	klog.Infof("Starting %s controller", controllerName)
+	defer klog.Infof("Shutting down %s controller", controllerName)
+
+	for i := 0; i < numThreads; i++ {
+		go wait.UntilWithContext(ctx, c.startWorker, time.Second)
+	}
+
+	<-ctx.Done()
+}
+
+func (c *Controller) startWorker(ctx context.Context) {
+	for c.processNextWorkItem(ctx) {
+	}
+}
+
+func (c *Controller) processNextWorkItem(ctx context.Context) bool {
+	// Wait until there is a new item in the working queue
+	k, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+	key := k.(string)
+
+	logger := logging.WithQueueKey(logging.NewLogger(controllerName), key)
+	ctx = logging.NewContext(ctx, logger)
+
+	// No matter what, tell the queue we're done with this key, to unblock
+	// other workers.
+	defer c.queue.Done(key)
+
+	if err := c.process(ctx, key); err != nil {
+		runtime.HandleError(fmt.Errorf("%q controller failed to sync %q, err: %w", controllerName, key, err))
+		c.queue.AddRateLimited(key)
+		return true
+	}
+	c.queue.Forget(key)
+	return true
+}
+
+func (c *Controller) process(ctx context.Context, key string) error {
+	cluster, err := c.workloadClusterLister.Get(key)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	shards, err := c.rootWorkspaceShardLister.List(labels.Everything())
+	if err != nil {
+		return err
+	}
+
+	r := &reconciler{
+		patchWorkloadCluster: func(ctx context.Context, clusterName logicalcluster.Name, name string, patch []byte) error {
+			_, err := c.kcpClusterClient.Cluster(clusterName).WorkloadV1alpha1().WorkloadClusters().Patch(ctx, name, types.MergePatchType, patch, metav1.PatchOptions{}, "status")
+			return err
+		},
+	}
+	return r.reconcile(ctx, cluster, shards)
+}
@@ -0,0 +1,93 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package virtualworkspaceurls
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"path"
+	"sort"
+
+	"github.com/kcp-dev/logicalcluster"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+
+	virtualcommandoptions "github.com/kcp-dev/kcp/cmd/virtual-workspaces/options"
+	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/workload/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/logging"
+	syncerbuilder "github.com/kcp-dev/kcp/pkg/virtual/syncer/builder"
+)
+
+// reconciler computes the syncer virtual workspace URLs of a WorkloadCluster.
+type reconciler struct {
+	patchWorkloadCluster func(ctx context.Context, clusterName logicalcluster.Name, name string, patch []byte) error
+}
+
+func (r *reconciler) reconcile(ctx context.Context, cluster *workloadv1alpha1.WorkloadCluster, shards []*tenancyv1alpha1.ClusterWorkspaceShard) error {
+	logger := logging.WithSyncTarget(logging.FromContext(ctx), cluster.Name)
+
+	sorted := make([]*tenancyv1alpha1.ClusterWorkspaceShard, len(shards))
+	copy(sorted, shards)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+
+	virtualWorkspaces := []workloadv1alpha1.VirtualWorkspace{}
+	for _, shard := range sorted {
+		u, err := SyncerVirtualWorkspaceURL(shard, logicalcluster.From(cluster), cluster.Name)
+		if err != nil {
+			logger.Error(err, "Invalid virtual workspace URL of ClusterWorkspaceShard", "shard", shard.Name)
+			continue // nothing we can do until the shard is fixed
+		}
+		virtualWorkspaces = append(virtualWorkspaces, workloadv1alpha1.VirtualWorkspace{URL: u})
+	}
+
+	if equality.Semantic.DeepEqual(virtualWorkspaces, cluster.Status.VirtualWorkspaces) ||
+		(len(virtualWorkspaces) == 0 && len(cluster.Status.VirtualWorkspaces) == 0) {
+		return nil
+	}
+
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"resourceVersion": cluster.ResourceVersion,
+		},
+		"status": map[string]interface{}{
+			"virtualWorkspaces": virtualWorkspaces,
+		},
+	})
+	if err != nil {
+		return err
+	}
+	logger.V(2).Info("Updating virtual workspace URLs of WorkloadCluster", "virtualWorkspaces", virtualWorkspaces)
+	return r.patchWorkloadCluster(ctx, logicalcluster.From(cluster), cluster.Name, patch)
+}
+
+// SyncerVirtualWorkspaceURL returns the URL of the syncer virtual workspace on the given shard
+// serving the given workload cluster. It falls back to the base URL of the shard if no
+// virtual workspace URL is set.
+func SyncerVirtualWorkspaceURL(shard *tenancyv1alpha1.ClusterWorkspaceShard, clusterName logicalcluster.Name, workloadClusterName string) (string, error) {
+	base := shard.Spec.VirtualWorkspaceURL
+	if base == "" {
+		base = shard.Spec.BaseURL
+	}
+	u, err := url.Parse(base)
+	if err != nil {
+		return "", err
+	}
+	u.Path = path.Join(u.Path, virtualcommandoptions.DefaultRootPathPrefix, syncerbuilder.SyncerVirtualWorkspaceName, clusterName.String(), workloadClusterName)
+	return u.String(), nil
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package virtualworkspaceurls
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kcp-dev/logicalcluster"
+	"github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/workload/v1alpha1"
+)
+
+func shard(name, baseURL, virtualWorkspaceURL string) *tenancyv1alpha1.ClusterWorkspaceShard {
+	return &tenancyv1alpha1.ClusterWorkspaceShard{
+		ObjectMeta: metav1.ObjectMeta{Name: name, ClusterName: "root"},
+		Spec: tenancyv1alpha1.ClusterWorkspaceShardSpec{
+			BaseURL:             baseURL,
+			ExternalURL:         baseURL,
+			VirtualWorkspaceURL: virtualWorkspaceURL,
+		},
+	}
+}
+
+func TestReconcile(t *testing.T) {
+	tests := map[string]struct {
+		shards   []*tenancyv1alpha1.ClusterWorkspaceShard
+		existing []string
+
+		wantPatch string
+	}{
+		"no shards": {},
+		"in-process virtual workspaces": {
+			shards:    []*tenancyv1alpha1.ClusterWorkspaceShard{shard("root", "https://shard.example.com", "")},
+			wantPatch: `{"metadata":{"resourceVersion":"42"},"status":{"virtualWorkspaces":[{"url":"https://shard.example.com/services/syncer/root:org:ws/us-east1"}]}}`,
+		},
+		"external virtual workspaces sorted by shard": {
+			shards: []*tenancyv1alpha1.ClusterWorkspaceShard{
+				shard("beta", "https://beta.example.com", "https://vw-beta.example.com/prefix"),
+				shard("alpha", "https://alpha.example.com", "https://vw-alpha.example.com"),
+			},
+			wantPatch: `{"metadata":{"resourceVersion":"42"},"status":{"virtualWorkspaces":[{"url":"https://vw-alpha.example.com/services/syncer/root:org:ws/us-east1"},{"url":"https://vw-beta.example.com/prefix/services/syncer/root:org:ws/us-east1"}]}}`,
+		},
+		"up-to-date": {
+			shards:   []*tenancyv1alpha1.ClusterWorkspaceShard{shard("root", "https://shard.example.com", "https://vw.example.com")},
+			existing: []string{"https://vw.example.com/services/syncer/root:org:ws/us-east1"},
+		},
+		"invalid shard is skipped": {
+			shards: []*tenancyv1alpha1.ClusterWorkspaceShard{
+				shard("root", "https://shard.example.com", ""),
+				shard("broken", "https://shard.example.com", "://broken"),
+			},
+			existing: []string{"https://shard.example.com/services/syncer/root:org:ws/us-east1"},
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			cluster := &workloadv1alpha1.WorkloadCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "us-east1", ClusterName: "root:org:ws", ResourceVersion: "42"},
+			}
+			for _, u := range tc.existing {
+				cluster.Status.VirtualWorkspaces = append(cluster.Status.VirtualWorkspaces, workloadv1alpha1.VirtualWorkspace{URL: u})
+			}
+
+			var gotPatch string
+			r := &reconciler{
+				patchWorkloadCluster: func(ctx context.Context, clusterName logicalcluster.Name, name string, patch []byte) error {
+					require.Equal(t, "root:org:ws", clusterName.String())
+					require.Equal(t, "us-east1", name)
+					gotPatch = string(patch)
+					return nil
+				},
+			}
+			err := r.reconcile(context.Background(), cluster, tc.shards)
+			require.NoError(t, err)
+			require.Equal(t, tc.wantPatch, gotPatch)
+		})
+	}
+}
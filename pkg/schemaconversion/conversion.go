@@ -0,0 +1,134 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package schemaconversion converts objects of bound APIs between the versions of their
+// APIResourceSchema, following the declarative conversions of the schema.
+package schemaconversion
+
+import (
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	apisv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apis/v1alpha1"
+)
+
+// reservedRootFields cannot be converted, because the apiserver sets them or
+// restricts changes of them during conversion.
+var reservedRootFields = map[string]bool{
+	"apiVersion": true,
+	"kind":       true,
+	"metadata":   true,
+}
+
+// ParseFieldPath parses a JSONPath selecting a field, e.g. `.spec.replicas`,
+// into its field names.
+func ParseFieldPath(path string) ([]string, error) {
+	if !strings.HasPrefix(path, ".") {
+		return nil, fmt.Errorf("must start with a dot")
+	}
+	fields := strings.Split(strings.TrimPrefix(path, "."), ".")
+	for _, f := range fields {
+		if f == "" {
+			return nil, fmt.Errorf("must not contain empty field names")
+		}
+		if errs := validation.IsCIdentifier(f); len(errs) > 0 {
+			return nil, fmt.Errorf("only field names are supported, got %q", f)
+		}
+	}
+	if reservedRootFields[fields[0]] {
+		return nil, fmt.Errorf("%s cannot be converted", fields[0])
+	}
+	return fields, nil
+}
+
+// Convert converts obj to the given version of the schema. The fields listed
+// in the conversion between the versions are moved, all others are kept.
+func Convert(s *apisv1alpha1.APIResourceSchema, obj *unstructured.Unstructured, toVersion string) (*unstructured.Unstructured, error) {
+	gv, err := schema.ParseGroupVersion(obj.GetAPIVersion())
+	if err != nil {
+		return nil, err
+	}
+	if gv.Group != s.Spec.Group {
+		return nil, fmt.Errorf("unexpected group %q, expected %q", gv.Group, s.Spec.Group)
+	}
+
+	converted := obj.DeepCopy()
+	converted.SetAPIVersion(schema.GroupVersion{Group: s.Spec.Group, Version: toVersion}.String())
+	if gv.Version == toVersion {
+		return converted, nil
+	}
+
+	for _, c := range s.Spec.Conversions {
+		switch {
+		case c.From == gv.Version && c.To == toVersion:
+			if err := moveFields(converted, c.Fields, false); err != nil {
+				return nil, err
+			}
+		case c.From == toVersion && c.To == gv.Version:
+			if err := moveFields(converted, c.Fields, true); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return converted, nil
+}
+
+// moveFields moves the values of all fields before setting any of them, such
+// that fields can be swapped.
+func moveFields(obj *unstructured.Unstructured, fields []apisv1alpha1.APIResourceConversionField, reverse bool) error {
+	type move struct {
+		to    []string
+		value interface{}
+	}
+	var moves []move
+	for _, f := range fields {
+		from, to := f.From, f.To
+		if reverse {
+			from, to = to, from
+		}
+		fromPath, err := ParseFieldPath(from)
+		if err != nil {
+			return fmt.Errorf("invalid field %q: %w", from, err)
+		}
+		toPath, err := ParseFieldPath(to)
+		if err != nil {
+			return fmt.Errorf("invalid field %q: %w", to, err)
+		}
+
+		value, found, err := unstructured.NestedFieldNoCopy(obj.Object, fromPath...)
+		if err != nil {
+			return fmt.Errorf("failed to read field %q: %w", from, err)
+		}
+		if !found {
+			continue
+		}
+		unstructured.RemoveNestedField(obj.Object, fromPath...)
+		moves = append(moves, move{to: toPath, value: runtime.DeepCopyJSONValue(value)})
+	}
+
+	for _, m := range moves {
+		if err := unstructured.SetNestedField(obj.Object, m.value, m.to...); err != nil {
+			return fmt.Errorf("failed to set field .%s: %w", strings.Join(m.to, "."), err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,171 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schemaconversion
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	apisv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apis/v1alpha1"
+)
+
+func TestParseFieldPath(t *testing.T) {
+	tests := []struct {
+		path    string
+		want    []string
+		wantErr string
+	}{
+		{path: ".spec.replicas", want: []string{"spec", "replicas"}},
+		{path: ".status", want: []string{"status"}},
+		{path: "spec.replicas", wantErr: "must start with a dot"},
+		{path: ".", wantErr: "must not contain empty field names"},
+		{path: ".spec..replicas", wantErr: "must not contain empty field names"},
+		{path: ".spec.containers[0]", wantErr: `only field names are supported, got "containers[0]"`},
+		{path: ".spec.*", wantErr: `only field names are supported, got "*"`},
+		{path: ".metadata.labels", wantErr: "metadata cannot be converted"},
+		{path: ".apiVersion", wantErr: "apiVersion cannot be converted"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			got, err := ParseFieldPath(tt.path)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestConvert(t *testing.T) {
+	schema := &apisv1alpha1.APIResourceSchema{
+		Spec: apisv1alpha1.APIResourceSchemaSpec{
+			Group: "wild.west",
+			Versions: []apisv1alpha1.APIResourceVersion{
+				{Name: "v1"},
+				{Name: "v2"},
+				{Name: "v3"},
+			},
+			Conversions: []apisv1alpha1.APIResourceConversion{
+				{
+					From: "v1",
+					To:   "v2",
+					Fields: []apisv1alpha1.APIResourceConversionField{
+						{From: ".spec.horse", To: ".spec.mount.name"},
+						{From: ".spec.left", To: ".spec.right"},
+						{From: ".spec.right", To: ".spec.left"},
+					},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name      string
+		obj       map[string]interface{}
+		toVersion string
+		want      map[string]interface{}
+		wantErr   string
+	}{
+		{
+			name: "forward conversion moves fields",
+			obj: map[string]interface{}{
+				"apiVersion": "wild.west/v1",
+				"kind":       "Cowboy",
+				"metadata":   map[string]interface{}{"name": "joe"},
+				"spec":       map[string]interface{}{"horse": "silver", "hat": "black", "left": int64(1), "right": int64(2)},
+			},
+			toVersion: "v2",
+			want: map[string]interface{}{
+				"apiVersion": "wild.west/v2",
+				"kind":       "Cowboy",
+				"metadata":   map[string]interface{}{"name": "joe"},
+				"spec":       map[string]interface{}{"mount": map[string]interface{}{"name": "silver"}, "hat": "black", "left": int64(2), "right": int64(1)},
+			},
+		},
+		{
+			name: "reverse conversion moves fields back",
+			obj: map[string]interface{}{
+				"apiVersion": "wild.west/v2",
+				"kind":       "Cowboy",
+				"spec":       map[string]interface{}{"mount": map[string]interface{}{"name": "silver"}},
+			},
+			toVersion: "v1",
+			want: map[string]interface{}{
+				"apiVersion": "wild.west/v1",
+				"kind":       "Cowboy",
+				"spec":       map[string]interface{}{"mount": map[string]interface{}{}, "horse": "silver"},
+			},
+		},
+		{
+			name: "missing fields are skipped",
+			obj: map[string]interface{}{
+				"apiVersion": "wild.west/v1",
+				"kind":       "Cowboy",
+				"spec":       map[string]interface{}{"hat": "black"},
+			},
+			toVersion: "v2",
+			want: map[string]interface{}{
+				"apiVersion": "wild.west/v2",
+				"kind":       "Cowboy",
+				"spec":       map[string]interface{}{"hat": "black"},
+			},
+		},
+		{
+			name: "versions without conversion only change the apiVersion",
+			obj: map[string]interface{}{
+				"apiVersion": "wild.west/v1",
+				"kind":       "Cowboy",
+				"spec":       map[string]interface{}{"horse": "silver"},
+			},
+			toVersion: "v3",
+			want: map[string]interface{}{
+				"apiVersion": "wild.west/v3",
+				"kind":       "Cowboy",
+				"spec":       map[string]interface{}{"horse": "silver"},
+			},
+		},
+		{
+			name: "objects of another group are rejected",
+			obj: map[string]interface{}{
+				"apiVersion": "tame.east/v1",
+				"kind":       "Cowboy",
+			},
+			toVersion: "v2",
+			wantErr:   `unexpected group "tame.east", expected "wild.west"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			obj := &unstructured.Unstructured{Object: tt.obj}
+			original := obj.DeepCopy()
+
+			got, err := Convert(schema, obj, tt.toVersion)
+			if tt.wantErr != "" {
+				require.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got.Object)
+			require.Equal(t, original, obj, "input object must not be mutated")
+		})
+	}
+}
@@ -0,0 +1,190 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schemaconversion
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/kcp-dev/logicalcluster"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apiserver/pkg/util/webhook"
+	"k8s.io/client-go/rest"
+	"k8s.io/klog/v2"
+	"k8s.io/utils/pointer"
+
+	apisv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apis/v1alpha1"
+)
+
+const (
+	// ServiceNamespace is the namespace of the conversion webhook service of bound CRDs.
+	// The service does not exist, but is served by kcp in-memory.
+	ServiceNamespace = "kcp-system"
+	// ServiceName is the name of the conversion webhook service of bound CRDs.
+	ServiceName = "apiresourceschema-conversion"
+)
+
+// SchemaGetter returns the APIResourceSchema with the given name in the given logical cluster.
+type SchemaGetter func(clusterName logicalcluster.Name, name string) (*apisv1alpha1.APIResourceSchema, error)
+
+// WebhookClientConfig returns the client config of the conversion webhook of a bound CRD
+// for the given APIResourceSchema.
+func WebhookClientConfig(s *apisv1alpha1.APIResourceSchema) *apiextensionsv1.WebhookClientConfig {
+	return &apiextensionsv1.WebhookClientConfig{
+		Service: &apiextensionsv1.ServiceReference{
+			Namespace: ServiceNamespace,
+			Name:      ServiceName,
+			Path:      pointer.StringPtr(webhookPath(logicalcluster.From(s), s.Name)),
+			Port:      pointer.Int32Ptr(443),
+		},
+	}
+}
+
+// webhookPath returns the path identifying an APIResourceSchema. Service paths
+// must consist of DNS subdomains, hence the separators of the logical cluster
+// name become path separators, e.g. /root/org/ws/today.cowboys.wild.west.
+func webhookPath(clusterName logicalcluster.Name, name string) string {
+	return "/" + strings.ReplaceAll(clusterName.String(), ":", "/") + "/" + name
+}
+
+// NewHandler returns a handler serving v1 ConversionReviews for bound CRDs. The
+// APIResourceSchema is identified by the request path, see webhookPath.
+func NewHandler(getSchema SchemaGetter) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		parts := strings.Split(strings.Trim(req.URL.Path, "/"), "/")
+		if len(parts) < 2 {
+			http.Error(w, fmt.Sprintf("unexpected path %q", req.URL.Path), http.StatusNotFound)
+			return
+		}
+		clusterName, name := logicalcluster.New(strings.Join(parts[:len(parts)-1], ":")), parts[len(parts)-1]
+		s, err := getSchema(clusterName, name)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("failed to get APIResourceSchema %s|%s: %v", clusterName, name, err), http.StatusNotFound)
+			return
+		}
+
+		body, err := ioutil.ReadAll(req.Body)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		review := &apiextensionsv1.ConversionReview{}
+		if err := json.Unmarshal(body, review); err != nil {
+			http.Error(w, fmt.Sprintf("failed to decode ConversionReview: %v", err), http.StatusBadRequest)
+			return
+		}
+		if review.Request == nil {
+			http.Error(w, "ConversionReview without request", http.StatusBadRequest)
+			return
+		}
+
+		review.Response = convertReview(s, review.Request)
+		review.Request = nil
+		review.TypeMeta = metav1.TypeMeta{APIVersion: apiextensionsv1.SchemeGroupVersion.String(), Kind: "ConversionReview"}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(review); err != nil {
+			klog.Errorf("Failed to encode ConversionReview for APIResourceSchema %s|%s: %v", clusterName, name, err)
+		}
+	})
+}
+
+func convertReview(s *apisv1alpha1.APIResourceSchema, req *apiextensionsv1.ConversionRequest) *apiextensionsv1.ConversionResponse {
+	resp := &apiextensionsv1.ConversionResponse{
+		UID:    req.UID,
+		Result: metav1.Status{Status: metav1.StatusSuccess},
+	}
+
+	failed := func(err error) *apiextensionsv1.ConversionResponse {
+		resp.ConvertedObjects = nil
+		resp.Result = metav1.Status{Status: metav1.StatusFailure, Message: err.Error()}
+		return resp
+	}
+
+	gv, err := schema.ParseGroupVersion(req.DesiredAPIVersion)
+	if err != nil {
+		return failed(err)
+	}
+	for _, raw := range req.Objects {
+		obj := &unstructured.Unstructured{}
+		if err := obj.UnmarshalJSON(raw.Raw); err != nil {
+			return failed(err)
+		}
+		converted, err := Convert(s, obj, gv.Version)
+		if err != nil {
+			return failed(err)
+		}
+		bs, err := converted.MarshalJSON()
+		if err != nil {
+			return failed(err)
+		}
+		resp.ConvertedObjects = append(resp.ConvertedObjects, runtime.RawExtension{Raw: bs})
+	}
+
+	return resp
+}
+
+// NewAuthenticationInfoResolverWrapper returns a wrapper that lets webhook clients reach
+// the conversion webhook service in-memory through handler. All other webhooks are
+// resolved by the delegate.
+func NewAuthenticationInfoResolverWrapper(handler http.Handler, delegate webhook.AuthenticationInfoResolverWrapper) webhook.AuthenticationInfoResolverWrapper {
+	return func(resolver webhook.AuthenticationInfoResolver) webhook.AuthenticationInfoResolver {
+		if delegate != nil {
+			resolver = delegate(resolver)
+		}
+		return &authenticationInfoResolver{
+			delegate: resolver,
+			handler:  handler,
+		}
+	}
+}
+
+type authenticationInfoResolver struct {
+	delegate webhook.AuthenticationInfoResolver
+	handler  http.Handler
+}
+
+func (r *authenticationInfoResolver) ClientConfigFor(hostPort string) (*rest.Config, error) {
+	return r.delegate.ClientConfigFor(hostPort)
+}
+
+func (r *authenticationInfoResolver) ClientConfigForService(serviceName, serviceNamespace string, servicePort int) (*rest.Config, error) {
+	if serviceNamespace == ServiceNamespace && serviceName == ServiceName {
+		return &rest.Config{Transport: &handlerRoundTripper{handler: r.handler}}, nil
+	}
+	return r.delegate.ClientConfigForService(serviceName, serviceNamespace, servicePort)
+}
+
+// handlerRoundTripper serves requests with a handler instead of sending them over the network.
+type handlerRoundTripper struct {
+	handler http.Handler
+}
+
+func (rt *handlerRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	w := httptest.NewRecorder()
+	rt.handler.ServeHTTP(w, req)
+	return w.Result(), nil
+}
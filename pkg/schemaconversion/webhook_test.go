@@ -0,0 +1,144 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schemaconversion
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/kcp-dev/logicalcluster"
+	"github.com/stretchr/testify/require"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apiserver/pkg/util/webhook"
+
+	apisv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apis/v1alpha1"
+)
+
+func TestWebhook(t *testing.T) {
+	cowboys := &apisv1alpha1.APIResourceSchema{
+		ObjectMeta: metav1.ObjectMeta{
+			ClusterName: "root:org:ws",
+			Name:        "today.cowboys.wild.west",
+		},
+		Spec: apisv1alpha1.APIResourceSchemaSpec{
+			Group: "wild.west",
+			Conversions: []apisv1alpha1.APIResourceConversion{
+				{
+					From:   "v1",
+					To:     "v2",
+					Fields: []apisv1alpha1.APIResourceConversionField{{From: ".spec.horse", To: ".spec.mount"}},
+				},
+			},
+		},
+	}
+	handler := NewHandler(func(clusterName logicalcluster.Name, name string) (*apisv1alpha1.APIResourceSchema, error) {
+		if clusterName == logicalcluster.From(cowboys) && name == cowboys.Name {
+			return cowboys, nil
+		}
+		return nil, errors.New("not found")
+	})
+
+	// talk to the handler through the webhook client of the apiextensions apiserver
+	cm, err := webhook.NewClientManager([]schema.GroupVersion{apiextensionsv1.SchemeGroupVersion}, apiextensionsv1.AddToScheme)
+	require.NoError(t, err)
+	authInfoResolver, err := webhook.NewDefaultAuthenticationInfoResolver("")
+	require.NoError(t, err)
+	cm.SetAuthenticationInfoResolver(authInfoResolver)
+	cm.SetAuthenticationInfoResolverWrapper(NewAuthenticationInfoResolverWrapper(handler, nil))
+	cm.SetServiceResolver(webhook.NewDefaultServiceResolver())
+	require.NoError(t, cm.Validate())
+
+	review := func(t *testing.T, clientConfig *apiextensionsv1.WebhookClientConfig, objects ...string) *apiextensionsv1.ConversionResponse {
+		client, err := cm.HookClient(webhook.ClientConfig{
+			Name: "test",
+			Service: &webhook.ClientConfigService{
+				Name:      clientConfig.Service.Name,
+				Namespace: clientConfig.Service.Namespace,
+				Path:      *clientConfig.Service.Path,
+				Port:      *clientConfig.Service.Port,
+			},
+		})
+		require.NoError(t, err)
+
+		request := &apiextensionsv1.ConversionReview{
+			TypeMeta: metav1.TypeMeta{APIVersion: "apiextensions.k8s.io/v1", Kind: "ConversionReview"},
+			Request: &apiextensionsv1.ConversionRequest{
+				UID:               types.UID("some-uid"),
+				DesiredAPIVersion: "wild.west/v2",
+			},
+		}
+		for _, o := range objects {
+			request.Request.Objects = append(request.Request.Objects, runtime.RawExtension{Raw: []byte(o)})
+		}
+		response := &apiextensionsv1.ConversionReview{}
+		err = client.Post().Body(request).Do(context.Background()).Into(response)
+		require.NoError(t, err)
+		require.Equal(t, "ConversionReview", response.Kind)
+		require.NotNil(t, response.Response)
+		require.Equal(t, types.UID("some-uid"), response.Response.UID)
+		return response.Response
+	}
+
+	t.Run("objects are converted", func(t *testing.T) {
+		response := review(t, WebhookClientConfig(cowboys),
+			`{"apiVersion":"wild.west/v1","kind":"Cowboy","metadata":{"name":"joe"},"spec":{"horse":"silver"}}`,
+			`{"apiVersion":"wild.west/v1","kind":"Cowboy","metadata":{"name":"jim"},"spec":{"hat":"black"}}`,
+		)
+		require.Equal(t, metav1.StatusSuccess, response.Result.Status, response.Result.Message)
+		require.Len(t, response.ConvertedObjects, 2)
+
+		var converted map[string]interface{}
+		require.NoError(t, json.Unmarshal(response.ConvertedObjects[0].Raw, &converted))
+		require.Equal(t, map[string]interface{}{
+			"apiVersion": "wild.west/v2",
+			"kind":       "Cowboy",
+			"metadata":   map[string]interface{}{"name": "joe"},
+			"spec":       map[string]interface{}{"mount": "silver"},
+		}, converted)
+	})
+
+	t.Run("conversion failures are reported in the response", func(t *testing.T) {
+		response := review(t, WebhookClientConfig(cowboys), `{"apiVersion":"tame.east/v1","kind":"Cowboy"}`)
+		require.Equal(t, metav1.StatusFailure, response.Result.Status)
+		require.Contains(t, response.Result.Message, `unexpected group "tame.east"`)
+		require.Empty(t, response.ConvertedObjects)
+	})
+
+	t.Run("unknown schemas fail", func(t *testing.T) {
+		unknown := cowboys.DeepCopy()
+		unknown.Name = "yesterday.cowboys.wild.west"
+		client, err := cm.HookClient(webhook.ClientConfig{
+			Name: "test",
+			Service: &webhook.ClientConfigService{
+				Name:      ServiceName,
+				Namespace: ServiceNamespace,
+				Path:      *WebhookClientConfig(unknown).Service.Path,
+				Port:      443,
+			},
+		})
+		require.NoError(t, err)
+		err = client.Post().Body(&apiextensionsv1.ConversionReview{}).Do(context.Background()).Error()
+		require.Error(t, err)
+	})
+}
@@ -0,0 +1,131 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package options
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	"github.com/kcp-dev/kcp/pkg/secretstore"
+)
+
+const (
+	// ProviderEtcd keeps secrets in etcd, i.e. no external secret store is used.
+	ProviderEtcd = "etcd"
+	// ProviderVault keeps secrets in the KV version 2 secrets engine of Vault.
+	ProviderVault = "vault"
+	// ProviderPlugin keeps secrets in a secret store plugin listening on a unix socket.
+	ProviderPlugin = "plugin"
+)
+
+var providers = []string{ProviderEtcd, ProviderVault, ProviderPlugin}
+
+// Options configures the external secret store of kcp-managed credentials.
+type Options struct {
+	Provider string
+
+	VaultAddress   string
+	VaultTokenFile string
+	VaultCAFile    string
+	VaultMount     string
+	VaultPrefix    string
+
+	PluginSocket string
+}
+
+func NewOptions() *Options {
+	return &Options{
+		Provider:    ProviderEtcd,
+		VaultMount:  "secret",
+		VaultPrefix: "kcp",
+	}
+}
+
+func (o *Options) AddFlags(fs *pflag.FlagSet) {
+	fs.StringVar(&o.Provider, "secret-store", o.Provider, fmt.Sprintf("Where kcp-managed credentials are stored. One of %s. %q keeps them in Secret objects.", strings.Join(providers, ", "), ProviderEtcd))
+	fs.StringVar(&o.VaultAddress, "secret-store-vault-address", o.VaultAddress, "URL of the Vault server of --secret-store=vault.")
+	fs.StringVar(&o.VaultTokenFile, "secret-store-vault-token-file", o.VaultTokenFile, "File with the Vault token. It is read for every request, such that renewed tokens are picked up.")
+	fs.StringVar(&o.VaultCAFile, "secret-store-vault-ca-file", o.VaultCAFile, "File with the CA certificates to verify the Vault server. The system CAs are used if empty.")
+	fs.StringVar(&o.VaultMount, "secret-store-vault-mount", o.VaultMount, "Mount path of the KV version 2 secrets engine in Vault.")
+	fs.StringVar(&o.VaultPrefix, "secret-store-vault-prefix", o.VaultPrefix, "Prefix of the paths of the secrets in the Vault secrets engine.")
+	fs.StringVar(&o.PluginSocket, "secret-store-plugin-socket", o.PluginSocket, "Unix socket of the secret store plugin of --secret-store=plugin.")
+}
+
+func (o *Options) Validate() []error {
+	var errs []error
+
+	switch o.Provider {
+	case ProviderEtcd:
+	case ProviderVault:
+		if o.VaultAddress == "" {
+			errs = append(errs, fmt.Errorf("--secret-store-vault-address is required for --secret-store=%s", ProviderVault))
+		}
+		if o.VaultTokenFile == "" {
+			errs = append(errs, fmt.Errorf("--secret-store-vault-token-file is required for --secret-store=%s", ProviderVault))
+		}
+		if o.VaultMount == "" {
+			errs = append(errs, fmt.Errorf("--secret-store-vault-mount must not be empty"))
+		}
+	case ProviderPlugin:
+		if o.PluginSocket == "" {
+			errs = append(errs, fmt.Errorf("--secret-store-plugin-socket is required for --secret-store=%s", ProviderPlugin))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("--secret-store must be one of %s", strings.Join(providers, ", ")))
+	}
+
+	return errs
+}
+
+// NewStore returns the configured secret store, or nil if secrets are kept in etcd.
+func (o *Options) NewStore() (secretstore.Store, error) {
+	switch o.Provider {
+	case ProviderVault:
+		client := &http.Client{Timeout: 10 * time.Second}
+		if o.VaultCAFile != "" {
+			caPEM, err := os.ReadFile(o.VaultCAFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read --secret-store-vault-ca-file: %w", err)
+			}
+			pool := x509.NewCertPool()
+			if !pool.AppendCertsFromPEM(caPEM) {
+				return nil, fmt.Errorf("no certificates found in --secret-store-vault-ca-file %q", o.VaultCAFile)
+			}
+			client.Transport = &http.Transport{
+				Proxy:           http.ProxyFromEnvironment,
+				TLSClientConfig: &tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12},
+			}
+		}
+		return secretstore.NewVaultStore(secretstore.VaultConfig{
+			Address:   o.VaultAddress,
+			TokenFile: o.VaultTokenFile,
+			Mount:     o.VaultMount,
+			Prefix:    o.VaultPrefix,
+			Client:    client,
+		}), nil
+	case ProviderPlugin:
+		return secretstore.NewPluginStore(o.PluginSocket), nil
+	}
+	return nil, nil
+}
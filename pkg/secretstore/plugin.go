@@ -0,0 +1,113 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secretstore
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"time"
+)
+
+// NewPluginStore returns a Store talking to a secret store plugin listening on the given unix socket, similar
+// to the KMS plugins of Kubernetes. Plugins serve HTTP on the socket:
+//
+//   - GET /v1/secrets/<key> returns 200 with {"data": {"<name>": "<base64 value>", ...}}, or 404.
+//   - POST /v1/secrets/<key> with the same body stores the data if the key has no data, or returns 409.
+//   - PUT /v1/secrets/<key> with the same body stores the data, replacing existing data.
+//
+// This allows keeping the data in any key management system, e.g. encrypted by an HSM.
+func NewPluginStore(socket string) Store {
+	dialer := &net.Dialer{Timeout: 5 * time.Second}
+	return &pluginStore{
+		client: &http.Client{
+			Timeout: 10 * time.Second,
+			Transport: &http.Transport{
+				DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+					return dialer.DialContext(ctx, "unix", socket)
+				},
+			},
+		},
+	}
+}
+
+type pluginStore struct {
+	client *http.Client
+}
+
+// pluginData is the payload of the plugin protocol.
+type pluginData struct {
+	Data map[string][]byte `json:"data"`
+}
+
+func (s *pluginStore) Get(ctx context.Context, key string) (map[string][]byte, error) {
+	var out pluginData
+	if err := s.do(ctx, http.MethodGet, key, nil, &out); err != nil {
+		return nil, err
+	}
+	return out.Data, nil
+}
+
+func (s *pluginStore) Create(ctx context.Context, key string, data map[string][]byte) error {
+	return s.do(ctx, http.MethodPost, key, &pluginData{Data: data}, nil)
+}
+
+func (s *pluginStore) Put(ctx context.Context, key string, data map[string][]byte) error {
+	return s.do(ctx, http.MethodPut, key, &pluginData{Data: data}, nil)
+}
+
+func (s *pluginStore) do(ctx context.Context, method, key string, in, out *pluginData) error {
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+	// the host is ignored by the dialer
+	req, err := http.NewRequestWithContext(ctx, method, "http://secretstore-plugin/v1/secrets/"+escapeKey(key), body)
+	if err != nil {
+		return err
+	}
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return fmt.Errorf("%w: %s", ErrNotFound, key)
+	case resp.StatusCode == http.StatusConflict:
+		return fmt.Errorf("%w: %s", ErrAlreadyExists, key)
+	case resp.StatusCode >= 300:
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("secret store plugin %s of %q failed with %s: %s", method, key, resp.Status, bytes.TrimSpace(msg))
+	case out == nil:
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
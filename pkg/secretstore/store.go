@@ -0,0 +1,68 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package secretstore stores credentials managed by kcp outside of etcd, e.g. in Vault or in a
+// key management service behind a plugin, for deployments with strict key-handling requirements.
+package secretstore
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strings"
+)
+
+// ErrNotFound is returned by a Store for keys without data.
+var ErrNotFound = errors.New("secret not found")
+
+// ErrAlreadyExists is returned by Store.Create for keys with data.
+var ErrAlreadyExists = errors.New("secret already exists")
+
+// IsNotFound returns true if the given error means that a key has no data.
+func IsNotFound(err error) bool {
+	return errors.Is(err, ErrNotFound)
+}
+
+// IsAlreadyExists returns true if the given error means that a key already has data.
+func IsAlreadyExists(err error) bool {
+	return errors.Is(err, ErrAlreadyExists)
+}
+
+// Store stores the data of secrets by key. Keys are slash separated paths, e.g.
+// shard-ca. Implementations must be safe for concurrent use.
+type Store interface {
+	// Get returns the data stored under the given key, or ErrNotFound.
+	Get(ctx context.Context, key string) (map[string][]byte, error)
+	// Create stores the given data under the given key if the key has no data, or returns ErrAlreadyExists.
+	// It is atomic, i.e. of concurrent creates of the same key exactly one succeeds.
+	Create(ctx context.Context, key string, data map[string][]byte) error
+	// Put stores the given data under the given key, replacing existing data.
+	Put(ctx context.Context, key string, data map[string][]byte) error
+}
+
+// Key joins the given segments to a key.
+func Key(segments ...string) string {
+	return strings.Join(segments, "/")
+}
+
+// escapeKey escapes the segments of the given key for use in a URL path.
+func escapeKey(key string) string {
+	segments := strings.Split(key, "/")
+	for i, s := range segments {
+		segments[i] = url.PathEscape(s)
+	}
+	return strings.Join(segments, "/")
+}
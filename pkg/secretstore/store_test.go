@@ -0,0 +1,160 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secretstore
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakeBackend serves the data of secrets by path in the format of the Vault KV version 2 secrets engine under
+// /v1/secret/data/, and in the format of the plugin protocol under /v1/secrets/.
+type fakeBackend struct {
+	t     *testing.T
+	token string
+
+	lock    sync.Mutex
+	secrets map[string]map[string][]byte
+}
+
+func (b *fakeBackend) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	var key string
+	var vault bool
+	switch {
+	case strings.HasPrefix(r.URL.Path, "/v1/secret/data/"):
+		key, vault = strings.TrimPrefix(r.URL.Path, "/v1/secret/data/"), true
+	case strings.HasPrefix(r.URL.Path, "/v1/secrets/"):
+		key = strings.TrimPrefix(r.URL.Path, "/v1/secrets/")
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	if vault && r.Header.Get("X-Vault-Token") != b.token {
+		http.Error(w, "permission denied", http.StatusForbidden)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		data, ok := b.secrets[key]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		var resp interface{} = map[string]interface{}{"data": data}
+		if vault {
+			resp = map[string]interface{}{"data": map[string]interface{}{"data": data}}
+		}
+		require.NoError(b.t, json.NewEncoder(w).Encode(resp))
+	case http.MethodPost, http.MethodPut:
+		var req struct {
+			Options *struct {
+				CAS *int `json:"cas"`
+			} `json:"options"`
+			Data map[string][]byte `json:"data"`
+		}
+		require.NoError(b.t, json.NewDecoder(r.Body).Decode(&req))
+		_, exists := b.secrets[key]
+		switch {
+		case vault && exists && req.Options != nil && req.Options.CAS != nil && *req.Options.CAS == 0:
+			http.Error(w, `{"errors":["check-and-set parameter did not match the current version"]}`, http.StatusBadRequest)
+			return
+		case !vault && exists && r.Method == http.MethodPost:
+			http.Error(w, "already exists", http.StatusConflict)
+			return
+		}
+		b.secrets[key] = req.Data
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func TestStores(t *testing.T) {
+	tests := map[string]func(t *testing.T, backend *fakeBackend) Store{
+		"vault": func(t *testing.T, backend *fakeBackend) Store {
+			server := httptest.NewServer(backend)
+			t.Cleanup(server.Close)
+			tokenFile := filepath.Join(t.TempDir(), "token")
+			require.NoError(t, os.WriteFile(tokenFile, []byte(backend.token+"\n"), 0600))
+			return NewVaultStore(VaultConfig{Address: server.URL + "/", TokenFile: tokenFile, Mount: "/secret/", Prefix: "kcp"})
+		},
+		"plugin": func(t *testing.T, backend *fakeBackend) Store {
+			socket := filepath.Join(t.TempDir(), "plugin.sock")
+			listener, err := net.Listen("unix", socket)
+			require.NoError(t, err)
+			server := httptest.NewUnstartedServer(backend)
+			server.Listener = listener
+			server.Start()
+			t.Cleanup(server.Close)
+			return NewPluginStore(socket)
+		},
+	}
+	for name, newStore := range tests {
+		t.Run(name, func(t *testing.T) {
+			backend := &fakeBackend{t: t, token: "s3cr3t", secrets: map[string]map[string][]byte{}}
+			store := newStore(t, backend)
+			ctx := context.Background()
+			key := Key("shards", "shard-ca")
+
+			_, err := store.Get(ctx, key)
+			require.True(t, IsNotFound(err), "expected not found, got %v", err)
+
+			data := map[string][]byte{"key": {0, 1, 2, 255}}
+			require.NoError(t, store.Create(ctx, key, data))
+			require.Len(t, backend.secrets, 1)
+
+			got, err := store.Get(ctx, key)
+			require.NoError(t, err)
+			require.Equal(t, data, got)
+
+			err = store.Create(ctx, key, map[string][]byte{"key": {3}})
+			require.True(t, IsAlreadyExists(err), "expected already exists, got %v", err)
+
+			updated := map[string][]byte{"key": {4, 5}}
+			require.NoError(t, store.Put(ctx, key, updated))
+			got, err = store.Get(ctx, key)
+			require.NoError(t, err)
+			require.Equal(t, updated, got)
+		})
+	}
+}
+
+func TestVaultStoreRejectedToken(t *testing.T) {
+	backend := &fakeBackend{t: t, token: "s3cr3t", secrets: map[string]map[string][]byte{}}
+	server := httptest.NewServer(backend)
+	t.Cleanup(server.Close)
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(tokenFile, []byte("wrong"), 0600))
+
+	store := NewVaultStore(VaultConfig{Address: server.URL, TokenFile: tokenFile, Mount: "secret"})
+	_, err := store.Get(context.Background(), "foo")
+	require.Error(t, err)
+	require.False(t, IsNotFound(err))
+	require.Contains(t, err.Error(), "permission denied")
+}
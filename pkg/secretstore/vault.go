@@ -0,0 +1,136 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package secretstore
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// VaultConfig configures a Store backed by the KV version 2 secrets engine of Vault.
+type VaultConfig struct {
+	// Address is the URL of the Vault server, e.g. https://vault.example.com:8200.
+	Address string
+	// TokenFile holds the Vault token. It is read for every request, such that a token
+	// renewed by e.g. the Vault agent is picked up.
+	TokenFile string
+	// Mount is the mount path of the KV secrets engine.
+	Mount string
+	// Prefix is prepended to all keys.
+	Prefix string
+	// Client is the HTTP client to talk to Vault, e.g. trusting the CA of the server.
+	Client *http.Client
+}
+
+type vaultStore struct {
+	config VaultConfig
+}
+
+// NewVaultStore returns a Store keeping the data of secrets in Vault. Values are stored base64 encoded.
+func NewVaultStore(config VaultConfig) Store {
+	if config.Client == nil {
+		config.Client = &http.Client{Timeout: 10 * time.Second}
+	}
+	config.Address = strings.TrimSuffix(config.Address, "/")
+	return &vaultStore{config: config}
+}
+
+// vaultData is the payload of reads and writes of the KV version 2 secrets engine.
+type vaultData struct {
+	Options *vaultOptions     `json:"options,omitempty"`
+	Data    map[string][]byte `json:"data"`
+}
+
+type vaultOptions struct {
+	// CAS is the version the key must have for a write to succeed. 0 means the key must not exist.
+	CAS int `json:"cas"`
+}
+
+func (s *vaultStore) Get(ctx context.Context, key string) (map[string][]byte, error) {
+	var resp struct {
+		Data vaultData `json:"data"`
+	}
+	if err := s.do(ctx, http.MethodGet, key, nil, &resp); err != nil {
+		return nil, err
+	}
+	return resp.Data.Data, nil
+}
+
+func (s *vaultStore) Create(ctx context.Context, key string, data map[string][]byte) error {
+	// check-and-set with version 0 only writes keys without data
+	return s.do(ctx, http.MethodPost, key, vaultData{Options: &vaultOptions{CAS: 0}, Data: data}, nil)
+}
+
+func (s *vaultStore) Put(ctx context.Context, key string, data map[string][]byte) error {
+	return s.do(ctx, http.MethodPost, key, vaultData{Data: data}, nil)
+}
+
+func (s *vaultStore) do(ctx context.Context, method, key string, in, out interface{}) error {
+	token, err := os.ReadFile(s.config.TokenFile)
+	if err != nil {
+		return fmt.Errorf("failed to read Vault token: %w", err)
+	}
+
+	var body io.Reader
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return err
+		}
+		body = bytes.NewReader(data)
+	}
+	fullKey := key
+	if prefix := strings.Trim(s.config.Prefix, "/"); prefix != "" {
+		fullKey = Key(prefix, key)
+	}
+	path := Key("v1", strings.Trim(s.config.Mount, "/"), "data", escapeKey(fullKey))
+	req, err := http.NewRequestWithContext(ctx, method, s.config.Address+"/"+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("X-Vault-Token", strings.TrimSpace(string(token)))
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := s.config.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return fmt.Errorf("%w: %s", ErrNotFound, key)
+	case resp.StatusCode >= 300:
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		if resp.StatusCode == http.StatusBadRequest && bytes.Contains(msg, []byte("check-and-set")) {
+			return fmt.Errorf("%w: %s", ErrAlreadyExists, key)
+		}
+		return fmt.Errorf("vault %s of %q failed with %s: %s", method, key, resp.Status, bytes.TrimSpace(msg))
+	case out == nil || resp.StatusCode == http.StatusNoContent:
+		return nil
+	}
+	return json.NewDecoder(resp.Body).Decode(out)
+}
@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"mime"
 	_ "net/http/pprof"
+	"sort"
 	"strings"
 
 	"github.com/kcp-dev/logicalcluster"
@@ -40,10 +41,12 @@ import (
 	"k8s.io/client-go/tools/clusters"
 	"k8s.io/klog/v2"
 
+	"github.com/kcp-dev/kcp/pkg/apis/apiresource"
 	apisv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apis/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/apis/scheduling"
 	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/apis/workload"
 	kcpclientset "github.com/kcp-dev/kcp/pkg/client/clientset/versioned"
-	apislisters "github.com/kcp-dev/kcp/pkg/client/listers/apis/v1alpha1"
 	tenancylisters "github.com/kcp-dev/kcp/pkg/client/listers/tenancy/v1alpha1"
 	kcpfeatures "github.com/kcp-dev/kcp/pkg/features"
 	"github.com/kcp-dev/kcp/pkg/reconciler/apis/apibinding"
@@ -59,8 +62,13 @@ type systemCRDProvider struct {
 	orgCRDs       sets.String
 	universalCRDs sets.String
 
-	getClusterWorkspace func(key string) (*tenancyv1alpha1.ClusterWorkspace, error)
-	getCRD              func(key string) (*apiextensionsv1.CustomResourceDefinition, error)
+	// excludableSystemCRDGroups are the API groups of system CRDs that a ClusterWorkspaceType can
+	// exclude from its workspaces.
+	excludableSystemCRDGroups sets.String
+
+	getClusterWorkspace     func(key string) (*tenancyv1alpha1.ClusterWorkspace, error)
+	getClusterWorkspaceType func(key string) (*tenancyv1alpha1.ClusterWorkspaceType, error)
+	getCRD                  func(key string) (*apiextensionsv1.CustomResourceDefinition, error)
 }
 
 // NewSystemCRDProvider returns CRDs for certain cluster workspace types and the root workspace.
@@ -69,6 +77,7 @@ type systemCRDProvider struct {
 //              as that would break wildcard informers.
 func newSystemCRDProvider(
 	getClusterWorkspace func(key string) (*tenancyv1alpha1.ClusterWorkspace, error),
+	getClusterWorkspaceType func(key string) (*tenancyv1alpha1.ClusterWorkspaceType, error),
 	getCRD func(key string) (*apiextensionsv1.CustomResourceDefinition, error),
 ) *systemCRDProvider {
 	p := &systemCRDProvider{
@@ -76,6 +85,7 @@ func newSystemCRDProvider(
 			clusters.ToClusterAwareKey(SystemCRDLogicalCluster, "clusterworkspaces.tenancy.kcp.dev"),
 			clusters.ToClusterAwareKey(SystemCRDLogicalCluster, "clusterworkspacetypes.tenancy.kcp.dev"),
 			clusters.ToClusterAwareKey(SystemCRDLogicalCluster, "clusterworkspaceshards.tenancy.kcp.dev"),
+			clusters.ToClusterAwareKey(SystemCRDLogicalCluster, "workspacesnapshots.tenancy.kcp.dev"),
 
 			// the following is installed to get discovery and OpenAPI right. But it is actually
 			// served by a native rest storage, projecting the clusterworkspaces.
@@ -84,6 +94,7 @@ func newSystemCRDProvider(
 		orgCRDs: sets.NewString(
 			clusters.ToClusterAwareKey(SystemCRDLogicalCluster, "clusterworkspaces.tenancy.kcp.dev"),
 			clusters.ToClusterAwareKey(SystemCRDLogicalCluster, "clusterworkspacetypes.tenancy.kcp.dev"),
+			clusters.ToClusterAwareKey(SystemCRDLogicalCluster, "workspacesnapshots.tenancy.kcp.dev"),
 
 			// the following is installed to get discovery and OpenAPI right. But it is actually
 			// served by a native rest storage, projecting the clusterworkspaces.
@@ -96,9 +107,16 @@ func newSystemCRDProvider(
 			clusters.ToClusterAwareKey(SystemCRDLogicalCluster, "apiexports.apis.kcp.dev"),
 			clusters.ToClusterAwareKey(SystemCRDLogicalCluster, "apibindings.apis.kcp.dev"),
 			clusters.ToClusterAwareKey(SystemCRDLogicalCluster, "apiresourceschemas.apis.kcp.dev"),
+			clusters.ToClusterAwareKey(SystemCRDLogicalCluster, "secretshares.apis.kcp.dev"),
+		),
+		excludableSystemCRDGroups: sets.NewString(
+			apiresource.GroupName,
+			workload.GroupName,
+			scheduling.GroupName,
 		),
-		getClusterWorkspace: getClusterWorkspace,
-		getCRD:              getCRD,
+		getClusterWorkspace:     getClusterWorkspace,
+		getClusterWorkspaceType: getClusterWorkspaceType,
+		getCRD:                  getCRD,
 	}
 
 	if utilfeature.DefaultFeatureGate.Enabled(kcpfeatures.LocationAPI) {
@@ -114,6 +132,12 @@ func newSystemCRDProvider(
 		p.universalCRDs.Insert(clusters.ToClusterAwareKey(SystemCRDLogicalCluster, "locations.scheduling.kcp.dev"))
 	}
 
+	if utilfeature.DefaultFeatureGate.Enabled(kcpfeatures.WorkspaceSource) {
+		p.rootCRDs.Insert(clusters.ToClusterAwareKey(SystemCRDLogicalCluster, "workspacesources.tenancy.kcp.dev"))
+		p.orgCRDs.Insert(clusters.ToClusterAwareKey(SystemCRDLogicalCluster, "workspacesources.tenancy.kcp.dev"))
+		p.universalCRDs.Insert(clusters.ToClusterAwareKey(SystemCRDLogicalCluster, "workspacesources.tenancy.kcp.dev"))
+	}
+
 	return p
 }
 
@@ -162,26 +186,57 @@ func (p *systemCRDProvider) Keys(clusterName logicalcluster.Name) sets.String {
 
 		switch clusterWorkspace.Spec.Type {
 		case "Universal":
-			return p.universalCRDs
+			return p.withoutExcludedSystemCRDGroups(parent, clusterWorkspace.Spec.Type, p.universalCRDs)
 		case "Organization", "Team":
 			// TODO(sttts): this cannot be hardcoded. There might be other org-like types
-			return p.orgCRDs
+			return p.withoutExcludedSystemCRDGroups(parent, clusterWorkspace.Spec.Type, p.orgCRDs)
 		}
 	}
 
 	return sets.NewString()
 }
 
+// withoutExcludedSystemCRDGroups returns the given system CRD keys without those of the API groups
+// excluded by the ClusterWorkspaceType of the given name in the given cluster. The ClusterWorkspaceType is
+// optional, e.g. for "Universal", in which case all keys are returned.
+func (p *systemCRDProvider) withoutExcludedSystemCRDGroups(clusterName logicalcluster.Name, typeName string, keys sets.String) sets.String {
+	typeKey := clusterWorkspaceTypeKey(clusterName, typeName)
+	cwt, err := p.getClusterWorkspaceType(typeKey)
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			klog.ErrorS(err, "Unable to determine excluded system CRD groups: error getting clusterworkspacetype", "typeKey", typeKey)
+		}
+		return keys
+	}
+
+	excluded := sets.NewString(cwt.Spec.ExcludedSystemCRDGroups...).Intersection(p.excludableSystemCRDGroups)
+	if excluded.Len() == 0 {
+		return keys
+	}
+
+	ret := sets.NewString()
+	for _, key := range keys.UnsortedList() {
+		_, name := clusters.SplitClusterAwareKey(key)
+		group, _ := crdNameToGroupResource(name)
+		if !excluded.Has(group) {
+			ret.Insert(key)
+		}
+	}
+	return ret
+}
+
 // apiBindingAwareCRDLister is a CRD lister combines APIs coming from APIBindings with CRDs in a workspace.
 type apiBindingAwareCRDLister struct {
 	kcpClusterClient     kcpclientset.ClusterInterface
 	crdLister            apiextensionslisters.CustomResourceDefinitionLister
 	workspaceLister      tenancylisters.ClusterWorkspaceLister
-	apiBindingLister     apislisters.APIBindingLister
 	apiBindingIndexer    cache.Indexer
 	apiExportIndexer     cache.Indexer
 	systemCRDProvider    *systemCRDProvider
 	getAPIResourceSchema func(clusterName logicalcluster.Name, name string) (*apisv1alpha1.APIResourceSchema, error)
+
+	// listCache caches the result of List without label selector, as asked for by discovery. Optional.
+	listCache *crdListCache
 }
 
 var _ kcp.ClusterAwareCRDLister = &apiBindingAwareCRDLister{}
@@ -194,6 +249,23 @@ func (c *apiBindingAwareCRDLister) List(ctx context.Context, selector labels.Sel
 		return nil, err
 	}
 
+	if c.listCache == nil || !selector.Empty() {
+		return c.list(clusterName, selector)
+	}
+
+	crds, generation, found := c.listCache.get(clusterName)
+	if found {
+		return crds, nil
+	}
+	crds, err = c.list(clusterName, selector)
+	if err != nil {
+		return nil, err
+	}
+	c.listCache.set(clusterName, crds, generation)
+	return crds, nil
+}
+
+func (c *apiBindingAwareCRDLister) list(clusterName logicalcluster.Name, selector labels.Selector) ([]*apiextensionsv1.CustomResourceDefinition, error) {
 	crdName := func(crd *apiextensionsv1.CustomResourceDefinition) string {
 		return crd.Spec.Names.Plural + "." + crd.Spec.Group
 	}
@@ -221,16 +293,11 @@ func (c *apiBindingAwareCRDLister) List(ctx context.Context, selector labels.Sel
 		seen.Insert(crdName(kcpSystemCRDs[i]))
 	}
 
-	apiBindings, err := c.apiBindingLister.List(labels.Everything())
+	apiBindings, err := c.apiBindingsInCluster(indexAPIBindingsByLogicalCluster, clusterName.String())
 	if err != nil {
 		return nil, err
 	}
-
-	// TODO(sttts): optimize this looping by using an informer index
 	for _, apiBinding := range apiBindings {
-		if logicalcluster.From(apiBinding) != clusterName {
-			continue
-		}
 		if !conditions.IsTrue(apiBinding, apisv1alpha1.InitialBindingCompleted) {
 			continue
 		}
@@ -247,10 +314,9 @@ func (c *apiBindingAwareCRDLister) List(ctx context.Context, selector labels.Sel
 				continue
 			}
 
-			// system CRDs take priority over APIBindings from the local workspace.
+			// system CRDs and APIBindings taking precedence win over other APIBindings from the local workspace.
 			if seen.Has(crdName(crd)) {
-				// Came from system
-				klog.Infof("Skipping APIBinding CRD %s|%s because it came in via system CRDs", crd.ClusterName, crd.Name)
+				klog.Infof("Skipping APIBinding CRD %s|%s of APIBinding %s|%s because it came in via system CRDs or an APIBinding taking precedence", crd.ClusterName, crd.Name, clusterName, apiBinding.Name)
 				continue
 			}
 
@@ -290,14 +356,46 @@ func (c *apiBindingAwareCRDLister) List(ctx context.Context, selector labels.Sel
 	return ret, nil
 }
 
+// apiBindingsInCluster returns the APIBindings of a logical cluster found by the given index and key, with the
+// APIBindings taking precedence first, such that they win naming conflicts deterministically.
+func (c *apiBindingAwareCRDLister) apiBindingsInCluster(indexName, key string) ([]*apisv1alpha1.APIBinding, error) {
+	objs, err := c.apiBindingIndexer.ByIndex(indexName, key)
+	if err != nil {
+		return nil, err
+	}
+
+	apiBindings := make([]*apisv1alpha1.APIBinding, 0, len(objs))
+	for _, obj := range objs {
+		apiBindings = append(apiBindings, obj.(*apisv1alpha1.APIBinding))
+	}
+	sort.Slice(apiBindings, func(i, j int) bool {
+		return apibinding.APIBindingPrecedes(apiBindings[i], apiBindings[j])
+	})
+
+	return apiBindings, nil
+}
+
+// isPartialMetadataRequest returns true if the Accept header of the request asks for
+// PartialObjectMetadata or PartialObjectMetadataList. Clients like client-go's metadata
+// client send a list of media types, e.g. protobuf first and json as fallback. All of
+// them must ask for partial metadata, otherwise the server might pick a full-data one.
 func isPartialMetadataRequest(ctx context.Context) bool {
-	if accept := ctx.Value(acceptHeaderContextKey).(string); len(accept) > 0 {
-		if _, params, err := mime.ParseMediaType(accept); err == nil {
-			return params["as"] == "PartialObjectMetadata" || params["as"] == "PartialObjectMetadataList"
+	accept, _ := ctx.Value(acceptHeaderContextKey).(string)
+	if len(accept) == 0 {
+		return false
+	}
+
+	for _, mediaType := range strings.Split(accept, ",") {
+		_, params, err := mime.ParseMediaType(strings.TrimSpace(mediaType))
+		if err != nil {
+			return false
+		}
+		if params["as"] != "PartialObjectMetadata" && params["as"] != "PartialObjectMetadataList" {
+			return false
 		}
 	}
 
-	return false
+	return true
 }
 
 func (c *apiBindingAwareCRDLister) Refresh(crd *apiextensionsv1.CustomResourceDefinition) (*apiextensionsv1.CustomResourceDefinition, error) {
@@ -383,18 +481,22 @@ func shallowCopyCRD(in *apiextensionsv1.CustomResourceDefinition) *apiextensions
 }
 
 // partialMetadataCRD modifies CRD and replaces all version schemas with minimal ones suitable for partial object
-// metadata.
+// metadata. The versions slice is copied such that a shallow copy of a CRD from the informer cache can be passed in
+// without mutating the cache.
 func partialMetadataCRD(crd *apiextensionsv1.CustomResourceDefinition) {
 	crd.Annotations[annotationKeyPartialMetadata] = ""
 
 	// set minimal schema that prunes everything but ObjectMeta
-	for _, v := range crd.Spec.Versions {
+	versions := make([]apiextensionsv1.CustomResourceDefinitionVersion, len(crd.Spec.Versions))
+	for i, v := range crd.Spec.Versions {
 		v.Schema = &apiextensionsv1.CustomResourceValidation{
 			OpenAPIV3Schema: &apiextensionsv1.JSONSchemaProps{
 				Type: "object",
 			},
 		}
+		versions[i] = v
 	}
+	crd.Spec.Versions = versions
 }
 
 func (c *apiBindingAwareCRDLister) getForFullData(clusterName logicalcluster.Name, name string) (*apiextensionsv1.CustomResourceDefinition, error) {
@@ -514,15 +616,11 @@ func (c *apiBindingAwareCRDLister) get(clusterName logicalcluster.Name, name str
 	// Priority 1: see if it comes from any APIBindings
 	group, resource := crdNameToGroupResource(name)
 
-	// TODO use scoped lister when ready
-	apiBindings, err := c.apiBindingLister.List(labels.Everything())
+	apiBindings, err := c.apiBindingsInCluster(indexAPIBindingsByClusterGroupResource, clusterGroupResourceKey(clusterName, group, resource))
 	if err != nil {
 		return nil, err
 	}
 	for _, apiBinding := range apiBindings {
-		if logicalcluster.From(apiBinding) != clusterName {
-			continue
-		}
 		if !conditions.IsTrue(apiBinding, apisv1alpha1.InitialBindingCompleted) {
 			continue
 		}
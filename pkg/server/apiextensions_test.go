@@ -17,13 +17,148 @@ limitations under the License.
 package server
 
 import (
+	"context"
 	"testing"
 
+	"github.com/kcp-dev/logicalcluster"
 	"github.com/stretchr/testify/require"
 
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/clusters"
+
 	"github.com/kcp-dev/kcp/pkg/admission/reservedcrdgroups"
+	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
 )
 
 func TestSystemCRDsLogicalClusterName(t *testing.T) {
 	require.Equal(t, SystemCRDLogicalCluster.String(), reservedcrdgroups.SystemCRDLogicalClusterName, "reservedcrdgroups admission check should match SystemCRDLogicalCluster")
 }
+
+func TestSystemCRDProviderExcludedSystemCRDGroups(t *testing.T) {
+	workspaces := map[string]*tenancyv1alpha1.ClusterWorkspace{
+		clusters.ToClusterAwareKey(logicalcluster.New("root:org"), "plain"):   {Spec: tenancyv1alpha1.ClusterWorkspaceSpec{Type: "Universal"}},
+		clusters.ToClusterAwareKey(logicalcluster.New("root:lean"), "plain"):  {Spec: tenancyv1alpha1.ClusterWorkspaceSpec{Type: "Universal"}},
+		clusters.ToClusterAwareKey(logicalcluster.New("root:lean"), "nested"): {Spec: tenancyv1alpha1.ClusterWorkspaceSpec{Type: "Team"}},
+	}
+	types := map[string]*tenancyv1alpha1.ClusterWorkspaceType{
+		clusters.ToClusterAwareKey(logicalcluster.New("root:lean"), "universal"): {Spec: tenancyv1alpha1.ClusterWorkspaceTypeSpec{
+			ExcludedSystemCRDGroups: []string{"workload.kcp.dev", "apiresource.kcp.dev", "apis.kcp.dev"},
+		}},
+		clusters.ToClusterAwareKey(logicalcluster.New("root:lean"), "team"): {Spec: tenancyv1alpha1.ClusterWorkspaceTypeSpec{
+			ExcludedSystemCRDGroups: []string{"tenancy.kcp.dev"},
+		}},
+	}
+	p := newSystemCRDProvider(
+		func(key string) (*tenancyv1alpha1.ClusterWorkspace, error) {
+			if cw, ok := workspaces[key]; ok {
+				return cw, nil
+			}
+			return nil, apierrors.NewNotFound(tenancyv1alpha1.Resource("clusterworkspaces"), key)
+		},
+		func(key string) (*tenancyv1alpha1.ClusterWorkspaceType, error) {
+			if cwt, ok := types[key]; ok {
+				return cwt, nil
+			}
+			return nil, apierrors.NewNotFound(tenancyv1alpha1.Resource("clusterworkspacetypes"), key)
+		},
+		nil,
+	)
+
+	names := func(clusterName string) []string {
+		var ret []string
+		for _, key := range p.Keys(logicalcluster.New(clusterName)).List() {
+			_, name := clusters.SplitClusterAwareKey(key)
+			ret = append(ret, name)
+		}
+		return ret
+	}
+
+	require.Contains(t, names("root:org:plain"), "workloadclusters.workload.kcp.dev")
+	require.Equal(t, []string{
+		"apibindings.apis.kcp.dev",
+		"apiexports.apis.kcp.dev",
+		"apiresourceschemas.apis.kcp.dev",
+		"secretshares.apis.kcp.dev",
+	}, filterGroups(names("root:lean:plain"), "apis.kcp.dev", "apiresource.kcp.dev", "workload.kcp.dev"), "workload and apiresource should be excluded, apis not")
+	require.Contains(t, names("root:lean:nested"), "clusterworkspaces.tenancy.kcp.dev", "tenancy cannot be excluded")
+	require.Contains(t, names("root:org:plain"), "workloadclusters.workload.kcp.dev", "the shared universal set must not be mutated")
+}
+
+func filterGroups(names []string, groups ...string) []string {
+	var ret []string
+	for _, name := range names {
+		group, _ := crdNameToGroupResource(name)
+		for _, g := range groups {
+			if group == g {
+				ret = append(ret, name)
+			}
+		}
+	}
+	return ret
+}
+
+func TestIsPartialMetadataRequest(t *testing.T) {
+	tests := []struct {
+		name   string
+		accept interface{}
+		want   bool
+	}{
+		{"no accept header in context", nil, false},
+		{"empty accept header", "", false},
+		{"json", "application/json", false},
+		{"partial object metadata", "application/json;as=PartialObjectMetadata;g=meta.k8s.io;v=v1", true},
+		{"partial object metadata list", "application/json;as=PartialObjectMetadataList;g=meta.k8s.io;v=v1", true},
+		{"table", "application/json;as=Table;g=meta.k8s.io;v=v1", false},
+		{"metadata client protobuf with json fallback", "application/vnd.kubernetes.protobuf;as=PartialObjectMetadataList;g=meta.k8s.io;v=v1, application/json;as=PartialObjectMetadataList;g=meta.k8s.io;v=v1", true},
+		{"partial metadata with full json fallback", "application/json;as=PartialObjectMetadataList;g=meta.k8s.io;v=v1, application/json", false},
+		{"invalid media type", "application/json;;;", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.accept != nil {
+				ctx = context.WithValue(ctx, acceptHeaderContextKey, tt.accept)
+			}
+			require.Equal(t, tt.want, isPartialMetadataRequest(ctx))
+		})
+	}
+}
+
+func TestPartialMetadataCRD(t *testing.T) {
+	schema := &apiextensionsv1.CustomResourceValidation{
+		OpenAPIV3Schema: &apiextensionsv1.JSONSchemaProps{
+			Type: "object",
+			Properties: map[string]apiextensionsv1.JSONSchemaProps{
+				"spec": {Type: "object"},
+			},
+		},
+	}
+	original := &apiextensionsv1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "sheriffs.wild.wild.west",
+			Annotations: map[string]string{"foo": "bar"},
+		},
+		Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+			Versions: []apiextensionsv1.CustomResourceDefinitionVersion{
+				{Name: "v1", Schema: schema},
+				{Name: "v2", Schema: schema},
+			},
+		},
+	}
+
+	crd := shallowCopyCRD(original)
+	partialMetadataCRD(crd)
+
+	require.Contains(t, crd.Annotations, annotationKeyPartialMetadata)
+	require.Len(t, crd.Spec.Versions, 2)
+	for _, v := range crd.Spec.Versions {
+		require.Equal(t, &apiextensionsv1.JSONSchemaProps{Type: "object"}, v.Schema.OpenAPIV3Schema, "version %s should have a minimal schema", v.Name)
+	}
+
+	require.NotContains(t, original.Annotations, annotationKeyPartialMetadata, "original CRD must not be mutated")
+	for _, v := range original.Spec.Versions {
+		require.Same(t, schema, v.Schema, "original CRD version %s must not be mutated", v.Name)
+	}
+}
@@ -0,0 +1,148 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apiserver/pkg/authorization/authorizer"
+	genericapirequest "k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/kubernetes/pkg/printers"
+	printerstorage "k8s.io/kubernetes/pkg/printers/storage"
+
+	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+)
+
+// clusterWorkspaceTableConverter prints ClusterWorkspaces with their remaining initializers in the
+// wide output, and with their shard for users allowed to get WorkspaceShards in the root workspace.
+type clusterWorkspaceTableConverter struct {
+	authorizer   authorizer.Authorizer
+	withShard    printerstorage.TableConvertor
+	withoutShard printerstorage.TableConvertor
+}
+
+func newClusterWorkspaceTableConverter(authz authorizer.Authorizer) *clusterWorkspaceTableConverter {
+	return &clusterWorkspaceTableConverter{
+		authorizer: authz,
+		withShard: printerstorage.TableConvertor{
+			TableGenerator: printers.NewTableGenerator().With(addClusterWorkspacePrintHandlers(true)),
+		},
+		withoutShard: printerstorage.TableConvertor{
+			TableGenerator: printers.NewTableGenerator().With(addClusterWorkspacePrintHandlers(false)),
+		},
+	}
+}
+
+func (c *clusterWorkspaceTableConverter) ConvertToTable(ctx context.Context, object runtime.Object, tableOptions runtime.Object) (*metav1.Table, error) {
+	var typed runtime.Object
+	switch t := object.(type) {
+	case *unstructured.Unstructured:
+		ws := &tenancyv1alpha1.ClusterWorkspace{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(t.Object, ws); err != nil {
+			return nil, fmt.Errorf("failed to convert ClusterWorkspace: %w", err)
+		}
+		typed = ws
+	case *unstructured.UnstructuredList:
+		list := &tenancyv1alpha1.ClusterWorkspaceList{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(t.UnstructuredContent(), list); err != nil {
+			return nil, fmt.Errorf("failed to convert ClusterWorkspaceList: %w", err)
+		}
+		typed = list
+	default:
+		typed = object
+	}
+
+	if c.canGetShards(ctx) {
+		return c.withShard.ConvertToTable(ctx, typed, tableOptions)
+	}
+	return c.withoutShard.ConvertToTable(ctx, typed, tableOptions)
+}
+
+func (c *clusterWorkspaceTableConverter) canGetShards(ctx context.Context) bool {
+	user, ok := genericapirequest.UserFrom(ctx)
+	if !ok || c.authorizer == nil {
+		return false
+	}
+	attr := authorizer.AttributesRecord{
+		User:            user,
+		Verb:            "get",
+		APIGroup:        tenancyv1alpha1.SchemeGroupVersion.Group,
+		APIVersion:      tenancyv1alpha1.SchemeGroupVersion.Version,
+		Resource:        "clusterworkspaceshards",
+		ResourceRequest: true,
+	}
+	rootCtx := genericapirequest.WithCluster(ctx, genericapirequest.Cluster{Name: tenancyv1alpha1.RootCluster})
+	dec, _, err := c.authorizer.Authorize(rootCtx, attr)
+	return err == nil && dec == authorizer.DecisionAllow
+}
+
+func addClusterWorkspacePrintHandlers(withShard bool) func(h printers.PrintHandler) {
+	return func(h printers.PrintHandler) {
+		columnDefinitions := []metav1.TableColumnDefinition{
+			{Name: "Name", Type: "string", Format: "name", Description: metav1.ObjectMeta{}.SwaggerDoc()["name"]},
+			{Name: "Type", Type: "string", Description: "Type of the workspace"},
+			{Name: "Phase", Type: "string", Description: "The current phase (e.g. Scheduling, Initializing, Ready)"},
+			{Name: "URL", Type: "string", Description: "URL to access the workspace"},
+			{Name: "Initializers", Type: "string", Description: "The initializers remaining before the workspace is ready", Priority: 1},
+		}
+		if withShard {
+			columnDefinitions = append(columnDefinitions, metav1.TableColumnDefinition{Name: "Shard", Type: "string", Description: "The shard the workspace is scheduled to", Priority: 1})
+		}
+
+		printClusterWorkspace := func(ws *tenancyv1alpha1.ClusterWorkspace, options printers.GenerateOptions) ([]metav1.TableRow, error) {
+			row := metav1.TableRow{
+				Object: runtime.RawExtension{Object: ws},
+			}
+			initializers := "<none>"
+			if len(ws.Status.Initializers) > 0 {
+				names := make([]string, 0, len(ws.Status.Initializers))
+				for _, initializer := range ws.Status.Initializers {
+					names = append(names, string(initializer))
+				}
+				initializers = strings.Join(names, ",")
+			}
+			row.Cells = append(row.Cells, ws.Name, ws.Spec.Type, string(ws.Status.Phase), ws.Status.BaseURL, initializers)
+			if withShard {
+				row.Cells = append(row.Cells, ws.Status.Location.Current)
+			}
+			return []metav1.TableRow{row}, nil
+		}
+		printClusterWorkspaceList := func(list *tenancyv1alpha1.ClusterWorkspaceList, options printers.GenerateOptions) ([]metav1.TableRow, error) {
+			rows := make([]metav1.TableRow, 0, len(list.Items))
+			for i := range list.Items {
+				r, err := printClusterWorkspace(&list.Items[i], options)
+				if err != nil {
+					return nil, err
+				}
+				rows = append(rows, r...)
+			}
+			return rows, nil
+		}
+
+		if err := h.TableHandler(columnDefinitions, printClusterWorkspaceList); err != nil {
+			panic(err)
+		}
+		if err := h.TableHandler(columnDefinitions, printClusterWorkspace); err != nil {
+			panic(err)
+		}
+	}
+}
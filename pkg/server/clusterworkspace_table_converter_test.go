@@ -0,0 +1,82 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/apiserver/pkg/authorization/authorizer"
+	genericapirequest "k8s.io/apiserver/pkg/endpoints/request"
+
+	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+)
+
+func TestClusterWorkspaceTableConverter(t *testing.T) {
+	ws := &tenancyv1alpha1.ClusterWorkspace{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "tenancy.kcp.dev/v1alpha1", Kind: "ClusterWorkspace"},
+		ObjectMeta: metav1.ObjectMeta{Name: "team"},
+		Spec:       tenancyv1alpha1.ClusterWorkspaceSpec{Type: "Universal"},
+		Status: tenancyv1alpha1.ClusterWorkspaceStatus{
+			Phase:        tenancyv1alpha1.ClusterWorkspacePhaseInitializing,
+			BaseURL:      "https://kcp.example.com/clusters/root:org:team",
+			Location:     tenancyv1alpha1.ClusterWorkspaceLocation{Current: "shard-1"},
+			Initializers: []tenancyv1alpha1.ClusterWorkspaceInitializer{"example.com/rbac", "example.com/bindings"},
+		},
+	}
+	raw, err := runtime.DefaultUnstructuredConverter.ToUnstructured(ws)
+	require.NoError(t, err)
+	list := &unstructured.UnstructuredList{Object: map[string]interface{}{"apiVersion": "tenancy.kcp.dev/v1alpha1", "kind": "ClusterWorkspaceList"}}
+	list.Items = []unstructured.Unstructured{{Object: raw}}
+
+	tests := map[string]struct {
+		decision  authorizer.Decision
+		wantCells []interface{}
+	}{
+		"shard admin": {
+			decision:  authorizer.DecisionAllow,
+			wantCells: []interface{}{"team", "Universal", "Initializing", "https://kcp.example.com/clusters/root:org:team", "example.com/rbac,example.com/bindings", "shard-1"},
+		},
+		"other user": {
+			decision:  authorizer.DecisionNoOpinion,
+			wantCells: []interface{}{"team", "Universal", "Initializing", "https://kcp.example.com/clusters/root:org:team", "example.com/rbac,example.com/bindings"},
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			authz := authorizer.AuthorizerFunc(func(ctx context.Context, attr authorizer.Attributes) (authorizer.Decision, string, error) {
+				require.Equal(t, tenancyv1alpha1.RootCluster, genericapirequest.ClusterFrom(ctx).Name)
+				require.Equal(t, "clusterworkspaceshards", attr.GetResource())
+				return tc.decision, "", nil
+			})
+			converter := newClusterWorkspaceTableConverter(authz)
+
+			ctx := genericapirequest.WithUser(context.Background(), &user.DefaultInfo{Name: "user"})
+			table, err := converter.ConvertToTable(ctx, list, &metav1.TableOptions{})
+			require.NoError(t, err)
+			require.Len(t, table.ColumnDefinitions, len(tc.wantCells))
+			require.Len(t, table.Rows, 1)
+			require.Equal(t, tc.wantCells, table.Rows[0].Cells)
+		})
+	}
+}
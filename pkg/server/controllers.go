@@ -23,6 +23,7 @@ import (
 	"io/ioutil"
 	_ "net/http/pprof"
 	"os"
+	"path/filepath"
 	"time"
 
 	"github.com/kcp-dev/logicalcluster"
@@ -45,24 +46,40 @@ import (
 	serviceaccountcontroller "k8s.io/kubernetes/pkg/controller/serviceaccount"
 	"k8s.io/kubernetes/pkg/serviceaccount"
 
+	confighelpers "github.com/kcp-dev/kcp/config/helpers"
 	configorganization "github.com/kcp-dev/kcp/config/organization"
+	configrootcompute "github.com/kcp-dev/kcp/config/rootcompute"
 	configteam "github.com/kcp-dev/kcp/config/team"
 	configuniversal "github.com/kcp-dev/kcp/config/universal"
 	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
 	kcpclient "github.com/kcp-dev/kcp/pkg/client/clientset/versioned"
+	"github.com/kcp-dev/kcp/pkg/informer"
 	metadataclient "github.com/kcp-dev/kcp/pkg/metadata"
+	"github.com/kcp-dev/kcp/pkg/reconciler/apiextensions/storageversionmigration"
 	"github.com/kcp-dev/kcp/pkg/reconciler/apis/apibinding"
+	"github.com/kcp-dev/kcp/pkg/reconciler/apis/apibindingdeprecation"
+	"github.com/kcp-dev/kcp/pkg/reconciler/apis/apibindingroles"
 	"github.com/kcp-dev/kcp/pkg/reconciler/apis/apiexport"
+	"github.com/kcp-dev/kcp/pkg/reconciler/apis/apiexportusage"
 	"github.com/kcp-dev/kcp/pkg/reconciler/apis/apiresource"
 	schedulinglocationstatus "github.com/kcp-dev/kcp/pkg/reconciler/scheduling/location"
 	schedulingplacement "github.com/kcp-dev/kcp/pkg/reconciler/scheduling/placement"
+	schedulingtopology "github.com/kcp-dev/kcp/pkg/reconciler/scheduling/topology"
 	"github.com/kcp-dev/kcp/pkg/reconciler/tenancy/bootstrap"
 	"github.com/kcp-dev/kcp/pkg/reconciler/tenancy/clusterworkspace"
 	"github.com/kcp-dev/kcp/pkg/reconciler/tenancy/clusterworkspacedeletion"
 	"github.com/kcp-dev/kcp/pkg/reconciler/tenancy/clusterworkspaceshard"
+	"github.com/kcp-dev/kcp/pkg/reconciler/tenancy/resourcequota"
+	"github.com/kcp-dev/kcp/pkg/reconciler/tenancy/storagewatchdog"
+	"github.com/kcp-dev/kcp/pkg/reconciler/tenancy/workspacelabels"
+	"github.com/kcp-dev/kcp/pkg/reconciler/tenancy/workspacesnapshot"
+	"github.com/kcp-dev/kcp/pkg/reconciler/tenancy/workspacesource"
+	"github.com/kcp-dev/kcp/pkg/reconciler/tenancy/workspacettl"
+	"github.com/kcp-dev/kcp/pkg/reconciler/tenancy/workspaceurl"
 	workloadsapiexport "github.com/kcp-dev/kcp/pkg/reconciler/workload/apiexport"
 	"github.com/kcp-dev/kcp/pkg/reconciler/workload/heartbeat"
 	kcpnamespace "github.com/kcp-dev/kcp/pkg/reconciler/workload/namespace"
+	"github.com/kcp-dev/kcp/pkg/reconciler/workload/virtualworkspaceurls"
 )
 
 func (s *Server) installClusterRoleAggregationController(ctx context.Context, config *rest.Config) error {
@@ -231,6 +248,9 @@ func (s *Server) installRootCAConfigMapController(ctx context.Context, config *r
 
 	// TODO(jmprusi): We should make the CA loading dynamic when the file changes on disk.
 	caDataPath := s.options.Controllers.SAController.RootCAFile
+	if caDataPath == "" && s.servesShardIdentityCert() {
+		caDataPath = s.options.ShardIdentity.CACertFile
+	}
 	if caDataPath == "" {
 		caDataPath = s.options.GenericControlPlane.SecureServing.SecureServingOptions.ServerCert.CertKey.CertFile
 	}
@@ -316,7 +336,276 @@ func (s *Server) installWorkspaceDeletionController(ctx context.Context, config
 	return nil
 }
 
+func (s *Server) installStorageWatchdogController(ctx context.Context, config *rest.Config, server *genericapiserver.GenericAPIServer) error {
+	controllerName := "kcp-workspace-storage-watchdog-controller"
+	config = rest.AddUserAgent(rest.CopyConfig(config), controllerName)
+	kcpClusterClient, err := kcpclient.NewClusterForConfig(config)
+	if err != nil {
+		return err
+	}
+	dynamicClusterClient, err := dynamic.NewClusterForConfig(config)
+	if err != nil {
+		return err
+	}
+	discoverResourcesFn := func(clusterName logicalcluster.Name) ([]*metav1.APIResourceList, error) {
+		logicalClusterConfig := rest.CopyConfig(config)
+		logicalClusterConfig.Host += clusterName.Path()
+		discoveryClient, err := discovery.NewDiscoveryClientForConfig(logicalClusterConfig)
+		if err != nil {
+			return nil, err
+		}
+		return discoveryClient.ServerPreferredResources()
+	}
+
+	c := storagewatchdog.NewController(
+		kcpClusterClient,
+		dynamicClusterClient,
+		discoverResourcesFn,
+		s.kcpSharedInformerFactory.Tenancy().V1alpha1().ClusterWorkspaces(),
+		s.options.Controllers.StorageWatchdog,
+	)
+
+	if err := server.AddPostStartHook(controllerName, func(hookContext genericapiserver.PostStartHookContext) error {
+		if err := s.waitForSync(hookContext.StopCh); err != nil {
+			klog.Errorf("failed to finish post-start-hook %s: %v", controllerName, err)
+			// nolint:nilerr
+			return nil // don't klog.Fatal. This only happens when context is cancelled.
+		}
+
+		go c.Start(goContext(hookContext), 1)
+
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *Server) installWorkspaceTTLController(ctx context.Context, config *rest.Config, server *genericapiserver.GenericAPIServer) error {
+	controllerName := "kcp-workspace-ttl-controller"
+	config = rest.AddUserAgent(rest.CopyConfig(config), controllerName)
+	kcpClusterClient, err := kcpclient.NewClusterForConfig(config)
+	if err != nil {
+		return err
+	}
+
+	c := workspacettl.NewController(
+		kcpClusterClient,
+		s.kcpSharedInformerFactory.Tenancy().V1alpha1().ClusterWorkspaces(),
+		s.options.Controllers.WorkspaceTTL,
+	)
+
+	if err := server.AddPostStartHook(controllerName, func(hookContext genericapiserver.PostStartHookContext) error {
+		if err := s.waitForSync(hookContext.StopCh); err != nil {
+			klog.Errorf("failed to finish post-start-hook %s: %v", controllerName, err)
+			// nolint:nilerr
+			return nil // don't klog.Fatal. This only happens when context is cancelled.
+		}
+
+		go c.Start(goContext(hookContext), 2)
+
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *Server) installWorkspaceSnapshotControllers(ctx context.Context, config *rest.Config) error {
+	config = rest.AddUserAgent(rest.CopyConfig(config), "kcp-workspace-snapshot-controller")
+	kcpClusterClient, err := kcpclient.NewClusterForConfig(config)
+	if err != nil {
+		return err
+	}
+	kubeClusterClient, err := kubernetes.NewClusterForConfig(config)
+	if err != nil {
+		return err
+	}
+	dynamicClusterClient, err := dynamic.NewClusterForConfig(config)
+	if err != nil {
+		return err
+	}
+	discoverResourcesFn := func(clusterName logicalcluster.Name) ([]*metav1.APIResourceList, error) {
+		logicalClusterConfig := rest.CopyConfig(config)
+		logicalClusterConfig.Host += clusterName.Path()
+		discoveryClient, err := discovery.NewDiscoveryClientForConfig(logicalClusterConfig)
+		if err != nil {
+			return nil, err
+		}
+		return discoveryClient.ServerPreferredResources()
+	}
+
+	snapshotController, err := workspacesnapshot.NewController(
+		kcpClusterClient,
+		kubeClusterClient,
+		dynamicClusterClient,
+		discoverResourcesFn,
+		s.kcpSharedInformerFactory.Tenancy().V1alpha1().WorkspaceSnapshots(),
+		s.kcpSharedInformerFactory.Tenancy().V1alpha1().ClusterWorkspaces(),
+	)
+	if err != nil {
+		return err
+	}
+	restoreController := workspacesnapshot.NewRestoreController(
+		kcpClusterClient,
+		kubeClusterClient,
+		dynamicClusterClient,
+		s.kcpSharedInformerFactory.Tenancy().V1alpha1().ClusterWorkspaces(),
+		s.kcpSharedInformerFactory.Tenancy().V1alpha1().WorkspaceSnapshots(),
+	)
+
+	s.AddPostStartHook("kcp-workspace-snapshot-controller", func(hookContext genericapiserver.PostStartHookContext) error {
+		if err := s.waitForSync(hookContext.StopCh); err != nil {
+			klog.Errorf("failed to finish post-start-hook kcp-workspace-snapshot-controller: %v", err)
+			// nolint:nilerr
+			return nil // don't klog.Fatal. This only happens when context is cancelled.
+		}
+
+		go snapshotController.Start(ctx, 2)
+		go restoreController.Start(ctx, 2)
+		return nil
+	})
+	return nil
+}
+
+func (s *Server) installWorkspaceLabelsController(ctx context.Context, config *rest.Config) error {
+	config = rest.AddUserAgent(rest.CopyConfig(config), "kcp-workspace-labels-controller")
+	kcpClusterClient, err := kcpclient.NewClusterForConfig(config)
+	if err != nil {
+		return err
+	}
+
+	c, err := workspacelabels.NewController(
+		kcpClusterClient,
+		s.kcpSharedInformerFactory.Tenancy().V1alpha1().ClusterWorkspaceTypes(),
+		s.kcpSharedInformerFactory.Tenancy().V1alpha1().ClusterWorkspaces(),
+	)
+	if err != nil {
+		return err
+	}
+
+	s.AddPostStartHook("kcp-workspace-labels-controller", func(hookContext genericapiserver.PostStartHookContext) error {
+		if err := s.waitForSync(hookContext.StopCh); err != nil {
+			klog.Errorf("failed to finish post-start-hook kcp-workspace-labels-controller: %v", err)
+			// nolint:nilerr
+			return nil // don't klog.Fatal. This only happens when context is cancelled.
+		}
+
+		go c.Start(ctx, 2)
+		return nil
+	})
+	return nil
+}
+
+func (s *Server) installWorkspaceURLController(ctx context.Context, config *rest.Config) error {
+	config = rest.AddUserAgent(rest.CopyConfig(config), "kcp-workspace-url-controller")
+	kcpClusterClient, err := kcpclient.NewClusterForConfig(config)
+	if err != nil {
+		return err
+	}
+
+	c, err := workspaceurl.NewController(
+		kcpClusterClient,
+		s.rootKcpSharedInformerFactory.Tenancy().V1alpha1().ClusterWorkspaceShards(),
+		s.kcpSharedInformerFactory.Tenancy().V1alpha1().ClusterWorkspaces(),
+	)
+	if err != nil {
+		return err
+	}
+
+	s.AddPostStartHook("kcp-workspace-url-controller", func(hookContext genericapiserver.PostStartHookContext) error {
+		if err := s.waitForSync(hookContext.StopCh); err != nil {
+			klog.Errorf("failed to finish post-start-hook kcp-workspace-url-controller: %v", err)
+			// nolint:nilerr
+			return nil // don't klog.Fatal. This only happens when context is cancelled.
+		}
+
+		go c.Start(ctx, 2)
+		return nil
+	})
+	return nil
+}
+
+func (s *Server) installWorkspaceSourceController(ctx context.Context, config *rest.Config) error {
+	config = rest.AddUserAgent(rest.CopyConfig(config), "kcp-workspace-source-controller")
+	kcpClusterClient, err := kcpclient.NewClusterForConfig(config)
+	if err != nil {
+		return err
+	}
+	listResourcesFn := func(clusterName logicalcluster.Name) ([]*metav1.APIResourceList, error) {
+		logicalClusterConfig := rest.CopyConfig(config)
+		logicalClusterConfig.Host += clusterName.Path()
+		discoveryClient, err := discovery.NewDiscoveryClientForConfig(logicalClusterConfig)
+		if err != nil {
+			return nil, err
+		}
+		// all versions, not only the preferred ones, to apply manifests of any served version
+		_, resources, err := discoveryClient.ServerGroupsAndResources()
+		return resources, err
+	}
+
+	c := workspacesource.NewController(
+		kcpClusterClient,
+		config,
+		listResourcesFn,
+		s.kcpSharedInformerFactory.Tenancy().V1alpha1().WorkspaceSources(),
+		s.options.Controllers.WorkspaceSource,
+	)
+
+	s.AddPostStartHook("kcp-workspace-source-controller", func(hookContext genericapiserver.PostStartHookContext) error {
+		if err := s.waitForSync(hookContext.StopCh); err != nil {
+			klog.Errorf("failed to finish post-start-hook kcp-workspace-source-controller: %v", err)
+			// nolint:nilerr
+			return nil // don't klog.Fatal. This only happens when context is cancelled.
+		}
+
+		go c.Start(ctx, 2)
+		return nil
+	})
+	return nil
+}
+
+func (s *Server) installRootComputeWorkspace(ctx context.Context, config *rest.Config) error {
+	config = rest.AddUserAgent(rest.CopyConfig(config), "kcp-root-compute-workspace-bootstrap")
+	crdClusterClient, err := apiextensionsclient.NewClusterForConfig(config)
+	if err != nil {
+		return err
+	}
+	dynamicClusterClient, err := dynamic.NewClusterForConfig(config)
+	if err != nil {
+		return err
+	}
+
+	s.AddPostStartHook("kcp-bootstrap-root-compute-workspace", func(hookContext genericapiserver.PostStartHookContext) error {
+		if err := s.waitForSync(hookContext.StopCh); err != nil {
+			klog.Errorf("failed to finish post-start-hook kcp-bootstrap-root-compute-workspace: %v", err)
+			// nolint:nilerr
+			return nil // don't klog.Fatal. This only happens when context is cancelled.
+		}
+
+		// the workspace is only initialized by the controllers started after sync, hence do not block here
+		go func() {
+			if err := configrootcompute.Bootstrap(ctx,
+				crdClusterClient.Cluster(tenancyv1alpha1.RootCluster).Discovery(),
+				dynamicClusterClient.Cluster(tenancyv1alpha1.RootCluster),
+				crdClusterClient.Cluster(configrootcompute.ClusterName).Discovery(),
+				dynamicClusterClient.Cluster(configrootcompute.ClusterName),
+				confighelpers.ForceOption(s.options.Extra.ForceBootstrapReconcile),
+			); err != nil {
+				klog.Errorf("failed to bootstrap the %s workspace: %v", configrootcompute.ClusterName, err)
+				return
+			}
+			klog.Infof("Finished bootstrapping the %s workspace", configrootcompute.ClusterName)
+		}()
+		return nil
+	})
+	return nil
+}
+
 func (s *Server) installWorkloadNamespaceScheduler(ctx context.Context, config *rest.Config) error {
+
 	config = rest.AddUserAgent(rest.CopyConfig(config), "kcp-workload-namespace-scheduler")
 	kubeClient, err := kubernetes.NewClusterForConfig(config)
 	if err != nil {
@@ -334,6 +623,11 @@ func (s *Server) installWorkloadNamespaceScheduler(ctx context.Context, config *
 		return err
 	}
 
+	var bookmarks *informer.BookmarkStore
+	if s.options.Extra.InformerBookmarkDirectory != "" {
+		bookmarks = informer.NewBookmarkStore(filepath.Join(s.options.Extra.InformerBookmarkDirectory, "namespace-scheduler"))
+	}
+
 	namespaceScheduler := kcpnamespace.NewController(
 		dynamicClusterClient,
 		metadataClusterClient,
@@ -345,6 +639,8 @@ func (s *Server) installWorkloadNamespaceScheduler(ctx context.Context, config *
 		s.kubeSharedInformerFactory.Core().V1().Namespaces(),
 		s.kubeSharedInformerFactory.Core().V1().Namespaces().Lister(),
 		s.options.Extra.DiscoveryPollInterval,
+		bookmarks,
+		s.options.Controllers.NamespaceScheduler,
 	)
 
 	s.AddPostStartHook("kcp-install-namespace-scheduler", func(hookContext genericapiserver.PostStartHookContext) error {
@@ -360,6 +656,39 @@ func (s *Server) installWorkloadNamespaceScheduler(ctx context.Context, config *
 	return nil
 }
 
+func (s *Server) installResourceQuotaController(ctx context.Context, config *rest.Config) error {
+	config = rest.AddUserAgent(rest.CopyConfig(config), "kcp-resourcequota-controller")
+	kubeClient, err := kubernetes.NewClusterForConfig(config)
+	if err != nil {
+		return err
+	}
+
+	// objects are only counted, hence the metadata is enough.
+	metadataClusterClient, err := metadataclient.NewDynamicMetadataClusterClientForConfig(config)
+	if err != nil {
+		return err
+	}
+
+	c := resourcequota.NewController(
+		kubeClient,
+		metadataClusterClient,
+		s.kubeSharedInformerFactory.Core().V1().ResourceQuotas(),
+		time.Minute,
+	)
+
+	s.AddPostStartHook("kcp-start-resourcequota-controller", func(hookContext genericapiserver.PostStartHookContext) error {
+		if err := s.waitForSync(hookContext.StopCh); err != nil {
+			klog.Errorf("failed to finish post-start-hook kcp-start-resourcequota-controller: %v", err)
+			// nolint:nilerr
+			return nil // don't klog.Fatal. This only happens when context is cancelled.
+		}
+
+		go c.Start(ctx, 2)
+		return nil
+	})
+	return nil
+}
+
 func (s *Server) installWorkspaceScheduler(ctx context.Context, config *rest.Config) error {
 	config = rest.AddUserAgent(rest.CopyConfig(config), "kcp-workspace-scheduler")
 	kcpClusterClient, err := kcpclient.NewClusterForConfig(config)
@@ -401,6 +730,7 @@ func (s *Server) installWorkspaceScheduler(ctx context.Context, config *rest.Con
 		s.kcpSharedInformerFactory.Tenancy().V1alpha1().ClusterWorkspaces(),
 		"Organization",
 		configorganization.Bootstrap,
+		s.options.Extra.ForceBootstrapReconcile,
 	)
 	if err != nil {
 		return err
@@ -413,6 +743,7 @@ func (s *Server) installWorkspaceScheduler(ctx context.Context, config *rest.Con
 		s.kcpSharedInformerFactory.Tenancy().V1alpha1().ClusterWorkspaces(),
 		"Team",
 		configteam.Bootstrap,
+		s.options.Extra.ForceBootstrapReconcile,
 	)
 	if err != nil {
 		return err
@@ -425,6 +756,7 @@ func (s *Server) installWorkspaceScheduler(ctx context.Context, config *rest.Con
 		s.kcpSharedInformerFactory.Tenancy().V1alpha1().ClusterWorkspaces(),
 		"Universal",
 		configuniversal.Bootstrap,
+		s.options.Extra.ForceBootstrapReconcile,
 	)
 	if err != nil {
 		return err
@@ -463,6 +795,7 @@ func (s *Server) installApiResourceController(ctx context.Context, config *rest.
 		crdClusterClient,
 		kcpClusterClient,
 		s.options.Controllers.ApiResource.AutoPublishAPIs,
+		s.options.Controllers.ApiResource.AutoPublishAPIGroups,
 		s.kcpSharedInformerFactory.Apiresource().V1alpha1().NegotiatedAPIResources(),
 		s.kcpSharedInformerFactory.Apiresource().V1alpha1().APIResourceImports(),
 		s.apiextensionsSharedInformerFactory.Apiextensions().V1().CustomResourceDefinitions(),
@@ -517,6 +850,35 @@ func (s *Server) installWorkloadClusterHeartbeatController(ctx context.Context,
 
 }
 
+func (s *Server) installWorkloadClusterVirtualWorkspaceURLsController(ctx context.Context, config *rest.Config) error {
+	config = rest.AddUserAgent(rest.CopyConfig(config), "kcp-workloadcluster-virtualworkspace-urls-controller")
+	kcpClusterClient, err := kcpclient.NewClusterForConfig(config)
+	if err != nil {
+		return err
+	}
+
+	c, err := virtualworkspaceurls.NewController(
+		kcpClusterClient,
+		s.rootKcpSharedInformerFactory.Tenancy().V1alpha1().ClusterWorkspaceShards(),
+		s.kcpSharedInformerFactory.Workload().V1alpha1().WorkloadClusters(),
+	)
+	if err != nil {
+		return err
+	}
+
+	s.AddPostStartHook("kcp-workloadcluster-virtualworkspace-urls-controller", func(hookContext genericapiserver.PostStartHookContext) error {
+		if err := s.waitForSync(hookContext.StopCh); err != nil {
+			klog.Errorf("failed to finish post-start-hook kcp-workloadcluster-virtualworkspace-urls-controller: %v", err)
+			// nolint:nilerr
+			return nil // don't klog.Fatal. This only happens when context is cancelled.
+		}
+
+		go c.Start(ctx, 2)
+		return nil
+	})
+	return nil
+}
+
 func (s *Server) installAPIBindingController(ctx context.Context, config *rest.Config, server *genericapiserver.GenericAPIServer) error {
 	config = rest.AddUserAgent(rest.CopyConfig(config), "kcp-apibinding-controller")
 	kcpClusterClient, err := kcpclient.NewClusterForConfig(config)
@@ -532,6 +894,7 @@ func (s *Server) installAPIBindingController(ctx context.Context, config *rest.C
 	c, err := apibinding.NewController(
 		crdClusterClient,
 		kcpClusterClient,
+		s.cacheClient,
 		s.kcpSharedInformerFactory.Apis().V1alpha1().APIBindings(),
 		s.kcpSharedInformerFactory.Apis().V1alpha1().APIExports(),
 		s.kcpSharedInformerFactory.Apis().V1alpha1().APIResourceSchemas(),
@@ -558,6 +921,45 @@ func (s *Server) installAPIBindingController(ctx context.Context, config *rest.C
 	return nil
 }
 
+func (s *Server) installStorageVersionMigrationController(ctx context.Context, config *rest.Config, server *genericapiserver.GenericAPIServer) error {
+	controllerName := "kcp-storage-version-migration-controller"
+	config = rest.AddUserAgent(rest.CopyConfig(config), controllerName)
+	crdClusterClient, err := apiextensionsclient.NewClusterForConfig(config)
+	if err != nil {
+		return err
+	}
+	dynamicClusterClient, err := dynamic.NewClusterForConfig(config)
+	if err != nil {
+		return err
+	}
+
+	c, err := storageversionmigration.NewController(
+		crdClusterClient,
+		dynamicClusterClient,
+		s.apiextensionsSharedInformerFactory.Apiextensions().V1().CustomResourceDefinitions(),
+		SystemCRDLogicalCluster,
+	)
+	if err != nil {
+		return err
+	}
+
+	if err := server.AddPostStartHook(controllerName, func(hookContext genericapiserver.PostStartHookContext) error {
+		if err := s.waitForSync(hookContext.StopCh); err != nil {
+			klog.Errorf("failed to finish post-start-hook %s: %v", controllerName, err)
+			// nolint:nilerr
+			return nil // don't klog.Fatal. This only happens when context is cancelled.
+		}
+
+		go c.Start(goContext(hookContext), 1)
+
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	return nil
+}
+
 func (s *Server) installAPIExportController(ctx context.Context, config *rest.Config, server *genericapiserver.GenericAPIServer) error {
 	config = rest.AddUserAgent(rest.CopyConfig(config), "kcp-apiexport-controller")
 
@@ -578,6 +980,7 @@ func (s *Server) installAPIExportController(ctx context.Context, config *rest.Co
 		kubeClusterClient,
 		s.kubeSharedInformerFactory.Core().V1().Namespaces(),
 		s.kubeSharedInformerFactory.Core().V1().Secrets(),
+		s.secretStore,
 	)
 	if err != nil {
 		return err
@@ -598,6 +1001,117 @@ func (s *Server) installAPIExportController(ctx context.Context, config *rest.Co
 	return nil
 }
 
+func (s *Server) installAPIBindingDeprecationController(ctx context.Context, config *rest.Config, server *genericapiserver.GenericAPIServer) error {
+	controllerName := "kcp-apibinding-deprecation-controller"
+	config = rest.AddUserAgent(rest.CopyConfig(config), controllerName)
+	kcpClusterClient, err := kcpclient.NewClusterForConfig(config)
+	if err != nil {
+		return err
+	}
+
+	c, err := apibindingdeprecation.NewController(
+		kcpClusterClient,
+		s.kcpSharedInformerFactory.Apis().V1alpha1().APIBindings(),
+		s.deprecatedVersionTracker,
+	)
+	if err != nil {
+		return err
+	}
+
+	if err := server.AddPostStartHook(controllerName, func(hookContext genericapiserver.PostStartHookContext) error {
+		if err := s.waitForSync(hookContext.StopCh); err != nil {
+			klog.Errorf("failed to finish post-start-hook %s: %v", controllerName, err)
+			// nolint:nilerr
+			return nil // don't klog.Fatal. This only happens when context is cancelled.
+		}
+
+		go c.Start(goContext(hookContext), 2)
+
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *Server) installAPIBindingRolesController(ctx context.Context, config *rest.Config, server *genericapiserver.GenericAPIServer) error {
+	controllerName := "kcp-apibinding-roles-controller"
+	config = rest.AddUserAgent(rest.CopyConfig(config), controllerName)
+	kubeClusterClient, err := kubernetes.NewClusterForConfig(config)
+	if err != nil {
+		return err
+	}
+
+	c, err := apibindingroles.NewController(
+		kubeClusterClient,
+		s.kcpSharedInformerFactory.Apis().V1alpha1().APIBindings(),
+		s.kcpSharedInformerFactory.Apis().V1alpha1().APIExports(),
+		s.kubeSharedInformerFactory.Rbac().V1().ClusterRoles(),
+	)
+	if err != nil {
+		return err
+	}
+
+	if err := server.AddPostStartHook(controllerName, func(hookContext genericapiserver.PostStartHookContext) error {
+		if err := s.waitForSync(hookContext.StopCh); err != nil {
+			klog.Errorf("failed to finish post-start-hook %s: %v", controllerName, err)
+			// nolint:nilerr
+			return nil // don't klog.Fatal. This only happens when context is cancelled.
+		}
+
+		go c.Start(goContext(hookContext), 2)
+
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s *Server) installAPIExportUsageController(ctx context.Context, config *rest.Config, server *genericapiserver.GenericAPIServer) error {
+	controllerName := "kcp-apiexport-usage-controller"
+	config = rest.AddUserAgent(rest.CopyConfig(config), controllerName)
+	kcpClusterClient, err := kcpclient.NewClusterForConfig(config)
+	if err != nil {
+		return err
+	}
+	metadataClusterClient, err := metadataclient.NewDynamicMetadataClusterClientForConfig(config)
+	if err != nil {
+		return err
+	}
+
+	c, err := apiexportusage.NewController(
+		kcpClusterClient,
+		metadataClusterClient,
+		s.kcpSharedInformerFactory.Apis().V1alpha1().APIExports(),
+		s.kcpSharedInformerFactory.Apis().V1alpha1().APIBindings(),
+		s.kcpSharedInformerFactory.Apis().V1alpha1().APIResourceSchemas(),
+		s.options.Controllers.APIExportUsage.Interval,
+		s.options.Controllers.APIExportUsage.Metrics,
+	)
+	if err != nil {
+		return err
+	}
+
+	if err := server.AddPostStartHook(controllerName, func(hookContext genericapiserver.PostStartHookContext) error {
+		if err := s.waitForSync(hookContext.StopCh); err != nil {
+			klog.Errorf("failed to finish post-start-hook %s: %v", controllerName, err)
+			// nolint:nilerr
+			return nil // don't klog.Fatal. This only happens when context is cancelled.
+		}
+
+		go c.Start(goContext(hookContext), 1)
+
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	return nil
+}
+
 func (s *Server) installSchedulingLocationStatusController(ctx context.Context, config *rest.Config, server *genericapiserver.GenericAPIServer) error {
 	controllerName := "kcp-scheduling-location-status-controller"
 	config = rest.AddUserAgent(rest.CopyConfig(config), controllerName)
@@ -632,6 +1146,40 @@ func (s *Server) installSchedulingLocationStatusController(ctx context.Context,
 	return nil
 }
 
+func (s *Server) installSchedulingTopologyController(ctx context.Context, config *rest.Config, server *genericapiserver.GenericAPIServer) error {
+	controllerName := "kcp-scheduling-topology-controller"
+	config = rest.AddUserAgent(rest.CopyConfig(config), controllerName)
+	kcpClusterClient, err := kcpclient.NewClusterForConfig(config)
+	if err != nil {
+		return err
+	}
+
+	c, err := schedulingtopology.NewController(
+		kcpClusterClient,
+		s.kcpSharedInformerFactory.Scheduling().V1alpha1().Locations(),
+		s.kcpSharedInformerFactory.Workload().V1alpha1().WorkloadClusters(),
+	)
+	if err != nil {
+		return err
+	}
+
+	if err := server.AddPostStartHook(controllerName, func(hookContext genericapiserver.PostStartHookContext) error {
+		if err := s.waitForSync(hookContext.StopCh); err != nil {
+			klog.Errorf("failed to finish post-start-hook %s: %v", controllerName, err)
+			// nolint:nilerr
+			return nil // don't klog.Fatal. This only happens when context is cancelled.
+		}
+
+		go c.Start(goContext(hookContext), 2)
+
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	return nil
+}
+
 func (s *Server) installSchedulingPlacementController(ctx context.Context, config *rest.Config, server *genericapiserver.GenericAPIServer) error {
 	controllerName := "kcp-scheduling-placement-controller"
 	config = rest.AddUserAgent(rest.CopyConfig(config), controllerName)
@@ -24,11 +24,14 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apiserver/pkg/authorization/authorizer"
 	"k8s.io/apiserver/pkg/registry/rest"
 	"k8s.io/kubernetes/pkg/api/legacyscheme"
 	"k8s.io/kubernetes/pkg/printers"
 	printersinternal "k8s.io/kubernetes/pkg/printers/internalversion"
 	printerstorage "k8s.io/kubernetes/pkg/printers/storage"
+
+	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
 )
 
 type TableConverterFunc func(ctx context.Context, object runtime.Object, tableOptions runtime.Object) (*metav1.Table, error)
@@ -38,16 +41,18 @@ func (tcf TableConverterFunc) ConvertToTable(ctx context.Context, object runtime
 }
 
 type tableConverterProvider struct {
-	internalTableConverter printerstorage.TableConvertor
+	internalTableConverter         printerstorage.TableConvertor
+	clusterWorkspaceTableConverter rest.TableConvertor
 }
 
 var _ apiserver.TableConverterProvider = &tableConverterProvider{}
 
-func NewTableConverterProvider() *tableConverterProvider {
+func NewTableConverterProvider(authz authorizer.Authorizer) *tableConverterProvider {
 	return &tableConverterProvider{
 		internalTableConverter: printerstorage.TableConvertor{
 			TableGenerator: printers.NewTableGenerator().With(printersinternal.AddHandlers),
 		},
+		clusterWorkspaceTableConverter: newClusterWorkspaceTableConverter(authz),
 	}
 }
 
@@ -59,9 +64,16 @@ func NewTableConverterProvider() *tableConverterProvider {
 // via a CRD, the table columns shown from a `kubectl get deployments` command are not the ones
 // typically expected.
 //
+// ClusterWorkspaces get a table converter showing their initializers and, for users allowed to get
+// WorkspaceShards, their shard in the wide output.
+//
 // In the future this should probably be replaced by some new mechanism that would allow customizing
 // some behaviors of resources defined by CRDs.
 func (t *tableConverterProvider) GetTableConverter(group, kind, listKind string) rest.TableConvertor {
+	if group == tenancyv1alpha1.SchemeGroupVersion.Group && kind == "ClusterWorkspace" {
+		return t.clusterWorkspaceTableConverter
+	}
+
 	objectGVK := schema.GroupVersionKind{
 		Group:   group,
 		Kind:    kind,
@@ -0,0 +1,74 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"net/http"
+
+	"github.com/kcp-dev/logicalcluster"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/client-go/tools/cache"
+
+	apisv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apis/v1alpha1"
+)
+
+// WithDeprecatedVersionUsage records requests to versions of bound resources that are deprecated in their
+// APIResourceSchema, for the usage in the APIBinding status. The Warning header is added by the handler of
+// the bound CRD, which carries the deprecation of the schema.
+func WithDeprecatedVersionUsage(
+	apiHandler http.Handler,
+	deprecatedBinding func(clusterName logicalcluster.Name, gvr schema.GroupVersionResource) (string, bool),
+	record func(clusterName logicalcluster.Name, apiBindingName string, gvr schema.GroupVersionResource, userAgent string),
+) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		requestInfo, ok := request.RequestInfoFrom(req.Context())
+		cluster := request.ClusterFrom(req.Context())
+		if ok && cluster != nil && !cluster.Wildcard && requestInfo.IsResourceRequest {
+			gvr := schema.GroupVersionResource{Group: requestInfo.APIGroup, Version: requestInfo.APIVersion, Resource: requestInfo.Resource}
+			if apiBindingName, deprecated := deprecatedBinding(cluster.Name, gvr); deprecated {
+				record(cluster.Name, apiBindingName, gvr, req.UserAgent())
+			}
+		}
+		apiHandler.ServeHTTP(w, req)
+	}
+}
+
+// deprecatedBoundVersionFunc returns a func returning the APIBinding through which a resource is bound in a
+// logical cluster if the requested version is deprecated. The APIBinding indexer must have the
+// indexAPIBindingsByClusterGroupResource index, and the CRD indexer the indexCRDsByDeprecatedVersion index.
+func deprecatedBoundVersionFunc(apiBindingIndexer, crdIndexer cache.Indexer) func(clusterName logicalcluster.Name, gvr schema.GroupVersionResource) (string, bool) {
+	return func(clusterName logicalcluster.Name, gvr schema.GroupVersionResource) (string, bool) {
+		apiBindings, err := apiBindingIndexer.ByIndex(indexAPIBindingsByClusterGroupResource, clusterGroupResourceKey(clusterName, gvr.Group, gvr.Resource))
+		if err != nil || len(apiBindings) == 0 {
+			return "", false
+		}
+		apiBinding := apiBindings[0].(*apisv1alpha1.APIBinding)
+		for _, boundResource := range apiBinding.Status.BoundResources {
+			if boundResource.Group != gvr.Group || boundResource.Resource != gvr.Resource {
+				continue
+			}
+			crds, err := crdIndexer.ByIndex(indexCRDsByDeprecatedVersion, deprecatedVersionKey(boundResource.Schema.UID, gvr.Version))
+			if err != nil || len(crds) == 0 {
+				return "", false
+			}
+			return apiBinding.Name, true
+		}
+		return "", false
+	}
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kcp-dev/logicalcluster"
+	"github.com/stretchr/testify/require"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/client-go/tools/cache"
+
+	apisv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apis/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/reconciler/apis/apibinding"
+)
+
+func TestWithDeprecatedVersionUsage(t *testing.T) {
+	apiBindingIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{indexAPIBindingsByClusterGroupResource: indexAPIBindingsByClusterGroupResourceFunc})
+	require.NoError(t, apiBindingIndexer.Add(&apisv1alpha1.APIBinding{
+		ObjectMeta: metav1.ObjectMeta{ClusterName: "root:org:ws", Name: "widgets"},
+		Status: apisv1alpha1.APIBindingStatus{
+			BoundResources: []apisv1alpha1.BoundAPIResource{
+				{Group: "example.com", Resource: "widgets", Schema: apisv1alpha1.BoundAPIResourceSchema{UID: "uid"}},
+			},
+		},
+	}))
+	crdIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{indexCRDsByDeprecatedVersion: indexCRDsByDeprecatedVersionFunc})
+	require.NoError(t, crdIndexer.Add(&apiextensionsv1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{ClusterName: apibinding.ShadowWorkspaceName.String(), Name: "uid"},
+		Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+			Versions: []apiextensionsv1.CustomResourceDefinitionVersion{
+				{Name: "v1alpha1", Deprecated: true},
+				{Name: "v1"},
+			},
+		},
+	}))
+	deprecatedBinding := deprecatedBoundVersionFunc(apiBindingIndexer, crdIndexer)
+
+	type recorded struct {
+		cluster    logicalcluster.Name
+		apiBinding string
+		gvr        schema.GroupVersionResource
+		userAgent  string
+	}
+	var got []recorded
+	handler := WithDeprecatedVersionUsage(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {}), deprecatedBinding,
+		func(clusterName logicalcluster.Name, apiBindingName string, gvr schema.GroupVersionResource, userAgent string) {
+			got = append(got, recorded{cluster: clusterName, apiBinding: apiBindingName, gvr: gvr, userAgent: userAgent})
+		},
+	)
+
+	tests := []struct {
+		name     string
+		cluster  string
+		group    string
+		version  string
+		resource string
+		expected []recorded
+	}{
+		{name: "deprecated version", cluster: "root:org:ws", group: "example.com", version: "v1alpha1", resource: "widgets", expected: []recorded{
+			{cluster: logicalcluster.New("root:org:ws"), apiBinding: "widgets", gvr: schema.GroupVersionResource{Group: "example.com", Version: "v1alpha1", Resource: "widgets"}, userAgent: "kubectl"},
+		}},
+		{name: "served version", cluster: "root:org:ws", group: "example.com", version: "v1", resource: "widgets"},
+		{name: "unknown version", cluster: "root:org:ws", group: "example.com", version: "v2", resource: "widgets"},
+		{name: "other workspace", cluster: "root:org:other", group: "example.com", version: "v1alpha1", resource: "widgets"},
+		{name: "unbound resource", cluster: "root:org:ws", group: "example.com", version: "v1alpha1", resource: "gadgets"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			got = nil
+
+			req := httptest.NewRequest("GET", "/clusters/"+tt.cluster+"/apis/"+tt.group+"/"+tt.version+"/"+tt.resource, nil)
+			req.Header.Set("User-Agent", "kubectl")
+			ctx := request.WithCluster(req.Context(), request.Cluster{Name: logicalcluster.New(tt.cluster)})
+			ctx = request.WithRequestInfo(ctx, &request.RequestInfo{IsResourceRequest: true, Verb: "list", APIGroup: tt.group, APIVersion: tt.version, Resource: tt.resource})
+			handler.ServeHTTP(httptest.NewRecorder(), req.WithContext(ctx))
+
+			require.Equal(t, tt.expected, got)
+		})
+	}
+}
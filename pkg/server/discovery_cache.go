@@ -0,0 +1,178 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"sync"
+
+	"github.com/kcp-dev/logicalcluster"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/clusters"
+
+	apisv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apis/v1alpha1"
+	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/reconciler/apis/apibinding"
+)
+
+// crdListCache caches the CRDs served in a logical cluster, i.e. the result of listing without
+// label selector. Discovery asks for them on every request, and computing them walks all
+// APIBindings and CRDs. Entries are invalidated by CRD, APIBinding, ClusterWorkspace and ClusterWorkspaceType events.
+type crdListCache struct {
+	lock sync.RWMutex
+	// generation is incremented on every invalidation. It avoids storing a result that was
+	// computed before a concurrent invalidation.
+	generation uint64
+	entries    map[logicalcluster.Name][]*apiextensionsv1.CustomResourceDefinition
+}
+
+func newCRDListCache() *crdListCache {
+	return &crdListCache{
+		entries: map[logicalcluster.Name][]*apiextensionsv1.CustomResourceDefinition{},
+	}
+}
+
+// get returns a copy of the cached CRDs of the given logical cluster if present, and the
+// current generation to be passed to set.
+func (c *crdListCache) get(clusterName logicalcluster.Name) ([]*apiextensionsv1.CustomResourceDefinition, uint64, bool) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+
+	crds, found := c.entries[clusterName]
+	if !found {
+		return nil, c.generation, false
+	}
+	return append([]*apiextensionsv1.CustomResourceDefinition(nil), crds...), c.generation, true
+}
+
+// set stores the CRDs of the given logical cluster unless there was an invalidation since
+// the given generation was returned by get.
+func (c *crdListCache) set(clusterName logicalcluster.Name, crds []*apiextensionsv1.CustomResourceDefinition, generation uint64) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if generation != c.generation {
+		return
+	}
+	c.entries[clusterName] = append([]*apiextensionsv1.CustomResourceDefinition(nil), crds...)
+}
+
+func (c *crdListCache) invalidate(clusterName logicalcluster.Name) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.generation++
+	delete(c.entries, clusterName)
+}
+
+func (c *crdListCache) invalidateAll() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.generation++
+	c.entries = map[logicalcluster.Name][]*apiextensionsv1.CustomResourceDefinition{}
+}
+
+// crdEventHandler invalidates the logical cluster of a CRD. CRDs of bound APIs and system CRDs
+// are served in other logical clusters, so their changes invalidate everything.
+func (c *crdListCache) crdEventHandler() cache.ResourceEventHandler {
+	return eventHandlerFor(func(obj interface{}) {
+		crd, ok := obj.(*apiextensionsv1.CustomResourceDefinition)
+		if !ok {
+			return
+		}
+		switch clusterName := logicalcluster.From(crd); clusterName {
+		case apibinding.ShadowWorkspaceName, SystemCRDLogicalCluster:
+			c.invalidateAll()
+		default:
+			c.invalidate(clusterName)
+		}
+	}, true)
+}
+
+// apiBindingEventHandler invalidates the logical cluster of an APIBinding.
+func (c *crdListCache) apiBindingEventHandler() cache.ResourceEventHandler {
+	return eventHandlerFor(func(obj interface{}) {
+		if apiBinding, ok := obj.(*apisv1alpha1.APIBinding); ok {
+			c.invalidate(logicalcluster.From(apiBinding))
+		}
+	}, true)
+}
+
+// clusterWorkspaceEventHandler invalidates the logical cluster of a ClusterWorkspace when it
+// is created or deleted, as its type determines the system CRDs. The type is immutable, so
+// updates are ignored.
+func (c *crdListCache) clusterWorkspaceEventHandler() cache.ResourceEventHandler {
+	return eventHandlerFor(func(obj interface{}) {
+		if clusterWorkspace, ok := obj.(*tenancyv1alpha1.ClusterWorkspace); ok {
+			c.invalidate(logicalcluster.From(clusterWorkspace).Join(clusterWorkspace.Name))
+		}
+	}, false)
+}
+
+// clusterWorkspaceTypeEventHandler invalidates the logical clusters of the workspaces of a
+// ClusterWorkspaceType when its excluded system CRD groups change, as they determine the system CRDs
+// of the workspaces. The workspaces are looked up in the given indexer by indexClusterWorkspacesByType.
+func (c *crdListCache) clusterWorkspaceTypeEventHandler(clusterWorkspaceIndexer cache.Indexer) cache.ResourceEventHandler {
+	invalidate := func(obj interface{}) {
+		cwt, ok := obj.(*tenancyv1alpha1.ClusterWorkspaceType)
+		if !ok {
+			return
+		}
+		workspaces, err := clusterWorkspaceIndexer.ByIndex(indexClusterWorkspacesByType, clusters.ToClusterAwareKey(logicalcluster.From(cwt), cwt.Name))
+		if err != nil {
+			utilruntime.HandleError(err)
+			c.invalidateAll()
+			return
+		}
+		for _, obj := range workspaces {
+			if clusterWorkspace, ok := obj.(*tenancyv1alpha1.ClusterWorkspace); ok {
+				c.invalidate(logicalcluster.From(clusterWorkspace).Join(clusterWorkspace.Name))
+			}
+		}
+	}
+
+	handler := eventHandlerFor(invalidate, false)
+	handler.UpdateFunc = func(oldObj, newObj interface{}) {
+		oldCWT, oldOK := oldObj.(*tenancyv1alpha1.ClusterWorkspaceType)
+		newCWT, newOK := newObj.(*tenancyv1alpha1.ClusterWorkspaceType)
+		if oldOK && newOK && sets.NewString(oldCWT.Spec.ExcludedSystemCRDGroups...).Equal(sets.NewString(newCWT.Spec.ExcludedSystemCRDGroups...)) {
+			return
+		}
+		invalidate(newObj)
+	}
+	return handler
+}
+
+func eventHandlerFor(invalidate func(obj interface{}), onUpdate bool) cache.ResourceEventHandlerFuncs {
+	handler := cache.ResourceEventHandlerFuncs{
+		AddFunc: invalidate,
+		DeleteFunc: func(obj interface{}) {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			invalidate(obj)
+		},
+	}
+	if onUpdate {
+		handler.UpdateFunc = func(_, obj interface{}) { invalidate(obj) }
+	}
+	return handler
+}
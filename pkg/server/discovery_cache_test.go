@@ -0,0 +1,126 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"testing"
+
+	"github.com/kcp-dev/logicalcluster"
+	"github.com/stretchr/testify/require"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+
+	apisv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apis/v1alpha1"
+	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/reconciler/apis/apibinding"
+)
+
+func TestCRDListCache(t *testing.T) {
+	foo := logicalcluster.New("root:org:foo")
+	bar := logicalcluster.New("root:org:bar")
+	crd := func(clusterName logicalcluster.Name, name string) *apiextensionsv1.CustomResourceDefinition {
+		return &apiextensionsv1.CustomResourceDefinition{
+			ObjectMeta: metav1.ObjectMeta{ClusterName: clusterName.String(), Name: name},
+		}
+	}
+	fill := func(c *crdListCache) {
+		for _, clusterName := range []logicalcluster.Name{foo, bar} {
+			_, generation, _ := c.get(clusterName)
+			c.set(clusterName, []*apiextensionsv1.CustomResourceDefinition{crd(clusterName, "widgets.example.com")}, generation)
+		}
+	}
+	cached := func(c *crdListCache, clusterName logicalcluster.Name) bool {
+		_, _, found := c.get(clusterName)
+		return found
+	}
+
+	t.Run("set and get", func(t *testing.T) {
+		c := newCRDListCache()
+		fill(c)
+		crds, _, found := c.get(foo)
+		require.True(t, found)
+		require.Len(t, crds, 1)
+
+		// the returned slice is a copy
+		crds[0] = nil
+		crds, _, _ = c.get(foo)
+		require.NotNil(t, crds[0])
+	})
+
+	t.Run("concurrent invalidation drops stale result", func(t *testing.T) {
+		c := newCRDListCache()
+		_, generation, found := c.get(foo)
+		require.False(t, found)
+		c.invalidate(bar)
+		c.set(foo, nil, generation)
+		require.False(t, cached(c, foo))
+	})
+
+	t.Run("local CRD invalidates its logical cluster", func(t *testing.T) {
+		c := newCRDListCache()
+		fill(c)
+		c.crdEventHandler().OnUpdate(nil, crd(foo, "widgets.example.com"))
+		require.False(t, cached(c, foo))
+		require.True(t, cached(c, bar))
+	})
+
+	t.Run("bound CRD invalidates all logical clusters", func(t *testing.T) {
+		c := newCRDListCache()
+		fill(c)
+		c.crdEventHandler().OnDelete(cache.DeletedFinalStateUnknown{Obj: crd(apibinding.ShadowWorkspaceName, "some-uid")})
+		require.False(t, cached(c, foo))
+		require.False(t, cached(c, bar))
+	})
+
+	t.Run("APIBinding invalidates its logical cluster", func(t *testing.T) {
+		c := newCRDListCache()
+		fill(c)
+		c.apiBindingEventHandler().OnAdd(&apisv1alpha1.APIBinding{ObjectMeta: metav1.ObjectMeta{ClusterName: bar.String(), Name: "widgets"}})
+		require.True(t, cached(c, foo))
+		require.False(t, cached(c, bar))
+	})
+
+	t.Run("ClusterWorkspaceType invalidates the logical clusters of its workspaces", func(t *testing.T) {
+		indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{indexClusterWorkspacesByType: indexClusterWorkspacesByTypeFunc})
+		require.NoError(t, indexer.Add(&tenancyv1alpha1.ClusterWorkspace{
+			ObjectMeta: metav1.ObjectMeta{ClusterName: "root:org", Name: "foo"},
+			Spec:       tenancyv1alpha1.ClusterWorkspaceSpec{Type: "Universal"},
+		}))
+		require.NoError(t, indexer.Add(&tenancyv1alpha1.ClusterWorkspace{
+			ObjectMeta: metav1.ObjectMeta{ClusterName: "root:org", Name: "bar"},
+			Spec:       tenancyv1alpha1.ClusterWorkspaceSpec{Type: "Team"},
+		}))
+		cwt := func(excluded ...string) *tenancyv1alpha1.ClusterWorkspaceType {
+			return &tenancyv1alpha1.ClusterWorkspaceType{
+				ObjectMeta: metav1.ObjectMeta{ClusterName: "root:org", Name: "universal"},
+				Spec:       tenancyv1alpha1.ClusterWorkspaceTypeSpec{ExcludedSystemCRDGroups: excluded},
+			}
+		}
+
+		c := newCRDListCache()
+		fill(c)
+		c.clusterWorkspaceTypeEventHandler(indexer).OnUpdate(cwt("workload.kcp.dev"), cwt("workload.kcp.dev"))
+		require.True(t, cached(c, foo), "unchanged excluded groups must not invalidate")
+		require.True(t, cached(c, bar))
+
+		c.clusterWorkspaceTypeEventHandler(indexer).OnUpdate(cwt(), cwt("workload.kcp.dev"))
+		require.False(t, cached(c, foo))
+		require.True(t, cached(c, bar), "workspaces of other types must not be invalidated")
+	})
+}
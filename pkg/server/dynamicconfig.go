@@ -0,0 +1,150 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"syscall"
+
+	utilfeature "k8s.io/apiserver/pkg/util/feature"
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/yaml"
+
+	kcpfeatures "github.com/kcp-dev/kcp/pkg/features"
+)
+
+// DynamicConfig is the content of the --dynamic-config-file. It is applied on startup, and
+// re-read on SIGHUP, i.e. without restarting the server and dropping the watches of clients.
+type DynamicConfig struct {
+	// FeatureGates enables or disables features. Only the features evaluated per request
+	// can be changed, see features.ReloadableFeatures.
+	FeatureGates map[string]bool `json:"featureGates,omitempty"`
+
+	// Verbosity is the log level verbosity, like the -v flag.
+	Verbosity *int `json:"verbosity,omitempty"`
+
+	// VModule is a comma-separated list of pattern=N settings for file-filtered logging,
+	// like the --vmodule flag.
+	VModule *string `json:"vmodule,omitempty"`
+}
+
+// loadDynamicConfig reads and validates the dynamic config in the given file.
+func loadDynamicConfig(path string) (*DynamicConfig, error) {
+	bs, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var config DynamicConfig
+	if err := yaml.UnmarshalStrict(bs, &config); err != nil {
+		return nil, fmt.Errorf("failed to decode dynamic config %s: %w", path, err)
+	}
+
+	var invalid []string
+	for name := range config.FeatureGates {
+		if !kcpfeatures.ReloadableFeatures.Has(name) {
+			invalid = append(invalid, name)
+		}
+	}
+	if len(invalid) > 0 {
+		sort.Strings(invalid)
+		return nil, fmt.Errorf("feature gates %v in dynamic config %s cannot be changed at runtime, only %v", invalid, path, kcpfeatures.ReloadableFeatures.List())
+	}
+	if config.Verbosity != nil && *config.Verbosity < 0 {
+		return nil, fmt.Errorf("invalid verbosity %d in dynamic config %s", *config.Verbosity, path)
+	}
+
+	return &config, nil
+}
+
+// apply sets the feature gates and log verbosity of the config. Unset fields are left unchanged.
+func (c *DynamicConfig) apply() error {
+	if len(c.FeatureGates) > 0 {
+		if err := utilfeature.DefaultMutableFeatureGate.SetFromMap(c.FeatureGates); err != nil {
+			return err
+		}
+	}
+
+	// klog has no setters for its flags, hence bind them to a private flag set.
+	klogFlags := flag.NewFlagSet("klog", flag.ContinueOnError)
+	klog.InitFlags(klogFlags)
+	if c.Verbosity != nil {
+		if err := klogFlags.Set("v", strconv.Itoa(*c.Verbosity)); err != nil {
+			return err
+		}
+	}
+	if c.VModule != nil {
+		if err := klogFlags.Set("vmodule", *c.VModule); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// applyDynamicConfig loads and applies the dynamic config in the given file.
+func applyDynamicConfig(path string) error {
+	config, err := loadDynamicConfig(path)
+	if err != nil {
+		return err
+	}
+	if err := config.apply(); err != nil {
+		return fmt.Errorf("failed to apply dynamic config %s: %w", path, err)
+	}
+	klog.Infof("Applied dynamic config %s: feature gates %v, verbosity %s, vmodule %s", path, config.FeatureGates, optionalString(config.Verbosity), optionalString(config.VModule))
+	return nil
+}
+
+// watchDynamicConfig re-applies the dynamic config in the given file on every SIGHUP until the context
+// is done. Invalid configs are logged and leave the current settings in place.
+func watchDynamicConfig(ctx context.Context, path string) {
+	sighup := make(chan os.Signal, 1)
+	signal.Notify(sighup, syscall.SIGHUP)
+	defer signal.Stop(sighup)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-sighup:
+			klog.Infof("Received SIGHUP, reloading dynamic config %s", path)
+			if err := applyDynamicConfig(path); err != nil {
+				klog.Errorf("Failed to reload dynamic config: %v", err)
+			}
+		}
+	}
+}
+
+func optionalString(v interface{}) string {
+	switch v := v.(type) {
+	case *int:
+		if v != nil {
+			return strconv.Itoa(*v)
+		}
+	case *string:
+		if v != nil {
+			return strconv.Quote(*v)
+		}
+	}
+	return "unchanged"
+}
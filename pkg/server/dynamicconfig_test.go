@@ -0,0 +1,105 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"flag"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	genericfeatures "k8s.io/apiserver/pkg/features"
+	utilfeature "k8s.io/apiserver/pkg/util/feature"
+	"k8s.io/klog/v2"
+)
+
+func TestApplyDynamicConfig(t *testing.T) {
+	klogFlags := flag.NewFlagSet("klog", flag.ContinueOnError)
+	klog.InitFlags(klogFlags)
+	verbosity := klogFlags.Lookup("v").Value.String()
+	compression := utilfeature.DefaultFeatureGate.Enabled(genericfeatures.APIResponseCompression)
+	t.Cleanup(func() {
+		require.NoError(t, klogFlags.Set("v", verbosity))
+		require.NoError(t, klogFlags.Set("vmodule", ""))
+		require.NoError(t, utilfeature.DefaultMutableFeatureGate.SetFromMap(map[string]bool{string(genericfeatures.APIResponseCompression): compression}))
+	})
+
+	tests := []struct {
+		name              string
+		content           string
+		wantErr           string
+		wantCompression   bool
+		wantVerbosity     string
+		wantVModuleChange bool
+	}{
+		{
+			name:            "empty config changes nothing",
+			content:         "",
+			wantCompression: compression,
+			wantVerbosity:   verbosity,
+		},
+		{
+			name:            "feature gate and verbosity",
+			content:         "featureGates:\n  APIResponseCompression: false\nverbosity: 6\n",
+			wantCompression: false,
+			wantVerbosity:   "6",
+		},
+		{
+			name:              "vmodule keeps verbosity",
+			content:           "vmodule: apibinding*=4\n",
+			wantCompression:   false,
+			wantVerbosity:     "6",
+			wantVModuleChange: true,
+		},
+		{
+			name:    "features read on startup are rejected",
+			content: "featureGates:\n  KCPLocationAPI: true\n",
+			wantErr: "feature gates [KCPLocationAPI] in dynamic config",
+		},
+		{
+			name:    "unknown fields are rejected",
+			content: "logLevel: 2\n",
+			wantErr: "failed to decode dynamic config",
+		},
+		{
+			name:    "negative verbosity is rejected",
+			content: "verbosity: -1\n",
+			wantErr: "invalid verbosity -1",
+		},
+	}
+
+	for _, tc := range tests {
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		require.NoError(t, ioutil.WriteFile(path, []byte(tc.content), 0600))
+
+		err := applyDynamicConfig(path)
+		if tc.wantErr != "" {
+			require.Error(t, err, tc.name)
+			require.Contains(t, err.Error(), tc.wantErr, tc.name)
+			continue
+		}
+		require.NoError(t, err, tc.name)
+
+		require.Equal(t, tc.wantCompression, utilfeature.DefaultFeatureGate.Enabled(genericfeatures.APIResponseCompression), tc.name)
+		require.Equal(t, tc.wantVerbosity, klogFlags.Lookup("v").Value.String(), tc.name)
+		if tc.wantVModuleChange {
+			require.Equal(t, "apibinding*=4", klogFlags.Lookup("vmodule").Value.String(), tc.name)
+		}
+	}
+}
@@ -52,6 +52,7 @@ import (
 	"k8s.io/kubernetes/pkg/genericcontrolplane/aggregator"
 
 	tenancyv1beta1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1beta1"
+	"github.com/kcp-dev/kcp/pkg/tracing"
 )
 
 var (
@@ -170,6 +171,17 @@ func WithWorkspaceProjection(apiHandler http.Handler) http.HandlerFunc {
 	}
 }
 
+// WithClusterTracing annotates the span of the request, started by the generic handler chain when
+// tracing is enabled, with the logical cluster of the request.
+func WithClusterTracing(apiHandler http.Handler) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		if cluster := request.ClusterFrom(req.Context()); cluster != nil {
+			tracing.AnnotateSpan(req.Context(), cluster.Name)
+		}
+		apiHandler.ServeHTTP(w, req)
+	}
+}
+
 func WithWildcardListWatchGuard(apiHandler http.Handler) http.HandlerFunc {
 	return func(w http.ResponseWriter, req *http.Request) {
 		cluster := request.ClusterFrom(req.Context())
@@ -196,6 +208,43 @@ func WithWildcardListWatchGuard(apiHandler http.Handler) http.HandlerFunc {
 	}
 }
 
+// WithWatchTerminationDuringShutdown ends running watches when terminateCh is closed, and rejects new
+// watches afterwards with a 429 and a Retry-After header. The watch streams end cleanly and the HTTP/2
+// GOAWAY sent by the shutting down server makes clients re-establish them, through the load balancer,
+// against another shard instead of waiting for the connection to be torn down.
+func WithWatchTerminationDuringShutdown(apiHandler http.Handler, terminateCh <-chan struct{}) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		requestInfo, ok := request.RequestInfoFrom(req.Context())
+		if !ok || requestInfo.Verb != "watch" {
+			apiHandler.ServeHTTP(w, req)
+			return
+		}
+
+		select {
+		case <-terminateCh:
+			responsewriters.ErrorNegotiated(
+				apierrors.NewTooManyRequests("the server is shutting down", 1),
+				errorCodecs, schema.GroupVersion{Group: requestInfo.APIGroup, Version: requestInfo.APIVersion}, w, req,
+			)
+			return
+		default:
+		}
+
+		ctx, cancel := context.WithCancel(req.Context())
+		defer cancel()
+		go func() {
+			select {
+			case <-terminateCh:
+				klog.V(4).Infof("Terminating watch %s because of shutdown", req.URL.Path)
+				cancel()
+			case <-ctx.Done():
+			}
+		}()
+
+		apiHandler.ServeHTTP(w, req.WithContext(ctx))
+	}
+}
+
 // WithInClusterServiceAccountRequestRewrite adds the /clusters/<clusterName> prefix to the request path if the request comes
 // from an InCluster service account requests (InCluster clients don't support prefixes).
 func WithInClusterServiceAccountRequestRewrite(handler http.Handler, unsafeServiceAccountPreAuth authenticator.Request) http.Handler {
@@ -20,6 +20,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"path/filepath"
 	"runtime"
@@ -223,3 +224,44 @@ func TestProcessResourceIdentity(t *testing.T) {
 		})
 	}
 }
+
+func TestWithWatchTerminationDuringShutdown(t *testing.T) {
+	terminateCh := make(chan struct{})
+	watchStarted := make(chan struct{})
+	handler := WithWatchTerminationDuringShutdown(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if info, _ := request.RequestInfoFrom(req.Context()); info.Verb == "watch" {
+			close(watchStarted)
+			<-req.Context().Done()
+		}
+		w.WriteHeader(http.StatusOK)
+	}), terminateCh)
+
+	newRequest := func(verb string) *http.Request {
+		req := httptest.NewRequest("GET", "/clusters/root/api/v1/namespaces", nil)
+		return req.WithContext(request.WithRequestInfo(req.Context(), &request.RequestInfo{IsResourceRequest: true, Verb: verb, APIVersion: "v1", Resource: "namespaces"}))
+	}
+
+	watchDone := make(chan int)
+	go func() {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, newRequest("watch"))
+		watchDone <- w.Code
+	}()
+	<-watchStarted
+
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, newRequest("list"))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	close(terminateCh)
+	require.Equal(t, http.StatusOK, <-watchDone, "running watch should end on termination")
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, newRequest("watch"))
+	require.Equal(t, http.StatusTooManyRequests, w.Code)
+	require.Equal(t, "1", w.Header().Get("Retry-After"))
+
+	w = httptest.NewRecorder()
+	handler.ServeHTTP(w, newRequest("list"))
+	require.Equal(t, http.StatusOK, w.Code)
+}
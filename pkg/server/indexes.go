@@ -0,0 +1,109 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kcp-dev/logicalcluster"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/client-go/tools/clusters"
+
+	apisv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apis/v1alpha1"
+	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/reconciler/apis/apibinding"
+)
+
+const indexAPIBindingsByLogicalCluster = "apiBindingsByLogicalCluster"
+
+// indexAPIBindingsByLogicalClusterFunc is an index function that maps an APIBinding to its logical cluster.
+func indexAPIBindingsByLogicalClusterFunc(obj interface{}) ([]string, error) {
+	apiBinding, ok := obj.(*apisv1alpha1.APIBinding)
+	if !ok {
+		return []string{}, fmt.Errorf("obj is supposed to be an APIBinding, but is %T", obj)
+	}
+
+	return []string{logicalcluster.From(apiBinding).String()}, nil
+}
+
+const indexAPIBindingsByClusterGroupResource = "apiBindingsByClusterGroupResource"
+
+// indexAPIBindingsByClusterGroupResourceFunc is an index function that maps an APIBinding to the keys of the
+// resources it binds in its logical cluster.
+func indexAPIBindingsByClusterGroupResourceFunc(obj interface{}) ([]string, error) {
+	apiBinding, ok := obj.(*apisv1alpha1.APIBinding)
+	if !ok {
+		return []string{}, fmt.Errorf("obj is supposed to be an APIBinding, but is %T", obj)
+	}
+
+	ret := make([]string, 0, len(apiBinding.Status.BoundResources))
+	for _, r := range apiBinding.Status.BoundResources {
+		ret = append(ret, clusterGroupResourceKey(logicalcluster.From(apiBinding), r.Group, r.Resource))
+	}
+	return ret, nil
+}
+
+func clusterGroupResourceKey(clusterName logicalcluster.Name, group, resource string) string {
+	return clusters.ToClusterAwareKey(clusterName, group+"/"+resource)
+}
+
+const indexClusterWorkspacesByType = "clusterWorkspacesByType"
+
+// indexClusterWorkspacesByTypeFunc is an index function that maps a ClusterWorkspace to the key of its
+// ClusterWorkspaceType in the logical cluster of the ClusterWorkspace.
+func indexClusterWorkspacesByTypeFunc(obj interface{}) ([]string, error) {
+	clusterWorkspace, ok := obj.(*tenancyv1alpha1.ClusterWorkspace)
+	if !ok {
+		return []string{}, fmt.Errorf("obj is supposed to be a ClusterWorkspace, but is %T", obj)
+	}
+
+	return []string{clusterWorkspaceTypeKey(logicalcluster.From(clusterWorkspace), clusterWorkspace.Spec.Type)}, nil
+}
+
+// clusterWorkspaceTypeKey returns the key of the ClusterWorkspaceType of the given type name in the given
+// logical cluster.
+func clusterWorkspaceTypeKey(clusterName logicalcluster.Name, typeName string) string {
+	return clusters.ToClusterAwareKey(clusterName, strings.ToLower(typeName))
+}
+
+const indexCRDsByDeprecatedVersion = "crdsByDeprecatedVersion"
+
+// indexCRDsByDeprecatedVersionFunc is an index function that maps a bound CRD to the keys of its deprecated
+// versions, such that requests need not look at the versions of the CRD.
+func indexCRDsByDeprecatedVersionFunc(obj interface{}) ([]string, error) {
+	crd, ok := obj.(*apiextensionsv1.CustomResourceDefinition)
+	if !ok {
+		return []string{}, fmt.Errorf("obj is supposed to be a CustomResourceDefinition, but is %T", obj)
+	}
+	if logicalcluster.From(crd) != apibinding.ShadowWorkspaceName {
+		return []string{}, nil
+	}
+
+	var ret []string
+	for _, version := range crd.Spec.Versions {
+		if version.Deprecated {
+			ret = append(ret, deprecatedVersionKey(crd.Name, version.Name))
+		}
+	}
+	return ret, nil
+}
+
+func deprecatedVersionKey(crdName, version string) string {
+	return crdName + "/" + version
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"net/http"
+	"sync"
+
+	"github.com/kcp-dev/logicalcluster"
+
+	"k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+
+	apisv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apis/v1alpha1"
+	"github.com/kcp-dev/kcp/third_party/conditions/util/conditions"
+)
+
+var (
+	boundResourceListRequests = metrics.NewCounterVec(
+		&metrics.CounterOpts{
+			Namespace:      "kcp",
+			Name:           "bound_resource_list_requests_total",
+			Help:           "Number of list requests for resources bound through APIBindings, by group and resource. Compare with apiserver_cache_list_total for the lists served from the watch cache.",
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"group", "resource"},
+	)
+
+	wildcardWatches = metrics.NewGaugeVec(
+		&metrics.GaugeOpts{
+			Namespace:      "kcp",
+			Name:           "wildcard_watches",
+			Help:           "Number of running watches in the * logical cluster, by whether they are opened by system or other users.",
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"class"},
+	)
+
+	rejectedWildcardWatches = metrics.NewCounter(
+		&metrics.CounterOpts{
+			Namespace:      "kcp",
+			Name:           "rejected_wildcard_watches_total",
+			Help:           "Number of watches in the * logical cluster rejected because the user runs too many of them.",
+			StabilityLevel: metrics.ALPHA,
+		},
+	)
+)
+
+var registerMetrics sync.Once
+
+// RegisterMetrics registers the kcp server metrics.
+func RegisterMetrics() {
+	registerMetrics.Do(func() {
+		legacyregistry.MustRegister(boundResourceListRequests)
+		legacyregistry.MustRegister(wildcardWatches)
+		legacyregistry.MustRegister(rejectedWildcardWatches)
+	})
+}
+
+// WithBoundResourceListMetrics counts list requests for bound resources. Whether the watch cache serves
+// them is decided by the cacher, which counts the lists it serves in apiserver_cache_list_total.
+func WithBoundResourceListMetrics(apiHandler http.Handler, isBound func(clusterName logicalcluster.Name, group, resource string) bool) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		requestInfo, ok := request.RequestInfoFrom(req.Context())
+		cluster := request.ClusterFrom(req.Context())
+		if ok && cluster != nil && requestInfo.IsResourceRequest && requestInfo.Verb == "list" && requestInfo.Subresource == "" {
+			if cluster.Wildcard && IdentityFromContext(req.Context()) != "" || isBound(cluster.Name, requestInfo.APIGroup, requestInfo.Resource) {
+				boundResourceListRequests.WithLabelValues(requestInfo.APIGroup, requestInfo.Resource).Inc()
+			}
+		}
+		apiHandler.ServeHTTP(w, req)
+	}
+}
+
+// boundResourceFunc returns a func telling whether a resource is bound through an APIBinding in a logical cluster.
+func boundResourceFunc(apiBindingIndexer cache.Indexer) func(clusterName logicalcluster.Name, group, resource string) bool {
+	return func(clusterName logicalcluster.Name, group, resource string) bool {
+		objs, err := apiBindingIndexer.ByIndex(indexAPIBindingsByClusterGroupResource, clusterGroupResourceKey(clusterName, group, resource))
+		if err != nil {
+			return false
+		}
+		for _, obj := range objs {
+			if conditions.IsTrue(obj.(*apisv1alpha1.APIBinding), apisv1alpha1.InitialBindingCompleted) {
+				return true
+			}
+		}
+		return false
+	}
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kcp-dev/logicalcluster"
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/component-base/metrics/testutil"
+
+	apisv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apis/v1alpha1"
+	conditionsv1alpha1 "github.com/kcp-dev/kcp/third_party/conditions/apis/conditions/v1alpha1"
+)
+
+func TestWithBoundResourceListMetrics(t *testing.T) {
+	RegisterMetrics()
+
+	apiBindingIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{indexAPIBindingsByClusterGroupResource: indexAPIBindingsByClusterGroupResourceFunc})
+	require.NoError(t, apiBindingIndexer.Add(&apisv1alpha1.APIBinding{
+		ObjectMeta: metav1.ObjectMeta{ClusterName: "root:org:ws", Name: "widgets"},
+		Status: apisv1alpha1.APIBindingStatus{
+			Conditions: conditionsv1alpha1.Conditions{{Type: apisv1alpha1.InitialBindingCompleted, Status: corev1.ConditionTrue}},
+			BoundResources: []apisv1alpha1.BoundAPIResource{
+				{Group: "example.com", Resource: "widgets"},
+			},
+		},
+	}))
+	require.NoError(t, apiBindingIndexer.Add(&apisv1alpha1.APIBinding{
+		ObjectMeta: metav1.ObjectMeta{ClusterName: "root:org:ws", Name: "gadgets"},
+		Status: apisv1alpha1.APIBindingStatus{
+			BoundResources: []apisv1alpha1.BoundAPIResource{
+				{Group: "example.com", Resource: "gadgets"},
+			},
+		},
+	}))
+	handler := WithBoundResourceListMetrics(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {}), boundResourceFunc(apiBindingIndexer))
+
+	tests := []struct {
+		name     string
+		cluster  string
+		resource string
+		verb     string
+		counted  bool
+	}{
+		{name: "list of bound resource", cluster: "root:org:ws", resource: "widgets", verb: "list", counted: true},
+		{name: "list of unbound resource", cluster: "root:org:other", resource: "widgets", verb: "list"},
+		{name: "list of resource still being bound", cluster: "root:org:ws", resource: "gadgets", verb: "list"},
+		{name: "get of bound resource", cluster: "root:org:ws", resource: "widgets", verb: "get"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			before, err := testutil.GetCounterMetricValue(boundResourceListRequests.WithLabelValues("example.com", tt.resource))
+			require.NoError(t, err)
+
+			req := httptest.NewRequest("GET", "/clusters/"+tt.cluster+"/apis/example.com/v1/"+tt.resource, nil)
+			ctx := request.WithCluster(req.Context(), request.Cluster{Name: logicalcluster.New(tt.cluster)})
+			ctx = request.WithRequestInfo(ctx, &request.RequestInfo{IsResourceRequest: true, Verb: tt.verb, APIGroup: "example.com", APIVersion: "v1", Resource: tt.resource})
+			handler.ServeHTTP(httptest.NewRecorder(), req.WithContext(ctx))
+
+			value, err := testutil.GetCounterMetricValue(boundResourceListRequests.WithLabelValues("example.com", tt.resource))
+			require.NoError(t, err)
+			expected := before
+			if tt.counted {
+				expected++
+			}
+			require.Equal(t, expected, value)
+		})
+	}
+}
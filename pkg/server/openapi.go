@@ -0,0 +1,217 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/kcp-dev/logicalcluster"
+
+	apiextensionshelpers "k8s.io/apiextensions-apiserver/pkg/apihelpers"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apiextensions-apiserver/pkg/controller/openapi/builder"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
+	utilcache "k8s.io/apimachinery/pkg/util/cache"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/apiserver/pkg/endpoints/handlers/responsewriters"
+	"k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/klog/v2"
+	"k8s.io/kube-openapi/pkg/handler"
+	"k8s.io/kube-openapi/pkg/validation/spec"
+)
+
+const (
+	openAPIV2Path = "/openapi/v2"
+
+	// clusterOpenAPICacheSize is the number of logical clusters whose merged OpenAPI spec is cached.
+	clusterOpenAPICacheSize = 1000
+	// clusterOpenAPICacheTTL is the time after which a cached merged OpenAPI spec is dropped, bounding
+	// the memory held for logical clusters that are not requested anymore.
+	clusterOpenAPICacheTTL = 30 * time.Minute
+)
+
+// clusterOpenAPI serves /openapi/v2 per logical cluster, merging the built-in types with the CRDs
+// served in the logical cluster, i.e. system CRDs, CRDs of bound APIs and local CRDs. The merged
+// spec is computed lazily on request and cached per logical cluster, keyed by a fingerprint of the
+// CRDs it is built from.
+type clusterOpenAPI struct {
+	// crdLister lists the CRDs served in a logical cluster. It is set once the server chain is created.
+	crdLister *apiBindingAwareCRDLister
+	// controlPlane serves the OpenAPI spec of the built-in types. It is set once the server
+	// chain is created. Requests are passed through until then.
+	controlPlane http.Handler
+
+	lock             sync.Mutex
+	controlPlaneSpec *spec.Swagger
+
+	// clusterSpecs holds a *clusterOpenAPIEntry per logical cluster.
+	clusterSpecs *utilcache.LRUExpireCache
+}
+
+type clusterOpenAPIEntry struct {
+	fingerprint string
+	handler     http.Handler
+}
+
+func newClusterOpenAPI() *clusterOpenAPI {
+	return &clusterOpenAPI{
+		clusterSpecs: utilcache.NewLRUExpireCache(clusterOpenAPICacheSize),
+	}
+}
+
+// WithClusterOpenAPI serves /openapi/v2 requests of a logical cluster from the given clusterOpenAPI.
+func WithClusterOpenAPI(apiHandler http.Handler, openAPI *clusterOpenAPI) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != openAPIV2Path || openAPI.controlPlane == nil {
+			apiHandler.ServeHTTP(w, req)
+			return
+		}
+
+		cluster := request.ClusterFrom(req.Context())
+		if cluster == nil || cluster.Name.Empty() || cluster.Wildcard {
+			apiHandler.ServeHTTP(w, req)
+			return
+		}
+
+		h, err := openAPI.handlerFor(cluster.Name)
+		if err != nil {
+			responsewriters.InternalError(w, req, err)
+			return
+		}
+		h.ServeHTTP(w, req)
+	}
+}
+
+// handlerFor returns the handler serving the merged OpenAPI spec of the given logical cluster,
+// building it if the CRDs of the logical cluster changed since it was last built.
+func (o *clusterOpenAPI) handlerFor(clusterName logicalcluster.Name) (http.Handler, error) {
+	staticSpec, err := o.getControlPlaneSpec()
+	if err != nil {
+		return nil, err
+	}
+
+	crds, err := o.crdLister.List(request.WithCluster(context.Background(), request.Cluster{Name: clusterName}), labels.Everything())
+	if err != nil {
+		return nil, fmt.Errorf("error listing CustomResourceDefinitions: %w", err)
+	}
+	var established []*apiextensionsv1.CustomResourceDefinition
+	for _, crd := range crds {
+		if apiextensionshelpers.IsCRDConditionTrue(crd, apiextensionsv1.Established) {
+			established = append(established, crd)
+		}
+	}
+
+	fingerprint := crdsFingerprint(established)
+	if entry, found := o.clusterSpecs.Get(clusterName); found && entry.(*clusterOpenAPIEntry).fingerprint == fingerprint {
+		return entry.(*clusterOpenAPIEntry).handler, nil
+	}
+
+	var crdSpecs []*spec.Swagger
+	for _, crd := range established {
+		for _, v := range crd.Spec.Versions {
+			if !v.Served {
+				continue
+			}
+			crdSpec, err := builder.BuildOpenAPIV2(crd, v.Name, builder.Options{V2: true})
+			if err != nil {
+				klog.Errorf("Failed to build OpenAPI spec of CRD %s|%s version %s: %v", logicalcluster.From(crd), crd.Name, v.Name, err)
+				continue
+			}
+			crdSpecs = append(crdSpecs, crdSpec)
+		}
+	}
+	mergedSpec, err := builder.MergeSpecs(staticSpec, crdSpecs...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to merge OpenAPI specs: %w", err)
+	}
+
+	openAPIService, err := handler.NewOpenAPIService(mergedSpec)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build OpenAPI service: %w", err)
+	}
+	h := &singlePathHandler{}
+	if err := openAPIService.RegisterOpenAPIVersionedService(openAPIV2Path, h); err != nil {
+		return nil, fmt.Errorf("failed to register OpenAPI service: %w", err)
+	}
+
+	klog.V(4).Infof("Built OpenAPI spec of logical cluster %s from %d CRDs", clusterName, len(established))
+	o.clusterSpecs.Add(clusterName, &clusterOpenAPIEntry{fingerprint: fingerprint, handler: h}, clusterOpenAPICacheTTL)
+	return h, nil
+}
+
+// getControlPlaneSpec returns the OpenAPI spec of the built-in types. It is downloaded once
+// as it does not change at runtime.
+func (o *clusterOpenAPI) getControlPlaneSpec() (*spec.Swagger, error) {
+	o.lock.Lock()
+	defer o.lock.Unlock()
+
+	if o.controlPlaneSpec != nil {
+		return o.controlPlaneSpec, nil
+	}
+
+	req, err := http.NewRequest(http.MethodGet, openAPIV2Path, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Add("Accept", "application/json")
+	req = req.WithContext(request.WithUser(req.Context(), &user.DefaultInfo{Name: user.APIServerUser, Groups: []string{user.SystemPrivilegedGroup}}))
+
+	writer := newInMemoryResponseWriter()
+	o.controlPlane.ServeHTTP(writer, req)
+	if writer.respCode != http.StatusOK {
+		return nil, apierrors.NewInternalError(fmt.Errorf("unable to get the OpenAPI spec of built-in types: %s", writer.String()))
+	}
+
+	var controlPlaneSpec spec.Swagger
+	if err := json.Unmarshal(writer.data, &controlPlaneSpec); err != nil {
+		return nil, fmt.Errorf("failed to decode the OpenAPI spec of built-in types: %w", err)
+	}
+	o.controlPlaneSpec = &controlPlaneSpec
+	return o.controlPlaneSpec, nil
+}
+
+// crdsFingerprint identifies the given set of CRDs and their versions.
+func crdsFingerprint(crds []*apiextensionsv1.CustomResourceDefinition) string {
+	keys := make([]string, 0, len(crds))
+	for _, crd := range crds {
+		keys = append(keys, fmt.Sprintf("%s|%s|%s|%s", logicalcluster.From(crd), crd.Name, crd.UID, crd.ResourceVersion))
+	}
+	sort.Strings(keys)
+
+	h := sha256.New()
+	for _, key := range keys {
+		fmt.Fprintln(h, key)
+	}
+	return fmt.Sprintf("%x", h.Sum(nil))
+}
+
+// singlePathHandler grabs the http.Handler registered by the OpenAPI service for a single path.
+type singlePathHandler struct {
+	http.Handler
+}
+
+func (h *singlePathHandler) Handle(_ string, handler http.Handler) {
+	h.Handler = handler
+}
@@ -0,0 +1,42 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+func TestCRDsFingerprint(t *testing.T) {
+	crd := func(clusterName, name, uid, resourceVersion string) *apiextensionsv1.CustomResourceDefinition {
+		return &apiextensionsv1.CustomResourceDefinition{
+			ObjectMeta: metav1.ObjectMeta{ClusterName: clusterName, Name: name, UID: types.UID(uid), ResourceVersion: resourceVersion},
+		}
+	}
+	a := crd("root:org:ws", "cowboys.wildwest.dev", "uid-a", "1")
+	b := crd("system:bound-crds", "uid-b", "uid-b", "5")
+
+	require.Equal(t, crdsFingerprint([]*apiextensionsv1.CustomResourceDefinition{a, b}), crdsFingerprint([]*apiextensionsv1.CustomResourceDefinition{b, a}), "order must not matter")
+	require.NotEqual(t, crdsFingerprint([]*apiextensionsv1.CustomResourceDefinition{a, b}), crdsFingerprint([]*apiextensionsv1.CustomResourceDefinition{a}), "removed CRD must change the fingerprint")
+	require.NotEqual(t, crdsFingerprint([]*apiextensionsv1.CustomResourceDefinition{a, b}), crdsFingerprint([]*apiextensionsv1.CustomResourceDefinition{a, crd("system:bound-crds", "uid-b", "uid-b", "6")}), "updated CRD must change the fingerprint")
+	require.NotEqual(t, crdsFingerprint([]*apiextensionsv1.CustomResourceDefinition{a}), crdsFingerprint([]*apiextensionsv1.CustomResourceDefinition{crd("root:org:ws", "cowboys.wildwest.dev", "uid-c", "1")}), "recreated CRD must change the fingerprint")
+}
@@ -107,8 +107,13 @@ func (s *AdminAuthentication) ApplyTo(config *genericapiserver.Config) (newToken
 	return newTokenOrEmpty, tokenHash, nil
 }
 
-func (s *AdminAuthentication) WriteKubeConfig(config *genericapiserver.Config, newToken string, tokenHash []byte) error {
-	externalCACert, _ := config.SecureServing.Cert.CurrentCertKeyContent()
+// WriteKubeConfig writes the admin kubeconfig. The given CA data is used to verify the server,
+// or the serving certificate if it is empty.
+func (s *AdminAuthentication) WriteKubeConfig(config *genericapiserver.Config, newToken string, tokenHash []byte, caData []byte) error {
+	externalCACert := caData
+	if len(externalCACert) == 0 {
+		externalCACert, _ = config.SecureServing.Cert.CurrentCertKeyContent()
+	}
 	externalKubeConfigHost := fmt.Sprintf("https://%s", config.ExternalAddress)
 
 	externalAdminUserName := "admin"
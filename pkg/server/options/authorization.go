@@ -17,19 +17,41 @@ limitations under the License.
 package options
 
 import (
+	"fmt"
+	"strings"
+	"time"
+
 	"github.com/spf13/pflag"
 
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/apiserver/pkg/authorization/authorizer"
 	"k8s.io/apiserver/pkg/authorization/authorizerfactory"
 	"k8s.io/apiserver/pkg/authorization/path"
 	"k8s.io/apiserver/pkg/authorization/union"
 	genericapiserver "k8s.io/apiserver/pkg/server"
+	"k8s.io/apiserver/plugin/pkg/authorizer/webhook"
 	coreexternalversions "k8s.io/client-go/informers"
 
 	"github.com/kcp-dev/kcp/pkg/authorization"
+	apisinformers "github.com/kcp-dev/kcp/pkg/client/informers/externalversions/apis/v1alpha1"
 	"github.com/kcp-dev/kcp/pkg/client/listers/tenancy/v1alpha1"
 )
 
+const (
+	// AlwaysAllowGroupsAuthorizer allows everything to the AlwaysAllowGroups, e.g. system:masters.
+	AlwaysAllowGroupsAuthorizer = "AlwaysAllowGroups"
+	// AlwaysAllowPathsAuthorizer allows the --authorization-always-allow-paths to everybody.
+	AlwaysAllowPathsAuthorizer = "AlwaysAllowPaths"
+	// WebhookAuthorizer asks the SubjectAccessReview webhook of --authorization-webhook-config-file. The logical
+	// cluster of the request is passed in the extra of the review, see authorization.ClusterNameExtraKey.
+	WebhookAuthorizer = "Webhook"
+	// KCPAuthorizer is the chain of kcp authorizers, see docs/authorization.md.
+	KCPAuthorizer = "KCP"
+)
+
+var knownAuthorizers = sets.NewString(AlwaysAllowGroupsAuthorizer, AlwaysAllowPathsAuthorizer, WebhookAuthorizer, KCPAuthorizer)
+
 type Authorization struct {
 	// AlwaysAllowPaths are HTTP paths which are excluded from authorization. They can be plain
 	// paths or end in * in which case prefix-match is applied. A leading / is optional.
@@ -37,6 +59,23 @@ type Authorization struct {
 
 	// AlwaysAllowGroups are groups which are allowed to take any actions.  In kube, this is system:masters.
 	AlwaysAllowGroups []string
+
+	// Chain is the ordered list of authorizers asked for a decision. The first authorizer
+	// allowing or denying a request decides. It must contain the KCP authorizer.
+	Chain []string
+
+	// DisableBootstrapPolicy removes the Kubernetes bootstrap policy authorizer from the kcp
+	// authorizers, such that only the local RBAC policy of workspaces applies.
+	DisableBootstrapPolicy bool
+
+	// WebhookConfigFile is a kubeconfig file of the SubjectAccessReview webhook of the Webhook authorizer.
+	WebhookConfigFile string
+	// WebhookVersion is the version of the SubjectAccessReview API sent to the webhook.
+	WebhookVersion string
+	// WebhookCacheAuthorizedTTL is the duration to cache allowed responses of the webhook.
+	WebhookCacheAuthorizedTTL time.Duration
+	// WebhookCacheUnauthorizedTTL is the duration to cache denied responses of the webhook.
+	WebhookCacheUnauthorizedTTL time.Duration
 }
 
 func NewAuthorization() *Authorization {
@@ -45,6 +84,12 @@ func NewAuthorization() *Authorization {
 		// This field can be cleared by callers if they don't want this behavior.
 		AlwaysAllowPaths:  []string{"/healthz", "/readyz", "/livez"},
 		AlwaysAllowGroups: []string{"system:masters"},
+
+		Chain: []string{AlwaysAllowGroupsAuthorizer, AlwaysAllowPathsAuthorizer, KCPAuthorizer},
+
+		WebhookVersion:              "v1beta1",
+		WebhookCacheAuthorizedTTL:   5 * time.Minute,
+		WebhookCacheUnauthorizedTTL: 30 * time.Second,
 	}
 }
 
@@ -67,6 +112,28 @@ func (s *Authorization) Validate() []error {
 
 	allErrors := []error{}
 
+	seen := sets.NewString()
+	for _, name := range s.Chain {
+		if !knownAuthorizers.Has(name) {
+			allErrors = append(allErrors, fmt.Errorf("--authorization-chain contains unknown authorizer %q, must be one of %s", name, strings.Join(knownAuthorizers.List(), ", ")))
+		} else if seen.Has(name) {
+			allErrors = append(allErrors, fmt.Errorf("--authorization-chain contains %q more than once", name))
+		}
+		seen.Insert(name)
+	}
+	if !seen.Has(KCPAuthorizer) {
+		allErrors = append(allErrors, fmt.Errorf("--authorization-chain must contain %s", KCPAuthorizer))
+	}
+	if seen.Has(WebhookAuthorizer) && s.WebhookConfigFile == "" {
+		allErrors = append(allErrors, fmt.Errorf("--authorization-webhook-config-file is required if --authorization-chain contains %s", WebhookAuthorizer))
+	}
+	if !seen.Has(WebhookAuthorizer) && s.WebhookConfigFile != "" {
+		allErrors = append(allErrors, fmt.Errorf("--authorization-webhook-config-file must be empty if --authorization-chain does not contain %s", WebhookAuthorizer))
+	}
+	if s.WebhookVersion != "v1" && s.WebhookVersion != "v1beta1" {
+		allErrors = append(allErrors, fmt.Errorf("--authorization-webhook-version must be v1 or v1beta1"))
+	}
+
 	return allErrors
 }
 
@@ -78,37 +145,96 @@ func (s *Authorization) AddFlags(fs *pflag.FlagSet) {
 	fs.StringSliceVar(&s.AlwaysAllowPaths, "authorization-always-allow-paths", s.AlwaysAllowPaths,
 		"A list of HTTP paths to skip during authorization, i.e. these are authorized without "+
 			"contacting the 'core' kubernetes server.")
+
+	fs.StringSliceVar(&s.Chain, "authorization-chain", s.Chain,
+		"Ordered list of authorizers asked for a decision. The first authorizer allowing or denying a request decides. "+
+			"Known authorizers are "+strings.Join(knownAuthorizers.List(), ", ")+".")
+	fs.BoolVar(&s.DisableBootstrapPolicy, "authorization-disable-bootstrap-policy", s.DisableBootstrapPolicy,
+		"Disable the Kubernetes bootstrap policy authorizer of the KCP authorizers, i.e. only RBAC rules in the workspaces apply.")
+	fs.StringVar(&s.WebhookConfigFile, "authorization-webhook-config-file", s.WebhookConfigFile,
+		"File with webhook configuration in kubeconfig format of the Webhook authorizer. The API server will query the remote service to determine access on the API server's secure port.")
+	fs.StringVar(&s.WebhookVersion, "authorization-webhook-version", s.WebhookVersion,
+		"The API version of the authorization.k8s.io SubjectAccessReview to send to and expect from the webhook.")
+	fs.DurationVar(&s.WebhookCacheAuthorizedTTL, "authorization-webhook-cache-authorized-ttl", s.WebhookCacheAuthorizedTTL,
+		"The duration to cache 'authorized' responses from the webhook authorizer.")
+	fs.DurationVar(&s.WebhookCacheUnauthorizedTTL, "authorization-webhook-cache-unauthorized-ttl", s.WebhookCacheUnauthorizedTTL,
+		"The duration to cache 'unauthorized' responses from the webhook authorizer.")
 }
 
-func (s *Authorization) ApplyTo(config *genericapiserver.Config, informer coreexternalversions.SharedInformerFactory, workspaceLister v1alpha1.ClusterWorkspaceLister) error {
+func (s *Authorization) ApplyTo(config *genericapiserver.Config, informer coreexternalversions.SharedInformerFactory, workspaceLister v1alpha1.ClusterWorkspaceLister, secretShareInformer apisinformers.SecretShareInformer) error {
 	var authorizers []authorizer.Authorizer
-
-	// group authorizer
-	if len(s.AlwaysAllowGroups) > 0 {
-		authorizers = append(authorizers, authorizerfactory.NewPrivilegedGroups(s.AlwaysAllowGroups...))
-	}
-
-	// path authorizer
-	if len(s.AlwaysAllowPaths) > 0 {
-		a, err := path.NewAuthorizer(s.AlwaysAllowPaths)
-		if err != nil {
-			return err
+	var ruleResolvers []authorizer.RuleResolver
+
+	for _, name := range s.Chain {
+		switch name {
+		case AlwaysAllowGroupsAuthorizer:
+			if len(s.AlwaysAllowGroups) > 0 {
+				authorizers = append(authorizers, authorizerfactory.NewPrivilegedGroups(s.AlwaysAllowGroups...))
+			}
+
+		case AlwaysAllowPathsAuthorizer:
+			if len(s.AlwaysAllowPaths) > 0 {
+				a, err := path.NewAuthorizer(s.AlwaysAllowPaths)
+				if err != nil {
+					return err
+				}
+				authorizers = append(authorizers, a)
+			}
+
+		case WebhookAuthorizer:
+			a, err := webhook.New(s.WebhookConfigFile, s.WebhookVersion, s.WebhookCacheAuthorizedTTL, s.WebhookCacheUnauthorizedTTL, wait.Backoff{
+				Duration: 500 * time.Millisecond,
+				Factor:   1.5,
+				Jitter:   0.2,
+				Steps:    5,
+			}, nil)
+			if err != nil {
+				return fmt.Errorf("failed to create webhook authorizer: %w", err)
+			}
+			// the webhook is not aware of logical clusters, i.e. the cluster is passed in the extra of the review.
+			authorizers = append(authorizers, authorization.NewClusterAwareAuthorizer(a))
+			ruleResolvers = append(ruleResolvers, a)
+
+		case KCPAuthorizer:
+			a, resolvers, err := s.newKCPAuthorizer(informer, workspaceLister, secretShareInformer)
+			if err != nil {
+				return err
+			}
+			authorizers = append(authorizers, a)
+			ruleResolvers = append(ruleResolvers, resolvers...)
+
+		default:
+			return fmt.Errorf("unknown authorizer %q", name) // shouldn't happen due to options validation
 		}
-		authorizers = append(authorizers, a)
 	}
 
-	// kcp authorizers
-	bootstrapAuth, bootstrapRules := authorization.NewBootstrapPolicyAuthorizer(informer)
+	config.RuleResolver = union.NewRuleResolvers(ruleResolvers...)
+	config.Authorization.Authorizer = union.New(authorizers...)
+	return nil
+}
+
+// newKCPAuthorizer returns the chain of kcp authorizers as described in docs/authorization.md.
+func (s *Authorization) newKCPAuthorizer(informer coreexternalversions.SharedInformerFactory, workspaceLister v1alpha1.ClusterWorkspaceLister, secretShareInformer apisinformers.SecretShareInformer) (authorizer.Authorizer, []authorizer.RuleResolver, error) {
 	localAuth, localResolver := authorization.NewLocalAuthorizer(informer)
-	authorizers = append(authorizers,
-		authorization.NewTopLevelOrganizationAccessAuthorizer(informer, workspaceLister,
-			authorization.NewWorkspaceContentAuthorizer(informer, workspaceLister,
-				union.New(bootstrapAuth, localAuth),
+	policyAuthorizers := []authorizer.Authorizer{localAuth}
+	ruleResolvers := []authorizer.RuleResolver{localResolver}
+	if !s.DisableBootstrapPolicy {
+		bootstrapAuth, bootstrapRules := authorization.NewBootstrapPolicyAuthorizer(informer)
+		policyAuthorizers = append([]authorizer.Authorizer{bootstrapAuth}, policyAuthorizers...)
+		ruleResolvers = append([]authorizer.RuleResolver{bootstrapRules}, ruleResolvers...)
+	}
+
+	secretShareAuth, err := authorization.NewSecretShareAuthorizer(secretShareInformer,
+		authorization.NewSubtreeImpersonationAuthorizer(s.AlwaysAllowGroups,
+			authorization.NewTopLevelOrganizationAccessAuthorizer(informer, workspaceLister,
+				authorization.NewWorkspaceContentAuthorizer(informer, workspaceLister,
+					union.New(policyAuthorizers...),
+				),
 			),
 		),
 	)
-
-	config.RuleResolver = union.NewRuleResolvers(bootstrapRules, localResolver)
-	config.Authorization.Authorizer = union.New(authorizers...)
-	return nil
+	if err != nil {
+		return nil, nil, err
+	}
+	return secretShareAuth, ruleResolvers, nil
 }
@@ -0,0 +1,50 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package options
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuthorizationValidate(t *testing.T) {
+	tests := map[string]struct {
+		chain             []string
+		webhookConfigFile string
+		wantErrs          int
+	}{
+		"defaults":                    {},
+		"reordered":                   {chain: []string{KCPAuthorizer, AlwaysAllowGroupsAuthorizer}},
+		"webhook":                     {chain: []string{AlwaysAllowGroupsAuthorizer, WebhookAuthorizer, KCPAuthorizer}, webhookConfigFile: "webhook.kubeconfig"},
+		"webhook without config file": {chain: []string{WebhookAuthorizer, KCPAuthorizer}, wantErrs: 1},
+		"config file without webhook": {chain: []string{KCPAuthorizer}, webhookConfigFile: "webhook.kubeconfig", wantErrs: 1},
+		"unknown authorizer":          {chain: []string{"RBAC", KCPAuthorizer}, wantErrs: 1},
+		"duplicate authorizer":        {chain: []string{KCPAuthorizer, KCPAuthorizer}, wantErrs: 1},
+		"without kcp authorizer":      {chain: []string{AlwaysAllowGroupsAuthorizer, AlwaysAllowPathsAuthorizer}, wantErrs: 1},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			o := NewAuthorization()
+			if tt.chain != nil {
+				o.Chain = tt.chain
+			}
+			o.WebhookConfigFile = tt.webhookConfigFile
+			require.Len(t, o.Validate(), tt.wantErrs)
+		})
+	}
+}
@@ -29,20 +29,35 @@ import (
 	"k8s.io/klog/v2"
 	kcmoptions "k8s.io/kubernetes/cmd/kube-controller-manager/app/options"
 
+	"github.com/kcp-dev/kcp/pkg/reconciler/apis/apiexportusage"
 	"github.com/kcp-dev/kcp/pkg/reconciler/apis/apiresource"
+	"github.com/kcp-dev/kcp/pkg/reconciler/tenancy/storagewatchdog"
+	"github.com/kcp-dev/kcp/pkg/reconciler/tenancy/workspacesource"
+	"github.com/kcp-dev/kcp/pkg/reconciler/tenancy/workspacettl"
 	"github.com/kcp-dev/kcp/pkg/reconciler/workload/heartbeat"
+	"github.com/kcp-dev/kcp/pkg/reconciler/workload/namespace"
 )
 
 type Controllers struct {
 	EnableAll                bool
 	IndividuallyEnabled      []string
 	ApiResource              ApiResourceController
+	APIExportUsage           APIExportUsageController
 	WorkloadClusterHeartbeat WorkloadClusterHeartbeatController
+	NamespaceScheduler       NamespaceSchedulerController
+	StorageWatchdog          StorageWatchdogController
+	WorkspaceTTL             WorkspaceTTLController
+	WorkspaceSource          WorkspaceSourceController
 	SAController             kcmoptions.SAControllerOptions
 }
 
 type ApiResourceController = apiresource.Options
+type APIExportUsageController = apiexportusage.Options
 type WorkloadClusterHeartbeatController = heartbeat.Options
+type NamespaceSchedulerController = namespace.Options
+type StorageWatchdogController = storagewatchdog.Options
+type WorkspaceTTLController = workspacettl.Options
+type WorkspaceSourceController = workspacesource.Options
 
 var kcmDefaults *kcmoptions.KubeControllerManagerOptions
 
@@ -60,7 +75,12 @@ func NewControllers() *Controllers {
 		EnableAll: true,
 
 		ApiResource:              *apiresource.DefaultOptions(),
+		APIExportUsage:           *apiexportusage.DefaultOptions(),
 		WorkloadClusterHeartbeat: *heartbeat.DefaultOptions(),
+		NamespaceScheduler:       *namespace.DefaultOptions(),
+		StorageWatchdog:          *storagewatchdog.DefaultOptions(),
+		WorkspaceTTL:             *workspacettl.DefaultOptions(),
+		WorkspaceSource:          *workspacesource.DefaultOptions(),
 		SAController:             *kcmDefaults.SAController,
 	}
 }
@@ -72,7 +92,12 @@ func (c *Controllers) AddFlags(fs *pflag.FlagSet) {
 	fs.MarkHidden("unsupported-run-individual-controllers") //nolint:errcheck
 
 	apiresource.BindOptions(&c.ApiResource, fs)
+	apiexportusage.BindOptions(&c.APIExportUsage, fs)
 	heartbeat.BindOptions(&c.WorkloadClusterHeartbeat, fs)
+	namespace.BindOptions(&c.NamespaceScheduler, fs)
+	storagewatchdog.BindOptions(&c.StorageWatchdog, fs)
+	workspacettl.BindOptions(&c.WorkspaceTTL, fs)
+	workspacesource.BindOptions(&c.WorkspaceSource, fs)
 
 	c.SAController.AddFlags(fs)
 }
@@ -109,9 +134,24 @@ func (c *Controllers) Validate() []error {
 	if err := c.ApiResource.Validate(); err != nil {
 		errs = append(errs, err)
 	}
+	if err := c.APIExportUsage.Validate(); err != nil {
+		errs = append(errs, err)
+	}
 	if err := c.WorkloadClusterHeartbeat.Validate(); err != nil {
 		errs = append(errs, err)
 	}
+	if err := c.NamespaceScheduler.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+	if err := c.StorageWatchdog.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+	if err := c.WorkspaceTTL.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+	if err := c.WorkspaceSource.Validate(); err != nil {
+		errs = append(errs, err)
+	}
 	if saErrs := c.SAController.Validate(); saErrs != nil {
 		errs = append(errs, saErrs...)
 	}
@@ -37,6 +37,8 @@ var (
 		"KCP Authorization",
 		"KCP Virtual Workspaces",
 		"KCP Controllers",
+		"KCP Shard Identity",
+		"KCP Secret Store",
 		"KCP",
 	}
 
@@ -97,7 +99,13 @@ var (
 		"token-auth-file",                    // If set, the file that will be used to secure the secure port of the API server via token authentication.
 
 		// KCP Authorization flags
-		"authorization-always-allow-paths", // A list of HTTP paths to skip during authorization, i.e. these are authorized without contacting the 'core' kubernetes server.
+		"authorization-always-allow-paths",             // A list of HTTP paths to skip during authorization, i.e. these are authorized without contacting the 'core' kubernetes server.
+		"authorization-chain",                          // Ordered list of authorizers asked for a decision. The first authorizer allowing or denying a request decides.
+		"authorization-disable-bootstrap-policy",       // Disable the Kubernetes bootstrap policy authorizer of the KCP authorizers, i.e. only RBAC rules in the workspaces apply.
+		"authorization-webhook-cache-authorized-ttl",   // The duration to cache 'authorized' responses from the webhook authorizer.
+		"authorization-webhook-cache-unauthorized-ttl", // The duration to cache 'unauthorized' responses from the webhook authorizer.
+		"authorization-webhook-config-file",            // File with webhook configuration in kubeconfig format of the Webhook authorizer.
+		"authorization-webhook-version",                // The API version of the authorization.k8s.io SubjectAccessReview to send to and expect from the webhook.
 
 		// KCP Admin Authentication flags
 		"authentication-admin-token-path", // Path to which the administrative token hash should be written at startup. If this is relative, it is relative to --root-directory.
@@ -127,12 +135,38 @@ var (
 		"tracing-config-file", // File with apiserver tracing configuration.
 
 		// KCP flags
-		"discovery-poll-interval",     // Polling interval for dynamic discovery informers.
-		"enable-sharding",             // Enable delegating to peer kcp shards.
-		"profiler-address",            // [Address]:port to bind the profiler to
-		"root-directory",              // Root directory.
-		"shard-kubeconfig-file",       // Kubeconfig holding admin(!) credentials to peer kcp shards.
-		"experimental-bind-free-port", // Bind to a free port. --secure-bind-port must be 0. Use the admin.kubeconfig to extract the chosen port.
+		"bootstrap-template-vars",       // Variables of the templates of the resources bootstrapped in the root workspace.
+		"cache-server-kubeconfig-file",  // Kubeconfig of the cache server, through which controllers resolve objects of other shards.
+		"discovery-poll-interval",       // Polling interval for dynamic discovery informers.
+		"dynamic-config-file",           // File with feature gates and log verbosity (featureGates, verbosity, vmodule) applied on startup and re-read on SIGHUP.
+		"enable-sharding",               // Enable delegating to peer kcp shards.
+		"force-bootstrap-reconcile",     // Update bootstrapped resources on startup even if their content did not change, overwriting manual changes.
+		"informer-bookmark-directory",   // Directory in which the dynamic discovery informers persist their objects and resourceVersion to resume after a restart.
+		"max-wildcard-watches-per-user", // Maximal number of concurrent watches in the * logical cluster per user.
+		"profiler-address",              // [Address]:port to bind the profiler to
+		"root-directory",                // Root directory.
+		"shard-kubeconfig-file",         // Kubeconfig holding admin(!) credentials to peer kcp shards.
+		"slow-request-threshold",        // Log non-long-running requests taking longer than this, with workspace, user, verb, resource and duration.
+		"workspace-request-timeouts",    // Timeouts of non-long-running requests to a workspace and its descendants, e.g. root:org=10s.
+		"experimental-bind-free-port",   // Bind to a free port. --secure-bind-port must be 0. Use the admin.kubeconfig to extract the chosen port.
+
+		// KCP Shard Identity flags
+		"shard-identity",               // Issue the serving certificate and a client certificate for peer shards from the kcp shard CA.
+		"shard-ca-cert-file",           // File with the kcp shard CA certificate.
+		"shard-ca-key-file",            // File with the private key of the kcp shard CA.
+		"front-proxy-ca-cert-file",     // File with the CA certificate of the client certificate of the kcp-front-proxy.
+		"front-proxy-ca-key-file",      // File with the private key of the front-proxy CA.
+		"shard-identity-cert-validity", // Validity of the certificates issued from the kcp shard CA.
+		"shard-identity-extra-hosts",   // Additional host names and IPs of the serving certificate issued from the kcp shard CA.
+
+		// KCP Secret Store flags
+		"secret-store",                  // Where kcp-managed credentials are stored. One of etcd, vault, plugin. "etcd" keeps them in Secret objects.
+		"secret-store-vault-address",    // URL of the Vault server of --secret-store=vault.
+		"secret-store-vault-token-file", // File with the Vault token. It is read for every request, such that renewed tokens are picked up.
+		"secret-store-vault-ca-file",    // File with the CA certificates to verify the Vault server. The system CAs are used if empty.
+		"secret-store-vault-mount",      // Mount path of the KV version 2 secrets engine in Vault.
+		"secret-store-vault-prefix",     // Prefix of the paths of the secrets in the Vault secrets engine.
+		"secret-store-plugin-socket",    // Unix socket of the secret store plugin of --secret-store=plugin.
 
 		// secure serving flags
 		"bind-address",                     // The IP address on which to listen for the --secure-port port. The associated interface(s) must be reachable by the rest of the cluster, and by CLI/web clients. If blank or an unspecified address (0.0.0.0 or ::), all interfaces will be used.
@@ -155,11 +189,21 @@ var (
 
 		// KCP Controllers flags
 		"auto-publish-apis",                      // If true, the APIs imported from physical clusters will be published automatically as CRDs
+		"auto-publish-api-groups",                // API groups of APIs imported from physical clusters which are published automatically as CRDs if --auto-publish-apis is false.
+		"apiexport-usage-interval",               // Minimal time between two scans of the objects bound through an APIExport for its usage status
+		"apiexport-usage-metrics",                // Export the usage of every APIExport also as metrics, labelled by workspace and name of the APIExport. Only enable with a bounded number of APIExports
 		"apiresource-controller-threads",         // Number of threads to use for the apiresource controller.
+		"namespace-scheduler-rebalance-budget",   // Maximal number of namespaces of a workspace moved to another workload cluster per --namespace-scheduler-rebalance-interval.
+		"namespace-scheduler-rebalance-interval", // Minimal time between two rebalancings of the namespaces of a workspace.
+		"namespace-scheduler-rebalance-policy",   // Whether scheduled namespaces are moved to workload clusters becoming schedulable. One of None, Spread.
 		"run-controllers",                        // Run the controllers in-process
 		"run-virtual-workspaces",                 // Run the virtual workspaces apiservers in-process
 		"unsupported-run-individual-controllers", // Run individual controllers in-process. The controller names can change at any time.
 		"workload-cluster-heartbeat-threshold",   // Amount of time to wait for a successful heartbeat before marking the cluster as not ready.
+		"workspace-expiration-warning",           // Time ahead of the expiration of a workspace with a ttl from which on its LifetimeRemaining condition warns about the expiration
+		"workspace-max-objects",                  // Number of objects in a workspace above which creates in the workspace are rejected. 0 means no limit
+		"workspace-source-allowed-hosts",         // Hosts of the Git repositories WorkspaceSources may fetch from, e.g. github.com or *.example.com for all subdomains. Repositories on other hosts are not fetched. Empty means none.
+		"workspace-storage-watchdog-interval",    // Minimal time between two scans of the objects of a workspace for its usage status
 
 		// generic flags
 		"cors-allowed-origins",                 // List of allowed origins for CORS, comma separated.  An allowed origin can be a regular expression to support subdomain matching. If this list is empty CORS will not be enabled.
@@ -25,6 +25,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/kcp-dev/logicalcluster"
 	"github.com/spf13/pflag"
 
 	"k8s.io/apimachinery/pkg/util/sets"
@@ -35,8 +36,10 @@ import (
 	kubeoptions "k8s.io/kubernetes/pkg/kubeapiserver/options"
 
 	kcpadmission "github.com/kcp-dev/kcp/pkg/admission"
+	tenancypath "github.com/kcp-dev/kcp/pkg/apis/tenancy/path"
 	_ "github.com/kcp-dev/kcp/pkg/features"
 	kcpfeatures "github.com/kcp-dev/kcp/pkg/features"
+	secretstoreoptions "github.com/kcp-dev/kcp/pkg/secretstore/options"
 )
 
 type Options struct {
@@ -46,17 +49,29 @@ type Options struct {
 	Authorization       Authorization
 	AdminAuthentication AdminAuthentication
 	Virtual             Virtual
+	ShardIdentity       ShardIdentity
+	SecretStore         secretstoreoptions.Options
 
 	Extra ExtraOptions
 }
 
 type ExtraOptions struct {
-	RootDirectory            string
-	ProfilerAddress          string
-	ShardKubeconfigFile      string
-	EnableSharding           bool
-	DiscoveryPollInterval    time.Duration
-	ExperimentalBindFreePort bool
+	RootDirectory             string
+	ProfilerAddress           string
+	ShardKubeconfigFile       string
+	CacheServerKubeconfigFile string
+	EnableSharding            bool
+	DiscoveryPollInterval     time.Duration
+	ExperimentalBindFreePort  bool
+	ForceBootstrapReconcile   bool
+	BootstrapTemplateVars     map[string]string
+	DynamicConfigFile         string
+	InformerBookmarkDirectory string
+
+	MaxWildcardWatchesPerUser int
+
+	WorkspaceRequestTimeouts map[string]string
+	SlowRequestThreshold     time.Duration
 }
 
 type completedOptions struct {
@@ -66,6 +81,8 @@ type completedOptions struct {
 	Authorization       Authorization
 	AdminAuthentication AdminAuthentication
 	Virtual             Virtual
+	ShardIdentity       ShardIdentity
+	SecretStore         secretstoreoptions.Options
 
 	Extra ExtraOptions
 }
@@ -85,14 +102,23 @@ func NewOptions() *Options {
 		Authorization:       *NewAuthorization(),
 		AdminAuthentication: *NewAdminAuthentication(),
 		Virtual:             *NewVirtual(),
+		ShardIdentity:       *NewShardIdentity(),
+		SecretStore:         *secretstoreoptions.NewOptions(),
 
 		Extra: ExtraOptions{
-			RootDirectory:            ".kcp",
-			ProfilerAddress:          "",
-			ShardKubeconfigFile:      "",
-			EnableSharding:           false,
-			DiscoveryPollInterval:    60 * time.Second,
-			ExperimentalBindFreePort: false,
+			RootDirectory:             ".kcp",
+			ProfilerAddress:           "",
+			ShardKubeconfigFile:       "",
+			CacheServerKubeconfigFile: "",
+			EnableSharding:            false,
+			DiscoveryPollInterval:     60 * time.Second,
+			ExperimentalBindFreePort:  false,
+			ForceBootstrapReconcile:   false,
+			BootstrapTemplateVars:     map[string]string{},
+			DynamicConfigFile:         "",
+
+			WorkspaceRequestTimeouts:  map[string]string{},
+			MaxWildcardWatchesPerUser: 100,
 		},
 	}
 
@@ -143,14 +169,24 @@ func (o *Options) rawFlags() cliflag.NamedFlagSets {
 	o.Authorization.AddFlags(fss.FlagSet("KCP Authorization"))
 	o.AdminAuthentication.AddFlags(fss.FlagSet("KCP Authentication"))
 	o.Virtual.AddFlags(fss.FlagSet("KCP Virtual Workspaces"))
+	o.ShardIdentity.AddFlags(fss.FlagSet("KCP Shard Identity"))
+	o.SecretStore.AddFlags(fss.FlagSet("KCP Secret Store"))
 
 	fs := fss.FlagSet("KCP")
 	fs.StringVar(&o.Extra.ProfilerAddress, "profiler-address", o.Extra.ProfilerAddress, "[Address]:port to bind the profiler to")
 	fs.StringVar(&o.Extra.ShardKubeconfigFile, "shard-kubeconfig-file", o.Extra.ShardKubeconfigFile, "Kubeconfig holding admin(!) credentials to peer kcp shards.")
+	fs.StringVar(&o.Extra.CacheServerKubeconfigFile, "cache-server-kubeconfig-file", o.Extra.CacheServerKubeconfigFile, "Kubeconfig of the cache server, through which controllers resolve objects of other shards, e.g. APIExports and ClusterWorkspaceShards. Without it, they are resolved by live requests.")
 	fs.BoolVar(&o.Extra.EnableSharding, "enable-sharding", o.Extra.EnableSharding, "Enable delegating to peer kcp shards.")
 	fs.StringVar(&o.Extra.RootDirectory, "root-directory", o.Extra.RootDirectory, "Root directory.")
 	fs.DurationVar(&o.Extra.DiscoveryPollInterval, "discovery-poll-interval", o.Extra.DiscoveryPollInterval, "Polling interval for dynamic discovery informers.")
-
+	fs.StringVar(&o.Extra.InformerBookmarkDirectory, "informer-bookmark-directory", o.Extra.InformerBookmarkDirectory, "Directory in which the dynamic discovery informers persist their objects and resourceVersion every minute and on shutdown, to resume their watches after a restart instead of relisting every logical cluster. Relative to --root-directory. Empty disables persistence.")
+	fs.BoolVar(&o.Extra.ForceBootstrapReconcile, "force-bootstrap-reconcile", o.Extra.ForceBootstrapReconcile, "Update bootstrapped resources on startup even if their content did not change, overwriting manual changes.")
+	fs.StringToStringVar(&o.Extra.BootstrapTemplateVars, "bootstrap-template-vars", o.Extra.BootstrapTemplateVars, "Variables of the templates of the resources bootstrapped in the root workspace, e.g. ShardExternalURL=https://kcp.example.com. Overrides the defaults of ShardName, ShardBaseURL, ShardExternalURL, ShardVirtualWorkspaceURL and DefaultOrganizationName.")
+	fs.StringVar(&o.Extra.DynamicConfigFile, "dynamic-config-file", o.Extra.DynamicConfigFile, "File with feature gates and log verbosity (featureGates, verbosity, vmodule) applied on startup and re-read on SIGHUP. Only feature gates evaluated per request can be set: "+strings.Join(kcpfeatures.ReloadableFeatures.List(), ", ")+".")
+
+	fs.IntVar(&o.Extra.MaxWildcardWatchesPerUser, "max-wildcard-watches-per-user", o.Extra.MaxWildcardWatchesPerUser, "Maximal number of concurrent watches in the * logical cluster per user. Further wildcard watches are rejected with 429 Too Many Requests. The loopback client and the system:masters group are exempt. 0 disables the limit.")
+	fs.StringToStringVar(&o.Extra.WorkspaceRequestTimeouts, "workspace-request-timeouts", o.Extra.WorkspaceRequestTimeouts, "Timeouts of non-long-running requests to a workspace and its descendants, e.g. root:org=10s. The timeout of the closest workspace applies. They can only shorten --request-timeout.")
+	fs.DurationVar(&o.Extra.SlowRequestThreshold, "slow-request-threshold", o.Extra.SlowRequestThreshold, "Log non-long-running requests taking longer than this, with workspace, user, verb, resource and duration. 0 disables logging slow requests.")
 	fs.BoolVar(&o.Extra.ExperimentalBindFreePort, "experimental-bind-free-port", o.Extra.ExperimentalBindFreePort, "Bind to a free port. --secure-port must be 0. Use the admin.kubeconfig to extract the chosen port.")
 	fs.MarkHidden("experimental-bind-free-port") // nolint:errcheck
 
@@ -172,10 +208,26 @@ func (o *CompletedOptions) Validate() []error {
 	errs = append(errs, o.Authorization.Validate()...)
 	errs = append(errs, o.AdminAuthentication.Validate()...)
 	errs = append(errs, o.Virtual.Validate()...)
+	errs = append(errs, o.ShardIdentity.Validate()...)
+	errs = append(errs, o.SecretStore.Validate()...)
 
 	if o.Extra.DiscoveryPollInterval == 0 {
 		errs = append(errs, fmt.Errorf("--discovery-poll-interval not set"))
 	}
+	if o.Extra.MaxWildcardWatchesPerUser < 0 {
+		errs = append(errs, fmt.Errorf("--max-wildcard-watches-per-user must not be negative"))
+	}
+	for workspace, timeout := range o.Extra.WorkspaceRequestTimeouts {
+		if err := tenancypath.Validate(logicalcluster.New(workspace)); err != nil {
+			errs = append(errs, fmt.Errorf("--workspace-request-timeouts: %w", err))
+		}
+		if d, err := time.ParseDuration(timeout); err != nil || d <= 0 {
+			errs = append(errs, fmt.Errorf("--workspace-request-timeouts: timeout %q of workspace %q must be a positive duration", timeout, workspace))
+		}
+	}
+	if o.Extra.SlowRequestThreshold < 0 {
+		errs = append(errs, fmt.Errorf("--slow-request-threshold must not be negative"))
+	}
 
 	return errs
 }
@@ -216,6 +268,12 @@ func (o *Options) Complete() (*CompletedOptions, error) {
 	if !filepath.IsAbs(o.AdminAuthentication.KubeConfigPath) {
 		o.AdminAuthentication.KubeConfigPath = filepath.Join(o.Extra.RootDirectory, o.AdminAuthentication.KubeConfigPath)
 	}
+	if o.Extra.InformerBookmarkDirectory != "" && !filepath.IsAbs(o.Extra.InformerBookmarkDirectory) {
+		o.Extra.InformerBookmarkDirectory = filepath.Join(o.Extra.RootDirectory, o.Extra.InformerBookmarkDirectory)
+	}
+
+	o.ShardIdentity.Complete(o.Extra.RootDirectory)
+	o.ShardIdentity.ApplyTo(o.GenericControlPlane.SecureServing, o.GenericControlPlane.Authentication.ClientCert, o.GenericControlPlane.Authentication.RequestHeader)
 
 	if o.Extra.ExperimentalBindFreePort {
 		listener, _, err := genericapiserveroptions.CreateListener("tcp", fmt.Sprintf("%s:0", o.GenericControlPlane.SecureServing.BindAddress), net.ListenConfig{})
@@ -259,6 +317,8 @@ func (o *Options) Complete() (*CompletedOptions, error) {
 			Authorization:       o.Authorization,
 			AdminAuthentication: o.AdminAuthentication,
 			Virtual:             o.Virtual,
+			ShardIdentity:       o.ShardIdentity,
+			SecretStore:         o.SecretStore,
 			Extra:               o.Extra,
 		},
 	}, nil
@@ -0,0 +1,162 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package options
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	genericapiserveroptions "k8s.io/apiserver/pkg/server/options"
+
+	"github.com/kcp-dev/kcp/pkg/shardidentity"
+)
+
+// ShardIdentity configures the certificates issued from the kcp shard CA that the shard,
+// its peer shards and the kcp-front-proxy use to authenticate each other. The client
+// certificate of the kcp-front-proxy is issued from a separate front-proxy CA, such that
+// it is only accepted for request header authentication.
+type ShardIdentity struct {
+	Enabled              bool
+	CACertFile           string
+	CAKeyFile            string
+	FrontProxyCACertFile string
+	FrontProxyCAKeyFile  string
+	CertValidity         time.Duration
+	ExtraHosts           []string
+
+	// Directory holds the issued certificates. It is completed to be in the root directory.
+	Directory string
+}
+
+func NewShardIdentity() *ShardIdentity {
+	return &ShardIdentity{
+		Enabled:              false,
+		CACertFile:           "shard-ca.crt",
+		CAKeyFile:            "shard-ca.key",
+		FrontProxyCACertFile: "front-proxy-ca.crt",
+		FrontProxyCAKeyFile:  "front-proxy-ca.key",
+		CertValidity:         30 * 24 * time.Hour,
+	}
+}
+
+func (s *ShardIdentity) AddFlags(fs *pflag.FlagSet) {
+	fs.BoolVar(&s.Enabled, "shard-identity", s.Enabled, "Issue the serving certificate and a client certificate for peer shards from the kcp shard CA, and a client certificate for the kcp-front-proxy from the front-proxy CA. Peer shards are authenticated by their certificates of the shard CA, the kcp-front-proxy by its certificate of the front-proxy CA. The certificates are rotated before they expire. Explicitly configured serving certificates and client CAs take precedence.")
+	fs.StringVar(&s.CACertFile, "shard-ca-cert-file", s.CACertFile, "File with the kcp shard CA certificate. Generated together with --shard-ca-key-file if both do not exist. If this is relative, it is relative to --root-directory.")
+	fs.StringVar(&s.CAKeyFile, "shard-ca-key-file", s.CAKeyFile, "File with the private key of the kcp shard CA. If this is relative, it is relative to --root-directory.")
+	fs.StringVar(&s.FrontProxyCACertFile, "front-proxy-ca-cert-file", s.FrontProxyCACertFile, "File with the CA certificate of the client certificate of the kcp-front-proxy, used for request header authentication. Generated together with --front-proxy-ca-key-file if both do not exist. If this is relative, it is relative to --root-directory.")
+	fs.StringVar(&s.FrontProxyCAKeyFile, "front-proxy-ca-key-file", s.FrontProxyCAKeyFile, "File with the private key of the front-proxy CA. If this is relative, it is relative to --root-directory.")
+	fs.DurationVar(&s.CertValidity, "shard-identity-cert-validity", s.CertValidity, "Validity of the certificates issued from the kcp shard CA. They are rotated after two thirds of it.")
+	fs.StringSliceVar(&s.ExtraHosts, "shard-identity-extra-hosts", s.ExtraHosts, "Additional host names and IPs of the serving certificate issued from the kcp shard CA. The external hostname, localhost and the loopback IPs are always included.")
+}
+
+func (s *ShardIdentity) Complete(rootDir string) {
+	if !filepath.IsAbs(s.CACertFile) {
+		s.CACertFile = filepath.Join(rootDir, s.CACertFile)
+	}
+	if !filepath.IsAbs(s.CAKeyFile) {
+		s.CAKeyFile = filepath.Join(rootDir, s.CAKeyFile)
+	}
+	if !filepath.IsAbs(s.FrontProxyCACertFile) {
+		s.FrontProxyCACertFile = filepath.Join(rootDir, s.FrontProxyCACertFile)
+	}
+	if !filepath.IsAbs(s.FrontProxyCAKeyFile) {
+		s.FrontProxyCAKeyFile = filepath.Join(rootDir, s.FrontProxyCAKeyFile)
+	}
+	s.Directory = filepath.Join(rootDir, "shard-identity")
+}
+
+// ApplyTo defaults the serving certificate and the client CA to the shard CA, and the request
+// header authentication of the kcp-front-proxy to the front-proxy CA, if not configured explicitly.
+func (s *ShardIdentity) ApplyTo(secureServing *genericapiserveroptions.SecureServingOptionsWithLoopback, clientCert *genericapiserveroptions.ClientCertAuthenticationOptions, requestHeader *genericapiserveroptions.RequestHeaderAuthenticationOptions) {
+	if !s.Enabled {
+		return
+	}
+
+	if secureServing.ServerCert.CertKey.CertFile == "" && secureServing.ServerCert.CertKey.KeyFile == "" {
+		secureServing.ServerCert.CertKey.CertFile = s.ServingCertFile()
+		secureServing.ServerCert.CertKey.KeyFile = s.ServingKeyFile()
+	}
+	if clientCert != nil && clientCert.ClientCA == "" {
+		clientCert.ClientCA = s.CACertFile
+	}
+	if requestHeader != nil && requestHeader.ClientCAFile == "" {
+		requestHeader.ClientCAFile = s.FrontProxyCACertFile
+		if len(requestHeader.AllowedNames) == 0 {
+			requestHeader.AllowedNames = []string{shardidentity.FrontProxyUser}
+		}
+		if len(requestHeader.UsernameHeaders) == 0 {
+			requestHeader.UsernameHeaders = []string{"X-Remote-User"}
+		}
+		if len(requestHeader.GroupHeaders) == 0 {
+			requestHeader.GroupHeaders = []string{"X-Remote-Group"}
+		}
+		if len(requestHeader.ExtraHeaderPrefixes) == 0 {
+			requestHeader.ExtraHeaderPrefixes = []string{"X-Remote-Extra-"}
+		}
+	}
+}
+
+func (s *ShardIdentity) Validate() []error {
+	var errs []error
+
+	if s.Enabled {
+		if s.CertValidity < 10*time.Minute {
+			errs = append(errs, fmt.Errorf("--shard-identity-cert-validity must be at least 10m"))
+		}
+		for _, host := range s.ExtraHosts {
+			if host == "" {
+				errs = append(errs, fmt.Errorf("--shard-identity-extra-hosts must not contain empty hosts"))
+			}
+		}
+	}
+
+	return errs
+}
+
+// ServingCertFile is the file of the serving certificate issued from the kcp shard CA.
+func (s *ShardIdentity) ServingCertFile() string {
+	return filepath.Join(s.Directory, "serving.crt")
+}
+
+// ServingKeyFile is the file of the key of the serving certificate issued from the kcp shard CA.
+func (s *ShardIdentity) ServingKeyFile() string {
+	return filepath.Join(s.Directory, "serving.key")
+}
+
+// ClientCertFile is the file of the client certificate for peer shards issued from the kcp shard CA.
+func (s *ShardIdentity) ClientCertFile() string {
+	return filepath.Join(s.Directory, "client.crt")
+}
+
+// ClientKeyFile is the file of the key of the client certificate for peer shards.
+func (s *ShardIdentity) ClientKeyFile() string {
+	return filepath.Join(s.Directory, "client.key")
+}
+
+// FrontProxyClientCertFile is the file of the client certificate for the kcp-front-proxy issued
+// from the front-proxy CA.
+func (s *ShardIdentity) FrontProxyClientCertFile() string {
+	return filepath.Join(s.Directory, "front-proxy-client.crt")
+}
+
+// FrontProxyClientKeyFile is the file of the key of the client certificate for the kcp-front-proxy.
+func (s *ShardIdentity) FrontProxyClientKeyFile() string {
+	return filepath.Join(s.Directory, "front-proxy-client.key")
+}
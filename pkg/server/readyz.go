@@ -0,0 +1,66 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+
+	"k8s.io/apiserver/pkg/server/healthz"
+)
+
+// cacheSyncWaiter is implemented by all shared informer factories.
+type cacheSyncWaiter interface {
+	WaitForCacheSync(stopCh <-chan struct{}) map[reflect.Type]bool
+}
+
+// informerSyncCheck returns a readyz check named informer-sync-<name> that fails until started
+// is closed, and afterwards while any informer of the factory has not synced, e.g. because it
+// was requested late by a controller.
+func informerSyncCheck(name string, started <-chan struct{}, factory cacheSyncWaiter) healthz.HealthChecker {
+	return healthz.NamedCheck("informer-sync-"+name, func(_ *http.Request) error {
+		select {
+		case <-started:
+		default:
+			return fmt.Errorf("informers not started yet")
+		}
+
+		// with a closed stop channel this only checks the current state and does not block
+		stopCh := make(chan struct{})
+		close(stopCh)
+		var unsynced []string
+		for informerType, synced := range factory.WaitForCacheSync(stopCh) {
+			if !synced {
+				unsynced = append(unsynced, informerType.String())
+			}
+		}
+		if len(unsynced) > 0 {
+			sort.Strings(unsynced)
+			return fmt.Errorf("informers not synced: %s", strings.Join(unsynced, ", "))
+		}
+		return nil
+	})
+}
+
+// AddReadyzChecks allows you to add readyz checks that get passed to the underlying genericapiserver
+// implementation. Every check is served under /readyz/<name> and is part of /readyz.
+func (s *Server) AddReadyzChecks(checks ...healthz.HealthChecker) {
+	s.readyzChecks = append(s.readyzChecks, checks...)
+}
@@ -0,0 +1,53 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+)
+
+type fakeCacheSyncWaiter map[reflect.Type]bool
+
+func (f fakeCacheSyncWaiter) WaitForCacheSync(stopCh <-chan struct{}) map[reflect.Type]bool {
+	return f
+}
+
+func TestInformerSyncCheck(t *testing.T) {
+	started := make(chan struct{})
+	informers := fakeCacheSyncWaiter{
+		reflect.TypeOf(&corev1.Namespace{}):   true,
+		reflect.TypeOf(&rbacv1.ClusterRole{}): false,
+		reflect.TypeOf(&corev1.Secret{}):      false,
+	}
+	check := informerSyncCheck("kube", started, informers)
+	require.Equal(t, "informer-sync-kube", check.Name())
+
+	require.EqualError(t, check.Check(nil), "informers not started yet")
+
+	close(started)
+	require.EqualError(t, check.Check(nil), "informers not synced: *v1.ClusterRole, *v1.Secret")
+
+	informers[reflect.TypeOf(&rbacv1.ClusterRole{})] = true
+	informers[reflect.TypeOf(&corev1.Secret{})] = true
+	require.NoError(t, check.Check(nil))
+}
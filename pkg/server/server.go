@@ -33,17 +33,21 @@ import (
 	"k8s.io/apiserver/pkg/admission"
 	"k8s.io/apiserver/pkg/endpoints/filters"
 	genericapiserver "k8s.io/apiserver/pkg/server"
+	"k8s.io/apiserver/pkg/server/healthz"
 	utilfeature "k8s.io/apiserver/pkg/util/feature"
 	"k8s.io/apiserver/pkg/util/webhook"
 	"k8s.io/client-go/dynamic"
 	coreexternalversions "k8s.io/client-go/informers"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/clientcmd"
 	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
 	"k8s.io/client-go/tools/clusters"
 	"k8s.io/klog/v2"
 	"k8s.io/kubernetes/pkg/genericcontrolplane"
 
+	confighelpers "github.com/kcp-dev/kcp/config/helpers"
 	configroot "github.com/kcp-dev/kcp/config/root"
 	systemcrds "github.com/kcp-dev/kcp/config/system-crds"
 	kcpadmissioninitializers "github.com/kcp-dev/kcp/pkg/admission/initializers"
@@ -51,11 +55,16 @@ import (
 	"github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
 	"github.com/kcp-dev/kcp/pkg/authentication"
 	bootstrappolicy "github.com/kcp-dev/kcp/pkg/authorization/bootstrap"
+	cacheclient "github.com/kcp-dev/kcp/pkg/cache/client"
 	kcpclient "github.com/kcp-dev/kcp/pkg/client/clientset/versioned"
 	kcpexternalversions "github.com/kcp-dev/kcp/pkg/client/informers/externalversions"
 	"github.com/kcp-dev/kcp/pkg/etcd"
 	kcpfeatures "github.com/kcp-dev/kcp/pkg/features"
+	"github.com/kcp-dev/kcp/pkg/reconciler/apis/apibindingdeprecation"
+	"github.com/kcp-dev/kcp/pkg/schemaconversion"
+	"github.com/kcp-dev/kcp/pkg/secretstore"
 	kcpserveroptions "github.com/kcp-dev/kcp/pkg/server/options"
+	"github.com/kcp-dev/kcp/pkg/shardidentity"
 	"github.com/kcp-dev/kcp/pkg/sharding"
 )
 
@@ -80,6 +89,7 @@ type Server struct {
 
 	postStartHooks   []postStartHookEntry
 	preShutdownHooks []preShutdownHookEntry
+	readyzChecks     []healthz.HealthChecker
 
 	syncedCh chan struct{}
 
@@ -90,13 +100,27 @@ type Server struct {
 	// TODO(sttts): get rid of these. We have wildcard informers already.
 	rootKcpSharedInformerFactory  kcpexternalversions.SharedInformerFactory
 	rootKubeSharedInformerFactory coreexternalversions.SharedInformerFactory
+
+	deprecatedVersionTracker *apibindingdeprecation.Tracker
+
+	// secretStore keeps kcp-managed credentials outside of etcd. It is nil if they are kept in Secret objects.
+	secretStore secretstore.Store
+
+	// shardClientCert rotates the client certificate for peer shards in the secret store. It is nil
+	// if the certificate is rotated on disk or --shard-identity is disabled.
+	shardClientCert *shardidentity.Rotator
+
+	// cacheClient resolves objects of other shards through the cache server, or by live requests
+	// without --cache-server-kubeconfig-file.
+	cacheClient *cacheclient.Client
 }
 
 // NewServer creates a new instance of Server which manages the KCP api-server.
 func NewServer(o *kcpserveroptions.CompletedOptions) (*Server, error) {
 	return &Server{
-		options:  o,
-		syncedCh: make(chan struct{}),
+		options:                  o,
+		syncedCh:                 make(chan struct{}),
+		deprecatedVersionTracker: apibindingdeprecation.NewTracker(),
 	}, nil
 }
 
@@ -117,15 +141,43 @@ type preShutdownHookEntry struct {
 
 // Run starts the KCP api-server. This function blocks until the api-server stops or an error.
 func (s *Server) Run(ctx context.Context) error {
+	RegisterMetrics()
+
+	if s.options.Extra.DynamicConfigFile != "" {
+		if err := applyDynamicConfig(s.options.Extra.DynamicConfigFile); err != nil {
+			return err
+		}
+		go watchDynamicConfig(ctx, s.options.Extra.DynamicConfigFile)
+	}
+
 	if s.options.Extra.ProfilerAddress != "" {
 		// nolint:errcheck
 		go http.ListenAndServe(s.options.Extra.ProfilerAddress, nil)
 	}
+
+	secretStore, err := s.options.SecretStore.NewStore()
+	if err != nil {
+		return err
+	}
+	s.secretStore = secretStore
+
+	var shardCA *shardidentity.CA
+	if s.options.ShardIdentity.Enabled {
+		if shardCA, err = s.startShardIdentity(ctx); err != nil {
+			return err
+		}
+	}
+
 	if s.options.EmbeddedEtcd.Enabled {
+		// the embedded etcd must outlive the graceful shutdown of the apiserver, which completes
+		// in-flight writes after ctx is done.
+		etcdCtx, cancelEtcd := context.WithCancel(context.Background())
+		defer cancelEtcd()
+
 		es := &etcd.Server{
 			Dir: s.options.EmbeddedEtcd.Directory,
 		}
-		embeddedClientInfo, err := es.Run(ctx, s.options.EmbeddedEtcd.PeerPort, s.options.EmbeddedEtcd.ClientPort, s.options.EmbeddedEtcd.WalSizeBytes)
+		embeddedClientInfo, err := es.Run(etcdCtx, s.options.EmbeddedEtcd.PeerPort, s.options.EmbeddedEtcd.ClientPort, s.options.EmbeddedEtcd.WalSizeBytes)
 		if err != nil {
 			return err
 		}
@@ -149,6 +201,21 @@ func (s *Server) Run(ctx context.Context) error {
 	kcpClient := kcpClusterClient.Cluster(logicalcluster.Wildcard)
 	s.kcpSharedInformerFactory = kcpexternalversions.NewSharedInformerFactoryWithOptions(kcpClient, resyncPeriod)
 
+	var cacheServerConfig *rest.Config
+	if s.options.Extra.CacheServerKubeconfigFile != "" {
+		cacheServerConfig, err = clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+			&clientcmd.ClientConfigLoadingRules{ExplicitPath: s.options.Extra.CacheServerKubeconfigFile},
+			&clientcmd.ConfigOverrides{},
+		).ClientConfig()
+		if err != nil {
+			return fmt.Errorf("failed to load --cache-server-kubeconfig-file: %w", err)
+		}
+	}
+	s.cacheClient, err = cacheclient.New(cacheServerConfig, kcpClusterClient)
+	if err != nil {
+		return err
+	}
+
 	// Setup kube * informers
 	kubeClusterClient, err := kubernetes.NewClusterForConfig(genericConfig.LoopbackClientConfig)
 	if err != nil {
@@ -175,7 +242,7 @@ func (s *Server) Run(ctx context.Context) error {
 		return err
 	}
 
-	if err := s.options.Authorization.ApplyTo(genericConfig, s.kubeSharedInformerFactory, s.kcpSharedInformerFactory.Tenancy().V1alpha1().ClusterWorkspaces().Lister()); err != nil {
+	if err := s.options.Authorization.ApplyTo(genericConfig, s.kubeSharedInformerFactory, s.kcpSharedInformerFactory.Tenancy().V1alpha1().ClusterWorkspaces().Lister(), s.kcpSharedInformerFactory.Apis().V1alpha1().SecretShares()); err != nil {
 		return err
 	}
 	newTokenOrEmpty, tokenHash, err := s.options.AdminAuthentication.ApplyTo(genericConfig)
@@ -195,19 +262,85 @@ func (s *Server) Run(ctx context.Context) error {
 	// is called multiple times, but only one of the handler chain will actually be used. Hence, we wrap it
 	// to give handlers below one mux.Handle func to call.
 	var preHandlerChainMux handlerChainMuxes
+	openAPI := newClusterOpenAPI()
+
+	// Watches are terminated when the server stops listening after the shutdown delay, during which
+	// /readyz fails to let load balancers take the shard out of rotation.
+	watchTerminationCh := make(chan struct{})
+	go func() {
+		<-ctx.Done()
+		time.Sleep(s.options.GenericControlPlane.GenericServerRunOptions.ShutdownDelayDuration)
+		close(watchTerminationCh)
+	}()
+
+	var shardClientLoader *sharding.ClientLoader
+	if s.options.Extra.EnableSharding {
+		shardClientLoader = sharding.NewClientLoader()
+		if s.options.Extra.ShardKubeconfigFile != "" {
+			if err := shardClientLoader.AddKubeConfigContexts(s.options.Extra.ShardKubeconfigFile); err != nil {
+				return fmt.Errorf("failed to load --shard-kubeconfig-file: %w", err)
+			}
+		}
+		if s.options.ShardIdentity.Enabled {
+			tlsConfig := rest.TLSClientConfig{CAFile: s.options.ShardIdentity.CACertFile}
+			if s.shardClientCert != nil {
+				shardClientLoader.DefaultClientCertificate(s.shardClientCert.PEM)
+			} else {
+				tlsConfig.CertFile = s.options.ShardIdentity.ClientCertFile()
+				tlsConfig.KeyFile = s.options.ShardIdentity.ClientKeyFile()
+			}
+			shardClientLoader.DefaultTLSClientConfig(tlsConfig)
+		}
+	}
+
+	// shared by all handler chains below, the counts must be global
+	wildcardWatchLimiter := newWildcardWatchLimiter(s.options.Extra.MaxWildcardWatchesPerUser)
+	workspaceRequestTimeouts, err := newWorkspaceRequestTimeouts(s.options.Extra.WorkspaceRequestTimeouts)
+	if err != nil {
+		return fmt.Errorf("invalid --workspace-request-timeouts: %w", err)
+	}
+
+	// indexes for the lookups of every request in the handler chain and the CRD lister below
+	if err := s.kcpSharedInformerFactory.Apis().V1alpha1().APIBindings().Informer().AddIndexers(cache.Indexers{
+		indexAPIBindingsByLogicalCluster:       indexAPIBindingsByLogicalClusterFunc,
+		indexAPIBindingsByClusterGroupResource: indexAPIBindingsByClusterGroupResourceFunc,
+	}); err != nil {
+		return err
+	}
+	if err := s.apiextensionsSharedInformerFactory.Apiextensions().V1().CustomResourceDefinitions().Informer().AddIndexers(cache.Indexers{
+		indexCRDsByDeprecatedVersion: indexCRDsByDeprecatedVersionFunc,
+	}); err != nil {
+		return err
+	}
+	if err := s.kcpSharedInformerFactory.Tenancy().V1alpha1().ClusterWorkspaces().Informer().AddIndexers(cache.Indexers{
+		indexClusterWorkspacesByType: indexClusterWorkspacesByTypeFunc,
+	}); err != nil {
+		return err
+	}
+
 	genericConfig.BuildHandlerChainFunc = func(apiHandler http.Handler, c *genericapiserver.Config) (secure http.Handler) {
 		// we want a request to hit the chain like:
 		// - lcluster handler (this package's ServeHTTP)
 		// - shard proxy (sharding.ServeHTTP)
 		// - original handler chain
 		// the lcluster handler is a pass-through, not a delegate, so the wrapping looks weird
-		if s.options.Extra.EnableSharding {
-			clientLoader := sharding.NewClientLoader()
-			clientLoader.Add(genericConfig.ExternalAddress, genericConfig.LoopbackClientConfig)
-			apiHandler = sharding.WithSharding(apiHandler, clientLoader)
+		if shardClientLoader != nil {
+			shardClientLoader.Add(genericConfig.ExternalAddress, genericConfig.LoopbackClientConfig)
+			apiHandler = sharding.WithSharding(apiHandler, shardClientLoader)
 		}
 		apiHandler = WithWildcardListWatchGuard(apiHandler)
+		apiHandler = WithWatchTerminationDuringShutdown(apiHandler, watchTerminationCh)
+		apiHandler = WithWildcardWatchLimit(apiHandler, wildcardWatchLimiter)
+		apiHandler = WithWorkspaceRequestDeadline(apiHandler, workspaceRequestTimeouts, c.LongRunningFunc)
+		apiHandler = WithSlowRequestLogging(apiHandler, s.options.Extra.SlowRequestThreshold, c.LongRunningFunc)
+		apiHandler = WithBoundResourceListMetrics(apiHandler, boundResourceFunc(s.kcpSharedInformerFactory.Apis().V1alpha1().APIBindings().Informer().GetIndexer()))
+		apiHandler = WithDeprecatedVersionUsage(apiHandler,
+			deprecatedBoundVersionFunc(s.kcpSharedInformerFactory.Apis().V1alpha1().APIBindings().Informer().GetIndexer(), s.apiextensionsSharedInformerFactory.Apiextensions().V1().CustomResourceDefinitions().Informer().GetIndexer()),
+			s.deprecatedVersionTracker.Record,
+		)
 		apiHandler = WithWildcardIdentity(apiHandler)
+		apiHandler = WithClusterOpenAPI(apiHandler, openAPI)
+		apiHandler = WithClusterTracing(apiHandler)
 		apiHandler = genericapiserver.DefaultBuildHandlerChain(apiHandler, c)
 
 		// this will be replaced in DefaultBuildHandlerChain. So at worst we get twice as many warning.
@@ -232,6 +365,7 @@ func (s *Server) Run(ctx context.Context) error {
 		kcpadmissioninitializers.NewKcpInformersInitializer(s.kcpSharedInformerFactory),
 		kcpadmissioninitializers.NewKubeClusterClientInitializer(kubeClusterClient),
 		kcpadmissioninitializers.NewKcpClusterClientInitializer(kcpClusterClient),
+		kcpadmissioninitializers.NewCacheClientInitializer(s.cacheClient),
 		// The external address is provided as a function, as its value may be updated
 		// with the default secure port, when the config is later completed.
 		kcpadmissioninitializers.NewExternalAddressInitializer(func() string { return genericConfig.ExternalAddress }),
@@ -253,14 +387,19 @@ func (s *Server) Run(ctx context.Context) error {
 
 		// Wire in a ServiceResolver that always returns an error that ResolveEndpoint is not yet
 		// supported. The effect is that CRD webhook conversions are not supported and will always get an
-		// error.
+		// error, apart from the declarative conversions of bound CRDs served in-memory below.
 		&unimplementedServiceResolver{},
 
-		webhook.NewDefaultAuthenticationInfoResolverWrapper(
-			nil,
-			apisConfig.GenericConfig.EgressSelector,
-			apisConfig.GenericConfig.LoopbackClientConfig,
-			apisConfig.GenericConfig.TracerProvider,
+		schemaconversion.NewAuthenticationInfoResolverWrapper(
+			schemaconversion.NewHandler(func(clusterName logicalcluster.Name, name string) (*apisv1alpha1.APIResourceSchema, error) {
+				return s.kcpSharedInformerFactory.Apis().V1alpha1().APIResourceSchemas().Lister().Get(clusters.ToClusterAwareKey(clusterName, name))
+			}),
+			webhook.NewDefaultAuthenticationInfoResolverWrapper(
+				nil,
+				apisConfig.GenericConfig.EgressSelector,
+				apisConfig.GenericConfig.LoopbackClientConfig,
+				apisConfig.GenericConfig.TracerProvider,
+			),
 		),
 	)
 	if err != nil {
@@ -271,7 +410,6 @@ func (s *Server) Run(ctx context.Context) error {
 		kcpClusterClient:  kcpClusterClient,
 		crdLister:         s.apiextensionsSharedInformerFactory.Apiextensions().V1().CustomResourceDefinitions().Lister(),
 		workspaceLister:   s.kcpSharedInformerFactory.Tenancy().V1alpha1().ClusterWorkspaces().Lister(),
-		apiBindingLister:  s.kcpSharedInformerFactory.Apis().V1alpha1().APIBindings().Lister(),
 		apiBindingIndexer: s.kcpSharedInformerFactory.Apis().V1alpha1().APIBindings().Informer().GetIndexer(),
 		apiExportIndexer:  s.kcpSharedInformerFactory.Apis().V1alpha1().APIExports().Informer().GetIndexer(),
 		systemCRDProvider: newSystemCRDProvider(
@@ -290,6 +428,7 @@ func (s *Server) Run(ctx context.Context) error {
 
 				return cws, err
 			},
+			s.kcpSharedInformerFactory.Tenancy().V1alpha1().ClusterWorkspaceTypes().Lister().Get,
 			s.apiextensionsSharedInformerFactory.Apiextensions().V1().CustomResourceDefinitions().Lister().Get,
 		),
 		getAPIResourceSchema: func(clusterName logicalcluster.Name, name string) (*apisv1alpha1.APIResourceSchema, error) {
@@ -297,15 +436,22 @@ func (s *Server) Run(ctx context.Context) error {
 			return s.kcpSharedInformerFactory.Apis().V1alpha1().APIResourceSchemas().Lister().Get(key)
 		},
 	}
+	apiBindingAwareCRDLister.listCache = newCRDListCache()
+	s.apiextensionsSharedInformerFactory.Apiextensions().V1().CustomResourceDefinitions().Informer().AddEventHandler(apiBindingAwareCRDLister.listCache.crdEventHandler())
+	s.kcpSharedInformerFactory.Apis().V1alpha1().APIBindings().Informer().AddEventHandler(apiBindingAwareCRDLister.listCache.apiBindingEventHandler())
+	s.kcpSharedInformerFactory.Tenancy().V1alpha1().ClusterWorkspaces().Informer().AddEventHandler(apiBindingAwareCRDLister.listCache.clusterWorkspaceEventHandler())
+	s.kcpSharedInformerFactory.Tenancy().V1alpha1().ClusterWorkspaceTypes().Informer().AddEventHandler(apiBindingAwareCRDLister.listCache.clusterWorkspaceTypeEventHandler(s.kcpSharedInformerFactory.Tenancy().V1alpha1().ClusterWorkspaces().Informer().GetIndexer()))
 	apiExtensionsConfig.ExtraConfig.ClusterAwareCRDLister = apiBindingAwareCRDLister
 
-	apiExtensionsConfig.ExtraConfig.TableConverterProvider = NewTableConverterProvider()
+	apiExtensionsConfig.ExtraConfig.TableConverterProvider = NewTableConverterProvider(genericConfig.Authorization.Authorizer)
 
 	serverChain, err := genericcontrolplane.CreateServerChain(apisConfig.Complete(), apiExtensionsConfig.Complete())
 	if err != nil {
 		return err
 	}
 	server := serverChain.MiniAggregator.GenericAPIServer
+	openAPI.crdLister = apiBindingAwareCRDLister
+	openAPI.controlPlane = serverChain.GenericControlPlane.GenericAPIServer.Handler.Director
 	serverChain.GenericControlPlane.GenericAPIServer.Handler.GoRestfulContainer.Filter(
 		mergeCRDsIntoCoreGroup(
 			apiBindingAwareCRDLister,
@@ -330,6 +476,7 @@ func (s *Server) Run(ctx context.Context) error {
 			apiextensionsClusterClient.Cluster(SystemCRDLogicalCluster),
 			apiextensionsClusterClient.Cluster(SystemCRDLogicalCluster).Discovery(),
 			dynamicClusterClient.Cluster(SystemCRDLogicalCluster),
+			confighelpers.ForceOption(s.options.Extra.ForceBootstrapReconcile),
 		); err != nil {
 			klog.Errorf("failed to bootstrap system CRDs: %v", err)
 			// nolint:nilerr
@@ -347,6 +494,10 @@ func (s *Server) Run(ctx context.Context) error {
 
 		// bootstrap root workspace with workspace shard
 		servingCert, _ := server.SecureServingInfo.Cert.CurrentCertKeyContent()
+		if s.servesShardIdentityCert() {
+			// the serving certificate is rotated, but the CA stays
+			servingCert = shardCA.CertPEM()
+		}
 		if err := configroot.Bootstrap(goContext(ctx),
 			apiextensionsClusterClient.Cluster(v1alpha1.RootCluster).Discovery(),
 			dynamicClusterClient.Cluster(v1alpha1.RootCluster),
@@ -365,9 +516,18 @@ func (s *Server) Run(ctx context.Context) error {
 					"shard": {Cluster: "shard"},
 				},
 				CurrentContext: "shard",
-			}); err != nil {
-			// nolint:nilerr
-			return nil // don't klog.Fatal. This only happens when context is cancelled.
+			},
+			s.bootstrapTemplateVars(),
+			confighelpers.ForceOption(s.options.Extra.ForceBootstrapReconcile),
+		); err != nil {
+			select {
+			case <-ctx.StopCh:
+				// nolint:nilerr
+				return nil // don't klog.Fatal when the context is cancelled.
+			default:
+			}
+			// e.g. missing template variables
+			return fmt.Errorf("failed to bootstrap the root workspace: %w", err)
 		}
 
 		klog.Infof("Bootstrapped resources and synced all informers. Ready to start controllers")
@@ -376,6 +536,14 @@ func (s *Server) Run(ctx context.Context) error {
 		return nil
 	})
 
+	s.AddReadyzChecks(
+		informerSyncCheck("kube", s.syncedCh, s.kubeSharedInformerFactory),
+		informerSyncCheck("apiextensions", s.syncedCh, s.apiextensionsSharedInformerFactory),
+		informerSyncCheck("kcp", s.syncedCh, s.kcpSharedInformerFactory),
+		informerSyncCheck("root-kube", s.syncedCh, s.rootKubeSharedInformerFactory),
+		informerSyncCheck("root-kcp", s.syncedCh, s.rootKcpSharedInformerFactory),
+	)
+
 	// ========================================================================================================
 	// TODO: split apart everything after this line, into their own commands, optional launched in this process
 
@@ -401,6 +569,10 @@ func (s *Server) Run(ctx context.Context) error {
 		return err
 	}
 
+	if err := s.installResourceQuotaController(ctx, controllerConfig); err != nil {
+		return err
+	}
+
 	enabled := sets.NewString(s.options.Controllers.IndividuallyEnabled...)
 	if len(enabled) > 0 {
 		klog.Infof("Starting controllers individually: %v", enabled)
@@ -415,6 +587,9 @@ func (s *Server) Run(ctx context.Context) error {
 		if err := s.installWorkloadClusterHeartbeatController(ctx, controllerConfig); err != nil {
 			return err
 		}
+		if err := s.installWorkloadClusterVirtualWorkspaceURLsController(ctx, controllerConfig); err != nil {
+			return err
+		}
 	}
 
 	if s.options.Controllers.EnableAll || enabled.Has("workspace-scheduler") {
@@ -424,6 +599,42 @@ func (s *Server) Run(ctx context.Context) error {
 		if err := s.installWorkspaceDeletionController(ctx, controllerConfig); err != nil {
 			return err
 		}
+		if err := s.installWorkspaceSnapshotControllers(ctx, controllerConfig); err != nil {
+			return err
+		}
+		if err := s.installWorkspaceLabelsController(ctx, controllerConfig); err != nil {
+			return err
+		}
+		if err := s.installWorkspaceURLController(ctx, controllerConfig); err != nil {
+			return err
+		}
+
+	}
+
+	if s.options.Controllers.EnableAll || enabled.Has("storagewatchdog") {
+		if err := s.installStorageWatchdogController(ctx, controllerConfig, server); err != nil {
+			return err
+		}
+	}
+
+	if s.options.Controllers.EnableAll || enabled.Has("workspacettl") {
+		if err := s.installWorkspaceTTLController(ctx, controllerConfig, server); err != nil {
+			return err
+		}
+	}
+
+	if utilfeature.DefaultFeatureGate.Enabled(kcpfeatures.WorkspaceSource) {
+		if s.options.Controllers.EnableAll || enabled.Has("workspacesource") {
+			if err := s.installWorkspaceSourceController(ctx, controllerConfig); err != nil {
+				return err
+			}
+		}
+	}
+
+	if utilfeature.DefaultFeatureGate.Enabled(kcpfeatures.RootComputeWorkspace) {
+		if err := s.installRootComputeWorkspace(ctx, controllerConfig); err != nil {
+			return err
+		}
 	}
 
 	if s.options.Controllers.EnableAll || enabled.Has("namespace-scheduler") {
@@ -438,17 +649,45 @@ func (s *Server) Run(ctx context.Context) error {
 		}
 	}
 
+	if s.options.Controllers.EnableAll || enabled.Has("apibindingdeprecation") {
+		if err := s.installAPIBindingDeprecationController(ctx, controllerConfig, server); err != nil {
+			return err
+		}
+	}
+
+	if s.options.Controllers.EnableAll || enabled.Has("apibindingroles") {
+		if err := s.installAPIBindingRolesController(ctx, controllerConfig, server); err != nil {
+			return err
+		}
+	}
+
+	if s.options.Controllers.EnableAll || enabled.Has("storageversionmigration") {
+
+		if err := s.installStorageVersionMigrationController(ctx, controllerConfig, server); err != nil {
+			return err
+		}
+	}
+
 	if s.options.Controllers.EnableAll || enabled.Has("apiexport") {
 		if err := s.installAPIExportController(ctx, controllerConfig, server); err != nil {
 			return err
 		}
 	}
 
+	if s.options.Controllers.EnableAll || enabled.Has("apiexportusage") {
+		if err := s.installAPIExportUsageController(ctx, controllerConfig, server); err != nil {
+			return err
+		}
+	}
+
 	if utilfeature.DefaultFeatureGate.Enabled(kcpfeatures.LocationAPI) {
 		if s.options.Controllers.EnableAll || enabled.Has("scheduling") {
 			if err := s.installSchedulingLocationStatusController(ctx, controllerConfig, server); err != nil {
 				return err
 			}
+			if err := s.installSchedulingTopologyController(ctx, controllerConfig, server); err != nil {
+				return err
+			}
 			if err := s.installSchedulingPlacementController(ctx, controllerConfig, server); err != nil {
 				return err
 			}
@@ -459,7 +698,7 @@ func (s *Server) Run(ctx context.Context) error {
 	}
 
 	if s.options.Virtual.Enabled {
-		if err := s.installVirtualWorkspaces(ctx, kubeClusterClient, dynamicClusterClient, kcpClusterClient, genericConfig.Authentication, genericConfig.ExternalAddress, preHandlerChainMux); err != nil {
+		if err := s.installVirtualWorkspaces(ctx, kubeClusterClient, dynamicClusterClient, kcpClusterClient, genericConfig.Authentication, genericConfig.TracerProvider, genericConfig.ExternalAddress, preHandlerChainMux); err != nil {
 			return err
 		}
 	} else if err := s.installVirtualWorkspacesRedirect(ctx, preHandlerChainMux); err != nil {
@@ -479,8 +718,15 @@ func (s *Server) Run(ctx context.Context) error {
 			return err
 		}
 	}
+	if err := server.AddReadyzChecks(s.readyzChecks...); err != nil {
+		return err
+	}
 
-	if err := s.options.AdminAuthentication.WriteKubeConfig(genericConfig, newTokenOrEmpty, tokenHash); err != nil {
+	var externalCAData []byte
+	if s.servesShardIdentityCert() {
+		externalCAData = shardCA.CertPEM()
+	}
+	if err := s.options.AdminAuthentication.WriteKubeConfig(genericConfig, newTokenOrEmpty, tokenHash, externalCAData); err != nil {
 		return err
 	}
 
@@ -0,0 +1,116 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apiserver/pkg/authentication/user"
+
+	"github.com/kcp-dev/kcp/pkg/secretstore"
+	"github.com/kcp-dev/kcp/pkg/shardidentity"
+)
+
+// shardCASecretStoreKey is the key of the kcp shard CA in the secret store. It is shared by all
+// shards using the same secret store, such that they trust each other's certificates.
+const shardCASecretStoreKey = "shard-ca"
+
+// frontProxyCASecretStoreKey is the key of the front-proxy CA in the secret store.
+const frontProxyCASecretStoreKey = "front-proxy-ca"
+
+// shardsSecretStoreKey is the prefix of the keys of the client certificates issued by the shards
+// in the secret store, i.e. shards/<shard name>/client and shards/<shard name>/front-proxy-client.
+const shardsSecretStoreKey = "shards"
+
+// startShardIdentity issues the serving certificate and the client certificate for peer shards
+// from the kcp shard CA, and the client certificate of the kcp-front-proxy from the front-proxy CA,
+// and rotates them until the context is done. The apiserver reloads the rotated serving certificate
+// from disk. The client certificates are rotated on disk too, or in the secret store if configured,
+// from where the clients of peer shards and the kcp-front-proxy reload them.
+func (s *Server) startShardIdentity(ctx context.Context) (*shardidentity.CA, error) {
+	o := s.options.ShardIdentity
+	ca, err := s.loadOrCreateCA(ctx, shardCASecretStoreKey, o.CACertFile, o.CAKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load kcp shard CA: %w", err)
+	}
+	frontProxyCA, err := s.loadOrCreateCA(ctx, frontProxyCASecretStoreKey, o.FrontProxyCACertFile, o.FrontProxyCAKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load front-proxy CA: %w", err)
+	}
+	if err := os.MkdirAll(o.Directory, 0700); err != nil {
+		return nil, err
+	}
+
+	var rotators []*shardidentity.Rotator
+	if s.servesShardIdentityCert() {
+		hosts := sets.NewString("localhost", "127.0.0.1", "::1")
+		hosts.Insert(o.ExtraHosts...)
+		if externalHost := s.options.GenericControlPlane.GenericServerRunOptions.ExternalHost; externalHost != "" {
+			if host, _, err := net.SplitHostPort(externalHost); err == nil {
+				externalHost = host
+			}
+			hosts.Insert(externalHost)
+		}
+		rotators = append(rotators, shardidentity.NewServingCertRotator(ca, "kcp-shard-"+s.shardName(), hosts.List(), o.CertValidity, o.ServingCertFile(), o.ServingKeyFile()))
+	}
+	// peer shards need full access to serve sharded requests.
+	clientCert := shardidentity.NewClientCertRotator(ca, shardidentity.ShardUserPrefix+s.shardName(), []string{user.SystemPrivilegedGroup}, o.CertValidity, o.ClientCertFile(), o.ClientKeyFile())
+	// the front proxy only asserts the user headers of its clients.
+	frontProxyClientCert := shardidentity.NewClientCertRotator(frontProxyCA, shardidentity.FrontProxyUser, nil, o.CertValidity, o.FrontProxyClientCertFile(), o.FrontProxyClientKeyFile())
+	if s.secretStore != nil {
+		clientCert.WithSecretStore(s.secretStore, secretstore.Key(shardsSecretStoreKey, s.shardName(), "client"))
+		frontProxyClientCert.WithSecretStore(s.secretStore, secretstore.Key(shardsSecretStoreKey, s.shardName(), "front-proxy-client"))
+		s.shardClientCert = clientCert
+	}
+	rotators = append(rotators, clientCert, frontProxyClientCert)
+
+	for _, r := range rotators {
+		if err := r.Ensure(ctx); err != nil {
+			return nil, err
+		}
+		go r.Run(ctx)
+	}
+
+	return ca, nil
+}
+
+// loadOrCreateCA loads the CA from the given key of the secret store, if configured, or else from
+// the given files. The certificate is written to the certificate file in either case.
+func (s *Server) loadOrCreateCA(ctx context.Context, secretStoreKey, certFile, keyFile string) (*shardidentity.CA, error) {
+	if s.secretStore != nil {
+		return shardidentity.LoadOrCreateCAFromStore(ctx, s.secretStore, secretStoreKey, certFile)
+	}
+	return shardidentity.LoadOrCreateCA(certFile, keyFile)
+}
+
+// shardName returns the name of the ClusterWorkspaceShard of this kcp instance.
+func (s *Server) shardName() string {
+	if name := s.options.Extra.BootstrapTemplateVars["ShardName"]; name != "" {
+		return name
+	}
+	return "root"
+}
+
+// servesShardIdentityCert returns whether the serving certificate is issued from the kcp
+// shard CA, i.e. whether clients should verify the server with the CA.
+func (s *Server) servesShardIdentityCert() bool {
+	return s.options.ShardIdentity.Enabled && s.options.GenericControlPlane.SecureServing.ServerCert.CertKey.CertFile == s.options.ShardIdentity.ServingCertFile()
+}
@@ -22,6 +22,8 @@ import (
 	"net/url"
 	"path"
 
+	"go.opentelemetry.io/otel/trace"
+
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
@@ -40,7 +42,7 @@ type mux interface {
 	Handle(pattern string, handler http.Handler)
 }
 
-func (s *Server) installVirtualWorkspaces(ctx context.Context, kubeClusterClient kubernetesclient.ClusterInterface, dynamicClusterClient dynamic.ClusterInterface, kcpClusterClient kcpclient.ClusterInterface, auth genericapiserver.AuthenticationInfo, externalAddress string, preHandlerChainMux mux) error {
+func (s *Server) installVirtualWorkspaces(ctx context.Context, kubeClusterClient kubernetesclient.ClusterInterface, dynamicClusterClient dynamic.ClusterInterface, kcpClusterClient kcpclient.ClusterInterface, auth genericapiserver.AuthenticationInfo, tracerProvider *trace.TracerProvider, externalAddress string, preHandlerChainMux mux) error {
 	// create virtual workspaces
 	extraInformerStarts, virtualWorkspaces, err := s.options.Virtual.VirtualWorkspaces.NewVirtualWorkspaces(
 		virtualcommandoptions.DefaultRootPathPrefix,
@@ -69,6 +71,8 @@ func (s *Server) installVirtualWorkspaces(ctx context.Context, kubeClusterClient
 	codecs := serializer.NewCodecFactory(scheme)
 	recommendedConfig := genericapiserver.NewRecommendedConfig(codecs)
 	recommendedConfig.Authentication = auth
+	// requests are traced like those to kcp itself, with the forwarding storages propagating the trace context.
+	recommendedConfig.TracerProvider = tracerProvider
 	rootAPIServerConfig, err := virtualrootapiserver.NewRootAPIConfig(recommendedConfig, extraInformerStarts, virtualWorkspaces...)
 	if err != nil {
 		return err
@@ -94,6 +98,9 @@ func (s *Server) installVirtualWorkspaces(ctx context.Context, kubeClusterClient
 	klog.Infof("Starting virtual workspace apiserver")
 	preHandlerChainMux.Handle(virtualcommandoptions.DefaultRootPathPrefix+"/", preparedRootAPIServer.GenericAPIServer.Handler)
 
+	// expose the readiness of every virtual workspace also through the readyz endpoint of kcp
+	s.AddReadyzChecks(virtualrootapiserver.ReadyzChecks(virtualWorkspaces...)...)
+
 	return nil
 }
 
@@ -121,3 +128,17 @@ func (s *Server) installVirtualWorkspacesRedirect(ctx context.Context, preHandle
 
 	return nil
 }
+
+// bootstrapTemplateVars returns the variables of the bootstrapped root workspace resources,
+// defaulting the virtual workspace URL of the shard to the stand-alone virtual workspace
+// apiserver if virtual workspaces do not run in-process.
+func (s *Server) bootstrapTemplateVars() map[string]string {
+	vars := make(map[string]string, len(s.options.Extra.BootstrapTemplateVars)+1)
+	if !s.options.Virtual.Enabled && s.options.Virtual.ExternalVirtualWorkspaceAddress != "" {
+		vars["ShardVirtualWorkspaceURL"] = s.options.Virtual.ExternalVirtualWorkspaceAddress
+	}
+	for k, v := range s.options.Extra.BootstrapTemplateVars {
+		vars[k] = v
+	}
+	return vars
+}
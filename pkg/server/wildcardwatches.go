@@ -0,0 +1,115 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/apiserver/pkg/endpoints/handlers/responsewriters"
+	"k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/klog/v2"
+)
+
+const (
+	wildcardWatchClassUser   = "user"
+	wildcardWatchClassSystem = "system"
+)
+
+// wildcardWatchLimiter counts the concurrent watches in the * logical cluster per user.
+type wildcardWatchLimiter struct {
+	// limit is the maximal number of wildcard watches of a user, 0 means unlimited.
+	limit int
+
+	lock    sync.Mutex
+	watches map[string]int
+}
+
+func newWildcardWatchLimiter(limit int) *wildcardWatchLimiter {
+	return &wildcardWatchLimiter{
+		limit:   limit,
+		watches: map[string]int{},
+	}
+}
+
+// acquire counts a wildcard watch of the given user, or returns false if the user is at the limit.
+func (l *wildcardWatchLimiter) acquire(userName string) bool {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if l.limit > 0 && l.watches[userName] >= l.limit {
+		return false
+	}
+	l.watches[userName]++
+	return true
+}
+
+// release uncounts a wildcard watch of the given user.
+func (l *wildcardWatchLimiter) release(userName string) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	l.watches[userName]--
+	if l.watches[userName] <= 0 {
+		delete(l.watches, userName)
+	}
+}
+
+// WithWildcardWatchLimit rejects watches in the * logical cluster with 429 Too Many Requests if the user
+// already runs the maximal number of them, such that a single misbehaving client cannot destabilize a
+// shard. The loopback client and members of the system:masters group, e.g. kcp's own controllers, are
+// exempt from the limit.
+func WithWildcardWatchLimit(apiHandler http.Handler, limiter *wildcardWatchLimiter) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		cluster := request.ClusterFrom(req.Context())
+		requestInfo, ok := request.RequestInfoFrom(req.Context())
+		userInfo, hasUser := request.UserFrom(req.Context())
+		if cluster == nil || !cluster.Wildcard || !ok || requestInfo.Verb != "watch" || !hasUser {
+			apiHandler.ServeHTTP(w, req)
+			return
+		}
+
+		if userInfo.GetName() == user.APIServerUser || sets.NewString(userInfo.GetGroups()...).Has(user.SystemPrivilegedGroup) {
+			wildcardWatches.WithLabelValues(wildcardWatchClassSystem).Inc()
+			defer wildcardWatches.WithLabelValues(wildcardWatchClassSystem).Dec()
+			apiHandler.ServeHTTP(w, req)
+			return
+		}
+
+		if !limiter.acquire(userInfo.GetName()) {
+			klog.V(2).Infof("Rejecting wildcard watch of %s by user %q: too many wildcard watches", req.URL.Path, userInfo.GetName())
+			rejectedWildcardWatches.Inc()
+			responsewriters.ErrorNegotiated(
+				apierrors.NewTooManyRequests(fmt.Sprintf("too many concurrent watches in the %s logical cluster", cluster.Name), 1),
+				errorCodecs, schema.GroupVersion{Group: requestInfo.APIGroup, Version: requestInfo.APIVersion}, w, req,
+			)
+			return
+		}
+		wildcardWatches.WithLabelValues(wildcardWatchClassUser).Inc()
+		defer func() {
+			wildcardWatches.WithLabelValues(wildcardWatchClassUser).Dec()
+			limiter.release(userInfo.GetName())
+		}()
+
+		apiHandler.ServeHTTP(w, req)
+	}
+}
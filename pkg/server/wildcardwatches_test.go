@@ -0,0 +1,76 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kcp-dev/logicalcluster"
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/apiserver/pkg/endpoints/request"
+)
+
+func TestWithWildcardWatchLimit(t *testing.T) {
+	RegisterMetrics()
+
+	limiter := newWildcardWatchLimiter(1)
+	served := 0
+	handler := WithWildcardWatchLimit(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		served++
+	}), limiter)
+
+	serve := func(cluster logicalcluster.Name, verb string, userInfo user.Info) int {
+		req := httptest.NewRequest("GET", "/clusters/"+cluster.String()+"/api/v1/configmaps?watch=true", nil)
+		ctx := request.WithCluster(req.Context(), request.Cluster{Name: cluster, Wildcard: cluster == logicalcluster.Wildcard})
+		ctx = request.WithRequestInfo(ctx, &request.RequestInfo{IsResourceRequest: true, Verb: verb, APIVersion: "v1", Resource: "configmaps"})
+		ctx = request.WithUser(ctx, userInfo)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req.WithContext(ctx))
+		return rec.Code
+	}
+
+	alice := &user.DefaultInfo{Name: "alice"}
+	controller := &user.DefaultInfo{Name: "system:kcp:controller", Groups: []string{user.SystemPrivilegedGroup}}
+	loopback := &user.DefaultInfo{Name: user.APIServerUser}
+
+	// watches are released when they end
+	require.Equal(t, http.StatusOK, serve(logicalcluster.Wildcard, "watch", alice))
+	require.Equal(t, http.StatusOK, serve(logicalcluster.Wildcard, "watch", alice))
+	require.Equal(t, 2, served)
+
+	// alice runs a wildcard watch already
+	require.True(t, limiter.acquire("alice"))
+	require.Equal(t, http.StatusTooManyRequests, serve(logicalcluster.Wildcard, "watch", alice))
+	require.Equal(t, http.StatusOK, serve(logicalcluster.Wildcard, "list", alice))
+	require.Equal(t, http.StatusOK, serve(logicalcluster.New("root:org:ws"), "watch", alice))
+	require.Equal(t, 4, served)
+
+	// system users are exempt
+	require.True(t, limiter.acquire(controller.Name))
+	require.True(t, limiter.acquire(loopback.Name))
+	require.Equal(t, http.StatusOK, serve(logicalcluster.Wildcard, "watch", controller))
+	require.Equal(t, http.StatusOK, serve(logicalcluster.Wildcard, "watch", loopback))
+	require.Equal(t, 6, served)
+
+	limiter.release("alice")
+	require.Equal(t, http.StatusOK, serve(logicalcluster.Wildcard, "watch", alice))
+	require.Equal(t, 7, served)
+}
@@ -0,0 +1,128 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/kcp-dev/logicalcluster"
+
+	"k8s.io/apiserver/pkg/endpoints/request"
+	genericfilters "k8s.io/apiserver/pkg/server/filters"
+	"k8s.io/klog/v2"
+)
+
+// workspaceRequestTimeouts are the timeouts of non-long-running requests to workspaces and their descendants.
+type workspaceRequestTimeouts map[logicalcluster.Name]time.Duration
+
+// newWorkspaceRequestTimeouts parses the workspace=duration pairs of --workspace-request-timeouts.
+func newWorkspaceRequestTimeouts(timeouts map[string]string) (workspaceRequestTimeouts, error) {
+	parsed := workspaceRequestTimeouts{}
+	for workspace, timeout := range timeouts {
+		d, err := time.ParseDuration(timeout)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timeout %q of workspace %q: %w", timeout, workspace, err)
+		}
+		parsed[logicalcluster.New(workspace)] = d
+	}
+	return parsed, nil
+}
+
+// timeout returns the timeout of the given logical cluster, or of its closest ancestor with a timeout.
+func (t workspaceRequestTimeouts) timeout(clusterName logicalcluster.Name) (time.Duration, bool) {
+	for {
+		if d, found := t[clusterName]; found {
+			return d, true
+		}
+		parent, hasParent := clusterName.Parent()
+		if !hasParent {
+			return 0, false
+		}
+		clusterName = parent
+	}
+}
+
+// WithWorkspaceRequestDeadline shortens the deadline of non-long-running requests to workspaces with a
+// timeout, and responds with 504 Gateway Timeout when it is exceeded. Deadlines are never extended, i.e.
+// a timeout longer than the deadline set by --request-timeout or the timeout parameter has no effect.
+func WithWorkspaceRequestDeadline(apiHandler http.Handler, timeouts workspaceRequestTimeouts, longRunning request.LongRunningRequestCheck) http.Handler {
+	if len(timeouts) == 0 {
+		return apiHandler
+	}
+
+	timeoutHandler := genericfilters.WithTimeoutForNonLongRunningRequests(apiHandler, longRunning)
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		cluster := request.ClusterFrom(req.Context())
+		requestInfo, ok := request.RequestInfoFrom(req.Context())
+		if cluster == nil || cluster.Wildcard || !ok || longRunning(req, requestInfo) {
+			apiHandler.ServeHTTP(w, req)
+			return
+		}
+		timeout, found := timeouts.timeout(cluster.Name)
+		if !found {
+			apiHandler.ServeHTTP(w, req)
+			return
+		}
+		if deadline, hasDeadline := req.Context().Deadline(); hasDeadline && time.Until(deadline) <= timeout {
+			apiHandler.ServeHTTP(w, req)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(req.Context(), timeout)
+		defer cancel()
+		timeoutHandler.ServeHTTP(w, req.WithContext(ctx))
+	})
+}
+
+// WithSlowRequestLogging logs non-long-running requests taking longer than the given threshold, with
+// workspace, user, verb, resource and duration, such that operators can find tenants issuing pathological
+// requests. A threshold of 0 disables logging.
+func WithSlowRequestLogging(apiHandler http.Handler, threshold time.Duration, longRunning request.LongRunningRequestCheck) http.Handler {
+	if threshold <= 0 {
+		return apiHandler
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		requestInfo, ok := request.RequestInfoFrom(req.Context())
+		if !ok || longRunning(req, requestInfo) {
+			apiHandler.ServeHTTP(w, req)
+			return
+		}
+
+		start := time.Now()
+		apiHandler.ServeHTTP(w, req)
+		duration := time.Since(start)
+		if duration < threshold {
+			return
+		}
+
+		values := []interface{}{"verb", requestInfo.Verb, "path", req.URL.Path, "duration", duration}
+		if cluster := request.ClusterFrom(req.Context()); cluster != nil {
+			values = append(values, "workspace", cluster.Name)
+		}
+		if userInfo, hasUser := request.UserFrom(req.Context()); hasUser {
+			values = append(values, "user", userInfo.GetName())
+		}
+		if requestInfo.IsResourceRequest {
+			values = append(values, "group", requestInfo.APIGroup, "resource", requestInfo.Resource, "subresource", requestInfo.Subresource, "namespace", requestInfo.Namespace, "name", requestInfo.Name)
+		}
+		klog.InfoS("Slow request", values...)
+	})
+}
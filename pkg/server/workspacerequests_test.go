@@ -0,0 +1,104 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/kcp-dev/logicalcluster"
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/apiserver/pkg/endpoints/request"
+)
+
+func TestWorkspaceRequestTimeouts(t *testing.T) {
+	timeouts, err := newWorkspaceRequestTimeouts(map[string]string{"root:org": "10s", "root:org:team": "5s"})
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		want  time.Duration
+		found bool
+	}{
+		"root":              {},
+		"root:other":        {},
+		"root:org":          {want: 10 * time.Second, found: true},
+		"root:org:ws":       {want: 10 * time.Second, found: true},
+		"root:org:team":     {want: 5 * time.Second, found: true},
+		"root:org:team:ws":  {want: 5 * time.Second, found: true},
+		"root:organization": {},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, found := timeouts.timeout(logicalcluster.New(name))
+			require.Equal(t, tc.found, found)
+			require.Equal(t, tc.want, got)
+		})
+	}
+
+	_, err = newWorkspaceRequestTimeouts(map[string]string{"root:org": "soon"})
+	require.Error(t, err)
+}
+
+func TestWithWorkspaceRequestDeadline(t *testing.T) {
+	timeouts, err := newWorkspaceRequestTimeouts(map[string]string{"root:slow": "50ms"})
+	require.NoError(t, err)
+	longRunning := func(req *http.Request, requestInfo *request.RequestInfo) bool {
+		return requestInfo.Verb == "watch"
+	}
+
+	var deadline time.Time
+	var hasDeadline bool
+	handler := WithWorkspaceRequestDeadline(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		deadline, hasDeadline = req.Context().Deadline()
+		if req.URL.Query().Get("block") != "" {
+			<-req.Context().Done()
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}), timeouts, longRunning)
+
+	serve := func(ctx context.Context, cluster logicalcluster.Name, verb, query string) int {
+		req := httptest.NewRequest("GET", "/clusters/"+cluster.String()+"/api/v1/namespaces/default/configmaps?"+query, nil)
+		ctx = request.WithCluster(ctx, request.Cluster{Name: cluster, Wildcard: cluster == logicalcluster.Wildcard})
+		ctx = request.WithRequestInfo(ctx, &request.RequestInfo{IsResourceRequest: true, Verb: verb, APIVersion: "v1", Namespace: "default", Resource: "configmaps"})
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req.WithContext(ctx))
+		return rec.Code
+	}
+
+	require.Equal(t, http.StatusOK, serve(context.Background(), logicalcluster.New("root:slow:ws"), "list", ""))
+	require.True(t, hasDeadline, "requests to descendants must get the timeout")
+	require.WithinDuration(t, time.Now().Add(50*time.Millisecond), deadline, 50*time.Millisecond)
+
+	require.Equal(t, http.StatusOK, serve(context.Background(), logicalcluster.New("root:fast"), "list", ""))
+	require.False(t, hasDeadline, "requests to other workspaces must not get a timeout")
+
+	require.Equal(t, http.StatusOK, serve(context.Background(), logicalcluster.New("root:slow"), "watch", ""))
+	require.False(t, hasDeadline, "long-running requests must not get a timeout")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	expected, _ := ctx.Deadline()
+	require.Equal(t, http.StatusOK, serve(ctx, logicalcluster.New("root:slow"), "list", ""))
+	require.Equal(t, expected, deadline, "earlier deadlines must be kept")
+
+	require.Equal(t, http.StatusGatewayTimeout, serve(context.Background(), logicalcluster.New("root:slow"), "get", "block=true"))
+}
@@ -0,0 +1,243 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package shardidentity issues and rotates the certificates kcp shards and the kcp-front-proxy
+// use to authenticate each other via mutual TLS, from CAs shared by the shards.
+package shardidentity
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"os"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	certutil "k8s.io/client-go/util/cert"
+	"k8s.io/client-go/util/keyutil"
+	"k8s.io/klog/v2"
+
+	"github.com/kcp-dev/kcp/pkg/secretstore"
+)
+
+const (
+	// FrontProxyUser is the user name of the client certificates of the kcp-front-proxy.
+	FrontProxyUser = "kcp-front-proxy"
+
+	// ShardUserPrefix is the prefix of the user name of the client certificates of shards,
+	// followed by the shard name.
+	ShardUserPrefix = "system:kcp:shard:"
+)
+
+// CA is the kcp certificate authority issuing the serving and client certificates
+// shards and the front-proxy use to authenticate each other.
+type CA struct {
+	Cert *x509.Certificate
+	Key  crypto.Signer
+
+	certPEM []byte
+}
+
+// LoadOrCreateCA reads the CA from the given files, or creates a new self-signed CA
+// and writes it to them if neither of the files exists.
+func LoadOrCreateCA(certFile, keyFile string) (*CA, error) {
+	_, certErr := os.Stat(certFile)
+	_, keyErr := os.Stat(keyFile)
+	if os.IsNotExist(certErr) && os.IsNotExist(keyErr) {
+		klog.Infof("Generating kcp shard CA %s", certFile)
+		certPEM, keyPEM, err := generateCA()
+		if err != nil {
+			return nil, err
+		}
+		if err := keyutil.WriteKey(keyFile, keyPEM); err != nil {
+			return nil, fmt.Errorf("error writing CA key file %q: %w", keyFile, err)
+		}
+		if err := certutil.WriteCert(certFile, certPEM); err != nil {
+			return nil, fmt.Errorf("error writing CA certificate file %q: %w", certFile, err)
+		}
+	}
+
+	return LoadCA(certFile, keyFile)
+}
+
+// LoadOrCreateCAFromStore reads the CA from the given key of the secret store, or creates a new
+// self-signed CA there if the key has no data. Of shards creating the CA concurrently, all use the
+// one stored first. The private key is never written to disk. The certificate is written to the
+// given file, for the components configured with a client CA file.
+func LoadOrCreateCAFromStore(ctx context.Context, store secretstore.Store, key, certFile string) (*CA, error) {
+	data, err := store.Get(ctx, key)
+	if secretstore.IsNotFound(err) {
+		klog.Infof("Generating kcp shard CA in secret store key %s", key)
+		certPEM, keyPEM, err := generateCA()
+		if err != nil {
+			return nil, err
+		}
+		data = map[string][]byte{corev1.TLSCertKey: certPEM, corev1.TLSPrivateKeyKey: keyPEM}
+		if err := store.Create(ctx, key, data); secretstore.IsAlreadyExists(err) {
+			// another shard created the CA concurrently, use that one
+			klog.Infof("kcp shard CA in secret store key %s was created concurrently", key)
+			data, err = store.Get(ctx, key)
+			if err != nil {
+				return nil, fmt.Errorf("error reading CA from secret store key %q: %w", key, err)
+			}
+		} else if err != nil {
+			return nil, fmt.Errorf("error storing CA in secret store key %q: %w", key, err)
+		}
+	} else if err != nil {
+		return nil, fmt.Errorf("error reading CA from secret store key %q: %w", key, err)
+	}
+
+	ca, err := ParseCA(data[corev1.TLSCertKey], data[corev1.TLSPrivateKeyKey])
+	if err != nil {
+		return nil, err
+	}
+	if err := certutil.WriteCert(certFile, ca.CertPEM()); err != nil {
+		return nil, fmt.Errorf("error writing CA certificate file %q: %w", certFile, err)
+	}
+	return ca, nil
+}
+
+// generateCA returns the PEM encoded certificate and private key of a new self-signed CA.
+func generateCA() (certPEM, keyPEM []byte, err error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	cert, err := certutil.NewSelfSignedCACert(certutil.Config{CommonName: "kcp-shard-ca"}, key)
+	if err != nil {
+		return nil, nil, err
+	}
+	keyPEM, err = keyutil.MarshalPrivateKeyToPEM(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	return encodeCertPEM(cert.Raw), keyPEM, nil
+}
+
+// LoadCA reads the CA from the given files.
+func LoadCA(certFile, keyFile string) (*CA, error) {
+	certPEM, err := os.ReadFile(certFile)
+	if err != nil {
+		return nil, err
+	}
+	keyPEM, err := os.ReadFile(keyFile)
+	if err != nil {
+		return nil, err
+	}
+	return ParseCA(certPEM, keyPEM)
+}
+
+// ParseCA parses a CA from its PEM encoded certificate and private key.
+func ParseCA(certPEM, keyPEM []byte) (*CA, error) {
+	certs, err := certutil.ParseCertsPEM(certPEM)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse CA certificate: %w", err)
+	}
+	if !certs[0].IsCA {
+		return nil, fmt.Errorf("certificate %q is not a CA", certs[0].Subject.CommonName)
+	}
+	key, err := keyutil.ParsePrivateKeyPEM(keyPEM)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse CA key: %w", err)
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported CA key type %T", key)
+	}
+	return &CA{Cert: certs[0], Key: signer, certPEM: encodeCertPEM(certs[0].Raw)}, nil
+}
+
+// CertPEM returns the PEM encoded CA certificate.
+func (ca *CA) CertPEM() []byte {
+	return ca.certPEM
+}
+
+// CertPool returns a pool holding the CA certificate, to verify certificates issued by the CA.
+func (ca *CA) CertPool() *x509.CertPool {
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.Cert)
+	return pool
+}
+
+// IssueServingCert returns a PEM encoded serving certificate and key for the given
+// host names and IPs, valid for the given duration.
+func (ca *CA) IssueServingCert(name string, hosts []string, validity time.Duration) (certPEM, keyPEM []byte, err error) {
+	template := &x509.Certificate{
+		Subject:     pkix.Name{CommonName: name},
+		KeyUsage:    x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	for _, host := range hosts {
+		if ip := net.ParseIP(host); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else {
+			template.DNSNames = append(template.DNSNames, host)
+		}
+	}
+	return ca.issue(template, validity)
+}
+
+// IssueClientCert returns a PEM encoded client certificate and key authenticating as the
+// given user with the given groups, valid for the given duration.
+func (ca *CA) IssueClientCert(user string, groups []string, validity time.Duration) (certPEM, keyPEM []byte, err error) {
+	return ca.issue(&x509.Certificate{
+		Subject:     pkix.Name{CommonName: user, Organization: groups},
+		KeyUsage:    x509.KeyUsageKeyEncipherment | x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}, validity)
+}
+
+func (ca *CA) issue(template *x509.Certificate, validity time.Duration) (certPEM, keyPEM []byte, err error) {
+	serialNumber, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, nil, err
+	}
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	now := time.Now()
+	template.SerialNumber = serialNumber
+	template.NotBefore = now.Add(-time.Minute) // tolerate some clock skew
+	template.NotAfter = now.Add(validity)
+	if template.NotAfter.After(ca.Cert.NotAfter) {
+		template.NotAfter = ca.Cert.NotAfter
+	}
+	template.BasicConstraintsValid = true
+
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.Cert, key.Public(), ca.Key)
+	if err != nil {
+		return nil, nil, err
+	}
+	keyPEM, err = keyutil.MarshalPrivateKeyToPEM(key)
+	if err != nil {
+		return nil, nil, err
+	}
+	return encodeCertPEM(der), keyPEM, nil
+}
+
+func encodeCertPEM(der []byte) []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: certutil.CertificateBlockType, Bytes: der})
+}
@@ -0,0 +1,141 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package shardidentity
+
+import (
+	"context"
+	"crypto/x509"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	certutil "k8s.io/client-go/util/cert"
+
+	"github.com/kcp-dev/kcp/pkg/secretstore"
+)
+
+func TestLoadOrCreateCA(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile := filepath.Join(dir, "ca.crt"), filepath.Join(dir, "ca.key")
+
+	ca, err := LoadOrCreateCA(certFile, keyFile)
+	require.NoError(t, err)
+	require.True(t, ca.Cert.IsCA)
+
+	reloaded, err := LoadOrCreateCA(certFile, keyFile)
+	require.NoError(t, err)
+	require.Equal(t, ca.CertPEM(), reloaded.CertPEM(), "existing CA should be reused")
+
+	_, err = LoadOrCreateCA(certFile, filepath.Join(dir, "missing.key"))
+	require.Error(t, err, "a CA with a missing key should not be replaced")
+}
+
+func TestLoadOrCreateCAFromStore(t *testing.T) {
+	ctx := context.Background()
+	store := mapStore{}
+	certFile := filepath.Join(t.TempDir(), "ca.crt")
+
+	ca, err := LoadOrCreateCAFromStore(ctx, store, "shard-ca", certFile)
+	require.NoError(t, err)
+	require.True(t, ca.Cert.IsCA)
+	require.Contains(t, store, "shard-ca")
+	certPEM, err := os.ReadFile(certFile)
+	require.NoError(t, err)
+	require.Equal(t, ca.CertPEM(), certPEM, "the certificate should be written to the file")
+
+	reloaded, err := LoadOrCreateCAFromStore(ctx, store, "shard-ca", certFile)
+	require.NoError(t, err)
+	require.Equal(t, ca.CertPEM(), reloaded.CertPEM(), "existing CA should be reused")
+
+	// another shard creates the CA between our get and create
+	racing := &racingStore{mapStore: mapStore{}, other: mapStore{}}
+	other, err := LoadOrCreateCAFromStore(ctx, racing.other, "shard-ca", filepath.Join(t.TempDir(), "ca.crt"))
+	require.NoError(t, err)
+	lost, err := LoadOrCreateCAFromStore(ctx, racing, "shard-ca", certFile)
+	require.NoError(t, err)
+	require.Equal(t, other.CertPEM(), lost.CertPEM(), "the CA created first should be used")
+}
+
+// racingStore returns no data for the first get, as if the data of other was created after it.
+type racingStore struct {
+	mapStore
+	other mapStore
+	read  bool
+}
+
+func (s *racingStore) Get(ctx context.Context, key string) (map[string][]byte, error) {
+	if !s.read {
+		s.read = true
+		for k, v := range s.other {
+			s.mapStore[k] = v
+		}
+		return nil, secretstore.ErrNotFound
+	}
+	return s.mapStore.Get(ctx, key)
+}
+
+// mapStore is an in-memory secretstore.Store.
+type mapStore map[string]map[string][]byte
+
+func (s mapStore) Get(_ context.Context, key string) (map[string][]byte, error) {
+	data, ok := s[key]
+	if !ok {
+		return nil, secretstore.ErrNotFound
+	}
+	return data, nil
+}
+
+func (s mapStore) Put(_ context.Context, key string, data map[string][]byte) error {
+	s[key] = data
+	return nil
+}
+
+func (s mapStore) Create(_ context.Context, key string, data map[string][]byte) error {
+	if _, ok := s[key]; ok {
+		return secretstore.ErrAlreadyExists
+	}
+	s[key] = data
+	return nil
+}
+
+func TestIssue(t *testing.T) {
+	dir := t.TempDir()
+	ca, err := LoadOrCreateCA(filepath.Join(dir, "ca.crt"), filepath.Join(dir, "ca.key"))
+	require.NoError(t, err)
+
+	certPEM, _, err := ca.IssueServingCert("root", []string{"localhost", "127.0.0.1", "kcp.example.com"}, time.Hour)
+	require.NoError(t, err)
+	certs, err := certutil.ParseCertsPEM(certPEM)
+	require.NoError(t, err)
+	for _, host := range []string{"localhost", "127.0.0.1", "kcp.example.com"} {
+		_, err = certs[0].Verify(x509.VerifyOptions{DNSName: host, Roots: ca.CertPool()})
+		require.NoError(t, err, "serving certificate should be valid for %s", host)
+	}
+
+	certPEM, _, err = ca.IssueClientCert(FrontProxyUser, []string{"system:masters"}, time.Hour)
+	require.NoError(t, err)
+	certs, err = certutil.ParseCertsPEM(certPEM)
+	require.NoError(t, err)
+	_, err = certs[0].Verify(x509.VerifyOptions{Roots: ca.CertPool(), KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}})
+	require.NoError(t, err)
+	require.Equal(t, FrontProxyUser, certs[0].Subject.CommonName)
+	require.Equal(t, []string{"system:masters"}, certs[0].Subject.Organization)
+	require.WithinDuration(t, time.Now().Add(time.Hour), certs[0].NotAfter, time.Minute)
+}
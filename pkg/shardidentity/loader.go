@@ -0,0 +1,153 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package shardidentity
+
+import (
+	"context"
+	"crypto/tls"
+	"os"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/klog/v2"
+
+	"github.com/kcp-dev/kcp/pkg/secretstore"
+)
+
+// CertFileLoader serves a certificate from files that are rotated by another process,
+// e.g. the client certificate a shard issues for the kcp-front-proxy. The files are
+// read again when they have been modified.
+type CertFileLoader struct {
+	certFile, keyFile string
+
+	lock    sync.Mutex
+	modTime time.Time
+	current *tls.Certificate
+}
+
+// NewCertFileLoader returns a loader for the given certificate and key files.
+func NewCertFileLoader(certFile, keyFile string) *CertFileLoader {
+	return &CertFileLoader{certFile: certFile, keyFile: keyFile}
+}
+
+// Certificate returns the certificate from the files. While the files are being rotated,
+// i.e. do not form a valid pair, the previous certificate is returned.
+func (l *CertFileLoader) Certificate() (*tls.Certificate, error) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	modTime, err := l.latestModTime()
+	if err != nil {
+		if l.current != nil {
+			klog.V(2).Infof("Keeping certificate %s: %v", l.certFile, err)
+			return l.current, nil
+		}
+		return nil, err
+	}
+	if l.current != nil && !modTime.After(l.modTime) {
+		return l.current, nil
+	}
+
+	cert, err := l.load()
+	if err != nil {
+		if l.current != nil {
+			klog.V(2).Infof("Keeping certificate %s: %v", l.certFile, err)
+			return l.current, nil
+		}
+		return nil, err
+	}
+	l.current, l.modTime = cert, modTime
+	return cert, nil
+}
+
+func (l *CertFileLoader) load() (*tls.Certificate, error) {
+	certPEM, err := os.ReadFile(l.certFile)
+	if err != nil {
+		return nil, err
+	}
+	keyPEM, err := os.ReadFile(l.keyFile)
+	if err != nil {
+		return nil, err
+	}
+	cert, _, err := parseKeyPair(certPEM, keyPEM)
+	return cert, err
+}
+
+func (l *CertFileLoader) latestModTime() (time.Time, error) {
+	var latest time.Time
+	for _, file := range []string{l.certFile, l.keyFile} {
+		info, err := os.Stat(file)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest, nil
+}
+
+// CertStoreLoader serves a certificate from a key of the secret store that is rotated by another
+// process, e.g. the client certificate a shard issues for the kcp-front-proxy. The key is read
+// again when the certificate was loaded more than a minute ago.
+type CertStoreLoader struct {
+	store secretstore.Store
+	key   string
+
+	now func() time.Time
+
+	lock    sync.Mutex
+	loaded  time.Time
+	current *tls.Certificate
+}
+
+// NewCertStoreLoader returns a loader for the given key of the secret store.
+func NewCertStoreLoader(store secretstore.Store, key string) *CertStoreLoader {
+	return &CertStoreLoader{store: store, key: key, now: time.Now}
+}
+
+// Certificate returns the certificate from the secret store. If the store fails, the previous
+// certificate is returned.
+func (l *CertStoreLoader) Certificate() (*tls.Certificate, error) {
+	l.lock.Lock()
+	defer l.lock.Unlock()
+
+	if l.current != nil && l.now().Before(l.loaded.Add(rotationCheckInterval)) {
+		return l.current, nil
+	}
+
+	cert, err := l.load(context.Background())
+	if err != nil {
+		if l.current != nil {
+			klog.V(2).Infof("Keeping certificate of secret store key %s: %v", l.key, err)
+			return l.current, nil
+		}
+		return nil, err
+	}
+	l.current, l.loaded = cert, l.now()
+	return cert, nil
+}
+
+func (l *CertStoreLoader) load(ctx context.Context) (*tls.Certificate, error) {
+	data, err := l.store.Get(ctx, l.key)
+	if err != nil {
+		return nil, err
+	}
+	cert, _, err := parseKeyPair(data[corev1.TLSCertKey], data[corev1.TLSPrivateKeyKey])
+	return cert, err
+}
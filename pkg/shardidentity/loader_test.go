@@ -0,0 +1,115 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package shardidentity
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCertFileLoader(t *testing.T) {
+	dir := t.TempDir()
+	ca, err := LoadOrCreateCA(filepath.Join(dir, "ca.crt"), filepath.Join(dir, "ca.key"))
+	require.NoError(t, err)
+	certFile, keyFile := filepath.Join(dir, "client.crt"), filepath.Join(dir, "client.key")
+
+	l := NewCertFileLoader(certFile, keyFile)
+	_, err = l.Certificate()
+	require.Error(t, err, "files do not exist yet")
+
+	firstCert, firstKey, err := ca.IssueClientCert(FrontProxyUser, nil, time.Hour)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(keyFile, firstKey, 0600))
+	require.NoError(t, os.WriteFile(certFile, firstCert, 0600))
+	first, err := l.Certificate()
+	require.NoError(t, err)
+
+	t.Log("While the files are rotated, the previous certificate is kept")
+	secondCert, secondKey, err := ca.IssueClientCert(FrontProxyUser, nil, time.Hour)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(keyFile, secondKey, 0600))
+	later := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(keyFile, later, later))
+	cert, err := l.Certificate()
+	require.NoError(t, err)
+	require.Equal(t, first.Certificate, cert.Certificate)
+
+	t.Log("The rotated certificate is loaded once the pair is complete")
+	require.NoError(t, os.WriteFile(certFile, secondCert, 0600))
+	later = later.Add(time.Minute)
+	require.NoError(t, os.Chtimes(certFile, later, later))
+	cert, err = l.Certificate()
+	require.NoError(t, err)
+	require.NotEqual(t, first.Certificate, cert.Certificate)
+}
+
+func TestCertStoreLoader(t *testing.T) {
+	dir := t.TempDir()
+	ca, err := LoadOrCreateCA(filepath.Join(dir, "ca.crt"), filepath.Join(dir, "ca.key"))
+	require.NoError(t, err)
+	store := &failingStore{mapStore: mapStore{}}
+	now := time.Now()
+
+	l := NewCertStoreLoader(store, "shards/root/front-proxy-client")
+	l.now = func() time.Time { return now }
+	_, err = l.Certificate()
+	require.Error(t, err, "key does not exist yet")
+
+	r := NewClientCertRotator(ca, FrontProxyUser, nil, time.Hour, "", "").WithSecretStore(store, "shards/root/front-proxy-client")
+	require.NoError(t, r.Ensure(context.Background()))
+	first, err := l.Certificate()
+	require.NoError(t, err)
+
+	t.Log("The certificate is not read again within a minute")
+	r.now = func() time.Time { return time.Now().Add(time.Hour) }
+	require.NoError(t, r.Ensure(context.Background()))
+	cert, err := l.Certificate()
+	require.NoError(t, err)
+	require.Equal(t, first.Certificate, cert.Certificate)
+
+	t.Log("If the store fails, the previous certificate is kept")
+	now = now.Add(2 * time.Minute)
+	store.err = errors.New("store unavailable")
+	cert, err = l.Certificate()
+	require.NoError(t, err)
+	require.Equal(t, first.Certificate, cert.Certificate)
+
+	t.Log("The rotated certificate is read after a minute")
+	store.err = nil
+	cert, err = l.Certificate()
+	require.NoError(t, err)
+	require.NotEqual(t, first.Certificate, cert.Certificate)
+}
+
+// failingStore is a mapStore whose gets fail with err, if set.
+type failingStore struct {
+	mapStore
+	err error
+}
+
+func (s failingStore) Get(ctx context.Context, key string) (map[string][]byte, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return s.mapStore.Get(ctx, key)
+}
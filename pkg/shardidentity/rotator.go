@@ -0,0 +1,232 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package shardidentity
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	certutil "k8s.io/client-go/util/cert"
+	"k8s.io/client-go/util/keyutil"
+	"k8s.io/klog/v2"
+
+	"github.com/kcp-dev/kcp/pkg/secretstore"
+)
+
+// rotationCheckInterval is the interval in which rotators check whether the certificate is due for rotation.
+const rotationCheckInterval = time.Minute
+
+// Rotator keeps a certificate issued by the CA valid, re-issuing it when two thirds of its
+// lifetime have passed. If files are given, the certificate and key are persisted there, such that
+// consumers watching the files, e.g. the dynamic serving certificates of the apiserver, pick up
+// rotated certificates. With a secret store, they are persisted in the store instead, and never
+// written to disk. Other consumers use Certificate, e.g. through tls.Config.GetClientCertificate.
+type Rotator struct {
+	ca       *CA
+	name     string
+	groups   []string
+	hosts    []string
+	serving  bool
+	validity time.Duration
+
+	certFile, keyFile string
+
+	store    secretstore.Store
+	storeKey string
+
+	now func() time.Time
+
+	lock    sync.RWMutex
+	current *tls.Certificate
+	leaf    *x509.Certificate
+	certPEM []byte
+	keyPEM  []byte
+}
+
+// NewServingCertRotator returns a rotator for a serving certificate for the given hosts.
+func NewServingCertRotator(ca *CA, name string, hosts []string, validity time.Duration, certFile, keyFile string) *Rotator {
+	return &Rotator{ca: ca, name: name, hosts: hosts, serving: true, validity: validity, certFile: certFile, keyFile: keyFile, now: time.Now}
+}
+
+// NewClientCertRotator returns a rotator for a client certificate of the given user and groups.
+func NewClientCertRotator(ca *CA, user string, groups []string, validity time.Duration, certFile, keyFile string) *Rotator {
+	return &Rotator{ca: ca, name: user, groups: groups, validity: validity, certFile: certFile, keyFile: keyFile, now: time.Now}
+}
+
+// WithSecretStore persists the certificate and key in the given key of the secret store
+// instead of in files.
+func (r *Rotator) WithSecretStore(store secretstore.Store, key string) *Rotator {
+	r.store, r.storeKey = store, key
+	r.certFile, r.keyFile = "", ""
+	return r
+}
+
+// Ensure issues a new certificate if there is none, if the persisted one was not issued by
+// the CA for the same subject, or if it is due for rotation.
+func (r *Rotator) Ensure(ctx context.Context) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.current == nil && (r.certFile != "" || r.store != nil) {
+		if certPEM, keyPEM, err := r.load(ctx); err != nil {
+			klog.V(2).Infof("Not using existing certificate for %q: %v", r.name, err)
+		} else if err := r.setCurrent(certPEM, keyPEM); err != nil {
+			return err
+		}
+	}
+	if r.current != nil && r.now().Before(rotationDeadline(r.leaf)) {
+		return nil
+	}
+
+	var certPEM, keyPEM []byte
+	var err error
+	if r.serving {
+		certPEM, keyPEM, err = r.ca.IssueServingCert(r.name, r.hosts, r.validity)
+	} else {
+		certPEM, keyPEM, err = r.ca.IssueClientCert(r.name, r.groups, r.validity)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to issue certificate for %q: %w", r.name, err)
+	}
+	switch {
+	case r.store != nil:
+		if err := r.store.Put(ctx, r.storeKey, map[string][]byte{corev1.TLSCertKey: certPEM, corev1.TLSPrivateKeyKey: keyPEM}); err != nil {
+			return fmt.Errorf("error storing certificate in secret store key %q: %w", r.storeKey, err)
+		}
+	case r.certFile != "":
+		// write the key first. Consumers watching the files fail to load a mismatching pair and retry.
+		if err := keyutil.WriteKey(r.keyFile, keyPEM); err != nil {
+			return fmt.Errorf("error writing key file %q: %w", r.keyFile, err)
+		}
+		if err := certutil.WriteCert(r.certFile, certPEM); err != nil {
+			return fmt.Errorf("error writing certificate file %q: %w", r.certFile, err)
+		}
+	}
+	if err := r.setCurrent(certPEM, keyPEM); err != nil {
+		return err
+	}
+	klog.Infof("Issued certificate for %q valid until %s", r.name, r.leaf.NotAfter.Format(time.RFC3339))
+	return nil
+}
+
+func (r *Rotator) setCurrent(certPEM, keyPEM []byte) error {
+	cert, leaf, err := parseKeyPair(certPEM, keyPEM)
+	if err != nil {
+		return err
+	}
+	r.current, r.leaf, r.certPEM, r.keyPEM = cert, leaf, certPEM, keyPEM
+	return nil
+}
+
+// Certificate returns the current certificate.
+func (r *Rotator) Certificate() (*tls.Certificate, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	if r.current == nil {
+		return nil, errors.New("no certificate issued yet")
+	}
+	return r.current, nil
+}
+
+// PEM returns the PEM encoded current certificate and key.
+func (r *Rotator) PEM() (certPEM, keyPEM []byte, err error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	if r.current == nil {
+		return nil, nil, errors.New("no certificate issued yet")
+	}
+	return r.certPEM, r.keyPEM, nil
+}
+
+// Run rotates the certificate until the context is done.
+func (r *Rotator) Run(ctx context.Context) {
+	wait.UntilWithContext(ctx, func(ctx context.Context) {
+		if err := r.Ensure(ctx); err != nil {
+			runtime.HandleError(err)
+		}
+	}, rotationCheckInterval)
+}
+
+// load reads the persisted certificate and key, and verifies that they were issued by the CA
+// for the subject of the rotator.
+func (r *Rotator) load(ctx context.Context) (certPEM, keyPEM []byte, err error) {
+	if r.store != nil {
+		data, err := r.store.Get(ctx, r.storeKey)
+		if err != nil {
+			return nil, nil, err
+		}
+		certPEM, keyPEM = data[corev1.TLSCertKey], data[corev1.TLSPrivateKeyKey]
+	} else {
+		if certPEM, err = os.ReadFile(r.certFile); err != nil {
+			return nil, nil, err
+		}
+		if keyPEM, err = os.ReadFile(r.keyFile); err != nil {
+			return nil, nil, err
+		}
+	}
+	_, leaf, err := parseKeyPair(certPEM, keyPEM)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := leaf.CheckSignatureFrom(r.ca.Cert); err != nil {
+		return nil, nil, fmt.Errorf("not issued by the CA: %w", err)
+	}
+	if leaf.Subject.CommonName != r.name {
+		return nil, nil, fmt.Errorf("issued for %q", leaf.Subject.CommonName)
+	}
+	if !sets.NewString(leaf.Subject.Organization...).Equal(sets.NewString(r.groups...)) {
+		return nil, nil, fmt.Errorf("issued for groups %v", leaf.Subject.Organization)
+	}
+	for _, host := range r.hosts {
+		if err := leaf.VerifyHostname(host); err != nil {
+			return nil, nil, err
+		}
+	}
+	return certPEM, keyPEM, nil
+}
+
+func parseKeyPair(certPEM, keyPEM []byte) (*tls.Certificate, *x509.Certificate, error) {
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return nil, nil, err
+	}
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	if err != nil {
+		return nil, nil, err
+	}
+	cert.Leaf = leaf
+	return &cert, leaf, nil
+}
+
+// rotationDeadline returns the time after which the certificate is rotated, i.e. when
+// two thirds of its lifetime have passed.
+func rotationDeadline(leaf *x509.Certificate) time.Time {
+	lifetime := leaf.NotAfter.Sub(leaf.NotBefore)
+	return leaf.NotBefore.Add(lifetime * 2 / 3)
+}
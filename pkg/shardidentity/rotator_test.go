@@ -0,0 +1,122 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package shardidentity
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRotator(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	ca, err := LoadOrCreateCA(filepath.Join(dir, "ca.crt"), filepath.Join(dir, "ca.key"))
+	require.NoError(t, err)
+	certFile, keyFile := filepath.Join(dir, "client.crt"), filepath.Join(dir, "client.key")
+
+	r := NewClientCertRotator(ca, "system:kcp:shard:root", []string{"system:masters"}, 3*time.Hour, certFile, keyFile)
+	_, err = r.Certificate()
+	require.Error(t, err)
+	require.NoError(t, r.Ensure(ctx))
+	first, err := r.Certificate()
+	require.NoError(t, err)
+	firstPEM, err := os.ReadFile(certFile)
+	require.NoError(t, err)
+
+	t.Log("A restarted rotator reuses the certificate on disk")
+	r = NewClientCertRotator(ca, "system:kcp:shard:root", []string{"system:masters"}, 3*time.Hour, certFile, keyFile)
+	require.NoError(t, r.Ensure(ctx))
+	cert, err := r.Certificate()
+	require.NoError(t, err)
+	require.Equal(t, first.Certificate, cert.Certificate)
+
+	t.Log("The certificate is kept until two thirds of its lifetime passed")
+	r.now = func() time.Time { return time.Now().Add(time.Hour) }
+	require.NoError(t, r.Ensure(ctx))
+	cert, err = r.Certificate()
+	require.NoError(t, err)
+	require.Equal(t, first.Certificate, cert.Certificate)
+
+	t.Log("The certificate is rotated after two thirds of its lifetime")
+	r.now = func() time.Time { return time.Now().Add(2*time.Hour + time.Minute) }
+	require.NoError(t, r.Ensure(ctx))
+	cert, err = r.Certificate()
+	require.NoError(t, err)
+	require.NotEqual(t, first.Certificate, cert.Certificate)
+	rotatedPEM, err := os.ReadFile(certFile)
+	require.NoError(t, err)
+	require.NotEqual(t, firstPEM, rotatedPEM, "rotated certificate should be written to disk")
+
+	t.Log("A certificate on disk for another subject is replaced")
+	r = NewClientCertRotator(ca, "system:kcp:shard:other", nil, 3*time.Hour, certFile, keyFile)
+	require.NoError(t, r.Ensure(ctx))
+	cert, err = r.Certificate()
+	require.NoError(t, err)
+	require.Equal(t, "system:kcp:shard:other", cert.Leaf.Subject.CommonName)
+
+	t.Log("A certificate on disk from another CA is replaced")
+	otherCA, err := LoadOrCreateCA(filepath.Join(dir, "other-ca.crt"), filepath.Join(dir, "other-ca.key"))
+	require.NoError(t, err)
+	r = NewClientCertRotator(otherCA, "system:kcp:shard:other", nil, 3*time.Hour, certFile, keyFile)
+	require.NoError(t, r.Ensure(ctx))
+	cert, err = r.Certificate()
+	require.NoError(t, err)
+	require.NoError(t, cert.Leaf.CheckSignatureFrom(otherCA.Cert))
+}
+
+func TestRotatorWithSecretStore(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	ca, err := LoadOrCreateCA(filepath.Join(dir, "ca.crt"), filepath.Join(dir, "ca.key"))
+	require.NoError(t, err)
+	store := mapStore{}
+	certFile, keyFile := filepath.Join(dir, "client.crt"), filepath.Join(dir, "client.key")
+
+	r := NewClientCertRotator(ca, "system:kcp:shard:root", []string{"system:masters"}, 3*time.Hour, certFile, keyFile).WithSecretStore(store, "shards/root/client")
+	require.NoError(t, r.Ensure(ctx))
+	first, err := r.Certificate()
+	require.NoError(t, err)
+	require.Contains(t, store, "shards/root/client")
+	require.NoFileExists(t, certFile, "the certificate should only be kept in the store")
+	require.NoFileExists(t, keyFile, "the key should only be kept in the store")
+	certPEM, keyPEM, err := r.PEM()
+	require.NoError(t, err)
+	require.Equal(t, store["shards/root/client"]["tls.crt"], certPEM)
+	require.Equal(t, store["shards/root/client"]["tls.key"], keyPEM)
+
+	t.Log("A restarted rotator reuses the certificate in the store")
+	r = NewClientCertRotator(ca, "system:kcp:shard:root", []string{"system:masters"}, 3*time.Hour, "", "").WithSecretStore(store, "shards/root/client")
+	require.NoError(t, r.Ensure(ctx))
+	cert, err := r.Certificate()
+	require.NoError(t, err)
+	require.Equal(t, first.Certificate, cert.Certificate)
+
+	t.Log("The rotated certificate is stored")
+	r.now = func() time.Time { return time.Now().Add(2*time.Hour + time.Minute) }
+	require.NoError(t, r.Ensure(ctx))
+	cert, err = r.Certificate()
+	require.NoError(t, err)
+	require.NotEqual(t, first.Certificate, cert.Certificate)
+	certPEM, _, err = r.PEM()
+	require.NoError(t, err)
+	require.Equal(t, store["shards/root/client"]["tls.crt"], certPEM)
+}
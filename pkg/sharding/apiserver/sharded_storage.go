@@ -31,8 +31,12 @@ import (
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/apiserver/pkg/endpoints/request"
 	"k8s.io/apiserver/pkg/registry/rest"
+	"k8s.io/apiserver/pkg/warning"
 )
 
+// inconsistentListWarning is returned as warning for lists spanning multiple shards.
+const inconsistentListWarning = "the list spans %d shards read at different resourceVersions, it is not a consistent snapshot across shards"
+
 type shardedStorage struct {
 	storageBase
 }
@@ -168,6 +172,11 @@ func (s *shardedStorage) List(ctx context.Context, options *internalversion.List
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse sharded chunked state: %w", err)
 	}
+	// Every shard is read at its own resourceVersion. Hence, the list is only a consistent snapshot
+	// if it is served by a single shard.
+	if len(shardIdentifiers) > 1 {
+		warning.AddWarning(ctx, "", fmt.Sprintf(inconsistentListWarning, len(shardIdentifiers)))
+	}
 	var output *unstructured.UnstructuredList
 	for {
 		shard, continueToken, err := state.NextQuery()
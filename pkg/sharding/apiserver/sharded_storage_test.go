@@ -0,0 +1,90 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apiserver
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/apimachinery/pkg/apis/meta/internalversion"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/apiserver/pkg/warning"
+	clientrest "k8s.io/client-go/rest"
+)
+
+type recordingWarnings []string
+
+func (r *recordingWarnings) AddWarning(agent, text string) {
+	*r = append(*r, text)
+}
+
+func TestShardedListWarning(t *testing.T) {
+	shard := func(resourceVersion string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprintf(w, `{"apiVersion":"v1","kind":"ConfigMapList","metadata":{"resourceVersion":%q},"items":[{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"cm","resourceVersion":%q}}]}`, resourceVersion, resourceVersion)
+		}))
+	}
+	one, two := shard("10"), shard("20")
+	defer one.Close()
+	defer two.Close()
+
+	scheme := runtime.NewScheme()
+	metav1.AddToGroupVersion(scheme, metav1.SchemeGroupVersion)
+	negotiatedSerializer := &unstructuredNegotiatedSerializer{scheme: &delegatingUnstructuredScheme{delegate: scheme}}
+
+	tests := map[string]struct {
+		shards       map[string]*clientrest.Config
+		wantItems    int
+		wantWarnings []string
+	}{
+		"single shard": {
+			shards:    map[string]*clientrest.Config{"one": {Host: one.URL}},
+			wantItems: 1,
+		},
+		"multiple shards": {
+			shards:       map[string]*clientrest.Config{"one": {Host: one.URL}, "two": {Host: two.URL}},
+			wantItems:    2,
+			wantWarnings: []string{"the list spans 2 shards read at different resourceVersions, it is not a consistent snapshot across shards"},
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			s := &shardedStorage{storageBase: storageBase{
+				shards:                         tc.shards,
+				shardIdentifierResourceVersion: 1,
+				requestFor:                     requestFor(httptest.NewRequest(http.MethodGet, "/clusters/*/api/v1/configmaps", nil)),
+				clientFor:                      clientFor(&user.DefaultInfo{Name: "user"}, negotiatedSerializer),
+			}}
+
+			var warnings recordingWarnings
+			ctx := warning.WithWarningRecorder(context.Background(), &warnings)
+			list, err := s.List(ctx, &internalversion.ListOptions{})
+			require.NoError(t, err)
+			require.Len(t, list.(*unstructured.UnstructuredList).Items, tc.wantItems)
+			require.Equal(t, tc.wantWarnings, []string(warnings))
+		})
+	}
+}
@@ -22,11 +22,16 @@ import (
 
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/klog/v2"
 )
 
 type ClientLoader struct {
 	sync.RWMutex
 	clients map[string]*rest.Config
+
+	// clientCertificate returns the PEM encoded client certificate and key for clients not
+	// having credentials of their own. It is nil if those clients are not given a certificate.
+	clientCertificate func() (certPEM, keyPEM []byte, err error)
 }
 
 func NewClientLoader() *ClientLoader {
@@ -58,20 +63,63 @@ func (c *ClientLoader) AddKubeConfigContexts(path string) error {
 		}
 		contextCfg.ContentType = "application/json"
 		c.clients[context] = contextCfg
-		c.Unlock()
 	}
 
 	return nil
 }
 
+// DefaultTLSClientConfig sets the client certificate and CA of the given TLS config on the
+// clients not having credentials or a CA of their own, e.g. to authenticate to peer shards
+// with a client certificate issued from the kcp shard CA.
+func (c *ClientLoader) DefaultTLSClientConfig(tlsConfig rest.TLSClientConfig) {
+	c.Lock()
+	defer c.Unlock()
+
+	for _, cfg := range c.clients {
+		if !hasCredentials(cfg) {
+			cfg.CertFile = tlsConfig.CertFile
+			cfg.KeyFile = tlsConfig.KeyFile
+		}
+		if cfg.CAFile == "" && len(cfg.CAData) == 0 && !cfg.Insecure {
+			cfg.CAFile = tlsConfig.CAFile
+		}
+	}
+}
+
+// DefaultClientCertificate sets the client certificate returned by certificate on the clients
+// not having credentials of their own, every time the clients are returned. Use it for client
+// certificates that are rotated in memory instead of on disk, e.g. when kept in the secret store.
+func (c *ClientLoader) DefaultClientCertificate(certificate func() (certPEM, keyPEM []byte, err error)) {
+	c.Lock()
+	defer c.Unlock()
+	c.clientCertificate = certificate
+}
+
 func (c *ClientLoader) Clients() map[string]*rest.Config {
 	c.Lock()
 	defer c.Unlock()
 
+	var certPEM, keyPEM []byte
+	if c.clientCertificate != nil {
+		var err error
+		if certPEM, keyPEM, err = c.clientCertificate(); err != nil {
+			klog.Errorf("Failed to get the client certificate for shards: %v", err)
+		}
+	}
+
 	out := make(map[string]*rest.Config, len(c.clients))
 	for key, value := range c.clients {
-		out[key] = rest.CopyConfig(value)
+		cfg := rest.CopyConfig(value)
+		if len(certPEM) > 0 && !hasCredentials(cfg) {
+			cfg.CertData, cfg.KeyData = certPEM, keyPEM
+		}
+		out[key] = cfg
 	}
 
 	return out
 }
+
+func hasCredentials(cfg *rest.Config) bool {
+	return cfg.BearerToken != "" || cfg.BearerTokenFile != "" || cfg.Username != "" ||
+		cfg.CertFile != "" || len(cfg.CertData) > 0 || cfg.ExecProvider != nil || cfg.AuthProvider != nil
+}
@@ -0,0 +1,48 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sharding
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/client-go/rest"
+)
+
+func TestClientLoaderDefaultClientCertificate(t *testing.T) {
+	l := NewClientLoader()
+	l.Add("peer", &rest.Config{Host: "https://peer:6443"})
+	l.Add("token", &rest.Config{Host: "https://token:6443", BearerToken: "token"})
+	l.DefaultTLSClientConfig(rest.TLSClientConfig{CAFile: "shard-ca.crt"})
+
+	cert := "first"
+	l.DefaultClientCertificate(func() ([]byte, []byte, error) {
+		return []byte(cert + " cert"), []byte(cert + " key"), nil
+	})
+
+	clients := l.Clients()
+	require.Equal(t, []byte("first cert"), clients["peer"].CertData)
+	require.Equal(t, []byte("first key"), clients["peer"].KeyData)
+	require.Equal(t, "shard-ca.crt", clients["peer"].CAFile)
+	require.Empty(t, clients["token"].CertData, "clients with credentials keep them")
+
+	t.Log("A rotated certificate is used by the clients returned next")
+	cert = "second"
+	require.Equal(t, []byte("second cert"), l.Clients()["peer"].CertData)
+	require.Equal(t, []byte("first cert"), clients["peer"].CertData, "returned clients are copies")
+}
@@ -0,0 +1,166 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package syncer
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/kcp-dev/logicalcluster"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/client-go/rest"
+
+	apiresourcev1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apiresource/v1alpha1"
+	kcpclient "github.com/kcp-dev/kcp/pkg/client/clientset/versioned"
+	"github.com/kcp-dev/kcp/pkg/crdpuller"
+	"github.com/kcp-dev/kcp/pkg/schemacompat"
+)
+
+// APIImportAction is what the API importer does with the APIResourceImport of an API of the physical cluster.
+type APIImportAction string
+
+const (
+	// APIImportActionCreate means that the APIResourceImport does not exist yet and is created.
+	APIImportActionCreate APIImportAction = "Create"
+	// APIImportActionUpdate means that the schema of the existing APIResourceImport is updated.
+	APIImportActionUpdate APIImportAction = "Update"
+	// APIImportActionNone means that the existing APIResourceImport is up to date.
+	APIImportActionNone APIImportAction = "None"
+)
+
+// APIImportPlanEntry describes the import of one API of the physical cluster.
+type APIImportPlanEntry struct {
+	GVR    metav1.GroupVersionResource
+	Action APIImportAction
+
+	// Negotiated is true if the workspace already has a NegotiatedAPIResource for the API.
+	Negotiated bool
+	// Conflict is the reason why the API is not compatible with the API negotiated in
+	// the workspace, empty if it is compatible.
+	Conflict string
+}
+
+// PlanAPIImport returns what the API importer would import and negotiate for the given resources of the
+// physical cluster into the given logical cluster, and which APIs conflict with the APIs negotiated in the
+// workspace, without making any change.
+func PlanAPIImport(ctx context.Context, upstreamConfig, downstreamConfig *rest.Config, resourcesToSync []string, logicalClusterName logicalcluster.Name, location string) ([]APIImportPlanEntry, error) {
+	agent := fmt.Sprintf("kcp-workload-api-importer-%s-%s", logicalClusterName, location)
+	upstreamConfig = rest.AddUserAgent(rest.CopyConfig(upstreamConfig), agent)
+	downstreamConfig = rest.AddUserAgent(rest.CopyConfig(downstreamConfig), agent)
+
+	kcpClusterClient, err := kcpclient.NewClusterForConfig(upstreamConfig)
+	if err != nil {
+		return nil, err
+	}
+	kcpClient := kcpClusterClient.Cluster(logicalClusterName)
+
+	schemaPuller, err := crdpuller.NewSchemaPuller(downstreamConfig)
+	if err != nil {
+		return nil, err
+	}
+	crds, err := schemaPuller.PullCRDs(ctx, resourcesToSync...)
+	if err != nil {
+		return nil, fmt.Errorf("error pulling CRDs: %w", err)
+	}
+
+	imports, err := kcpClient.ApiresourceV1alpha1().APIResourceImports().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("error listing APIResourceImports: %w", err)
+	}
+
+	return planAPIImport(crds, imports.Items, location, func(name string) (*apiresourcev1alpha1.NegotiatedAPIResource, error) {
+		return kcpClient.ApiresourceV1alpha1().NegotiatedAPIResources().Get(ctx, name, metav1.GetOptions{})
+	})
+}
+
+func planAPIImport(
+	crds map[schema.GroupResource]*apiextensionsv1.CustomResourceDefinition,
+	imports []apiresourcev1alpha1.APIResourceImport,
+	location string,
+	getNegotiatedAPIResource func(name string) (*apiresourcev1alpha1.NegotiatedAPIResource, error),
+) ([]APIImportPlanEntry, error) {
+	var plan []APIImportPlanEntry
+	for groupResource, pulledCrd := range crds {
+		crdVersion := pulledCrd.Spec.Versions[0]
+		gvr := metav1.GroupVersionResource{
+			Group:    pulledCrd.Spec.Group,
+			Version:  crdVersion.Name,
+			Resource: groupResource.Resource,
+		}
+		var importSchema *apiextensionsv1.JSONSchemaProps
+		if crdVersion.Schema != nil {
+			importSchema = crdVersion.Schema.OpenAPIV3Schema
+		}
+
+		entry := APIImportPlanEntry{GVR: gvr, Action: APIImportActionCreate}
+		for i := range imports {
+			apiResourceImport := &imports[i]
+			if apiResourceImport.Spec.Location != location || apiResourceImport.GVR() != gvr {
+				continue
+			}
+			existingSchema, err := apiResourceImport.Spec.GetSchema()
+			if err != nil {
+				return nil, fmt.Errorf("error getting schema of APIResourceImport %s: %w", apiResourceImport.Name, err)
+			}
+			entry.Action = APIImportActionNone
+			if !equality.Semantic.DeepEqual(existingSchema, importSchema) {
+				entry.Action = APIImportActionUpdate
+			}
+			break
+		}
+
+		negotiatedAPIResource, err := getNegotiatedAPIResource(negotiatedAPIResourceName(gvr))
+		if err != nil && !apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("error getting NegotiatedAPIResource of %s: %w", gvr.String(), err)
+		}
+		if err == nil {
+			entry.Negotiated = true
+			negotiatedSchema, err := negotiatedAPIResource.Spec.GetSchema()
+			if err != nil {
+				return nil, fmt.Errorf("error getting schema of NegotiatedAPIResource %s: %w", negotiatedAPIResource.Name, err)
+			}
+			// the same check as the negotiation, for a new import, which is allowed to update unpublished APIs
+			allowUpdateNegotiatedSchema := !negotiatedAPIResource.IsConditionTrue(apiresourcev1alpha1.Enforced) &&
+				apiresourcev1alpha1.UpdateUnpublished.CanUpdate(negotiatedAPIResource.IsConditionTrue(apiresourcev1alpha1.Published))
+			if _, err := schemacompat.EnsureStructuralSchemaCompatibility(field.NewPath(negotiatedAPIResource.Spec.Kind), negotiatedSchema, importSchema, allowUpdateNegotiatedSchema); err != nil {
+				entry.Conflict = err.Error()
+			}
+		}
+
+		plan = append(plan, entry)
+	}
+
+	sort.Slice(plan, func(i, j int) bool {
+		return plan[i].GVR.String() < plan[j].GVR.String()
+	})
+
+	return plan, nil
+}
+
+func negotiatedAPIResourceName(gvr metav1.GroupVersionResource) string {
+	if gvr.Group == "" {
+		return gvr.Resource + "." + gvr.Version + ".core"
+	}
+	return gvr.Resource + "." + gvr.Version + "." + gvr.Group
+}
@@ -0,0 +1,117 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package syncer
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	apiresourcev1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apiresource/v1alpha1"
+)
+
+func TestPlanAPIImport(t *testing.T) {
+	objectSchema := func(specType string) *apiextensionsv1.JSONSchemaProps {
+		return &apiextensionsv1.JSONSchemaProps{
+			Type: "object",
+			Properties: map[string]apiextensionsv1.JSONSchemaProps{
+				"spec": {Type: specType},
+			},
+		}
+	}
+	crd := func(group, resource string, s *apiextensionsv1.JSONSchemaProps) *apiextensionsv1.CustomResourceDefinition {
+		return &apiextensionsv1.CustomResourceDefinition{
+			Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+				Group: group,
+				Names: apiextensionsv1.CustomResourceDefinitionNames{Plural: resource, Kind: "Kind"},
+				Versions: []apiextensionsv1.CustomResourceDefinitionVersion{{
+					Name:   "v1",
+					Schema: &apiextensionsv1.CustomResourceValidation{OpenAPIV3Schema: s},
+				}},
+			},
+		}
+	}
+	apiResourceImport := func(location, group, resource string, s *apiextensionsv1.JSONSchemaProps) apiresourcev1alpha1.APIResourceImport {
+		i := apiresourcev1alpha1.APIResourceImport{
+			Spec: apiresourcev1alpha1.APIResourceImportSpec{
+				Location: location,
+				CommonAPIResourceSpec: apiresourcev1alpha1.CommonAPIResourceSpec{
+					GroupVersion:                  apiresourcev1alpha1.GroupVersion{Group: group, Version: "v1"},
+					CustomResourceDefinitionNames: apiextensionsv1.CustomResourceDefinitionNames{Plural: resource},
+				},
+			},
+		}
+		require.NoError(t, i.Spec.SetSchema(s))
+		return i
+	}
+	negotiated := func(s *apiextensionsv1.JSONSchemaProps) *apiresourcev1alpha1.NegotiatedAPIResource {
+		r := &apiresourcev1alpha1.NegotiatedAPIResource{}
+		r.Spec.Kind = "Kind"
+		require.NoError(t, r.Spec.SetSchema(s))
+		r.SetCondition(apiresourcev1alpha1.NegotiatedAPIResourceCondition{Type: apiresourcev1alpha1.Enforced, Status: metav1.ConditionTrue})
+		return r
+	}
+
+	crds := map[schema.GroupResource]*apiextensionsv1.CustomResourceDefinition{
+		{Resource: "services"}:                              crd("", "services", objectSchema("object")),
+		{Group: "apps", Resource: "deployments"}:            crd("apps", "deployments", objectSchema("object")),
+		{Group: "example.io", Resource: "widgets"}:          crd("example.io", "widgets", objectSchema("string")),
+		{Group: "networking.k8s.io", Resource: "ingresses"}: crd("networking.k8s.io", "ingresses", objectSchema("object")),
+	}
+	imports := []apiresourcev1alpha1.APIResourceImport{
+		apiResourceImport("east", "apps", "deployments", objectSchema("object")),
+		apiResourceImport("east", "example.io", "widgets", objectSchema("object")),
+		apiResourceImport("west", "networking.k8s.io", "ingresses", objectSchema("object")),
+	}
+	negotiatedAPIResources := map[string]*apiresourcev1alpha1.NegotiatedAPIResource{
+		"deployments.v1.apps":   negotiated(objectSchema("object")),
+		"widgets.v1.example.io": negotiated(objectSchema("object")),
+		"services.v1.core":      negotiated(objectSchema("object")),
+	}
+
+	plan, err := planAPIImport(crds, imports, "east", func(name string) (*apiresourcev1alpha1.NegotiatedAPIResource, error) {
+		if r, ok := negotiatedAPIResources[name]; ok {
+			return r, nil
+		}
+		return nil, apierrors.NewNotFound(apiresourcev1alpha1.Resource("negotiatedapiresources"), name)
+	})
+	require.NoError(t, err)
+	require.Len(t, plan, 4)
+
+	byResource := map[string]APIImportPlanEntry{}
+	for _, entry := range plan {
+		byResource[entry.GVR.Resource] = entry
+	}
+
+	require.Equal(t, APIImportActionNone, byResource["deployments"].Action)
+	require.True(t, byResource["deployments"].Negotiated)
+	require.Empty(t, byResource["deployments"].Conflict)
+
+	require.Equal(t, APIImportActionUpdate, byResource["widgets"].Action)
+	require.NotEmpty(t, byResource["widgets"].Conflict, "string spec is incompatible with the enforced object spec")
+
+	require.Equal(t, APIImportActionCreate, byResource["ingresses"].Action, "the import of another location does not count")
+	require.False(t, byResource["ingresses"].Negotiated)
+
+	require.Equal(t, APIImportActionCreate, byResource["services"].Action)
+	require.True(t, byResource["services"].Negotiated, "core resources are negotiated with the core group name")
+}
@@ -22,6 +22,7 @@ import (
 	"reflect"
 	"time"
 
+	"github.com/go-logr/logr"
 	"github.com/kcp-dev/logicalcluster"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -31,13 +32,13 @@ import (
 	"k8s.io/apiserver/pkg/endpoints/request"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/cache"
-	"k8s.io/klog/v2"
 
 	apiresourcev1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apiresource/v1alpha1"
 	workloadv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/workload/v1alpha1"
 	kcpclient "github.com/kcp-dev/kcp/pkg/client/clientset/versioned"
 	kcpexternalversions "github.com/kcp-dev/kcp/pkg/client/informers/externalversions"
 	"github.com/kcp-dev/kcp/pkg/crdpuller"
+	"github.com/kcp-dev/kcp/pkg/logging"
 	clusterctl "github.com/kcp-dev/kcp/pkg/reconciler/workload/basecontroller"
 )
 
@@ -113,6 +114,8 @@ func NewAPIImporter(
 		location:           location,
 		logicalClusterName: logicalClusterName,
 		schemaPuller:       schemaPuller,
+
+		logger: logging.WithSyncTarget(logging.WithWorkspace(logging.NewLogger("kcp-workload-api-importer"), logicalClusterName), location),
 	}, nil
 }
 
@@ -127,6 +130,8 @@ type APIImporter struct {
 	logicalClusterName logicalcluster.Name
 	schemaPuller       crdpuller.SchemaPuller
 	SyncedGVRs         map[string]metav1.GroupVersionResource
+
+	logger logr.Logger
 }
 
 func (i *APIImporter) Start(ctx context.Context, pollInterval time.Duration) {
@@ -135,7 +140,7 @@ func (i *APIImporter) Start(ctx context.Context, pollInterval time.Duration) {
 	i.kcpInformerFactory.Start(ctx.Done())
 	i.kcpInformerFactory.WaitForCacheSync(ctx.Done())
 
-	klog.Infof("Starting API Importer for location %s in cluster %s", i.location, i.logicalClusterName)
+	i.logger.Info("Starting API Importer")
 
 	clusterContext := request.WithCluster(ctx, request.Cluster{Name: i.logicalClusterName})
 	go wait.UntilWithContext(clusterContext, func(innerCtx context.Context) {
@@ -147,29 +152,29 @@ func (i *APIImporter) Start(ctx context.Context, pollInterval time.Duration) {
 }
 
 func (i *APIImporter) Stop() {
-	klog.Infof("Stopping API Importer for location %s in cluster %s", i.location, i.logicalClusterName)
+	i.logger.Info("Stopping API Importer")
 
 	objs, err := i.apiresourceImportIndexer.ByIndex(
 		clusterctl.LocationInLogicalClusterIndexName,
 		clusterctl.GetLocationInLogicalClusterIndexKey(i.location, i.logicalClusterName),
 	)
 	if err != nil {
-		klog.Errorf("error trying to list APIResourceImport objects for location %s in logical cluster %s: %v", i.location, i.logicalClusterName, err)
+		i.logger.Error(err, "Error trying to list APIResourceImport objects")
 	}
 	for _, obj := range objs {
 		apiResourceImportToDelete := obj.(*apiresourcev1alpha1.APIResourceImport)
 		err := i.kcpClusterClient.Cluster(i.logicalClusterName).ApiresourceV1alpha1().APIResourceImports().Delete(request.WithCluster(context.Background(), request.Cluster{Name: i.logicalClusterName}), apiResourceImportToDelete.Name, metav1.DeleteOptions{})
 		if err != nil {
-			klog.Errorf("error deleting APIResourceImport %s: %v", apiResourceImportToDelete.Name, err)
+			i.logger.Error(err, "Error deleting APIResourceImport", "apiResourceImport", apiResourceImportToDelete.Name)
 		}
 	}
 }
 
 func (i *APIImporter) ImportAPIs(ctx context.Context) {
-	klog.Infof("Importing APIs from location %s in logical cluster %s (resources=%v)", i.location, i.logicalClusterName, i.resourcesToSync)
+	i.logger.Info("Importing APIs", "resources", i.resourcesToSync)
 	crds, err := i.schemaPuller.PullCRDs(ctx, i.resourcesToSync...)
 	if err != nil {
-		klog.Errorf("error pulling CRDs: %v", err)
+		i.logger.Error(err, "Error pulling CRDs")
 		return
 	}
 
@@ -187,21 +192,21 @@ func (i *APIImporter) ImportAPIs(ctx context.Context) {
 			clusterctl.GetGVRForLocationInLogicalClusterIndexKey(i.location, i.logicalClusterName, gvr),
 		)
 		if err != nil {
-			klog.Errorf("error pulling CRDs: %v", err)
+			i.logger.Error(err, "Error pulling CRDs")
 			continue
 		}
 		if len(objs) > 1 {
-			klog.Errorf("There should be only one APIResourceImport of GVR %s for location %s in logical cluster %s, but there was %d", gvr.String(), i.location, i.logicalClusterName, len(objs))
+			i.logger.Error(nil, "There should be only one APIResourceImport of GVR", "gvr", gvr.String(), "count", len(objs))
 			continue
 		}
 		if len(objs) == 1 {
 			apiResourceImport := objs[0].(*apiresourcev1alpha1.APIResourceImport).DeepCopy()
 			if err := apiResourceImport.Spec.SetSchema(crdVersion.Schema.OpenAPIV3Schema); err != nil {
-				klog.Errorf("Error setting schema: %v", err)
+				i.logger.Error(err, "Error setting schema", "gvr", gvr.String())
 				continue
 			}
 			if _, err := i.kcpClusterClient.Cluster(i.logicalClusterName).ApiresourceV1alpha1().APIResourceImports().Update(ctx, apiResourceImport, metav1.UpdateOptions{}); err != nil {
-				klog.Errorf("error updating APIResourceImport %s: %v", apiResourceImport.Name, err)
+				i.logger.Error(err, "Error updating APIResourceImport", "apiResourceImport", apiResourceImport.Name)
 				continue
 			}
 		} else {
@@ -219,21 +224,21 @@ func (i *APIImporter) ImportAPIs(ctx context.Context) {
 				},
 			})
 			if err != nil {
-				klog.Errorf("error creating APIResourceImport %s: %v", apiResourceImportName, err)
+				i.logger.Error(err, "Error creating APIResourceImport", "apiResourceImport", apiResourceImportName)
 				continue
 			}
 			clusterObj, exists, err := i.clusterIndexer.GetByKey(clusterKey)
 			if err != nil {
-				klog.Errorf("error creating APIResourceImport %s: %v", apiResourceImportName, err)
+				i.logger.Error(err, "Error creating APIResourceImport", "apiResourceImport", apiResourceImportName)
 				continue
 			}
 			if !exists {
-				klog.Errorf("error creating APIResourceImport %s: the cluster object should exist in the index for location %s in logical cluster %s", apiResourceImportName, i.location, i.logicalClusterName)
+				i.logger.Error(nil, "Error creating APIResourceImport: the cluster object should exist in the index", "apiResourceImport", apiResourceImportName)
 				continue
 			}
 			cluster, isCluster := clusterObj.(*workloadv1alpha1.WorkloadCluster)
 			if !isCluster {
-				klog.Errorf("error creating APIResourceImport %s: the object retrieved from the cluster index for location %s in logical cluster %s should be a cluster object, but is of type: %T", apiResourceImportName, i.location, i.logicalClusterName, clusterObj)
+				i.logger.Error(nil, "Error creating APIResourceImport: the object retrieved from the cluster index should be a cluster object", "apiResourceImport", apiResourceImportName, "type", fmt.Sprintf("%T", clusterObj))
 				continue
 			}
 			groupVersion := apiresourcev1alpha1.GroupVersion{
@@ -267,11 +272,11 @@ func (i *APIImporter) ImportAPIs(ctx context.Context) {
 				},
 			}
 			if err := apiResourceImport.Spec.SetSchema(crdVersion.Schema.OpenAPIV3Schema); err != nil {
-				klog.Errorf("Error setting schema: %v", err)
+				i.logger.Error(err, "Error setting schema", "gvr", gvr.String())
 				continue
 			}
 			if _, err := i.kcpClusterClient.Cluster(i.logicalClusterName).ApiresourceV1alpha1().APIResourceImports().Create(ctx, apiResourceImport, metav1.CreateOptions{}); err != nil {
-				klog.Errorf("error creating APIResourceImport %s: %v", apiResourceImport.Name, err)
+				i.logger.Error(err, "Error creating APIResourceImport", "apiResourceImport", apiResourceImport.Name)
 				continue
 			}
 		}
@@ -286,18 +291,18 @@ func (i *APIImporter) ImportAPIs(ctx context.Context) {
 			clusterctl.GetGVRForLocationInLogicalClusterIndexKey(i.location, i.logicalClusterName, gvr),
 		)
 		if err != nil {
-			klog.Errorf("error pulling CRDs: %v", err)
+			i.logger.Error(err, "Error pulling CRDs")
 			continue
 		}
 		if len(objs) > 1 {
-			klog.Errorf("There should be only one APIResourceImport of GVR %s for location %s in logical cluster %s, but there was %d", gvr.String(), i.location, i.logicalClusterName, len(objs))
+			i.logger.Error(nil, "There should be only one APIResourceImport of GVR", "gvr", gvr.String(), "count", len(objs))
 			continue
 		}
 		if len(objs) == 1 {
 			apiResourceImportToRemove := objs[0].(*apiresourcev1alpha1.APIResourceImport)
 			err := i.kcpClusterClient.Cluster(i.logicalClusterName).ApiresourceV1alpha1().APIResourceImports().Delete(ctx, apiResourceImportToRemove.Name, metav1.DeleteOptions{})
 			if err != nil {
-				klog.Errorf("error deleting APIResourceImport %s: %v", apiResourceImportToRemove.Name, err)
+				i.logger.Error(err, "Error deleting APIResourceImport", "apiResourceImport", apiResourceImportToRemove.Name)
 				continue
 			}
 		}
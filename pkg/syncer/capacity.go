@@ -0,0 +1,129 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package syncer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+
+	workloadv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/workload/v1alpha1"
+	workloadclient "github.com/kcp-dev/kcp/pkg/client/clientset/versioned/typed/workload/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/logging"
+)
+
+// capacityResourceNames are the node resources aggregated into the capacity of the physical cluster.
+var capacityResourceNames = []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory, corev1.ResourcePods}
+
+// nodeCapacity returns the capacity summed over all given nodes, the allocatable resources summed
+// over the ready and schedulable nodes, and the node counts.
+func nodeCapacity(nodes []corev1.Node) (capacity, allocatable corev1.ResourceList, counts workloadv1alpha1.WorkloadClusterNodeCounts) {
+	capacity = corev1.ResourceList{}
+	allocatable = corev1.ResourceList{}
+	for _, name := range capacityResourceNames {
+		capacity[name] = resource.Quantity{Format: formatOf(name)}
+		allocatable[name] = resource.Quantity{Format: formatOf(name)}
+	}
+
+	for i := range nodes {
+		node := &nodes[i]
+		counts.Total++
+		addResources(capacity, node.Status.Capacity)
+
+		if !nodeReady(node) {
+			continue
+		}
+		counts.Ready++
+		if node.Spec.Unschedulable {
+			continue
+		}
+		counts.Schedulable++
+		addResources(allocatable, node.Status.Allocatable)
+	}
+	return capacity, allocatable, counts
+}
+
+func addResources(total, resources corev1.ResourceList) {
+	for _, name := range capacityResourceNames {
+		q, found := resources[name]
+		if !found {
+			continue
+		}
+		sum := total[name]
+		sum.Add(q)
+		total[name] = sum
+	}
+}
+
+func formatOf(name corev1.ResourceName) resource.Format {
+	if name == corev1.ResourceMemory {
+		return resource.BinarySI
+	}
+	return resource.DecimalSI
+}
+
+func nodeReady(node *corev1.Node) bool {
+	for _, c := range node.Status.Conditions {
+		if c.Type == corev1.NodeReady {
+			return c.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// reportCapacity sets status.capacity, status.allocatable and status.nodes of the given workload
+// cluster to the aggregate of the nodes of the physical cluster, if they changed.
+func reportCapacity(ctx context.Context, downstreamKubeClient kubernetes.Interface, workloadClusters workloadclient.WorkloadClusterInterface, workloadClusterName string) error {
+	nodes, err := downstreamKubeClient.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to list nodes of the physical cluster: %w", err)
+	}
+	capacity, allocatable, counts := nodeCapacity(nodes.Items)
+
+	workloadCluster, err := workloadClusters.Get(ctx, workloadClusterName, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	status := workloadCluster.Status
+	if status.Capacity != nil && equality.Semantic.DeepEqual(capacity, *status.Capacity) &&
+		status.Allocatable != nil && equality.Semantic.DeepEqual(allocatable, *status.Allocatable) &&
+		status.Nodes != nil && *status.Nodes == counts {
+		return nil
+	}
+
+	patch := []map[string]interface{}{
+		{"op": "add", "path": "/status/capacity", "value": capacity},
+		{"op": "add", "path": "/status/allocatable", "value": allocatable},
+		{"op": "add", "path": "/status/nodes", "value": counts},
+	}
+	patchBytes, err := json.Marshal(patch)
+	if err != nil {
+		return err
+	}
+	if _, err := workloadClusters.Patch(ctx, workloadClusterName, types.JSONPatchType, patchBytes, metav1.PatchOptions{}, "status"); err != nil {
+		return fmt.Errorf("failed to set the capacity of WorkloadCluster %s: %w", workloadClusterName, err)
+	}
+	logging.FromContext(ctx).V(2).Info("Reported capacity", "capacity", capacity, "allocatable", allocatable, "nodes", counts)
+	return nil
+}
@@ -0,0 +1,164 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package syncer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+
+	workloadv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/workload/v1alpha1"
+	kcpfake "github.com/kcp-dev/kcp/pkg/client/clientset/versioned/fake"
+)
+
+func capacityNode(name string, ready, unschedulable bool, cpu, memory string) corev1.Node {
+	resources := corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse(cpu),
+		corev1.ResourceMemory: resource.MustParse(memory),
+		corev1.ResourcePods:   resource.MustParse("110"),
+	}
+	status := corev1.ConditionFalse
+	if ready {
+		status = corev1.ConditionTrue
+	}
+	return corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec:       corev1.NodeSpec{Unschedulable: unschedulable},
+		Status: corev1.NodeStatus{
+			Capacity:    resources,
+			Allocatable: resources,
+			Conditions:  []corev1.NodeCondition{{Type: corev1.NodeReady, Status: status}},
+		},
+	}
+}
+
+func TestNodeCapacity(t *testing.T) {
+	tests := map[string]struct {
+		nodes           []corev1.Node
+		wantCapacity    map[corev1.ResourceName]string
+		wantAllocatable map[corev1.ResourceName]string
+		wantCounts      workloadv1alpha1.WorkloadClusterNodeCounts
+	}{
+		"no nodes": {
+			wantCapacity:    map[corev1.ResourceName]string{"cpu": "0", "memory": "0", "pods": "0"},
+			wantAllocatable: map[corev1.ResourceName]string{"cpu": "0", "memory": "0", "pods": "0"},
+		},
+		"ready nodes": {
+			nodes: []corev1.Node{
+				capacityNode("a", true, false, "4", "16Gi"),
+				capacityNode("b", true, false, "2500m", "8Gi"),
+			},
+			wantCapacity:    map[corev1.ResourceName]string{"cpu": "6500m", "memory": "24Gi", "pods": "220"},
+			wantAllocatable: map[corev1.ResourceName]string{"cpu": "6500m", "memory": "24Gi", "pods": "220"},
+			wantCounts:      workloadv1alpha1.WorkloadClusterNodeCounts{Total: 2, Ready: 2, Schedulable: 2},
+		},
+		"not ready and cordoned nodes are not allocatable": {
+			nodes: []corev1.Node{
+				capacityNode("a", true, false, "4", "16Gi"),
+				capacityNode("b", false, false, "4", "16Gi"),
+				capacityNode("c", true, true, "4", "16Gi"),
+			},
+			wantCapacity:    map[corev1.ResourceName]string{"cpu": "12", "memory": "48Gi", "pods": "330"},
+			wantAllocatable: map[corev1.ResourceName]string{"cpu": "4", "memory": "16Gi", "pods": "110"},
+			wantCounts:      workloadv1alpha1.WorkloadClusterNodeCounts{Total: 3, Ready: 2, Schedulable: 1},
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			capacity, allocatable, counts := nodeCapacity(tc.nodes)
+			require.Equal(t, tc.wantCapacity, quantityStrings(capacity))
+			require.Equal(t, tc.wantAllocatable, quantityStrings(allocatable))
+			require.Equal(t, tc.wantCounts, counts)
+		})
+	}
+}
+
+func quantityStrings(resources corev1.ResourceList) map[corev1.ResourceName]string {
+	ret := map[corev1.ResourceName]string{}
+	for name, q := range resources {
+		ret[name] = q.String()
+	}
+	return ret
+}
+
+func TestReportCapacity(t *testing.T) {
+	nodes := []corev1.Node{capacityNode("a", true, false, "4", "16Gi")}
+	capacity, allocatable, counts := nodeCapacity(nodes)
+
+	tests := map[string]struct {
+		status    workloadv1alpha1.WorkloadClusterStatus
+		wantPatch bool
+	}{
+		"capacity is reported": {
+			wantPatch: true,
+		},
+		"unchanged capacity is not patched": {
+			status: workloadv1alpha1.WorkloadClusterStatus{
+				Capacity:    &capacity,
+				Allocatable: &allocatable,
+				Nodes:       &counts,
+			},
+		},
+		"changed node counts are patched": {
+			status: workloadv1alpha1.WorkloadClusterStatus{
+				Capacity:    &capacity,
+				Allocatable: &allocatable,
+				Nodes:       &workloadv1alpha1.WorkloadClusterNodeCounts{Total: 2, Ready: 1, Schedulable: 1},
+			},
+			wantPatch: true,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var objects []runtime.Object
+			for i := range nodes {
+				objects = append(objects, &nodes[i])
+			}
+			kubeClient := kubefake.NewSimpleClientset(objects...)
+			kcpClient := kcpfake.NewSimpleClientset(&workloadv1alpha1.WorkloadCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "us-east1"},
+				Status:     tc.status,
+			})
+
+			err := reportCapacity(context.Background(), kubeClient, kcpClient.WorkloadV1alpha1().WorkloadClusters(), "us-east1")
+			require.NoError(t, err)
+
+			patched := false
+			for _, action := range kcpClient.Actions() {
+				if action.GetVerb() == "patch" {
+					patched = true
+				}
+			}
+			require.Equal(t, tc.wantPatch, patched)
+
+			got, err := kcpClient.WorkloadV1alpha1().WorkloadClusters().Get(context.Background(), "us-east1", metav1.GetOptions{})
+			require.NoError(t, err)
+			require.NotNil(t, got.Status.Capacity)
+			require.Equal(t, map[corev1.ResourceName]string{"cpu": "4", "memory": "16Gi", "pods": "110"}, quantityStrings(*got.Status.Capacity))
+			require.NotNil(t, got.Status.Allocatable)
+			require.Equal(t, map[corev1.ResourceName]string{"cpu": "4", "memory": "16Gi", "pods": "110"}, quantityStrings(*got.Status.Allocatable))
+			require.Equal(t, &counts, got.Status.Nodes)
+		})
+	}
+}
@@ -0,0 +1,146 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package credentials
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/rest"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// DownstreamAuthProvider is how the syncer authenticates against the physical cluster.
+type DownstreamAuthProvider string
+
+const (
+	// DownstreamAuthKubeconfig uses the credentials of the kubeconfig, or of the in-cluster configuration. This
+	// includes exec credential plugins configured in the kubeconfig.
+	DownstreamAuthKubeconfig DownstreamAuthProvider = "kubeconfig"
+	// DownstreamAuthExec uses the exec credential plugin given on the command line instead of the credentials
+	// of the kubeconfig.
+	DownstreamAuthExec DownstreamAuthProvider = "exec"
+	// DownstreamAuthEKS gets tokens with `aws eks get-token`, e.g. with IAM roles for service accounts (IRSA).
+	DownstreamAuthEKS DownstreamAuthProvider = "eks"
+	// DownstreamAuthGKE gets tokens with `gke-gcloud-auth-plugin`, e.g. with GKE workload identity federation.
+	DownstreamAuthGKE DownstreamAuthProvider = "gke"
+
+	execAPIVersion = "client.authentication.k8s.io/v1beta1"
+)
+
+// DownstreamAuthProviders are the supported downstream authentication providers.
+var DownstreamAuthProviders = sets.NewString(
+	string(DownstreamAuthKubeconfig),
+	string(DownstreamAuthExec),
+	string(DownstreamAuthEKS),
+	string(DownstreamAuthGKE),
+)
+
+// DownstreamAuth configures how the syncer authenticates against the physical cluster.
+type DownstreamAuth struct {
+	Provider DownstreamAuthProvider
+
+	// ExecCommand, ExecArgs and ExecEnv are the exec credential plugin of the exec provider. ExecEnv holds
+	// NAME=VALUE pairs.
+	ExecCommand string
+	ExecArgs    []string
+	ExecEnv     []string
+
+	// ClusterName and Region identify the cluster of the eks provider.
+	ClusterName string
+	Region      string
+}
+
+// Validate checks that the configuration of the provider is complete.
+func (a DownstreamAuth) Validate() error {
+	switch a.Provider {
+	case "", DownstreamAuthKubeconfig, DownstreamAuthGKE:
+	case DownstreamAuthExec:
+		if a.ExecCommand == "" {
+			return errors.New("the exec command is required")
+		}
+	case DownstreamAuthEKS:
+		if a.ClusterName == "" {
+			return errors.New("the EKS cluster name is required")
+		}
+	default:
+		return fmt.Errorf("unknown provider %q, must be one of %s", a.Provider, strings.Join(DownstreamAuthProviders.List(), ", "))
+	}
+	for _, env := range a.ExecEnv {
+		if parts := strings.SplitN(env, "=", 2); len(parts) != 2 || parts[0] == "" {
+			return fmt.Errorf("invalid environment variable %q, must be NAME=VALUE", env)
+		}
+	}
+	return nil
+}
+
+// ExecConfig returns the exec credential plugin of the provider, or nil if the credentials of the kubeconfig
+// are used.
+func (a DownstreamAuth) ExecConfig() *clientcmdapi.ExecConfig {
+	var exec *clientcmdapi.ExecConfig
+	switch a.Provider {
+	case DownstreamAuthExec:
+		exec = &clientcmdapi.ExecConfig{
+			Command: a.ExecCommand,
+			Args:    a.ExecArgs,
+		}
+	case DownstreamAuthEKS:
+		// the AWS CLI picks up the web identity token and role of IRSA from the environment.
+		args := []string{"eks", "get-token", "--cluster-name", a.ClusterName}
+		if a.Region != "" {
+			args = append(args, "--region", a.Region)
+		}
+		exec = &clientcmdapi.ExecConfig{
+			Command:     "aws",
+			Args:        args,
+			InstallHint: "The eks provider needs the AWS CLI in the syncer image, see https://aws.amazon.com/cli/.",
+		}
+	case DownstreamAuthGKE:
+		// the plugin uses the application default credentials, i.e. the workload identity on GKE.
+		exec = &clientcmdapi.ExecConfig{
+			Command:            "gke-gcloud-auth-plugin",
+			ProvideClusterInfo: true,
+			InstallHint:        "The gke provider needs gke-gcloud-auth-plugin in the syncer image, see https://cloud.google.com/kubernetes-engine/docs/how-to/cluster-access-for-kubectl.",
+		}
+	default:
+		return nil
+	}
+
+	exec.APIVersion = execAPIVersion
+	exec.InteractiveMode = clientcmdapi.NeverExecInteractiveMode
+	for _, env := range a.ExecEnv {
+		parts := strings.SplitN(env, "=", 2)
+		exec.Env = append(exec.Env, clientcmdapi.ExecEnvVar{Name: parts[0], Value: parts[1]})
+	}
+	return exec
+}
+
+// WithDownstreamAuth replaces the credentials of the config by the exec credential plugin of the provider, if
+// any. Client-go calls the plugin again when the credentials expire or are rejected as unauthorized, so no
+// long-lived secret is needed.
+func WithDownstreamAuth(cfg *rest.Config, auth DownstreamAuth) *rest.Config {
+	exec := auth.ExecConfig()
+	if exec == nil {
+		return cfg
+	}
+
+	cfg = rest.AnonymousClientConfig(cfg)
+	cfg.ExecProvider = exec
+	return cfg
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package credentials
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/client-go/rest"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+func TestDownstreamAuthValidate(t *testing.T) {
+	tests := map[string]struct {
+		auth    DownstreamAuth
+		wantErr bool
+	}{
+		"default":               {auth: DownstreamAuth{}},
+		"kubeconfig":            {auth: DownstreamAuth{Provider: DownstreamAuthKubeconfig}},
+		"exec":                  {auth: DownstreamAuth{Provider: DownstreamAuthExec, ExecCommand: "get-token", ExecEnv: []string{"FOO=bar=baz", "EMPTY="}}},
+		"exec without command":  {auth: DownstreamAuth{Provider: DownstreamAuthExec}, wantErr: true},
+		"exec with invalid env": {auth: DownstreamAuth{Provider: DownstreamAuthExec, ExecCommand: "get-token", ExecEnv: []string{"FOO"}}, wantErr: true},
+		"eks":                   {auth: DownstreamAuth{Provider: DownstreamAuthEKS, ClusterName: "east"}},
+		"eks without cluster":   {auth: DownstreamAuth{Provider: DownstreamAuthEKS}, wantErr: true},
+		"gke":                   {auth: DownstreamAuth{Provider: DownstreamAuthGKE}},
+		"unknown":               {auth: DownstreamAuth{Provider: "azure"}, wantErr: true},
+	}
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			err := tc.auth.Validate()
+			if tc.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestDownstreamAuthExecConfig(t *testing.T) {
+	tests := map[string]struct {
+		auth DownstreamAuth
+		want *clientcmdapi.ExecConfig
+	}{
+		"kubeconfig": {
+			auth: DownstreamAuth{Provider: DownstreamAuthKubeconfig},
+		},
+		"exec": {
+			auth: DownstreamAuth{Provider: DownstreamAuthExec, ExecCommand: "get-token", ExecArgs: []string{"--audience", "east"}, ExecEnv: []string{"FOO=bar=baz"}},
+			want: &clientcmdapi.ExecConfig{
+				Command:         "get-token",
+				Args:            []string{"--audience", "east"},
+				Env:             []clientcmdapi.ExecEnvVar{{Name: "FOO", Value: "bar=baz"}},
+				APIVersion:      execAPIVersion,
+				InteractiveMode: clientcmdapi.NeverExecInteractiveMode,
+			},
+		},
+		"eks": {
+			auth: DownstreamAuth{Provider: DownstreamAuthEKS, ClusterName: "east", Region: "us-east-1"},
+			want: &clientcmdapi.ExecConfig{
+				Command:         "aws",
+				Args:            []string{"eks", "get-token", "--cluster-name", "east", "--region", "us-east-1"},
+				APIVersion:      execAPIVersion,
+				InteractiveMode: clientcmdapi.NeverExecInteractiveMode,
+				InstallHint:     "The eks provider needs the AWS CLI in the syncer image, see https://aws.amazon.com/cli/.",
+			},
+		},
+	}
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, tc.want, tc.auth.ExecConfig())
+		})
+	}
+}
+
+func TestWithDownstreamAuth(t *testing.T) {
+	dir := t.TempDir()
+	plugin := filepath.Join(dir, "get-token")
+	require.NoError(t, os.WriteFile(plugin, []byte(`#!/bin/sh
+echo '{"apiVersion":"client.authentication.k8s.io/v1beta1","kind":"ExecCredential","status":{"token":"'"$TOKEN"'"}}'
+`), 0700))
+
+	var authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+	}))
+	defer server.Close()
+
+	cfg := WithDownstreamAuth(&rest.Config{Host: server.URL, BearerToken: "static"}, DownstreamAuth{
+		Provider:    DownstreamAuthExec,
+		ExecCommand: plugin,
+		ExecEnv:     []string{"TOKEN=from-plugin"},
+	})
+	require.Empty(t, cfg.BearerToken)
+
+	transport, err := rest.TransportFor(cfg)
+	require.NoError(t, err)
+	resp, err := (&http.Client{Transport: transport}).Get(server.URL)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, "Bearer from-plugin", authorization)
+}
@@ -0,0 +1,206 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package credentials
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	utilnet "k8s.io/apimachinery/pkg/util/net"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	"k8s.io/klog/v2"
+
+	"github.com/kcp-dev/kcp/pkg/secretstore"
+)
+
+// reloadPeriod is how often the token is re-read from the kubeconfig file. Mounted secrets are
+// updated by the kubelet with a delay of about a minute as well.
+const reloadPeriod = time.Minute
+
+// WithReloadedToken returns a copy of the given config, loaded from the given kubeconfig file and
+// context, which re-reads the bearer token from the file every minute and after unauthorized
+// responses. This picks up rotated credentials, e.g. written into a mounted secret by the Rotator,
+// without restarting. Configs without bearer token are returned unchanged.
+func WithReloadedToken(cfg *rest.Config, kubeconfigPath, contextName string) *rest.Config {
+	if cfg.BearerToken == "" || kubeconfigPath == "" {
+		return cfg
+	}
+
+	token := &reloadingToken{
+		path:    kubeconfigPath,
+		context: contextName,
+		token:   cfg.BearerToken,
+		readAt:  time.Now(),
+		now:     time.Now,
+	}
+	cfg = rest.CopyConfig(cfg)
+	cfg.BearerToken = ""
+	cfg.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+		return &reloadingTokenRoundTripper{token: token, base: rt}
+	})
+	return cfg
+}
+
+// WithReloadedStoreToken is like WithReloadedToken, but re-reads the bearer token from the kubeconfig
+// stored under dataKey of the given key of the secret store.
+func WithReloadedStoreToken(cfg *rest.Config, store secretstore.Store, key, dataKey, contextName string) *rest.Config {
+	if cfg.BearerToken == "" {
+		return cfg
+	}
+
+	token := &reloadingToken{
+		path:    key,
+		context: contextName,
+		read: func() (string, error) {
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+			data, err := store.Get(ctx, key)
+			if err != nil {
+				return "", err
+			}
+			return parseToken(data[dataKey], contextName, key)
+		},
+		token:  cfg.BearerToken,
+		readAt: time.Now(),
+		now:    time.Now,
+	}
+	cfg = rest.CopyConfig(cfg)
+	cfg.BearerToken = ""
+	cfg.Wrap(func(rt http.RoundTripper) http.RoundTripper {
+		return &reloadingTokenRoundTripper{token: token, base: rt}
+	})
+	return cfg
+}
+
+// reloadingToken is a bearer token read from a kubeconfig file, cached for reloadPeriod.
+type reloadingToken struct {
+	path    string
+	context string
+	// read overrides reading the token from the kubeconfig file at path, e.g. to read it from a secret store.
+	read func() (string, error)
+
+	lock   sync.Mutex
+	token  string
+	readAt time.Time
+
+	// for testing
+	now func() time.Time
+}
+
+// get returns the cached token, re-reading it if it is older than reloadPeriod. If reading
+// fails, the previous token is returned.
+func (t *reloadingToken) get() string {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if t.token != "" && t.now().Sub(t.readAt) < reloadPeriod {
+		return t.token
+	}
+	read := t.read
+	if read == nil {
+		read = func() (string, error) { return readToken(t.path, t.context) }
+	}
+	token, err := read()
+	if err != nil {
+		klog.ErrorS(err, "Failed to reload the token", "path", t.path)
+		return t.token
+	}
+	if token != t.token {
+		klog.InfoS("Reloaded the rotated token", "path", t.path)
+	}
+	t.token = token
+	t.readAt = t.now()
+	return token
+}
+
+// reset makes the next get re-read the token.
+func (t *reloadingToken) reset() {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.readAt = time.Time{}
+}
+
+type reloadingTokenRoundTripper struct {
+	token *reloadingToken
+	base  http.RoundTripper
+}
+
+func (rt *reloadingTokenRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	// do not override explicit credentials
+	if req.Header.Get("Authorization") != "" {
+		return rt.base.RoundTrip(req)
+	}
+
+	req = utilnet.CloneRequest(req)
+	req.Header.Set("Authorization", "Bearer "+rt.token.get())
+	resp, err := rt.base.RoundTrip(req)
+	if err == nil && resp.StatusCode == http.StatusUnauthorized {
+		rt.token.reset()
+	}
+	return resp, err
+}
+
+func (rt *reloadingTokenRoundTripper) WrappedRoundTripper() http.RoundTripper { return rt.base }
+
+// readToken returns the bearer token of the given context, or of the current context if empty,
+// of the given kubeconfig file.
+func readToken(path, contextName string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return parseToken(data, contextName, path)
+}
+
+// parseToken returns the bearer token of the given context, or of the current context if empty,
+// of the given kubeconfig, read from source.
+func parseToken(kubeconfig []byte, contextName, source string) (string, error) {
+	raw, err := clientcmd.Load(kubeconfig)
+	if err != nil {
+		return "", err
+	}
+	authInfo, err := contextAuthInfo(raw, contextName)
+	if err != nil {
+		return "", err
+	}
+	if authInfo.Token == "" {
+		return "", fmt.Errorf("no token found in %s", source)
+	}
+	return authInfo.Token, nil
+}
+
+// contextAuthInfo returns the user of the given context, or of the current context if empty.
+func contextAuthInfo(raw *clientcmdapi.Config, contextName string) (*clientcmdapi.AuthInfo, error) {
+	if contextName == "" {
+		contextName = raw.CurrentContext
+	}
+	context, found := raw.Contexts[contextName]
+	if !found {
+		return nil, fmt.Errorf("context %q not found", contextName)
+	}
+	authInfo, found := raw.AuthInfos[context.AuthInfo]
+	if !found {
+		return nil, fmt.Errorf("user %q of context %q not found", context.AuthInfo, contextName)
+	}
+	return authInfo, nil
+}
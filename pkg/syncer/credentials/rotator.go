@@ -0,0 +1,240 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package credentials
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apiserver/pkg/authentication/serviceaccount"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/kcp-dev/kcp/pkg/logging"
+	"github.com/kcp-dev/kcp/pkg/secretstore"
+	"github.com/kcp-dev/kcp/pkg/syncer/shared"
+)
+
+const (
+	// DefaultSecretKey is the key of the kubeconfig in the syncer secret.
+	DefaultSecretKey = "kubeconfig"
+	// DefaultTokenLifetime is the default lifetime of rotated tokens.
+	DefaultTokenLifetime = 7 * 24 * time.Hour
+	// MinTokenLifetime is the minimal lifetime of tokens issued by the TokenRequest API.
+	MinTokenLifetime = 10 * time.Minute
+)
+
+// RotationPolicy defines how the syncer rotates the service account token it uses to talk to kcp.
+// The zero value disables rotation.
+type RotationPolicy struct {
+	// SecretNamespace and SecretName identify the secret on the physical cluster holding the kubeconfig
+	// the syncer uses to talk to kcp, usually mounted into the syncer pod. The kubeconfig is stored
+	// under SecretKey.
+	SecretNamespace string
+	SecretName      string
+	SecretKey       string
+	// Store and StoreKey identify the kubeconfig in an external secret store instead, stored under
+	// SecretKey of the data of StoreKey.
+	Store    secretstore.Store
+	StoreKey string
+	// Context is the kubeconfig context used by the syncer. Empty means the current context.
+	Context string
+	// TokenLifetime is the requested lifetime of rotated tokens. A token is rotated when less than a
+	// third of its lifetime is left, or right away if it does not expire.
+	TokenLifetime time.Duration
+}
+
+// Enabled returns true if the token is rotated.
+func (p RotationPolicy) Enabled() bool {
+	return p.SecretName != "" || p.StoreKey != ""
+}
+
+// Rotator rotates the service account token in the kubeconfig secret of the syncer before it expires,
+// using the TokenRequest API of kcp. The syncer picks up the new token through WithReloadedToken.
+type Rotator struct {
+	policy              RotationPolicy
+	workloadClusterName string
+
+	// upstreamClient is scoped to the logical cluster of the syncer.
+	upstreamClient   kubernetes.Interface
+	downstreamClient kubernetes.Interface
+
+	// for testing
+	now func() time.Time
+}
+
+// NewRotator returns a Rotator for the syncer of the given workload cluster.
+func NewRotator(policy RotationPolicy, workloadClusterName string, upstreamClient, downstreamClient kubernetes.Interface) *Rotator {
+	if policy.SecretKey == "" {
+		policy.SecretKey = DefaultSecretKey
+	}
+	if policy.TokenLifetime == 0 {
+		policy.TokenLifetime = DefaultTokenLifetime
+	}
+	return &Rotator{
+		policy:              policy,
+		workloadClusterName: workloadClusterName,
+		upstreamClient:      upstreamClient,
+		downstreamClient:    downstreamClient,
+		now:                 time.Now,
+	}
+}
+
+// Start checks the token every interval, and rotates it when needed, until the context is done.
+func (r *Rotator) Start(ctx context.Context, interval time.Duration) {
+	logger := logging.FromContext(ctx)
+	if r.policy.StoreKey != "" {
+		logger = logger.WithValues("secretStoreKey", r.policy.StoreKey)
+	} else {
+		logger = logger.WithValues("secretNamespace", r.policy.SecretNamespace, "secretName", r.policy.SecretName)
+	}
+	ctx = logging.NewContext(ctx, logger)
+	logger.Info("Starting token rotation")
+
+	wait.UntilWithContext(ctx, func(ctx context.Context) {
+		if err := r.rotate(ctx); err != nil {
+			logger.Error(err, "Failed to rotate the kcp token")
+		}
+	}, interval)
+}
+
+// rotate replaces the token in the kubeconfig secret by a new one if it expires soon.
+func (r *Rotator) rotate(ctx context.Context) error {
+	kubeconfig, save, err := r.loadKubeconfig(ctx)
+	if err != nil {
+		return err
+	}
+	raw, err := clientcmd.Load(kubeconfig)
+	if err != nil {
+		return fmt.Errorf("invalid kubeconfig in key %q: %w", r.policy.SecretKey, err)
+	}
+	authInfo, err := contextAuthInfo(raw, r.policy.Context)
+	if err != nil {
+		return err
+	}
+	claims, err := parseTokenClaims(authInfo.Token)
+	if err != nil {
+		return err
+	}
+	if claims.Expiry != nil {
+		shared.ObserveUpstreamTokenExpiry(r.workloadClusterName, time.Unix(*claims.Expiry, 0))
+	}
+	if !r.needsRotation(claims) {
+		return nil
+	}
+
+	namespace, name, err := serviceaccount.SplitUsername(claims.Subject)
+	if err != nil {
+		return fmt.Errorf("token does not belong to a service account: %w", err)
+	}
+	expirationSeconds := int64(r.policy.TokenLifetime.Seconds())
+	tokenRequest, err := r.upstreamClient.CoreV1().ServiceAccounts(namespace).CreateToken(ctx, name, &authenticationv1.TokenRequest{
+		Spec: authenticationv1.TokenRequestSpec{ExpirationSeconds: &expirationSeconds},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to request a token for ServiceAccount %s/%s: %w", namespace, name, err)
+	}
+
+	authInfo.Token = tokenRequest.Status.Token
+	data, err := clientcmd.Write(*raw)
+	if err != nil {
+		return err
+	}
+	if err := save(data); err != nil {
+		return err
+	}
+
+	logging.FromContext(ctx).Info("Rotated the kcp token", "expiration", tokenRequest.Status.ExpirationTimestamp.Time)
+	shared.ObserveUpstreamTokenExpiry(r.workloadClusterName, tokenRequest.Status.ExpirationTimestamp.Time)
+	return nil
+}
+
+// loadKubeconfig returns the kubeconfig from the secret store or the secret on the physical cluster,
+// and a function to save the rotated kubeconfig there.
+func (r *Rotator) loadKubeconfig(ctx context.Context) ([]byte, func([]byte) error, error) {
+	if r.policy.StoreKey != "" {
+		data, err := r.policy.Store.Get(ctx, r.policy.StoreKey)
+		if err != nil {
+			return nil, nil, err
+		}
+		return data[r.policy.SecretKey], func(kubeconfig []byte) error {
+			updated := make(map[string][]byte, len(data))
+			for k, v := range data {
+				updated[k] = v
+			}
+			updated[r.policy.SecretKey] = kubeconfig
+			return r.policy.Store.Put(ctx, r.policy.StoreKey, updated)
+		}, nil
+	}
+
+	secret, err := r.downstreamClient.CoreV1().Secrets(r.policy.SecretNamespace).Get(ctx, r.policy.SecretName, metav1.GetOptions{})
+	if err != nil {
+		return nil, nil, err
+	}
+	return secret.Data[r.policy.SecretKey], func(kubeconfig []byte) error {
+		secret = secret.DeepCopy()
+		secret.Data[r.policy.SecretKey] = kubeconfig
+		_, err := r.downstreamClient.CoreV1().Secrets(secret.Namespace).Update(ctx, secret, metav1.UpdateOptions{})
+		return err
+	}, nil
+}
+
+// needsRotation returns true if the token does not expire, or if less than a third of its lifetime
+// is left.
+func (r *Rotator) needsRotation(claims *tokenClaims) bool {
+	if claims.Expiry == nil {
+		return true
+	}
+	expiry := time.Unix(*claims.Expiry, 0)
+	lifetime := r.policy.TokenLifetime
+	if claims.IssuedAt != nil {
+		lifetime = expiry.Sub(time.Unix(*claims.IssuedAt, 0))
+	}
+	return expiry.Sub(r.now()) < lifetime/3
+}
+
+// tokenClaims are the claims of a service account token relevant for rotation.
+type tokenClaims struct {
+	Subject  string `json:"sub"`
+	Expiry   *int64 `json:"exp,omitempty"`
+	IssuedAt *int64 `json:"iat,omitempty"`
+}
+
+// parseTokenClaims decodes the claims of the given JWT. The signature is not verified, the claims
+// are only used to schedule the rotation.
+func parseTokenClaims(token string) (*tokenClaims, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("token is not a JWT")
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return nil, fmt.Errorf("invalid token payload: %w", err)
+	}
+	var claims tokenClaims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, fmt.Errorf("invalid token claims: %w", err)
+	}
+	return &claims, nil
+}
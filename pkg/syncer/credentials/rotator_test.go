@@ -0,0 +1,215 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package credentials
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+
+	"github.com/kcp-dev/kcp/pkg/secretstore"
+)
+
+var now = time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC)
+
+func TestRotate(t *testing.T) {
+	tests := map[string]struct {
+		token       string
+		wantRotated bool
+		wantError   bool
+	}{
+		"legacy token without expiry": {
+			token:       jwt(t, "system:serviceaccount:default:kcp-syncer-east", nil, nil),
+			wantRotated: true,
+		},
+		"fresh token": {
+			token: jwt(t, "system:serviceaccount:default:kcp-syncer-east", unix(now.Add(-time.Hour)), unix(now.Add(23*time.Hour))),
+		},
+		"token close to expiry": {
+			token:       jwt(t, "system:serviceaccount:default:kcp-syncer-east", unix(now.Add(-20*time.Hour)), unix(now.Add(4*time.Hour))),
+			wantRotated: true,
+		},
+		"token close to expiry without issue time": {
+			token:       jwt(t, "system:serviceaccount:default:kcp-syncer-east", nil, unix(now.Add(4*time.Hour))),
+			wantRotated: true,
+		},
+		"token of a user": {
+			token:     jwt(t, "admin", nil, nil),
+			wantError: true,
+		},
+		"not a JWT": {
+			token:     "static-token",
+			wantError: true,
+		},
+	}
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			secret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "kcp-syncer", Name: "kcp-syncer-config"},
+				Data:       map[string][]byte{"kubeconfig": kubeconfig(t, tc.token)},
+			}
+			downstreamClient := fake.NewSimpleClientset(secret)
+			upstreamClient := fake.NewSimpleClientset()
+			upstreamClient.PrependReactor("create", "serviceaccounts", func(action clienttesting.Action) (bool, runtime.Object, error) {
+				create := action.(clienttesting.CreateAction)
+				require.Equal(t, "token", create.GetSubresource())
+				require.Equal(t, "default", create.GetNamespace())
+				tokenRequest := create.GetObject().(*authenticationv1.TokenRequest)
+				require.Equal(t, int64(24*60*60), *tokenRequest.Spec.ExpirationSeconds)
+				tokenRequest.Status.Token = "new-token"
+				tokenRequest.Status.ExpirationTimestamp = metav1.NewTime(now.Add(24 * time.Hour))
+				return true, tokenRequest, nil
+			})
+
+			r := NewRotator(RotationPolicy{SecretNamespace: "kcp-syncer", SecretName: "kcp-syncer-config", TokenLifetime: 24 * time.Hour}, "east", upstreamClient, downstreamClient)
+			r.now = func() time.Time { return now }
+			err := r.rotate(context.Background())
+			if tc.wantError {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+
+			secret, err = downstreamClient.CoreV1().Secrets("kcp-syncer").Get(context.Background(), "kcp-syncer-config", metav1.GetOptions{})
+			require.NoError(t, err)
+			raw, err := clientcmd.Load(secret.Data["kubeconfig"])
+			require.NoError(t, err)
+			if tc.wantRotated {
+				require.Equal(t, "new-token", raw.AuthInfos["default-user"].Token)
+				require.Equal(t, "https://kcp.example.com", raw.Clusters["default-cluster"].Server, "cluster must be kept")
+			} else {
+				require.Equal(t, tc.token, raw.AuthInfos["default-user"].Token)
+			}
+		})
+	}
+}
+
+func TestRotateInSecretStore(t *testing.T) {
+	store := mapStore{"syncer/east": {"kubeconfig": kubeconfig(t, jwt(t, "system:serviceaccount:default:kcp-syncer-east", nil, nil)), "other": []byte("kept")}}
+	upstreamClient := fake.NewSimpleClientset()
+	upstreamClient.PrependReactor("create", "serviceaccounts", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		tokenRequest := action.(clienttesting.CreateAction).GetObject().(*authenticationv1.TokenRequest)
+		tokenRequest.Status.Token = "new-token"
+		tokenRequest.Status.ExpirationTimestamp = metav1.NewTime(now.Add(24 * time.Hour))
+		return true, tokenRequest, nil
+	})
+
+	policy := RotationPolicy{Store: store, StoreKey: "syncer/east", TokenLifetime: 24 * time.Hour}
+	require.True(t, policy.Enabled())
+	r := NewRotator(policy, "east", upstreamClient, fake.NewSimpleClientset())
+	r.now = func() time.Time { return now }
+	require.NoError(t, r.rotate(context.Background()))
+
+	raw, err := clientcmd.Load(store["syncer/east"]["kubeconfig"])
+	require.NoError(t, err)
+	require.Equal(t, "new-token", raw.AuthInfos["default-user"].Token)
+	require.Equal(t, []byte("kept"), store["syncer/east"]["other"], "other keys must be kept")
+
+	token := &reloadingToken{path: "syncer/east", token: "old-token", now: func() time.Time { return now }, read: func() (string, error) {
+		return parseToken(store["syncer/east"]["kubeconfig"], "", "syncer/east")
+	}}
+	require.Equal(t, "new-token", token.get(), "rotated token must be read from the store")
+}
+
+func TestReloadingToken(t *testing.T) {
+	path := t.TempDir() + "/kubeconfig"
+	writeKubeconfig := func(token string) {
+		require.NoError(t, clientcmd.WriteToFile(*kubeconfigConfig(token), path))
+	}
+	writeKubeconfig("old-token")
+
+	clock := now
+	token := &reloadingToken{path: path, token: "old-token", readAt: clock, now: func() time.Time { return clock }}
+	require.Equal(t, "old-token", token.get())
+
+	writeKubeconfig("new-token")
+	require.Equal(t, "old-token", token.get(), "token must be cached")
+
+	clock = clock.Add(reloadPeriod)
+	require.Equal(t, "new-token", token.get(), "token must be reloaded after the reload period")
+
+	writeKubeconfig("newer-token")
+	token.reset()
+	require.Equal(t, "newer-token", token.get(), "token must be reloaded after a reset")
+
+	writeKubeconfig("")
+	token.reset()
+	require.Equal(t, "newer-token", token.get(), "previous token must be kept if reloading fails")
+}
+
+func jwt(t *testing.T, subject string, issuedAt, expiry *int64) string {
+	payload, err := json.Marshal(tokenClaims{Subject: subject, IssuedAt: issuedAt, Expiry: expiry})
+	require.NoError(t, err)
+	return "eyJhbGciOiJSUzI1NiJ9." + base64.RawURLEncoding.EncodeToString(payload) + ".c2lnbmF0dXJl"
+}
+
+func unix(t time.Time) *int64 {
+	u := t.Unix()
+	return &u
+}
+
+func kubeconfig(t *testing.T, token string) []byte {
+	data, err := clientcmd.Write(*kubeconfigConfig(token))
+	require.NoError(t, err)
+	return data
+}
+
+func kubeconfigConfig(token string) *clientcmdapi.Config {
+	return &clientcmdapi.Config{
+		Clusters:       map[string]*clientcmdapi.Cluster{"default-cluster": {Server: "https://kcp.example.com"}},
+		Contexts:       map[string]*clientcmdapi.Context{"default-context": {Cluster: "default-cluster", AuthInfo: "default-user"}},
+		AuthInfos:      map[string]*clientcmdapi.AuthInfo{"default-user": {Token: token}},
+		CurrentContext: "default-context",
+	}
+}
+
+type mapStore map[string]map[string][]byte
+
+func (s mapStore) Get(_ context.Context, key string) (map[string][]byte, error) {
+	data, ok := s[key]
+	if !ok {
+		return nil, secretstore.ErrNotFound
+	}
+	return data, nil
+}
+
+func (s mapStore) Put(_ context.Context, key string, data map[string][]byte) error {
+	s[key] = data
+	return nil
+}
+
+func (s mapStore) Create(_ context.Context, key string, data map[string][]byte) error {
+	if _, ok := s[key]; ok {
+		return secretstore.ErrAlreadyExists
+	}
+	s[key] = data
+	return nil
+}
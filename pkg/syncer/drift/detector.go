@@ -0,0 +1,209 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drift
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/kcp-dev/logicalcluster"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/tools/clusters"
+
+	"github.com/kcp-dev/kcp/pkg/logging"
+	"github.com/kcp-dev/kcp/pkg/syncer/shared"
+)
+
+const (
+	controllerName = "kcp-workload-syncer-drift"
+)
+
+var namespacesGVR = schema.GroupVersionResource{Version: "v1", Resource: "namespaces"}
+
+// Controller periodically compares the downstream objects with their hash recorded by the spec syncer
+// when it last wrote them. Objects changed out-of-band on the physical cluster are queued in the spec
+// syncer again, which repairs them by re-applying the upstream state.
+type Controller struct {
+	store   *Store
+	enqueue func(gvr schema.GroupVersionResource, upstreamObj interface{})
+
+	upstreamInformers, downstreamInformers dynamicinformer.DynamicSharedInformerFactory
+
+	gvrs                []schema.GroupVersionResource
+	workloadClusterName string
+	syncPause           *shared.SyncPause
+}
+
+// NewDriftDetector returns a drift detector for the downstream objects recorded in the given store, queueing
+// drifted objects with the given function, usually the AddToQueue of the spec syncer.
+func NewDriftDetector(gvrs []schema.GroupVersionResource, workloadClusterName string, store *Store, syncPause *shared.SyncPause,
+	enqueue func(gvr schema.GroupVersionResource, upstreamObj interface{}), upstreamInformers, downstreamInformers dynamicinformer.DynamicSharedInformerFactory) *Controller {
+	RegisterMetrics()
+
+	c := &Controller{
+		store:   store,
+		enqueue: enqueue,
+
+		upstreamInformers:   upstreamInformers,
+		downstreamInformers: downstreamInformers,
+
+		workloadClusterName: workloadClusterName,
+		syncPause:           syncPause,
+	}
+
+	for _, gvr := range gvrs {
+		if gvr == namespacesGVR {
+			// downstream namespaces are not synced from upstream
+			continue
+		}
+		c.gvrs = append(c.gvrs, gvr)
+
+		// make sure the informers are started
+		upstreamInformers.ForResource(gvr).Informer()
+		downstreamInformers.ForResource(gvr).Informer()
+	}
+	downstreamInformers.ForResource(namespacesGVR).Informer()
+
+	return c
+}
+
+// Start runs a detection pass every interval until the context is done. A zero interval disables drift
+// detection. The informers are expected to be synced.
+func (c *Controller) Start(ctx context.Context, interval time.Duration) {
+	defer runtime.HandleCrash()
+
+	logger := logging.FromContext(ctx)
+	if interval <= 0 {
+		logger.Info("Drift detection is disabled")
+		return
+	}
+
+	logger.Info("Starting drift detection", "interval", interval)
+	defer logger.Info("Stopping drift detection")
+
+	wait.UntilWithContext(ctx, c.detect, interval)
+}
+
+func (c *Controller) detect(ctx context.Context) {
+	logger := logging.FromContext(ctx)
+	for _, gvr := range c.gvrs {
+		drifted, err := c.findDrifted(gvr)
+		if err != nil {
+			runtime.HandleError(fmt.Errorf("%s failed to find drifted objects of %q: %w", controllerName, gvr.String(), err))
+			continue
+		}
+
+		upstreamIndexer := c.upstreamInformers.ForResource(gvr).Informer().GetIndexer()
+		for upstreamKey, obj := range drifted {
+			logger := logger.WithValues("gvr", gvr.String(), "downstreamNamespace", obj.GetNamespace(), "downstreamName", obj.GetName())
+			driftedObjects.WithLabelValues(c.workloadClusterName, gvr.GroupResource().String()).Inc()
+
+			upstreamObj, exists, err := upstreamIndexer.GetByKey(upstreamKey)
+			if err != nil {
+				runtime.HandleError(fmt.Errorf("%s failed to get upstream object %q of %q: %w", controllerName, upstreamKey, gvr.String(), err))
+				continue
+			}
+			if !exists {
+				// the spec syncer or the orphan pruning take care of it
+				logger.V(2).Info("Found drifted downstream object without upstream object")
+				continue
+			}
+			logger.Info("Found drifted downstream object, syncing it again")
+			c.enqueue(gvr, upstreamObj)
+		}
+	}
+}
+
+// findDrifted returns the downstream objects of the given resource whose hash differs from the one recorded
+// when the spec syncer last wrote them, by the key of their upstream object. The current hash is recorded
+// for them, such that a drift is only reported once even if the repair fails.
+func (c *Controller) findDrifted(gvr schema.GroupVersionResource) (map[string]*unstructured.Unstructured, error) {
+	downstreamObjs, err := c.downstreamInformers.ForResource(gvr).Lister().List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+
+	seen := sets.NewString()
+	drifted := map[string]*unstructured.Unstructured{}
+	for _, o := range downstreamObjs {
+		obj, ok := o.(*unstructured.Unstructured)
+		if !ok {
+			return nil, fmt.Errorf("object is expected to be Unstructured, but is %T", o)
+		}
+
+		key := downstreamKey(obj.GetNamespace(), obj.GetName())
+		e, ok := c.store.get(gvr, key)
+		if !ok {
+			continue
+		}
+		seen.Insert(key)
+
+		if obj.GetDeletionTimestamp() != nil {
+			continue
+		}
+		if c.syncPause.Mode(gvr.GroupResource(), c.namespaceAnnotations(obj)).PausesSpec() {
+			// changes to paused objects are intended
+			continue
+		}
+
+		hash, err := Hash(obj)
+		if err != nil {
+			return nil, err
+		}
+		if hash == e.hash {
+			continue
+		}
+		e.hash = hash
+		c.store.set(gvr, key, e)
+		drifted[e.upstreamKey] = obj
+	}
+	c.store.retain(gvr, seen)
+
+	return drifted, nil
+}
+
+// namespaceAnnotations returns the annotations of the downstream namespace of the given object, or nil if it is
+// cluster-scoped or the namespace is unknown.
+func (c *Controller) namespaceAnnotations(obj *unstructured.Unstructured) map[string]string {
+	if obj.GetNamespace() == "" {
+		return nil
+	}
+	nsKey := obj.GetNamespace()
+	if clusterName := logicalcluster.From(obj); !clusterName.Empty() {
+		// If our "physical" cluster is a kcp instance (e.g. for testing purposes), it will return resources
+		// with metadata.clusterName set, which means their keys are cluster-aware, so we need to do the same here.
+		nsKey = clusters.ToClusterAwareKey(clusterName, nsKey)
+	}
+	nsObj, err := c.downstreamInformers.ForResource(namespacesGVR).Lister().Get(nsKey)
+	if err != nil {
+		return nil
+	}
+	nsMeta, ok := nsObj.(metav1.Object)
+	if !ok {
+		return nil
+	}
+	return nsMeta.GetAnnotations()
+}
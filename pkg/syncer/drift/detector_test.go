@@ -0,0 +1,191 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drift
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/component-base/metrics/testutil"
+
+	workloadv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/workload/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/syncer/shared"
+)
+
+var configMapsGVR = schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+
+func TestHash(t *testing.T) {
+	obj := configMap("kcp-test", "foo", "blue")
+	hash, err := Hash(obj)
+	require.NoError(t, err)
+
+	changedMeta := obj.DeepCopy()
+	changedMeta.SetResourceVersion("42")
+	changedMeta.SetLabels(map[string]string{"a": "b"})
+	require.NoError(t, unstructured.SetNestedField(changedMeta.Object, "Bound", "status", "phase"))
+	got, err := Hash(changedMeta)
+	require.NoError(t, err)
+	require.Equal(t, hash, got, "metadata and status must not change the hash")
+
+	got, err = Hash(configMap("kcp-test", "foo", "red"))
+	require.NoError(t, err)
+	require.NotEqual(t, hash, got, "data must change the hash")
+}
+
+func TestFindDrifted(t *testing.T) {
+	tests := map[string]struct {
+		recorded   *unstructured.Unstructured
+		downstream *unstructured.Unstructured
+		paused     string
+		want       []string
+	}{
+		"unchanged": {
+			recorded:   configMap("kcp-test", "foo", "blue"),
+			downstream: configMap("kcp-test", "foo", "blue"),
+		},
+		"changed out-of-band": {
+			recorded:   configMap("kcp-test", "foo", "blue"),
+			downstream: configMap("kcp-test", "foo", "red"),
+			want:       []string{"test/root:org:ws|foo"},
+		},
+		"not written by the syncer": {
+			downstream: configMap("kcp-test", "foo", "red"),
+		},
+		"paused namespace": {
+			recorded:   configMap("kcp-paused", "foo", "blue"),
+			downstream: configMap("kcp-paused", "foo", "red"),
+		},
+		"paused resource": {
+			recorded:   configMap("kcp-test", "foo", "blue"),
+			downstream: configMap("kcp-test", "foo", "red"),
+			paused:     "configmaps=Spec",
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			c, _ := newTestController(t, "us-west1", tc.downstream)
+			require.NoError(t, c.syncPause.UpdateFromAnnotations(map[string]string{workloadv1alpha1.ExperimentalSyncPausedResourcesAnnotation: tc.paused}))
+			if tc.recorded != nil {
+				require.NoError(t, c.store.Record(configMapsGVR, "test/root:org:ws|foo", tc.recorded))
+			}
+
+			drifted, err := c.findDrifted(configMapsGVR)
+			require.NoError(t, err)
+			var got []string
+			for key := range drifted {
+				got = append(got, key)
+			}
+			require.Equal(t, tc.want, got)
+
+			drifted, err = c.findDrifted(configMapsGVR)
+			require.NoError(t, err)
+			require.Empty(t, drifted, "a drift must only be reported once")
+		})
+	}
+}
+
+func TestFindDriftedForgetsDeletedObjects(t *testing.T) {
+	c, _ := newTestController(t, "us-west1")
+	require.NoError(t, c.store.Record(configMapsGVR, "test/root:org:ws|foo", configMap("kcp-test", "foo", "blue")))
+
+	_, err := c.findDrifted(configMapsGVR)
+	require.NoError(t, err)
+	_, ok := c.store.get(configMapsGVR, "kcp-test/foo")
+	require.True(t, ok, "objects must only be forgotten when missing twice in a row")
+
+	_, err = c.findDrifted(configMapsGVR)
+	require.NoError(t, err)
+	_, ok = c.store.get(configMapsGVR, "kcp-test/foo")
+	require.False(t, ok)
+}
+
+func TestDetect(t *testing.T) {
+	upstream := configMap("test", "foo", "blue")
+	upstream.SetClusterName("root:org:ws")
+	c, enqueued := newTestController(t, "detect", configMap("kcp-test", "foo", "red"), configMap("kcp-test", "bar", "red"))
+	require.NoError(t, c.upstreamInformers.ForResource(configMapsGVR).Informer().GetIndexer().Add(upstream))
+	require.NoError(t, c.store.Record(configMapsGVR, "test/root:org:ws|foo", configMap("kcp-test", "foo", "blue")))
+	require.NoError(t, c.store.Record(configMapsGVR, "test/root:org:ws|bar", configMap("kcp-test", "bar", "blue")))
+
+	c.detect(context.Background())
+
+	require.Equal(t, []interface{}{upstream}, *enqueued, "only drifted objects with upstream object must be queued")
+	drifted, err := testutil.GetCounterMetricValue(driftedObjects.WithLabelValues("detect", configMapsGVR.GroupResource().String()))
+	require.NoError(t, err)
+	require.Equal(t, float64(2), drifted)
+}
+
+// newTestController returns a drift detector for the given workload cluster with the downstream informer
+// pre-filled with the given objects and the namespaces kcp-test for root:org:ws|test and kcp-paused with
+// paused syncing, and a pointer to the upstream objects queued by it.
+func newTestController(t *testing.T, workloadClusterName string, downstream ...*unstructured.Unstructured) (*Controller, *[]interface{}) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(scheme))
+
+	upstreamInformers := dynamicinformer.NewDynamicSharedInformerFactory(dynamicfake.NewSimpleDynamicClient(scheme), time.Hour)
+	downstreamInformers := dynamicinformer.NewDynamicSharedInformerFactory(dynamicfake.NewSimpleDynamicClient(scheme), time.Hour)
+
+	var enqueued []interface{}
+	c := NewDriftDetector([]schema.GroupVersionResource{namespacesGVR, configMapsGVR}, workloadClusterName, NewStore(), shared.NewSyncPause(),
+		func(gvr schema.GroupVersionResource, obj interface{}) { enqueued = append(enqueued, obj) }, upstreamInformers, downstreamInformers)
+
+	for _, obj := range downstream {
+		if obj != nil {
+			require.NoError(t, downstreamInformers.ForResource(configMapsGVR).Informer().GetIndexer().Add(obj))
+		}
+	}
+	namespaces := []*unstructured.Unstructured{
+		downstreamNamespace("kcp-test", map[string]string{shared.NamespaceLocatorAnnotation: `{"logical-cluster":"root:org:ws","namespace":"test"}`}),
+		downstreamNamespace("kcp-paused", map[string]string{
+			shared.NamespaceLocatorAnnotation:                 `{"logical-cluster":"root:org:ws","namespace":"paused"}`,
+			workloadv1alpha1.ExperimentalSyncPausedAnnotation: "Spec",
+		}),
+	}
+	for _, ns := range namespaces {
+		require.NoError(t, downstreamInformers.ForResource(namespacesGVR).Informer().GetIndexer().Add(ns))
+	}
+
+	return c, &enqueued
+}
+
+func configMap(namespace, name, color string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion("v1")
+	obj.SetKind("ConfigMap")
+	obj.SetNamespace(namespace)
+	obj.SetName(name)
+	obj.Object["data"] = map[string]interface{}{"color": color}
+	return obj
+}
+
+func downstreamNamespace(name string, annotations map[string]string) *unstructured.Unstructured {
+	ns := &unstructured.Unstructured{}
+	ns.SetAPIVersion("v1")
+	ns.SetKind("Namespace")
+	ns.SetName(name)
+	ns.SetAnnotations(annotations)
+	return ns
+}
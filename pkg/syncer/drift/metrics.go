@@ -0,0 +1,49 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drift
+
+import (
+	"sync"
+
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+)
+
+const (
+	namespace = "kcp"
+	subsystem = "syncer"
+)
+
+var driftedObjects = metrics.NewCounterVec(
+	&metrics.CounterOpts{
+		Namespace:      namespace,
+		Subsystem:      subsystem,
+		Name:           "drifted_downstream_objects_total",
+		Help:           "Number of downstream objects found changed out-of-band since they were last synced, and queued for repair, by workload cluster and resource.",
+		StabilityLevel: metrics.ALPHA,
+	},
+	[]string{"workload_cluster", "resource"},
+)
+
+var registerMetrics sync.Once
+
+// RegisterMetrics registers the drift detection metrics.
+func RegisterMetrics() {
+	registerMetrics.Do(func() {
+		legacyregistry.MustRegister(driftedObjects)
+	})
+}
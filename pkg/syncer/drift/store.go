@@ -0,0 +1,125 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package drift
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// Hash returns a hash of the content of the given object, i.e. of all its top-level fields apart from
+// the type, the metadata and the status. These are the fields synced from upstream, e.g. the spec of a
+// deployment or the data of a configmap.
+func Hash(obj *unstructured.Unstructured) (string, error) {
+	content := make(map[string]interface{}, len(obj.Object))
+	for k, v := range obj.Object {
+		switch k {
+		case "apiVersion", "kind", "metadata", "status":
+			continue
+		}
+		content[k] = v
+	}
+	data, err := json.Marshal(content) // map keys are sorted, hence the hash is stable
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+type entry struct {
+	hash        string
+	upstreamKey string
+	// missing is set when the downstream object was not found by the previous detection pass.
+	missing bool
+}
+
+// Store remembers the hash of the downstream objects as last written by the spec syncer, together with the
+// key of their upstream object. It is safe for concurrent use.
+type Store struct {
+	lock    sync.Mutex
+	entries map[schema.GroupVersionResource]map[string]entry
+}
+
+// NewStore returns an empty store.
+func NewStore() *Store {
+	return &Store{
+		entries: map[schema.GroupVersionResource]map[string]entry{},
+	}
+}
+
+// Record remembers the given downstream object as written by the spec syncer for the upstream object with
+// the given key. A nil store ignores the call.
+func (s *Store) Record(gvr schema.GroupVersionResource, upstreamKey string, downstreamObj *unstructured.Unstructured) error {
+	if s == nil {
+		return nil
+	}
+	hash, err := Hash(downstreamObj)
+	if err != nil {
+		return err
+	}
+	s.set(gvr, downstreamKey(downstreamObj.GetNamespace(), downstreamObj.GetName()), entry{hash: hash, upstreamKey: upstreamKey})
+	return nil
+}
+
+func (s *Store) set(gvr schema.GroupVersionResource, key string, e entry) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.entries[gvr] == nil {
+		s.entries[gvr] = map[string]entry{}
+	}
+	s.entries[gvr][key] = e
+}
+
+func (s *Store) get(gvr schema.GroupVersionResource, key string) (entry, bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	e, ok := s.entries[gvr][key]
+	return e, ok
+}
+
+// retain forgets the downstream objects of the given resource whose keys are not in the given set for the
+// second time in a row. Objects recorded right before the pass might not have reached the informer yet.
+func (s *Store) retain(gvr schema.GroupVersionResource, keys sets.String) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	for key, e := range s.entries[gvr] {
+		switch {
+		case keys.Has(key):
+			e.missing = false
+			s.entries[gvr][key] = e
+		case e.missing:
+			delete(s.entries[gvr], key)
+		default:
+			e.missing = true
+			s.entries[gvr][key] = e
+		}
+	}
+}
+
+func downstreamKey(namespace, name string) string {
+	if namespace == "" {
+		return name
+	}
+	return namespace + "/" + name
+}
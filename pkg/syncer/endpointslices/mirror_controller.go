@@ -0,0 +1,235 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpointslices
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/kcp-dev/logicalcluster"
+
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+
+	workloadv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/workload/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/logging"
+)
+
+const (
+	controllerName = "kcp-workload-syncer-endpointslices"
+
+	// ManagedBy is the value of the endpointslice.kubernetes.io/managed-by label of the mirrored
+	// EndpointSlices upstream.
+	ManagedBy = "syncer.workloads.kcp.dev"
+
+	// sourceAnnotation holds the key of the downstream EndpointSlice an upstream mirror is created from.
+	sourceAnnotation = "endpointslice.internal.workloads.kcp.dev/source"
+	bySourceIndex    = "bySource"
+
+	// apiPollInterval is how often the syncer checks whether kcp serves EndpointSlices, until it does.
+	apiPollInterval = 5 * time.Second
+)
+
+// Resource is the resource mirrored upstream, imported into kcp by the API importer of the syncer.
+var Resource = discoveryv1.SchemeGroupVersion.WithResource("endpointslices")
+
+// Controller mirrors the EndpointSlices of the services synced to the physical cluster upstream, into the
+// namespaces of the services in kcp, such that components like global load balancers see the endpoints of a
+// service across all its placements. The downstream EndpointSlices are found by the workload cluster label,
+// which the EndpointSlice controller copies from the synced service.
+type Controller struct {
+	queue workqueue.RateLimitingInterface
+
+	logger logr.Logger
+
+	upstreamClient   kubernetes.Interface
+	upstreamInformer cache.SharedIndexInformer
+
+	downstreamInformers     informers.SharedInformerFactory
+	downstreamSliceIndexer  cache.Indexer
+	downstreamNamespaceList corelisters.NamespaceLister
+
+	upstreamClusterName logicalcluster.Name
+	workloadClusterName string
+}
+
+// NewEndpointSliceMirror returns a controller mirroring the downstream EndpointSlices of the given workload
+// cluster into the given upstream logical cluster.
+func NewEndpointSliceMirror(upstreamClusterName logicalcluster.Name, workloadClusterName string, upstreamClient, downstreamClient kubernetes.Interface, resyncPeriod time.Duration) *Controller {
+	upstreamInformers := informers.NewSharedInformerFactoryWithOptions(upstreamClient, resyncPeriod, informers.WithTweakListOptions(func(o *metav1.ListOptions) {
+		o.LabelSelector = workloadv1alpha1.InternalEndpointSliceMirrorLabel + "=" + workloadClusterName
+	}))
+	downstreamInformers := informers.NewSharedInformerFactoryWithOptions(downstreamClient, resyncPeriod, informers.WithTweakListOptions(func(o *metav1.ListOptions) {
+		o.LabelSelector = workloadv1alpha1.InternalDownstreamClusterLabel + "=" + workloadClusterName
+	}))
+
+	c := &Controller{
+		queue: workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), controllerName),
+
+		logger: logging.WithSyncTarget(logging.WithWorkspace(logging.NewLogger(controllerName), upstreamClusterName), workloadClusterName),
+
+		upstreamClient:   upstreamClient,
+		upstreamInformer: upstreamInformers.Discovery().V1().EndpointSlices().Informer(),
+
+		downstreamInformers:     downstreamInformers,
+		downstreamSliceIndexer:  downstreamInformers.Discovery().V1().EndpointSlices().Informer().GetIndexer(),
+		downstreamNamespaceList: downstreamInformers.Core().V1().Namespaces().Lister(),
+
+		upstreamClusterName: upstreamClusterName,
+		workloadClusterName: workloadClusterName,
+	}
+
+	if err := c.upstreamInformer.AddIndexers(cache.Indexers{
+		bySourceIndex: indexBySource,
+	}); err != nil {
+		// only fails after the informer is started
+		runtime.HandleError(err)
+	}
+
+	downstreamInformers.Discovery().V1().EndpointSlices().Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj interface{}) { c.enqueueDownstream(obj) },
+		UpdateFunc: func(_, obj interface{}) { c.enqueueDownstream(obj) },
+		DeleteFunc: func(obj interface{}) { c.enqueueDownstream(obj) },
+	})
+	// Upstream mirrors are requeued by their source, e.g. to remove them when their downstream EndpointSlice
+	// was deleted while the syncer was down.
+	c.upstreamInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj interface{}) { c.enqueueUpstream(obj) },
+		UpdateFunc: func(_, obj interface{}) { c.enqueueUpstream(obj) },
+		DeleteFunc: func(obj interface{}) { c.enqueueUpstream(obj) },
+	})
+
+	return c
+}
+
+func indexBySource(obj interface{}) ([]string, error) {
+	slice, ok := obj.(*discoveryv1.EndpointSlice)
+	if !ok {
+		return nil, fmt.Errorf("expected EndpointSlice, got %T", obj)
+	}
+	if source, found := slice.Annotations[sourceAnnotation]; found {
+		return []string{source}, nil
+	}
+	return nil, nil
+}
+
+func (c *Controller) enqueueDownstream(obj interface{}) {
+	key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+	if err != nil {
+		runtime.HandleError(err)
+		return
+	}
+	logging.WithQueueKey(c.logger, key).V(2).Info("queueing EndpointSlice")
+	c.queue.Add(key)
+}
+
+func (c *Controller) enqueueUpstream(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	slice, ok := obj.(*discoveryv1.EndpointSlice)
+	if !ok {
+		runtime.HandleError(fmt.Errorf("expected EndpointSlice, got %T", obj))
+		return
+	}
+	key, found := slice.Annotations[sourceAnnotation]
+	if !found {
+		return
+	}
+	logging.WithQueueKey(c.logger, key).V(2).Info("queueing EndpointSlice because of its upstream mirror")
+	c.queue.Add(key)
+}
+
+// Start waits for kcp to serve EndpointSlices, starts the informers and then N worker processes processing
+// work items.
+func (c *Controller) Start(ctx context.Context, numThreads int) {
+	defer runtime.HandleCrash()
+	defer c.queue.ShutDown()
+
+	ctx = logging.NewContext(ctx, c.logger)
+	if err := wait.PollImmediateInfiniteWithContext(ctx, apiPollInterval, c.upstreamAPIServed); err != nil {
+		return
+	}
+
+	c.downstreamInformers.Start(ctx.Done())
+	go c.upstreamInformer.Run(ctx.Done())
+	c.downstreamInformers.WaitForCacheSync(ctx.Done())
+	if !cache.WaitForCacheSync(ctx.Done(), c.upstreamInformer.HasSynced) {
+		return
+	}
+
+	c.logger.Info("Starting workers")
+	defer c.logger.Info("Stopping workers")
+	for i := 0; i < numThreads; i++ {
+		go wait.UntilWithContext(ctx, c.startWorker, time.Second)
+	}
+
+	<-ctx.Done()
+}
+
+// upstreamAPIServed returns whether the upstream logical cluster serves EndpointSlices, which happens
+// once the API importer imported them and the API is negotiated.
+func (c *Controller) upstreamAPIServed(ctx context.Context) (bool, error) {
+	resources, err := c.upstreamClient.Discovery().ServerResourcesForGroupVersion(Resource.GroupVersion().String())
+	if err != nil {
+		logging.FromContext(ctx).V(2).Info("Waiting for EndpointSlices to be served upstream", "err", err.Error())
+		return false, nil
+	}
+	for _, r := range resources.APIResources {
+		if r.Name == Resource.Resource {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (c *Controller) startWorker(ctx context.Context) {
+	for c.processNextWorkItem(ctx) {
+	}
+}
+
+func (c *Controller) processNextWorkItem(ctx context.Context) bool {
+	// Wait until there is a new item in the working queue
+	k, quit := c.queue.Get()
+	if quit {
+		return false
+	}
+	key := k.(string)
+
+	ctx = logging.NewContext(ctx, logging.WithQueueKey(c.logger, key))
+
+	// No matter what, tell the queue we're done with this key, to unblock
+	// other workers.
+	defer c.queue.Done(key)
+
+	if err := c.process(ctx, key); err != nil {
+		runtime.HandleError(fmt.Errorf("%s failed to sync %q, err: %w", controllerName, key, err))
+		c.queue.AddRateLimited(key)
+		return true
+	}
+	c.queue.Forget(key)
+	return true
+}
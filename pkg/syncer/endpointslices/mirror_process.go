@@ -0,0 +1,177 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpointslices
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+
+	discoveryv1 "k8s.io/api/discovery/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	workloadv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/workload/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/logging"
+	"github.com/kcp-dev/kcp/pkg/syncer/shared"
+)
+
+// process creates, updates or deletes the upstream mirror of the downstream EndpointSlice with the given key.
+func (c *Controller) process(ctx context.Context, key string) error {
+	logger := logging.FromContext(ctx)
+
+	existing, err := c.upstreamInformer.GetIndexer().ByIndex(bySourceIndex, key)
+	if err != nil {
+		return err
+	}
+
+	desired, err := c.desiredMirror(key)
+	if err != nil {
+		return err
+	}
+
+	for _, obj := range existing {
+		mirror := obj.(*discoveryv1.EndpointSlice)
+		if desired != nil && mirror.Namespace == desired.Namespace && mirror.Name == desired.Name {
+			if mirrorEqual(mirror, desired) {
+				logger.V(3).Info("Upstream mirror is up to date")
+				desired = nil
+				continue
+			}
+			updated := mirror.DeepCopy()
+			updated.Labels, updated.Annotations = desired.Labels, desired.Annotations
+			updated.AddressType, updated.Endpoints, updated.Ports = desired.AddressType, desired.Endpoints, desired.Ports
+			if _, err := c.upstreamClient.DiscoveryV1().EndpointSlices(mirror.Namespace).Update(ctx, updated, metav1.UpdateOptions{}); err != nil {
+				return err
+			}
+			logger.Info("Updated upstream mirror", "namespace", mirror.Namespace, "name", mirror.Name)
+			desired = nil
+			continue
+		}
+
+		// the downstream EndpointSlice is gone, or moved to another upstream namespace
+		err := c.upstreamClient.DiscoveryV1().EndpointSlices(mirror.Namespace).Delete(ctx, mirror.Name, metav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+		logger.Info("Deleted upstream mirror", "namespace", mirror.Namespace, "name", mirror.Name)
+	}
+
+	if desired == nil {
+		return nil
+	}
+	if _, err := c.upstreamClient.DiscoveryV1().EndpointSlices(desired.Namespace).Create(ctx, desired, metav1.CreateOptions{}); err != nil {
+		if apierrors.IsAlreadyExists(err) {
+			// not in the informer yet, it will be requeued by the informer event
+			return nil
+		}
+		return err
+	}
+	logger.Info("Created upstream mirror", "namespace", desired.Namespace, "name", desired.Name)
+	return nil
+}
+
+// desiredMirror returns the upstream mirror of the downstream EndpointSlice with the given key, or nil if it should
+// not be mirrored, e.g. because it does not exist (anymore) or does not belong to a service of the upstream logical
+// cluster.
+func (c *Controller) desiredMirror(key string) (*discoveryv1.EndpointSlice, error) {
+	obj, exists, err := c.downstreamSliceIndexer.GetByKey(key)
+	if err != nil || !exists {
+		return nil, err
+	}
+	slice, ok := obj.(*discoveryv1.EndpointSlice)
+	if !ok {
+		return nil, fmt.Errorf("expected EndpointSlice, got %T", obj)
+	}
+	serviceName := slice.Labels[discoveryv1.LabelServiceName]
+	if serviceName == "" {
+		return nil, nil
+	}
+
+	namespace, err := c.downstreamNamespaceList.Get(slice.Namespace)
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	locator, err := shared.LocatorFromAnnotations(namespace.Annotations)
+	if err != nil {
+		return nil, err
+	}
+	if locator == nil || locator.LogicalCluster != c.upstreamClusterName {
+		// Only mirror the EndpointSlices of the configured logical cluster to ensure
+		// that syncers for multiple logical clusters can coexist.
+		return nil, nil
+	}
+
+	mirror := &discoveryv1.EndpointSlice{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      mirrorName(c.workloadClusterName, slice.Name),
+			Namespace: locator.Namespace,
+			Labels: map[string]string{
+				discoveryv1.LabelServiceName:                      serviceName,
+				discoveryv1.LabelManagedBy:                        ManagedBy,
+				workloadv1alpha1.InternalEndpointSliceMirrorLabel: c.workloadClusterName,
+			},
+			Annotations: map[string]string{
+				sourceAnnotation: key,
+			},
+		},
+		AddressType: slice.AddressType,
+		Ports:       slice.Ports,
+	}
+	for _, endpoint := range slice.Endpoints {
+		endpoint := *endpoint.DeepCopy()
+		if endpoint.TargetRef != nil {
+			// the target, usually a pod, lives in the upstream namespace, but its downstream UID and
+			// resource version are meaningless upstream.
+			endpoint.TargetRef.Namespace = locator.Namespace
+			endpoint.TargetRef.UID = ""
+			endpoint.TargetRef.ResourceVersion = ""
+		}
+		mirror.Endpoints = append(mirror.Endpoints, endpoint)
+	}
+	return mirror, nil
+}
+
+// mirrorName returns the name of the upstream mirror of the downstream EndpointSlice with the given name. The suffix
+// keeps the mirrors of the syncers of different workload clusters apart.
+func mirrorName(workloadClusterName, downstreamName string) string {
+	suffix := fmt.Sprintf("-%x", sha256.Sum224([]byte(workloadClusterName)))[:9]
+	if maxLen := validation.DNS1123SubdomainMaxLength - len(suffix); len(downstreamName) > maxLen {
+		downstreamName = downstreamName[:maxLen]
+	}
+	return downstreamName + suffix
+}
+
+func mirrorEqual(existing, desired *discoveryv1.EndpointSlice) bool {
+	for k, v := range desired.Labels {
+		if existing.Labels[k] != v {
+			return false
+		}
+	}
+	for k, v := range desired.Annotations {
+		if existing.Annotations[k] != v {
+			return false
+		}
+	}
+	return existing.AddressType == desired.AddressType &&
+		equality.Semantic.DeepEqual(existing.Endpoints, desired.Endpoints) &&
+		equality.Semantic.DeepEqual(existing.Ports, desired.Ports)
+}
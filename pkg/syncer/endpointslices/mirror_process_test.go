@@ -0,0 +1,190 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package endpointslices
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kcp-dev/logicalcluster"
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	discoveryv1 "k8s.io/api/discovery/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	corelisters "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+
+	workloadv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/workload/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/syncer/shared"
+)
+
+func TestMirrorProcess(t *testing.T) {
+	locatorAnnotation := func(clusterName, namespace string) map[string]string {
+		return map[string]string{
+			shared.NamespaceLocatorAnnotation: `{"logical-cluster":"` + clusterName + `","namespace":"` + namespace + `"}`,
+		}
+	}
+	downstreamNamespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: "kcp-ns", Annotations: locatorAnnotation("root:org:ws", "ns")},
+	}
+	otherWorkspaceNamespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: "kcp-other", Annotations: locatorAnnotation("root:org:other", "ns")},
+	}
+
+	ready := true
+	port := int32(8080)
+	downstreamSlice := func(namespace string) *discoveryv1.EndpointSlice {
+		return &discoveryv1.EndpointSlice{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "svc-abcde",
+				Namespace: namespace,
+				Labels: map[string]string{
+					discoveryv1.LabelServiceName:                    "svc",
+					workloadv1alpha1.InternalDownstreamClusterLabel: "us-east1",
+				},
+			},
+			AddressType: discoveryv1.AddressTypeIPv4,
+			Endpoints: []discoveryv1.Endpoint{{
+				Addresses:  []string{"10.0.0.1"},
+				Conditions: discoveryv1.EndpointConditions{Ready: &ready},
+				TargetRef:  &corev1.ObjectReference{Kind: "Pod", Namespace: namespace, Name: "pod", UID: "uid", ResourceVersion: "42"},
+			}},
+			Ports: []discoveryv1.EndpointPort{{Port: &port}},
+		}
+	}
+	mirror := func(addresses ...string) *discoveryv1.EndpointSlice {
+		return &discoveryv1.EndpointSlice{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      mirrorName("us-east1", "svc-abcde"),
+				Namespace: "ns",
+				Labels: map[string]string{
+					discoveryv1.LabelServiceName:                      "svc",
+					discoveryv1.LabelManagedBy:                        ManagedBy,
+					workloadv1alpha1.InternalEndpointSliceMirrorLabel: "us-east1",
+				},
+				Annotations: map[string]string{
+					sourceAnnotation: "kcp-ns/svc-abcde",
+				},
+			},
+			AddressType: discoveryv1.AddressTypeIPv4,
+			Endpoints: []discoveryv1.Endpoint{{
+				Addresses:  addresses,
+				Conditions: discoveryv1.EndpointConditions{Ready: &ready},
+				TargetRef:  &corev1.ObjectReference{Kind: "Pod", Namespace: "ns", Name: "pod"},
+			}},
+			Ports: []discoveryv1.EndpointPort{{Port: &port}},
+		}
+	}
+
+	tests := map[string]struct {
+		downstreamNamespaces []*corev1.Namespace
+		downstreamSlices     []*discoveryv1.EndpointSlice
+		upstreamMirrors      []*discoveryv1.EndpointSlice
+		wantVerbs            []string
+		wantMirror           *discoveryv1.EndpointSlice
+	}{
+		"mirror is created": {
+			downstreamNamespaces: []*corev1.Namespace{downstreamNamespace},
+			downstreamSlices:     []*discoveryv1.EndpointSlice{downstreamSlice("kcp-ns")},
+			wantVerbs:            []string{"create"},
+			wantMirror:           mirror("10.0.0.1"),
+		},
+		"mirror is up to date": {
+			downstreamNamespaces: []*corev1.Namespace{downstreamNamespace},
+			downstreamSlices:     []*discoveryv1.EndpointSlice{downstreamSlice("kcp-ns")},
+			upstreamMirrors:      []*discoveryv1.EndpointSlice{mirror("10.0.0.1")},
+		},
+		"mirror is updated": {
+			downstreamNamespaces: []*corev1.Namespace{downstreamNamespace},
+			downstreamSlices:     []*discoveryv1.EndpointSlice{downstreamSlice("kcp-ns")},
+			upstreamMirrors:      []*discoveryv1.EndpointSlice{mirror("10.0.0.2")},
+			wantVerbs:            []string{"update"},
+			wantMirror:           mirror("10.0.0.1"),
+		},
+		"mirror is deleted with its downstream EndpointSlice": {
+			downstreamNamespaces: []*corev1.Namespace{downstreamNamespace},
+			upstreamMirrors:      []*discoveryv1.EndpointSlice{mirror("10.0.0.1")},
+			wantVerbs:            []string{"delete"},
+		},
+		"EndpointSlices of other logical clusters are not mirrored": {
+			downstreamNamespaces: []*corev1.Namespace{otherWorkspaceNamespace},
+			downstreamSlices:     []*discoveryv1.EndpointSlice{downstreamSlice("kcp-other")},
+		},
+	}
+
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			var objects []runtime.Object
+			upstreamInformer := cache.NewSharedIndexInformer(&cache.ListWatch{}, &discoveryv1.EndpointSlice{}, 0, cache.Indexers{bySourceIndex: indexBySource})
+			for _, m := range tc.upstreamMirrors {
+				objects = append(objects, m)
+				require.NoError(t, upstreamInformer.GetIndexer().Add(m))
+			}
+			upstreamClient := kubefake.NewSimpleClientset(objects...)
+
+			namespaceIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+			for _, ns := range tc.downstreamNamespaces {
+				require.NoError(t, namespaceIndexer.Add(ns))
+			}
+			sliceIndexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+			for _, slice := range tc.downstreamSlices {
+				require.NoError(t, sliceIndexer.Add(slice))
+			}
+
+			c := &Controller{
+				upstreamClient:          upstreamClient,
+				upstreamInformer:        upstreamInformer,
+				downstreamSliceIndexer:  sliceIndexer,
+				downstreamNamespaceList: corelisters.NewNamespaceLister(namespaceIndexer),
+				upstreamClusterName:     logicalcluster.New("root:org:ws"),
+				workloadClusterName:     "us-east1",
+			}
+
+			key := "kcp-ns/svc-abcde"
+			if len(tc.downstreamSlices) > 0 {
+				key = tc.downstreamSlices[0].Namespace + "/" + tc.downstreamSlices[0].Name
+			}
+			require.NoError(t, c.process(context.Background(), key))
+
+			var verbs []string
+			for _, action := range upstreamClient.Actions() {
+				verbs = append(verbs, action.GetVerb())
+			}
+			require.Equal(t, tc.wantVerbs, verbs)
+
+			if tc.wantMirror != nil {
+				got, err := upstreamClient.Tracker().Get(Resource, tc.wantMirror.Namespace, tc.wantMirror.Name)
+				require.NoError(t, err)
+				gotMirror := got.(*discoveryv1.EndpointSlice)
+				require.Equal(t, tc.wantMirror.Labels, gotMirror.Labels)
+				require.Equal(t, tc.wantMirror.Annotations, gotMirror.Annotations)
+				require.Equal(t, tc.wantMirror.Endpoints, gotMirror.Endpoints)
+				require.Equal(t, tc.wantMirror.Ports, gotMirror.Ports)
+			}
+		})
+	}
+}
+
+func TestMirrorName(t *testing.T) {
+	require.Equal(t, mirrorName("us-east1", "svc-abcde"), mirrorName("us-east1", "svc-abcde"))
+	require.NotEqual(t, mirrorName("us-east1", "svc-abcde"), mirrorName("us-west1", "svc-abcde"))
+	require.LessOrEqual(t, len(mirrorName("us-east1", string(make([]byte, 300)))), 253)
+}
@@ -0,0 +1,47 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package syncer
+
+import (
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"k8s.io/apiserver/pkg/server/healthz"
+)
+
+// heartbeatTimeout is the time after the last successful heartbeat after which a syncer is not ready anymore.
+const heartbeatTimeout = 3 * heartbeatInterval
+
+// lastHeartbeats holds the time of the last successful heartbeat by syncer ID.
+var lastHeartbeats sync.Map
+
+// HeartbeatCheck returns a readiness check which fails until the syncer with the given ID has started
+// and heartbeated its WorkloadCluster, and when it has not heartbeated for some time.
+func HeartbeatCheck(syncerID string) healthz.HealthChecker {
+	return healthz.NamedCheck("heartbeat", func(_ *http.Request) error {
+		last, ok := lastHeartbeats.Load(syncerID)
+		if !ok {
+			return fmt.Errorf("syncer has not heartbeated yet")
+		}
+		if age := time.Since(last.(time.Time)); age > heartbeatTimeout {
+			return fmt.Errorf("last heartbeat was %s ago", age.Round(time.Second))
+		}
+		return nil
+	})
+}
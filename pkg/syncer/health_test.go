@@ -0,0 +1,37 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package syncer
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHeartbeatCheck(t *testing.T) {
+	check := HeartbeatCheck("test-syncer")
+	defer lastHeartbeats.Delete("test-syncer")
+
+	require.Error(t, check.Check(nil), "expected a syncer without heartbeat not to be ready")
+
+	lastHeartbeats.Store("test-syncer", time.Now())
+	require.NoError(t, check.Check(nil))
+
+	lastHeartbeats.Store("test-syncer", time.Now().Add(-2*heartbeatTimeout))
+	require.Error(t, check.Check(nil), "expected a syncer with an old heartbeat not to be ready")
+}
@@ -0,0 +1,63 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pruning
+
+import (
+	"sync"
+
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+)
+
+const (
+	namespace = "kcp"
+	subsystem = "syncer"
+)
+
+var (
+	orphanedObjects = metrics.NewGaugeVec(
+		&metrics.GaugeOpts{
+			Namespace:      namespace,
+			Subsystem:      subsystem,
+			Name:           "orphaned_downstream_objects",
+			Help:           "Number of downstream objects without upstream counterpart found by the last orphan pruning pass, by workload cluster and resource.",
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"workload_cluster", "resource"},
+	)
+
+	prunedObjects = metrics.NewCounterVec(
+		&metrics.CounterOpts{
+			Namespace:      namespace,
+			Subsystem:      subsystem,
+			Name:           "pruned_downstream_objects_total",
+			Help:           "Number of orphaned downstream objects deleted by the orphan pruning, by workload cluster and resource.",
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"workload_cluster", "resource"},
+	)
+)
+
+var registerMetrics sync.Once
+
+// RegisterMetrics registers the orphan pruning metrics.
+func RegisterMetrics() {
+	registerMetrics.Do(func() {
+		legacyregistry.MustRegister(orphanedObjects)
+		legacyregistry.MustRegister(prunedObjects)
+	})
+}
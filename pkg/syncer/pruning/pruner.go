@@ -0,0 +1,301 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pruning
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/kcp-dev/logicalcluster"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/tools/clusters"
+
+	"github.com/kcp-dev/kcp/pkg/logging"
+	"github.com/kcp-dev/kcp/pkg/syncer/shared"
+)
+
+const (
+	controllerName = "kcp-workload-syncer-pruning"
+)
+
+// Mode defines what the orphan pruning does with downstream objects whose upstream counterpart is gone.
+type Mode string
+
+const (
+	// ModeDisabled turns the orphan pruning off.
+	ModeDisabled Mode = "disabled"
+	// ModeDryRun only logs orphaned downstream objects and reports them as metric.
+	ModeDryRun Mode = "dry-run"
+	// ModeEnforce deletes orphaned downstream objects.
+	ModeEnforce Mode = "enforce"
+)
+
+// Modes is the set of valid orphan pruning modes.
+var Modes = sets.NewString(string(ModeDisabled), string(ModeDryRun), string(ModeEnforce))
+
+var namespacesGVR = schema.GroupVersionResource{Version: "v1", Resource: "namespaces"}
+
+// Controller periodically looks for downstream objects of the workload cluster whose upstream object
+// does not exist anymore, e.g. because the syncer missed the delete event while it was down, and
+// removes them.
+type Controller struct {
+	mode Mode
+
+	downstreamClient                       dynamic.Interface
+	upstreamInformers, downstreamInformers dynamicinformer.DynamicSharedInformerFactory
+
+	gvrs                []schema.GroupVersionResource
+	workloadClusterName string
+	upstreamClusterName logicalcluster.Name
+	clusterScopedPolicy shared.ClusterScopedPolicy
+	syncPause           *shared.SyncPause
+
+	// orphans are the downstream objects found orphaned by the previous pass. In enforce mode,
+	// objects are only deleted when found orphaned twice in a row, giving the spec syncer the
+	// chance to handle the deletion in between.
+	orphans sets.String
+}
+
+func NewOrphanPruner(gvrs []schema.GroupVersionResource, upstreamClusterName logicalcluster.Name, workloadClusterName string, mode Mode, clusterScopedPolicy shared.ClusterScopedPolicy, syncPause *shared.SyncPause,
+	downstreamClient dynamic.Interface, upstreamInformers, downstreamInformers dynamicinformer.DynamicSharedInformerFactory) (*Controller, error) {
+	if !Modes.Has(string(mode)) {
+		return nil, fmt.Errorf("unknown orphan pruning mode %q, must be one of %v", mode, Modes.List())
+	}
+
+	RegisterMetrics()
+
+	c := &Controller{
+		mode: mode,
+
+		downstreamClient:    downstreamClient,
+		upstreamInformers:   upstreamInformers,
+		downstreamInformers: downstreamInformers,
+
+		workloadClusterName: workloadClusterName,
+		upstreamClusterName: upstreamClusterName,
+		clusterScopedPolicy: clusterScopedPolicy,
+		syncPause:           syncPause,
+
+		orphans: sets.NewString(),
+	}
+
+	for _, gvr := range gvrs {
+		if gvr == namespacesGVR {
+			// downstream namespaces are not synced from upstream
+			continue
+		}
+		c.gvrs = append(c.gvrs, gvr)
+
+		// make sure the informers are started
+		upstreamInformers.ForResource(gvr).Informer()
+		downstreamInformers.ForResource(gvr).Informer()
+	}
+	downstreamInformers.ForResource(namespacesGVR).Informer()
+
+	return c, nil
+}
+
+// Start runs a pruning pass every interval until the context is done. The informers are expected to be synced.
+func (c *Controller) Start(ctx context.Context, interval time.Duration) {
+	defer runtime.HandleCrash()
+
+	logger := logging.FromContext(ctx)
+	if c.mode == ModeDisabled {
+		logger.Info("Orphan pruning is disabled")
+		return
+	}
+
+	logger.Info("Starting orphan pruning", "mode", c.mode, "interval", interval)
+	defer logger.Info("Stopping orphan pruning")
+
+	wait.UntilWithContext(ctx, c.prune, interval)
+}
+
+func (c *Controller) prune(ctx context.Context) {
+	logger := logging.FromContext(ctx)
+	orphans := sets.NewString()
+	for _, gvr := range c.gvrs {
+		found, err := c.findOrphans(gvr)
+		if err != nil {
+			runtime.HandleError(fmt.Errorf("%s failed to find orphans of %q: %w", controllerName, gvr.String(), err))
+			continue
+		}
+		orphanedObjects.WithLabelValues(c.workloadClusterName, gvr.GroupResource().String()).Set(float64(len(found)))
+
+		for _, obj := range found {
+			key := orphanKey(gvr, obj)
+			logger := logger.WithValues("gvr", gvr.String(), "downstreamNamespace", obj.GetNamespace(), "downstreamName", obj.GetName())
+			orphans.Insert(key)
+
+			if c.mode != ModeEnforce {
+				logger.V(2).Info("Found orphaned downstream object")
+				continue
+			}
+			if !c.orphans.Has(key) {
+				logger.V(2).Info("Found orphaned downstream object, deleting it if still orphaned in the next pass")
+				continue
+			}
+
+			logger.Info("Deleting orphaned downstream object")
+			uid := obj.GetUID()
+			err := c.downstreamClient.Resource(gvr).Namespace(obj.GetNamespace()).Delete(ctx, obj.GetName(), metav1.DeleteOptions{
+				Preconditions: &metav1.Preconditions{UID: &uid},
+			})
+			if err != nil && !apierrors.IsNotFound(err) && !apierrors.IsConflict(err) {
+				runtime.HandleError(fmt.Errorf("%s failed to delete orphaned downstream GVR %q object %s/%s: %w", controllerName, gvr.String(), obj.GetNamespace(), obj.GetName(), err))
+				continue
+			}
+			prunedObjects.WithLabelValues(c.workloadClusterName, gvr.GroupResource().String()).Inc()
+		}
+	}
+	c.orphans = orphans
+}
+
+// findOrphans returns the downstream objects of the given resource which belong to the upstream logical
+// cluster of this syncer, but whose upstream object does not exist or is not scheduled to this workload
+// cluster anymore.
+func (c *Controller) findOrphans(gvr schema.GroupVersionResource) ([]*unstructured.Unstructured, error) {
+	downstreamObjs, err := c.downstreamInformers.ForResource(gvr).Lister().List(labels.Everything())
+	if err != nil {
+		return nil, err
+	}
+	upstreamIndexer := c.upstreamInformers.ForResource(gvr).Informer().GetIndexer()
+
+	var orphans []*unstructured.Unstructured
+	for _, o := range downstreamObjs {
+		obj, ok := o.(*unstructured.Unstructured)
+		if !ok {
+			return nil, fmt.Errorf("object is expected to be Unstructured, but is %T", o)
+		}
+		if obj.GetDeletionTimestamp() != nil {
+			continue
+		}
+		if c.syncPause.Mode(gvr.GroupResource(), c.namespaceAnnotations(obj)).PausesSpec() {
+			continue
+		}
+
+		var key string
+		if obj.GetNamespace() == "" && c.clusterScopedPolicy.Has(gvr) {
+			// cluster-scoped objects carry the locator of their logical cluster themselves
+			if !shared.IsOwnedBy(obj.GetAnnotations(), c.upstreamClusterName) {
+				continue
+			}
+			upstreamName, ok := c.clusterScopedPolicy.UpstreamName(obj.GetName())
+			if !ok {
+				continue
+			}
+			key = clusters.ToClusterAwareKey(c.upstreamClusterName, upstreamName)
+		} else {
+			upstreamNamespace, ok := c.upstreamNamespace(obj)
+			if !ok {
+				continue
+			}
+			upstreamName, ok := upstreamName(obj)
+			if !ok {
+				continue
+			}
+			key = upstreamNamespace + "/" + clusters.ToClusterAwareKey(c.upstreamClusterName, upstreamName)
+		}
+
+		_, exists, err := upstreamIndexer.GetByKey(key)
+		if err != nil {
+			return nil, err
+		}
+		if !exists {
+			orphans = append(orphans, obj)
+		}
+	}
+	return orphans, nil
+}
+
+// namespaceAnnotations returns the annotations of the downstream namespace of the given object, or nil if it is
+// cluster-scoped or the namespace is unknown.
+func (c *Controller) namespaceAnnotations(obj *unstructured.Unstructured) map[string]string {
+	if obj.GetNamespace() == "" {
+		return nil
+	}
+	nsKey := obj.GetNamespace()
+	if clusterName := logicalcluster.From(obj); !clusterName.Empty() {
+		nsKey = clusters.ToClusterAwareKey(clusterName, nsKey)
+	}
+	nsObj, err := c.downstreamInformers.ForResource(namespacesGVR).Lister().Get(nsKey)
+	if err != nil {
+		return nil
+	}
+	nsMeta, ok := nsObj.(metav1.Object)
+	if !ok {
+		return nil
+	}
+	return nsMeta.GetAnnotations()
+}
+
+// upstreamNamespace returns the upstream namespace of the given downstream object, or false if the
+// object does not live in a namespace synced from the upstream logical cluster of this syncer.
+func (c *Controller) upstreamNamespace(obj *unstructured.Unstructured) (string, bool) {
+	nsKey := obj.GetNamespace()
+	if clusterName := logicalcluster.From(obj); !clusterName.Empty() {
+		// If our "physical" cluster is a kcp instance (e.g. for testing purposes), it will return resources
+		// with metadata.clusterName set, which means their keys are cluster-aware, so we need to do the same here.
+		nsKey = clusters.ToClusterAwareKey(clusterName, nsKey)
+	}
+	nsObj, err := c.downstreamInformers.ForResource(namespacesGVR).Lister().Get(nsKey)
+	if err != nil {
+		// unknown namespaces are never pruned
+		return "", false
+	}
+	nsMeta, ok := nsObj.(metav1.Object)
+	if !ok {
+		return "", false
+	}
+	locator, err := shared.LocatorFromAnnotations(nsMeta.GetAnnotations())
+	if err != nil || locator == nil || locator.LogicalCluster != c.upstreamClusterName {
+		// Only prune resources of the configured logical cluster to ensure
+		// that syncers for multiple logical clusters can coexist.
+		return "", false
+	}
+	return locator.Namespace, true
+}
+
+// upstreamName reverts the name transformations of the spec syncer, or returns false if the
+// upstream name cannot be recovered.
+func upstreamName(obj *unstructured.Unstructured) (string, bool) {
+	switch gk := obj.GroupVersionKind().GroupKind(); {
+	case gk == schema.GroupKind{Kind: "ConfigMap"} && obj.GetName() == "kcp-root-ca.crt":
+		return "kube-root-ca.crt", true
+	case gk == schema.GroupKind{Kind: "ServiceAccount"} && obj.GetName() == "kcp-default":
+		return "default", true
+	case gk == schema.GroupKind{Kind: "Secret"} && obj.GetName() == "kcp-default-token":
+		// synced from one of the default-token-XXXX secrets, we cannot know which
+		return "", false
+	}
+	return obj.GetName(), true
+}
+
+func orphanKey(gvr schema.GroupVersionResource, obj *unstructured.Unstructured) string {
+	return gvr.String() + "/" + string(obj.GetUID())
+}
@@ -0,0 +1,292 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pruning
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kcp-dev/logicalcluster"
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/dynamic/dynamicinformer"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	clienttesting "k8s.io/client-go/testing"
+	"k8s.io/component-base/metrics/testutil"
+
+	workloadv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/workload/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/syncer/shared"
+)
+
+var (
+	configMapsGVR = schema.GroupVersionResource{Version: "v1", Resource: "configmaps"}
+	secretsGVR    = schema.GroupVersionResource{Version: "v1", Resource: "secrets"}
+
+	priorityClassesGVR = schema.GroupVersionResource{Group: "scheduling.k8s.io", Version: "v1", Resource: "priorityclasses"}
+)
+
+func TestFindOrphans(t *testing.T) {
+	tests := map[string]struct {
+		gvr        schema.GroupVersionResource
+		upstream   []*unstructured.Unstructured
+		downstream []*unstructured.Unstructured
+		paused     string
+		want       []string
+	}{
+		"upstream object exists": {
+			gvr:        configMapsGVR,
+			upstream:   []*unstructured.Unstructured{object("ConfigMap", "test", "root:org:ws", "foo", "")},
+			downstream: []*unstructured.Unstructured{object("ConfigMap", "kcp-test", "", "foo", "uid-foo")},
+		},
+		"upstream object is gone": {
+			gvr:        configMapsGVR,
+			upstream:   []*unstructured.Unstructured{object("ConfigMap", "test", "root:org:ws", "bar", "")},
+			downstream: []*unstructured.Unstructured{object("ConfigMap", "kcp-test", "", "foo", "uid-foo")},
+			want:       []string{"foo"},
+		},
+		"upstream object lives in another namespace": {
+			gvr:        configMapsGVR,
+			upstream:   []*unstructured.Unstructured{object("ConfigMap", "other", "root:org:ws", "foo", "")},
+			downstream: []*unstructured.Unstructured{object("ConfigMap", "kcp-test", "", "foo", "uid-foo")},
+			want:       []string{"foo"},
+		},
+		"namespace of another logical cluster": {
+			gvr:        configMapsGVR,
+			downstream: []*unstructured.Unstructured{object("ConfigMap", "kcp-other-cluster", "", "foo", "uid-foo")},
+		},
+		"namespace without locator": {
+			gvr:        configMapsGVR,
+			downstream: []*unstructured.Unstructured{object("ConfigMap", "unmanaged", "", "foo", "uid-foo")},
+		},
+		"paused namespace": {
+			gvr:        configMapsGVR,
+			downstream: []*unstructured.Unstructured{object("ConfigMap", "kcp-paused", "", "foo", "uid-foo")},
+		},
+		"paused resource": {
+			gvr:        configMapsGVR,
+			downstream: []*unstructured.Unstructured{object("ConfigMap", "kcp-test", "", "foo", "uid-foo")},
+			paused:     "configmaps=Spec",
+		},
+		"other paused resource": {
+			gvr:        configMapsGVR,
+			downstream: []*unstructured.Unstructured{object("ConfigMap", "kcp-test", "", "foo", "uid-foo")},
+			paused:     "secrets=All",
+			want:       []string{"foo"},
+		},
+		"renamed root CA configmap": {
+			gvr:        configMapsGVR,
+			upstream:   []*unstructured.Unstructured{object("ConfigMap", "test", "root:org:ws", "kube-root-ca.crt", "")},
+			downstream: []*unstructured.Unstructured{object("ConfigMap", "kcp-test", "", "kcp-root-ca.crt", "uid-ca")},
+		},
+		"renamed default token secret is never pruned": {
+			gvr:        secretsGVR,
+			downstream: []*unstructured.Unstructured{object("Secret", "kcp-test", "", "kcp-default-token", "uid-token")},
+		},
+		"cluster-scoped upstream object exists": {
+			gvr:        priorityClassesGVR,
+			upstream:   []*unstructured.Unstructured{object("PriorityClass", "", "root:org:ws", "high", "")},
+			downstream: []*unstructured.Unstructured{ownedObject("PriorityClass", "kcp-high", "uid-high", `{"logical-cluster":"root:org:ws","namespace":""}`)},
+		},
+		"cluster-scoped upstream object is gone": {
+			gvr:        priorityClassesGVR,
+			downstream: []*unstructured.Unstructured{ownedObject("PriorityClass", "kcp-high", "uid-high", `{"logical-cluster":"root:org:ws","namespace":""}`)},
+			want:       []string{"kcp-high"},
+		},
+		"cluster-scoped object of another logical cluster": {
+			gvr:        priorityClassesGVR,
+			downstream: []*unstructured.Unstructured{ownedObject("PriorityClass", "kcp-high", "uid-high", `{"logical-cluster":"root:org:other","namespace":""}`)},
+		},
+		"cluster-scoped object without locator": {
+			gvr:        priorityClassesGVR,
+			downstream: []*unstructured.Unstructured{object("PriorityClass", "", "", "kcp-high", "uid-high")},
+		},
+		"object being deleted": {
+			gvr: configMapsGVR,
+			downstream: []*unstructured.Unstructured{func() *unstructured.Unstructured {
+				obj := object("ConfigMap", "kcp-test", "", "foo", "uid-foo")
+				now := metav1.Now()
+				obj.SetDeletionTimestamp(&now)
+				return obj
+			}()},
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			c, _ := newTestController(t, ModeDryRun, tc.gvr, tc.upstream, tc.downstream)
+			c.syncPause = shared.NewSyncPause()
+			require.NoError(t, c.syncPause.UpdateFromAnnotations(map[string]string{workloadv1alpha1.ExperimentalSyncPausedResourcesAnnotation: tc.paused}))
+
+			orphans, err := c.findOrphans(tc.gvr)
+			require.NoError(t, err)
+
+			var got []string
+			for _, obj := range orphans {
+				got = append(got, obj.GetName())
+			}
+			require.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestPrune(t *testing.T) {
+	downstream := []*unstructured.Unstructured{
+		object("ConfigMap", "kcp-test", "", "orphan", "uid-orphan"),
+		object("ConfigMap", "kcp-test", "", "synced", "uid-synced"),
+	}
+	upstream := []*unstructured.Unstructured{object("ConfigMap", "test", "root:org:ws", "synced", "")}
+
+	// the fake dynamic client drops the delete options, hence the UID precondition is not visible here
+	deleteAction := clienttesting.DeleteActionImpl{
+		ActionImpl: clienttesting.ActionImpl{
+			Namespace: "kcp-test",
+			Verb:      "delete",
+			Resource:  configMapsGVR,
+		},
+		Name: "orphan",
+	}
+
+	t.Run("dry-run", func(t *testing.T) {
+		c, client := newTestController(t, ModeDryRun, configMapsGVR, upstream, downstream)
+
+		c.prune(context.Background())
+		c.prune(context.Background())
+
+		require.Empty(t, client.Actions())
+		requireGauge(t, 1, "dry-run", configMapsGVR)
+	})
+
+	t.Run("enforce", func(t *testing.T) {
+		c, client := newTestController(t, ModeEnforce, configMapsGVR, upstream, downstream)
+
+		c.prune(context.Background())
+		require.Empty(t, client.Actions(), "orphans must only be deleted in the second pass")
+		requireGauge(t, 1, "enforce", configMapsGVR)
+
+		prunedBefore, err := testutil.GetCounterMetricValue(prunedObjects.WithLabelValues("enforce", configMapsGVR.GroupResource().String()))
+		require.NoError(t, err)
+
+		c.prune(context.Background())
+		require.Equal(t, []clienttesting.Action{deleteAction}, client.Actions())
+
+		pruned, err := testutil.GetCounterMetricValue(prunedObjects.WithLabelValues("enforce", configMapsGVR.GroupResource().String()))
+		require.NoError(t, err)
+		require.Equal(t, prunedBefore+1, pruned)
+	})
+
+	t.Run("enforce with orphan recreated in between", func(t *testing.T) {
+		c, client := newTestController(t, ModeEnforce, configMapsGVR, upstream, downstream)
+
+		c.prune(context.Background())
+
+		indexer := c.downstreamInformers.ForResource(configMapsGVR).Informer().GetIndexer()
+		require.NoError(t, indexer.Update(object("ConfigMap", "kcp-test", "", "orphan", "uid-recreated")))
+
+		c.prune(context.Background())
+		require.Empty(t, client.Actions())
+	})
+}
+
+func TestNewOrphanPrunerInvalidMode(t *testing.T) {
+	_, err := NewOrphanPruner(nil, logicalcluster.New("root:org:ws"), "us-west1", "sometimes", shared.ClusterScopedPolicy{}, nil, nil, nil, nil)
+	require.Error(t, err)
+}
+
+// newTestController returns a pruning controller for the workload cluster named like the mode, with
+// informers pre-filled with the given objects and the namespaces kcp-test for root:org:ws|test,
+// kcp-other-cluster for root:org:other|test, unmanaged without locator, and kcp-paused for root:org:ws|paused
+// with paused syncing.
+func newTestController(t *testing.T, mode Mode, gvr schema.GroupVersionResource, upstream, downstream []*unstructured.Unstructured) (*Controller, *dynamicfake.FakeDynamicClient) {
+	scheme := runtime.NewScheme()
+	require.NoError(t, corev1.AddToScheme(scheme))
+
+	var downstreamObjs []runtime.Object
+	for _, obj := range downstream {
+		downstreamObjs = append(downstreamObjs, obj)
+	}
+	downstreamClient := dynamicfake.NewSimpleDynamicClient(scheme, downstreamObjs...)
+	upstreamInformers := dynamicinformer.NewDynamicSharedInformerFactory(dynamicfake.NewSimpleDynamicClient(scheme), time.Hour)
+	downstreamInformers := dynamicinformer.NewDynamicSharedInformerFactory(downstreamClient, time.Hour)
+
+	clusterScopedPolicy := shared.ClusterScopedPolicy{GroupResources: sets.NewString(priorityClassesGVR.GroupResource().String()), NamePrefix: "kcp-"}
+	c, err := NewOrphanPruner([]schema.GroupVersionResource{namespacesGVR, gvr}, logicalcluster.New("root:org:ws"), string(mode), mode, clusterScopedPolicy, nil,
+		downstreamClient, upstreamInformers, downstreamInformers)
+	require.NoError(t, err)
+
+	for _, obj := range upstream {
+		require.NoError(t, upstreamInformers.ForResource(gvr).Informer().GetIndexer().Add(obj))
+	}
+	for _, obj := range downstream {
+		require.NoError(t, downstreamInformers.ForResource(gvr).Informer().GetIndexer().Add(obj))
+	}
+	namespaces := []*unstructured.Unstructured{
+		downstreamNamespace("kcp-test", `{"logical-cluster":"root:org:ws","namespace":"test"}`),
+		downstreamNamespace("kcp-other-cluster", `{"logical-cluster":"root:org:other","namespace":"test"}`),
+		downstreamNamespace("unmanaged", ""),
+		downstreamNamespace("kcp-paused", `{"logical-cluster":"root:org:ws","namespace":"paused"}`),
+	}
+	namespaces[3].SetAnnotations(map[string]string{
+		shared.NamespaceLocatorAnnotation:                 `{"logical-cluster":"root:org:ws","namespace":"paused"}`,
+		workloadv1alpha1.ExperimentalSyncPausedAnnotation: "Spec",
+	})
+	for _, ns := range namespaces {
+		require.NoError(t, downstreamInformers.ForResource(namespacesGVR).Informer().GetIndexer().Add(ns))
+	}
+
+	return c, downstreamClient
+}
+
+func requireGauge(t *testing.T, expected float64, workloadCluster string, gvr schema.GroupVersionResource) {
+	got, err := testutil.GetGaugeMetricValue(orphanedObjects.WithLabelValues(workloadCluster, gvr.GroupResource().String()))
+	require.NoError(t, err)
+	require.Equal(t, expected, got)
+}
+
+func object(kind, namespace, clusterName, name, uid string) *unstructured.Unstructured {
+	obj := &unstructured.Unstructured{}
+	obj.SetAPIVersion("v1")
+	obj.SetKind(kind)
+	obj.SetNamespace(namespace)
+	obj.SetClusterName(clusterName)
+	obj.SetName(name)
+	obj.SetUID(types.UID(uid))
+	return obj
+}
+
+func ownedObject(kind, name, uid, locator string) *unstructured.Unstructured {
+	obj := object(kind, "", "", name, uid)
+	obj.SetAnnotations(map[string]string{shared.NamespaceLocatorAnnotation: locator})
+	return obj
+}
+
+func downstreamNamespace(name, locator string) *unstructured.Unstructured {
+	ns := &unstructured.Unstructured{}
+	ns.SetAPIVersion("v1")
+	ns.SetKind("Namespace")
+	ns.SetName(name)
+	if locator != "" {
+		ns.SetAnnotations(map[string]string{"kcp.dev/namespace-locator": locator})
+	}
+	return ns
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package shared
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/kcp-dev/logicalcluster"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// ClusterScopedPolicy defines which cluster-scoped resources are synced downstream, and how they
+// are named there. Namespaces are never synced through it. Downstream, the synced objects carry the
+// NamespaceLocatorAnnotation with an empty namespace, identifying the upstream logical cluster that
+// owns them, and are only updated and deleted if owned by the logical cluster of the syncer.
+type ClusterScopedPolicy struct {
+	// GroupResources are the allow-listed cluster-scoped resources, e.g. "priorityclasses.scheduling.k8s.io".
+	GroupResources sets.String
+	// NamePrefix is prepended to the names of the synced objects downstream, e.g. to keep the objects of
+	// syncers of different logical clusters on the same physical cluster apart. References to the objects,
+	// e.g. priorityClassName of pods, are not rewritten, i.e. with a prefix workloads must reference the
+	// prefixed names.
+	NamePrefix string
+}
+
+// Validate checks that the name prefix leads to valid names.
+func (p ClusterScopedPolicy) Validate() error {
+	if p.NamePrefix == "" {
+		return nil
+	}
+	if errs := validation.IsDNS1123Subdomain(p.NamePrefix + "x"); len(errs) > 0 {
+		return fmt.Errorf("invalid cluster-scoped name prefix %q: %s", p.NamePrefix, strings.Join(errs, ", "))
+	}
+	return nil
+}
+
+// Has returns true if the given resource is synced as cluster-scoped resource.
+func (p ClusterScopedPolicy) Has(gvr schema.GroupVersionResource) bool {
+	if gvr.Group == "" && gvr.Resource == "namespaces" {
+		return false
+	}
+	return p.GroupResources.Has(gvr.GroupResource().String())
+}
+
+// DownstreamName returns the downstream name of the given upstream name.
+func (p ClusterScopedPolicy) DownstreamName(name string) string {
+	return p.NamePrefix + name
+}
+
+// UpstreamName returns the upstream name of the given downstream name, or false if the downstream
+// name does not carry the name prefix.
+func (p ClusterScopedPolicy) UpstreamName(name string) (string, bool) {
+	if !strings.HasPrefix(name, p.NamePrefix) {
+		return "", false
+	}
+	return strings.TrimPrefix(name, p.NamePrefix), true
+}
+
+// IsOwnedBy returns true if the given annotations of a downstream cluster-scoped object carry the
+// locator of the given upstream logical cluster.
+func IsOwnedBy(annotations map[string]string, clusterName logicalcluster.Name) bool {
+	locator, err := LocatorFromAnnotations(annotations)
+	return err == nil && locator != nil && locator.LogicalCluster == clusterName && locator.Namespace == ""
+}
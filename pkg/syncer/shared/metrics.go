@@ -0,0 +1,116 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package shared
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/component-base/metrics"
+	"k8s.io/component-base/metrics/legacyregistry"
+)
+
+const (
+	metricsNamespace = "kcp"
+	metricsSubsystem = "syncer"
+)
+
+var (
+	syncDuration = metrics.NewHistogramVec(
+		&metrics.HistogramOpts{
+			Namespace:      metricsNamespace,
+			Subsystem:      metricsSubsystem,
+			Name:           "sync_duration_seconds",
+			Help:           "Time to sync an object, by direction (spec or status), workload cluster, resource and result.",
+			Buckets:        metrics.ExponentialBuckets(0.001, 2, 15),
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"direction", "workload_cluster", "resource", "result"},
+	)
+
+	lastHeartbeat = metrics.NewGaugeVec(
+		&metrics.GaugeOpts{
+			Namespace:      metricsNamespace,
+			Subsystem:      metricsSubsystem,
+			Name:           "last_heartbeat_timestamp_seconds",
+			Help:           "Time of the last successful heartbeat of the syncer to its workload cluster in kcp, in seconds since the epoch.",
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"workload_cluster"},
+	)
+
+	applyConflicts = metrics.NewCounterVec(
+		&metrics.CounterOpts{
+			Namespace:      metricsNamespace,
+			Subsystem:      metricsSubsystem,
+			Name:           "apply_conflicts_total",
+			Help:           "Number of downstream applies conflicting with fields owned by other field managers on the physical cluster, by workload cluster and resource.",
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"workload_cluster", "resource"},
+	)
+
+	upstreamTokenExpiry = metrics.NewGaugeVec(
+		&metrics.GaugeOpts{
+			Namespace:      metricsNamespace,
+			Subsystem:      metricsSubsystem,
+			Name:           "upstream_token_expiry_timestamp_seconds",
+			Help:           "Expiry of the token the syncer uses to talk to kcp, in seconds since the epoch. Only reported if the token is rotated.",
+			StabilityLevel: metrics.ALPHA,
+		},
+		[]string{"workload_cluster"},
+	)
+)
+
+var registerMetrics sync.Once
+
+// RegisterMetrics registers the sync, heartbeat, apply conflict and token expiry metrics.
+func RegisterMetrics() {
+	registerMetrics.Do(func() {
+		legacyregistry.MustRegister(syncDuration)
+		legacyregistry.MustRegister(lastHeartbeat)
+		legacyregistry.MustRegister(applyConflicts)
+		legacyregistry.MustRegister(upstreamTokenExpiry)
+	})
+}
+
+// ObserveSync records the duration of syncing an object of the given resource, started at start,
+// in the given direction.
+func ObserveSync(direction, workloadClusterName string, gvr schema.GroupVersionResource, start time.Time, err error) {
+	result := "success"
+	if err != nil {
+		result = "error"
+	}
+	syncDuration.WithLabelValues(direction, workloadClusterName, gvr.GroupResource().String(), result).Observe(time.Since(start).Seconds())
+}
+
+// ObserveHeartbeat records a successful heartbeat of the syncer at the given time.
+func ObserveHeartbeat(workloadClusterName string, t time.Time) {
+	lastHeartbeat.WithLabelValues(workloadClusterName).Set(float64(t.Unix()))
+}
+
+// ObserveApplyConflict records a downstream apply of an object of the given resource conflicting
+// with other field managers.
+func ObserveApplyConflict(workloadClusterName string, gvr schema.GroupVersionResource) {
+	applyConflicts.WithLabelValues(workloadClusterName, gvr.GroupResource().String()).Inc()
+}
+
+// ObserveUpstreamTokenExpiry records the expiry of the token the syncer uses to talk to kcp.
+func ObserveUpstreamTokenExpiry(workloadClusterName string, t time.Time) {
+	upstreamTokenExpiry.WithLabelValues(workloadClusterName).Set(float64(t.Unix()))
+}
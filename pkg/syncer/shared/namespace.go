@@ -20,8 +20,13 @@ import (
 	"crypto/sha256"
 	"encoding/json"
 	"fmt"
+	"strings"
 
 	"github.com/kcp-dev/logicalcluster"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	workloadv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/workload/v1alpha1"
 )
 
 const (
@@ -50,10 +55,109 @@ func LocatorFromAnnotations(annotations map[string]string) (*NamespaceLocator, e
 // PhysicalClusterNamespaceName encodes the NamespaceLocator to a new
 // namespace name for use on a physical cluster. The encoding is repeatable.
 func PhysicalClusterNamespaceName(l NamespaceLocator) (string, error) {
+	return NamespaceNamer{Strategy: NamespaceNamingHashed}.Name(l)
+}
+
+// NamespaceNamingStrategy defines how upstream namespaces are mapped to
+// namespace names on a physical cluster.
+type NamespaceNamingStrategy string
+
+const (
+	// NamespaceNamingHashed maps to <prefix><sha224 of the locator>. This is the default.
+	NamespaceNamingHashed NamespaceNamingStrategy = "hashed"
+	// NamespaceNamingReadable maps to <prefix><workspace>-<namespace>, with the colons of the
+	// workspace replaced by dashes. Names longer than a namespace name allows are truncated
+	// and suffixed with a short hash.
+	NamespaceNamingReadable NamespaceNamingStrategy = "readable"
+
+	defaultHashedNamespacePrefix = "kcp"
+	// maxHashedNamespacePrefix keeps hashed names within 63 characters.
+	maxHashedNamespacePrefix = validation.DNS1123LabelMaxLength - sha256.Size224*2
+	// readableNamespaceHashLength is the length of the hash suffix of truncated readable names.
+	readableNamespaceHashLength = 8
+)
+
+// NamespaceNamer maps upstream namespaces to namespace names on a physical cluster.
+type NamespaceNamer struct {
+	// Strategy is the naming strategy. Empty means NamespaceNamingHashed.
+	Strategy NamespaceNamingStrategy
+	// Prefix is prepended to every namespace name. Empty means the default of the
+	// strategy, i.e. "kcp" for hashed and none for readable names.
+	Prefix string
+}
+
+// NamespaceNamerFromAnnotations returns the NamespaceNamer configured through the
+// experimental namespace naming annotations of a workload cluster.
+func NamespaceNamerFromAnnotations(annotations map[string]string) (NamespaceNamer, error) {
+	namer := NamespaceNamer{
+		Strategy: NamespaceNamingStrategy(annotations[workloadv1alpha1.ExperimentalNamespaceNamingStrategyAnnotation]),
+		Prefix:   annotations[workloadv1alpha1.ExperimentalNamespacePrefixAnnotation],
+	}
+	if err := namer.Validate(); err != nil {
+		return NamespaceNamer{}, err
+	}
+	return namer, nil
+}
+
+// Validate checks that the strategy is known and the prefix leads to valid namespace names.
+func (n NamespaceNamer) Validate() error {
+	switch n.Strategy {
+	case "", NamespaceNamingHashed:
+		if len(n.Prefix) > maxHashedNamespacePrefix {
+			return fmt.Errorf("namespace prefix %q must not be longer than %d characters for the %s strategy", n.Prefix, maxHashedNamespacePrefix, NamespaceNamingHashed)
+		}
+	case NamespaceNamingReadable:
+	default:
+		return fmt.Errorf("unknown namespace naming strategy %q, must be one of %s, %s", n.Strategy, NamespaceNamingHashed, NamespaceNamingReadable)
+	}
+	if n.Prefix != "" {
+		// the prefix must be valid as the start of a DNS label
+		if errs := validation.IsDNS1123Label(n.Prefix + "x"); len(errs) > 0 {
+			return fmt.Errorf("invalid namespace prefix %q: %s", n.Prefix, strings.Join(errs, ", "))
+		}
+	}
+	return nil
+}
+
+// Name encodes the NamespaceLocator to a namespace name for use on a physical
+// cluster. The encoding is repeatable.
+func (n NamespaceNamer) Name(l NamespaceLocator) (string, error) {
 	b, err := json.Marshal(l)
 	if err != nil {
 		return "", err
 	}
 	hash := sha256.Sum224(b)
-	return fmt.Sprintf("kcp%x", hash), nil
+
+	switch n.Strategy {
+	case "", NamespaceNamingHashed:
+		prefix := n.Prefix
+		if prefix == "" {
+			prefix = defaultHashedNamespacePrefix
+		}
+		return fmt.Sprintf("%s%x", prefix, hash), nil
+	case NamespaceNamingReadable:
+		name := n.Prefix + sanitizeNamespaceName(l.LogicalCluster.String()+"-"+l.Namespace)
+		if len(name) > validation.DNS1123LabelMaxLength {
+			truncated := strings.TrimRight(name[:validation.DNS1123LabelMaxLength-readableNamespaceHashLength-1], "-")
+			name = fmt.Sprintf("%s-%x", truncated, hash[:readableNamespaceHashLength/2])
+		}
+		return name, nil
+	default:
+		return "", fmt.Errorf("unknown namespace naming strategy %q", n.Strategy)
+	}
+}
+
+// sanitizeNamespaceName lower-cases the given name and replaces all characters
+// not allowed in a namespace name by dashes.
+func sanitizeNamespaceName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r - 'A' + 'a'
+		default:
+			return '-'
+		}
+	}, name)
 }
@@ -0,0 +1,159 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package shared
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/kcp-dev/logicalcluster"
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	workloadv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/workload/v1alpha1"
+)
+
+func TestNamespaceNamerName(t *testing.T) {
+	tests := []struct {
+		name    string
+		namer   NamespaceNamer
+		locator NamespaceLocator
+		want    string
+		wantErr bool
+	}{
+		{
+			name:    "default is hashed",
+			locator: NamespaceLocator{LogicalCluster: logicalcluster.New("root:org:ws"), Namespace: "test"},
+			want:    "kcp0124d7647eb6a00b1fcb6f2252201601634989dd79deb7375c373973",
+		},
+		{
+			name:    "hashed with custom prefix",
+			namer:   NamespaceNamer{Strategy: NamespaceNamingHashed, Prefix: "east-"},
+			locator: NamespaceLocator{LogicalCluster: logicalcluster.New("root:org:ws"), Namespace: "test"},
+			want:    "east-0124d7647eb6a00b1fcb6f2252201601634989dd79deb7375c373973",
+		},
+		{
+			name:    "readable",
+			namer:   NamespaceNamer{Strategy: NamespaceNamingReadable},
+			locator: NamespaceLocator{LogicalCluster: logicalcluster.New("root:org:ws"), Namespace: "test"},
+			want:    "root-org-ws-test",
+		},
+		{
+			name:    "readable with prefix",
+			namer:   NamespaceNamer{Strategy: NamespaceNamingReadable, Prefix: "kcp-"},
+			locator: NamespaceLocator{LogicalCluster: logicalcluster.New("root:org:ws"), Namespace: "test"},
+			want:    "kcp-root-org-ws-test",
+		},
+		{
+			name:    "readable with upper case workspace",
+			namer:   NamespaceNamer{Strategy: NamespaceNamingReadable},
+			locator: NamespaceLocator{LogicalCluster: logicalcluster.New("root:Org:ws"), Namespace: "test"},
+			want:    "root-org-ws-test",
+		},
+		{
+			name:    "unknown strategy",
+			namer:   NamespaceNamer{Strategy: "foo"},
+			locator: NamespaceLocator{LogicalCluster: logicalcluster.New("root:org:ws"), Namespace: "test"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.namer.Name(tt.locator)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestNamespaceNamerNameTruncation(t *testing.T) {
+	namer := NamespaceNamer{Strategy: NamespaceNamingReadable}
+	long := NamespaceLocator{LogicalCluster: logicalcluster.New("root:" + strings.Repeat("a", 40)), Namespace: strings.Repeat("b", 40)}
+	other := NamespaceLocator{LogicalCluster: long.LogicalCluster, Namespace: strings.Repeat("b", 41)}
+
+	name, err := namer.Name(long)
+	require.NoError(t, err)
+	require.Empty(t, validation.IsDNS1123Label(name))
+	require.Len(t, name, validation.DNS1123LabelMaxLength)
+
+	otherName, err := namer.Name(other)
+	require.NoError(t, err)
+	require.Empty(t, validation.IsDNS1123Label(otherName))
+	require.NotEqual(t, name, otherName, "truncated names must be disambiguated by the hash suffix")
+
+	again, err := namer.Name(long)
+	require.NoError(t, err)
+	require.Equal(t, name, again, "names must be repeatable")
+}
+
+func TestNamespaceNamerFromAnnotations(t *testing.T) {
+	tests := []struct {
+		name        string
+		annotations map[string]string
+		want        NamespaceNamer
+		wantErr     bool
+	}{
+		{
+			name: "no annotations",
+		},
+		{
+			name: "readable with prefix",
+			annotations: map[string]string{
+				workloadv1alpha1.ExperimentalNamespaceNamingStrategyAnnotation: "readable",
+				workloadv1alpha1.ExperimentalNamespacePrefixAnnotation:         "kcp-",
+			},
+			want: NamespaceNamer{Strategy: NamespaceNamingReadable, Prefix: "kcp-"},
+		},
+		{
+			name: "unknown strategy",
+			annotations: map[string]string{
+				workloadv1alpha1.ExperimentalNamespaceNamingStrategyAnnotation: "random",
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid prefix",
+			annotations: map[string]string{
+				workloadv1alpha1.ExperimentalNamespacePrefixAnnotation: "Kcp_",
+			},
+			wantErr: true,
+		},
+		{
+			name: "hashed prefix too long",
+			annotations: map[string]string{
+				workloadv1alpha1.ExperimentalNamespacePrefixAnnotation: "too-long-",
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NamespaceNamerFromAnnotations(tt.annotations)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,173 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package shared
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	workloadv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/workload/v1alpha1"
+)
+
+// PauseMode is how far syncing is paused, see workloadv1alpha1.ExperimentalSyncPausedAnnotation.
+type PauseMode string
+
+const (
+	// NotPaused syncs in both directions.
+	NotPaused PauseMode = ""
+	// PauseSpec stops syncing downstream, but keeps syncing the status upstream.
+	PauseSpec PauseMode = "Spec"
+	// PauseAll stops syncing in both directions.
+	PauseAll PauseMode = "All"
+)
+
+// ParsePauseMode parses the value of a pause annotation.
+func ParsePauseMode(value string) (PauseMode, error) {
+	switch mode := PauseMode(value); mode {
+	case NotPaused, PauseSpec, PauseAll:
+		return mode, nil
+	default:
+		return NotPaused, fmt.Errorf("invalid pause mode %q, must be %q or %q", value, PauseSpec, PauseAll)
+	}
+}
+
+// PausesSpec returns true if objects are not applied or deleted downstream.
+func (m PauseMode) PausesSpec() bool {
+	return m == PauseSpec || m == PauseAll
+}
+
+// PausesStatus returns true if the status is not synced upstream.
+func (m PauseMode) PausesStatus() bool {
+	return m == PauseAll
+}
+
+// max returns the mode pausing more of the two.
+func (m PauseMode) max(other PauseMode) PauseMode {
+	if m == PauseAll || other == PauseAll {
+		return PauseAll
+	}
+	if m == PauseSpec || other == PauseSpec {
+		return PauseSpec
+	}
+	return NotPaused
+}
+
+// NamespacePauseMode returns the pause mode set on a downstream namespace with the given annotations. Invalid
+// values pause everything, as pausing is what the operator asked for.
+func NamespacePauseMode(annotations map[string]string) PauseMode {
+	value, found := annotations[workloadv1alpha1.ExperimentalSyncPausedAnnotation]
+	if !found {
+		return NotPaused
+	}
+	mode, err := ParsePauseMode(value)
+	if err != nil || mode == NotPaused {
+		return PauseAll
+	}
+	return mode
+}
+
+// SyncPause holds the resources the syncer is paused for, as configured on the workload cluster. It is shared
+// by the spec syncer, the status syncer and the orphan pruner. A nil SyncPause pauses nothing.
+type SyncPause struct {
+	lock      sync.RWMutex
+	resources map[schema.GroupResource]PauseMode
+	handlers  []func(gr schema.GroupResource)
+}
+
+func NewSyncPause() *SyncPause {
+	return &SyncPause{resources: map[schema.GroupResource]PauseMode{}}
+}
+
+// AddResumeHandler adds a handler called when pausing of a resource is lifted or reduced, such that the
+// objects of the resource can be synced again.
+func (p *SyncPause) AddResumeHandler(handler func(gr schema.GroupResource)) {
+	if p == nil {
+		return
+	}
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.handlers = append(p.handlers, handler)
+}
+
+// Mode returns the pause mode of objects of the given resource in a downstream namespace with the given
+// annotations. For cluster-scoped objects, the annotations are nil.
+func (p *SyncPause) Mode(gr schema.GroupResource, namespaceAnnotations map[string]string) PauseMode {
+	mode := NamespacePauseMode(namespaceAnnotations)
+	if p == nil {
+		return mode
+	}
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+	return mode.max(p.resources[gr])
+}
+
+// UpdateFromAnnotations sets the paused resources from the given annotations of the workload cluster. If the
+// annotation is invalid, the paused resources are not changed.
+func (p *SyncPause) UpdateFromAnnotations(annotations map[string]string) error {
+	resources, err := ParsePausedResources(annotations[workloadv1alpha1.ExperimentalSyncPausedResourcesAnnotation])
+	if err != nil {
+		return fmt.Errorf("invalid %s annotation: %w", workloadv1alpha1.ExperimentalSyncPausedResourcesAnnotation, err)
+	}
+
+	p.lock.Lock()
+	var resumed []schema.GroupResource
+	for gr, old := range p.resources {
+		if resources[gr] != old && resources[gr].max(old) == old {
+			resumed = append(resumed, gr)
+		}
+	}
+	p.resources = resources
+	handlers := p.handlers
+	p.lock.Unlock()
+
+	sort.Slice(resumed, func(i, j int) bool { return resumed[i].String() < resumed[j].String() })
+	for _, gr := range resumed {
+		for _, handler := range handlers {
+			handler(gr)
+		}
+	}
+	return nil
+}
+
+// ParsePausedResources parses the value of the workloadv1alpha1.ExperimentalSyncPausedResourcesAnnotation
+// annotation.
+func ParsePausedResources(value string) (map[schema.GroupResource]PauseMode, error) {
+	resources := map[schema.GroupResource]PauseMode{}
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		parts := strings.SplitN(entry, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return nil, fmt.Errorf("invalid entry %q, must be <resource>.<group>=<mode>", entry)
+		}
+		mode, err := ParsePauseMode(parts[1])
+		if err != nil {
+			return nil, err
+		}
+		if mode == NotPaused {
+			continue
+		}
+		resources[schema.ParseGroupResource(parts[0])] = mode
+	}
+	return resources, nil
+}
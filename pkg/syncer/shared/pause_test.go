@@ -0,0 +1,112 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package shared
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	workloadv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/workload/v1alpha1"
+)
+
+var (
+	deploymentsGR = schema.GroupResource{Group: "apps", Resource: "deployments"}
+	servicesGR    = schema.GroupResource{Resource: "services"}
+)
+
+func TestParsePausedResources(t *testing.T) {
+	tests := map[string]struct {
+		value   string
+		want    map[schema.GroupResource]PauseMode
+		wantErr bool
+	}{
+		"empty": {
+			want: map[schema.GroupResource]PauseMode{},
+		},
+		"resources": {
+			value: "deployments.apps=Spec, services=All,configmaps=",
+			want:  map[schema.GroupResource]PauseMode{deploymentsGR: PauseSpec, servicesGR: PauseAll},
+		},
+		"missing mode": {
+			value:   "deployments.apps",
+			wantErr: true,
+		},
+		"invalid mode": {
+			value:   "deployments.apps=Status",
+			wantErr: true,
+		},
+	}
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			got, err := ParsePausedResources(tc.value)
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.want, got)
+		})
+	}
+}
+
+func TestSyncPauseMode(t *testing.T) {
+	p := NewSyncPause()
+	require.NoError(t, p.UpdateFromAnnotations(map[string]string{workloadv1alpha1.ExperimentalSyncPausedResourcesAnnotation: "deployments.apps=Spec"}))
+
+	tests := map[string]struct {
+		gr          schema.GroupResource
+		annotations map[string]string
+		want        PauseMode
+	}{
+		"not paused":                          {gr: servicesGR, want: NotPaused},
+		"resource paused":                     {gr: deploymentsGR, want: PauseSpec},
+		"namespace paused":                    {gr: servicesGR, annotations: map[string]string{workloadv1alpha1.ExperimentalSyncPausedAnnotation: "Spec"}, want: PauseSpec},
+		"namespace pauses more than resource": {gr: deploymentsGR, annotations: map[string]string{workloadv1alpha1.ExperimentalSyncPausedAnnotation: "All"}, want: PauseAll},
+		"invalid namespace pause pauses all":  {gr: servicesGR, annotations: map[string]string{workloadv1alpha1.ExperimentalSyncPausedAnnotation: "yes"}, want: PauseAll},
+	}
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, tc.want, p.Mode(tc.gr, tc.annotations))
+		})
+	}
+
+	require.Equal(t, NotPaused, (*SyncPause)(nil).Mode(deploymentsGR, nil))
+}
+
+func TestSyncPauseResume(t *testing.T) {
+	p := NewSyncPause()
+	var resumed []schema.GroupResource
+	p.AddResumeHandler(func(gr schema.GroupResource) { resumed = append(resumed, gr) })
+
+	update := func(value string) []schema.GroupResource {
+		resumed = nil
+		require.NoError(t, p.UpdateFromAnnotations(map[string]string{workloadv1alpha1.ExperimentalSyncPausedResourcesAnnotation: value}))
+		return resumed
+	}
+
+	require.Empty(t, update("deployments.apps=All,services=Spec"))
+	require.Equal(t, []schema.GroupResource{deploymentsGR}, update("deployments.apps=Spec,services=All"))
+	require.Equal(t, []schema.GroupResource{deploymentsGR, servicesGR}, update(""))
+
+	require.Error(t, p.UpdateFromAnnotations(map[string]string{workloadv1alpha1.ExperimentalSyncPausedResourcesAnnotation: "services"}))
+	require.Equal(t, NotPaused, p.Mode(servicesGR, nil))
+}
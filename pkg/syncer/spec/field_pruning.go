@@ -0,0 +1,145 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spec
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	workloadv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/workload/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/logging"
+	conditionsv1alpha1 "github.com/kcp-dev/kcp/third_party/conditions/apis/conditions/v1alpha1"
+)
+
+const (
+	// SyncedCondition is the type of the condition stored in the sync condition annotation of upstream objects.
+	SyncedCondition conditionsv1alpha1.ConditionType = "Synced"
+
+	// ObjectTooLargeReason is the reason of the Synced condition of objects exceeding the maximal object size.
+	ObjectTooLargeReason = "ObjectTooLarge"
+
+	// DefaultMaxObjectSize is the default maximal size of downstream objects, matching the default maximal
+	// request size of etcd.
+	DefaultMaxObjectSize = 1536 * 1024
+)
+
+// FieldPruningPolicy defines which fields of upstream objects are not synced downstream, on top of the
+// managed fields and the other metadata owned by the syncer or the physical cluster.
+type FieldPruningPolicy struct {
+	// PruneStatus drops the status of objects. The status is owned by the physical cluster, but it is
+	// applied along with the object for resources without status subresource.
+	PruneStatus bool
+	// PrunedAnnotations are the keys of annotations which are not synced.
+	PrunedAnnotations sets.String
+	// MaxAnnotationSize drops annotations with longer values, e.g. kubectl.kubernetes.io/last-applied-configuration.
+	// Zero means no limit.
+	MaxAnnotationSize int
+	// MaxObjectSize is the maximal size in bytes of a serialized downstream object. Larger objects are not
+	// synced, but reported with the Synced condition upstream. Zero means no limit.
+	MaxObjectSize int
+}
+
+// prune drops the fields of the given downstream object according to the policy.
+func (p FieldPruningPolicy) prune(downstreamObj *unstructured.Unstructured) {
+	if p.PruneStatus {
+		unstructured.RemoveNestedField(downstreamObj.Object, "status")
+	}
+
+	annotations := downstreamObj.GetAnnotations()
+	if len(annotations) == 0 {
+		return
+	}
+	for k, v := range annotations {
+		if p.PrunedAnnotations.Has(k) || (p.MaxAnnotationSize > 0 && len(v) > p.MaxAnnotationSize) {
+			delete(annotations, k)
+		}
+	}
+	downstreamObj.SetAnnotations(annotations)
+}
+
+// checkSize returns an error if the serialized downstream object exceeds the maximal object size.
+func (p FieldPruningPolicy) checkSize(data []byte) error {
+	if p.MaxObjectSize > 0 && len(data) > p.MaxObjectSize {
+		return fmt.Errorf("object of %d bytes exceeds the maximal size of %d bytes", len(data), p.MaxObjectSize)
+	}
+	return nil
+}
+
+// updateSyncCondition stores the given condition in the sync condition annotation of the upstream object,
+// or removes the annotation if the condition is nil. It is a noop if the annotation is up-to-date.
+func (c *Controller) updateSyncCondition(ctx context.Context, gvr schema.GroupVersionResource, upstreamObj *unstructured.Unstructured, condition *conditionsv1alpha1.Condition) error {
+	key := workloadv1alpha1.ExperimentalClusterSyncConditionAnnotationPrefix + c.workloadClusterName
+	existing, found := upstreamObj.GetAnnotations()[key]
+
+	var value *string
+	if condition != nil {
+		if found {
+			var old conditionsv1alpha1.Condition
+			if err := json.Unmarshal([]byte(existing), &old); err == nil && old.Type == condition.Type && old.Status == condition.Status && old.Reason == condition.Reason && old.Message == condition.Message {
+				return nil
+			}
+		}
+		bs, err := json.Marshal(condition)
+		if err != nil {
+			return err
+		}
+		s := string(bs)
+		value = &s
+	} else if !found {
+		return nil
+	}
+
+	return c.patchUpstreamAnnotation(ctx, gvr, upstreamObj, key, value)
+}
+
+// patchUpstreamAnnotation sets the annotation with the given key of the upstream object to the given value,
+// or removes it if the value is nil.
+func (c *Controller) patchUpstreamAnnotation(ctx context.Context, gvr schema.GroupVersionResource, upstreamObj *unstructured.Unstructured, key string, value *string) error {
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]*string{key: value},
+		},
+	})
+	if err != nil {
+		return err
+	}
+	if _, err := c.upstreamClient.Resource(gvr).Namespace(upstreamObj.GetNamespace()).Patch(ctx, upstreamObj.GetName(), types.MergePatchType, patch, metav1.PatchOptions{}); err != nil {
+		logging.FromContext(ctx).Error(err, "Failed to update annotation upstream", "annotation", key)
+		return err
+	}
+	return nil
+}
+
+// objectTooLargeCondition returns the Synced condition of an object exceeding the maximal object size.
+func objectTooLargeCondition(err error) *conditionsv1alpha1.Condition {
+	return &conditionsv1alpha1.Condition{
+		Type:               SyncedCondition,
+		Status:             corev1.ConditionFalse,
+		Severity:           conditionsv1alpha1.ConditionSeverityError,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ObjectTooLargeReason,
+		Message:            err.Error(),
+	}
+}
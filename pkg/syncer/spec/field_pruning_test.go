@@ -0,0 +1,82 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spec
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+func TestFieldPruningPolicyPrune(t *testing.T) {
+	newObj := func() *unstructured.Unstructured {
+		return &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "v1",
+			"kind":       "ConfigMap",
+			"metadata": map[string]interface{}{
+				"name": "test",
+				"annotations": map[string]interface{}{
+					"small":   "value",
+					"large":   strings.Repeat("x", 100),
+					"dropped": "value",
+				},
+			},
+			"status": map[string]interface{}{"phase": "Running"},
+		}}
+	}
+
+	tests := map[string]struct {
+		policy          FieldPruningPolicy
+		wantAnnotations map[string]string
+		wantStatus      bool
+	}{
+		"no pruning": {
+			wantAnnotations: map[string]string{"small": "value", "large": strings.Repeat("x", 100), "dropped": "value"},
+			wantStatus:      true,
+		},
+		"prune status": {
+			policy:          FieldPruningPolicy{PruneStatus: true},
+			wantAnnotations: map[string]string{"small": "value", "large": strings.Repeat("x", 100), "dropped": "value"},
+		},
+		"prune annotations by key and size": {
+			policy:          FieldPruningPolicy{PrunedAnnotations: sets.NewString("dropped"), MaxAnnotationSize: 10},
+			wantAnnotations: map[string]string{"small": "value"},
+			wantStatus:      true,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			obj := newObj()
+			tc.policy.prune(obj)
+			require.Equal(t, tc.wantAnnotations, obj.GetAnnotations())
+			_, found, err := unstructured.NestedMap(obj.Object, "status")
+			require.NoError(t, err)
+			require.Equal(t, tc.wantStatus, found)
+		})
+	}
+}
+
+func TestFieldPruningPolicyCheckSize(t *testing.T) {
+	data := []byte(strings.Repeat("x", 100))
+	require.NoError(t, FieldPruningPolicy{}.checkSize(data))
+	require.NoError(t, FieldPruningPolicy{MaxObjectSize: 100}.checkSize(data))
+	require.Error(t, FieldPruningPolicy{MaxObjectSize: 99}.checkSize(data))
+}
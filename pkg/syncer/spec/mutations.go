@@ -0,0 +1,147 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spec
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	workloadv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/workload/v1alpha1"
+)
+
+// maxMutationPaths is the maximal number of paths recorded in the mutations annotation.
+const maxMutationPaths = 20
+
+// downstreamMutations is the value of the mutations annotation of upstream objects, summarizing how
+// the downstream object differs from the upstream object.
+type downstreamMutations struct {
+	Added     []string `json:"added,omitempty"`
+	Removed   []string `json:"removed,omitempty"`
+	Changed   []string `json:"changed,omitempty"`
+	Truncated int      `json:"truncated,omitempty"`
+}
+
+// unsyncedMetadata are the metadata fields owned by kcp or the physical cluster, which are never synced
+// and hence not reported as mutations.
+var unsyncedMetadata = []string{
+	"uid", "resourceVersion", "generation", "creationTimestamp", "deletionTimestamp", "deletionGracePeriodSeconds",
+	"selfLink", "managedFields", "clusterName", "ownerReferences", "finalizers",
+}
+
+// diffDownstream returns the mutations of the given downstream object compared to the upstream object
+// it originates from, or nil if there are none. Fields never synced, like the status and the metadata
+// owned by kcp or the physical cluster, are not reported.
+func (c *Controller) diffDownstream(upstreamObj, downstreamObj *unstructured.Unstructured) *downstreamMutations {
+	expected, actual := upstreamObj.DeepCopy(), downstreamObj.DeepCopy()
+	for _, obj := range []*unstructured.Unstructured{expected, actual} {
+		unstructured.RemoveNestedField(obj.Object, "status")
+		for _, field := range unsyncedMetadata {
+			unstructured.RemoveNestedField(obj.Object, "metadata", field)
+		}
+	}
+	annotations := expected.GetAnnotations()
+	delete(annotations, workloadv1alpha1.ExperimentalClusterSyncConditionAnnotationPrefix+c.workloadClusterName)
+	delete(annotations, workloadv1alpha1.ExperimentalClusterMutationsAnnotationPrefix+c.workloadClusterName)
+	expected.SetAnnotations(annotations)
+	labels := expected.GetLabels()
+	delete(labels, workloadv1alpha1.InternalClusterResourceStateLabelPrefix+c.workloadClusterName)
+	expected.SetLabels(labels)
+
+	mutations := &downstreamMutations{}
+	diffValues(mutations, "", expected.Object, actual.Object)
+	if len(mutations.Added)+len(mutations.Removed)+len(mutations.Changed) == 0 {
+		return nil
+	}
+	return mutations
+}
+
+// diffValues records the differences of the given values at the given JSON pointer in mutations.
+// Maps are compared key by key, all other values as a whole.
+func diffValues(mutations *downstreamMutations, path string, expected, actual interface{}) {
+	expectedMap, expectedIsMap := expected.(map[string]interface{})
+	actualMap, actualIsMap := actual.(map[string]interface{})
+	if !expectedIsMap || !actualIsMap {
+		if !equality.Semantic.DeepEqual(expected, actual) {
+			mutations.record(&mutations.Changed, path)
+		}
+		return
+	}
+
+	keys := make([]string, 0, len(expectedMap)+len(actualMap))
+	for k := range expectedMap {
+		keys = append(keys, k)
+	}
+	for k := range actualMap {
+		if _, found := expectedMap[k]; !found {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		childPath := path + "/" + strings.NewReplacer("~", "~0", "/", "~1").Replace(k)
+		expectedValue, inExpected := expectedMap[k]
+		actualValue, inActual := actualMap[k]
+		switch {
+		case !inExpected:
+			mutations.record(&mutations.Added, childPath)
+		case !inActual:
+			mutations.record(&mutations.Removed, childPath)
+		default:
+			diffValues(mutations, childPath, expectedValue, actualValue)
+		}
+	}
+}
+
+// record appends the path to the given list, or counts it as truncated if maxMutationPaths are recorded already.
+func (m *downstreamMutations) record(paths *[]string, path string) {
+	if len(m.Added)+len(m.Removed)+len(m.Changed) >= maxMutationPaths {
+		m.Truncated++
+		return
+	}
+	*paths = append(*paths, path)
+}
+
+// updateMutations stores the given mutations in the mutations annotation of the upstream object, or
+// removes the annotation if there are none. It is a noop if the annotation is up-to-date.
+func (c *Controller) updateMutations(ctx context.Context, gvr schema.GroupVersionResource, upstreamObj *unstructured.Unstructured, mutations *downstreamMutations) error {
+	key := workloadv1alpha1.ExperimentalClusterMutationsAnnotationPrefix + c.workloadClusterName
+	existing, found := upstreamObj.GetAnnotations()[key]
+
+	var value *string
+	if mutations != nil {
+		bs, err := json.Marshal(mutations)
+		if err != nil {
+			return err
+		}
+		if found && existing == string(bs) {
+			return nil
+		}
+		s := string(bs)
+		value = &s
+	} else if !found {
+		return nil
+	}
+
+	return c.patchUpstreamAnnotation(ctx, gvr, upstreamObj, key, value)
+}
@@ -0,0 +1,120 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spec
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+func TestDiffDownstream(t *testing.T) {
+	upstream := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata": map[string]interface{}{
+			"name":            "test",
+			"namespace":       "default",
+			"uid":             "1234",
+			"resourceVersion": "42",
+			"clusterName":     "root:org:ws",
+			"labels": map[string]interface{}{
+				"app": "test",
+				"state.internal.workloads.kcp.dev/us-west1": "Sync",
+			},
+			"annotations": map[string]interface{}{
+				"kubectl.kubernetes.io/last-applied-configuration":       "{}",
+				"experimental.sync-condition.workloads.kcp.dev/us-west1": "{}",
+				"experimental.mutations.workloads.kcp.dev/us-west1":      "{}",
+			},
+		},
+		"spec": map[string]interface{}{
+			"replicas": int64(1),
+			"template": map[string]interface{}{
+				"spec": map[string]interface{}{
+					"serviceAccountName": "default",
+				},
+			},
+		},
+		"status": map[string]interface{}{
+			"replicas": int64(1),
+		},
+	}}
+
+	tests := map[string]struct {
+		mutate func(t *testing.T, obj *unstructured.Unstructured)
+		want   *downstreamMutations
+	}{
+		"unmutated": {
+			mutate: func(t *testing.T, obj *unstructured.Unstructured) {},
+		},
+		"mutated": {
+			mutate: func(t *testing.T, obj *unstructured.Unstructured) {
+				obj.SetNamespace("kcp-01234567")
+				obj.SetLabels(map[string]string{"app": "test", "internal.workloads.kcp.dev/cluster": "us-west1"})
+				obj.SetAnnotations(nil)
+				require.NoError(t, unstructured.SetNestedField(obj.Object, "kcp-default", "spec", "template", "spec", "serviceAccountName"))
+			},
+			want: &downstreamMutations{
+				Added:   []string{"/metadata/labels/internal.workloads.kcp.dev~1cluster"},
+				Removed: []string{"/metadata/annotations"},
+				Changed: []string{"/metadata/namespace", "/spec/template/spec/serviceAccountName"},
+			},
+		},
+		"truncated": {
+			mutate: func(t *testing.T, obj *unstructured.Unstructured) {
+				labels := obj.GetLabels()
+				for i := 0; i < maxMutationPaths+5; i++ {
+					labels[fmt.Sprintf("label-%02d", i)] = "value"
+				}
+				obj.SetLabels(labels)
+			},
+			want: func() *downstreamMutations {
+				m := &downstreamMutations{Truncated: 5}
+				for i := 0; i < maxMutationPaths; i++ {
+					m.Added = append(m.Added, fmt.Sprintf("/metadata/labels/label-%02d", i))
+				}
+				return m
+			}(),
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			c := &Controller{workloadClusterName: "us-west1"}
+
+			// the downstream object as built by the syncer
+			downstream := upstream.DeepCopy()
+			downstream.SetUID("")
+			downstream.SetResourceVersion("")
+			downstream.SetClusterName("")
+			labels := downstream.GetLabels()
+			delete(labels, "state.internal.workloads.kcp.dev/us-west1")
+			downstream.SetLabels(labels)
+			annotations := downstream.GetAnnotations()
+			delete(annotations, "experimental.sync-condition.workloads.kcp.dev/us-west1")
+			delete(annotations, "experimental.mutations.workloads.kcp.dev/us-west1")
+			downstream.SetAnnotations(annotations)
+			unstructured.RemoveNestedField(downstream.Object, "status")
+			tt.mutate(t, downstream)
+
+			require.Equal(t, tt.want, c.diffDownstream(upstream, downstream))
+		})
+	}
+}
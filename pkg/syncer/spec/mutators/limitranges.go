@@ -0,0 +1,177 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mutators
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/cache"
+)
+
+// LimitRangeMutator sets the container defaults of the LimitRanges of the upstream namespace
+// on pods and deployments, i.e. the same defaults the LimitRanger admission plugin of the
+// physical cluster applies to pods, such that workloads always land with resource requests.
+type LimitRangeMutator struct {
+	upstreamLimitRangeLister cache.GenericLister
+}
+
+func (lm *LimitRangeMutator) GVRs() []schema.GroupVersionResource {
+	return []schema.GroupVersionResource{
+		{
+			Group:    "",
+			Version:  "v1",
+			Resource: "pods",
+		},
+		{
+			Group:    "apps",
+			Version:  "v1",
+			Resource: "deployments",
+		},
+	}
+}
+
+func NewLimitRangeMutator(upstreamLimitRangeLister cache.GenericLister) *LimitRangeMutator {
+	return &LimitRangeMutator{
+		upstreamLimitRangeLister: upstreamLimitRangeLister,
+	}
+}
+
+// Mutate applies the mutator changes to the object, using the LimitRanges of the given upstream namespace.
+func (lm *LimitRangeMutator) Mutate(upstreamNamespace string, downstreamObj *unstructured.Unstructured) error {
+	var podSpecPath []string
+	switch downstreamObj.GetKind() {
+	case "Pod":
+		podSpecPath = []string{"spec"}
+	case "Deployment":
+		podSpecPath = []string{"spec", "template", "spec"}
+	default:
+		return nil
+	}
+
+	objs, err := lm.upstreamLimitRangeLister.ByNamespace(upstreamNamespace).List(labels.Everything())
+	if err != nil {
+		return err
+	}
+	if len(objs) == 0 {
+		return nil
+	}
+	limitRanges := make([]corev1.LimitRange, len(objs))
+	for i, obj := range objs {
+		unstr, ok := obj.(*unstructured.Unstructured)
+		if !ok {
+			return fmt.Errorf("expected unstructured LimitRange, got %T", obj)
+		}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(unstr.UnstructuredContent(), &limitRanges[i]); err != nil {
+			return err
+		}
+	}
+
+	podSpecContent, found, err := unstructured.NestedMap(downstreamObj.UnstructuredContent(), podSpecPath...)
+	if err != nil || !found {
+		return err
+	}
+	var podSpec corev1.PodSpec
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(podSpecContent, &podSpec); err != nil {
+		return err
+	}
+
+	changed := false
+	for _, limitRange := range limitRanges {
+		for _, item := range limitRange.Spec.Limits {
+			if item.Type != corev1.LimitTypeContainer {
+				continue
+			}
+			limits, requests := containerDefaults(item)
+			for i := range podSpec.InitContainers {
+				changed = setDefaultResources(&podSpec.InitContainers[i].Resources, limits, requests) || changed
+			}
+			for i := range podSpec.Containers {
+				changed = setDefaultResources(&podSpec.Containers[i].Resources, limits, requests) || changed
+			}
+		}
+	}
+	if !changed {
+		return nil
+	}
+
+	podSpecContent, err = runtime.DefaultUnstructuredConverter.ToUnstructured(&podSpec)
+	if err != nil {
+		return err
+	}
+
+	// Set the changes back into the obj.
+	return unstructured.SetNestedMap(downstreamObj.UnstructuredContent(), podSpecContent, podSpecPath...)
+}
+
+// containerDefaults returns the default limits and requests of a container LimitRange item. Like
+// in the defaulting of LimitRanges, the default limit falls back to the max, and the default request
+// to the default limit.
+func containerDefaults(item corev1.LimitRangeItem) (limits, requests corev1.ResourceList) {
+	limits = corev1.ResourceList{}
+	for name, quantity := range item.Max {
+		limits[name] = quantity
+	}
+	for name, quantity := range item.Default {
+		limits[name] = quantity
+	}
+
+	requests = corev1.ResourceList{}
+	for name, quantity := range limits {
+		requests[name] = quantity
+	}
+	for name, quantity := range item.DefaultRequest {
+		requests[name] = quantity
+	}
+
+	return limits, requests
+}
+
+// setDefaultResources sets the given limits and requests for resources the container does not specify,
+// and returns whether anything was changed.
+func setDefaultResources(resources *corev1.ResourceRequirements, limits, requests corev1.ResourceList) bool {
+	changed := false
+	for name, quantity := range limits {
+		if _, found := resources.Limits[name]; found {
+			continue
+		}
+		if resources.Limits == nil {
+			resources.Limits = corev1.ResourceList{}
+		}
+		resources.Limits[name] = quantity.DeepCopy()
+		changed = true
+	}
+	for name, quantity := range requests {
+		if _, found := resources.Requests[name]; found {
+			continue
+		}
+		if limit, found := resources.Limits[name]; found && limit.Cmp(quantity) < 0 {
+			// never request more than the limit of the container
+			quantity = limit
+		}
+		if resources.Requests == nil {
+			resources.Requests = corev1.ResourceList{}
+		}
+		resources.Requests[name] = quantity.DeepCopy()
+		changed = true
+	}
+	return changed
+}
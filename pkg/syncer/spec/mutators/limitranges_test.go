@@ -0,0 +1,171 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mutators
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/cache"
+)
+
+func newLimitRangeLister(t *testing.T, limitRanges ...*corev1.LimitRange) cache.GenericLister {
+	t.Helper()
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc})
+	for _, limitRange := range limitRanges {
+		obj, err := toUnstructured(limitRange)
+		require.NoError(t, err)
+		require.NoError(t, indexer.Add(obj))
+	}
+	return cache.NewGenericLister(indexer, corev1.Resource("limitranges"))
+}
+
+func TestLimitRangeMutate(t *testing.T) {
+	containerLimits := &corev1.LimitRange{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "LimitRange"},
+		ObjectMeta: metav1.ObjectMeta{Name: "limits", Namespace: "upstream-ns"},
+		Spec: corev1.LimitRangeSpec{
+			Limits: []corev1.LimitRangeItem{
+				{
+					Type: corev1.LimitTypeContainer,
+					Default: corev1.ResourceList{
+						corev1.ResourceCPU:    resource.MustParse("500m"),
+						corev1.ResourceMemory: resource.MustParse("512Mi"),
+					},
+					DefaultRequest: corev1.ResourceList{
+						corev1.ResourceCPU: resource.MustParse("100m"),
+					},
+				},
+				{
+					Type: corev1.LimitTypePod,
+					Max: corev1.ResourceList{
+						corev1.ResourceCPU: resource.MustParse("4"),
+					},
+				},
+			},
+		},
+	}
+
+	tests := map[string]struct {
+		limitRanges        []*corev1.LimitRange
+		upstreamNamespace  string
+		containers         []corev1.Container
+		expectedContainers []corev1.Container
+	}{
+		"no limit ranges": {
+			upstreamNamespace:  "upstream-ns",
+			containers:         []corev1.Container{{Name: "app"}},
+			expectedContainers: []corev1.Container{{Name: "app"}},
+		},
+		"limit ranges of other namespaces are ignored": {
+			limitRanges:        []*corev1.LimitRange{containerLimits},
+			upstreamNamespace:  "other-ns",
+			containers:         []corev1.Container{{Name: "app"}},
+			expectedContainers: []corev1.Container{{Name: "app"}},
+		},
+		"defaults are set": {
+			limitRanges:       []*corev1.LimitRange{containerLimits},
+			upstreamNamespace: "upstream-ns",
+			containers:        []corev1.Container{{Name: "app"}},
+			expectedContainers: []corev1.Container{{
+				Name: "app",
+				Resources: corev1.ResourceRequirements{
+					Limits: corev1.ResourceList{
+						corev1.ResourceCPU:    resource.MustParse("500m"),
+						corev1.ResourceMemory: resource.MustParse("512Mi"),
+					},
+					Requests: corev1.ResourceList{
+						corev1.ResourceCPU:    resource.MustParse("100m"),
+						corev1.ResourceMemory: resource.MustParse("512Mi"),
+					},
+				},
+			}},
+		},
+		"specified resources are kept, requests do not exceed limits": {
+			limitRanges:       []*corev1.LimitRange{containerLimits},
+			upstreamNamespace: "upstream-ns",
+			containers: []corev1.Container{{
+				Name: "app",
+				Resources: corev1.ResourceRequirements{
+					Limits: corev1.ResourceList{
+						corev1.ResourceMemory: resource.MustParse("128Mi"),
+					},
+					Requests: corev1.ResourceList{
+						corev1.ResourceCPU: resource.MustParse("200m"),
+					},
+				},
+			}},
+			expectedContainers: []corev1.Container{{
+				Name: "app",
+				Resources: corev1.ResourceRequirements{
+					Limits: corev1.ResourceList{
+						corev1.ResourceCPU:    resource.MustParse("500m"),
+						corev1.ResourceMemory: resource.MustParse("128Mi"),
+					},
+					Requests: corev1.ResourceList{
+						corev1.ResourceCPU:    resource.MustParse("200m"),
+						corev1.ResourceMemory: resource.MustParse("128Mi"),
+					},
+				},
+			}},
+		},
+	}
+
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			lm := NewLimitRangeMutator(newLimitRangeLister(t, tc.limitRanges...))
+
+			pod := &corev1.Pod{
+				TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"},
+				ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: "downstream-ns"},
+				Spec:       corev1.PodSpec{Containers: tc.containers},
+			}
+			unstrPod, err := toUnstructured(pod)
+			require.NoError(t, err)
+			require.NoError(t, lm.Mutate(tc.upstreamNamespace, unstrPod))
+			var mutatedPod corev1.Pod
+			require.NoError(t, runtime.DefaultUnstructuredConverter.FromUnstructured(unstrPod.Object, &mutatedPod))
+			require.Equal(t, tc.expectedContainers, mutatedPod.Spec.Containers)
+
+			deployment := &appsv1.Deployment{
+				TypeMeta:   metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
+				ObjectMeta: metav1.ObjectMeta{Name: "deployment", Namespace: "downstream-ns"},
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							InitContainers: tc.containers,
+							Containers:     tc.containers,
+						},
+					},
+				},
+			}
+			unstrDeployment, err := toUnstructured(deployment)
+			require.NoError(t, err)
+			require.NoError(t, lm.Mutate(tc.upstreamNamespace, unstrDeployment))
+			var mutatedDeployment appsv1.Deployment
+			require.NoError(t, runtime.DefaultUnstructuredConverter.FromUnstructured(unstrDeployment.Object, &mutatedDeployment))
+			require.Equal(t, tc.expectedContainers, mutatedDeployment.Spec.Template.Spec.Containers)
+			require.Equal(t, tc.expectedContainers, mutatedDeployment.Spec.Template.Spec.InitContainers)
+		})
+	}
+}
@@ -0,0 +1,274 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mutators
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+
+	"github.com/kcp-dev/logicalcluster"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/tools/cache"
+
+	workloadv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/workload/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/syncer/shared"
+)
+
+// DefaultClusterDomain is the default DNS domain of the services of a physical cluster.
+const DefaultClusterDomain = "cluster.local"
+
+// ServiceDNSPolicy defines whether synced workloads get host aliases for the services of their workspace.
+type ServiceDNSPolicy struct {
+	// Enabled turns on the host aliases.
+	Enabled bool
+	// ClusterDomain is the DNS domain of the services of the physical cluster, e.g. cluster.local.
+	ClusterDomain string
+}
+
+// ServiceDNSMutator makes the services of the upstream namespaces of the workspace resolvable by their
+// upstream names from the pods of deployments, also when the namespaces are placed on other physical clusters.
+// Downstream, namespaces have different names, and services of namespaces placed elsewhere do not exist
+// at all. The mutator adds host aliases to the pod template of deployments for <service>.<namespace>, <service>.<namespace>.svc and
+// <service>.<namespace>.svc.<cluster domain> of every service of the workspace, and for the short name
+// of the services of the own namespace placed elsewhere. Services synced to this physical cluster resolve
+// to their downstream cluster IP. Services placed on other physical clusters resolve to the first load
+// balancer IP in their upstream status, as synced up by the syncer of the other cluster. Services without
+// such IP are not resolvable. Pods are not mutated, because host aliases of running pods cannot be changed.
+type ServiceDNSMutator struct {
+	upstreamServiceInformer   cache.SharedIndexInformer
+	downstreamServiceInformer cache.SharedIndexInformer
+
+	upstreamClusterName logicalcluster.Name
+	workloadClusterName string
+	namespaceNamer      shared.NamespaceNamer
+	clusterDomain       string
+}
+
+func (sm *ServiceDNSMutator) GVRs() []schema.GroupVersionResource {
+	return []schema.GroupVersionResource{
+		{
+			Group:    "apps",
+			Version:  "v1",
+			Resource: "deployments",
+		},
+	}
+}
+
+// NewServiceDNSMutator returns a mutator for the services known to the given informers. The upstream informer
+// must see all services of the workspace, not only those synced to this physical cluster.
+func NewServiceDNSMutator(upstreamServiceInformer, downstreamServiceInformer cache.SharedIndexInformer, upstreamClusterName logicalcluster.Name, workloadClusterName string,
+	namespaceNamer shared.NamespaceNamer, clusterDomain string) *ServiceDNSMutator {
+	return &ServiceDNSMutator{
+		upstreamServiceInformer:   upstreamServiceInformer,
+		downstreamServiceInformer: downstreamServiceInformer,
+		upstreamClusterName:       upstreamClusterName,
+		workloadClusterName:       workloadClusterName,
+		namespaceNamer:            namespaceNamer,
+		clusterDomain:             clusterDomain,
+	}
+}
+
+// AddChangeHandler calls the given handler whenever the host aliases change because a service is added,
+// deleted or gets another IP upstream or downstream. Other changes of services are ignored.
+func (sm *ServiceDNSMutator) AddChangeHandler(handler func()) {
+	handlers := sm.changeHandlers(handler)
+	sm.upstreamServiceInformer.AddEventHandler(handlers)
+	sm.downstreamServiceInformer.AddEventHandler(handlers)
+}
+
+// changeHandlers returns the service event handlers calling the given handler when the host aliases change.
+func (sm *ServiceDNSMutator) changeHandlers(handler func()) cache.ResourceEventHandlerFuncs {
+	var lock sync.Mutex
+	var last []serviceIP
+	changed := func() {
+		lock.Lock()
+		defer lock.Unlock()
+
+		current, err := sm.serviceIPs()
+		if err != nil {
+			utilruntime.HandleError(err)
+			return
+		}
+		if reflect.DeepEqual(last, current) {
+			return
+		}
+		last = current
+		handler()
+	}
+	return cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) { changed() },
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			oldUnstr, oldOK := oldObj.(*unstructured.Unstructured)
+			newUnstr, newOK := newObj.(*unstructured.Unstructured)
+			if oldOK && newOK && oldUnstr.GetResourceVersion() == newUnstr.GetResourceVersion() {
+				return // resync
+			}
+			changed()
+		},
+		DeleteFunc: func(obj interface{}) { changed() },
+	}
+}
+
+// Mutate adds the host aliases of the services of the workspace to the object, which lives in the given
+// upstream namespace.
+func (sm *ServiceDNSMutator) Mutate(upstreamNamespace string, downstreamObj *unstructured.Unstructured) error {
+	if downstreamObj.GetKind() != "Deployment" {
+		return nil
+	}
+	podSpecPath := []string{"spec", "template", "spec"}
+
+	hostAliases, err := sm.hostAliases(upstreamNamespace)
+	if err != nil || len(hostAliases) == 0 {
+		return err
+	}
+
+	podSpecContent, found, err := unstructured.NestedMap(downstreamObj.UnstructuredContent(), podSpecPath...)
+	if err != nil || !found {
+		return err
+	}
+	var podSpec corev1.PodSpec
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(podSpecContent, &podSpec); err != nil {
+		return err
+	}
+
+	podSpec.HostAliases = append(podSpec.HostAliases, hostAliases...)
+
+	podSpecContent, err = runtime.DefaultUnstructuredConverter.ToUnstructured(&podSpec)
+	if err != nil {
+		return err
+	}
+
+	// Set the changes back into the obj.
+	return unstructured.SetNestedMap(downstreamObj.UnstructuredContent(), podSpecContent, podSpecPath...)
+}
+
+// serviceIP is the IP of an upstream service of the workspace.
+type serviceIP struct {
+	namespace, name string
+	ip              string
+	syncedHere      bool
+}
+
+// serviceIPs returns the upstream services of the workspace reachable by IP from this physical cluster, sorted
+// by namespace and name.
+func (sm *ServiceDNSMutator) serviceIPs() ([]serviceIP, error) {
+	var ret []serviceIP
+	for _, obj := range sm.upstreamServiceInformer.GetIndexer().List() {
+		unstr, ok := obj.(*unstructured.Unstructured)
+		if !ok {
+			return nil, fmt.Errorf("expected unstructured Service, got %T", obj)
+		}
+		if logicalcluster.From(unstr) != sm.upstreamClusterName {
+			continue
+		}
+		var service corev1.Service
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(unstr.UnstructuredContent(), &service); err != nil {
+			return nil, err
+		}
+
+		syncedHere := service.Labels[workloadv1alpha1.InternalClusterResourceStateLabelPrefix+sm.workloadClusterName] == string(workloadv1alpha1.ResourceStateSync)
+		ip, err := sm.serviceIP(&service, syncedHere)
+		if err != nil {
+			return nil, err
+		}
+		if ip == "" {
+			continue
+		}
+		ret = append(ret, serviceIP{namespace: service.Namespace, name: service.Name, ip: ip, syncedHere: syncedHere})
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		if ret[i].namespace != ret[j].namespace {
+			return ret[i].namespace < ret[j].namespace
+		}
+		return ret[i].name < ret[j].name
+	})
+	return ret, nil
+}
+
+// hostAliases returns the host aliases of the services of the workspace, as seen from the given upstream
+// namespace, sorted by IP.
+func (sm *ServiceDNSMutator) hostAliases(upstreamNamespace string) ([]corev1.HostAlias, error) {
+	services, err := sm.serviceIPs()
+	if err != nil {
+		return nil, err
+	}
+	hostnamesByIP := map[string][]string{}
+	for _, service := range services {
+		var hostnames []string
+		if service.namespace == upstreamNamespace && !service.syncedHere {
+			// the short names of services in the own namespace only resolve natively if they are synced here
+			hostnames = append(hostnames, service.name)
+		}
+		qualified := service.name + "." + service.namespace
+		hostnames = append(hostnames, qualified, qualified+".svc", qualified+".svc."+sm.clusterDomain)
+		hostnamesByIP[service.ip] = append(hostnamesByIP[service.ip], hostnames...)
+	}
+
+	hostAliases := make([]corev1.HostAlias, 0, len(hostnamesByIP))
+	for ip, hostnames := range hostnamesByIP {
+		sort.Strings(hostnames)
+		hostAliases = append(hostAliases, corev1.HostAlias{IP: ip, Hostnames: hostnames})
+	}
+	sort.Slice(hostAliases, func(i, j int) bool {
+		return hostAliases[i].IP < hostAliases[j].IP
+	})
+	return hostAliases, nil
+}
+
+// serviceIP returns the IP the given upstream service is reachable at from this physical cluster, or the empty
+// string if it is not reachable by IP.
+func (sm *ServiceDNSMutator) serviceIP(service *corev1.Service, syncedHere bool) (string, error) {
+	if !syncedHere {
+		for _, ingress := range service.Status.LoadBalancer.Ingress {
+			if ingress.IP != "" {
+				return ingress.IP, nil
+			}
+		}
+		return "", nil
+	}
+
+	downstreamNamespace, err := sm.namespaceNamer.Name(shared.NamespaceLocator{
+		LogicalCluster: sm.upstreamClusterName,
+		Namespace:      service.Namespace,
+	})
+	if err != nil {
+		return "", err
+	}
+	obj, exists, err := sm.downstreamServiceInformer.GetIndexer().GetByKey(downstreamNamespace + "/" + service.Name)
+	if err != nil {
+		return "", err
+	}
+	if !exists {
+		return "", nil // not synced yet
+	}
+	unstr, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return "", fmt.Errorf("expected unstructured Service, got %T", obj)
+	}
+	clusterIP, _, err := unstructured.NestedString(unstr.UnstructuredContent(), "spec", "clusterIP")
+	if err != nil || clusterIP == corev1.ClusterIPNone {
+		return "", err
+	}
+	return clusterIP, nil
+}
@@ -0,0 +1,204 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mutators
+
+import (
+	"testing"
+
+	"github.com/kcp-dev/logicalcluster"
+	"github.com/stretchr/testify/require"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/cache"
+
+	workloadv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/workload/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/syncer/shared"
+)
+
+func newServiceInformer(t *testing.T, services ...*corev1.Service) cache.SharedIndexInformer {
+	t.Helper()
+	informer := cache.NewSharedIndexInformer(&cache.ListWatch{}, &unstructured.Unstructured{}, 0, cache.Indexers{})
+	for _, service := range services {
+		obj, err := toUnstructured(service)
+		require.NoError(t, err)
+		require.NoError(t, informer.GetIndexer().Add(obj))
+	}
+	return informer
+}
+
+func TestServiceDNSMutate(t *testing.T) {
+	upstreamService := func(clusterName, namespace, name, syncTarget, lbIP string) *corev1.Service {
+		service := &corev1.Service{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Service"},
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, ClusterName: clusterName},
+		}
+		if syncTarget != "" {
+			service.Labels = map[string]string{
+				workloadv1alpha1.InternalClusterResourceStateLabelPrefix + syncTarget: string(workloadv1alpha1.ResourceStateSync),
+			}
+		}
+		if lbIP != "" {
+			service.Status.LoadBalancer.Ingress = []corev1.LoadBalancerIngress{{IP: lbIP}}
+		}
+		return service
+	}
+	downstreamService := func(namespace, name, clusterIP string) *corev1.Service {
+		return &corev1.Service{
+			TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Service"},
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Spec:       corev1.ServiceSpec{ClusterIP: clusterIP},
+		}
+	}
+
+	tests := map[string]struct {
+		upstreamServices    []*corev1.Service
+		downstreamServices  []*corev1.Service
+		upstreamNamespace   string
+		expectedHostAliases []corev1.HostAlias
+	}{
+		"no services": {
+			upstreamNamespace: "ns",
+		},
+		"service synced here resolves to its downstream cluster IP": {
+			upstreamServices:   []*corev1.Service{upstreamService("root:org:ws", "ns", "svc", "us-east1", "")},
+			downstreamServices: []*corev1.Service{downstreamService("root-org-ws-ns", "svc", "10.0.0.1")},
+			upstreamNamespace:  "ns",
+			expectedHostAliases: []corev1.HostAlias{
+				{IP: "10.0.0.1", Hostnames: []string{"svc.ns", "svc.ns.svc", "svc.ns.svc.cluster.local"}},
+			},
+		},
+		"service synced here but not yet downstream is skipped": {
+			upstreamServices:  []*corev1.Service{upstreamService("root:org:ws", "ns", "svc", "us-east1", "")},
+			upstreamNamespace: "ns",
+		},
+		"headless service is skipped": {
+			upstreamServices:   []*corev1.Service{upstreamService("root:org:ws", "ns", "svc", "us-east1", "")},
+			downstreamServices: []*corev1.Service{downstreamService("root-org-ws-ns", "svc", corev1.ClusterIPNone)},
+			upstreamNamespace:  "ns",
+		},
+		"service placed elsewhere resolves to its load balancer IP, also by short name in the own namespace": {
+			upstreamServices: []*corev1.Service{
+				upstreamService("root:org:ws", "ns", "svc", "us-west1", "1.2.3.4"),
+				upstreamService("root:org:ws", "other", "db", "us-west1", "1.2.3.5"),
+			},
+			upstreamNamespace: "ns",
+			expectedHostAliases: []corev1.HostAlias{
+				{IP: "1.2.3.4", Hostnames: []string{"svc", "svc.ns", "svc.ns.svc", "svc.ns.svc.cluster.local"}},
+				{IP: "1.2.3.5", Hostnames: []string{"db.other", "db.other.svc", "db.other.svc.cluster.local"}},
+			},
+		},
+		"service placed elsewhere without load balancer IP is skipped": {
+			upstreamServices:  []*corev1.Service{upstreamService("root:org:ws", "ns", "svc", "us-west1", "")},
+			upstreamNamespace: "ns",
+		},
+		"services of other workspaces are ignored": {
+			upstreamServices:  []*corev1.Service{upstreamService("root:org:other", "ns", "svc", "us-west1", "1.2.3.4")},
+			upstreamNamespace: "ns",
+		},
+	}
+
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			sm := NewServiceDNSMutator(
+				newServiceInformer(t, tc.upstreamServices...),
+				newServiceInformer(t, tc.downstreamServices...),
+				logicalcluster.New("root:org:ws"),
+				"us-east1",
+				shared.NamespaceNamer{Strategy: shared.NamespaceNamingReadable},
+				DefaultClusterDomain,
+			)
+
+			pod := &corev1.Pod{
+				TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"},
+				ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: "root-org-ws-ns"},
+				Spec:       corev1.PodSpec{Containers: []corev1.Container{{Name: "app"}}},
+			}
+			unstrPod, err := toUnstructured(pod)
+			require.NoError(t, err)
+			require.NoError(t, sm.Mutate(tc.upstreamNamespace, unstrPod))
+			var mutatedPod corev1.Pod
+			require.NoError(t, runtime.DefaultUnstructuredConverter.FromUnstructured(unstrPod.Object, &mutatedPod))
+			require.Empty(t, mutatedPod.Spec.HostAliases, "pods must not be mutated")
+
+			deployment := &appsv1.Deployment{
+				TypeMeta:   metav1.TypeMeta{APIVersion: "apps/v1", Kind: "Deployment"},
+				ObjectMeta: metav1.ObjectMeta{Name: "deployment", Namespace: "root-org-ws-ns"},
+				Spec: appsv1.DeploymentSpec{
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "app"}}},
+					},
+				},
+			}
+			unstrDeployment, err := toUnstructured(deployment)
+			require.NoError(t, err)
+			require.NoError(t, sm.Mutate(tc.upstreamNamespace, unstrDeployment))
+			var mutatedDeployment appsv1.Deployment
+			require.NoError(t, runtime.DefaultUnstructuredConverter.FromUnstructured(unstrDeployment.Object, &mutatedDeployment))
+			require.Equal(t, tc.expectedHostAliases, mutatedDeployment.Spec.Template.Spec.HostAliases)
+		})
+	}
+}
+
+func TestServiceDNSChangeHandler(t *testing.T) {
+	service := &corev1.Service{
+		TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Service"},
+		ObjectMeta: metav1.ObjectMeta{Name: "svc", Namespace: "ns", ClusterName: "root:org:ws", ResourceVersion: "1"},
+		Status: corev1.ServiceStatus{LoadBalancer: corev1.LoadBalancerStatus{
+			Ingress: []corev1.LoadBalancerIngress{{IP: "1.2.3.4"}},
+		}},
+	}
+	upstreamInformer := newServiceInformer(t)
+	sm := NewServiceDNSMutator(upstreamInformer, newServiceInformer(t), logicalcluster.New("root:org:ws"), "us-east1",
+		shared.NamespaceNamer{Strategy: shared.NamespaceNamingReadable}, DefaultClusterDomain)
+	calls := 0
+	handlers := sm.changeHandlers(func() { calls++ })
+
+	update := func(mutate func(service *corev1.Service)) {
+		t.Helper()
+		old, err := toUnstructured(service)
+		require.NoError(t, err)
+		service = service.DeepCopy()
+		mutate(service)
+		obj, err := toUnstructured(service)
+		require.NoError(t, err)
+		require.NoError(t, upstreamInformer.GetIndexer().Update(obj))
+		handlers.OnUpdate(old, obj)
+	}
+
+	obj, err := toUnstructured(service)
+	require.NoError(t, err)
+	require.NoError(t, upstreamInformer.GetIndexer().Add(obj))
+	handlers.OnAdd(obj)
+	require.Equal(t, 1, calls, "a new service changes the host aliases")
+
+	update(func(service *corev1.Service) {
+		service.ResourceVersion = "2"
+		service.Annotations = map[string]string{"foo": "bar"}
+	})
+	require.Equal(t, 1, calls, "other changes must not resync deployments")
+
+	update(func(service *corev1.Service) {
+		service.ResourceVersion = "3"
+		service.Status.LoadBalancer.Ingress[0].IP = "1.2.3.5"
+	})
+	require.Equal(t, 2, calls, "a new IP changes the host aliases")
+}
@@ -22,9 +22,14 @@ import (
 	"net/url"
 	"time"
 
+	"github.com/go-logr/logr"
 	"github.com/kcp-dev/logicalcluster"
+	"go.opentelemetry.io/otel/attribute"
 
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/apimachinery/pkg/util/wait"
@@ -32,19 +37,33 @@ import (
 	"k8s.io/client-go/dynamic/dynamicinformer"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/util/workqueue"
-	"k8s.io/klog/v2"
 
+	"github.com/kcp-dev/kcp/pkg/logging"
+	"github.com/kcp-dev/kcp/pkg/syncer/drift"
+	"github.com/kcp-dev/kcp/pkg/syncer/shared"
 	specmutators "github.com/kcp-dev/kcp/pkg/syncer/spec/mutators"
+	"github.com/kcp-dev/kcp/pkg/tracing"
 )
 
 const (
 	controllerName = "kcp-workload-syncer-spec"
 )
 
+var (
+	limitRangesGVR = corev1.SchemeGroupVersion.WithResource("limitranges")
+	namespacesGVR  = corev1.SchemeGroupVersion.WithResource("namespaces")
+)
+
 type Controller struct {
 	queue workqueue.RateLimitingInterface
 
+	logger logr.Logger
+
 	mutators mutatorGvrMap
+	// limitRangeMutator is nil if LimitRanges are not synced.
+	limitRangeMutator *specmutators.LimitRangeMutator
+	// serviceDNSMutator is nil if service DNS is disabled.
+	serviceDNSMutator *specmutators.ServiceDNSMutator
 
 	upstreamClient, downstreamClient       dynamic.Interface
 	upstreamInformers, downstreamInformers dynamicinformer.DynamicSharedInformerFactory
@@ -52,16 +71,42 @@ type Controller struct {
 	workloadClusterName       string
 	upstreamClusterName       logicalcluster.Name
 	advancedSchedulingEnabled bool
+	namespaceNamer            shared.NamespaceNamer
+	fieldPruningPolicy        FieldPruningPolicy
+	clusterScopedPolicy       shared.ClusterScopedPolicy
+	syncPause                 *shared.SyncPause
+	// recordMutations enables summarizing the mutations of downstream objects in an upstream annotation.
+	recordMutations bool
+	// driftStore records the downstream objects written, for drift detection. It is nil if drift detection is disabled.
+	driftStore *drift.Store
 }
 
-func NewSpecSyncer(gvrs []schema.GroupVersionResource, upstreamClusterName logicalcluster.Name, workloadClusterName string, upstreamURL *url.URL, advancedSchedulingEnabled bool,
-	upstreamClient, downstreamClient dynamic.Interface, upstreamInformers, downstreamInformers dynamicinformer.DynamicSharedInformerFactory) (*Controller, error) {
+// Options are the optional features of the spec syncer. The zero value syncs the objects as they are.
+type Options struct {
+	AdvancedSchedulingEnabled bool
+	NamespaceNamer            shared.NamespaceNamer
+	FieldPruningPolicy        FieldPruningPolicy
+	ClusterScopedPolicy       shared.ClusterScopedPolicy
+	// SyncPause pauses syncing of resources. Nil means never paused.
+	SyncPause *shared.SyncPause
+	// ServiceDNSMutator makes the services of the workspace resolvable by their upstream names. Nil disables it.
+	ServiceDNSMutator *specmutators.ServiceDNSMutator
+	// RecordMutations enables summarizing the mutations of downstream objects in an upstream annotation.
+	RecordMutations bool
+	// DriftStore records the downstream objects written, for drift detection. Nil disables drift detection.
+	DriftStore *drift.Store
+}
+
+func NewSpecSyncer(gvrs []schema.GroupVersionResource, upstreamClusterName logicalcluster.Name, workloadClusterName string, upstreamURL *url.URL,
+	upstreamClient, downstreamClient dynamic.Interface, upstreamInformers, downstreamInformers dynamicinformer.DynamicSharedInformerFactory, options Options) (*Controller, error) {
 	deploymentMutator := specmutators.NewDeploymentMutator(upstreamURL)
 	secretMutator := specmutators.NewSecretMutator()
 
 	c := Controller{
 		queue: workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), controllerName),
 
+		logger: logging.WithSyncTarget(logging.WithWorkspace(logging.NewLogger(controllerName), upstreamClusterName), workloadClusterName),
+
 		mutators: mutatorGvrMap{
 			deploymentMutator.GVR(): deploymentMutator.Mutate,
 			secretMutator.GVR():     secretMutator.Mutate,
@@ -74,7 +119,34 @@ func NewSpecSyncer(gvrs []schema.GroupVersionResource, upstreamClusterName logic
 
 		workloadClusterName:       workloadClusterName,
 		upstreamClusterName:       upstreamClusterName,
-		advancedSchedulingEnabled: advancedSchedulingEnabled,
+		advancedSchedulingEnabled: options.AdvancedSchedulingEnabled,
+		namespaceNamer:            options.NamespaceNamer,
+		fieldPruningPolicy:        options.FieldPruningPolicy,
+		clusterScopedPolicy:       options.ClusterScopedPolicy,
+		syncPause:                 options.SyncPause,
+		serviceDNSMutator:         options.ServiceDNSMutator,
+		recordMutations:           options.RecordMutations,
+		driftStore:                options.DriftStore,
+	}
+
+	for _, gvr := range gvrs {
+		if gvr == limitRangesGVR {
+			c.limitRangeMutator = specmutators.NewLimitRangeMutator(upstreamInformers.ForResource(gvr).Lister())
+			break
+		}
+	}
+
+	// Sync pods and deployments again when the host aliases of the services of the workspace change.
+	if c.serviceDNSMutator != nil {
+		c.serviceDNSMutator.AddChangeHandler(func() {
+			for _, gvr := range gvrs {
+				for _, mutatedGVR := range c.serviceDNSMutator.GVRs() {
+					if gvr == mutatedGVR {
+						c.addAllToQueue(gvr, metav1.NamespaceAll)
+					}
+				}
+			}
+		})
 	}
 
 	for _, gvr := range gvrs {
@@ -96,9 +168,33 @@ func NewSpecSyncer(gvrs []schema.GroupVersionResource, upstreamClusterName logic
 				c.AddToQueue(gvr, obj)
 			},
 		})
-		klog.InfoS("Set up informer", "clusterName", upstreamClusterName, "pcluster", workloadClusterName, "gvr", gvr.String())
+		c.logger.Info("Set up informer", "gvr", gvr.String())
 	}
 
+	// Sync the objects again when pausing is lifted.
+	downstreamInformers.ForResource(namespacesGVR).Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			oldNamespace, newNamespace := oldObj.(*unstructured.Unstructured), newObj.(*unstructured.Unstructured)
+			if !shared.NamespacePauseMode(oldNamespace.GetAnnotations()).PausesSpec() || shared.NamespacePauseMode(newNamespace.GetAnnotations()).PausesSpec() {
+				return
+			}
+			locator, err := shared.LocatorFromAnnotations(newNamespace.GetAnnotations())
+			if err != nil || locator == nil || locator.LogicalCluster != upstreamClusterName {
+				return
+			}
+			for _, gvr := range gvrs {
+				c.addAllToQueue(gvr, locator.Namespace)
+			}
+		},
+	})
+	c.syncPause.AddResumeHandler(func(gr schema.GroupResource) {
+		for _, gvr := range gvrs {
+			if gvr.GroupResource() == gr {
+				c.addAllToQueue(gvr, metav1.NamespaceAll)
+			}
+		}
+	})
+
 	return &c, nil
 }
 
@@ -114,7 +210,7 @@ func (c *Controller) AddToQueue(gvr schema.GroupVersionResource, obj interface{}
 		return
 	}
 
-	klog.Infof("%s queueing GVR %q %s", controllerName, gvr.String(), key)
+	logging.WithQueueKey(c.logger, key).Info("Queueing", "gvr", gvr.String())
 	c.queue.Add(
 		queueKey{
 			gvr: gvr,
@@ -123,13 +219,28 @@ func (c *Controller) AddToQueue(gvr schema.GroupVersionResource, obj interface{}
 	)
 }
 
+// addAllToQueue queues the upstream objects of the given resource in the given upstream namespace, or in all
+// namespaces, of the upstream logical cluster of the syncer.
+func (c *Controller) addAllToQueue(gvr schema.GroupVersionResource, namespace string) {
+	objs, err := c.upstreamInformers.ForResource(gvr).Lister().ByNamespace(namespace).List(labels.Everything())
+	if err != nil {
+		runtime.HandleError(err)
+		return
+	}
+	for _, obj := range objs {
+		if o, ok := obj.(metav1.Object); ok && logicalcluster.From(o) == c.upstreamClusterName {
+			c.AddToQueue(gvr, obj)
+		}
+	}
+}
+
 // Start starts N worker processes processing work items.
 func (c *Controller) Start(ctx context.Context, numThreads int) {
 	defer runtime.HandleCrash()
 	defer c.queue.ShutDown()
 
-	klog.InfoS("Starting syncer workers", "controller", controllerName)
-	defer klog.InfoS("Stopping syncer workers", "controller", controllerName)
+	c.logger.Info("Starting syncer workers")
+	defer c.logger.Info("Stopping syncer workers")
 	for i := 0; i < numThreads; i++ {
 		go wait.UntilWithContext(ctx, c.startWorker, time.Second)
 	}
@@ -155,7 +266,17 @@ func (c *Controller) processNextWorkItem(ctx context.Context) bool {
 	// other workers.
 	defer c.queue.Done(key)
 
-	if err := c.process(ctx, qk.gvr, qk.key); err != nil {
+	ctx = logging.NewContext(ctx, logging.WithQueueKey(c.logger, qk.key).WithValues("gvr", qk.gvr.String()))
+
+	ctx, span := tracing.StartSpan(ctx, controllerName, "Sync", c.upstreamClusterName,
+		attribute.String("gvr", qk.gvr.String()), attribute.String("key", qk.key))
+	defer span.End()
+
+	start := time.Now()
+	err := c.process(ctx, qk.gvr, qk.key)
+	shared.ObserveSync("spec", c.workloadClusterName, qk.gvr, start, err)
+	if err != nil {
+		span.RecordError(err)
 		runtime.HandleError(fmt.Errorf("%s failed to sync %q, err: %w", controllerName, key, err))
 		c.queue.AddRateLimited(key)
 		return true
@@ -39,6 +39,7 @@ import (
 	"k8s.io/utils/pointer"
 
 	workloadv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/workload/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/logging"
 	"github.com/kcp-dev/kcp/pkg/syncer/shared"
 )
 
@@ -53,22 +54,22 @@ func deepEqualApartFromStatus(oldUnstrob, newUnstrob *unstructured.Unstructured)
 	// remove status annotation from oldObj and newObj before comparing
 	oldAnnotations, _, err := unstructured.NestedStringMap(oldUnstrob.Object, "metadata", "annotations")
 	if err != nil {
-		klog.Errorf("failed to get annotations from object: %v", err)
+		klog.ErrorS(err, "Failed to get annotations from object")
 		return false
 	}
 	for k := range oldAnnotations {
-		if strings.HasPrefix(k, workloadv1alpha1.InternalClusterStatusAnnotationPrefix) {
+		if strings.HasPrefix(k, workloadv1alpha1.InternalClusterStatusAnnotationPrefix) || strings.HasPrefix(k, workloadv1alpha1.ExperimentalClusterMutationsAnnotationPrefix) {
 			delete(oldAnnotations, k)
 		}
 	}
 
 	newAnnotations, _, err := unstructured.NestedStringMap(newUnstrob.Object, "metadata", "annotations")
 	if err != nil {
-		klog.Errorf("failed to get annotations from object: %v", err)
+		klog.ErrorS(err, "Failed to get annotations from object")
 		return false
 	}
 	for k := range newAnnotations {
-		if strings.HasPrefix(k, workloadv1alpha1.InternalClusterStatusAnnotationPrefix) {
+		if strings.HasPrefix(k, workloadv1alpha1.InternalClusterStatusAnnotationPrefix) || strings.HasPrefix(k, workloadv1alpha1.ExperimentalClusterMutationsAnnotationPrefix) {
 			delete(newAnnotations, k)
 		}
 	}
@@ -103,26 +104,36 @@ func deepEqualApartFromStatus(oldUnstrob, newUnstrob *unstructured.Unstructured)
 }
 
 func (c *Controller) process(ctx context.Context, gvr schema.GroupVersionResource, key string) error {
-	klog.V(3).InfoS("Processing", "gvr", gvr, "key", key)
+	logger := logging.FromContext(ctx)
+	logger.V(3).Info("Processing")
 
 	// from upstream
 	upstreamNamespace, clusterAwareName, err := cache.SplitMetaNamespaceKey(key)
 	if err != nil {
-		klog.Errorf("Invalid key %q: %v", key, err)
+		logger.Error(err, "Invalid key")
 		return nil
 	}
 	clusterName, name := clusters.SplitClusterAwareKey(clusterAwareName)
 
+	if upstreamNamespace == "" && c.clusterScopedPolicy.Has(gvr) {
+		return c.processClusterScoped(ctx, gvr, key, clusterName, name)
+	}
+
 	// to downstream
-	downstreamNamespace, err := shared.PhysicalClusterNamespaceName(shared.NamespaceLocator{
+	downstreamNamespace, err := c.namespaceNamer.Name(shared.NamespaceLocator{
 		LogicalCluster: clusterName,
 		Namespace:      upstreamNamespace,
 	})
 	if err != nil {
-		klog.Errorf("Error hashing namespace %s|%s: %v", clusterName, upstreamNamespace, err)
+		logger.Error(err, "Error hashing namespace", "upstreamNamespace", upstreamNamespace)
 		return nil // ignore error, shouldn't happen
 	}
 
+	if mode := c.pauseMode(gvr, downstreamNamespace); mode.PausesSpec() {
+		logger.V(2).Info("Syncing is paused", "downstreamNamespace", downstreamNamespace, "pauseMode", mode)
+		return nil
+	}
+
 	// get the upstream object
 	obj, exists, err := c.upstreamInformers.ForResource(gvr).Informer().GetIndexer().GetByKey(key)
 	if err != nil {
@@ -130,7 +141,7 @@ func (c *Controller) process(ctx context.Context, gvr schema.GroupVersionResourc
 	}
 	if !exists {
 		// deleted upstream => delete downstream
-		klog.Infof("Deleting downstream GVR %q object %s/%s for upstream cluster %q", gvr.String(), upstreamNamespace, name, clusterName)
+		logger.Info("Deleting downstream object", "downstreamNamespace", downstreamNamespace, "downstreamName", name)
 		if err := c.downstreamClient.Resource(gvr).Namespace(downstreamNamespace).Delete(ctx, name, metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
 			return err
 		}
@@ -145,9 +156,89 @@ func (c *Controller) process(ctx context.Context, gvr schema.GroupVersionResourc
 	return c.applyToDownstream(ctx, gvr, downstreamNamespace, u)
 }
 
+// pauseMode returns how far syncing of objects of the given resource in the given downstream namespace is
+// paused. Namespaces not existing downstream yet are not paused.
+func (c *Controller) pauseMode(gvr schema.GroupVersionResource, downstreamNamespace string) shared.PauseMode {
+	var annotations map[string]string
+	if obj, err := c.downstreamInformers.ForResource(namespacesGVR).Lister().Get(downstreamNamespace); err == nil {
+		if ns, ok := obj.(metav1.Object); ok {
+			annotations = ns.GetAnnotations()
+		}
+	}
+	return c.syncPause.Mode(gvr.GroupResource(), annotations)
+}
+
+// processClusterScoped syncs an allow-listed cluster-scoped object downstream, or deletes it downstream when it
+// is deleted or not assigned to the workload cluster anymore upstream.
+func (c *Controller) processClusterScoped(ctx context.Context, gvr schema.GroupVersionResource, key string, clusterName logicalcluster.Name, name string) error {
+	if mode := c.syncPause.Mode(gvr.GroupResource(), nil); mode.PausesSpec() {
+		logging.FromContext(ctx).V(2).Info("Syncing is paused", "pauseMode", mode)
+		return nil
+	}
+
+	obj, exists, err := c.upstreamInformers.ForResource(gvr).Informer().GetIndexer().GetByKey(key)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		_, err := c.deleteClusterScopedDownstream(ctx, gvr, clusterName, c.clusterScopedPolicy.DownstreamName(name))
+		return err
+	}
+
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return fmt.Errorf("object to synchronize is expected to be Unstructured, but is %T", obj)
+	}
+	return c.applyToDownstream(ctx, gvr, "", u)
+}
+
+// deleteClusterScopedDownstream deletes the given cluster-scoped object downstream if it is owned by the
+// given upstream logical cluster. It returns true if there is no object of the logical cluster downstream,
+// i.e. if the object does not exist or is not owned by the logical cluster.
+func (c *Controller) deleteClusterScopedDownstream(ctx context.Context, gvr schema.GroupVersionResource, clusterName logicalcluster.Name, downstreamName string) (bool, error) {
+	logger := logging.FromContext(ctx).WithValues("downstreamName", downstreamName)
+	existing, err := c.downstreamClient.Resource(gvr).Get(ctx, downstreamName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return true, nil
+	} else if err != nil {
+		return false, err
+	}
+	if !shared.IsOwnedBy(existing.GetAnnotations(), clusterName) {
+		logger.V(2).Info("Not deleting downstream object not owned by upstream cluster")
+		return true, nil
+	}
+
+	logger.Info("Deleting downstream object")
+	uid := existing.GetUID()
+	if err := c.downstreamClient.Resource(gvr).Delete(ctx, downstreamName, metav1.DeleteOptions{Preconditions: &metav1.Preconditions{UID: &uid}}); apierrors.IsNotFound(err) {
+		return true, nil
+	} else if err != nil {
+		return false, err
+	}
+	return false, nil
+}
+
+// ensureClusterScopedOwnership makes sure that an existing downstream cluster-scoped object is owned by the
+// upstream logical cluster, i.e. that kcp does not take over objects of the physical cluster or of other
+// logical clusters.
+func (c *Controller) ensureClusterScopedOwnership(ctx context.Context, gvr schema.GroupVersionResource, clusterName logicalcluster.Name, downstreamName string) error {
+	existing, err := c.downstreamClient.Resource(gvr).Get(ctx, downstreamName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	if !shared.IsOwnedBy(existing.GetAnnotations(), clusterName) {
+		// TODO bubble this up as a condition somewhere.
+		return fmt.Errorf("downstream %s %s for upstream cluster %q already exists and is not owned by it", gvr.Resource, downstreamName, clusterName)
+	}
+	return nil
+}
+
 // TODO: This function is there as a quick and dirty implementation of namespace creation.
 //       In fact We should also be getting notifications about namespaces created upstream and be creating downstream equivalents.
 func (c *Controller) ensureDownstreamNamespaceExists(ctx context.Context, downstreamNamespace string, upstreamObj *unstructured.Unstructured) error {
+	logger := logging.FromContext(ctx).WithValues("downstreamNamespace", downstreamNamespace)
 	namespaces := c.downstreamClient.Resource(schema.GroupVersionResource{
 		Group:    "",
 		Version:  "v1",
@@ -186,17 +277,34 @@ func (c *Controller) ensureDownstreamNamespaceExists(ctx context.Context, downst
 		if !k8serrors.IsAlreadyExists(err) {
 			// Any other error is not good, though.
 			// TODO bubble this up as a condition somewhere.
-			klog.Errorf("Error while creating namespace %q: %v", downstreamNamespace, err)
+			logger.Error(err, "Error while creating downstream namespace")
+			return err
+		}
+
+		// Make sure that the existing namespace belongs to the same upstream namespace. Otherwise, the naming
+		// strategy maps two upstream namespaces to the same name, or the namespace is not owned by kcp at all.
+		existing, err := namespaces.Get(ctx, downstreamNamespace, metav1.GetOptions{})
+		if err != nil {
 			return err
 		}
+		existingLocator, err := shared.LocatorFromAnnotations(existing.GetAnnotations())
+		if err != nil {
+			return fmt.Errorf("failed to decode namespace locator of downstream namespace %s: %w", downstreamNamespace, err)
+		}
+		if existingLocator == nil || *existingLocator != l {
+			// TODO bubble this up as a condition somewhere.
+			logger.Error(nil, "Downstream namespace collides with an existing namespace", "upstreamNamespace", l.Namespace, "existingLocator", existing.GetAnnotations()[shared.NamespaceLocatorAnnotation])
+			return fmt.Errorf("downstream namespace %s for upstream namespace %s|%s is already in use", downstreamNamespace, l.LogicalCluster, l.Namespace)
+		}
 	} else {
-		klog.Infof("Created downstream namespace %s for upstream namespace %s|%s", downstreamNamespace, c.upstreamClusterName, upstreamObj.GetNamespace())
+		logger.Info("Created downstream namespace", "upstreamNamespace", upstreamObj.GetNamespace())
 	}
 
 	return nil
 }
 
 func (c *Controller) ensureSyncerFinalizer(ctx context.Context, gvr schema.GroupVersionResource, upstreamObj *unstructured.Unstructured) error {
+	logger := logging.FromContext(ctx)
 	upstreamFinalizers := upstreamObj.GetFinalizers()
 	hasFinalizer := false
 	for _, finalizer := range upstreamFinalizers {
@@ -206,24 +314,27 @@ func (c *Controller) ensureSyncerFinalizer(ctx context.Context, gvr schema.Group
 	}
 	if !hasFinalizer {
 		upstreamObjCopy := upstreamObj.DeepCopy()
-		name := upstreamObjCopy.GetName()
 		namespace := upstreamObjCopy.GetNamespace()
 
 		upstreamFinalizers = append(upstreamFinalizers, shared.SyncerFinalizerNamePrefix+c.workloadClusterName)
 		upstreamObjCopy.SetFinalizers(upstreamFinalizers)
 		if _, err := c.upstreamClient.Resource(gvr).Namespace(namespace).Update(ctx, upstreamObjCopy, metav1.UpdateOptions{}); err != nil {
-			klog.Errorf("Failed adding finalizer upstream on resource %s|%s/%s: %v", c.upstreamClusterName, namespace, name, err)
+			logger.Error(err, "Failed adding syncer finalizer upstream")
 			return err
 		}
-		klog.Infof("Updated resource %s|%s/%s with syncer finalizer upstream", c.upstreamClusterName, namespace, name)
+		logger.Info("Updated resource with syncer finalizer upstream")
 	}
 
 	return nil
 }
 
 func (c *Controller) applyToDownstream(ctx context.Context, gvr schema.GroupVersionResource, downstreamNamespace string, upstreamObj *unstructured.Unstructured) error {
-	if err := c.ensureDownstreamNamespaceExists(ctx, downstreamNamespace, upstreamObj); err != nil {
-		return err
+	logger := logging.FromContext(ctx)
+	clusterScoped := upstreamObj.GetNamespace() == "" && c.clusterScopedPolicy.Has(gvr)
+	if !clusterScoped {
+		if err := c.ensureDownstreamNamespaceExists(ctx, downstreamNamespace, upstreamObj); err != nil {
+			return err
+		}
 	}
 
 	// If the advanced scheduling feature is enabled, add the Syncer Finalizer to the upstream object
@@ -257,6 +368,9 @@ func (c *Controller) applyToDownstream(ctx context.Context, gvr schema.GroupVers
 
 	// Run name transformations on the downstreamObj.
 	transformName(downstreamObj)
+	if clusterScoped {
+		downstreamObj.SetName(c.clusterScopedPolicy.DownstreamName(upstreamObj.GetName()))
+	}
 
 	// Run any transformations on the object before we apply it to the downstream cluster.
 	if mutator, ok := c.mutators[gvr]; ok {
@@ -265,6 +379,28 @@ func (c *Controller) applyToDownstream(ctx context.Context, gvr schema.GroupVers
 		}
 	}
 
+	// Default the resources of the containers the way the LimitRanges synced along would.
+	if c.limitRangeMutator != nil {
+		for _, limitedGVR := range c.limitRangeMutator.GVRs() {
+			if gvr == limitedGVR {
+				if err := c.limitRangeMutator.Mutate(upstreamObj.GetNamespace(), downstreamObj); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	// Make the services of the workspace resolvable by their upstream names.
+	if c.serviceDNSMutator != nil {
+		for _, mutatedGVR := range c.serviceDNSMutator.GVRs() {
+			if gvr == mutatedGVR {
+				if err := c.serviceDNSMutator.Mutate(upstreamObj.GetNamespace(), downstreamObj); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
 	if c.advancedSchedulingEnabled {
 		specDiffPatch := upstreamObj.GetAnnotations()[workloadv1alpha1.ClusterSpecDiffAnnotationPrefix+c.workloadClusterName]
 		if specDiffPatch != "" {
@@ -276,7 +412,7 @@ func (c *Controller) applyToDownstream(ctx context.Context, gvr schema.GroupVers
 				// TODO(jmprusi): Surface those errors to the user.
 				patch, err := jsonpatch.DecodePatch([]byte(specDiffPatch))
 				if err != nil {
-					klog.Errorf("Failed to decode spec diff patch: %v", err)
+					logger.Error(err, "Failed to decode spec diff patch")
 					return err
 				}
 				upstreamSpecJSON, err := json.Marshal(upstreamSpec)
@@ -307,6 +443,18 @@ func (c *Controller) applyToDownstream(ctx context.Context, gvr schema.GroupVers
 		// TODO(jmprusi): When using syncer virtual workspace this condition would not be necessary anymore, since directly tested on the virtual workspace side.
 		stillOwnedByExternalActorForLocation := upstreamObj.GetAnnotations()[workloadv1alpha1.ClusterFinalizerAnnotationPrefix+c.workloadClusterName] != ""
 
+		if intendedToBeRemovedFromLocation && !stillOwnedByExternalActorForLocation && clusterScoped {
+			// cluster-scoped objects of the physical cluster or of other logical clusters must not be deleted
+			gone, err := c.deleteClusterScopedDownstream(ctx, gvr, logicalcluster.From(upstreamObj), downstreamObj.GetName())
+			if err != nil {
+				logger.Error(err, "Error deleting downstream object", "downstreamName", downstreamObj.GetName())
+				return err
+			}
+			if gone {
+				return shared.EnsureUpstreamFinalizerRemoved(ctx, gvr, c.upstreamClient, upstreamObj.GetNamespace(), c.workloadClusterName, c.upstreamClusterName, upstreamObj.GetName())
+			}
+			return nil
+		}
 		if intendedToBeRemovedFromLocation && !stillOwnedByExternalActorForLocation {
 			if err := c.downstreamClient.Resource(gvr).Namespace(downstreamNamespace).Delete(ctx, downstreamObj.GetName(), metav1.DeleteOptions{}); err != nil {
 				if apierrors.IsNotFound(err) {
@@ -317,27 +465,98 @@ func (c *Controller) applyToDownstream(ctx context.Context, gvr schema.GroupVers
 					}
 					return nil
 				}
-				klog.Errorf("Error deleting %s %s/%s from downstream %s|%s/%s: %v", gvr.Resource, upstreamObj.GetNamespace(), upstreamObj.GetName(), upstreamObj.GetClusterName(), upstreamObj.GetNamespace(), upstreamObj.GetName(), err)
+				logger.Error(err, "Error deleting downstream object", "downstreamNamespace", downstreamNamespace, "downstreamName", downstreamObj.GetName())
 				return err
 			}
-			klog.V(2).Infof("Deleted %s %s/%s from downstream %s|%s/%s", gvr.Resource, upstreamObj.GetNamespace(), downstreamObj.GetName(), upstreamObj.GetClusterName(), upstreamObj.GetNamespace(), upstreamObj.GetName())
+			logger.V(2).Info("Deleted downstream object", "downstreamNamespace", downstreamNamespace, "downstreamName", downstreamObj.GetName())
 			return nil
 		}
 	}
 
+	// The sync condition and the mutations only make sense upstream.
+	annotations := downstreamObj.GetAnnotations()
+	delete(annotations, workloadv1alpha1.ExperimentalClusterSyncConditionAnnotationPrefix+c.workloadClusterName)
+	delete(annotations, workloadv1alpha1.ExperimentalClusterMutationsAnnotationPrefix+c.workloadClusterName)
+	if clusterScoped {
+		// Cluster-scoped objects carry the locator of their logical cluster themselves, to identify their owner.
+		locator, err := json.Marshal(shared.NamespaceLocator{LogicalCluster: logicalcluster.From(upstreamObj)})
+		if err != nil {
+			return err
+		}
+		if annotations == nil {
+			annotations = map[string]string{}
+		}
+		annotations[shared.NamespaceLocatorAnnotation] = string(locator)
+	}
+	downstreamObj.SetAnnotations(annotations)
+	c.fieldPruningPolicy.prune(downstreamObj)
+
+	// Only apply fields originating upstream: the remaining metadata is populated by the downstream
+	// API server. The status is only dropped by the field pruning policy, as it is ignored downstream
+	// for resources with status subresource anyway.
+	unstructured.RemoveNestedField(downstreamObj.Object, "metadata", "creationTimestamp")
+	unstructured.RemoveNestedField(downstreamObj.Object, "metadata", "generation")
+	unstructured.RemoveNestedField(downstreamObj.Object, "metadata", "selfLink")
+
 	// Marshalling the unstructured object is good enough as SSA patch
 	data, err := json.Marshal(downstreamObj)
 	if err != nil {
 		return err
 	}
 
-	if _, err := c.downstreamClient.Resource(gvr).Namespace(downstreamNamespace).Patch(ctx, downstreamObj.GetName(), types.ApplyPatchType, data, metav1.PatchOptions{FieldManager: syncerApplyManager, Force: pointer.Bool(true)}); err != nil {
-		klog.Errorf("Error upserting %s %s/%s from upstream %s|%s/%s: %v", gvr.Resource, downstreamObj.GetNamespace(), downstreamObj.GetName(), upstreamObj.GetClusterName(), upstreamObj.GetNamespace(), upstreamObj.GetName(), err)
+	// Objects exceeding the limits of the physical cluster are reported upstream instead of failing forever.
+	if err := c.fieldPruningPolicy.checkSize(data); err != nil {
+		logger.Error(err, "Not upserting downstream object", "downstreamNamespace", downstreamObj.GetNamespace(), "downstreamName", downstreamObj.GetName())
+		return c.updateSyncCondition(ctx, gvr, upstreamObj, objectTooLargeCondition(err))
+	}
+
+	if clusterScoped {
+		if err := c.ensureClusterScopedOwnership(ctx, gvr, logicalcluster.From(upstreamObj), downstreamObj.GetName()); err != nil {
+			logger.Error(err, "Not upserting downstream object", "downstreamName", downstreamObj.GetName())
+			return err
+		}
+	}
+
+	applied, err := c.applyDownstream(ctx, gvr, downstreamNamespace, downstreamObj.GetName(), data)
+	if err != nil {
+		logger.Error(err, "Error upserting downstream object", "downstreamNamespace", downstreamObj.GetNamespace(), "downstreamName", downstreamObj.GetName())
 		return err
 	}
-	klog.Infof("Upserted %s %s/%s from upstream %s|%s/%s", gvr.Resource, downstreamObj.GetNamespace(), downstreamObj.GetName(), upstreamObj.GetClusterName(), upstreamObj.GetNamespace(), upstreamObj.GetName())
+	logger.Info("Upserted downstream object", "downstreamNamespace", downstreamObj.GetNamespace(), "downstreamName", downstreamObj.GetName())
 
-	return nil
+	// Remember what we wrote, for the drift detection to notice out-of-band changes downstream.
+	if c.driftStore != nil && applied != nil {
+		upstreamKey, err := cache.MetaNamespaceKeyFunc(upstreamObj)
+		if err != nil {
+			return err
+		}
+		if err := c.driftStore.Record(gvr, upstreamKey, applied); err != nil {
+			return err
+		}
+	}
+
+	if c.recordMutations {
+		if err := c.updateMutations(ctx, gvr, upstreamObj, c.diffDownstream(upstreamObj, downstreamObj)); err != nil {
+			return err
+		}
+	}
+
+	return c.updateSyncCondition(ctx, gvr, upstreamObj, nil)
+}
+
+// applyDownstream server-side applies the given object downstream. Fields of the object owned by other
+// managers on the physical cluster are only taken over on conflict, as upstream is the source of truth.
+// Fields set downstream that do not originate upstream, e.g. by mutating webhooks, are left alone.
+func (c *Controller) applyDownstream(ctx context.Context, gvr schema.GroupVersionResource, namespace, name string, data []byte) (*unstructured.Unstructured, error) {
+	client := c.downstreamClient.Resource(gvr).Namespace(namespace)
+	applied, err := client.Patch(ctx, name, types.ApplyPatchType, data, metav1.PatchOptions{FieldManager: syncerApplyManager})
+	if !apierrors.IsConflict(err) {
+		return applied, err
+	}
+
+	shared.ObserveApplyConflict(c.workloadClusterName, gvr)
+	logging.FromContext(ctx).V(2).Info("Taking over conflicting fields downstream", "downstreamNamespace", namespace, "downstreamName", name, "conflict", err.Error())
+	return client.Patch(ctx, name, types.ApplyPatchType, data, metav1.PatchOptions{FieldManager: syncerApplyManager, Force: pointer.Bool(true)})
 }
 
 // transformName changes the object name into the desired one downstream.
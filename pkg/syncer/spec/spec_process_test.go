@@ -19,6 +19,7 @@ package spec
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"net/url"
 	"strings"
 	"testing"
@@ -30,6 +31,7 @@ import (
 
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -503,6 +505,7 @@ func TestSyncerProcess(t *testing.T) {
 							toUnstructured(t, deployment("theDeployment", "kcp0124d7647eb6a00b1fcb6f2252201601634989dd79deb7375c373973", "", map[string]string{
 								"internal.workloads.kcp.dev/cluster": "us-west1",
 							}, nil, nil)),
+							removeNilOrEmptyFields,
 							setNestedField(map[string]interface{}{}, "status"),
 							setPodSpecServiceAccount("spec", "template", "spec"),
 						),
@@ -574,6 +577,7 @@ func TestSyncerProcess(t *testing.T) {
 							toUnstructured(t, deployment("theDeployment", "kcp0124d7647eb6a00b1fcb6f2252201601634989dd79deb7375c373973", "", map[string]string{
 								"internal.workloads.kcp.dev/cluster": "us-west1",
 							}, nil, nil)),
+							removeNilOrEmptyFields,
 							setNestedField(map[string]interface{}{}, "status"),
 							setPodSpecServiceAccount("spec", "template", "spec"),
 						),
@@ -641,6 +645,7 @@ func TestSyncerProcess(t *testing.T) {
 									"containers": nil,
 								},
 							}, "spec", "template"),
+							removeNilOrEmptyFields,
 							setNestedField(map[string]interface{}{}, "status"),
 						),
 					),
@@ -688,6 +693,7 @@ func TestSyncerProcess(t *testing.T) {
 						removeNilOrEmptyFields,
 					),
 				),
+				getNamespaceAction("kcp0124d7647eb6a00b1fcb6f2252201601634989dd79deb7375c373973"),
 				deleteDeploymentAction(
 					"theDeployment",
 					"kcp0124d7647eb6a00b1fcb6f2252201601634989dd79deb7375c373973",
@@ -747,12 +753,55 @@ func TestSyncerProcess(t *testing.T) {
 						removeNilOrEmptyFields,
 					),
 				),
+				getNamespaceAction("kcp0124d7647eb6a00b1fcb6f2252201601634989dd79deb7375c373973"),
 				deleteDeploymentAction(
 					"theDeployment",
 					"kcp0124d7647eb6a00b1fcb6f2252201601634989dd79deb7375c373973",
 				),
 			},
 		},
+		"SpecSyncer namespace collision: downstream namespace belongs to another upstream namespace": {
+			upstreamLogicalCluster: "root:org:ws",
+			fromNamespace: namespace("test", "root:org:ws", map[string]string{
+				"state.internal.workloads.kcp.dev/us-west1": "Sync",
+			}, nil),
+			gvr: schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"},
+			toResources: []runtime.Object{
+				namespace("kcp0124d7647eb6a00b1fcb6f2252201601634989dd79deb7375c373973", "", map[string]string{
+					"internal.workloads.kcp.dev/cluster": "us-west1",
+				},
+					map[string]string{
+						"kcp.dev/namespace-locator": `{"logical-cluster":"root:org:other","namespace":"test"}`,
+					}),
+			},
+			fromResource: deployment("theDeployment", "test", "root:org:ws",
+				map[string]string{"state.internal.workloads.kcp.dev/us-west1": "Sync"},
+				nil,
+				[]string{"workloads.kcp.dev/syncer-us-west1"}),
+			resourceToProcessLogicalClusterName: "root:org:ws",
+			resourceToProcessName:               "theDeployment",
+			workloadClusterName:                 "us-west1",
+			advancedSchedulingEnabled:           true,
+			expectError:                         true,
+
+			expectActionsOnFrom: []clienttesting.Action{},
+			expectActionsOnTo: []clienttesting.Action{
+				createNamespaceAction(
+					"",
+					changeUnstructured(
+						toUnstructured(t, namespace("kcp0124d7647eb6a00b1fcb6f2252201601634989dd79deb7375c373973", "",
+							map[string]string{
+								"internal.workloads.kcp.dev/cluster": "us-west1",
+							},
+							map[string]string{
+								"kcp.dev/namespace-locator": `{"logical-cluster":"root:org:ws","namespace":"test"}`,
+							})),
+						removeNilOrEmptyFields,
+					),
+				),
+				getNamespaceAction("kcp0124d7647eb6a00b1fcb6f2252201601634989dd79deb7375c373973"),
+			},
+		},
 		"SpecSyncer with AdvancedScheduling, deletion: upstream object has external finalizer": {
 			upstreamLogicalCluster: "root:org:ws",
 			fromNamespace: namespace("test", "root:org:ws", map[string]string{
@@ -797,6 +846,7 @@ func TestSyncerProcess(t *testing.T) {
 						removeNilOrEmptyFields,
 					),
 				),
+				getNamespaceAction("kcp0124d7647eb6a00b1fcb6f2252201601634989dd79deb7375c373973"),
 				patchDeploymentAction(
 					"theDeployment",
 					"kcp0124d7647eb6a00b1fcb6f2252201601634989dd79deb7375c373973",
@@ -821,6 +871,7 @@ func TestSyncerProcess(t *testing.T) {
 									"containers": nil,
 								},
 							}, "spec", "template"),
+							removeNilOrEmptyFields,
 							setNestedField(map[string]interface{}{}, "status"),
 							setPodSpecServiceAccount("spec", "template", "spec"),
 						),
@@ -861,7 +912,7 @@ func TestSyncerProcess(t *testing.T) {
 			}
 			upstreamURL, err := url.Parse("https://kcp.dev:6443")
 			require.NoError(t, err)
-			controller, err := NewSpecSyncer(gvrs, kcpLogicalCluster, tc.workloadClusterName, upstreamURL, tc.advancedSchedulingEnabled, fromClient, toClient, fromInformers, toInformers)
+			controller, err := NewSpecSyncer(gvrs, kcpLogicalCluster, tc.workloadClusterName, upstreamURL, fromClient, toClient, fromInformers, toInformers, Options{AdvancedSchedulingEnabled: tc.advancedSchedulingEnabled})
 			require.NoError(t, err)
 
 			fromInformers.Start(ctx.Done())
@@ -896,6 +947,51 @@ func TestSyncerProcess(t *testing.T) {
 	}
 }
 
+func TestApplyDownstreamConflict(t *testing.T) {
+	gvr := schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+
+	tests := map[string]struct {
+		conflicts   int
+		wantPatches int
+		wantError   bool
+	}{
+		"no conflict": {
+			wantPatches: 1,
+		},
+		"conflicting fields are taken over": {
+			conflicts:   1,
+			wantPatches: 2,
+		},
+		"failing forced apply": {
+			conflicts:   2,
+			wantPatches: 2,
+			wantError:   true,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			toClient := dynamicfake.NewSimpleDynamicClient(scheme)
+			conflicts := tc.conflicts
+			toClient.PrependReactor("patch", "*", func(action clienttesting.Action) (handled bool, ret runtime.Object, err error) {
+				if conflicts > 0 {
+					conflicts--
+					return true, nil, apierrors.NewConflict(gvr.GroupResource(), "theDeployment", errors.New(`Apply failed with 1 conflict: conflict with "kubectl": .spec.replicas`))
+				}
+				return true, nil, nil
+			})
+
+			c := &Controller{downstreamClient: toClient, workloadClusterName: "us-west1"}
+			_, err := c.applyDownstream(context.Background(), gvr, "kcp-ns", "theDeployment", []byte(`{}`))
+			if tc.wantError {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Len(t, toClient.Actions(), tc.wantPatches)
+		})
+	}
+}
+
 func setupServersideApplyPatchReactor(toClient *dynamicfake.FakeDynamicClient) {
 	toClient.PrependReactor("patch", "*", func(action clienttesting.Action) (handled bool, ret runtime.Object, err error) {
 		patchAction := action.(clienttesting.PatchAction)
@@ -1045,6 +1141,13 @@ func getDeploymentAction(name, namespace string) clienttesting.GetActionImpl {
 	}
 }
 
+func getNamespaceAction(name string) clienttesting.GetActionImpl {
+	return clienttesting.GetActionImpl{
+		ActionImpl: namespaceAction("get"),
+		Name:       name,
+	}
+}
+
 func createNamespaceAction(name string, object runtime.Object) clienttesting.CreateActionImpl {
 	return clienttesting.CreateActionImpl{
 		ActionImpl: namespaceAction("create"),
@@ -1076,3 +1179,107 @@ func deleteDeploymentAction(name, namespace string, subresources ...string) clie
 		DeleteOptions: metav1.DeleteOptions{},
 	}
 }
+
+func TestDeleteClusterScopedDownstream(t *testing.T) {
+	gvr := schema.GroupVersionResource{Group: "scheduling.k8s.io", Version: "v1", Resource: "priorityclasses"}
+
+	tests := map[string]struct {
+		locator    string
+		wantDelete bool
+	}{
+		"owned by the logical cluster": {
+			locator:    `{"logical-cluster":"root:org:ws","namespace":""}`,
+			wantDelete: true,
+		},
+		"owned by another logical cluster": {
+			locator: `{"logical-cluster":"root:org:other","namespace":""}`,
+		},
+		"not created by a syncer": {},
+	}
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			existing := &unstructured.Unstructured{}
+			existing.SetAPIVersion("scheduling.k8s.io/v1")
+			existing.SetKind("PriorityClass")
+			existing.SetName("kcp-high")
+			existing.SetUID("uid-high")
+			if tc.locator != "" {
+				existing.SetAnnotations(map[string]string{shared.NamespaceLocatorAnnotation: tc.locator})
+			}
+			toClient := dynamicfake.NewSimpleDynamicClient(scheme, existing)
+
+			c := &Controller{downstreamClient: toClient, workloadClusterName: "us-west1"}
+			gone, err := c.deleteClusterScopedDownstream(context.Background(), gvr, logicalcluster.New("root:org:ws"), "kcp-high")
+			require.NoError(t, err)
+			require.Equal(t, !tc.wantDelete, gone)
+
+			_, err = toClient.Resource(gvr).Get(context.Background(), "kcp-high", metav1.GetOptions{})
+			require.Equal(t, tc.wantDelete, apierrors.IsNotFound(err), "unexpected error: %v", err)
+
+			// objects left behind are not taken over
+			err = c.ensureClusterScopedOwnership(context.Background(), gvr, logicalcluster.New("root:org:ws"), "kcp-high")
+			require.Equal(t, !tc.wantDelete, err != nil, "unexpected error: %v", err)
+		})
+	}
+}
+
+func TestProcessPaused(t *testing.T) {
+	gvr := schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+	clusterName := logicalcluster.New("root:org:ws")
+	downstreamNamespace, err := shared.PhysicalClusterNamespaceName(shared.NamespaceLocator{LogicalCluster: clusterName, Namespace: "test"})
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		namespacePause  string
+		pausedResources string
+		wantActions     int
+	}{
+		"not paused, the downstream object of the deleted upstream object is deleted": {
+			wantActions: 1,
+		},
+		"paused namespace": {
+			namespacePause: "Spec",
+		},
+		"paused resource": {
+			pausedResources: "deployments.apps=All",
+		},
+		"other paused resource": {
+			pausedResources: "services=All",
+			wantActions:     1,
+		},
+	}
+	for name, tc := range tests {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			toClient := dynamicfake.NewSimpleDynamicClient(scheme)
+			fromInformers := dynamicinformer.NewDynamicSharedInformerFactory(dynamicfake.NewSimpleDynamicClient(scheme), time.Hour)
+			toInformers := dynamicinformer.NewDynamicSharedInformerFactory(toClient, time.Hour)
+
+			ns := &unstructured.Unstructured{}
+			ns.SetAPIVersion("v1")
+			ns.SetKind("Namespace")
+			ns.SetName(downstreamNamespace)
+			if tc.namespacePause != "" {
+				ns.SetAnnotations(map[string]string{workloadv1alpha1.ExperimentalSyncPausedAnnotation: tc.namespacePause})
+			}
+			require.NoError(t, toInformers.ForResource(namespacesGVR).Informer().GetIndexer().Add(ns))
+			fromInformers.ForResource(gvr).Informer()
+
+			syncPause := shared.NewSyncPause()
+			require.NoError(t, syncPause.UpdateFromAnnotations(map[string]string{workloadv1alpha1.ExperimentalSyncPausedResourcesAnnotation: tc.pausedResources}))
+
+			c := &Controller{
+				downstreamClient:    toClient,
+				upstreamInformers:   fromInformers,
+				downstreamInformers: toInformers,
+				workloadClusterName: "us-west1",
+				upstreamClusterName: clusterName,
+				syncPause:           syncPause,
+			}
+			err := c.process(context.Background(), gvr, "test/"+clusters.ToClusterAwareKey(clusterName, "theDeployment"))
+			require.NoError(t, err)
+			require.Len(t, toClient.Actions(), tc.wantActions)
+		})
+	}
+}
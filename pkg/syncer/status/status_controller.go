@@ -21,9 +21,13 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/go-logr/logr"
 	"github.com/kcp-dev/logicalcluster"
+	"go.opentelemetry.io/otel/attribute"
 
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/apimachinery/pkg/util/wait"
@@ -31,30 +35,50 @@ import (
 	"k8s.io/client-go/dynamic/dynamicinformer"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/util/workqueue"
-	"k8s.io/klog/v2"
+
+	"github.com/kcp-dev/kcp/pkg/logging"
+	"github.com/kcp-dev/kcp/pkg/syncer/shared"
+	"github.com/kcp-dev/kcp/pkg/syncer/status/summarizers"
+	"github.com/kcp-dev/kcp/pkg/tracing"
 )
 
 const (
 	controllerName = "kcp-workload-syncer-status"
 )
 
+var namespacesGVR = schema.GroupVersionResource{Version: "v1", Resource: "namespaces"}
+
+type summarizerGvrMap map[schema.GroupVersionResource]func(statuses map[string]map[string]interface{}) (map[string]interface{}, error)
+
 type Controller struct {
 	queue workqueue.RateLimitingInterface
 
+	logger logr.Logger
+
+	summarizers summarizerGvrMap
+
 	upstreamClient, downstreamClient       dynamic.Interface
 	upstreamInformers, downstreamInformers dynamicinformer.DynamicSharedInformerFactory
 
 	workloadClusterName       string
 	upstreamClusterName       logicalcluster.Name
 	advancedSchedulingEnabled bool
+	syncPause                 *shared.SyncPause
 }
 
-func NewStatusSyncer(gvrs []schema.GroupVersionResource, upstreamClusterName logicalcluster.Name, workloadClusterName string, advancedSchedulingEnabled bool,
+func NewStatusSyncer(gvrs []schema.GroupVersionResource, upstreamClusterName logicalcluster.Name, workloadClusterName string, advancedSchedulingEnabled bool, syncPause *shared.SyncPause,
 	upstreamClient, downstreamClient dynamic.Interface, upstreamInformers, downstreamInformers dynamicinformer.DynamicSharedInformerFactory) (*Controller, error) {
+	deploymentSummarizer := summarizers.NewDeploymentSummarizer()
 
 	c := &Controller{
 		queue: workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), controllerName),
 
+		logger: logging.WithSyncTarget(logging.WithWorkspace(logging.NewLogger(controllerName), upstreamClusterName), workloadClusterName),
+
+		summarizers: summarizerGvrMap{
+			deploymentSummarizer.GVR(): deploymentSummarizer.Summarize,
+		},
+
 		upstreamClient:      upstreamClient,
 		downstreamClient:    downstreamClient,
 		upstreamInformers:   upstreamInformers,
@@ -63,6 +87,7 @@ func NewStatusSyncer(gvrs []schema.GroupVersionResource, upstreamClusterName log
 		workloadClusterName:       workloadClusterName,
 		upstreamClusterName:       upstreamClusterName,
 		advancedSchedulingEnabled: advancedSchedulingEnabled,
+		syncPause:                 syncPause,
 	}
 
 	for _, gvr := range gvrs {
@@ -84,9 +109,29 @@ func NewStatusSyncer(gvrs []schema.GroupVersionResource, upstreamClusterName log
 				c.AddToQueue(gvr, obj)
 			},
 		})
-		klog.InfoS("Set up informer", "clusterName", upstreamClusterName, "pcluster", workloadClusterName, "gvr", gvr.String())
+		c.logger.Info("Set up informer", "gvr", gvr.String())
 	}
 
+	// Sync the status again when pausing is lifted.
+	downstreamInformers.ForResource(namespacesGVR).Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			oldNamespace, newNamespace := oldObj.(*unstructured.Unstructured), newObj.(*unstructured.Unstructured)
+			if !shared.NamespacePauseMode(oldNamespace.GetAnnotations()).PausesStatus() || shared.NamespacePauseMode(newNamespace.GetAnnotations()).PausesStatus() {
+				return
+			}
+			for _, gvr := range gvrs {
+				c.addAllToQueue(gvr, newNamespace.GetName())
+			}
+		},
+	})
+	syncPause.AddResumeHandler(func(gr schema.GroupResource) {
+		for _, gvr := range gvrs {
+			if gvr.GroupResource() == gr {
+				c.addAllToQueue(gvr, metav1.NamespaceAll)
+			}
+		}
+	})
+
 	return c, nil
 }
 
@@ -102,7 +147,7 @@ func (c *Controller) AddToQueue(gvr schema.GroupVersionResource, obj interface{}
 		return
 	}
 
-	klog.Infof("%s queueing GVR %q %s", controllerName, gvr.String(), key)
+	logging.WithQueueKey(c.logger, key).Info("Queueing", "gvr", gvr.String())
 	c.queue.Add(
 		queueKey{
 			gvr: gvr,
@@ -111,13 +156,26 @@ func (c *Controller) AddToQueue(gvr schema.GroupVersionResource, obj interface{}
 	)
 }
 
+// addAllToQueue queues the downstream objects of the given resource in the given downstream namespace, or in
+// all namespaces.
+func (c *Controller) addAllToQueue(gvr schema.GroupVersionResource, namespace string) {
+	objs, err := c.downstreamInformers.ForResource(gvr).Lister().ByNamespace(namespace).List(labels.Everything())
+	if err != nil {
+		runtime.HandleError(err)
+		return
+	}
+	for _, obj := range objs {
+		c.AddToQueue(gvr, obj)
+	}
+}
+
 // Start starts N worker processes processing work items.
 func (c *Controller) Start(ctx context.Context, numThreads int) {
 	defer runtime.HandleCrash()
 	defer c.queue.ShutDown()
 
-	klog.InfoS("Starting syncer workers", "controller", controllerName)
-	defer klog.InfoS("Stopping syncer workers", "controller", controllerName)
+	c.logger.Info("Starting syncer workers")
+	defer c.logger.Info("Stopping syncer workers")
 	for i := 0; i < numThreads; i++ {
 		go wait.UntilWithContext(ctx, c.startWorker, time.Second)
 	}
@@ -143,7 +201,17 @@ func (c *Controller) processNextWorkItem(ctx context.Context) bool {
 	// other workers.
 	defer c.queue.Done(key)
 
-	if err := c.process(ctx, qk.gvr, qk.key); err != nil {
+	ctx = logging.NewContext(ctx, logging.WithQueueKey(c.logger, qk.key).WithValues("gvr", qk.gvr.String()))
+
+	ctx, span := tracing.StartSpan(ctx, controllerName, "Sync", c.upstreamClusterName,
+		attribute.String("gvr", qk.gvr.String()), attribute.String("key", qk.key))
+	defer span.End()
+
+	start := time.Now()
+	err := c.process(ctx, qk.gvr, qk.key)
+	shared.ObserveSync("status", c.workloadClusterName, qk.gvr, start, err)
+	if err != nil {
+		span.RecordError(err)
 		runtime.HandleError(fmt.Errorf("%s failed to sync %q, err: %w", controllerName, key, err))
 		c.queue.AddRateLimited(key)
 		return true
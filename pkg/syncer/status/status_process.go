@@ -20,20 +20,25 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
-	"reflect"
 	"strings"
 
+	jsonpatch "github.com/evanphx/json-patch"
+
 	"k8s.io/apimachinery/pkg/api/equality"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	utiljson "k8s.io/apimachinery/pkg/util/json"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/clusters"
-	"k8s.io/klog/v2"
 
 	workloadv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/workload/v1alpha1"
 	workloadcliplugin "github.com/kcp-dev/kcp/pkg/cliplugins/workload/plugin"
+	"github.com/kcp-dev/kcp/pkg/logging"
 	"github.com/kcp-dev/kcp/pkg/syncer/shared"
+
+	"github.com/kcp-dev/kcp/pkg/syncer/status/summarizers"
 )
 
 func deepEqualFinalizersAndStatus(oldUnstrob, newUnstrob *unstructured.Unstructured) bool {
@@ -47,12 +52,17 @@ func deepEqualFinalizersAndStatus(oldUnstrob, newUnstrob *unstructured.Unstructu
 }
 
 func (c *Controller) process(ctx context.Context, gvr schema.GroupVersionResource, key string) error {
-	klog.V(3).InfoS("Processing", "gvr", gvr, "key", key)
+	logger := logging.FromContext(ctx)
+	logger.V(3).Info("Processing")
 
 	// from downstream
 	downstreamNamespace, clusterAwareName, err := cache.SplitMetaNamespaceKey(key)
 	if err != nil {
-		klog.Errorf("Invalid key: %q: %v", key, err)
+		logger.Error(err, "Invalid key")
+		return nil
+	}
+	if downstreamNamespace == "" {
+		// cluster-scoped objects are only synced downstream, their status is not synced upstream.
 		return nil
 	}
 	downstreamClusterName, name := clusters.SplitClusterAwareKey(clusterAwareName)
@@ -63,7 +73,7 @@ func (c *Controller) process(ctx context.Context, gvr schema.GroupVersionResourc
 	}
 
 	// to upstream
-	nsInformer := c.downstreamInformers.ForResource(schema.GroupVersionResource{Version: "v1", Resource: "namespaces"})
+	nsInformer := c.downstreamInformers.ForResource(namespacesGVR)
 	nsKey := downstreamNamespace
 	if !downstreamClusterName.Empty() {
 		// If our "physical" cluster is a kcp instance (e.g. for testing purposes), it will return resources
@@ -72,17 +82,17 @@ func (c *Controller) process(ctx context.Context, gvr schema.GroupVersionResourc
 	}
 	nsObj, err := nsInformer.Lister().Get(nsKey)
 	if err != nil {
-		klog.Errorf("Error retrieving namespace %q from downstream lister: %v", nsKey, err)
+		logger.Error(err, "Error retrieving namespace from downstream lister", "downstreamNamespace", nsKey)
 		return nil
 	}
 	nsMeta, ok := nsObj.(metav1.Object)
 	if !ok {
-		klog.Errorf("Namespace %q expected to be metav1.Object, got %T", nsKey, nsObj)
+		logger.Error(nil, "Namespace expected to be metav1.Object", "downstreamNamespace", nsKey, "type", fmt.Sprintf("%T", nsObj))
 		return nil
 	}
 	namespaceLocator, err := shared.LocatorFromAnnotations(nsMeta.GetAnnotations())
 	if err != nil {
-		klog.Errorf(" namespace %q: error decoding annotation: %v", nsKey, err)
+		logger.Error(err, "Error decoding namespace locator annotation", "downstreamNamespace", nsKey)
 		return nil
 	}
 	if namespaceLocator == nil || namespaceLocator.LogicalCluster != c.upstreamClusterName {
@@ -91,6 +101,13 @@ func (c *Controller) process(ctx context.Context, gvr schema.GroupVersionResourc
 		return nil
 	}
 	upstreamNamespace := namespaceLocator.Namespace
+	logger = logger.WithValues("upstreamNamespace", upstreamNamespace)
+	ctx = logging.NewContext(ctx, logger)
+
+	if mode := c.syncPause.Mode(gvr.GroupResource(), nsMeta.GetAnnotations()); mode.PausesStatus() {
+		logger.V(2).Info("Syncing is paused", "pauseMode", mode)
+		return nil
+	}
 
 	// get the downstream object
 	obj, exists, err := c.downstreamInformers.ForResource(gvr).Informer().GetIndexer().GetByKey(key)
@@ -100,7 +117,7 @@ func (c *Controller) process(ctx context.Context, gvr schema.GroupVersionResourc
 	if !exists {
 		if c.advancedSchedulingEnabled {
 			// deleted downstream => remove finalizer upstream
-			klog.InfoS("Downstream GVR %q object %s|%s/%s does not exist. Removing finalizer upstream", gvr.String(), downstreamClusterName, upstreamNamespace, name)
+			logger.Info("Downstream object does not exist. Removing finalizer upstream")
 			return shared.EnsureUpstreamFinalizerRemoved(ctx, gvr, c.upstreamClient, upstreamNamespace, c.workloadClusterName, c.upstreamClusterName, name)
 		}
 		return nil
@@ -115,9 +132,9 @@ func (c *Controller) process(ctx context.Context, gvr schema.GroupVersionResourc
 }
 
 func (c *Controller) updateStatusInUpstream(ctx context.Context, gvr schema.GroupVersionResource, upstreamNamespace string, downstreamObj *unstructured.Unstructured) error {
+	logger := logging.FromContext(ctx)
+
 	upstreamObj := downstreamObj.DeepCopy()
-	upstreamObj.SetUID("")
-	upstreamObj.SetResourceVersion("")
 	upstreamObj.SetNamespace(upstreamNamespace)
 
 	// Run name transformations on upstreamObj
@@ -128,60 +145,137 @@ func (c *Controller) updateStatusInUpstream(ctx context.Context, gvr schema.Grou
 	if err != nil {
 		return err
 	} else if !statusExists {
-		klog.Infof("Resource doesn't contain a status. Skipping updating status of resource %s|%s/%s from workloadClusterName namespace %s", c.upstreamClusterName, upstreamNamespace, name, downstreamObj.GetNamespace())
+		logger.Info("Resource doesn't contain a status. Skipping updating status upstream")
 		return nil
 	}
 
 	existing, err := c.upstreamClient.Resource(gvr).Namespace(upstreamNamespace).Get(ctx, name, metav1.GetOptions{})
 	if err != nil {
-		klog.Errorf("Getting resource %s/%s: %v", upstreamNamespace, name, err)
+		logger.Error(err, "Error getting upstream resource")
 		return err
 	}
 
-	labels := upstreamObj.GetLabels()
-	delete(labels, workloadv1alpha1.InternalDownstreamClusterLabel)
-	labels[workloadv1alpha1.InternalClusterResourceStateLabelPrefix+c.workloadClusterName] = string(workloadv1alpha1.ResourceStateSync)
-	upstreamObj.SetLabels(labels)
-
-	// TODO: verify that we really only update status, and not some non-status fields in ObjectMeta.
-	//       I believe to remember that we had resources where that happened.
-
-	upstreamObj.SetResourceVersion(existing.GetResourceVersion())
-
 	if c.advancedSchedulingEnabled {
-		newUpstream := existing.DeepCopy()
 		statusAnnotationValue, err := json.Marshal(downstreamStatus)
 		if err != nil {
 			return err
 		}
-		newUpstreamAnnotations := newUpstream.GetAnnotations()
-		if newUpstreamAnnotations == nil {
-			newUpstreamAnnotations = make(map[string]string)
-		}
-		newUpstreamAnnotations[workloadv1alpha1.InternalClusterStatusAnnotationPrefix+c.workloadClusterName] = string(statusAnnotationValue)
-		newUpstream.SetAnnotations(newUpstreamAnnotations)
+		statusAnnotation := workloadv1alpha1.InternalClusterStatusAnnotationPrefix + c.workloadClusterName
 
-		if reflect.DeepEqual(existing, newUpstream) {
-			klog.V(2).Infof("No need to update the status of resource %s|%s/%s from workloadClusterName namespace %s", c.upstreamClusterName, upstreamNamespace, name, downstreamObj.GetNamespace())
-			return nil
+		if value, found := existing.GetAnnotations()[statusAnnotation]; found && value == string(statusAnnotationValue) {
+			logger.V(2).Info("No need to update the status upstream")
+		} else {
+			// every syncer owns its own annotation, hence no need for a resource version precondition
+			patch, err := json.Marshal(map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"annotations": map[string]string{statusAnnotation: string(statusAnnotationValue)},
+				},
+			})
+			if err != nil {
+				return err
+			}
+			updated, err := c.upstreamClient.Resource(gvr).Namespace(upstreamNamespace).Patch(ctx, name, types.MergePatchType, patch, metav1.PatchOptions{})
+			if err != nil {
+				logger.Error(err, "Failed updating location status annotation upstream")
+				return err
+			}
+			logger.Info("Updated location status annotation upstream")
+			existing = updated
 		}
 
-		if _, err := c.upstreamClient.Resource(gvr).Namespace(upstreamNamespace).Update(ctx, newUpstream, metav1.UpdateOptions{}); err != nil {
-			klog.Errorf("Failed updating location status annotation of resource %s|%s/%s from workloadClusterName namespace %s: %v", c.upstreamClusterName, upstreamNamespace, upstreamObj.GetName(), downstreamObj.GetNamespace(), err)
-			return err
+		return c.summarizeStatusInUpstream(ctx, gvr, upstreamNamespace, existing)
+	}
+
+	if patched, err := c.patchStatusInUpstream(ctx, gvr, existing, downstreamStatus, false); err != nil {
+		logger.Error(err, "Failed updating status upstream")
+		return err
+	} else if patched {
+		logger.Info("Updated status upstream")
+	}
+	return nil
+}
+
+// summarizeStatusInUpstream sets the status of the upstream object to the summary of the statuses
+// of all workload clusters it is synced to, as stored in the status annotations. The summary is the same
+// no matter which syncer computes it, and conflicts between syncers are resolved through the resource version.
+//
+// TODO: when a workload cluster is removed, the summary is only updated with the next status change
+// on one of the remaining workload clusters.
+func (c *Controller) summarizeStatusInUpstream(ctx context.Context, gvr schema.GroupVersionResource, upstreamNamespace string, upstreamObj *unstructured.Unstructured) error {
+	logger := logging.FromContext(ctx)
+
+	statuses := map[string]map[string]interface{}{}
+	for key, value := range upstreamObj.GetAnnotations() {
+		if !strings.HasPrefix(key, workloadv1alpha1.InternalClusterStatusAnnotationPrefix) {
+			continue
+		}
+		workloadClusterName := strings.TrimPrefix(key, workloadv1alpha1.InternalClusterStatusAnnotationPrefix)
+		var status map[string]interface{}
+		if err := utiljson.Unmarshal([]byte(value), &status); err != nil {
+			logger.Error(err, "Failed decoding the status of workload cluster", "statusOf", workloadClusterName)
+			continue
 		}
-		klog.Infof("Updated status of resource %s|%s/%s from workloadClusterName namespace %s", c.upstreamClusterName, upstreamNamespace, upstreamObj.GetName(), downstreamObj.GetNamespace())
+		statuses[workloadClusterName] = status
+	}
+
+	summarize, ok := c.summarizers[gvr]
+	if !ok {
+		summarize = summarizers.Default
+	}
+	summary, err := summarize(statuses)
+	if err != nil {
+		return err
+	}
+	if summary == nil {
 		return nil
 	}
 
-	if _, err := c.upstreamClient.Resource(gvr).Namespace(upstreamNamespace).UpdateStatus(ctx, upstreamObj, metav1.UpdateOptions{}); err != nil {
-		klog.Errorf("Failed updating status of resource %q %s|%s/%s from pcluster namespace %s: %v", gvr.String(), c.upstreamClusterName, upstreamNamespace, upstreamObj.GetName(), downstreamObj.GetNamespace(), err)
+	if patched, err := c.patchStatusInUpstream(ctx, gvr, upstreamObj, summary, true); err != nil {
+		logger.Error(err, "Failed updating summarized status upstream")
 		return err
+	} else if patched {
+		logger.Info("Updated summarized status upstream", "workloadClusters", len(statuses))
 	}
-	klog.Infof("Updated status of resource %q %s|%s/%s from pcluster namespace %s", gvr.String(), c.upstreamClusterName, upstreamNamespace, upstreamObj.GetName(), downstreamObj.GetNamespace())
 	return nil
 }
 
+// patchStatusInUpstream changes the status of the upstream object to the given status with a minimal JSON
+// merge patch instead of an update of the whole object, and returns false if the status is up-to-date already.
+// With checkResourceVersion, the patch is rejected with a conflict if the object has changed in the meantime.
+func (c *Controller) patchStatusInUpstream(ctx context.Context, gvr schema.GroupVersionResource, upstreamObj *unstructured.Unstructured, status interface{}, checkResourceVersion bool) (bool, error) {
+	oldData, err := json.Marshal(map[string]interface{}{"status": upstreamObj.UnstructuredContent()["status"]})
+	if err != nil {
+		return false, err
+	}
+	newData, err := json.Marshal(map[string]interface{}{"status": status})
+	if err != nil {
+		return false, err
+	}
+	patch, err := jsonpatch.CreateMergePatch(oldData, newData)
+	if err != nil {
+		return false, err
+	}
+	if string(patch) == "{}" {
+		return false, nil
+	}
+
+	if checkResourceVersion && upstreamObj.GetResourceVersion() != "" {
+		var patchMap map[string]interface{}
+		if err := json.Unmarshal(patch, &patchMap); err != nil {
+			return false, err
+		}
+		patchMap["metadata"] = map[string]interface{}{"resourceVersion": upstreamObj.GetResourceVersion()}
+		if patch, err = json.Marshal(patchMap); err != nil {
+			return false, err
+		}
+	}
+
+	if _, err := c.upstreamClient.Resource(gvr).Namespace(upstreamObj.GetNamespace()).Patch(ctx, upstreamObj.GetName(), types.MergePatchType, patch, metav1.PatchOptions{}, "status"); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
 // TransformName changes the object name into the desired one upstream.
 func transformName(syncedObject *unstructured.Unstructured) {
 	configMapGVR := schema.GroupVersionKind{Group: "", Version: "v1", Kind: "ConfigMap"}
@@ -335,15 +335,7 @@ func TestSyncerProcess(t *testing.T) {
 			expectActionsOnFrom: []clienttesting.Action{},
 			expectActionsOnTo: []clienttesting.Action{
 				getDeploymentAction("theDeployment", "test"),
-				updateDeploymentAction("test",
-					toUnstructured(t, changeDeployment(
-						deployment("theDeployment", "test", "", map[string]string{
-							"state.internal.workloads.kcp.dev/us-west1": "Sync",
-						}, nil, nil),
-						addDeploymentStatus(appsv1.DeploymentStatus{
-							Replicas: 15,
-						}))),
-					"status"),
+				patchDeploymentAction("theDeployment", "test", types.MergePatchType, []byte(`{"status":{"replicas":15}}`), "status"),
 			},
 		},
 		"StatusSyncer upstream deletion": {
@@ -403,16 +395,12 @@ func TestSyncerProcess(t *testing.T) {
 			expectActionsOnFrom: []clienttesting.Action{},
 			expectActionsOnTo: []clienttesting.Action{
 				getDeploymentAction("theDeployment", "test"),
-				updateDeploymentAction("test",
-					toUnstructured(t, changeDeployment(
-						deployment("theDeployment", "test", "root:org:ws", map[string]string{
-							"state.internal.workloads.kcp.dev/us-west1": "Sync",
-						}, map[string]string{
-							"experimental.status.workloads.kcp.dev/us-west1": "{\"replicas\":15}",
-						}, nil)))),
+				patchDeploymentAction("theDeployment", "test", types.MergePatchType,
+					[]byte(`{"metadata":{"annotations":{"experimental.status.workloads.kcp.dev/us-west1":"{\"replicas\":15}"}}}`)),
+				patchDeploymentAction("theDeployment", "test", types.MergePatchType, []byte(`{"status":{"replicas":15}}`), "status"),
 			},
 		},
-		"StatusSyncer with AdvancedScheduling, deletion: object exists upstream": {
+		"StatusSyncer with AdvancedScheduling, summarize status of multiple workload clusters": {
 			upstreamLogicalCluster: "root:org:ws",
 			fromNamespace: namespace("kcp0124d7647eb6a00b1fcb6f2252201601634989dd79deb7375c373973", "",
 				map[string]string{
@@ -431,11 +419,53 @@ func TestSyncerProcess(t *testing.T) {
 				})),
 			toResources: []runtime.Object{
 				deployment("theDeployment", "test", "root:org:ws", map[string]string{
+					"state.internal.workloads.kcp.dev/us-east1": "Sync",
 					"state.internal.workloads.kcp.dev/us-west1": "Sync",
 				}, map[string]string{
-					"deletion.internal.workloads.kcp.dev/us-west1":   time.Now().Format(time.RFC3339),
-					"experimental.status.workloads.kcp.dev/us-west1": "{\"replicas\":15}",
-				}, []string{"workloads.kcp.dev/syncer-us-west1"}),
+					"experimental.status.workloads.kcp.dev/us-east1": "{\"replicas\":10}",
+				}, nil),
+			},
+			resourceToProcessLogicalClusterName: "",
+			resourceToProcessName:               "theDeployment",
+			workloadClusterName:                 "us-west1",
+			advancedSchedulingEnabled:           true,
+
+			expectActionsOnFrom: []clienttesting.Action{},
+			expectActionsOnTo: []clienttesting.Action{
+				getDeploymentAction("theDeployment", "test"),
+				patchDeploymentAction("theDeployment", "test", types.MergePatchType,
+					[]byte(`{"metadata":{"annotations":{"experimental.status.workloads.kcp.dev/us-west1":"{\"replicas\":15}"}}}`)),
+				patchDeploymentAction("theDeployment", "test", types.MergePatchType, []byte(`{"status":{"replicas":25}}`), "status"),
+			},
+		},
+		"StatusSyncer with AdvancedScheduling, deletion: object exists upstream": {
+			upstreamLogicalCluster: "root:org:ws",
+			fromNamespace: namespace("kcp0124d7647eb6a00b1fcb6f2252201601634989dd79deb7375c373973", "",
+				map[string]string{
+					"internal.workloads.kcp.dev/cluster": "us-west1",
+				},
+				map[string]string{
+					"kcp.dev/namespace-locator": `{"logical-cluster":"root:org:ws","namespace":"test"}`,
+				}),
+			gvr: schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"},
+			fromResource: changeDeployment(
+				deployment("theDeployment", "kcp0124d7647eb6a00b1fcb6f2252201601634989dd79deb7375c373973", "", map[string]string{
+					"internal.workloads.kcp.dev/cluster": "us-west1",
+				}, nil, nil),
+				addDeploymentStatus(appsv1.DeploymentStatus{
+					Replicas: 15,
+				})),
+			toResources: []runtime.Object{
+				changeDeployment(
+					deployment("theDeployment", "test", "root:org:ws", map[string]string{
+						"state.internal.workloads.kcp.dev/us-west1": "Sync",
+					}, map[string]string{
+						"deletion.internal.workloads.kcp.dev/us-west1":   time.Now().Format(time.RFC3339),
+						"experimental.status.workloads.kcp.dev/us-west1": "{\"replicas\":15}",
+					}, []string{"workloads.kcp.dev/syncer-us-west1"}),
+					addDeploymentStatus(appsv1.DeploymentStatus{
+						Replicas: 15,
+					})),
 			},
 			resourceToProcessLogicalClusterName: "",
 			resourceToProcessName:               "theDeployment",
@@ -520,7 +550,7 @@ func TestSyncerProcess(t *testing.T) {
 				{Group: "", Version: "v1", Resource: "namespaces"},
 				tc.gvr,
 			}
-			controller, err := NewStatusSyncer(gvrs, kcpLogicalCluster, tc.workloadClusterName, tc.advancedSchedulingEnabled, toClient, fromClient, toInformers, fromInformers)
+			controller, err := NewStatusSyncer(gvrs, kcpLogicalCluster, tc.workloadClusterName, tc.advancedSchedulingEnabled, nil, toClient, fromClient, toInformers, fromInformers)
 			require.NoError(t, err)
 
 			fromInformers.Start(ctx.Done())
@@ -664,3 +694,12 @@ func updateDeploymentAction(namespace string, object runtime.Object, subresource
 		Object:     object,
 	}
 }
+
+func patchDeploymentAction(name, namespace string, patchType types.PatchType, patch []byte, subresources ...string) clienttesting.PatchActionImpl {
+	return clienttesting.PatchActionImpl{
+		ActionImpl: deploymentAction("patch", namespace, subresources...),
+		Name:       name,
+		PatchType:  patchType,
+		Patch:      patch,
+	}
+}
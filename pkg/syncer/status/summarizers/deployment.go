@@ -0,0 +1,82 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package summarizers
+
+import (
+	appsv1 "k8s.io/api/apps/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+type DeploymentSummarizer struct {
+}
+
+func (ds *DeploymentSummarizer) GVR() schema.GroupVersionResource {
+	return schema.GroupVersionResource{
+		Group:    "apps",
+		Version:  "v1",
+		Resource: "deployments",
+	}
+}
+
+func NewDeploymentSummarizer() *DeploymentSummarizer {
+	return &DeploymentSummarizer{}
+}
+
+// Summarize sums up the replica counts of all workload clusters, and takes the minimum of the
+// observed generations and the maximum of the collision counts. Conditions are AND-ed as in Default.
+func (ds *DeploymentSummarizer) Summarize(statuses map[string]map[string]interface{}) (map[string]interface{}, error) {
+	summary, err := Default(statuses)
+	if err != nil || len(statuses) <= 1 {
+		return summary, err
+	}
+
+	var status appsv1.DeploymentStatus
+	for i, cluster := range sortedClusters(statuses) {
+		var clusterStatus appsv1.DeploymentStatus
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(statuses[cluster], &clusterStatus); err != nil {
+			return nil, err
+		}
+
+		status.Replicas += clusterStatus.Replicas
+		status.UpdatedReplicas += clusterStatus.UpdatedReplicas
+		status.ReadyReplicas += clusterStatus.ReadyReplicas
+		status.AvailableReplicas += clusterStatus.AvailableReplicas
+		status.UnavailableReplicas += clusterStatus.UnavailableReplicas
+		if i == 0 || clusterStatus.ObservedGeneration < status.ObservedGeneration {
+			status.ObservedGeneration = clusterStatus.ObservedGeneration
+		}
+		if clusterStatus.CollisionCount != nil && (status.CollisionCount == nil || *clusterStatus.CollisionCount > *status.CollisionCount) {
+			collisionCount := *clusterStatus.CollisionCount
+			status.CollisionCount = &collisionCount
+		}
+	}
+
+	counts, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&status)
+	if err != nil {
+		return nil, err
+	}
+	// keep the merged conditions as they are, and only replace the counts
+	for _, field := range []string{"replicas", "updatedReplicas", "readyReplicas", "availableReplicas", "unavailableReplicas", "observedGeneration", "collisionCount"} {
+		if value, ok := counts[field]; ok {
+			summary[field] = value
+		} else {
+			delete(summary, field)
+		}
+	}
+	return summary, nil
+}
@@ -0,0 +1,166 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package summarizers
+
+import (
+	"fmt"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	conditionStatusTrue    = "True"
+	conditionStatusFalse   = "False"
+	conditionStatusUnknown = "Unknown"
+)
+
+// Default summarizes the statuses of an object synced to multiple workload clusters, keyed by
+// workload cluster name, by taking the status of the first workload cluster in alphabetical
+// order and AND-ing the conditions of all of them.
+func Default(statuses map[string]map[string]interface{}) (map[string]interface{}, error) {
+	clusters := sortedClusters(statuses)
+	if len(clusters) == 0 {
+		return nil, nil
+	}
+	summary := runtime.DeepCopyJSON(statuses[clusters[0]])
+	if len(clusters) == 1 {
+		return summary, nil
+	}
+
+	conditions, err := MergeConditions(statuses)
+	if err != nil {
+		return nil, err
+	}
+	if conditions != nil {
+		summary["conditions"] = conditions
+	} else {
+		delete(summary, "conditions")
+	}
+	return summary, nil
+}
+
+// MergeConditions AND-s the status.conditions of multiple workload clusters, keyed by workload
+// cluster name. A condition is True if it is True on all workload clusters, False if it is False
+// on any, and Unknown otherwise, including when a workload cluster does not report it. Reason and
+// message are taken from the first workload cluster in alphabetical order with the resulting
+// condition status, the message prefixed with its name.
+func MergeConditions(statuses map[string]map[string]interface{}) ([]interface{}, error) {
+	clusters := sortedClusters(statuses)
+
+	var types []string
+	byCluster := map[string]map[string]map[string]interface{}{}
+	for _, cluster := range clusters {
+		byCluster[cluster] = map[string]map[string]interface{}{}
+		conditions, ok := statuses[cluster]["conditions"]
+		if !ok || conditions == nil {
+			continue
+		}
+		list, ok := conditions.([]interface{})
+		if !ok {
+			return nil, fmt.Errorf("status.conditions of workload cluster %s is expected to be a list, but is %T", cluster, conditions)
+		}
+		for _, c := range list {
+			condition, ok := c.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("condition of workload cluster %s is expected to be an object, but is %T", cluster, c)
+			}
+			conditionType, _ := condition["type"].(string)
+			if conditionType == "" {
+				continue
+			}
+			if _, seen := byCluster[cluster][conditionType]; seen {
+				continue
+			}
+			byCluster[cluster][conditionType] = condition
+			if !contains(types, conditionType) {
+				types = append(types, conditionType)
+			}
+		}
+	}
+	if len(types) == 0 {
+		return nil, nil
+	}
+
+	merged := make([]interface{}, 0, len(types))
+	for _, conditionType := range types {
+		status := conditionStatusTrue
+		for _, cluster := range clusters {
+			switch conditionStatus(byCluster[cluster][conditionType]) {
+			case conditionStatusFalse:
+				status = conditionStatusFalse
+			case conditionStatusUnknown:
+				if status == conditionStatusTrue {
+					status = conditionStatusUnknown
+				}
+			}
+		}
+
+		var condition map[string]interface{}
+		for _, cluster := range clusters {
+			c, ok := byCluster[cluster][conditionType]
+			if conditionStatus(c) != status {
+				continue
+			}
+			if !ok {
+				condition = map[string]interface{}{
+					"type":    conditionType,
+					"status":  conditionStatusUnknown,
+					"reason":  "NotReported",
+					"message": cluster + ": condition not reported",
+				}
+				break
+			}
+			condition = runtime.DeepCopyJSONValue(c).(map[string]interface{})
+			condition["status"] = status
+			if message, _ := condition["message"].(string); message != "" && status != conditionStatusTrue {
+				condition["message"] = cluster + ": " + message
+			}
+			break
+		}
+		merged = append(merged, condition)
+	}
+	return merged, nil
+}
+
+// conditionStatus returns the status of the condition, Unknown if missing or invalid.
+func conditionStatus(condition map[string]interface{}) string {
+	switch status, _ := condition["status"].(string); status {
+	case conditionStatusTrue, conditionStatusFalse:
+		return status
+	default:
+		return conditionStatusUnknown
+	}
+}
+
+func sortedClusters(statuses map[string]map[string]interface{}) []string {
+	clusters := make([]string, 0, len(statuses))
+	for cluster := range statuses {
+		clusters = append(clusters, cluster)
+	}
+	sort.Strings(clusters)
+	return clusters
+}
+
+func contains(ss []string, s string) bool {
+	for _, n := range ss {
+		if n == s {
+			return true
+		}
+	}
+	return false
+}
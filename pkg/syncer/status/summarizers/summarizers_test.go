@@ -0,0 +1,132 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package summarizers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	utiljson "k8s.io/apimachinery/pkg/util/json"
+)
+
+func TestDefault(t *testing.T) {
+	tests := map[string]struct {
+		statuses map[string]string
+		want     string
+	}{
+		"no status": {
+			want: "null",
+		},
+		"single workload cluster": {
+			statuses: map[string]string{
+				"us-west1": `{"phase":"Running","conditions":[{"type":"Ready","status":"False","message":"not yet"}]}`,
+			},
+			want: `{"phase":"Running","conditions":[{"type":"Ready","status":"False","message":"not yet"}]}`,
+		},
+		"all conditions true": {
+			statuses: map[string]string{
+				"us-west1": `{"phase":"West","conditions":[{"type":"Ready","status":"True","reason":"West"}]}`,
+				"us-east1": `{"phase":"East","conditions":[{"type":"Ready","status":"True","reason":"East"}]}`,
+			},
+			want: `{"phase":"East","conditions":[{"type":"Ready","status":"True","reason":"East"}]}`,
+		},
+		"one condition false": {
+			statuses: map[string]string{
+				"us-west1": `{"conditions":[{"type":"Ready","status":"False","reason":"Broken","message":"boom"},{"type":"Synced","status":"True"}]}`,
+				"us-east1": `{"conditions":[{"type":"Ready","status":"True","reason":"AllGood"},{"type":"Synced","status":"True"}]}`,
+			},
+			want: `{"conditions":[{"type":"Ready","status":"False","reason":"Broken","message":"us-west1: boom"},{"type":"Synced","status":"True"}]}`,
+		},
+		"false wins over unknown": {
+			statuses: map[string]string{
+				"a": `{"conditions":[{"type":"Ready","status":"Unknown"}]}`,
+				"b": `{"conditions":[{"type":"Ready","status":"False","reason":"Broken"}]}`,
+			},
+			want: `{"conditions":[{"type":"Ready","status":"False","reason":"Broken"}]}`,
+		},
+		"condition missing on one workload cluster": {
+			statuses: map[string]string{
+				"us-west1": `{"conditions":[{"type":"Ready","status":"True"}]}`,
+				"us-east1": `{}`,
+			},
+			want: `{"conditions":[{"type":"Ready","status":"Unknown","reason":"NotReported","message":"us-east1: condition not reported"}]}`,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := Default(decodeStatuses(t, tc.statuses))
+			require.NoError(t, err)
+			requireJSONEqual(t, tc.want, got)
+		})
+	}
+}
+
+func TestDefaultInvalidConditions(t *testing.T) {
+	_, err := Default(decodeStatuses(t, map[string]string{
+		"us-west1": `{"conditions":"Ready"}`,
+		"us-east1": `{}`,
+	}))
+	require.Error(t, err)
+}
+
+func TestDeploymentSummarizer(t *testing.T) {
+	tests := map[string]struct {
+		statuses map[string]string
+		want     string
+	}{
+		"single workload cluster": {
+			statuses: map[string]string{
+				"us-west1": `{"replicas":3,"availableReplicas":2,"observedGeneration":4}`,
+			},
+			want: `{"replicas":3,"availableReplicas":2,"observedGeneration":4}`,
+		},
+		"multiple workload clusters": {
+			statuses: map[string]string{
+				"us-west1": `{"replicas":3,"updatedReplicas":3,"readyReplicas":3,"availableReplicas":3,"observedGeneration":4,"collisionCount":1,
+					"conditions":[{"type":"Available","status":"True","reason":"MinimumReplicasAvailable"}]}`,
+				"us-east1": `{"replicas":2,"updatedReplicas":2,"readyReplicas":1,"availableReplicas":1,"unavailableReplicas":1,"observedGeneration":3,
+					"conditions":[{"type":"Available","status":"False","reason":"MinimumReplicasUnavailable","message":"Deployment does not have minimum availability."}]}`,
+			},
+			want: `{"replicas":5,"updatedReplicas":5,"readyReplicas":4,"availableReplicas":4,"unavailableReplicas":1,"observedGeneration":3,"collisionCount":1,
+				"conditions":[{"type":"Available","status":"False","reason":"MinimumReplicasUnavailable","message":"us-east1: Deployment does not have minimum availability."}]}`,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			got, err := NewDeploymentSummarizer().Summarize(decodeStatuses(t, tc.statuses))
+			require.NoError(t, err)
+			requireJSONEqual(t, tc.want, got)
+		})
+	}
+}
+
+func decodeStatuses(t *testing.T, statuses map[string]string) map[string]map[string]interface{} {
+	decoded := map[string]map[string]interface{}{}
+	for cluster, status := range statuses {
+		var s map[string]interface{}
+		require.NoError(t, utiljson.Unmarshal([]byte(status), &s))
+		decoded[cluster] = s
+	}
+	return decoded
+}
+
+func requireJSONEqual(t *testing.T, expected string, actual map[string]interface{}) {
+	bs, err := utiljson.Marshal(actual)
+	require.NoError(t, err)
+	require.JSONEq(t, expected, string(bs))
+}
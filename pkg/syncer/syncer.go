@@ -24,7 +24,9 @@ import (
 	"time"
 
 	"github.com/kcp-dev/logicalcluster"
+	"go.opentelemetry.io/otel/trace"
 
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
@@ -33,13 +35,23 @@ import (
 	"k8s.io/client-go/discovery"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/dynamic/dynamicinformer"
+	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
+	"k8s.io/component-base/traces"
 	"k8s.io/klog/v2"
 
 	workloadv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/workload/v1alpha1"
 	kcpclient "github.com/kcp-dev/kcp/pkg/client/clientset/versioned"
 	workloadcliplugin "github.com/kcp-dev/kcp/pkg/cliplugins/workload/plugin"
+	"github.com/kcp-dev/kcp/pkg/logging"
+
+	"github.com/kcp-dev/kcp/pkg/syncer/credentials"
+	"github.com/kcp-dev/kcp/pkg/syncer/drift"
+	"github.com/kcp-dev/kcp/pkg/syncer/endpointslices"
+	"github.com/kcp-dev/kcp/pkg/syncer/pruning"
+	"github.com/kcp-dev/kcp/pkg/syncer/shared"
 	"github.com/kcp-dev/kcp/pkg/syncer/spec"
+	specmutators "github.com/kcp-dev/kcp/pkg/syncer/spec/mutators"
 	"github.com/kcp-dev/kcp/pkg/syncer/status"
 )
 
@@ -53,6 +65,12 @@ const (
 
 	// TODO(marun) Ensure backoff rather than using a constant to avoid thundering herds
 	gvrQueryInterval = 1 * time.Second
+
+	// topologyInterval is how often the topology labels of the physical cluster are reported.
+	topologyInterval = 5 * time.Minute
+
+	// tokenRotationInterval is how often the expiry of the token used to talk to kcp is checked.
+	tokenRotationInterval = 1 * time.Minute
 )
 
 // SyncerConfig defines the syncer configuration that is guaranteed to
@@ -64,6 +82,46 @@ type SyncerConfig struct {
 	ResourcesToSync     sets.String
 	KCPClusterName      logicalcluster.Name
 	WorkloadClusterName string
+
+	// OrphanPruningMode defines whether downstream objects without upstream counterpart are
+	// reported or deleted. Empty means pruning.ModeDisabled.
+	OrphanPruningMode     pruning.Mode
+	OrphanPruningInterval time.Duration
+
+	// CapacityReportInterval is how often the capacity and the node counts of the physical cluster
+	// are reported in the WorkloadCluster status. Zero disables reporting.
+	CapacityReportInterval time.Duration
+
+	// DriftDetectionInterval is how often downstream objects are checked for out-of-band changes since
+	// the spec syncer wrote them, which are then repaired by syncing them again. Zero disables detection.
+	DriftDetectionInterval time.Duration
+
+	// FieldPruningPolicy defines the fields of upstream objects which are not synced downstream, and the
+	// maximal size of downstream objects.
+	FieldPruningPolicy spec.FieldPruningPolicy
+
+	// RecordDownstreamMutations enables summarizing how downstream objects differ from their upstream
+	// objects in the experimental.mutations.workloads.kcp.dev/<workload-cluster-name> annotation upstream.
+	RecordDownstreamMutations bool
+
+	// ClusterScopedPolicy defines the cluster-scoped resources which are synced downstream in addition
+	// to ResourcesToSync, and how they are named there.
+	ClusterScopedPolicy shared.ClusterScopedPolicy
+
+	// ServiceDNSPolicy defines whether synced pods and deployments get host aliases for the services of
+	// the workspace, including those placed on other physical clusters.
+	ServiceDNSPolicy specmutators.ServiceDNSPolicy
+
+	// MirrorEndpointSlices enables mirroring the EndpointSlices of the synced services into their upstream
+	// namespaces. The EndpointSlices API is imported into kcp for that purpose.
+	MirrorEndpointSlices bool
+
+	// UpstreamTokenRotation defines how the token in the kubeconfig secret used to talk to kcp is rotated
+	// before it expires. The zero value disables rotation.
+	UpstreamTokenRotation credentials.RotationPolicy
+
+	// TracerProvider traces the requests of the syncer to kcp and the physical cluster, if set.
+	TracerProvider trace.TracerProvider
 }
 
 func (sc *SyncerConfig) ID() string {
@@ -71,12 +129,24 @@ func (sc *SyncerConfig) ID() string {
 }
 
 func StartSyncer(ctx context.Context, cfg *SyncerConfig, numSyncerThreads int, importPollInterval time.Duration) error {
-	klog.Infof("Starting syncer for logical-cluster: %s, workload-cluster: %s", cfg.KCPClusterName, cfg.WorkloadClusterName)
+	logger := logging.WithSyncTarget(logging.WithWorkspace(logging.NewLogger("kcp-workload-syncer"), cfg.KCPClusterName), cfg.WorkloadClusterName)
+	ctx = logging.NewContext(ctx, logger)
+	logger.Info("Starting syncer")
+
+	if err := cfg.ClusterScopedPolicy.Validate(); err != nil {
+		return err
+	}
+
+	shared.RegisterMetrics()
 
 	upstreamConfig := rest.CopyConfig(cfg.UpstreamConfig)
 	upstreamConfig.UserAgent = "kcp#spec-syncer/v0.0.0"
 	downstreamConfig := rest.CopyConfig(cfg.DownstreamConfig)
 	downstreamConfig.UserAgent = "kcp#status-syncer/v0.0.0"
+	if cfg.TracerProvider != nil {
+		upstreamConfig.Wrap(traces.WrapperFor(&cfg.TracerProvider))
+		downstreamConfig.Wrap(traces.WrapperFor(&cfg.TracerProvider))
+	}
 
 	kcpClusterClient, err := kcpclient.NewClusterForConfig(upstreamConfig)
 	if err != nil {
@@ -90,11 +160,26 @@ func StartSyncer(ctx context.Context, cfg *SyncerConfig, numSyncerThreads int, i
 	if err != nil {
 		return err
 	}
+	downstreamKubeClient, err := kubernetes.NewForConfig(downstreamConfig)
+	if err != nil {
+		return err
+	}
 	upstreamDiscoveryClient, err := discovery.NewDiscoveryClientForConfig(cfg.UpstreamConfig)
 	if err != nil {
 		return err
 	}
 
+	// Rotate the token first, independently of the syncer coming up, such that it does not expire
+	// while the syncer is blocked.
+	if cfg.UpstreamTokenRotation.Enabled() {
+		upstreamKubeClient, err := kubernetes.NewClusterForConfig(upstreamConfig)
+		if err != nil {
+			return err
+		}
+		rotator := credentials.NewRotator(cfg.UpstreamTokenRotation, cfg.WorkloadClusterName, upstreamKubeClient.Cluster(cfg.KCPClusterName), downstreamKubeClient)
+		go rotator.Start(ctx, tokenRotationInterval)
+	}
+
 	upstreamInformers := dynamicinformer.NewFilteredDynamicSharedInformerFactory(upstreamDynamicClient.Cluster(cfg.KCPClusterName), resyncPeriod, metav1.NamespaceAll, func(o *metav1.ListOptions) {
 		o.LabelSelector = workloadv1alpha1.InternalClusterResourceStateLabelPrefix + cfg.WorkloadClusterName + "=" + string(workloadv1alpha1.ResourceStateSync)
 	})
@@ -105,12 +190,16 @@ func StartSyncer(ctx context.Context, cfg *SyncerConfig, numSyncerThreads int, i
 	// Resources are accepted as a set to ensure the provision of a
 	// unique set of resources, but all subsequent consumption is via
 	// slice whose entries are assumed to be unique.
-	resources := cfg.ResourcesToSync.List()
+	resources := cfg.ResourcesToSync.Union(cfg.ClusterScopedPolicy.GroupResources).List()
 
 	// Start api import first because spec and status syncers are blocked by
 	// gvr discovery finding all the configured resource types in the kcp
 	// workspace.
-	apiImporter, err := NewAPIImporter(cfg.UpstreamConfig, cfg.DownstreamConfig, resources, cfg.KCPClusterName, cfg.WorkloadClusterName)
+	importedResources := resources
+	if cfg.MirrorEndpointSlices {
+		importedResources = append(importedResources, endpointslices.Resource.GroupResource().String())
+	}
+	apiImporter, err := NewAPIImporter(cfg.UpstreamConfig, cfg.DownstreamConfig, importedResources, cfg.KCPClusterName, cfg.WorkloadClusterName)
 	if err != nil {
 		return err
 	}
@@ -125,15 +214,15 @@ func StartSyncer(ctx context.Context, cfg *SyncerConfig, numSyncerThreads int, i
 	// syncers depend on the types being present to start their informers.
 	var gvrs []schema.GroupVersionResource
 	err = wait.PollImmediateInfinite(gvrQueryInterval, func() (bool, error) {
-		klog.Infof("Attempting to retrieve GVRs from upstream clusterName %s (for pcluster %s)", cfg.KCPClusterName, cfg.WorkloadClusterName)
+		logger.Info("Attempting to retrieve GVRs from upstream")
 
 		var err error
 		// Get all types the upstream API server knows about.
 		// TODO: watch this and learn about new types, or forget about old ones.
-		gvrs, err = getAllGVRs(upstreamDiscoveryClient.WithCluster(cfg.KCPClusterName), resources...)
+		gvrs, err = getAllGVRs(upstreamDiscoveryClient.WithCluster(cfg.KCPClusterName), cfg.ClusterScopedPolicy, resources...)
 		// TODO(marun) Should some of these errors be fatal?
 		if err != nil {
-			klog.Errorf("Failed to retrieve GVRs from kcp: %v", err)
+			logger.Error(err, "Failed to retrieve GVRs from kcp")
 			return false, nil
 		}
 		return true, nil
@@ -150,36 +239,94 @@ func StartSyncer(ctx context.Context, cfg *SyncerConfig, numSyncerThreads int, i
 	}
 	advancedSchedulingEnabled := false
 	if workloadCluster.GetAnnotations()[advancedSchedulingFeatureAnnotation] == "true" {
-		klog.Infof("Advanced Scheduling feature is enabled for workloadCluster %s", cfg.WorkloadClusterName)
+		logger.Info("Advanced Scheduling feature is enabled")
 		advancedSchedulingEnabled = true
 	}
+	namespaceNamer, err := shared.NamespaceNamerFromAnnotations(workloadCluster.GetAnnotations())
+	if err != nil {
+		return fmt.Errorf("invalid namespace naming of WorkloadCluster %s|%s: %w", cfg.KCPClusterName, cfg.WorkloadClusterName, err)
+	}
+	// the paused resources are updated with every heartbeat
+	syncPause := shared.NewSyncPause()
+	if err := syncPause.UpdateFromAnnotations(workloadCluster.GetAnnotations()); err != nil {
+		logger.Error(err, "Failed to pause resources")
+	}
 
-	klog.Infof("Creating spec syncer for clusterName %s to pcluster %s, resources %v", cfg.KCPClusterName, cfg.WorkloadClusterName, resources)
+	// The host aliases cover all services of the workspace, not only those synced to this physical cluster.
+	var serviceDNSInformers dynamicinformer.DynamicSharedInformerFactory
+	var serviceDNSMutator *specmutators.ServiceDNSMutator
+	if cfg.ServiceDNSPolicy.Enabled {
+		servicesGVR := corev1.SchemeGroupVersion.WithResource("services")
+		serviceDNSInformers = dynamicinformer.NewDynamicSharedInformerFactory(upstreamDynamicClient.Cluster(cfg.KCPClusterName), resyncPeriod)
+		serviceDNSMutator = specmutators.NewServiceDNSMutator(serviceDNSInformers.ForResource(servicesGVR).Informer(), downstreamInformers.ForResource(servicesGVR).Informer(),
+			cfg.KCPClusterName, cfg.WorkloadClusterName, namespaceNamer, cfg.ServiceDNSPolicy.ClusterDomain)
+	}
+
+	logger.Info("Creating spec syncer", "resources", resources)
 	upstreamURL, err := url.Parse(cfg.UpstreamConfig.Host)
 	if err != nil {
 		return err
 	}
-	specSyncer, err := spec.NewSpecSyncer(gvrs, cfg.KCPClusterName, cfg.WorkloadClusterName, upstreamURL, advancedSchedulingEnabled,
-		upstreamDynamicClient.Cluster(cfg.KCPClusterName), downstreamDynamicClient, upstreamInformers, downstreamInformers)
+	var driftStore *drift.Store
+	if cfg.DriftDetectionInterval > 0 {
+		driftStore = drift.NewStore()
+	}
+	specSyncer, err := spec.NewSpecSyncer(gvrs, cfg.KCPClusterName, cfg.WorkloadClusterName, upstreamURL, upstreamDynamicClient.Cluster(cfg.KCPClusterName), downstreamDynamicClient, upstreamInformers, downstreamInformers, spec.Options{
+		AdvancedSchedulingEnabled: advancedSchedulingEnabled,
+		NamespaceNamer:            namespaceNamer,
+		FieldPruningPolicy:        cfg.FieldPruningPolicy,
+		ClusterScopedPolicy:       cfg.ClusterScopedPolicy,
+		SyncPause:                 syncPause,
+		ServiceDNSMutator:         serviceDNSMutator,
+		RecordMutations:           cfg.RecordDownstreamMutations,
+		DriftStore:                driftStore,
+	})
 	if err != nil {
 		return err
 	}
 
-	klog.Infof("Creating status syncer for clusterName %s from pcluster %s, resources %v", cfg.KCPClusterName, cfg.WorkloadClusterName, resources)
-	statusSyncer, err := status.NewStatusSyncer(gvrs, cfg.KCPClusterName, cfg.WorkloadClusterName, advancedSchedulingEnabled,
+	logger.Info("Creating status syncer", "resources", resources)
+	statusSyncer, err := status.NewStatusSyncer(gvrs, cfg.KCPClusterName, cfg.WorkloadClusterName, advancedSchedulingEnabled, syncPause,
 		upstreamDynamicClient.Cluster(cfg.KCPClusterName), downstreamDynamicClient, upstreamInformers, downstreamInformers)
 	if err != nil {
 		return err
 	}
 
+	orphanPruningMode := cfg.OrphanPruningMode
+	if orphanPruningMode == "" {
+		orphanPruningMode = pruning.ModeDisabled
+	}
+	orphanPruner, err := pruning.NewOrphanPruner(gvrs, cfg.KCPClusterName, cfg.WorkloadClusterName, orphanPruningMode, cfg.ClusterScopedPolicy, syncPause,
+		downstreamDynamicClient, upstreamInformers, downstreamInformers)
+	if err != nil {
+		return err
+	}
+
+	driftDetector := drift.NewDriftDetector(gvrs, cfg.WorkloadClusterName, driftStore, syncPause, specSyncer.AddToQueue, upstreamInformers, downstreamInformers)
+
 	upstreamInformers.Start(ctx.Done())
 	downstreamInformers.Start(ctx.Done())
 
 	upstreamInformers.WaitForCacheSync(ctx.Done())
 	downstreamInformers.WaitForCacheSync(ctx.Done())
+	if serviceDNSInformers != nil {
+		serviceDNSInformers.Start(ctx.Done())
+		serviceDNSInformers.WaitForCacheSync(ctx.Done())
+	}
 
 	go specSyncer.Start(ctx, numSyncerThreads)
 	go statusSyncer.Start(ctx, numSyncerThreads)
+	go orphanPruner.Start(ctx, cfg.OrphanPruningInterval)
+	go driftDetector.Start(ctx, cfg.DriftDetectionInterval)
+
+	if cfg.MirrorEndpointSlices {
+		upstreamKubeClient, err := kubernetes.NewClusterForConfig(upstreamConfig)
+		if err != nil {
+			return err
+		}
+		endpointSliceMirror := endpointslices.NewEndpointSliceMirror(cfg.KCPClusterName, cfg.WorkloadClusterName, upstreamKubeClient.Cluster(cfg.KCPClusterName), downstreamKubeClient, resyncPeriod)
+		go endpointSliceMirror.Start(ctx, numSyncerThreads)
+	}
 
 	// Attempt to heartbeat every interval
 	go wait.UntilWithContext(ctx, func(ctx context.Context) {
@@ -193,17 +340,40 @@ func StartSyncer(ctx context.Context, cfg *SyncerConfig, numSyncerThreads int, i
 			patchBytes := []byte(fmt.Sprintf(`[{"op":"replace","path":"/status/lastSyncerHeartbeatTime","value":%q}]`, time.Now().Format(time.RFC3339)))
 			workloadCluster, err := kcpClusterClient.Cluster(cfg.KCPClusterName).WorkloadV1alpha1().WorkloadClusters().Patch(ctx, cfg.WorkloadClusterName, types.JSONPatchType, patchBytes, metav1.PatchOptions{}, "status")
 			if err != nil {
-				klog.Errorf("failed to set status.lastSyncerHeartbeatTime for WorkloadCluster %s|%s: %v", cfg.KCPClusterName, cfg.WorkloadClusterName, err)
+				logger.Error(err, "Failed to set status.lastSyncerHeartbeatTime")
 				return false, nil
 			}
 			heartbeatTime = workloadCluster.Status.LastSyncerHeartbeatTime.Time
+			lastHeartbeats.Store(cfg.ID(), time.Now())
+			shared.ObserveHeartbeat(cfg.WorkloadClusterName, heartbeatTime)
+			if err := syncPause.UpdateFromAnnotations(workloadCluster.GetAnnotations()); err != nil {
+				logger.Error(err, "Failed to update the paused resources")
+			}
 			return true, nil
 		})
 
-		klog.V(5).Infof("Heartbeat set for WorkloadCluster %s|%s: %s", cfg.KCPClusterName, cfg.WorkloadClusterName, heartbeatTime)
+		logger.V(5).Info("Heartbeat set", "heartbeatTime", heartbeatTime)
 
 	}, heartbeatInterval)
 
+	// Report the region and zone of the physical cluster, used to aggregate workload clusters into locations.
+	go wait.UntilWithContext(ctx, func(ctx context.Context) {
+		workloadClusters := kcpClusterClient.Cluster(cfg.KCPClusterName).WorkloadV1alpha1().WorkloadClusters()
+		if err := reportTopology(ctx, downstreamKubeClient, workloadClusters, cfg.WorkloadClusterName); err != nil {
+			logger.Error(err, "Failed to report topology")
+		}
+	}, topologyInterval)
+
+	// Report the aggregated capacity of the nodes of the physical cluster, used by capacity-aware placement.
+	if cfg.CapacityReportInterval > 0 {
+		go wait.UntilWithContext(ctx, func(ctx context.Context) {
+			workloadClusters := kcpClusterClient.Cluster(cfg.KCPClusterName).WorkloadV1alpha1().WorkloadClusters()
+			if err := reportCapacity(ctx, downstreamKubeClient, workloadClusters, cfg.WorkloadClusterName); err != nil {
+				logger.Error(err, "Failed to report capacity")
+			}
+		}, cfg.CapacityReportInterval)
+	}
+
 	return nil
 }
 
@@ -216,7 +386,7 @@ func contains(ss []string, s string) bool {
 	return false
 }
 
-func getAllGVRs(discoveryClient discovery.DiscoveryInterface, resourcesToSync ...string) ([]schema.GroupVersionResource, error) {
+func getAllGVRs(discoveryClient discovery.DiscoveryInterface, clusterScopedPolicy shared.ClusterScopedPolicy, resourcesToSync ...string) ([]schema.GroupVersionResource, error) {
 	toSyncSet := sets.NewString(resourcesToSync...)
 	willBeSyncedSet := sets.NewString()
 	rs, err := discoveryClient.ServerPreferredResources()
@@ -235,14 +405,14 @@ func getAllGVRs(discoveryClient discovery.DiscoveryInterface, resourcesToSync ..
 	}
 	// TODO(jmprusi): Added ServiceAccounts, Configmaps and Secrets to the default syncing, but we should figure out
 	//                a way to avoid doing that: https://github.com/kcp-dev/kcp/issues/727
-	gvrstrs := sets.NewString("namespaces.v1.", "serviceaccounts.v1.", "configmaps.v1.", "secrets.v1.") // A syncer should always watch namespaces, serviceaccounts, secrets and configmaps.
+	gvrstrs := sets.NewString("namespaces.v1.", "serviceaccounts.v1.", "configmaps.v1.", "secrets.v1.", "limitranges.v1.") // A syncer should always watch namespaces, serviceaccounts, secrets, configmaps and limitranges.
 	for _, r := range rs {
 		// v1 -> v1.
 		// apps/v1 -> v1.apps
 		// tekton.dev/v1beta1 -> v1beta1.tekton.dev
 		groupVersion, err := schema.ParseGroupVersion(r.GroupVersion)
 		if err != nil {
-			klog.Warningf("Unable to parse GroupVersion %s : %v", r.GroupVersion, err)
+			klog.ErrorS(err, "Unable to parse GroupVersion", "groupVersion", r.GroupVersion)
 			continue
 		}
 		vr := groupVersion.Version + "." + groupVersion.Group
@@ -265,12 +435,12 @@ func getAllGVRs(discoveryClient discovery.DiscoveryInterface, resourcesToSync ..
 				// foo/status, pods/exec, namespace/finalize, etc.
 				continue
 			}
-			if !ai.Namespaced {
-				// Ignore cluster-scoped things.
+			if !ai.Namespaced && !clusterScopedPolicy.Has(groupVersion.WithResource(ai.Name)) {
+				// Ignore cluster-scoped things which are not allow-listed.
 				continue
 			}
 			if !contains(ai.Verbs, "watch") {
-				klog.Infof("resource %s %s is not watchable: %v", vr, ai.Name, ai.Verbs)
+				klog.InfoS("Resource is not watchable", "groupVersion", vr, "resource", ai.Name, "verbs", ai.Verbs)
 				continue
 			}
 			gvrstrs.Insert(fmt.Sprintf("%s.%s", ai.Name, vr))
@@ -290,7 +460,7 @@ func getAllGVRs(discoveryClient discovery.DiscoveryInterface, resourcesToSync ..
 	for _, gvrstr := range gvrstrs.List() {
 		gvr, _ := schema.ParseResourceArg(gvrstr)
 		if gvr == nil {
-			klog.Warningf("Unable to parse resource %q as <resource>.<version>.<group>", gvrstr)
+			klog.InfoS("Unable to parse resource as <resource>.<version>.<group>", "resource", gvrstr)
 			continue
 		}
 		gvrs = append(gvrs, *gvr)
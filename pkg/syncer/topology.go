@@ -0,0 +1,93 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package syncer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+
+	workloadclient "github.com/kcp-dev/kcp/pkg/client/clientset/versioned/typed/workload/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/logging"
+)
+
+// topologyLabelKeys are the node labels reported as topology labels of the physical cluster.
+var topologyLabelKeys = []string{corev1.LabelTopologyRegion, corev1.LabelTopologyZone}
+
+// topologyLabels returns the topology labels with the same value on all the given nodes.
+func topologyLabels(nodes []corev1.Node) map[string]string {
+	labels := map[string]string{}
+	if len(nodes) == 0 {
+		return labels
+	}
+
+	for _, key := range topologyLabelKeys {
+		value, found := nodes[0].Labels[key]
+		if !found || value == "" {
+			continue
+		}
+		common := true
+		for _, node := range nodes[1:] {
+			if node.Labels[key] != value {
+				common = false
+				break
+			}
+		}
+		if common {
+			labels[key] = value
+		}
+	}
+	return labels
+}
+
+// reportTopology sets status.topologyLabels of the given workload cluster to the topology
+// labels common to all nodes of the physical cluster, if they changed.
+func reportTopology(ctx context.Context, downstreamKubeClient kubernetes.Interface, workloadClusters workloadclient.WorkloadClusterInterface, workloadClusterName string) error {
+	nodes, err := downstreamKubeClient.CoreV1().Nodes().List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to list nodes of the physical cluster: %w", err)
+	}
+	labels := topologyLabels(nodes.Items)
+
+	workloadCluster, err := workloadClusters.Get(ctx, workloadClusterName, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	if len(labels) == 0 && len(workloadCluster.Status.TopologyLabels) == 0 {
+		return nil
+	}
+	if reflect.DeepEqual(labels, workloadCluster.Status.TopologyLabels) {
+		return nil
+	}
+
+	value, err := json.Marshal(labels)
+	if err != nil {
+		return err
+	}
+	patchBytes := []byte(fmt.Sprintf(`[{"op":"add","path":"/status/topologyLabels","value":%s}]`, value))
+	if _, err := workloadClusters.Patch(ctx, workloadClusterName, types.JSONPatchType, patchBytes, metav1.PatchOptions{}, "status"); err != nil {
+		return fmt.Errorf("failed to set status.topologyLabels of WorkloadCluster %s: %w", workloadClusterName, err)
+	}
+	logging.FromContext(ctx).V(2).Info("Reported topology labels", "labels", labels)
+	return nil
+}
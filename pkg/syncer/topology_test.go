@@ -0,0 +1,142 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package syncer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+
+	workloadv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/workload/v1alpha1"
+	kcpfake "github.com/kcp-dev/kcp/pkg/client/clientset/versioned/fake"
+)
+
+func node(name string, labels map[string]string) corev1.Node {
+	return corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels}}
+}
+
+func TestTopologyLabels(t *testing.T) {
+	tests := map[string]struct {
+		nodes []corev1.Node
+		want  map[string]string
+	}{
+		"no nodes": {
+			want: map[string]string{},
+		},
+		"single zone": {
+			nodes: []corev1.Node{
+				node("a", map[string]string{"topology.kubernetes.io/region": "us-east1", "topology.kubernetes.io/zone": "us-east1-b", "foo": "bar"}),
+				node("b", map[string]string{"topology.kubernetes.io/region": "us-east1", "topology.kubernetes.io/zone": "us-east1-b"}),
+			},
+			want: map[string]string{"topology.kubernetes.io/region": "us-east1", "topology.kubernetes.io/zone": "us-east1-b"},
+		},
+		"multiple zones": {
+			nodes: []corev1.Node{
+				node("a", map[string]string{"topology.kubernetes.io/region": "us-east1", "topology.kubernetes.io/zone": "us-east1-b"}),
+				node("b", map[string]string{"topology.kubernetes.io/region": "us-east1", "topology.kubernetes.io/zone": "us-east1-c"}),
+			},
+			want: map[string]string{"topology.kubernetes.io/region": "us-east1"},
+		},
+		"unlabelled node": {
+			nodes: []corev1.Node{
+				node("a", map[string]string{"topology.kubernetes.io/region": "us-east1"}),
+				node("b", nil),
+			},
+			want: map[string]string{},
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, tc.want, topologyLabels(tc.nodes))
+		})
+	}
+}
+
+func TestReportTopology(t *testing.T) {
+	tests := map[string]struct {
+		nodes          []corev1.Node
+		reportedLabels map[string]string
+		wantPatch      bool
+		wantLabels     map[string]string
+	}{
+		"labels are reported": {
+			nodes: []corev1.Node{
+				node("a", map[string]string{"topology.kubernetes.io/region": "us-east1"}),
+			},
+			wantPatch:  true,
+			wantLabels: map[string]string{"topology.kubernetes.io/region": "us-east1"},
+		},
+		"unchanged labels are not patched": {
+			nodes: []corev1.Node{
+				node("a", map[string]string{"topology.kubernetes.io/region": "us-east1"}),
+			},
+			reportedLabels: map[string]string{"topology.kubernetes.io/region": "us-east1"},
+			wantLabels:     map[string]string{"topology.kubernetes.io/region": "us-east1"},
+		},
+		"removed labels are cleared": {
+			nodes: []corev1.Node{
+				node("a", nil),
+			},
+			reportedLabels: map[string]string{"topology.kubernetes.io/region": "us-east1"},
+			wantPatch:      true,
+			wantLabels:     map[string]string{},
+		},
+		"no labels": {
+			nodes: []corev1.Node{
+				node("a", nil),
+			},
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			var objects []runtime.Object
+			for i := range tc.nodes {
+				objects = append(objects, &tc.nodes[i])
+			}
+			kubeClient := kubefake.NewSimpleClientset(objects...)
+			kcpClient := kcpfake.NewSimpleClientset(&workloadv1alpha1.WorkloadCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "us-east1"},
+				Status:     workloadv1alpha1.WorkloadClusterStatus{TopologyLabels: tc.reportedLabels},
+			})
+
+			err := reportTopology(context.Background(), kubeClient, kcpClient.WorkloadV1alpha1().WorkloadClusters(), "us-east1")
+			require.NoError(t, err)
+
+			patched := false
+			for _, action := range kcpClient.Actions() {
+				if action.GetVerb() == "patch" {
+					patched = true
+				}
+			}
+			require.Equal(t, tc.wantPatch, patched)
+
+			got, err := kcpClient.WorkloadV1alpha1().WorkloadClusters().Get(context.Background(), "us-east1", metav1.GetOptions{})
+			require.NoError(t, err)
+			if len(tc.wantLabels) == 0 {
+				require.Empty(t, got.Status.TopologyLabels)
+			} else {
+				require.Equal(t, tc.wantLabels, got.Status.TopologyLabels)
+			}
+		})
+	}
+}
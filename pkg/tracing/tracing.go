@@ -0,0 +1,109 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package tracing provides the OpenTelemetry tracing shared by the kcp components, i.e. the
+// workspace aware span attributes and the tracer providers of components that are not
+// generic apiservers, like the front proxy and the syncer.
+package tracing
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kcp-dev/logicalcluster"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlpgrpc"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/semconv"
+	"go.opentelemetry.io/otel/trace"
+
+	apiservertracing "k8s.io/apiserver/pkg/tracing"
+	"k8s.io/component-base/traces"
+)
+
+const (
+	// LogicalClusterKey is the span attribute holding the logical cluster of a request, i.e.
+	// the workspace path like root:org:ws, or * for requests across workspaces.
+	LogicalClusterKey = attribute.Key("kcp.logical_cluster")
+
+	// WorkspaceKey is the span attribute holding the name of the workspace of a request,
+	// i.e. the last segment of the logical cluster.
+	WorkspaceKey = attribute.Key("kcp.workspace")
+
+	// ParentWorkspaceKey is the span attribute holding the path of the parent workspace of
+	// the workspace of a request.
+	ParentWorkspaceKey = attribute.Key("kcp.workspace.parent")
+)
+
+// LogicalClusterAttributes returns the span attributes describing the given logical cluster.
+func LogicalClusterAttributes(clusterName logicalcluster.Name) []attribute.KeyValue {
+	if clusterName.Empty() {
+		return nil
+	}
+	attrs := []attribute.KeyValue{LogicalClusterKey.String(clusterName.String())}
+	if clusterName == logicalcluster.Wildcard {
+		return attrs
+	}
+	attrs = append(attrs, WorkspaceKey.String(clusterName.Base()))
+	if parent, ok := clusterName.Parent(); ok {
+		attrs = append(attrs, ParentWorkspaceKey.String(parent.String()))
+	}
+	return attrs
+}
+
+// AnnotateSpan adds the attributes of the given logical cluster to the span of the context, if any.
+func AnnotateSpan(ctx context.Context, clusterName logicalcluster.Name) {
+	span := trace.SpanFromContext(ctx)
+	if !span.IsRecording() {
+		return
+	}
+	span.SetAttributes(LogicalClusterAttributes(clusterName)...)
+}
+
+// StartSpan starts a span with the tracer of the given name from the global tracer provider, annotated
+// with the given logical cluster. The caller must end the span.
+func StartSpan(ctx context.Context, tracerName, spanName string, clusterName logicalcluster.Name, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	attrs = append(attrs, LogicalClusterAttributes(clusterName)...)
+	return otel.Tracer(tracerName).Start(ctx, spanName, trace.WithAttributes(attrs...))
+}
+
+// NewProvider returns a tracer provider exporting the spans of the given service through OTLP, as
+// configured in the given file. The file has the format of --tracing-config-file of kube-apiserver.
+func NewProvider(ctx context.Context, configFile, serviceName string) (trace.TracerProvider, error) {
+	config, err := apiservertracing.ReadTracingConfiguration(configFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tracing config: %w", err)
+	}
+	if errs := apiservertracing.ValidateTracingConfiguration(config); len(errs) > 0 {
+		return nil, fmt.Errorf("failed to validate tracing configuration: %w", errs.ToAggregate())
+	}
+
+	var opts []otlpgrpc.Option
+	if config.Endpoint != nil {
+		opts = append(opts, otlpgrpc.WithEndpoint(*config.Endpoint))
+	}
+	sampler := sdktrace.NeverSample()
+	if config.SamplingRatePerMillion != nil && *config.SamplingRatePerMillion > 0 {
+		sampler = sdktrace.TraceIDRatioBased(float64(*config.SamplingRatePerMillion) / float64(1000000))
+	}
+	resourceOpts := []resource.Option{
+		resource.WithAttributes(semconv.ServiceNameKey.String(serviceName)),
+	}
+
+	return traces.NewProvider(ctx, sampler, resourceOpts, opts...), nil
+}
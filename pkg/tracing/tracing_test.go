@@ -0,0 +1,60 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracing
+
+import (
+	"testing"
+
+	"github.com/kcp-dev/logicalcluster"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+func TestLogicalClusterAttributes(t *testing.T) {
+	tests := map[string]struct {
+		clusterName logicalcluster.Name
+		want        []attribute.KeyValue
+	}{
+		"empty": {},
+		"root": {
+			clusterName: logicalcluster.New("root"),
+			want: []attribute.KeyValue{
+				LogicalClusterKey.String("root"),
+				WorkspaceKey.String("root"),
+			},
+		},
+		"nested": {
+			clusterName: logicalcluster.New("root:org:team"),
+			want: []attribute.KeyValue{
+				LogicalClusterKey.String("root:org:team"),
+				WorkspaceKey.String("team"),
+				ParentWorkspaceKey.String("root:org"),
+			},
+		},
+		"wildcard": {
+			clusterName: logicalcluster.Wildcard,
+			want: []attribute.KeyValue{
+				LogicalClusterKey.String("*"),
+			},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, tt.want, LogicalClusterAttributes(tt.clusterName))
+		})
+	}
+}
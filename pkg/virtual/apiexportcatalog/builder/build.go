@@ -0,0 +1,98 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package builder
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/kcp-dev/logicalcluster"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apiserver/pkg/registry/rest"
+	genericapiserver "k8s.io/apiserver/pkg/server"
+	rbacinformers "k8s.io/client-go/informers/rbac/v1"
+	"k8s.io/client-go/kubernetes"
+
+	apisv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apis/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/authorization/delegated"
+	apisinformers "github.com/kcp-dev/kcp/pkg/client/informers/externalversions/apis/v1alpha1"
+	kcpopenapi "github.com/kcp-dev/kcp/pkg/openapi"
+	"github.com/kcp-dev/kcp/pkg/virtual/apiexportcatalog/registry"
+	"github.com/kcp-dev/kcp/pkg/virtual/framework"
+	frameworkauthorization "github.com/kcp-dev/kcp/pkg/virtual/framework/authorization"
+	"github.com/kcp-dev/kcp/pkg/virtual/framework/fixedgvs"
+)
+
+const APIExportCatalogVirtualWorkspaceName string = "apiexportcatalog"
+
+// BuildVirtualWorkspace builds a virtual workspace serving, under <rootPathPrefix>/<workspace>,
+// the APIExports that can be bound to from <workspace>.
+func BuildVirtualWorkspace(rootPathPrefix string, wildcardAPIExports apisinformers.APIExportInformer, wildcardsRbacInformers rbacinformers.Interface, kubeClusterClient kubernetes.ClusterInterface) framework.VirtualWorkspace {
+	if !strings.HasSuffix(rootPathPrefix, "/") {
+		rootPathPrefix += "/"
+	}
+
+	return &fixedgvs.FixedGroupVersionsVirtualWorkspace{
+		Name: APIExportCatalogVirtualWorkspaceName,
+		Ready: func() error {
+			if !wildcardAPIExports.Informer().HasSynced() {
+				return errors.New("APIExport informer is not synced")
+			}
+			return nil
+		},
+		RootPathResolver: func(urlPath string, requestContext context.Context) (accepted bool, prefixToStrip string, completedContext context.Context) {
+			completedContext = requestContext
+			if path := urlPath; strings.HasPrefix(path, rootPathPrefix) {
+				path = strings.TrimPrefix(path, rootPathPrefix)
+				segments := strings.SplitN(path, "/", 2)
+				consumer := logicalcluster.New(segments[0])
+				if _, hasParent := consumer.Parent(); !hasParent {
+					return
+				}
+
+				return true, rootPathPrefix + segments[0],
+					context.WithValue(requestContext, registry.ConsumerClusterKey, consumer)
+			}
+			return
+		},
+		GroupVersionAPISets: []fixedgvs.GroupVersionAPISet{
+			{
+				GroupVersion:       apisv1alpha1.SchemeGroupVersion,
+				AddToScheme:        apisv1alpha1.AddToScheme,
+				OpenAPIDefinitions: kcpopenapi.GetOpenAPIDefinitions,
+				BootstrapRestResources: func(mainConfig genericapiserver.CompletedConfig) (map[string]fixedgvs.RestStorageBuilder, error) {
+					delegatedAuthzCache := frameworkauthorization.NewDelegatedAuthorizerCache(frameworkauthorization.DefaultDelegatedAuthorizerCacheTTL, wildcardsRbacInformers)
+
+					apiExportsRest := registry.NewREST(
+						func() ([]*apisv1alpha1.APIExport, error) {
+							return wildcardAPIExports.Lister().List(labels.Everything())
+						},
+						kubeClusterClient,
+						delegatedAuthzCache.Wrap(delegated.NewDelegatedAuthorizer),
+					)
+					return map[string]fixedgvs.RestStorageBuilder{
+						"apiexports": func(apiGroupAPIServerConfig genericapiserver.CompletedConfig) (rest.Storage, error) {
+							return apiExportsRest, nil
+						},
+					}, nil
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,33 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package apiexportcatalog and its sub-packages provide the APIExport Catalog Virtual Workspace.
+//
+// It exposes an APIserver URL for each workspace, serving a read-only list of the APIExports in the
+// sibling workspaces that the user is allowed to bind to from that workspace, with the display metadata
+// in spec.catalog, e.g. for UIs helping users to find APIs.
+//
+// It combines and integrates:
+//
+// - a FixedGroupVersionsVirtualWorkspace instantiation serving the apiexports resource of the
+// apis.kcp.dev/v1alpha1 group on a workspace-dedicated path (in the ../framework/fixedgvs package)
+//
+// - a REST storage implementation listing the APIExports from a wildcard informer, filtered by the
+// bind permission of the user (in the registry package)
+//
+// The builder package is the place where all these components are combined together, especially in the
+// BuildVirtualWorkspace() function.
+package apiexportcatalog
@@ -0,0 +1,94 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package printers
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	kprinters "k8s.io/kubernetes/pkg/printers"
+
+	apisv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apis/v1alpha1"
+)
+
+func AddAPIExportPrintHandlers(h kprinters.PrintHandler) {
+	apiExportColumnDefinitions := []metav1.TableColumnDefinition{
+		{
+			Name:        "Name",
+			Type:        "string",
+			Format:      "name",
+			Description: metav1.ObjectMeta{}.SwaggerDoc()["name"],
+			Priority:    0,
+		},
+		{
+			Name:        "Workspace",
+			Type:        "string",
+			Description: "Workspace of the APIExport",
+			Priority:    0,
+		},
+		{
+			Name:        "Display Name",
+			Type:        "string",
+			Description: "Human readable name of the API",
+			Priority:    0,
+		},
+		{
+			Name:        "Description",
+			Type:        "string",
+			Description: "Description of the API",
+			Priority:    1,
+		},
+		{
+			Name:        "Documentation",
+			Type:        "string",
+			Description: "URL of the documentation of the API",
+			Priority:    1,
+		},
+	}
+
+	if err := h.TableHandler(apiExportColumnDefinitions, printAPIExportList); err != nil {
+		panic(err)
+	}
+	if err := h.TableHandler(apiExportColumnDefinitions, printAPIExport); err != nil {
+		panic(err)
+	}
+}
+
+func printAPIExport(export *apisv1alpha1.APIExport, options kprinters.GenerateOptions) ([]metav1.TableRow, error) {
+	row := metav1.TableRow{
+		Object: runtime.RawExtension{Object: export},
+	}
+
+	var catalog apisv1alpha1.APIExportCatalog
+	if export.Spec.Catalog != nil {
+		catalog = *export.Spec.Catalog
+	}
+	row.Cells = append(row.Cells, export.Name, export.ClusterName, catalog.DisplayName, catalog.Description, catalog.DocumentationURL)
+
+	return []metav1.TableRow{row}, nil
+}
+
+func printAPIExportList(list *apisv1alpha1.APIExportList, options kprinters.GenerateOptions) ([]metav1.TableRow, error) {
+	rows := make([]metav1.TableRow, 0, len(list.Items))
+	for i := range list.Items {
+		r, err := printAPIExport(&list.Items[i], options)
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, r...)
+	}
+	return rows, nil
+}
@@ -0,0 +1,245 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/kcp-dev/logicalcluster"
+
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metainternal "k8s.io/apimachinery/pkg/apis/meta/internalversion"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/apiserver/pkg/authorization/authorizer"
+	apirequest "k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/apiserver/pkg/registry/rest"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+	"k8s.io/kubernetes/pkg/printers"
+	printerstorage "k8s.io/kubernetes/pkg/printers/storage"
+
+	apisv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apis/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/authorization/delegated"
+	catalogprinters "github.com/kcp-dev/kcp/pkg/virtual/apiexportcatalog/printers"
+)
+
+type consumerClusterKeyType int
+
+// ConsumerClusterKey is the context key under which the logical cluster name of the workspace
+// the catalog is served for is stored.
+const ConsumerClusterKey consumerClusterKeyType = iota
+
+// REST implements a read-only RESTStorage listing the APIExports a user is allowed to bind to
+// from a given consumer workspace.
+type REST struct {
+	// listAPIExports lists the APIExports of all the logical clusters.
+	listAPIExports func() ([]*apisv1alpha1.APIExport, error)
+
+	kubeClusterClient kubernetes.ClusterInterface
+	delegatedAuthz    delegated.DelegatedAuthorizerFactory
+
+	rest.TableConvertor
+}
+
+var _ rest.Lister = &REST{}
+var _ rest.Scoper = &REST{}
+
+// NewREST returns a RESTStorage object that will work against APIExports in the sibling workspaces
+// of the consumer workspace.
+func NewREST(
+	listAPIExports func() ([]*apisv1alpha1.APIExport, error),
+	kubeClusterClient kubernetes.ClusterInterface,
+	delegatedAuthz delegated.DelegatedAuthorizerFactory,
+) *REST {
+	return &REST{
+		listAPIExports: listAPIExports,
+
+		kubeClusterClient: kubeClusterClient,
+		delegatedAuthz:    delegatedAuthz,
+
+		TableConvertor: printerstorage.TableConvertor{TableGenerator: printers.NewTableGenerator().With(catalogprinters.AddAPIExportPrintHandlers)},
+	}
+}
+
+// New returns a new APIExport
+func (s *REST) New() runtime.Object {
+	return &apisv1alpha1.APIExport{}
+}
+
+// NewList returns a new APIExportList
+func (*REST) NewList() runtime.Object {
+	return &apisv1alpha1.APIExportList{}
+}
+
+func (s *REST) NamespaceScoped() bool {
+	return false
+}
+
+// List returns the APIExports of the sibling workspaces of the consumer workspace that the user
+// is allowed to bind to. Only the catalog-relevant parts of the APIExports are returned.
+func (s *REST) List(ctx context.Context, options *metainternal.ListOptions) (runtime.Object, error) {
+	userInfo, ok := apirequest.UserFrom(ctx)
+	if !ok {
+		return nil, kerrors.NewForbidden(apisv1alpha1.Resource("apiexports"), "", fmt.Errorf("unable to list apiexports without a user on the context"))
+	}
+	consumer, ok := ctx.Value(ConsumerClusterKey).(logicalcluster.Name)
+	if !ok || consumer.Empty() {
+		return nil, kerrors.NewForbidden(apisv1alpha1.Resource("apiexports"), "", fmt.Errorf("unable to list apiexports without a workspace"))
+	}
+
+	// only users that can create APIBindings in the consumer workspace get to see the catalog.
+	if allowed, err := s.authorize(ctx, consumer, authorizer.AttributesRecord{
+		User:            userInfo,
+		Verb:            "create",
+		APIGroup:        apisv1alpha1.SchemeGroupVersion.Group,
+		APIVersion:      apisv1alpha1.SchemeGroupVersion.Version,
+		Resource:        "apibindings",
+		ResourceRequest: true,
+	}); err != nil {
+		klog.Errorf("failed to authorize user %q to create apibindings in %s: %v", userInfo.GetName(), consumer, err)
+		return nil, kerrors.NewForbidden(apisv1alpha1.Resource("apiexports"), "", fmt.Errorf("%q workspace access not permitted", consumer))
+	} else if !allowed {
+		return nil, kerrors.NewForbidden(apisv1alpha1.Resource("apiexports"), "", fmt.Errorf("%q workspace access not permitted", consumer))
+	}
+
+	labelSelector, fieldSelector := internalListOptionsToSelectors(options)
+
+	exports, err := s.listAPIExports()
+	if err != nil {
+		return nil, err
+	}
+
+	// the catalog only lists the APIExports of sibling workspaces, which APIBindings reference by name.
+	// APIExports of other workspaces can be bound through a path reference, but are not listed.
+	parent, hasParent := consumer.Parent()
+	if !hasParent {
+		return &apisv1alpha1.APIExportList{}, nil
+	}
+
+	authorizers := map[logicalcluster.Name]authorizer.Authorizer{}
+	list := &apisv1alpha1.APIExportList{}
+	for _, export := range exports {
+		clusterName := logicalcluster.From(export)
+		if exportParent, ok := clusterName.Parent(); !ok || exportParent != parent {
+			continue
+		}
+		if !labelSelector.Matches(labels.Set(export.Labels)) || !fieldSelector.Matches(fields.Set{"metadata.name": export.Name}) {
+			continue
+		}
+
+		allowed, err := s.authorizeBind(ctx, authorizers, userInfo, clusterName, export.Name)
+		if err != nil {
+			klog.Errorf("failed to authorize user %q to bind apiexport %s|%s: %v", userInfo.GetName(), clusterName, export.Name, err)
+			continue
+		}
+		if !allowed {
+			continue
+		}
+
+		list.Items = append(list.Items, catalogEntry(export))
+	}
+
+	sort.Slice(list.Items, func(i, j int) bool {
+		if list.Items[i].ClusterName != list.Items[j].ClusterName {
+			return list.Items[i].ClusterName < list.Items[j].ClusterName
+		}
+		return list.Items[i].Name < list.Items[j].Name
+	})
+
+	return list, nil
+}
+
+func (s *REST) authorizeBind(ctx context.Context, authorizers map[logicalcluster.Name]authorizer.Authorizer, userInfo user.Info, clusterName logicalcluster.Name, name string) (bool, error) {
+	authz, ok := authorizers[clusterName]
+	if !ok {
+		var err error
+		authz, err = s.delegatedAuthz(clusterName, s.kubeClusterClient)
+		if err != nil {
+			return false, err
+		}
+		authorizers[clusterName] = authz
+	}
+
+	decision, _, err := authz.Authorize(ctx, authorizer.AttributesRecord{
+		User:            userInfo,
+		Verb:            "bind",
+		APIGroup:        apisv1alpha1.SchemeGroupVersion.Group,
+		APIVersion:      apisv1alpha1.SchemeGroupVersion.Version,
+		Resource:        "apiexports",
+		Name:            name,
+		ResourceRequest: true,
+	})
+	if err != nil {
+		return false, err
+	}
+	return decision == authorizer.DecisionAllow, nil
+}
+
+func (s *REST) authorize(ctx context.Context, clusterName logicalcluster.Name, attr authorizer.AttributesRecord) (bool, error) {
+	authz, err := s.delegatedAuthz(clusterName, s.kubeClusterClient)
+	if err != nil {
+		return false, err
+	}
+	decision, _, err := authz.Authorize(ctx, attr)
+	if err != nil {
+		return false, err
+	}
+	return decision == authorizer.DecisionAllow, nil
+}
+
+// catalogEntry returns a copy of the APIExport restricted to what is needed to discover it and
+// to bind to it. In particular, the identity and the status are dropped.
+func catalogEntry(export *apisv1alpha1.APIExport) apisv1alpha1.APIExport {
+	entry := apisv1alpha1.APIExport{
+		TypeMeta: export.TypeMeta,
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              export.Name,
+			ClusterName:       export.ClusterName,
+			UID:               export.UID,
+			CreationTimestamp: export.CreationTimestamp,
+		},
+	}
+	if export.Labels != nil {
+		entry.Labels = make(map[string]string, len(export.Labels))
+		for k, v := range export.Labels {
+			entry.Labels[k] = v
+		}
+	}
+	entry.Spec.LatestResourceSchemas = append([]string(nil), export.Spec.LatestResourceSchemas...)
+	if export.Spec.Catalog != nil {
+		entry.Spec.Catalog = export.Spec.Catalog.DeepCopy()
+	}
+	return entry
+}
+
+func internalListOptionsToSelectors(options *metainternal.ListOptions) (labels.Selector, fields.Selector) {
+	label := labels.Everything()
+	if options != nil && options.LabelSelector != nil {
+		label = options.LabelSelector
+	}
+	field := fields.Everything()
+	if options != nil && options.FieldSelector != nil {
+		field = options.FieldSelector
+	}
+	return label, field
+}
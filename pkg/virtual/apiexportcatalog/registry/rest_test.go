@@ -0,0 +1,164 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kcp-dev/logicalcluster"
+	"github.com/stretchr/testify/require"
+
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metainternal "k8s.io/apimachinery/pkg/apis/meta/internalversion"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/apiserver/pkg/authorization/authorizer"
+	apirequest "k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/client-go/kubernetes"
+
+	apisv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apis/v1alpha1"
+)
+
+func newAPIExport(clusterName, name string, labels map[string]string) *apisv1alpha1.APIExport {
+	return &apisv1alpha1.APIExport{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        name,
+			ClusterName: clusterName,
+			Labels:      labels,
+			Annotations: map[string]string{"internal": "true"},
+		},
+		Spec: apisv1alpha1.APIExportSpec{
+			LatestResourceSchemas: []string{"v1.widgets.example.com"},
+			Identity:              &apisv1alpha1.Identity{},
+			Catalog: &apisv1alpha1.APIExportCatalog{
+				DisplayName: name,
+			},
+		},
+		Status: apisv1alpha1.APIExportStatus{
+			IdentityHash: "secret-hash",
+		},
+	}
+}
+
+func TestList(t *testing.T) {
+	exports := []*apisv1alpha1.APIExport{
+		newAPIExport("root:org:b", "widgets", map[string]string{"tier": "gold"}),
+		newAPIExport("root:org:a", "widgets", nil),
+		newAPIExport("root:org:a", "gadgets", nil),
+		newAPIExport("root:org:a", "private", nil),
+		newAPIExport("root:other:c", "widgets", nil),
+	}
+
+	// bindable maps a cluster to the APIExports the user may bind to there.
+	bindable := map[string]map[string]bool{
+		"root:org:a":   {"widgets": true, "gadgets": true},
+		"root:org:b":   {"widgets": true},
+		"root:other:c": {"widgets": true},
+	}
+
+	tests := []struct {
+		name          string
+		user          user.Info
+		consumer      logicalcluster.Name
+		canBind       bool
+		labelSelector labels.Selector
+		wantExports   []string
+		wantForbidden bool
+	}{
+		{
+			name:          "no user",
+			consumer:      logicalcluster.New("root:org:consumer"),
+			canBind:       true,
+			wantForbidden: true,
+		},
+		{
+			name:          "no permission to create apibindings",
+			user:          &user.DefaultInfo{Name: "alice"},
+			consumer:      logicalcluster.New("root:org:consumer"),
+			wantForbidden: true,
+		},
+		{
+			name:        "bindable siblings are listed",
+			user:        &user.DefaultInfo{Name: "alice"},
+			consumer:    logicalcluster.New("root:org:consumer"),
+			canBind:     true,
+			wantExports: []string{"root:org:a|gadgets", "root:org:a|widgets", "root:org:b|widgets"},
+		},
+		{
+			name:          "label selector",
+			user:          &user.DefaultInfo{Name: "alice"},
+			consumer:      logicalcluster.New("root:org:consumer"),
+			canBind:       true,
+			labelSelector: labels.SelectorFromSet(labels.Set{"tier": "gold"}),
+			wantExports:   []string{"root:org:b|widgets"},
+		},
+		{
+			name:        "exports of other organizations are not listed",
+			user:        &user.DefaultInfo{Name: "alice"},
+			consumer:    logicalcluster.New("root:other:consumer"),
+			canBind:     true,
+			wantExports: []string{"root:other:c|widgets"},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewREST(
+				func() ([]*apisv1alpha1.APIExport, error) { return exports, nil },
+				nil,
+				func(clusterName logicalcluster.Name, _ kubernetes.ClusterInterface) (authorizer.Authorizer, error) {
+					return authorizer.AuthorizerFunc(func(ctx context.Context, attr authorizer.Attributes) (authorizer.Decision, string, error) {
+						switch {
+						case attr.GetResource() == "apibindings" && attr.GetVerb() == "create" && clusterName == tt.consumer && tt.canBind:
+							return authorizer.DecisionAllow, "", nil
+						case attr.GetResource() == "apiexports" && attr.GetVerb() == "bind" && bindable[clusterName.String()][attr.GetName()]:
+							return authorizer.DecisionAllow, "", nil
+						}
+						return authorizer.DecisionNoOpinion, "", nil
+					}), nil
+				},
+			)
+
+			ctx := context.WithValue(context.Background(), ConsumerClusterKey, tt.consumer)
+			if tt.user != nil {
+				ctx = apirequest.WithUser(ctx, tt.user)
+			}
+
+			obj, err := s.List(ctx, &metainternal.ListOptions{LabelSelector: tt.labelSelector})
+			if tt.wantForbidden {
+				require.True(t, kerrors.IsForbidden(err), "expected forbidden error, got %v", err)
+				return
+			}
+			require.NoError(t, err)
+
+			list := obj.(*apisv1alpha1.APIExportList)
+			got := make([]string, 0, len(list.Items))
+			for _, export := range list.Items {
+				got = append(got, export.ClusterName+"|"+export.Name)
+
+				require.Nil(t, export.Spec.Identity, "identity must not be exposed")
+				require.Empty(t, export.Status.IdentityHash, "status must not be exposed")
+				require.Empty(t, export.Annotations, "annotations must not be exposed")
+				require.NotNil(t, export.Spec.Catalog)
+				require.Equal(t, []string{"v1.widgets.example.com"}, export.Spec.LatestResourceSchemas)
+			}
+			require.Equal(t, tt.wantExports, got)
+		})
+	}
+}
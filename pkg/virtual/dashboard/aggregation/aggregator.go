@@ -0,0 +1,315 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aggregation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/kcp-dev/logicalcluster"
+
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/apiserver/pkg/authorization/authorizer"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+
+	apisv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apis/v1alpha1"
+	schedulingv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/scheduling/v1alpha1"
+	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/workload/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/authorization/bootstrap"
+	"github.com/kcp-dev/kcp/pkg/authorization/delegated"
+)
+
+// Aggregator computes the aggregated views of a workspace tree, filtered by the
+// permissions of the user.
+type Aggregator struct {
+	// listClusterWorkspaces lists the ClusterWorkspaces in the given logical cluster.
+	listClusterWorkspaces func(clusterName logicalcluster.Name) ([]*tenancyv1alpha1.ClusterWorkspace, error)
+	// listAPIBindings lists the APIBindings in the given logical cluster.
+	listAPIBindings func(clusterName logicalcluster.Name) ([]*apisv1alpha1.APIBinding, error)
+	// listNamespaces lists the Namespaces in the given logical cluster.
+	listNamespaces func(clusterName logicalcluster.Name) ([]*corev1.Namespace, error)
+
+	kubeClusterClient kubernetes.ClusterInterface
+	delegatedAuthz    delegated.DelegatedAuthorizerFactory
+}
+
+func NewAggregator(
+	listClusterWorkspaces func(clusterName logicalcluster.Name) ([]*tenancyv1alpha1.ClusterWorkspace, error),
+	listAPIBindings func(clusterName logicalcluster.Name) ([]*apisv1alpha1.APIBinding, error),
+	listNamespaces func(clusterName logicalcluster.Name) ([]*corev1.Namespace, error),
+	kubeClusterClient kubernetes.ClusterInterface,
+	delegatedAuthz delegated.DelegatedAuthorizerFactory,
+) *Aggregator {
+	return &Aggregator{
+		listClusterWorkspaces: listClusterWorkspaces,
+		listAPIBindings:       listAPIBindings,
+		listNamespaces:        listNamespaces,
+
+		kubeClusterClient: kubeClusterClient,
+		delegatedAuthz:    delegatedAuthz,
+	}
+}
+
+// WorkspaceTree returns the tree of workspaces below the given workspace the user has access to.
+// It returns a forbidden error if the user has no access to the given workspace itself.
+func (a *Aggregator) WorkspaceTree(ctx context.Context, userInfo user.Info, clusterName logicalcluster.Name) (*Workspace, error) {
+	authz := newRequestAuthorizer(a, userInfo)
+
+	top := &Workspace{
+		Name:        clusterName.Base(),
+		ClusterName: clusterName.String(),
+	}
+	if parent, hasParent := clusterName.Parent(); hasParent {
+		allowed, err := authz.allowed(ctx, parent, workspaceAccessAttributes(clusterName.Base()))
+		if err != nil {
+			return nil, err
+		}
+		if !allowed {
+			return nil, kerrors.NewForbidden(tenancyv1alpha1.Resource("clusterworkspaces"), clusterName.Base(), fmt.Errorf("%q workspace access not permitted", clusterName))
+		}
+
+		siblings, err := a.listClusterWorkspaces(parent)
+		if err != nil {
+			return nil, err
+		}
+		for _, cws := range siblings {
+			if cws.Name == clusterName.Base() {
+				setStatus(top, cws)
+				break
+			}
+		}
+	}
+
+	if err := a.addChildren(ctx, authz, top, clusterName); err != nil {
+		return nil, err
+	}
+	return top, nil
+}
+
+func (a *Aggregator) addChildren(ctx context.Context, authz *requestAuthorizer, node *Workspace, clusterName logicalcluster.Name) error {
+	children, err := a.listClusterWorkspaces(clusterName)
+	if err != nil {
+		return err
+	}
+	sort.Slice(children, func(i, j int) bool {
+		return children[i].Name < children[j].Name
+	})
+
+	for _, cws := range children {
+		allowed, err := authz.allowed(ctx, clusterName, workspaceAccessAttributes(cws.Name))
+		if err != nil {
+			klog.Errorf("failed to authorize user %q to access workspace %s|%s: %v", authz.user.GetName(), clusterName, cws.Name, err)
+			continue
+		}
+		if !allowed {
+			continue
+		}
+
+		child := Workspace{
+			Name:        cws.Name,
+			ClusterName: clusterName.Join(cws.Name).String(),
+		}
+		setStatus(&child, cws)
+		if err := a.addChildren(ctx, authz, &child, clusterName.Join(cws.Name)); err != nil {
+			return err
+		}
+		node.Children = append(node.Children, child)
+	}
+	return nil
+}
+
+// APIBindings returns the APIBindings of the workspaces of the tree below the given workspace
+// in which the user can list APIBindings.
+func (a *Aggregator) APIBindings(ctx context.Context, userInfo user.Info, clusterName logicalcluster.Name) (*WorkspaceAPIBindingsList, error) {
+	tree, err := a.WorkspaceTree(ctx, userInfo, clusterName)
+	if err != nil {
+		return nil, err
+	}
+
+	authz := newRequestAuthorizer(a, userInfo)
+	list := &WorkspaceAPIBindingsList{Workspaces: []WorkspaceAPIBindings{}}
+	for _, ws := range flatten(tree) {
+		if allowed, err := authz.allowed(ctx, ws, listAttributes(apisv1alpha1.SchemeGroupVersion.Group, apisv1alpha1.SchemeGroupVersion.Version, "apibindings")); err != nil {
+			klog.Errorf("failed to authorize user %q to list apibindings in %s: %v", userInfo.GetName(), ws, err)
+			continue
+		} else if !allowed {
+			continue
+		}
+
+		bindings, err := a.listAPIBindings(ws)
+		if err != nil {
+			return nil, err
+		}
+		sort.Slice(bindings, func(i, j int) bool {
+			return bindings[i].Name < bindings[j].Name
+		})
+
+		entry := WorkspaceAPIBindings{ClusterName: ws.String(), APIBindings: make([]APIBinding, 0, len(bindings))}
+		for _, binding := range bindings {
+			summary := APIBinding{
+				Name:  binding.Name,
+				Phase: binding.Status.Phase,
+			}
+			if ref := binding.Spec.Reference.Workspace; ref != nil {
+				summary.ExportWorkspace = ref.String()
+				summary.ExportName = ref.ExportName
+			}
+			for _, r := range binding.Status.BoundResources {
+				summary.BoundResources = append(summary.BoundResources, strings.TrimSuffix(r.Resource+"."+r.Group, "."))
+			}
+			entry.APIBindings = append(entry.APIBindings, summary)
+		}
+		list.Workspaces = append(list.Workspaces, entry)
+	}
+	return list, nil
+}
+
+// Placements returns the placements of the namespaces of the workspaces of the tree below the given
+// workspace in which the user can list namespaces.
+func (a *Aggregator) Placements(ctx context.Context, userInfo user.Info, clusterName logicalcluster.Name) (*WorkspacePlacementsList, error) {
+	tree, err := a.WorkspaceTree(ctx, userInfo, clusterName)
+	if err != nil {
+		return nil, err
+	}
+
+	authz := newRequestAuthorizer(a, userInfo)
+	list := &WorkspacePlacementsList{Workspaces: []WorkspacePlacements{}}
+	for _, ws := range flatten(tree) {
+		if allowed, err := authz.allowed(ctx, ws, listAttributes("", "v1", "namespaces")); err != nil {
+			klog.Errorf("failed to authorize user %q to list namespaces in %s: %v", userInfo.GetName(), ws, err)
+			continue
+		} else if !allowed {
+			continue
+		}
+
+		namespaces, err := a.listNamespaces(ws)
+		if err != nil {
+			return nil, err
+		}
+		sort.Slice(namespaces, func(i, j int) bool {
+			return namespaces[i].Name < namespaces[j].Name
+		})
+
+		entry := WorkspacePlacements{ClusterName: ws.String(), Namespaces: make([]NamespacePlacement, 0, len(namespaces))}
+		for _, ns := range namespaces {
+			entry.Namespaces = append(entry.Namespaces, namespacePlacement(ns))
+		}
+		list.Workspaces = append(list.Workspaces, entry)
+	}
+	return list, nil
+}
+
+func namespacePlacement(ns *corev1.Namespace) NamespacePlacement {
+	placement := NamespacePlacement{Name: ns.Name}
+	for k, v := range ns.Labels {
+		if strings.HasPrefix(k, workloadv1alpha1.InternalClusterResourceStateLabelPrefix) {
+			if placement.SyncTargets == nil {
+				placement.SyncTargets = map[string]string{}
+			}
+			placement.SyncTargets[strings.TrimPrefix(k, workloadv1alpha1.InternalClusterResourceStateLabelPrefix)] = v
+		}
+	}
+	if value, found := ns.Annotations[schedulingv1alpha1.PlacementAnnotationKey]; found {
+		var annotation schedulingv1alpha1.PlacementAnnotation
+		if err := json.Unmarshal([]byte(value), &annotation); err != nil {
+			klog.V(4).Infof("failed to decode %s annotation of namespace %s|%s: %v", schedulingv1alpha1.PlacementAnnotationKey, ns.ClusterName, ns.Name, err)
+		} else {
+			placement.Placement = annotation
+		}
+	}
+	return placement
+}
+
+func setStatus(node *Workspace, cws *tenancyv1alpha1.ClusterWorkspace) {
+	node.Type = cws.Spec.Type
+	node.ReadOnly = cws.Spec.ReadOnly
+	node.Phase = cws.Status.Phase
+	node.URL = cws.Status.BaseURL
+}
+
+// flatten returns the logical clusters of the tree in depth-first order.
+func flatten(node *Workspace) []logicalcluster.Name {
+	clusters := []logicalcluster.Name{logicalcluster.New(node.ClusterName)}
+	for i := range node.Children {
+		clusters = append(clusters, flatten(&node.Children[i])...)
+	}
+	return clusters
+}
+
+func workspaceAccessAttributes(name string) authorizer.AttributesRecord {
+	return authorizer.AttributesRecord{
+		Verb:            bootstrap.WorkspaceAccessVerb,
+		APIGroup:        tenancyv1alpha1.SchemeGroupVersion.Group,
+		APIVersion:      tenancyv1alpha1.SchemeGroupVersion.Version,
+		Resource:        "clusterworkspaces",
+		Subresource:     "content",
+		Name:            name,
+		ResourceRequest: true,
+	}
+}
+
+func listAttributes(group, version, resource string) authorizer.AttributesRecord {
+	return authorizer.AttributesRecord{
+		Verb:            "list",
+		APIGroup:        group,
+		APIVersion:      version,
+		Resource:        resource,
+		ResourceRequest: true,
+	}
+}
+
+// requestAuthorizer authorizes a user against many logical clusters, reusing the
+// delegated authorizer of a logical cluster during a request.
+type requestAuthorizer struct {
+	aggregator  *Aggregator
+	user        user.Info
+	authorizers map[logicalcluster.Name]authorizer.Authorizer
+}
+
+func newRequestAuthorizer(a *Aggregator, userInfo user.Info) *requestAuthorizer {
+	return &requestAuthorizer{
+		aggregator:  a,
+		user:        userInfo,
+		authorizers: map[logicalcluster.Name]authorizer.Authorizer{},
+	}
+}
+
+func (r *requestAuthorizer) allowed(ctx context.Context, clusterName logicalcluster.Name, attr authorizer.AttributesRecord) (bool, error) {
+	authz, ok := r.authorizers[clusterName]
+	if !ok {
+		var err error
+		authz, err = r.aggregator.delegatedAuthz(clusterName, r.aggregator.kubeClusterClient)
+		if err != nil {
+			return false, err
+		}
+		r.authorizers[clusterName] = authz
+	}
+
+	attr.User = r.user
+	decision, _, err := authz.Authorize(ctx, attr)
+	if err != nil {
+		return false, err
+	}
+	return decision == authorizer.DecisionAllow, nil
+}
@@ -0,0 +1,241 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aggregation
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kcp-dev/logicalcluster"
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/apiserver/pkg/authorization/authorizer"
+	apirequest "k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/client-go/kubernetes"
+
+	apisv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apis/v1alpha1"
+	schedulingv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/scheduling/v1alpha1"
+	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+)
+
+func newClusterWorkspace(clusterName, name string) *tenancyv1alpha1.ClusterWorkspace {
+	return &tenancyv1alpha1.ClusterWorkspace{
+		ObjectMeta: metav1.ObjectMeta{Name: name, ClusterName: clusterName},
+		Spec:       tenancyv1alpha1.ClusterWorkspaceSpec{Type: "Universal"},
+		Status: tenancyv1alpha1.ClusterWorkspaceStatus{
+			Phase:   tenancyv1alpha1.ClusterWorkspacePhaseReady,
+			BaseURL: "https://kcp/clusters/" + clusterName + ":" + name,
+		},
+	}
+}
+
+// newTestAggregator returns an aggregator over the tree
+//
+//	root:org
+//	├── team-a
+//	│   └── app
+//	└── team-b
+//
+// where allowed maps "<cluster>|<verb>|<resource>|<name>" to allowed requests.
+func newTestAggregator(allowed map[string]bool) *Aggregator {
+	workspaces := map[string][]*tenancyv1alpha1.ClusterWorkspace{
+		"root":            {newClusterWorkspace("root", "org")},
+		"root:org":        {newClusterWorkspace("root:org", "team-b"), newClusterWorkspace("root:org", "team-a")},
+		"root:org:team-a": {newClusterWorkspace("root:org:team-a", "app")},
+	}
+	bindings := map[string][]*apisv1alpha1.APIBinding{
+		"root:org:team-a": {{
+			ObjectMeta: metav1.ObjectMeta{Name: "widgets", ClusterName: "root:org:team-a"},
+			Spec: apisv1alpha1.APIBindingSpec{
+				Reference: apisv1alpha1.ExportReference{Workspace: &apisv1alpha1.WorkspaceExportReference{WorkspaceName: "providers", ExportName: "widgets"}},
+			},
+			Status: apisv1alpha1.APIBindingStatus{
+				Phase:          apisv1alpha1.APIBindingPhaseBound,
+				BoundResources: []apisv1alpha1.BoundAPIResource{{Group: "example.com", Resource: "widgets"}},
+			},
+		}},
+	}
+	namespaces := map[string][]*corev1.Namespace{
+		"root:org:team-a:app": {{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "default",
+				ClusterName: "root:org:team-a:app",
+				Labels:      map[string]string{"state.internal.workloads.kcp.dev/us-east1": "Sync", "other": "label"},
+				Annotations: map[string]string{schedulingv1alpha1.PlacementAnnotationKey: `{"root:org:team-a+us-east1+us-east1":"Bound"}`},
+			},
+		}},
+	}
+
+	return NewAggregator(
+		func(clusterName logicalcluster.Name) ([]*tenancyv1alpha1.ClusterWorkspace, error) {
+			return append([]*tenancyv1alpha1.ClusterWorkspace(nil), workspaces[clusterName.String()]...), nil
+		},
+		func(clusterName logicalcluster.Name) ([]*apisv1alpha1.APIBinding, error) {
+			return append([]*apisv1alpha1.APIBinding(nil), bindings[clusterName.String()]...), nil
+		},
+		func(clusterName logicalcluster.Name) ([]*corev1.Namespace, error) {
+			return append([]*corev1.Namespace(nil), namespaces[clusterName.String()]...), nil
+		},
+		nil,
+		func(clusterName logicalcluster.Name, _ kubernetes.ClusterInterface) (authorizer.Authorizer, error) {
+			return authorizer.AuthorizerFunc(func(ctx context.Context, attr authorizer.Attributes) (authorizer.Decision, string, error) {
+				if allowed[clusterName.String()+"|"+attr.GetVerb()+"|"+attr.GetResource()+"|"+attr.GetName()] {
+					return authorizer.DecisionAllow, "", nil
+				}
+				return authorizer.DecisionNoOpinion, "", nil
+			}), nil
+		},
+	)
+}
+
+func TestWorkspaceTree(t *testing.T) {
+	tests := []struct {
+		name          string
+		allowed       map[string]bool
+		want          *Workspace
+		wantForbidden bool
+	}{
+		{
+			name:          "no access to the top workspace",
+			allowed:       map[string]bool{"root:org|access|clusterworkspaces|team-a": true},
+			wantForbidden: true,
+		},
+		{
+			name: "only accessible workspaces are listed",
+			allowed: map[string]bool{
+				"root|access|clusterworkspaces|org":            true,
+				"root:org|access|clusterworkspaces|team-a":     true,
+				"root:org:team-a|access|clusterworkspaces|app": true,
+			},
+			want: &Workspace{
+				Name: "org", ClusterName: "root:org", Type: "Universal", Phase: tenancyv1alpha1.ClusterWorkspacePhaseReady, URL: "https://kcp/clusters/root:org",
+				Children: []Workspace{{
+					Name: "team-a", ClusterName: "root:org:team-a", Type: "Universal", Phase: tenancyv1alpha1.ClusterWorkspacePhaseReady, URL: "https://kcp/clusters/root:org:team-a",
+					Children: []Workspace{{
+						Name: "app", ClusterName: "root:org:team-a:app", Type: "Universal", Phase: tenancyv1alpha1.ClusterWorkspacePhaseReady, URL: "https://kcp/clusters/root:org:team-a:app",
+					}},
+				}},
+			},
+		},
+		{
+			name: "sub-trees of inaccessible workspaces are not listed",
+			allowed: map[string]bool{
+				"root|access|clusterworkspaces|org":            true,
+				"root:org|access|clusterworkspaces|team-b":     true,
+				"root:org:team-a|access|clusterworkspaces|app": true,
+			},
+			want: &Workspace{
+				Name: "org", ClusterName: "root:org", Type: "Universal", Phase: tenancyv1alpha1.ClusterWorkspacePhaseReady, URL: "https://kcp/clusters/root:org",
+				Children: []Workspace{{
+					Name: "team-b", ClusterName: "root:org:team-b", Type: "Universal", Phase: tenancyv1alpha1.ClusterWorkspacePhaseReady, URL: "https://kcp/clusters/root:org:team-b",
+				}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTestAggregator(tt.allowed)
+			got, err := a.WorkspaceTree(context.Background(), &user.DefaultInfo{Name: "alice"}, logicalcluster.New("root:org"))
+			if tt.wantForbidden {
+				require.True(t, kerrors.IsForbidden(err), "expected forbidden error, got %v", err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestAPIBindingsAndPlacements(t *testing.T) {
+	a := newTestAggregator(map[string]bool{
+		"root|access|clusterworkspaces|org":            true,
+		"root:org|access|clusterworkspaces|team-a":     true,
+		"root:org:team-a|access|clusterworkspaces|app": true,
+		"root:org:team-a|list|apibindings|":            true,
+		"root:org:team-a:app|list|namespaces|":         true,
+	})
+	userInfo := &user.DefaultInfo{Name: "alice"}
+
+	bindings, err := a.APIBindings(context.Background(), userInfo, logicalcluster.New("root:org"))
+	require.NoError(t, err)
+	require.Equal(t, &WorkspaceAPIBindingsList{Workspaces: []WorkspaceAPIBindings{{
+		ClusterName: "root:org:team-a",
+		APIBindings: []APIBinding{{
+			Name:            "widgets",
+			ExportWorkspace: "providers",
+			ExportName:      "widgets",
+			Phase:           apisv1alpha1.APIBindingPhaseBound,
+			BoundResources:  []string{"widgets.example.com"},
+		}},
+	}}}, bindings)
+
+	placements, err := a.Placements(context.Background(), userInfo, logicalcluster.New("root:org"))
+	require.NoError(t, err)
+	require.Equal(t, &WorkspacePlacementsList{Workspaces: []WorkspacePlacements{{
+		ClusterName: "root:org:team-a:app",
+		Namespaces: []NamespacePlacement{{
+			Name:        "default",
+			SyncTargets: map[string]string{"us-east1": "Sync"},
+			Placement:   schedulingv1alpha1.PlacementAnnotation{"root:org:team-a+us-east1+us-east1": schedulingv1alpha1.PlacementStateBound},
+		}},
+	}}}, placements)
+}
+
+func TestHandler(t *testing.T) {
+	h := NewHandler(newTestAggregator(map[string]bool{
+		"root|access|clusterworkspaces|org": true,
+	}))
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		user       user.Info
+		wantStatus int
+	}{
+		{name: "workspaces", method: http.MethodGet, path: WorkspacesPath, user: &user.DefaultInfo{Name: "alice"}, wantStatus: http.StatusOK},
+		{name: "apibindings", method: http.MethodGet, path: APIBindingsPath, user: &user.DefaultInfo{Name: "alice"}, wantStatus: http.StatusOK},
+		{name: "placements", method: http.MethodGet, path: PlacementsPath, user: &user.DefaultInfo{Name: "alice"}, wantStatus: http.StatusOK},
+		{name: "unknown path", method: http.MethodGet, path: "/unknown", user: &user.DefaultInfo{Name: "alice"}, wantStatus: http.StatusNotFound},
+		{name: "write", method: http.MethodPost, path: WorkspacesPath, user: &user.DefaultInfo{Name: "alice"}, wantStatus: http.StatusMethodNotAllowed},
+		{name: "no user", method: http.MethodGet, path: WorkspacesPath, wantStatus: http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.WithValue(context.Background(), WorkspaceKey, logicalcluster.New("root:org"))
+			if tt.user != nil {
+				ctx = apirequest.WithUser(ctx, tt.user)
+			}
+			req := httptest.NewRequest(tt.method, tt.path, nil).WithContext(ctx)
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+			require.Equal(t, tt.wantStatus, rec.Code, rec.Body.String())
+			if tt.wantStatus == http.StatusOK {
+				require.True(t, json.Valid(rec.Body.Bytes()))
+			}
+		})
+	}
+}
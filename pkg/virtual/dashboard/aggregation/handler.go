@@ -0,0 +1,108 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aggregation
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/kcp-dev/logicalcluster"
+
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apiserver/pkg/endpoints/handlers/responsewriters"
+	apirequest "k8s.io/apiserver/pkg/endpoints/request"
+)
+
+type workspaceKeyType int
+
+// WorkspaceKey is the context key under which the logical cluster name of the workspace
+// at the top of the aggregated tree is stored.
+const WorkspaceKey workspaceKeyType = iota
+
+const (
+	// WorkspacesPath serves the workspace tree.
+	WorkspacesPath = "/workspaces"
+	// APIBindingsPath serves the APIBindings per workspace.
+	APIBindingsPath = "/apibindings"
+	// PlacementsPath serves the placements per namespace.
+	PlacementsPath = "/placements"
+)
+
+var (
+	errorScheme = runtime.NewScheme()
+	errorCodecs = serializer.NewCodecFactory(errorScheme)
+)
+
+func init() {
+	errorScheme.AddUnversionedTypes(metav1.Unversioned,
+		&metav1.Status{},
+	)
+}
+
+// NewHandler returns the HTTP handler serving the aggregated endpoints. The
+// request context must hold the user and the top workspace under WorkspaceKey.
+func NewHandler(aggregator *Aggregator) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet {
+			responsewriters.ErrorNegotiated(kerrors.NewMethodNotSupported(schema.GroupResource{Resource: req.URL.Path}, req.Method), errorCodecs, schema.GroupVersion{}, w, req)
+			return
+		}
+		userInfo, ok := apirequest.UserFrom(req.Context())
+		if !ok {
+			responsewriters.ErrorNegotiated(kerrors.NewForbidden(schema.GroupResource{Resource: req.URL.Path}, "", fmt.Errorf("no user on the request")), errorCodecs, schema.GroupVersion{}, w, req)
+			return
+		}
+		clusterName, ok := req.Context().Value(WorkspaceKey).(logicalcluster.Name)
+		if !ok || clusterName.Empty() {
+			responsewriters.ErrorNegotiated(kerrors.NewBadRequest("no workspace in the request path"), errorCodecs, schema.GroupVersion{}, w, req)
+			return
+		}
+
+		var result interface{}
+		var err error
+		switch req.URL.Path {
+		case WorkspacesPath:
+			result, err = aggregator.WorkspaceTree(req.Context(), userInfo, clusterName)
+		case APIBindingsPath:
+			result, err = aggregator.APIBindings(req.Context(), userInfo, clusterName)
+		case PlacementsPath:
+			result, err = aggregator.Placements(req.Context(), userInfo, clusterName)
+		default:
+			responsewriters.ErrorNegotiated(kerrors.NewNotFound(schema.GroupResource{Resource: req.URL.Path}, ""), errorCodecs, schema.GroupVersion{}, w, req)
+			return
+		}
+		if err != nil {
+			if _, isStatus := err.(kerrors.APIStatus); !isStatus {
+				err = kerrors.NewInternalError(err)
+			}
+			responsewriters.ErrorNegotiated(err, errorCodecs, schema.GroupVersion{}, w, req)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		if err := json.NewEncoder(w).Encode(result); err != nil {
+			utilruntime.HandleError(err)
+		}
+	})
+}
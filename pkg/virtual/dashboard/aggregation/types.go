@@ -0,0 +1,85 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package aggregation
+
+import (
+	apisv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apis/v1alpha1"
+	schedulingv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/scheduling/v1alpha1"
+	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+)
+
+// Workspace is a node of the workspace tree, holding the status of the workspace
+// and the sub-workspaces visible to the user.
+type Workspace struct {
+	// name is the name of the workspace in its parent.
+	Name string `json:"name"`
+	// clusterName is the logical cluster of the workspace, e.g. root:org:ws.
+	ClusterName string `json:"clusterName"`
+	// type is the ClusterWorkspaceType of the workspace. It is empty for the root workspace.
+	Type string `json:"type,omitempty"`
+	// phase is the phase of the workspace. It is empty for the root workspace.
+	Phase tenancyv1alpha1.ClusterWorkspacePhaseType `json:"phase,omitempty"`
+	// readOnly is true if writes to the workspace are rejected.
+	ReadOnly bool `json:"readOnly,omitempty"`
+	// url is the URL to reach the workspace.
+	URL string `json:"url,omitempty"`
+	// children are the sub-workspaces the user has access to.
+	Children []Workspace `json:"children,omitempty"`
+}
+
+// WorkspaceAPIBindings lists the APIBindings of a workspace.
+type WorkspaceAPIBindings struct {
+	ClusterName string       `json:"clusterName"`
+	APIBindings []APIBinding `json:"apiBindings"`
+}
+
+// APIBinding is the summary of an APIBinding.
+type APIBinding struct {
+	Name string `json:"name"`
+	// exportWorkspace and exportName reference the bound APIExport.
+	ExportWorkspace string                           `json:"exportWorkspace,omitempty"`
+	ExportName      string                           `json:"exportName,omitempty"`
+	Phase           apisv1alpha1.APIBindingPhaseType `json:"phase,omitempty"`
+	// boundResources are the bound resources in <resource>.<group> notation.
+	BoundResources []string `json:"boundResources,omitempty"`
+}
+
+// WorkspacePlacements lists the placements of the namespaces of a workspace.
+type WorkspacePlacements struct {
+	ClusterName string               `json:"clusterName"`
+	Namespaces  []NamespacePlacement `json:"namespaces"`
+}
+
+// NamespacePlacement is the placement of a namespace onto workload clusters.
+type NamespacePlacement struct {
+	Name string `json:"name"`
+	// syncTargets maps the workload clusters the namespace is assigned to, to the resource state
+	// of the namespace on them.
+	SyncTargets map[string]string `json:"syncTargets,omitempty"`
+	// placement is the content of the scheduling.kcp.dev/placement annotation, if any.
+	Placement schedulingv1alpha1.PlacementAnnotation `json:"placement,omitempty"`
+}
+
+// WorkspaceAPIBindingsList is the response of the apibindings endpoint.
+type WorkspaceAPIBindingsList struct {
+	Workspaces []WorkspaceAPIBindings `json:"workspaces"`
+}
+
+// WorkspacePlacementsList is the response of the placements endpoint.
+type WorkspacePlacementsList struct {
+	Workspaces []WorkspacePlacements `json:"workspaces"`
+}
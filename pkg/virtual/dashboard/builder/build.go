@@ -0,0 +1,139 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package builder
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/kcp-dev/logicalcluster"
+
+	corev1 "k8s.io/api/core/v1"
+	genericapiserver "k8s.io/apiserver/pkg/server"
+	coreinformers "k8s.io/client-go/informers/core/v1"
+	rbacinformers "k8s.io/client-go/informers/rbac/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+
+	apisv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apis/v1alpha1"
+	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/authorization/delegated"
+	apisinformers "github.com/kcp-dev/kcp/pkg/client/informers/externalversions/apis/v1alpha1"
+	tenancyinformers "github.com/kcp-dev/kcp/pkg/client/informers/externalversions/tenancy/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/virtual/dashboard/aggregation"
+	"github.com/kcp-dev/kcp/pkg/virtual/framework"
+	frameworkauthorization "github.com/kcp-dev/kcp/pkg/virtual/framework/authorization"
+	"github.com/kcp-dev/kcp/pkg/virtual/framework/handler"
+)
+
+const DashboardVirtualWorkspaceName string = "dashboard"
+
+// BuildVirtualWorkspace builds a virtual workspace serving, under <rootPathPrefix>/<workspace>,
+// aggregated JSON views of the workspace tree below <workspace>.
+func BuildVirtualWorkspace(
+	rootPathPrefix string,
+	wildcardClusterWorkspaces tenancyinformers.ClusterWorkspaceInformer,
+	wildcardAPIBindings apisinformers.APIBindingInformer,
+	wildcardNamespaces coreinformers.NamespaceInformer,
+	wildcardsRbacInformers rbacinformers.Interface,
+	kubeClusterClient kubernetes.ClusterInterface,
+) (framework.VirtualWorkspace, error) {
+	if !strings.HasSuffix(rootPathPrefix, "/") {
+		rootPathPrefix += "/"
+	}
+
+	informers := []cache.SharedIndexInformer{
+		wildcardClusterWorkspaces.Informer(),
+		wildcardAPIBindings.Informer(),
+		wildcardNamespaces.Informer(),
+	}
+	for _, informer := range informers {
+		if err := informer.AddIndexers(cache.Indexers{byLogicalCluster: indexByLogicalCluster}); err != nil {
+			return nil, err
+		}
+	}
+
+	return &handler.HandlerVirtualWorkspace{
+		Name: DashboardVirtualWorkspaceName,
+		Ready: func() error {
+			for _, informer := range informers {
+				if !informer.HasSynced() {
+					return errors.New("informers are not synced")
+				}
+			}
+			return nil
+		},
+		RootPathResolver: func(urlPath string, requestContext context.Context) (accepted bool, prefixToStrip string, completedContext context.Context) {
+			completedContext = requestContext
+			if path := urlPath; strings.HasPrefix(path, rootPathPrefix) {
+				path = strings.TrimPrefix(path, rootPathPrefix)
+				segments := strings.SplitN(path, "/", 2)
+				if segments[0] == "" {
+					return
+				}
+
+				return true, rootPathPrefix + segments[0],
+					context.WithValue(requestContext, aggregation.WorkspaceKey, logicalcluster.New(segments[0]))
+			}
+			return
+		},
+		BootstrapHandler: func(rootAPIServerConfig genericapiserver.CompletedConfig) (http.Handler, error) {
+			delegatedAuthzCache := frameworkauthorization.NewDelegatedAuthorizerCache(frameworkauthorization.DefaultDelegatedAuthorizerCacheTTL, wildcardsRbacInformers)
+
+			aggregator := aggregation.NewAggregator(
+				func(clusterName logicalcluster.Name) ([]*tenancyv1alpha1.ClusterWorkspace, error) {
+					objs, err := wildcardClusterWorkspaces.Informer().GetIndexer().ByIndex(byLogicalCluster, clusterName.String())
+					if err != nil {
+						return nil, err
+					}
+					ret := make([]*tenancyv1alpha1.ClusterWorkspace, 0, len(objs))
+					for _, obj := range objs {
+						ret = append(ret, obj.(*tenancyv1alpha1.ClusterWorkspace))
+					}
+					return ret, nil
+				},
+				func(clusterName logicalcluster.Name) ([]*apisv1alpha1.APIBinding, error) {
+					objs, err := wildcardAPIBindings.Informer().GetIndexer().ByIndex(byLogicalCluster, clusterName.String())
+					if err != nil {
+						return nil, err
+					}
+					ret := make([]*apisv1alpha1.APIBinding, 0, len(objs))
+					for _, obj := range objs {
+						ret = append(ret, obj.(*apisv1alpha1.APIBinding))
+					}
+					return ret, nil
+				},
+				func(clusterName logicalcluster.Name) ([]*corev1.Namespace, error) {
+					objs, err := wildcardNamespaces.Informer().GetIndexer().ByIndex(byLogicalCluster, clusterName.String())
+					if err != nil {
+						return nil, err
+					}
+					ret := make([]*corev1.Namespace, 0, len(objs))
+					for _, obj := range objs {
+						ret = append(ret, obj.(*corev1.Namespace))
+					}
+					return ret, nil
+				},
+				kubeClusterClient,
+				delegatedAuthzCache.Wrap(delegated.NewDelegatedAuthorizer),
+			)
+			return aggregation.NewHandler(aggregator), nil
+		},
+	}, nil
+}
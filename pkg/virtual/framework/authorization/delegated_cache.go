@@ -0,0 +1,207 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package authorization
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/kcp-dev/logicalcluster"
+
+	"k8s.io/apiserver/pkg/authorization/authorizer"
+	rbacinformers "k8s.io/client-go/informers/rbac/v1"
+	kubeclient "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+
+	"github.com/kcp-dev/kcp/pkg/authorization/delegated"
+)
+
+// DefaultDelegatedAuthorizerCacheTTL is the time authorization decisions are kept
+// in the DelegatedAuthorizerCache when no RBAC change happens in the logical cluster.
+const DefaultDelegatedAuthorizerCacheTTL = 10 * time.Second
+
+// DelegatedAuthorizerCache caches the decisions of delegated authorizers, i.e. of
+// SubjectAccessReviews, per logical cluster. Entries expire after a short TTL, and
+// all the entries of a logical cluster are dropped as soon as a Role, RoleBinding,
+// ClusterRole or ClusterRoleBinding of that logical cluster changes.
+type DelegatedAuthorizerCache struct {
+	ttl time.Duration
+	now func() time.Time
+
+	lock    sync.RWMutex
+	entries map[logicalcluster.Name]map[string]cachedDecision
+}
+
+type cachedDecision struct {
+	decision authorizer.Decision
+	reason   string
+	expiry   time.Time
+}
+
+// NewDelegatedAuthorizerCache returns a DelegatedAuthorizerCache keeping decisions for
+// the given TTL, and invalidated through the given wildcard RBAC informers.
+func NewDelegatedAuthorizerCache(ttl time.Duration, wildcardRbacInformers rbacinformers.Interface) *DelegatedAuthorizerCache {
+	c := &DelegatedAuthorizerCache{
+		ttl:     ttl,
+		now:     time.Now,
+		entries: map[logicalcluster.Name]map[string]cachedDecision{},
+	}
+
+	handler := cache.ResourceEventHandlerFuncs{
+		AddFunc:    c.invalidateObject,
+		UpdateFunc: func(_, obj interface{}) { c.invalidateObject(obj) },
+		DeleteFunc: c.invalidateObject,
+	}
+	for _, informer := range []cache.SharedIndexInformer{
+		wildcardRbacInformers.Roles().Informer(),
+		wildcardRbacInformers.RoleBindings().Informer(),
+		wildcardRbacInformers.ClusterRoles().Informer(),
+		wildcardRbacInformers.ClusterRoleBindings().Informer(),
+	} {
+		informer.AddEventHandler(handler)
+	}
+
+	return c
+}
+
+// Wrap returns a DelegatedAuthorizerFactory whose authorizers answer from the cache when
+// possible, and only create and call the authorizers of the given factory otherwise.
+func (c *DelegatedAuthorizerCache) Wrap(factory delegated.DelegatedAuthorizerFactory) delegated.DelegatedAuthorizerFactory {
+	return func(clusterName logicalcluster.Name, client kubeclient.ClusterInterface) (authorizer.Authorizer, error) {
+		return &cachingAuthorizer{
+			cache:       c,
+			clusterName: clusterName,
+			newDelegate: func() (authorizer.Authorizer, error) {
+				return factory(clusterName, client)
+			},
+		}, nil
+	}
+}
+
+// Invalidate drops all the cached decisions of the given logical cluster.
+func (c *DelegatedAuthorizerCache) Invalidate(clusterName logicalcluster.Name) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	delete(c.entries, clusterName)
+}
+
+func (c *DelegatedAuthorizerCache) invalidateObject(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	metaObj, ok := obj.(logicalcluster.Object)
+	if !ok {
+		return
+	}
+	c.Invalidate(logicalcluster.From(metaObj))
+}
+
+func (c *DelegatedAuthorizerCache) get(clusterName logicalcluster.Name, key string) (cachedDecision, bool) {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	entry, found := c.entries[clusterName][key]
+	if !found || !c.now().Before(entry.expiry) {
+		return cachedDecision{}, false
+	}
+	return entry, true
+}
+
+func (c *DelegatedAuthorizerCache) add(clusterName logicalcluster.Name, key string, decision authorizer.Decision, reason string) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	now := c.now()
+	entries, found := c.entries[clusterName]
+	if !found {
+		entries = map[string]cachedDecision{}
+		c.entries[clusterName] = entries
+	}
+	for k, entry := range entries {
+		if !now.Before(entry.expiry) {
+			delete(entries, k)
+		}
+	}
+	entries[key] = cachedDecision{decision: decision, reason: reason, expiry: now.Add(c.ttl)}
+}
+
+type cachingAuthorizer struct {
+	cache       *DelegatedAuthorizerCache
+	clusterName logicalcluster.Name
+	newDelegate func() (authorizer.Authorizer, error)
+}
+
+func (a *cachingAuthorizer) Authorize(ctx context.Context, attr authorizer.Attributes) (authorizer.Decision, string, error) {
+	key := cacheKey(attr)
+	if entry, found := a.cache.get(a.clusterName, key); found {
+		return entry.decision, entry.reason, nil
+	}
+
+	delegate, err := a.newDelegate()
+	if err != nil {
+		return authorizer.DecisionNoOpinion, "", err
+	}
+	decision, reason, err := delegate.Authorize(ctx, attr)
+	if err != nil {
+		return decision, reason, err
+	}
+
+	a.cache.add(a.clusterName, key, decision, reason)
+	return decision, reason, nil
+}
+
+// cacheKey identifies the user and the request of the given attributes.
+func cacheKey(attr authorizer.Attributes) string {
+	var b strings.Builder
+	write := func(values ...string) {
+		for _, v := range values {
+			b.WriteString(v)
+			b.WriteByte(0)
+		}
+	}
+
+	if user := attr.GetUser(); user != nil {
+		write(user.GetName(), user.GetUID())
+		groups := append([]string(nil), user.GetGroups()...)
+		sort.Strings(groups)
+		write(groups...)
+		b.WriteByte(1)
+
+		extra := user.GetExtra()
+		keys := make([]string, 0, len(extra))
+		for k := range extra {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			write(k)
+			write(extra[k]...)
+			b.WriteByte(1)
+		}
+	}
+	b.WriteByte(2)
+
+	if attr.IsResourceRequest() {
+		write("resource", attr.GetVerb(), attr.GetAPIGroup(), attr.GetAPIVersion(), attr.GetResource(), attr.GetSubresource(), attr.GetNamespace(), attr.GetName())
+	} else {
+		write("nonresource", attr.GetVerb(), attr.GetPath())
+	}
+
+	return b.String()
+}
@@ -0,0 +1,116 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package authorization
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kcp-dev/logicalcluster"
+	"github.com/stretchr/testify/require"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/apiserver/pkg/authorization/authorizer"
+	kubeinformers "k8s.io/client-go/informers"
+	kubeclient "k8s.io/client-go/kubernetes"
+	kubefake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+)
+
+type countingAuthorizer struct {
+	calls    map[logicalcluster.Name]int
+	decision authorizer.Decision
+}
+
+func (a *countingAuthorizer) factory(clusterName logicalcluster.Name, _ kubeclient.ClusterInterface) (authorizer.Authorizer, error) {
+	return authorizer.AuthorizerFunc(func(ctx context.Context, attr authorizer.Attributes) (authorizer.Decision, string, error) {
+		a.calls[clusterName]++
+		return a.decision, "", nil
+	}), nil
+}
+
+func TestDelegatedAuthorizerCache(t *testing.T) {
+	informers := kubeinformers.NewSharedInformerFactory(kubefake.NewSimpleClientset(), 0)
+	c := NewDelegatedAuthorizerCache(time.Minute, informers.Rbac().V1())
+	now := time.Now()
+	c.now = func() time.Time { return now }
+
+	delegate := &countingAuthorizer{calls: map[logicalcluster.Name]int{}, decision: authorizer.DecisionAllow}
+	factory := c.Wrap(delegate.factory)
+
+	org, other := logicalcluster.New("root:org"), logicalcluster.New("root:other")
+	orgAuthz, err := factory(org, nil)
+	require.NoError(t, err)
+	otherAuthz, err := factory(other, nil)
+	require.NoError(t, err)
+
+	attr := authorizer.AttributesRecord{
+		User:            &user.DefaultInfo{Name: "alice", Groups: []string{"b", "a"}},
+		Verb:            "get",
+		APIGroup:        "tenancy.kcp.dev",
+		Resource:        "clusterworkspaces",
+		Subresource:     "content",
+		Name:            "ws",
+		ResourceRequest: true,
+	}
+	authorize := func(authz authorizer.Authorizer, attr authorizer.AttributesRecord) {
+		decision, _, err := authz.Authorize(context.Background(), attr)
+		require.NoError(t, err)
+		require.Equal(t, authorizer.DecisionAllow, decision)
+	}
+
+	authorize(orgAuthz, attr)
+	authorize(orgAuthz, attr)
+	require.Equal(t, 1, delegate.calls[org], "the second call should have been answered by the cache")
+
+	sameGroups := attr
+	sameGroups.User = &user.DefaultInfo{Name: "alice", Groups: []string{"a", "b"}}
+	authorize(orgAuthz, sameGroups)
+	require.Equal(t, 1, delegate.calls[org], "the order of groups should not matter")
+
+	otherVerb := attr
+	otherVerb.Verb = "delete"
+	authorize(orgAuthz, otherVerb)
+	require.Equal(t, 2, delegate.calls[org], "another verb should not be answered by the cache")
+
+	otherUser := attr
+	otherUser.User = &user.DefaultInfo{Name: "bob", Groups: []string{"a", "b"}}
+	authorize(orgAuthz, otherUser)
+	require.Equal(t, 3, delegate.calls[org], "another user should not be answered by the cache")
+
+	authorize(otherAuthz, attr)
+	require.Equal(t, 1, delegate.calls[other], "another logical cluster should not be answered by the cache")
+
+	// an RBAC change in the org drops its decisions only
+	c.invalidateObject(&rbacv1.RoleBinding{ObjectMeta: metav1.ObjectMeta{Name: "binding", ClusterName: org.String()}})
+	authorize(orgAuthz, attr)
+	require.Equal(t, 4, delegate.calls[org], "decisions should have been invalidated by the RBAC change")
+	authorize(otherAuthz, attr)
+	require.Equal(t, 1, delegate.calls[other], "decisions of other logical clusters should not have been invalidated")
+
+	c.invalidateObject(cache.DeletedFinalStateUnknown{Obj: &rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: "role", ClusterName: other.String()}}})
+	authorize(otherAuthz, attr)
+	require.Equal(t, 2, delegate.calls[other], "decisions should have been invalidated by the deleted RBAC object")
+
+	// entries expire after the TTL
+	now = now.Add(time.Minute)
+	authorize(orgAuthz, attr)
+	require.Equal(t, 5, delegate.calls[org], "decisions should have expired")
+}
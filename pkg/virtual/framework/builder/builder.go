@@ -0,0 +1,228 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package builder
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/kcp-dev/logicalcluster"
+
+	"k8s.io/apiserver/pkg/authorization/authorizer"
+	genericapirequest "k8s.io/apiserver/pkg/endpoints/request"
+	genericapiserver "k8s.io/apiserver/pkg/server"
+
+	"github.com/kcp-dev/kcp/pkg/virtual/framework"
+	"github.com/kcp-dev/kcp/pkg/virtual/framework/dynamic"
+	"github.com/kcp-dev/kcp/pkg/virtual/framework/dynamic/apidefinition"
+	"github.com/kcp-dev/kcp/pkg/virtual/framework/fixedgvs"
+)
+
+// PathSegmentsInjectorFunc injects the given URL path segments into the context of a request.
+// It returns false if the request should not be accepted by the virtual workspace.
+type PathSegmentsInjectorFunc func(ctx context.Context, segments []string) (context.Context, bool)
+
+type pathSegments struct {
+	count  int
+	inject PathSegmentsInjectorFunc
+}
+
+// Builder assembles a framework.VirtualWorkspace serving requests under a root path prefix.
+type Builder struct {
+	name             string
+	rootPathPrefix   string
+	segments         []pathSegments
+	clusterInjection bool
+	readyChecks      []framework.ReadyFunc
+	rejectUntilReady bool
+	authorizer       authorizer.Authorizer
+}
+
+// New returns a Builder for a virtual workspace with the given name, serving requests under the given root path prefix.
+func New(name, rootPathPrefix string) *Builder {
+	if !strings.HasSuffix(rootPathPrefix, "/") {
+		rootPathPrefix += "/"
+	}
+	return &Builder{
+		name:           name,
+		rootPathPrefix: rootPathPrefix,
+	}
+}
+
+// WithPathSegments consumes the given number of non-empty URL path segments, following the root path prefix
+// and the segments consumed before, and passes them to the injector.
+func (b *Builder) WithPathSegments(count int, inject PathSegmentsInjectorFunc) *Builder {
+	b.segments = append(b.segments, pathSegments{count: count, inject: inject})
+	return b
+}
+
+// WithClusterInjection parses an optional /clusters/<logical-cluster> path following the consumed segments,
+// and injects the logical cluster into the request context. Without it, or for /clusters/*,
+// the wildcard cluster is injected.
+func (b *Builder) WithClusterInjection() *Builder {
+	b.clusterInjection = true
+	return b
+}
+
+// WithReadyCheck adds a readiness check to the virtual workspace.
+func (b *Builder) WithReadyCheck(ready framework.ReadyFunc) *Builder {
+	b.readyChecks = append(b.readyChecks, ready)
+	return b
+}
+
+// RejectRequestsUntilReady makes the virtual workspace not accept any request until it is ready.
+func (b *Builder) RejectRequestsUntilReady() *Builder {
+	b.rejectUntilReady = true
+	return b
+}
+
+// WithAuthorizer authorizes the requests accepted by the virtual workspace, on top of
+// the authorization of the root API server.
+func (b *Builder) WithAuthorizer(authz authorizer.Authorizer) *Builder {
+	b.authorizer = authz
+	return b
+}
+
+// Ready returns the readiness check of the virtual workspace, failing as long as one of the ready checks fails.
+func (b *Builder) Ready() framework.ReadyFunc {
+	readyChecks := append([]framework.ReadyFunc(nil), b.readyChecks...)
+	return func() error {
+		for _, ready := range readyChecks {
+			if err := ready(); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// RootPathResolver returns the root path resolver of the virtual workspace.
+func (b *Builder) RootPathResolver() framework.RootPathResolverFunc {
+	rootPathPrefix := b.rootPathPrefix
+	segments := append([]pathSegments(nil), b.segments...)
+	clusterInjection := b.clusterInjection
+	ready := b.Ready()
+	rejectUntilReady := b.rejectUntilReady
+
+	return func(urlPath string, requestContext context.Context) (accepted bool, prefixToStrip string, completedContext context.Context) {
+		completedContext = requestContext
+		if rejectUntilReady && ready() != nil {
+			return
+		}
+		if !strings.HasPrefix(urlPath, rootPathPrefix) {
+			return
+		}
+		rest := strings.TrimPrefix(urlPath, rootPathPrefix)
+
+		ctx := requestContext
+		for i, s := range segments {
+			parts := strings.SplitN(rest, "/", s.count+1)
+			if len(parts) < s.count {
+				return
+			}
+			for _, part := range parts[:s.count] {
+				if part == "" {
+					return
+				}
+			}
+			var ok bool
+			if ctx, ok = s.inject(ctx, parts[:s.count]); !ok {
+				return
+			}
+
+			rest = ""
+			if len(parts) > s.count {
+				rest = parts[s.count]
+			} else if i < len(segments)-1 {
+				return
+			}
+		}
+		realPath := "/" + rest
+
+		if clusterInjection {
+			cluster := genericapirequest.Cluster{Name: logicalcluster.Wildcard, Wildcard: true}
+			if strings.HasPrefix(realPath, "/clusters/") {
+				parts := strings.SplitN(strings.TrimPrefix(realPath, "/clusters/"), "/", 2)
+				if parts[0] == "" {
+					return
+				}
+				realPath = "/"
+				if len(parts) > 1 {
+					realPath += parts[1]
+				}
+				if parts[0] != "*" {
+					cluster = genericapirequest.Cluster{Name: logicalcluster.New(parts[0])}
+				}
+			}
+			ctx = genericapirequest.WithCluster(ctx, cluster)
+		}
+
+		return true, strings.TrimSuffix(urlPath, realPath), ctx
+	}
+}
+
+// BuildDynamic builds a virtual workspace dynamically serving the APIs of the APIDefinitionSetGetter
+// returned by bootstrapAPISetManagement. See dynamic.DynamicVirtualWorkspace for details.
+func (b *Builder) BuildDynamic(bootstrapAPISetManagement func(mainConfig genericapiserver.CompletedConfig) (apidefinition.APIDefinitionSetGetter, error)) framework.VirtualWorkspace {
+	return b.withAuthorizer(&dynamic.DynamicVirtualWorkspace{
+		Name:                      b.name,
+		RootPathResolver:          b.RootPathResolver(),
+		Ready:                     b.Ready(),
+		BootstrapAPISetManagement: bootstrapAPISetManagement,
+	})
+}
+
+// BuildFixedGroupVersions builds a virtual workspace serving the given group/versions.
+// See fixedgvs.FixedGroupVersionsVirtualWorkspace for details.
+func (b *Builder) BuildFixedGroupVersions(groupVersionAPISets ...fixedgvs.GroupVersionAPISet) framework.VirtualWorkspace {
+	return b.withAuthorizer(&fixedgvs.FixedGroupVersionsVirtualWorkspace{
+		Name:                b.name,
+		RootPathResolver:    b.RootPathResolver(),
+		Ready:               b.Ready(),
+		GroupVersionAPISets: groupVersionAPISets,
+	})
+}
+
+func (b *Builder) withAuthorizer(virtualWorkspace framework.VirtualWorkspace) framework.VirtualWorkspace {
+	if b.authorizer == nil {
+		return virtualWorkspace
+	}
+	return &authorizingVirtualWorkspace{
+		VirtualWorkspace: virtualWorkspace,
+		Authorizer:       b.authorizer,
+	}
+}
+
+var _ framework.AuthorizingVirtualWorkspace = (*authorizingVirtualWorkspace)(nil)
+
+type authorizingVirtualWorkspace struct {
+	framework.VirtualWorkspace
+	authorizer.Authorizer
+}
+
+// ReadyChannel returns a ready check succeeding once the given channel is closed.
+func ReadyChannel(readyCh <-chan struct{}, notReadyMessage string) framework.ReadyFunc {
+	return func() error {
+		select {
+		case <-readyCh:
+			return nil
+		default:
+			return errors.New(notReadyMessage)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package builder
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/kcp-dev/logicalcluster"
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/apiserver/pkg/authorization/authorizer"
+	genericapirequest "k8s.io/apiserver/pkg/endpoints/request"
+
+	"github.com/kcp-dev/kcp/pkg/virtual/framework"
+)
+
+type segmentsKeyType string
+
+const segmentsKey segmentsKeyType = "segments"
+
+func TestRootPathResolver(t *testing.T) {
+	injectSegments := func(ctx context.Context, segments []string) (context.Context, bool) {
+		if segments[0] == "rejected" {
+			return ctx, false
+		}
+		return context.WithValue(ctx, segmentsKey, segments), true
+	}
+
+	tests := map[string]struct {
+		builder *Builder
+		path    string
+
+		wantAccepted bool
+		wantPrefix   string
+		wantSegments []string
+		wantCluster  *genericapirequest.Cluster
+	}{
+		"other prefix": {
+			builder: New("test", "/services/test").WithPathSegments(1, injectSegments),
+			path:    "/services/other/foo/api/v1",
+		},
+		"prefix only": {
+			builder: New("test", "/services/test").WithPathSegments(1, injectSegments),
+			path:    "/services/test/",
+		},
+		"segments": {
+			builder:      New("test", "/services/test").WithPathSegments(2, injectSegments),
+			path:         "/services/test/foo/bar/api/v1/configmaps",
+			wantAccepted: true,
+			wantPrefix:   "/services/test/foo/bar",
+			wantSegments: []string{"foo", "bar"},
+		},
+		"segments without path": {
+			builder:      New("test", "/services/test").WithPathSegments(2, injectSegments),
+			path:         "/services/test/foo/bar",
+			wantAccepted: true,
+			wantPrefix:   "/services/test/foo/bar",
+			wantSegments: []string{"foo", "bar"},
+		},
+		"missing segment": {
+			builder: New("test", "/services/test").WithPathSegments(2, injectSegments),
+			path:    "/services/test/foo",
+		},
+		"empty segment": {
+			builder: New("test", "/services/test").WithPathSegments(2, injectSegments),
+			path:    "/services/test/foo//api/v1",
+		},
+		"rejected by injector": {
+			builder: New("test", "/services/test").WithPathSegments(1, injectSegments),
+			path:    "/services/test/rejected/api/v1",
+		},
+		"wildcard cluster by default": {
+			builder:      New("test", "/services/test").WithPathSegments(1, injectSegments).WithClusterInjection(),
+			path:         "/services/test/foo/api/v1",
+			wantAccepted: true,
+			wantPrefix:   "/services/test/foo",
+			wantSegments: []string{"foo"},
+			wantCluster:  &genericapirequest.Cluster{Name: logicalcluster.Wildcard, Wildcard: true},
+		},
+		"explicit wildcard cluster": {
+			builder:      New("test", "/services/test").WithPathSegments(1, injectSegments).WithClusterInjection(),
+			path:         "/services/test/foo/clusters/*/api/v1",
+			wantAccepted: true,
+			wantPrefix:   "/services/test/foo/clusters/*",
+			wantSegments: []string{"foo"},
+			wantCluster:  &genericapirequest.Cluster{Name: logicalcluster.Wildcard, Wildcard: true},
+		},
+		"logical cluster": {
+			builder:      New("test", "/services/test").WithPathSegments(1, injectSegments).WithClusterInjection(),
+			path:         "/services/test/foo/clusters/root:org/api/v1",
+			wantAccepted: true,
+			wantPrefix:   "/services/test/foo/clusters/root:org",
+			wantSegments: []string{"foo"},
+			wantCluster:  &genericapirequest.Cluster{Name: logicalcluster.New("root:org")},
+		},
+		"empty logical cluster": {
+			builder: New("test", "/services/test").WithPathSegments(1, injectSegments).WithClusterInjection(),
+			path:    "/services/test/foo/clusters//api/v1",
+		},
+		"not ready": {
+			builder: New("test", "/services/test").WithPathSegments(1, injectSegments).
+				WithReadyCheck(func() error { return errors.New("not ready") }).
+				RejectRequestsUntilReady(),
+			path: "/services/test/foo/api/v1",
+		},
+		"not ready but accepting": {
+			builder: New("test", "/services/test").WithPathSegments(1, injectSegments).
+				WithReadyCheck(func() error { return errors.New("not ready") }),
+			path:         "/services/test/foo/api/v1",
+			wantAccepted: true,
+			wantPrefix:   "/services/test/foo",
+			wantSegments: []string{"foo"},
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			accepted, prefix, ctx := tc.builder.RootPathResolver()(tc.path, context.Background())
+			require.Equal(t, tc.wantAccepted, accepted)
+			if !tc.wantAccepted {
+				return
+			}
+			require.Equal(t, tc.wantPrefix, prefix)
+			require.Equal(t, tc.wantSegments, ctx.Value(segmentsKey))
+			cluster := genericapirequest.ClusterFrom(ctx)
+			require.Equal(t, tc.wantCluster, cluster)
+		})
+	}
+}
+
+func TestBuildWithAuthorizer(t *testing.T) {
+	vw := New("test", "/services/test").BuildDynamic(nil)
+	_, ok := vw.(framework.AuthorizingVirtualWorkspace)
+	require.False(t, ok, "virtual workspace without authorizer should not authorize requests")
+
+	vw = New("test", "/services/test").
+		WithAuthorizer(authorizer.AuthorizerFunc(func(ctx context.Context, a authorizer.Attributes) (authorizer.Decision, string, error) {
+			return authorizer.DecisionDeny, "denied", nil
+		})).
+		BuildFixedGroupVersions()
+	authz, ok := vw.(framework.AuthorizingVirtualWorkspace)
+	require.True(t, ok, "virtual workspace with authorizer should authorize requests")
+	require.Equal(t, "test", authz.GetName())
+	decision, reason, err := authz.Authorize(context.Background(), &authorizer.AttributesRecord{})
+	require.NoError(t, err)
+	require.Equal(t, authorizer.DecisionDeny, decision)
+	require.Equal(t, "denied", reason)
+}
@@ -0,0 +1,37 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package builder provides a declarative way to assemble virtual workspaces
+// from the reusable parts of the framework, instead of writing the root path
+// resolution, context injection, readiness and authorization logic by hand.
+//
+// A typical virtual workspace serving APIs of a logical cluster, chosen by the
+// first segment of the path after the root path prefix, would be built like this:
+//
+//	builder.New("my-virtual-workspace", "/services/my-virtual-workspace").
+//		WithPathSegments(1, func(ctx context.Context, segments []string) (context.Context, bool) {
+//			return dynamiccontext.WithAPIDomainKey(ctx, dynamiccontext.APIDomainKey(segments[0])), true
+//		}).
+//		WithClusterInjection().
+//		WithAuthorizer(authz).
+//		BuildDynamic(bootstrapAPISetManagement)
+//
+// which serves requests at paths like
+// /services/my-virtual-workspace/<api-domain>/clusters/<logical-cluster>/apis/...
+//
+// Resources of such a dynamic virtual workspace are usually served by REST storages
+// forwarding to kcp through the forwardingregistry package, as provided by ForwardingRestStorage.
+package builder
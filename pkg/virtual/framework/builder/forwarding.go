@@ -28,12 +28,14 @@ import (
 	"k8s.io/client-go/dynamic"
 	"k8s.io/kube-openapi/pkg/validation/validate"
 
-	workloadv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/workload/v1alpha1"
 	"github.com/kcp-dev/kcp/pkg/virtual/framework/dynamic/apiserver"
 	registry "github.com/kcp-dev/kcp/pkg/virtual/framework/forwardingregistry"
 )
 
-func provideForwardingRestStorage(ctx context.Context, clusterClient dynamic.ClusterInterface, workloadClusterName string) apiserver.RestProviderFunc {
+// ForwardingRestStorage returns a RestProviderFunc for dynamic virtual workspaces, which creates
+// forwardingregistry REST storages forwarding the requests to the given cluster client,
+// restricted to the objects matching the given label selector.
+func ForwardingRestStorage(ctx context.Context, clusterClient dynamic.ClusterInterface, labelSelector map[string]string) apiserver.RestProviderFunc {
 	return func(resource schema.GroupVersionResource, kind schema.GroupVersionKind, listKind schema.GroupVersionKind, typer runtime.ObjectTyper, tableConvertor rest.TableConvertor, namespaceScoped bool, schemaValidator *validate.SchemaValidator, subresourcesSchemaValidator map[string]*validate.SchemaValidator, structuralSchema *structuralschema.Structural) (mainStorage rest.Storage, subresourceStorages map[string]rest.Storage) {
 		statusSchemaValidate, statusEnabled := subresourcesSchemaValidator["status"]
 
@@ -67,7 +69,7 @@ func provideForwardingRestStorage(ctx context.Context, clusterClient dynamic.Clu
 			nil,
 			clusterClient,
 			nil,
-			map[string]string{workloadv1alpha1.InternalClusterResourceStateLabelPrefix + workloadClusterName: string(workloadv1alpha1.ResourceStateSync)},
+			labelSelector,
 		)
 
 		subresourceStorages = make(map[string]rest.Storage)
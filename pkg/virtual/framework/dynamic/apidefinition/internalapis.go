@@ -65,6 +65,16 @@ var KCPInternalAPIs = []InternalAPI{
 		instance: &corev1.ConfigMap{},
 		scope:    apiextensionsv1.NamespaceScoped,
 	},
+	{
+		names: apiextensionsv1.CustomResourceDefinitionNames{
+			Plural:   "limitranges",
+			Singular: "limitrange",
+			Kind:     "LimitRange",
+		},
+		gv:       schema.GroupVersion{Group: "", Version: "v1"},
+		instance: &corev1.LimitRange{},
+		scope:    apiextensionsv1.NamespaceScoped,
+	},
 	{
 		names: apiextensionsv1.CustomResourceDefinitionNames{
 			Plural:   "secrets",
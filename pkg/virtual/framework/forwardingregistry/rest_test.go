@@ -328,6 +328,47 @@ func TestUpdate(t *testing.T) {
 
 }
 
+func TestUpdateCreate(t *testing.T) {
+	resource := createResource("default", "foo")
+	fakeClient := fake.NewSimpleDynamicClient(runtime.NewScheme())
+
+	storage := newStorage(t, &mockedClusterClient{fakeClient}, nil)
+	ctx := request.WithNamespace(context.Background(), "default")
+	ctx = request.WithCluster(ctx, request.Cluster{Name: logicalcluster.New("foo")})
+
+	_, _, err := storage.CustomResource.Update(ctx, resource.GetName(), rest.DefaultUpdatedObjectInfo(resource), rest.ValidateAllObjectFunc, rest.ValidateAllObjectUpdateFunc, false, &metav1.UpdateOptions{})
+	require.EqualError(t, err, "noxus.mygroup.example.com \"foo\" not found")
+
+	withStatus := resource.DeepCopy()
+	err = unstructured.SetNestedField(withStatus.UnstructuredContent(), int64(10), "status", "availableReplicas")
+	require.NoError(t, err)
+
+	result, created, err := storage.CustomResource.Update(ctx, resource.GetName(), rest.DefaultUpdatedObjectInfo(withStatus), rest.ValidateAllObjectFunc, rest.ValidateAllObjectUpdateFunc, true, &metav1.UpdateOptions{})
+	require.NoError(t, err)
+	require.True(t, created, "Object should have been created")
+
+	// The create strategy drops the status and sets the initial generation.
+	resultResource := result.(*unstructured.Unstructured)
+	require.Equal(t, int64(1), resultResource.GetGeneration())
+	_, found, err := unstructured.NestedFieldNoCopy(resultResource.UnstructuredContent(), "status")
+	require.NoError(t, err)
+	require.False(t, found, "Status should not be set on create")
+
+	creates := 0
+	for _, action := range fakeClient.Actions() {
+		if action.GetVerb() == "create" {
+			creates++
+		}
+	}
+	require.Equal(t, 1, creates)
+
+	_, err = fakeClient.Tracker().Get(schema.GroupVersionResource{Group: "mygroup.example.com", Version: "v1beta1", Resource: "noxus"}, "default", "foo")
+	require.NoError(t, err)
+
+	_, _, err = storage.Status.Update(ctx, "bar", rest.DefaultUpdatedObjectInfo(createResource("default", "bar")), rest.ValidateAllObjectFunc, rest.ValidateAllObjectUpdateFunc, true, &metav1.UpdateOptions{})
+	require.EqualError(t, err, "noxus.mygroup.example.com \"bar\" not found", "Subresources should never be created on update")
+}
+
 func TestStatusUpdate(t *testing.T) {
 	resource := createResource("default", "foo")
 	resource.SetGeneration(1)
@@ -441,3 +482,100 @@ func TestPatch(t *testing.T) {
 	}
 	require.Equalf(t, backoff.Steps, updates, "Should have tried calling client.Update %d times to overcome resourceVersion conflicts, before finally returning a Conflict error.", backoff.Steps)
 }
+
+// deleteOptionsRecordingClusterClient records the options of delete calls, which the fake dynamic client drops.
+type deleteOptionsRecordingClusterClient struct {
+	client  *fake.FakeDynamicClient
+	options *[]metav1.DeleteOptions
+}
+
+func (c *deleteOptionsRecordingClusterClient) Cluster(cluster logicalcluster.Name) dynamic.Interface {
+	return c
+}
+
+func (c *deleteOptionsRecordingClusterClient) Resource(resource schema.GroupVersionResource) dynamic.NamespaceableResourceInterface {
+	return &deleteOptionsRecordingResource{c.client.Resource(resource), c.options}
+}
+
+type deleteOptionsRecordingResource struct {
+	dynamic.NamespaceableResourceInterface
+	options *[]metav1.DeleteOptions
+}
+
+func (r *deleteOptionsRecordingResource) Namespace(namespace string) dynamic.ResourceInterface {
+	return &deleteOptionsRecordingNamespacedResource{r.NamespaceableResourceInterface.Namespace(namespace), r.options}
+}
+
+func (r *deleteOptionsRecordingResource) Delete(ctx context.Context, name string, options metav1.DeleteOptions, subresources ...string) error {
+	*r.options = append(*r.options, options)
+	return r.NamespaceableResourceInterface.Delete(ctx, name, options, subresources...)
+}
+
+type deleteOptionsRecordingNamespacedResource struct {
+	dynamic.ResourceInterface
+	options *[]metav1.DeleteOptions
+}
+
+func (r *deleteOptionsRecordingNamespacedResource) Delete(ctx context.Context, name string, options metav1.DeleteOptions, subresources ...string) error {
+	*r.options = append(*r.options, options)
+	return r.ResourceInterface.Delete(ctx, name, options, subresources...)
+}
+
+func finalizingDeleteReactor(fakeClient *fake.FakeDynamicClient) kubernetestesting.ReactionFunc {
+	return func(action kubernetestesting.Action) (handled bool, ret runtime.Object, err error) {
+		deleteAction := action.(kubernetestesting.DeleteAction)
+		existingObject, err := fakeClient.Tracker().Get(action.GetResource(), action.GetNamespace(), deleteAction.GetName())
+		if err != nil {
+			return true, nil, err
+		}
+		existingResource := existingObject.(*unstructured.Unstructured)
+		if len(existingResource.GetFinalizers()) == 0 {
+			return false, nil, nil
+		}
+
+		now := metav1.Now()
+		existingResource.SetDeletionTimestamp(&now)
+		if err := fakeClient.Tracker().Update(action.GetResource(), existingResource, action.GetNamespace()); err != nil {
+			return true, nil, err
+		}
+		return true, nil, nil
+	}
+}
+
+func TestDelete(t *testing.T) {
+	resource := createResource("default", "foo")
+	resource.SetUID("uid-foo")
+	finalizing := createResource("default", "bar")
+	finalizing.SetFinalizers([]string{"example.com/finalizer"})
+	fakeClient := fake.NewSimpleDynamicClient(runtime.NewScheme(), resource, finalizing)
+	fakeClient.PrependReactor("delete", "noxus", finalizingDeleteReactor(fakeClient))
+
+	var deleteOptions []metav1.DeleteOptions
+	storage := newStorage(t, &deleteOptionsRecordingClusterClient{fakeClient, &deleteOptions}, nil)
+	ctx := request.WithNamespace(context.Background(), "default")
+	ctx = request.WithCluster(ctx, request.Cluster{Name: logicalcluster.New("foo")})
+
+	_, _, err := storage.CustomResource.Delete(ctx, "missing", rest.ValidateAllObjectFunc, &metav1.DeleteOptions{})
+	require.EqualError(t, err, "noxus.mygroup.example.com \"missing\" not found")
+
+	orphan := metav1.DeletePropagationOrphan
+	result, deleted, err := storage.CustomResource.Delete(ctx, "foo", rest.ValidateAllObjectFunc, &metav1.DeleteOptions{PropagationPolicy: &orphan})
+	require.NoError(t, err)
+	require.True(t, deleted, "Object without finalizers should be deleted immediately")
+	require.Equal(t, "foo", result.(*unstructured.Unstructured).GetName())
+
+	require.Len(t, deleteOptions, 1)
+	require.Equal(t, &orphan, deleteOptions[0].PropagationPolicy)
+	require.NotNil(t, deleteOptions[0].Preconditions)
+	require.Equal(t, resource.GetUID(), *deleteOptions[0].Preconditions.UID)
+
+	result, deleted, err = storage.CustomResource.Delete(ctx, "bar", rest.ValidateAllObjectFunc, &metav1.DeleteOptions{})
+	require.NoError(t, err)
+	require.False(t, deleted, "Object with finalizers should not be deleted immediately")
+	require.NotNil(t, result.(*unstructured.Unstructured).GetDeletionTimestamp(), "Object with finalizers should be returned in the deleting state")
+
+	_, _, err = storage.CustomResource.Delete(ctx, "bar", func(ctx context.Context, obj runtime.Object) error {
+		return errors.NewForbidden(schema.GroupResource{Group: "mygroup.example.com", Resource: "noxus"}, "bar", fmt.Errorf("denied"))
+	}, &metav1.DeleteOptions{})
+	require.True(t, errors.IsForbidden(err), "Delete validation errors should be returned")
+}
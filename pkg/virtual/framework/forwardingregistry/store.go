@@ -179,46 +179,84 @@ func (s *Store) Update(ctx context.Context, name string, objInfo rest.UpdatedObj
 		return nil, false, err
 	}
 
-	doUpdate := func() (*unstructured.Unstructured, error) {
+	doUpdate := func() (*unstructured.Unstructured, bool, error) {
 		oldObj, err := s.Get(ctx, name, &metav1.GetOptions{})
 		if err != nil {
-			return nil, err
+			// Continue on a NotFound error only for create-on-update of the main resource,
+			// subresources cannot be created.
+			if !kerrors.IsNotFound(err) || !forceAllowCreate || len(s.subResources) > 0 {
+				return nil, false, err
+			}
+			result, err := s.createOnUpdate(ctx, delegate, name, objInfo, createValidation, options)
+			return result, err == nil, err
 		}
 
 		obj, err := objInfo.UpdatedObject(ctx, oldObj)
 		if err != nil {
-			return nil, err
+			return nil, false, err
 		}
 
 		unstructuredObj, ok := obj.(*unstructured.Unstructured)
 		if !ok {
-			return nil, fmt.Errorf("not an Unstructured: %#v", obj)
+			return nil, false, fmt.Errorf("not an Unstructured: %#v", obj)
 		}
 
 		s.UpdateStrategy.PrepareForUpdate(ctx, obj, oldObj)
 		if errs := s.UpdateStrategy.ValidateUpdate(ctx, obj, oldObj); len(errs) > 0 {
-			return nil, kerrors.NewInvalid(unstructuredObj.GroupVersionKind().GroupKind(), unstructuredObj.GetName(), errs)
+			return nil, false, kerrors.NewInvalid(unstructuredObj.GroupVersionKind().GroupKind(), unstructuredObj.GetName(), errs)
 		}
 		if err := updateValidation(ctx, obj.DeepCopyObject(), oldObj.DeepCopyObject()); err != nil {
-			return nil, err
+			return nil, false, err
 		}
 
-		return delegate.Update(ctx, unstructuredObj, *options, s.subResources...)
+		result, err := delegate.Update(ctx, unstructuredObj, *options, s.subResources...)
+		return result, false, err
 	}
 
 	requestInfo, _ := genericapirequest.RequestInfoFrom(ctx)
 	if requestInfo != nil && requestInfo.Verb == "patch" {
 		var result *unstructured.Unstructured
+		var created bool
 		err := retry.RetryOnConflict(s.patchConflictRetryBackoff, func() error {
 			var err error
-			result, err = doUpdate()
+			result, created, err = doUpdate()
 			return err
 		})
-		return result, false, err
+		return result, created, err
 	}
 
-	result, err := doUpdate()
-	return result, false, err
+	return doUpdate()
+}
+
+// createOnUpdate creates the object returned by objInfo when it does not exist yet,
+// honoring the CreateStrategy the same way a create request would.
+func (s *Store) createOnUpdate(ctx context.Context, delegate dynamic.ResourceInterface, name string, objInfo rest.UpdatedObjectInfo, createValidation rest.ValidateObjectFunc, options *metav1.UpdateOptions) (*unstructured.Unstructured, error) {
+	obj, err := objInfo.UpdatedObject(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	unstructuredObj, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return nil, fmt.Errorf("not an Unstructured: %#v", obj)
+	}
+	if unstructuredObj.GetName() != name {
+		return nil, kerrors.NewBadRequest(fmt.Sprintf("name %q in the object does not match the name %q in the request", unstructuredObj.GetName(), name))
+	}
+
+	if err := rest.BeforeCreate(s.CreateStrategy, ctx, obj); err != nil {
+		return nil, err
+	}
+	if createValidation != nil {
+		if err := createValidation(ctx, obj.DeepCopyObject()); err != nil {
+			return nil, err
+		}
+	}
+	if !matches(s.labelSelector, unstructuredObj) {
+		return nil, kerrors.NewForbidden(s.DefaultQualifiedResource, name, fmt.Errorf("object labels do not match the selector %q", toExpression(s.labelSelector)))
+	}
+
+	return delegate.Create(ctx, unstructuredObj, metav1.CreateOptions{DryRun: options.DryRun, FieldManager: options.FieldManager, FieldValidation: options.FieldValidation})
 }
 
 func (s *Store) Create(ctx context.Context, obj runtime.Object, createValidation rest.ValidateObjectFunc, options *metav1.CreateOptions) (runtime.Object, error) {
@@ -226,9 +264,68 @@ func (s *Store) Create(ctx context.Context, obj runtime.Object, createValidation
 	panic("implement me")
 }
 
+// Delete implements rest.GracefulDeleter. The delete options, including the propagation policy,
+// are forwarded to the delegate. If the object still exists afterwards, e.g. because of pending
+// finalizers, it is returned with deleted set to false, like a regular apiserver would do.
 func (s *Store) Delete(ctx context.Context, name string, deleteValidation rest.ValidateObjectFunc, options *metav1.DeleteOptions) (runtime.Object, bool, error) {
-	//TODO implement me
-	panic("implement me")
+	if len(s.subResources) > 0 {
+		return nil, false, kerrors.NewMethodNotSupported(s.DefaultQualifiedResource, "delete")
+	}
+
+	delegate, err := s.getClientResource(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if options == nil {
+		options = &metav1.DeleteOptions{}
+	}
+
+	existing, err := s.Get(ctx, name, &metav1.GetOptions{})
+	if err != nil {
+		return nil, false, err
+	}
+	oldObj, ok := existing.(*unstructured.Unstructured)
+	if !ok {
+		return nil, false, fmt.Errorf("not an Unstructured: %#v", existing)
+	}
+	if deleteValidation != nil {
+		if err := deleteValidation(ctx, oldObj.DeepCopyObject()); err != nil {
+			return nil, false, err
+		}
+	}
+
+	// Make sure the object that has been validated is the one being deleted.
+	deleteOptions := *options.DeepCopy()
+	if deleteOptions.Preconditions == nil {
+		deleteOptions.Preconditions = &metav1.Preconditions{}
+	}
+	if uid := oldObj.GetUID(); deleteOptions.Preconditions.UID == nil && uid != "" {
+		deleteOptions.Preconditions.UID = &uid
+	}
+
+	if err := delegate.Delete(ctx, name, deleteOptions); err != nil {
+		return nil, false, err
+	}
+
+	if len(deleteOptions.DryRun) > 0 {
+		// a dry-run deletion of an object with finalizers would leave it in the deleting state
+		return oldObj, len(oldObj.GetFinalizers()) == 0, nil
+	}
+
+	obj, err := delegate.Get(ctx, name, metav1.GetOptions{})
+	if kerrors.IsNotFound(err) {
+		return oldObj, true, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	if obj.GetUID() != oldObj.GetUID() {
+		// the object has been deleted and recreated in the meantime
+		return oldObj, true, nil
+	}
+
+	return obj, false, nil
 }
 
 func (s *Store) DeleteCollection(ctx context.Context, deleteValidation rest.ValidateObjectFunc, options *metav1.DeleteOptions, listOptions *metainternalversion.ListOptions) (runtime.Object, error) {
@@ -0,0 +1,52 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handler
+
+import (
+	"context"
+	"net/http"
+
+	genericapiserver "k8s.io/apiserver/pkg/server"
+
+	"github.com/kcp-dev/kcp/pkg/virtual/framework"
+)
+
+// HandlerBuilder is a function that builds the HTTP handler of a virtual workspace.
+// The request URL path seen by the handler has the prefix returned by the RootPathResolver
+// stripped, and the request context contains the user and what the RootPathResolver added.
+type HandlerBuilder func(rootAPIServerConfig genericapiserver.CompletedConfig) (http.Handler, error)
+
+// HandlerVirtualWorkspace is an implementation of the VirtualWorkspace interface
+// which serves the requests it accepts with a plain http.Handler.
+type HandlerVirtualWorkspace struct {
+	Name             string
+	RootPathResolver framework.RootPathResolverFunc
+	Ready            framework.ReadyFunc
+	BootstrapHandler HandlerBuilder
+}
+
+func (vw *HandlerVirtualWorkspace) GetName() string {
+	return vw.Name
+}
+
+func (vw *HandlerVirtualWorkspace) IsReady() error {
+	return vw.Ready()
+}
+
+func (vw *HandlerVirtualWorkspace) ResolveRootPath(urlPath string, context context.Context) (accepted bool, prefixToStrip string, completedContext context.Context) {
+	return vw.RootPathResolver(urlPath, context)
+}
@@ -0,0 +1,51 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rootapiserver
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/go-logr/logr"
+
+	genericapirequest "k8s.io/apiserver/pkg/endpoints/request"
+
+	"github.com/kcp-dev/kcp/pkg/logging"
+	virtualcontext "github.com/kcp-dev/kcp/pkg/virtual/framework/context"
+)
+
+// withRequestLogging passes a logger carrying the virtual workspace and the workspace of the request
+// in the request context to the given handler, such that the logs of virtual workspaces can be filtered
+// per tenant.
+func withRequestLogging(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		logger := requestLogger(req.Context())
+		logger.V(4).Info("Serving request", "verb", req.Method, "path", req.URL.Path)
+		handler.ServeHTTP(w, req.WithContext(logging.NewContext(req.Context(), logger)))
+	})
+}
+
+func requestLogger(ctx context.Context) logr.Logger {
+	logger := logging.FromContext(ctx)
+	if name, ok := ctx.Value(virtualcontext.VirtualWorkspaceNameKey).(string); ok {
+		logger = logger.WithValues(logging.VirtualWorkspaceKey, name)
+	}
+	if cluster := genericapirequest.ClusterFrom(ctx); cluster != nil && !cluster.Name.Empty() {
+		logger = logging.WithWorkspace(logger, cluster.Name)
+	}
+	return logger
+}
@@ -26,12 +26,17 @@ import (
 
 	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apiserver/pkg/authorization/authorizer"
 	"k8s.io/apiserver/pkg/authorization/authorizerfactory"
+	"k8s.io/apiserver/pkg/endpoints/filters"
+	"k8s.io/apiserver/pkg/endpoints/handlers/responsewriters"
 	genericapirequest "k8s.io/apiserver/pkg/endpoints/request"
 	genericapiserver "k8s.io/apiserver/pkg/server"
+	"k8s.io/apiserver/pkg/server/healthz"
 	"k8s.io/apiserver/pkg/warning"
 	"k8s.io/client-go/rest"
 	componentbaseversion "k8s.io/component-base/version"
+	"k8s.io/klog/v2"
 
 	"github.com/kcp-dev/kcp/pkg/virtual/framework"
 	virtualcontext "github.com/kcp-dev/kcp/pkg/virtual/framework/context"
@@ -93,7 +98,6 @@ func (c *completedConfig) WithOpenAPIAggregationController(delegatedAPIServer *g
 func (c completedConfig) New(delegationTarget genericapiserver.DelegationTarget) (*RootAPIServer, error) {
 	delegateAPIServer := delegationTarget
 
-	var readys []framework.ReadyFunc
 	vwNames := sets.NewString()
 	for _, virtualWorkspace := range c.ExtraConfig.VirtualWorkspaces {
 		name := virtualWorkspace.GetName()
@@ -107,12 +111,11 @@ func (c completedConfig) New(delegationTarget genericapiserver.DelegationTarget)
 		if err != nil {
 			return nil, err
 		}
-		readys = append(readys, virtualWorkspace.IsReady)
 	}
 
 	c.GenericConfig.BuildHandlerChainFunc = c.getRootHandlerChain(delegateAPIServer)
 	c.GenericConfig.RequestInfoResolver = c
-	c.GenericConfig.ReadyzChecks = append(c.GenericConfig.ReadyzChecks, asHealthCheck(readys))
+	c.GenericConfig.ReadyzChecks = append(c.GenericConfig.ReadyzChecks, ReadyzChecks(c.ExtraConfig.VirtualWorkspaces...)...)
 
 	genericServer, err := c.GenericConfig.New("virtual-workspaces-root-apiserver", delegateAPIServer)
 	if err != nil {
@@ -132,26 +135,29 @@ func (c completedConfig) New(delegationTarget genericapiserver.DelegationTarget)
 	return s, nil
 }
 
-type asHealthCheck []framework.ReadyFunc
-
-func (readys asHealthCheck) Name() string {
-	return "VirtualWotrkspaceAdditionalReadinessChecks"
-}
-
-func (readys asHealthCheck) Check(req *http.Request) error {
-	for _, ready := range readys {
-		if err := ready(); err != nil {
-			return err
-		}
+// ReadyzChecks returns a readyz check named virtual-workspace-<name> for each of the given virtual workspaces.
+func ReadyzChecks(virtualWorkspaces ...framework.VirtualWorkspace) []healthz.HealthChecker {
+	checks := make([]healthz.HealthChecker, 0, len(virtualWorkspaces))
+	for _, virtualWorkspace := range virtualWorkspaces {
+		virtualWorkspace := virtualWorkspace
+		checks = append(checks, healthz.NamedCheck("virtual-workspace-"+virtualWorkspace.GetName(), func(_ *http.Request) error {
+			return virtualWorkspace.IsReady()
+		}))
 	}
-	return nil
+	return checks
 }
 
 func (c completedConfig) resolveRootPaths(urlPath string, requestContext context.Context) (accepted bool, prefixToStrip string, completedContext context.Context) {
+	_, accepted, prefixToStrip, completedContext = c.resolveVirtualWorkspace(urlPath, requestContext)
+	return
+}
+
+// resolveVirtualWorkspace is like resolveRootPaths, but also returns the virtual workspace which accepted the request.
+func (c completedConfig) resolveVirtualWorkspace(urlPath string, requestContext context.Context) (resolved framework.VirtualWorkspace, accepted bool, prefixToStrip string, completedContext context.Context) {
 	completedContext = requestContext
 	for _, virtualWorkspace := range c.ExtraConfig.VirtualWorkspaces {
 		if accepted, prefixToStrip, completedContext := virtualWorkspace.ResolveRootPath(urlPath, requestContext); accepted {
-			return accepted, prefixToStrip, context.WithValue(completedContext, virtualcontext.VirtualWorkspaceNameKey, virtualWorkspace.GetName())
+			return virtualWorkspace, accepted, prefixToStrip, context.WithValue(completedContext, virtualcontext.VirtualWorkspaceNameKey, virtualWorkspace.GetName())
 		}
 	}
 	return
@@ -167,13 +173,16 @@ func (c completedConfig) getRootHandlerChain(delegateAPIServer genericapiserver.
 					fmt.Sprintf("You are using an old kubectl-kcp plugin. Please update to a version matching the kcp server version %q.", componentbaseversion.Get().GitVersion))
 			}
 
-			if accepted, prefixToStrip, context := c.resolveRootPaths(req.URL.Path, req.Context()); accepted {
+			if virtualWorkspace, accepted, prefixToStrip, context := c.resolveVirtualWorkspace(req.URL.Path, req.Context()); accepted {
 				req.URL.Path = strings.TrimPrefix(req.URL.Path, prefixToStrip)
 				req.URL.RawPath = strings.TrimPrefix(req.URL.RawPath, prefixToStrip)
 				req = req.WithContext(context)
+				if authz, ok := virtualWorkspace.(framework.AuthorizingVirtualWorkspace); ok && !c.authorize(authz, w, req) {
+					return
+				}
 				delegatedHandler := delegateAPIServer.UnprotectedHandler()
 				if delegatedHandler != nil {
-					delegatedHandler.ServeHTTP(w, req)
+					withRequestLogging(delegatedHandler).ServeHTTP(w, req)
 				}
 				return
 			}
@@ -240,3 +249,27 @@ func NewRootAPIConfig(recommendedConfig *genericapiserver.RecommendedConfig, inf
 
 	return ret, ret.ExtraConfig.Validate()
 }
+
+// authorize checks the request against the authorizer of a virtual workspace,
+// and writes an error response if it is not allowed.
+func (c completedConfig) authorize(authz authorizer.Authorizer, w http.ResponseWriter, req *http.Request) bool {
+	ctx := req.Context()
+	attributes, err := filters.GetAuthorizerAttributes(ctx)
+	if err != nil {
+		responsewriters.InternalError(w, req, err)
+		return false
+	}
+
+	decision, reason, err := authz.Authorize(ctx, attributes)
+	if decision == authorizer.DecisionAllow {
+		return true
+	}
+	if err != nil {
+		responsewriters.InternalError(w, req, err)
+		return false
+	}
+
+	klog.V(4).Infof("Forbidden: %#v, Reason: %q", req.RequestURI, reason)
+	responsewriters.Forbidden(ctx, attributes, w, req, reason, c.GenericConfig.Serializer)
+	return false
+}
@@ -19,6 +19,7 @@ package framework
 import (
 	"context"
 
+	"k8s.io/apiserver/pkg/authorization/authorizer"
 	genericapiserver "k8s.io/apiserver/pkg/server"
 )
 
@@ -48,3 +49,12 @@ type VirtualWorkspace interface {
 	IsReady() error
 	Register(rootAPIServerConfig genericapiserver.CompletedConfig, delegateAPIServer genericapiserver.DelegationTarget) (genericapiserver.DelegationTarget, error)
 }
+
+// AuthorizingVirtualWorkspace is a VirtualWorkspace that authorizes the requests
+// it accepts, on top of the authorization of the Root API server.
+// Requests that are not allowed are rejected as forbidden before reaching the
+// delegated APIServer of the VirtualWorkspace.
+type AuthorizingVirtualWorkspace interface {
+	VirtualWorkspace
+	authorizer.Authorizer
+}
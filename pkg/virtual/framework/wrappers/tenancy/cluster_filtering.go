@@ -54,6 +54,14 @@ func (i *filteredInterface) ClusterWorkspaceShards() tenancyinformers.ClusterWor
 	return FilterWorkspaceShardInformer(i.clusterName, i.informers.ClusterWorkspaceShards())
 }
 
+func (i *filteredInterface) WorkspaceSnapshots() tenancyinformers.WorkspaceSnapshotInformer {
+	return FilterWorkspaceSnapshotInformer(i.clusterName, i.informers.WorkspaceSnapshots())
+}
+
+func (i *filteredInterface) WorkspaceSources() tenancyinformers.WorkspaceSourceInformer {
+	return FilterWorkspaceSourceInformer(i.clusterName, i.informers.WorkspaceSources())
+}
+
 func FilterClusterWorkspaceTypeInformer(clusterName logicalcluster.Name, informer tenancyinformers.ClusterWorkspaceTypeInformer) tenancyinformers.ClusterWorkspaceTypeInformer {
 	return &filteredClusterWorkspaceTypeInformer{
 		clusterName: clusterName,
@@ -206,3 +214,105 @@ func (l *filteredWorkspaceShardLister) Get(name string) (*tenancyapis.ClusterWor
 	}
 	return l.lister.Get(name)
 }
+
+func FilterWorkspaceSnapshotInformer(clusterName logicalcluster.Name, informer tenancyinformers.WorkspaceSnapshotInformer) tenancyinformers.WorkspaceSnapshotInformer {
+	return &filteredWorkspaceSnapshotInformer{
+		clusterName: clusterName,
+		informer:    informer,
+	}
+}
+
+var _ tenancyinformers.WorkspaceSnapshotInformer = (*filteredWorkspaceSnapshotInformer)(nil)
+var _ tenancylisters.WorkspaceSnapshotLister = (*filteredWorkspaceSnapshotLister)(nil)
+
+type filteredWorkspaceSnapshotInformer struct {
+	clusterName logicalcluster.Name
+	informer    tenancyinformers.WorkspaceSnapshotInformer
+}
+
+type filteredWorkspaceSnapshotLister struct {
+	clusterName logicalcluster.Name
+	lister      tenancylisters.WorkspaceSnapshotLister
+}
+
+func (i *filteredWorkspaceSnapshotInformer) Informer() cache.SharedIndexInformer {
+	return i.informer.Informer()
+}
+
+func (i *filteredWorkspaceSnapshotInformer) Lister() tenancylisters.WorkspaceSnapshotLister {
+	return &filteredWorkspaceSnapshotLister{
+		clusterName: i.clusterName,
+		lister:      i.informer.Lister(),
+	}
+}
+
+func (l *filteredWorkspaceSnapshotLister) List(selector labels.Selector) (ret []*tenancyapis.WorkspaceSnapshot, err error) {
+	items, err := l.lister.List(selector)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range items {
+		if logicalcluster.From(item) == l.clusterName {
+			ret = append(ret, item)
+		}
+	}
+	return
+}
+
+func (l *filteredWorkspaceSnapshotLister) Get(name string) (*tenancyapis.WorkspaceSnapshot, error) {
+	if clusterName, _ := clusters.SplitClusterAwareKey(name); clusterName.Empty() {
+		name = clusters.ToClusterAwareKey(l.clusterName, name)
+	}
+	return l.lister.Get(name)
+}
+
+func FilterWorkspaceSourceInformer(clusterName logicalcluster.Name, informer tenancyinformers.WorkspaceSourceInformer) tenancyinformers.WorkspaceSourceInformer {
+	return &filteredWorkspaceSourceInformer{
+		clusterName: clusterName,
+		informer:    informer,
+	}
+}
+
+var _ tenancyinformers.WorkspaceSourceInformer = (*filteredWorkspaceSourceInformer)(nil)
+var _ tenancylisters.WorkspaceSourceLister = (*filteredWorkspaceSourceLister)(nil)
+
+type filteredWorkspaceSourceInformer struct {
+	clusterName logicalcluster.Name
+	informer    tenancyinformers.WorkspaceSourceInformer
+}
+
+type filteredWorkspaceSourceLister struct {
+	clusterName logicalcluster.Name
+	lister      tenancylisters.WorkspaceSourceLister
+}
+
+func (i *filteredWorkspaceSourceInformer) Informer() cache.SharedIndexInformer {
+	return i.informer.Informer()
+}
+
+func (i *filteredWorkspaceSourceInformer) Lister() tenancylisters.WorkspaceSourceLister {
+	return &filteredWorkspaceSourceLister{
+		clusterName: i.clusterName,
+		lister:      i.informer.Lister(),
+	}
+}
+
+func (l *filteredWorkspaceSourceLister) List(selector labels.Selector) (ret []*tenancyapis.WorkspaceSource, err error) {
+	items, err := l.lister.List(selector)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range items {
+		if logicalcluster.From(item) == l.clusterName {
+			ret = append(ret, item)
+		}
+	}
+	return
+}
+
+func (l *filteredWorkspaceSourceLister) Get(name string) (*tenancyapis.WorkspaceSource, error) {
+	if clusterName, _ := clusters.SplitClusterAwareKey(name); clusterName.Empty() {
+		name = clusters.ToClusterAwareKey(l.clusterName, name)
+	}
+	return l.lister.Get(name)
+}
@@ -0,0 +1,312 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package builder
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	structuralschema "k8s.io/apiextensions-apiserver/pkg/apiserver/schema"
+	"k8s.io/apiextensions-apiserver/pkg/registry/customresource"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilcache "k8s.io/apimachinery/pkg/util/cache"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apiserver/pkg/authorization/authorizer"
+	"k8s.io/apiserver/pkg/registry/rest"
+	genericapiserver "k8s.io/apiserver/pkg/server"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/klog/v2"
+	"k8s.io/kube-openapi/pkg/validation/validate"
+
+	configcrds "github.com/kcp-dev/kcp/config/crds"
+	apiresourcev1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apiresource/v1alpha1"
+	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+	tenancyhelper "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1/helper"
+	"github.com/kcp-dev/kcp/pkg/authorization/delegated"
+	"github.com/kcp-dev/kcp/pkg/virtual/framework"
+	frameworkbuilder "github.com/kcp-dev/kcp/pkg/virtual/framework/builder"
+	"github.com/kcp-dev/kcp/pkg/virtual/framework/dynamic/apidefinition"
+	"github.com/kcp-dev/kcp/pkg/virtual/framework/dynamic/apiserver"
+	dynamiccontext "github.com/kcp-dev/kcp/pkg/virtual/framework/dynamic/context"
+)
+
+const InitializingWorkspacesVirtualWorkspaceName string = "initializingworkspaces"
+
+// InitializeVerb is the verb an initializer controller must be allowed, in the root workspace, on the
+// clusterworkspaceinitializers resource with the name of the initializer, to use the virtual workspace.
+const InitializeVerb = "initialize"
+
+const (
+	// initializerAPISetsCacheSize is the number of initializers whose API definitions are cached.
+	initializerAPISetsCacheSize = 100
+	// initializerAPISetsCacheTTL is the time after which the API definitions of an initializer are
+	// dropped, such that the cache does not grow with initializers which are not used anymore.
+	initializerAPISetsCacheTTL = time.Hour
+)
+
+var (
+	// readVerbs are the verbs of the requests served for the ClusterWorkspaces of an initializer.
+	readVerbs = sets.NewString("get", "list", "watch")
+	// statusVerbs are the verbs of the requests served for the status of the ClusterWorkspaces of an initializer.
+	statusVerbs = sets.NewString("get", "update", "patch")
+)
+
+// InitializerPathSegment returns the URL path segment of the given initializer, i.e. the initializer
+// with slashes replaced by colons.
+func InitializerPathSegment(initializer tenancyv1alpha1.ClusterWorkspaceInitializer) string {
+	return strings.ReplaceAll(string(initializer), "/", ":")
+}
+
+// BuildVirtualWorkspace builds an InitializingWorkspacesVirtualWorkspace by instanciating a DynamicVirtualWorkspace which
+// serves the ClusterWorkspaces of an initializer, with a ForwardingREST REST storage restricted to the ClusterWorkspaces
+// labelled for this initializer. The only change an initializer can make is the removal of its initializer from the status.
+//
+// It serves paths like .../<initializer-path-segment>/clusters/*/apis/tenancy.kcp.dev/v1alpha1/clusterworkspaces
+func BuildVirtualWorkspace(rootPathPrefix string, dynamicClusterClient dynamic.ClusterInterface, kubeClusterClient kubernetes.ClusterInterface, delegatedAuthz delegated.DelegatedAuthorizerFactory) framework.VirtualWorkspace {
+	return frameworkbuilder.New(InitializingWorkspacesVirtualWorkspaceName, rootPathPrefix).
+		WithPathSegments(1, func(ctx context.Context, segments []string) (context.Context, bool) {
+			initializer := strings.ReplaceAll(segments[0], ":", "/")
+			return dynamiccontext.WithAPIDomainKey(ctx, dynamiccontext.APIDomainKey(initializer)), true
+		}).
+		WithClusterInjection().
+		WithAuthorizer(&initializerAuthorizer{
+			kubeClusterClient: kubeClusterClient,
+			delegatedAuthz:    delegatedAuthz,
+		}).
+		BuildDynamic(func(mainConfig genericapiserver.CompletedConfig) (apidefinition.APIDefinitionSetGetter, error) {
+			crd, err := configcrds.Get(metav1.GroupResource{Group: tenancyv1alpha1.SchemeGroupVersion.Group, Resource: "clusterworkspaces"})
+			if err != nil {
+				return nil, err
+			}
+			spec, err := apiResourceSpecFor(crd, tenancyv1alpha1.SchemeGroupVersion.Version)
+			if err != nil {
+				return nil, err
+			}
+
+			return &initializerAPISets{
+				createAPIDefinition: func(initializer tenancyv1alpha1.ClusterWorkspaceInitializer) (apidefinition.APIDefinition, error) {
+					key, value := tenancyhelper.InitializerToLabel(initializer)
+					return apiserver.CreateServingInfoFor(mainConfig, tenancyv1alpha1.RootCluster, spec, initializerRestStorage(initializer, frameworkbuilder.ForwardingRestStorage(context.Background(), dynamicClusterClient, map[string]string{key: value})))
+				},
+				apiSets: utilcache.NewLRUExpireCache(initializerAPISetsCacheSize),
+			}, nil
+		})
+}
+
+func apiResourceSpecFor(crd *apiextensionsv1.CustomResourceDefinition, version string) (*apiresourcev1alpha1.CommonAPIResourceSpec, error) {
+	for i := range crd.Spec.Versions {
+		crdVersion := &crd.Spec.Versions[i]
+		if crdVersion.Name != version {
+			continue
+		}
+		spec := &apiresourcev1alpha1.CommonAPIResourceSpec{
+			GroupVersion: apiresourcev1alpha1.GroupVersion{
+				Group:   crd.Spec.Group,
+				Version: crdVersion.Name,
+			},
+			Scope:                         crd.Spec.Scope,
+			CustomResourceDefinitionNames: crd.Spec.Names,
+			SubResources:                  *(&apiresourcev1alpha1.SubResources{}).ImportFromCRDVersion(crdVersion),
+			ColumnDefinitions:             *(&apiresourcev1alpha1.ColumnDefinitions{}).ImportFromCRDVersion(crdVersion),
+		}
+		if err := spec.SetSchema(crdVersion.Schema.OpenAPIV3Schema); err != nil {
+			return nil, err
+		}
+		return spec, nil
+	}
+	return nil, fmt.Errorf("version %q of CRD %s not found", version, crd.Name)
+}
+
+// initializerRestStorage restricts the storages of the given provider to the removal of the given initializer
+// through the status subresource.
+func initializerRestStorage(initializer tenancyv1alpha1.ClusterWorkspaceInitializer, provider apiserver.RestProviderFunc) apiserver.RestProviderFunc {
+	return func(resource schema.GroupVersionResource, kind schema.GroupVersionKind, listKind schema.GroupVersionKind, typer runtime.ObjectTyper, tableConvertor rest.TableConvertor, namespaceScoped bool, schemaValidator *validate.SchemaValidator, subresourcesSchemaValidator map[string]*validate.SchemaValidator, structuralSchema *structuralschema.Structural) (rest.Storage, map[string]rest.Storage) {
+		mainStorage, subresourceStorages := provider(resource, kind, listKind, typer, tableConvertor, namespaceScoped, schemaValidator, subresourcesSchemaValidator, structuralSchema)
+		if status, ok := subresourceStorages["status"].(*customresource.StatusREST); ok {
+			subresourceStorages["status"] = &initializerStatusREST{StatusREST: status, initializer: initializer}
+		}
+		return mainStorage, subresourceStorages
+	}
+}
+
+// initializerStatusREST only allows an initializer to remove itself from the status of a ClusterWorkspace.
+type initializerStatusREST struct {
+	*customresource.StatusREST
+	initializer tenancyv1alpha1.ClusterWorkspaceInitializer
+}
+
+func (r *initializerStatusREST) Update(ctx context.Context, name string, objInfo rest.UpdatedObjectInfo, createValidation rest.ValidateObjectFunc, updateValidation rest.ValidateObjectUpdateFunc, forceAllowCreate bool, options *metav1.UpdateOptions) (runtime.Object, bool, error) {
+	return r.StatusREST.Update(ctx, name, objInfo, createValidation, func(ctx context.Context, obj, old runtime.Object) error {
+		if err := validateInitializerUpdate(r.initializer, obj, old); err != nil {
+			return apierrors.NewForbidden(tenancyv1alpha1.Resource("clusterworkspaces"), name, err)
+		}
+		if updateValidation != nil {
+			return updateValidation(ctx, obj, old)
+		}
+		return nil
+	}, false, options)
+}
+
+// validateInitializerUpdate returns an error unless the status of the new ClusterWorkspace equals the one of the old
+// ClusterWorkspace, with or without the given initializer, apart from the entry of the given initializer in
+// status.initializerFailures. Changes outside of the status are already dropped by the status strategy.
+func validateInitializerUpdate(initializer tenancyv1alpha1.ClusterWorkspaceInitializer, obj, old runtime.Object) error {
+	newWorkspace, err := toClusterWorkspace(obj)
+	if err != nil {
+		return err
+	}
+	oldWorkspace, err := toClusterWorkspace(old)
+	if err != nil {
+		return err
+	}
+	withoutOwnFailure(&newWorkspace.Status, initializer)
+	withoutOwnFailure(&oldWorkspace.Status, initializer)
+	withoutInitializer := oldWorkspace.Status.DeepCopy()
+	withoutInitializer.Initializers = nil
+	for _, i := range oldWorkspace.Status.Initializers {
+		if i != initializer {
+			withoutInitializer.Initializers = append(withoutInitializer.Initializers, i)
+		}
+	}
+	if len(newWorkspace.Status.Initializers) == 0 {
+		newWorkspace.Status.Initializers = nil
+	}
+	if len(oldWorkspace.Status.Initializers) == 0 {
+		oldWorkspace.Status.Initializers = nil
+	}
+	if equality.Semantic.DeepEqual(newWorkspace.Status, oldWorkspace.Status) || equality.Semantic.DeepEqual(newWorkspace.Status, *withoutInitializer) {
+		return nil
+	}
+	return fmt.Errorf("initializer %q can only remove itself from status.initializers and update its own entry in status.initializerFailures", initializer)
+}
+
+// withoutOwnFailure drops the entry of the given initializer from status.initializerFailures.
+func withoutOwnFailure(status *tenancyv1alpha1.ClusterWorkspaceStatus, initializer tenancyv1alpha1.ClusterWorkspaceInitializer) {
+	var failures []tenancyv1alpha1.ClusterWorkspaceInitializerFailure
+	for _, f := range status.InitializerFailures {
+		if f.Initializer != initializer {
+			failures = append(failures, f)
+		}
+	}
+	status.InitializerFailures = failures
+}
+
+func toClusterWorkspace(obj runtime.Object) (*tenancyv1alpha1.ClusterWorkspace, error) {
+	u, ok := obj.(*unstructured.Unstructured)
+	if !ok {
+		return nil, fmt.Errorf("unexpected type %T", obj)
+	}
+	workspace := &tenancyv1alpha1.ClusterWorkspace{}
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(u.Object, workspace); err != nil {
+		return nil, err
+	}
+	return workspace, nil
+}
+
+// initializerAPISets serves the ClusterWorkspaces API for any initializer, creating
+// the API definition of an initializer on first use. The API definitions of the
+// least recently used initializers are dropped.
+type initializerAPISets struct {
+	createAPIDefinition func(initializer tenancyv1alpha1.ClusterWorkspaceInitializer) (apidefinition.APIDefinition, error)
+
+	lock sync.Mutex
+	// apiSets holds an apidefinition.APIDefinitionSet per dynamiccontext.APIDomainKey.
+	apiSets *utilcache.LRUExpireCache
+}
+
+func (s *initializerAPISets) GetAPIDefinitionSet(key dynamiccontext.APIDomainKey) (apidefinition.APIDefinitionSet, bool) {
+	if key == "" {
+		return nil, false
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if apiSet, found := s.apiSets.Get(key); found {
+		return apiSet.(apidefinition.APIDefinitionSet), true
+	}
+
+	def, err := s.createAPIDefinition(tenancyv1alpha1.ClusterWorkspaceInitializer(key))
+	if err != nil {
+		klog.Errorf("failed to create the ClusterWorkspaces API for initializer %q: %v", key, err)
+		return nil, false
+	}
+	apiSet := apidefinition.APIDefinitionSet{
+		tenancyv1alpha1.SchemeGroupVersion.WithResource("clusterworkspaces"): def,
+	}
+	s.apiSets.Add(key, apiSet, initializerAPISetsCacheTTL)
+	return apiSet, true
+}
+
+// initializerAuthorizer only allows users who may initialize workspaces for the initializer of the
+// request, and only the verbs needed by initializer controllers.
+type initializerAuthorizer struct {
+	kubeClusterClient kubernetes.ClusterInterface
+	delegatedAuthz    delegated.DelegatedAuthorizerFactory
+}
+
+func (a *initializerAuthorizer) Authorize(ctx context.Context, attr authorizer.Attributes) (authorizer.Decision, string, error) {
+	initializer := dynamiccontext.APIDomainKeyFrom(ctx)
+	if initializer == "" {
+		return authorizer.DecisionNoOpinion, "no initializer in the request path", nil
+	}
+	if attr.IsResourceRequest() {
+		switch attr.GetSubresource() {
+		case "":
+			if !readVerbs.Has(attr.GetVerb()) {
+				return authorizer.DecisionDeny, fmt.Sprintf("verb %q is not supported for initializers", attr.GetVerb()), nil
+			}
+		case "status":
+			if !statusVerbs.Has(attr.GetVerb()) {
+				return authorizer.DecisionDeny, fmt.Sprintf("verb %q is not supported for initializers on the status", attr.GetVerb()), nil
+			}
+		default:
+			return authorizer.DecisionDeny, fmt.Sprintf("subresource %q is not supported for initializers", attr.GetSubresource()), nil
+		}
+	}
+
+	authz, err := a.delegatedAuthz(tenancyv1alpha1.RootCluster, a.kubeClusterClient)
+	if err != nil {
+		return authorizer.DecisionNoOpinion, "", err
+	}
+	decision, reason, err := authz.Authorize(ctx, authorizer.AttributesRecord{
+		User:            attr.GetUser(),
+		Verb:            InitializeVerb,
+		APIGroup:        tenancyv1alpha1.SchemeGroupVersion.Group,
+		APIVersion:      tenancyv1alpha1.SchemeGroupVersion.Version,
+		Resource:        "clusterworkspaceinitializers",
+		Name:            string(initializer),
+		ResourceRequest: true,
+	})
+	if err != nil {
+		return authorizer.DecisionNoOpinion, reason, err
+	}
+	if decision != authorizer.DecisionAllow {
+		return decision, fmt.Sprintf("not allowed to %s workspaces for initializer %q in %s: %s", InitializeVerb, initializer, tenancyv1alpha1.RootCluster, reason), nil
+	}
+	return authorizer.DecisionAllow, reason, nil
+}
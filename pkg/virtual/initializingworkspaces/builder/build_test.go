@@ -0,0 +1,100 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package builder
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+)
+
+func TestValidateInitializerUpdate(t *testing.T) {
+	workspace := func(phase tenancyv1alpha1.ClusterWorkspacePhaseType, initializers ...tenancyv1alpha1.ClusterWorkspaceInitializer) runtime.Object {
+		u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&tenancyv1alpha1.ClusterWorkspace{
+			Status: tenancyv1alpha1.ClusterWorkspaceStatus{Phase: phase, Initializers: initializers},
+		})
+		require.NoError(t, err)
+		return &unstructured.Unstructured{Object: u}
+	}
+	initializing := tenancyv1alpha1.ClusterWorkspacePhaseInitializing
+	withFailures := func(obj runtime.Object, initializers ...tenancyv1alpha1.ClusterWorkspaceInitializer) runtime.Object {
+		var failures []interface{}
+		for _, i := range initializers {
+			failures = append(failures, map[string]interface{}{"initializer": string(i), "failures": int64(1), "lastError": "boom"})
+		}
+		u := obj.(*unstructured.Unstructured)
+		require.NoError(t, unstructured.SetNestedSlice(u.Object, failures, "status", "initializerFailures"))
+		return u
+	}
+
+	tests := map[string]struct {
+		obj, old runtime.Object
+		wantErr  bool
+	}{
+		"no change": {
+			obj: workspace(initializing, "root:a", "root:b"),
+			old: workspace(initializing, "root:a", "root:b"),
+		},
+		"own initializer removed": {
+			obj: workspace(initializing, "root:b"),
+			old: workspace(initializing, "root:a", "root:b"),
+		},
+		"last initializer removed": {
+			obj: workspace(initializing),
+			old: workspace(initializing, "root:a"),
+		},
+		"other initializer removed": {
+			obj:     workspace(initializing, "root:a"),
+			old:     workspace(initializing, "root:a", "root:b"),
+			wantErr: true,
+		},
+		"initializer added": {
+			obj:     workspace(initializing, "root:a", "root:b", "root:c"),
+			old:     workspace(initializing, "root:a", "root:b"),
+			wantErr: true,
+		},
+		"own failure recorded": {
+			obj: withFailures(workspace(initializing, "root:a", "root:b"), "root:a"),
+			old: workspace(initializing, "root:a", "root:b"),
+		},
+		"own failure cleared with own initializer": {
+			obj: withFailures(workspace(initializing, "root:b"), "root:b"),
+			old: withFailures(workspace(initializing, "root:a", "root:b"), "root:a", "root:b"),
+		},
+		"other failure recorded": {
+			obj:     withFailures(workspace(initializing, "root:a", "root:b"), "root:b"),
+			old:     workspace(initializing, "root:a", "root:b"),
+			wantErr: true,
+		},
+		"phase changed": {
+			obj:     workspace(tenancyv1alpha1.ClusterWorkspacePhaseReady, "root:b"),
+			old:     workspace(initializing, "root:a", "root:b"),
+			wantErr: true,
+		},
+	}
+	for name, tc := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := validateInitializerUpdate("root:a", tc.obj, tc.old)
+			require.Equal(t, tc.wantErr, err != nil, "unexpected error: %v", err)
+		})
+	}
+}
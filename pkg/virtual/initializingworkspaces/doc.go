@@ -0,0 +1,36 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package initializingworkspaces and its sub-packages provide the Initializing Workspaces Virtual Workspace.
+//
+// It exposes an APIserver URL for each ClusterWorkspace initializer, serving the ClusterWorkspaces
+// which still carry this initializer, and nothing else. An initializer controller can list and watch
+// these workspaces, and remove its initializer from their status once its work is done.
+//
+// It combines and integrates:
+//
+// - the initializer labels that the ClusterWorkspace controller maintains on ClusterWorkspaces for their
+// initializers (see tenancyv1alpha1.ClusterWorkspaceInitializerLabelPrefix)
+//
+// - a DynamicVirtualWorkspace instantiation that serves ClusterWorkspaces on an initializer-dedicated path
+// through CRD-like handlers (in the ../framework/dynamic package)
+//
+// - a REST storage implementation that forwards requests to kcp, restricted to the ClusterWorkspaces
+// labelled for the initializer (in the ../framework/forwardingregistry package)
+//
+// The builder package is the place where all these components are combined together, especially in the
+// BuildVirtualWorkspace() function.
+package initializingworkspaces
@@ -25,8 +25,11 @@ import (
 
 	kcpclient "github.com/kcp-dev/kcp/pkg/client/clientset/versioned"
 	kcpinformer "github.com/kcp-dev/kcp/pkg/client/informers/externalversions"
+	apiexportcatalogoptions "github.com/kcp-dev/kcp/pkg/virtual/apiexportcatalog/options"
+	dashboardoptions "github.com/kcp-dev/kcp/pkg/virtual/dashboard/options"
 	"github.com/kcp-dev/kcp/pkg/virtual/framework"
 	"github.com/kcp-dev/kcp/pkg/virtual/framework/rootapiserver"
+	initializingworkspacesoptions "github.com/kcp-dev/kcp/pkg/virtual/initializingworkspaces/options"
 	synceroptions "github.com/kcp-dev/kcp/pkg/virtual/syncer/options"
 	workspacesoptions "github.com/kcp-dev/kcp/pkg/virtual/workspaces/options"
 )
@@ -34,14 +37,20 @@ import (
 const virtualWorkspacesFlagPrefix = "virtual-workspaces-"
 
 type Options struct {
-	Workspaces *workspacesoptions.Workspaces
-	Syncer     *synceroptions.Syncer
+	Workspaces             *workspacesoptions.Workspaces
+	Syncer                 *synceroptions.Syncer
+	InitializingWorkspaces *initializingworkspacesoptions.InitializingWorkspaces
+	APIExportCatalog       *apiexportcatalogoptions.APIExportCatalog
+	Dashboard              *dashboardoptions.Dashboard
 }
 
 func NewOptions() *Options {
 	return &Options{
-		Workspaces: workspacesoptions.NewWorkspaces(),
-		Syncer:     synceroptions.NewSyncer(),
+		Workspaces:             workspacesoptions.NewWorkspaces(),
+		Syncer:                 synceroptions.NewSyncer(),
+		InitializingWorkspaces: initializingworkspacesoptions.NewInitializingWorkspaces(),
+		APIExportCatalog:       apiexportcatalogoptions.NewAPIExportCatalog(),
+		Dashboard:              dashboardoptions.NewDashboard(),
 	}
 }
 
@@ -52,6 +61,9 @@ func (v *Options) Validate() []error {
 
 	errs = append(errs, v.Workspaces.Validate(virtualWorkspacesFlagPrefix)...)
 	errs = append(errs, v.Syncer.Validate(virtualWorkspacesFlagPrefix)...)
+	errs = append(errs, v.InitializingWorkspaces.Validate(virtualWorkspacesFlagPrefix)...)
+	errs = append(errs, v.APIExportCatalog.Validate(virtualWorkspacesFlagPrefix)...)
+	errs = append(errs, v.Dashboard.Validate(virtualWorkspacesFlagPrefix)...)
 
 	return errs
 }
@@ -84,5 +96,26 @@ func (o *Options) NewVirtualWorkspaces(
 	extraInformers = append(extraInformers, inf...)
 	workspaces = append(workspaces, vws...)
 
+	inf, vws, err = o.InitializingWorkspaces.NewVirtualWorkspaces(rootPathPrefix, kubeClusterClient, dynamicClusterClient, kcpClusterClient, wildcardKubeInformers, wildcardKcpInformers)
+	if err != nil {
+		return nil, nil, err
+	}
+	extraInformers = append(extraInformers, inf...)
+	workspaces = append(workspaces, vws...)
+
+	inf, vws, err = o.APIExportCatalog.NewVirtualWorkspaces(rootPathPrefix, kubeClusterClient, dynamicClusterClient, kcpClusterClient, wildcardKubeInformers, wildcardKcpInformers)
+	if err != nil {
+		return nil, nil, err
+	}
+	extraInformers = append(extraInformers, inf...)
+	workspaces = append(workspaces, vws...)
+
+	inf, vws, err = o.Dashboard.NewVirtualWorkspaces(rootPathPrefix, kubeClusterClient, dynamicClusterClient, kcpClusterClient, wildcardKubeInformers, wildcardKcpInformers)
+	if err != nil {
+		return nil, nil, err
+	}
+	extraInformers = append(extraInformers, inf...)
+	workspaces = append(workspaces, vws...)
+
 	return extraInformers, workspaces, nil
 }
@@ -19,21 +19,20 @@ package builder
 import (
 	"context"
 	"errors"
-	"strings"
 
 	"github.com/kcp-dev/logicalcluster"
 
-	genericapirequest "k8s.io/apiserver/pkg/endpoints/request"
 	genericapiserver "k8s.io/apiserver/pkg/server"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/clusters"
 
 	apiresourcev1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apiresource/v1alpha1"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/workload/v1alpha1"
 	kcpclient "github.com/kcp-dev/kcp/pkg/client/clientset/versioned"
 	kcpinformer "github.com/kcp-dev/kcp/pkg/client/informers/externalversions"
 	"github.com/kcp-dev/kcp/pkg/virtual/framework"
-	virtualworkspacesdynamic "github.com/kcp-dev/kcp/pkg/virtual/framework/dynamic"
+	frameworkbuilder "github.com/kcp-dev/kcp/pkg/virtual/framework/builder"
 	"github.com/kcp-dev/kcp/pkg/virtual/framework/dynamic/apidefinition"
 	"github.com/kcp-dev/kcp/pkg/virtual/framework/dynamic/apiserver"
 	dynamiccontext "github.com/kcp-dev/kcp/pkg/virtual/framework/dynamic/context"
@@ -44,78 +43,29 @@ const SyncerVirtualWorkspaceName string = "syncer"
 
 // BuildVirtualWorkspace builds a SyncerVirtualWorkspace by instanciating a DynamicVirtualWorkspace which, combined with a
 // ForwardingREST REST storage implementation, serves a WorkloadClusterAPI list maintained by the APIReconciler controller.
+// Each workload cluster only gets the APIs imported from it.
 func BuildVirtualWorkspace(rootPathPrefix string, dynamicClusterClient dynamic.ClusterInterface, kcpClusterClient kcpclient.ClusterInterface, wildcardKcpInformers kcpinformer.SharedInformerFactory) framework.VirtualWorkspace {
 
-	if !strings.HasSuffix(rootPathPrefix, "/") {
-		rootPathPrefix += "/"
-	}
-
 	readyCh := make(chan struct{})
 
-	return &virtualworkspacesdynamic.DynamicVirtualWorkspace{
-		Name: SyncerVirtualWorkspaceName,
-		RootPathResolver: func(urlPath string, requestContext context.Context) (accepted bool, prefixToStrip string, completedContext context.Context) {
-			select {
-			case <-readyCh:
-			default:
-				return
-			}
-
-			completedContext = requestContext
-			if !strings.HasPrefix(urlPath, rootPathPrefix) {
-				return
-			}
-			withoutRootPathPrefix := strings.TrimPrefix(urlPath, rootPathPrefix)
-
-			// paths like: .../root:org:ws/<workload-cluster-name>/clusters/*/api/v1/configmaps
-			parts := strings.SplitN(withoutRootPathPrefix, "/", 3)
-			if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
-				return
-			}
-			apiDomainKey := dynamiccontext.APIDomainKey(clusters.ToClusterAwareKey(logicalcluster.New(parts[0]), parts[1]))
-
-			realPath := "/"
-			if len(parts) > 2 {
-				realPath += parts[2]
-			}
-
-			cluster := genericapirequest.Cluster{Name: logicalcluster.Wildcard, Wildcard: true}
-			if strings.HasPrefix(realPath, "/clusters/") {
-				withoutClustersPrefix := strings.TrimPrefix(realPath, "/clusters/")
-				parts = strings.SplitN(withoutClustersPrefix, "/", 2)
-				lclusterName := parts[0]
-				realPath = "/"
-				if len(parts) > 1 {
-					realPath += parts[1]
-				}
-				cluster = genericapirequest.Cluster{Name: logicalcluster.New(lclusterName)}
-				if lclusterName == "*" {
-					cluster.Wildcard = true
-				}
-			}
-
-			completedContext = genericapirequest.WithCluster(requestContext, cluster)
-			completedContext = dynamiccontext.WithAPIDomainKey(completedContext, apiDomainKey)
-			prefixToStrip = strings.TrimSuffix(urlPath, realPath)
-			accepted = true
-			return
-		},
-		Ready: func() error {
-			select {
-			case <-readyCh:
-				return nil
-			default:
-				return errors.New("syncer virtual workspace controllers are not started")
-			}
-		},
-		BootstrapAPISetManagement: func(mainConfig genericapiserver.CompletedConfig) (apidefinition.APIDefinitionSetGetter, error) {
+	return frameworkbuilder.New(SyncerVirtualWorkspaceName, rootPathPrefix).
+		// paths like: .../root:org:ws/<workload-cluster-name>/clusters/*/api/v1/configmaps
+		WithPathSegments(2, func(ctx context.Context, segments []string) (context.Context, bool) {
+			apiDomainKey := dynamiccontext.APIDomainKey(clusters.ToClusterAwareKey(logicalcluster.New(segments[0]), segments[1]))
+			return dynamiccontext.WithAPIDomainKey(ctx, apiDomainKey), true
+		}).
+		WithClusterInjection().
+		WithReadyCheck(frameworkbuilder.ReadyChannel(readyCh, "syncer virtual workspace controllers are not started")).
+		RejectRequestsUntilReady().
+		BuildDynamic(func(mainConfig genericapiserver.CompletedConfig) (apidefinition.APIDefinitionSetGetter, error) {
 			apiReconciler, err := apireconciler.NewAPIReconciler(
 				kcpClusterClient,
 				wildcardKcpInformers.Workload().V1alpha1().WorkloadClusters(),
 				wildcardKcpInformers.Apiresource().V1alpha1().NegotiatedAPIResources(),
+				wildcardKcpInformers.Apiresource().V1alpha1().APIResourceImports(),
 				func(logicalClusterName logicalcluster.Name, workloadClusterName string, spec *apiresourcev1alpha1.CommonAPIResourceSpec) (apidefinition.APIDefinition, error) {
 					ctx, cancelFn := context.WithCancel(context.Background())
-					def, err := apiserver.CreateServingInfoFor(mainConfig, logicalClusterName, spec, provideForwardingRestStorage(ctx, dynamicClusterClient, workloadClusterName))
+					def, err := apiserver.CreateServingInfoFor(mainConfig, logicalClusterName, spec, frameworkbuilder.ForwardingRestStorage(ctx, dynamicClusterClient, map[string]string{workloadv1alpha1.InternalClusterResourceStateLabelPrefix + workloadClusterName: string(workloadv1alpha1.ResourceStateSync)}))
 					if err != nil {
 						cancelFn()
 						return nil, err
@@ -136,6 +86,7 @@ func BuildVirtualWorkspace(rootPathPrefix string, dynamicClusterClient dynamic.C
 				for name, informer := range map[string]cache.SharedIndexInformer{
 					"workloadclusters":       wildcardKcpInformers.Workload().V1alpha1().WorkloadClusters().Informer(),
 					"negotiatedapiresources": wildcardKcpInformers.Apiresource().V1alpha1().NegotiatedAPIResources().Informer(),
+					"apiresourceimports":     wildcardKcpInformers.Apiresource().V1alpha1().APIResourceImports().Informer(),
 				} {
 					if !cache.WaitForNamedCacheSync(name, hookContext.StopCh, informer.HasSynced) {
 						return errors.New("informer not synced")
@@ -149,8 +100,7 @@ func BuildVirtualWorkspace(rootPathPrefix string, dynamicClusterClient dynamic.C
 			}
 
 			return apiReconciler, nil
-		},
-	}
+		})
 }
 
 // apiDefinitionWithCancel calls the cancelFn on tear-down.
@@ -41,6 +41,7 @@ import (
 	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/client/informers/externalversions/workload/v1alpha1"
 	apiresourcelistersv1alpha1 "github.com/kcp-dev/kcp/pkg/client/listers/apiresource/v1alpha1"
 	tenancylistersv1alpha1 "github.com/kcp-dev/kcp/pkg/client/listers/workload/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/logging"
 	"github.com/kcp-dev/kcp/pkg/virtual/framework/dynamic/apidefinition"
 	dynamiccontext "github.com/kcp-dev/kcp/pkg/virtual/framework/dynamic/context"
 )
@@ -48,16 +49,21 @@ import (
 const (
 	controllerName = "kcp-virtual-syncer-api-reconciler"
 	byWorkspace    = controllerName + "-byWorkspace" // will go away with scoping
+	byLocation     = controllerName + "-byLocation"
 )
 
 type CreateAPIDefinitionFunc func(logicalClusterName logicalcluster.Name, workloadClusterName string, spec *apiresourcev1alpha1.CommonAPIResourceSpec) (apidefinition.APIDefinition, error)
 
 // NewAPIReconciler returns a new controller which reconciles APIResourceImport resources
 // and delegates the corresponding WorkloadClusterAPI management to the given WorkloadClusterAPIManager.
+//
+// Only the NegotiatedAPIResources imported from a workload cluster, i.e. with an APIResourceImport
+// located at this workload cluster, are served for it.
 func NewAPIReconciler(
 	kcpClusterClient kcpclient.ClusterInterface,
 	workloadClusterInformer tenancyv1alpha1.WorkloadClusterInformer,
 	negotiatedAPIResourceInformer apiresourceinformer.NegotiatedAPIResourceInformer,
+	apiResourceImportInformer apiresourceinformer.APIResourceImportInformer,
 	createAPIDefinition CreateAPIDefinitionFunc,
 ) (*APIReconciler, error) {
 	queue := workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), controllerName)
@@ -71,6 +77,8 @@ func NewAPIReconciler(
 		negotiatedAPIResourceLister:  negotiatedAPIResourceInformer.Lister(),
 		negotiatedAPIResourceIndexer: negotiatedAPIResourceInformer.Informer().GetIndexer(),
 
+		apiResourceImportIndexer: apiResourceImportInformer.Informer().GetIndexer(),
+
 		queue: queue,
 
 		createAPIDefinition: createAPIDefinition,
@@ -90,6 +98,12 @@ func NewAPIReconciler(
 		return nil, err
 	}
 
+	if err := apiResourceImportInformer.Informer().AddIndexers(cache.Indexers{
+		byLocation: indexByLocation,
+	}); err != nil {
+		return nil, err
+	}
+
 	workloadClusterInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
 		AddFunc: func(obj interface{}) {
 			c.enqueueWorkloadCluster(obj)
@@ -111,6 +125,15 @@ func NewAPIReconciler(
 		},
 	})
 
+	apiResourceImportInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			c.enqueueAPIResourceImport(obj)
+		},
+		DeleteFunc: func(obj interface{}) {
+			c.enqueueAPIResourceImport(obj)
+		},
+	})
+
 	return c, nil
 }
 
@@ -125,6 +148,8 @@ type APIReconciler struct {
 	negotiatedAPIResourceLister  apiresourcelistersv1alpha1.NegotiatedAPIResourceLister
 	negotiatedAPIResourceIndexer cache.Indexer
 
+	apiResourceImportIndexer cache.Indexer
+
 	queue workqueue.RateLimitingInterface
 
 	createAPIDefinition CreateAPIDefinitionFunc
@@ -152,7 +177,7 @@ func (c *APIReconciler) enqueueWorkloadCluster(obj interface{}) {
 
 		resourceKey := name + "::" + clusters.ToClusterAwareKey(clusterName, r.Name)
 
-		klog.V(2).Infof("Queueing NegotiatedAPIResource %s|%s for workload cluster %s", clusterName, r.Name, name)
+		logging.WithSyncTarget(logging.NewLogger(controllerName), name).V(2).Info("Queueing NegotiatedAPIResource", logging.LogicalClusterKey, clusterName.String(), "negotiatedAPIResource", r.Name)
 		c.queue.Add(resourceKey)
 	}
 }
@@ -176,11 +201,38 @@ func (c *APIReconciler) enqueueNegotiatedAPIResource(obj interface{}) {
 
 		resourceKey := wc.Name + "::" + clusters.ToClusterAwareKey(clusterName, name)
 
-		klog.V(2).Infof("Queueing NegotiatedAPIResource %s|%s for workload cluster %s", clusterName, name, wc.Name)
+		logging.WithSyncTarget(logging.NewLogger(controllerName), wc.Name).V(2).Info("Queueing NegotiatedAPIResource", logging.LogicalClusterKey, clusterName.String(), "negotiatedAPIResource", name)
 		c.queue.Add(resourceKey)
 	}
 }
 
+// enqueueAPIResourceImport queues the NegotiatedAPIResource of an imported resource for the
+// workload cluster the resource is imported from, such that the resource is served or removed
+// when the list of resources of the workload cluster changes.
+func (c *APIReconciler) enqueueAPIResourceImport(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	apiResourceImport, ok := obj.(*apiresourcev1alpha1.APIResourceImport)
+	if !ok {
+		runtime.HandleError(fmt.Errorf("obj is supposed to be an APIResourceImport, but is %T", obj))
+		return
+	}
+
+	clusterName := logicalcluster.From(apiResourceImport)
+	gvr := apiResourceImport.GVR()
+	group := gvr.Group
+	if group == "" {
+		group = "core"
+	}
+	resourceName := gvr.Resource + "." + gvr.Version + "." + group
+
+	resourceKey := apiResourceImport.Spec.Location + "::" + clusters.ToClusterAwareKey(clusterName, resourceName)
+
+	logging.WithSyncTarget(logging.NewLogger(controllerName), apiResourceImport.Spec.Location).V(2).Info("Queueing NegotiatedAPIResource", logging.LogicalClusterKey, clusterName.String(), "negotiatedAPIResource", resourceName)
+	c.queue.Add(resourceKey)
+}
+
 func (c *APIReconciler) startWorker(ctx context.Context) {
 	for c.processNextWorkItem(ctx) {
 	}
@@ -221,6 +273,10 @@ func (c *APIReconciler) processNextWorkItem(ctx context.Context) bool {
 	}
 	key := k.(string)
 
+	// keys are not cluster-aware object keys here, logical cluster and sync target are added in process.
+	logger := logging.NewLogger(controllerName).WithValues(logging.ReconcilerKey, key)
+	ctx = logging.NewContext(ctx, logger)
+
 	// No matter what, tell the queue we're done with this key, to unblock
 	// other workers.
 	defer c.queue.Done(key)
@@ -243,23 +299,24 @@ func (c *APIReconciler) process(ctx context.Context, key string) error {
 	workloadClusterName, resourceKey := comps[0], comps[1]
 	clusterName, resourceName := clusters.SplitClusterAwareKey(resourceKey)
 	apiDomainKey := dynamiccontext.APIDomainKey(clusters.ToClusterAwareKey(clusterName, workloadClusterName))
+	logger := logging.WithSyncTarget(logging.FromContext(ctx), workloadClusterName).WithValues(logging.LogicalClusterKey, clusterName.String(), "resource", resourceName)
 
 	_, err := c.workloadClusterLister.Get(clusters.ToClusterAwareKey(clusterName, workloadClusterName))
 	if err != nil && !apierrors.IsNotFound(err) {
-		klog.Errorf("failed to get workload cluster %s|%s from lister: %v", clusterName, workloadClusterName, err)
+		logger.Error(err, "Failed to get workload cluster from lister")
 		return nil // nothing we can do here
 	} else if apierrors.IsNotFound(err) {
 		c.mutex.Lock()
 		defer c.mutex.Unlock()
 
 		if oldSet, found := c.apiSets[apiDomainKey]; found {
-			klog.V(3).Infof("Workload cluster %s|%s not found. Removing resource %s.", clusterName, workloadClusterName, resourceName)
+			logger.V(3).Info("Workload cluster not found. Removing resource")
 			for _, v := range oldSet {
 				v.TearDown()
 			}
 			delete(c.apiSets, apiDomainKey)
 		} else {
-			klog.V(4).Infof("Workload cluster %s|%s not found. No need to remove resource %s.", clusterName, workloadClusterName, resourceName)
+			logger.V(4).Info("Workload cluster not found. No need to remove resource")
 		}
 
 		return nil
@@ -267,7 +324,7 @@ func (c *APIReconciler) process(ctx context.Context, key string) error {
 
 	resource, err := c.negotiatedAPIResourceLister.Get(resourceKey)
 	if err != nil && !apierrors.IsNotFound(err) {
-		klog.Errorf("failed to get NegotiatedAPIResource with key %s from lister: %v", resourceKey)
+		logger.Error(err, "Failed to get NegotiatedAPIResource from lister")
 		return nil // nothing we can do here
 	}
 	shouldRemove := false
@@ -275,6 +332,11 @@ func (c *APIReconciler) process(ctx context.Context, key string) error {
 	if apierrors.IsNotFound(err) {
 		shouldRemove = true
 		reason = "not found"
+	} else if imported, err := c.isImportedFrom(clusterName, workloadClusterName, resourceNameToGVR(resourceName)); err != nil {
+		return err
+	} else if !imported {
+		shouldRemove = true
+		reason = "not imported"
 	} else if !resource.IsConditionTrue(apiresourcev1alpha1.Published) {
 		reason = "not published"
 	}
@@ -289,7 +351,7 @@ func (c *APIReconciler) process(ctx context.Context, key string) error {
 		resourceGVR := resourceNameToGVR(resourceName)
 		for gvr, v := range oldSet {
 			if gvr == resourceGVR {
-				klog.V(3).Infof("NegotiatedAPIResource %s|%s is %s. Removing resource from workload cluster %s.", clusterName, resourceName, reason, workloadClusterName)
+				logger.V(3).Info("Removing resource from workload cluster", "reason", reason)
 				v.TearDown()
 				continue
 			}
@@ -302,7 +364,7 @@ func (c *APIReconciler) process(ctx context.Context, key string) error {
 	}
 
 	// both resource and workload cluster exist and resource is published. Upsert APIDefinition
-	klog.V(3).Infof("Upserting resource %s|%s for workload cluster %s", clusterName, resourceName, workloadClusterName)
+	logger.V(3).Info("Upserting resource for workload cluster")
 
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
@@ -312,7 +374,7 @@ func (c *APIReconciler) process(ctx context.Context, key string) error {
 		for _, api := range internalAPIs {
 			def, err := c.createAPIDefinition(clusterName, workloadClusterName, api)
 			if err != nil {
-				klog.Errorf("Failed to create APIDefinition for %s|%s: %v", clusterName, resourceName, err)
+				logger.Error(err, "Failed to create APIDefinition")
 				continue // nothing we can do, skip it
 			}
 			newSet[schema.GroupVersionResource{
@@ -332,7 +394,7 @@ func (c *APIReconciler) process(ctx context.Context, key string) error {
 	}
 	def, err := c.createAPIDefinition(clusterName, workloadClusterName, &resource.Spec.CommonAPIResourceSpec)
 	if err != nil {
-		klog.Errorf("Failed to create APIDefinition for %s|%s: %v", clusterName, resourceName, err)
+		logger.Error(err, "Failed to create APIDefinition")
 		return nil // nothing we can do
 	}
 	newSet[resourceGVR] = def
@@ -350,6 +412,24 @@ func (c *APIReconciler) GetAPIDefinitionSet(key dynamiccontext.APIDomainKey) (ap
 	return apiSet, ok
 }
 
+// isImportedFrom returns whether there is an APIResourceImport of the given resource located at
+// the given workload cluster.
+func (c *APIReconciler) isImportedFrom(clusterName logicalcluster.Name, workloadClusterName string, gvr schema.GroupVersionResource) (bool, error) {
+	imports, err := c.apiResourceImportIndexer.ByIndex(byLocation, clusters.ToClusterAwareKey(clusterName, workloadClusterName))
+	if err != nil {
+		return false, err
+	}
+	for _, obj := range imports {
+		apiResourceImport := obj.(*apiresourcev1alpha1.APIResourceImport)
+		if apiResourceImport.Spec.GroupVersion.APIGroup() == gvr.Group &&
+			apiResourceImport.Spec.GroupVersion.Version == gvr.Version &&
+			apiResourceImport.Spec.Plural == gvr.Resource {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
 func resourceNameToGVR(key string) schema.GroupVersionResource {
 	parts := strings.SplitN(key, ".", 3)
 	resource, version, group := parts[0], parts[1], parts[2]
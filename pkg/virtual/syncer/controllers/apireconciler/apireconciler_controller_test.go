@@ -0,0 +1,165 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apireconciler
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kcp-dev/logicalcluster"
+	"github.com/stretchr/testify/require"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/clusters"
+
+	apiresourcev1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apiresource/v1alpha1"
+	workloadv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/workload/v1alpha1"
+	kcpfakeclient "github.com/kcp-dev/kcp/pkg/client/clientset/versioned/fake"
+	kcpinformer "github.com/kcp-dev/kcp/pkg/client/informers/externalversions"
+	"github.com/kcp-dev/kcp/pkg/virtual/framework/dynamic/apidefinition"
+	dynamiccontext "github.com/kcp-dev/kcp/pkg/virtual/framework/dynamic/context"
+)
+
+type fakeAPIDefinition struct {
+	apidefinition.APIDefinition
+	spec     *apiresourcev1alpha1.CommonAPIResourceSpec
+	tornDown bool
+}
+
+func (d *fakeAPIDefinition) GetAPIResourceSpec() *apiresourcev1alpha1.CommonAPIResourceSpec {
+	return d.spec
+}
+
+func (d *fakeAPIDefinition) TearDown() {
+	d.tornDown = true
+}
+
+func TestImportedAPIsPerWorkloadCluster(t *testing.T) {
+	clusterName := logicalcluster.New("root:org:ws")
+
+	workloadCluster := func(name string) *workloadv1alpha1.WorkloadCluster {
+		return &workloadv1alpha1.WorkloadCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: name, ClusterName: clusterName.String()},
+		}
+	}
+	negotiated := func(group, resource string) *apiresourcev1alpha1.NegotiatedAPIResource {
+		nameGroup := group
+		if nameGroup == "" {
+			nameGroup = "core"
+		}
+		return &apiresourcev1alpha1.NegotiatedAPIResource{
+			ObjectMeta: metav1.ObjectMeta{Name: resource + ".v1." + nameGroup, ClusterName: clusterName.String()},
+			Spec: apiresourcev1alpha1.NegotiatedAPIResourceSpec{
+				CommonAPIResourceSpec: apiresourcev1alpha1.CommonAPIResourceSpec{
+					GroupVersion:                  apiresourcev1alpha1.GroupVersion{Group: group, Version: "v1"},
+					CustomResourceDefinitionNames: apiextensionsv1.CustomResourceDefinitionNames{Plural: resource},
+				},
+			},
+		}
+	}
+	imported := func(location, group, resource string) *apiresourcev1alpha1.APIResourceImport {
+		return &apiresourcev1alpha1.APIResourceImport{
+			ObjectMeta: metav1.ObjectMeta{Name: resource + "." + location + ".v1." + group, ClusterName: clusterName.String()},
+			Spec: apiresourcev1alpha1.APIResourceImportSpec{
+				Location: location,
+				CommonAPIResourceSpec: apiresourcev1alpha1.CommonAPIResourceSpec{
+					GroupVersion:                  apiresourcev1alpha1.GroupVersion{Group: group, Version: "v1"},
+					CustomResourceDefinitionNames: apiextensionsv1.CustomResourceDefinitionNames{Plural: resource},
+				},
+			},
+		}
+	}
+
+	informers := kcpinformer.NewSharedInformerFactory(kcpfakeclient.NewSimpleClientset(), 0)
+	workloadClusterInformer := informers.Workload().V1alpha1().WorkloadClusters()
+	negotiatedAPIResourceInformer := informers.Apiresource().V1alpha1().NegotiatedAPIResources()
+	apiResourceImportInformer := informers.Apiresource().V1alpha1().APIResourceImports()
+
+	c, err := NewAPIReconciler(nil, workloadClusterInformer, negotiatedAPIResourceInformer, apiResourceImportInformer,
+		func(logicalClusterName logicalcluster.Name, workloadClusterName string, spec *apiresourcev1alpha1.CommonAPIResourceSpec) (apidefinition.APIDefinition, error) {
+			return &fakeAPIDefinition{spec: spec}, nil
+		},
+	)
+	require.NoError(t, err)
+
+	require.NoError(t, workloadClusterInformer.Informer().GetIndexer().Add(workloadCluster("east")))
+	require.NoError(t, workloadClusterInformer.Informer().GetIndexer().Add(workloadCluster("west")))
+	require.NoError(t, negotiatedAPIResourceInformer.Informer().GetIndexer().Add(negotiated("", "services")))
+	require.NoError(t, negotiatedAPIResourceInformer.Informer().GetIndexer().Add(negotiated("apps", "deployments")))
+	servicesEast := imported("east", "", "services")
+	require.NoError(t, apiResourceImportInformer.Informer().GetIndexer().Add(servicesEast))
+	require.NoError(t, apiResourceImportInformer.Informer().GetIndexer().Add(imported("east", "apps", "deployments")))
+	require.NoError(t, apiResourceImportInformer.Informer().GetIndexer().Add(imported("west", "apps", "deployments")))
+
+	ctx := context.Background()
+	processAll := func() {
+		for _, workloadClusterName := range []string{"east", "west"} {
+			for _, resourceName := range []string{"services.v1.core", "deployments.v1.apps"} {
+				require.NoError(t, c.process(ctx, workloadClusterName+"::"+clusters.ToClusterAwareKey(clusterName, resourceName)))
+			}
+		}
+	}
+	servedResources := func(workloadClusterName string) []schema.GroupVersionResource {
+		apiSet, found := c.GetAPIDefinitionSet(dynamiccontext.APIDomainKey(clusters.ToClusterAwareKey(clusterName, workloadClusterName)))
+		require.True(t, found, "no APIs for workload cluster %s", workloadClusterName)
+		var gvrs []schema.GroupVersionResource
+		for gvr, def := range apiSet {
+			if isInternalAPI(def.GetAPIResourceSpec()) {
+				continue
+			}
+			gvrs = append(gvrs, gvr)
+		}
+		return gvrs
+	}
+
+	processAll()
+	require.ElementsMatch(t, []schema.GroupVersionResource{
+		{Version: "v1", Resource: "services"},
+		{Group: "apps", Version: "v1", Resource: "deployments"},
+	}, servedResources("east"))
+	require.ElementsMatch(t, []schema.GroupVersionResource{
+		{Group: "apps", Version: "v1", Resource: "deployments"},
+	}, servedResources("west"))
+
+	t.Log("Removing the services import of east removes services from east")
+	services := c.apiSets[dynamiccontext.APIDomainKey(clusters.ToClusterAwareKey(clusterName, "east"))][schema.GroupVersionResource{Version: "v1", Resource: "services"}].(*fakeAPIDefinition)
+	require.NoError(t, apiResourceImportInformer.Informer().GetIndexer().Delete(servicesEast))
+	processAll()
+	require.True(t, services.tornDown, "services API definition of east should be torn down")
+	require.ElementsMatch(t, []schema.GroupVersionResource{
+		{Group: "apps", Version: "v1", Resource: "deployments"},
+	}, servedResources("east"))
+
+	t.Log("Importing services from west adds services to west")
+	require.NoError(t, apiResourceImportInformer.Informer().GetIndexer().Add(imported("west", "", "services")))
+	processAll()
+	require.ElementsMatch(t, []schema.GroupVersionResource{
+		{Version: "v1", Resource: "services"},
+		{Group: "apps", Version: "v1", Resource: "deployments"},
+	}, servedResources("west"))
+}
+
+func isInternalAPI(spec *apiresourcev1alpha1.CommonAPIResourceSpec) bool {
+	for _, api := range internalAPIs {
+		if api == spec {
+			return true
+		}
+	}
+	return false
+}
@@ -22,6 +22,9 @@ import (
 	"github.com/kcp-dev/logicalcluster"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/clusters"
+
+	apiresourcev1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apiresource/v1alpha1"
 )
 
 func indexByWorksapce(obj interface{}) ([]string, error) {
@@ -33,3 +36,14 @@ func indexByWorksapce(obj interface{}) ([]string, error) {
 	lcluster := logicalcluster.From(metaObj)
 	return []string{lcluster.String()}, nil
 }
+
+// indexByLocation indexes APIResourceImports by the workspace and the workload cluster they are imported from.
+func indexByLocation(obj interface{}) ([]string, error) {
+	apiResourceImport, ok := obj.(*apiresourcev1alpha1.APIResourceImport)
+	if !ok {
+		return []string{}, fmt.Errorf("obj is supposed to be an APIResourceImport, but is %T", obj)
+	}
+
+	lcluster := logicalcluster.From(apiResourceImport)
+	return []string{clusters.ToClusterAwareKey(lcluster, apiResourceImport.Spec.Location)}, nil
+}
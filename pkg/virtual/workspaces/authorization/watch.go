@@ -17,7 +17,8 @@ limitations under the License.
 package authorization
 
 import (
-	"errors"
+	"fmt"
+	"strings"
 	"sync"
 
 	"github.com/kcp-dev/logicalcluster"
@@ -79,8 +80,11 @@ type userWorkspaceWatcher struct {
 	authCache             WatchableCache
 
 	initialClusterWorkspaces []workspaceapi.ClusterWorkspace
-	// knownWorkspaces maps name to resourceVersion
-	knownWorkspaces map[string]string
+	// knownWorkspaces maps name to the last known ClusterWorkspace
+	knownWorkspaces map[string]*workspaceapi.ClusterWorkspace
+
+	// predicate selects the workspaces to send events for
+	predicate kstorage.SelectionPredicate
 
 	lclusterName logicalcluster.Name
 }
@@ -93,15 +97,19 @@ var (
 
 func NewUserWorkspaceWatcher(user user.Info, lclusterName logicalcluster.Name, clusterWorkspaceCache *workspacecache.ClusterWorkspaceCache, authCache WatchableCache, includeAllExistingWorkspaces bool, predicate kstorage.SelectionPredicate) *userWorkspaceWatcher {
 	workspaces, _ := authCache.List(user, labels.Everything(), fields.Everything())
-	knownWorkspaces := map[string]string{}
-	for _, workspace := range workspaces.Items {
-		knownWorkspaces[workspace.Name] = workspace.ResourceVersion
+	knownWorkspaces := map[string]*workspaceapi.ClusterWorkspace{}
+	for i := range workspaces.Items {
+		knownWorkspaces[workspaces.Items[i].Name] = &workspaces.Items[i]
 	}
 
 	// this is optional.  If they don't request it, don't include it.
 	initialWorkspaces := []workspaceapi.ClusterWorkspace{}
 	if includeAllExistingWorkspaces {
-		initialWorkspaces = append(initialWorkspaces, workspaces.Items...)
+		for i := range workspaces.Items {
+			if matches, err := predicate.Matches(&workspaces.Items[i]); err == nil && matches {
+				initialWorkspaces = append(initialWorkspaces, workspaces.Items[i])
+			}
+		}
 	}
 
 	w := &userWorkspaceWatcher{
@@ -116,18 +124,11 @@ func NewUserWorkspaceWatcher(user user.Info, lclusterName logicalcluster.Name, c
 		authCache:                authCache,
 		initialClusterWorkspaces: initialWorkspaces,
 		knownWorkspaces:          knownWorkspaces,
+		predicate:                predicate,
 
 		lclusterName: lclusterName,
 	}
 	w.emit = func(e watch.Event) {
-		// if dealing with workspace events, ensure that we only emit events for workspaces
-		// that match the field or label selector specified by a consumer
-		if workspace, ok := e.Object.(*workspaceapibeta1.Workspace); ok {
-			if matches, err := predicate.Matches(workspace); err != nil || !matches {
-				return
-			}
-		}
-
 		select {
 		case w.outgoing <- e:
 		case <-w.userStop:
@@ -136,72 +137,72 @@ func NewUserWorkspaceWatcher(user user.Info, lclusterName logicalcluster.Name, c
 	return w
 }
 
+// GroupMembershipChanged sends an event for the given workspace if it is new, changed or gone from the
+// perspective of the user. Workspaces moving into or out of the selection of the watch are sent as added
+// or deleted respectively. Deleted events carry the last known workspace.
 func (w *userWorkspaceWatcher) GroupMembershipChanged(workspaceName string, users, groups sets.String) {
 	hasAccess := users.Has(w.user.GetName()) || groups.HasAny(w.user.GetGroups()...)
-	_, known := w.knownWorkspaces[workspaceName]
-
-	var workspace workspaceapibeta1.Workspace
-	projection.ProjectClusterWorkspaceToWorkspace(&workspaceapi.ClusterWorkspace{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:        workspaceName,
-			ClusterName: w.lclusterName.String(),
-		},
-	}, &workspace)
+	lastKnown, known := w.knownWorkspaces[workspaceName]
 
+	var event watch.Event
+	var clusterWorkspace *workspaceapi.ClusterWorkspace
 	switch {
-	// this means that we were removed from the workspace
+	// this means that we were removed from the workspace, or that it got deleted
 	case !hasAccess && known:
 		delete(w.knownWorkspaces, workspaceName)
-
-		select {
-		case w.cacheIncoming <- watch.Event{
-			Type:   watch.Deleted,
-			Object: &workspace,
-		}:
-		default:
-			// remove the watcher so that we wont' be notified again and block
-			w.authCache.RemoveWatcher(w)
-			w.cacheError <- errors.New("delete notification timeout")
+		if !w.matches(lastKnown) {
+			return
 		}
+		event.Type, clusterWorkspace = watch.Deleted, lastKnown
 
 	case hasAccess:
-		clusterWorkspace, err := w.clusterWorkspaceCache.Get(w.lclusterName, workspaceName)
+		var err error
+		clusterWorkspace, err = w.clusterWorkspaceCache.Get(w.lclusterName, workspaceName)
 		if err != nil {
 			utilruntime.HandleError(err)
 			return
 		}
-		var workspace workspaceapibeta1.Workspace
-		projection.ProjectClusterWorkspaceToWorkspace(clusterWorkspace, &workspace)
-		if err != nil {
-			utilruntime.HandleError(err)
+
+		// if we've already notified for this particular resourceVersion, there's no work to do
+		if known && lastKnown.ResourceVersion == clusterWorkspace.ResourceVersion {
 			return
 		}
-		event := watch.Event{
-			Type:   watch.Added,
-			Object: &workspace,
-		}
+		w.knownWorkspaces[workspaceName] = clusterWorkspace
 
 		// if we already have this in our list, then we're getting notified because the object changed
-		if lastResourceVersion, known := w.knownWorkspaces[workspaceName]; known {
+		matches, matched := w.matches(clusterWorkspace), known && w.matches(lastKnown)
+		switch {
+		case matches && matched:
 			event.Type = watch.Modified
-
-			// if we've already notified for this particular resourceVersion, there's no work to do
-			if lastResourceVersion == clusterWorkspace.ResourceVersion {
-				return
-			}
-		}
-		w.knownWorkspaces[workspaceName] = clusterWorkspace.ResourceVersion
-
-		select {
-		case w.cacheIncoming <- event:
+		case matches:
+			event.Type = watch.Added
+		case matched:
+			event.Type, clusterWorkspace = watch.Deleted, lastKnown
 		default:
-			// remove the watcher so that we won't be notified again and block
-			w.authCache.RemoveWatcher(w)
-			w.cacheError <- errors.New("add notification timeout")
+			return
 		}
 
+	default:
+		return
+	}
+
+	var workspace workspaceapibeta1.Workspace
+	projection.ProjectClusterWorkspaceToWorkspace(clusterWorkspace, &workspace)
+	event.Object = &workspace
+
+	select {
+	case w.cacheIncoming <- event:
+	default:
+		// remove the watcher so that we won't be notified again and block
+		w.authCache.RemoveWatcher(w)
+		w.cacheError <- fmt.Errorf("%s notification timeout", strings.ToLower(string(event.Type)))
 	}
+}
 
+// matches returns whether the given workspace is selected by the watch.
+func (w *userWorkspaceWatcher) matches(clusterWorkspace *workspaceapi.ClusterWorkspace) bool {
+	matches, err := w.predicate.Matches(clusterWorkspace)
+	return err == nil && matches
 }
 
 // Watch pulls stuff from etcd, converts, and pushes out the outgoing channel. Meant to be
@@ -280,6 +280,98 @@ func TestAddModifyDeleteEventsByGroup(t *testing.T) {
 	}
 }
 
+func TestSelectionChangeEvents(t *testing.T) {
+	m := workspaceutil.MatchWorkspace(labels.SelectorFromSet(labels.Set{"team": "a"}), fields.Everything())
+	watcher, _ := newTestWatcher("bob", nil, m)
+	go watcher.Watch()
+
+	setTeam := func(resourceVersion, team string) {
+		workspace := &workspaceapi.ClusterWorkspace{ObjectMeta: metav1.ObjectMeta{
+			Name:            "ns-01",
+			ClusterName:     "lclusterName",
+			ResourceVersion: resourceVersion,
+			Labels:          map[string]string{"team": team},
+		}}
+		if err := watcher.clusterWorkspaceCache.Store.Update(workspace); err != nil {
+			t.Fatalf("unexpected error %v", err)
+		}
+	}
+	expectEvent := func(eventType watch.EventType, team string) {
+		select {
+		case event := <-watcher.ResultChan():
+			if event.Type != eventType {
+				t.Errorf("expected %v, got %v", eventType, event)
+			}
+			if got := event.Object.(*workspaceapiv1beta1.Workspace).Labels["team"]; got != team {
+				t.Errorf("expected team %q, got %q", team, got)
+			}
+		case <-time.After(3 * time.Second):
+			t.Fatalf("timeout")
+		}
+	}
+
+	setTeam("1", "a")
+	watcher.GroupMembershipChanged("ns-01", sets.NewString("bob"), sets.String{})
+	expectEvent(watch.Added, "a")
+
+	setTeam("2", "a")
+	watcher.GroupMembershipChanged("ns-01", sets.NewString("bob"), sets.String{})
+	expectEvent(watch.Modified, "a")
+
+	// moving out of the selection is observed as deletion of the last selected state
+	setTeam("3", "b")
+	watcher.GroupMembershipChanged("ns-01", sets.NewString("bob"), sets.String{})
+	expectEvent(watch.Deleted, "a")
+
+	// changes outside of the selection are not observed
+	setTeam("4", "c")
+	watcher.GroupMembershipChanged("ns-01", sets.NewString("bob"), sets.String{})
+	select {
+	case event := <-watcher.ResultChan():
+		t.Fatalf("unexpected event %v", event)
+	case <-time.After(3 * time.Second):
+	}
+
+	setTeam("5", "a")
+	watcher.GroupMembershipChanged("ns-01", sets.NewString("bob"), sets.String{})
+	expectEvent(watch.Added, "a")
+
+	// losing access is observed as deletion of the last known state
+	watcher.GroupMembershipChanged("ns-01", sets.NewString("alice"), sets.String{})
+	expectEvent(watch.Deleted, "a")
+}
+
+func TestInitialEvents(t *testing.T) {
+	objects := newClusterWorkspaces("ns-01", "ns-02")
+	objects[1].Labels = map[string]string{"team": "a"}
+	watcher, fakeAuthCache := newTestWatcher("bob", nil, matchAllPredicate(), objects...)
+	fakeAuthCache.clusterWorkspaces = objects
+
+	m := workspaceutil.MatchWorkspace(labels.SelectorFromSet(labels.Set{"team": "a"}), fields.Everything())
+	watcher = NewUserWorkspaceWatcher(watcher.user, watcher.lclusterName, watcher.clusterWorkspaceCache, fakeAuthCache, true, m)
+	go watcher.Watch()
+
+	select {
+	case event := <-watcher.ResultChan():
+		if event.Type != watch.Added {
+			t.Errorf("expected added, got %v", event)
+		}
+		if event.Object.(*workspaceapiv1beta1.Workspace).Name != "ns-02" {
+			t.Errorf("expected %v, got %#v", "ns-02", event.Object)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatalf("timeout")
+	}
+
+	// known workspaces are not added again
+	watcher.GroupMembershipChanged("ns-02", sets.NewString("bob"), sets.String{})
+	select {
+	case event := <-watcher.ResultChan():
+		t.Fatalf("unexpected event %v", event)
+	case <-time.After(3 * time.Second):
+	}
+}
+
 func newClusterWorkspaces(names ...string) []*workspaceapi.ClusterWorkspace {
 	ret := []*workspaceapi.ClusterWorkspace{}
 	for _, name := range names {
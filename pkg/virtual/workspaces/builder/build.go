@@ -32,10 +32,13 @@ import (
 
 	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
 	tenancyv1beta1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1beta1"
+	"github.com/kcp-dev/kcp/pkg/authorization/bootstrap"
+	"github.com/kcp-dev/kcp/pkg/authorization/delegated"
 	kcpclient "github.com/kcp-dev/kcp/pkg/client/clientset/versioned"
 	workspaceinformer "github.com/kcp-dev/kcp/pkg/client/informers/externalversions/tenancy/v1alpha1"
 	kcpopenapi "github.com/kcp-dev/kcp/pkg/openapi"
 	"github.com/kcp-dev/kcp/pkg/virtual/framework"
+	frameworkauthorization "github.com/kcp-dev/kcp/pkg/virtual/framework/authorization"
 	"github.com/kcp-dev/kcp/pkg/virtual/framework/fixedgvs"
 	frameworkrbac "github.com/kcp-dev/kcp/pkg/virtual/framework/rbac"
 	rbacwrapper "github.com/kcp-dev/kcp/pkg/virtual/framework/wrappers/rbac"
@@ -109,7 +112,7 @@ func BuildVirtualWorkspace(rootPathPrefix string, wildcardsClusterWorkspaces wor
 						rootClusterWorkspaceInformer.Informer(),
 						rootReviewer,
 						*workspaceauth.NewAttributesBuilder().
-							Verb("access").
+							Verb(bootstrap.WorkspaceAccessVerb).
 							Resource(tenancyv1alpha1.SchemeGroupVersion.WithResource("clusterworkspaces"), "content").
 							AttributesRecord,
 						rootRBACInformers,
@@ -140,11 +143,16 @@ func BuildVirtualWorkspace(rootPathPrefix string, wildcardsClusterWorkspaces wor
 						return nil, err
 					}
 
-					workspacesRest := registry.NewREST(kcpClusterClient.Cluster(tenancyv1alpha1.RootCluster).TenancyV1alpha1(), kubeClusterClient, kcpClusterClient, globalClusterWorkspaceCache, crbInformer, orgListener.FilteredClusterWorkspaces)
+					delegatedAuthzCache := frameworkauthorization.NewDelegatedAuthorizerCache(frameworkauthorization.DefaultDelegatedAuthorizerCacheTTL, wildcardsRbacInformers)
+
+					workspacesRest := registry.NewREST(kcpClusterClient.Cluster(tenancyv1alpha1.RootCluster).TenancyV1alpha1(), kubeClusterClient, kcpClusterClient, globalClusterWorkspaceCache, crbInformer, orgListener.FilteredClusterWorkspaces, delegatedAuthzCache.Wrap(delegated.NewDelegatedAuthorizer))
 					return map[string]fixedgvs.RestStorageBuilder{
 						"workspaces": func(apiGroupAPIServerConfig genericapiserver.CompletedConfig) (rest.Storage, error) {
 							return workspacesRest, nil
 						},
+						"workspaceaccessreviews": func(apiGroupAPIServerConfig genericapiserver.CompletedConfig) (rest.Storage, error) {
+							return registry.NewAccessReviewREST(workspacesRest), nil
+						},
 					}, nil
 				},
 			},
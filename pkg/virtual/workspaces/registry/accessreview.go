@@ -0,0 +1,187 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/kcp-dev/logicalcluster"
+
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	kuser "k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/apiserver/pkg/authorization/authorizer"
+	apirequest "k8s.io/apiserver/pkg/endpoints/request"
+	"k8s.io/apiserver/pkg/registry/rest"
+	"k8s.io/klog/v2"
+
+	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+	tenancyv1beta1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1beta1"
+	"github.com/kcp-dev/kcp/pkg/authorization/bootstrap"
+)
+
+// MaxAccessReviewWorkspaces is the maximum number of workspaces that can be
+// reviewed in one WorkspaceAccessReview.
+const MaxAccessReviewWorkspaces = 500
+
+// AccessReviewREST answers WorkspaceAccessReviews, i.e. which of a number of
+// workspaces of the org the request is made in can the user access and administrate.
+type AccessReviewREST struct {
+	workspaces *REST
+}
+
+var _ rest.Creater = &AccessReviewREST{}
+var _ rest.Scoper = &AccessReviewREST{}
+
+// NewAccessReviewREST returns a RESTStorage object answering WorkspaceAccessReviews against
+// the same workspaces as the given workspaces storage.
+func NewAccessReviewREST(workspaces *REST) *AccessReviewREST {
+	return &AccessReviewREST{
+		workspaces: workspaces,
+	}
+}
+
+// New returns a new WorkspaceAccessReview
+func (s *AccessReviewREST) New() runtime.Object {
+	return &tenancyv1beta1.WorkspaceAccessReview{}
+}
+
+func (s *AccessReviewREST) NamespaceScoped() bool {
+	return false
+}
+
+func (s *AccessReviewREST) Create(ctx context.Context, obj runtime.Object, createValidation rest.ValidateObjectFunc, options *metav1.CreateOptions) (runtime.Object, error) {
+	review, ok := obj.(*tenancyv1beta1.WorkspaceAccessReview)
+	if !ok {
+		return nil, kerrors.NewBadRequest(fmt.Sprintf("not a WorkspaceAccessReview: %#v", obj))
+	}
+	if errs := validateAccessReview(review); len(errs) > 0 {
+		return nil, kerrors.NewInvalid(tenancyv1beta1.Kind("WorkspaceAccessReview"), review.Name, errs)
+	}
+	if createValidation != nil {
+		if err := createValidation(ctx, obj.DeepCopyObject()); err != nil {
+			return nil, err
+		}
+	}
+
+	userInfo, ok := apirequest.UserFrom(ctx)
+	if !ok {
+		return nil, kerrors.NewForbidden(tenancyv1beta1.Resource("workspaceaccessreviews"), "", fmt.Errorf("unable to review workspace access without a user on the context"))
+	}
+
+	orgClusterName := ctx.Value(WorkspacesOrgKey).(logicalcluster.Name)
+	if err := s.workspaces.authorizeOrgForUser(ctx, orgClusterName, userInfo, bootstrap.WorkspaceAccessVerb); err != nil {
+		return nil, err
+	}
+	usePersonalScope := shouldUsePersonalScope(ctx.Value(WorkspacesScopeKey).(string), orgClusterName)
+
+	visible, err := s.visibleWorkspaces(userInfo, orgClusterName, usePersonalScope)
+	if err != nil {
+		return nil, err
+	}
+
+	authz, err := s.workspaces.delegatedAuthz(orgClusterName, s.workspaces.kubeClusterClient)
+	if err != nil {
+		klog.Errorf("failed to get delegated authorizer for logical cluster %s", orgClusterName)
+		return nil, kerrors.NewForbidden(tenancyv1beta1.Resource("workspaceaccessreviews"), "", fmt.Errorf("%q workspace access not permitted", orgClusterName))
+	}
+
+	result := review.DeepCopy()
+	result.Status.Workspaces = make([]tenancyv1beta1.WorkspaceAccess, 0, len(review.Spec.Workspaces))
+	for _, name := range review.Spec.Workspaces {
+		access := tenancyv1beta1.WorkspaceAccess{Name: name}
+
+		internalName := name
+		if usePersonalScope {
+			internalName, err = s.workspaces.getInternalNameFromPrettyName(userInfo, orgClusterName, name)
+			if kerrors.IsNotFound(err) {
+				internalName = ""
+			} else if err != nil {
+				return nil, err
+			}
+		}
+		if visible.Has(internalName) {
+			access.Access = allowedOnContent(ctx, authz, userInfo, bootstrap.WorkspaceAccessVerb, orgClusterName, internalName)
+			access.Admin = allowedOnContent(ctx, authz, userInfo, bootstrap.WorkspaceAdminVerb, orgClusterName, internalName)
+		}
+
+		result.Status.Workspaces = append(result.Status.Workspaces, access)
+	}
+
+	return result, nil
+}
+
+// visibleWorkspaces returns the internal names of the workspaces the user can see in the org,
+// such that workspaces that do not exist are never reported as accessible because of wildcard
+// RBAC rules.
+func (s *AccessReviewREST) visibleWorkspaces(userInfo kuser.Info, orgClusterName logicalcluster.Name, usePersonalScope bool) (sets.String, error) {
+	visible := sets.NewString()
+
+	clusterWorkspaces := s.workspaces.getFilteredClusterWorkspaces(orgClusterName)
+	if clusterWorkspaces == nil {
+		return visible, nil
+	}
+	list, err := clusterWorkspaces.List(withoutGroupsWhenPersonal(userInfo, usePersonalScope), labels.Everything(), fields.Everything())
+	if err != nil {
+		return nil, err
+	}
+	for _, ws := range list.Items {
+		visible.Insert(ws.Name)
+	}
+	return visible, nil
+}
+
+func allowedOnContent(ctx context.Context, authz authorizer.Authorizer, userInfo kuser.Info, verb string, orgClusterName logicalcluster.Name, name string) bool {
+	attr := authorizer.AttributesRecord{
+		User:            userInfo,
+		Verb:            verb,
+		APIGroup:        tenancyv1alpha1.SchemeGroupVersion.Group,
+		APIVersion:      tenancyv1alpha1.SchemeGroupVersion.Version,
+		Resource:        "clusterworkspaces",
+		Subresource:     "content",
+		Name:            name,
+		ResourceRequest: true,
+	}
+	decision, _, err := authz.Authorize(ctx, attr)
+	if err != nil {
+		klog.Errorf("failed to authorize user %q to %q clusterworkspaces/content name %q in %s: %v", userInfo.GetName(), verb, name, orgClusterName, err)
+		return false
+	}
+	return decision == authorizer.DecisionAllow
+}
+
+func validateAccessReview(review *tenancyv1beta1.WorkspaceAccessReview) field.ErrorList {
+	var errs field.ErrorList
+
+	workspacesPath := field.NewPath("spec", "workspaces")
+	if len(review.Spec.Workspaces) > MaxAccessReviewWorkspaces {
+		errs = append(errs, field.TooMany(workspacesPath, len(review.Spec.Workspaces), MaxAccessReviewWorkspaces))
+	}
+	for i, name := range review.Spec.Workspaces {
+		if name == "" {
+			errs = append(errs, field.Required(workspacesPath.Index(i), "workspace name must not be empty"))
+		}
+	}
+
+	return errs
+}
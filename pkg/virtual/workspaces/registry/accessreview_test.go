@@ -0,0 +1,207 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registry
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/kcp-dev/logicalcluster"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	kuser "k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/client-go/kubernetes/fake"
+
+	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+	tenancyv1beta1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1beta1"
+	tenancyv1fake "github.com/kcp-dev/kcp/pkg/client/clientset/versioned/fake"
+	workspaceauth "github.com/kcp-dev/kcp/pkg/virtual/workspaces/authorization"
+)
+
+func newAccessReview(names ...string) *tenancyv1beta1.WorkspaceAccessReview {
+	return &tenancyv1beta1.WorkspaceAccessReview{
+		Spec: tenancyv1beta1.WorkspaceAccessReviewSpec{
+			Workspaces: names,
+		},
+	}
+}
+
+func TestAccessReviewOrganizationWorkspaces(t *testing.T) {
+	user := &kuser.DefaultInfo{
+		Name:   "test-user",
+		UID:    "test-uid",
+		Groups: []string{"test-group"},
+	}
+	test := TestDescription{
+		TestData: TestData{
+			user:    user,
+			scope:   OrganizationScope,
+			orgName: logicalcluster.New("root:orgName"),
+			reviewer: workspaceauth.NewReviewer(&mockSubjectLocator{
+				subjects: map[string]map[string][]rbacv1.Subject{
+					"access/tenancy.kcp.dev/v1alpha1/clusterworkspaces/content": {
+						"foo":     rbacUsers(user.Name),
+						"bar":     rbacGroups("test-group"),
+						"missing": rbacUsers(user.Name),
+					},
+					"admin/tenancy.kcp.dev/v1alpha1/clusterworkspaces/content": {
+						"foo": rbacUsers(user.Name),
+					},
+				},
+			}),
+			rootReviewer: workspaceauth.NewReviewer(&mockSubjectLocator{
+				subjects: map[string]map[string][]rbacv1.Subject{
+					"access/tenancy.kcp.dev/v1alpha1/clusterworkspaces/content": {
+						"orgName": rbacGroups("test-group"),
+					},
+				},
+			}),
+			clusterWorkspaces: []tenancyv1alpha1.ClusterWorkspace{
+				{ObjectMeta: metav1.ObjectMeta{Name: "foo", ClusterName: "root:orgName"}},
+				{ObjectMeta: metav1.ObjectMeta{Name: "bar", ClusterName: "root:orgName"}},
+				{ObjectMeta: metav1.ObjectMeta{Name: "baz", ClusterName: "root:orgName"}},
+			},
+		},
+		apply: func(t *testing.T, storage *REST, ctx context.Context, kubeClient *fake.Clientset, kcpClient *tenancyv1fake.Clientset, listerCheckedUsers func() []kuser.Info, testData TestData) {
+			response, err := NewAccessReviewREST(storage).Create(ctx, newAccessReview("foo", "bar", "baz", "missing"), nil, &metav1.CreateOptions{})
+			require.NoError(t, err)
+			require.IsType(t, &tenancyv1beta1.WorkspaceAccessReview{}, response)
+			assert.Equal(t, []tenancyv1beta1.WorkspaceAccess{
+				{Name: "foo", Access: true, Admin: true},
+				{Name: "bar", Access: true},
+				{Name: "baz"},
+				{Name: "missing"},
+			}, response.(*tenancyv1beta1.WorkspaceAccessReview).Status.Workspaces)
+			require.Len(t, listerCheckedUsers(), 1, "The workspaceLister should have been called only once")
+		},
+	}
+	applyTest(t, test)
+}
+
+func TestAccessReviewPersonalWorkspacesWithPrettyName(t *testing.T) {
+	user := &kuser.DefaultInfo{
+		Name:   "test-user",
+		UID:    "test-uid",
+		Groups: []string{"test-group"},
+	}
+	test := TestDescription{
+		TestData: TestData{
+			user:    user,
+			scope:   PersonalScope,
+			orgName: logicalcluster.New("root:orgName"),
+			reviewer: workspaceauth.NewReviewer(&mockSubjectLocator{
+				subjects: map[string]map[string][]rbacv1.Subject{
+					"access/tenancy.kcp.dev/v1alpha1/clusterworkspaces/content": {
+						"foo--1": rbacUsers(user.Name),
+						"bar":    rbacUsers(user.Name),
+					},
+				},
+			}),
+			rootReviewer: workspaceauth.NewReviewer(&mockSubjectLocator{
+				subjects: map[string]map[string][]rbacv1.Subject{
+					"access/tenancy.kcp.dev/v1alpha1/clusterworkspaces/content": {
+						"orgName": rbacGroups("test-group"),
+					},
+				},
+			}),
+			clusterWorkspaces: []tenancyv1alpha1.ClusterWorkspace{
+				{ObjectMeta: metav1.ObjectMeta{Name: "foo--1", ClusterName: "root:orgName"}},
+				{ObjectMeta: metav1.ObjectMeta{Name: "bar", ClusterName: "root:orgName"}},
+			},
+			clusterRoleBindings: []rbacv1.ClusterRoleBinding{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:        getRoleBindingName(OwnerRoleType, "foo", user),
+						ClusterName: "root:orgName",
+						Labels: map[string]string{
+							PrettyNameLabel:   "foo",
+							InternalNameLabel: "foo--1",
+						},
+					},
+					Subjects: []rbacv1.Subject{
+						{
+							Kind: "User",
+							Name: user.Name,
+						},
+					},
+				},
+			},
+		},
+		apply: func(t *testing.T, storage *REST, ctx context.Context, kubeClient *fake.Clientset, kcpClient *tenancyv1fake.Clientset, listerCheckedUsers func() []kuser.Info, testData TestData) {
+			response, err := NewAccessReviewREST(storage).Create(ctx, newAccessReview("foo", "bar"), nil, &metav1.CreateOptions{})
+			require.NoError(t, err)
+			assert.Equal(t, []tenancyv1beta1.WorkspaceAccess{
+				{Name: "foo", Access: true},
+				{Name: "bar"},
+			}, response.(*tenancyv1beta1.WorkspaceAccessReview).Status.Workspaces, "bar has no pretty name for the user")
+			checkedUsers := listerCheckedUsers()
+			require.Len(t, checkedUsers, 1, "The workspaceLister should have been called only once")
+			assert.Empty(t, checkedUsers[0].GetGroups(), "The workspaceLister should have checked the user without its groups")
+		},
+	}
+	applyTest(t, test)
+}
+
+func TestAccessReviewForbiddenToNonOrgMember(t *testing.T) {
+	user := &kuser.DefaultInfo{
+		Name:   "test-user",
+		UID:    "test-uid",
+		Groups: []string{"test-group"},
+	}
+	test := TestDescription{
+		TestData: TestData{
+			user:         user,
+			scope:        OrganizationScope,
+			orgName:      logicalcluster.New("root:orgName"),
+			reviewer:     workspaceauth.NewReviewer(nil),
+			rootReviewer: workspaceauth.NewReviewer(&mockSubjectLocator{}),
+		},
+		apply: func(t *testing.T, storage *REST, ctx context.Context, kubeClient *fake.Clientset, kcpClient *tenancyv1fake.Clientset, listerCheckedUsers func() []kuser.Info, testData TestData) {
+			response, err := NewAccessReviewREST(storage).Create(ctx, newAccessReview("foo"), nil, &metav1.CreateOptions{})
+			assert.Nil(t, response)
+			require.Error(t, err)
+			assert.True(t, errors.IsForbidden(err))
+			assert.Empty(t, listerCheckedUsers())
+		},
+	}
+	applyTest(t, test)
+}
+
+func TestAccessReviewInvalid(t *testing.T) {
+	tooMany := make([]string, MaxAccessReviewWorkspaces+1)
+	for i := range tooMany {
+		tooMany[i] = fmt.Sprintf("ws-%d", i)
+	}
+
+	tests := map[string]*tenancyv1beta1.WorkspaceAccessReview{
+		"empty name":          newAccessReview("foo", ""),
+		"too many workspaces": newAccessReview(tooMany...),
+	}
+	for name, review := range tests {
+		review := review
+		t.Run(name, func(t *testing.T) {
+			_, err := NewAccessReviewREST(&REST{}).Create(context.Background(), review, nil, &metav1.CreateOptions{})
+			require.Error(t, err)
+			assert.True(t, errors.IsInvalid(err), "expected invalid error, got %v", err)
+		})
+	}
+}
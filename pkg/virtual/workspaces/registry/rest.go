@@ -48,6 +48,7 @@ import (
 	"github.com/kcp-dev/kcp/pkg/apis/tenancy/projection"
 	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
 	tenancyv1beta1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1beta1"
+	"github.com/kcp-dev/kcp/pkg/authorization/bootstrap"
 	"github.com/kcp-dev/kcp/pkg/authorization/delegated"
 	kcpclientset "github.com/kcp-dev/kcp/pkg/client/clientset/versioned"
 	tenancyclient "github.com/kcp-dev/kcp/pkg/client/clientset/versioned/typed/tenancy/v1alpha1"
@@ -143,13 +144,14 @@ func NewREST(
 	clusterWorkspaceCache *workspacecache.ClusterWorkspaceCache,
 	wilcardsCRBInformer rbacinformers.ClusterRoleBindingInformer,
 	getFilteredClusterWorkspaces func(orgClusterName logicalcluster.Name) FilteredClusterWorkspaces,
+	delegatedAuthz delegated.DelegatedAuthorizerFactory,
 ) *REST {
 	mainRest := &REST{
 		getFilteredClusterWorkspaces: getFilteredClusterWorkspaces,
 
 		kubeClusterClient: kubeClusterClient,
 		kcpClusterClient:  kcpClusterClient,
-		delegatedAuthz:    delegated.NewDelegatedAuthorizer,
+		delegatedAuthz:    delegatedAuthz,
 
 		crbInformer: wilcardsCRBInformer,
 
@@ -264,7 +266,7 @@ func (s *REST) List(ctx context.Context, options *metainternal.ListOptions) (run
 		return nil, kerrors.NewForbidden(tenancyv1beta1.Resource("workspaces"), "", fmt.Errorf("unable to list workspaces without a user on the context"))
 	}
 	orgClusterName := ctx.Value(WorkspacesOrgKey).(logicalcluster.Name)
-	if err := s.authorizeOrgForUser(ctx, orgClusterName, userInfo, "access"); err != nil {
+	if err := s.authorizeOrgForUser(ctx, orgClusterName, userInfo, bootstrap.WorkspaceAccessVerb); err != nil {
 		return nil, err
 	}
 
@@ -313,19 +315,39 @@ func (s *REST) Watch(ctx context.Context, options *metainternal.ListOptions) (wa
 	}
 
 	orgClusterName := ctx.Value(WorkspacesOrgKey).(logicalcluster.Name)
-	if err := s.authorizeOrgForUser(ctx, orgClusterName, userInfo, "access"); err != nil {
+	if err := s.authorizeOrgForUser(ctx, orgClusterName, userInfo, bootstrap.WorkspaceAccessVerb); err != nil {
 		return nil, err
 	}
 	clusterWorkspaces := s.getFilteredClusterWorkspaces(orgClusterName)
 
-	includeAllExistingProjects := (options != nil) && options.ResourceVersion == "0"
+	// like for other resources, start with the current state unless a specific resource version is requested
+	includeAllExistingProjects := options == nil || options.ResourceVersion == "" || options.ResourceVersion == "0"
 
+	usePersonalScope := shouldUsePersonalScope(ctx.Value(WorkspacesScopeKey).(string), orgClusterName)
 	m := workspaceutil.MatchWorkspace(InternalListOptionsToSelectors(options))
-	watcher := workspaceauth.NewUserWorkspaceWatcher(userInfo, orgClusterName, s.clusterWorkspaceCache, clusterWorkspaces, includeAllExistingProjects, m)
+	watcher := workspaceauth.NewUserWorkspaceWatcher(withoutGroupsWhenPersonal(userInfo, usePersonalScope), orgClusterName, s.clusterWorkspaceCache, clusterWorkspaces, includeAllExistingProjects, m)
 	clusterWorkspaces.AddWatcher(watcher)
 
 	go watcher.Watch()
-	return watcher, nil
+
+	if !usePersonalScope {
+		return watcher, nil
+	}
+
+	// translate the internal names of personal workspaces to pretty names, as done by List
+	return watch.Filter(watcher, func(event watch.Event) (watch.Event, bool) {
+		workspace, ok := event.Object.(*tenancyv1beta1.Workspace)
+		if !ok {
+			return event, true
+		}
+		prettyName, err := s.getPrettyNameFromInternalName(userInfo, orgClusterName, workspace.Name)
+		if err != nil {
+			klog.V(4).Infof("Dropping %s event of workspace %s without pretty name: %v", event.Type, workspace.Name, err)
+			return event, false
+		}
+		workspace.Name = prettyName
+		return event, true
+	}), nil
 }
 
 var _ = rest.Getter(&REST{})
@@ -354,7 +376,7 @@ func (s *REST) getClusterWorkspace(ctx context.Context, name string, options *me
 	}
 
 	orgClusterName := ctx.Value(WorkspacesOrgKey).(logicalcluster.Name)
-	if err := s.authorizeOrgForUser(ctx, orgClusterName, userInfo, "access"); err != nil {
+	if err := s.authorizeOrgForUser(ctx, orgClusterName, userInfo, bootstrap.WorkspaceAccessVerb); err != nil {
 		return nil, err
 	}
 
@@ -420,7 +442,7 @@ var roleRules = map[RoleType][]rbacv1.PolicyRule{
 		},
 		{
 			Resources: []string{"clusterworkspaces/content"},
-			Verbs:     []string{"admin", "access"},
+			Verbs:     []string{bootstrap.WorkspaceAdminVerb, bootstrap.WorkspaceAccessVerb},
 		},
 	},
 }
@@ -499,7 +521,7 @@ func (s *REST) Create(ctx context.Context, obj runtime.Object, createValidation
 	}
 
 	orgClusterName := ctx.Value(WorkspacesOrgKey).(logicalcluster.Name)
-	if err := s.authorizeOrgForUser(ctx, orgClusterName, userInfo, "member"); err != nil {
+	if err := s.authorizeOrgForUser(ctx, orgClusterName, userInfo, bootstrap.WorkspaceMemberVerb); err != nil {
 		return nil, err
 	}
 
@@ -581,12 +603,8 @@ func (s *REST) Create(ctx context.Context, obj runtime.Object, createValidation
 	// retrying with increasing suffixes until a workspace with the same name
 	// doesn't already exist.
 	// The suffixed name based on the pretty name will be the internal name
-	clusterWorkspace := &tenancyv1alpha1.ClusterWorkspace{
-		ObjectMeta: workspace.ObjectMeta,
-		Spec: tenancyv1alpha1.ClusterWorkspaceSpec{
-			Type: workspace.Spec.Type,
-		},
-	}
+	clusterWorkspace := &tenancyv1alpha1.ClusterWorkspace{}
+	projection.ProjectWorkspaceToClusterWorkspace(workspace, clusterWorkspace)
 	createdClusterWorkspace, err := s.kcpClusterClient.Cluster(orgClusterName).TenancyV1alpha1().ClusterWorkspaces().Create(ctx, clusterWorkspace, metav1.CreateOptions{})
 	if err != nil && kerrors.IsAlreadyExists(err) {
 		clusterWorkspace.Name = ""
@@ -646,7 +664,7 @@ func (s *REST) Delete(ctx context.Context, name string, deleteValidation rest.Va
 	}
 
 	orgClusterName := ctx.Value(WorkspacesOrgKey).(logicalcluster.Name)
-	if err := s.authorizeOrgForUser(ctx, orgClusterName, userInfo, "access"); err != nil {
+	if err := s.authorizeOrgForUser(ctx, orgClusterName, userInfo, bootstrap.WorkspaceAccessVerb); err != nil {
 		return nil, false, err
 	}
 
@@ -686,7 +704,7 @@ func (s *REST) Delete(ctx context.Context, name string, deleteValidation rest.Va
 		// check for admin verb on the content
 		contentAdminAttr := authorizer.AttributesRecord{
 			User:            userInfo,
-			Verb:            "admin",
+			Verb:            bootstrap.WorkspaceAdminVerb,
 			APIGroup:        tenancyv1alpha1.SchemeGroupVersion.Group,
 			APIVersion:      tenancyv1alpha1.SchemeGroupVersion.Version,
 			Resource:        "clusterworkspaces",
@@ -695,10 +713,10 @@ func (s *REST) Delete(ctx context.Context, name string, deleteValidation rest.Va
 			ResourceRequest: true,
 		}
 		if decision, reason, err := authz.Authorize(ctx, contentAdminAttr); err != nil {
-			klog.Errorf("failed to authorize user %q to %q clusterworkspaces/content name %q in %s", userInfo.GetName(), "admin", internalName, orgClusterName)
+			klog.Errorf("failed to authorize user %q to %q clusterworkspaces/content name %q in %s", userInfo.GetName(), bootstrap.WorkspaceAdminVerb, internalName, orgClusterName)
 			return nil, false, kerrors.NewForbidden(tenancyv1beta1.Resource("workspaces"), "", fmt.Errorf("deletion in workspace %q is not allowed", orgClusterName))
 		} else if decision != authorizer.DecisionAllow {
-			klog.Errorf("user %q lacks (%s) clusterworkspaces/content %q permission and clusterworkspaces/workspace %s permission for %q in %s: %s", userInfo.GetName(), decisions[decision], "admin", "delete", internalName, orgClusterName, reason)
+			klog.Errorf("user %q lacks (%s) clusterworkspaces/content %q permission and clusterworkspaces/workspace %s permission for %q in %s: %s", userInfo.GetName(), decisions[decision], bootstrap.WorkspaceAdminVerb, "delete", internalName, orgClusterName, reason)
 			return nil, false, kerrors.NewForbidden(tenancyv1beta1.Resource("workspaces"), internalName, fmt.Errorf("deletion in workspace %q is not allowed", orgClusterName))
 		}
 	}
@@ -0,0 +1,125 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apibinding
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/restmapper"
+
+	"github.com/kcp-dev/kcp/config/helpers"
+	apisv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apis/v1alpha1"
+	clientset "github.com/kcp-dev/kcp/pkg/client/clientset/versioned"
+	"github.com/kcp-dev/kcp/test/e2e/framework"
+)
+
+func TestAPIBindingConversion(t *testing.T) {
+	t.Parallel()
+
+	server := framework.SharedKcpServer(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	orgClusterName := framework.NewOrganizationFixture(t, server)
+	sourceWorkspace := framework.NewWorkspaceFixture(t, server, orgClusterName, "Universal")
+	targetWorkspace := framework.NewWorkspaceFixture(t, server, orgClusterName, "Universal")
+
+	cfg := server.DefaultConfig(t)
+
+	kcpClients, err := clientset.NewClusterForConfig(cfg)
+	require.NoError(t, err, "failed to construct kcp cluster client for server")
+
+	dynamicClients, err := dynamic.NewClusterForConfig(cfg)
+	require.NoError(t, err, "failed to construct dynamic cluster client for server")
+
+	t.Logf("Install a cowboys APIResourceSchema with two versions and a conversion into workspace %q", sourceWorkspace)
+	mapper := restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(kcpClients.Cluster(sourceWorkspace).Discovery()))
+	err = helpers.CreateResourceFromFS(ctx, dynamicClients.Cluster(sourceWorkspace), mapper, "apiresourceschema_cowboys_versioned.yaml", testFiles)
+	require.NoError(t, err)
+
+	t.Logf("Create an APIExport for it")
+	cowboysAPIExport := &apisv1alpha1.APIExport{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "versioned-cowboys",
+		},
+		Spec: apisv1alpha1.APIExportSpec{
+			LatestResourceSchemas: []string{"versioned.cowboys.wildwest.dev"},
+		},
+	}
+	_, err = kcpClients.Cluster(sourceWorkspace).ApisV1alpha1().APIExports().Create(ctx, cowboysAPIExport, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	t.Logf("Create an APIBinding in workspace %q that points to the versioned-cowboys export", targetWorkspace)
+	apiBinding := &apisv1alpha1.APIBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "cowboys",
+		},
+		Spec: apisv1alpha1.APIBindingSpec{
+			Reference: apisv1alpha1.ExportReference{
+				Workspace: &apisv1alpha1.WorkspaceExportReference{
+					WorkspaceName: sourceWorkspace.Base(),
+					ExportName:    cowboysAPIExport.Name,
+				},
+			},
+		},
+	}
+	_, err = kcpClients.Cluster(targetWorkspace).ApisV1alpha1().APIBindings().Create(ctx, apiBinding, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	v1alpha1Cowboys := dynamicClients.Cluster(targetWorkspace).Resource(schema.GroupVersionResource{Group: "wildwest.dev", Version: "v1alpha1", Resource: "cowboys"}).Namespace("default")
+	v1alpha2Cowboys := dynamicClients.Cluster(targetWorkspace).Resource(schema.GroupVersionResource{Group: "wildwest.dev", Version: "v1alpha2", Resource: "cowboys"}).Namespace("default")
+
+	t.Logf("Create a v1alpha1 cowboy in workspace %q", targetWorkspace)
+	require.Eventually(t, func() bool {
+		_, err := v1alpha1Cowboys.Create(ctx, &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "wildwest.dev/v1alpha1",
+			"kind":       "Cowboy",
+			"metadata":   map[string]interface{}{"name": "joe"},
+			"spec":       map[string]interface{}{"intent": "ride"},
+		}}, metav1.CreateOptions{})
+		if err != nil {
+			t.Logf("error creating cowboy: %v", err)
+			return false
+		}
+		return true
+	}, wait.ForeverTestTimeout, 100*time.Millisecond, "failed to create v1alpha1 cowboy")
+
+	t.Logf("Expect the cowboy to be converted to v1alpha2")
+	cowboy, err := v1alpha2Cowboys.Get(ctx, "joe", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{"purpose": "ride"}, cowboy.Object["spec"])
+
+	t.Logf("Update the cowboy through v1alpha2 and expect the change in v1alpha1")
+	require.NoError(t, unstructured.SetNestedField(cowboy.Object, "rest", "spec", "purpose"))
+	_, err = v1alpha2Cowboys.Update(ctx, cowboy, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	cowboy, err = v1alpha1Cowboys.Get(ctx, "joe", metav1.GetOptions{})
+	require.NoError(t, err)
+	require.Equal(t, "wildwest.dev/v1alpha1", cowboy.GetAPIVersion())
+	require.Equal(t, map[string]interface{}{"intent": "rest"}, cowboy.Object["spec"])
+}
@@ -0,0 +1,121 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apibinding
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/restmapper"
+
+	"github.com/kcp-dev/kcp/config/helpers"
+	apisv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apis/v1alpha1"
+	clientset "github.com/kcp-dev/kcp/pkg/client/clientset/versioned"
+	"github.com/kcp-dev/kcp/test/e2e/framework"
+)
+
+func TestAPIBindingOpenAPI(t *testing.T) {
+	t.Parallel()
+
+	server := framework.SharedKcpServer(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	orgClusterName := framework.NewOrganizationFixture(t, server)
+	serviceProviderWorkspace := framework.NewWorkspaceFixture(t, server, orgClusterName, "Universal")
+	consumerWorkspace := framework.NewWorkspaceFixture(t, server, orgClusterName, "Universal")
+
+	cfg := server.DefaultConfig(t)
+
+	kcpClients, err := clientset.NewClusterForConfig(cfg)
+	require.NoError(t, err, "failed to construct kcp cluster client for server")
+
+	dynamicClients, err := dynamic.NewClusterForConfig(cfg)
+	require.NoError(t, err, "failed to construct dynamic cluster client for server")
+
+	const cowboysPath = "/apis/wildwest.dev/v1alpha1/namespaces/{namespace}/cowboys"
+	openAPIPaths := func(t *testing.T) sets.String {
+		doc, err := kcpClients.Cluster(consumerWorkspace).Discovery().OpenAPISchema()
+		require.NoError(t, err, "error retrieving OpenAPI spec of workspace %q", consumerWorkspace)
+		paths := sets.NewString()
+		for _, path := range doc.GetPaths().GetPath() {
+			paths.Insert(path.GetName())
+		}
+		return paths
+	}
+
+	t.Logf("Make sure the OpenAPI spec of consumer workspace %q has built-in and kcp types, but no cowboys", consumerWorkspace)
+	paths := openAPIPaths(t)
+	require.True(t, paths.Has("/api/v1/namespaces/{namespace}/configmaps"), "missing built-in configmaps")
+	require.True(t, paths.Has("/apis/apis.kcp.dev/v1alpha1/apibindings"), "missing kcp apibindings")
+	require.False(t, paths.Has(cowboysPath), "unexpected cowboys")
+
+	t.Logf("Install today cowboys APIResourceSchema into service provider workspace %q", serviceProviderWorkspace)
+	mapper := restmapper.NewDeferredDiscoveryRESTMapper(memory.NewMemCacheClient(kcpClients.Cluster(serviceProviderWorkspace).Discovery()))
+	err = helpers.CreateResourceFromFS(ctx, dynamicClients.Cluster(serviceProviderWorkspace), mapper, "apiresourceschema_cowboys.yaml", testFiles)
+	require.NoError(t, err)
+
+	t.Logf("Create an APIExport for it")
+	cowboysAPIExport := &apisv1alpha1.APIExport{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "today-cowboys",
+		},
+		Spec: apisv1alpha1.APIExportSpec{
+			LatestResourceSchemas: []string{"today.cowboys.wildwest.dev"},
+		},
+	}
+	_, err = kcpClients.Cluster(serviceProviderWorkspace).ApisV1alpha1().APIExports().Create(ctx, cowboysAPIExport, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	t.Logf("Create an APIBinding in consumer workspace %q", consumerWorkspace)
+	apiBinding := &apisv1alpha1.APIBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "cowboys",
+		},
+		Spec: apisv1alpha1.APIBindingSpec{
+			Reference: apisv1alpha1.ExportReference{
+				Workspace: &apisv1alpha1.WorkspaceExportReference{
+					WorkspaceName: serviceProviderWorkspace.Base(),
+					ExportName:    "today-cowboys",
+				},
+			},
+		},
+	}
+	_, err = kcpClients.Cluster(consumerWorkspace).ApisV1alpha1().APIBindings().Create(ctx, apiBinding, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	t.Logf("Make sure cowboys show up in the OpenAPI spec of consumer workspace %q", consumerWorkspace)
+	require.Eventually(t, func() bool {
+		return openAPIPaths(t).Has(cowboysPath)
+	}, wait.ForeverTestTimeout, 100*time.Millisecond, "cowboys missing in the OpenAPI spec of workspace %q", consumerWorkspace)
+
+	t.Logf("Make sure cowboys do NOT show up in the OpenAPI spec of service provider workspace %q", serviceProviderWorkspace)
+	doc, err := kcpClients.Cluster(serviceProviderWorkspace).Discovery().OpenAPISchema()
+	require.NoError(t, err)
+	for _, path := range doc.GetPaths().GetPath() {
+		require.NotEqual(t, cowboysPath, path.GetName(), "unexpected cowboys in workspace %q", serviceProviderWorkspace)
+	}
+}
@@ -0,0 +1,119 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package authorizer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kcp-dev/logicalcluster"
+	"github.com/stretchr/testify/require"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apiserver/pkg/authentication/user"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+
+	"github.com/kcp-dev/kcp/test/e2e/framework"
+)
+
+func TestImpersonation(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancelFunc := context.WithCancel(context.Background())
+	t.Cleanup(cancelFunc)
+
+	server := framework.SharedKcpServer(t)
+
+	cfg := server.DefaultConfig(t)
+	kubeClusterClient, err := kubernetes.NewClusterForConfig(cfg)
+	require.NoError(t, err)
+
+	org := framework.NewOrganizationFixture(t, server)
+	team := framework.NewWorkspaceFixture(t, server, org, "Universal")
+	otherOrg := framework.NewOrganizationFixture(t, server)
+	otherTeam := framework.NewWorkspaceFixture(t, server, otherOrg, "Universal")
+
+	for _, clusterName := range []logicalcluster.Name{org, team, otherOrg, otherTeam} {
+		framework.AdmitWorkspaceAccess(t, ctx, kubeClusterClient, clusterName, []string{"user-1", "user-2"}, nil, []string{"access"})
+	}
+
+	t.Logf("Allow user-2 to list configmaps in both team workspaces")
+	for _, clusterName := range []logicalcluster.Name{team, otherTeam} {
+		grant(t, ctx, kubeClusterClient, clusterName, "user-2", rbacv1.PolicyRule{Verbs: []string{"list"}, APIGroups: []string{""}, Resources: []string{"configmaps"}})
+	}
+
+	t.Logf("Allow user-1 to impersonate user-2 in %s and its subtree", org)
+	grant(t, ctx, kubeClusterClient, org, "user-1", rbacv1.PolicyRule{Verbs: []string{"impersonate"}, APIGroups: []string{""}, Resources: []string{"users"}, ResourceNames: []string{"user-2"}})
+
+	user1KubeClusterClient, err := kubernetes.NewClusterForConfig(userConfig("user-1", cfg))
+	require.NoError(t, err)
+	impersonatingKubeClusterClient, err := kubernetes.NewClusterForConfig(impersonatingConfig(userConfig("user-1", cfg), "user-2"))
+	require.NoError(t, err)
+
+	t.Logf("user-1 cannot list configmaps in %s on its own", team)
+	_, err = user1KubeClusterClient.Cluster(team).CoreV1().ConfigMaps("default").List(ctx, metav1.ListOptions{})
+	require.Error(t, err)
+
+	t.Logf("user-1 can list configmaps in %s when impersonating user-2", team)
+	require.Eventually(t, func() bool {
+		_, err := impersonatingKubeClusterClient.Cluster(team).CoreV1().ConfigMaps("default").List(ctx, metav1.ListOptions{})
+		if err != nil {
+			t.Logf("failed to list configmaps as user-2: %v", err)
+			return false
+		}
+		return true
+	}, wait.ForeverTestTimeout, time.Millisecond*100)
+
+	t.Logf("user-1 cannot impersonate user-2 in %s outside of the subtree", otherTeam)
+	_, err = impersonatingKubeClusterClient.Cluster(otherTeam).CoreV1().ConfigMaps("default").List(ctx, metav1.ListOptions{})
+	require.Error(t, err)
+
+	t.Logf("user-1 cannot impersonate %s in %s", user.SystemPrivilegedGroup, team)
+	grant(t, ctx, kubeClusterClient, org, "user-1", rbacv1.PolicyRule{Verbs: []string{"impersonate"}, APIGroups: []string{""}, Resources: []string{"groups"}})
+	mastersConfig := impersonatingConfig(userConfig("user-1", cfg), "user-2")
+	mastersConfig.Impersonate.Groups = []string{user.SystemPrivilegedGroup}
+	mastersKubeClusterClient, err := kubernetes.NewClusterForConfig(mastersConfig)
+	require.NoError(t, err)
+	_, err = mastersKubeClusterClient.Cluster(team).CoreV1().Namespaces().List(ctx, metav1.ListOptions{})
+	require.Error(t, err)
+}
+
+func impersonatingConfig(cfg *rest.Config, username string) *rest.Config {
+	cfgCopy := rest.CopyConfig(cfg)
+	cfgCopy.Impersonate.UserName = username
+	return cfgCopy
+}
+
+// grant gives the user the permissions of the rule in the given workspace.
+func grant(t *testing.T, ctx context.Context, kubeClusterClient kubernetes.ClusterInterface, clusterName logicalcluster.Name, username string, rule rbacv1.PolicyRule) {
+	name := username + "-" + rule.Verbs[0] + "-" + rule.Resources[0]
+	_, err := kubeClusterClient.Cluster(clusterName).RbacV1().ClusterRoles().Create(ctx, &rbacv1.ClusterRole{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Rules:      []rbacv1.PolicyRule{rule},
+	}, metav1.CreateOptions{})
+	require.NoError(t, err)
+	_, err = kubeClusterClient.Cluster(clusterName).RbacV1().ClusterRoleBindings().Create(ctx, &rbacv1.ClusterRoleBinding{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Subjects:   []rbacv1.Subject{{Kind: "User", APIGroup: "rbac.authorization.k8s.io", Name: username}},
+		RoleRef:    rbacv1.RoleRef{Kind: "ClusterRole", APIGroup: "rbac.authorization.k8s.io", Name: name},
+	}, metav1.CreateOptions{})
+	require.NoError(t, err)
+}
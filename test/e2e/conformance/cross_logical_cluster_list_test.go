@@ -232,6 +232,7 @@ func TestCrossLogicalClusterListPartialObjectMetadata(t *testing.T) {
 		func(obj interface{}) bool { return true },
 		informer.GVREventHandlerFuncs{},
 		time.Second*2,
+		nil,
 	)
 	informerFactory.Start(ctx)
 
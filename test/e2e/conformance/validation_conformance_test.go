@@ -0,0 +1,220 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package conformance
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/kcp-dev/logicalcluster"
+	"github.com/stretchr/testify/require"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apiextensionsclient "k8s.io/apiextensions-apiserver/pkg/client/clientset/clientset"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/utils/pointer"
+
+	configcrds "github.com/kcp-dev/kcp/config/crds"
+	apisv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apis/v1alpha1"
+	kcpclientset "github.com/kcp-dev/kcp/pkg/client/clientset/versioned"
+	"github.com/kcp-dev/kcp/test/e2e/framework"
+)
+
+// TestBoundAPIValidationConformance verifies that an API bound through an APIBinding validates, defaults
+// and prunes objects exactly like a vanilla CRD with the same schema, including CEL validation rules.
+func TestBoundAPIValidationConformance(t *testing.T) {
+	t.Parallel()
+
+	tokenAuthFile := framework.WriteTokenAuthFile(t)
+	server := framework.PrivateKcpServer(t,
+		append(framework.TestServerArgsWithTokenAuthFile(tokenAuthFile),
+			"--feature-gates=CustomResourceValidationExpressions=true",
+		)...,
+	)
+
+	ctx, cancelFunc := context.WithCancel(context.Background())
+	t.Cleanup(cancelFunc)
+
+	org := framework.NewOrganizationFixture(t, server)
+	vanillaWorkspace := framework.NewWorkspaceFixture(t, server, org, "Universal")
+	providerWorkspace := framework.NewWorkspaceFixture(t, server, org, "Universal")
+	consumerWorkspace := framework.NewWorkspaceFixture(t, server, org, "Universal")
+
+	cfg := server.DefaultConfig(t)
+
+	crdClusterClient, err := apiextensionsclient.NewClusterForConfig(cfg)
+	require.NoError(t, err, "failed to construct apiextensions client for server")
+	kcpClusterClient, err := kcpclientset.NewClusterForConfig(cfg)
+	require.NoError(t, err, "failed to construct kcp client for server")
+	dynamicClusterClient, err := dynamic.NewClusterForConfig(cfg)
+	require.NoError(t, err, "failed to construct dynamic client for server")
+
+	group := uuid.New().String() + ".io"
+	gvr := schema.GroupVersionResource{Group: group, Version: "v1", Resource: "scalers"}
+	openAPIV3Schema := &apiextensionsv1.JSONSchemaProps{
+		Type: "object",
+		Properties: map[string]apiextensionsv1.JSONSchemaProps{
+			"spec": {
+				Type: "object",
+				XValidations: apiextensionsv1.ValidationRules{
+					{Rule: "self.minReplicas <= self.maxReplicas", Message: "minReplicas must not exceed maxReplicas"},
+				},
+				Properties: map[string]apiextensionsv1.JSONSchemaProps{
+					"minReplicas": {Type: "integer", Default: &apiextensionsv1.JSON{Raw: []byte("1")}},
+					"maxReplicas": {Type: "integer", Default: &apiextensionsv1.JSON{Raw: []byte("10")}},
+					"name":        {Type: "string", MaxLength: pointer.Int64(10)},
+				},
+			},
+		},
+	}
+	names := apiextensionsv1.CustomResourceDefinitionNames{
+		Plural:   "scalers",
+		Singular: "scaler",
+		Kind:     "Scaler",
+		ListKind: "ScalerList",
+	}
+
+	t.Logf("Install a vanilla scalers CRD into workspace %q", vanillaWorkspace)
+	crd := &apiextensionsv1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: gvr.Resource + "." + group},
+		Spec: apiextensionsv1.CustomResourceDefinitionSpec{
+			Group: group,
+			Names: names,
+			Scope: apiextensionsv1.NamespaceScoped,
+			Versions: []apiextensionsv1.CustomResourceDefinitionVersion{
+				{Name: "v1", Served: true, Storage: true, Schema: &apiextensionsv1.CustomResourceValidation{OpenAPIV3Schema: openAPIV3Schema}},
+			},
+		},
+	}
+	bootstrapCtx, bootstrapCancel := context.WithTimeout(ctx, wait.ForeverTestTimeout)
+	t.Cleanup(bootstrapCancel)
+	err = configcrds.CreateSingle(bootstrapCtx, crdClusterClient.Cluster(vanillaWorkspace).ApiextensionsV1().CustomResourceDefinitions(), crd)
+	require.NoError(t, err, "error bootstrapping CRD %s in workspace %s", crd.Name, vanillaWorkspace)
+
+	t.Logf("Export the same schema from workspace %q", providerWorkspace)
+	rawSchema, err := json.Marshal(openAPIV3Schema)
+	require.NoError(t, err)
+	apiResourceSchema := &apisv1alpha1.APIResourceSchema{
+		ObjectMeta: metav1.ObjectMeta{Name: "today." + gvr.Resource + "." + group},
+		Spec: apisv1alpha1.APIResourceSchemaSpec{
+			Group: group,
+			Names: names,
+			Scope: apiextensionsv1.NamespaceScoped,
+			Versions: []apisv1alpha1.APIResourceVersion{
+				{Name: "v1", Served: true, Storage: true, Schema: runtime.RawExtension{Raw: rawSchema}},
+			},
+		},
+	}
+	_, err = kcpClusterClient.Cluster(providerWorkspace).ApisV1alpha1().APIResourceSchemas().Create(ctx, apiResourceSchema, metav1.CreateOptions{})
+	require.NoError(t, err, "error creating APIResourceSchema")
+	apiExport := &apisv1alpha1.APIExport{
+		ObjectMeta: metav1.ObjectMeta{Name: "scalers"},
+		Spec:       apisv1alpha1.APIExportSpec{LatestResourceSchemas: []string{apiResourceSchema.Name}},
+	}
+	_, err = kcpClusterClient.Cluster(providerWorkspace).ApisV1alpha1().APIExports().Create(ctx, apiExport, metav1.CreateOptions{})
+	require.NoError(t, err, "error creating APIExport")
+
+	t.Logf("Bind it into workspace %q", consumerWorkspace)
+	apiBinding := &apisv1alpha1.APIBinding{
+		ObjectMeta: metav1.ObjectMeta{Name: "scalers"},
+		Spec: apisv1alpha1.APIBindingSpec{
+			Reference: apisv1alpha1.ExportReference{
+				Workspace: &apisv1alpha1.WorkspaceExportReference{
+					WorkspaceName: providerWorkspace.Base(),
+					ExportName:    apiExport.Name,
+				},
+			},
+		},
+	}
+	_, err = kcpClusterClient.Cluster(consumerWorkspace).ApisV1alpha1().APIBindings().Create(ctx, apiBinding, metav1.CreateOptions{})
+	require.NoError(t, err, "error creating APIBinding")
+
+	t.Logf("Wait for the bound API to be served in workspace %q", consumerWorkspace)
+	require.Eventually(t, func() bool {
+		_, err := dynamicClusterClient.Cluster(consumerWorkspace).Resource(gvr).Namespace("default").List(ctx, metav1.ListOptions{})
+		return err == nil
+	}, wait.ForeverTestTimeout, 100*time.Millisecond, "bound API is not served")
+
+	tests := []struct {
+		name      string
+		spec      map[string]interface{}
+		wantSpec  map[string]interface{}
+		wantError string
+	}{
+		{
+			name:     "defaulting",
+			spec:     map[string]interface{}{},
+			wantSpec: map[string]interface{}{"minReplicas": int64(1), "maxReplicas": int64(10)},
+		},
+		{
+			name:     "pruning of unknown fields",
+			spec:     map[string]interface{}{"minReplicas": int64(2), "unknown": "field"},
+			wantSpec: map[string]interface{}{"minReplicas": int64(2), "maxReplicas": int64(10)},
+		},
+		{
+			name:      "CEL validation rule",
+			spec:      map[string]interface{}{"minReplicas": int64(5), "maxReplicas": int64(3)},
+			wantError: "minReplicas must not exceed maxReplicas",
+		},
+		{
+			name:      "OpenAPI validation",
+			spec:      map[string]interface{}{"name": "much-too-long-name"},
+			wantError: "should be at most 10 chars long",
+		},
+	}
+	for i, tc := range tests {
+		name := fmt.Sprintf("scaler-%d", i)
+		create := func(clusterName logicalcluster.Name) (*unstructured.Unstructured, error) {
+			return dynamicClusterClient.Cluster(clusterName).Resource(gvr).Namespace("default").Create(ctx, &unstructured.Unstructured{
+				Object: map[string]interface{}{
+					"apiVersion": gvr.GroupVersion().String(),
+					"kind":       names.Kind,
+					"metadata":   map[string]interface{}{"name": name},
+					"spec":       runtime.DeepCopyJSONValue(tc.spec),
+				},
+			}, metav1.CreateOptions{})
+		}
+
+		t.Logf("%s: create %s in the vanilla and the bound API", tc.name, name)
+		vanilla, vanillaErr := create(vanillaWorkspace)
+		bound, boundErr := create(consumerWorkspace)
+
+		if tc.wantError != "" {
+			require.Error(t, vanillaErr, "%s: expected the vanilla CRD to reject the object", tc.name)
+			require.Contains(t, vanillaErr.Error(), tc.wantError, "%s: unexpected error from the vanilla CRD", tc.name)
+			require.Error(t, boundErr, "%s: expected the bound API to reject the object", tc.name)
+			require.Equal(t, apierrors.ReasonForError(vanillaErr), apierrors.ReasonForError(boundErr), "%s: different error reasons", tc.name)
+			require.Equal(t, vanillaErr.Error(), boundErr.Error(), "%s: different error messages", tc.name)
+			continue
+		}
+
+		require.NoError(t, vanillaErr, "%s: failed to create object through the vanilla CRD", tc.name)
+		require.NoError(t, boundErr, "%s: failed to create object through the bound API", tc.name)
+		require.Equal(t, tc.wantSpec, vanilla.Object["spec"], "%s: unexpected spec from the vanilla CRD", tc.name)
+		require.Equal(t, vanilla.Object["spec"], bound.Object["spec"], "%s: bound API differs from the vanilla CRD", tc.name)
+	}
+}
@@ -36,6 +36,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/util/sets"
@@ -46,6 +47,7 @@ import (
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	"k8s.io/client-go/util/retry"
 	"k8s.io/klog/v2"
 
 	apiresourcev1alpha1 "github.com/kcp-dev/kcp/pkg/apis/apiresource/v1alpha1"
@@ -300,6 +302,22 @@ func NewWorkspaceWithWorkloads(t *testing.T, server RunningServer, orgClusterNam
 	return wsClusterName
 }
 
+// PClusterMode selects the cluster a SyncerFixture syncs to.
+type PClusterMode string
+
+const (
+	// PClusterDefault syncs to the pcluster of --pcluster-kubeconfig with a deployed syncer if
+	// set, and to a fake pcluster otherwise.
+	PClusterDefault PClusterMode = ""
+	// PClusterFake always syncs to a fake pcluster with an in-process syncer. The fake pcluster is a
+	// workspace of the upstream server with CRDs standing in for the APIs of a real cluster, i.e.
+	// objects are stored, but nothing runs them. Tests not depending on kubelet or other controllers
+	// of a real cluster run in seconds and without Docker.
+	PClusterFake PClusterMode = "Fake"
+	// PClusterReal requires the pcluster of --pcluster-kubeconfig, and skips the test otherwise.
+	PClusterReal PClusterMode = "Real"
+)
+
 // SyncerFixture configures a syncer fixture. Its `Start` method does the work of starting a syncer.
 type SyncerFixture struct {
 	ResourcesToSync      sets.String
@@ -307,6 +325,11 @@ type SyncerFixture struct {
 	WorkspaceClusterName logicalcluster.Name
 	WorkloadClusterName  string
 	InstallCRDs          func(config *rest.Config, isLogicalCluster bool)
+	// PCluster selects the cluster to sync to. Defaults to PClusterDefault.
+	PCluster PClusterMode
+	// DriftDetectionInterval enables the drift detection of an in-process syncer. Deployed syncers use
+	// the default interval of the syncer.
+	DriftDetectionInterval time.Duration
 }
 
 // SetDefaults ensures a valid configuration even if not all values are explicitly provided.
@@ -324,18 +347,30 @@ func (sf *SyncerFixture) setDefaults() {
 }
 
 // Start starts a new syncer against the given upstream kcp workspace. Whether the syncer run
-// in-process or deployed on a pcluster will depend whether --pcluster-kubeconfig and
-// --syncer-image are supplied to the test invocation.
+// in-process against a fake pcluster or deployed on a pcluster will depend on the PCluster mode,
+// and by default on whether --pcluster-kubeconfig and --syncer-image are supplied to the test
+// invocation.
 func (sf SyncerFixture) Start(t *testing.T) *StartedSyncerFixture {
 	sf.setDefaults()
 
+	var useDeployedSyncer bool
+	switch sf.PCluster {
+	case PClusterFake:
+		useDeployedSyncer = false
+	case PClusterReal:
+		if len(TestConfig.PClusterKubeconfig()) == 0 {
+			t.Skip("test requires a real pcluster, set --pcluster-kubeconfig and --syncer-image")
+		}
+		useDeployedSyncer = true
+	default:
+		useDeployedSyncer = len(TestConfig.PClusterKubeconfig()) > 0
+	}
+
 	// Write the upstream logical cluster config to disk for the workspace plugin
 	upstreamRawConfig, err := sf.UpstreamServer.RawConfig()
 	require.NoError(t, err)
 	_, kubeconfigPath := WriteLogicalClusterConfig(t, upstreamRawConfig, sf.WorkspaceClusterName)
 
-	useDeployedSyncer := len(TestConfig.PClusterKubeconfig()) > 0
-
 	syncerImage := TestConfig.SyncerImage()
 	if useDeployedSyncer {
 		require.NotZero(t, len(syncerImage), "--syncer-image must be specified if testing with a deployed syncer")
@@ -526,6 +561,7 @@ func (sf SyncerFixture) Start(t *testing.T) *StartedSyncerFixture {
 		})
 	} else {
 		// Start an in-process syncer
+		syncerConfig.DriftDetectionInterval = sf.DriftDetectionInterval
 		err := syncer.StartSyncer(ctx, syncerConfig, 2, 5*time.Second)
 		require.NoError(t, err, "syncer failed to start")
 	}
@@ -534,6 +570,7 @@ func (sf SyncerFixture) Start(t *testing.T) *StartedSyncerFixture {
 		SyncerConfig:         syncerConfig,
 		DownstreamConfig:     downstreamConfig,
 		DownstreamKubeClient: downstreamKubeClient,
+		FakePCluster:         !useDeployedSyncer,
 	}
 
 	// The workload cluster becoming ready indicates the syncer is healthy and has
@@ -552,6 +589,9 @@ type StartedSyncerFixture struct {
 	// SyncerConfig will be less privileged.
 	DownstreamConfig     *rest.Config
 	DownstreamKubeClient kubernetes.Interface
+
+	// FakePCluster is true if the syncer syncs to a fake pcluster, i.e. synced workloads do not run.
+	FakePCluster bool
 }
 
 // WaitForClusterReadyReason waits for the cluster to be ready with the given reason.
@@ -586,6 +626,38 @@ func (sf *StartedSyncerFixture) WaitForClusterReadyReason(t *testing.T, ctx cont
 	}
 }
 
+// ExpectDriftRepaired changes the downstream object of the given resource out-of-band with the given drift
+// function, and waits for the syncer to repair it, i.e. for the repaired function to return true. The
+// drift detection of the syncer must be enabled, see SyncerFixture.DriftDetectionInterval.
+func (sf *StartedSyncerFixture) ExpectDriftRepaired(t *testing.T, ctx context.Context, gvr schema.GroupVersionResource, namespace, name string,
+	drift func(obj *unstructured.Unstructured), repaired func(obj *unstructured.Unstructured) bool) {
+	downstreamDynamicClient, err := dynamic.NewForConfig(sf.DownstreamConfig)
+	require.NoError(t, err)
+	client := downstreamDynamicClient.Resource(gvr).Namespace(namespace)
+
+	t.Logf("Changing downstream %s %s/%s out-of-band", gvr.Resource, namespace, name)
+	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		obj, err := client.Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		drift(obj)
+		_, err = client.Update(ctx, obj, metav1.UpdateOptions{})
+		return err
+	})
+	require.NoError(t, err)
+
+	t.Logf("Waiting for downstream %s %s/%s to be repaired", gvr.Resource, namespace, name)
+	require.Eventually(t, func() bool {
+		obj, err := client.Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			t.Logf("Error getting downstream %s %s/%s: %v", gvr.Resource, namespace, name, err)
+			return false
+		}
+		return repaired(obj)
+	}, wait.ForeverTestTimeout, time.Millisecond*100, "downstream %s %s/%s was not repaired", gvr.Resource, namespace, name)
+}
+
 // WriteLogicalClusterConfig creates a logical cluster config for the given config and
 // cluster name and writes it to the test's artifact path. Useful for configuring the
 // workspace plugin with --kubeconfig.
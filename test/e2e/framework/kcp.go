@@ -226,12 +226,19 @@ func (c *kcpServer) Run(opts ...RunOption) error {
 			cleanupCancel()
 			return err
 		}
+		runDone := make(chan struct{})
 		go func() {
+			defer close(runDone)
 			defer func() { cleanupCancel() }()
 			if err := s.Run(ctx); err != nil && ctx.Err() == nil {
 				c.t.Errorf("`kcp` failed: %v", err)
 			}
 		}()
+		// the embedded etcd is only stopped when Run returns; wait for it before the data directory is removed.
+		c.t.Cleanup(func() {
+			cleanupCancel()
+			<-runDone
+		})
 
 		return nil
 	}
@@ -506,7 +513,8 @@ func newPersistentKCPServer(name, kubeconfigPath string) (RunningServer, error)
 }
 
 // NewFakeWorkloadServer creates a workspace in the provided server and org
-// and creates a server fixture for the logical cluster that results.
+// and creates a server fixture for the logical cluster that results. It serves
+// as fake pcluster, with CRDs standing in for the APIs of a real cluster.
 func NewFakeWorkloadServer(t *testing.T, server RunningServer, org logicalcluster.Name) RunningServer {
 	logicalClusterName := NewWorkspaceWithWorkloads(t, server, org, "Universal", false)
 	rawConfig, err := server.RawConfig()
@@ -0,0 +1,375 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package framework
+
+import (
+	"context"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kcp-dev/logicalcluster"
+	"github.com/stretchr/testify/require"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	kubernetesclientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/clientcmd"
+	certutil "k8s.io/client-go/util/cert"
+	"sigs.k8s.io/yaml"
+
+	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+	kcpclientset "github.com/kcp-dev/kcp/pkg/client/clientset/versioned"
+	tenancyv1alpha1client "github.com/kcp-dev/kcp/pkg/client/clientset/versioned/typed/tenancy/v1alpha1"
+	"github.com/kcp-dev/kcp/pkg/proxy"
+	proxyoptions "github.com/kcp-dev/kcp/pkg/proxy/options"
+)
+
+// RootShardName is the name of the ClusterWorkspaceShard of the shard holding the root workspace.
+const RootShardName = "root"
+
+// ShardedKcpConfig qualifies a multi-shard kcp topology to start.
+type ShardedKcpConfig struct {
+	// Shards is the number of shards to start in addition to the root shard.
+	Shards int
+	// FrontProxy starts a kcp-front-proxy in front of the root shard.
+	FrontProxy bool
+	// Args are passed to every shard.
+	Args []string
+}
+
+// ShardedKcpServers is a set of kcp shards registered as ClusterWorkspaceShards
+// in the root workspace of the root shard.
+type ShardedKcpServers struct {
+	// Root is the shard holding the root workspace.
+	Root RunningServer
+	// Shards maps ClusterWorkspaceShard names to their servers, including the root shard.
+	Shards map[string]RunningServer
+	// FrontProxy is the kcp-front-proxy routing to the root shard, or nil if not requested.
+	FrontProxy RunningServer
+}
+
+// PrivateShardedKcpServers starts a root shard and cfg.Shards additional shards,
+// registers the additional shards with the root shard such that workspaces get
+// scheduled to them, and optionally starts a front proxy. The shards run in
+// process if INPROCESS is set, and so does the front proxy.
+func PrivateShardedKcpServers(t *testing.T, cfg ShardedKcpConfig) *ShardedKcpServers {
+	tokenAuthFile := WriteTokenAuthFile(t)
+	args := append(TestServerArgsWithTokenAuthFile(tokenAuthFile), cfg.Args...)
+
+	cfgs := []kcpConfig{{Name: RootShardName, Args: args}}
+	for i := 1; i <= cfg.Shards; i++ {
+		cfgs = append(cfgs, kcpConfig{Name: fmt.Sprintf("shard-%d", i), Args: args})
+	}
+	f := newKcpFixture(t, cfgs...)
+
+	servers := &ShardedKcpServers{
+		Root:   f.Servers[RootShardName],
+		Shards: f.Servers,
+	}
+
+	ctx, cancelFunc := context.WithCancel(context.Background())
+	t.Cleanup(cancelFunc)
+
+	kcpClusterClient, err := kcpclientset.NewClusterForConfig(servers.Root.DefaultConfig(t))
+	require.NoError(t, err)
+	rootKcpClient := kcpClusterClient.Cluster(tenancyv1alpha1.RootCluster)
+
+	for name, server := range servers.Shards {
+		if name == RootShardName {
+			continue
+		}
+		registerShard(ctx, t, rootKcpClient, name, server)
+	}
+
+	// the root shard registers itself asynchronously during bootstrapping
+	require.Eventually(t, func() bool {
+		shards, err := rootKcpClient.TenancyV1alpha1().ClusterWorkspaceShards().List(ctx, metav1.ListOptions{})
+		if err != nil {
+			t.Logf("error listing shards: %v", err)
+			return false
+		}
+		return len(shards.Items) == len(servers.Shards)
+	}, wait.ForeverTestTimeout, 100*time.Millisecond, "did not see all shards registered")
+
+	if cfg.FrontProxy {
+		servers.FrontProxy = newFrontProxy(t, servers.Root)
+	}
+
+	return servers
+}
+
+// registerShard creates a ClusterWorkspaceShard with the given name for the given server in the root workspace.
+func registerShard(ctx context.Context, t *testing.T, rootKcpClient kcpclientset.Interface, name string, server RunningServer) {
+	host := server.DefaultConfig(t).Host
+	t.Logf("Registering shard %s at %s", name, host)
+	_, err := rootKcpClient.TenancyV1alpha1().ClusterWorkspaceShards().Create(ctx, &tenancyv1alpha1.ClusterWorkspaceShard{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec: tenancyv1alpha1.ClusterWorkspaceShardSpec{
+			BaseURL:     host,
+			ExternalURL: host,
+		},
+	}, metav1.CreateOptions{})
+	require.NoError(t, err, "failed to register shard %s", name)
+}
+
+// ShardFixture is a ClusterWorkspaceShard registered in the root workspace, with typed clients talking
+// directly to the shard, i.e. bypassing any front proxy, and an informer-backed expecter for the events
+// of the ClusterWorkspaceShard object.
+type ShardFixture struct {
+	// Name is the name of the ClusterWorkspaceShard.
+	Name string
+	// Server is the shard.
+	Server RunningServer
+
+	// KcpClusterClient and KubeClusterClient talk directly to the shard.
+	KcpClusterClient  kcpclientset.ClusterInterface
+	KubeClusterClient kubernetesclientset.ClusterInterface
+
+	// ShardClient manages the ClusterWorkspaceShards in the root workspace of the root shard.
+	ShardClient tenancyv1alpha1client.ClusterWorkspaceShardInterface
+	// Expect registers expectations about the ClusterWorkspaceShards in the root workspace of the root shard.
+	Expect RegisterWorkspaceShardExpectation
+}
+
+// NewShardFixture starts a new kcp shard with the given name and args, registers it as ClusterWorkspaceShard
+// in the root workspace of the given root shard and waits for the registration to be visible. The
+// ClusterWorkspaceShard is deleted when the test ends, unless PRESERVE is set. The root shard is expected to
+// serve the same token auth file as PrivateShardedKcpServers.
+func NewShardFixture(t *testing.T, root RunningServer, name string, args ...string) *ShardFixture {
+	tokenAuthFile := WriteTokenAuthFile(t)
+	f := newKcpFixture(t, kcpConfig{Name: name, Args: append(TestServerArgsWithTokenAuthFile(tokenAuthFile), args...)})
+	server := f.Servers[name]
+
+	ctx, cancelFunc := context.WithCancel(context.Background())
+	t.Cleanup(cancelFunc)
+
+	kcpClusterClient, err := kcpclientset.NewClusterForConfig(root.DefaultConfig(t))
+	require.NoError(t, err)
+	rootKcpClient := kcpClusterClient.Cluster(tenancyv1alpha1.RootCluster)
+	registerShard(ctx, t, rootKcpClient, name, server)
+	t.Cleanup(func() {
+		if preserveTestResources() {
+			return
+		}
+		ctx, cancelFn := context.WithDeadline(context.Background(), time.Now().Add(wait.ForeverTestTimeout))
+		defer cancelFn()
+		if err := rootKcpClient.TenancyV1alpha1().ClusterWorkspaceShards().Delete(ctx, name, metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
+			t.Logf("failed to delete shard %s: %v", name, err)
+		}
+	})
+
+	fixture := newShardFixture(ctx, t, rootKcpClient, name, server)
+	require.NoError(t, fixture.Expect(&tenancyv1alpha1.ClusterWorkspaceShard{ObjectMeta: metav1.ObjectMeta{Name: name, ClusterName: tenancyv1alpha1.RootCluster.String()}}, func(shard *tenancyv1alpha1.ClusterWorkspaceShard) error {
+		if shard.Spec.BaseURL != server.DefaultConfig(t).Host {
+			return fmt.Errorf("expected shard %s to have baseURL %q, got %q", name, server.DefaultConfig(t).Host, shard.Spec.BaseURL)
+		}
+		return nil
+	}), "did not see shard %s registered", name)
+	return fixture
+}
+
+// ShardFixture returns the fixture of the already registered shard with the given name.
+func (s *ShardedKcpServers) ShardFixture(t *testing.T, name string) *ShardFixture {
+	server, ok := s.Shards[name]
+	require.True(t, ok, "unknown shard %q", name)
+
+	ctx, cancelFunc := context.WithCancel(context.Background())
+	t.Cleanup(cancelFunc)
+
+	kcpClusterClient, err := kcpclientset.NewClusterForConfig(s.Root.DefaultConfig(t))
+	require.NoError(t, err)
+	return newShardFixture(ctx, t, kcpClusterClient.Cluster(tenancyv1alpha1.RootCluster), name, server)
+}
+
+func newShardFixture(ctx context.Context, t *testing.T, rootKcpClient kcpclientset.Interface, name string, server RunningServer) *ShardFixture {
+	cfg := server.DefaultConfig(t)
+	kcpClusterClient, err := kcpclientset.NewClusterForConfig(cfg)
+	require.NoError(t, err)
+	kubeClusterClient, err := kubernetesclientset.NewClusterForConfig(cfg)
+	require.NoError(t, err)
+
+	expect, err := ExpectWorkspaceShards(ctx, t, rootKcpClient)
+	require.NoError(t, err, "failed to start expecter for shards")
+
+	return &ShardFixture{
+		Name:              name,
+		Server:            server,
+		KcpClusterClient:  kcpClusterClient,
+		KubeClusterClient: kubeClusterClient,
+		ShardClient:       rootKcpClient.TenancyV1alpha1().ClusterWorkspaceShards(),
+		Expect:            expect,
+	}
+}
+
+// Eventually waits for the given assertion to hold for the given logical cluster, as seen directly on the
+// shard. The assertion returns whether it holds, and a message describing why not.
+func (f *ShardFixture) Eventually(t *testing.T, clusterName logicalcluster.Name, assertion func(kcpClient kcpclientset.Interface, kubeClient kubernetesclientset.Interface) (bool, string), msgAndArgs ...interface{}) {
+	t.Helper()
+	kcpClient := f.KcpClusterClient.Cluster(clusterName)
+	kubeClient := f.KubeClusterClient.Cluster(clusterName)
+	require.Eventually(t, func() bool {
+		ok, msg := assertion(kcpClient, kubeClient)
+		if !ok && msg != "" {
+			t.Logf("shard %s, cluster %s: %s", f.Name, clusterName, msg)
+		}
+		return ok
+	}, wait.ForeverTestTimeout, 100*time.Millisecond, msgAndArgs...)
+}
+
+// WorkspaceShard returns the server of the shard the given workspace has been scheduled to.
+func (s *ShardedKcpServers) WorkspaceShard(ctx context.Context, t *testing.T, client kcpclientset.Interface, workspaceName string) RunningServer {
+	shardName := WaitForWorkspaceShard(ctx, t, client, workspaceName)
+	server, ok := s.Shards[shardName]
+	require.True(t, ok, "workspace %s scheduled to unknown shard %q", workspaceName, shardName)
+	return server
+}
+
+// WaitForWorkspaceShard waits for the ClusterWorkspace with the given name to be scheduled
+// and returns the name of its ClusterWorkspaceShard.
+func WaitForWorkspaceShard(ctx context.Context, t *testing.T, client kcpclientset.Interface, workspaceName string) string {
+	var shardName string
+	require.Eventually(t, func() bool {
+		workspace, err := client.TenancyV1alpha1().ClusterWorkspaces().Get(ctx, workspaceName, metav1.GetOptions{})
+		if err != nil {
+			t.Logf("error getting workspace %s: %v", workspaceName, err)
+			return false
+		}
+		shardName = workspace.Status.Location.Current
+		return shardName != ""
+	}, wait.ForeverTestTimeout, 100*time.Millisecond, "workspace %s was not scheduled", workspaceName)
+	return shardName
+}
+
+// RequireWorkspaceOnShard waits for the ClusterWorkspace with the given name to be
+// scheduled and requires it to be placed on one of the given shards.
+func RequireWorkspaceOnShard(ctx context.Context, t *testing.T, client kcpclientset.Interface, workspaceName string, shardNames ...string) {
+	shardName := WaitForWorkspaceShard(ctx, t, client, workspaceName)
+	require.Contains(t, shardNames, shardName, "workspace %s scheduled to unexpected shard", workspaceName)
+}
+
+// newFrontProxy starts a kcp-front-proxy routing all requests to the given shard and
+// returns a server fixture with a kubeconfig pointing at the proxy. Bearer tokens are
+// passed through to the shard, hence the shard's admin credentials keep working.
+func newFrontProxy(t *testing.T, shard RunningServer) RunningServer {
+	artifactDir, dataDir, err := ScratchDirs(t)
+	require.NoError(t, err)
+	dataDir = filepath.Join(dataDir, "kcp-front-proxy")
+	require.NoError(t, os.MkdirAll(dataDir, 0755))
+
+	shardConfig := shard.DefaultConfig(t)
+	shardCAFile := filepath.Join(dataDir, "shard-ca.crt")
+	require.NoError(t, ioutil.WriteFile(shardCAFile, shardConfig.CAData, 0600))
+
+	// the shard does not trust this certificate, i.e. requests are authenticated by their bearer token.
+	clientCert, clientKey, err := certutil.GenerateSelfSignedCertKey("kcp-front-proxy", nil, nil)
+	require.NoError(t, err)
+	clientCertFile := filepath.Join(dataDir, "proxy-client.crt")
+	clientKeyFile := filepath.Join(dataDir, "proxy-client.key")
+	require.NoError(t, ioutil.WriteFile(clientCertFile, clientCert, 0600))
+	require.NoError(t, ioutil.WriteFile(clientKeyFile, clientKey, 0600))
+
+	mapping, err := yaml.Marshal([]proxy.PathMapping{{
+		Path:            "/",
+		Backend:         shardConfig.Host,
+		BackendServerCA: shardCAFile,
+		ProxyClientCert: clientCertFile,
+		ProxyClientKey:  clientKeyFile,
+	}})
+	require.NoError(t, err)
+	mappingFile := filepath.Join(dataDir, "mapping.yaml")
+	require.NoError(t, ioutil.WriteFile(mappingFile, mapping, 0600))
+
+	var proxyURL string
+	var proxyCAData []byte
+	if InProcessEnvSet() {
+		proxyOptions := proxyoptions.NewOptions()
+		proxyOptions.MappingFile = mappingFile
+		handler, err := proxy.NewHandler(proxyOptions)
+		require.NoError(t, err)
+
+		server := httptest.NewTLSServer(handler)
+		t.Cleanup(server.Close)
+
+		proxyURL = server.URL
+		proxyCAData = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	} else {
+		port, err := GetFreePort(t)
+		require.NoError(t, err)
+
+		cmd := append(DirectOrGoRunCommand("kcp-front-proxy"),
+			"--mapping-file="+mappingFile,
+			"--root-directory="+dataDir,
+			"--secure-port="+port,
+		)
+		var opts []RunOption
+		if LogToConsoleEnvSet() {
+			opts = append(opts, WithLogStreaming)
+		}
+		require.NoError(t, NewAccessory(t, artifactDir, "kcp-front-proxy", cmd...).Run(t, opts...))
+
+		// the proxy generates a self-signed serving certificate for localhost on startup
+		servingCertFile := filepath.Join(dataDir, "apiserver.crt")
+		require.Eventually(t, func() bool {
+			proxyCAData, err = ioutil.ReadFile(servingCertFile)
+			return err == nil && len(proxyCAData) > 0
+		}, 2*time.Minute, 100*time.Millisecond, "kcp-front-proxy did not write %s", servingCertFile) // go run may need to compile first
+
+		proxyURL = "https://localhost:" + port
+	}
+
+	rawConfig, err := shard.RawConfig()
+	require.NoError(t, err)
+	for name, cluster := range rawConfig.Clusters {
+		u, err := url.Parse(cluster.Server)
+		require.NoError(t, err)
+		cluster := cluster.DeepCopy()
+		cluster.Server = proxyURL + u.Path
+		cluster.CertificateAuthorityData = proxyCAData
+		cluster.TLSServerName = ""
+		rawConfig.Clusters[name] = cluster
+	}
+	kubeconfigPath := filepath.Join(dataDir, "admin.kubeconfig")
+	require.NoError(t, clientcmd.WriteToFile(rawConfig, kubeconfigPath))
+
+	cfg, err := loadKubeConfig(kubeconfigPath)
+	require.NoError(t, err)
+	frontProxy := &unmanagedKCPServer{
+		name:           "kcp-front-proxy",
+		kubeconfigPath: kubeconfigPath,
+		cfg:            cfg,
+	}
+
+	kubeClient, err := kubernetesclientset.NewForConfig(frontProxy.DefaultConfig(t))
+	require.NoError(t, err)
+	require.Eventually(t, func() bool {
+		if _, err := kubeClient.Discovery().ServerVersion(); err != nil {
+			t.Logf("error contacting kcp-front-proxy: %v", err)
+			return false
+		}
+		return true
+	}, wait.ForeverTestTimeout, 100*time.Millisecond, "kcp-front-proxy never became ready")
+
+	return frontProxy
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package quota
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	kubernetesclientset "k8s.io/client-go/kubernetes"
+
+	"github.com/kcp-dev/kcp/test/e2e/framework"
+)
+
+func TestObjectCountQuota(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancelFunc := context.WithCancel(context.Background())
+	t.Cleanup(cancelFunc)
+
+	server := framework.SharedKcpServer(t)
+	orgClusterName := framework.NewOrganizationFixture(t, server)
+	limitedClusterName := framework.NewWorkspaceFixture(t, server, orgClusterName, "Universal")
+	otherClusterName := framework.NewWorkspaceFixture(t, server, orgClusterName, "Universal")
+
+	kubeClusterClient, err := kubernetesclientset.NewClusterForConfig(server.DefaultConfig(t))
+	require.NoError(t, err)
+	limitedClient := kubeClusterClient.Cluster(limitedClusterName)
+	otherClient := kubeClusterClient.Cluster(otherClusterName)
+
+	t.Logf("Create namespace quota in both workspaces")
+	for _, client := range []kubernetesclientset.Interface{limitedClient, otherClient} {
+		_, err = client.CoreV1().Namespaces().Create(ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "quota"}}, metav1.CreateOptions{})
+		require.NoError(t, err, "failed to create namespace")
+	}
+
+	t.Logf("Limit configmaps to 3 in workspace %s", limitedClusterName)
+	_, err = limitedClient.CoreV1().ResourceQuotas("quota").Create(ctx, &corev1.ResourceQuota{
+		ObjectMeta: metav1.ObjectMeta{Name: "configmaps"},
+		Spec: corev1.ResourceQuotaSpec{
+			Hard: corev1.ResourceList{"count/configmaps": resource.MustParse("3")},
+		},
+	}, metav1.CreateOptions{})
+	require.NoError(t, err, "failed to create quota")
+
+	t.Logf("Wait for the usage to be calculated")
+	require.Eventually(t, func() bool {
+		quota, err := limitedClient.CoreV1().ResourceQuotas("quota").Get(ctx, "configmaps", metav1.GetOptions{})
+		require.NoError(t, err)
+		_, found := quota.Status.Used["count/configmaps"]
+		return found
+	}, wait.ForeverTestTimeout, time.Millisecond*100, "usage of quota never calculated")
+
+	t.Logf("Create configmaps until the quota is exceeded")
+	require.Eventually(t, func() bool {
+		_, err := limitedClient.CoreV1().ConfigMaps("quota").Create(ctx, &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{GenerateName: "e2e-"},
+		}, metav1.CreateOptions{})
+		if apierrors.IsForbidden(err) {
+			require.Contains(t, err.Error(), "exceeded quota: configmaps")
+			return true
+		}
+		require.NoError(t, err, "failed to create configmap")
+		return false
+	}, wait.ForeverTestTimeout, time.Millisecond*100, "quota was never exceeded")
+
+	configMaps, err := limitedClient.CoreV1().ConfigMaps("quota").List(ctx, metav1.ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, configMaps.Items, 3, "unexpected number of configmaps")
+
+	t.Logf("Create configmaps beyond the limit in workspace %s", otherClusterName)
+	for i := 0; i < 4; i++ {
+		_, err = otherClient.CoreV1().ConfigMaps("quota").Create(ctx, &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{GenerateName: "e2e-"},
+		}, metav1.CreateOptions{})
+		require.NoError(t, err, "quota of another workspace must not apply")
+	}
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterworkspace
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+	kcpclientset "github.com/kcp-dev/kcp/pkg/client/clientset/versioned"
+	"github.com/kcp-dev/kcp/test/e2e/framework"
+)
+
+func TestWorkspacePlacementAcrossShards(t *testing.T) {
+	t.Parallel()
+
+	servers := framework.PrivateShardedKcpServers(t, framework.ShardedKcpConfig{Shards: 1, FrontProxy: true})
+
+	ctx, cancelFunc := context.WithCancel(context.Background())
+	t.Cleanup(cancelFunc)
+
+	kcpClusterClient, err := kcpclientset.NewClusterForConfig(servers.FrontProxy.DefaultConfig(t))
+	require.NoError(t, err)
+	rootKcpClient := kcpClusterClient.Cluster(tenancyv1alpha1.RootCluster)
+
+	for i := 0; i < 3; i++ {
+		workspace, err := rootKcpClient.TenancyV1alpha1().ClusterWorkspaces().Create(ctx, &tenancyv1alpha1.ClusterWorkspace{
+			ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("placed-%d", i)},
+			Spec:       tenancyv1alpha1.ClusterWorkspaceSpec{Type: "Universal"},
+		}, metav1.CreateOptions{})
+		require.NoError(t, err, "failed to create workspace through the front proxy")
+
+		framework.RequireWorkspaceOnShard(ctx, t, rootKcpClient, workspace.Name, framework.RootShardName, "shard-1")
+
+		t.Logf("Expect workspace %s to point to the external URL of its shard", workspace.Name)
+		shard := servers.WorkspaceShard(ctx, t, rootKcpClient, workspace.Name)
+		workspaceShard, err := rootKcpClient.TenancyV1alpha1().ClusterWorkspaceShards().Get(ctx, shard.Name(), metav1.GetOptions{})
+		require.NoError(t, err)
+		workspace, err = rootKcpClient.TenancyV1alpha1().ClusterWorkspaces().Get(ctx, workspace.Name, metav1.GetOptions{})
+		require.NoError(t, err)
+		require.Equal(t, workspaceShard.Spec.ExternalURL+"/clusters/root:"+workspace.Name, workspace.Status.BaseURL)
+
+		t.Logf("Expect shard %s to be registered with its direct address", shard.Name())
+		shardFixture := servers.ShardFixture(t, shard.Name())
+		err = shardFixture.Expect(workspaceShard, func(current *tenancyv1alpha1.ClusterWorkspaceShard) error {
+			if u, err := url.Parse(current.Spec.BaseURL); err != nil {
+				return err
+			} else if host := shardFixture.Server.DefaultConfig(t).Host; u.Scheme+"://"+u.Host != host {
+				return fmt.Errorf("expected shard %s to have baseURL %q, got %q", current.Name, host, current.Spec.BaseURL)
+			}
+			return nil
+		})
+		require.NoError(t, err)
+	}
+}
@@ -67,6 +67,8 @@ func TestScheduling(t *testing.T) {
 		ResourcesToSync:      sets.NewString("services"),
 		UpstreamServer:       source,
 		WorkspaceClusterName: negotiationClusterName,
+		// only the API import and a ready workload cluster are needed
+		PCluster: framework.PClusterFake,
 		InstallCRDs: func(config *rest.Config, isLogicalCluster bool) {
 			if !isLogicalCluster {
 				// Only need to install services and ingresses in a logical cluster
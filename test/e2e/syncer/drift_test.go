@@ -0,0 +1,96 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package syncer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kcp-dev/logicalcluster"
+	"github.com/stretchr/testify/require"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/util/wait"
+	kubernetesclientset "k8s.io/client-go/kubernetes"
+
+	"github.com/kcp-dev/kcp/pkg/syncer/shared"
+	"github.com/kcp-dev/kcp/test/e2e/framework"
+)
+
+func TestSyncerDriftRepair(t *testing.T) {
+	t.Parallel()
+
+	upstreamServer := framework.SharedKcpServer(t)
+
+	t.Log("Creating an organization")
+	orgClusterName := framework.NewOrganizationFixture(t, upstreamServer)
+
+	t.Log("Creating a workspace")
+	wsClusterName := framework.NewWorkspaceFixture(t, upstreamServer, orgClusterName, "Universal")
+
+	syncerFixture := framework.SyncerFixture{
+		UpstreamServer:         upstreamServer,
+		WorkspaceClusterName:   wsClusterName,
+		PCluster:               framework.PClusterFake,
+		DriftDetectionInterval: time.Second,
+	}.Start(t)
+
+	ctx, cancelFunc := context.WithCancel(context.Background())
+	t.Cleanup(cancelFunc)
+
+	upstreamKubeClusterClient, err := kubernetesclientset.NewClusterForConfig(upstreamServer.DefaultConfig(t))
+	require.NoError(t, err)
+	upstreamKubeClient := upstreamKubeClusterClient.Cluster(wsClusterName)
+
+	t.Log("Creating upstream namespace and configmap")
+	upstreamNamespace, err := upstreamKubeClient.CoreV1().Namespaces().Create(ctx, &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-drift"},
+	}, metav1.CreateOptions{})
+	require.NoError(t, err)
+	_, err = upstreamKubeClient.CoreV1().ConfigMaps(upstreamNamespace.Name).Create(ctx, &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "drifting"},
+		Data:       map[string]string{"color": "blue"},
+	}, metav1.CreateOptions{})
+	require.NoError(t, err)
+
+	nsLocator := shared.NamespaceLocator{LogicalCluster: logicalcluster.From(upstreamNamespace), Namespace: upstreamNamespace.Name}
+	downstreamNamespaceName, err := shared.PhysicalClusterNamespaceName(nsLocator)
+	require.NoError(t, err)
+
+	t.Logf("Waiting for downstream configmap %s/drifting to be created", downstreamNamespaceName)
+	require.Eventually(t, func() bool {
+		_, err := syncerFixture.DownstreamKubeClient.CoreV1().ConfigMaps(downstreamNamespaceName).Get(ctx, "drifting", metav1.GetOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			t.Errorf("saw an error waiting for downstream configmap %s/drifting to be created: %v", downstreamNamespaceName, err)
+		}
+		return err == nil
+	}, wait.ForeverTestTimeout, time.Millisecond*100, "downstream configmap %s/drifting was not synced", downstreamNamespaceName)
+
+	syncerFixture.ExpectDriftRepaired(t, ctx, corev1.SchemeGroupVersion.WithResource("configmaps"), downstreamNamespaceName, "drifting",
+		func(obj *unstructured.Unstructured) {
+			require.NoError(t, unstructured.SetNestedField(obj.Object, "red", "data", "color"))
+		},
+		func(obj *unstructured.Unstructured) bool {
+			color, _, _ := unstructured.NestedString(obj.Object, "data", "color")
+			return color == "blue"
+		},
+	)
+}
@@ -0,0 +1,160 @@
+/*
+Copyright 2022 The KCP Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package initializingworkspaces
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kcp-dev/logicalcluster"
+	"github.com/stretchr/testify/require"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/util/retry"
+
+	tenancyv1alpha1 "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1"
+	tenancyhelper "github.com/kcp-dev/kcp/pkg/apis/tenancy/v1alpha1/helper"
+	kcpclientset "github.com/kcp-dev/kcp/pkg/client/clientset/versioned"
+	"github.com/kcp-dev/kcp/pkg/virtual/initializingworkspaces/builder"
+	"github.com/kcp-dev/kcp/test/e2e/framework"
+)
+
+func TestInitializingWorkspacesVirtualWorkspace(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancelFunc := context.WithCancel(context.Background())
+	t.Cleanup(cancelFunc)
+
+	source := framework.SharedKcpServer(t)
+	orgClusterName := framework.NewOrganizationFixture(t, source)
+
+	sourceConfig := source.DefaultConfig(t)
+	kcpClusterClient, err := kcpclientset.NewClusterForConfig(sourceConfig)
+	require.NoError(t, err)
+
+	initializer := tenancyv1alpha1.ClusterWorkspaceInitializer("e2e.kcp.dev/" + orgClusterName.Base())
+
+	t.Logf("Create type Foo with the initializer %s", initializer)
+	_, err = kcpClusterClient.Cluster(orgClusterName).TenancyV1alpha1().ClusterWorkspaceTypes().Create(ctx, &tenancyv1alpha1.ClusterWorkspaceType{
+		ObjectMeta: metav1.ObjectMeta{Name: "foo"},
+		Spec: tenancyv1alpha1.ClusterWorkspaceTypeSpec{
+			Initializers: []tenancyv1alpha1.ClusterWorkspaceInitializer{initializer},
+		},
+	}, metav1.CreateOptions{})
+	require.NoError(t, err, "failed to create workspace type")
+
+	t.Logf("Create a workspace of type Foo")
+	var workspace *tenancyv1alpha1.ClusterWorkspace
+	require.Eventually(t, func() bool {
+		// note: admission is informer based and hence would race with this create call
+		workspace, err = kcpClusterClient.Cluster(orgClusterName).TenancyV1alpha1().ClusterWorkspaces().Create(ctx, &tenancyv1alpha1.ClusterWorkspace{
+			ObjectMeta: metav1.ObjectMeta{GenerateName: "e2e-workspace-"},
+			Spec:       tenancyv1alpha1.ClusterWorkspaceSpec{Type: "Foo"},
+		}, metav1.CreateOptions{})
+		return err == nil
+	}, wait.ForeverTestTimeout, time.Millisecond*100, "failed to create workspace of type Foo")
+
+	t.Logf("Create a Universal workspace without the initializer")
+	universalClusterName := framework.NewWorkspaceFixture(t, source, orgClusterName, "Universal")
+
+	t.Logf("Wait for the initializer label on workspace %s", workspace.Name)
+	key, value := tenancyhelper.InitializerToLabel(initializer)
+	require.Eventually(t, func() bool {
+		ws, err := kcpClusterClient.Cluster(orgClusterName).TenancyV1alpha1().ClusterWorkspaces().Get(ctx, workspace.Name, metav1.GetOptions{})
+		require.NoError(t, err)
+		return ws.Labels[key] == value
+	}, wait.ForeverTestTimeout, time.Millisecond*100, "workspace %s did not get the initializer label", workspace.Name)
+
+	rawConfig, err := source.RawConfig()
+	require.NoError(t, err)
+	adminCluster := rawConfig.Clusters["system:admin"]
+	virtualWorkspaceRawConfig := rawConfig.DeepCopy()
+	virtualWorkspaceRawConfig.Clusters["system:admin"].Server = adminCluster.Server + "/services/initializingworkspaces/" + builder.InitializerPathSegment(initializer)
+	virtualWorkspaceConfig, err := clientcmd.NewNonInteractiveClientConfig(*virtualWorkspaceRawConfig, "system:admin", nil, nil).ClientConfig()
+	require.NoError(t, err)
+	virtualWorkspaceClusterClient, err := kcpclientset.NewClusterForConfig(virtualWorkspaceConfig)
+	require.NoError(t, err)
+
+	t.Logf("Expect only workspace %s to be served for the initializer", workspace.Name)
+	require.Eventually(t, func() bool {
+		list, err := virtualWorkspaceClusterClient.Cluster(logicalcluster.Wildcard).TenancyV1alpha1().ClusterWorkspaces().List(ctx, metav1.ListOptions{})
+		if err != nil {
+			t.Logf("failed to list workspaces through the virtual workspace: %v", err)
+			return false
+		}
+		if len(list.Items) != 1 {
+			return false
+		}
+		return list.Items[0].Name == workspace.Name
+	}, wait.ForeverTestTimeout, time.Millisecond*100, "expected only workspace %s to be listed", workspace.Name)
+
+	_, err = virtualWorkspaceClusterClient.Cluster(orgClusterName).TenancyV1alpha1().ClusterWorkspaces().Get(ctx, universalClusterName.Base(), metav1.GetOptions{})
+	require.True(t, apierrors.IsNotFound(err), "expected the Universal workspace not to be found, got: %v", err)
+
+	t.Logf("Expect a user without the %s permission to be forbidden", builder.InitializeVerb)
+	userClusterClient, err := kcpclientset.NewClusterForConfig(userConfig("user-1", virtualWorkspaceConfig))
+	require.NoError(t, err)
+	_, err = userClusterClient.Cluster(logicalcluster.Wildcard).TenancyV1alpha1().ClusterWorkspaces().List(ctx, metav1.ListOptions{})
+	require.True(t, apierrors.IsForbidden(err), "expected forbidden, got: %v", err)
+
+	t.Logf("Expect deletion to be forbidden through the virtual workspace")
+	err = virtualWorkspaceClusterClient.Cluster(orgClusterName).TenancyV1alpha1().ClusterWorkspaces().Delete(ctx, workspace.Name, metav1.DeleteOptions{})
+	require.True(t, apierrors.IsForbidden(err), "expected forbidden, got: %v", err)
+
+	t.Logf("Remove the initializer through the virtual workspace")
+	err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		ws, err := virtualWorkspaceClusterClient.Cluster(orgClusterName).TenancyV1alpha1().ClusterWorkspaces().Get(ctx, workspace.Name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		for i, current := range ws.Status.Initializers {
+			if current == initializer {
+				ws.Status.Initializers = append(ws.Status.Initializers[:i], ws.Status.Initializers[i+1:]...)
+				break
+			}
+		}
+		_, err = virtualWorkspaceClusterClient.Cluster(orgClusterName).TenancyV1alpha1().ClusterWorkspaces().UpdateStatus(ctx, ws, metav1.UpdateOptions{})
+		return err
+	})
+	require.NoError(t, err)
+
+	t.Logf("Expect workspace %s to become ready and to disappear from the virtual workspace", workspace.Name)
+	require.Eventually(t, func() bool {
+		ws, err := kcpClusterClient.Cluster(orgClusterName).TenancyV1alpha1().ClusterWorkspaces().Get(ctx, workspace.Name, metav1.GetOptions{})
+		require.NoError(t, err)
+		return ws.Status.Phase == tenancyv1alpha1.ClusterWorkspacePhaseReady
+	}, wait.ForeverTestTimeout, time.Millisecond*100, "workspace %s did not become ready", workspace.Name)
+	require.Eventually(t, func() bool {
+		list, err := virtualWorkspaceClusterClient.Cluster(logicalcluster.Wildcard).TenancyV1alpha1().ClusterWorkspaces().List(ctx, metav1.ListOptions{})
+		if err != nil {
+			t.Logf("failed to list workspaces through the virtual workspace: %v", err)
+			return false
+		}
+		return len(list.Items) == 0
+	}, wait.ForeverTestTimeout, time.Millisecond*100, "expected workspace %s to disappear from the virtual workspace", workspace.Name)
+}
+
+func userConfig(username string, cfg *rest.Config) *rest.Config {
+	cfgCopy := rest.CopyConfig(cfg)
+	cfgCopy.BearerToken = username + "-token"
+	return cfgCopy
+}
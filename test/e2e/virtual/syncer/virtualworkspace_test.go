@@ -92,6 +92,14 @@ func requiredCoreAPIResourceList(workspaceName string) *metav1.APIResourceList {
 				Verbs:              metav1.Verbs{"get", "list", "patch", "create", "update", "watch"},
 				StorageVersionHash: discovery.StorageVersionHash(workspaceName, "", "v1", "ConfigMap"),
 			},
+			{
+				Kind:               "LimitRange",
+				Name:               "limitranges",
+				SingularName:       "limitrange",
+				Namespaced:         true,
+				Verbs:              metav1.Verbs{"get", "list", "patch", "create", "update", "watch"},
+				StorageVersionHash: discovery.StorageVersionHash(workspaceName, "", "v1", "LimitRange"),
+			},
 			{
 				Kind:               "Namespace",
 				Name:               "namespaces",
@@ -23,6 +23,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/kcp-dev/logicalcluster"
 	"github.com/stretchr/testify/require"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -41,8 +42,9 @@ func TestClusterWorkspaceTypes(t *testing.T) {
 
 	type runningServer struct {
 		framework.RunningServer
-		orgKcpClient clientset.Interface
-		orgExpect    framework.RegisterClusterWorkspaceExpectation
+		orgClusterName logicalcluster.Name
+		orgKcpClient   clientset.Interface
+		orgExpect      framework.RegisterClusterWorkspaceExpectation
 	}
 	var testCases = []struct {
 		name string
@@ -150,6 +152,67 @@ func TestClusterWorkspaceTypes(t *testing.T) {
 				require.NoError(t, err, "workspace did not become ready")
 			},
 		},
+		{
+			name: "create workspaces honoring allowed child and parent types",
+			work: func(ctx context.Context, t *testing.T, server runningServer) {
+				t.Logf("Create type Bar that only allows Team parents")
+				_, err := server.orgKcpClient.TenancyV1alpha1().ClusterWorkspaceTypes().Create(ctx, &tenancyv1alpha1.ClusterWorkspaceType{
+					ObjectMeta: metav1.ObjectMeta{Name: "bar"},
+					Spec: tenancyv1alpha1.ClusterWorkspaceTypeSpec{
+						AllowedParentWorkspaceTypes: []tenancyv1alpha1.ClusterWorkspaceTypeName{"Team"},
+					},
+				}, metav1.CreateOptions{})
+				require.NoError(t, err, "failed to create workspace type")
+
+				t.Logf("Expect a Bar workspace in the organization to be rejected")
+				require.Eventually(t, func() bool {
+					// note: admission is informer based and hence would race with this create call
+					_, err = server.orgKcpClient.TenancyV1alpha1().ClusterWorkspaces().Create(ctx, &tenancyv1alpha1.ClusterWorkspace{
+						ObjectMeta: metav1.ObjectMeta{Name: "bar"},
+						Spec:       tenancyv1alpha1.ClusterWorkspaceSpec{Type: "Bar"},
+					}, metav1.CreateOptions{})
+					return err != nil && strings.Contains(err.Error(), `workspaces of type "Bar" can only be created in workspaces of types [Team]`)
+				}, wait.ForeverTestTimeout, time.Millisecond*100, "expected Bar workspace to be rejected, last error: %v", err)
+
+				t.Logf("Restrict the Team type to Universal children")
+				err = retry.RetryOnConflict(retry.DefaultRetry, func() error {
+					teamType, err := server.orgKcpClient.TenancyV1alpha1().ClusterWorkspaceTypes().Get(ctx, "team", metav1.GetOptions{})
+					if err != nil {
+						return err
+					}
+					teamType.Spec.AllowedChildWorkspaceTypes = []tenancyv1alpha1.ClusterWorkspaceTypeName{"Universal"}
+					_, err = server.orgKcpClient.TenancyV1alpha1().ClusterWorkspaceTypes().Update(ctx, teamType, metav1.UpdateOptions{})
+					return err
+				})
+				require.NoError(t, err, "failed to update workspace type")
+
+				team := framework.NewWorkspaceFixture(t, server, server.orgClusterName, "Team")
+				kcpClusterClient, err := kcpclientset.NewClusterForConfig(server.DefaultConfig(t))
+				require.NoError(t, err, "failed to construct client for server")
+				teamKcpClient := kcpClusterClient.Cluster(team)
+
+				t.Logf("Create type Qux in the Team workspace")
+				_, err = teamKcpClient.TenancyV1alpha1().ClusterWorkspaceTypes().Create(ctx, &tenancyv1alpha1.ClusterWorkspaceType{
+					ObjectMeta: metav1.ObjectMeta{Name: "qux"},
+				}, metav1.CreateOptions{})
+				require.NoError(t, err, "failed to create workspace type")
+
+				t.Logf("Expect a Qux workspace in the Team workspace to be rejected")
+				require.Eventually(t, func() bool {
+					_, err = teamKcpClient.TenancyV1alpha1().ClusterWorkspaces().Create(ctx, &tenancyv1alpha1.ClusterWorkspace{
+						ObjectMeta: metav1.ObjectMeta{Name: "qux"},
+						Spec:       tenancyv1alpha1.ClusterWorkspaceSpec{Type: "Qux"},
+					}, metav1.CreateOptions{})
+					return err != nil && strings.Contains(err.Error(), `of type "Team" only allows child workspaces of types [Universal], not "Qux"`)
+				}, wait.ForeverTestTimeout, time.Millisecond*100, "expected Qux workspace to be rejected, last error: %v", err)
+
+				t.Logf("Expect a Universal workspace in the Team workspace to be accepted")
+				_, err = teamKcpClient.TenancyV1alpha1().ClusterWorkspaces().Create(ctx, &tenancyv1alpha1.ClusterWorkspace{
+					ObjectMeta: metav1.ObjectMeta{Name: "universal"},
+				}, metav1.CreateOptions{})
+				require.NoError(t, err, "failed to create Universal workspace")
+			},
+		},
 		{
 			name: "create a workspace with deeper nesting",
 			work: func(ctx context.Context, t *testing.T, server runningServer) {
@@ -183,9 +246,10 @@ func TestClusterWorkspaceTypes(t *testing.T) {
 			require.NoError(t, err, "failed to start expecter")
 
 			testCase.work(ctx, t, runningServer{
-				RunningServer: server,
-				orgKcpClient:  orgKcpClient,
-				orgExpect:     orgExpect,
+				RunningServer:  server,
+				orgClusterName: orgClusterName,
+				orgKcpClient:   orgKcpClient,
+				orgExpect:      orgExpect,
 			})
 		})
 	}
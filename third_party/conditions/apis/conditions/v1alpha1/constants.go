@@ -38,6 +38,10 @@ const (
 
 	// IncorrectExternalRefReason (Severity=Error) documents an object with an incorrect external object reference.
 	IncorrectExternalRefReason = "IncorrectExternalRef"
+
+	// ReconcilingReason (Severity=Info) documents an condition not in Status=True because the conditions it
+	// summarizes have not observed the current generation of the object yet.
+	ReconcilingReason = "Reconciling"
 )
 
 const (
@@ -82,6 +82,12 @@ type Condition struct {
 	// This field may be empty.
 	// +optional
 	Message string `json:"message,omitempty"`
+
+	// The metadata.generation of the object the condition was observed for. If it is lower than
+	// the current metadata.generation, the condition is out-of-date with respect to the spec.
+	// This field is not set for conditions not tracking generations.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
 }
 
 // ANCHOR_END: Condition
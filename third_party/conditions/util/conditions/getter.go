@@ -17,6 +17,8 @@ limitations under the License.
 package conditions
 
 import (
+	"strings"
+
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -82,6 +84,16 @@ func IsUnknown(from Getter, t conditionsapi.ConditionType) bool {
 	return true
 }
 
+// IsObserved is true if the condition with the given type has observed the current generation
+// of the object, otherwise it returns false if the condition is out-of-date, does not track
+// generations or does not exist (is nil).
+func IsObserved(from Getter, t conditionsapi.ConditionType) bool {
+	if c := Get(from, t); c != nil {
+		return c.ObservedGeneration != 0 && c.ObservedGeneration == from.GetGeneration()
+	}
+	return false
+}
+
 // GetReason returns a nil safe string of Reason for the condition with the given type.
 func GetReason(from Getter, t conditionsapi.ConditionType) string {
 	if c := Get(from, t); c != nil {
@@ -184,7 +196,27 @@ func summary(from Getter, options ...MergeOption) *conditionsapi.Condition {
 		}
 	}
 
-	return merge(conditionsInScope, conditionsapi.ReadyCondition, mergeOpt)
+	target := merge(conditionsInScope, conditionsapi.ReadyCondition, mergeOpt)
+	if target == nil || !mergeOpt.observedGeneration {
+		return target
+	}
+
+	// Only report Ready=True if all the conditions in scope tracking generations have observed the
+	// current generation of the object; otherwise the summary would be based on an outdated spec.
+	generation := from.GetGeneration()
+	if target.Status == corev1.ConditionTrue {
+		var outdated []string
+		for _, c := range conditionsInScope {
+			if c.ObservedGeneration != 0 && c.ObservedGeneration != generation {
+				outdated = append(outdated, string(c.Type))
+			}
+		}
+		if len(outdated) > 0 {
+			target = UnknownCondition(conditionsapi.ReadyCondition, conditionsapi.ReconcilingReason, "%s not observed generation %d yet", strings.Join(outdated, ", "), generation)
+		}
+	}
+	target.ObservedGeneration = generation
+	return target
 }
 
 // mirrorOptions allows to set options for the mirror operation.
@@ -31,6 +31,7 @@ type mergeOptions struct {
 	addStepCounter                     bool
 	addStepCounterIfOnlyConditionTypes []conditionsapi.ConditionType
 	stepCounter                        int
+	observedGeneration                 bool
 }
 
 // MergeOption defines an option for computing a summary of conditions.
@@ -58,6 +59,17 @@ func WithStepCounter() MergeOption {
 	}
 }
 
+// WithObservedGeneration instructs merge to only report Status=True if all the conditions in scope
+// tracking generations have observed the current generation of the object, and to record it as the
+// observed generation of the target condition.
+//
+// IMPORTANT: This options works only while generating the Summary condition.
+func WithObservedGeneration() MergeOption {
+	return func(c *mergeOptions) {
+		c.observedGeneration = true
+	}
+}
+
 // WithStepCounterIf adds a step counter if the value is true.
 // This can be used e.g. to add a step counter only if the object is not being deleted.
 //
@@ -37,7 +37,8 @@ type Setter interface {
 // Set sets the given condition.
 //
 // NOTE: If a condition already exists, the LastTransitionTime is updated only if a change is detected
-// in any of the following fields: Status, Reason, Severity and Message.
+// in any of the following fields: Status, Reason, Severity and Message. The ObservedGeneration is
+// always updated.
 func Set(to Setter, condition *conditionsapi.Condition) {
 	if to == nil || condition == nil {
 		return
@@ -57,6 +58,7 @@ func Set(to Setter, condition *conditionsapi.Condition) {
 				break
 			}
 			condition.LastTransitionTime = existingCondition.LastTransitionTime
+			conditions[i] = *condition
 			break
 		}
 	}
@@ -121,6 +123,30 @@ func MarkFalse(to Setter, t conditionsapi.ConditionType, reason string, severity
 	Set(to, FalseCondition(t, reason, severity, messageFormat, messageArgs...))
 }
 
+// MarkTrueObserved sets Status=True for the condition with the given type, recording the
+// current generation of the object as observed.
+func MarkTrueObserved(to Setter, t conditionsapi.ConditionType) {
+	Set(to, observed(to, TrueCondition(t)))
+}
+
+// MarkUnknownObserved sets Status=Unknown for the condition with the given type, recording the
+// current generation of the object as observed.
+func MarkUnknownObserved(to Setter, t conditionsapi.ConditionType, reason, messageFormat string, messageArgs ...interface{}) {
+	Set(to, observed(to, UnknownCondition(t, reason, messageFormat, messageArgs...)))
+}
+
+// MarkFalseObserved sets Status=False for the condition with the given type, recording the
+// current generation of the object as observed.
+func MarkFalseObserved(to Setter, t conditionsapi.ConditionType, reason string, severity conditionsapi.ConditionSeverity, messageFormat string, messageArgs ...interface{}) {
+	Set(to, observed(to, FalseCondition(t, reason, severity, messageFormat, messageArgs...)))
+}
+
+// observed sets the ObservedGeneration of the given condition to the current generation of the object.
+func observed(to Getter, condition *conditionsapi.Condition) *conditionsapi.Condition {
+	condition.ObservedGeneration = to.GetGeneration()
+	return condition
+}
+
 // SetSummary sets a Ready condition with the summary of all the conditions existing
 // on an object. If the object does not have other conditions, no summary condition is generated.
 func SetSummary(to Setter, options ...MergeOption) {
@@ -226,6 +226,55 @@ func TestMarkMethods(t *testing.T) {
 	}))
 }
 
+func TestMarkObservedMethods(t *testing.T) {
+	g := NewWithT(t)
+
+	cluster := newConditioned("test")
+	cluster.SetGeneration(2)
+
+	MarkTrueObserved(cluster, "conditionFoo")
+	MarkFalseObserved(cluster, "conditionBar", "reasonBar", conditionsapi.ConditionSeverityError, "messageBar")
+	MarkUnknownObserved(cluster, "conditionBaz", "reasonBaz", "messageBaz")
+	for _, conditionType := range []conditionsapi.ConditionType{"conditionFoo", "conditionBar", "conditionBaz"} {
+		g.Expect(Get(cluster, conditionType).ObservedGeneration).To(Equal(int64(2)))
+		g.Expect(IsObserved(cluster, conditionType)).To(BeTrue())
+	}
+
+	// a new generation is recorded without a transition
+	lastTransitionTime := Get(cluster, "conditionFoo").LastTransitionTime
+	cluster.SetGeneration(3)
+	g.Expect(IsObserved(cluster, "conditionFoo")).To(BeFalse())
+	MarkTrueObserved(cluster, "conditionFoo")
+	g.Expect(IsObserved(cluster, "conditionFoo")).To(BeTrue())
+	g.Expect(Get(cluster, "conditionFoo").LastTransitionTime).To(Equal(lastTransitionTime))
+
+	// conditions not tracking generations are never observed
+	MarkTrue(cluster, "conditionFoo")
+	g.Expect(IsObserved(cluster, "conditionFoo")).To(BeFalse())
+}
+
+func TestSetSummaryWithObservedGeneration(t *testing.T) {
+	g := NewWithT(t)
+
+	foo := TrueCondition("foo")
+	foo.ObservedGeneration = 1
+	bar := TrueCondition("bar")
+	target := setterWithConditions(foo, bar)
+	target.(*conditioned).SetGeneration(2)
+
+	// foo has not observed the current generation
+	SetSummary(target, WithObservedGeneration())
+	g.Expect(IsUnknown(target, conditionsapi.ReadyCondition)).To(BeTrue())
+	g.Expect(GetReason(target, conditionsapi.ReadyCondition)).To(Equal(conditionsapi.ReconcilingReason))
+	g.Expect(Get(target, conditionsapi.ReadyCondition).ObservedGeneration).To(Equal(int64(2)))
+
+	// bar does not track generations
+	MarkTrueObserved(target, "foo")
+	SetSummary(target, WithObservedGeneration())
+	g.Expect(IsTrue(target, conditionsapi.ReadyCondition)).To(BeTrue())
+	g.Expect(IsObserved(target, conditionsapi.ReadyCondition)).To(BeTrue())
+}
+
 func TestSetSummary(t *testing.T) {
 	g := NewWithT(t)
 	target := setterWithConditions(TrueCondition("foo"))